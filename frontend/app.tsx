@@ -1,6 +1,7 @@
 // Copyright 2025, Command Line Inc.
 // SPDX-License-Identifier: Apache-2.0
 
+import { EffectiveConfigModalContainer } from "@/apprunlist/effectiveconfig-modal";
 import { CodeLinkPickerModalContainer } from "@/codelink/codelink-picker-modal";
 import { DisconnectionModalContainer } from "@/elements/disconnection-modal";
 import { ToastContainer } from "@/elements/toast";
@@ -9,6 +10,7 @@ import { HomePage } from "@/homepage/homepage";
 import { GettingStartedModalContainer } from "@/homepage/gettingstarted-modal";
 import { MainApp } from "@/mainapp/mainapp";
 import { SettingsModalContainer } from "@/settings/settings-modal";
+import { SnapshotsModalContainer } from "@/snapshots/snapshots-modal";
 import { keydownWrapper } from "@/util/keyutil";
 import { serverConnectedAtom } from "@/websocket/client";
 import { useAtom, useAtomValue } from "jotai";
@@ -91,6 +93,8 @@ function AppWrapper({ children }: AppWrapperProps) {
             <UpdateModalContainer />
             <CodeLinkPickerModalContainer />
             <GettingStartedModalContainer />
+            <SnapshotsModalContainer />
+            <EffectiveConfigModalContainer />
             <DisconnectionModalContainer />
             
             {/* Portal container for highlight overlays */}
@@ -6,7 +6,8 @@ import { Toast } from "@/elements/toast";
 import { emitter } from "@/events";
 import { DefaultRpcClient } from "@/init";
 import { RpcApi } from "@/rpc/rpcclientapi";
-import { sendHomepageEvent, sendTabEvent } from "@/tevent";
+import { SettingsModel } from "@/settings/settings-model";
+import { sendHomepageEvent, sendSelectAppRunEvent, sendTabEvent } from "@/tevent";
 import { isBlank } from "@/util/util";
 import { atom, Atom, getDefaultStore, PrimitiveAtom } from "jotai";
 
@@ -37,6 +38,8 @@ class AppModel {
     updateModalOpen: PrimitiveAtom<boolean> = atom<boolean>(false); // State for update modal
     codeLinkPickerModalOpen: PrimitiveAtom<boolean> = atom<boolean>(false); // State for code link picker modal
     gettingStartedModalOpen: PrimitiveAtom<boolean> = atom<boolean>(false); // State for getting started modal
+    snapshotsModalOpen: PrimitiveAtom<boolean> = atom<boolean>(false); // State for scheduled snapshots modal
+    effectiveConfigAppRunId: PrimitiveAtom<string> = atom(null) as PrimitiveAtom<string>; // App run shown in the effective config modal (null when closed)
     newerVersion: PrimitiveAtom<string> = atom(null) as PrimitiveAtom<string>; // Newer version available
     fromTrayApp: PrimitiveAtom<boolean> = atom<boolean>(false); // Whether we're running from tray app
     isSearchTipsOpen: PrimitiveAtom<boolean> = atom<boolean>(false); // State for search tips popup
@@ -120,7 +123,7 @@ class AppModel {
                 // Send tab event on initial load/refresh
                 // We use setTimeout to ensure this happens after RPC client is initialized
                 setTimeout(() => {
-                    sendTabEvent(tabParam);
+                    sendTabEvent(tabParam, appRunIdParam);
                 }, 500);
             }
         } else {
@@ -214,6 +217,7 @@ class AppModel {
         this.updateUrl({ tab: tab }, false);
 
         this.checkAndDisableAutoFollow(appRunId);
+        sendSelectAppRunEvent(appRunId);
         // Check for updates when selecting a new app run with a small delay
         setTimeout(() => this.checkForUpdates(), 500);
     }
@@ -228,6 +232,7 @@ class AppModel {
         if (!isAutoFollowSelection) {
             this.checkAndDisableAutoFollow(appRunId);
         }
+        sendSelectAppRunEvent(appRunId);
 
         // Check for updates when selecting a new app run with a small delay
         setTimeout(() => this.checkForUpdates(), 500);
@@ -269,7 +274,7 @@ class AppModel {
         // Use replaceState for tab navigation (no history entry)
         this.updateUrl({ tab: "logs" }, false);
         // Send tab event
-        sendTabEvent("logs");
+        sendTabEvent("logs", getDefaultStore().get(this.selectedAppRunId));
     }
 
     // This method is kept for backward compatibility
@@ -284,7 +289,7 @@ class AppModel {
         // Use replaceState for tab navigation (no history entry)
         this.updateUrl({ tab: "goroutines" }, false);
         // Send tab event
-        sendTabEvent("goroutines");
+        sendTabEvent("goroutines", getDefaultStore().get(this.selectedAppRunId));
     }
 
     selectWatchesTab() {
@@ -293,7 +298,7 @@ class AppModel {
         // Use replaceState for tab navigation (no history entry)
         this.updateUrl({ tab: "watches" }, false);
         // Send tab event
-        sendTabEvent("watches");
+        sendTabEvent("watches", getDefaultStore().get(this.selectedAppRunId));
     }
 
     selectRuntimeStatsTab() {
@@ -302,7 +307,7 @@ class AppModel {
         // Use replaceState for tab navigation (no history entry)
         this.updateUrl({ tab: "runtimestats" }, false);
         // Send tab event
-        sendTabEvent("runtimestats");
+        sendTabEvent("runtimestats", getDefaultStore().get(this.selectedAppRunId));
     }
 
     applyTheme(): void {
@@ -387,6 +392,34 @@ class AppModel {
         emitter.emit("modalclose");
     }
 
+    openSnapshotsModal(): void {
+        getDefaultStore().set(this.snapshotsModalOpen, true);
+
+        // Blur any active element to ensure it doesn't receive input
+        if (document.activeElement instanceof HTMLElement) {
+            document.activeElement.blur();
+        }
+    }
+
+    closeSnapshotsModal(): void {
+        getDefaultStore().set(this.snapshotsModalOpen, false);
+        emitter.emit("modalclose");
+    }
+
+    openEffectiveConfigModal(appRunId: string): void {
+        getDefaultStore().set(this.effectiveConfigAppRunId, appRunId);
+
+        // Blur any active element to ensure it doesn't receive input
+        if (document.activeElement instanceof HTMLElement) {
+            document.activeElement.blur();
+        }
+    }
+
+    closeEffectiveConfigModal(): void {
+        getDefaultStore().set(this.effectiveConfigAppRunId, null);
+        emitter.emit("modalclose");
+    }
+
     // Search tips management
     openSearchTips(): void {
         getDefaultStore().set(this.isSearchTipsOpen, true);
@@ -442,19 +475,21 @@ class AppModel {
     }
 
     // Send the current browser tab URL to the backend
-    sendBrowserTabUrl() {
-        if (!DefaultRpcClient) return;
+    sendBrowserTabUrl(): Promise<void> {
+        if (!DefaultRpcClient) return Promise.resolve();
 
         const currentUrl = window.location.href;
         const selectedAppRunId = getDefaultStore().get(this.selectedAppRunId);
         const autoFollow = getDefaultStore().get(this.autoFollow);
+        const userName = getDefaultStore().get(SettingsModel.presenceUserName);
 
-        // Send the URL, app run ID, focus state, and autofollow state to the backend
-        RpcApi.UpdateBrowserTabUrlCommand(DefaultRpcClient, {
+        // Send the URL, app run ID, focus state, autofollow state, and user name (for presence) to the backend
+        return RpcApi.UpdateBrowserTabUrlCommand(DefaultRpcClient, {
             url: currentUrl,
             apprunid: selectedAppRunId || "",
             focused: document.hasFocus(),
             autofollow: autoFollow,
+            username: userName,
         }).catch((err: Error) => {
             console.error("Failed to send URL to backend:", err);
         });
@@ -201,6 +201,23 @@ class AppRunListModel {
         return `started at ${startDate.toLocaleString()}`;
     }
 
+    // Export the app run as a bundle (logs, goroutines, watches, runtime stats) and download it,
+    // the bundle can be loaded into another monitor with "outrig import"
+    async exportAppRun(appRun: AppRunInfo) {
+        try {
+            const result = await RpcApi.ExportAppRunCommand(DefaultRpcClient, { apprunid: appRun.apprunid });
+            const link = document.createElement("a");
+            link.href = result.downloadurl;
+            link.download = result.filename;
+            document.body.appendChild(link);
+            link.click();
+            link.remove();
+        } catch (error) {
+            console.error("Failed to export app run:", error);
+            AppModel.showToast("Export Failed", `Could not export app run ${appRun.appname}: ${error.message ?? error}`, 5000);
+        }
+    }
+
     // Method to trigger a full refresh of app runs
     triggerFullRefresh() {
         this.needsFullAppRunsRefresh = true;
@@ -6,8 +6,20 @@ import { AppRunListModel } from "@/apprunlist/apprunlist-model";
 import { Tag } from "@/elements/tag";
 import { cn, formatDuration, formatRelativeTime } from "@/util/util";
 import { useAtomValue } from "jotai";
-import { Box, CircleDot, Clock, ExternalLink, Eye, List, Plus } from "lucide-react";
-import { useEffect, useMemo, useState } from "react";
+import {
+    Box,
+    ChevronDown,
+    ChevronRight,
+    CircleDot,
+    Clock,
+    Download,
+    ExternalLink,
+    Eye,
+    List,
+    Plus,
+    SlidersHorizontal,
+} from "lucide-react";
+import React, { useEffect, useMemo, useState } from "react";
 
 interface AppRunStatusTagProps {
     status: string;
@@ -23,13 +35,79 @@ const AppRunStatusTag: React.FC<AppRunStatusTagProps> = ({ status }) => {
     }
 };
 
+interface AppRunMetaProps {
+    meta: { [key: string]: string };
+}
+
+// AppRunMeta shows the app run metadata (outrig.SetAppMeta / outrig run --meta) as key=value chips
+const AppRunMeta: React.FC<AppRunMetaProps> = ({ meta }) => {
+    const keys = Object.keys(meta).sort();
+    return (
+        <div className="mt-1 flex flex-wrap gap-1 text-xs">
+            {keys.map((key) => (
+                <span
+                    key={key}
+                    className="px-1.5 py-0.5 rounded bg-buttonhover text-secondary font-mono truncate max-w-[200px]"
+                    title={`${key}=${meta[key]}`}
+                >
+                    {key}={meta[key]}
+                </span>
+            ))}
+        </div>
+    );
+};
+
+// the app runs of one "outrig run --watch" share the runseries metadata (see runmode.MetaKeyRunSeries)
+const RunSeriesMetaKey = "runseries";
+
+// AppRunSeries is an app run list entry, the newest run of a watch mode series (or a single app run) and the
+// series' earlier runs
+interface AppRunSeries {
+    seriesKey: string; // the runseries id (the app run id for a single app run)
+    appRun: AppRunInfo;
+    earlierRuns: AppRunInfo[];
+}
+
+// groupAppRunSeries groups the (sorted) app runs by their run series, the first run of a series in the
+// order represents it
+function groupAppRunSeries(appRuns: AppRunInfo[]): AppRunSeries[] {
+    const rtn: AppRunSeries[] = [];
+    const seriesMap = new Map<string, AppRunSeries>();
+    for (const appRun of appRuns) {
+        const seriesId = appRun.meta?.[RunSeriesMetaKey];
+        const series = seriesId ? seriesMap.get(seriesId) : null;
+        if (series) {
+            series.earlierRuns.push(appRun);
+            continue;
+        }
+        const newSeries: AppRunSeries = { seriesKey: seriesId || appRun.apprunid, appRun, earlierRuns: [] };
+        if (seriesId) {
+            seriesMap.set(seriesId, newSeries);
+        }
+        rtn.push(newSeries);
+    }
+    return rtn;
+}
+
 interface AppRunItemProps {
     appRun: AppRunInfo;
     onClick: (appRunId: string) => void;
     isSelected: boolean;
+    isEarlierRun?: boolean;
+    numEarlierRuns?: number;
+    earlierRunsExpanded?: boolean;
+    onToggleEarlierRuns?: () => void;
 }
 
-const AppRunItem: React.FC<AppRunItemProps> = ({ appRun, onClick, isSelected }) => {
+const AppRunItem: React.FC<AppRunItemProps> = ({
+    appRun,
+    onClick,
+    isSelected,
+    isEarlierRun,
+    numEarlierRuns,
+    earlierRunsExpanded,
+    onToggleEarlierRuns,
+}) => {
     const [currentTime, setCurrentTime] = useState(() => Date.now());
 
     // Only update the time for running apps
@@ -62,6 +140,7 @@ const AppRunItem: React.FC<AppRunItemProps> = ({ appRun, onClick, isSelected })
         <div
             className={cn(
                 "p-4 hover:bg-buttonhover cursor-pointer block relative group",
+                isEarlierRun && "pl-10",
                 isSelected && "bg-buttonhover border-l-4 border-l-accent"
             )}
             onClick={() => onClick(appRun.apprunid)}
@@ -81,8 +160,34 @@ const AppRunItem: React.FC<AppRunItemProps> = ({ appRun, onClick, isSelected })
                     >
                         <ExternalLink size={14} />
                     </a>
+                    {!appRun.monitor && (
+                        <button
+                            className="ml-2 opacity-0 group-hover:opacity-100 transition-opacity text-muted hover:text-primary cursor-pointer"
+                            title="Export app run (load it into another monitor with 'outrig import')"
+                            onClick={(e) => {
+                                e.stopPropagation();
+                                AppRunListModel.exportAppRun(appRun);
+                            }}
+                        >
+                            <Download size={14} />
+                        </button>
+                    )}
+                    {!appRun.monitor && (
+                        <button
+                            className="ml-2 opacity-0 group-hover:opacity-100 transition-opacity text-muted hover:text-primary cursor-pointer"
+                            title="Effective config (app and monitor)"
+                            onClick={(e) => {
+                                e.stopPropagation();
+                                AppModel.openEffectiveConfigModal(appRun.apprunid);
+                            }}
+                        >
+                            <SlidersHorizontal size={14} />
+                        </button>
+                    )}
                 </div>
-                <div className="text-xs text-secondary">
+                <div className="text-xs text-secondary flex items-center gap-1">
+                    {appRun.imported && <Tag label="Imported" variant="secondary" isSelected={false} />}
+                    {appRun.monitor && <Tag label={appRun.monitor} variant="secondary" isSelected={false} />}
                     <AppRunStatusTag status={appRun.status} />
                 </div>
             </div>
@@ -143,7 +248,23 @@ const AppRunItem: React.FC<AppRunItemProps> = ({ appRun, onClick, isSelected })
                     </a>
                 )}
                 <div className="text-muted">({appRun.apprunid.substring(0, 8)})</div>
+                {numEarlierRuns > 0 && (
+                    <button
+                        className="flex items-center space-x-1 hover:text-primary cursor-pointer"
+                        title="Earlier runs of this outrig run --watch session"
+                        onClick={(e) => {
+                            e.stopPropagation();
+                            onToggleEarlierRuns?.();
+                        }}
+                    >
+                        {earlierRunsExpanded ? <ChevronDown size={12} /> : <ChevronRight size={12} />}
+                        <span>
+                            {numEarlierRuns} earlier {numEarlierRuns === 1 ? "run" : "runs"}
+                        </span>
+                    </button>
+                )}
             </div>
+            {appRun.meta && Object.keys(appRun.meta).length > 0 && <AppRunMeta meta={appRun.meta} />}
         </div>
     );
 };
@@ -167,6 +288,20 @@ export const AppRunList: React.FC<AppRunListProps> = ({ emptyStateComponent }) =
             return b.starttime - a.starttime;
         });
     }, [unsortedAppRuns]);
+    const appRunSeries = useMemo(() => groupAppRunSeries(appRuns), [appRuns]);
+    const [expandedSeries, setExpandedSeries] = useState<Set<string>>(() => new Set());
+
+    const toggleSeries = (seriesKey: string) => {
+        setExpandedSeries((prev) => {
+            const next = new Set(prev);
+            if (next.has(seriesKey)) {
+                next.delete(seriesKey);
+            } else {
+                next.add(seriesKey);
+            }
+            return next;
+        });
+    };
 
     const handleAppRunClick = (appRunId: string) => {
         AppModel.selectAppRun(appRunId);
@@ -180,14 +315,34 @@ export const AppRunList: React.FC<AppRunListProps> = ({ emptyStateComponent }) =
                 ) : (
                     <>
                         <div className="divide-y divide-border">
-                            {appRuns.map((appRun) => (
-                                <AppRunItem
-                                    key={appRun.apprunid}
-                                    appRun={appRun}
-                                    onClick={handleAppRunClick}
-                                    isSelected={appRun.apprunid === selectedAppRunId}
-                                />
-                            ))}
+                            {appRunSeries.map(({ seriesKey, appRun, earlierRuns }) => {
+                                // a selected earlier run stays visible
+                                const expanded =
+                                    expandedSeries.has(seriesKey) ||
+                                    earlierRuns.some((run) => run.apprunid === selectedAppRunId);
+                                return (
+                                    <React.Fragment key={seriesKey}>
+                                        <AppRunItem
+                                            appRun={appRun}
+                                            onClick={handleAppRunClick}
+                                            isSelected={appRun.apprunid === selectedAppRunId}
+                                            numEarlierRuns={earlierRuns.length}
+                                            earlierRunsExpanded={expanded}
+                                            onToggleEarlierRuns={() => toggleSeries(seriesKey)}
+                                        />
+                                        {expanded &&
+                                            earlierRuns.map((run) => (
+                                                <AppRunItem
+                                                    key={run.apprunid}
+                                                    appRun={run}
+                                                    onClick={handleAppRunClick}
+                                                    isSelected={run.apprunid === selectedAppRunId}
+                                                    isEarlierRun={true}
+                                                />
+                                            ))}
+                                    </React.Fragment>
+                                );
+                            })}
                         </div>
                         {/* Final divider at the bottom of the list */}
                         <div className="border-t border-border"></div>
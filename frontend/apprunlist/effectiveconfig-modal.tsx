@@ -0,0 +1,304 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+import { AppModel } from "@/appmodel";
+import { Modal } from "@/elements/modal";
+import { DefaultRpcClient } from "@/init";
+import { RpcApi } from "@/rpc/rpcclientapi";
+import { cn } from "@/util/util";
+import { useAtomValue } from "jotai";
+import { RefreshCw } from "lucide-react";
+import React, { useCallback, useEffect, useState } from "react";
+
+const buttonClassName =
+    "px-2 py-1 rounded text-xs cursor-pointer border border-border text-primary hover:bg-buttonhover disabled:opacity-50 disabled:cursor-default";
+const preClassName = "mt-1 p-2 text-xs font-mono bg-panel border border-border rounded overflow-auto max-h-[300px]";
+
+// Container component that checks isOpen state
+export const EffectiveConfigModalContainer: React.FC = () => {
+    const appRunId = useAtomValue(AppModel.effectiveConfigAppRunId);
+
+    if (appRunId == null) return null;
+
+    return <EffectiveConfigModal appRunId={appRunId} />;
+};
+
+interface ConfigRowProps {
+    label: string;
+    value: React.ReactNode;
+    fromFile?: boolean;
+}
+
+const ConfigRow: React.FC<ConfigRowProps> = ({ label, value, fromFile }) => {
+    return (
+        <div className="flex gap-3 py-0.5 text-sm">
+            <div className="w-44 shrink-0 text-muted">{label}</div>
+            <div className="flex-1 min-w-0 font-mono text-primary break-all">
+                {value}
+                {fromFile && <span className="ml-2 font-sans text-xs text-accent">(monitor.json)</span>}
+            </div>
+        </div>
+    );
+};
+
+function describeSource(source: string): string {
+    if (source === "default-config") {
+        return "defaults (no config file found)";
+    }
+    if (source === "init") {
+        return "passed to outrig.Init";
+    }
+    if (source.startsWith("file:")) {
+        return `config file ${source.substring(5)}`;
+    }
+    if (source.startsWith("env:")) {
+        return `environment variable ${source.substring(4)}`;
+    }
+    return source || "unknown";
+}
+
+function describeEnabled(collectorConfig: { enabled: boolean }): string {
+    if (collectorConfig == null) {
+        return "-";
+    }
+    return collectorConfig.enabled ? "enabled" : "disabled";
+}
+
+function describePlatform(sdk: SDKEffectiveConfig): string {
+    const caps = sdk.capabilities;
+    return `${caps.platform} (${caps.compiler}${caps.restricted ? ", restricted" : ""})`;
+}
+
+interface CollectorsTableProps {
+    sdk: SDKEffectiveConfig;
+}
+
+// Shows each collector's enabled flag in the resolved config next to its last reported status
+const CollectorsTable: React.FC<CollectorsTableProps> = ({ sdk }) => {
+    const configCollectors = (sdk.config.collectors ?? {}) as { [name: string]: { enabled: boolean } };
+    const statuses = sdk.collectors ?? {};
+    const names = [...new Set([...Object.keys(configCollectors), ...Object.keys(statuses)])].sort();
+
+    return (
+        <table className="w-full text-sm">
+            <thead>
+                <tr className="text-left text-muted text-xs">
+                    <th className="font-normal pb-1">Collector</th>
+                    <th className="font-normal pb-1">Config</th>
+                    <th className="font-normal pb-1">Status</th>
+                </tr>
+            </thead>
+            <tbody>
+                {names.map((name) => {
+                    const status = statuses[name];
+                    const problems = [...(status?.errors ?? []), ...(status?.warnings ?? [])];
+                    return (
+                        <tr key={name} className="border-t border-border align-top">
+                            <td className="py-1 font-mono text-primary">{name}</td>
+                            <td className="py-1 text-secondary">{describeEnabled(configCollectors[name])}</td>
+                            <td className="py-1">
+                                <span className={cn(status?.running ? "text-success" : "text-secondary")}>
+                                    {status == null ? "not reported" : status.running ? "running" : "not running"}
+                                </span>
+                                {status?.info && <div className="text-xs text-muted">{status.info}</div>}
+                                {problems.map((problem, idx) => (
+                                    <div key={idx} className="text-xs text-error">
+                                        {problem}
+                                    </div>
+                                ))}
+                            </td>
+                        </tr>
+                    );
+                })}
+            </tbody>
+        </table>
+    );
+};
+
+function describeLogTail(logTail: LogTailStatus): string {
+    const target = logTail.file ? logTail.file : `journald:${logTail.journald}`;
+    return logTail.appname ? `${target} => ${logTail.appname}` : target;
+}
+
+// The log files and journald units the monitor follows, with the lines added and dropped so far
+const LogTailList: React.FC<{ logTails: LogTailStatus[] }> = ({ logTails }) => {
+    return (
+        <div>
+            {logTails.map((logTail, idx) => (
+                <div key={idx}>
+                    {describeLogTail(logTail)}
+                    <span className={cn("ml-2 font-sans text-xs", logTail.running ? "text-success" : "text-secondary")}>
+                        {logTail.running ? "running" : "not running"}, {logTail.numlines} lines
+                        {logTail.numdropped ? `, ${logTail.numdropped} dropped (no matching app run)` : ""}
+                    </span>
+                    {logTail.error && <div className="font-sans text-xs text-error">{logTail.error}</div>}
+                </div>
+            ))}
+        </div>
+    );
+};
+
+interface MonitorConfigSectionProps {
+    monitor: MonitorEffectiveConfig;
+}
+
+const MonitorConfigSection: React.FC<MonitorConfigSectionProps> = ({ monitor }) => {
+    const fileSettings = new Set(monitor.configfilesettings ?? []);
+    const downstreams = Object.entries(monitor.downstreams ?? {})
+        .map(([name, addr]) => `${name}=${addr}`)
+        .join(", ");
+
+    return (
+        <div className="bg-secondary/10 rounded-lg p-4">
+            <h2 className="text-lg font-semibold mb-2 border-b border-secondary/20 pb-2">Monitor</h2>
+            <ConfigRow label="Version" value={`${monitor.version}${monitor.dev ? " (dev)" : ""}`} />
+            <ConfigRow label="Outrig home" value={monitor.outrighome} />
+            <ConfigRow label="Data directory" value={monitor.datadir} />
+            <ConfigRow
+                label="Config file"
+                value={`${monitor.configfile}${monitor.configfileexists ? "" : " (not found)"}`}
+            />
+            {monitor.configfileerror && (
+                <div className="my-1 text-xs text-error">
+                    The last config file reload was rejected (the previous config is still in effect):{" "}
+                    {monitor.configfileerror}
+                </div>
+            )}
+            <ConfigRow
+                label="Log buffer size"
+                value={monitor.logbuffersizemb ? `${monitor.logbuffersizemb} MB` : "0 (in memory)"}
+                fromFile={fileSettings.has("logbuffersizemb")}
+            />
+            <ConfigRow
+                label="Max runs per app"
+                value={monitor.maxrunsperapp || "no limit"}
+                fromFile={fileSettings.has("maxrunsperapp")}
+            />
+            <ConfigRow
+                label="Max run age"
+                value={monitor.maxrunage === "0s" ? "no limit" : monitor.maxrunage}
+                fromFile={fileSettings.has("maxrunage")}
+            />
+            <ConfigRow
+                label="Max data dir size"
+                value={monitor.maxdatadirsizemb ? `${monitor.maxdatadirsizemb} MB` : "no limit"}
+                fromFile={fileSettings.has("maxdatadirsizemb")}
+            />
+            <ConfigRow
+                label="Remote SDK listener"
+                value={monitor.remotelisten || "off"}
+                fromFile={fileSettings.has("remotelisten")}
+            />
+            <ConfigRow
+                label="Embed allowed origins"
+                value={monitor.embedallowedorigins?.join(", ") || "none"}
+                fromFile={fileSettings.has("embedallowedorigins")}
+            />
+            <ConfigRow
+                label="Downstream monitors"
+                value={downstreams || "none"}
+                fromFile={fileSettings.has("downstreams")}
+            />
+            <ConfigRow
+                label="Session timeline"
+                value={monitor.sessiontimeline ? "on" : "off"}
+                fromFile={fileSettings.has("sessiontimeline")}
+            />
+            <ConfigRow
+                label="Log search index"
+                value={monitor.logsearchindex ? "on" : "off"}
+                fromFile={fileSettings.has("logsearchindex")}
+            />
+            <ConfigRow
+                label="Log tails"
+                value={monitor.logtails?.length ? <LogTailList logTails={monitor.logtails} /> : "none"}
+                fromFile={fileSettings.has("logtails")}
+            />
+        </div>
+    );
+};
+
+interface EffectiveConfigModalProps {
+    appRunId: string;
+}
+
+// The config an app run's SDK resolved (defaults, config file, env overrides) and the monitor's own config
+export const EffectiveConfigModal: React.FC<EffectiveConfigModalProps> = ({ appRunId }) => {
+    const [data, setData] = useState<EffectiveConfigData>(null);
+    const [error, setError] = useState<string>(null);
+    const [isLoading, setIsLoading] = useState(false);
+
+    const loadData = useCallback(async () => {
+        setIsLoading(true);
+        try {
+            setData(await RpcApi.GetEffectiveConfigCommand(DefaultRpcClient, { apprunid: appRunId }));
+            setError(null);
+        } catch (e) {
+            setError(`${e.message ?? e}`);
+        } finally {
+            setIsLoading(false);
+        }
+    }, [appRunId]);
+
+    useEffect(() => {
+        loadData();
+    }, [loadData]);
+
+    const sdk = data?.sdk;
+    const env = Object.entries(sdk?.env ?? {}).sort(([a], [b]) => a.localeCompare(b));
+
+    return (
+        <Modal
+            isOpen={true}
+            title={data?.appname ? `Effective Config: ${data.appname}` : "Effective Config"}
+            onClose={() => AppModel.closeEffectiveConfigModal()}
+            className="w-[800px]"
+        >
+            <div className="text-primary p-1 space-y-4">
+                <div className="flex items-center justify-between">
+                    <div className="text-sm text-muted">
+                        {isLoading && !data ? "Loading..." : "The config the app and the monitor run with."}
+                    </div>
+                    <button onClick={loadData} disabled={isLoading} className={buttonClassName}>
+                        <RefreshCw size={12} className="inline mr-1" />
+                        Refresh
+                    </button>
+                </div>
+                {error && <div className="text-sm text-error">{error}</div>}
+
+                {data && (
+                    <div className="bg-secondary/10 rounded-lg p-4">
+                        <h2 className="text-lg font-semibold mb-2 border-b border-secondary/20 pb-2">App (SDK)</h2>
+                        {data.sdkerror && <div className="text-sm text-muted">{data.sdkerror}</div>}
+                        {sdk && (
+                            <div className="space-y-3">
+                                <div>
+                                    <ConfigRow label="SDK version" value={sdk.sdkversion || "unknown"} />
+                                    <ConfigRow label="Config from" value={describeSource(sdk.source)} />
+                                    {sdk.capabilities && <ConfigRow label="Platform" value={describePlatform(sdk)} />}
+                                </div>
+                                <CollectorsTable sdk={sdk} />
+                                {env.length > 0 && (
+                                    <div>
+                                        <div className="text-xs text-muted mb-1">OUTRIG_* environment variables</div>
+                                        {env.map(([key, value]) => (
+                                            <ConfigRow key={key} label={key} value={value} />
+                                        ))}
+                                    </div>
+                                )}
+                                <details>
+                                    <summary className="text-xs text-muted cursor-pointer">
+                                        Resolved config (JSON)
+                                    </summary>
+                                    <pre className={preClassName}>{JSON.stringify(sdk.config, null, 2)}</pre>
+                                </details>
+                            </div>
+                        )}
+                    </div>
+                )}
+
+                {data && <MonitorConfigSection monitor={data.monitor} />}
+            </div>
+        </Modal>
+    );
+};
@@ -64,6 +64,14 @@ type InternalStateType = {
 type SegmentType = {
     text: string;
     classes: string;
+    start: number; // offset of the text in the line (including escape codes)
+    highlight?: boolean;
+};
+
+// HighlightRange is a [start, end) string offset range in the line (offsets include ANSI escape codes)
+type HighlightRange = {
+    start: number;
+    end: number;
 };
 
 const makeInitialState = (): InternalStateType => ({
@@ -115,17 +123,48 @@ const stateToClasses = (state: InternalStateType): string => {
     return classes.join(" ");
 };
 
+const segmentSubstr = (seg: SegmentType, start: number, end: number): string => {
+    return seg.text.substring(start - seg.start, end - seg.start);
+};
+
+// splitHighlights splits the segments at the highlight range boundaries (ranges must be sorted)
+const splitHighlights = (segments: SegmentType[], highlights: HighlightRange[]): SegmentType[] => {
+    const rtn: SegmentType[] = [];
+    for (const seg of segments) {
+        let pos = seg.start;
+        const segEnd = seg.start + seg.text.length;
+        for (const hl of highlights) {
+            if (hl.end <= pos || hl.start >= segEnd) {
+                continue;
+            }
+            if (hl.start > pos) {
+                rtn.push({ ...seg, text: segmentSubstr(seg, pos, hl.start), start: pos });
+                pos = hl.start;
+            }
+            const end = Math.min(hl.end, segEnd);
+            rtn.push({ ...seg, text: segmentSubstr(seg, pos, end), start: pos, highlight: true });
+            pos = end;
+        }
+        if (pos < segEnd) {
+            rtn.push({ ...seg, text: segmentSubstr(seg, pos, segEnd), start: pos });
+        }
+    }
+    return rtn;
+};
+
 // eslint-disable-next-line no-control-regex
 const ansiRegex = /\x1b\[([0-9;]+)m/g;
 
 interface AnsiLineProps {
     line: string;
     className?: string;
+    highlights?: HighlightRange[]; // parts of the line to highlight (e.g. search matches)
 }
 
-const AnsiLine: React.FC<AnsiLineProps> = React.memo(({ line, className = "" }) => {
+const AnsiLine: React.FC<AnsiLineProps> = React.memo(({ line, className = "", highlights }) => {
+    const hasHighlights = highlights != null && highlights.length > 0;
     // Fast path: if no ANSI escapes are found, just render the text.
-    if (!line.includes("\x1b[")) {
+    if (!line.includes("\x1b[") && !hasHighlights) {
         return <div className={className}>{line}</div>;
     }
 
@@ -140,6 +179,7 @@ const AnsiLine: React.FC<AnsiLineProps> = React.memo(({ line, className = "" })
             segments.push({
                 text: line.substring(lastIndex, match.index),
                 classes: stateToClasses(currentState),
+                start: lastIndex,
             });
         }
         const codes = match[1].split(";").map(Number);
@@ -150,19 +190,27 @@ const AnsiLine: React.FC<AnsiLineProps> = React.memo(({ line, className = "" })
         segments.push({
             text: line.substring(lastIndex),
             classes: stateToClasses(currentState),
+            start: lastIndex,
         });
     }
+    const finalSegments = hasHighlights ? splitHighlights(segments, highlights) : segments;
 
     return (
         <div className={className}>
-            {segments.map((seg, idx) => (
-                <span key={idx} className={seg.classes}>
-                    {seg.text}
-                </span>
-            ))}
+            {finalSegments.map((seg, idx) =>
+                seg.highlight ? (
+                    <mark key={idx} className={`${seg.classes} bg-accent/30 text-inherit rounded-sm`}>
+                        {seg.text}
+                    </mark>
+                ) : (
+                    <span key={idx} className={seg.classes}>
+                        {seg.text}
+                    </span>
+                )
+            )}
         </div>
     );
 });
 
 export { AnsiLine };
-export type { AnsiLineProps };
+export type { AnsiLineProps, HighlightRange };
@@ -160,12 +160,48 @@ export const SearchTipsPopup: React.FC<SearchTipsPopupProps> = ({ referenceEleme
                                         </code>
                                         <span className="text-[10px]">Fields</span>
                                     </div>
+                                    <div className="flex justify-between items-end">
+                                        <code className="font-mono px-1 rounded text-blue-800 dark:text-blue-200">
+                                            $src:audit
+                                        </code>
+                                        <span className="text-[10px]">Log Stream</span>
+                                    </div>
+                                    <div className="flex justify-between items-end">
+                                        <code className="font-mono px-1 rounded text-blue-800 dark:text-blue-200">
+                                            $goid:/^42$/
+                                        </code>
+                                        <span className="text-[10px]">Goroutine</span>
+                                    </div>
+                                    <div className="flex justify-between items-end">
+                                        <code className="font-mono px-1 rounded text-blue-800 dark:text-blue-200">
+                                            $len:&gt;1kb
+                                        </code>
+                                        <span className="text-[10px]">Numeric</span>
+                                    </div>
+                                    <div className="flex justify-between items-end">
+                                        <code className="font-mono px-1 rounded text-blue-800 dark:text-blue-200">
+                                            $level:warn+
+                                        </code>
+                                        <span className="text-[10px]">Log Level</span>
+                                    </div>
+                                    <div className="flex justify-between items-end">
+                                        <code className="font-mono px-1 rounded text-blue-800 dark:text-blue-200">
+                                            -$user?
+                                        </code>
+                                        <span className="text-[10px]">Field Missing</span>
+                                    </div>
                                     <div className="flex justify-between items-end">
                                         <code className="font-mono px-1 rounded text-blue-800 dark:text-blue-200">
                                             #backend
                                         </code>
                                         <span className="text-[10px]">Tags</span>
                                     </div>
+                                    <div className="flex justify-between items-end">
+                                        <code className="font-mono px-1 rounded text-blue-800 dark:text-blue-200">
+                                            @last:15m
+                                        </code>
+                                        <span className="text-[10px]">Time Window</span>
+                                    </div>
                                 </div>
                             </div>
                         </div>
@@ -0,0 +1,40 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+import { AppModel } from "@/appmodel";
+import { Tooltip } from "@/elements/tooltip";
+import { cn } from "@/util/util";
+import { useAtomValue } from "jotai";
+import { Activity } from "lucide-react";
+import React from "react";
+import { GoRoutinesModel } from "./goroutines-model";
+
+interface ExecTraceButtonProps {
+    model: GoRoutinesModel;
+}
+
+export const ExecTraceButton: React.FC<ExecTraceButtonProps> = ({ model }) => {
+    const isCapturing = useAtomValue(model.isCapturingExecTrace);
+    const isAppRunning = useAtomValue(AppModel.selectedAppRunIsRunningAtom);
+
+    if (!isAppRunning && !isCapturing) {
+        return null;
+    }
+
+    return (
+        <Tooltip content={isCapturing ? "Capturing Execution Trace..." : "Capture Execution Trace (5s)"}>
+            <button
+                onClick={() => model.captureExecTrace()}
+                disabled={isCapturing}
+                className={cn(
+                    "p-1 rounded transition-colors",
+                    isCapturing
+                        ? "bg-primary/20 text-primary animate-pulse cursor-default"
+                        : "text-muted hover:bg-buttonhover hover:text-primary cursor-pointer"
+                )}
+            >
+                <Activity size={14} />
+            </button>
+        </Tooltip>
+    );
+};
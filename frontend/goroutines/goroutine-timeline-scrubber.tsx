@@ -2,6 +2,7 @@
 // SPDX-License-Identifier: Apache-2.0
 
 import { AppModel } from "@/appmodel";
+import { formatMemorySize } from "@/util/util";
 import { useAtomValue } from "jotai";
 import React, { useEffect, useRef } from "react";
 import { GoRoutinesModel } from "./goroutines-model";
@@ -83,6 +84,65 @@ function createGoRoutineTimelinePath(
     return path;
 }
 
+interface ExecTraceLinksProps {
+    execTraces: ExecTraceInfo[];
+}
+
+// Download links for the execution traces captured for this app run (open with "go tool trace <file>")
+const ExecTraceLinks: React.FC<ExecTraceLinksProps> = ({ execTraces }) => {
+    if (execTraces.length === 0) {
+        return null;
+    }
+    return (
+        <div className="flex flex-wrap items-center gap-x-3 mt-3 pl-6 text-[10px] text-muted">
+            <span className="font-medium">Execution Traces:</span>
+            {execTraces.map((execTrace) => {
+                const size = formatMemorySize(execTrace.size);
+                return (
+                    <a
+                        key={execTrace.traceid}
+                        href={execTrace.downloadurl}
+                        download
+                        className="text-accent hover:underline"
+                        title='Download the trace, then run "go tool trace <file>" to view it'
+                    >
+                        {formatTime(execTrace.ts)} ({(execTrace.durationms / 1000).toFixed(1)}s, {size.memstr}
+                        {size.memunit}
+                        {execTrace.truncated ? ", truncated" : ""})
+                    </a>
+                );
+            })}
+        </div>
+    );
+};
+
+interface GoroutineDumpLinksProps {
+    goroutineDumps: GoroutineDumpInfo[];
+}
+
+// Download links for the goroutine dumps taken on demand for this app run (pinned, they are not pruned)
+const GoroutineDumpLinks: React.FC<GoroutineDumpLinksProps> = ({ goroutineDumps }) => {
+    if (goroutineDumps.length === 0) {
+        return null;
+    }
+    return (
+        <div className="flex flex-wrap items-center gap-x-3 mt-3 pl-6 text-[10px] text-muted">
+            <span className="font-medium">Goroutine Dumps:</span>
+            {goroutineDumps.map((dump) => (
+                <a
+                    key={dump.dumpid}
+                    href={dump.downloadurl}
+                    download
+                    className="text-accent hover:underline"
+                    title={`Download the ${dump.label} (triggered by ${dump.triggeredby})`}
+                >
+                    {formatTime(dump.ts)} ({dump.label}, {dump.numgoroutines} goroutines)
+                </a>
+            ))}
+        </div>
+    );
+};
+
 interface GoRoutineTimelineScrubberProps {
     model: GoRoutinesModel;
 }
@@ -97,6 +157,8 @@ export const GoRoutineTimelineScrubber: React.FC<GoRoutineTimelineScrubberProps>
     const appRunInfoAtom = AppModel.getAppRunInfoAtom(model.appRunId);
     const appRunInfo = useAtomValue(appRunInfoAtom);
     const isAppRunning = useAtomValue(AppModel.selectedAppRunIsRunningAtom);
+    const execTraces = useAtomValue(model.execTraces);
+    const goroutineDumps = useAtomValue(model.goroutineDumps);
 
     // Set the scrubber ref in the model on mount
     useEffect(() => {
@@ -255,6 +317,40 @@ export const GoRoutineTimelineScrubber: React.FC<GoRoutineTimelineScrubberProps>
                             />
                         </svg>
 
+                        {/* Execution trace markers (the span of each captured trace) */}
+                        {execTraces.map((execTrace) => {
+                            const startIdx = timestampToTimeIdx(execTrace.ts);
+                            const endIdx = timestampToTimeIdx(execTrace.ts + execTrace.durationms);
+                            if (endIdx < minTimeIdx) {
+                                return null;
+                            }
+                            const leftPercent = ((Math.max(startIdx, minTimeIdx) - minTimeIdx) / plotTimeIdxRange) * 100;
+                            const widthPercent = ((endIdx - Math.max(startIdx, minTimeIdx)) / plotTimeIdxRange) * 100;
+                            return (
+                                <div
+                                    key={execTrace.traceid}
+                                    className="absolute top-0 h-full bg-warning/20 border-x border-warning/60 pointer-events-none"
+                                    style={{ left: `${leftPercent}%`, width: `max(${widthPercent}%, 2px)` }}
+                                />
+                            );
+                        })}
+
+                        {/* Goroutine dump pins (dumps taken on demand) */}
+                        {goroutineDumps.map((dump) => {
+                            const timeIdx = timestampToTimeIdx(dump.ts);
+                            if (timeIdx < minTimeIdx) {
+                                return null;
+                            }
+                            const leftPercent = ((timeIdx - minTimeIdx) / plotTimeIdxRange) * 100;
+                            return (
+                                <div
+                                    key={dump.dumpid}
+                                    className="absolute top-0 h-full w-[2px] bg-accent pointer-events-none"
+                                    style={{ left: `${leftPercent}%` }}
+                                />
+                            );
+                        })}
+
                         {/* Slider marker */}
                         <div
                             className="absolute w-[2px] bg-black pointer-events-none z-[1.5] rounded-lg"
@@ -306,6 +402,9 @@ export const GoRoutineTimelineScrubber: React.FC<GoRoutineTimelineScrubberProps>
                     </div>
                 </div>
             </div>
+
+            <ExecTraceLinks execTraces={execTraces} />
+            <GoroutineDumpLinks goroutineDumps={goroutineDumps} />
         </div>
     );
 };
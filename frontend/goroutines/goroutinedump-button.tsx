@@ -0,0 +1,40 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+import { AppModel } from "@/appmodel";
+import { Tooltip } from "@/elements/tooltip";
+import { cn } from "@/util/util";
+import { useAtomValue } from "jotai";
+import { Camera } from "lucide-react";
+import React from "react";
+import { GoRoutinesModel } from "./goroutines-model";
+
+interface GoroutineDumpButtonProps {
+    model: GoRoutinesModel;
+}
+
+export const GoroutineDumpButton: React.FC<GoroutineDumpButtonProps> = ({ model }) => {
+    const isTaking = useAtomValue(model.isTakingGoroutineDump);
+    const isAppRunning = useAtomValue(AppModel.selectedAppRunIsRunningAtom);
+
+    if (!isAppRunning && !isTaking) {
+        return null;
+    }
+
+    return (
+        <Tooltip content={isTaking ? "Taking Goroutine Dump..." : "Take Goroutine Dump Now (pinned on the timeline)"}>
+            <button
+                onClick={() => model.forceGoroutineDump()}
+                disabled={isTaking}
+                className={cn(
+                    "p-1 rounded transition-colors",
+                    isTaking
+                        ? "bg-primary/20 text-primary animate-pulse cursor-default"
+                        : "text-muted hover:bg-buttonhover hover:text-primary cursor-pointer"
+                )}
+            >
+                <Camera size={14} />
+            </button>
+        </Tooltip>
+    );
+};
@@ -31,3 +31,59 @@ export const DroppedGoroutinesIndicator = React.memo<DroppedGoroutinesIndicatorP
     );
 });
 DroppedGoroutinesIndicator.displayName = "DroppedGoroutinesIndicator";
+
+// Minimum start rate (goroutines/sec) of a call site for the churn indicator to be shown
+const ChurnIndicatorMinRate = 1;
+const ChurnTooltipMaxSites = 5;
+
+function formatCallSite(callSite: string): string {
+    return callSite.split("/").pop() || callSite;
+}
+
+function formatRate(rate: number): string {
+    return rate >= 10 ? Math.round(rate).toString() : rate.toFixed(1);
+}
+
+// Goroutine Churn Indicator component, shows the call site that starts the most short-lived goroutines
+// (goroutines that start and finish between polls never show up in the table)
+interface GoroutineChurnIndicatorProps {
+    model: GoRoutinesModel;
+}
+
+export const GoroutineChurnIndicator = React.memo<GoroutineChurnIndicatorProps>(({ model }) => {
+    const churnSites = useAtomValue(model.churnSites);
+    const activeSites = churnSites.filter((site) => site.startrate >= ChurnIndicatorMinRate);
+
+    if (activeSites.length === 0) {
+        return null;
+    }
+
+    const topSite = activeSites[0];
+    const tooltipContent = (
+        <div className="flex flex-col gap-1 text-xs">
+            <div className="text-secondary">Goroutines started per second (last 10s), by call site</div>
+            {activeSites.slice(0, ChurnTooltipMaxSites).map((site) => (
+                <div key={site.callsite} className="flex gap-2">
+                    <span className="text-primary font-mono">{formatRate(site.startrate)}/s</span>
+                    <span className="font-mono">{formatCallSite(site.callsite)}</span>
+                    {site.name && <span className="text-secondary">{site.name}</span>}
+                    <span className="text-muted">
+                        {site.started} started, {site.shortlived} never polled
+                        {site.avgruntimems != null && `, avg ${formatRate(site.avgruntimems)}ms`}
+                    </span>
+                </div>
+            ))}
+        </div>
+    );
+
+    return (
+        <div className="absolute bottom-0 left-0 bg-secondary/20 text-secondary rounded-tr-md px-2 py-1 text-xs z-10">
+            <Tooltip content={tooltipContent}>
+                <span className="font-normal cursor-default">
+                    {formatRate(topSite.startrate)} goroutines/sec created at {formatCallSite(topSite.callsite)}
+                </span>
+            </Tooltip>
+        </div>
+    );
+});
+GoroutineChurnIndicator.displayName = "GoroutineChurnIndicator";
@@ -8,7 +8,9 @@ import { checkKeyPressed } from "@/util/keyutil";
 import { useAtom, useAtomValue } from "jotai";
 import React from "react";
 import { Tag } from "../elements/tag";
+import { ExecTraceButton } from "./exectrace-button";
 import { GoRoutineTimelineScrubber } from "./goroutine-timeline-scrubber";
+import { GoroutineDumpButton } from "./goroutinedump-button";
 import { GoRoutinesModel } from "./goroutines-model";
 import { SearchLatestButton } from "./search-latest-button";
 import { StacktraceModeToggle } from "./stacktrace-mode-toggle";
@@ -23,6 +25,7 @@ export const GoRoutinesFilters: React.FC<GoRoutinesFiltersProps> = ({ model }) =
     const [showOutrig, setShowOutrig] = useAtom(model.showOutrigGoroutines);
     const [selectedStates, setSelectedStates] = useAtom(model.selectedStates);
     const [showActiveOnly, setShowActiveOnly] = useAtom(model.showActiveOnly);
+    const groupByCreationSite = useAtomValue(model.groupByCreationSite);
     const searchResultInfo = useAtomValue(model.searchResultInfo);
     const resultCount = useAtomValue(model.resultCount);
     const lastSearchTimestamp = useAtomValue(model.lastSearchTimestamp);
@@ -48,6 +51,10 @@ export const GoRoutinesFilters: React.FC<GoRoutinesFiltersProps> = ({ model }) =
         model.toggleShowOutrigGoroutines();
     };
 
+    const handleToggleGroupByCreationSite = () => {
+        model.toggleGroupByCreationSite();
+    };
+
     const handleToggleState = (state: string) => {
         model.toggleStateFilter(state);
     };
@@ -107,8 +114,10 @@ export const GoRoutinesFilters: React.FC<GoRoutinesFiltersProps> = ({ model }) =
                         </div>
                         <div className="flex flex-col">
                             <div className="text-xs text-muted font-medium h-[16px]"></div>
-                            <div className="flex items-center h-8 mt-4">
+                            <div className="flex items-center gap-1 h-8 mt-4">
                                 <SearchLatestButton model={model} />
+                                <ExecTraceButton model={model} />
+                                <GoroutineDumpButton model={model} />
                             </div>
                         </div>
                     </div>
@@ -147,7 +156,23 @@ export const GoRoutinesFilters: React.FC<GoRoutinesFiltersProps> = ({ model }) =
                         ))}
                     </div>
 
-                    <div className="flex items-start shrink-0">
+                    <div className="flex items-start gap-1.5 shrink-0">
+                        <Tooltip
+                            content={
+                                groupByCreationSite
+                                    ? "Grouping GoRoutines by Creation Site (Click to Toggle)"
+                                    : "Showing Individual GoRoutines (Click to Toggle)"
+                            }
+                        >
+                            <div>
+                                <Tag
+                                    label="Grouped"
+                                    isSelected={groupByCreationSite}
+                                    onToggle={handleToggleGroupByCreationSite}
+                                    variant={groupByCreationSite ? "accent" : "secondary"}
+                                />
+                            </div>
+                        </Tooltip>
                         <Tooltip
                             content={
                                 showOutrig
@@ -4,6 +4,7 @@
 import { AppModel } from "@/appmodel";
 import { DefaultRpcClient } from "@/init";
 import { SearchStore } from "@/store/searchstore";
+import { sendSearchEvent } from "@/tevent";
 import { padToMultiple } from "@/util/util";
 import { Atom, atom, getDefaultStore, PrimitiveAtom } from "jotai";
 import { RpcApi } from "../rpc/rpcclientapi";
@@ -11,6 +12,7 @@ import { RpcApi } from "../rpc/rpcclientapi";
 // Maximum timeline range in seconds (10 minutes)
 const MaxTimelineRangeSeconds = 600;
 const TimelinePaddingSeconds = 15;
+const ExecTraceDurationSec = 5;
 
 // Type for timeline range using timeidx values
 export type TimelineRange = {
@@ -57,6 +59,11 @@ class GoRoutinesModel {
     fullTimeSpan: PrimitiveAtom<TimeSpan> = atom<TimeSpan>(null) as PrimitiveAtom<TimeSpan>;
     droppedCount: PrimitiveAtom<number> = atom(0);
     activeCounts: PrimitiveAtom<GoRoutineActiveCount[]> = atom<GoRoutineActiveCount[]>([]);
+    churnSites: PrimitiveAtom<GoRoutineChurnSite[]> = atom<GoRoutineChurnSite[]>([]);
+    execTraces: PrimitiveAtom<ExecTraceInfo[]> = atom<ExecTraceInfo[]>([]);
+    isCapturingExecTrace: PrimitiveAtom<boolean> = atom(false);
+    goroutineDumps: PrimitiveAtom<GoroutineDumpInfo[]> = atom<GoroutineDumpInfo[]>([]);
+    isTakingGoroutineDump: PrimitiveAtom<boolean> = atom(false);
 
     // Timeline range using timeidx values (derived from fullTimeSpan)
     timelineRangeAtom: Atom<TimelineRange> = atom((get) => {
@@ -92,6 +99,12 @@ class GoRoutinesModel {
     // Toggle for showing all goroutines vs only active ones
     showActiveOnly: PrimitiveAtom<boolean> = atom(false);
 
+    // Toggle for grouping goroutines by creation site (one row per group)
+    groupByCreationSite: PrimitiveAtom<boolean> = atom(false);
+
+    // Groups from the last aggregate search, keyed by the representative goid
+    goRoutineGroups: PrimitiveAtom<Map<number, GoRoutineGroup>> = atom(new Map<number, GoRoutineGroup>());
+
     // Stacktrace display settings - can be "raw", "simplified", or "simplified:files"
     simpleStacktraceMode: PrimitiveAtom<string> = atom("simplified");
 
@@ -134,10 +147,11 @@ class GoRoutinesModel {
         return matchedIds.length;
     });
 
-    // Sorted goroutines with pinned ones first
+    // Sorted goroutines with pinned ones first (largest groups first when grouping by creation site)
     sortedGoRoutines: Atom<ParsedGoRoutine[]> = atom((get): ParsedGoRoutine[] => {
         const goroutines = get(this.appRunGoRoutines);
         const pinnedGoRoutineIds = get(this.pinnedGoRoutineIds);
+        const groups = get(this.goRoutineGroups);
 
         // Separate pinned and unpinned goroutines
         const pinnedGoroutines = goroutines.filter((gr) => pinnedGoRoutineIds.has(gr.goid));
@@ -145,6 +159,11 @@ class GoRoutinesModel {
 
         // Sort each group by start time, then by goid if start times are equal
         const sortByStartTime = (a: ParsedGoRoutine, b: ParsedGoRoutine) => {
+            const aCount = groups.get(a.goid)?.count || 0;
+            const bCount = groups.get(b.goid)?.count || 0;
+            if (aCount !== bCount) {
+                return bCount - aCount;
+            }
             const aStart = a.activetimespan?.start || 0;
             const bStart = b.activetimespan?.start || 0;
             if (aStart !== bStart) {
@@ -174,6 +193,8 @@ class GoRoutinesModel {
 
         this.startTimeSpansPolling();
         this.loadAppRunGoroutines();
+        this.loadExecTraces();
+        this.loadGoroutineDumps();
     }
 
     // Clean up resources when component unmounts
@@ -265,6 +286,16 @@ class GoRoutinesModel {
         this.searchGoroutines(store.get(this.searchTerm));
     }
 
+    // Toggle grouping goroutines by creation site
+    toggleGroupByCreationSite(): void {
+        const store = getDefaultStore();
+        const groupByCreationSite = store.get(this.groupByCreationSite);
+        store.set(this.groupByCreationSite, !groupByCreationSite);
+
+        // Trigger a new search with the current search term
+        this.searchGoroutines(store.get(this.searchTerm));
+    }
+
     // Toggle showing all vs active only goroutines
     toggleShowActiveOnly(): void {
         const store = getDefaultStore();
@@ -288,11 +319,13 @@ class GoRoutinesModel {
     // Search for goroutines matching the search term
     async searchGoroutines(searchTerm: string) {
         const store = getDefaultStore();
+        sendSearchEvent("goroutine", this.appRunId, searchTerm);
         const searchId = crypto.randomUUID();
         this.currentSearchId = searchId;
         const showOutrig = store.get(this.showOutrigGoroutines);
         const selectedStates = store.get(this.selectedStates);
         const showActiveOnly = store.get(this.showActiveOnly);
+        const groupByCreationSite = store.get(this.groupByCreationSite);
 
         try {
             // Build the systemQuery based on selected states and showOutrig setting
@@ -336,6 +369,7 @@ class GoRoutinesModel {
                 timestamp: effectiveTimestamp,
                 showoutrig: showOutrig,
                 activeonly: effectiveActiveOnly,
+                aggregate: groupByCreationSite,
             };
             const searchResult = await RpcApi.GoRoutineSearchRequestCommand(DefaultRpcClient, fullQuery);
 
@@ -355,6 +389,12 @@ class GoRoutinesModel {
             // Set the timestamp to the actual timestamp that was searched for
             store.set(this.lastSearchTimestamp, searchResult.effectivesearchtimestamp);
 
+            const groups = new Map<number, GoRoutineGroup>();
+            for (const group of searchResult.groups || []) {
+                groups.set(group.representativeid, group);
+            }
+            store.set(this.goRoutineGroups, groups);
+
             // Convert int64 IDs to numbers and store them
             const goIds = searchResult.results;
             store.set(this.matchedGoRoutineIds, goIds);
@@ -371,6 +411,7 @@ class GoRoutinesModel {
             // Reset state on error
             store.set(this.matchedGoRoutineIds, []);
             store.set(this.appRunGoRoutines, []);
+            store.set(this.goRoutineGroups, new Map<number, GoRoutineGroup>());
             store.set(this.searchResultInfo, { searchedCount: 0, totalCount: 0, errorSpans: [] });
         } finally {
             // No cleanup needed
@@ -498,6 +539,15 @@ class GoRoutinesModel {
         await this.searchGoroutines(term);
     }
 
+    // Switch to the logs tab, searching for the lines logged by this goroutine (only lines logged through the SDK have a goid)
+    showGoRoutineLogs(goid: number) {
+        const appRunInfo = getDefaultStore().get(AppModel.getAppRunInfoAtom(this.appRunId));
+        const appName = appRunInfo?.appname || "unknown";
+        const logSearchTerm = SearchStore.getSearchTermAtom(appName, this.appRunId, "logs");
+        getDefaultStore().set(logSearchTerm, `$goid:/^${goid}$/`);
+        AppModel.selectLogsTab();
+    }
+
     // Set the selected timestamp and disable search latest mode
     setSelectedTimestamp(timestamp: number) {
         const store = getDefaultStore();
@@ -616,12 +666,91 @@ class GoRoutinesModel {
                 setTimeout(() => {
                     this.searchGoroutines(searchTerm);
                 }, 0);
+                this.loadChurn();
             }
         } catch (error) {
             console.error(`Failed to poll time spans for app run ${this.appRunId}:`, error);
         }
     }
 
+    // Load the goroutine churn (goroutines started and finished between polls) by call site
+    async loadChurn() {
+        try {
+            const response = await RpcApi.GetGoRoutineChurnCommand(DefaultRpcClient, {
+                apprunid: this.appRunId,
+            });
+            getDefaultStore().set(this.churnSites, response.sites ?? []);
+        } catch (error) {
+            console.error(`Failed to load goroutine churn for app run ${this.appRunId}:`, error);
+        }
+    }
+
+    // Load the execution traces captured for this app run (shown on the timeline)
+    async loadExecTraces() {
+        try {
+            const response = await RpcApi.GetAppRunExecTracesCommand(DefaultRpcClient, {
+                apprunid: this.appRunId,
+            });
+            getDefaultStore().set(this.execTraces, response.traces ?? []);
+        } catch (error) {
+            console.error(`Failed to load execution traces for app run ${this.appRunId}:`, error);
+        }
+    }
+
+    // Capture an execution trace (runtime/trace) of the running app, the trace is downloaded from the timeline
+    async captureExecTrace() {
+        const store = getDefaultStore();
+        if (store.get(this.isCapturingExecTrace)) {
+            return;
+        }
+        store.set(this.isCapturingExecTrace, true);
+        try {
+            const traceInfo = await RpcApi.CaptureExecTraceCommand(
+                DefaultRpcClient,
+                { apprunid: this.appRunId, durationsec: ExecTraceDurationSec },
+                { timeout: (ExecTraceDurationSec + 30) * 1000 }
+            );
+            store.set(this.execTraces, [...store.get(this.execTraces), traceInfo]);
+        } catch (error) {
+            AppModel.showToast("Execution Trace Failed", `Could not capture an execution trace: ${error.message ?? error}`, 5000);
+        } finally {
+            store.set(this.isCapturingExecTrace, false);
+        }
+    }
+
+    // Load the goroutine dumps taken on demand for this app run (pinned on the timeline)
+    async loadGoroutineDumps() {
+        try {
+            const response = await RpcApi.GetAppRunGoroutineDumpsCommand(DefaultRpcClient, {
+                apprunid: this.appRunId,
+            });
+            getDefaultStore().set(this.goroutineDumps, response.dumps ?? []);
+        } catch (error) {
+            console.error(`Failed to load goroutine dumps for app run ${this.appRunId}:`, error);
+        }
+    }
+
+    // Take a full goroutine dump of the running app right away (outside the 1s poll), it is pinned on the timeline
+    async forceGoroutineDump() {
+        const store = getDefaultStore();
+        if (store.get(this.isTakingGoroutineDump)) {
+            return;
+        }
+        store.set(this.isTakingGoroutineDump, true);
+        try {
+            const dumpInfo = await RpcApi.ForceGoroutineDumpCommand(DefaultRpcClient, { apprunid: this.appRunId });
+            store.set(this.goroutineDumps, [...store.get(this.goroutineDumps), dumpInfo]);
+        } catch (error) {
+            AppModel.showToast(
+                "Goroutine Dump Failed",
+                `Could not take a goroutine dump: ${error.message ?? error}`,
+                5000
+            );
+        } finally {
+            store.set(this.isTakingGoroutineDump, false);
+        }
+    }
+
     // Helper function to convert timeidx to timestamp using activeCounts
     timeIdxToTimestamp(timeIdx: number): number {
         const store = getDefaultStore();
@@ -2,6 +2,8 @@
 // SPDX-License-Identifier: Apache-2.0
 
 import { AppModel } from "@/appmodel";
+import { GoRoutineViewers } from "@/presence/presence-indicator";
+import { PresenceModel } from "@/presence/presence-model";
 import { cn } from "@/util/util";
 import {
     CellContext,
@@ -12,7 +14,7 @@ import {
     useReactTable,
 } from "@tanstack/react-table";
 import { getDefaultStore, useAtomValue } from "jotai";
-import { ChevronDown, ChevronUp, List } from "lucide-react";
+import { ChevronDown, ChevronUp, List, ScrollText } from "lucide-react";
 import React from "react";
 import { Tag } from "../elements/tag";
 import { Tooltip } from "../elements/tooltip";
@@ -118,6 +120,7 @@ interface TableMeta {
     expandedRows: Set<number>;
     model: GoRoutinesModel;
     timelineRange: TimelineRange;
+    groups: Map<number, GoRoutineGroup>;
 }
 
 // Sort functions for table columns
@@ -177,6 +180,14 @@ function cell_goid(info: CellContext<ParsedGoRoutine, number>) {
     return <span className="font-mono text-sm text-secondary">{info.getValue()}</span>;
 }
 
+// formats a group's state counts for display (largest first), e.g. "5 chan receive, 3 running"
+function formatStateCounts(stateCounts: { [key: string]: number }): string {
+    return Object.entries(stateCounts ?? {})
+        .sort((a, b) => b[1] - a[1])
+        .map(([state, count]) => `${count} ${state}`)
+        .join(", ");
+}
+
 function cell_name(info: CellContext<ParsedGoRoutine, ParsedGoRoutine>) {
     const goroutine = info.row.original;
     const tags = goroutine.tags;
@@ -184,6 +195,7 @@ function cell_name(info: CellContext<ParsedGoRoutine, ParsedGoRoutine>) {
     const tableModel = meta.tableModel;
     const expandedRows = meta.expandedRows;
     const isExpanded = expandedRows.has(goroutine.goid);
+    const group = meta.groups.get(goroutine.goid);
 
     return (
         <div className="flex items-center gap-2">
@@ -193,18 +205,51 @@ function cell_name(info: CellContext<ParsedGoRoutine, ParsedGoRoutine>) {
                         "flex-shrink-0 w-4 h-4 flex items-center justify-center transition-colors cursor-pointer",
                         isExpanded ? "text-primary" : "text-secondary hover:text-primary"
                     )}
-                    onClick={() => tableModel.toggleRowExpanded(goroutine.goid)}
+                    onClick={() => {
+                        tableModel.toggleRowExpanded(goroutine.goid);
+                        PresenceModel.setGoRoutineFocus(goroutine.goid, getGoroutineNameText(goroutine), !isExpanded);
+                    }}
                 >
                     <List className="w-3 h-3" />
                 </button>
             </Tooltip>
+            <Tooltip content="Show Logs for this Goroutine">
+                <button
+                    className="flex-shrink-0 w-4 h-4 flex items-center justify-center transition-colors cursor-pointer text-secondary hover:text-primary"
+                    onClick={() => meta.model.showGoRoutineLogs(goroutine.goid)}
+                >
+                    <ScrollText className="w-3 h-3" />
+                </button>
+            </Tooltip>
             <div className="flex-1 flex items-center gap-2 min-w-0">
                 <div className="text-primary truncate">{formatGoroutineName(goroutine)}</div>
+                <GoRoutineViewers goid={goroutine.goid} />
+                {group && group.pool && (
+                    <Tooltip
+                        content={`${group.count} workers of pool ${group.pool} (${formatStateCounts(group.statecounts)}, showing goroutine ${goroutine.goid})`}
+                    >
+                        <div className="text-xs font-mono text-accent flex-shrink-0 cursor-default">
+                            pool &times;{group.count}
+                        </div>
+                    </Tooltip>
+                )}
+                {group && !group.pool && group.count > 1 && (
+                    <Tooltip
+                        content={`${group.count} goroutines started from this site (showing goroutine ${goroutine.goid})`}
+                    >
+                        <div className="text-xs font-mono text-accent flex-shrink-0 cursor-default">
+                            &times;{group.count}
+                        </div>
+                    </Tooltip>
+                )}
                 {tags && tags.length > 0 && (
                     <div className="text-xs text-muted hover:text-primary transition-colors cursor-default flex-shrink-0">
                         {tags.map((tag: string) => `#${tag}`).join(" ")}
                     </div>
                 )}
+                {goroutine.status && (
+                    <div className="text-xs text-accent truncate cursor-default">{goroutine.status}</div>
+                )}
             </div>
         </div>
     );
@@ -440,6 +485,7 @@ export const GoRoutinesTable: React.FC<GoRoutinesTableProps> = ({ tableModel, mo
     const simpleMode = useAtomValue(model.effectiveSimpleStacktraceMode);
     const expandedRows = useAtomValue(tableModel.expandedRows);
     const timelineRange = useAtomValue(model.timelineRangeAtom);
+    const groups = useAtomValue(model.goRoutineGroups);
 
     const timelineSortingFn = React.useMemo(() => (rowA: any, rowB: any) => sortByTimeline(rowA, rowB, model), [model]);
 
@@ -453,12 +499,14 @@ export const GoRoutinesTable: React.FC<GoRoutinesTableProps> = ({ tableModel, mo
         expandedRows,
         model,
         timelineRange,
+        groups,
     });
 
     metaRef.current.tableModel = tableModel;
     metaRef.current.expandedRows = expandedRows;
     metaRef.current.model = model;
     metaRef.current.timelineRange = timelineRange;
+    metaRef.current.groups = groups;
 
     const getColumnGrow = React.useMemo(
         () =>
@@ -5,7 +5,7 @@ import { EmptyMessageDelayMs } from "@/util/constants";
 import { useOutrigModel } from "@/util/hooks";
 import { useAtomValue } from "jotai";
 import React, { useEffect, useRef, useState } from "react";
-import { DroppedGoroutinesIndicator } from "./goroutines-comps";
+import { DroppedGoroutinesIndicator, GoroutineChurnIndicator } from "./goroutines-comps";
 import { GoRoutinesFilters } from "./goroutines-filters";
 import { GoRoutinesModel } from "./goroutines-model";
 import { GoRoutinesTable } from "./goroutines-table";
@@ -96,6 +96,7 @@ export const GoRoutines: React.FC<GoRoutinesProps> = ({ appRunId }) => {
             <GoRoutinesFilters model={model} />
             <GoRoutinesContent model={model} tableModel={tableModel} />
             <DroppedGoroutinesIndicator model={model} />
+            <GoroutineChurnIndicator model={model} />
         </div>
     );
 };
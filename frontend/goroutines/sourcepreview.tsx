@@ -0,0 +1,65 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+import { DefaultRpcClient } from "@/init";
+import { RpcApi } from "@/rpc/rpcclientapi";
+import { cn } from "@/util/util";
+import React, { useEffect, useState } from "react";
+
+interface SourcePreviewProps {
+    appRunId: string;
+    frame: StackFrame;
+}
+
+// Inline source of a stack frame's line (read by the server from the project directory or its git checkout)
+export const SourcePreview: React.FC<SourcePreviewProps> = ({ appRunId, frame }) => {
+    const [data, setData] = useState<SourcePreviewData>(null);
+    const [error, setError] = useState<string>(null);
+
+    useEffect(() => {
+        let canceled = false;
+        RpcApi.GetSourcePreviewCommand(DefaultRpcClient, { apprunid: appRunId, frame: frame })
+            .then((result) => {
+                if (!canceled) setData(result);
+            })
+            .catch((e) => {
+                if (!canceled) setError(`${e.message ?? e}`);
+            });
+        return () => {
+            canceled = true;
+        };
+    }, [appRunId, frame]);
+
+    if (error) {
+        return <div className="ml-4 my-1 text-error">{error}</div>;
+    }
+    if (!data) {
+        return <div className="ml-4 my-1 text-muted">Loading source...</div>;
+    }
+    const numWidth = String(data.startline + data.lines.length - 1).length;
+    return (
+        <div className="ml-4 my-1 border border-border rounded bg-panel overflow-x-auto">
+            {(data.modified || data.source === "git") && (
+                <div className={cn("px-2 py-0.5 border-b border-border", data.modified ? "text-warning" : "text-muted")}>
+                    {data.modified
+                        ? "The file changed after the app started, it may not match the running code"
+                        : `From revision ${data.revision?.substring(0, 12)}`}
+                </div>
+            )}
+            <pre className="py-1">
+                {data.lines.map((line, index) => {
+                    const lineNum = data.startline + index;
+                    const isFrameLine = lineNum === data.linenumber;
+                    return (
+                        <div key={lineNum} className={cn("px-2", isFrameLine && "bg-accent/15 text-primary")}>
+                            <span className="inline-block text-right text-muted select-none mr-3">
+                                {String(lineNum).padStart(numWidth, " ")}
+                            </span>
+                            {line}
+                        </div>
+                    );
+                })}
+            </pre>
+        </div>
+    );
+};
@@ -4,12 +4,14 @@ import { CodeLink } from "@/codelink/codelink";
 import { cn, escapeRegExp } from "@/util/util";
 import React, { useState } from "react";
 import { GoRoutinesModel } from "./goroutines-model";
+import { SourcePreview } from "./sourcepreview";
 
 // Component for displaying a single frame in the simplified stack trace
 interface SimplifiedStackFrameProps {
     frame: StackFrame;
     createdByGoid?: number; // Optional goroutine ID for "created by" frames
     showFileLink?: boolean; // Whether to show the file link separately (true for simplified:files, false for simplified)
+    appRunId?: string; // Enables the source preview of important frames (with showFileLink)
 }
 
 // Helper function to get just the base filename from a path
@@ -22,7 +24,9 @@ export const SimplifiedStackFrame: React.FC<SimplifiedStackFrameProps> = ({
     frame,
     createdByGoid,
     showFileLink = true, // Default to showing file link (for backward compatibility)
+    appRunId,
 }) => {
+    const [showPreview, setShowPreview] = useState(false);
     // Format for the tooltip - (basefilename.go:linenum)
     const fileLocationTip = `(${getBaseFileName(frame.filepath)}:${frame.linenumber})`;
 
@@ -83,8 +87,30 @@ export const SimplifiedStackFrame: React.FC<SimplifiedStackFrameProps> = ({
                     <CodeLink file={`${frame.filepath}:${frame.linenumber}`}>
                         {frame.filepath}:{frame.linenumber}
                     </CodeLink>
+                    {frame.vcsurl && (
+                        <a
+                            href={frame.vcsurl}
+                            target="_blank"
+                            rel="noopener noreferrer"
+                            title={frame.relpath}
+                            className="ml-2 text-secondary hover:text-blue-600 dark:hover:text-blue-300"
+                        >
+                            [source]
+                        </a>
+                    )}
+                    {appRunId && (
+                        <button
+                            onClick={() => setShowPreview(!showPreview)}
+                            className="ml-2 text-secondary hover:text-blue-600 dark:hover:text-blue-300 cursor-pointer"
+                        >
+                            {showPreview ? "[hide preview]" : "[preview]"}
+                        </button>
+                    )}
                 </div>
             )}
+            {frame.isimportant && showFileLink && appRunId && showPreview && (
+                <SourcePreview appRunId={appRunId} frame={frame} />
+            )}
         </div>
     );
 };
@@ -285,6 +311,7 @@ const SimplifiedStackTrace: React.FC<SimplifiedStackTraceProps> = ({
                                 key={`section-${currentSectionIndex}-frame-${frameIndex}`}
                                 frame={frame}
                                 showFileLink={showFileLinks}
+                                appRunId={model.appRunId}
                             />
                         );
                     });
@@ -315,7 +342,14 @@ const SimplifiedStackTrace: React.FC<SimplifiedStackTraceProps> = ({
                 addNonImportantSection();
 
                 // Then add this important frame
-                result.push(<SimplifiedStackFrame key={`frame-${index}`} frame={frame} showFileLink={showFileLinks} />);
+                result.push(
+                    <SimplifiedStackFrame
+                        key={`frame-${index}`}
+                        frame={frame}
+                        showFileLink={showFileLinks}
+                        appRunId={model.appRunId}
+                    />
+                );
             } else {
                 // Accumulate non-important frames
                 currentNonImportantFrames.push(frame);
@@ -337,6 +371,7 @@ const SimplifiedStackTrace: React.FC<SimplifiedStackTraceProps> = ({
                     frame={goroutine.createdbyframe}
                     createdByGoid={goroutine.createdbygoid}
                     showFileLink={showFileLinks}
+                    appRunId={model.appRunId}
                 />
             )}
         </div>
@@ -5,6 +5,7 @@ import { registerGlobalKeys } from "@/keymodel";
 import { RpcClientImpl } from "@/rpcclientimpl";
 import { getDefaultStore } from "jotai";
 import { RpcClient } from "./rpc/rpc";
+import { eventReconnectHandler } from "./rpc/rps";
 import { RpcRouter } from "./rpc/rpcrouter";
 import { addWSReconnectHandler, WebSocketController } from "./websocket/client";
 
@@ -62,6 +63,7 @@ function initRpcSystem() {
     DefaultRouter.registerRoute(DefaultRpcClient.routeId, DefaultRpcClient);
     addWSReconnectHandler(() => {
         DefaultRouter.reannounceRoutes();
+        eventReconnectHandler();
     });
 }
 
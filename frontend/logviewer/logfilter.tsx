@@ -7,7 +7,7 @@ import { Tooltip } from "@/elements/tooltip";
 import { SearchFilter } from "@/searchfilter/searchfilter";
 import { checkKeyPressed } from "@/util/keyutil";
 import { useAtom, useAtomValue } from "jotai";
-import { ArrowDown, ArrowDownCircle, Wifi, WifiOff } from "lucide-react";
+import { ArrowDown, ArrowDownCircle, Layers, UnfoldVertical, Wifi, WifiOff } from "lucide-react";
 import React, { useCallback } from "react";
 import { LogViewerModel } from "./logviewer-model";
 
@@ -78,6 +78,93 @@ const StreamingButton = React.memo<StreamingButtonProps>(({ model }) => {
 });
 StreamingButton.displayName = "StreamingButton";
 
+// Context Button component (cycles through the number of lines shown around each match)
+const ContextLineOptions = [0, 2, 5, 10];
+
+interface ContextButtonProps {
+    model: LogViewerModel;
+}
+
+const ContextButton = React.memo<ContextButtonProps>(({ model }) => {
+    const [contextLines, setContextLines] = useAtom(model.contextLines);
+
+    const cycleContext = useCallback(() => {
+        const idx = ContextLineOptions.indexOf(contextLines);
+        setContextLines(ContextLineOptions[(idx + 1) % ContextLineOptions.length]);
+    }, [contextLines, setContextLines]);
+
+    return (
+        <Tooltip
+            content={
+                contextLines > 0
+                    ? `Showing ${contextLines} Lines Around Matches (Click to Change)`
+                    : "No Context Lines (Click to Show Lines Around Matches)"
+            }
+        >
+            <button
+                onClick={cycleContext}
+                className={`p-1 mr-1 rounded flex items-center gap-0.5 ${
+                    contextLines > 0
+                        ? "bg-primary/20 text-primary hover:bg-primary/30"
+                        : "text-muted hover:bg-buttonhover hover:text-primary"
+                } cursor-pointer transition-colors`}
+                aria-pressed={contextLines > 0}
+            >
+                <UnfoldVertical size={16} />
+                {contextLines > 0 && <span className="text-[10px]">{contextLines}</span>}
+            </button>
+        </Tooltip>
+    );
+});
+ContextButton.displayName = "ContextButton";
+
+// Dedup Button component (cycles through off, consecutive lines, and the dedup windows)
+const DedupOptions: { windowMs: number | null; label: string; desc: string }[] = [
+    { windowMs: null, label: "", desc: "" },
+    { windowMs: 0, label: "", desc: "Identical Consecutive Lines" },
+    { windowMs: 10000, label: "10s", desc: "Identical Lines Within 10s" },
+    { windowMs: 60000, label: "1m", desc: "Identical Lines Within 1m" },
+];
+
+interface DedupButtonProps {
+    model: LogViewerModel;
+}
+
+const DedupButton = React.memo<DedupButtonProps>(({ model }) => {
+    const [dedupWindowMs, setDedupWindowMs] = useAtom(model.dedupWindowMs);
+    const idx = Math.max(DedupOptions.findIndex((opt) => opt.windowMs === dedupWindowMs), 0);
+    const option = DedupOptions[idx];
+    const isOn = option.windowMs != null;
+
+    const cycleDedup = useCallback(() => {
+        setDedupWindowMs(DedupOptions[(idx + 1) % DedupOptions.length].windowMs);
+    }, [idx, setDedupWindowMs]);
+
+    return (
+        <Tooltip
+            content={
+                isOn
+                    ? `Collapsing ${option.desc} (Click to Change)`
+                    : "Not Collapsing Repeated Lines (Click to Collapse Identical Lines)"
+            }
+        >
+            <button
+                onClick={cycleDedup}
+                className={`p-1 mr-1 rounded flex items-center gap-0.5 ${
+                    isOn
+                        ? "bg-primary/20 text-primary hover:bg-primary/30"
+                        : "text-muted hover:bg-buttonhover hover:text-primary"
+                } cursor-pointer transition-colors`}
+                aria-pressed={isOn}
+            >
+                <Layers size={16} />
+                {option.label && <span className="text-[10px]">{option.label}</span>}
+            </button>
+        </Tooltip>
+    );
+});
+DedupButton.displayName = "DedupButton";
+
 // Filter component
 interface LogViewerFilterProps {
     model: LogViewerModel;
@@ -131,6 +218,14 @@ export const LogViewerFilter = React.memo<LogViewerFilterProps>(({ model, classN
                     content={
                         <>
                             {filteredCount} matched / {searchedCount} searched / {totalCount} ingested
+                            {searchState.partial && (
+                                <>
+                                    <br />
+                                    <span className="text-warning">
+                                        The search timed out, only the first {searchedCount} lines were searched
+                                    </span>
+                                </>
+                            )}
                             {totalCount >= 10000 && (
                                 <>
                                     <br />
@@ -140,13 +235,18 @@ export const LogViewerFilter = React.memo<LogViewerFilterProps>(({ model, classN
                         </>
                     }
                 >
-                    <div className="text-xs text-muted mr-2 select-none">
+                    <div
+                        className={`text-xs mr-2 select-none ${searchState.partial ? "text-warning" : "text-muted"}`}
+                    >
                         {filteredCount}/{searchedCount}
                         {totalCount > searchedCount ? "+" : ""}
+                        {searchState.partial ? " (partial)" : ""}
                     </div>
                 </Tooltip>
 
                 <SearchTipsButton className="mr-1" />
+                <ContextButton model={model} />
+                <DedupButton model={model} />
                 <FollowButton model={model} />
                 <StreamingButton model={model} />
                 <RefreshButton
@@ -1,7 +1,7 @@
 // Copyright 2025, Command Line Inc.
 // SPDX-License-Identifier: Apache-2.0
 
-import { AnsiLine } from "@/elements/ansiline";
+import { AnsiLine, HighlightRange } from "@/elements/ansiline";
 import { LogSettings } from "@/settings/settings-model";
 import { cn } from "@/util/util";
 import EmojiJS from "emoji-js";
@@ -169,6 +169,26 @@ function processMessageText(message: string, source: string): string {
     return message;
 }
 
+// runeSpansToRanges converts the server's match spans (character offsets) to string offsets in text
+function runeSpansToRanges(text: string, spans: SearchMatchSpan[]): HighlightRange[] {
+    if (spans == null || spans.length === 0) {
+        return null;
+    }
+    // offsets[i] is the string offset of character i (surrogate pairs are one character)
+    const offsets: number[] = [];
+    let pos = 0;
+    for (const ch of text) {
+        offsets.push(pos);
+        pos += ch.length;
+    }
+    offsets.push(pos);
+    const maxIdx = offsets.length - 1;
+    return spans.map((span) => ({
+        start: offsets[Math.min(span.start, maxIdx)],
+        end: offsets[Math.min(span.end, maxIdx)],
+    }));
+}
+
 // LogLineComponent for rendering individual log lines in LogVList
 interface LogLineComponentProps {
     line: LogLine;
@@ -205,6 +225,15 @@ export const LogLineComponent = React.memo<LogLineComponentProps>(
             return processMessageText(line.msg, line.source);
         }, [line.msg, line.source]);
 
+        // search match highlights (the offsets don't apply if emoji replacement changed the message)
+        const matchSpans = model.getMatchSpans(line.linenum);
+        const highlights = useMemo(() => {
+            if (processedMessage !== line.msg) {
+                return null;
+            }
+            return runeSpansToRanges(line.msg, matchSpans);
+        }, [processedMessage, line.msg, matchSpans]);
+
         return (
             <div
                 data-linenum={line.linenum}
@@ -213,6 +242,7 @@ export const LogLineComponent = React.memo<LogLineComponentProps>(
                 onContextMenu={handleContextMenu}
                 className={cn(
                     "flex text-muted select-none pl-1 pr-2",
+                    line.iscontext && "opacity-60",
                     getLogLineColorClass(line.color) ||
                         (isMarked
                             ? "bg-gray-400/20 hover:bg-gray-400/30"
@@ -237,9 +267,18 @@ export const LogLineComponent = React.memo<LogLineComponentProps>(
                     </div>
                 )}
                 {logSettings.showSource && <div className="pl-2">{formatSource(line.source)}</div>}
+                {line.repeatcount > 1 && (
+                    <div
+                        className="flex-shrink-0 ml-2 px-1 rounded bg-accent/20 text-accent text-xs self-start"
+                        title={`Repeated ${line.repeatcount} times, last at ${formatTimestamp(line.lastrepeatts, true, "absolute")}`}
+                    >
+                        ×{line.repeatcount}
+                    </div>
+                )}
                 <AnsiLine
                     className="flex-1 min-w-0 pl-2 select-text cursor-default text-primary break-all overflow-hidden whitespace-pre data-copy"
                     line={processedMessage}
+                    highlights={highlights}
                 />
             </div>
         );
@@ -4,7 +4,7 @@
 import { CopyButton } from "@/elements/copybutton";
 import { Tooltip } from "@/elements/tooltip";
 import { getDefaultStore, useAtom, useAtomValue } from "jotai";
-import { ArrowDown, ArrowDownCircle, Wifi, WifiOff, X } from "lucide-react";
+import { ArrowDown, ArrowDownCircle, Download, Wifi, WifiOff, X } from "lucide-react";
 import React from "react";
 import { LogViewerModel } from "./logviewer-model";
 
@@ -141,6 +141,10 @@ export const MarkedLinesIndicator = React.memo<MarkedLinesIndicatorProps>(({ mod
         await model.copyMarkedLinesToClipboard();
     };
 
+    const handleExportMarkedLines = () => {
+        model.exportMarkedLines();
+    };
+
     return (
         <div className="absolute bottom-0 right-0 bg-accentbg text-primary dark:text-black rounded-tl-md px-2 py-1 text-xs z-10">
             <div className="flex items-center">
@@ -155,6 +159,15 @@ export const MarkedLinesIndicator = React.memo<MarkedLinesIndicatorProps>(({ mod
                     variant="primary"
                     onCopy={handleCopyMarkedLines}
                 />
+                <Tooltip content="Export marked lines (JSON)">
+                    <button
+                        onClick={handleExportMarkedLines}
+                        className="ml-2 hover:text-black/70 cursor-pointer"
+                        aria-label="Export marked lines"
+                    >
+                        <Download size={14} />
+                    </button>
+                </Tooltip>
                 <button
                     onClick={handleClearMarks}
                     className="ml-2 hover:text-black/70 cursor-pointer"
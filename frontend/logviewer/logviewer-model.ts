@@ -5,6 +5,7 @@ import { AppModel } from "@/appmodel";
 import { emitter } from "@/events";
 import { DefaultRpcClient } from "@/init";
 import { SearchStore } from "@/store/searchstore";
+import { sendSearchEvent } from "@/tevent";
 import { PromiseQueue } from "@/util/promisequeue";
 import { atom, getDefaultStore, PrimitiveAtom } from "jotai";
 import { selectAtom } from "jotai/utils";
@@ -12,6 +13,9 @@ import { unstable_batchedUpdates } from "react-dom";
 import { RpcApi } from "../rpc/rpcclientapi";
 
 const PAGESIZE = 100;
+// searches over large buffers can take longer than the default rpc timeout, a search that hits this
+// timeout returns the lines found so far (marked as partial)
+const SEARCH_TIMEOUT_MS = 60000;
 
 // Interfaces moved from logvlist
 export interface LogPageInterface {
@@ -37,6 +41,8 @@ interface LogCounts {
 // Store error spans from search results
 interface SearchState {
     errorSpans: SearchErrorSpan[];
+    // the search stopped before all lines were searched (timed out), the results are partial
+    partial: boolean;
 }
 
 class LogViewerModel {
@@ -48,6 +54,11 @@ class LogViewerModel {
     isLoading: PrimitiveAtom<boolean> = atom(false);
     followOutput: PrimitiveAtom<boolean> = atom(true);
     isStreaming: PrimitiveAtom<boolean> = atom(true);
+    // number of lines shown before and after each match (like grep -C), context lines have iscontext set
+    contextLines: PrimitiveAtom<number> = atom(0);
+    // collapse identical lines into one entry with a repeat count: null is off, 0 collapses consecutive lines,
+    // otherwise identical lines less than dedupWindowMs apart
+    dedupWindowMs: PrimitiveAtom<number | null> = atom<number | null>(null) as PrimitiveAtom<number | null>;
     vlistRef: React.RefObject<HTMLDivElement> = { current: null };
 
     // Batching for stream updates
@@ -58,6 +69,9 @@ class LogViewerModel {
     listAtom: PrimitiveAtom<LogListInterface>;
     listVersion: number = 0;
 
+    // what matched the search term in each loaded line (by line number), used for highlighting
+    matchSpans: Map<number, SearchMatchSpan[]> = new Map();
+
     // Single atom to hold all count values
     logCountsAtom: PrimitiveAtom<LogCounts> = atom<LogCounts>({
         total: 0,
@@ -68,6 +82,7 @@ class LogViewerModel {
     // Atom to hold search error spans
     searchStateAtom: PrimitiveAtom<SearchState> = atom<SearchState>({
         errorSpans: [],
+        partial: false,
     });
 
     // Derived atoms for individual counts (read-only)
@@ -84,11 +99,15 @@ class LogViewerModel {
     markedLines: Set<number> = new Set<number>();
     // Version atom to trigger reactivity when the set changes
     markedLinesVersion: PrimitiveAtom<number> = atom(0);
+    // Marks are stored per app run, existing marks are loaded after the first search
+    marksLoaded: boolean = false;
 
     // Container width atom for tracking the parent container width
     containerWidth: PrimitiveAtom<number> = atom(0);
 
     requestQueue: PromiseQueue = new PromiseQueue();
+    // reqid of the in-flight search (canceled when the search term changes again)
+    searchReqId: string = null;
     keepAliveTimeoutId: NodeJS.Timeout = null;
 
     // Method to get a log line by page number and line index
@@ -152,6 +171,16 @@ class LogViewerModel {
             // Re-issue the search when streaming flag changes
             this.onStreamingFlagChange();
         });
+
+        // Re-issue the search when the number of context lines changes
+        getDefaultStore().sub(this.contextLines, () => {
+            this.onStreamingFlagChange();
+        });
+
+        // Re-issue the search when the dedup mode changes
+        getDefaultStore().sub(this.dedupWindowMs, () => {
+            this.onStreamingFlagChange();
+        });
     }
 
     dispose() {
@@ -171,12 +200,21 @@ class LogViewerModel {
 
     async onSearchTermUpdate(searchTerm: string) {
         const startTime = performance.now();
+        sendSearchEvent("logs", this.appRunId, searchTerm);
         this.requestQueue.clearQueue();
+        if (this.searchReqId != null) {
+            // stop the previous search on the server, its (partial) results are ignored below
+            DefaultRpcClient.cancelRpc(this.searchReqId);
+        }
+        const reqId = crypto.randomUUID();
+        this.searchReqId = reqId;
         const quickSearchTimeoutId = setTimeout(() => {
             getDefaultStore().set(this.isLoading, true);
         }, 200);
         const followOutput = getDefaultStore().get(this.followOutput);
         const streaming = getDefaultStore().get(this.isStreaming);
+        const contextLines = getDefaultStore().get(this.contextLines);
+        const dedupWindowMs = getDefaultStore().get(this.dedupWindowMs);
 
         // Request initial pages
         let requestPages: number[];
@@ -196,15 +234,23 @@ class LogViewerModel {
                 systemQuery = "#m | #userquery";
             }
 
-            return RpcApi.LogSearchRequestCommand(DefaultRpcClient, {
-                widgetid: this.widgetId,
-                apprunid: this.appRunId,
-                searchterm: searchTerm,
-                systemquery: systemQuery,
-                pagesize: PAGESIZE,
-                requestpages: requestPages,
-                streaming: streaming,
-            });
+            return RpcApi.LogSearchRequestCommand(
+                DefaultRpcClient,
+                {
+                    widgetid: this.widgetId,
+                    apprunid: this.appRunId,
+                    searchterm: searchTerm,
+                    systemquery: systemQuery,
+                    pagesize: PAGESIZE,
+                    requestpages: requestPages,
+                    streaming: streaming,
+                    contextbefore: contextLines,
+                    contextafter: contextLines,
+                    dedup: dedupWindowMs != null,
+                    dedupwindowms: dedupWindowMs ?? 0,
+                },
+                { reqid: reqId, timeout: SEARCH_TIMEOUT_MS }
+            );
         };
 
         try {
@@ -215,9 +261,18 @@ class LogViewerModel {
             );
 
             const results = await this.requestQueue.enqueue(cmdPromiseFn);
+            if (this.searchReqId !== reqId) {
+                // a newer search has started
+                return;
+            }
+            if (results.canceled) {
+                console.log("log search timed out, showing partial results", results.searchedcount);
+            }
 
             // Increment version to trigger a full reset
             this.listVersion++;
+            this.matchSpans = new Map();
+            this.addMatchSpans(results.matchspans);
 
             // Calculate total number of pages needed
             const totalPages = Math.ceil(results.filteredcount / PAGESIZE);
@@ -260,6 +315,7 @@ class LogViewerModel {
 
                 getDefaultStore().set(this.searchStateAtom, {
                     errorSpans: results.errorspans || [],
+                    partial: !!results.canceled,
                 });
 
                 getDefaultStore().set(this.listAtom, {
@@ -269,8 +325,16 @@ class LogViewerModel {
                     trimmedLines: 0, // Initialize with 0, will be updated from stream updates
                 });
             });
+
+            if (!this.marksLoaded) {
+                this.marksLoaded = true;
+                this.loadMarkedLines();
+            }
         } catch (e) {
             console.error("Log search error", e);
+            if (this.searchReqId !== reqId) {
+                return;
+            }
 
             // Reset to empty state on error
             unstable_batchedUpdates(() => {
@@ -282,6 +346,7 @@ class LogViewerModel {
 
                 getDefaultStore().set(this.searchStateAtom, {
                     errorSpans: [],
+                    partial: false,
                 });
 
                 getDefaultStore().set(this.listAtom, {
@@ -293,7 +358,10 @@ class LogViewerModel {
             });
         } finally {
             clearTimeout(quickSearchTimeoutId);
-            getDefaultStore().set(this.isLoading, false);
+            if (this.searchReqId === reqId) {
+                this.searchReqId = null;
+                getDefaultStore().set(this.isLoading, false);
+            }
             const endTime = performance.now();
             console.log("Log search took", endTime - startTime, "ms");
         }
@@ -338,6 +406,8 @@ class LogViewerModel {
         // Get the search term and streaming flag
         const searchTerm = getDefaultStore().get(this.searchTerm);
         const streaming = getDefaultStore().get(this.isStreaming);
+        const contextLines = getDefaultStore().get(this.contextLines);
+        const dedupWindowMs = getDefaultStore().get(this.dedupWindowMs);
 
         const cmdPromiseFn = () => {
             // Always build system query when there are marked lines
@@ -346,15 +416,23 @@ class LogViewerModel {
                 systemQuery = "#m | #userquery";
             }
 
-            return RpcApi.LogSearchRequestCommand(DefaultRpcClient, {
-                widgetid: this.widgetId,
-                apprunid: this.appRunId,
-                searchterm: searchTerm,
-                systemquery: systemQuery,
-                pagesize: PAGESIZE,
-                requestpages: [pageNum],
-                streaming: streaming,
-            });
+            return RpcApi.LogSearchRequestCommand(
+                DefaultRpcClient,
+                {
+                    widgetid: this.widgetId,
+                    apprunid: this.appRunId,
+                    searchterm: searchTerm,
+                    systemquery: systemQuery,
+                    pagesize: PAGESIZE,
+                    requestpages: [pageNum],
+                    streaming: streaming,
+                    contextbefore: contextLines,
+                    contextafter: contextLines,
+                    dedup: dedupWindowMs != null,
+                    dedupwindowms: dedupWindowMs ?? 0,
+                },
+                { timeout: SEARCH_TIMEOUT_MS }
+            );
         };
 
         const startTime = Date.now();
@@ -365,6 +443,7 @@ class LogViewerModel {
             const loadedPage = results.pages.find((p) => p.pagenum === pageNum);
 
             if (loadedPage) {
+                this.addMatchSpans(results.matchspans);
                 // Update just this page atom
                 getDefaultStore().set(pageAtom, {
                     lines: loadedPage.lines || [],
@@ -381,6 +460,7 @@ class LogViewerModel {
 
                 getDefaultStore().set(this.searchStateAtom, {
                     errorSpans: results.errorspans || [],
+                    partial: !!results.canceled,
                 });
             }
         } catch (e) {
@@ -390,6 +470,24 @@ class LogViewerModel {
         }
     }
 
+    addMatchSpans(matchSpans: { [key: number]: SearchMatchSpan[] }) {
+        if (matchSpans == null) {
+            return;
+        }
+        // only the log message is highlighted
+        for (const [lineNum, spans] of Object.entries(matchSpans)) {
+            const msgSpans = spans.filter((span) => span.field === "msg");
+            if (msgSpans.length > 0) {
+                this.matchSpans.set(Number(lineNum), msgSpans);
+            }
+        }
+    }
+
+    // getMatchSpans returns the spans in the log message that matched the search term
+    getMatchSpans(lineNum: number): SearchMatchSpan[] {
+        return this.matchSpans.get(lineNum);
+    }
+
     async refresh() {
         const store = getDefaultStore();
 
@@ -549,6 +647,49 @@ class LogViewerModel {
         return this.markedLines.size;
     }
 
+    // Load the marks made on this app run (e.g. in another log widget) into the local set
+    async loadMarkedLines() {
+        try {
+            const result = await RpcApi.LogGetMarkedLinesCommand(DefaultRpcClient, {
+                widgetid: this.widgetId,
+            });
+            const lineNums = Object.keys(result.marks || {}).map((lineNum) => parseInt(lineNum, 10));
+            const newLineNums = lineNums.filter((lineNum) => !this.markedLines.has(lineNum));
+            if (newLineNums.length === 0) {
+                return;
+            }
+            const hadMarks = this.markedLines.size > 0;
+            newLineNums.forEach((lineNum) => this.markedLines.add(lineNum));
+            getDefaultStore().set(this.markedLinesVersion, (v) => v + 1);
+            if (!hadMarks) {
+                // re-run the search so the marked lines are included ("#m | #userquery")
+                this.refresh();
+            }
+        } catch (error) {
+            console.error("Failed to load marked lines from backend:", error);
+        }
+    }
+
+    // Download the marked lines (with their annotations) as a JSON bundle that can be shared
+    async exportMarkedLines() {
+        if (this.markedLines.size === 0) return;
+
+        try {
+            const bundle = await RpcApi.LogExportMarkedLinesCommand(DefaultRpcClient, {
+                widgetid: this.widgetId,
+            });
+            const blob = new Blob([JSON.stringify(bundle, null, 2)], { type: "application/json" });
+            const url = URL.createObjectURL(blob);
+            const link = document.createElement("a");
+            link.href = url;
+            link.download = `outrig-marked-${bundle.appname || "app"}-${bundle.apprunid.slice(0, 8)}.json`;
+            link.click();
+            URL.revokeObjectURL(url);
+        } catch (error) {
+            console.error("Failed to export marked lines:", error);
+        }
+    }
+
     // Get all marked lines from the backend and copy their messages to clipboard
     async copyMarkedLinesToClipboard() {
         if (this.markedLines.size === 0) return;
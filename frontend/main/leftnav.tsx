@@ -5,7 +5,7 @@ import { AppModel } from "@/appmodel";
 import { AppRunListModel } from "@/apprunlist/apprunlist-model";
 import { cn, formatDuration, formatRelativeTime } from "@/util/util";
 import { useAtom, useAtomValue } from "jotai";
-import { Box, Clock, Github, Home, Moon, Plus, Settings, Sun, X } from "lucide-react";
+import { Box, Camera, Clock, Github, Home, Moon, Plus, Settings, Sun, X } from "lucide-react";
 import React, { useEffect, useMemo, useState } from "react";
 
 // AppRunItem component for displaying a single app run item
@@ -327,6 +327,15 @@ export const LeftNav: React.FC = () => {
                             <Plus size={16} />
                             <span>Integration Instructions</span>
                         </button>
+                        <button
+                            className="w-full flex items-center space-x-2 p-2 text-secondary hover:text-primary hover:bg-buttonhover rounded cursor-pointer"
+                            onClick={() => {
+                                AppModel.openSnapshotsModal();
+                            }}
+                        >
+                            <Camera size={16} />
+                            <span>Snapshots</span>
+                        </button>
                         <ThemeToggle />
                         <button
                             className="w-full flex items-center space-x-2 p-2 text-secondary hover:text-primary hover:bg-buttonhover rounded cursor-pointer"
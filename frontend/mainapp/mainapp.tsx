@@ -10,6 +10,7 @@ import { UpdateBadge } from "@/elements/updatebadge";
 import { GoRoutines } from "@/goroutines/goroutines";
 import { LogViewer } from "@/logviewer/logviewer";
 import { LeftNav } from "@/main/leftnav";
+import { PresenceIndicator } from "@/presence/presence-indicator";
 import { RuntimeStats } from "@/runtimestats/runtimestats";
 import { Watches } from "@/watches/watches";
 import { useAtom, useAtomValue } from "jotai";
@@ -234,6 +235,7 @@ const AppHeader = React.memo(function AppHeader() {
                 </div>
             </div>
             <div className="flex items-center pr-1">
+                <PresenceIndicator />
                 <AutoFollowButton />
                 <div className="mx-1.5 xl:mx-3 h-5 w-[2px] bg-gray-300 dark:bg-gray-600"></div>
                 <SettingsButton onClick={() => AppModel.openSettingsModal()} />
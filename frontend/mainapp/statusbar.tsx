@@ -5,11 +5,30 @@ import { AppModel } from "@/appmodel";
 import { AppRunListModel } from "@/apprunlist/apprunlist-model";
 import { Tooltip } from "@/elements/tooltip";
 import { useAtomValue, useSetAtom } from "jotai";
-import { Box, CircleDot, Eye, List, PauseCircle, Wifi, WifiOff } from "lucide-react";
+import {
+    AlertTriangle,
+    Box,
+    CircleDot,
+    Eye,
+    List,
+    PauseCircle,
+    RefreshCw,
+    ShieldCheck,
+    Wifi,
+    WifiOff,
+} from "lucide-react";
 import { useMemo } from "react";
 
 const OutrigVersion = "v" + import.meta.env.PACKAGE_VERSION;
 
+// droppedTooltip describes the packets the SDK dropped because the monitor couldn't keep up
+function droppedTooltip(stats: TransportStats): string {
+    const byType = Object.entries(stats.dropped ?? {})
+        .map(([pkType, count]) => `${pkType}: ${count}`)
+        .join(", ");
+    return `The app dropped packets because the monitor couldn't keep up (${byType})`;
+}
+
 function ConnectionStatus({ status }: { status: string }) {
     let icon;
     let displayName;
@@ -136,6 +155,36 @@ export function StatusBar() {
                             </div>
                         </Tooltip>
                     )}
+                    {selectedAppRun.transportstats?.redacted > 0 && (
+                        <Tooltip
+                            content={`The app redacted ${selectedAppRun.transportstats.redacted} sensitive values from its logs and watches`}
+                            placement="bottom"
+                        >
+                            <div className="flex items-center space-x-1">
+                                <ShieldCheck size={12} />
+                                <span>{selectedAppRun.transportstats.redacted} redacted</span>
+                            </div>
+                        </Tooltip>
+                    )}
+                    {selectedAppRun.transportstats?.totaldropped > 0 && (
+                        <Tooltip content={droppedTooltip(selectedAppRun.transportstats)} placement="bottom">
+                            <div className="flex items-center space-x-1 text-warning">
+                                <AlertTriangle size={12} />
+                                <span>{selectedAppRun.transportstats.totaldropped} dropped</span>
+                            </div>
+                        </Tooltip>
+                    )}
+                    {selectedAppRun.numresyncs > 0 && (
+                        <Tooltip
+                            content={`The monitor requested ${selectedAppRun.numresyncs} full goroutine/watch ${selectedAppRun.numresyncs === 1 ? "update" : "updates"} from the app after updates it couldn't apply`}
+                            placement="bottom"
+                        >
+                            <div className="flex items-center space-x-1">
+                                <RefreshCw size={12} />
+                                <span>{selectedAppRun.numresyncs} resynced</span>
+                            </div>
+                        </Tooltip>
+                    )}
                 </div>
             )}
         </div>
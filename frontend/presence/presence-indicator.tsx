@@ -0,0 +1,109 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+import { AppModel } from "@/appmodel";
+import { Tooltip } from "@/elements/tooltip";
+import { cn } from "@/util/util";
+import { useAtomValue } from "jotai";
+import React, { useEffect } from "react";
+import { getPresenceFocusText, getPresenceUserName, PresenceModel } from "./presence-model";
+
+const MaxShownUsers = 5;
+
+const AvatarColors = ["bg-sky-600", "bg-emerald-600", "bg-amber-600", "bg-rose-600", "bg-violet-600", "bg-teal-600"];
+
+// picks a stable color for a user (the same name gets the same color in every tab)
+function getAvatarColor(name: string): string {
+    let hash = 0;
+    for (let i = 0; i < name.length; i++) {
+        hash = (hash * 31 + name.charCodeAt(i)) | 0;
+    }
+    return AvatarColors[Math.abs(hash) % AvatarColors.length];
+}
+
+interface PresenceAvatarProps {
+    user: PresenceUser;
+    size?: "sm" | "md";
+}
+
+// PresenceAvatar shows the initial of another user viewing the app run, clicking it follows their focus
+const PresenceAvatar = React.memo(function PresenceAvatar({ user, size = "md" }: PresenceAvatarProps) {
+    const name = getPresenceUserName(user);
+    const focusText = getPresenceFocusText(user.focus);
+    const content = (
+        <span>
+            <span className="font-medium">{name}</span>
+            {focusText ? ` is ${focusText}` : " is viewing this app run"}
+            {!user.focused && <span className="text-muted"> (idle)</span>}
+        </span>
+    );
+    return (
+        <Tooltip content={content}>
+            <div
+                className={cn(
+                    "rounded-full flex items-center justify-center text-white font-medium ring-2 ring-panel",
+                    size === "sm" ? "w-4 h-4 text-[9px]" : "w-6 h-6 text-xs",
+                    getAvatarColor(name),
+                    !user.focused && "opacity-50",
+                    user.focus && "cursor-pointer"
+                )}
+                onClick={() => PresenceModel.followUser(user)}
+            >
+                {name.charAt(0).toUpperCase()}
+            </div>
+        </Tooltip>
+    );
+});
+
+PresenceAvatar.displayName = "PresenceAvatar";
+
+// PresenceIndicator shows the other browser tabs viewing the selected app run (in the app header)
+const PresenceIndicator = React.memo(function PresenceIndicator() {
+    const selectedAppRunId = useAtomValue(AppModel.selectedAppRunId);
+    const otherUsers = useAtomValue(PresenceModel.otherUsers);
+
+    useEffect(() => {
+        PresenceModel.setAppRunId(selectedAppRunId);
+    }, [selectedAppRunId]);
+
+    if (otherUsers.length === 0) {
+        return null;
+    }
+    const shownUsers = otherUsers.slice(0, MaxShownUsers);
+    return (
+        <div className="flex items-center -space-x-1.5 mr-2">
+            {shownUsers.map((user) => (
+                <PresenceAvatar key={user.tabid} user={user} />
+            ))}
+            {otherUsers.length > shownUsers.length && (
+                <div className="pl-2.5 text-xs text-secondary">+{otherUsers.length - shownUsers.length}</div>
+            )}
+        </div>
+    );
+});
+
+PresenceIndicator.displayName = "PresenceIndicator";
+
+interface GoRoutineViewersProps {
+    goid: number;
+}
+
+// GoRoutineViewers shows the other users that have a goroutine expanded (in the goroutines table)
+const GoRoutineViewers = React.memo(function GoRoutineViewers({ goid }: GoRoutineViewersProps) {
+    const otherUsers = useAtomValue(PresenceModel.otherUsers);
+    const viewers = otherUsers.filter((user) => user.focus?.kind === "goroutine" && user.focus.id === String(goid));
+    if (viewers.length === 0) {
+        return null;
+    }
+    return (
+        <div className="flex items-center -space-x-1 flex-shrink-0">
+            {viewers.map((user) => (
+                <PresenceAvatar key={user.tabid} user={user} size="sm" />
+            ))}
+        </div>
+    );
+});
+
+GoRoutineViewers.displayName = "GoRoutineViewers";
+
+export { GoRoutineViewers, PresenceIndicator };
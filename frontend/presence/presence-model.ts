@@ -0,0 +1,142 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+import { AppModel } from "@/appmodel";
+import { DefaultRpcClient } from "@/init";
+import { eventSubscribe } from "@/rpc/rps";
+import { RpcApi } from "@/rpc/rpcclientapi";
+import { SearchStore } from "@/store/searchstore";
+import { addWSReconnectHandler } from "@/websocket/client";
+import { atom, Atom, getDefaultStore, PrimitiveAtom } from "jotai";
+
+const PresenceFocusGoRoutine = "goroutine";
+
+// PresenceModel tracks the browser tabs (of this and other users) viewing the selected app run
+// and what they are looking at, so people debugging together can follow each other
+class PresenceModel {
+    appRunId: string = "";
+    presence: PrimitiveAtom<AppRunPresenceData> = atom<AppRunPresenceData>(null);
+    myFocus: PresenceFocus = null;
+    unsubscribeFn: () => void = null;
+
+    // the other tabs viewing the app run
+    otherUsers: Atom<PresenceUser[]> = atom((get) => {
+        const presence = get(this.presence);
+        if (presence == null) {
+            return [];
+        }
+        return presence.users.filter((user) => user.tabid !== DefaultRpcClient?.routeId);
+    });
+
+    constructor() {
+        addWSReconnectHandler(() => {
+            // the monitor dropped this tab when the connection went down
+            AppModel.sendBrowserTabUrl().then(() => {
+                this.sendFocus();
+                this.loadPresence();
+            });
+        });
+    }
+
+    // starts tracking the presence of an app run (called when the selected app run changes)
+    setAppRunId(appRunId: string) {
+        if (appRunId === this.appRunId) {
+            return;
+        }
+        this.unsubscribeFn?.();
+        this.unsubscribeFn = null;
+        this.appRunId = appRunId;
+        this.myFocus = null;
+        getDefaultStore().set(this.presence, null);
+        if (!appRunId) {
+            return;
+        }
+        this.unsubscribeFn = eventSubscribe({
+            eventType: "app:presence",
+            scope: appRunId,
+            handler: (event: EventType) => {
+                const data = event.data as AppRunPresenceData;
+                if (data?.apprunid === this.appRunId) {
+                    getDefaultStore().set(this.presence, data);
+                }
+            },
+        });
+        this.loadPresence();
+    }
+
+    async loadPresence() {
+        const appRunId = this.appRunId;
+        if (!appRunId || !DefaultRpcClient) {
+            return;
+        }
+        try {
+            const data = await RpcApi.GetAppRunPresenceCommand(DefaultRpcClient, { apprunid: appRunId });
+            if (appRunId === this.appRunId) {
+                getDefaultStore().set(this.presence, data);
+            }
+        } catch (error) {
+            console.error("Failed to load app run presence:", error);
+        }
+    }
+
+    // tells the other tabs which goroutine this tab is looking at (expanded is false when the goroutine is closed)
+    setGoRoutineFocus(goid: number, name: string, expanded: boolean) {
+        const id = String(goid);
+        if (expanded) {
+            this.setFocus({ kind: PresenceFocusGoRoutine, id, label: name });
+        } else if (this.myFocus?.kind === PresenceFocusGoRoutine && this.myFocus.id === id) {
+            this.setFocus(null);
+        }
+    }
+
+    setFocus(focus: PresenceFocus) {
+        this.myFocus = focus;
+        this.sendFocus();
+    }
+
+    sendFocus() {
+        if (!this.appRunId || !DefaultRpcClient) {
+            return;
+        }
+        RpcApi.UpdateBrowserTabFocusCommand(DefaultRpcClient, {
+            apprunid: this.appRunId,
+            focus: this.myFocus,
+        }).catch((error: Error) => {
+            console.error("Failed to send presence focus:", error);
+        });
+    }
+
+    // shows what another user is looking at (their goroutine is searched in the goroutines view)
+    followUser(user: PresenceUser) {
+        if (user.focus?.kind !== PresenceFocusGoRoutine) {
+            return;
+        }
+        const appRunInfo = getDefaultStore().get(AppModel.getAppRunInfoAtom(this.appRunId));
+        const appName = appRunInfo?.appname || "unknown";
+        const searchTerm = SearchStore.getSearchTermAtom(appName, this.appRunId, "goroutines");
+        getDefaultStore().set(searchTerm, `$goid:${user.focus.id}`);
+        AppModel.selectGoRoutinesTab();
+    }
+}
+
+// getPresenceUserName returns the name to show for a tab (tabs without a user name get an anonymous name)
+function getPresenceUserName(user: PresenceUser): string {
+    if (user.username) {
+        return user.username;
+    }
+    return `anonymous-${user.tabid.slice(-4)}`;
+}
+
+// getPresenceFocusText describes what a user is looking at, e.g. "viewing goroutine 1234 (worker)"
+function getPresenceFocusText(focus: PresenceFocus): string {
+    if (focus == null) {
+        return "";
+    }
+    if (focus.kind === PresenceFocusGoRoutine) {
+        return focus.label ? `viewing goroutine ${focus.id} (${focus.label})` : `viewing goroutine ${focus.id}`;
+    }
+    return `viewing ${focus.kind} ${focus.id}`;
+}
+
+const model = new PresenceModel();
+export { getPresenceFocusText, getPresenceUserName, model as PresenceModel };
@@ -3,7 +3,7 @@
 
 import { isBlank } from "../util/util";
 import { RpcRouter } from "./rpcrouter";
-import { sendRpcCommand } from "./rpcutil";
+import { sendRpcCancel, sendRpcCommand } from "./rpcutil";
 
 const notFoundLogMap = new Map<string, boolean>();
 
@@ -59,7 +59,7 @@ class RpcClient {
             source: this.routeId,
         };
         if (!opts?.noresponse) {
-            msg.reqid = crypto.randomUUID();
+            msg.reqid = opts?.reqid ?? crypto.randomUUID();
         }
         if (opts?.timeout) {
             msg.timeout = opts.timeout;
@@ -84,7 +84,7 @@ class RpcClient {
         const msg: RpcMessage = {
             command: command,
             data: data,
-            reqid: crypto.randomUUID(),
+            reqid: opts?.reqid ?? crypto.randomUUID(),
             source: this.routeId,
         };
         if (opts?.timeout) {
@@ -97,6 +97,14 @@ class RpcClient {
         return rpcGen;
     }
 
+    // cancelRpc asks the handler of an in-flight request (sent with opts.reqid) to stop early
+    cancelRpc(reqid: string) {
+        if (isBlank(reqid)) {
+            return;
+        }
+        sendRpcCancel(this.router, reqid);
+    }
+
     async handleIncomingCommand(msg: RpcMessage) {
         // TODO implement a timeout (setTimeout + sendResponse)
         const helper = new RpcResponseHelper(this, msg);
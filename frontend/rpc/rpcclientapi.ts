@@ -6,11 +6,51 @@
 import { RpcClient } from "./rpc";
 
 class RpcApiType {
+    // command "broadcast" [call]
+    BroadcastCommand(client: RpcClient, data: BroadcastRequest, opts?: RpcOpts): Promise<BroadcastResponse> {
+        return client.rpcCall("broadcast", data, opts);
+    }
+
+    // command "capturecpuprofile" [call]
+    CaptureCPUProfileCommand(client: RpcClient, data: CaptureCPUProfileRequest, opts?: RpcOpts): Promise<CaptureCPUProfileResponse> {
+        return client.rpcCall("capturecpuprofile", data, opts);
+    }
+
+    // command "captureexectrace" [call]
+    CaptureExecTraceCommand(client: RpcClient, data: CaptureExecTraceRequest, opts?: RpcOpts): Promise<ExecTraceInfo> {
+        return client.rpcCall("captureexectrace", data, opts);
+    }
+
     // command "clearnonactiveappruns" [call]
     ClearNonActiveAppRunsCommand(client: RpcClient, opts?: RpcOpts): Promise<void> {
         return client.rpcCall("clearnonactiveappruns", null, opts);
     }
 
+    // command "collectoradmin" [call]
+    CollectorAdminCommand(client: RpcClient, data: CollectorAdminRequest, opts?: RpcOpts): Promise<void> {
+        return client.rpcCall("collectoradmin", data, opts);
+    }
+
+    // command "compactapprunstore" [call]
+    CompactAppRunStoreCommand(client: RpcClient, data: CompactAppRunStoreRequest, opts?: RpcOpts): Promise<CompactAppRunStoreResult> {
+        return client.rpcCall("compactapprunstore", data, opts);
+    }
+
+    // command "compareappruns" [call]
+    CompareAppRunsCommand(client: RpcClient, data: CompareAppRunsRequest, opts?: RpcOpts): Promise<CompareAppRunsData> {
+        return client.rpcCall("compareappruns", data, opts);
+    }
+
+    // command "deletesnapshot" [call]
+    DeleteSnapshotCommand(client: RpcClient, data: SnapshotRequest, opts?: RpcOpts): Promise<void> {
+        return client.rpcCall("deletesnapshot", data, opts);
+    }
+
+    // command "deletesnapshotrule" [call]
+    DeleteSnapshotRuleCommand(client: RpcClient, data: SnapshotRuleRequest, opts?: RpcOpts): Promise<void> {
+        return client.rpcCall("deletesnapshotrule", data, opts);
+    }
+
     // command "eventpublish" [call]
     EventPublishCommand(client: RpcClient, data: EventType, opts?: RpcOpts): Promise<void> {
         return client.rpcCall("eventpublish", data, opts);
@@ -36,11 +76,41 @@ class RpcApiType {
         return client.rpcCall("eventunsuball", null, opts);
     }
 
+    // command "exportapprun" [call]
+    ExportAppRunCommand(client: RpcClient, data: AppRunRequest, opts?: RpcOpts): Promise<ExportAppRunResponse> {
+        return client.rpcCall("exportapprun", data, opts);
+    }
+
+    // command "forcegoroutinedump" [call]
+    ForceGoroutineDumpCommand(client: RpcClient, data: ForceGoroutineDumpRequest, opts?: RpcOpts): Promise<GoroutineDumpInfo> {
+        return client.rpcCall("forcegoroutinedump", data, opts);
+    }
+
+    // command "getapprunexectraces" [call]
+    GetAppRunExecTracesCommand(client: RpcClient, data: AppRunRequest, opts?: RpcOpts): Promise<AppRunExecTracesData> {
+        return client.rpcCall("getapprunexectraces", data, opts);
+    }
+
+    // command "getapprungoroutinedumps" [call]
+    GetAppRunGoroutineDumpsCommand(client: RpcClient, data: AppRunRequest, opts?: RpcOpts): Promise<AppRunGoroutineDumpsData> {
+        return client.rpcCall("getapprungoroutinedumps", data, opts);
+    }
+
     // command "getapprungoroutinesbyids" [call]
     GetAppRunGoRoutinesByIdsCommand(client: RpcClient, data: AppRunGoRoutinesByIdsRequest, opts?: RpcOpts): Promise<AppRunGoRoutinesData> {
         return client.rpcCall("getapprungoroutinesbyids", data, opts);
     }
 
+    // command "getapprunpanics" [call]
+    GetAppRunPanicsCommand(client: RpcClient, data: AppRunRequest, opts?: RpcOpts): Promise<AppRunPanicsData> {
+        return client.rpcCall("getapprunpanics", data, opts);
+    }
+
+    // command "getapprunpresence" [call]
+    GetAppRunPresenceCommand(client: RpcClient, data: AppRunRequest, opts?: RpcOpts): Promise<AppRunPresenceData> {
+        return client.rpcCall("getapprunpresence", data, opts);
+    }
+
     // command "getapprunruntimestats" [call]
     GetAppRunRuntimeStatsCommand(client: RpcClient, data: AppRunRequest, opts?: RpcOpts): Promise<AppRunRuntimeStatsData> {
         return client.rpcCall("getapprunruntimestats", data, opts);
@@ -51,21 +121,86 @@ class RpcApiType {
         return client.rpcCall("getappruns", data, opts);
     }
 
+    // command "getappruntimeline" [call]
+    GetAppRunTimelineCommand(client: RpcClient, data: AppRunRequest, opts?: RpcOpts): Promise<AppRunTimelineData> {
+        return client.rpcCall("getappruntimeline", data, opts);
+    }
+
     // command "getapprunwatchesbyids" [call]
     GetAppRunWatchesByIdsCommand(client: RpcClient, data: AppRunWatchesByIdsRequest, opts?: RpcOpts): Promise<AppRunWatchesData> {
         return client.rpcCall("getapprunwatchesbyids", data, opts);
     }
 
+    // command "getauditlog" [call]
+    GetAuditLogCommand(client: RpcClient, data: AuditLogRequest, opts?: RpcOpts): Promise<AuditLogData> {
+        return client.rpcCall("getauditlog", data, opts);
+    }
+
     // command "getdemoappstatus" [call]
     GetDemoAppStatusCommand(client: RpcClient, opts?: RpcOpts): Promise<string> {
         return client.rpcCall("getdemoappstatus", null, opts);
     }
 
+    // command "getdiskusage" [call]
+    GetDiskUsageCommand(client: RpcClient, opts?: RpcOpts): Promise<DiskUsageData> {
+        return client.rpcCall("getdiskusage", null, opts);
+    }
+
+    // command "geteffectiveconfig" [call]
+    GetEffectiveConfigCommand(client: RpcClient, data: AppRunRequest, opts?: RpcOpts): Promise<EffectiveConfigData> {
+        return client.rpcCall("geteffectiveconfig", data, opts);
+    }
+
+    // command "getgoroutinechurn" [call]
+    GetGoRoutineChurnCommand(client: RpcClient, data: AppRunRequest, opts?: RpcOpts): Promise<GoRoutineChurnData> {
+        return client.rpcCall("getgoroutinechurn", data, opts);
+    }
+
+    // command "getgoroutinelogs" [call]
+    GetGoRoutineLogsCommand(client: RpcClient, data: GoRoutineLogsRequest, opts?: RpcOpts): Promise<GoRoutineLogsData> {
+        return client.rpcCall("getgoroutinelogs", data, opts);
+    }
+
+    // command "getsdkoverhead" [call]
+    GetSDKOverheadCommand(client: RpcClient, data: AppRunRequest, opts?: RpcOpts): Promise<SDKOverheadData> {
+        return client.rpcCall("getsdkoverhead", data, opts);
+    }
+
+    // command "getsessiontimeline" [call]
+    GetSessionTimelineCommand(client: RpcClient, data: SessionTimelineRequest, opts?: RpcOpts): Promise<SessionTimelineData> {
+        return client.rpcCall("getsessiontimeline", data, opts);
+    }
+
+    // command "getsnapshotrules" [call]
+    GetSnapshotRulesCommand(client: RpcClient, opts?: RpcOpts): Promise<SnapshotRulesData> {
+        return client.rpcCall("getsnapshotrules", null, opts);
+    }
+
+    // command "getsnapshots" [call]
+    GetSnapshotsCommand(client: RpcClient, data: SnapshotsRequest, opts?: RpcOpts): Promise<SnapshotsData> {
+        return client.rpcCall("getsnapshots", data, opts);
+    }
+
+    // command "getsourcepreview" [call]
+    GetSourcePreviewCommand(client: RpcClient, data: SourcePreviewRequest, opts?: RpcOpts): Promise<SourcePreviewData> {
+        return client.rpcCall("getsourcepreview", data, opts);
+    }
+
+    // command "globalsearchrequest" [call]
+    GlobalSearchRequestCommand(client: RpcClient, data: GlobalSearchRequest, opts?: RpcOpts): Promise<GlobalSearchResultData> {
+        return client.rpcCall("globalsearchrequest", data, opts);
+    }
+
     // command "goroutinesearchrequest" [call]
     GoRoutineSearchRequestCommand(client: RpcClient, data: GoRoutineSearchRequestData, opts?: RpcOpts): Promise<GoRoutineSearchResultData> {
         return client.rpcCall("goroutinesearchrequest", data, opts);
     }
 
+    // command "goroutinestatehistogram" [call]
+    GoRoutineStateHistogramCommand(client: RpcClient, data: GoRoutineStateHistogramRequest, opts?: RpcOpts): Promise<GoRoutineStateHistogramResponse> {
+        return client.rpcCall("goroutinestatehistogram", data, opts);
+    }
+
     // command "goroutinetimespans" [call]
     GoRoutineTimeSpansCommand(client: RpcClient, data: GoRoutineTimeSpansRequest, opts?: RpcOpts): Promise<GoRoutineTimeSpansResponse> {
         return client.rpcCall("goroutinetimespans", data, opts);
@@ -81,11 +216,21 @@ class RpcApiType {
         return client.rpcCall("launchdemoapp", null, opts);
     }
 
+    // command "logexportmarkedlines" [call]
+    LogExportMarkedLinesCommand(client: RpcClient, data: MarkedLinesRequestData, opts?: RpcOpts): Promise<MarkedLinesExportData> {
+        return client.rpcCall("logexportmarkedlines", data, opts);
+    }
+
     // command "loggetmarkedlines" [call]
     LogGetMarkedLinesCommand(client: RpcClient, data: MarkedLinesRequestData, opts?: RpcOpts): Promise<MarkedLinesResultData> {
         return client.rpcCall("loggetmarkedlines", data, opts);
     }
 
+    // command "logsearchrange" [call]
+    LogSearchRangeCommand(client: RpcClient, data: LogSearchRangeRequest, opts?: RpcOpts): Promise<LogSearchRangeResultData> {
+        return client.rpcCall("logsearchrange", data, opts);
+    }
+
     // command "logsearchrequest" [call]
     LogSearchRequestCommand(client: RpcClient, data: SearchRequestData, opts?: RpcOpts): Promise<SearchResultData> {
         return client.rpcCall("logsearchrequest", data, opts);
@@ -96,6 +241,11 @@ class RpcApiType {
         return client.rpcCall("logstreamupdate", data, opts);
     }
 
+    // command "logtail" [responsestream]
+    LogTailCommand(client: RpcClient, data: LogTailRequest, opts?: RpcOpts): AsyncGenerator<LogTailData, void, boolean> {
+        return client.rpcStream("logtail", data, opts);
+    }
+
     // command "logupdatemarkedlines" [call]
     LogUpdateMarkedLinesCommand(client: RpcClient, data: MarkedLinesData, opts?: RpcOpts): Promise<void> {
         return client.rpcCall("logupdatemarkedlines", data, opts);
@@ -111,16 +261,51 @@ class RpcApiType {
         return client.rpcCall("message", data, opts);
     }
 
+    // command "pruneappruns" [call]
+    PruneAppRunsCommand(client: RpcClient, data: PruneAppRunsRequest, opts?: RpcOpts): Promise<PruneAppRunsResult> {
+        return client.rpcCall("pruneappruns", data, opts);
+    }
+
+    // command "recordsessionevent" [call]
+    RecordSessionEventCommand(client: RpcClient, data: SessionEvent, opts?: RpcOpts): Promise<void> {
+        return client.rpcCall("recordsessionevent", data, opts);
+    }
+
+    // command "runsnapshotrule" [call]
+    RunSnapshotRuleCommand(client: RpcClient, data: SnapshotRuleRequest, opts?: RpcOpts): Promise<SnapshotsData> {
+        return client.rpcCall("runsnapshotrule", data, opts);
+    }
+
+    // command "runtimecontrol" [call]
+    RuntimeControlCommand(client: RpcClient, data: RuntimeControlRequest, opts?: RpcOpts): Promise<RuntimeControlResponse> {
+        return client.rpcCall("runtimecontrol", data, opts);
+    }
+
     // command "sendteventfe" [call]
     SendTEventFeCommand(client: RpcClient, data: TEventFeData, opts?: RpcOpts): Promise<void> {
         return client.rpcCall("sendteventfe", data, opts);
     }
 
+    // command "setwatchvalue" [call]
+    SetWatchValueCommand(client: RpcClient, data: SetWatchValueRequest, opts?: RpcOpts): Promise<SetWatchValueResponse> {
+        return client.rpcCall("setwatchvalue", data, opts);
+    }
+
+    // command "subscriberuntimestats" [responsestream]
+    SubscribeRuntimeStatsCommand(client: RpcClient, data: AppRunRequest, opts?: RpcOpts): AsyncGenerator<AppRunRuntimeStatsData, void, boolean> {
+        return client.rpcStream("subscriberuntimestats", data, opts);
+    }
+
     // command "triggertrayupdate" [call]
     TriggerTrayUpdateCommand(client: RpcClient, opts?: RpcOpts): Promise<void> {
         return client.rpcCall("triggertrayupdate", null, opts);
     }
 
+    // command "updatebrowsertabfocus" [call]
+    UpdateBrowserTabFocusCommand(client: RpcClient, data: BrowserTabFocusData, opts?: RpcOpts): Promise<void> {
+        return client.rpcCall("updatebrowsertabfocus", data, opts);
+    }
+
     // command "updatebrowsertaburl" [call]
     UpdateBrowserTabUrlCommand(client: RpcClient, data: BrowserTabUrlData, opts?: RpcOpts): Promise<void> {
         return client.rpcCall("updatebrowsertaburl", data, opts);
@@ -131,6 +316,11 @@ class RpcApiType {
         return client.rpcCall("updatecheck", null, opts);
     }
 
+    // command "updatesnapshotrule" [call]
+    UpdateSnapshotRuleCommand(client: RpcClient, data: SnapshotRule, opts?: RpcOpts): Promise<SnapshotRule> {
+        return client.rpcCall("updatesnapshotrule", data, opts);
+    }
+
     // command "updatestatus" [call]
     UpdateStatusCommand(client: RpcClient, data: StatusUpdateData, opts?: RpcOpts): Promise<void> {
         return client.rpcCall("updatestatus", data, opts);
@@ -67,6 +67,13 @@ export class RpcRouter {
     }
 
     recvRpcMessage(msg: RpcMessage) {
+        if (msg.databatch != null) {
+            // batched messages from the server, route them in order
+            for (const batchMsg of msg.databatch) {
+                this.recvRpcMessage(batchMsg);
+            }
+            return;
+        }
         dlog("router received message", msg);
         // we are a terminal node by definition, so we don't need to process with announce/unannounce messages
         if (msg.command == "routeannounce" || msg.command == "routeunannounce") {
@@ -81,4 +81,4 @@ function sendRpcCommand(
     return rtnGen;
 }
 
-export { sendRpcCommand };
+export { sendRpcCancel, sendRpcCommand };
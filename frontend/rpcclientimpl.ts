@@ -4,6 +4,7 @@
 import { RpcClient, RpcResponseHelper } from "@/rpc/rpc";
 import { RpcRouter } from "@/rpc/rpcrouter";
 import { emitter } from "@/events";
+import { handleRecvEvent } from "@/rpc/rps";
 
 class RpcClientImpl extends RpcClient {
     constructor(router: RpcRouter, routeId: string) {
@@ -14,6 +15,11 @@ class RpcClientImpl extends RpcClient {
         // Emit the event using mitt
         emitter.emit('logstreamupdate', data);
     }
+
+    handle_eventrecv(rh: RpcResponseHelper, data: EventType) {
+        // events the tab subscribed to with eventSubscribe
+        handleRecvEvent(data);
+    }
 }
 
 export { RpcClientImpl };
@@ -9,6 +9,14 @@ import { atom, getDefaultStore, PrimitiveAtom } from "jotai";
 // Maximum number of runtime stats entries to keep (10 minutes of data at 1s intervals)
 const MAX_RUNTIME_STATS_ENTRIES = 600;
 
+// The runtime stats subscription is restarted after this timeout (and after errors, with a delay)
+const SUBSCRIPTION_TIMEOUT_MS = 60 * 60 * 1000;
+const RESUBSCRIBE_DELAY_MS = 2000;
+
+// The SDK overhead comes from the collector statuses (sent by the SDK every couple of seconds),
+// it is refetched at most this often
+const OVERHEAD_REFRESH_MS = 2000;
+
 // Create a type that combines the AppRunRuntimeStatsData with a single RuntimeStatData
 // This is for backward compatibility with the existing UI
 export type CombinedStatsData = {
@@ -26,6 +34,10 @@ export type CombinedStatsData = {
     pid: number;
     cwd: string;
     memstats: MemoryStatsInfo;
+    fdstats?: FDStatsInfo;
+    schedstats?: SchedStatsInfo;
+    allocrate?: number;
+    gcrate?: number;
 };
 
 class RuntimeStatsModel {
@@ -39,25 +51,28 @@ class RuntimeStatsModel {
     runtimeStats: PrimitiveAtom<CombinedStatsData | null> = atom<CombinedStatsData | null>(
         null
     ) as PrimitiveAtom<CombinedStatsData | null>;
+    // What the SDK's collectors cost the app (null until loaded)
+    overhead: PrimitiveAtom<SDKOverheadData | null> = atom<SDKOverheadData | null>(
+        null
+    ) as PrimitiveAtom<SDKOverheadData | null>;
+    overheadFetchTs: number = 0;
     isRefreshing: PrimitiveAtom<boolean> = atom(false);
     autoRefresh: PrimitiveAtom<boolean> = atom(true); // Default to on
-    autoRefreshIntervalId: number | null = null;
-    autoRefreshInterval: number = 1000; // 1 second by default
+    // reqid of the runtime stats subscription (null when not subscribed)
+    subReqId: string | null = null;
+    resubscribeTimeoutId: number | null = null;
 
     constructor(appRunId: string) {
         this.widgetId = crypto.randomUUID();
         this.appRunId = appRunId;
 
-        // Initial refresh
-        this.quietRefresh(true);
-
-        // Start auto-refresh interval since default is on
-        this.startAutoRefreshInterval();
+        // Subscribe since auto-refresh is on by default (the first update has the stats collected so far)
+        this.startSubscription();
     }
 
     // Clean up resources when component unmounts
     dispose() {
-        this.stopAutoRefreshInterval();
+        this.stopSubscription();
     }
 
     // Toggle auto-refresh state
@@ -67,30 +82,67 @@ class RuntimeStatsModel {
         store.set(this.autoRefresh, !currentState);
 
         if (!currentState) {
-            // If turning on, start the interval
-            this.startAutoRefreshInterval();
+            // If turning on, subscribe to the stats updates
+            this.startSubscription();
         } else {
-            // If turning off, clear the interval
-            this.stopAutoRefreshInterval();
+            // If turning off, stop the subscription
+            this.stopSubscription();
         }
     }
 
-    // Start the auto-refresh interval
-    startAutoRefreshInterval() {
-        // Clear any existing interval first
-        this.stopAutoRefreshInterval();
+    // Start the runtime stats subscription, the server pushes new stats as they arrive
+    startSubscription() {
+        this.stopSubscription();
+        this.runSubscription();
+    }
 
-        // Set up new interval
-        this.autoRefreshIntervalId = window.setInterval(() => {
-            this.quietRefresh(false);
-        }, this.autoRefreshInterval);
+    // Stop the runtime stats subscription
+    stopSubscription() {
+        if (this.resubscribeTimeoutId !== null) {
+            window.clearTimeout(this.resubscribeTimeoutId);
+            this.resubscribeTimeoutId = null;
+        }
+        if (this.subReqId !== null) {
+            const reqId = this.subReqId;
+            this.subReqId = null;
+            DefaultRpcClient.cancelRpc(reqId);
+        }
     }
 
-    // Stop the auto-refresh interval
-    stopAutoRefreshInterval() {
-        if (this.autoRefreshIntervalId !== null) {
-            window.clearInterval(this.autoRefreshIntervalId);
-            this.autoRefreshIntervalId = null;
+    async runSubscription() {
+        const reqId = crypto.randomUUID();
+        this.subReqId = reqId;
+        try {
+            const gen = RpcApi.SubscribeRuntimeStatsCommand(
+                DefaultRpcClient,
+                {
+                    apprunid: this.appRunId,
+                    since: getDefaultStore().get(this.latestTimestamp),
+                },
+                { reqid: reqId, timeout: SUBSCRIPTION_TIMEOUT_MS }
+            );
+            for await (const result of gen) {
+                if (this.subReqId !== reqId) {
+                    // the subscription was stopped (or replaced)
+                    break;
+                }
+                this.addStats(result);
+            }
+        } catch (error) {
+            console.error(`Runtime stats subscription failed for app run ${this.appRunId}:`, error);
+        }
+        if (this.subReqId !== reqId) {
+            return;
+        }
+        this.subReqId = null;
+
+        // the stream ends when the app run stops, or on a timeout/error (resubscribe if the app is still running)
+        const appRunInfo = getDefaultStore().get(AppModel.getAppRunInfoAtom(this.appRunId));
+        if (appRunInfo?.status === "running" && getDefaultStore().get(this.autoRefresh)) {
+            this.resubscribeTimeoutId = window.setTimeout(() => {
+                this.resubscribeTimeoutId = null;
+                this.startSubscription();
+            }, RESUBSCRIBE_DELAY_MS);
         }
     }
 
@@ -113,6 +165,17 @@ class RuntimeStatsModel {
         }
     }
 
+    // Fetch the SDK overhead (failures are logged, the last overhead is kept)
+    async fetchOverhead() {
+        this.overheadFetchTs = Date.now();
+        try {
+            const result = await RpcApi.GetSDKOverheadCommand(DefaultRpcClient, { apprunid: this.appRunId });
+            getDefaultStore().set(this.overhead, result);
+        } catch (error) {
+            console.error(`Failed to load SDK overhead for app run ${this.appRunId}:`, error);
+        }
+    }
+
     // Update the latest timestamp based on the stats we received
     private updateLatestTimestamp(stats: RuntimeStatData[]) {
         if (stats.length === 0) return;
@@ -154,98 +217,63 @@ class RuntimeStatsModel {
             pid: latestStat.pid,
             cwd: latestStat.cwd,
             memstats: latestStat.memstats,
+            fdstats: latestStat.fdstats,
+            schedstats: latestStat.schedstats,
+            allocrate: latestStat.allocrate,
+            gcrate: latestStat.gcrate,
         };
 
         // Update the legacy stats atom
         store.set(this.runtimeStats, legacyStats);
     }
 
-    // Refresh runtime stats with a minimum time to show the refreshing state
-    async refresh() {
+    // Append the new stats of result (from a refresh or the subscription)
+    private addStats(result: AppRunRuntimeStatsData) {
+        if (result == null || result.stats == null || result.stats.length === 0) {
+            return;
+        }
         const store = getDefaultStore();
-
-        // If already refreshing, don't do anything
-        if (store.get(this.isRefreshing)) {
+        // skip the stats we already have (a refresh can race with the subscription)
+        const latestTs = store.get(this.latestTimestamp);
+        const newStats = result.stats.filter((stat) => stat.ts > latestTs);
+        if (newStats.length === 0) {
             return;
         }
 
-        // Set refreshing state to true
-        store.set(this.isRefreshing, true);
-
-        try {
-            // Fetch new stats
-            const result = await this.fetchRuntimeStats();
-
-            if (result && result.stats.length > 0) {
-                // Get current stats
-                const currentStats = store.get(this.allRuntimeStats);
-
-                // Append new stats
-                let updatedStats = [...currentStats, ...result.stats];
-
-                // Limit the array size to MAX_RUNTIME_STATS_ENTRIES
-                if (updatedStats.length > MAX_RUNTIME_STATS_ENTRIES) {
-                    updatedStats = updatedStats.slice(-MAX_RUNTIME_STATS_ENTRIES);
-                }
-
-                // Update all stats atom
-                store.set(this.allRuntimeStats, updatedStats);
+        // Append new stats, limiting the array size to MAX_RUNTIME_STATS_ENTRIES
+        let updatedStats = [...store.get(this.allRuntimeStats), ...newStats];
+        if (updatedStats.length > MAX_RUNTIME_STATS_ENTRIES) {
+            updatedStats = updatedStats.slice(-MAX_RUNTIME_STATS_ENTRIES);
+        }
+        store.set(this.allRuntimeStats, updatedStats);
 
-                // Update the latest timestamp
-                this.updateLatestTimestamp(result.stats);
+        this.updateLatestTimestamp(newStats);
+        this.updateLegacyRuntimeStats({ ...result, stats: newStats });
 
-                // Update the legacy stats object for backward compatibility
-                this.updateLegacyRuntimeStats(result);
-            }
-        } finally {
-            // Set refreshing state to false
-            store.set(this.isRefreshing, false);
+        if (Date.now() - this.overheadFetchTs >= OVERHEAD_REFRESH_MS) {
+            this.fetchOverhead();
         }
     }
 
-    // Quiet refresh for auto-refresh - doesn't set isRefreshing or clear stats
-    async quietRefresh(force: boolean) {
-        // Get the app run info to check its status
+    // Refresh runtime stats with a minimum time to show the refreshing state
+    async refresh() {
         const store = getDefaultStore();
-        const appRunInfoAtom = AppModel.getAppRunInfoAtom(this.appRunId);
-        const appRunInfo = store.get(appRunInfoAtom);
 
-        if (!appRunInfo) {
+        // If already refreshing, don't do anything
+        if (store.get(this.isRefreshing)) {
             return;
         }
 
-        // If app run is not connected (status is not "running"), don't refresh
-        if (!force && appRunInfo.status !== "running") {
-            return;
-        }
+        // Set refreshing state to true
+        store.set(this.isRefreshing, true);
 
         try {
             // Fetch new stats
-            const result = await this.fetchRuntimeStats();
-
-            if (result && result.stats.length > 0) {
-                // Get current stats
-                const currentStats = store.get(this.allRuntimeStats);
-
-                // Append new stats
-                let updatedStats = [...currentStats, ...result.stats];
-
-                // Limit the array size to MAX_RUNTIME_STATS_ENTRIES
-                if (updatedStats.length > MAX_RUNTIME_STATS_ENTRIES) {
-                    updatedStats = updatedStats.slice(-MAX_RUNTIME_STATS_ENTRIES);
-                }
-
-                // Update all stats atom
-                store.set(this.allRuntimeStats, updatedStats);
-
-                // Update the latest timestamp
-                this.updateLatestTimestamp(result.stats);
-
-                // Update the legacy stats object for backward compatibility
-                this.updateLegacyRuntimeStats(result);
-            }
-        } catch (error) {
-            console.error(`Failed to auto-refresh runtime stats for app run ${this.appRunId}:`, error);
+            const [result] = await Promise.all([this.fetchRuntimeStats(), this.fetchOverhead()]);
+            this.addStats(result);
+        } finally {
+            // Set refreshing state to false
+            store.set(this.isRefreshing, false);
         }
     }
 }
@@ -0,0 +1,102 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+import { cn, formatMemorySize, formatNanos } from "@/util/util";
+import { useAtomValue } from "jotai";
+import React from "react";
+import { RuntimeStatsModel } from "./runtimestats-model";
+
+// A collector using more than this share of a CPU gets a hint to raise its poll interval
+const HIGH_CPU_PERCENT = 1;
+
+function formatInterval(ms: number): string {
+    if (ms < 1000) {
+        return `${ms}ms`;
+    }
+    return `${ms / 1000}s`;
+}
+
+function formatBytes(bytes: number): string {
+    const mem = formatMemorySize(Math.round(bytes));
+    return `${mem.memstr} ${mem.memunit}`;
+}
+
+function formatPercent(percent: number): string {
+    if (percent > 0 && percent < 0.01) {
+        return "<0.01%";
+    }
+    return `${percent.toFixed(2)}%`;
+}
+
+// SDK overhead panel, what each collector costs the app per collection and at its interval
+interface SDKOverheadPanelProps {
+    model: RuntimeStatsModel;
+}
+
+export const SDKOverheadPanel: React.FC<SDKOverheadPanelProps> = ({ model }) => {
+    const overhead = useAtomValue(model.overhead);
+
+    // older SDKs don't report the overhead of their collectors
+    if (overhead == null || overhead.collectors.length === 0) {
+        return null;
+    }
+    const cpu = (value: string) => (overhead.cpumeasured ? value : "n/a");
+    const highCpu = overhead.collectors.filter((info) => info.cpupercent >= HIGH_CPU_PERCENT);
+
+    return (
+        <div className="mb-6 p-4 border border-border rounded-md bg-panel">
+            <div className="text-sm text-secondary font-medium mb-2">SDK Overhead</div>
+            <table className="w-full text-sm">
+                <thead>
+                    <tr className="text-left text-muted text-xs">
+                        <th className="font-normal pb-1">Collector</th>
+                        <th className="font-normal pb-1">Interval</th>
+                        <th className="font-normal pb-1">Collections</th>
+                        <th className="font-normal pb-1">CPU / collection</th>
+                        <th className="font-normal pb-1">CPU</th>
+                        <th className="font-normal pb-1">Alloc / collection</th>
+                        <th className="font-normal pb-1">Alloc rate</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {overhead.collectors.map((info) => (
+                        <tr key={info.name} className="border-t border-border">
+                            <td className="py-1 font-mono text-primary">
+                                {info.name}
+                                {!info.running && <span className="ml-2 text-xs text-muted">(stopped)</span>}
+                            </td>
+                            <td className="py-1 text-secondary">{formatInterval(info.overhead.intervalms)}</td>
+                            <td className="py-1 text-secondary">{info.overhead.numcycles.toLocaleString()}</td>
+                            <td className="py-1 text-secondary">{cpu(formatNanos(info.avgcpuus * 1000))}</td>
+                            <td
+                                className={cn(
+                                    "py-1",
+                                    info.cpupercent >= HIGH_CPU_PERCENT ? "text-warning" : "text-primary"
+                                )}
+                            >
+                                {cpu(formatPercent(info.cpupercent))}
+                            </td>
+                            <td className="py-1 text-secondary">{formatBytes(info.avgallocbytes)}</td>
+                            <td className="py-1 text-primary">{formatBytes(info.allocbytesrate)}/s</td>
+                        </tr>
+                    ))}
+                    <tr className="border-t border-border font-medium">
+                        <td className="py-1 text-primary" colSpan={4}>
+                            Total
+                        </td>
+                        <td className="py-1 text-primary">{cpu(formatPercent(overhead.totalcpupercent))}</td>
+                        <td className="py-1"></td>
+                        <td className="py-1 text-primary">{formatBytes(overhead.totalallocbytesrate)}/s</td>
+                    </tr>
+                </tbody>
+            </table>
+            <div className="mt-2 text-xs text-muted">
+                CPU is the share of one CPU used by the collections
+                {overhead.cpumeasured ? "" : " (not measured on this platform)"}. Allocations are measured for the
+                whole process while a collection runs, so they are an upper bound.
+                {highCpu.length > 0 &&
+                    ` Raise the poll interval of ${highCpu.map((info) => info.name).join(", ")} to lower its cost.`}
+            </div>
+        </div>
+    );
+};
@@ -0,0 +1,118 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+import { formatNanos } from "@/util/util";
+import { useAtomValue } from "jotai";
+import React from "react";
+import { CartesianGrid, Line, LineChart, ResponsiveContainer, Tooltip, XAxis, YAxis } from "recharts";
+import { RuntimeStatsModel } from "./runtimestats-model";
+
+// Scheduler health chart (scheduler latency, GC pauses, and mutex wait time per sample interval)
+interface SchedulerChartProps {
+    model: RuntimeStatsModel;
+    height?: number;
+}
+
+const lineColors = {
+    "Sched Latency p99": "#2563eb", // blue-600
+    "GC Pause Max": "#ca8a04", // yellow-600
+    "Mutex Wait": "#dc2626", // red-600
+};
+
+const SchedulerTooltip = ({ active, payload, label }: any) => {
+    if (!active || !payload || !payload.length) {
+        return null;
+    }
+    return (
+        <div className="bg-panel border border-border rounded-md px-3 py-2 text-sm text-primary shadow-md">
+            <div className="font-medium mb-1">{new Date(label).toLocaleTimeString()}</div>
+            {payload.map((entry: any, index: number) => (
+                <div key={`item-${index}`} className="flex items-center mb-1">
+                    <div className="w-3 h-3 mr-1 rounded-sm" style={{ backgroundColor: entry.stroke }}></div>
+                    <span>
+                        {entry.name}: {formatNanos(entry.value)}
+                    </span>
+                </div>
+            ))}
+        </div>
+    );
+};
+
+const formatXAxis = (timestamp: number) => {
+    const timeString = new Date(timestamp).toLocaleTimeString(undefined, {
+        hour: "numeric",
+        minute: "2-digit",
+        second: "2-digit",
+    });
+    return timeString.replace(/\s*(AM|PM)\b/g, (match) => match.toLowerCase().trim());
+};
+
+export const SchedulerChart: React.FC<SchedulerChartProps> = ({ model, height = 200 }) => {
+    const runtimeStats = useAtomValue(model.allRuntimeStats);
+
+    const chartData = runtimeStats
+        .filter((stat) => stat.schedstats != null)
+        .map((stat) => ({
+            timestamp: stat.ts,
+            "Sched Latency p99": stat.schedstats.schedlatencyp99ns,
+            "GC Pause Max": stat.schedstats.gcpausemaxns,
+            "Mutex Wait": stat.schedstats.mutexwaitns,
+        }));
+
+    // need a few samples before a line chart is useful
+    if (chartData.length < 2) {
+        return null;
+    }
+
+    return (
+        <div className="w-full">
+            <div style={{ height }}>
+                <ResponsiveContainer width="100%" height="100%">
+                    <LineChart data={chartData} margin={{ top: 10, right: 10, left: 0, bottom: 20 }}>
+                        <CartesianGrid strokeWidth={1} stroke="#444" />
+                        <XAxis
+                            dataKey="timestamp"
+                            type="number"
+                            scale="time"
+                            domain={["dataMin", "dataMax"]}
+                            tickFormatter={formatXAxis}
+                            tick={{ fontSize: 10, fill: "#9ca3af" }}
+                            height={30}
+                            tickMargin={8}
+                            axisLine={{ stroke: "#666" }}
+                            tickLine={{ stroke: "#666" }}
+                            minTickGap={30}
+                        />
+                        <YAxis
+                            tickFormatter={formatNanos}
+                            tick={{ fontSize: 10, fill: "#9ca3af" }}
+                            width={60}
+                            axisLine={{ stroke: "#666" }}
+                            tickLine={{ stroke: "#666" }}
+                        />
+                        <Tooltip content={<SchedulerTooltip />} />
+                        {Object.entries(lineColors).map(([name, color]) => (
+                            <Line
+                                key={name}
+                                type="linear"
+                                dataKey={name}
+                                stroke={color}
+                                dot={false}
+                                strokeWidth={1.5}
+                                isAnimationActive={false}
+                            />
+                        ))}
+                    </LineChart>
+                </ResponsiveContainer>
+            </div>
+            <div className="flex flex-wrap text-xs gap-3">
+                {Object.entries(lineColors).map(([name, color]) => (
+                    <div key={name} className="flex items-center">
+                        <div className="w-3 h-3 mr-1 rounded-sm" style={{ backgroundColor: color }}></div>
+                        <span className="text-primary">{name}</span>
+                    </div>
+                ))}
+            </div>
+        </div>
+    );
+};
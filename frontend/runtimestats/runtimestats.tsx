@@ -6,12 +6,14 @@ import { AutoRefreshButton } from "@/elements/autorefreshbutton";
 import { RefreshButton } from "@/elements/refreshbutton";
 import { TimestampDot } from "@/elements/timestampdot";
 import { useOutrigModel } from "@/util/hooks";
-import { formatMemorySize, formatTimeOffset } from "@/util/util";
+import { formatMemorySize, formatNanos, formatTimeOffset } from "@/util/util";
 import { useAtomValue } from "jotai";
 import React from "react";
 import { MemoryAreaChart } from "./runtimestats-memoryareachart";
 import { MemoryUsageChart } from "./runtimestats-memorychart";
 import { CombinedStatsData, RuntimeStatsModel } from "./runtimestats-model";
+import { SDKOverheadPanel } from "./runtimestats-overhead";
+import { SchedulerChart } from "./runtimestats-schedchart";
 import { RuntimeStatsTooltip } from "./tooltip";
 
 // Base component for stat items to ensure consistent styling
@@ -128,6 +130,7 @@ const MetricsGrid: React.FC<MetricsGridProps> = ({ stats, appRunInfo }) => {
     const heapMemory = formatMemorySize(stats.memstats.heapalloc);
     const totalMemory = formatMemorySize(stats.memstats.totalalloc);
     const sysMemory = formatMemorySize(stats.memstats.sys);
+    const allocRate = formatMemorySize(Math.round(stats.allocrate ?? 0));
 
     return (
         <div className="grid grid-cols-3 sm:grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-3">
@@ -164,6 +167,73 @@ const MetricsGrid: React.FC<MetricsGridProps> = ({ stats, appRunInfo }) => {
                 desc="Number of live heap objects currently in memory (calculated as total allocated minus freed objects)."
             />
 
+            <StatItem
+                value={allocRate.memstr}
+                label="Allocation Rate"
+                unit={`${allocRate.memunit}/s`}
+                desc="Bytes allocated on the heap per second since the previous sample. High allocation rates put pressure on the garbage collector."
+            />
+
+            <StatItem
+                value={(stats.gcrate ?? 0).toFixed(2)}
+                label="GC Rate"
+                unit="cycles/s"
+                desc="Completed GC cycles per second since the previous sample."
+            />
+
+            {/* Third row (only when the fdstats collector is running): Open Files, Sockets, Connections */}
+            {stats.fdstats && (
+                <>
+                    <StatItem
+                        value={stats.fdstats.openfds.toLocaleString()}
+                        label="Open File Descriptors"
+                        unit={stats.fdstats.maxfds ? `/ ${stats.fdstats.maxfds.toLocaleString()}` : undefined}
+                        desc="Number of file descriptors currently open in the process (files, sockets, pipes), out of the soft RLIMIT_NOFILE limit. A steadily growing count usually indicates a descriptor leak, which often accompanies a goroutine leak."
+                    />
+
+                    <StatItem
+                        value={stats.fdstats.sockets.toLocaleString()}
+                        label="Sockets"
+                        desc="Number of open sockets (TCP, UDP, and unix domain sockets)."
+                    />
+
+                    <StatItem
+                        value={stats.fdstats.established.toLocaleString()}
+                        label="Established Connections"
+                        unit={`(${stats.fdstats.listening.toLocaleString()} listening)`}
+                        desc="Number of established TCP connections, and the number of listening sockets."
+                    />
+                </>
+            )}
+
+            {/* Scheduler section (only when the schedstats collector is running) */}
+            {stats.schedstats && (
+                <>
+                    <SectionHeader title="Scheduler" />
+
+                    <StatItem
+                        value={formatNanos(stats.schedstats.schedlatencyp99ns)}
+                        label="Scheduler Latency (p99)"
+                        unit={`(p50 ${formatNanos(stats.schedstats.schedlatencyp50ns)})`}
+                        desc="How long goroutines waited in the run queue before they were scheduled, over the last sample interval. High latencies mean there are more runnable goroutines than GOMAXPROCS can run, or the process is being CPU throttled."
+                    />
+
+                    <StatItem
+                        value={formatNanos(stats.schedstats.gcpausemaxns)}
+                        label="GC Pause (max)"
+                        unit={`(${stats.schedstats.gcpauses.toLocaleString()} pauses)`}
+                        desc="Longest stop-the-world GC pause in the last sample interval. All goroutines are stopped during these pauses."
+                    />
+
+                    <StatItem
+                        value={formatNanos(stats.schedstats.mutexwaitns)}
+                        label="Mutex Wait"
+                        unit={`(${formatNanos(stats.schedstats.mutexwaittotalns)} total)`}
+                        desc="Time goroutines spent blocked on a sync.Mutex or sync.RWMutex in the last sample interval (summed over all goroutines). Growing wait times indicate lock contention."
+                    />
+                </>
+            )}
+
             {/* Lifetime section */}
             <SectionHeader title="Lifetime" />
 
@@ -220,7 +290,13 @@ const RuntimeStatsContent: React.FC<RuntimeStatsContentProps> = ({ model }) => {
     const infoItems = [
         { key: "processId", label: "Process ID", value: stats.pid },
         { key: "workingDirectory", label: "Working Directory", value: stats.cwd },
-        { key: "goMaxProcs", label: "GOMAXPROCS", value: stats.gomaxprocs },
+        {
+            key: "goMaxProcs",
+            label: "GOMAXPROCS",
+            value: stats.schedstats?.gomaxprocschanges
+                ? `${stats.gomaxprocs} (changed ${stats.schedstats.gomaxprocschanges}x, last at ${new Date(stats.schedstats.gomaxprocschangedts).toLocaleTimeString()})`
+                : stats.gomaxprocs,
+        },
         { key: "cpuCores", label: "CPU Cores", value: stats.numcpu },
         { key: "platform", label: "Platform", value: `${stats.goos}/${stats.goarch}` },
         { key: "goVersion", label: "Go Version", value: stats.goversion },
@@ -228,6 +304,13 @@ const RuntimeStatsContent: React.FC<RuntimeStatsContentProps> = ({ model }) => {
         { key: "executable", label: "Executable", value: appRunInfo.executable },
         { key: "sdkVersion", label: "Outrig SDK Version", value: appRunInfo.outrigsdkversion },
     ];
+    if (appRunInfo.clockskewms) {
+        infoItems.push({
+            key: "clockSkew",
+            label: "Clock Skew",
+            value: `app clock ${Math.abs(appRunInfo.clockskewms)}ms ${appRunInfo.clockskewms > 0 ? "ahead of" : "behind"} the monitor (timestamps corrected)`,
+        });
+    }
 
     return (
         <div className="w-full h-full overflow-auto p-4">
@@ -243,6 +326,17 @@ const RuntimeStatsContent: React.FC<RuntimeStatsContentProps> = ({ model }) => {
                 <MemoryUsageChart memStats={stats.memstats} />
             </div>
 
+            {/* Scheduler health visualization */}
+            {stats.schedstats && (
+                <div className="mb-6 p-4 border border-border rounded-md bg-panel">
+                    <div className="text-sm text-secondary font-medium mb-2">Scheduler Health</div>
+                    <SchedulerChart model={model} height={200} />
+                </div>
+            )}
+
+            {/* What the SDK's collectors cost the app */}
+            <SDKOverheadPanel model={model} />
+
             {/* Information panel */}
             <div className="mb-6 p-4 border border-border rounded-md bg-panel">
                 <div className="text-sm text-secondary font-medium mb-3">Application Information</div>
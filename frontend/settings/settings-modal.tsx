@@ -41,6 +41,13 @@ export const SettingsModal: React.FC = () => {
         }
     }, []);
 
+    // the display name is sent with the browser tab url, the other tabs see the new name once the modal closes
+    useEffect(() => {
+        return () => {
+            AppModel.sendBrowserTabUrl();
+        };
+    }, []);
+
     const showSource = useAtomValue(SettingsModel.logsShowSource);
     const showTimestamp = useAtomValue(SettingsModel.logsShowTimestamp);
     const showMilliseconds = useAtomValue(SettingsModel.logsShowMilliseconds);
@@ -48,6 +55,7 @@ export const SettingsModal: React.FC = () => {
     const showLineNumbers = useAtomValue(SettingsModel.logsShowLineNumbers);
     const emojiReplacement = useAtomValue(SettingsModel.logsEmojiReplacement);
     const codeLinkType = useAtomValue(SettingsModel.codeLinkType);
+    const presenceUserName = useAtomValue(SettingsModel.presenceUserName);
     const [darkMode, setDarkMode] = useAtom(AppModel.darkMode);
 
     return (
@@ -182,6 +190,29 @@ export const SettingsModal: React.FC = () => {
                         </div>
                     </div>
 
+                    {/* Presence Section */}
+                    <div className="bg-secondary/10 rounded-lg p-4">
+                        <h2 className="text-lg font-semibold mb-4 border-b border-secondary/20 pb-2">Presence</h2>
+                        <div className="space-y-4">
+                            <div className="flex items-center justify-between gap-4">
+                                <div>
+                                    <div className="font-medium">Display Name</div>
+                                    <div className="text-sm text-secondary">
+                                        Shown to the other people viewing the same app run
+                                    </div>
+                                </div>
+                                <input
+                                    type="text"
+                                    value={presenceUserName}
+                                    maxLength={40}
+                                    onChange={(e) => SettingsModel.setPresenceUserName(e.target.value)}
+                                    placeholder="anonymous"
+                                    className="w-48 px-2 py-1 text-sm bg-panel border border-border rounded focus:outline-none focus:border-primary/50"
+                                />
+                            </div>
+                        </div>
+                    </div>
+
                     {/* Data Management Section */}
                     <div className="bg-secondary/10 rounded-lg p-4">
                         <h2 className="text-lg font-semibold mb-4 border-b border-secondary/20 pb-2">
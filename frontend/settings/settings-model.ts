@@ -17,6 +17,10 @@ export interface CodeLinkSettings {
     linkType: CodeLinkType;
 }
 
+export interface PresenceSettings {
+    userName: string;
+}
+
 const SETTINGS_STORAGE_KEY = "outrig:settings";
 const DEFAULT_SHOW_SOURCE = true;
 const DEFAULT_SHOW_LINE_NUMBERS = true;
@@ -24,6 +28,7 @@ const DEFAULT_SHOW_LINE_NUMBERS = true;
 export interface Settings {
     logs?: Partial<LogSettings>;
     codeLink?: Partial<CodeLinkSettings>;
+    presence?: Partial<PresenceSettings>;
 }
 
 const DEFAULT_SHOW_MILLISECONDS = true;
@@ -207,6 +212,25 @@ class SettingsModel {
         getDefaultStore().set(this.settings, newSettings);
         saveSettings(newSettings);
     }
+
+    // name shown to the other browser tabs viewing the same app run ("" shows an anonymous name)
+    presenceUserName = atom((get) => {
+        const settings = get(this.settings);
+        return settings?.presence?.userName ?? "";
+    });
+
+    setPresenceUserName(value: string): void {
+        const currentSettings = getDefaultStore().get(this.settings) || {};
+        const newSettings = {
+            ...currentSettings,
+            presence: {
+                ...(currentSettings.presence || {}),
+                userName: value,
+            },
+        };
+        getDefaultStore().set(this.settings, newSettings);
+        saveSettings(newSettings);
+    }
 }
 
 const model = new SettingsModel();
@@ -0,0 +1,286 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+import { AppModel } from "@/appmodel";
+import { AppRunListModel } from "@/apprunlist/apprunlist-model";
+import { Dropdown } from "@/elements/dropdown";
+import { Modal } from "@/elements/modal";
+import { Toggle } from "@/elements/toggle";
+import { SnapshotsModel, SnapshotsRefreshIntervalMs } from "@/snapshots/snapshots-model";
+import { formatMemorySize, formatRelativeTime } from "@/util/util";
+import { useAtomValue } from "jotai";
+import { Download, Play, Trash2 } from "lucide-react";
+import React, { useEffect, useMemo, useState } from "react";
+
+const SchedulePresets = [
+    { value: "@every 10m", label: "Every 10 minutes" },
+    { value: "@hourly", label: "Hourly" },
+    { value: "@daily", label: "Daily (midnight)" },
+    { value: "@weekly", label: "Weekly (Sunday midnight)" },
+    { value: "custom", label: "Custom (@every <duration> or cron)" },
+];
+
+const inputClassName =
+    "w-full px-2 py-1 text-sm bg-panel border border-border rounded focus:outline-none focus:border-primary/50";
+const buttonClassName =
+    "px-2 py-1 rounded text-xs cursor-pointer border border-border text-primary hover:bg-buttonhover disabled:opacity-50 disabled:cursor-default";
+
+function formatTs(ts: number): string {
+    if (!ts) {
+        return "never";
+    }
+    return new Date(ts).toLocaleString();
+}
+
+// Container component that checks isOpen state
+export const SnapshotsModalContainer: React.FC = () => {
+    const isOpen = useAtomValue(AppModel.snapshotsModalOpen);
+
+    if (!isOpen) return null;
+
+    return <SnapshotsModal />;
+};
+
+interface NewRuleFormProps {
+    onDone: () => void;
+}
+
+const NewRuleForm: React.FC<NewRuleFormProps> = ({ onDone }) => {
+    const appRuns = useAtomValue(AppRunListModel.appRuns);
+    const [name, setName] = useState("");
+    const [kind, setKind] = useState("goroutines");
+    const [preset, setPreset] = useState("@every 10m");
+    const [customSchedule, setCustomSchedule] = useState("");
+    const [appName, setAppName] = useState("");
+    const [searchTerm, setSearchTerm] = useState("ERROR");
+    const [saving, setSaving] = useState(false);
+    const appNames = useMemo(() => [...new Set(appRuns.map((appRun) => appRun.appname))].sort(), [appRuns]);
+
+    const handleSave = async () => {
+        setSaving(true);
+        const ok = await SnapshotsModel.saveRule({
+            ruleid: "",
+            name,
+            kind,
+            schedule: preset === "custom" ? customSchedule : preset,
+            appname: appName,
+            searchterm: kind === "logs" ? searchTerm : "",
+        });
+        setSaving(false);
+        if (ok) {
+            onDone();
+        }
+    };
+
+    return (
+        <div className="bg-secondary/10 rounded-lg p-4 space-y-3">
+            <div className="grid grid-cols-2 gap-3">
+                <Dropdown
+                    id="snapshot-kind"
+                    label="Capture"
+                    value={kind}
+                    onChange={setKind}
+                    options={[
+                        { value: "goroutines", label: "Goroutine dump" },
+                        { value: "logs", label: "Log export" },
+                    ]}
+                />
+                <Dropdown
+                    id="snapshot-schedule"
+                    label="Schedule"
+                    value={preset}
+                    onChange={setPreset}
+                    options={SchedulePresets}
+                />
+            </div>
+            {preset === "custom" && (
+                <input
+                    type="text"
+                    value={customSchedule}
+                    onChange={(e) => setCustomSchedule(e.target.value)}
+                    placeholder='e.g. "@every 30m" or "0 3 * * 1-5" (minute hour day month weekday)'
+                    className={`${inputClassName} font-mono`}
+                />
+            )}
+            {kind === "logs" && (
+                <input
+                    type="text"
+                    value={searchTerm}
+                    onChange={(e) => setSearchTerm(e.target.value)}
+                    placeholder="Log search (same syntax as the log viewer, empty exports every line)"
+                    className={`${inputClassName} font-mono`}
+                />
+            )}
+            <div className="grid grid-cols-2 gap-3">
+                <input
+                    type="text"
+                    value={appName}
+                    list="snapshot-appnames"
+                    onChange={(e) => setAppName(e.target.value)}
+                    placeholder="App name (empty for every app)"
+                    className={inputClassName}
+                />
+                <datalist id="snapshot-appnames">
+                    {appNames.map((appName) => (
+                        <option key={appName} value={appName} />
+                    ))}
+                </datalist>
+                <input
+                    type="text"
+                    value={name}
+                    onChange={(e) => setName(e.target.value)}
+                    placeholder="Rule name (optional)"
+                    className={inputClassName}
+                />
+            </div>
+            <div className="flex justify-end gap-2">
+                <button onClick={onDone} className={buttonClassName}>
+                    Cancel
+                </button>
+                <button onClick={handleSave} disabled={saving} className={buttonClassName}>
+                    Add Rule
+                </button>
+            </div>
+        </div>
+    );
+};
+
+interface RuleRowProps {
+    rule: SnapshotRule;
+}
+
+const RuleRow: React.FC<RuleRowProps> = ({ rule }) => {
+    const runningRuleIds = useAtomValue(SnapshotsModel.runningRuleIds);
+    const isRunning = runningRuleIds.has(rule.ruleid);
+
+    return (
+        <div className="py-2 border-b border-border last:border-b-0">
+            <div className="flex items-center gap-3">
+                <Toggle
+                    id={`snapshot-rule-${rule.ruleid}`}
+                    checked={!rule.disabled}
+                    onChange={(checked) => SnapshotsModel.setRuleDisabled(rule, !checked)}
+                />
+                <div className="flex-1 min-w-0">
+                    <div className="text-sm text-primary truncate">{rule.name}</div>
+                    <div className="text-xs text-muted">
+                        <span className="font-mono">{rule.schedule}</span>
+                        {rule.appname ? ` · ${rule.appname}` : " · all apps"}
+                        {` · last run ${rule.lastrunts ? formatRelativeTime(rule.lastrunts) : "never"}`}
+                        {!rule.disabled && rule.nextrunts ? ` · next ${formatTs(rule.nextrunts)}` : ""}
+                    </div>
+                </div>
+                <button
+                    onClick={() => SnapshotsModel.runRule(rule)}
+                    disabled={isRunning}
+                    className="p-1 text-secondary hover:text-primary cursor-pointer disabled:opacity-50"
+                    title="Run now"
+                >
+                    <Play size={14} />
+                </button>
+                <button
+                    onClick={() => SnapshotsModel.deleteRule(rule.ruleid)}
+                    className="p-1 text-secondary hover:text-error cursor-pointer"
+                    title="Delete rule (its snapshots are kept)"
+                >
+                    <Trash2 size={14} />
+                </button>
+            </div>
+            {rule.lasterror && <div className="pt-1 pl-12 text-xs text-error">{rule.lasterror}</div>}
+        </div>
+    );
+};
+
+interface SnapshotRowProps {
+    snapshot: SnapshotInfo;
+}
+
+const SnapshotRow: React.FC<SnapshotRowProps> = ({ snapshot }) => {
+    const size = formatMemorySize(snapshot.size);
+    const itemLabel = snapshot.kind === "goroutines" ? "goroutines" : "lines";
+
+    return (
+        <div className="flex items-center gap-3 py-1.5 border-b border-border last:border-b-0 text-sm">
+            <div className="flex-1 min-w-0">
+                <div className="text-primary truncate">
+                    {snapshot.appname} <span className="text-muted">· {snapshot.rulename}</span>
+                </div>
+                <div className="text-xs text-muted">
+                    {formatTs(snapshot.ts)} · {snapshot.numitems} {itemLabel} · {size.memstr}
+                    {size.memunit}
+                </div>
+            </div>
+            <a
+                href={snapshot.downloadurl}
+                download={snapshot.filename}
+                className="p-1 text-secondary hover:text-primary"
+                title={`Download ${snapshot.filename}`}
+            >
+                <Download size={14} />
+            </a>
+            <button
+                onClick={() => SnapshotsModel.deleteSnapshot(snapshot.snapshotid)}
+                className="p-1 text-secondary hover:text-error cursor-pointer"
+                title="Delete snapshot"
+            >
+                <Trash2 size={14} />
+            </button>
+        </div>
+    );
+};
+
+// Scheduled snapshot rules and the snapshots they took
+export const SnapshotsModal: React.FC = () => {
+    const rules = useAtomValue(SnapshotsModel.rules);
+    const snapshots = useAtomValue(SnapshotsModel.snapshots);
+    const isLoading = useAtomValue(SnapshotsModel.isLoading);
+    const [showNewRule, setShowNewRule] = useState(false);
+
+    useEffect(() => {
+        SnapshotsModel.loadData();
+        const interval = setInterval(() => SnapshotsModel.loadData(true), SnapshotsRefreshIntervalMs);
+        return () => clearInterval(interval);
+    }, []);
+
+    return (
+        <Modal
+            isOpen={true}
+            title="Scheduled Snapshots"
+            onClose={() => AppModel.closeSnapshotsModal()}
+            className="w-[750px]"
+        >
+            <div className="text-primary p-1 space-y-4">
+                <div className="bg-secondary/10 rounded-lg p-4">
+                    <div className="flex items-center justify-between mb-2 border-b border-secondary/20 pb-2">
+                        <h2 className="text-lg font-semibold">Rules</h2>
+                        {!showNewRule && (
+                            <button onClick={() => setShowNewRule(true)} className={buttonClassName}>
+                                New Rule
+                            </button>
+                        )}
+                    </div>
+                    {showNewRule && <NewRuleForm onDone={() => setShowNewRule(false)} />}
+                    {rules.length === 0 && !showNewRule && (
+                        <div className="text-sm text-muted py-2">
+                            No snapshot rules. Rules capture goroutine dumps or export matching log lines from running
+                            apps on a schedule, the snapshots are kept by the monitor after the apps exit.
+                        </div>
+                    )}
+                    {rules.map((rule) => (
+                        <RuleRow key={rule.ruleid} rule={rule} />
+                    ))}
+                </div>
+
+                <div className="bg-secondary/10 rounded-lg p-4">
+                    <h2 className="text-lg font-semibold mb-2 border-b border-secondary/20 pb-2">Snapshots</h2>
+                    {snapshots.length === 0 && (
+                        <div className="text-sm text-muted py-2">{isLoading ? "Loading..." : "No snapshots yet."}</div>
+                    )}
+                    {snapshots.map((snapshot) => (
+                        <SnapshotRow key={snapshot.snapshotid} snapshot={snapshot} />
+                    ))}
+                </div>
+            </div>
+        </Modal>
+    );
+};
@@ -0,0 +1,109 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+import { AppModel } from "@/appmodel";
+import { DefaultRpcClient } from "@/init";
+import { RpcApi } from "@/rpc/rpcclientapi";
+import { atom, getDefaultStore, PrimitiveAtom } from "jotai";
+
+// Number of snapshots shown in the snapshots panel
+const SnapshotsListLimit = 200;
+
+// The panel refreshes while open (scheduled rules add snapshots in the background)
+export const SnapshotsRefreshIntervalMs = 10 * 1000;
+
+// Log scans can take a while on large buffers (the server bounds each app run's scan at 30s)
+const RunRuleTimeoutMs = 60 * 1000;
+
+// Scheduled snapshot rules and the snapshots they took (stored by the monitor in its data dir)
+class SnapshotsModel {
+    rules: PrimitiveAtom<SnapshotRule[]> = atom<SnapshotRule[]>([]);
+    snapshots: PrimitiveAtom<SnapshotInfo[]> = atom<SnapshotInfo[]>([]);
+    isLoading: PrimitiveAtom<boolean> = atom<boolean>(false);
+    runningRuleIds: PrimitiveAtom<Set<string>> = atom<Set<string>>(new Set<string>());
+
+    async loadData(background = false): Promise<void> {
+        const store = getDefaultStore();
+        if (!background) {
+            store.set(this.isLoading, true);
+        }
+        try {
+            const [rulesData, snapshotsData] = await Promise.all([
+                RpcApi.GetSnapshotRulesCommand(DefaultRpcClient),
+                RpcApi.GetSnapshotsCommand(DefaultRpcClient, { limit: SnapshotsListLimit }),
+            ]);
+            store.set(this.rules, rulesData.rules ?? []);
+            store.set(this.snapshots, snapshotsData.snapshots ?? []);
+        } catch (error) {
+            if (!background) {
+                AppModel.showToast("Snapshots", `Could not load snapshots: ${error.message ?? error}`, 5000);
+            }
+        } finally {
+            store.set(this.isLoading, false);
+        }
+    }
+
+    // creates (empty ruleid) or updates a rule, returns false if the monitor rejected it
+    async saveRule(rule: SnapshotRule): Promise<boolean> {
+        try {
+            await RpcApi.UpdateSnapshotRuleCommand(DefaultRpcClient, rule);
+        } catch (error) {
+            AppModel.showToast("Invalid Snapshot Rule", `${error.message ?? error}`, 5000);
+            return false;
+        }
+        await this.loadData();
+        return true;
+    }
+
+    async setRuleDisabled(rule: SnapshotRule, disabled: boolean): Promise<void> {
+        await this.saveRule({ ...rule, disabled });
+    }
+
+    async deleteRule(ruleId: string): Promise<void> {
+        try {
+            await RpcApi.DeleteSnapshotRuleCommand(DefaultRpcClient, { ruleid: ruleId });
+        } catch (error) {
+            AppModel.showToast("Snapshots", `Could not delete the rule: ${error.message ?? error}`, 5000);
+        }
+        await this.loadData();
+    }
+
+    async runRule(rule: SnapshotRule): Promise<void> {
+        const store = getDefaultStore();
+        store.set(this.runningRuleIds, new Set([...store.get(this.runningRuleIds), rule.ruleid]));
+        try {
+            const result = await RpcApi.RunSnapshotRuleCommand(
+                DefaultRpcClient,
+                { ruleid: rule.ruleid },
+                { timeout: RunRuleTimeoutMs }
+            );
+            const count = result.snapshots?.length ?? 0;
+            if (count === 0) {
+                AppModel.showToast(
+                    rule.name,
+                    "Nothing to capture (no running app runs matched, or no new log lines)",
+                    5000
+                );
+            }
+        } catch (error) {
+            AppModel.showToast(rule.name, `Snapshot failed: ${error.message ?? error}`, 5000);
+        } finally {
+            const running = new Set(store.get(this.runningRuleIds));
+            running.delete(rule.ruleid);
+            store.set(this.runningRuleIds, running);
+        }
+        await this.loadData();
+    }
+
+    async deleteSnapshot(snapshotId: string): Promise<void> {
+        try {
+            await RpcApi.DeleteSnapshotCommand(DefaultRpcClient, { snapshotid: snapshotId });
+        } catch (error) {
+            AppModel.showToast("Snapshots", `Could not delete the snapshot: ${error.message ?? error}`, 5000);
+        }
+        await this.loadData();
+    }
+}
+
+const model = new SnapshotsModel();
+export { model as SnapshotsModel };
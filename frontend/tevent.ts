@@ -10,13 +10,31 @@ let lastTabEvent: { tab: string; timestamp: number } | null = null;
 // Minimum time between tab events in milliseconds (to prevent duplicates from React dev mode and HMR)
 const MIN_EVENT_INTERVAL = 1000;
 
+// Minimum time a search term must stay unchanged before a search event is sent (so typing doesn't send an event per keystroke)
+const SEARCH_EVENT_DELAY = 2000;
+
+// Pending and last sent search events, per search kind (logs, goroutine, watch)
+const searchEventTimeouts: { [kind: string]: ReturnType<typeof setTimeout> } = {};
+const lastSearchEvents: { [kind: string]: string } = {};
+
+/**
+ * Record an event in the local session timeline (the server ignores it unless the sessiontimeline setting is on)
+ * Session events are never sent with the telemetry
+ */
+function recordSessionEvent(event: string, data: Omit<SessionEvent, "ts" | "event" | "appname">): void {
+    RpcApi.RecordSessionEventCommand(DefaultRpcClient, { ts: 0, event, ...data }).catch((err: Error) => {
+        console.error("Failed to record session event:", err);
+    });
+}
+
 /**
  * Send a frontend:tab event when a tab is selected
  * This function includes debouncing to prevent duplicate events from React dev mode and HMR
  *
  * @param tabName The name of the selected tab (logs, goroutines, watches, or runtimestats)
+ * @param appRunId The selected app run (only recorded in the local session timeline)
  */
-export function sendTabEvent(tabName: string): void {
+export function sendTabEvent(tabName: string, appRunId?: string): void {
     // Skip if RPC client is not initialized
     if (!DefaultRpcClient) {
         return;
@@ -46,6 +64,7 @@ export function sendTabEvent(tabName: string): void {
     RpcApi.SendTEventFeCommand(DefaultRpcClient, teventData).catch((err: Error) => {
         console.error("Failed to send tab event:", err);
     });
+    recordSessionEvent("frontend:tab", { apprunid: appRunId, tab: tabName });
 }
 
 /**
@@ -89,3 +108,41 @@ export function sendHomepageEvent(): void {
         console.error("Failed to send homepage event:", err);
     });
 }
+
+/**
+ * Record a frontend:selectapprun event in the local session timeline when an app run is selected
+ *
+ * @param appRunId The selected app run
+ */
+export function sendSelectAppRunEvent(appRunId: string): void {
+    // Skip if RPC client is not initialized
+    if (!DefaultRpcClient) {
+        return;
+    }
+    recordSessionEvent("frontend:selectapprun", { apprunid: appRunId });
+}
+
+/**
+ * Record a frontend:search:[kind] event in the local session timeline once a search term has been unchanged
+ * for SEARCH_EVENT_DELAY. Empty search terms and repeated searches (refreshes) are skipped
+ *
+ * @param kind The kind of search (logs, goroutine, or watch)
+ * @param appRunId The searched app run
+ * @param searchTerm The search term
+ */
+export function sendSearchEvent(kind: string, appRunId: string, searchTerm: string): void {
+    clearTimeout(searchEventTimeouts[kind]);
+    searchTerm = (searchTerm ?? "").trim();
+    const searchKey = appRunId + "\x00" + searchTerm;
+    if (searchTerm === "" || lastSearchEvents[kind] === searchKey) {
+        return;
+    }
+    searchEventTimeouts[kind] = setTimeout(() => {
+        // Skip if RPC client is not initialized
+        if (!DefaultRpcClient) {
+            return;
+        }
+        lastSearchEvents[kind] = searchKey;
+        recordSessionEvent(`frontend:search:${kind}`, { apprunid: appRunId, searchterm: searchTerm });
+    }, SEARCH_EVENT_DELAY);
+}
@@ -5,6 +5,55 @@
 
 declare global {
 
+    // rpctypes.AppLifecycleEvent
+    type AppLifecycleEvent = {
+        apprunid: string;
+        event: string;
+        ts: number;
+        status: string;
+        exitcode?: number;
+        signal?: string;
+    };
+
+    // rpctypes.AppRunCompareSample
+    type AppRunCompareSample = {
+        offsetms: number;
+        goroutinecount: number;
+        heapalloc: number;
+        sys: number;
+        numgc: number;
+    };
+
+    // rpctypes.AppRunComparison
+    type AppRunComparison = {
+        apprunid: string;
+        starttime: number;
+        status: string;
+        buildinfo?: BuildInfoData;
+        samples: AppRunCompareSample[];
+        maxgoroutinecount: number;
+        maxheapalloc: number;
+        goroutineslope: number;
+        heapallocslope: number;
+    };
+
+    // rpctypes.AppRunDiskUsage
+    type AppRunDiskUsage = {
+        apprunid: string;
+        appname: string;
+        status: string;
+        isrunning: boolean;
+        membytes: number;
+        diskbytes: number;
+        stores: AppRunStoreUsage[];
+    };
+
+    // rpctypes.AppRunExecTracesData
+    type AppRunExecTracesData = {
+        apprunid: string;
+        traces: ExecTraceInfo[];
+    };
+
     // rpctypes.AppRunGoRoutinesByIdsRequest
     type AppRunGoRoutinesByIdsRequest = {
         apprunid: string;
@@ -19,6 +68,12 @@ declare global {
         goroutines: ParsedGoRoutine[];
     };
 
+    // rpctypes.AppRunGoroutineDumpsData
+    type AppRunGoroutineDumpsData = {
+        apprunid: string;
+        dumps: GoroutineDumpInfo[];
+    };
+
     // rpctypes.AppRunInfo
     type AppRunInfo = {
         apprunid: string;
@@ -33,11 +88,31 @@ declare global {
         numoutriggoroutines: number;
         numactivewatches: number;
         numtotalwatches: number;
+        numpanics?: number;
         lastmodtime: number;
         buildinfo?: BuildInfoData;
         modulename?: string;
         executable?: string;
         outrigsdkversion?: string;
+        meta?: {[key: string]: string};
+        imported?: boolean;
+        transportstats?: TransportStats;
+        monitor?: string;
+        clockskewms?: number;
+        numresyncs?: number;
+    };
+
+    // rpctypes.AppRunPanicsData
+    type AppRunPanicsData = {
+        apprunid: string;
+        appname: string;
+        panics: PanicData[];
+    };
+
+    // rpctypes.AppRunPresenceData
+    type AppRunPresenceData = {
+        apprunid: string;
+        users: PresenceUser[];
     };
 
     // rpctypes.AppRunRequest
@@ -56,9 +131,27 @@ declare global {
         stats: RuntimeStatData[];
     };
 
+    // rpctypes.AppRunStoreUsage
+    type AppRunStoreUsage = {
+        store: string;
+        items: number;
+        membytes: number;
+        diskbytes?: number;
+        cancompact?: boolean;
+        candrop?: boolean;
+    };
+
+    // rpctypes.AppRunTimelineData
+    type AppRunTimelineData = {
+        apprunid: string;
+        appname: string;
+        events: AppLifecycleEvent[];
+    };
+
     // rpctypes.AppRunUpdatesRequest
     type AppRunUpdatesRequest = {
         since: number;
+        meta?: {[key: string]: string};
     };
 
     // rpctypes.AppRunWatchesByIdsRequest
@@ -79,12 +172,65 @@ declare global {
         appruns: AppRunInfo[];
     };
 
+    // rpctypes.AuditLogData
+    type AuditLogData = {
+        entries: AuditLogEntry[];
+        totalcount: number;
+    };
+
+    // rpctypes.AuditLogEntry
+    type AuditLogEntry = {
+        id: number;
+        ts: number;
+        source: string;
+        command: string;
+        apprunid?: string;
+        params?: any;
+        error?: string;
+    };
+
+    // rpctypes.AuditLogRequest
+    type AuditLogRequest = {
+        sinceid?: number;
+        command?: string;
+        source?: string;
+        apprunid?: string;
+        limit?: number;
+    };
+
+    // rpctypes.BroadcastRequest
+    type BroadcastRequest = {
+        route: string;
+        command: string;
+        data?: any;
+    };
+
+    // rpctypes.BroadcastResponse
+    type BroadcastResponse = {
+        results: BroadcastRouteResult[];
+    };
+
+    // rpctypes.BroadcastRouteResult
+    type BroadcastRouteResult = {
+        route: string;
+        apprunid?: string;
+        data?: any;
+        error?: string;
+    };
+
+    // rpctypes.BrowserTabFocusData
+    type BrowserTabFocusData = {
+        apprunid: string;
+        focus?: PresenceFocus;
+    };
+
     // rpctypes.BrowserTabUrlData
     type BrowserTabUrlData = {
         url: string;
         apprunid?: string;
         focused: boolean;
         autofollow: boolean;
+        username?: string;
     };
 
     // rpctypes.BuildInfoData
@@ -95,11 +241,87 @@ declare global {
         settings?: {[key: string]: string};
     };
 
+    // rpctypes.CaptureCPUProfileRequest
+    type CaptureCPUProfileRequest = {
+        apprunid: string;
+        durationsec?: number;
+    };
+
+    // rpctypes.CaptureCPUProfileResponse
+    type CaptureCPUProfileResponse = {
+        apprunid: string;
+        profileid: string;
+        ts: number;
+        durationms: number;
+        size: number;
+        triggeredby: string;
+        downloadurl: string;
+    };
+
+    // rpctypes.CaptureExecTraceRequest
+    type CaptureExecTraceRequest = {
+        apprunid: string;
+        durationsec?: number;
+    };
+
+    // rpctypes.CollectorAdminRequest
+    type CollectorAdminRequest = {
+        apprunid: string;
+        collector: string;
+        action?: string;
+        settings?: {[key: string]: any};
+    };
+
+    // config.CollectorConfig
+    type CollectorConfig = {
+        logs: LogProcessorConfig;
+        runtimestats: RuntimeStatsConfig;
+        watch: WatchConfig;
+        goroutine: GoRoutineConfig;
+        dbpool: DBPoolConfig;
+        fdstats: FDStatsConfig;
+        schedstats: SchedStatsConfig;
+    };
+
+    // ds.CollectorOverhead
+    type CollectorOverhead = {
+        intervalms: number;
+        numcycles: number;
+        cpumeasured: boolean;
+        lastcpuus: number;
+        totalcpuus: number;
+        lastallocbytes: number;
+        totalallocbytes: number;
+    };
+
+    // rpctypes.CollectorOverheadInfo
+    type CollectorOverheadInfo = {
+        name: string;
+        running: boolean;
+        overhead: CollectorOverhead;
+        avgcpuus: number;
+        avgallocbytes: number;
+        cpupercent: number;
+        allocbytesrate: number;
+    };
+
+    // ds.CollectorStatus
+    type CollectorStatus = {
+        running: boolean;
+        info?: string;
+        warnings?: string[];
+        errors?: string[];
+        collectduration?: number;
+        overhead?: CollectorOverhead;
+    };
+
     // rpctypes.CombinedWatchSample
     type CombinedWatchSample = {
         watchnum: number;
         decl: WatchDecl;
         sample: WatchSample;
+        rate?: number;
+        ratehistory?: number[];
     };
 
     // rpctypes.CommandMessageData
@@ -107,6 +329,101 @@ declare global {
         message: string;
     };
 
+    // rpctypes.CompactAppRunStoreRequest
+    type CompactAppRunStoreRequest = {
+        apprunid: string;
+        store: string;
+        drop?: boolean;
+    };
+
+    // rpctypes.CompactAppRunStoreResult
+    type CompactAppRunStoreResult = {
+        apprunid: string;
+        store: string;
+        itemsremoved: number;
+        membytesfreed: number;
+        diskbytesfreed: number;
+    };
+
+    // rpctypes.CompareAppRunsData
+    type CompareAppRunsData = {
+        appname: string;
+        intervalms: number;
+        runs: AppRunComparison[];
+    };
+
+    // rpctypes.CompareAppRunsRequest
+    type CompareAppRunsRequest = {
+        appname: string;
+        apprunids?: string[];
+        intervalms?: number;
+    };
+
+    // config.Config
+    type Config = {
+        quiet: boolean;
+        appname: string;
+        domainsocketpath: string;
+        tcpaddr: string;
+        pipename?: string;
+        transport?: string;
+        disabledockerprobe: boolean;
+        modulename: string;
+        connectoninit: boolean;
+        remote?: RemoteConfig;
+        collectors: CollectorConfig;
+        redact?: RedactConfig;
+        runmode?: RunModeConfig;
+        exec?: ExecConfig;
+    };
+
+    // rpctypes.ConfigChangedEvent
+    type ConfigChangedEvent = {
+        ts: number;
+        path: string;
+        changed?: string[];
+        restartrequired?: string[];
+        error?: string;
+    };
+
+    // config.DBPoolConfig
+    type DBPoolConfig = {
+        enabled: boolean;
+    };
+
+    // rpctypes.DiskUsageData
+    type DiskUsageData = {
+        datadir: string;
+        datadirbytes: number;
+        logbufferbytes: number;
+        snapshotsbytes?: number;
+        teventsbytes: number;
+        packetrecorddir?: string;
+        packetrecordbytes?: number;
+        freebytes?: number;
+        totalbytes?: number;
+        maxdatadirbytes?: number;
+        warning?: string;
+        appruns: AppRunDiskUsage[];
+    };
+
+    // rpctypes.DiskUsageWarningEvent
+    type DiskUsageWarningEvent = {
+        ts: number;
+        datadirbytes: number;
+        maxdatadirbytes: number;
+        warning?: string;
+    };
+
+    // rpctypes.EffectiveConfigData
+    type EffectiveConfigData = {
+        apprunid?: string;
+        appname?: string;
+        sdk?: SDKEffectiveConfig;
+        sdkerror?: string;
+        monitor: MonitorEffectiveConfig;
+    };
+
     // rpctypes.EventCommonFields
     type EventCommonFields = {
         scopes?: string[];
@@ -123,11 +440,119 @@ declare global {
 
     // EventType union (rpctypes.EventToTypeMap)
     type EventType = 
+        | (EventCommonFields & { event: "app:connected"; data: AppLifecycleEvent })
+        | (EventCommonFields & { event: "app:crashed"; data: AppLifecycleEvent })
+        | (EventCommonFields & { event: "app:disconnected"; data: AppLifecycleEvent })
+        | (EventCommonFields & { event: "app:logalert"; data: LogAlertEvent })
+        | (EventCommonFields & { event: "app:presence"; data: AppRunPresenceData })
         | (EventCommonFields & { event: "app:statusupdate"; data: StatusUpdateData })
+        | (EventCommonFields & { event: "audit:log"; data: AuditLogEntry })
         | (EventCommonFields & { event: "route:down"; data?: null })
         | (EventCommonFields & { event: "route:up"; data?: null })
+        | (EventCommonFields & { event: "server:configchanged"; data: ConfigChangedEvent })
+        | (EventCommonFields & { event: "server:diskusagewarning"; data: DiskUsageWarningEvent })
+        | (EventCommonFields & { event: "server:snapshot"; data: SnapshotInfo })
     ;
 
+    // config.ExecConfig
+    type ExecConfig = {
+        entry?: string;
+        buildflags?: string[];
+        args?: string[];
+        envfile?: string[];
+        env?: {[key: string]: string};
+        cwd?: string;
+        rawcmd?: string;
+        rawcmdshell?: string;
+        notoolchainpin?: boolean;
+    };
+
+    // rpctypes.ExecTraceInfo
+    type ExecTraceInfo = {
+        apprunid: string;
+        traceid: string;
+        ts: number;
+        durationms: number;
+        size: number;
+        truncated?: boolean;
+        triggeredby: string;
+        downloadurl: string;
+    };
+
+    // rpctypes.ExportAppRunResponse
+    type ExportAppRunResponse = {
+        apprunid: string;
+        exportid: string;
+        filename: string;
+        size: number;
+        numlogs: number;
+        numgoroutinesamples: number;
+        numwatchsamples: number;
+        numruntimestats: number;
+        numpanics: number;
+        downloadurl: string;
+    };
+
+    // config.FDStatsConfig
+    type FDStatsConfig = {
+        enabled: boolean;
+    };
+
+    // ds.FDStatsInfo
+    type FDStatsInfo = {
+        ts: number;
+        openfds: number;
+        maxfds?: number;
+        sockets: number;
+        listening: number;
+        established: number;
+    };
+
+    // rpctypes.ForceGoroutineDumpRequest
+    type ForceGoroutineDumpRequest = {
+        apprunid: string;
+        label?: string;
+    };
+
+    // rpctypes.GlobalSearchLine
+    type GlobalSearchLine = {
+        apprunid: string;
+        appname: string;
+        line: LogLine;
+        matchspans?: SearchMatchSpan[];
+    };
+
+    // rpctypes.GlobalSearchRequest
+    type GlobalSearchRequest = {
+        searchterm: string;
+        apprunids?: string[];
+        appname?: string;
+        maxresults?: number;
+    };
+
+    // rpctypes.GlobalSearchResultData
+    type GlobalSearchResultData = {
+        lines: GlobalSearchLine[];
+        runs: GlobalSearchRunResult[];
+        matchcount: number;
+        searchedcount: number;
+        truncated?: boolean;
+        errorspans?: SearchErrorSpan[];
+        canceled?: boolean;
+    };
+
+    // rpctypes.GlobalSearchRunResult
+    type GlobalSearchRunResult = {
+        apprunid: string;
+        appname: string;
+        status: string;
+        starttime: number;
+        matchcount: number;
+        searchedcount: number;
+        totalcount: number;
+        error?: string;
+    };
+
     // rpctypes.GoRoutineActiveCount
     type GoRoutineActiveCount = {
         count: number;
@@ -135,6 +560,66 @@ declare global {
         ts: number;
     };
 
+    // rpctypes.GoRoutineChurnData
+    type GoRoutineChurnData = {
+        apprunid: string;
+        appname: string;
+        sites: GoRoutineChurnSite[];
+    };
+
+    // rpctypes.GoRoutineChurnSite
+    type GoRoutineChurnSite = {
+        callsite: string;
+        func?: string;
+        name?: string;
+        started: number;
+        finished: number;
+        shortlived: number;
+        avgruntimems?: number;
+        startrate: number;
+        peakstartrate: number;
+        lastts: number;
+    };
+
+    // config.GoRoutineConfig
+    type GoRoutineConfig = {
+        enabled: boolean;
+        maxstackdepth?: number;
+        dropframeprefixes?: string[];
+        inheritparentname: boolean;
+        inheritnameseparator?: string;
+    };
+
+    // rpctypes.GoRoutineGroup
+    type GoRoutineGroup = {
+        key: string;
+        pool?: string;
+        package?: string;
+        funcname?: string;
+        createdbyframe?: StackFrame;
+        csnum?: number;
+        count: number;
+        statecounts: {[key: string]: number};
+        goids: number[];
+        representativeid: number;
+    };
+
+    // rpctypes.GoRoutineLogsData
+    type GoRoutineLogsData = {
+        apprunid: string;
+        goid: number;
+        lines: LogLine[];
+        totalcount: number;
+        searchterm: string;
+    };
+
+    // rpctypes.GoRoutineLogsRequest
+    type GoRoutineLogsRequest = {
+        apprunid: string;
+        goid: number;
+        maxlines?: number;
+    };
+
     // rpctypes.GoRoutineSearchRequestData
     type GoRoutineSearchRequestData = {
         apprunid: string;
@@ -143,6 +628,7 @@ declare global {
         timestamp?: number;
         showoutrig: boolean;
         activeonly: boolean;
+        aggregate?: boolean;
     };
 
     // rpctypes.GoRoutineSearchResultData
@@ -154,6 +640,28 @@ declare global {
         results: number[];
         errorspans?: SearchErrorSpan[];
         effectivesearchtimestamp: number;
+        groups?: GoRoutineGroup[];
+    };
+
+    // rpctypes.GoRoutineStateHistogramRequest
+    type GoRoutineStateHistogramRequest = {
+        apprunid: string;
+        sincetickidx: number;
+        showoutrig: boolean;
+    };
+
+    // rpctypes.GoRoutineStateHistogramResponse
+    type GoRoutineStateHistogramResponse = {
+        ticks: GoRoutineStateTick[];
+        states: string[];
+        lasttick: Tick;
+    };
+
+    // rpctypes.GoRoutineStateTick
+    type GoRoutineStateTick = {
+        timeidx: number;
+        ts: number;
+        statecounts?: {[key: string]: number};
     };
 
     // rpctypes.GoRoutineTimeSpansRequest
@@ -178,6 +686,41 @@ declare global {
         span: TimeSpan;
     };
 
+    // rpctypes.GoroutineDumpInfo
+    type GoroutineDumpInfo = {
+        apprunid: string;
+        dumpid: string;
+        ts: number;
+        label: string;
+        numgoroutines: number;
+        triggeredby: string;
+        downloadurl: string;
+    };
+
+    // ds.JsonPatchOp
+    type JsonPatchOp = {
+        op: string;
+        path: string;
+        value?: string;
+    };
+
+    // rpctypes.LineMark
+    type LineMark = {
+        annotation?: string;
+        color?: string;
+    };
+
+    // rpctypes.LogAlertEvent
+    type LogAlertEvent = {
+        ts: number;
+        apprunid: string;
+        appname: string;
+        rulename: string;
+        search: string;
+        linenum: number;
+        msg: string;
+    };
+
     // ds.LogLine
     type LogLine = {
         linenum: number;
@@ -185,6 +728,87 @@ declare global {
         msg: string;
         source?: string;
         color: number;
+        goid?: number;
+        level?: string;
+        iscontext?: boolean;
+        repeatcount?: number;
+        lastrepeatts?: number;
+    };
+
+    // config.LogProcessorConfig
+    type LogProcessorConfig = {
+        enabled: boolean;
+        wrapstdout: boolean;
+        wrapstderr: boolean;
+        outrigpath: string;
+        additionalargs: string[];
+    };
+
+    // rpctypes.LogSearchRangeRequest
+    type LogSearchRangeRequest = {
+        widgetid: string;
+        apprunid: string;
+        searchterm: string;
+        systemquery?: string;
+        offset: number;
+        limit: number;
+        streaming: boolean;
+        contextbefore?: number;
+        contextafter?: number;
+        dedup?: boolean;
+        dedupwindowms?: number;
+    };
+
+    // rpctypes.LogSearchRangeResultData
+    type LogSearchRangeResultData = {
+        filteredcount: number;
+        searchedcount: number;
+        totalcount: number;
+        maxcount: number;
+        lines: LogLine[];
+        errorspans?: SearchErrorSpan[];
+        canceled?: boolean;
+        matchspans?: {[key: number]: SearchMatchSpan[]};
+    };
+
+    // rpctypes.LogTailData
+    type LogTailData = {
+        widgetid: string;
+        filteredcount: number;
+        searchedcount: number;
+        totalcount: number;
+        trimmedlines: number;
+        offset: number;
+        lines: LogLine[];
+        droppedlines?: number;
+        errorspans?: SearchErrorSpan[];
+        matchspans?: {[key: number]: SearchMatchSpan[]};
+    };
+
+    // rpctypes.LogTailRequest
+    type LogTailRequest = {
+        widgetid: string;
+        apprunid: string;
+        searchterm: string;
+        systemquery?: string;
+        backfill?: number;
+        contextbefore?: number;
+        contextafter?: number;
+        dedup?: boolean;
+        dedupwindowms?: number;
+    };
+
+    // rpctypes.LogTailStatus
+    type LogTailStatus = {
+        file?: string;
+        journald?: string;
+        appname?: string;
+        source: string;
+        running: boolean;
+        error?: string;
+        numlines: number;
+        numdropped?: number;
+        lastlinets?: number;
     };
 
     // rpctypes.LogWidgetAdminData
@@ -194,13 +818,30 @@ declare global {
         keepalive?: boolean;
     };
 
+    // rpctypes.MarkedLineExport
+    type MarkedLineExport = {
+        line: LogLine;
+        annotation?: string;
+        color?: string;
+    };
+
     // rpctypes.MarkedLinesData
     type MarkedLinesData = {
         widgetid: string;
         markedlines: {[key: string]: boolean};
+        ranges?: MarkedRange[];
         clear?: boolean;
     };
 
+    // rpctypes.MarkedLinesExportData
+    type MarkedLinesExportData = {
+        version: number;
+        apprunid: string;
+        appname: string;
+        exportts: number;
+        lines: MarkedLineExport[];
+    };
+
     // rpctypes.MarkedLinesRequestData
     type MarkedLinesRequestData = {
         widgetid: string;
@@ -209,6 +850,16 @@ declare global {
     // rpctypes.MarkedLinesResultData
     type MarkedLinesResultData = {
         lines: LogLine[];
+        marks?: {[key: string]: LineMark};
+    };
+
+    // rpctypes.MarkedRange
+    type MarkedRange = {
+        start: number;
+        end: number;
+        unmark?: boolean;
+        annotation?: string;
+        color?: string;
     };
 
     // ds.MemoryStatsInfo
@@ -236,17 +887,57 @@ declare global {
         totalheapobjfree: number;
     };
 
+    // rpctypes.MonitorEffectiveConfig
+    type MonitorEffectiveConfig = {
+        version: string;
+        dev?: boolean;
+        outrighome: string;
+        datadir: string;
+        configfile: string;
+        configfileexists: boolean;
+        configfilesettings?: string[];
+        configfileerror?: string;
+        remotelisten?: string;
+        logbuffersizemb: number;
+        maxrunsperapp: number;
+        maxrunage: string;
+        maxdatadirsizemb: number;
+        embedallowedorigins?: string[];
+        downstreams?: {[key: string]: string};
+        sessiontimeline?: boolean;
+        logsearchindex?: boolean;
+        logtails?: LogTailStatus[];
+        alertrules?: string[];
+    };
+
     // rpctypes.PageData
     type PageData = {
         pagenum: number;
         lines: LogLine[];
     };
 
+    // rpctypes.PanicData
+    type PanicData = {
+        goid: number;
+        ts: number;
+        name?: string;
+        tags?: string[];
+        panicval: string;
+        panictype: string;
+        recovered?: boolean;
+        rawstacktrace: string;
+        parsedframes?: StackFrame[];
+        createdbygoid?: number;
+        createdbyframe?: StackFrame;
+    };
+
     // rpctypes.ParsedGoRoutine
     type ParsedGoRoutine = {
         goid: number;
         name?: string;
         tags?: string[];
+        status?: string;
+        pool?: string;
         csnum?: number;
         activetimespan: TimeSpan;
         active: boolean;
@@ -262,6 +953,64 @@ declare global {
         parseerror?: string;
     };
 
+    // rpctypes.PresenceFocus
+    type PresenceFocus = {
+        kind: string;
+        id: string;
+        label?: string;
+    };
+
+    // rpctypes.PresenceUser
+    type PresenceUser = {
+        tabid: string;
+        username: string;
+        focused: boolean;
+        focus?: PresenceFocus;
+        updatedts: number;
+    };
+
+    // rpctypes.PruneAppRunsRequest
+    type PruneAppRunsRequest = {
+        maxrunsperapp?: number;
+        maxagems?: number;
+        dryrun?: boolean;
+    };
+
+    // rpctypes.PruneAppRunsResult
+    type PruneAppRunsResult = {
+        pruned: PrunedAppRun[];
+        dryrun?: boolean;
+    };
+
+    // rpctypes.PrunedAppRun
+    type PrunedAppRun = {
+        apprunid: string;
+        appname: string;
+        starttime: number;
+        lastmodtime: number;
+        reason: string;
+    };
+
+    // config.RedactConfig
+    type RedactConfig = {
+        patterns?: string[];
+        fields?: string[];
+        replacement?: string;
+    };
+
+    // config.RemoteConfig
+    type RemoteConfig = {
+        addr?: string;
+        tls?: boolean;
+        servername?: string;
+        cafile?: string;
+        certfile?: string;
+        keyfile?: string;
+        insecureskipverify?: boolean;
+        buffersize?: number;
+        maxbackoffms?: number;
+    };
+
     // rpc.RpcMessage
     type RpcMessage = {
         command?: string;
@@ -276,6 +1025,7 @@ declare global {
         error?: string;
         datatype?: string;
         data?: any;
+        databatch?: RpcMessage[];
     };
 
     // rpc.RpcOpts
@@ -283,6 +1033,45 @@ declare global {
         timeout?: number;
         noresponse?: boolean;
         route?: string;
+        reqid?: string;
+    };
+
+    // config.RunModeConfig
+    type RunModeConfig = {
+        sdkreplacepath?: string;
+        transformpkgs?: string[];
+    };
+
+    // rpctypes.RuntimeControlRequest
+    type RuntimeControlRequest = {
+        apprunid: string;
+        action: string;
+        path?: string;
+        gcpercent?: number;
+    };
+
+    // rpctypes.RuntimeControlResponse
+    type RuntimeControlResponse = {
+        apprunid: string;
+        triggeredby: string;
+        result: RuntimeControlResult;
+    };
+
+    // ds.RuntimeControlResult
+    type RuntimeControlResult = {
+        commandid: string;
+        action: string;
+        ts: number;
+        durationms: number;
+        error?: string;
+        path?: string;
+        filesize?: number;
+        oldgcpercent?: number;
+        newgcpercent?: number;
+        heapallocbefore: number;
+        heapallocafter: number;
+        heapreleasedbefore: number;
+        heapreleasedafter: number;
     };
 
     // rpctypes.RuntimeStatData
@@ -297,6 +1086,68 @@ declare global {
         pid: number;
         cwd: string;
         memstats: MemoryStatsInfo;
+        fdstats?: FDStatsInfo;
+        schedstats?: SchedStatsInfo;
+        allocrate?: number;
+        gcrate?: number;
+    };
+
+    // config.RuntimeStatsConfig
+    type RuntimeStatsConfig = {
+        enabled: boolean;
+    };
+
+    // ds.SDKCapabilities
+    type SDKCapabilities = {
+        platform: string;
+        compiler: string;
+        restricted?: boolean;
+        connect: boolean;
+        stdiocapture: boolean;
+        goroutinestacks: boolean;
+        fdstats: boolean;
+    };
+
+    // rpctypes.SDKEffectiveConfig
+    type SDKEffectiveConfig = {
+        sdkversion?: string;
+        source: string;
+        config: Config;
+        env?: {[key: string]: string};
+        capabilities?: SDKCapabilities;
+        collectors?: {[key: string]: CollectorStatus};
+    };
+
+    // rpctypes.SDKOverheadData
+    type SDKOverheadData = {
+        apprunid: string;
+        appname: string;
+        cpumeasured: boolean;
+        collectors: CollectorOverheadInfo[];
+        totalcpupercent: number;
+        totalallocbytesrate: number;
+    };
+
+    // config.SchedStatsConfig
+    type SchedStatsConfig = {
+        enabled: boolean;
+    };
+
+    // ds.SchedStatsInfo
+    type SchedStatsInfo = {
+        ts: number;
+        intervalms: number;
+        schedlatencyp50ns: number;
+        schedlatencyp99ns: number;
+        schedlatencymaxns: number;
+        gcpauses: number;
+        gcpausep99ns: number;
+        gcpausemaxns: number;
+        mutexwaitns: number;
+        mutexwaittotalns: number;
+        gomaxprocs: number;
+        gomaxprocschanges?: number;
+        gomaxprocschangedts?: number;
     };
 
     // rpctypes.SearchErrorSpan
@@ -306,6 +1157,14 @@ declare global {
         errormessage: string;
     };
 
+    // rpctypes.SearchMatchSpan
+    type SearchMatchSpan = {
+        field: string;
+        start: number;
+        end: number;
+        term: string;
+    };
+
     // rpctypes.SearchRequestData
     type SearchRequestData = {
         widgetid: string;
@@ -315,6 +1174,10 @@ declare global {
         pagesize: number;
         requestpages: number[];
         streaming: boolean;
+        contextbefore?: number;
+        contextafter?: number;
+        dedup?: boolean;
+        dedupwindowms?: number;
     };
 
     // rpctypes.SearchResultData
@@ -325,6 +1188,8 @@ declare global {
         maxcount: number;
         pages: PageData[];
         errorspans?: SearchErrorSpan[];
+        canceled?: boolean;
+        matchspans?: {[key: number]: SearchMatchSpan[]};
     };
 
     // rpctypes.ServerCommandMeta
@@ -332,6 +1197,120 @@ declare global {
         commandtype: string;
     };
 
+    // rpctypes.SessionEvent
+    type SessionEvent = {
+        ts: number;
+        event: string;
+        apprunid?: string;
+        appname?: string;
+        tab?: string;
+        searchterm?: string;
+    };
+
+    // rpctypes.SessionTimelineData
+    type SessionTimelineData = {
+        enabled: boolean;
+        events: SessionEvent[];
+    };
+
+    // rpctypes.SessionTimelineRequest
+    type SessionTimelineRequest = {
+        startts?: number;
+        endts?: number;
+        apprunid?: string;
+        limit?: number;
+    };
+
+    // rpctypes.SetWatchValueRequest
+    type SetWatchValueRequest = {
+        apprunid: string;
+        name: string;
+        value: string;
+    };
+
+    // rpctypes.SetWatchValueResponse
+    type SetWatchValueResponse = {
+        apprunid: string;
+        name: string;
+        triggeredby: string;
+    };
+
+    // rpctypes.SnapshotInfo
+    type SnapshotInfo = {
+        snapshotid: string;
+        ruleid: string;
+        rulename: string;
+        kind: string;
+        apprunid: string;
+        appname: string;
+        ts: number;
+        numitems: number;
+        size: number;
+        filename: string;
+        downloadurl: string;
+    };
+
+    // rpctypes.SnapshotRequest
+    type SnapshotRequest = {
+        snapshotid: string;
+    };
+
+    // rpctypes.SnapshotRule
+    type SnapshotRule = {
+        ruleid: string;
+        name: string;
+        kind: string;
+        schedule: string;
+        appname?: string;
+        searchterm?: string;
+        disabled?: boolean;
+        createdts?: number;
+        lastrunts?: number;
+        nextrunts?: number;
+        lasterror?: string;
+    };
+
+    // rpctypes.SnapshotRuleRequest
+    type SnapshotRuleRequest = {
+        ruleid: string;
+    };
+
+    // rpctypes.SnapshotRulesData
+    type SnapshotRulesData = {
+        rules: SnapshotRule[];
+    };
+
+    // rpctypes.SnapshotsData
+    type SnapshotsData = {
+        snapshots: SnapshotInfo[];
+    };
+
+    // rpctypes.SnapshotsRequest
+    type SnapshotsRequest = {
+        ruleid?: string;
+        apprunid?: string;
+        limit?: number;
+    };
+
+    // rpctypes.SourcePreviewData
+    type SourcePreviewData = {
+        filepath: string;
+        relpath?: string;
+        source: string;
+        revision?: string;
+        linenumber: number;
+        startline: number;
+        lines: string[];
+        modified?: boolean;
+    };
+
+    // rpctypes.SourcePreviewRequest
+    type SourcePreviewRequest = {
+        apprunid: string;
+        frame: StackFrame;
+        contextlines?: number;
+    };
+
     // rpctypes.StackFrame
     type StackFrame = {
         package: string;
@@ -340,6 +1319,8 @@ declare global {
         filepath: string;
         linenumber: number;
         pcoffset?: string;
+        relpath?: string;
+        vcsurl?: string;
         isimportant?: boolean;
         issys?: boolean;
     };
@@ -401,15 +1382,30 @@ declare global {
         exact?: boolean;
     };
 
+    // ds.TransportStats
+    type TransportStats = {
+        ts: number;
+        queuecap: number;
+        totaldropped: number;
+        dropped?: {[key: string]: number};
+        redacted?: number;
+    };
+
     // rpctypes.UpdateCheckData
     type UpdateCheckData = {
         newerversion: string;
         fromtrayapp?: boolean;
     };
 
+    // config.WatchConfig
+    type WatchConfig = {
+        enabled: boolean;
+    };
+
     // ds.WatchDecl
     type WatchDecl = {
         name: string;
+        group?: string;
         tags?: string[];
         newline?: string;
         watchtype: string;
@@ -417,6 +1413,16 @@ declare global {
         counter?: boolean;
         invalid?: boolean;
         unregistered?: boolean;
+        evicted?: boolean;
+        settable?: boolean;
+        unit?: string;
+        display?: string;
+    };
+
+    // ds.WatchPatch
+    type WatchPatch = {
+        ops: JsonPatchOp[];
+        crc: number;
     };
 
     // ds.WatchSample
@@ -433,6 +1439,7 @@ declare global {
         len?: number;
         fmt?: string;
         polldur?: number;
+        patch?: WatchPatch;
     };
 
     // rpctypes.WatchSearchRequestData
@@ -448,6 +1455,7 @@ declare global {
         totalcount: number;
         results: number[];
         errorspans?: SearchErrorSpan[];
+        warnings?: string[];
     };
 
 }
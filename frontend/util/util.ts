@@ -100,6 +100,22 @@ export function formatDuration(durationInSeconds: number): string {
     }
 }
 
+/**
+ * Formats a duration in nanoseconds with 3 significant figures (e.g., "850ns", "12.3µs", "4.56ms", "1.20s")
+ */
+export function formatNanos(ns: number): string {
+    if (ns < 1000) {
+        return `${Math.round(ns)}ns`;
+    }
+    if (ns < 1e6) {
+        return `${(ns / 1e3).toPrecision(3)}µs`;
+    }
+    if (ns < 1e9) {
+        return `${(ns / 1e6).toPrecision(3)}ms`;
+    }
+    return `${(ns / 1e9).toPrecision(3)}s`;
+}
+
 /**
  * Efficiently merges two arrays of the same type, replacing items in the first array with matching items from the second array,
  * and adding new items from the second array. Uses a map for O(n) time complexity.
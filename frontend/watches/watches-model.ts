@@ -4,6 +4,7 @@
 import { AppModel } from "@/appmodel";
 import { DefaultRpcClient } from "@/init";
 import { SearchStore } from "@/store/searchstore";
+import { sendSearchEvent } from "@/tevent";
 import { Atom, atom, getDefaultStore, PrimitiveAtom } from "jotai";
 import { RpcApi } from "../rpc/rpcclientapi";
 
@@ -12,6 +13,13 @@ export type SearchResultInfo = {
     searchedCount: number;
     totalCount: number;
     errorSpans?: SearchErrorSpan[];
+    warnings?: string[];
+};
+
+// A run of watches shown together, group is null for pinned and ungrouped watches
+export type WatchSection = {
+    group: string;
+    watches: CombinedWatchSample[];
 };
 
 class WatchesModel {
@@ -20,6 +28,7 @@ class WatchesModel {
     appRunWatches: PrimitiveAtom<CombinedWatchSample[]> = atom<CombinedWatchSample[]>([]);
     matchedWatchIds: PrimitiveAtom<number[]> = atom<number[]>([]);
     pinnedWatchNames: PrimitiveAtom<Record<string, boolean>> = atom<Record<string, boolean>>({});
+    collapsedWatchGroups: PrimitiveAtom<Record<string, boolean>> = atom<Record<string, boolean>>({});
     appName: string;
     searchResultInfo: PrimitiveAtom<SearchResultInfo> = atom<SearchResultInfo>({
         searchedCount: 0,
@@ -61,8 +70,9 @@ class WatchesModel {
         const appRunInfo = getDefaultStore().get(appRunInfoAtom);
         this.appName = appRunInfo?.appname || "unknown";
 
-        // Load pinned watches from localStorage
+        // Load pinned watches and collapsed groups from localStorage
         this.loadPinnedWatchesFromStorage();
+        this.loadCollapsedGroupsFromStorage();
 
         // Get search term atom from SearchStore
         this.searchTerm = SearchStore.getSearchTermAtom(this.appName, appRunId, "watches");
@@ -104,6 +114,35 @@ class WatchesModel {
         }
     }
 
+    // Load collapsed watch groups from localStorage
+    loadCollapsedGroupsFromStorage() {
+        try {
+            const stored = localStorage.getItem(`outrig:watchgroups:${this.appName}`);
+            if (stored) {
+                getDefaultStore().set(this.collapsedWatchGroups, JSON.parse(stored) as Record<string, boolean>);
+            }
+        } catch (error) {
+            console.error("Failed to load collapsed watch groups from localStorage:", error);
+        }
+    }
+
+    // Toggle the collapsed state of a watch group (see outrig.WatchGroup)
+    toggleGroupCollapsed(group: string) {
+        const store = getDefaultStore();
+        const newCollapsed = { ...store.get(this.collapsedWatchGroups) };
+        if (newCollapsed[group]) {
+            delete newCollapsed[group];
+        } else {
+            newCollapsed[group] = true;
+        }
+        store.set(this.collapsedWatchGroups, newCollapsed);
+        try {
+            localStorage.setItem(`outrig:watchgroups:${this.appName}`, JSON.stringify(newCollapsed));
+        } catch (error) {
+            console.error("Failed to save collapsed watch groups to localStorage:", error);
+        }
+    }
+
     // Page up in the content view
     pageUp() {
         if (!this.contentRef?.current) return;
@@ -175,17 +214,39 @@ class WatchesModel {
         const pinnedWatches = validWatches.filter((watch) => pinnedWatchNames[watch.decl.name]);
         const unpinnedWatches = validWatches.filter((watch) => !pinnedWatchNames[watch.decl.name]);
 
-        // Sort each group by watch name
+        // Sort pinned watches by name, unpinned watches by group (ungrouped first), then by name
         pinnedWatches.sort((a, b) => a.decl.name.localeCompare(b.decl.name));
-        unpinnedWatches.sort((a, b) => a.decl.name.localeCompare(b.decl.name));
+        unpinnedWatches.sort(
+            (a, b) =>
+                (a.decl.group ?? "").localeCompare(b.decl.group ?? "") || a.decl.name.localeCompare(b.decl.name)
+        );
 
         // Return pinned watches first, then unpinned
         return [...pinnedWatches, ...unpinnedWatches];
     });
 
+    // The filtered watches split into sections: pinned and ungrouped watches (group is null), then one section
+    // per watch group (in the order of filteredWatches)
+    watchSections: Atom<WatchSection[]> = atom((get): WatchSection[] => {
+        const watches = get(this.filteredWatches);
+        const pinnedWatchNames = get(this.pinnedWatchNames);
+        const sections: WatchSection[] = [];
+        for (const watch of watches) {
+            const group = pinnedWatchNames[watch.decl.name] ? null : (watch.decl.group ?? null);
+            const lastSection = sections[sections.length - 1];
+            if (lastSection != null && lastSection.group === group) {
+                lastSection.watches.push(watch);
+            } else {
+                sections.push({ group, watches: [watch] });
+            }
+        }
+        return sections;
+    });
+
     // Search for watches matching the search term
     async searchWatches(searchTerm: string) {
         const store = getDefaultStore();
+        sendSearchEvent("watch", this.appRunId, searchTerm);
         const searchId = crypto.randomUUID();
         this.currentSearchId = searchId;
 
@@ -206,6 +267,7 @@ class WatchesModel {
                 searchedCount: searchResult.searchedcount,
                 totalCount: searchResult.totalcount,
                 errorSpans: searchResult.errorspans || [],
+                warnings: searchResult.warnings || [],
             });
 
             // Store the matched watch IDs
@@ -278,6 +340,22 @@ class WatchesModel {
         }
     }
 
+    // Set the value of a settable watch (value is JSON for the setter's argument type), returns an error message or null
+    async setWatchValue(name: string, value: string): Promise<string> {
+        try {
+            await RpcApi.SetWatchValueCommand(DefaultRpcClient, {
+                apprunid: this.appRunId,
+                name: name,
+                value: value,
+            });
+        } catch (error) {
+            return error.message;
+        }
+        // polling watches show the new value after the next collection
+        setTimeout(() => this.quietRefresh(true), 1000);
+        return null;
+    }
+
     // Refresh watches with a minimum time to show the refreshing state
     async refresh() {
         const store = getDefaultStore();
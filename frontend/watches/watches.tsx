@@ -12,13 +12,13 @@ import { sendClickEvent } from "@/tevent";
 import { EmptyMessageDelayMs } from "@/util/constants";
 import { useOutrigModel } from "@/util/hooks";
 import { checkKeyPressed } from "@/util/keyutil";
-import { prettyPrintGoFmt, prettyPrintJson } from "@/util/util";
+import { formatDuration, formatMemorySize, formatNanos, prettyPrintGoFmt, prettyPrintJson } from "@/util/util";
 import { useAtom, useAtomValue } from "jotai";
-import { Pin } from "lucide-react";
+import { ChevronDown, ChevronRight, Pencil, Pin } from "lucide-react";
 import React, { useEffect, useRef, useState } from "react";
 import { NoWatchesMessage } from "./nowatchmessage";
 import { WatchVal } from "./watch-val";
-import { WatchesModel } from "./watches-model";
+import { WatchesModel, WatchSection } from "./watches-model";
 
 // Go reflect.Kind constants
 enum Kind {
@@ -56,6 +56,75 @@ function kindToString(kind: Kind): string {
     return Kind[kind] || "Unknown";
 }
 
+const NumericKinds = new Set<Kind>([
+    Kind.Int,
+    Kind.Int8,
+    Kind.Int16,
+    Kind.Int32,
+    Kind.Int64,
+    Kind.Uint,
+    Kind.Uint8,
+    Kind.Uint16,
+    Kind.Uint32,
+    Kind.Uint64,
+    Kind.Uintptr,
+    Kind.Float32,
+    Kind.Float64,
+]);
+
+// Nanoseconds per duration unit (see WithUnit in the SDK)
+const DurationUnitNanos: Record<string, number> = { ns: 1, us: 1e3, ms: 1e6, s: 1e9 };
+
+// Format a number by its watch unit (e.g. "1.50 MB", "12.3ms", "42%", "7 req")
+function formatUnitValue(num: number, unit: string): string {
+    if (num < 0) {
+        return "-" + formatUnitValue(-num, unit);
+    }
+    if (unit === "bytes") {
+        if (num < 1024) {
+            return `${Math.round(num)} B`;
+        }
+        const mem = formatMemorySize(num);
+        return `${mem.memstr} ${mem.memunit}`;
+    }
+    if (unit in DurationUnitNanos) {
+        const ns = num * DurationUnitNanos[unit];
+        return ns < 60e9 ? formatNanos(ns) : formatDuration(Math.round(ns / 1e9));
+    }
+    const numStr = Number.isInteger(num) ? String(num) : String(Number(num.toPrecision(3)));
+    if (unit === "percent") {
+        return `${numStr}%`;
+    }
+    return unit ? `${numStr} ${unit}` : numStr;
+}
+
+interface RateSparklineProps {
+    rates: number[];
+}
+
+// Sparkline of the recent rates of a "rate" watch
+const RateSparkline: React.FC<RateSparklineProps> = ({ rates }) => {
+    if (rates == null || rates.length < 2) {
+        return null;
+    }
+    const minRate = Math.min(0, ...rates);
+    const range = Math.max(...rates) - minRate || 1;
+    const points = rates
+        .map((rate, idx) => `${(idx / (rates.length - 1)) * 100},${20 - ((rate - minRate) / range) * 20}`)
+        .join(" ");
+    return (
+        <svg className="w-24 h-5" viewBox="0 0 100 20" preserveAspectRatio="none">
+            <polyline
+                points={points}
+                fill="none"
+                stroke="var(--color-accent)"
+                strokeWidth="1"
+                vectorEffect="non-scaling-stroke"
+            />
+        </svg>
+    );
+};
+
 // Individual watch view component
 interface WatchViewProps {
     watch: CombinedWatchSample;
@@ -65,15 +134,34 @@ interface WatchViewProps {
 // Component to display watch value and related information
 interface WatchValueDisplayProps {
     sample: WatchSample;
+    decl: WatchDecl;
+    rate?: number;
+    rateHistory?: number[];
 }
 
-const WatchValueDisplay: React.FC<WatchValueDisplayProps> = ({ sample }) => {
+const WatchValueDisplay: React.FC<WatchValueDisplayProps> = ({ sample, decl, rate, rateHistory }) => {
+    const isNumeric = NumericKinds.has(sample.kind) && sample.val && !isNaN(Number(sample.val));
+    const formatUnit = isNumeric && decl.unit && decl.display !== "raw";
+
+    const formatRate = (rate: number) =>
+        decl.display !== "raw" ? formatUnitValue(rate, decl.unit) : String(Number(rate.toPrecision(3)));
+
     // Format the watch value for display
     const formatValue = () => {
         if (sample.error) {
             return <WatchVal content={sample.error} className="text-error" tooltipText="Copy error message" />;
         }
 
+        if (formatUnit) {
+            return (
+                <WatchVal
+                    content={formatUnitValue(Number(sample.val), decl.unit)}
+                    tooltipText="Copy value"
+                    tag={sample.val}
+                />
+            );
+        }
+
         if (sample.val) {
             // Format based on the sample fmt field
             if (sample.fmt === "json") {
@@ -91,6 +179,12 @@ const WatchValueDisplay: React.FC<WatchValueDisplayProps> = ({ sample }) => {
     return (
         <>
             <div className="text-sm text-primary pb-2">{formatValue()}</div>
+            {rate != null && !sample.error && (
+                <div className="pb-2 flex items-center gap-2 text-xs text-muted">
+                    <span>Rate: {formatRate(rate)}/s</span>
+                    <RateSparkline rates={rateHistory} />
+                </div>
+            )}
             {(sample.len != null || sample.cap != null) && (
                 <div className="pb-2 flex gap-3">
                     {sample.len != null && <span className="text-xs text-muted">Length: {sample.len}</span>}
@@ -101,16 +195,86 @@ const WatchValueDisplay: React.FC<WatchValueDisplayProps> = ({ sample }) => {
     );
 };
 
+interface WatchValueEditorProps {
+    watch: CombinedWatchSample;
+    model: WatchesModel;
+    onClose: () => void;
+}
+
+// Inline editor for settable watches, the value is sent to the app's setter
+const WatchValueEditor: React.FC<WatchValueEditorProps> = ({ watch, model, onClose }) => {
+    const [value, setValue] = useState(watch.sample.val ?? "");
+    const [error, setError] = useState<string>(null);
+    const [saving, setSaving] = useState(false);
+
+    const handleSave = async () => {
+        setSaving(true);
+        const errMsg = await model.setWatchValue(watch.decl.name, value);
+        setSaving(false);
+        if (errMsg) {
+            setError(errMsg);
+            return;
+        }
+        onClose();
+    };
+
+    const handleKeyDown = (e: React.KeyboardEvent<HTMLInputElement>) => {
+        if (e.key === "Enter") {
+            e.preventDefault();
+            handleSave();
+        } else if (e.key === "Escape") {
+            e.preventDefault();
+            onClose();
+        }
+    };
+
+    return (
+        <div className="pb-2">
+            <div className="flex items-center gap-2">
+                <input
+                    type="text"
+                    autoFocus
+                    value={value}
+                    disabled={saving}
+                    onChange={(e) => setValue(e.target.value)}
+                    onKeyDown={handleKeyDown}
+                    placeholder='JSON value (e.g. true, 42, "debug")'
+                    className="flex-grow px-2 py-1 text-sm font-mono bg-panel border border-border rounded focus:outline-none focus:border-primary/50"
+                />
+                <button
+                    onClick={handleSave}
+                    disabled={saving}
+                    className="px-2 py-1 rounded text-xs cursor-pointer border border-border text-primary hover:bg-buttonhover"
+                >
+                    Set
+                </button>
+                <button
+                    onClick={onClose}
+                    className="px-2 py-1 rounded text-xs cursor-pointer border border-border text-secondary hover:bg-buttonhover"
+                >
+                    Cancel
+                </button>
+            </div>
+            {error && <div className="pt-1 text-xs text-error">{error}</div>}
+        </div>
+    );
+};
+
 const WatchView: React.FC<WatchViewProps> = ({ watch, model }) => {
     const isPinned = useAtomValue(model.getWatchPinnedAtom(watch.decl.name));
+    const [isEditing, setIsEditing] = useState(false);
+    const canEdit = watch.decl.settable && !watch.decl.unregistered;
 
     // Get tags based on the watch declaration
     const getWatchTags = (decl: WatchDecl) => {
         const tags = [];
 
         if (decl.counter) tags.push({ label: "Counter", variant: "success" });
+        if (decl.settable) tags.push({ label: "Settable", variant: "accent" });
+        if (decl.unit) tags.push({ label: decl.unit, variant: "secondary" });
         if (decl.invalid) tags.push({ label: "Invalid", variant: "error" });
-        if (decl.unregistered) tags.push({ label: "Unregistered", variant: "warning" });
+        if (decl.evicted) tags.push({ label: "Evicted", variant: "warning" });
+        else if (decl.unregistered) tags.push({ label: "Unregistered", variant: "warning" });
 
         // Add watchtype as a tag
         if (decl.watchtype) tags.push({ label: decl.watchtype, variant: "info" });
@@ -119,6 +283,8 @@ const WatchView: React.FC<WatchViewProps> = ({ watch, model }) => {
     };
 
     const watchTags = getWatchTags(watch.decl);
+    const groupPrefix =
+        watch.decl.group && watch.decl.name.startsWith(watch.decl.group + ".") ? watch.decl.group + "." : "";
 
     const handlePinClick = () => {
         model.toggleWatchPin(watch.decl.name);
@@ -130,7 +296,10 @@ const WatchView: React.FC<WatchViewProps> = ({ watch, model }) => {
                 <div className="flex items-center gap-2">
                     <div className="relative flex items-center gap-2">
                         <TimestampDot timestamp={watch.sample.ts} />
-                        <div className="font-semibold text-primary flex-grow">{watch.decl.name}</div>
+                        <div className="font-semibold text-primary flex-grow">
+                            {groupPrefix && <span className="font-normal text-muted">{groupPrefix}</span>}
+                            {watch.decl.name.substring(groupPrefix.length)}
+                        </div>
                     </div>
                     <div className="text-sm px-2 py-0.5 rounded-md bg-secondary/10 text-secondary font-mono">
                         {watch.sample.type}
@@ -161,6 +330,16 @@ const WatchView: React.FC<WatchViewProps> = ({ watch, model }) => {
                             }
                         />
                     ))}
+                    {canEdit && (
+                        <Tooltip content="Set value">
+                            <button
+                                onClick={() => setIsEditing(!isEditing)}
+                                className="flex items-center gap-1 px-2 py-1 rounded text-xs cursor-pointer border text-secondary hover:text-primary border-border hover:border-primary/30 hover:bg-buttonhover transition-colors"
+                            >
+                                <Pencil size={14} />
+                            </button>
+                        </Tooltip>
+                    )}
                     {/* Pin button */}
                     <Tooltip content={isPinned ? "Unpin watch" : "Pin watch"}>
                         <button
@@ -177,7 +356,13 @@ const WatchView: React.FC<WatchViewProps> = ({ watch, model }) => {
                     </Tooltip>
                 </div>
             </div>
-            <WatchValueDisplay sample={watch.sample} />
+            {isEditing && <WatchValueEditor watch={watch} model={model} onClose={() => setIsEditing(false)} />}
+            <WatchValueDisplay
+                sample={watch.sample}
+                decl={watch.decl}
+                rate={watch.rate}
+                rateHistory={watch.ratehistory}
+            />
             {watch.sample.polldur != null && watch.sample.polldur > 2000 && (
                 <div className="absolute bottom-2 right-2 text-xs text-warning/80">
                     Long poll duration: {(watch.sample.polldur / 1000).toFixed(2)}ms
@@ -197,6 +382,7 @@ const WatchesFilters: React.FC<WatchesFiltersProps> = ({ model }) => {
     const searchResultInfo = useAtomValue(model.searchResultInfo);
     const resultCount = useAtomValue(model.resultCount);
     const errorSpans = searchResultInfo.errorSpans || [];
+    const warnings = searchResultInfo.warnings || [];
 
     return (
         <div className="py-1 px-1 border-b border-border">
@@ -238,10 +424,56 @@ const WatchesFilters: React.FC<WatchesFiltersProps> = ({ model }) => {
                     size={16}
                 />
             </div>
+            {warnings.length > 0 && (
+                <div className="mt-1 px-2 py-1 text-xs text-warning bg-warning/10 border border-warning/30 rounded">
+                    Watch limits reached: {warnings.join(", ")}
+                </div>
+            )}
         </div>
     );
 };
 
+// A section of the watch list, watch groups get a header that collapses them
+interface WatchSectionViewProps {
+    section: WatchSection;
+    model: WatchesModel;
+}
+
+const WatchSectionView: React.FC<WatchSectionViewProps> = ({ section, model }) => {
+    const collapsedGroups = useAtomValue(model.collapsedWatchGroups);
+    const isCollapsed = section.group != null && collapsedGroups[section.group];
+
+    return (
+        <>
+            {section.group != null && (
+                <>
+                    <div
+                        className="flex items-center gap-1.5 pl-2 pr-2 py-1 cursor-pointer select-none text-secondary hover:text-primary"
+                        onClick={() => model.toggleGroupCollapsed(section.group)}
+                    >
+                        {isCollapsed ? <ChevronRight size={16} /> : <ChevronDown size={16} />}
+                        <span className="font-semibold">{section.group}</span>
+                        <span className="text-xs text-muted">
+                            {section.watches.length} {section.watches.length === 1 ? "watch" : "watches"}
+                        </span>
+                    </div>
+                    <div className="h-px bg-border my-2 w-full"></div>
+                </>
+            )}
+            {!isCollapsed &&
+                section.watches.map((watch) => (
+                    <React.Fragment key={watch.decl.name}>
+                        <div className={section.group != null ? "pl-4" : null}>
+                            <WatchView watch={watch} model={model} />
+                        </div>
+                        {/* Add divider after each watch */}
+                        <div className="h-px bg-border my-2 w-full"></div>
+                    </React.Fragment>
+                ))}
+        </>
+    );
+};
+
 // Content component that displays the watches
 interface WatchesContentProps {
     model: WatchesModel;
@@ -249,6 +481,7 @@ interface WatchesContentProps {
 
 const WatchesContent: React.FC<WatchesContentProps> = ({ model }) => {
     const filteredWatches = useAtomValue(model.filteredWatches);
+    const sections = useAtomValue(model.watchSections);
     const isRefreshing = useAtomValue(model.isRefreshing);
     const search = useAtomValue(model.searchTerm);
     const contentRef = useRef<HTMLDivElement>(null);
@@ -290,12 +523,8 @@ const WatchesContent: React.FC<WatchesContentProps> = ({ model }) => {
                 )
             ) : (
                 <div>
-                    {filteredWatches.map((watch, index) => (
-                        <React.Fragment key={watch.decl.name}>
-                            <WatchView watch={watch} model={model} />
-                            {/* Add divider after each watch */}
-                            <div className="h-px bg-border my-2 w-full"></div>
-                        </React.Fragment>
+                    {sections.map((section, index) => (
+                        <WatchSectionView key={section.group ?? `ungrouped-${index}`} section={section} model={model} />
                     ))}
                 </div>
             )}
@@ -5,7 +5,6 @@ go 1.21
 require (
 	github.com/google/uuid v1.6.0
 	github.com/outrigdev/goid v0.3.0
+	golang.org/x/sys v0.30.0
 	golang.org/x/term v0.29.0
 )
-
-require golang.org/x/sys v0.30.0 // indirect
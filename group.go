@@ -0,0 +1,172 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package outrig
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// the kind of a group is the tag of its watch (same as watch.GroupKind_*)
+const (
+	groupKind_WaitGroup = "waitgroup"
+	groupKind_ErrGroup  = "errgroup"
+)
+
+// WaitGroup is a drop-in replacement for sync.WaitGroup that is tracked by Outrig. The group is reported as
+// a watch (tagged #waitgroup) with its counter, running members, and waiters. Goroutines started with Go are
+// named after the group, and a goroutine blocked in Wait shows "waiting on N members of waitgroup <name>"
+// in the goroutine view, which makes hung waits easy to find. The zero value is an untracked WaitGroup,
+// use NewWaitGroup to track it. Like sync.WaitGroup, a WaitGroup must not be copied after first use.
+//
+// Example:
+//
+//	wg := outrig.NewWaitGroup("fetchers")
+//	for _, url := range urls {
+//		wg.Go(func() { fetch(url) })
+//	}
+//	wg.Wait()
+type WaitGroup struct {
+	wg      sync.WaitGroup
+	tracker *groupTracker
+}
+
+// NewWaitGroup returns a WaitGroup that is tracked under name (groups with the same name share a watch)
+func NewWaitGroup(name string) *WaitGroup {
+	return &WaitGroup{tracker: makeGroupTracker(groupKind_WaitGroup, name)}
+}
+
+// Add adds delta (which may be negative) to the WaitGroup counter, like sync.WaitGroup.Add
+func (wg *WaitGroup) Add(delta int) {
+	wg.tracker.add(delta)
+	wg.wg.Add(delta)
+}
+
+// Done decrements the WaitGroup counter by one
+func (wg *WaitGroup) Done() {
+	wg.Add(-1)
+}
+
+// Wait blocks until the WaitGroup counter is zero
+func (wg *WaitGroup) Wait() {
+	wg.tracker.wait(wg.wg.Wait)
+}
+
+// Go calls f in a new goroutine that is a member of the group (it adds 1 to the counter and calls Done
+// when f returns). Panics in f are not recovered, like in a plain goroutine.
+func (wg *WaitGroup) Go(f func()) {
+	wg.Add(1)
+	wg.tracker.run(func() error {
+		f()
+		return nil
+	}, wg.Done)
+}
+
+// ErrGroup is a drop-in replacement for errgroup.Group (golang.org/x/sync/errgroup) that is tracked by
+// Outrig the same way as a WaitGroup (its watch is tagged #errgroup and also reports the member errors).
+// The zero value is a valid, untracked ErrGroup that has no limit and does not cancel on error.
+//
+// Example:
+//
+//	g, ctx := outrig.NewErrGroupWithContext(ctx, "uploaders")
+//	g.SetLimit(4)
+//	for _, file := range files {
+//		g.Go(func() error { return upload(ctx, file) })
+//	}
+//	err := g.Wait()
+type ErrGroup struct {
+	wg      WaitGroup
+	cancel  context.CancelCauseFunc
+	sem     chan struct{}
+	errOnce sync.Once
+	err     error
+}
+
+// NewErrGroup returns an ErrGroup that is tracked under name (groups with the same name share a watch)
+func NewErrGroup(name string) *ErrGroup {
+	return &ErrGroup{wg: WaitGroup{tracker: makeGroupTracker(groupKind_ErrGroup, name)}}
+}
+
+// NewErrGroupWithContext is like errgroup.WithContext, it returns a tracked ErrGroup and a context derived
+// from ctx that is canceled (with the error as the cause) the first time a member returns an error,
+// or when Wait returns, whichever occurs first.
+func NewErrGroupWithContext(ctx context.Context, name string) (*ErrGroup, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	g := &ErrGroup{
+		wg:     WaitGroup{tracker: makeGroupTracker(groupKind_ErrGroup, name)},
+		cancel: cancel,
+	}
+	return g, ctx
+}
+
+// Wait blocks until all the members have returned, then returns the first non-nil error (if any) from them
+func (g *ErrGroup) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(g.err)
+	}
+	return g.err
+}
+
+// Go calls f in a new goroutine that is a member of the group. The first member that returns a non-nil
+// error cancels the group's context (if created with NewErrGroupWithContext), its error is returned by Wait.
+// If the group has a limit, Go blocks until a new member can be added without exceeding it.
+func (g *ErrGroup) Go(f func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+	g.wg.tracker.run(g.wrapMember(f), g.done)
+}
+
+// TryGo calls f in a new goroutine only if the number of active members is below the group's limit,
+// it returns whether the goroutine was started
+func (g *ErrGroup) TryGo(f func() error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.wg.Add(1)
+	g.wg.tracker.run(g.wrapMember(f), g.done)
+	return true
+}
+
+// SetLimit limits the number of active members to at most n (a negative value means no limit).
+// The limit must not be modified while members are active.
+func (g *ErrGroup) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic(fmt.Errorf("errgroup: modify limit while %v goroutines in the group are still active", len(g.sem)))
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+func (g *ErrGroup) wrapMember(f func() error) func() error {
+	return func() error {
+		err := f()
+		if err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				if g.cancel != nil {
+					g.cancel(err)
+				}
+			})
+		}
+		return err
+	}
+}
+
+func (g *ErrGroup) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
@@ -9,12 +9,14 @@ import (
 	"log"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
@@ -84,6 +86,9 @@ const (
 	// Update check interval
 	AppcastUpdateCheckInterval = 8 * time.Hour
 	SparkleUpdateCheckInterval = 8 * time.Hour
+
+	// Max number of runs listed in an app's submenu
+	MaxTrayAppRunsPerGroup = 10
 )
 
 // LinkState represents the current state of the CLI symlink
@@ -221,13 +226,16 @@ type StatusData struct {
 	HasConnections bool             `json:"hasconnections"`
 	AppRuns        []TrayAppRunInfo `json:"appruns"`
 	Version        string           `json:"version"`
+	DiskWarning    string           `json:"diskwarning,omitempty"`
 }
 
 type TrayAppRunInfo struct {
 	AppRunId  string `json:"apprunid"`
 	AppName   string `json:"appname"`
 	IsRunning bool   `json:"isrunning"`
+	Status    string `json:"status"`
 	StartTime int64  `json:"starttime"`
+	NumPanics int    `json:"numpanics,omitempty"`
 }
 
 // AppGroup represents a group of app runs with the same app name
@@ -269,6 +277,7 @@ type ServerStatus struct {
 	HasConnections bool
 	AppRuns        []TrayAppRunInfo
 	Version        string
+	DiskWarning    string // set when the data directory is over its max size
 }
 
 func getIconTypeForStatus(status ServerStatus) string {
@@ -331,6 +340,7 @@ func getServerStatus() ServerStatus {
 		status.HasConnections = statusResp.Data.HasConnections
 		status.AppRuns = statusResp.Data.AppRuns
 		status.Version = statusResp.Data.Version
+		status.DiskWarning = statusResp.Data.DiskWarning
 
 		// Sort AppRuns by apprunid to ensure consistent ordering
 		sort.Slice(status.AppRuns, func(i, j int) bool {
@@ -356,6 +366,7 @@ func updateServerStatus(serverStatus ServerStatus) {
 
 	iconType := getIconTypeForStatus(serverStatus)
 	updateIcon(iconType)
+	notifyStatusChanges(lastServerStatus, serverStatus)
 
 	if serverStatus.Running {
 		serverStartOnce.Do(func() {
@@ -557,6 +568,10 @@ func rebuildMenu(status ServerStatus) {
 		mNotRunning := systray.AddMenuItem("Outrig Server Not Running", "")
 		mNotRunning.Disable()
 	}
+	if status.Running && status.DiskWarning != "" {
+		mDiskWarning := systray.AddMenuItem("⚠ "+status.DiskWarning, "Clear or compact old app runs to free space")
+		mDiskWarning.Disable()
+	}
 
 	systray.AddSeparator()
 
@@ -569,38 +584,29 @@ func rebuildMenu(status ServerStatus) {
 		// Group and sort app runs
 		appGroups := groupAppRuns(appRuns)
 
-		// Add menu items for each app group
+		// Add a submenu for each app group, listing its runs (running first, then newest)
 		for _, group := range appGroups {
 			topRun := group.GetTopAppRun()
 
-			// Create menu item text
-			var menuText string
-			var iconType string
-
+			iconType := IconTypeAppStopped
 			if topRun.IsRunning {
-				menuText = group.AppName
 				iconType = IconTypeAppRunning
-			} else {
-				menuText = group.AppName
-				iconType = IconTypeAppStopped
 			}
-
-			// Add menu item
-			menuItem := systray.AddMenuItem(menuText, fmt.Sprintf("Open '%s' App Run in the Outrig web interface", group.AppName))
-
-			// Set the icon for the menu item
-			menuItem.SetTemplateIcon(iconDataMap[iconType], iconDataMap[iconType])
-
-			// Set up click handler
-			go func(appRunId string) {
-				for range menuItem.ClickedCh {
-					url := fmt.Sprintf("http://localhost:5005/?appRunId=%s&tab=logs", appRunId)
-					err := utilfn.LaunchUrl(url)
-					if err != nil {
-						log.Printf("Error opening browser: %v", err)
-					}
+			groupItem := systray.AddMenuItem(group.AppName, fmt.Sprintf("App Runs for '%s'", group.AppName))
+			groupItem.SetTemplateIcon(iconDataMap[iconType], iconDataMap[iconType])
+
+			for idx, appRun := range group.AppRuns {
+				if idx >= MaxTrayAppRunsPerGroup {
+					moreItem := groupItem.AddSubMenuItem(fmt.Sprintf("%d more runs...", len(group.AppRuns)-idx), "Open Outrig to see all app runs")
+					go func() {
+						for range moreItem.ClickedCh {
+							launchOutrigUrl("http://localhost:5005")
+						}
+					}()
+					break
 				}
-			}(topRun.AppRunId)
+				addAppRunSubMenu(groupItem, appRun)
+			}
 		}
 	}
 
@@ -629,6 +635,9 @@ func rebuildMenu(status ServerStatus) {
 		}
 	}()
 
+	systray.AddSeparator()
+	addNotificationMenuItems()
+
 	systray.AddSeparator()
 
 	mRestart := systray.AddMenuItem("Restart Outrig Server", "")
@@ -649,6 +658,117 @@ func rebuildMenu(status ServerStatus) {
 	}()
 }
 
+// getAppRunMenuTitle returns the title of an app run submenu, e.g. "Running · Jan 2 15:04:05"
+func getAppRunMenuTitle(appRun TrayAppRunInfo) string {
+	state := "Stopped"
+	if appRun.IsRunning {
+		state = "Running"
+	}
+	startTime := time.UnixMilli(appRun.StartTime)
+	format := "15:04:05"
+	if !isToday(startTime) {
+		format = "Jan 2 15:04:05"
+	}
+	return fmt.Sprintf("%s \u00b7 %s", state, startTime.Format(format))
+}
+
+func isToday(t time.Time) bool {
+	now := time.Now()
+	return t.Year() == now.Year() && t.YearDay() == now.YearDay()
+}
+
+// addAppRunSubMenu adds a submenu with the quick actions for a single app run
+func addAppRunSubMenu(groupItem *systray.MenuItem, appRun TrayAppRunInfo) {
+	runItem := groupItem.AddSubMenuItem(getAppRunMenuTitle(appRun), appRun.AppRunId)
+	iconType := IconTypeAppStopped
+	if appRun.IsRunning {
+		iconType = IconTypeAppRunning
+	}
+	runItem.SetTemplateIcon(iconDataMap[iconType], iconDataMap[iconType])
+
+	mLogs := runItem.AddSubMenuItem("Open Logs", "Open the logs for this app run in the Outrig web interface")
+	mGoroutines := runItem.AddSubMenuItem("Open Goroutines", "Open the goroutines for this app run in the Outrig web interface")
+	mCopyId := runItem.AddSubMenuItem("Copy AppRunId", appRun.AppRunId)
+	mClear := runItem.AddSubMenuItem("Clear Run", "Remove this app run from Outrig")
+	if appRun.IsRunning {
+		mClear.Disable()
+	}
+
+	// the click channels are closed when the menu is rebuilt
+	go func() {
+		for {
+			select {
+			case _, ok := <-mLogs.ClickedCh:
+				if !ok {
+					return
+				}
+				launchOutrigUrl(getAppRunUrl(appRun.AppRunId, "logs"))
+			case _, ok := <-mGoroutines.ClickedCh:
+				if !ok {
+					return
+				}
+				launchOutrigUrl(getAppRunUrl(appRun.AppRunId, "goroutines"))
+			case _, ok := <-mCopyId.ClickedCh:
+				if !ok {
+					return
+				}
+				if err := copyToClipboard(appRun.AppRunId); err != nil {
+					log.Printf("Error copying app run id: %v", err)
+				}
+			case _, ok := <-mClear.ClickedCh:
+				if !ok {
+					return
+				}
+				if err := clearAppRun(appRun.AppRunId); err != nil {
+					log.Printf("Error clearing app run %s: %v", appRun.AppRunId, err)
+					continue
+				}
+				go updateServerStatus(getServerStatus())
+			}
+		}
+	}()
+}
+
+func getAppRunUrl(appRunId string, tab string) string {
+	return fmt.Sprintf("http://localhost:5005/?appRunId=%s&tab=%s", url.QueryEscape(appRunId), tab)
+}
+
+func launchOutrigUrl(urlStr string) {
+	err := utilfn.LaunchUrl(urlStr)
+	if err != nil {
+		log.Printf("Error opening browser: %v", err)
+	}
+}
+
+func copyToClipboard(text string) error {
+	cmd := exec.Command("pbcopy")
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// clearAppRun asks the server to remove a (non-running) app run
+func clearAppRun(appRunId string) error {
+	client := http.Client{
+		Timeout: 2 * time.Second,
+	}
+	resp, err := client.Post("http://localhost:5005/api/clearapprun?apprunid="+url.QueryEscape(appRunId), "application/json", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("error decoding response (status %d): %w", resp.StatusCode, err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%s", result.Error)
+	}
+	return nil
+}
+
 func updateCheckUpdatesMenuItem() {
 	rebuildMenuLock.Lock()
 	defer rebuildMenuLock.Unlock()
@@ -981,6 +1101,9 @@ func checkAppcastUpdates() {
 		log.Printf("No new version available")
 	}
 	appcastVersionLock.Unlock()
+	if latest.GreaterThan(current) {
+		notifyUpdateAvailable(latestVersion)
+	}
 
 	// Update the last check time
 	lastAppcastCheck.Store(time.Now().UnixMilli())
@@ -1027,6 +1150,7 @@ func main() {
 	}()
 
 	ensureCliLinkStartup()
+	loadTraySettings()
 
 	log.Printf("Starting OutrigApp")
 	log.Printf("PATH: %s\n", os.Getenv("PATH"))
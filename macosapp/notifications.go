@@ -0,0 +1,258 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"fyne.io/systray"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+const (
+	// Tray app preferences, stored in the outrig home dir
+	TraySettingsFile = "trayapp.json"
+
+	NotifyKindPanic      = "panic"
+	NotifyKindDisconnect = "disconnect"
+	NotifyKindUpdate     = "update"
+)
+
+// TraySettings holds the tray app preferences
+type TraySettings struct {
+	NotificationsMuted bool `json:"notificationsmuted"`
+	NotifyPanics       bool `json:"notifypanics"`
+	NotifyDisconnects  bool `json:"notifydisconnects"`
+	NotifyUpdates      bool `json:"notifyupdates"`
+}
+
+var (
+	traySettings     = defaultTraySettings()
+	traySettingsLock sync.Mutex
+
+	// the update version we last notified about (so each version is only announced once per tray session)
+	lastNotifiedUpdateVersion string
+	notifiedUpdateLock        sync.Mutex
+)
+
+func defaultTraySettings() TraySettings {
+	return TraySettings{
+		NotifyPanics:      true,
+		NotifyDisconnects: true,
+		NotifyUpdates:     true,
+	}
+}
+
+func getTraySettingsPath() string {
+	return filepath.Join(utilfn.ExpandHomeDir(serverbase.GetOutrigHome()), TraySettingsFile)
+}
+
+// loadTraySettings reads the preferences (missing fields keep their defaults, a missing file is not an error)
+func loadTraySettings() {
+	barr, err := os.ReadFile(getTraySettingsPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading tray settings: %v", err)
+		}
+		return
+	}
+	settings := defaultTraySettings()
+	if err := json.Unmarshal(barr, &settings); err != nil {
+		log.Printf("Error parsing tray settings %s: %v", getTraySettingsPath(), err)
+		return
+	}
+	traySettingsLock.Lock()
+	traySettings = settings
+	traySettingsLock.Unlock()
+}
+
+func getTraySettings() TraySettings {
+	traySettingsLock.Lock()
+	defer traySettingsLock.Unlock()
+	return traySettings
+}
+
+// updateTraySettings applies fn to the preferences and saves them
+func updateTraySettings(fn func(settings *TraySettings)) {
+	traySettingsLock.Lock()
+	fn(&traySettings)
+	settings := traySettings
+	traySettingsLock.Unlock()
+
+	barr, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		log.Printf("Error encoding tray settings: %v", err)
+		return
+	}
+	settingsPath := getTraySettingsPath()
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		log.Printf("Error creating tray settings dir: %v", err)
+		return
+	}
+	if err := os.WriteFile(settingsPath, barr, 0644); err != nil {
+		log.Printf("Error writing tray settings: %v", err)
+	}
+}
+
+func isNotifyKindEnabled(settings TraySettings, kind string) bool {
+	if settings.NotificationsMuted {
+		return false
+	}
+	switch kind {
+	case NotifyKindPanic:
+		return settings.NotifyPanics
+	case NotifyKindDisconnect:
+		return settings.NotifyDisconnects
+	case NotifyKindUpdate:
+		return settings.NotifyUpdates
+	}
+	return false
+}
+
+// escapeAppleScriptString quotes s as an AppleScript string literal
+func escapeAppleScriptString(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	return "\"" + s + "\""
+}
+
+// postNotification shows a native notification if the user has that kind of notification enabled
+func postNotification(kind string, subtitle string, message string) {
+	if !isNotifyKindEnabled(getTraySettings(), kind) {
+		return
+	}
+	script := fmt.Sprintf("display notification %s with title \"Outrig\" subtitle %s",
+		escapeAppleScriptString(message), escapeAppleScriptString(subtitle))
+	if err := exec.Command("osascript", "-e", script).Run(); err != nil {
+		log.Printf("Error posting notification: %v", err)
+	}
+}
+
+// trayNotification is a notification to post (see postNotification)
+type trayNotification struct {
+	Kind     string
+	Subtitle string
+	Message  string
+}
+
+// getStatusNotifications returns the notifications for app runs that panicked or disconnected since the
+// previous status poll. Nothing is returned unless the server was running for both polls, so restarting the
+// tray app or the server doesn't replay old events.
+func getStatusNotifications(prevStatus ServerStatus, status ServerStatus) []trayNotification {
+	if !prevStatus.Running || !status.Running {
+		return nil
+	}
+	prevAppRuns := make(map[string]TrayAppRunInfo)
+	for _, appRun := range prevStatus.AppRuns {
+		prevAppRuns[appRun.AppRunId] = appRun
+	}
+	var rtn []trayNotification
+	for _, appRun := range status.AppRuns {
+		prevAppRun, found := prevAppRuns[appRun.AppRunId]
+		if !found {
+			// a new app run only counts if it is live (not e.g. an imported bundle)
+			if appRun.IsRunning && appRun.NumPanics > 0 {
+				rtn = append(rtn, trayNotification{Kind: NotifyKindPanic, Subtitle: appRun.AppName, Message: "The app panicked"})
+			}
+			continue
+		}
+		if appRun.NumPanics > prevAppRun.NumPanics {
+			newPanics := appRun.NumPanics - prevAppRun.NumPanics
+			message := "The app panicked"
+			if newPanics > 1 {
+				message = fmt.Sprintf("The app panicked (%d new panics)", newPanics)
+			}
+			rtn = append(rtn, trayNotification{Kind: NotifyKindPanic, Subtitle: appRun.AppName, Message: message})
+		}
+		if prevAppRun.Status == "running" && appRun.Status == "disconnected" {
+			rtn = append(rtn, trayNotification{Kind: NotifyKindDisconnect, Subtitle: appRun.AppName, Message: "The app disconnected without shutting down cleanly"})
+		}
+	}
+	return rtn
+}
+
+// notifyStatusChanges posts the notifications for the changes between two status polls (see getStatusNotifications)
+func notifyStatusChanges(prevStatus ServerStatus, status ServerStatus) {
+	for _, notification := range getStatusNotifications(prevStatus, status) {
+		go postNotification(notification.Kind, notification.Subtitle, notification.Message)
+	}
+}
+
+// notifyUpdateAvailable posts a notification the first time a new version is seen
+func notifyUpdateAvailable(version string) {
+	notifiedUpdateLock.Lock()
+	if version == lastNotifiedUpdateVersion {
+		notifiedUpdateLock.Unlock()
+		return
+	}
+	lastNotifiedUpdateVersion = version
+	notifiedUpdateLock.Unlock()
+	postNotification(NotifyKindUpdate, "Update Available", fmt.Sprintf("Outrig %s is available (use Install Outrig %s... in the menu)", version, version))
+}
+
+// addNotificationMenuItems adds the mute toggle and the per-event notification preferences
+func addNotificationMenuItems() {
+	settings := getTraySettings()
+
+	mMute := systray.AddMenuItemCheckbox("Mute Notifications", "Don't show any Outrig notifications", settings.NotificationsMuted)
+	mPrefs := systray.AddMenuItem("Notify On", "Choose which events show a notification")
+	mPanics := mPrefs.AddSubMenuItemCheckbox("App Panics", "A monitored app panicked", settings.NotifyPanics)
+	mDisconnects := mPrefs.AddSubMenuItemCheckbox("Unexpected Disconnects", "A monitored app disconnected without calling outrig.AppDone", settings.NotifyDisconnects)
+	mUpdates := mPrefs.AddSubMenuItemCheckbox("Updates Available", "A new version of Outrig is available", settings.NotifyUpdates)
+	if settings.NotificationsMuted {
+		mPrefs.Disable()
+	}
+
+	toggle := func(item *systray.MenuItem, fn func(settings *TraySettings, checked bool)) {
+		checked := !item.Checked()
+		if checked {
+			item.Check()
+		} else {
+			item.Uncheck()
+		}
+		updateTraySettings(func(settings *TraySettings) {
+			fn(settings, checked)
+		})
+	}
+	// the click channels are closed when the menu is rebuilt, which ends this goroutine
+	go func() {
+		for {
+			select {
+			case _, ok := <-mMute.ClickedCh:
+				if !ok {
+					return
+				}
+				toggle(mMute, func(settings *TraySettings, checked bool) { settings.NotificationsMuted = checked })
+				if mMute.Checked() {
+					mPrefs.Disable()
+				} else {
+					mPrefs.Enable()
+				}
+			case _, ok := <-mPanics.ClickedCh:
+				if !ok {
+					return
+				}
+				toggle(mPanics, func(settings *TraySettings, checked bool) { settings.NotifyPanics = checked })
+			case _, ok := <-mDisconnects.ClickedCh:
+				if !ok {
+					return
+				}
+				toggle(mDisconnects, func(settings *TraySettings, checked bool) { settings.NotifyDisconnects = checked })
+			case _, ok := <-mUpdates.ClickedCh:
+				if !ok {
+					return
+				}
+				toggle(mUpdates, func(settings *TraySettings, checked bool) { settings.NotifyUpdates = checked })
+			}
+		}
+	}()
+}
@@ -0,0 +1,72 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetStatusNotifications(t *testing.T) {
+	running := TrayAppRunInfo{AppRunId: "run1", AppName: "app", IsRunning: true, Status: "running"}
+	panicked := running
+	panicked.NumPanics = 1
+	panickedTwice := running
+	panickedTwice.NumPanics = 3
+	disconnected := running
+	disconnected.IsRunning = false
+	disconnected.Status = "disconnected"
+	done := running
+	done.IsRunning = false
+	done.Status = "done"
+	imported := TrayAppRunInfo{AppRunId: "run2", AppName: "imported", Status: "done", NumPanics: 2}
+
+	status := func(running bool, appRuns ...TrayAppRunInfo) ServerStatus {
+		return ServerStatus{Running: running, AppRuns: appRuns}
+	}
+	tests := []struct {
+		name   string
+		prev   ServerStatus
+		cur    ServerStatus
+		expect []trayNotification
+	}{
+		{"no changes", status(true, running), status(true, running), nil},
+		{"new panic", status(true, running), status(true, panicked), []trayNotification{
+			{Kind: NotifyKindPanic, Subtitle: "app", Message: "The app panicked"},
+		}},
+		{"several new panics", status(true, panicked), status(true, panickedTwice), []trayNotification{
+			{Kind: NotifyKindPanic, Subtitle: "app", Message: "The app panicked (2 new panics)"},
+		}},
+		{"new running app run that already panicked", status(true), status(true, panicked), []trayNotification{
+			{Kind: NotifyKindPanic, Subtitle: "app", Message: "The app panicked"},
+		}},
+		{"new imported app run", status(true), status(true, imported), nil},
+		{"unexpected disconnect", status(true, running), status(true, disconnected), []trayNotification{
+			{Kind: NotifyKindDisconnect, Subtitle: "app", Message: "The app disconnected without shutting down cleanly"},
+		}},
+		{"clean shutdown", status(true, running), status(true, done), nil},
+		{"server was down", status(false), status(true, panicked), nil},
+		{"server went down", status(true, running), status(false, disconnected), nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := getStatusNotifications(tc.prev, tc.cur)
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("got %+v, want %+v", got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestIsNotifyKindEnabled(t *testing.T) {
+	settings := defaultTraySettings()
+	settings.NotifyDisconnects = false
+	if !isNotifyKindEnabled(settings, NotifyKindPanic) || isNotifyKindEnabled(settings, NotifyKindDisconnect) {
+		t.Errorf("per-kind settings not applied: %+v", settings)
+	}
+	settings.NotificationsMuted = true
+	if isNotifyKindEnabled(settings, NotifyKindPanic) {
+		t.Errorf("muted notifications should not be enabled")
+	}
+}
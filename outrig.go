@@ -6,33 +6,50 @@
 package outrig
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"reflect"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/outrigdev/goid"
+	"github.com/outrigdev/outrig/pkg/collector/dbpool"
+	"github.com/outrigdev/outrig/pkg/collector/fdstats"
 	"github.com/outrigdev/outrig/pkg/collector/goroutine"
 	"github.com/outrigdev/outrig/pkg/collector/loginitex"
 	"github.com/outrigdev/outrig/pkg/collector/logprocess"
 	"github.com/outrigdev/outrig/pkg/collector/runtimestats"
+	"github.com/outrigdev/outrig/pkg/collector/schedstats"
 	"github.com/outrigdev/outrig/pkg/collector/watch"
 	"github.com/outrigdev/outrig/pkg/config"
 	"github.com/outrigdev/outrig/pkg/controller"
 	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/global"
 	"github.com/outrigdev/outrig/pkg/ioutrig"
+	"github.com/outrigdev/outrig/pkg/platform"
+	"github.com/outrigdev/outrig/pkg/redact"
 	"github.com/outrigdev/outrig/pkg/utilfn"
 )
 
 var initOnce sync.Once
 
+var (
+	appMetaLock    sync.Mutex
+	pendingAppMeta = make(map[string]string) // SetAppMeta calls made before Init
+)
+
 // Re-export ds.Config so callers can use "outrig.Config"
 type Config = config.Config
 
+// Capabilities reports which SDK features work on the compile target (see GetCapabilities())
+type Capabilities = ds.SDKCapabilities
+
 type Watch struct {
 	decl *ds.WatchDecl
 }
@@ -43,7 +60,39 @@ type Pusher struct {
 }
 
 type GoRoutine struct {
-	decl *ds.GoDecl
+	decl          *ds.GoDecl
+	inheritParent bool
+	callerSkip    int // extra frames between Run and the "created by" caller (SDK wrappers that call Run)
+}
+
+// Ticker is a time.Ticker that reports its scheduling stats as a watch (returned by WatchTicker)
+type Ticker struct {
+	C <-chan time.Time // the channel on which the ticks are delivered
+
+	ticker   *time.Ticker
+	decl     *ds.WatchDecl
+	pollObj  *watch.TickerPollObj
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// TypeCounter reports the number of live instances of T as a watch (returned by WatchTypeCount)
+type TypeCounter[T any] struct {
+	decl    *ds.WatchDecl
+	pollObj *watch.TypeCountPollObj
+}
+
+// CounterWatch is a cumulative counter reported as a watch (returned by Counter)
+type CounterWatch struct {
+	decl    *ds.WatchDecl
+	pollObj *watch.CounterPollObj
+}
+
+// WorkerPool reports a worker pool as a watch and ties its workers to it (returned by Pool)
+type WorkerPool struct {
+	name    string
+	decl    *ds.WatchDecl
+	pollObj *watch.PoolPollObj
 }
 
 func init() {
@@ -77,15 +126,18 @@ func DefaultConfig() *config.Config {
 
 // Init initializes Outrig, returns (enabled, error)
 func Init(appName string, cfgParam *config.Config) (bool, error) {
+	configSource := config.ConfigSourceInit
 	if cfgParam == nil {
-		loadedCfg, _, err := config.LoadConfig("", "")
+		loadedCfg, loadedSource, err := config.LoadConfig("", "")
 		if err != nil {
 			return false, err
 		}
 		if loadedCfg == nil {
 			cfgParam = DefaultConfig()
+			configSource = config.ConfigSourceDefault
 		} else {
 			cfgParam = loadedCfg
+			configSource = loadedSource
 		}
 	}
 	// copy to avoid cross contamination with original
@@ -102,11 +154,20 @@ func Init(appName string, cfgParam *config.Config) (bool, error) {
 	initOnce.Do(func() {
 		wasFirstCall = true
 
+		// redaction must be set up before anything is collected (an invalid config disables outrig)
+		if err := redact.Init(&finalCfg.Redact); err != nil {
+			initErr = err
+			return
+		}
+
 		// init/register the collectors
 		logprocess.Init(&finalCfg.Collectors.Logs)
 		goroutine.Init(&finalCfg.Collectors.Goroutine)
 		watch.Init(&finalCfg.Collectors.Watch)
 		runtimestats.Init(&finalCfg.Collectors.RuntimeStats)
+		dbpool.Init(&finalCfg.Collectors.DBPool)
+		fdstats.Init(&finalCfg.Collectors.FDStats)
+		schedstats.Init(&finalCfg.Collectors.SchedStats)
 
 		// Create and initialize the controller
 		// (collectors are now initialized inside MakeController)
@@ -115,9 +176,16 @@ func Init(appName string, cfgParam *config.Config) (bool, error) {
 			initErr = err
 			return
 		}
+		ctrlImpl.SetConfigSource(configSource)
 		// Store the controller in global.Controller
 		var cif ds.Controller = ctrlImpl
 		global.Controller.Store(&cif)
+		appMetaLock.Lock()
+		for key, value := range pendingAppMeta {
+			ctrlImpl.SetAppMeta(key, value)
+		}
+		clear(pendingAppMeta)
+		appMetaLock.Unlock()
 		ctrlImpl.InitialStart()
 	})
 
@@ -160,6 +228,49 @@ func GetAppRunId() string {
 	return config.GetAppRunId()
 }
 
+// SetAppMeta attaches a key/value pair to the current app run, e.g. SetAppMeta("gitsha", sha).
+// The metadata is shown with the app run and can be used to filter app runs, an empty value removes the key.
+// Defaults for hostname, region, and gitsha are set automatically. Can be called before Init.
+func SetAppMeta(key string, value string) {
+	appMetaLock.Lock()
+	ctrlPtr := getController()
+	if ctrlPtr == nil {
+		pendingAppMeta[key] = value
+		appMetaLock.Unlock()
+		return
+	}
+	appMetaLock.Unlock()
+	ctrlPtr.SetAppMeta(key, value)
+}
+
+// GetAppMeta returns a copy of the metadata of the current app run
+func GetAppMeta() map[string]string {
+	ctrlPtr := getController()
+	if ctrlPtr == nil {
+		appMetaLock.Lock()
+		defer appMetaLock.Unlock()
+		return maps.Clone(pendingAppMeta)
+	}
+	return ctrlPtr.GetAppMeta()
+}
+
+// AddPacketHook registers a hook that is called with every packet the SDK sends to Outrig (log lines,
+// watches, goroutines, runtime stats, ...) before it is queued. The hook can modify the packet (pk.Data holds
+// the ds type for pk.Type, e.g. *ds.LogLine for "log" or *ds.WatchInfo for "watch") or return false to drop
+// it. Example, dropping log lines that contain "healthcheck":
+//
+//	outrig.AddPacketHook(func(pk *ds.PacketType) bool {
+//		logLine, ok := pk.Data.(*ds.LogLine)
+//		return !ok || !strings.Contains(logLine.Msg, "healthcheck")
+//	})
+//
+// Hooks run on the goroutine that sends the packet, so they should be fast and must not log to Outrig.
+// stdout/stderr are captured by a separate process, those lines don't pass through the hooks. Can be
+// called before Init.
+func AddPacketHook(hook func(pk *ds.PacketType) bool) {
+	controller.AddPacketHook(hook)
+}
+
 // AppDone signals that the application is done
 // This should be deferred in the program's main function
 func AppDone() {
@@ -212,6 +323,13 @@ func OutrigVersion() string {
 	return config.OutrigSDKVersion
 }
 
+// GetCapabilities returns the SDK capability report for the platform the app was compiled for.
+// On restricted targets (js/wasm, wasip1, TinyGo) the SDK compiles, but can't connect to the server
+// and the collectors that need unsupported runtime/OS features are disabled.
+func GetCapabilities() Capabilities {
+	return platform.GetCapabilities()
+}
+
 func logInternal(str string) {
 	ctrlPtr := getController()
 	if ctrlPtr == nil {
@@ -221,6 +339,7 @@ func logInternal(str string) {
 		Ts:     time.Now().UnixMilli(),
 		Msg:    str,
 		Source: "outrig",
+		GoId:   int64(goid.Get()),
 	}
 	packet := &ds.PacketType{
 		Type: ds.PacketTypeLog,
@@ -256,6 +375,14 @@ func MakeLogStream(name string) io.Writer {
 	return logprocess.MakeLogStreamWriter(name)
 }
 
+// LogWriter returns an io.Writer for the named log stream (e.g. "audit").
+// Lines written to it show up in Outrig with the stream name as their source (search with $src:audit).
+// Calling LogWriter with the same name returns the same writer, so it is safe to call it wherever it is needed.
+// Like MakeLogStream it never blocks on I/O.
+func LogWriter(name string) io.Writer {
+	return logprocess.GetLogStreamWriter(name)
+}
+
 func NewWatch(name string) *Watch {
 	w := &Watch{
 		decl: &ds.WatchDecl{
@@ -267,6 +394,49 @@ func NewWatch(name string) *Watch {
 	return w
 }
 
+// WatchNamespace is a group of related watches (returned by WatchGroup)
+type WatchNamespace struct {
+	name string
+}
+
+// WatchGroup returns a namespace for related watches. Watches created with its NewWatch are named
+// "<group>.<name>" and are shown together (as a collapsible group) in Outrig. Groups can be nested
+// with (*WatchNamespace).WatchGroup, and calling WatchGroup with the same name returns an equivalent namespace.
+//
+// Example:
+//
+//	cacheWatches := outrig.WatchGroup("cache")
+//	cacheWatches.NewWatch("hits").PollAtomic(&cacheHits)     // "cache.hits"
+//	cacheWatches.NewWatch("misses").PollAtomic(&cacheMisses) // "cache.misses"
+func WatchGroup(name string) *WatchNamespace {
+	return &WatchNamespace{name: utilfn.NormalizeName(strings.Trim(name, "."))}
+}
+
+// Name returns the full name of the group (e.g. "cache.l1" for a nested group)
+func (g *WatchNamespace) Name() string {
+	return g.name
+}
+
+// WatchGroup returns a nested group named "<group>.<name>"
+func (g *WatchNamespace) WatchGroup(name string) *WatchNamespace {
+	return WatchGroup(g.name + "." + strings.Trim(name, "."))
+}
+
+// NewWatch creates a watch in the group, its name is "<group>.<name>"
+func (g *WatchNamespace) NewWatch(name string) *Watch {
+	w := &Watch{
+		decl: &ds.WatchDecl{
+			Name:    utilfn.NormalizeName(name),
+			NewLine: getCallerInfo(1),
+		},
+	}
+	if g.name != "" {
+		w.decl.Name = utilfn.NormalizeName(g.name + "." + name)
+		w.decl.Group = g.name
+	}
+	return w
+}
+
 // WithTags adds tags to the watch. Tags can be specified with or without a "#" prefix,
 // which will be stripped if present. Empty, duplicate tags are removed, and all tags are trimmed.
 func (w *Watch) WithTags(tags ...string) *Watch {
@@ -294,6 +464,62 @@ func (w *Watch) AsGoFmt() *Watch {
 	return w
 }
 
+// WithUnit sets the unit of the watch's numeric value so Outrig can format it. The known units are
+// "bytes" (shown as kB, MB, ...), "ns", "us", "ms", "s" (shown as durations) and "percent", other units
+// (e.g. "req" or "items") are shown after the value.
+//
+// Example:
+//
+//	outrig.NewWatch("cache-size").WithUnit("bytes").PollAtomic(&cacheSize)
+func (w *Watch) WithUnit(unit string) *Watch {
+	unit = strings.TrimSpace(unit)
+	err := watch.ValidateUnit(unit)
+	if err != nil {
+		w.addConfigErr(err, false)
+		return w
+	}
+	w.decl.Unit = unit
+	return w
+}
+
+// WithDisplay sets how Outrig displays the watch's value: "gauge" (the default, the value is a level),
+// "rate" (the value is cumulative, e.g. a counter, and its rate of change per second is shown as well) or
+// "raw" (the value is shown as is, without formatting it by its unit).
+func (w *Watch) WithDisplay(display string) *Watch {
+	err := watch.ValidateDisplay(display)
+	if err != nil {
+		w.addConfigErr(err, false)
+		return w
+	}
+	w.decl.Display = display
+	return w
+}
+
+// Settable lets the value of the watch be changed from Outrig (e.g. to flip a feature flag or change
+// a log level at runtime). setFn is called with the new value and must take exactly one argument and
+// return nothing or an error. The value sent from Outrig is JSON that is converted to the argument's type
+// (plain text is accepted for string arguments). Settable must be called before the watch is registered
+// (before ForPush, PollFunc, PollAtomic, etc.).
+//
+// Example:
+//
+//	var debugMode atomic.Bool
+//	outrig.NewWatch("debug-mode").Settable(func(v bool) { debugMode.Store(v) }).PollAtomic(&debugMode)
+func (w *Watch) Settable(setFn any) *Watch {
+	if w.decl.WatchType != "" {
+		w.addConfigErr(fmt.Errorf("Settable must be called before the watch is registered"), false)
+		return w
+	}
+	err := watch.ValidateSetFunc(setFn)
+	if err != nil {
+		w.addConfigErr(err, false)
+		return w
+	}
+	w.decl.Settable = true
+	w.decl.SetFn = setFn
+	return w
+}
+
 func (w *Watch) setType(typ string) bool {
 	if w.decl.WatchType != "" {
 		return false
@@ -427,6 +653,30 @@ func (w *Watch) PollSync(lock sync.Locker, val any) *Watch {
 	return w
 }
 
+// PollContext sets up a watch that tracks a context.Context. Each poll reports whether the
+// context is done, its error and cause (context.Cause), the time since the watch was created,
+// and the time left until its deadline (if it has one). This makes it easy to spot long-lived
+// operation contexts that are stale (or were canceled but are still being held).
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancelCause(context.Background())
+//	outrig.NewWatch("sync-job-ctx").PollContext(ctx)
+func (w *Watch) PollContext(ctx context.Context) *Watch {
+	if !w.setType(watch.WatchType_Context) {
+		w.addConfigErr(fmt.Errorf("cannot change watch type from %s to %s", w.decl.WatchType, watch.WatchType_Context), false)
+		return w
+	}
+	err := watch.ValidatePollContext(ctx)
+	if err != nil {
+		w.addConfigErr(err, true)
+	} else {
+		w.decl.PollObj = watch.MakeContextPollObj(ctx)
+	}
+	w.registerWatch()
+	return w
+}
+
 // Static sets up a static watch that holds a constant value. The value is set once
 // when the watch is created and never changes. This is useful for configuration
 // values, URLs, or other constants that you want to monitor but don't need to poll.
@@ -454,6 +704,383 @@ func (w *Watch) Unregister() {
 	wc.UnregisterWatch(w.decl)
 }
 
+// WatchTicker returns a ticker like time.NewTicker(d) that is also reported as a watch with the given name
+// (tagged #ticker). The watch shows the number of ticks, missed ticks (ticks the runtime skipped because the
+// timer fired late, or that were dropped because the previous tick wasn't received yet), the average and max
+// drift of the tick interval, and the timestamps of the most recent ticks. This makes scheduling jitter in
+// periodic jobs observable. Like time.NewTicker it panics if d <= 0.
+//
+// Example:
+//
+//	ticker := outrig.WatchTicker("cache-refresh", 5*time.Second)
+//	defer ticker.Stop()
+//	for range ticker.C {
+//		refreshCache()
+//	}
+func WatchTicker(name string, d time.Duration) *Ticker {
+	ticker := time.NewTicker(d)
+	c := make(chan time.Time, 1)
+	t := &Ticker{
+		C:       c,
+		ticker:  ticker,
+		pollObj: watch.MakeTickerPollObj(d),
+		stopCh:  make(chan struct{}),
+	}
+	t.decl = &ds.WatchDecl{
+		Name:      utilfn.NormalizeName(name),
+		Tags:      []string{watch.TickerTag},
+		NewLine:   getCallerInfo(1),
+		WatchType: watch.WatchType_Ticker,
+		Format:    watch.WatchFormat_Json,
+		PollObj:   t.pollObj,
+	}
+	watch.GetInstance().RegisterWatchDecl(t.decl)
+	go func() {
+		ioutrig.I.SetGoRoutineNameAndTags("WatchTicker:"+t.decl.Name, "outrig")
+		for {
+			select {
+			case <-t.stopCh:
+				return
+			case ts := <-ticker.C:
+				delivered := false
+				select {
+				case c <- ts:
+					delivered = true
+				default:
+				}
+				t.pollObj.RecordTick(ts, delivered)
+			}
+		}
+	}()
+	return t
+}
+
+// Reset stops the ticker and resets its period to d, like time.Ticker.Reset (the drift of the next tick is
+// not measured against the ticks before the reset). Reset must not be called after Stop.
+func (t *Ticker) Reset(d time.Duration) {
+	t.ticker.Reset(d)
+	t.pollObj.Reset(d)
+}
+
+// Stop turns off the ticker and unregisters its watch. Like time.Ticker.Stop it does not close t.C.
+func (t *Ticker) Stop() {
+	t.stopOnce.Do(func() {
+		t.ticker.Stop()
+		close(t.stopCh)
+		watch.GetInstance().UnregisterWatch(t.decl)
+	})
+}
+
+// WatchTypeCount returns a counter that reports the live instances of T as a watch with the given name
+// (tagged #typecount). The watch shows the live, peak live, created, and freed counts, which makes leaks of
+// a specific struct visible. Go's heap profiles don't record types, so instances are counted when they are
+// registered: call Track in the constructor to count an object until it is garbage collected, or use Inc/Dec
+// for objects with an explicit lifetime (pooled or closed objects).
+//
+// Example:
+//
+//	var sessionCount = outrig.WatchTypeCount[Session]("live-sessions")
+//
+//	func NewSession() *Session {
+//		return sessionCount.Track(&Session{})
+//	}
+func WatchTypeCount[T any](name string) *TypeCounter[T] {
+	typeName := reflect.TypeOf((*T)(nil)).Elem().String()
+	c := &TypeCounter[T]{
+		pollObj: watch.MakeTypeCountPollObj(typeName),
+	}
+	c.decl = &ds.WatchDecl{
+		Name:      utilfn.NormalizeName(name),
+		Tags:      []string{watch.TypeCountTag},
+		NewLine:   getCallerInfo(1),
+		WatchType: watch.WatchType_TypeCount,
+		Format:    watch.WatchFormat_Json,
+		PollObj:   c.pollObj,
+	}
+	watch.GetInstance().RegisterWatchDecl(c.decl)
+	return c
+}
+
+// Track counts obj as a live instance until it is garbage collected and returns obj. With Go 1.24+ it uses
+// runtime.AddCleanup, with older versions it sets a finalizer on obj (so don't Track objects that have their
+// own finalizer). obj must point to the start of an allocation (e.g. the result of new(T) or &T{}).
+func (c *TypeCounter[T]) Track(obj *T) *T {
+	if obj == nil {
+		return nil
+	}
+	watch.TrackObject(c.pollObj, obj)
+	return obj
+}
+
+// Inc counts a new live instance (for objects whose lifetime ends with an explicit Close/Release, see Dec)
+func (c *TypeCounter[T]) Inc() {
+	c.pollObj.RecordCreated()
+}
+
+// Dec counts a freed instance (pairs with Inc)
+func (c *TypeCounter[T]) Dec() {
+	c.pollObj.RecordFreed()
+}
+
+// Unregister unregisters the counter's watch (tracked objects can still be collected safely)
+func (c *TypeCounter[T]) Unregister() {
+	watch.GetInstance().UnregisterWatch(c.decl)
+}
+
+var (
+	counterWatchesLock sync.Mutex
+	counterWatches     = make(map[string]*CounterWatch) // counter name => counter
+)
+
+// Counter returns the counter with the given name, it is reported as a watch (tagged #counter) the first time
+// it is used and later calls with the same name return the same counter. The counter is cheap to update from
+// many goroutines (the value is spread over atomic shards), the cumulative value is sent and Outrig shows
+// its rate per second along with a sparkline of the recent rates.
+//
+// Example:
+//
+//	outrig.Counter("requests").Inc()
+//	outrig.Counter("bytes-read").Add(int64(n))
+func Counter(name string) *CounterWatch {
+	name = utilfn.NormalizeName(name)
+	counterWatchesLock.Lock()
+	defer counterWatchesLock.Unlock()
+	if c := counterWatches[name]; c != nil {
+		return c
+	}
+	c := &CounterWatch{
+		pollObj: watch.MakeCounterPollObj(),
+	}
+	c.decl = &ds.WatchDecl{
+		Name:      name,
+		Tags:      []string{watch.CounterTag},
+		NewLine:   getCallerInfo(1),
+		WatchType: watch.WatchType_Counter,
+		Counter:   true,
+		Display:   ds.WatchDisplayRate,
+		PollObj:   c.pollObj,
+	}
+	counterWatches[name] = c
+	watch.GetInstance().RegisterWatchDecl(c.decl)
+	return c
+}
+
+// Inc adds 1 to the counter
+func (c *CounterWatch) Inc() {
+	c.pollObj.Add(1)
+}
+
+// Add adds n to the counter (n should not be negative, the rate of a counter that goes down is negative)
+func (c *CounterWatch) Add(n int64) {
+	c.pollObj.Add(n)
+}
+
+// Value returns the cumulative value of the counter
+func (c *CounterWatch) Value() int64 {
+	return c.pollObj.GetValue()
+}
+
+// Pool returns a tracker for a pool of size workers that is reported as a watch with the given name (tagged
+// #pool). The watch shows the running workers, busy and idle workers, the queue depth (see SetQueueDepthFunc),
+// and task duration stats. Workers started with Go are named after the pool and are shown as a single row
+// (with a pool badge) when the goroutine view is grouped by creation site.
+//
+// Example:
+//
+//	pool := outrig.Pool("image-workers", 8)
+//	pool.SetQueueDepthFunc(func() int { return len(jobs) })
+//	for i := 0; i < 8; i++ {
+//		pool.Go(func() {
+//			for job := range jobs {
+//				pool.RunTask(func() { process(job) })
+//			}
+//		})
+//	}
+func Pool(name string, size int) *WorkerPool {
+	p := &WorkerPool{
+		name:    utilfn.NormalizeName(name),
+		pollObj: watch.MakePoolPollObj(size),
+	}
+	p.decl = &ds.WatchDecl{
+		Name:      p.name,
+		Tags:      []string{watch.PoolTag},
+		NewLine:   getCallerInfo(1),
+		WatchType: watch.WatchType_Pool,
+		Format:    watch.WatchFormat_Json,
+		PollObj:   p.pollObj,
+	}
+	watch.GetInstance().RegisterWatchDecl(p.decl)
+	return p
+}
+
+// Go starts fn as a worker goroutine of the pool (named after the pool and tagged #pool).
+// Panics in fn are not recovered, like in a plain goroutine.
+func (p *WorkerPool) Go(fn func()) {
+	gr := Go(p.name).WithGroup(p.name).WithTags(watch.PoolTag).WithoutRecover()
+	gr.decl.Pool = p.name
+	// the goroutine is "created by" the caller of Go (not by WorkerPool.Go)
+	gr.callerSkip = 1
+	gr.Run(func() {
+		p.pollObj.WorkerStart()
+		defer p.pollObj.WorkerEnd()
+		fn()
+	})
+}
+
+// RunTask runs fn as a task of the pool (call it from a worker), the worker is counted as busy while fn runs
+// and the task's duration is recorded
+func (p *WorkerPool) RunTask(fn func()) {
+	done := p.StartTask()
+	defer done()
+	fn()
+}
+
+// StartTask records the start of a task, the returned func must be called (once) when the task is done
+// (use it when the task doesn't fit in a closure, see RunTask)
+func (p *WorkerPool) StartTask() func() {
+	startTime := time.Now()
+	p.pollObj.TaskStart()
+	return func() {
+		p.pollObj.TaskEnd(time.Since(startTime))
+	}
+}
+
+// SetQueueDepthFunc sets the function that reports the number of queued tasks, e.g. func() int { return len(jobs) }
+// (it is called every time the watch is polled, so it should be cheap)
+func (p *WorkerPool) SetQueueDepthFunc(fn func() int) *WorkerPool {
+	p.pollObj.SetQueueDepthFunc(fn)
+	return p
+}
+
+// Unregister unregisters the pool's watch (running workers are not affected)
+func (p *WorkerPool) Unregister() {
+	watch.GetInstance().UnregisterWatch(p.decl)
+}
+
+var (
+	groupWatchesLock sync.Mutex
+	groupWatches     = make(map[string]*watch.GroupPollObj) // group name => poll object of the group's watch
+)
+
+// groupTracker reports a WaitGroup or ErrGroup to Outrig (a nil tracker is an untracked group)
+type groupTracker struct {
+	kind    string
+	name    string
+	pollObj *watch.GroupPollObj
+
+	lock    sync.Mutex
+	pending int64               // this group's counter (the watch reports the total of all the groups with the name)
+	waiters map[*ds.GoDecl]bool // decls of the goroutines blocked in Wait
+}
+
+func makeGroupTracker(kind string, name string) *groupTracker {
+	name = utilfn.NormalizeName(name)
+	return &groupTracker{
+		kind:    kind,
+		name:    name,
+		pollObj: getGroupPollObj(kind, name, getCallerInfo(2)),
+		waiters: make(map[*ds.GoDecl]bool),
+	}
+}
+
+// getGroupPollObj returns the poll object of the watch for the groups named name, the watch is registered
+// the first time a group with the name is created (and stays registered)
+func getGroupPollObj(kind string, name string, newLine string) *watch.GroupPollObj {
+	groupWatchesLock.Lock()
+	defer groupWatchesLock.Unlock()
+	if pollObj := groupWatches[name]; pollObj != nil {
+		return pollObj
+	}
+	pollObj := watch.MakeGroupPollObj(kind)
+	groupWatches[name] = pollObj
+	watch.GetInstance().RegisterWatchDecl(&ds.WatchDecl{
+		Name:      name,
+		Tags:      []string{kind},
+		NewLine:   newLine,
+		WatchType: watch.WatchType_Group,
+		Format:    watch.WatchFormat_Json,
+		PollObj:   pollObj,
+	})
+	return pollObj
+}
+
+func (t *groupTracker) add(delta int) {
+	if t == nil {
+		return
+	}
+	t.pollObj.Add(delta)
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.pending += int64(delta)
+	if len(t.waiters) == 0 {
+		return
+	}
+	status := t.makeWaitStatus_nolock()
+	gc := goroutine.GetInstance()
+	for decl := range t.waiters {
+		gc.UpdateGoRoutineStatus(decl, status)
+	}
+}
+
+// run starts fn as a member goroutine of the group (named after the group), done is called when fn returns
+func (t *groupTracker) run(fn func() error, done func()) {
+	if t == nil {
+		go func() {
+			defer done()
+			fn()
+		}()
+		return
+	}
+	gr := Go(t.name).WithGroup(t.name).WithTags(t.kind).WithoutRecover()
+	// the goroutine is "created by" the caller of the group's Go method (not by groupTracker.run)
+	gr.callerSkip = 2
+	gr.Run(func() {
+		goId := int64(goid.Get())
+		t.pollObj.MemberStart(goId)
+		var err error
+		defer func() {
+			t.pollObj.MemberEnd(goId, err)
+			done()
+		}()
+		err = fn()
+	})
+}
+
+// wait calls waitFn, the calling goroutine's status shows how many members it is waiting on until waitFn returns
+func (t *groupTracker) wait(waitFn func()) {
+	if t == nil {
+		waitFn()
+		return
+	}
+	waitId := t.pollObj.WaitStart()
+	defer t.pollObj.WaitEnd(waitId)
+	gr := CurrentGR()
+	if gr == nil {
+		waitFn()
+		return
+	}
+	gc := goroutine.GetInstance()
+	t.lock.Lock()
+	t.waiters[gr.decl] = true
+	gc.UpdateGoRoutineStatus(gr.decl, t.makeWaitStatus_nolock())
+	t.lock.Unlock()
+	defer func() {
+		t.lock.Lock()
+		delete(t.waiters, gr.decl)
+		t.lock.Unlock()
+		gc.UpdateGoRoutineStatus(gr.decl, "")
+	}()
+	waitFn()
+}
+
+func (t *groupTracker) makeWaitStatus_nolock() string {
+	members := "members"
+	if t.pending == 1 {
+		members = "member"
+	}
+	return fmt.Sprintf("waiting on %d %s of %s %s", t.pending, members, t.kind, t.name)
+}
+
 // getCallerInfo returns the file and line number of the caller.
 // The skip parameter specifies how many stack frames to skip before reporting.
 // A skip value of 0 returns the file and line number of the getCallerInfo call itself.
@@ -566,6 +1193,16 @@ func (g *GoRoutine) WithPkg(pkg string) *GoRoutine {
 	return g
 }
 
+// InheritParent prefixes the goroutine's name with the name of the goroutine that calls Run (and adds its tags),
+// controlled by the inheritparentname goroutine collector setting. Used for go statements transformed by "outrig run".
+func (g *GoRoutine) InheritParent() *GoRoutine {
+	if atomic.LoadInt32(&g.decl.State) != goroutine.GoState_Init {
+		return g
+	}
+	g.inheritParent = true
+	return g
+}
+
 func (g *GoRoutine) WithoutRecover() *GoRoutine {
 	if atomic.LoadInt32(&g.decl.State) != goroutine.GoState_Init {
 		return g
@@ -579,8 +1216,11 @@ func (g *GoRoutine) Run(fn func()) {
 		return
 	}
 	gc := goroutine.GetInstance()
+	if g.inheritParent {
+		gc.InheritParentInfo(g.decl, int64(goid.Get()))
+	}
 	g.decl.StartTs = time.Now().UnixMilli()
-	g.decl.RealCreatedBy = getCallerCreatedByInfo(1)
+	g.decl.RealCreatedBy = getCallerCreatedByInfo(1 + g.callerSkip)
 	go func() {
 		gc.RecordGoRoutineStart(g.decl, nil)
 		if g.decl.NoRecover {
@@ -6,16 +6,22 @@
 package outrig
 
 import (
+	"context"
 	"io"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/ds"
 )
 
 // Re-export config.Config so callers can use "outrig.Config"
 type Config = config.Config
 
+// Capabilities reports which SDK features work on the compile target
+type Capabilities = ds.SDKCapabilities
+
 type Watch struct {
 	// No actual implementation needed for no_outrig build
 }
@@ -28,6 +34,28 @@ type GoRoutine struct {
 	// No actual implementation needed for no_outrig build
 }
 
+// Ticker wraps a time.Ticker (no watch is reported for no_outrig build)
+type Ticker struct {
+	C <-chan time.Time
+
+	ticker *time.Ticker
+}
+
+// TypeCounter counts the live instances of T (no watch is reported for no_outrig build)
+type TypeCounter[T any] struct {
+	// No actual implementation needed for no_outrig build
+}
+
+// CounterWatch is a counter (no watch is reported for no_outrig build)
+type CounterWatch struct {
+	// No actual implementation needed for no_outrig build
+}
+
+// WorkerPool tracks a worker pool (no watch is reported for no_outrig build)
+type WorkerPool struct {
+	// No actual implementation needed for no_outrig build
+}
+
 // Disable is a no-op when no_outrig is set
 func Disable(disconnect bool) {}
 
@@ -58,6 +86,14 @@ func GetAppRunId() string {
 	return ""
 }
 
+// SetAppMeta is a no-op when no_outrig is set
+func SetAppMeta(key string, value string) {}
+
+// GetAppMeta returns nil when no_outrig is set
+func GetAppMeta() map[string]string {
+	return nil
+}
+
 // AppDone is a no-op when no_outrig is set
 func AppDone() {}
 
@@ -67,6 +103,34 @@ func NewWatch(name string) *Watch {
 	return &Watch{}
 }
 
+// WatchNamespace is a group of related watches
+type WatchNamespace struct {
+	name string
+}
+
+// WatchGroup returns a namespace for related watches
+// This is a no-op implementation for no_outrig build
+func WatchGroup(name string) *WatchNamespace {
+	return &WatchNamespace{name: name}
+}
+
+// Name returns the full name of the group
+func (g *WatchNamespace) Name() string {
+	return g.name
+}
+
+// WatchGroup returns a nested group
+// This is a no-op implementation for no_outrig build
+func (g *WatchNamespace) WatchGroup(name string) *WatchNamespace {
+	return &WatchNamespace{name: g.name + "." + name}
+}
+
+// NewWatch creates a watch in the group
+// This is a no-op implementation for no_outrig build
+func (g *WatchNamespace) NewWatch(name string) *Watch {
+	return &Watch{}
+}
+
 // WithTags adds tags to the watch
 // This is a no-op implementation for no_outrig build
 func (w *Watch) WithTags(tags ...string) *Watch {
@@ -97,6 +161,24 @@ func (w *Watch) AsGoFmt() *Watch {
 	return w
 }
 
+// WithUnit sets the unit of the watch's numeric value
+// This is a no-op implementation for no_outrig build
+func (w *Watch) WithUnit(unit string) *Watch {
+	return w
+}
+
+// WithDisplay sets how Outrig displays the watch's value
+// This is a no-op implementation for no_outrig build
+func (w *Watch) WithDisplay(display string) *Watch {
+	return w
+}
+
+// Settable lets the value of the watch be changed from Outrig
+// This is a no-op implementation for no_outrig build
+func (w *Watch) Settable(setFn any) *Watch {
+	return w
+}
+
 // ForPush creates a pusher for this watch
 // This is a no-op implementation for no_outrig build
 func (w *Watch) ForPush() *Pusher {
@@ -121,6 +203,12 @@ func (w *Watch) PollSync(lock sync.Locker, val any) *Watch {
 	return w
 }
 
+// PollContext sets up a watch that tracks a context.Context
+// This is a no-op implementation for no_outrig build
+func (w *Watch) PollContext(ctx context.Context) *Watch {
+	return w
+}
+
 // Static sets up a static watch that holds a constant value
 // This is a no-op implementation for no_outrig build
 func (w *Watch) Static(val any) *Watch {
@@ -133,6 +221,111 @@ func (w *Watch) Unregister() {
 	// No-op
 }
 
+// WatchTicker returns a plain time.Ticker wrapper
+// No watch is reported for no_outrig build
+func WatchTicker(name string, d time.Duration) *Ticker {
+	ticker := time.NewTicker(d)
+	return &Ticker{C: ticker.C, ticker: ticker}
+}
+
+// Reset resets the ticker's period to d
+func (t *Ticker) Reset(d time.Duration) {
+	t.ticker.Reset(d)
+}
+
+// Stop turns off the ticker
+func (t *Ticker) Stop() {
+	t.ticker.Stop()
+}
+
+// WatchTypeCount returns a counter for the live instances of T
+// This is a no-op implementation for no_outrig build
+func WatchTypeCount[T any](name string) *TypeCounter[T] {
+	return &TypeCounter[T]{}
+}
+
+// Track returns obj
+// This is a no-op implementation for no_outrig build
+func (c *TypeCounter[T]) Track(obj *T) *T {
+	return obj
+}
+
+// Inc counts a new live instance
+// This is a no-op implementation for no_outrig build
+func (c *TypeCounter[T]) Inc() {
+	// No-op
+}
+
+// Dec counts a freed instance
+// This is a no-op implementation for no_outrig build
+func (c *TypeCounter[T]) Dec() {
+	// No-op
+}
+
+// Unregister unregisters the counter's watch
+// This is a no-op implementation for no_outrig build
+func (c *TypeCounter[T]) Unregister() {
+	// No-op
+}
+
+// Counter returns a counter
+// This is a no-op implementation for no_outrig build
+func Counter(name string) *CounterWatch {
+	return &CounterWatch{}
+}
+
+// Inc adds 1 to the counter
+// This is a no-op implementation for no_outrig build
+func (c *CounterWatch) Inc() {
+	// No-op
+}
+
+// Add adds n to the counter
+// This is a no-op implementation for no_outrig build
+func (c *CounterWatch) Add(n int64) {
+	// No-op
+}
+
+// Value returns the value of the counter
+// This is a no-op implementation for no_outrig build
+func (c *CounterWatch) Value() int64 {
+	return 0
+}
+
+// Pool returns a worker pool tracker
+// This is a no-op implementation for no_outrig build
+func Pool(name string, size int) *WorkerPool {
+	return &WorkerPool{}
+}
+
+// Go starts fn in a new goroutine
+func (p *WorkerPool) Go(fn func()) {
+	go fn()
+}
+
+// RunTask runs fn
+func (p *WorkerPool) RunTask(fn func()) {
+	fn()
+}
+
+// StartTask returns a func to call when the task is done
+// This is a no-op implementation for no_outrig build
+func (p *WorkerPool) StartTask() func() {
+	return func() {}
+}
+
+// SetQueueDepthFunc sets the queue depth func
+// This is a no-op implementation for no_outrig build
+func (p *WorkerPool) SetQueueDepthFunc(fn func() int) *WorkerPool {
+	return p
+}
+
+// Unregister unregisters the pool's watch
+// This is a no-op implementation for no_outrig build
+func (p *WorkerPool) Unregister() {
+	// No-op
+}
+
 // Push pushes a value to the watch
 // This is a no-op implementation for no_outrig build
 func (p *Pusher) Push(val any) {
@@ -186,6 +379,12 @@ func (g *GoRoutine) WithPkg(pkg string) *GoRoutine {
 	return g
 }
 
+// InheritParent prefixes the goroutine's name with the name of its parent goroutine
+// This is a no-op implementation for no_outrig build
+func (g *GoRoutine) InheritParent() *GoRoutine {
+	return g
+}
+
 // WithoutRecover disables panic recovery for the goroutine
 // This is a no-op implementation for no_outrig build
 func (g *GoRoutine) WithoutRecover() *GoRoutine {
@@ -216,6 +415,11 @@ func OutrigVersion() string {
 	return config.OutrigSDKVersion
 }
 
+// GetCapabilities returns an empty report when no_outrig is set
+func GetCapabilities() Capabilities {
+	return Capabilities{}
+}
+
 // Log is a no-op when no_outrig is set
 func Log(str string) {}
 
@@ -225,3 +429,31 @@ func Logf(format string, args ...any) {}
 func MakeLogStream(name string) io.Writer {
 	return io.Discard
 }
+
+// AddPacketHook is a no-op when no_outrig is set
+func AddPacketHook(hook func(pk *ds.PacketType) bool) {}
+
+// LogWriter is a no-op when no_outrig is set
+func LogWriter(name string) io.Writer {
+	return io.Discard
+}
+
+// groupTracker is a no-op for no_outrig build (WaitGroup and ErrGroup are not tracked)
+type groupTracker struct{}
+
+func makeGroupTracker(kind string, name string) *groupTracker {
+	return nil
+}
+
+func (t *groupTracker) add(delta int) {}
+
+func (t *groupTracker) run(fn func() error, done func()) {
+	go func() {
+		defer done()
+		fn()
+	}()
+}
+
+func (t *groupTracker) wait(waitFn func()) {
+	waitFn()
+}
@@ -0,0 +1,30 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !no_outrig
+
+package outrig
+
+import (
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/collector/watch"
+)
+
+func TestCounter(t *testing.T) {
+	c := Counter("test counter")
+	c.Inc()
+	c.Add(4)
+
+	// the same (normalized) name returns the same counter
+	same := Counter("test_counter")
+	if same != c || same.Value() != 5 {
+		t.Errorf("got a different counter (value %d) for the same name", same.Value())
+	}
+	if other := Counter("test.other"); other == c || other.Value() != 0 {
+		t.Errorf("got the same counter for another name")
+	}
+	if c.decl.Name != "test_counter" || c.decl.WatchType != watch.WatchType_Counter || !c.decl.Counter {
+		t.Errorf("got decl %+v, want a counter watch", c.decl)
+	}
+}
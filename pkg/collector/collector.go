@@ -3,10 +3,15 @@
 
 package collector
 
-import "github.com/outrigdev/outrig/pkg/ds"
+import (
+	"fmt"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
 
 // Collector defines the interface for collection functionality
-// Implementations: goroutine/goroutine.go, logprocess/logprocess.go, runtimestats/runtimestats.go, watch/watch.go
+// Implementations: dbpool/dbpool.go, fdstats/fdstats.go, goroutine/goroutine.go, logprocess/logprocess.go, runtimestats/runtimestats.go, schedstats/schedstats.go, watch/watch.go
 type Collector interface {
 	// CollectorName returns the unique name of the collector
 	CollectorName() string
@@ -21,3 +26,36 @@ type Collector interface {
 	// Collectors that need to send full updates on new connections should implement this
 	OnNewConnection()
 }
+
+// ConfigurableCollector is implemented by collectors whose settings can be changed at runtime
+// (settings are sent from the server, see ds.CollectorAdminData)
+type ConfigurableCollector interface {
+	ApplySettings(settings map[string]any) error
+}
+
+// PacketDropListener is implemented by collectors that send delta packets, when one of their packets is
+// dropped by the SDK's send queue they need to send a full update next (see controller.packetQueue).
+// It is also called when the server lost track of the deltas and asks for a full update (see ds.ResyncData).
+type PacketDropListener interface {
+	OnPacketDropped(pkType string)
+}
+
+// ApplyExecutorSettings applies the settings shared by the collectors that run on a PeriodicExecutor
+func ApplyExecutorSettings(executor *PeriodicExecutor, settings map[string]any) error {
+	for key, val := range settings {
+		switch key {
+		case ds.CollectorSettingPollIntervalMs:
+			// JSON numbers are decoded as float64
+			ms, ok := val.(float64)
+			if !ok || ms < 1 {
+				return fmt.Errorf("invalid %s value: %v", key, val)
+			}
+			if err := executor.SetDuration(time.Duration(ms) * time.Millisecond); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown collector setting: %s", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package dbpool
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/collector"
+	"github.com/outrigdev/outrig/pkg/collector/watch"
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/global"
+	"github.com/outrigdev/outrig/pkg/utilds"
+)
+
+// DBPoolTag is added to every database pool watch (search with #dbpool)
+const DBPoolTag = "dbpool"
+
+// PoolStats is the watch sample reported for a database pool (from sql.DBStats, see sdk/outrigsql).
+// This package doesn't import database/sql, so the SDK only depends on it when outrigsql is used.
+type PoolStats struct {
+	MaxOpenConnections int   `json:"maxopenconnections"` // 0 means unlimited
+	OpenConnections    int   `json:"openconnections"`
+	InUse              int   `json:"inuse"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"waitcount"`      // total number of connections waited for
+	WaitDurationMs     int64 `json:"waitdurationms"` // total time blocked waiting for a new connection
+	MaxIdleClosed      int64 `json:"maxidleclosed"`
+	MaxIdleTimeClosed  int64 `json:"maxidletimeclosed"`
+	MaxLifetimeClosed  int64 `json:"maxlifetimeclosed"`
+	Exhausted          bool  `json:"exhausted,omitempty"` // all connections are in use (only set when MaxOpenConnections > 0)
+}
+
+type poolEntry struct {
+	statsFn func() PoolStats
+	decl    *ds.WatchDecl
+}
+
+// DBPoolCollector implements the collector.Collector interface for database pool stats
+// the stats are reported as push watches (one per pool) through the watch collector
+type DBPoolCollector struct {
+	lock     sync.Mutex
+	config   *utilds.SetOnceConfig[config.DBPoolConfig]
+	executor *collector.PeriodicExecutor
+	pools    map[string]*poolEntry
+}
+
+// CollectorName returns the unique name of the collector
+func (dc *DBPoolCollector) CollectorName() string {
+	return "dbpool"
+}
+
+// singleton instance
+var instance *DBPoolCollector
+var instanceOnce sync.Once
+
+// GetInstance returns the singleton instance of DBPoolCollector
+func GetInstance() *DBPoolCollector {
+	instanceOnce.Do(func() {
+		instance = &DBPoolCollector{
+			config: utilds.NewSetOnceConfig(config.DefaultConfig().Collectors.DBPool),
+			pools:  make(map[string]*poolEntry),
+		}
+		instance.executor = collector.MakePeriodicExecutor("DBPoolCollector", 1*time.Second, instance.CollectDBPools)
+	})
+	return instance
+}
+
+func Init(cfg *config.DBPoolConfig) error {
+	dc := GetInstance()
+	if dc.executor.IsEnabled() {
+		return fmt.Errorf("dbpool collector is already initialized")
+	}
+	ok := dc.config.SetOnce(cfg)
+	if !ok {
+		return fmt.Errorf("dbpool collector configuration already set")
+	}
+	collector.RegisterCollector(dc)
+	return nil
+}
+
+// Enable is called when the collector should start collecting data
+func (dc *DBPoolCollector) Enable() {
+	cfg := dc.config.Get()
+	if !cfg.Enabled {
+		return
+	}
+	dc.executor.Enable()
+}
+
+// Disable stops the collector
+func (dc *DBPoolCollector) Disable() {
+	dc.executor.Disable()
+}
+
+// ApplySettings changes the collector settings at runtime (e.g. the poll interval)
+func (dc *DBPoolCollector) ApplySettings(settings map[string]any) error {
+	return collector.ApplyExecutorSettings(dc.executor, settings)
+}
+
+// OnNewConnection is called when a new connection is established
+func (dc *DBPoolCollector) OnNewConnection() {
+	// No action needed, the watch collector sends the full watch state on new connections
+}
+
+// RegisterPool registers the stats of a pool to be polled, they are reported as the push watch decl.Name
+func (dc *DBPoolCollector) RegisterPool(statsFn func() PoolStats, decl *ds.WatchDecl) error {
+	if statsFn == nil {
+		return fmt.Errorf("cannot watch database pool %q without stats", decl.Name)
+	}
+	dc.lock.Lock()
+	if _, exists := dc.pools[decl.Name]; exists {
+		dc.lock.Unlock()
+		return fmt.Errorf("cannot register database pool with duplicate name %q", decl.Name)
+	}
+	dc.pools[decl.Name] = &poolEntry{statsFn: statsFn, decl: decl}
+	dc.lock.Unlock()
+
+	wc := watch.GetInstance()
+	wc.RegisterWatchDecl(decl)
+	// push the first sample right away so the pool shows up before the next poll
+	wc.PushWatchSample(decl.Name, statsFn())
+	return nil
+}
+
+// UnregisterPool stops polling the named pool and unregisters its watch
+func (dc *DBPoolCollector) UnregisterPool(name string) {
+	dc.lock.Lock()
+	entry := dc.pools[name]
+	delete(dc.pools, name)
+	dc.lock.Unlock()
+	if entry == nil {
+		return
+	}
+	watch.GetInstance().UnregisterWatch(entry.decl)
+}
+
+func (dc *DBPoolCollector) getPools() []*poolEntry {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+	rtn := make([]*poolEntry, 0, len(dc.pools))
+	for _, entry := range dc.pools {
+		rtn = append(rtn, entry)
+	}
+	return rtn
+}
+
+// GetPoolNames returns the names of the registered pools (sorted)
+func (dc *DBPoolCollector) GetPoolNames() []string {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+	names := make([]string, 0, len(dc.pools))
+	for name := range dc.pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CollectDBPools polls the stats of every registered pool and pushes them to the watch collector
+func (dc *DBPoolCollector) CollectDBPools() {
+	if !global.OutrigEnabled.Load() {
+		return
+	}
+	wc := watch.GetInstance()
+	for _, entry := range dc.getPools() {
+		wc.PushWatchSample(entry.decl.Name, entry.statsFn())
+	}
+}
+
+// GetStatus returns the current status of the dbpool collector
+func (dc *DBPoolCollector) GetStatus() ds.CollectorStatus {
+	cfg := dc.config.Get()
+	status := ds.CollectorStatus{
+		Running: cfg.Enabled,
+	}
+
+	if !cfg.Enabled {
+		status.Info = "Disabled in configuration"
+	} else {
+		status.Info = fmt.Sprintf("Database pool collection active (%d pools)", len(dc.GetPoolNames()))
+		status.CollectDuration = dc.executor.GetLastExecDuration()
+		status.Overhead = dc.executor.GetOverhead()
+
+		if lastErr := dc.executor.GetLastErr(); lastErr != nil {
+			status.Errors = append(status.Errors, lastErr.Error())
+		}
+	}
+
+	return status
+}
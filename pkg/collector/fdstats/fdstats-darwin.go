@@ -0,0 +1,62 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build darwin
+
+package fdstats
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+const fdStatsSupported = true
+
+// sampleFDStats lists /dev/fd and inspects each socket with getsockopt/getpeername.
+// proc_pidinfo(PROC_PIDLISTFDS) would need libproc (cgo), this gives the same counts for our own process.
+func sampleFDStats() (*ds.FDStatsInfo, error) {
+	entries, err := os.ReadDir("/dev/fd")
+	if err != nil {
+		return nil, err
+	}
+	stats := &ds.FDStatsInfo{MaxFDs: getMaxFDs()}
+	for _, entry := range entries {
+		fd, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		var stat syscall.Stat_t
+		if err := syscall.Fstat(fd, &stat); err != nil {
+			// already closed (this includes the fd used to read the directory)
+			continue
+		}
+		stats.OpenFDs++
+		if stat.Mode&syscall.S_IFMT != syscall.S_IFSOCK {
+			continue
+		}
+		stats.Sockets++
+		if acceptConn, err := syscall.GetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_ACCEPTCONN); err == nil && acceptConn != 0 {
+			stats.Listening++
+			continue
+		}
+		if sockType, err := syscall.GetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_TYPE); err != nil || sockType != syscall.SOCK_STREAM {
+			continue
+		}
+		switch peer, _ := syscall.Getpeername(fd); peer.(type) {
+		case *syscall.SockaddrInet4, *syscall.SockaddrInet6:
+			stats.Established++
+		}
+	}
+	return stats, nil
+}
+
+func getMaxFDs() int {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return 0
+	}
+	return int(rlimit.Cur)
+}
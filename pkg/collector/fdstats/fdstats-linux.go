@@ -0,0 +1,101 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package fdstats
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+const fdStatsSupported = true
+
+const (
+	tcpStateEstablished = "01"
+	tcpStateListen      = "0A"
+	unixFlagAcceptConn  = 0x10000 // __SO_ACCEPTCON in /proc/net/unix
+)
+
+// sampleFDStats reads /proc/self/fd, sockets are matched by inode against /proc/self/net/{tcp,tcp6,unix}
+func sampleFDStats() (*ds.FDStatsInfo, error) {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return nil, err
+	}
+	stats := &ds.FDStatsInfo{MaxFDs: getMaxFDs()}
+	socketInodes := make(map[string]bool)
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join("/proc/self/fd", entry.Name()))
+		if err != nil {
+			// already closed (this includes the fd used to read the directory)
+			continue
+		}
+		stats.OpenFDs++
+		if strings.HasPrefix(target, "socket:[") {
+			stats.Sockets++
+			socketInodes[strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")] = true
+		}
+	}
+	if len(socketInodes) == 0 {
+		return stats, nil
+	}
+	for _, name := range []string{"tcp", "tcp6"} {
+		scanProcNetFile("/proc/self/net/"+name, func(fields []string) {
+			// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+			if len(fields) < 10 || !socketInodes[fields[9]] {
+				return
+			}
+			switch fields[3] {
+			case tcpStateListen:
+				stats.Listening++
+			case tcpStateEstablished:
+				stats.Established++
+			}
+		})
+	}
+	scanProcNetFile("/proc/self/net/unix", func(fields []string) {
+		// Num RefCount Protocol Flags Type St Inode Path
+		if len(fields) < 7 || !socketInodes[fields[6]] {
+			return
+		}
+		flags, err := strconv.ParseUint(fields[3], 16, 64)
+		if err == nil && flags&unixFlagAcceptConn != 0 {
+			stats.Listening++
+		}
+	})
+	return stats, nil
+}
+
+// scanProcNetFile calls fn with the fields of every line after the header (missing files are ignored)
+func scanProcNetFile(path string, fn func(fields []string)) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	isHeader := true
+	for scanner.Scan() {
+		if isHeader {
+			isHeader = false
+			continue
+		}
+		fn(strings.Fields(scanner.Text()))
+	}
+}
+
+func getMaxFDs() int {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return 0
+	}
+	return int(rlimit.Cur)
+}
@@ -0,0 +1,18 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !linux && !darwin
+
+package fdstats
+
+import (
+	"errors"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+const fdStatsSupported = false
+
+func sampleFDStats() (*ds.FDStatsInfo, error) {
+	return nil, errors.New("file descriptor stats are not supported on this platform")
+}
@@ -0,0 +1,150 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package fdstats
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/collector"
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/global"
+	"github.com/outrigdev/outrig/pkg/utilds"
+)
+
+// FDStatsPollInterval is how often the fd table is scanned (scanning is O(open fds), so it runs less often than the runtime stats)
+const FDStatsPollInterval = 5 * time.Second
+
+// FDStatsCollector implements the collector.Collector interface for open file descriptor and socket counts.
+// It doesn't send its own packets, the last sample is attached to the runtime stats (see GetLastStats).
+type FDStatsCollector struct {
+	lock      sync.Mutex
+	config    *utilds.SetOnceConfig[config.FDStatsConfig]
+	executor  *collector.PeriodicExecutor
+	lastStats atomic.Pointer[ds.FDStatsInfo]
+	sampleErr error // protected by lock
+}
+
+// CollectorName returns the unique name of the collector
+func (fc *FDStatsCollector) CollectorName() string {
+	return "fdstats"
+}
+
+// singleton instance
+var instance *FDStatsCollector
+var instanceOnce sync.Once
+
+// GetInstance returns the singleton instance of FDStatsCollector
+func GetInstance() *FDStatsCollector {
+	instanceOnce.Do(func() {
+		instance = &FDStatsCollector{
+			config: utilds.NewSetOnceConfig(config.DefaultConfig().Collectors.FDStats),
+		}
+		instance.executor = collector.MakePeriodicExecutor("FDStatsCollector", FDStatsPollInterval, instance.CollectFDStats)
+	})
+	return instance
+}
+
+func Init(cfg *config.FDStatsConfig) error {
+	fc := GetInstance()
+	if fc.executor.IsEnabled() {
+		return fmt.Errorf("fdstats collector is already initialized")
+	}
+	ok := fc.config.SetOnce(cfg)
+	if !ok {
+		return fmt.Errorf("fdstats collector configuration already set")
+	}
+	collector.RegisterCollector(fc)
+	return nil
+}
+
+// Enable is called when the collector should start collecting data
+func (fc *FDStatsCollector) Enable() {
+	cfg := fc.config.Get()
+	if !cfg.Enabled || !fdStatsSupported {
+		return
+	}
+	fc.executor.Enable()
+}
+
+// Disable stops the collector (the runtime stats stop including fd stats)
+func (fc *FDStatsCollector) Disable() {
+	fc.executor.Disable()
+	fc.lastStats.Store(nil)
+}
+
+// ApplySettings changes the collector settings at runtime (e.g. the poll interval)
+func (fc *FDStatsCollector) ApplySettings(settings map[string]any) error {
+	return collector.ApplyExecutorSettings(fc.executor, settings)
+}
+
+// OnNewConnection is called when a new connection is established
+func (fc *FDStatsCollector) OnNewConnection() {
+	// No action needed, the stats are sent with the runtime stats
+}
+
+// CollectFDStats samples the fd table of the process
+func (fc *FDStatsCollector) CollectFDStats() {
+	if !global.OutrigEnabled.Load() {
+		return
+	}
+	stats, err := sampleFDStats()
+	fc.lock.Lock()
+	fc.sampleErr = err
+	fc.lock.Unlock()
+	if err != nil {
+		return
+	}
+	stats.Ts = time.Now().UnixMilli()
+	fc.lastStats.Store(stats)
+}
+
+func (fc *FDStatsCollector) getSampleErr() error {
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+	return fc.sampleErr
+}
+
+// GetLastStats returns the most recent sample (nil if the collector is disabled or hasn't run yet)
+func (fc *FDStatsCollector) GetLastStats() *ds.FDStatsInfo {
+	if !fc.executor.IsEnabled() {
+		return nil
+	}
+	return fc.lastStats.Load()
+}
+
+// GetStatus returns the current status of the fdstats collector
+func (fc *FDStatsCollector) GetStatus() ds.CollectorStatus {
+	cfg := fc.config.Get()
+	status := ds.CollectorStatus{
+		Running: cfg.Enabled,
+	}
+
+	if !cfg.Enabled {
+		status.Info = "Disabled in configuration"
+	} else if !fdStatsSupported {
+		status.Running = false
+		status.Info = "Not supported on this platform"
+	} else {
+		if stats := fc.lastStats.Load(); stats != nil {
+			status.Info = fmt.Sprintf("Tracking %d open fds (%d sockets)", stats.OpenFDs, stats.Sockets)
+		} else {
+			status.Info = "File descriptor collection active"
+		}
+		status.CollectDuration = fc.executor.GetLastExecDuration()
+		status.Overhead = fc.executor.GetOverhead()
+
+		if lastErr := fc.executor.GetLastErr(); lastErr != nil {
+			status.Errors = append(status.Errors, lastErr.Error())
+		}
+		if sampleErr := fc.getSampleErr(); sampleErr != nil {
+			status.Errors = append(status.Errors, sampleErr.Error())
+		}
+	}
+
+	return status
+}
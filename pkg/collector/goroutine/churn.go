@@ -0,0 +1,97 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package goroutine
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+// churnTracker counts the goroutines started with the SDK's Run() func by call site, between two reports
+type churnTracker struct {
+	lock      sync.Mutex
+	sinceTs   int64
+	callSites map[string]*ds.GoroutineChurnCallSite // call site => counts since sinceTs
+}
+
+// getChurnCallSite_nolock returns the counts for the call site of decl (nil for goroutines not started with Run())
+func (ct *churnTracker) getChurnCallSite_nolock(decl *ds.GoDecl) *ds.GoroutineChurnCallSite {
+	if decl.RealCreatedBy == "" {
+		return nil
+	}
+	frame, _, ok := parseCreatedBy(decl.RealCreatedBy)
+	if !ok || frame.Location() == "" {
+		return nil
+	}
+	callSite := frame.Location()
+	if ct.callSites == nil {
+		ct.callSites = make(map[string]*ds.GoroutineChurnCallSite)
+	}
+	if ct.sinceTs == 0 {
+		ct.sinceTs = time.Now().UnixMilli()
+	}
+	cs := ct.callSites[callSite]
+	if cs == nil {
+		cs = &ds.GoroutineChurnCallSite{CallSite: callSite, Func: frame.Package + "." + frame.FuncName}
+		ct.callSites[callSite] = cs
+	}
+	return cs
+}
+
+func (ct *churnTracker) recordStart(decl *ds.GoDecl) {
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+	cs := ct.getChurnCallSite_nolock(decl)
+	if cs == nil {
+		return
+	}
+	cs.Started++
+	if decl.Name != "" {
+		cs.Name = decl.Name
+	}
+}
+
+func (ct *churnTracker) recordEnd(decl *ds.GoDecl, endTs int64) {
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+	cs := ct.getChurnCallSite_nolock(decl)
+	if cs == nil {
+		return
+	}
+	cs.Finished++
+	if atomic.LoadInt64(&decl.FirstPollTs) == 0 {
+		cs.ShortLived++
+	}
+	if decl.StartTs > 0 && endTs > decl.StartTs {
+		cs.RunTimeMs += endTs - decl.StartTs
+	}
+}
+
+// getChurnInfo returns the counts since the last call (sorted by call site) and resets them,
+// it returns nil if no goroutines were started or finished
+func (ct *churnTracker) getChurnInfo(ts int64) *ds.GoroutineChurnInfo {
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+	sinceTs := ct.sinceTs
+	ct.sinceTs = ts
+	if len(ct.callSites) == 0 {
+		return nil
+	}
+	info := &ds.GoroutineChurnInfo{
+		Ts:      ts,
+		SinceTs: sinceTs,
+	}
+	for _, cs := range ct.callSites {
+		info.CallSites = append(info.CallSites, *cs)
+	}
+	sort.Slice(info.CallSites, func(i, j int) bool {
+		return info.CallSites[i].CallSite < info.CallSites[j].CallSite
+	})
+	ct.callSites = nil
+	return info
+}
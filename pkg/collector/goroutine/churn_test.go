@@ -0,0 +1,61 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package goroutine
+
+import (
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func makeTestChurnDecl(line string, startTs int64) *ds.GoDecl {
+	return &ds.GoDecl{
+		RealCreatedBy: "created by main.startWorkers in goroutine 1\n\t/src/app/main.go:" + line + " +0x45",
+		StartTs:       startTs,
+	}
+}
+
+func TestChurnTracker(t *testing.T) {
+	var ct churnTracker
+	if info := ct.getChurnInfo(1000); info != nil {
+		t.Fatalf("got %+v, want nil with no goroutines", info)
+	}
+
+	// a short lived goroutine (never polled) and a polled one from the same call site
+	shortLived := makeTestChurnDecl("10", 1000)
+	shortLived.Name = "worker"
+	polled := makeTestChurnDecl("10", 1200)
+	polled.FirstPollTs = 1500
+	other := makeTestChurnDecl("20", 1300)
+	ct.recordStart(shortLived)
+	ct.recordStart(polled)
+	ct.recordStart(other)
+	ct.recordEnd(shortLived, 1100)
+	ct.recordEnd(polled, 2200)
+	// goroutines not started with Run() are not counted
+	ct.recordStart(&ds.GoDecl{StartTs: 1000})
+
+	info := ct.getChurnInfo(3000)
+	if info == nil || info.Ts != 3000 || len(info.CallSites) != 2 {
+		t.Fatalf("got %+v, want 2 call sites", info)
+	}
+	cs := info.CallSites[0]
+	expect := ds.GoroutineChurnCallSite{CallSite: "/src/app/main.go:10", Func: "main.startWorkers", Name: "worker", Started: 2, Finished: 2, ShortLived: 1, RunTimeMs: 1100}
+	if cs != expect {
+		t.Errorf("got %+v, want %+v", cs, expect)
+	}
+	if cs := info.CallSites[1]; cs.CallSite != "/src/app/main.go:20" || cs.Started != 1 || cs.Finished != 0 {
+		t.Errorf("got %+v for the second call site", cs)
+	}
+
+	// the counts are reset and the next report starts at the last one
+	if info := ct.getChurnInfo(4000); info != nil {
+		t.Errorf("got %+v, want nil after the reset", info)
+	}
+	ct.recordEnd(other, 4500)
+	info = ct.getChurnInfo(5000)
+	if info == nil || info.SinceTs != 4000 || len(info.CallSites) != 1 || info.CallSites[0].Finished != 1 || info.CallSites[0].RunTimeMs != 3200 {
+		t.Errorf("got %+v, want the finished goroutine since the last report", info)
+	}
+}
@@ -9,8 +9,8 @@ import (
 	"log"
 	"regexp"
 	"runtime"
+	"runtime/debug"
 	"slices"
-	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -20,7 +20,10 @@ import (
 	"github.com/outrigdev/outrig/pkg/config"
 	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/global"
+	"github.com/outrigdev/outrig/pkg/platform"
+	"github.com/outrigdev/outrig/pkg/stackparse"
 	"github.com/outrigdev/outrig/pkg/utilds"
+	"github.com/outrigdev/outrig/pkg/utilfn"
 )
 
 // MinStackBufferSize is the minimum buffer size for goroutine stack dumps (1MB)
@@ -30,8 +33,8 @@ const SingleStackBufferSize = 8 * 1024
 // GoroutinePollInterval is how often we poll for goroutine stacks
 const GoroutinePollInterval = 1 * time.Second
 
-// GoroutineGracePeriod is how long to keep goroutine declarations before cleanup
-const GoroutineGracePeriod = 2 * GoroutinePollInterval
+// GoroutineGracePeriod is how long to keep goroutine declarations before cleanup (in poll intervals)
+const GoroutineGracePeriod = 2
 
 const (
 	GoState_Init    = 0
@@ -55,6 +58,7 @@ type GoroutineCollector struct {
 	lastStackSize       int                         // last actual stack size (not buffer size)
 	updatedDecls        []ds.GoDecl                 // declarations updated since last send
 	callSiteCounts      map[string]callSiteInfo     // tracks call site information for goroutines
+	churn               churnTracker                // goroutines started/finished between reports (by call site)
 }
 
 // CollectorName returns the unique name of the collector
@@ -69,19 +73,24 @@ var instanceOnce sync.Once
 // GetInstance returns the singleton instance of GoroutineCollector
 func GetInstance() *GoroutineCollector {
 	instanceOnce.Do(func() {
-		instance = &GoroutineCollector{
-			config:              utilds.NewSetOnceConfig(config.DefaultConfig().Collectors.Goroutine),
-			goroutineDecls:      make(map[int64]*ds.GoDecl),
-			lastGoroutineStacks: make(map[int64]ds.GoRoutineStack),
-			nextSendFull:        true,               // First send is always a full update
-			lastStackSize:       MinStackBufferSize, // Start with minimum stack size estimate
-			callSiteCounts:      make(map[string]callSiteInfo),
-		}
-		instance.executor = collector.MakePeriodicExecutor("GoroutineCollector", GoroutinePollInterval, instance.DumpGoroutines)
+		instance = makeGoroutineCollector()
 	})
 	return instance
 }
 
+func makeGoroutineCollector() *GoroutineCollector {
+	gc := &GoroutineCollector{
+		config:              utilds.NewSetOnceConfig(config.DefaultConfig().Collectors.Goroutine),
+		goroutineDecls:      make(map[int64]*ds.GoDecl),
+		lastGoroutineStacks: make(map[int64]ds.GoRoutineStack),
+		nextSendFull:        true,               // First send is always a full update
+		lastStackSize:       MinStackBufferSize, // Start with minimum stack size estimate
+		callSiteCounts:      make(map[string]callSiteInfo),
+	}
+	gc.executor = collector.MakePeriodicExecutor("GoroutineCollector", GoroutinePollInterval, gc.DumpGoroutines)
+	return gc
+}
+
 func Init(cfg *config.GoRoutineConfig) error {
 	gc := GetInstance()
 	if gc.executor.IsEnabled() {
@@ -98,7 +107,7 @@ func Init(cfg *config.GoRoutineConfig) error {
 // Enable is called when the collector should start collecting data
 func (gc *GoroutineCollector) Enable() {
 	cfg := gc.config.Get()
-	if !cfg.Enabled {
+	if !cfg.Enabled || !platform.GoRoutineStacks {
 		return
 	}
 	gc.executor.Enable()
@@ -108,19 +117,29 @@ func (gc *GoroutineCollector) Disable() {
 	gc.executor.Disable()
 }
 
+// ApplySettings changes the collector settings at runtime (e.g. the poll interval)
+func (gc *GoroutineCollector) ApplySettings(settings map[string]any) error {
+	return collector.ApplyExecutorSettings(gc.executor, settings)
+}
+
 func (gc *GoroutineCollector) setGoRoutineDecl(decl *ds.GoDecl) {
 	gc.lock.Lock()
 	defer gc.lock.Unlock()
 	if decl.GoId == 0 {
 		return
 	}
-	if gc.goroutineDecls[decl.GoId] != nil {
-		return
+	if existing := gc.goroutineDecls[decl.GoId]; existing != nil {
+		// a goroutine started with Run() can be polled before it records its decl, its decl replaces the polled one
+		if decl.StartTs == 0 || existing.StartTs != 0 {
+			return
+		}
+		decl.FirstPollTs = atomic.LoadInt64(&existing.FirstPollTs)
+		decl.LastPollTs = atomic.LoadInt64(&existing.LastPollTs)
 	}
 	gc.goroutineDecls[decl.GoId] = decl
 
 	// Add to updated declarations (make a copy to avoid reference issues)
-	declCopy := *decl
+	declCopy := copyGoDecl(decl)
 	gc.updatedDecls = append(gc.updatedDecls, declCopy)
 }
 
@@ -133,7 +152,7 @@ func (gc *GoroutineCollector) incrementParentSpawnCount(parentGoId int64) {
 		atomic.AddInt64(&parentDecl.NumSpawned, 1)
 
 		// Add to updated declarations (make a copy to avoid reference issues)
-		declCopy := *parentDecl
+		declCopy := copyGoDecl(parentDecl)
 		gc.updatedDecls = append(gc.updatedDecls, declCopy)
 	}
 }
@@ -172,7 +191,7 @@ func (gc *GoroutineCollector) backpatchFirstCallSiteDecl_nolock(initialGoId int6
 	if decl, exists := gc.goroutineDecls[initialGoId]; exists && decl.CSNum == 0 {
 		decl.CSNum = 1
 		// Add to updated declarations
-		declCopy := *decl
+		declCopy := copyGoDecl(decl)
 		gc.updatedDecls = append(gc.updatedDecls, declCopy)
 	}
 }
@@ -184,7 +203,7 @@ func (gc *GoroutineCollector) UpdateGoRoutineName(decl *ds.GoDecl, newName strin
 	decl.Name = newName
 
 	// Add to updated declarations (make a copy to avoid reference issues)
-	declCopy := *decl
+	declCopy := copyGoDecl(decl)
 	gc.updatedDecls = append(gc.updatedDecls, declCopy)
 }
 
@@ -195,7 +214,7 @@ func (gc *GoroutineCollector) UpdateGoRoutineTags(decl *ds.GoDecl, newTags []str
 	decl.Tags = newTags
 
 	// Add to updated declarations (make a copy to avoid reference issues)
-	declCopy := *decl
+	declCopy := copyGoDecl(decl)
 	gc.updatedDecls = append(gc.updatedDecls, declCopy)
 }
 
@@ -206,7 +225,7 @@ func (gc *GoroutineCollector) UpdateGoRoutinePkg(decl *ds.GoDecl, newPkg string)
 	decl.Pkg = newPkg
 
 	// Add to updated declarations (make a copy to avoid reference issues)
-	declCopy := *decl
+	declCopy := copyGoDecl(decl)
 	gc.updatedDecls = append(gc.updatedDecls, declCopy)
 }
 
@@ -217,7 +236,21 @@ func (gc *GoroutineCollector) UpdateGoRoutineGroup(decl *ds.GoDecl, newGroup str
 	decl.Group = newGroup
 
 	// Add to updated declarations (make a copy to avoid reference issues)
-	declCopy := *decl
+	declCopy := copyGoDecl(decl)
+	gc.updatedDecls = append(gc.updatedDecls, declCopy)
+}
+
+func (gc *GoroutineCollector) UpdateGoRoutineStatus(decl *ds.GoDecl, newStatus string) {
+	// we use the gc.Lock to synchronize access to existing decls
+	gc.lock.Lock()
+	defer gc.lock.Unlock()
+	if decl.Status == newStatus {
+		return
+	}
+	decl.Status = newStatus
+
+	// Add to updated declarations (make a copy to avoid reference issues)
+	declCopy := copyGoDecl(decl)
 	gc.updatedDecls = append(gc.updatedDecls, declCopy)
 }
 
@@ -232,38 +265,27 @@ func (gc *GoroutineCollector) setInitialGoDeclInfo(decl *ds.GoDecl, stack []byte
 		}
 	}
 
-	// Extract the goroutine ID from the stack trace
+	stackStr := strings.TrimSpace(string(stack))
+
+	// Extract the goroutine ID from the stack trace header
 	if decl.GoId == 0 {
-		goMatches := goCreationRe.FindSubmatch(stack)
-		if len(goMatches) >= 2 {
-			goId, err := strconv.ParseInt(string(goMatches[1]), 10, 64)
-			if err == nil {
-				decl.GoId = goId
-			}
+		firstLine, _, _ := strings.Cut(stackStr, "\n")
+		if header, ok := stackparse.ParseHeader(firstLine); ok {
+			decl.GoId = header.GoId
 		}
 	}
 
-	// Extract the parent goroutine ID from the stack trace
-	if decl.ParentGoId == 0 {
-		parentMatches := parentGoRe.FindSubmatch(stack)
-		if len(parentMatches) >= 2 {
-			parentGoId, err := strconv.ParseInt(string(parentMatches[1]), 10, 64)
-			if err == nil {
+	// Extract the parent goroutine ID, package name, and function name from the "created by" frame
+	if decl.ParentGoId == 0 || decl.Pkg == "" || decl.Func == "" {
+		if frame, parentGoId, ok := parseCreatedBy(stackStr); ok {
+			if decl.ParentGoId == 0 {
 				decl.ParentGoId = parentGoId
 			}
-		}
-	}
-
-	// Extract the package name and function name from the stack trace
-	if decl.Pkg == "" || decl.Func == "" {
-		createdByMatches := createdByRe.FindSubmatch(stack)
-		if len(createdByMatches) >= 2 {
-			funcName := string(createdByMatches[1])
 			if decl.Pkg == "" {
-				decl.Pkg = extractPackage(funcName)
+				decl.Pkg = frame.Package
 			}
 			if decl.Func == "" {
-				decl.Func = extractFunction(funcName)
+				decl.Func = extractFunction(frame.FuncName)
 			}
 		}
 	}
@@ -271,21 +293,49 @@ func (gc *GoroutineCollector) setInitialGoDeclInfo(decl *ds.GoDecl, stack []byte
 	// Extract call site and assign CSNum
 	if decl.CSNum == 0 {
 		// Use patched stack for call site extraction
-		stackStr := strings.TrimSpace(string(stack))
 		patchedStack := patchCreatedByStack(decl, stackStr)
-		callSite := extractCallSite(patchedStack)
-		if callSite != "" {
-			decl.CSNum = gc.getNextCallSiteNum(callSite, decl.GoId)
+		if frame, _, ok := parseCreatedBy(patchedStack); ok && frame.Location() != "" {
+			decl.CSNum = gc.getNextCallSiteNum(frame.Location(), decl.GoId)
 		}
 	}
 }
 
+// InheritParentInfo prefixes the name of a goroutine that hasn't started yet with the name of its
+// parent goroutine and adds the parent's tags (when GoRoutineConfig.InheritParentName is set).
+// Nothing is inherited from unnamed parents.
+func (gc *GoroutineCollector) InheritParentInfo(decl *ds.GoDecl, parentGoId int64) {
+	cfg := gc.config.Get()
+	if !cfg.InheritParentName || parentGoId <= 0 {
+		return
+	}
+	parentDecl, ok := gc.GetGoRoutineDeclCopy(parentGoId)
+	if !ok || parentDecl.Name == "" {
+		return
+	}
+	separator := cfg.InheritNameSeparator
+	if separator == "" {
+		separator = config.DefaultInheritNameSeparator
+	}
+	if decl.Name == "" {
+		decl.Name = parentDecl.Name
+	} else {
+		decl.Name = parentDecl.Name + separator + decl.Name
+	}
+	if len(parentDecl.Tags) > 0 {
+		decl.Tags = utilfn.CleanTagSlice(append(append([]string{}, parentDecl.Tags...), decl.Tags...))
+	}
+	if decl.ParentGoId == 0 {
+		decl.ParentGoId = parentGoId
+	}
+}
+
 func (gc *GoroutineCollector) RecordGoRoutineStart(decl *ds.GoDecl, stack []byte) {
 	gc.setInitialGoDeclInfo(decl, stack)
 	if decl.ParentGoId != 0 {
 		gc.incrementParentSpawnCount(decl.ParentGoId)
 	}
 	gc.setGoRoutineDecl(decl)
+	gc.churn.recordStart(decl)
 }
 
 func (gc *GoroutineCollector) RecordGoRoutineEnd(decl *ds.GoDecl, panicVal any, flush bool) {
@@ -295,9 +345,72 @@ func (gc *GoroutineCollector) RecordGoRoutineEnd(decl *ds.GoDecl, panicVal any,
 
 	// Add to updated declarations (make a copy to avoid reference issues)
 	gc.lock.Lock()
-	defer gc.lock.Unlock()
-	declCopy := *decl
+	declCopy := copyGoDecl(decl)
 	gc.updatedDecls = append(gc.updatedDecls, declCopy)
+	gc.lock.Unlock()
+	gc.churn.recordEnd(decl, endTs)
+
+	if panicVal != nil {
+		// we are still running inside the deferred func, so debug.Stack() includes the panicking frames
+		gc.sendPanicInfo(&declCopy, panicVal, debug.Stack(), endTs, !flush)
+		if flush {
+			// the panic is about to crash the program, give a small delay to allow the packet to be sent
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+}
+
+func (gc *GoroutineCollector) sendPanicInfo(decl *ds.GoDecl, panicVal any, stack []byte, ts int64, recovered bool) {
+	if !global.OutrigEnabled.Load() {
+		return
+	}
+	ctl := global.GetController()
+	if ctl == nil {
+		return
+	}
+	panicInfo := &ds.PanicInfo{
+		GoId:       decl.GoId,
+		Ts:         ts,
+		Name:       decl.Name,
+		Tags:       decl.Tags,
+		PanicVal:   formatPanicVal(panicVal),
+		PanicType:  fmt.Sprintf("%T", panicVal),
+		Recovered:  recovered,
+		StackTrace: trimPanicStack(string(stack)),
+	}
+	pk := &ds.PacketType{
+		Type: ds.PacketTypePanic,
+		Data: panicInfo,
+	}
+	ctl.SendPacket(pk)
+}
+
+func formatPanicVal(panicVal any) string {
+	if err, ok := panicVal.(error); ok {
+		return err.Error()
+	}
+	if s, ok := panicVal.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%v", panicVal)
+}
+
+// trimPanicStack removes the goroutine header and the SDK frames that sit above the panic() frame
+// (debug.Stack, RecordGoRoutineEnd, and the deferred func) so the trace starts where the panic was raised
+func trimPanicStack(stack string) string {
+	stack = strings.TrimSpace(stack)
+	if strings.HasPrefix(stack, "goroutine ") {
+		if idx := strings.Index(stack, "\n"); idx != -1 {
+			stack = stack[idx+1:]
+		}
+	}
+	if strings.HasPrefix(stack, "panic(") {
+		return stack
+	}
+	if idx := strings.Index(stack, "\npanic("); idx != -1 {
+		return stack[idx+1:]
+	}
+	return stack
 }
 
 // getSendFullAndReset returns the current sendFull value and always sets it to false
@@ -323,6 +436,13 @@ func (gc *GoroutineCollector) OnNewConnection() {
 	gc.SetNextSendFull(true)
 }
 
+// OnPacketDropped is called when a packet was dropped by the send queue (the next dump must be a full update)
+func (gc *GoroutineCollector) OnPacketDropped(pkType string) {
+	if pkType == ds.PacketTypeGoroutine {
+		gc.SetNextSendFull(true)
+	}
+}
+
 func (gc *GoroutineCollector) getLastStackSize() int {
 	gc.lock.Lock()
 	defer gc.lock.Unlock()
@@ -344,10 +464,7 @@ func (gc *GoroutineCollector) getDeclList(delta bool) []ds.GoDecl {
 
 	if !delta {
 		// For full updates, return all declarations
-		declList := make([]ds.GoDecl, 0, len(gc.goroutineDecls))
-		for _, decl := range gc.goroutineDecls {
-			declList = append(declList, *decl)
-		}
+		declList := gc.getAllDecls_nolock()
 		// Clear updated declarations after a full update
 		gc.updatedDecls = nil
 		return declList
@@ -359,6 +476,41 @@ func (gc *GoroutineCollector) getDeclList(delta bool) []ds.GoDecl {
 	return declList
 }
 
+func (gc *GoroutineCollector) getAllDecls_nolock() []ds.GoDecl {
+	declList := make([]ds.GoDecl, 0, len(gc.goroutineDecls))
+	for _, decl := range gc.goroutineDecls {
+		declList = append(declList, copyGoDecl(decl))
+	}
+	return declList
+}
+
+// copyGoDecl copies a decl, the fields that are updated without the collector lock (State, EndTs,
+// NumSpawned, StartTs, and the poll timestamps) are read atomically
+func copyGoDecl(decl *ds.GoDecl) ds.GoDecl {
+	return ds.GoDecl{
+		GoId:          decl.GoId,
+		Name:          decl.Name,
+		Group:         decl.Group,
+		Pool:          decl.Pool,
+		Tags:          decl.Tags,
+		Pkg:           decl.Pkg,
+		Func:          decl.Func,
+		NewLine:       decl.NewLine,
+		RunLine:       decl.RunLine,
+		NoRecover:     decl.NoRecover,
+		ParentGoId:    decl.ParentGoId,
+		NumSpawned:    atomic.LoadInt64(&decl.NumSpawned),
+		State:         atomic.LoadInt32(&decl.State),
+		StartTs:       atomic.LoadInt64(&decl.StartTs),
+		EndTs:         atomic.LoadInt64(&decl.EndTs),
+		FirstPollTs:   atomic.LoadInt64(&decl.FirstPollTs),
+		LastPollTs:    atomic.LoadInt64(&decl.LastPollTs),
+		CSNum:         decl.CSNum,
+		RealCreatedBy: decl.RealCreatedBy,
+		Status:        decl.Status,
+	}
+}
+
 // dumpAllStacks gets all goroutine stacks, automatically increasing buffer size if needed
 // and storing the last successful buffer size for future calls
 func (gc *GoroutineCollector) dumpAllStacks() []byte {
@@ -401,13 +553,43 @@ func (gc *GoroutineCollector) DumpGoroutines() {
 	}
 	timestamp := time.Now().UnixMilli()
 	stackData := gc.dumpAllStacks()
-	sendFull := gc.getSendFullAndReset()
+	// servers that don't support delta updates get a full update every time
+	sendFull := gc.getSendFullAndReset() || ctl.GetProtocolFeatures().DeltaVersion < ds.DeltaVersionBase
 	goroutineInfo := gc.parseGoroutineStacks(stackData, !sendFull, timestamp)
 	pk := &ds.PacketType{
 		Type: ds.PacketTypeGoroutine,
 		Data: goroutineInfo,
 	}
 	ctl.SendPacket(pk)
+	if churnInfo := gc.churn.getChurnInfo(timestamp); churnInfo != nil {
+		ctl.SendPacket(&ds.PacketType{
+			Type: ds.PacketTypeGoroutineChurn,
+			Data: churnInfo,
+		})
+	}
+}
+
+// DumpGoroutinesFull returns a full dump of all goroutines (stacks and declarations) without sending it.
+// It doesn't change the state of the regular (delta) updates, it is used for the goroutine dumps requested
+// by the server outside the regular poll (see ds.GoroutineDumpData).
+func (gc *GoroutineCollector) DumpGoroutinesFull() *ds.GoroutineInfo {
+	timestamp := time.Now().UnixMilli()
+	stackData := gc.dumpAllStacks()
+	goroutineStacks := make([]ds.GoRoutineStack, 0)
+	filter := makeStackFilter(gc.config.Get())
+	scanner := stackparse.NewScanner(bytes.NewReader(stackData))
+	for scanner.Scan() {
+		goroutineStacks = append(goroutineStacks, gc.makeGoRoutineStack(scanner, timestamp, filter))
+	}
+	gc.lock.Lock()
+	declList := gc.getAllDecls_nolock()
+	gc.lock.Unlock()
+	return &ds.GoroutineInfo{
+		Ts:     timestamp,
+		Count:  len(goroutineStacks),
+		Stacks: goroutineStacks,
+		Decls:  declList,
+	}
 }
 
 // GetGoRoutineName gets the name for a goroutine
@@ -442,33 +624,7 @@ func (gc *GoroutineCollector) GetGoRoutineDeclCopy(goId int64) (ds.GoDecl, bool)
 	if !ok {
 		return ds.GoDecl{}, false
 	}
-	return *decl, true
-}
-
-var startRe = regexp.MustCompile(`(?m)^goroutine\s+\d+`)
-var stackRe = regexp.MustCompile(`goroutine (\d+) \[([^\]]+)\].*\n((?s).*)`)
-var goCreationRe = regexp.MustCompile(`goroutine (\d+) \[([^\]]+)\]`)
-var parentGoRe = regexp.MustCompile(`created by .* in goroutine (\d+)`)
-var createdByRe = regexp.MustCompile(`created by\s+(\S+)`)
-var callSiteRe = regexp.MustCompile(`(?m)^created by[^\n]*\n\s+(\S+\.go:\d+)`)
-
-// extractPackage extracts the package name from a stack trace function name
-func extractPackage(funcName string) string {
-	lastSlash := strings.LastIndex(funcName, "/")
-	if lastSlash == -1 {
-		// No slash, look for first dot
-		if dot := strings.Index(funcName, "."); dot != -1 {
-			return funcName[:dot]
-		}
-		return funcName
-	}
-
-	// Find first dot after last slash
-	remaining := funcName[lastSlash:]
-	if dot := strings.Index(remaining, "."); dot != -1 {
-		return funcName[:lastSlash+dot]
-	}
-	return funcName
+	return copyGoDecl(decl), true
 }
 
 // extractFunction extracts the function name from a stack trace function name,
@@ -493,15 +649,19 @@ func extractFunction(funcName string) string {
 	return funcName
 }
 
-// extractCallSite extracts the call site (file:line) from a goroutine stack trace
-// It looks for the line after "created by" which contains the file:line information
+// parseCreatedBy parses the "created by" frame at the end of a goroutine stack trace (or a GoDecl.RealCreatedBy),
+// returning the frame (its Location() is the call site) and the id of the creating goroutine
 // Example input: "created by github.com/outrigdev/outrig/server/pkg/gensearch.init.0 in goroutine 1\n\t/Users/mike/work/outrig/server/pkg/gensearch/searchmanager.go:201 +0x24"
-// Returns: "/Users/mike/work/outrig/server/pkg/gensearch/searchmanager.go:201"
-func extractCallSite(stack string) string {
-	if m := callSiteRe.FindStringSubmatch(stack); m != nil {
-		return m[1]
+func parseCreatedBy(stack string) (*stackparse.Frame, int64, bool) {
+	idx := strings.LastIndex(stack, "\ncreated by ")
+	if idx >= 0 {
+		stack = stack[idx+1:]
+	} else if !strings.HasPrefix(stack, "created by ") {
+		return nil, 0, false
 	}
-	return ""
+	funcLine, rest, _ := strings.Cut(stack, "\n")
+	fileLine, _, _ := strings.Cut(rest, "\n")
+	return stackparse.ParseCreatedByFrame(funcLine, strings.TrimSpace(fileLine))
 }
 
 // computeDeltaStack compares current and last goroutine stack and returns a delta stack
@@ -518,7 +678,8 @@ func (gc *GoroutineCollector) computeDeltaStack(id int64, current ds.GoRoutineSt
 	allSame := lastStack.State == current.State &&
 		lastStack.StackTrace == current.StackTrace &&
 		lastStack.Name == current.Name &&
-		slices.Equal(lastStack.Tags, current.Tags)
+		slices.Equal(lastStack.Tags, current.Tags) &&
+		lastStack.Labels == current.Labels
 
 	if allSame {
 		// All fields are the same, clear all fields and set Same
@@ -547,49 +708,47 @@ func (gc *GoroutineCollector) logInfo() {
 	log.Printf("#grnames %v", grNames)
 }
 
+// makeGoRoutineStack returns the (full) stack of the goroutine the scanner is at
+func (gc *GoroutineCollector) makeGoRoutineStack(scanner *stackparse.Scanner, timestamp int64, filter *stackFilter) ds.GoRoutineStack {
+	header := scanner.Header()
+	// the goroutine's pprof labels (if any) are sent raw and parsed by the monitor
+	grStack := ds.GoRoutineStack{
+		GoId:       header.GoId,
+		Ts:         timestamp,
+		State:      header.State,
+		Labels:     header.RawLabels,
+		StackTrace: scanner.StackTrace(),
+	}
+	if decl, ok := gc.GetGoRoutineDeclCopy(header.GoId); ok {
+		if decl.Name != "" {
+			grStack.Name = decl.Name
+			grStack.Tags = decl.Tags
+		}
+		// Patch the stack trace to replace Outrig SDK frames with real creator
+		grStack.StackTrace = patchCreatedByStack(&decl, grStack.StackTrace)
+	}
+	// filter after patching (patchCreatedByStack matches the SDK frames at the end of the full stack)
+	grStack.StackTrace = filter.apply(grStack.StackTrace)
+	return grStack
+}
+
 func (gc *GoroutineCollector) parseGoroutineStacks(stackData []byte, delta bool, timestamp int64) *ds.GoroutineInfo {
 	goroutineStacks := make([]ds.GoRoutineStack, 0)
 	activeGoroutines := make(map[int64]bool)
 	currentStacks := make(map[int64]ds.GoRoutineStack)
 
-	startIndices := startRe.FindAllIndex(stackData, -1)
 	numSameStack := 0
-	for i, startIdx := range startIndices {
-		start := startIdx[0]
-		end := len(stackData)
-		if i+1 < len(startIndices) {
-			end = startIndices[i+1][0]
-		}
-		goroutineData := stackData[start:end]
-		matches := stackRe.FindSubmatch(goroutineData)
-		if len(matches) < 4 {
-			continue
-		}
-		id, _ := strconv.ParseInt(string(matches[1]), 10, 64) // this is safe because the regex guarantees a number
+	filter := makeStackFilter(gc.config.Get())
+	scanner := stackparse.NewScanner(bytes.NewReader(stackData))
+	for scanner.Scan() {
+		header := scanner.Header()
+		id := header.GoId
 		activeGoroutines[id] = true
 
-		state := string(matches[2])
-		stackTrace := string(bytes.TrimSpace(matches[3]))
-
 		// Record this goroutine if we haven't seen it before or update its poll timestamps
-		gc.recordPolledGoroutine(id, goroutineData)
-
-		grStack := ds.GoRoutineStack{
-			GoId:       id,
-			Ts:         timestamp,
-			State:      state,
-			StackTrace: stackTrace,
-		}
-
-		if decl, ok := gc.GetGoRoutineDeclCopy(id); ok {
-			if decl.Name != "" {
-				grStack.Name = decl.Name
-				grStack.Tags = decl.Tags
-			}
-			// Patch the stack trace to replace Outrig SDK frames with real creator
-			grStack.StackTrace = patchCreatedByStack(&decl, grStack.StackTrace)
-		}
+		gc.recordPolledGoroutine(id, scanner.Raw())
 
+		grStack := gc.makeGoRoutineStack(scanner, timestamp, filter)
 		currentStacks[id] = grStack
 
 		// For delta updates, only include changed fields
@@ -652,6 +811,7 @@ func (gc *GoroutineCollector) setLastGoroutineStacksAndCleanupNames(stacks map[i
 
 	// Remove declarations for goroutines that are not in the keep map
 	now := time.Now().UnixMilli()
+	gracePeriodMs := GoroutineGracePeriod * gc.executor.GetDuration().Milliseconds()
 	for id, decl := range gc.goroutineDecls {
 		if keepMap[id] {
 			continue
@@ -659,13 +819,13 @@ func (gc *GoroutineCollector) setLastGoroutineStacksAndCleanupNames(stacks map[i
 
 		// Check grace periods before removing
 		startTs := atomic.LoadInt64(&decl.StartTs)
-		withinStartGrace := startTs > 0 && (now-startTs) < int64(GoroutineGracePeriod.Milliseconds())
+		withinStartGrace := startTs > 0 && (now-startTs) < gracePeriodMs
 		if withinStartGrace {
 			continue
 		}
 
 		lastPollTs := atomic.LoadInt64(&decl.LastPollTs)
-		withinPollGrace := lastPollTs > 0 && (now-lastPollTs) < int64(GoroutineGracePeriod.Milliseconds())
+		withinPollGrace := lastPollTs > 0 && (now-lastPollTs) < gracePeriodMs
 		if withinPollGrace {
 			continue
 		}
@@ -702,7 +862,7 @@ func (gc *GoroutineCollector) recordPolledGoroutine(goId int64, goroutineData []
 		// Only add to updated declarations if something other than LastPollTs changed
 		if wasFirstPollUpdated {
 			gc.lock.Lock()
-			declCopy := *decl
+			declCopy := copyGoDecl(decl)
 			gc.updatedDecls = append(gc.updatedDecls, declCopy)
 			gc.lock.Unlock()
 		}
@@ -731,10 +891,14 @@ func (gc *GoroutineCollector) GetStatus() ds.CollectorStatus {
 	}
 	if !cfg.Enabled {
 		status.Info = "Disabled in configuration"
+	} else if !platform.GoRoutineStacks {
+		status.Running = false
+		status.Info = "Not supported on this platform"
 	} else {
 		activeGoroutines, totalDecls := gc.getMonitoringCounts()
 		status.Info = fmt.Sprintf("Monitoring %d active goroutines, %d total declarations", activeGoroutines, totalDecls)
 		status.CollectDuration = gc.executor.GetLastExecDuration()
+		status.Overhead = gc.executor.GetOverhead()
 
 		if lastErr := gc.executor.GetLastErr(); lastErr != nil {
 			status.Errors = append(status.Errors, lastErr.Error())
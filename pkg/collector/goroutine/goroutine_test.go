@@ -0,0 +1,164 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package goroutine
+
+import (
+	"errors"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func blockedTestGoroutine(ch chan struct{}) {
+	<-ch
+}
+
+func findTestGoroutineStack(info *ds.GoroutineInfo) *ds.GoRoutineStack {
+	for i := range info.Stacks {
+		if strings.Contains(info.Stacks[i].StackTrace, "blockedTestGoroutine") {
+			return &info.Stacks[i]
+		}
+	}
+	return nil
+}
+
+func TestDumpGoroutinesFull(t *testing.T) {
+	gc := makeGoroutineCollector()
+	ch := make(chan struct{})
+	defer close(ch)
+	go blockedTestGoroutine(ch)
+
+	var stack *ds.GoRoutineStack
+	deadline := time.Now().Add(5 * time.Second)
+	for stack == nil || stack.State != "chan receive" {
+		if time.Now().After(deadline) {
+			t.Fatalf("the test goroutine is not in the dump (got %+v)", stack)
+		}
+		time.Sleep(10 * time.Millisecond)
+		stack = findTestGoroutineStack(gc.DumpGoroutinesFull())
+	}
+	gc.setGoRoutineDecl(&ds.GoDecl{GoId: stack.GoId, Name: "blocked", Tags: []string{"test"}})
+
+	info := gc.DumpGoroutinesFull()
+	if info.Delta || info.Count == 0 || info.Count != len(info.Stacks) {
+		t.Errorf("got delta=%v with count %d for %d stacks, want a full dump", info.Delta, info.Count, len(info.Stacks))
+	}
+	stack = findTestGoroutineStack(info)
+	if stack == nil || stack.Name != "blocked" || stack.Ts != info.Ts || stack.Same {
+		t.Errorf("got stack %+v, want the named goroutine with all its fields", stack)
+	}
+	if len(info.Decls) != 1 || info.Decls[0].Name != "blocked" {
+		t.Errorf("got decls %+v, want every known decl", info.Decls)
+	}
+
+	// the regular (delta) updates are not affected, the decl is still sent with the next update
+	if len(gc.lastGoroutineStacks) != 0 || !gc.nextSendFull || len(gc.updatedDecls) != 1 {
+		t.Errorf("the dump changed the update state: %d last stacks, nextSendFull=%v, %d updated decls",
+			len(gc.lastGoroutineStacks), gc.nextSendFull, len(gc.updatedDecls))
+	}
+}
+
+func TestSetGoRoutineDeclReplacesPolled(t *testing.T) {
+	gc := makeGoroutineCollector()
+	gc.recordPolledGoroutine(100, nil)
+	polled := gc.GetGoRoutineDecl(100)
+	if polled == nil || polled.FirstPollTs == 0 || polled.StartTs != 0 {
+		t.Fatalf("got polled decl %+v", polled)
+	}
+
+	// the decl recorded by Run() replaces the polled one and keeps its poll times
+	gc.setGoRoutineDecl(&ds.GoDecl{GoId: 100, Name: "worker", StartTs: 50})
+	decl := gc.GetGoRoutineDecl(100)
+	if decl.Name != "worker" || decl.FirstPollTs != polled.FirstPollTs || decl.LastPollTs != polled.LastPollTs {
+		t.Errorf("got decl %+v, want the named decl with the poll times of %+v", decl, polled)
+	}
+
+	// a started decl isn't replaced, and a decl without a start time never replaces one
+	gc.setGoRoutineDecl(&ds.GoDecl{GoId: 100, Name: "other", StartTs: 60})
+	gc.recordPolledGoroutine(101, nil)
+	gc.setGoRoutineDecl(&ds.GoDecl{GoId: 101, Name: "unstarted"})
+	if name := gc.GetGoRoutineDecl(100).Name; name != "worker" {
+		t.Errorf("got name %q, the started decl was replaced", name)
+	}
+	if name := gc.GetGoRoutineDecl(101).Name; name != "" {
+		t.Errorf("got name %q, a decl without a start time replaced the polled one", name)
+	}
+}
+
+type testPanicStringer struct{}
+
+func (testPanicStringer) String() string { return "stringer value" }
+
+func TestFormatPanicVal(t *testing.T) {
+	tests := []struct {
+		panicVal any
+		expect   string
+	}{
+		{errors.New("boom"), "boom"},
+		{testPanicStringer{}, "stringer value"},
+		{"plain string", "plain string"},
+		{42, "42"},
+	}
+	for _, tc := range tests {
+		if got := formatPanicVal(tc.panicVal); got != tc.expect {
+			t.Errorf("formatPanicVal(%#v): got %q, want %q", tc.panicVal, got, tc.expect)
+		}
+	}
+}
+
+func panickingTestFunc() {
+	panic("test panic")
+}
+
+func TestTrimPanicStack(t *testing.T) {
+	var stack string
+	func() {
+		defer func() {
+			recover()
+			stack = string(debug.Stack())
+		}()
+		panickingTestFunc()
+	}()
+	trimmed := trimPanicStack(stack)
+	if !strings.HasPrefix(trimmed, "panic(") || !strings.Contains(trimmed, "panickingTestFunc") {
+		t.Errorf("got %q, want the stack to start at the panic() frame", trimmed)
+	}
+	if strings.Contains(trimmed, "runtime/debug.Stack") {
+		t.Errorf("got %q, the frames above the panic weren't removed", trimmed)
+	}
+
+	// stacks without a panic() frame only lose the goroutine header
+	noPanic := "goroutine 7 [running]:\nmain.main()\n\t/src/main.go:10 +0x1d\n"
+	if got := trimPanicStack(noPanic); got != "main.main()\n\t/src/main.go:10 +0x1d" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestCopyGoDeclCopiesAllFields(t *testing.T) {
+	// set every field to a non-zero value, so a field that copyGoDecl misses shows up as a difference
+	var decl ds.GoDecl
+	val := reflect.ValueOf(&decl).Elem()
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(val.Type().Field(i).Name)
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			field.SetInt(int64(i + 1))
+		case reflect.Bool:
+			field.SetBool(true)
+		case reflect.Slice:
+			field.Set(reflect.ValueOf([]string{"tag"}))
+		default:
+			t.Fatalf("unhandled field type %s for %s", field.Kind(), val.Type().Field(i).Name)
+		}
+	}
+	if got := copyGoDecl(&decl); !reflect.DeepEqual(got, decl) {
+		t.Errorf("copyGoDecl missed fields:\ngot  %+v\nwant %+v", got, decl)
+	}
+}
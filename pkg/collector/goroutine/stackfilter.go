@@ -0,0 +1,83 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package goroutine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/outrigdev/outrig/pkg/config"
+)
+
+// stackFilter shrinks goroutine stack traces before they are sent (see GoRoutineConfig.MaxStackDepth and DropFramePrefixes)
+type stackFilter struct {
+	maxDepth     int
+	dropPrefixes []string
+}
+
+// makeStackFilter returns nil if no filtering is configured
+func makeStackFilter(cfg config.GoRoutineConfig) *stackFilter {
+	var dropPrefixes []string
+	for _, prefix := range cfg.DropFramePrefixes {
+		if prefix != "" {
+			dropPrefixes = append(dropPrefixes, prefix)
+		}
+	}
+	if cfg.MaxStackDepth <= 0 && len(dropPrefixes) == 0 {
+		return nil
+	}
+	return &stackFilter{maxDepth: cfg.MaxStackDepth, dropPrefixes: dropPrefixes}
+}
+
+func (sf *stackFilter) shouldDrop(funcLine string) bool {
+	for _, prefix := range sf.dropPrefixes {
+		if strings.HasPrefix(funcLine, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply filters a stack trace (without the "goroutine N [state]:" header).
+// A frame is a function line followed by an indented file:line line, the trailing "created by" frame is always kept.
+// Removed frames are replaced by a single "...N frames elided..." line (parsers skip it like the runtime's own marker).
+func (sf *stackFilter) apply(stackTrace string) string {
+	if sf == nil || stackTrace == "" {
+		return stackTrace
+	}
+	lines := strings.Split(stackTrace, "\n")
+	var kept []string
+	var tail []string // "created by" frame and anything after it
+	numFrames := 0
+	numElided := 0
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "created by ") {
+			tail = lines[i:]
+			break
+		}
+		frameLines := []string{line}
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t") {
+			frameLines = append(frameLines, lines[i+1])
+			i++
+		}
+		if strings.HasPrefix(line, "...") {
+			// the runtime's own elided marker, keep it
+			kept = append(kept, frameLines...)
+			continue
+		}
+		if sf.shouldDrop(line) || (sf.maxDepth > 0 && numFrames >= sf.maxDepth) {
+			numElided++
+			continue
+		}
+		numFrames++
+		kept = append(kept, frameLines...)
+	}
+	if numElided == 0 {
+		return stackTrace
+	}
+	kept = append(kept, fmt.Sprintf("...%d frames elided...", numElided))
+	kept = append(kept, tail...)
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,21 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo
+
+package collector
+
+import "runtime/metrics"
+
+const heapAllocsMetric = "/gc/heap/allocs:bytes"
+
+// getHeapAllocBytes returns the cumulative bytes allocated on the heap by the process
+// (runtime/metrics doesn't stop the world, unlike runtime.ReadMemStats)
+func getHeapAllocBytes() (int64, bool) {
+	samples := []metrics.Sample{{Name: heapAllocsMetric}}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() != metrics.KindUint64 {
+		return 0, false
+	}
+	return int64(samples[0].Value.Uint64()), true
+}
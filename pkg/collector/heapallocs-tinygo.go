@@ -0,0 +1,11 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build tinygo
+
+package collector
+
+// getHeapAllocBytes is not supported with tinygo (no runtime/metrics)
+func getHeapAllocBytes() (int64, bool) {
+	return 0, false
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/outrigdev/outrig/pkg/config"
 	"github.com/outrigdev/outrig/pkg/ioutrig"
+	"github.com/outrigdev/outrig/pkg/redact"
 	"github.com/outrigdev/outrig/pkg/utilfn"
 )
 
@@ -113,6 +114,11 @@ func enableExternalLogWrapImpl(appRunId string, cfg config.LogProcessorConfig) e
 	cmd.Env = os.Environ()
 	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", config.AppRunIdEnvName, appRunId))
 
+	// The capture process redacts the logs with the app's redaction config
+	if redactJson := redact.GetConfigJson(); redactJson != "" {
+		cmd.Env = append(cmd.Env, config.RedactJsonEnvName+"="+redactJson)
+	}
+
 	// Add any additional arguments before "capturelogs"
 	if len(cfg.AdditionalArgs) > 0 {
 		cmd.Args = append(cmd.Args, cfg.AdditionalArgs...)
@@ -6,6 +6,7 @@ package logprocess
 import (
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/outrigdev/outrig/pkg/collector"
@@ -13,6 +14,7 @@ import (
 	"github.com/outrigdev/outrig/pkg/config"
 	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/global"
+	"github.com/outrigdev/outrig/pkg/platform"
 	"github.com/outrigdev/outrig/pkg/utilds"
 )
 
@@ -62,7 +64,7 @@ func (lc *LogCollector) Enable() {
 	}
 
 	cfg := lc.config.Get()
-	if !cfg.Enabled {
+	if !cfg.Enabled || !platform.StdioCapture {
 		return
 	}
 
@@ -127,10 +129,16 @@ func (lc *LogCollector) GetStatus() ds.CollectorStatus {
 	} else {
 		if isExternalActive {
 			status.Info = "Log processing active (external log wrapping enabled)"
+		} else if !platform.StdioCapture {
+			status.Info = "Log processing active (stdout/stderr capture not supported on this platform)"
 		} else {
 			status.Info = "Log processing active (external log wrapping disabled)"
 		}
 
+		if names := GetLogStreamNames(); len(names) > 0 {
+			status.Info += ", streams: " + strings.Join(names, ", ")
+		}
+
 		// Check for external log wrap error
 		if err := lc.getExternalLogWrapError(); err != nil {
 			status.Errors = append(status.Errors, "External log wrapping failed: "+err.Error())
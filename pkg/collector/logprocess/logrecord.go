@@ -0,0 +1,118 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package logprocess
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/outrigdev/goid"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/global"
+)
+
+// LogField is a key/value pair attached to a structured log record
+type LogField struct {
+	Key   string
+	Value any
+}
+
+// LogRecord is a structured log record produced by a logging library adapter (slog, zap, logrus)
+type LogRecord struct {
+	Ts     time.Time
+	Level  string // normalized to upper case (DEBUG, INFO, WARN, ERROR, ...)
+	Msg    string
+	Caller string // "file:line", optional
+	Fields []LogField
+}
+
+// SendLogRecord formats the record as a single log line and sends it to Outrig with the given source.
+// Unlike the stdout/stderr capture the line is never split or interleaved with other output.
+func SendLogRecord(source string, rec *LogRecord) {
+	if !global.OutrigEnabled.Load() {
+		return
+	}
+	c := global.Controller.Load()
+	if c == nil || *c == nil {
+		return
+	}
+	ts := rec.Ts
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	logLine := &ds.LogLine{
+		Ts:     ts.UnixMilli(),
+		Msg:    FormatLogRecord(rec),
+		Source: source,
+		GoId:   int64(goid.Get()),
+	}
+	(*c).SendPacket(&ds.PacketType{
+		Type: ds.PacketTypeLog,
+		Data: logLine,
+	})
+}
+
+// FormatLogRecord renders a record as "LEVEL msg key=value ...", values are quoted (logfmt style)
+// when they contain spaces, quotes, '=' or newlines so the fields can be searched for as "key=value"
+func FormatLogRecord(rec *LogRecord) string {
+	var sb strings.Builder
+	if rec.Level != "" {
+		sb.WriteString(strings.ToUpper(rec.Level))
+		sb.WriteByte(' ')
+	}
+	sb.WriteString(strings.TrimRight(rec.Msg, "\n"))
+	if rec.Caller != "" {
+		sb.WriteString(" caller=")
+		sb.WriteString(quoteLogValue(rec.Caller))
+	}
+	for _, field := range rec.Fields {
+		if field.Key == "" {
+			continue
+		}
+		sb.WriteByte(' ')
+		sb.WriteString(field.Key)
+		sb.WriteByte('=')
+		sb.WriteString(quoteLogValue(formatLogValue(field.Value)))
+	}
+	return sb.String()
+}
+
+func formatLogValue(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case error:
+		return v.Error()
+	case time.Time:
+		// before fmt.Stringer, time.Time implements it
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32, float64:
+		return fmt.Sprint(v)
+	case map[string]any, []any:
+		barr, err := json.Marshal(v)
+		if err == nil {
+			return string(barr)
+		}
+	}
+	return fmt.Sprintf("%+v", val)
+}
+
+func quoteLogValue(s string) string {
+	if s == "" {
+		return `""`
+	}
+	if strings.ContainsAny(s, " \t\r\n\"=") || !strconv.CanBackquote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
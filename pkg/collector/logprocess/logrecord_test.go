@@ -0,0 +1,52 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package logprocess
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFormatLogRecord(t *testing.T) {
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name   string
+		rec    LogRecord
+		expect string
+	}{
+		{"message only", LogRecord{Msg: "started\n"}, "started"},
+		{"level and caller", LogRecord{Level: "info", Msg: "started", Caller: "main.go:10"}, "INFO started caller=main.go:10"},
+		{"fields", LogRecord{Level: "WARN", Msg: "slow", Fields: []LogField{
+			{Key: "ms", Value: 120},
+			{Key: "ok", Value: false},
+			{Key: "user", Value: "bob"},
+			{Key: "", Value: "dropped"},
+		}}, "WARN slow ms=120 ok=false user=bob"},
+		{"quoted values", LogRecord{Msg: "m", Fields: []LogField{
+			{Key: "path", Value: "a b"},
+			{Key: "q", Value: `say "hi"`},
+			{Key: "eq", Value: "a=b"},
+			{Key: "empty", Value: ""},
+			{Key: "nl", Value: "a\nb"},
+		}}, `m path="a b" q="say \"hi\"" eq="a=b" empty="" nl="a\nb"`},
+		{"value types", LogRecord{Msg: "m", Fields: []LogField{
+			{Key: "err", Value: errors.New("boom")},
+			{Key: "nil", Value: nil},
+			{Key: "ts", Value: ts},
+			{Key: "dur", Value: 2 * time.Second},
+			{Key: "bytes", Value: []byte("raw")},
+			{Key: "map", Value: map[string]any{"a": 1}},
+			{Key: "list", Value: []any{1, "x"}},
+			{Key: "struct", Value: struct{ A int }{A: 1}},
+		}}, `m err=boom nil=<nil> ts=2025-01-02T03:04:05Z dur=2s bytes=raw map="{\"a\":1}" list="[1,\"x\"]" struct={A:1}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := FormatLogRecord(&tc.rec); got != tc.expect {
+				t.Errorf("got %q, want %q", got, tc.expect)
+			}
+		})
+	}
+}
@@ -5,8 +5,12 @@ package logprocess
 
 import (
 	"io"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/outrigdev/goid"
 	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/global"
 	"github.com/outrigdev/outrig/pkg/utilfn"
@@ -14,10 +18,17 @@ import (
 
 // LogStreamWriter implements io.Writer to send logs to Outrig
 type LogStreamWriter struct {
+	lock    sync.Mutex // writers can be shared (see GetLogStreamWriter)
 	name    string
 	lineBuf *utilfn.LineBuf
 }
 
+// named streams registered with GetLogStreamWriter
+var (
+	namedStreamsLock sync.Mutex
+	namedStreams     = make(map[string]*LogStreamWriter)
+)
+
 // Ensure LogStreamWriter implements io.Writer
 var _ io.Writer = (*LogStreamWriter)(nil)
 
@@ -35,9 +46,12 @@ func (w *LogStreamWriter) Write(p []byte) (n int, err error) {
 	controller := *c
 
 	// Process the buffer into lines
+	w.lock.Lock()
 	lines := w.lineBuf.ProcessBuf(p)
+	w.lock.Unlock()
 
 	var logTs int64
+	goId := int64(goid.Get())
 	// Send each complete line as a log packet
 	for _, line := range lines {
 		if logTs == 0 {
@@ -47,6 +61,7 @@ func (w *LogStreamWriter) Write(p []byte) (n int, err error) {
 			Ts:     logTs,
 			Msg:    line,
 			Source: w.name,
+			GoId:   goId,
 		}
 		packet := &ds.PacketType{
 			Type: ds.PacketTypeLog,
@@ -65,3 +80,29 @@ func MakeLogStreamWriter(name string) *LogStreamWriter {
 		lineBuf: utilfn.MakeLineBuf(),
 	}
 }
+
+// GetLogStreamWriter returns the writer for a named stream, registering it on first use
+// (all callers using the same name share a writer). Lines are sent with the stream name as their source.
+func GetLogStreamWriter(name string) *LogStreamWriter {
+	name = strings.TrimSpace(name)
+	namedStreamsLock.Lock()
+	defer namedStreamsLock.Unlock()
+	w := namedStreams[name]
+	if w == nil {
+		w = MakeLogStreamWriter(name)
+		namedStreams[name] = w
+	}
+	return w
+}
+
+// GetLogStreamNames returns the names of the registered named streams (sorted)
+func GetLogStreamNames() []string {
+	namedStreamsLock.Lock()
+	defer namedStreamsLock.Unlock()
+	names := make([]string, 0, len(namedStreams))
+	for name := range namedStreams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
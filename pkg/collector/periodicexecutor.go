@@ -5,11 +5,13 @@ package collector
 
 import (
 	"fmt"
+	"runtime"
 	"runtime/debug"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/ioutrig"
 )
 
@@ -23,6 +25,14 @@ type PeriodicExecutor struct {
 	isFnRunning      atomic.Bool
 	lastExecDuration atomic.Int64 // duration in milliseconds
 	lastErr          error        // protected by lock
+
+	// overhead of the executions (see ds.CollectorOverhead)
+	numRuns         atomic.Int64
+	cpuMeasured     atomic.Bool
+	lastCpuUs       atomic.Int64
+	totalCpuUs      atomic.Int64
+	lastAllocBytes  atomic.Int64
+	totalAllocBytes atomic.Int64
 }
 
 func MakePeriodicExecutor(name string, dur time.Duration, execFn func()) *PeriodicExecutor {
@@ -83,6 +93,27 @@ func (p *PeriodicExecutor) Disable() {
 	p.done = nil
 }
 
+// SetDuration changes the interval between executions (takes effect immediately if running)
+func (p *PeriodicExecutor) SetDuration(dur time.Duration) error {
+	if dur <= 0 {
+		return fmt.Errorf("duration must be greater than 0")
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.duration = dur
+	if p.ticker != nil {
+		p.ticker.Reset(dur)
+	}
+	return nil
+}
+
+// GetDuration returns the interval between executions
+func (p *PeriodicExecutor) GetDuration() time.Duration {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.duration
+}
+
 func (p *PeriodicExecutor) runFunc() {
 	ok := p.isFnRunning.CompareAndSwap(false, true)
 	if !ok {
@@ -90,10 +121,16 @@ func (p *PeriodicExecutor) runFunc() {
 	}
 	defer p.isFnRunning.Store(false)
 
+	// the goroutine stays on its thread, so the thread's CPU time is the execution's CPU time
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	startCpu, cpuOk := getThreadCPUTime()
+	startAlloc, allocOk := getHeapAllocBytes()
 	start := time.Now()
 	defer func() {
 		duration := time.Since(start).Milliseconds()
 		p.lastExecDuration.Store(duration)
+		p.recordOverhead(startCpu, cpuOk, startAlloc, allocOk)
 
 		if r := recover(); r != nil {
 			stack := debug.Stack()
@@ -125,3 +162,32 @@ func (p *PeriodicExecutor) GetLastErr() error {
 func (p *PeriodicExecutor) GetLastExecDuration() int64 {
 	return p.lastExecDuration.Load()
 }
+
+// recordOverhead records the CPU time and allocations of an execution (from the values read before it ran)
+func (p *PeriodicExecutor) recordOverhead(startCpu time.Duration, cpuOk bool, startAlloc int64, allocOk bool) {
+	p.numRuns.Add(1)
+	if endCpu, ok := getThreadCPUTime(); cpuOk && ok {
+		cpuUs := (endCpu - startCpu).Microseconds()
+		p.lastCpuUs.Store(cpuUs)
+		p.totalCpuUs.Add(cpuUs)
+		p.cpuMeasured.Store(true)
+	}
+	if endAlloc, ok := getHeapAllocBytes(); allocOk && ok {
+		allocBytes := endAlloc - startAlloc
+		p.lastAllocBytes.Store(allocBytes)
+		p.totalAllocBytes.Add(allocBytes)
+	}
+}
+
+// GetOverhead returns what the executions have cost the app so far
+func (p *PeriodicExecutor) GetOverhead() *ds.CollectorOverhead {
+	return &ds.CollectorOverhead{
+		IntervalMs:      p.GetDuration().Milliseconds(),
+		NumCycles:       p.numRuns.Load(),
+		CpuMeasured:     p.cpuMeasured.Load(),
+		LastCpuUs:       p.lastCpuUs.Load(),
+		TotalCpuUs:      p.totalCpuUs.Load(),
+		LastAllocBytes:  p.lastAllocBytes.Load(),
+		TotalAllocBytes: p.totalAllocBytes.Load(),
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+var overheadSink [][]byte
+
+func TestPeriodicExecutorOverhead(t *testing.T) {
+	exec := MakePeriodicExecutor("overheadtest", time.Hour, func() {
+		// burn some CPU and allocate about 1MB
+		deadline := time.Now().Add(20 * time.Millisecond)
+		for time.Now().Before(deadline) {
+		}
+		overheadSink = nil
+		for i := 0; i < 16; i++ {
+			overheadSink = append(overheadSink, make([]byte, 64*1024))
+		}
+	})
+	exec.runFunc()
+	exec.runFunc()
+
+	overhead := exec.GetOverhead()
+	if overhead.IntervalMs != time.Hour.Milliseconds() || overhead.NumCycles != 2 {
+		t.Fatalf("got interval %dms and %d cycles, want %dms and 2 cycles", overhead.IntervalMs, overhead.NumCycles, time.Hour.Milliseconds())
+	}
+	if overhead.LastAllocBytes < 1024*1024 || overhead.TotalAllocBytes < 2*1024*1024 {
+		t.Errorf("allocations not measured: last %d, total %d", overhead.LastAllocBytes, overhead.TotalAllocBytes)
+	}
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		return
+	}
+	if !overhead.CpuMeasured || overhead.LastCpuUs < 10000 || overhead.TotalCpuUs < overhead.LastCpuUs {
+		t.Errorf("cpu time not measured: measured %v, last %dus, total %dus", overhead.CpuMeasured, overhead.LastCpuUs, overhead.TotalCpuUs)
+	}
+}
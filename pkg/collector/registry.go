@@ -4,6 +4,7 @@
 package collector
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/outrigdev/outrig/pkg/config"
@@ -14,17 +15,21 @@ var (
 	collectors        map[string]Collector
 	collectorsEnabled bool
 	collectorsLock    sync.Mutex
+
+	// collectors turned off at runtime (from the server), they stay off when collectors are re-enabled
+	adminDisabled map[string]bool
 )
 
 func init() {
 	collectors = make(map[string]Collector)
+	adminDisabled = make(map[string]bool)
 }
 
 func RegisterCollector(c Collector) {
 	collectorsLock.Lock()
 	defer collectorsLock.Unlock()
 	collectors[c.CollectorName()] = c
-	if collectorsEnabled {
+	if collectorsEnabled && !adminDisabled[c.CollectorName()] {
 		c.Enable()
 	}
 }
@@ -41,7 +46,11 @@ func GetCollectorStatuses() map[string]ds.CollectorStatus {
 
 	statuses := make(map[string]ds.CollectorStatus)
 	for name, collector := range collectors {
-		statuses[name] = collector.GetStatus()
+		status := collector.GetStatus()
+		if adminDisabled[name] && status.Info == "" {
+			status.Info = "disabled at runtime"
+		}
+		statuses[name] = status
 	}
 	return statuses
 }
@@ -55,7 +64,10 @@ func SetCollectorsEnabled(enabled bool, cfg *config.Config) {
 	}
 	collectorsEnabled = enabled
 	if enabled {
-		for _, collector := range collectors {
+		for name, collector := range collectors {
+			if adminDisabled[name] {
+				continue
+			}
 			collector.Enable()
 		}
 	} else {
@@ -74,3 +86,53 @@ func NotifyCollectorsNewConnection() {
 		collector.OnNewConnection()
 	}
 }
+
+// NotifyCollectorsPacketDropped calls OnPacketDropped on the collectors that implement PacketDropListener
+func NotifyCollectorsPacketDropped(pkType string) {
+	collectorsLock.Lock()
+	defer collectorsLock.Unlock()
+
+	for _, collector := range collectors {
+		if listener, ok := collector.(PacketDropListener); ok {
+			listener.OnPacketDropped(pkType)
+		}
+	}
+}
+
+// SetCollectorEnabled turns a single collector on or off at runtime
+// A disabled collector stays off until it is enabled again with this function
+func SetCollectorEnabled(name string, enabled bool) error {
+	collectorsLock.Lock()
+	defer collectorsLock.Unlock()
+
+	c := collectors[name]
+	if c == nil {
+		return fmt.Errorf("collector not found: %s", name)
+	}
+	if enabled {
+		delete(adminDisabled, name)
+		if collectorsEnabled {
+			c.Enable()
+		}
+	} else {
+		adminDisabled[name] = true
+		c.Disable()
+	}
+	return nil
+}
+
+// ApplyCollectorSettings changes the settings of a collector at runtime
+func ApplyCollectorSettings(name string, settings map[string]any) error {
+	collectorsLock.Lock()
+	c := collectors[name]
+	collectorsLock.Unlock()
+
+	if c == nil {
+		return fmt.Errorf("collector not found: %s", name)
+	}
+	cc, ok := c.(ConfigurableCollector)
+	if !ok {
+		return fmt.Errorf("collector %s does not support runtime settings", name)
+	}
+	return cc.ApplySettings(settings)
+}
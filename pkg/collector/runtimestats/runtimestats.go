@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/outrigdev/outrig/pkg/collector"
+	"github.com/outrigdev/outrig/pkg/collector/fdstats"
+	"github.com/outrigdev/outrig/pkg/collector/schedstats"
 	"github.com/outrigdev/outrig/pkg/config"
 	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/global"
@@ -70,6 +72,11 @@ func (rc *RuntimeStatsCollector) Disable() {
 	rc.executor.Disable()
 }
 
+// ApplySettings changes the collector settings at runtime (e.g. the poll interval)
+func (rc *RuntimeStatsCollector) ApplySettings(settings map[string]any) error {
+	return collector.ApplyExecutorSettings(rc.executor, settings)
+}
+
 // OnNewConnection is called when a new connection is established
 func (rc *RuntimeStatsCollector) OnNewConnection() {
 	// No action needed for runtime stats collector
@@ -132,6 +139,8 @@ func (rc *RuntimeStatsCollector) CollectRuntimeStats() {
 		Pid:            pid,
 		Cwd:            cwd,
 		MemStats:       memStatsInfo,
+		FDStats:        fdstats.GetInstance().GetLastStats(),
+		SchedStats:     schedstats.GetInstance().GetLastStats(),
 	}
 
 	// Send the runtime stats packet
@@ -155,6 +164,7 @@ func (rc *RuntimeStatsCollector) GetStatus() ds.CollectorStatus {
 	} else {
 		status.Info = "Runtime statistics collection active"
 		status.CollectDuration = rc.executor.GetLastExecDuration()
+		status.Overhead = rc.executor.GetOverhead()
 
 		if lastErr := rc.executor.GetLastErr(); lastErr != nil {
 			status.Errors = append(status.Errors, lastErr.Error())
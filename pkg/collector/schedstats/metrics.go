@@ -0,0 +1,179 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package schedstats
+
+import (
+	"math"
+	"runtime/metrics"
+	"slices"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+const (
+	MetricSchedLatencies = "/sched/latencies:seconds"
+	MetricGCPauses       = "/sched/pauses/total/gc:seconds" // go1.22+
+	MetricGCPausesLegacy = "/gc/pauses:seconds"             // deprecated in go1.22
+	MetricGoMaxProcs     = "/sched/gomaxprocs:threads"      // go1.20+
+	MetricMutexWait      = "/sync/mutex/wait/total:seconds" // go1.20+
+)
+
+// metricsSampler reads the scheduler metrics and keeps the previous histograms to compute per-interval deltas
+type metricsSampler struct {
+	samples       []metrics.Sample
+	missing       []string            // metrics not supported by the running Go version
+	prevHist      map[string][]uint64 // bucket counts of the previous sample
+	prevTs        time.Time
+	prevMutexWait float64
+
+	goMaxProcs          int
+	goMaxProcsChanges   int
+	goMaxProcsChangedTs int64
+}
+
+func makeMetricsSampler() *metricsSampler {
+	supported := make(map[string]bool)
+	for _, desc := range metrics.All() {
+		supported[desc.Name] = true
+	}
+	gcPausesMetric := MetricGCPauses
+	if !supported[gcPausesMetric] {
+		gcPausesMetric = MetricGCPausesLegacy
+	}
+	ms := &metricsSampler{prevHist: make(map[string][]uint64)}
+	for _, name := range []string{MetricSchedLatencies, gcPausesMetric, MetricGoMaxProcs, MetricMutexWait} {
+		if !supported[name] {
+			ms.missing = append(ms.missing, name)
+			continue
+		}
+		ms.samples = append(ms.samples, metrics.Sample{Name: name})
+	}
+	return ms
+}
+
+// sample reads the metrics, returns nil for the first call (which only records the baseline)
+func (ms *metricsSampler) sample(now time.Time) *ds.SchedStatsInfo {
+	metrics.Read(ms.samples)
+	isFirst := ms.prevTs.IsZero()
+	stats := &ds.SchedStatsInfo{
+		Ts: now.UnixMilli(),
+	}
+	if !isFirst {
+		stats.IntervalMs = now.Sub(ms.prevTs).Milliseconds()
+	}
+	for _, sample := range ms.samples {
+		switch sample.Value.Kind() {
+		case metrics.KindFloat64Histogram:
+			hist := sample.Value.Float64Histogram()
+			delta := histDelta(hist.Counts, ms.prevHist[sample.Name])
+			// the histogram is owned by the runtime/metrics package (it can be reused by the next Read)
+			ms.prevHist[sample.Name] = slices.Clone(hist.Counts)
+			if isFirst {
+				continue
+			}
+			switch sample.Name {
+			case MetricSchedLatencies:
+				stats.SchedLatencyP50Ns = secsToNs(histQuantile(delta, hist.Buckets, 0.5))
+				stats.SchedLatencyP99Ns = secsToNs(histQuantile(delta, hist.Buckets, 0.99))
+				stats.SchedLatencyMaxNs = secsToNs(histMax(delta, hist.Buckets))
+			case MetricGCPauses, MetricGCPausesLegacy:
+				stats.GCPauses = int64(histTotal(delta))
+				stats.GCPauseP99Ns = secsToNs(histQuantile(delta, hist.Buckets, 0.99))
+				stats.GCPauseMaxNs = secsToNs(histMax(delta, hist.Buckets))
+			}
+		case metrics.KindFloat64:
+			if sample.Name == MetricMutexWait {
+				mutexWait := sample.Value.Float64()
+				stats.MutexWaitTotalNs = secsToNs(mutexWait)
+				if !isFirst {
+					stats.MutexWaitNs = secsToNs(mutexWait - ms.prevMutexWait)
+				}
+				ms.prevMutexWait = mutexWait
+			}
+		case metrics.KindUint64:
+			if sample.Name == MetricGoMaxProcs {
+				goMaxProcs := int(sample.Value.Uint64())
+				if !isFirst && goMaxProcs != ms.goMaxProcs {
+					ms.goMaxProcsChanges++
+					ms.goMaxProcsChangedTs = stats.Ts
+				}
+				ms.goMaxProcs = goMaxProcs
+			}
+		}
+	}
+	stats.GoMaxProcs = ms.goMaxProcs
+	stats.GoMaxProcsChanges = ms.goMaxProcsChanges
+	stats.GoMaxProcsChangedTs = ms.goMaxProcsChangedTs
+	ms.prevTs = now
+	if isFirst {
+		return nil
+	}
+	return stats
+}
+
+// histDelta returns the bucket counts added since prev (all of cur if prev is missing or has other buckets)
+func histDelta(cur []uint64, prev []uint64) []uint64 {
+	delta := slices.Clone(cur)
+	if len(prev) != len(cur) {
+		return delta
+	}
+	for i := range delta {
+		if delta[i] >= prev[i] {
+			delta[i] -= prev[i]
+		}
+	}
+	return delta
+}
+
+func histTotal(counts []uint64) uint64 {
+	var total uint64
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
+
+// bucketValue returns the upper bound of bucket i (the lower bound for the open-ended last bucket).
+// buckets holds the len(counts)+1 bucket boundaries.
+func bucketValue(buckets []float64, i int) float64 {
+	if i+1 < len(buckets) && !math.IsInf(buckets[i+1], 1) {
+		return buckets[i+1]
+	}
+	if math.IsInf(buckets[i], -1) {
+		return 0
+	}
+	return buckets[i]
+}
+
+// histQuantile returns the (approximate) q quantile of a histogram, 0 if it is empty
+func histQuantile(counts []uint64, buckets []float64, q float64) float64 {
+	total := histTotal(counts)
+	if total == 0 {
+		return 0
+	}
+	target := uint64(math.Ceil(q * float64(total)))
+	var cumulative uint64
+	for i, count := range counts {
+		cumulative += count
+		if cumulative >= target {
+			return bucketValue(buckets, i)
+		}
+	}
+	return bucketValue(buckets, len(counts)-1)
+}
+
+// histMax returns the (approximate) maximum of a histogram, 0 if it is empty
+func histMax(counts []uint64, buckets []float64) float64 {
+	for i := len(counts) - 1; i >= 0; i-- {
+		if counts[i] > 0 {
+			return bucketValue(buckets, i)
+		}
+	}
+	return 0
+}
+
+func secsToNs(secs float64) int64 {
+	return int64(secs * float64(time.Second))
+}
@@ -0,0 +1,74 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package schedstats
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHistQuantile(t *testing.T) {
+	buckets := []float64{math.Inf(-1), 0.001, 0.01, 0.1, math.Inf(1)}
+	tests := []struct {
+		name   string
+		counts []uint64
+		q      float64
+		expect float64
+	}{
+		{"empty", []uint64{0, 0, 0, 0}, 0.5, 0},
+		{"median", []uint64{0, 90, 9, 1}, 0.5, 0.01},
+		{"p99", []uint64{0, 90, 9, 1}, 0.99, 0.1},
+		{"max is the lower bound of the open last bucket", []uint64{0, 90, 9, 1}, 1, 0.1},
+		{"first bucket", []uint64{5, 0, 0, 0}, 0.5, 0.001},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := histQuantile(tc.counts, buckets, tc.q); got != tc.expect {
+				t.Errorf("got %v, want %v", got, tc.expect)
+			}
+		})
+	}
+
+	// a single unbounded bucket has no usable bound
+	if got := histQuantile([]uint64{3}, []float64{math.Inf(-1), math.Inf(1)}, 0.5); got != 0 {
+		t.Errorf("got %v for an unbounded bucket, want 0", got)
+	}
+}
+
+func TestHistMax(t *testing.T) {
+	buckets := []float64{0, 0.001, 0.01, 0.1}
+	if got := histMax([]uint64{1, 4, 0}, buckets); got != 0.01 {
+		t.Errorf("got %v, want the upper bound of the last non-empty bucket", got)
+	}
+	if got := histMax([]uint64{0, 0, 0}, buckets); got != 0 {
+		t.Errorf("got %v for an empty histogram", got)
+	}
+}
+
+func TestHistDelta(t *testing.T) {
+	if got := histDelta([]uint64{5, 7, 9}, []uint64{1, 7, 4}); !reflect.DeepEqual(got, []uint64{4, 0, 5}) {
+		t.Errorf("got %v", got)
+	}
+	// no (or a different) previous histogram counts everything
+	if got := histDelta([]uint64{5, 7}, nil); !reflect.DeepEqual(got, []uint64{5, 7}) {
+		t.Errorf("got %v without a previous histogram", got)
+	}
+	if got := histDelta([]uint64{5, 7}, []uint64{1, 2, 3}); !reflect.DeepEqual(got, []uint64{5, 7}) {
+		t.Errorf("got %v with other buckets", got)
+	}
+}
+
+func TestMetricsSampler(t *testing.T) {
+	ms := makeMetricsSampler()
+	now := time.Now()
+	if stats := ms.sample(now); stats != nil {
+		t.Errorf("got %+v for the first sample, want nil (baseline)", stats)
+	}
+	stats := ms.sample(now.Add(time.Second))
+	if stats == nil || stats.IntervalMs != 1000 || stats.GoMaxProcs <= 0 {
+		t.Errorf("got %+v, want a 1s interval with gomaxprocs set", stats)
+	}
+}
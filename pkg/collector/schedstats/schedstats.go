@@ -0,0 +1,156 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package schedstats
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/collector"
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/global"
+	"github.com/outrigdev/outrig/pkg/utilds"
+)
+
+// SchedStatsPollInterval matches the runtime stats interval, so each runtime stats sample covers one interval
+const SchedStatsPollInterval = 1 * time.Second
+
+// SchedStatsCollector implements the collector.Collector interface for scheduler metrics (runtime/metrics).
+// Like fdstats it doesn't send its own packets, the last sample is attached to the runtime stats (see GetLastStats).
+type SchedStatsCollector struct {
+	lock      sync.Mutex
+	config    *utilds.SetOnceConfig[config.SchedStatsConfig]
+	executor  *collector.PeriodicExecutor
+	lastStats atomic.Pointer[ds.SchedStatsInfo]
+	sampler   *metricsSampler // protected by lock
+}
+
+// CollectorName returns the unique name of the collector
+func (sc *SchedStatsCollector) CollectorName() string {
+	return "schedstats"
+}
+
+// singleton instance
+var instance *SchedStatsCollector
+var instanceOnce sync.Once
+
+// GetInstance returns the singleton instance of SchedStatsCollector
+func GetInstance() *SchedStatsCollector {
+	instanceOnce.Do(func() {
+		instance = &SchedStatsCollector{
+			config: utilds.NewSetOnceConfig(config.DefaultConfig().Collectors.SchedStats),
+		}
+		instance.executor = collector.MakePeriodicExecutor("SchedStatsCollector", SchedStatsPollInterval, instance.CollectSchedStats)
+	})
+	return instance
+}
+
+func Init(cfg *config.SchedStatsConfig) error {
+	sc := GetInstance()
+	if sc.executor.IsEnabled() {
+		return fmt.Errorf("schedstats collector is already initialized")
+	}
+	ok := sc.config.SetOnce(cfg)
+	if !ok {
+		return fmt.Errorf("schedstats collector configuration already set")
+	}
+	collector.RegisterCollector(sc)
+	return nil
+}
+
+// Enable is called when the collector should start collecting data
+func (sc *SchedStatsCollector) Enable() {
+	cfg := sc.config.Get()
+	if !cfg.Enabled {
+		return
+	}
+	sc.executor.Enable()
+}
+
+// Disable stops the collector (the runtime stats stop including scheduler stats)
+func (sc *SchedStatsCollector) Disable() {
+	sc.executor.Disable()
+	sc.lastStats.Store(nil)
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	// the next sample after re-enabling starts a new interval
+	sc.sampler = nil
+}
+
+// ApplySettings changes the collector settings at runtime (e.g. the poll interval)
+func (sc *SchedStatsCollector) ApplySettings(settings map[string]any) error {
+	return collector.ApplyExecutorSettings(sc.executor, settings)
+}
+
+// OnNewConnection is called when a new connection is established
+func (sc *SchedStatsCollector) OnNewConnection() {
+	// No action needed, the stats are sent with the runtime stats
+}
+
+// CollectSchedStats reads the scheduler metrics and computes the deltas since the previous sample
+func (sc *SchedStatsCollector) CollectSchedStats() {
+	if !global.OutrigEnabled.Load() {
+		return
+	}
+	sc.lock.Lock()
+	if sc.sampler == nil {
+		sc.sampler = makeMetricsSampler()
+	}
+	stats := sc.sampler.sample(time.Now())
+	sc.lock.Unlock()
+	if stats == nil {
+		// first sample only sets the baseline for the histogram deltas
+		return
+	}
+	sc.lastStats.Store(stats)
+}
+
+// GetLastStats returns the most recent sample (nil if the collector is disabled or hasn't run twice yet)
+func (sc *SchedStatsCollector) GetLastStats() *ds.SchedStatsInfo {
+	if !sc.executor.IsEnabled() {
+		return nil
+	}
+	return sc.lastStats.Load()
+}
+
+func (sc *SchedStatsCollector) getMissingMetrics() []string {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	if sc.sampler == nil {
+		return nil
+	}
+	return sc.sampler.missing
+}
+
+// GetStatus returns the current status of the schedstats collector
+func (sc *SchedStatsCollector) GetStatus() ds.CollectorStatus {
+	cfg := sc.config.Get()
+	status := ds.CollectorStatus{
+		Running: cfg.Enabled,
+	}
+
+	if !cfg.Enabled {
+		status.Info = "Disabled in configuration"
+	} else {
+		if stats := sc.lastStats.Load(); stats != nil {
+			status.Info = fmt.Sprintf("Scheduler latency p99 %s", time.Duration(stats.SchedLatencyP99Ns))
+		} else {
+			status.Info = "Scheduler metrics collection active"
+		}
+		status.CollectDuration = sc.executor.GetLastExecDuration()
+		status.Overhead = sc.executor.GetOverhead()
+
+		for _, name := range sc.getMissingMetrics() {
+			status.Warnings = append(status.Warnings, fmt.Sprintf("metric %s is not supported by this Go version", name))
+		}
+		if lastErr := sc.executor.GetLastErr(); lastErr != nil {
+			status.Errors = append(status.Errors, lastErr.Error())
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,13 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !(linux || darwin) || tinygo
+
+package collector
+
+import "time"
+
+// getThreadCPUTime is not supported on this platform
+func getThreadCPUTime() (time.Duration, bool) {
+	return 0, false
+}
@@ -0,0 +1,22 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build (linux || darwin) && !tinygo
+
+package collector
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// getThreadCPUTime returns the CPU time used by the current OS thread
+// (the caller must be locked to its thread with runtime.LockOSThread)
+func getThreadCPUTime() (time.Duration, bool) {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_THREAD_CPUTIME_ID, &ts); err != nil {
+		return 0, false
+	}
+	return time.Duration(ts.Nano()), true
+}
@@ -0,0 +1,57 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ContextState is the value reported by a context watch (see outrig.Watch.PollContext)
+type ContextState struct {
+	Done        bool   `json:"done"`
+	Err         string `json:"err,omitempty"`         // ctx.Err()
+	Cause       string `json:"cause,omitempty"`       // context.Cause(ctx), only set if it differs from Err
+	AgeMs       int64  `json:"agems"`                 // time since the watch was created
+	DoneAgoMs   int64  `json:"doneagoms,omitempty"`   // time since the context was first seen done (poll interval resolution)
+	HasDeadline bool   `json:"hasdeadline,omitempty"` // true if the context has a deadline
+	DeadlineMs  int64  `json:"deadlinems,omitempty"`  // time until the deadline (negative once it has passed)
+}
+
+// ContextPollObj is the PollObj of a WatchType_Context watch
+type ContextPollObj struct {
+	ctx       context.Context
+	createdTs time.Time
+	doneTs    atomic.Int64 // unix millis when the context was first seen done (0 if not yet)
+}
+
+// MakeContextPollObj creates the poll object for ctx, the age is measured from now
+func MakeContextPollObj(ctx context.Context) *ContextPollObj {
+	return &ContextPollObj{ctx: ctx, createdTs: time.Now()}
+}
+
+// GetState returns the current state of the watched context
+func (c *ContextPollObj) GetState(now time.Time) ContextState {
+	state := ContextState{
+		AgeMs: now.Sub(c.createdTs).Milliseconds(),
+	}
+	if deadline, ok := c.ctx.Deadline(); ok {
+		state.HasDeadline = true
+		state.DeadlineMs = deadline.Sub(now).Milliseconds()
+	}
+	err := c.ctx.Err()
+	if err == nil {
+		return state
+	}
+	state.Done = true
+	state.Err = err.Error()
+	if cause := context.Cause(c.ctx); cause != nil && !errors.Is(err, cause) {
+		state.Cause = cause.Error()
+	}
+	c.doneTs.CompareAndSwap(0, now.UnixMilli())
+	state.DoneAgoMs = now.UnixMilli() - c.doneTs.Load()
+	return state
+}
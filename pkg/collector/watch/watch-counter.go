@@ -0,0 +1,47 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"sync/atomic"
+
+	"github.com/outrigdev/goid"
+)
+
+// CounterTag is added to every counter watch (search with #counter)
+const CounterTag = "counter"
+
+// CounterShards is the number of shards a counter's value is spread over (a power of 2)
+const CounterShards = 16
+
+// counterShard is padded to a cache line so shards updated from different cores don't contend
+type counterShard struct {
+	val atomic.Int64
+	_   [56]byte
+}
+
+// CounterPollObj is the PollObj of a WatchType_Counter watch. Goroutines add to the shard picked by their
+// goroutine id, the shards are summed when the watch is polled.
+type CounterPollObj struct {
+	shards [CounterShards]counterShard
+}
+
+// MakeCounterPollObj creates the poll object of a counter
+func MakeCounterPollObj() *CounterPollObj {
+	return &CounterPollObj{}
+}
+
+// Add adds n to the counter
+func (c *CounterPollObj) Add(n int64) {
+	c.shards[goid.Get()&(CounterShards-1)].val.Add(n)
+}
+
+// GetValue returns the cumulative value of the counter
+func (c *CounterPollObj) GetValue() int64 {
+	var rtn int64
+	for i := range c.shards {
+		rtn += c.shards[i].val.Load()
+	}
+	return rtn
+}
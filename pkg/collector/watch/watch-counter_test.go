@@ -0,0 +1,46 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func TestCounterPollObj(t *testing.T) {
+	c := MakeCounterPollObj()
+	const numGoroutines = 50
+	const numAdds = 1000
+	var wg sync.WaitGroup
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numAdds; j++ {
+				c.Add(2)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := c.GetValue(); got != 2*numGoroutines*numAdds {
+		t.Errorf("got %d, want the sum of all shards %d", got, 2*numGoroutines*numAdds)
+	}
+	var numUsed int
+	for i := range c.shards {
+		if c.shards[i].val.Load() != 0 {
+			numUsed++
+		}
+	}
+	if numUsed < 2 {
+		t.Errorf("got %d shards used by %d goroutines, want the adds spread over the shards", numUsed, numGoroutines)
+	}
+
+	// the watch reports the sum
+	sample := GetInstance().collectWatch(&ds.WatchDecl{Name: "counter", WatchType: WatchType_Counter, PollObj: c})
+	if sample == nil || sample.Error != "" || sample.Val != "100000" {
+		t.Errorf("got sample %+v, want the value 100000", sample)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// the group kinds are also the tag of the group's watch (search with #waitgroup or #errgroup)
+const (
+	GroupKind_WaitGroup = "waitgroup"
+	GroupKind_ErrGroup  = "errgroup"
+)
+
+// GroupMaxGoIds is the max number of running member goroutine ids reported by a group watch
+const GroupMaxGoIds = 20
+
+// GroupState is the value reported by a group watch (see outrig.NewWaitGroup and outrig.NewErrGroup).
+// Groups created with the same name share a watch, so the counts are totals over all of them.
+type GroupState struct {
+	Kind          string  `json:"kind"`
+	Pending       int64   `json:"pending"`                 // the group counter (members that were added but are not done)
+	Running       int64   `json:"running"`                 // member goroutines (started with Go) that are running
+	Started       int64   `json:"started"`                 // member goroutines started with Go
+	Finished      int64   `json:"finished"`                // member goroutines that returned
+	Waiters       int64   `json:"waiters"`                 // goroutines blocked in Wait
+	LongestWaitMs int64   `json:"longestwaitms,omitempty"` // how long the oldest waiter has been waiting
+	Errors        int64   `json:"errors,omitempty"`        // members that returned an error (errgroup)
+	LastErr       string  `json:"lasterr,omitempty"`       // the most recent member error (errgroup)
+	RunningGoIds  []int64 `json:"runninggoids,omitempty"`  // ids of the running members (at most GroupMaxGoIds)
+}
+
+// GroupPollObj is the PollObj of a WatchType_Group watch
+type GroupPollObj struct {
+	lock     sync.Mutex
+	kind     string
+	pending  int64
+	started  int64
+	finished int64
+	errors   int64
+	lastErr  string
+	running  map[int64]bool      // goid => true
+	waiters  map[int64]time.Time // wait id => wait start
+	waitId   int64
+}
+
+// MakeGroupPollObj creates the poll object for the groups of the given kind
+func MakeGroupPollObj(kind string) *GroupPollObj {
+	return &GroupPollObj{
+		kind:    kind,
+		running: make(map[int64]bool),
+		waiters: make(map[int64]time.Time),
+	}
+}
+
+// Add adds delta to the pending counter
+func (g *GroupPollObj) Add(delta int) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.pending += int64(delta)
+}
+
+// MemberStart records that the member goroutine goId started running
+func (g *GroupPollObj) MemberStart(goId int64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.started++
+	g.running[goId] = true
+}
+
+// MemberEnd records that the member goroutine goId returned err (nil for waitgroup members)
+func (g *GroupPollObj) MemberEnd(goId int64, err error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.finished++
+	delete(g.running, goId)
+	if err != nil {
+		g.errors++
+		g.lastErr = err.Error()
+	}
+}
+
+// WaitStart records a goroutine that started waiting, the returned id is passed to WaitEnd
+func (g *GroupPollObj) WaitStart() int64 {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.waitId++
+	g.waiters[g.waitId] = time.Now()
+	return g.waitId
+}
+
+// WaitEnd records that the waiter returned by WaitStart is done waiting
+func (g *GroupPollObj) WaitEnd(waitId int64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	delete(g.waiters, waitId)
+}
+
+// GetState returns the current state of the groups
+func (g *GroupPollObj) GetState(now time.Time) GroupState {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	state := GroupState{
+		Kind:     g.kind,
+		Pending:  g.pending,
+		Running:  int64(len(g.running)),
+		Started:  g.started,
+		Finished: g.finished,
+		Waiters:  int64(len(g.waiters)),
+		Errors:   g.errors,
+		LastErr:  g.lastErr,
+	}
+	for _, waitStart := range g.waiters {
+		state.LongestWaitMs = max(state.LongestWaitMs, now.Sub(waitStart).Milliseconds())
+	}
+	for goId := range g.running {
+		state.RunningGoIds = append(state.RunningGoIds, goId)
+	}
+	sort.Slice(state.RunningGoIds, func(i, j int) bool {
+		return state.RunningGoIds[i] < state.RunningGoIds[j]
+	})
+	if len(state.RunningGoIds) > GroupMaxGoIds {
+		state.RunningGoIds = state.RunningGoIds[:GroupMaxGoIds]
+	}
+	return state
+}
@@ -0,0 +1,129 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// PoolTag is added to every worker pool watch and to the pool's worker goroutines (search with #pool)
+const PoolTag = "pool"
+
+// PoolNumRecentTasks is the number of task durations kept for the pool's task duration percentiles
+const PoolNumRecentTasks = 1000
+
+// PoolState is the value reported by a worker pool watch (see outrig.Pool)
+type PoolState struct {
+	Size         int     `json:"size"`                 // the configured number of workers
+	Workers      int64   `json:"workers"`              // worker goroutines (started with Go) that are running
+	Busy         int64   `json:"busy"`                 // tasks that are running
+	Idle         int64   `json:"idle"`                 // running workers that are not running a task
+	QueueDepth   *int64  `json:"queuedepth,omitempty"` // queued tasks (only if the pool has a queue depth func)
+	TasksStarted int64   `json:"tasksstarted"`
+	TasksDone    int64   `json:"tasksdone"`
+	TaskAvgMs    float64 `json:"taskavgms,omitempty"` // the duration stats are over the most recent tasks (PoolNumRecentTasks)
+	TaskP50Ms    float64 `json:"taskp50ms,omitempty"`
+	TaskP95Ms    float64 `json:"taskp95ms,omitempty"`
+	TaskMaxMs    float64 `json:"taskmaxms,omitempty"`
+}
+
+// PoolPollObj is the PollObj of a WatchType_Pool watch
+type PoolPollObj struct {
+	lock           sync.Mutex
+	size           int
+	workers        int64
+	busy           int64
+	tasksStarted   int64
+	tasksDone      int64
+	recentTasks    []time.Duration // ring buffer of the most recent task durations
+	recentTaskPos  int
+	queueDepthFunc func() int
+}
+
+// MakePoolPollObj creates the poll object for a pool with size workers
+func MakePoolPollObj(size int) *PoolPollObj {
+	return &PoolPollObj{size: size}
+}
+
+// SetQueueDepthFunc sets the function that returns the number of queued tasks (called when the watch is polled)
+func (p *PoolPollObj) SetQueueDepthFunc(fn func() int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.queueDepthFunc = fn
+}
+
+// WorkerStart records a worker goroutine that started
+func (p *PoolPollObj) WorkerStart() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.workers++
+}
+
+// WorkerEnd records a worker goroutine that returned
+func (p *PoolPollObj) WorkerEnd() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.workers--
+}
+
+// TaskStart records a task that started running
+func (p *PoolPollObj) TaskStart() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.busy++
+	p.tasksStarted++
+}
+
+// TaskEnd records a task that finished after running for dur
+func (p *PoolPollObj) TaskEnd(dur time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.busy--
+	p.tasksDone++
+	if len(p.recentTasks) < PoolNumRecentTasks {
+		p.recentTasks = append(p.recentTasks, dur)
+		return
+	}
+	p.recentTasks[p.recentTaskPos] = dur
+	p.recentTaskPos = (p.recentTaskPos + 1) % PoolNumRecentTasks
+}
+
+// GetState returns the current state of the pool
+func (p *PoolPollObj) GetState() PoolState {
+	p.lock.Lock()
+	state := PoolState{
+		Size:         p.size,
+		Workers:      p.workers,
+		Busy:         p.busy,
+		Idle:         max(p.workers-p.busy, 0),
+		TasksStarted: p.tasksStarted,
+		TasksDone:    p.tasksDone,
+	}
+	durations := make([]time.Duration, len(p.recentTasks))
+	copy(durations, p.recentTasks)
+	queueDepthFunc := p.queueDepthFunc
+	p.lock.Unlock()
+
+	// the queue depth func is user code, don't hold the lock while calling it
+	if queueDepthFunc != nil {
+		queueDepth := int64(queueDepthFunc())
+		state.QueueDepth = &queueDepth
+	}
+	if len(durations) > 0 {
+		sort.Slice(durations, func(i, j int) bool {
+			return durations[i] < durations[j]
+		})
+		var total time.Duration
+		for _, dur := range durations {
+			total += dur
+		}
+		state.TaskAvgMs = durationMs(total / time.Duration(len(durations)))
+		state.TaskP50Ms = durationMs(durations[len(durations)*50/100])
+		state.TaskP95Ms = durationMs(durations[len(durations)*95/100])
+		state.TaskMaxMs = durationMs(durations[len(durations)-1])
+	}
+	return state
+}
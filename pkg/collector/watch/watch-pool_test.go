@@ -0,0 +1,69 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolPollObj(t *testing.T) {
+	pool := MakePoolPollObj(4)
+	for i := 0; i < 3; i++ {
+		pool.WorkerStart()
+	}
+	for i := 1; i <= 100; i++ {
+		pool.TaskStart()
+		pool.TaskEnd(time.Duration(i) * time.Millisecond)
+	}
+	pool.TaskStart()
+	pool.TaskStart()
+
+	state := pool.GetState()
+	expect := PoolState{
+		Size:         4,
+		Workers:      3,
+		Busy:         2,
+		Idle:         1,
+		TasksStarted: 102,
+		TasksDone:    100,
+		TaskAvgMs:    50.5,
+		TaskP50Ms:    51,
+		TaskP95Ms:    96,
+		TaskMaxMs:    100,
+	}
+	if state != expect {
+		t.Errorf("got %+v, want %+v", state, expect)
+	}
+
+	// the queue depth is only reported with a queue depth func
+	pool.SetQueueDepthFunc(func() int { return 7 })
+	if state := pool.GetState(); state.QueueDepth == nil || *state.QueueDepth != 7 {
+		t.Errorf("got queue depth %v, want 7", state.QueueDepth)
+	}
+
+	// idle can't go negative when a task runs outside of a registered worker
+	pool.WorkerEnd()
+	pool.WorkerEnd()
+	if state := pool.GetState(); state.Workers != 1 || state.Idle != 0 {
+		t.Errorf("got workers %d, idle %d, want 1 worker and no idle ones", state.Workers, state.Idle)
+	}
+}
+
+func TestPoolPollObjRecentTasks(t *testing.T) {
+	pool := MakePoolPollObj(1)
+	// the long tasks are pushed out of the duration stats by the newer short ones
+	for i := 0; i < 10; i++ {
+		pool.TaskStart()
+		pool.TaskEnd(time.Second)
+	}
+	for i := 0; i < PoolNumRecentTasks; i++ {
+		pool.TaskStart()
+		pool.TaskEnd(time.Millisecond)
+	}
+	state := pool.GetState()
+	if state.TaskMaxMs != 1 || state.TaskAvgMs != 1 || state.TasksDone != int64(PoolNumRecentTasks+10) {
+		t.Errorf("got %+v, want the stats of the last %d tasks", state, PoolNumRecentTasks)
+	}
+}
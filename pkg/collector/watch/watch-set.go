@@ -0,0 +1,59 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SetWatchValue sets the value of a settable watch by calling its setter with value converted
+// to the setter's argument type. value is JSON (e.g. true, 42, {"level":"debug"}), for string
+// setters plain text is accepted as well. Setter panics are returned as errors.
+func (wc *WatchCollector) SetWatchValue(name string, value string) error {
+	decl := wc.getWatchDecl(name)
+	if decl == nil {
+		return fmt.Errorf("watch %q not found", name)
+	}
+	if !decl.Settable || decl.SetFn == nil {
+		return fmt.Errorf("watch %q is not settable", name)
+	}
+	fnVal := reflect.ValueOf(decl.SetFn)
+	argVal, err := convertSetValue(value, fnVal.Type().In(0))
+	if err != nil {
+		return fmt.Errorf("invalid value for watch %q: %w", name, err)
+	}
+	// polling watches pick up the new value on the next collection (push watches are up to the setter)
+	return callSetFn(fnVal, argVal)
+}
+
+// convertSetValue unmarshals the JSON value into a new value of argType
+func convertSetValue(value string, argType reflect.Type) (reflect.Value, error) {
+	argPtr := reflect.New(argType)
+	err := json.Unmarshal([]byte(value), argPtr.Interface())
+	if err != nil {
+		if argType.Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(value), "\"") {
+			// plain text for a string setter
+			argPtr.Elem().SetString(value)
+			return argPtr.Elem(), nil
+		}
+		return reflect.Value{}, fmt.Errorf("cannot convert %q to %s: %w", value, argType.String(), err)
+	}
+	return argPtr.Elem(), nil
+}
+
+func callSetFn(fnVal reflect.Value, argVal reflect.Value) (rtnErr error) {
+	defer func() {
+		if r := recover(); r != nil {
+			rtnErr = fmt.Errorf("setter panicked: %v", r)
+		}
+	}()
+	results := fnVal.Call([]reflect.Value{argVal})
+	if len(results) == 1 && !results[0].IsNil() {
+		return results[0].Interface().(error)
+	}
+	return nil
+}
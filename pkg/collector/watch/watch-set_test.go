@@ -0,0 +1,81 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testSetConfig struct {
+	Level string `json:"level"`
+	Limit int    `json:"limit"`
+}
+
+func TestConvertSetValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		argType   reflect.Type
+		expect    any
+		expectErr bool
+	}{
+		{"bool", "true", reflect.TypeOf(false), true, false},
+		{"int", "42", reflect.TypeOf(0), 42, false},
+		{"float", "1.5", reflect.TypeOf(0.0), 1.5, false},
+		{"json string", `"debug"`, reflect.TypeOf(""), "debug", false},
+		{"plain text string", "debug mode", reflect.TypeOf(""), "debug mode", false},
+		{"struct", `{"level":"warn","limit":10}`, reflect.TypeOf(testSetConfig{}), testSetConfig{Level: "warn", Limit: 10}, false},
+		{"pointer", `{"level":"warn"}`, reflect.TypeOf(&testSetConfig{}), &testSetConfig{Level: "warn"}, false},
+		{"slice", `["a","b"]`, reflect.TypeOf([]string{}), []string{"a", "b"}, false},
+		{"not a number", "lots", reflect.TypeOf(0), nil, true},
+		{"wrong type", `"42"`, reflect.TypeOf(0), nil, true},
+		{"invalid quoted string", `"unterminated`, reflect.TypeOf(""), nil, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := convertSetValue(tc.value, tc.argType)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Interface(), tc.expect) {
+				t.Errorf("got %#v, want %#v", got.Interface(), tc.expect)
+			}
+		})
+	}
+}
+
+func TestCallSetFn(t *testing.T) {
+	var got int
+	setter := reflect.ValueOf(func(v int) { got = v })
+	if err := callSetFn(setter, reflect.ValueOf(5)); err != nil || got != 5 {
+		t.Errorf("got %d, %v, want the setter called with 5", got, err)
+	}
+
+	errSetter := reflect.ValueOf(func(v int) error {
+		if v < 0 {
+			return errors.New("must not be negative")
+		}
+		return nil
+	})
+	if err := callSetFn(errSetter, reflect.ValueOf(1)); err != nil {
+		t.Errorf("got %v for a nil error", err)
+	}
+	if err := callSetFn(errSetter, reflect.ValueOf(-1)); err == nil || err.Error() != "must not be negative" {
+		t.Errorf("got %v, want the setter's error", err)
+	}
+
+	panicSetter := reflect.ValueOf(func(v int) { panic("boom") })
+	if err := callSetFn(panicSetter, reflect.ValueOf(1)); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("got %v, want the panic as an error", err)
+	}
+}
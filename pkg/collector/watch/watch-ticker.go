@@ -0,0 +1,114 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"sync"
+	"time"
+)
+
+// TickerTag is added to every ticker watch (search with #ticker)
+const TickerTag = "ticker"
+
+// TickerNumRecentTicks is the number of tick timestamps reported by a ticker watch
+const TickerNumRecentTicks = 10
+
+// TickerState is the value reported by a ticker watch (see outrig.WatchTicker)
+type TickerState struct {
+	IntervalMs    float64 `json:"intervalms"`
+	Ticks         int64   `json:"ticks"`
+	MissedTicks   int64   `json:"missedticks"`             // ticks that were skipped (late runtime timer or a slow receiver)
+	SlowReceiver  int64   `json:"slowreceiver,omitempty"`  // ticks dropped because the previous tick wasn't received yet
+	AvgDriftMs    float64 `json:"avgdriftms"`              // average difference between the actual and expected tick interval
+	MaxDriftMs    float64 `json:"maxdriftms"`              // largest difference between the actual and expected tick interval
+	LastTickAgoMs int64   `json:"lasttickagoms,omitempty"` // time since the last tick
+	RecentTicks   []int64 `json:"recentticks,omitempty"`   // unix millis of the most recent ticks (oldest first)
+}
+
+// TickerPollObj is the PollObj of a WatchType_Ticker watch
+type TickerPollObj struct {
+	lock          sync.Mutex
+	interval      time.Duration
+	lastTick      time.Time // zero until the first tick (or after Reset)
+	ticks         int64
+	missed        int64
+	slowReceiver  int64
+	driftSum      time.Duration
+	driftCount    int64
+	maxDrift      time.Duration
+	recentTicks   [TickerNumRecentTicks]int64
+	recentTickPos int
+}
+
+// MakeTickerPollObj creates the poll object for a ticker with interval d
+func MakeTickerPollObj(d time.Duration) *TickerPollObj {
+	return &TickerPollObj{interval: d}
+}
+
+// RecordTick records a tick at ts, delivered is false if the tick was dropped because the receiver was too slow
+func (t *TickerPollObj) RecordTick(ts time.Time, delivered bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.ticks++
+	if !delivered {
+		t.slowReceiver++
+		t.missed++
+	}
+	if !t.lastTick.IsZero() {
+		gap := ts.Sub(t.lastTick)
+		// the runtime skips ticks when the timer fires late, so a long gap means ticks were missed
+		if skipped := int64((gap + t.interval/2) / t.interval); skipped > 1 {
+			t.missed += skipped - 1
+			gap -= time.Duration(skipped-1) * t.interval
+		}
+		drift := gap - t.interval
+		if drift < 0 {
+			drift = -drift
+		}
+		t.driftSum += drift
+		t.driftCount++
+		t.maxDrift = max(t.maxDrift, drift)
+	}
+	t.lastTick = ts
+	t.recentTicks[t.recentTickPos] = ts.UnixMilli()
+	t.recentTickPos = (t.recentTickPos + 1) % TickerNumRecentTicks
+}
+
+// Reset changes the interval, the next tick is not compared against the ticks before the reset
+func (t *TickerPollObj) Reset(d time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.interval = d
+	t.lastTick = time.Time{}
+}
+
+// GetState returns the current stats of the ticker
+func (t *TickerPollObj) GetState(now time.Time) TickerState {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	state := TickerState{
+		IntervalMs:   durationMs(t.interval),
+		Ticks:        t.ticks,
+		MissedTicks:  t.missed,
+		SlowReceiver: t.slowReceiver,
+		MaxDriftMs:   durationMs(t.maxDrift),
+	}
+	if t.driftCount > 0 {
+		state.AvgDriftMs = durationMs(t.driftSum / time.Duration(t.driftCount))
+	}
+	if !t.lastTick.IsZero() {
+		state.LastTickAgoMs = now.Sub(t.lastTick).Milliseconds()
+	}
+	for i := 0; i < TickerNumRecentTicks; i++ {
+		ts := t.recentTicks[(t.recentTickPos+i)%TickerNumRecentTicks]
+		if ts != 0 {
+			state.RecentTicks = append(state.RecentTicks, ts)
+		}
+	}
+	return state
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
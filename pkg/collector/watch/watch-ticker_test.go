@@ -0,0 +1,55 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTickerRecordTick(t *testing.T) {
+	start := time.UnixMilli(1_000_000)
+	ticker := MakeTickerPollObj(100 * time.Millisecond)
+	ticker.RecordTick(start, true)
+	ticker.RecordTick(start.Add(110*time.Millisecond), true) // 10ms late
+	ticker.RecordTick(start.Add(200*time.Millisecond), true) // 10ms early
+	ticker.RecordTick(start.Add(500*time.Millisecond), true) // 2 ticks skipped by the runtime, on time otherwise
+	ticker.RecordTick(start.Add(600*time.Millisecond), false)
+
+	state := ticker.GetState(start.Add(650 * time.Millisecond))
+	expect := TickerState{
+		IntervalMs:    100,
+		Ticks:         5,
+		MissedTicks:   3,
+		SlowReceiver:  1,
+		AvgDriftMs:    5,
+		MaxDriftMs:    10,
+		LastTickAgoMs: 50,
+		RecentTicks:   []int64{1_000_000, 1_000_110, 1_000_200, 1_000_500, 1_000_600},
+	}
+	if !reflect.DeepEqual(state, expect) {
+		t.Errorf("got %+v, want %+v", state, expect)
+	}
+
+	// after a reset the next tick isn't compared with the old ticks
+	ticker.Reset(50 * time.Millisecond)
+	ticker.RecordTick(start.Add(10*time.Second), true)
+	state = ticker.GetState(start.Add(10 * time.Second))
+	if state.IntervalMs != 50 || state.MissedTicks != 3 || state.MaxDriftMs != 10 || state.AvgDriftMs != 5 {
+		t.Errorf("got %+v after the reset, want the missed ticks and drift unchanged", state)
+	}
+}
+
+func TestTickerRecentTicks(t *testing.T) {
+	start := time.UnixMilli(1_000_000)
+	ticker := MakeTickerPollObj(time.Second)
+	for i := 0; i < TickerNumRecentTicks+3; i++ {
+		ticker.RecordTick(start.Add(time.Duration(i)*time.Second), true)
+	}
+	recent := ticker.GetState(start).RecentTicks
+	if len(recent) != TickerNumRecentTicks || recent[0] != 1_003_000 || recent[len(recent)-1] != 1_000_000+int64(TickerNumRecentTicks+2)*1000 {
+		t.Errorf("got recent ticks %v, want the newest %d oldest first", recent, TickerNumRecentTicks)
+	}
+}
@@ -0,0 +1,17 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.24
+
+package watch
+
+import "runtime"
+
+// TrackObject counts obj as a live instance until it is garbage collected (uses runtime.AddCleanup, so it
+// doesn't interfere with finalizers or other cleanups set on obj)
+func TrackObject[T any](t *TypeCountPollObj, obj *T) {
+	t.RecordCreated()
+	runtime.AddCleanup(obj, func(t *TypeCountPollObj) {
+		t.RecordFreed()
+	}, t)
+}
@@ -0,0 +1,17 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !go1.24
+
+package watch
+
+import "runtime"
+
+// TrackObject counts obj as a live instance until it is garbage collected. Before Go 1.24 (no runtime.AddCleanup)
+// this sets a finalizer on obj, so it must not be used for objects that have their own finalizer.
+func TrackObject[T any](t *TypeCountPollObj, obj *T) {
+	t.RecordCreated()
+	runtime.SetFinalizer(obj, func(*T) {
+		t.RecordFreed()
+	})
+}
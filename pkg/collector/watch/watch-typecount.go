@@ -0,0 +1,65 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"sync/atomic"
+)
+
+// TypeCountTag is added to every type count watch (search with #typecount)
+const TypeCountTag = "typecount"
+
+// TypeCountState is the value reported by a type count watch (see outrig.WatchTypeCount)
+type TypeCountState struct {
+	Type     string `json:"type"`
+	Live     int64  `json:"live"`     // instances created and not yet freed (or garbage collected)
+	PeakLive int64  `json:"peaklive"` // largest live count seen
+	Created  int64  `json:"created"`
+	Freed    int64  `json:"freed"`
+}
+
+// TypeCountPollObj is the PollObj of a WatchType_TypeCount watch. The counts are updated from constructors
+// and from cleanups run by the garbage collector, so they are kept in atomics.
+type TypeCountPollObj struct {
+	typeName string
+	created  atomic.Int64
+	freed    atomic.Int64
+	peakLive atomic.Int64
+}
+
+// MakeTypeCountPollObj creates the poll object for the instances of typeName
+func MakeTypeCountPollObj(typeName string) *TypeCountPollObj {
+	return &TypeCountPollObj{typeName: typeName}
+}
+
+// RecordCreated counts a new live instance
+func (t *TypeCountPollObj) RecordCreated() {
+	created := t.created.Add(1)
+	live := created - t.freed.Load()
+	for {
+		peak := t.peakLive.Load()
+		if live <= peak || t.peakLive.CompareAndSwap(peak, live) {
+			return
+		}
+	}
+}
+
+// RecordFreed counts an instance that was freed (or garbage collected)
+func (t *TypeCountPollObj) RecordFreed() {
+	t.freed.Add(1)
+}
+
+// GetState returns the current counts
+func (t *TypeCountPollObj) GetState() TypeCountState {
+	// load freed first so a concurrent create/free pair can't make live negative
+	freed := t.freed.Load()
+	created := t.created.Load()
+	return TypeCountState{
+		Type:     t.typeName,
+		Live:     created - freed,
+		PeakLive: t.peakLive.Load(),
+		Created:  created,
+		Freed:    freed,
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTypeCountPollObj(t *testing.T) {
+	tc := MakeTypeCountPollObj("main.Conn")
+	tc.RecordCreated()
+	tc.RecordCreated()
+	tc.RecordCreated()
+	tc.RecordFreed()
+	tc.RecordFreed()
+	tc.RecordCreated()
+	expect := TypeCountState{Type: "main.Conn", Live: 2, PeakLive: 3, Created: 4, Freed: 2}
+	if got := tc.GetState(); got != expect {
+		t.Errorf("got %+v, want %+v", got, expect)
+	}
+}
+
+func TestTypeCountPollObjConcurrent(t *testing.T) {
+	tc := MakeTypeCountPollObj("main.Conn")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				tc.RecordCreated()
+				tc.RecordFreed()
+			}
+		}()
+	}
+	wg.Wait()
+	state := tc.GetState()
+	if state.Live != 0 || state.Created != 10000 || state.Freed != 10000 || state.PeakLive < 1 || state.PeakLive > 10 {
+		t.Errorf("got %+v, want everything freed with a peak of 1-10", state)
+	}
+}
+
+type testTrackedObj struct {
+	buf []byte
+}
+
+func TestTrackObject(t *testing.T) {
+	tc := MakeTypeCountPollObj("watch.testTrackedObj")
+	kept := &testTrackedObj{buf: make([]byte, 16)}
+	TrackObject(tc, kept)
+	for i := 0; i < 5; i++ {
+		TrackObject(tc, &testTrackedObj{buf: make([]byte, 16)})
+	}
+	// the cleanups run in the background after a gc
+	deadline := time.Now().Add(5 * time.Second)
+	for tc.GetState().Freed < 5 && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	state := tc.GetState()
+	if state.Created != 6 || state.Freed != 5 || state.Live != 1 {
+		t.Errorf("got %+v, want the 5 dropped objects freed", state)
+	}
+	runtime.KeepAlive(kept)
+}
@@ -4,10 +4,13 @@
 package watch
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
 	"sync"
+
+	"github.com/outrigdev/outrig/pkg/ds"
 )
 
 // ValidatePollFunc validates that the provided function is suitable for use as a poll function.
@@ -37,6 +40,14 @@ func ValidatePollFunc(fn any) error {
 	return nil
 }
 
+// ValidatePollContext validates that the provided context can be watched (it must be non-nil)
+func ValidatePollContext(ctx context.Context) error {
+	if ctx == nil {
+		return fmt.Errorf("PollContext requires a non-nil context")
+	}
+	return nil
+}
+
 // ValidatePollAtomic validates that the provided value is suitable for use as an atomic poll value.
 // A valid atomic poll value must:
 // - Be non-nil
@@ -118,3 +129,57 @@ func ValidatePollSync(lock sync.Locker, val any) error {
 
 	return nil
 }
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ValidateSetFunc validates that the provided function is suitable for use as a watch setter.
+// A valid setter must:
+// - Be non-nil
+// - Be a function
+// - Take exactly 1 argument (the new value)
+// - Return nothing or a single error
+func ValidateSetFunc(fn any) error {
+	if fn == nil {
+		return fmt.Errorf("Settable requires a non-nil function")
+	}
+
+	fnType := reflect.TypeOf(fn)
+	if fnType.Kind() != reflect.Func {
+		return fmt.Errorf("Settable requires a function, got %s", fnType.Kind())
+	}
+
+	if fnType.NumIn() != 1 {
+		return fmt.Errorf("Settable requires a function with 1 argument, got %d", fnType.NumIn())
+	}
+
+	if fnType.NumOut() > 1 || (fnType.NumOut() == 1 && fnType.Out(0) != errorType) {
+		return fmt.Errorf("Settable requires a function that returns nothing or an error")
+	}
+
+	return nil
+}
+
+// ValidateUnit validates a watch unit (see ds.WatchUnit*, other units are allowed as long as they are short
+// and don't contain whitespace)
+func ValidateUnit(unit string) error {
+	if unit == "" {
+		return fmt.Errorf("WithUnit requires a non-empty unit")
+	}
+	if len(unit) > ds.MaxWatchUnitLen {
+		return fmt.Errorf("WithUnit unit %q is too long (max %d characters)", unit, ds.MaxWatchUnitLen)
+	}
+	if strings.ContainsAny(unit, " \t\r\n") {
+		return fmt.Errorf("WithUnit unit %q must not contain whitespace", unit)
+	}
+	return nil
+}
+
+// ValidateDisplay validates a watch display hint (one of the ds.WatchDisplay* constants)
+func ValidateDisplay(display string) error {
+	switch display {
+	case ds.WatchDisplayGauge, ds.WatchDisplayRate, ds.WatchDisplayRaw:
+		return nil
+	}
+	return fmt.Errorf("WithDisplay hint %q is not valid (must be %q, %q or %q)", display,
+		ds.WatchDisplayGauge, ds.WatchDisplayRate, ds.WatchDisplayRaw)
+}
@@ -0,0 +1,45 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func TestValidateUnit(t *testing.T) {
+	tests := []struct {
+		unit      string
+		expectErr bool
+	}{
+		{ds.WatchUnitBytes, false},
+		{ds.WatchUnitPercent, false},
+		{"req/s", false},
+		{strings.Repeat("x", ds.MaxWatchUnitLen), false},
+		{"", true},
+		{strings.Repeat("x", ds.MaxWatchUnitLen+1), true},
+		{"per sec", true},
+		{"ms\n", true},
+	}
+	for _, tc := range tests {
+		if err := ValidateUnit(tc.unit); (err != nil) != tc.expectErr {
+			t.Errorf("ValidateUnit(%q): got %v, want error %v", tc.unit, err, tc.expectErr)
+		}
+	}
+}
+
+func TestValidateDisplay(t *testing.T) {
+	for _, display := range []string{ds.WatchDisplayGauge, ds.WatchDisplayRate, ds.WatchDisplayRaw} {
+		if err := ValidateDisplay(display); err != nil {
+			t.Errorf("ValidateDisplay(%q): %v", display, err)
+		}
+	}
+	for _, display := range []string{"", "Rate", "counter"} {
+		if err := ValidateDisplay(display); err == nil {
+			t.Errorf("ValidateDisplay(%q): got nil, want an error", display)
+		}
+	}
+}
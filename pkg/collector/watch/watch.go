@@ -6,6 +6,7 @@ package watch
 import (
 	"encoding/json"
 	"fmt"
+	"hash/crc32"
 	"reflect"
 	"slices"
 	"sort"
@@ -17,23 +18,41 @@ import (
 	"github.com/outrigdev/outrig/pkg/config"
 	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/global"
+	"github.com/outrigdev/outrig/pkg/jsonpatch"
+	"github.com/outrigdev/outrig/pkg/redact"
 	"github.com/outrigdev/outrig/pkg/utilds"
 	"github.com/outrigdev/outrig/pkg/utilfn"
 )
 
+// MaxWatchVals is the max number of registered watches, and the max number of pushed samples buffered between collections.
+// When it is reached the least recently used push/static watch is evicted (polling watches are never evicted).
 const MaxWatchVals = 10000
 const MaxWatchValSize = 128 * 1024
 
+// json values of at least WatchPatchMinValSize bytes are sent as a patch against the previous sample when the
+// patch is less than half the size of the value. After WatchPatchMaxChain patches in a row the full value is sent
+// again (so a value the server could not patch is corrected).
+const (
+	WatchPatchMinValSize = 2048
+	WatchPatchMaxChain   = 60
+)
+
 const (
 	WatchFormat_Json     = "json"
 	WatchFormat_Stringer = "stringer"
 	WatchFormat_Gofmt    = "gofmt"
 
-	WatchType_Sync   = "sync"
-	WatchType_Atomic = "atomic"
-	WatchType_Func   = "func"
-	WatchType_Push   = "push"
-	WatchType_Static = "static"
+	WatchType_Sync      = "sync"
+	WatchType_Atomic    = "atomic"
+	WatchType_Func      = "func"
+	WatchType_Push      = "push"
+	WatchType_Static    = "static"
+	WatchType_Context   = "context"
+	WatchType_Ticker    = "ticker"
+	WatchType_Group     = "group"
+	WatchType_TypeCount = "typecount"
+	WatchType_Pool      = "pool"
+	WatchType_Counter   = "counter"
 )
 
 // WatchCollector implements the collector.Collector interface for watch collection
@@ -47,7 +66,11 @@ type WatchCollector struct {
 	nextSendFull      bool                      // true for full update, false for delta update
 	regErrors         []ds.ErrWithContext       // errors encountered during watch registration
 	regErrorsDeltaIdx int
-	newDecls          []ds.WatchDecl // new declarations added since last delta
+	newDecls          []ds.WatchDecl   // new declarations added since last delta
+	lastUsed          map[string]int64 // watch name => useSeq of the last registration/push/poll, for LRU eviction
+	useSeq            int64
+	limitStats        ds.WatchLimitStats
+	patchChains       map[string]int // watch name => number of patches sent in a row
 }
 
 // CollectorName returns the unique name of the collector
@@ -66,8 +89,11 @@ func GetInstance() *WatchCollector {
 			config:           utilds.NewSetOnceConfig(config.DefaultConfig().Collectors.Watch),
 			watchDecls:       make(map[string]*ds.WatchDecl),
 			lastWatchSamples: make(map[string]ds.WatchSample),
+			patchChains:      make(map[string]int),
 			nextSendFull:     true, // First send is always a full update
 			regErrors:        make([]ds.ErrWithContext, 0),
+			lastUsed:         make(map[string]int64),
+			limitStats:       ds.WatchLimitStats{MaxWatches: MaxWatchVals},
 		}
 		instance.executor = collector.MakePeriodicExecutor("WatchCollector", 1*time.Second, instance.CollectWatches)
 	})
@@ -102,6 +128,43 @@ func (wc *WatchCollector) UnregisterWatch(decl *ds.WatchDecl) {
 
 	// Remove from watchDecls map
 	delete(wc.watchDecls, decl.Name)
+	delete(wc.lastUsed, decl.Name)
+}
+
+func (wc *WatchCollector) markUsed_nolock(name string) {
+	if _, ok := wc.watchDecls[name]; !ok {
+		return
+	}
+	wc.useSeq++
+	wc.lastUsed[name] = wc.useSeq
+}
+
+// evictLRUWatch_nolock unregisters the least recently used push/static watch to make room for a new watch
+// returns false if there is no watch that can be evicted
+func (wc *WatchCollector) evictLRUWatch_nolock() bool {
+	var lruName string
+	var lruSeq int64
+	for name, decl := range wc.watchDecls {
+		if decl.WatchType != WatchType_Push && decl.WatchType != WatchType_Static {
+			continue
+		}
+		if seq := wc.lastUsed[name]; lruName == "" || seq < lruSeq {
+			lruName = name
+			lruSeq = seq
+		}
+	}
+	if lruName == "" {
+		return false
+	}
+	delete(wc.watchDecls, lruName)
+	delete(wc.lastUsed, lruName)
+	wc.newDecls = append(wc.newDecls, ds.WatchDecl{
+		Name:         lruName,
+		Unregistered: true,
+		Evicted:      true,
+	})
+	wc.limitStats.NumEvicted++
+	return true
 }
 
 // RegisterWatchDecl registers a watch declaration in the watchDecls map
@@ -129,8 +192,18 @@ func (wc *WatchCollector) RegisterWatchDecl(decl *ds.WatchDecl) {
 		return
 	}
 
+	if len(wc.watchDecls) >= MaxWatchVals && !wc.evictLRUWatch_nolock() {
+		wc.limitStats.NumRejected++
+		wc.regErrors = append(wc.regErrors, ds.ErrWithContext{
+			Error: fmt.Sprintf("cannot register watch %q, max number of watches (%d) reached", decl.Name, MaxWatchVals),
+			Line:  decl.NewLine,
+		})
+		return
+	}
+
 	// Register the watch declaration
 	wc.watchDecls[decl.Name] = decl
+	wc.markUsed_nolock(decl.Name)
 	wc.newDecls = append(wc.newDecls, *decl)
 }
 
@@ -158,6 +231,9 @@ func (wc *WatchCollector) getSendFullAndReset() bool {
 
 	sendFull := wc.nextSendFull
 	wc.nextSendFull = false // Always set to false after getting the value
+	if sendFull {
+		clear(wc.patchChains)
+	}
 	return sendFull
 }
 
@@ -174,6 +250,13 @@ func (wc *WatchCollector) OnNewConnection() {
 	wc.SetNextSendFull(true)
 }
 
+// OnPacketDropped is called when a packet was dropped by the send queue (the next update must be a full update)
+func (wc *WatchCollector) OnPacketDropped(pkType string) {
+	if pkType == ds.PacketTypeWatch {
+		wc.SetNextSendFull(true)
+	}
+}
+
 // Enable is called when the collector should start collecting data
 func (wc *WatchCollector) Enable() {
 	cfg := wc.config.Get()
@@ -188,6 +271,11 @@ func (wc *WatchCollector) Disable() {
 	wc.executor.Disable()
 }
 
+// ApplySettings changes the collector settings at runtime (e.g. the poll interval)
+func (wc *WatchCollector) ApplySettings(settings map[string]any) error {
+	return collector.ApplyExecutorSettings(wc.executor, settings)
+}
+
 func (wc *WatchCollector) GetWatchNames() []string {
 	wc.lock.Lock()
 	defer wc.lock.Unlock()
@@ -216,6 +304,12 @@ func (wc *WatchCollector) PushWatchSample(name string, val any) {
 	}
 	wc.lock.Lock()
 	defer wc.lock.Unlock()
+	wc.markUsed_nolock(name)
+	if len(wc.pushSamples) >= MaxWatchVals {
+		// drop the oldest sample
+		wc.pushSamples = wc.pushSamples[1:]
+		wc.limitStats.NumDropSamples++
+	}
 	wc.pushSamples = append(wc.pushSamples, *sample)
 }
 
@@ -236,7 +330,8 @@ func (wc *WatchCollector) getLastSample(name string) (ds.WatchSample, bool) {
 
 // computeDeltaWatch compares current and last watch sample and returns a delta sample
 // For delta updates, we start with a full copy of current and clear fields that haven't changed
-func (wc *WatchCollector) computeDeltaWatch(name string, current ds.WatchSample) (ds.WatchSample, bool) {
+// (large json values that changed are sent as a patch if allowPatch is set, see makeWatchPatch)
+func (wc *WatchCollector) computeDeltaWatch(name string, current ds.WatchSample, allowPatch bool) (ds.WatchSample, bool) {
 	// For push values, we always include all fields and don't compute deltas
 	decl := wc.getWatchDecl(name)
 	if decl.WatchType == WatchType_Push {
@@ -272,8 +367,39 @@ func (wc *WatchCollector) computeDeltaWatch(name string, current ds.WatchSample)
 		deltaSample.Cap = 0
 		deltaSample.Len = 0
 		deltaSample.Fmt = ""
+		return deltaSample, true
+	}
+	if !allowPatch {
+		return deltaSample, false
+	}
+	if patch := wc.makeWatchPatch(name, current, lastSample); patch != nil {
+		deltaSample.Val = ""
+		deltaSample.Patch = patch
+	}
+	return deltaSample, false
+}
+
+// makeWatchPatch returns a patch for a large json value that changed (nil if the full value should be sent)
+func (wc *WatchCollector) makeWatchPatch(name string, current ds.WatchSample, lastSample ds.WatchSample) *ds.WatchPatch {
+	wc.lock.Lock()
+	defer wc.lock.Unlock()
+	if current.Fmt != WatchFormat_Json || lastSample.Fmt != WatchFormat_Json || current.Error != "" || lastSample.Error != "" ||
+		len(current.Val) < WatchPatchMinValSize || wc.patchChains[name] >= WatchPatchMaxChain {
+		delete(wc.patchChains, name)
+		return nil
+	}
+	ops, err := jsonpatch.Make(lastSample.Val, current.Val)
+	if err != nil {
+		delete(wc.patchChains, name)
+		return nil
+	}
+	patchBarr, err := json.Marshal(ops)
+	if err != nil || len(patchBarr) >= len(current.Val)/2 {
+		delete(wc.patchChains, name)
+		return nil
 	}
-	return deltaSample, sameValue
+	wc.patchChains[name]++
+	return &ds.WatchPatch{Ops: ops, Crc: crc32.ChecksumIEEE([]byte(current.Val))}
 }
 
 func (wc *WatchCollector) getDeclList(delta bool) []ds.WatchDecl {
@@ -293,6 +419,17 @@ func (wc *WatchCollector) getDeclList(delta bool) []ds.WatchDecl {
 	return declList
 }
 
+// getLimitStats returns the limit stats, nil if no limit has been hit
+func (wc *WatchCollector) getLimitStats() *ds.WatchLimitStats {
+	wc.lock.Lock()
+	defer wc.lock.Unlock()
+	stats := wc.limitStats
+	if stats.NumEvicted == 0 && stats.NumRejected == 0 && stats.NumDropSamples == 0 {
+		return nil
+	}
+	return &stats
+}
+
 func (wc *WatchCollector) getRegErrors(delta bool) []ds.ErrWithContext {
 	wc.lock.Lock()
 	defer wc.lock.Unlock()
@@ -315,7 +452,9 @@ func (wc *WatchCollector) CollectWatches() {
 		return
 	}
 	var samples []ds.WatchSample
-	sendFull := wc.getSendFullAndReset()
+	// servers that don't support delta updates get a full update every time (and older ones don't parse patches)
+	deltaVersion := ctl.GetProtocolFeatures().DeltaVersion
+	sendFull := wc.getSendFullAndReset() || deltaVersion < ds.DeltaVersionBase
 	watchNames := wc.GetWatchNames()
 	for _, name := range watchNames {
 		watchDecl := wc.getWatchDecl(name)
@@ -337,7 +476,7 @@ func (wc *WatchCollector) CollectWatches() {
 		if sendFull {
 			continue
 		}
-		deltaWatch, sameValue := wc.computeDeltaWatch(watch.Name, watch)
+		deltaWatch, sameValue := wc.computeDeltaWatch(watch.Name, watch, deltaVersion >= ds.DeltaVersionWatchPatch)
 		if sameValue {
 			numSameValue++
 		}
@@ -354,6 +493,7 @@ func (wc *WatchCollector) CollectWatches() {
 		Decls:     wc.getDeclList(!sendFull),
 		Watches:   samples,
 		RegErrors: wc.getRegErrors(!sendFull),
+		Limits:    wc.getLimitStats(),
 	}
 
 	// Send the watch packet
@@ -465,6 +605,48 @@ func (wc *WatchCollector) collectWatch(decl *ds.WatchDecl) *ds.WatchSample {
 		}
 		rval = results[0]
 
+	case WatchType_Context:
+		pollObj, ok := decl.PollObj.(*ContextPollObj)
+		if !ok {
+			return watchSampleErr(decl, startTime, "invalid context watch")
+		}
+		rval = reflect.ValueOf(pollObj.GetState(time.Now()))
+
+	case WatchType_Ticker:
+		pollObj, ok := decl.PollObj.(*TickerPollObj)
+		if !ok {
+			return watchSampleErr(decl, startTime, "invalid ticker watch")
+		}
+		rval = reflect.ValueOf(pollObj.GetState(time.Now()))
+
+	case WatchType_Group:
+		pollObj, ok := decl.PollObj.(*GroupPollObj)
+		if !ok {
+			return watchSampleErr(decl, startTime, "invalid group watch")
+		}
+		rval = reflect.ValueOf(pollObj.GetState(time.Now()))
+
+	case WatchType_TypeCount:
+		pollObj, ok := decl.PollObj.(*TypeCountPollObj)
+		if !ok {
+			return watchSampleErr(decl, startTime, "invalid type count watch")
+		}
+		rval = reflect.ValueOf(pollObj.GetState())
+
+	case WatchType_Pool:
+		pollObj, ok := decl.PollObj.(*PoolPollObj)
+		if !ok {
+			return watchSampleErr(decl, startTime, "invalid pool watch")
+		}
+		rval = reflect.ValueOf(pollObj.GetState())
+
+	case WatchType_Counter:
+		pollObj, ok := decl.PollObj.(*CounterPollObj)
+		if !ok {
+			return watchSampleErr(decl, startTime, "invalid counter watch")
+		}
+		rval = reflect.ValueOf(pollObj.GetValue())
+
 	case WatchType_Push:
 		return nil
 
@@ -484,6 +666,8 @@ func (wc *WatchCollector) newWatchSample(decl *ds.WatchDecl, rval reflect.Value,
 		if rtnVal == nil {
 			return
 		}
+		rtnVal.Val = redact.RedactString(rtnVal.Val)
+		rtnVal.Error = redact.RedactString(rtnVal.Error)
 		// strip the value if it exceeds the maximum size
 		if len(rtnVal.Val) > MaxWatchValSize {
 			rtnVal.Val = ""
@@ -627,8 +811,14 @@ func (wc *WatchCollector) setLastWatchSamples(watches map[string]ds.WatchSample)
 	for name := range wc.lastWatchSamples {
 		if _, found := watches[name]; !found {
 			delete(wc.lastWatchSamples, name)
+			delete(wc.patchChains, name)
 		}
 	}
+
+	// Polled watches count as used
+	for name := range watches {
+		wc.markUsed_nolock(name)
+	}
 }
 
 // getWatchCounts returns the current watch counts with proper locking
@@ -659,12 +849,14 @@ func (wc *WatchCollector) GetStatus() ds.CollectorStatus {
 		status.Info = "Disabled in configuration"
 	} else {
 		totalWatches, pollingWatches, totalErrors := wc.getWatchCounts()
-		status.Info = fmt.Sprintf("Monitoring %d watches (%d polling)", totalWatches, pollingWatches)
+		status.Info = fmt.Sprintf("Monitoring %d watches (%d polling, max %d)", totalWatches, pollingWatches, MaxWatchVals)
 		status.CollectDuration = wc.executor.GetLastExecDuration()
+		status.Overhead = wc.executor.GetOverhead()
 
 		if totalErrors > 0 {
 			status.Warnings = append(status.Warnings, fmt.Sprintf("%d registration errors", totalErrors))
 		}
+		status.Warnings = append(status.Warnings, wc.getLimitStats().Warnings()...)
 
 		if lastErr := wc.executor.GetLastErr(); lastErr != nil {
 			status.Errors = append(status.Errors, lastErr.Error())
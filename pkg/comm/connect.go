@@ -4,6 +4,7 @@
 package comm
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -20,9 +21,12 @@ import (
 const ConnectDialTimeout = 500 * time.Millisecond
 
 type ConnectAddr struct {
-	ConnType string
-	Network  string
-	DialAddr string
+	ConnType  string
+	Network   string
+	DialAddr  string
+	Remote    bool        // true for a remote monitor (see config.RemoteConfig)
+	TlsConfig *tls.Config // non-nil to dial with TLS
+	ConfigErr error       // set if the address could not be configured (e.g. bad TLS files)
 }
 
 func (ca ConnectAddr) IsTcp() bool {
@@ -30,8 +34,8 @@ func (ca ConnectAddr) IsTcp() bool {
 }
 
 func (ca ConnectAddr) IsLocal() bool {
-	// Domain sockets are always local
-	if ca.Network == "unix" {
+	// Domain sockets and named pipes are always local
+	if ca.Network == "unix" || ca.Network == "pipe" {
 		return true
 	}
 
@@ -91,6 +95,11 @@ func probeDockerHost() bool {
 
 // MakeConnectAddrs builds the list of connection addresses based on config and environment variables
 func MakeConnectAddrs(cfg *config.Config) []ConnectAddr {
+	// A remote monitor replaces all of the local addresses
+	if remoteAddr := GetRemoteAddr(cfg); remoteAddr != "" {
+		return []ConnectAddr{makeRemoteConnectAddr(cfg, remoteAddr)}
+	}
+
 	// Check for domain socket override from environment variable
 	domainSocketPath := cfg.DomainSocketPath
 	if envPath := os.Getenv(config.DomainSocketEnvName); envPath != "" {
@@ -103,8 +112,40 @@ func MakeConnectAddrs(cfg *config.Config) []ConnectAddr {
 		tcpAddr = envAddr
 	}
 
+	// Check for named pipe override from environment variable
+	pipeName := cfg.PipeName
+	if envPipe := os.Getenv(config.PipeNameEnvName); envPipe != "" {
+		pipeName = envPipe
+	}
+
+	transport := cfg.Transport
+	if envTransport := os.Getenv(config.TransportEnvName); envTransport != "" {
+		transport = envTransport
+	}
+	switch transport {
+	case "", config.TransportAuto:
+		transport = config.TransportAuto
+	case config.TransportTcp, config.TransportUnix:
+	case config.TransportPipe:
+		if !PipeSupported {
+			return []ConnectAddr{{ConnType: "named pipe", Network: "pipe", DialAddr: pipeName, ConfigErr: fmt.Errorf("transport %q is only supported on windows", transport)}}
+		}
+	default:
+		return []ConnectAddr{{ConnType: "unknown", DialAddr: transport, ConfigErr: fmt.Errorf("invalid transport %q (must be auto, tcp, unix, or pipe)", transport)}}
+	}
+	useTransport := func(t string) bool {
+		return transport == config.TransportAuto || transport == t
+	}
+
 	var connectAddrs []ConnectAddr
-	if domainSocketPath != "" && domainSocketPath != "-" {
+	if PipeSupported && useTransport(config.TransportPipe) && pipeName != "" && pipeName != "-" {
+		connectAddrs = append(connectAddrs, ConnectAddr{
+			ConnType: "named pipe",
+			Network:  "pipe",
+			DialAddr: pipeName,
+		})
+	}
+	if useTransport(config.TransportUnix) && domainSocketPath != "" && domainSocketPath != "-" {
 		dialAddr := utilfn.ExpandHomeDir(domainSocketPath)
 		connectAddrs = append(connectAddrs, ConnectAddr{
 			ConnType: "domain socket",
@@ -112,7 +153,7 @@ func MakeConnectAddrs(cfg *config.Config) []ConnectAddr {
 			DialAddr: dialAddr,
 		})
 	}
-	if tcpAddr != "" && tcpAddr != "-" {
+	if useTransport(config.TransportTcp) && tcpAddr != "" && tcpAddr != "-" {
 		connectAddrs = append(connectAddrs, ConnectAddr{
 			ConnType: "TCP server",
 			Network:  "tcp",
@@ -127,7 +168,7 @@ func MakeConnectAddrs(cfg *config.Config) []ConnectAddr {
 	}
 
 	// Add Docker host probe if enabled and running in Docker environment
-	if !disableDockerProbe && utilfn.InDockerEnv() {
+	if useTransport(config.TransportTcp) && !disableDockerProbe && utilfn.InDockerEnv() {
 		if probeDockerHost() {
 			port := config.GetMonitorPort()
 			dockerAddr := "host.docker.internal:" + strconv.Itoa(port)
@@ -152,7 +193,20 @@ func tryConnect(connectAddr ConnectAddr) *ConnWrap {
 		}
 	}
 
-	conn, err := net.DialTimeout(connectAddr.Network, connectAddr.DialAddr, ConnectDialTimeout)
+	dialTimeout := ConnectDialTimeout
+	if connectAddr.Remote {
+		dialTimeout = RemoteDialTimeout
+	}
+	var conn net.Conn
+	var err error
+	if connectAddr.Network == "pipe" {
+		conn, err = DialPipe(connectAddr.DialAddr, dialTimeout)
+	} else if connectAddr.TlsConfig != nil {
+		dialer := &net.Dialer{Timeout: dialTimeout}
+		conn, err = tls.DialWithDialer(dialer, connectAddr.Network, connectAddr.DialAddr, connectAddr.TlsConfig)
+	} else {
+		conn, err = net.DialTimeout(connectAddr.Network, connectAddr.DialAddr, dialTimeout)
+	}
 	if err != nil {
 		return nil
 	}
@@ -175,6 +229,9 @@ func Connect(mode string, submode string, appRunId string, cfg *config.Config) (
 
 	// log.Printf("Connecting to Outrig server with mode: %s, submode: %s, appRunId: %s, connectAddrs: %v\n", mode, submode, appRunId, connectAddrsToStrings(connectAddrs))
 	for _, connectAddr := range connectAddrs {
+		if connectAddr.ConfigErr != nil {
+			return nil, fmt.Errorf("cannot connect to %s: %w", connectAddr.DialAddr, connectAddr.ConfigErr), nil
+		}
 		triedConnect = true
 		attemptedAddrs = append(attemptedAddrs, connectAddr.DialAddr)
 
@@ -208,6 +265,9 @@ func GetServerVersion(cfg *config.Config) (string, int, string, error) {
 	connectAddrs := MakeConnectAddrs(cfg)
 
 	for _, connectAddr := range connectAddrs {
+		if connectAddr.ConfigErr != nil {
+			continue
+		}
 		connWrap := tryConnect(connectAddr)
 		if connWrap == nil {
 			continue
@@ -12,9 +12,11 @@ import (
 	"net"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/utilfn"
 )
 
@@ -24,9 +26,25 @@ const (
 	ConnectionModeLog    = "log"
 )
 
+// PacketSubmodeWrapper is the packet connection of the outrig run/exec process wrapper, it only reports the
+// app's exit (the SDK's packet connection, with no submode, is the one used to send commands to the app)
+const PacketSubmodeWrapper = "wrapper"
+
 const MinClientVersion = "v0.10.0"
 const MinServerVersion = "v0.10.0"
 
+// legacyPacketTypes are the packet types a monitor that doesn't negotiate features can parse
+var legacyPacketTypes = []string{
+	ds.PacketTypeLog,
+	ds.PacketTypeMultiLog,
+	ds.PacketTypeAppInfo,
+	ds.PacketTypeGoroutine,
+	ds.PacketTypeAppDone,
+	ds.PacketTypeWatch,
+	ds.PacketTypeRuntimeStats,
+	ds.PacketTypeCollectorStatus,
+}
+
 type ServerHandshakePacket struct {
 	OutrigVersion  string `json:"outrigversion"`
 	ServerHttpPort int    `json:"serverhttpport,omitempty"`
@@ -38,12 +56,30 @@ type ClientHandshakePacket struct {
 	Mode      string `json:"mode"`
 	Submode   string `json:"submode,omitempty"`
 	AppRunID  string `json:"apprunid,omitempty"`
+
+	Features  *ds.ProtocolFeatures `json:"features,omitempty"`  // the features the SDK supports (not sent by older SDKs)
+	ClockSync *ClockSync           `json:"clocksync,omitempty"` // not sent by older SDKs
+}
+
+// ClockSync holds the client's clock readings during the handshake (unix microseconds), the server uses them
+// to estimate how far the client's clock is off (see EstimateClockOffset)
+type ClockSync struct {
+	RecvTs int64 `json:"recvts"` // when the client read the ServerHandshakePacket
+	SendTs int64 `json:"sendts"` // when the client sent the ClientHandshakePacket
+}
+
+// ClockOffset is the NTP-style estimate of the client's clock offset from the server's clock
+type ClockOffset struct {
+	OffsetUs int64 // client clock - server clock
+	DelayUs  int64 // round trip time of the handshake, the estimate is accurate to +/- DelayUs/2
 }
 
 type ServerHandshakeResponse struct {
 	Success        bool   `json:"success"`
 	Error          string `json:"error,omitempty"`
 	ServerHttpPort int    `json:"serverhttpport,omitempty"`
+
+	Features *ds.ProtocolFeatures `json:"features,omitempty"` // the negotiated features (not sent by older monitors)
 }
 
 // Regexp for validating log source paths
@@ -55,6 +91,7 @@ type ConnWrap struct {
 	Reader         *bufio.Reader
 	PeerName       string
 	ServerResponse *ServerHandshakeResponse // set on client side connections
+	ClockOffset    *ClockOffset             // set on server side connections (nil if the client didn't send a ClockSync)
 }
 
 // MakeConnWrap creates a new ConnWrap from a net.Conn.
@@ -66,6 +103,56 @@ func MakeConnWrap(conn net.Conn, peerName string) *ConnWrap {
 	}
 }
 
+// GetProtocolFeatures returns the features negotiated in the handshake (on client side connections).
+// Monitors that don't negotiate features get the legacy feature set.
+func (cw *ConnWrap) GetProtocolFeatures() ds.ProtocolFeatures {
+	if cw.ServerResponse == nil || cw.ServerResponse.Features == nil {
+		return LegacyProtocolFeatures()
+	}
+	return *cw.ServerResponse.Features
+}
+
+// LocalProtocolFeatures returns the protocol features of this build
+func LocalProtocolFeatures() ds.ProtocolFeatures {
+	return ds.ProtocolFeatures{
+		PacketTypes:  ds.SDKPacketTypes,
+		DeltaVersion: ds.DeltaVersion,
+	}
+}
+
+// LegacyProtocolFeatures returns the protocol features of a monitor that doesn't negotiate features
+func LegacyProtocolFeatures() ds.ProtocolFeatures {
+	return ds.ProtocolFeatures{
+		PacketTypes:  legacyPacketTypes,
+		DeltaVersion: ds.DeltaVersionBase,
+	}
+}
+
+// NegotiateProtocolFeatures returns the features supported by both the SDK and the server
+func NegotiateProtocolFeatures(sdkFeatures ds.ProtocolFeatures, serverFeatures ds.ProtocolFeatures) ds.ProtocolFeatures {
+	rtn := ds.ProtocolFeatures{
+		PacketTypes:  []string{},
+		DeltaVersion: min(sdkFeatures.DeltaVersion, serverFeatures.DeltaVersion),
+	}
+	for _, pkType := range sdkFeatures.PacketTypes {
+		if serverFeatures.SupportsPacketType(pkType) {
+			rtn.PacketTypes = append(rtn.PacketTypes, pkType)
+		}
+	}
+	return rtn
+}
+
+// EstimateClockOffset estimates the client's clock offset from the server send/receive times of the
+// handshake (serverSendTs: ServerHandshakePacket sent, serverRecvTs: ClientHandshakePacket received, unix
+// microseconds) and the client's readings. The estimate assumes the network delay is the same both ways.
+func EstimateClockOffset(serverSendTs int64, serverRecvTs int64, clockSync ClockSync) ClockOffset {
+	delay := (serverRecvTs - serverSendTs) - (clockSync.SendTs - clockSync.RecvTs)
+	return ClockOffset{
+		OffsetUs: ((clockSync.RecvTs - serverSendTs) + (clockSync.SendTs - serverRecvTs)) / 2,
+		DelayUs:  max(delay, 0),
+	}
+}
+
 // ReadLine reads a line from the connection.
 func (cw *ConnWrap) ReadLine() (string, error) {
 	return cw.Reader.ReadString('\n')
@@ -102,6 +189,7 @@ func (cw *ConnWrap) ClientHandshake(modeName string, submode string, appRunId st
 	if err != nil {
 		return nil, fmt.Errorf("failed to read server handshake packet: %v", err)
 	}
+	recvTs := time.Now().UnixMicro()
 
 	packetLine = strings.TrimSpace(packetLine)
 
@@ -123,12 +211,16 @@ func (cw *ConnWrap) ClientHandshake(modeName string, submode string, appRunId st
 	}
 
 	// Create the client handshake packet
+	sdkFeatures := LocalProtocolFeatures()
 	clientPacket := ClientHandshakePacket{
 		OutrigSDK: config.OutrigSDKVersion,
 		Mode:      modeName,
 		Submode:   submode,
 		AppRunID:  appRunId,
+		Features:  &sdkFeatures,
+		ClockSync: &ClockSync{RecvTs: recvTs},
 	}
+	clientPacket.ClockSync.SendTs = time.Now().UnixMicro()
 
 	// Convert to JSON
 	jsonData, err := json.Marshal(clientPacket)
@@ -176,10 +268,11 @@ func sendErrorResponse(cw *ConnWrap, err error) error {
 }
 
 // Helper function to send success response
-func sendSuccessResponse(cw *ConnWrap, webServerPort int) error {
+func sendSuccessResponse(cw *ConnWrap, webServerPort int, features *ds.ProtocolFeatures) error {
 	response := ServerHandshakeResponse{
 		Success:        true,
 		ServerHttpPort: webServerPort,
+		Features:       features,
 	}
 	jsonData, err := json.Marshal(response)
 	if err != nil {
@@ -232,6 +325,7 @@ func (cw *ConnWrap) ServerHandshake(webServerPort int, isTcp bool) (*ClientHands
 		return nil, fmt.Errorf("failed to marshal server handshake packet: %v", err)
 	}
 
+	serverSendTs := time.Now().UnixMicro()
 	if err := cw.WriteLine(string(jsonData)); err != nil {
 		return nil, fmt.Errorf("failed to send server handshake packet: %v", err)
 	}
@@ -246,6 +340,7 @@ func (cw *ConnWrap) ServerHandshake(webServerPort int, isTcp bool) (*ClientHands
 		sendErrorResponse(cw, readErr)
 		return nil, readErr
 	}
+	serverRecvTs := time.Now().UnixMicro()
 
 	packetLine = strings.TrimSpace(packetLine)
 
@@ -294,8 +389,20 @@ func (cw *ConnWrap) ServerHandshake(webServerPort int, isTcp bool) (*ClientHands
 		}
 	}
 
+	// Negotiate the protocol features (older SDKs don't send theirs, they only use the legacy features)
+	var features *ds.ProtocolFeatures
+	if packet.Features != nil {
+		negotiated := NegotiateProtocolFeatures(*packet.Features, LocalProtocolFeatures())
+		features = &negotiated
+	}
+
+	if packet.ClockSync != nil {
+		clockOffset := EstimateClockOffset(serverSendTs, serverRecvTs, *packet.ClockSync)
+		cw.ClockOffset = &clockOffset
+	}
+
 	// Send success response
-	if err := sendSuccessResponse(cw, webServerPort); err != nil {
+	if err := sendSuccessResponse(cw, webServerPort, features); err != nil {
 		return nil, fmt.Errorf("failed to send success response: %v", err)
 	}
 
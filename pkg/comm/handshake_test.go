@@ -0,0 +1,31 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package comm
+
+import "testing"
+
+func TestEstimateClockOffset(t *testing.T) {
+	tests := []struct {
+		name         string
+		serverSendTs int64
+		serverRecvTs int64
+		clockSync    ClockSync
+		expect       ClockOffset
+	}{
+		{"same clock", 1000, 1300, ClockSync{RecvTs: 1100, SendTs: 1200}, ClockOffset{OffsetUs: 0, DelayUs: 200}},
+		{"client ahead", 1000, 1300, ClockSync{RecvTs: 51100, SendTs: 51200}, ClockOffset{OffsetUs: 50000, DelayUs: 200}},
+		{"client behind", 1000, 1300, ClockSync{RecvTs: -48900, SendTs: -48800}, ClockOffset{OffsetUs: -50000, DelayUs: 200}},
+		{"asymmetric delay", 1000, 1200, ClockSync{RecvTs: 1150, SendTs: 1150}, ClockOffset{OffsetUs: 50, DelayUs: 200}},
+		{"client time excludes the processing", 1000, 2000, ClockSync{RecvTs: 1050, SendTs: 1950}, ClockOffset{OffsetUs: 0, DelayUs: 100}},
+		{"inconsistent readings", 1000, 1100, ClockSync{RecvTs: 1000, SendTs: 1500}, ClockOffset{OffsetUs: 200, DelayUs: 0}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := EstimateClockOffset(tc.serverSendTs, tc.serverRecvTs, tc.clockSync)
+			if got != tc.expect {
+				t.Errorf("got %+v, want %+v", got, tc.expect)
+			}
+		})
+	}
+}
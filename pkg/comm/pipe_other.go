@@ -0,0 +1,27 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package comm
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// PipeSupported is true on platforms with named pipe support (Windows)
+const PipeSupported = false
+
+var errPipeNotSupported = errors.New("named pipes are only supported on windows")
+
+// DialPipe is only supported on Windows
+func DialPipe(name string, timeout time.Duration) (net.Conn, error) {
+	return nil, errPipeNotSupported
+}
+
+// ListenPipe is only supported on Windows
+func ListenPipe(name string) (net.Listener, error) {
+	return nil, errPipeNotSupported
+}
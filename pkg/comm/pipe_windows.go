@@ -0,0 +1,296 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build windows
+
+package comm
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// PipeSupported is true on platforms with named pipe support (Windows)
+const PipeSupported = true
+
+const pipeBufferSize = 64 * 1024
+
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
+
+// pipeConn is a net.Conn over a named pipe handle opened for overlapped I/O
+// (so a pending read does not block writes on the same handle)
+type pipeConn struct {
+	handle        windows.Handle
+	name          string
+	closed        atomic.Bool
+	closeOnce     sync.Once
+	readDeadline  atomic.Int64 // unix nanos, 0 for no deadline
+	writeDeadline atomic.Int64
+}
+
+// overlappedIO runs a ReadFile/WriteFile call and waits for it to finish (or for the deadline)
+func (pc *pipeConn) overlappedIO(deadline int64, ioFn func(done *uint32, overlapped *windows.Overlapped) error) (int, error) {
+	if pc.closed.Load() {
+		return 0, net.ErrClosed
+	}
+	event, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer windows.CloseHandle(event)
+	overlapped := &windows.Overlapped{HEvent: event}
+	var done uint32
+	err = ioFn(&done, overlapped)
+	if err == nil {
+		return int(done), nil
+	}
+	if err != windows.ERROR_IO_PENDING {
+		return int(done), err
+	}
+	timedOut := false
+	if deadline != 0 {
+		waitMs := max(time.Until(time.Unix(0, deadline)).Milliseconds(), 0)
+		if event, _ := windows.WaitForSingleObject(event, uint32(waitMs)); event == uint32(windows.WAIT_TIMEOUT) {
+			windows.CancelIoEx(pc.handle, overlapped)
+			timedOut = true
+		}
+	}
+	err = windows.GetOverlappedResult(pc.handle, overlapped, &done, true)
+	if err == windows.ERROR_OPERATION_ABORTED && timedOut {
+		return int(done), os.ErrDeadlineExceeded
+	}
+	return int(done), err
+}
+
+func (pc *pipeConn) mapErr(err error, isRead bool) error {
+	if err == nil {
+		return nil
+	}
+	if pc.closed.Load() {
+		return net.ErrClosed
+	}
+	if errors.Is(err, windows.ERROR_BROKEN_PIPE) || errors.Is(err, windows.ERROR_NO_DATA) || errors.Is(err, windows.ERROR_PIPE_NOT_CONNECTED) {
+		if isRead {
+			return io.EOF
+		}
+		return io.ErrClosedPipe
+	}
+	return err
+}
+
+func (pc *pipeConn) Read(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	n, err := pc.overlappedIO(pc.readDeadline.Load(), func(done *uint32, overlapped *windows.Overlapped) error {
+		return windows.ReadFile(pc.handle, b, done, overlapped)
+	})
+	return n, pc.mapErr(err, true)
+}
+
+func (pc *pipeConn) Write(b []byte) (int, error) {
+	written := 0
+	for written < len(b) {
+		n, err := pc.overlappedIO(pc.writeDeadline.Load(), func(done *uint32, overlapped *windows.Overlapped) error {
+			return windows.WriteFile(pc.handle, b[written:], done, overlapped)
+		})
+		written += n
+		if err != nil {
+			return written, pc.mapErr(err, false)
+		}
+	}
+	return written, nil
+}
+
+func (pc *pipeConn) Close() error {
+	var err error
+	pc.closeOnce.Do(func() {
+		pc.closed.Store(true)
+		// abort any pending reads/writes before closing the handle
+		windows.CancelIoEx(pc.handle, nil)
+		err = windows.CloseHandle(pc.handle)
+	})
+	return err
+}
+
+func (pc *pipeConn) LocalAddr() net.Addr  { return pipeAddr(pc.name) }
+func (pc *pipeConn) RemoteAddr() net.Addr { return pipeAddr(pc.name) }
+
+func deadlineToNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func (pc *pipeConn) SetDeadline(t time.Time) error {
+	pc.readDeadline.Store(deadlineToNanos(t))
+	pc.writeDeadline.Store(deadlineToNanos(t))
+	return nil
+}
+
+func (pc *pipeConn) SetReadDeadline(t time.Time) error {
+	pc.readDeadline.Store(deadlineToNanos(t))
+	return nil
+}
+
+func (pc *pipeConn) SetWriteDeadline(t time.Time) error {
+	pc.writeDeadline.Store(deadlineToNanos(t))
+	return nil
+}
+
+// DialPipe connects to a named pipe (e.g. \\.\pipe\outrig), retrying while all pipe instances are busy
+func DialPipe(name string, timeout time.Duration) (net.Conn, error) {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		// SECURITY_IDENTIFICATION keeps the pipe server from impersonating the client
+		handle, err := windows.CreateFile(namePtr, windows.GENERIC_READ|windows.GENERIC_WRITE, 0, nil, windows.OPEN_EXISTING,
+			windows.FILE_FLAG_OVERLAPPED|windows.SECURITY_SQOS_PRESENT|windows.SECURITY_IDENTIFICATION, 0)
+		if err == nil {
+			return &pipeConn{handle: handle, name: name}, nil
+		}
+		if err != windows.ERROR_PIPE_BUSY || time.Now().After(deadline) {
+			return nil, &net.OpError{Op: "dial", Net: "pipe", Addr: pipeAddr(name), Err: err}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// pipeListener accepts connections on a named pipe. There is always one pipe instance
+// waiting for the next client so clients never see the pipe missing between accepts.
+type pipeListener struct {
+	name      string
+	namePtr   *uint16
+	lock      sync.Mutex
+	pending   windows.Handle // instance waiting for the next client (0 if none)
+	accepting bool
+	closed    bool
+}
+
+func (l *pipeListener) createInstance(first bool) (windows.Handle, error) {
+	flags := uint32(windows.PIPE_ACCESS_DUPLEX | windows.FILE_FLAG_OVERLAPPED)
+	if first {
+		// fail if another process (e.g. a second monitor) already owns the pipe name
+		flags |= windows.FILE_FLAG_FIRST_PIPE_INSTANCE
+	}
+	pipeMode := uint32(windows.PIPE_TYPE_BYTE | windows.PIPE_READMODE_BYTE | windows.PIPE_WAIT | windows.PIPE_REJECT_REMOTE_CLIENTS)
+	return windows.CreateNamedPipe(l.namePtr, flags, pipeMode, windows.PIPE_UNLIMITED_INSTANCES, pipeBufferSize, pipeBufferSize, 0, nil)
+}
+
+// ListenPipe creates a named pipe (with the default security descriptor, so only the
+// current user and administrators can connect) and returns a listener for it
+func ListenPipe(name string) (net.Listener, error) {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	l := &pipeListener{name: name, namePtr: namePtr}
+	handle, err := l.createInstance(true)
+	if err != nil {
+		return nil, &net.OpError{Op: "listen", Net: "pipe", Addr: pipeAddr(name), Err: err}
+	}
+	l.pending = handle
+	return l, nil
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	l.lock.Lock()
+	if l.closed {
+		l.lock.Unlock()
+		return nil, net.ErrClosed
+	}
+	if l.accepting {
+		l.lock.Unlock()
+		return nil, errors.New("concurrent Accept calls on a pipe listener")
+	}
+	handle := l.pending
+	if handle == 0 {
+		var err error
+		handle, err = l.createInstance(false)
+		if err != nil {
+			l.lock.Unlock()
+			return nil, err
+		}
+		l.pending = handle
+	}
+	l.accepting = true
+	l.lock.Unlock()
+
+	err := connectPipe(handle)
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.accepting = false
+	l.pending = 0
+	if l.closed {
+		windows.CloseHandle(handle)
+		return nil, net.ErrClosed
+	}
+	if err != nil {
+		windows.CloseHandle(handle)
+		return nil, err
+	}
+	// create the instance for the next client right away
+	if next, err := l.createInstance(false); err == nil {
+		l.pending = next
+	}
+	return &pipeConn{handle: handle, name: l.name}, nil
+}
+
+// connectPipe waits for a client to connect to a pipe instance
+func connectPipe(handle windows.Handle) error {
+	event, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(event)
+	overlapped := &windows.Overlapped{HEvent: event}
+	err = windows.ConnectNamedPipe(handle, overlapped)
+	switch err {
+	case nil, windows.ERROR_PIPE_CONNECTED:
+		return nil
+	case windows.ERROR_IO_PENDING:
+		var done uint32
+		return windows.GetOverlappedResult(handle, overlapped, &done, true)
+	default:
+		return err
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	if l.pending == 0 {
+		return nil
+	}
+	if l.accepting {
+		// Accept closes the handle once ConnectNamedPipe is aborted
+		windows.CancelIoEx(l.pending, nil)
+		return nil
+	}
+	windows.CloseHandle(l.pending)
+	l.pending = 0
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr(l.name)
+}
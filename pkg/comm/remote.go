@@ -0,0 +1,105 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package comm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+)
+
+// remote monitors are usually across a network, so allow more time than for local connections
+const RemoteDialTimeout = 5 * time.Second
+
+// GetRemoteAddr returns the remote monitor address (env var overrides the config), "" if not in remote mode
+func GetRemoteAddr(cfg *config.Config) string {
+	if envAddr := os.Getenv(config.RemoteAddrEnvName); envAddr != "" {
+		return envAddr
+	}
+	if cfg == nil {
+		return ""
+	}
+	return cfg.Remote.Addr
+}
+
+// IsRemoteMode returns true if the SDK should connect to a remote monitor
+func IsRemoteMode(cfg *config.Config) bool {
+	return GetRemoteAddr(cfg) != ""
+}
+
+// makeRemoteConnectAddr builds the ConnectAddr for a remote monitor.
+// TLS configuration errors are returned in ConfigErr (they are permanent, retrying won't fix them).
+func makeRemoteConnectAddr(cfg *config.Config, remoteAddr string) ConnectAddr {
+	connectAddr := ConnectAddr{
+		ConnType: "remote TCP server",
+		Network:  "tcp",
+		DialAddr: remoteAddr,
+		Remote:   true,
+	}
+	if !cfg.Remote.TLS {
+		return connectAddr
+	}
+	connectAddr.ConnType = "remote TLS server"
+	tlsConfig, err := MakeClientTlsConfig(&cfg.Remote, remoteAddr)
+	if err != nil {
+		connectAddr.ConfigErr = err
+		return connectAddr
+	}
+	connectAddr.TlsConfig = tlsConfig
+	return connectAddr
+}
+
+// MakeClientTlsConfig creates the TLS config used to connect to a remote monitor
+func MakeClientTlsConfig(rcfg *config.RemoteConfig, remoteAddr string) (*tls.Config, error) {
+	serverName := rcfg.ServerName
+	if serverName == "" {
+		host, _, err := net.SplitHostPort(remoteAddr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote address %q: %w", remoteAddr, err)
+		}
+		serverName = host
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         serverName,
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: rcfg.InsecureSkipVerify,
+	}
+	if rcfg.CAFile != "" {
+		pool, err := LoadCertPool(rcfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if rcfg.CertFile != "" || rcfg.KeyFile != "" {
+		if rcfg.CertFile == "" || rcfg.KeyFile == "" {
+			return nil, fmt.Errorf("remote mTLS requires both certfile and keyfile")
+		}
+		cert, err := tls.LoadX509KeyPair(utilfn.ExpandHomeDir(rcfg.CertFile), utilfn.ExpandHomeDir(rcfg.KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// LoadCertPool loads a PEM file with one or more CA certificates
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	caBytes, err := os.ReadFile(utilfn.ExpandHomeDir(caFile))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBytes) {
+		return nil, fmt.Errorf("no valid certificates found in CA file %s", caFile)
+	}
+	return pool, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package comm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/config"
+)
+
+// writeTestCAFile writes a self-signed CA certificate (PEM) to a temp file
+func writeTestCAFile(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "outrig test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return caFile
+}
+
+func TestGetRemoteAddr(t *testing.T) {
+	t.Setenv(config.RemoteAddrEnvName, "")
+	cfg := &config.Config{}
+	if IsRemoteMode(cfg) || IsRemoteMode(nil) {
+		t.Errorf("got remote mode without an address")
+	}
+	cfg.Remote.Addr = "monitor:5005"
+	if got := GetRemoteAddr(cfg); got != "monitor:5005" {
+		t.Errorf("got %q, want the config address", got)
+	}
+	t.Setenv(config.RemoteAddrEnvName, "other:5005")
+	if got := GetRemoteAddr(cfg); got != "other:5005" || !IsRemoteMode(nil) {
+		t.Errorf("got %q, want the env var to override the config", got)
+	}
+}
+
+func TestMakeConnectAddrsRemote(t *testing.T) {
+	t.Setenv(config.RemoteAddrEnvName, "")
+	cfg := &config.Config{Remote: config.RemoteConfig{Addr: "monitor.example.com:5005"}}
+	addrs := MakeConnectAddrs(cfg)
+	if len(addrs) != 1 || addrs[0].DialAddr != "monitor.example.com:5005" || !addrs[0].Remote || addrs[0].TlsConfig != nil {
+		t.Fatalf("got %+v, want only the remote TCP address", addrs)
+	}
+
+	cfg.Remote.TLS = true
+	addr := MakeConnectAddrs(cfg)[0]
+	if addr.ConfigErr != nil || addr.TlsConfig == nil || addr.TlsConfig.ServerName != "monitor.example.com" {
+		t.Errorf("got %+v, want TLS with the host as the server name", addr)
+	}
+
+	cfg.Remote.CAFile = filepath.Join(t.TempDir(), "missing.pem")
+	if addr := MakeConnectAddrs(cfg)[0]; addr.ConfigErr == nil {
+		t.Errorf("got no config error for a missing CA file")
+	}
+}
+
+func TestMakeClientTlsConfig(t *testing.T) {
+	caFile := writeTestCAFile(t)
+	notCertFile := filepath.Join(t.TempDir(), "notcert.pem")
+	if err := os.WriteFile(notCertFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig, err := MakeClientTlsConfig(&config.RemoteConfig{CAFile: caFile, ServerName: "outrig.internal"}, "10.0.0.1:5005")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.ServerName != "outrig.internal" || tlsConfig.RootCAs == nil {
+		t.Errorf("got %+v, want the configured server name and CA", tlsConfig)
+	}
+
+	tests := []struct {
+		name      string
+		rcfg      config.RemoteConfig
+		addr      string
+		expectErr string
+	}{
+		{"invalid address", config.RemoteConfig{}, "monitor", "invalid remote address"},
+		{"cert without key", config.RemoteConfig{CertFile: "client.pem"}, "monitor:5005", "both certfile and keyfile"},
+		{"missing cert files", config.RemoteConfig{CertFile: "missing.pem", KeyFile: "missing.key"}, "monitor:5005", "cannot load client certificate"},
+		{"not a certificate", config.RemoteConfig{CAFile: notCertFile}, "monitor:5005", "no valid certificates"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := MakeClientTlsConfig(&tc.rcfg, tc.addr)
+			if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+				t.Errorf("got %v, want an error containing %q", err, tc.expectErr)
+			}
+		})
+	}
+}
@@ -20,6 +20,8 @@ const OutrigSDKVersion = "v0.10.0-alpha.0"
 const (
 	DomainSocketEnvName       = "OUTRIG_DOMAINSOCKET"
 	TcpAddrEnvName            = "OUTRIG_TCPADDR"
+	PipeNameEnvName           = "OUTRIG_PIPENAME"
+	TransportEnvName          = "OUTRIG_TRANSPORT"
 	DisabledEnvName           = "OUTRIG_DISABLED"
 	NoTelemetryEnvName        = "OUTRIG_NOTELEMETRY"
 	DevConfigEnvName          = "OUTRIG_DEVCONFIG"
@@ -33,6 +35,9 @@ const (
 	RunSDKReplacePathEnvName  = "OUTRIG_RUN_SDKREPLACEPATH"
 	FromRunModeEnvName        = "OUTRIG_FROMRUNMODE"
 	DaemonEnvName             = "OUTRIG_DAEMON"
+	RemoteAddrEnvName         = "OUTRIG_REMOTEADDR"
+	AppMetaEnvName            = "OUTRIG_APPMETA"    // "key=value,key2=value2" metadata for the app run
+	RedactJsonEnvName         = "OUTRIG_REDACTJSON" // RedactConfig JSON for the external log capture process
 )
 
 // Home directory paths
@@ -42,6 +47,20 @@ const (
 	DefaultDomainSocketName = "/outrig.sock"
 )
 
+// Windows named pipes used by the monitor (see Config.PipeName)
+const (
+	DefaultPipeName    = `\\.\pipe\outrig`
+	DefaultDevPipeName = `\\.\pipe\outrig-dev`
+)
+
+// SDK connection transports (see Config.Transport)
+const (
+	TransportAuto = "auto" // domain socket (named pipe on Windows), then TCP
+	TransportTcp  = "tcp"
+	TransportUnix = "unix"
+	TransportPipe = "pipe"
+)
+
 // Default ports for the server (should match serverbase)
 const (
 	ProdWebServerPort = 5005
@@ -74,6 +93,15 @@ type Config struct {
 	// If "-" => disable TCP connection. Domain socket will be tried first (except on Windows where domain sockets are not supported).)
 	TcpAddr string `json:"tcpaddr"`
 
+	// PipeName is the Windows named pipe of the Outrig server. If "" => use default.
+	// If "-" => disable the named pipe. Only used on Windows.
+	PipeName string `json:"pipename,omitempty"`
+
+	// Transport selects how the SDK connects to the local Outrig server: "auto" (or "") tries the domain socket
+	// (named pipe on Windows) and then TCP, "tcp", "unix", and "pipe" only use that transport.
+	// The domain socket and named pipe are only reachable by the current user, unlike the localhost TCP port.
+	Transport string `json:"transport,omitempty"`
+
 	// By default the SDK will probe host.docker.internal:5005 to see if the Outrig monitor is running on the host machine
 	// We do an initial DNS lookup at startup and only try this host/port if the DNS lookup succeeds.
 	// Setting this to true will disable the initial probe.
@@ -86,9 +114,15 @@ type Config struct {
 	// If true, try to synchronously connect to the server on Init
 	ConnectOnInit bool `json:"connectoninit"`
 
+	// Remote monitor configuration (connect to a central Outrig monitor over TCP/TLS)
+	Remote RemoteConfig `json:"remote,omitempty"`
+
 	// Collector configurations
 	Collectors CollectorConfig `json:"collectors"`
 
+	// Redaction of sensitive values in log lines and watch values (applied before they are sent)
+	Redact RedactConfig `json:"redact,omitempty"`
+
 	// RunMode configuration
 	RunMode RunModeConfig `json:"runmode,omitempty"`
 
@@ -96,6 +130,56 @@ type Config struct {
 	Exec ExecConfig `json:"exec,omitempty"`
 }
 
+// Defaults for remote monitor connections
+const (
+	DefaultRemoteBufferSize   = 1000
+	DefaultRemoteMaxBackoffMs = 30000
+)
+
+type RemoteConfig struct {
+	// Addr is the host:port of a remote Outrig monitor (started with --remote-listen).
+	// When set, the SDK only connects to this address, the local domain socket and TCP address are not tried.
+	Addr string `json:"addr,omitempty"`
+
+	// TLS enables TLS for the remote connection. The server certificate is verified against CAFile
+	// (or the system roots if CAFile is empty).
+	TLS bool `json:"tls,omitempty"`
+
+	// ServerName overrides the name used to verify the server certificate (defaults to the host in Addr)
+	ServerName string `json:"servername,omitempty"`
+
+	// CAFile is a PEM file with the CA certificate(s) used to verify the server
+	CAFile string `json:"cafile,omitempty"`
+
+	// CertFile and KeyFile are the PEM client certificate and key used for mutual TLS (optional)
+	CertFile string `json:"certfile,omitempty"`
+	KeyFile  string `json:"keyfile,omitempty"`
+
+	// InsecureSkipVerify disables server certificate verification (for testing only)
+	InsecureSkipVerify bool `json:"insecureskipverify,omitempty"`
+
+	// BufferSize is the number of packets buffered while disconnected from the remote monitor.
+	// The oldest packets are dropped once the buffer is full.
+	BufferSize int `json:"buffersize,omitempty"`
+
+	// MaxBackoffMs caps the exponential reconnect backoff (in milliseconds)
+	MaxBackoffMs int `json:"maxbackoffms,omitempty"`
+}
+
+// RedactConfig configures the redaction of sensitive values. Redaction is applied in the app (and in the log
+// capture process for stdout/stderr) before log lines and watch values are sent to the Outrig server.
+type RedactConfig struct {
+	// Patterns are regular expressions, the matching text is replaced
+	Patterns []string `json:"patterns,omitempty"`
+
+	// Fields are field names (case-insensitive, e.g. "password", "authorization") whose values are replaced
+	// in "name=value", "name: value", and JSON "name": "value" text
+	Fields []string `json:"fields,omitempty"`
+
+	// Replacement is the text that replaces the redacted values (defaults to "[REDACTED]")
+	Replacement string `json:"replacement,omitempty"`
+}
+
 type LogProcessorConfig struct {
 	// Enabled indicates whether the log processor is enabled
 	Enabled    bool `json:"enabled"`
@@ -114,9 +198,29 @@ type WatchConfig struct {
 	Enabled bool `json:"enabled"`
 }
 
+// DefaultInheritNameSeparator separates parent and child goroutine names (see GoRoutineConfig.InheritParentName)
+const DefaultInheritNameSeparator = "/"
+
 type GoRoutineConfig struct {
 	// Enabled indicates whether the goroutine collector is enabled
 	Enabled bool `json:"enabled"`
+
+	// MaxStackDepth caps the number of frames sent for each goroutine stack (0 means no limit).
+	// The "created by" frame is always kept.
+	MaxStackDepth int `json:"maxstackdepth,omitempty"`
+
+	// DropFramePrefixes removes stack frames whose function name starts with one of these prefixes
+	// (e.g. "runtime.", "github.com/some/vendored/pkg.") before the stacks are sent.
+	// Frames are dropped before MaxStackDepth is applied.
+	DropFramePrefixes []string `json:"dropframeprefixes,omitempty"`
+
+	// InheritParentName prefixes the name of goroutines started by go statements transformed by "outrig run"
+	// with the name of the (named) goroutine that started them, and adds the parent's tags.
+	// Unnamed children get the parent's name, so worker trees read as "pool/worker" instead of anonymous funcs.
+	InheritParentName bool `json:"inheritparentname"`
+
+	// InheritNameSeparator separates the parent and child names (defaults to "/")
+	InheritNameSeparator string `json:"inheritnameseparator,omitempty"`
 }
 
 type RuntimeStatsConfig struct {
@@ -124,11 +228,31 @@ type RuntimeStatsConfig struct {
 	Enabled bool `json:"enabled"`
 }
 
+type DBPoolConfig struct {
+	// Enabled indicates whether the database pool collector (outrigsql.WatchDBPool) is enabled
+	Enabled bool `json:"enabled"`
+}
+
+type FDStatsConfig struct {
+	// Enabled indicates whether the file descriptor / socket collector is enabled
+	// (the counts are reported in the runtime stats)
+	Enabled bool `json:"enabled"`
+}
+
+type SchedStatsConfig struct {
+	// Enabled indicates whether the scheduler metrics collector (runtime/metrics) is enabled
+	// (scheduler latency, GC pauses, and mutex wait time are reported in the runtime stats)
+	Enabled bool `json:"enabled"`
+}
+
 type CollectorConfig struct {
 	Logs         LogProcessorConfig `json:"logs"`
 	RuntimeStats RuntimeStatsConfig `json:"runtimestats"`
 	Watch        WatchConfig        `json:"watch"`
 	Goroutine    GoRoutineConfig    `json:"goroutine"`
+	DBPool       DBPoolConfig       `json:"dbpool"`
+	FDStats      FDStatsConfig      `json:"fdstats"`
+	SchedStats   SchedStatsConfig   `json:"schedstats"`
 
 	Plugins map[string]any `json:"-"`
 }
@@ -153,13 +277,21 @@ type ExecConfig struct {
 	BuildFlags []string `json:"buildflags,omitempty"`
 
 	// Args are command-line arguments to pass to the Go program after it's built.
+	// ${VAR} references are replaced (see Env).
 	Args []string `json:"args,omitempty"`
 
+	// EnvFile lists .env files (KEY=VALUE lines, relative to config file location) that are loaded in order,
+	// their variables are set when running the program (Env wins over the envfile values).
+	EnvFile []string `json:"envfile,omitempty"`
+
 	// Env specifies additional environment variables to set when running the program.
+	// Values can reference the envfile variables and the environment with ${VAR} or ${VAR:-default}
+	// ("$$" is a literal "$"), so secrets and machine paths don't need to be hardcoded.
+	// Build variables (CC, CXX, CGO_*, GOOS, GOARCH, GOFLAGS, GOTOOLCHAIN, ...) also apply when loading and building the program.
 	Env map[string]string `json:"env,omitempty"`
 
 	// Cwd specifies the working directory for the program (relative to config file location).
-	// If not specified, defaults to the directory containing the config file.
+	// If not specified, defaults to the directory containing the config file. ${VAR} references are replaced.
 	Cwd string `json:"cwd,omitempty"`
 
 	// RawCmd specifies a raw shell command to execute instead of running Go code.
@@ -170,6 +302,10 @@ type ExecConfig struct {
 	// RawCmdShell specifies which shell to use for RawCmd execution.
 	// Defaults to $SHELL environment variable.
 	RawCmdShell string `json:"rawcmdshell,omitempty"`
+
+	// NoToolchainPin stops "outrig run" from setting GOTOOLCHAIN to the toolchain version the program was loaded
+	// with, so the go command picks the toolchain itself (for projects that need a specific toolchain or wrapper).
+	NoToolchainPin bool `json:"notoolchainpin,omitempty"`
 }
 
 // getDefaultConfig returns a default configuration with the specified dev mode
@@ -177,8 +313,13 @@ func getDefaultConfig(isDev bool) *Config {
 	return &Config{
 		DomainSocketPath: GetDomainSocketNameForClient(),
 		TcpAddr:          GetTcpAddrForClient(),
+		PipeName:         GetPipeNameForClient(),
 		ModuleName:       "",
 		ConnectOnInit:    true,
+		Remote: RemoteConfig{
+			BufferSize:   DefaultRemoteBufferSize,
+			MaxBackoffMs: DefaultRemoteMaxBackoffMs,
+		},
 		Collectors: CollectorConfig{
 			Logs: LogProcessorConfig{
 				Enabled:    true,
@@ -189,11 +330,22 @@ func getDefaultConfig(isDev bool) *Config {
 				Enabled: true,
 			},
 			Goroutine: GoRoutineConfig{
-				Enabled: true,
+				Enabled:              true,
+				InheritParentName:    true,
+				InheritNameSeparator: DefaultInheritNameSeparator,
 			},
 			RuntimeStats: RuntimeStatsConfig{
 				Enabled: true,
 			},
+			DBPool: DBPoolConfig{
+				Enabled: true,
+			},
+			FDStats: FDStatsConfig{
+				Enabled: true,
+			},
+			SchedStats: SchedStatsConfig{
+				Enabled: true,
+			},
 		},
 	}
 }
@@ -264,6 +416,14 @@ func GetOutrigHomeForClient() string {
 	return OutrigHome
 }
 
+// GetPipeNameForClient returns the named pipe of the monitor (Windows only)
+func GetPipeNameForClient() string {
+	if UseDevConfig() {
+		return DefaultDevPipeName
+	}
+	return DefaultPipeName
+}
+
 // GetDomainSocketNameForClient returns the full domain socket path for client
 func GetDomainSocketNameForClient() string {
 	return GetOutrigHomeForClient() + DefaultDomainSocketName
@@ -324,6 +484,39 @@ func (c *RuntimeStatsConfig) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, (*alias)(c))
 }
 
+// UnmarshalJSON implements custom unmarshaling for DBPoolConfig with defaults
+func (c *DBPoolConfig) UnmarshalJSON(data []byte) error {
+	// Set defaults first
+	defaultConfig := getDefaultConfig(UseDevConfig())
+	*c = defaultConfig.Collectors.DBPool
+
+	// Then unmarshal user values
+	type alias DBPoolConfig
+	return json.Unmarshal(data, (*alias)(c))
+}
+
+// UnmarshalJSON implements custom unmarshaling for FDStatsConfig with defaults
+func (c *FDStatsConfig) UnmarshalJSON(data []byte) error {
+	// Set defaults first
+	defaultConfig := getDefaultConfig(UseDevConfig())
+	*c = defaultConfig.Collectors.FDStats
+
+	// Then unmarshal user values
+	type alias FDStatsConfig
+	return json.Unmarshal(data, (*alias)(c))
+}
+
+// UnmarshalJSON implements custom unmarshaling for SchedStatsConfig with defaults
+func (c *SchedStatsConfig) UnmarshalJSON(data []byte) error {
+	// Set defaults first
+	defaultConfig := getDefaultConfig(UseDevConfig())
+	*c = defaultConfig.Collectors.SchedStats
+
+	// Then unmarshal user values
+	type alias SchedStatsConfig
+	return json.Unmarshal(data, (*alias)(c))
+}
+
 // UnmarshalJSON implements custom unmarshaling for RunModeConfig with defaults
 func (c *RunModeConfig) UnmarshalJSON(data []byte) error {
 	// Set defaults first
@@ -335,6 +528,17 @@ func (c *RunModeConfig) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, (*alias)(c))
 }
 
+// UnmarshalJSON implements custom unmarshaling for RemoteConfig with defaults
+func (c *RemoteConfig) UnmarshalJSON(data []byte) error {
+	// Set defaults first
+	defaultConfig := getDefaultConfig(UseDevConfig())
+	*c = defaultConfig.Remote
+
+	// Then unmarshal user values
+	type alias RemoteConfig
+	return json.Unmarshal(data, (*alias)(c))
+}
+
 // UnmarshalJSON implements custom unmarshaling for ExecConfig with defaults
 func (c *ExecConfig) UnmarshalJSON(data []byte) error {
 	// Set defaults first
@@ -383,6 +587,24 @@ func (c *CollectorConfig) UnmarshalJSON(data []byte) error {
 		}
 		delete(raw, "goroutine")
 	}
+	if dbpool, ok := raw["dbpool"]; ok {
+		if err := json.Unmarshal(dbpool, &c.DBPool); err != nil {
+			return err
+		}
+		delete(raw, "dbpool")
+	}
+	if fdstats, ok := raw["fdstats"]; ok {
+		if err := json.Unmarshal(fdstats, &c.FDStats); err != nil {
+			return err
+		}
+		delete(raw, "fdstats")
+	}
+	if schedstats, ok := raw["schedstats"]; ok {
+		if err := json.Unmarshal(schedstats, &c.SchedStats); err != nil {
+			return err
+		}
+		delete(raw, "schedstats")
+	}
 
 	// Everything else goes into Plugins as RawMessage
 	c.Plugins = make(map[string]any)
@@ -414,5 +636,5 @@ func (e *ExecConfig) ValidateExecConfig() error {
 		return fmt.Errorf("'buildflags' and 'args' are not compatible with 'rawcmd'")
 	}
 
-	return nil
+	return e.validateExecVars()
 }
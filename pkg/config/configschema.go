@@ -0,0 +1,208 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaPath is where the Outrig monitor serves the config schema (add "$schema" to outrig.json for editor support)
+const SchemaPath = "/schema/outrig.json"
+
+// SchemaId is the $id of the generated schema
+const SchemaId = "https://outrig.run/schema/outrig.json"
+
+// SchemaJSON is the JSON schema for outrig.json, generated from Config by server/cmd/generateschema
+//
+//go:embed outrig.schema.json
+var SchemaJSON []byte
+
+// JSONSchema is the subset of JSON Schema used to describe Config
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Id                   string                 `json:"$id,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"` // false or a *JSONSchema
+}
+
+// schemaJsonTypeNames are used in the validation errors ("must be a string")
+var schemaJsonTypeNames = map[string]string{
+	"object":  "an object",
+	"array":   "an array",
+	"string":  "a string",
+	"boolean": "a boolean",
+	"integer": "an integer",
+	"number":  "a number",
+}
+
+// openObjectTypes are config structs that accept fields they don't declare (collector plugin configs)
+var openObjectTypes = map[reflect.Type]bool{
+	reflect.TypeOf(CollectorConfig{}): true,
+}
+
+// GenerateSchema builds the JSON schema for Config from its json struct tags.
+// fieldDocs maps "TypeName.FieldName" to the field's description.
+func GenerateSchema(fieldDocs map[string]string) *JSONSchema {
+	schema := typeToSchema(reflect.TypeOf(Config{}), fieldDocs)
+	schema.Schema = "https://json-schema.org/draft/2020-12/schema"
+	schema.Id = SchemaId
+	schema.Title = "Outrig configuration (outrig.json)"
+	schema.Properties["$schema"] = &JSONSchema{Type: "string", Description: "URL of this JSON schema (ignored by Outrig)"}
+	return schema
+}
+
+func typeToSchema(rtype reflect.Type, fieldDocs map[string]string) *JSONSchema {
+	switch rtype.Kind() {
+	case reflect.Ptr:
+		return typeToSchema(rtype.Elem(), fieldDocs)
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: typeToSchema(rtype.Elem(), fieldDocs)}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: typeToSchema(rtype.Elem(), fieldDocs)}
+	case reflect.Struct:
+		schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+		if !openObjectTypes[rtype] {
+			schema.AdditionalProperties = false
+		}
+		for i := 0; i < rtype.NumField(); i++ {
+			field := rtype.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			fieldSchema := typeToSchema(field.Type, fieldDocs)
+			fieldSchema.Description = fieldDocs[rtype.Name()+"."+field.Name]
+			schema.Properties[name] = fieldSchema
+		}
+		return schema
+	default:
+		// interface{} fields accept any value
+		return &JSONSchema{}
+	}
+}
+
+// ValidateConfigJSON checks a config file against the schema before it is unmarshaled,
+// returning errors with the path of each bad value (e.g. "exec.rawcmdshell must be a string").
+func ValidateConfigJSON(data []byte) error {
+	var schema JSONSchema
+	if err := json.Unmarshal(SchemaJSON, &schema); err != nil {
+		return fmt.Errorf("invalid embedded config schema: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	var errs []error
+	validateValue(&schema, value, "", &errs)
+	return errors.Join(errs...)
+}
+
+func validateValue(schema *JSONSchema, value any, path string, errs *[]error) {
+	if schema == nil || schema.Type == "" {
+		return
+	}
+	displayPath := path
+	if displayPath == "" {
+		displayPath = "config"
+	}
+	if !valueMatchesType(schema.Type, value) {
+		*errs = append(*errs, fmt.Errorf("%s must be %s", displayPath, schemaJsonTypeNames[schema.Type]))
+		return
+	}
+	switch val := value.(type) {
+	case []any:
+		for idx, item := range val {
+			validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", displayPath, idx), errs)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			if propSchema, ok := schema.Properties[key]; ok {
+				validateValue(propSchema, val[key], keyPath, errs)
+				continue
+			}
+			switch additional := schema.AdditionalProperties.(type) {
+			case bool:
+				if !additional {
+					*errs = append(*errs, fmt.Errorf("%s is not a known setting", keyPath))
+				}
+			case map[string]any:
+				// AdditionalProperties is decoded generically, convert it back into a schema
+				var itemSchema JSONSchema
+				barr, _ := json.Marshal(additional)
+				if json.Unmarshal(barr, &itemSchema) == nil {
+					validateValue(&itemSchema, val[key], keyPath, errs)
+				}
+			}
+		}
+	}
+}
+
+func valueMatchesType(schemaType string, value any) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		num, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		if _, err := num.Int64(); err == nil {
+			return true
+		}
+		fval, err := num.Float64()
+		return err == nil && fval == math.Trunc(fval)
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func stripDescriptions(schema map[string]any) {
+	delete(schema, "description")
+	for _, value := range schema {
+		if val, ok := value.(map[string]any); ok {
+			stripDescriptions(val)
+		}
+	}
+}
+
+func TestSchemaUpToDate(t *testing.T) {
+	barr, err := json.Marshal(GenerateSchema(nil))
+	if err != nil {
+		t.Fatalf("error marshaling schema: %v", err)
+	}
+	var generated, embedded map[string]any
+	json.Unmarshal(barr, &generated)
+	if err := json.Unmarshal(SchemaJSON, &embedded); err != nil {
+		t.Fatalf("invalid embedded schema: %v", err)
+	}
+	stripDescriptions(generated)
+	stripDescriptions(embedded)
+	if !reflect.DeepEqual(generated, embedded) {
+		t.Errorf("outrig.schema.json is out of date, run 'task generate'")
+	}
+}
+
+func TestValidateConfigJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected []string
+	}{
+		{"valid", `{"$schema": "x", "appname": "app", "exec": {"entry": ".", "env": {"A": "1"}}, "collectors": {"myplugin": {"x": 1}}}`, nil},
+		{"string", `{"exec": {"rawcmdshell": 5}}`, []string{"exec.rawcmdshell must be a string"}},
+		{"bool", `{"collectors": {"logs": {"enabled": "yes"}}}`, []string{"collectors.logs.enabled must be a boolean"}},
+		{"integer", `{"remote": {"buffersize": 1.5}}`, []string{"remote.buffersize must be an integer"}},
+		{"array item", `{"exec": {"args": ["a", 1]}}`, []string{"exec.args[1] must be a string"}},
+		{"map value", `{"exec": {"env": {"A": true}}}`, []string{"exec.env.A must be a string"}},
+		{"unknown", `{"exec": {"entyr": "."}}`, []string{"exec.entyr is not a known setting"}},
+		{"top level", `[]`, []string{"config must be an object"}},
+		{"multiple", `{"appname": 1, "quiet": "no"}`, []string{"appname must be a string", "quiet must be a boolean"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConfigJSON([]byte(tt.json))
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %v, got nil", tt.expected)
+			}
+			if got := strings.Split(err.Error(), "\n"); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
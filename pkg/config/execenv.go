@@ -0,0 +1,265 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var envVarNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ExpandExecVars replaces the ${VAR} references in s with the value returned by lookup.
+// ${VAR:-default} uses default (which may reference other variables) when VAR is unset or empty,
+// and "$$" is a literal "$". A "$" that doesn't start a reference is kept as is.
+// It is an error to reference a variable that is not set (and has no default).
+func ExpandExecVars(s string, lookup func(name string) (string, bool)) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var buf strings.Builder
+	for idx := 0; idx < len(s); idx++ {
+		if s[idx] != '$' || idx+1 >= len(s) {
+			buf.WriteByte(s[idx])
+			continue
+		}
+		if s[idx+1] == '$' {
+			buf.WriteByte('$')
+			idx++
+			continue
+		}
+		if s[idx+1] != '{' {
+			buf.WriteByte('$')
+			continue
+		}
+		endIdx := findVarRefEnd(s, idx+2)
+		if endIdx < 0 {
+			return "", fmt.Errorf("unterminated variable reference in %q", s)
+		}
+		name, defaultVal, hasDefault := strings.Cut(s[idx+2:endIdx], ":-")
+		if !envVarNameRe.MatchString(name) {
+			return "", fmt.Errorf("invalid variable name %q in %q", name, s)
+		}
+		value, ok := lookup(name)
+		if hasDefault && value == "" {
+			var err error
+			value, err = ExpandExecVars(defaultVal, lookup)
+			if err != nil {
+				return "", err
+			}
+		} else if !ok {
+			return "", fmt.Errorf("variable %q is not set (referenced in %q, use ${%s:-default} for a default value)", name, s, name)
+		}
+		buf.WriteString(value)
+		idx = endIdx
+	}
+	return buf.String(), nil
+}
+
+// findVarRefEnd returns the index of the "}" that closes the reference whose contents start at startIdx
+// (defaults can contain nested references), or -1
+func findVarRefEnd(s string, startIdx int) int {
+	depth := 1
+	for idx := startIdx; idx < len(s); idx++ {
+		switch {
+		case s[idx] == '$' && idx+1 < len(s) && s[idx+1] == '{':
+			depth++
+			idx++
+		case s[idx] == '}':
+			depth--
+			if depth == 0 {
+				return idx
+			}
+		}
+	}
+	return -1
+}
+
+// checkExecVars checks the syntax of the variable references in s (without resolving them)
+func checkExecVars(s string) error {
+	_, err := ExpandExecVars(s, func(string) (string, bool) { return "", true })
+	return err
+}
+
+// ParseEnvFile parses the contents of a .env file: KEY=VALUE lines (optionally prefixed with "export"),
+// blank lines, and # comments. Values can be single quoted (literal), double quoted (with \n, \t, \", and \\
+// escapes), or unquoted (trimmed, a " #" starts a comment). Values are not interpolated.
+func ParseEnvFile(data []byte) (map[string]string, error) {
+	rtn := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNum)
+		}
+		if !envVarNameRe.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid variable name %q", lineNum, key)
+		}
+		value, err := parseEnvFileValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		rtn[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rtn, nil
+}
+
+func parseEnvFileValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	switch value[0] {
+	case '\'':
+		endIdx := strings.IndexByte(value[1:], '\'')
+		if endIdx < 0 {
+			return "", fmt.Errorf("unterminated single quoted value")
+		}
+		return value[1 : endIdx+1], nil
+	case '"':
+		var buf strings.Builder
+		for idx := 1; idx < len(value); idx++ {
+			ch := value[idx]
+			if ch == '"' {
+				return buf.String(), nil
+			}
+			if ch == '\\' && idx+1 < len(value) {
+				idx++
+				switch value[idx] {
+				case 'n':
+					buf.WriteByte('\n')
+				case 't':
+					buf.WriteByte('\t')
+				case 'r':
+					buf.WriteByte('\r')
+				default:
+					buf.WriteByte(value[idx])
+				}
+				continue
+			}
+			buf.WriteByte(ch)
+		}
+		return "", fmt.Errorf("unterminated double quoted value")
+	}
+	if commentIdx := strings.Index(value, " #"); commentIdx >= 0 {
+		value = value[:commentIdx]
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// validateExecVars checks the envfile entries, the env names, and the variable references of the config
+func (e *ExecConfig) validateExecVars() error {
+	for _, envFile := range e.EnvFile {
+		if strings.TrimSpace(envFile) == "" {
+			return fmt.Errorf("'envfile' entries cannot be empty")
+		}
+		if err := checkExecVars(envFile); err != nil {
+			return fmt.Errorf("invalid 'envfile' entry: %w", err)
+		}
+	}
+	for key, value := range e.Env {
+		if !envVarNameRe.MatchString(key) {
+			return fmt.Errorf("invalid 'env' variable name %q", key)
+		}
+		if err := checkExecVars(value); err != nil {
+			return fmt.Errorf("invalid 'env' value for %s: %w", key, err)
+		}
+	}
+	for _, arg := range e.Args {
+		if err := checkExecVars(arg); err != nil {
+			return fmt.Errorf("invalid 'args' entry: %w", err)
+		}
+	}
+	if err := checkExecVars(e.Cwd); err != nil {
+		return fmt.Errorf("invalid 'cwd': %w", err)
+	}
+	return nil
+}
+
+// ResolveExecConfig returns a copy of the config with the envfiles loaded and the ${VAR} references
+// in env, args, and cwd replaced. Relative envfile paths are relative to baseDir (the config file directory).
+// The envfiles are loaded in order on top of the process environment, and the env values are resolved
+// against that (so they can't reference each other). args and cwd are resolved against the final environment.
+// In the returned config Env has the envfile variables as well (env wins), and EnvFile is cleared.
+func (e *ExecConfig) ResolveExecConfig(baseDir string) (ExecConfig, error) {
+	if err := e.validateExecVars(); err != nil {
+		return ExecConfig{}, err
+	}
+	rtn := *e
+	rtn.EnvFile = nil
+	rtn.Env = make(map[string]string)
+	fileVars := make(map[string]string)
+	lookupFileVars := func(name string) (string, bool) {
+		if value, ok := fileVars[name]; ok {
+			return value, true
+		}
+		return os.LookupEnv(name)
+	}
+	for _, envFile := range e.EnvFile {
+		envFilePath, err := ExpandExecVars(envFile, lookupFileVars)
+		if err != nil {
+			return ExecConfig{}, fmt.Errorf("envfile %q: %w", envFile, err)
+		}
+		if !filepath.IsAbs(envFilePath) {
+			envFilePath = filepath.Join(baseDir, envFilePath)
+		}
+		data, err := os.ReadFile(envFilePath)
+		if err != nil {
+			return ExecConfig{}, fmt.Errorf("failed to read envfile: %w", err)
+		}
+		vars, err := ParseEnvFile(data)
+		if err != nil {
+			return ExecConfig{}, fmt.Errorf("failed to parse envfile %s: %w", envFilePath, err)
+		}
+		for key, value := range vars {
+			fileVars[key] = value
+			rtn.Env[key] = value
+		}
+	}
+	for key, value := range e.Env {
+		expanded, err := ExpandExecVars(value, lookupFileVars)
+		if err != nil {
+			return ExecConfig{}, fmt.Errorf("env %s: %w", key, err)
+		}
+		rtn.Env[key] = expanded
+	}
+	lookupEnv := func(name string) (string, bool) {
+		if value, ok := rtn.Env[name]; ok {
+			return value, true
+		}
+		return os.LookupEnv(name)
+	}
+	rtn.Args = nil
+	for _, arg := range e.Args {
+		expanded, err := ExpandExecVars(arg, lookupEnv)
+		if err != nil {
+			return ExecConfig{}, fmt.Errorf("args: %w", err)
+		}
+		rtn.Args = append(rtn.Args, expanded)
+	}
+	cwd, err := ExpandExecVars(e.Cwd, lookupEnv)
+	if err != nil {
+		return ExecConfig{}, fmt.Errorf("cwd: %w", err)
+	}
+	rtn.Cwd = cwd
+	if len(rtn.Env) == 0 {
+		rtn.Env = nil
+	}
+	return rtn, nil
+}
@@ -12,6 +12,12 @@ import (
 
 const ConfigFileName = "outrig.json"
 
+// Config sources that don't come from LoadConfig (LoadConfig returns "file:<path>" or "env:<name>")
+const (
+	ConfigSourceDefault = "default-config" // no config was found, DefaultConfig() is used
+	ConfigSourceInit    = "init"           // the config was passed to outrig.Init
+)
+
 // LoadConfig loads configuration from various sources in priority order.
 // The overrideFileName parameter, if provided, takes highest priority and overrides all other sources.
 // This is typically used when a config file is explicitly specified via CLI arguments.
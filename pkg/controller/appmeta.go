@@ -0,0 +1,89 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+// environment variables checked (in order) for the default "region" metadata
+var regionEnvNames = []string{"OUTRIG_REGION", "AWS_REGION", "AWS_DEFAULT_REGION", "FLY_REGION", "GOOGLE_CLOUD_REGION"}
+
+// SetAppMeta sets a metadata key of the app run (an empty value removes it) and sends the metadata to the server
+func (c *ControllerImpl) SetAppMeta(key string, value string) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return
+	}
+	c.Lock.Lock()
+	// copy so AppInfo/AppMeta packets that are already queued keep their own map
+	meta := maps.Clone(c.AppInfo.Meta)
+	if meta == nil {
+		meta = make(map[string]string)
+	}
+	if value == "" {
+		delete(meta, key)
+	} else {
+		meta[key] = value
+	}
+	c.AppInfo.Meta = meta
+	c.Lock.Unlock()
+
+	c.transport.SendPacket(&ds.PacketType{
+		Type: ds.PacketTypeAppMeta,
+		Data: ds.AppMetaData{Meta: meta},
+	}, false)
+}
+
+// GetAppMeta returns a copy of the app run metadata
+func (c *ControllerImpl) GetAppMeta() map[string]string {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	return maps.Clone(c.AppInfo.Meta)
+}
+
+// makeDefaultAppMeta returns the initial metadata of the app run: hostname, region, and gitsha (when known),
+// overridden by the key=value pairs in OUTRIG_APPMETA (set by "outrig run --meta")
+func makeDefaultAppMeta(appInfo *ds.AppInfo) map[string]string {
+	meta := make(map[string]string)
+	if appInfo.Hostname != "" {
+		meta["hostname"] = appInfo.Hostname
+	}
+	for _, envName := range regionEnvNames {
+		if region := os.Getenv(envName); region != "" {
+			meta["region"] = region
+			break
+		}
+	}
+	if appInfo.BuildInfo != nil && appInfo.BuildInfo.Settings["vcs.revision"] != "" {
+		meta["gitsha"] = appInfo.BuildInfo.Settings["vcs.revision"]
+	}
+	for key, value := range parseAppMeta(os.Getenv(config.AppMetaEnvName)) {
+		if value == "" {
+			delete(meta, key)
+		} else {
+			meta[key] = value
+		}
+	}
+	return meta
+}
+
+// parseAppMeta parses "key=value,key2=value2" (the OUTRIG_APPMETA format), entries without a key are skipped
+func parseAppMeta(str string) map[string]string {
+	rtn := make(map[string]string)
+	for _, entry := range strings.Split(str, ",") {
+		key, value, _ := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		rtn[key] = strings.TrimSpace(value)
+	}
+	return rtn
+}
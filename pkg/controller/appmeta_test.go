@@ -0,0 +1,53 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func TestParseAppMeta(t *testing.T) {
+	tests := []struct {
+		name   string
+		str    string
+		expect map[string]string
+	}{
+		{"empty", "", map[string]string{}},
+		{"pairs", "env=prod,team=core", map[string]string{"env": "prod", "team": "core"}},
+		{"spaces are trimmed", " env = prod , team=core ", map[string]string{"env": "prod", "team": "core"}},
+		{"value with equals", "query=a=b", map[string]string{"query": "a=b"}},
+		{"no value", "env,team=", map[string]string{"env": "", "team": ""}},
+		{"no key", "=prod,,env=dev", map[string]string{"env": "dev"}},
+		{"last one wins", "env=prod,env=dev", map[string]string{"env": "dev"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseAppMeta(tc.str); !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("got %v, want %v", got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestMakeDefaultAppMeta(t *testing.T) {
+	for _, envName := range regionEnvNames {
+		t.Setenv(envName, "")
+	}
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("FLY_REGION", "ord")
+	t.Setenv(config.AppMetaEnvName, "hostname=,env=prod")
+	appInfo := &ds.AppInfo{
+		Hostname:  "box",
+		BuildInfo: &ds.BuildInfoData{Settings: map[string]string{"vcs.revision": "abc123"}},
+	}
+	// the first region variable that is set wins, OUTRIG_APPMETA overrides (an empty value removes the key)
+	expect := map[string]string{"region": "us-east-1", "gitsha": "abc123", "env": "prod"}
+	if got := makeDefaultAppMeta(appInfo); !reflect.DeepEqual(got, expect) {
+		t.Errorf("got %v, want %v", got, expect)
+	}
+}
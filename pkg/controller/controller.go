@@ -4,6 +4,7 @@
 package controller
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -13,6 +14,7 @@ import (
 	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/outrigdev/outrig/pkg/collector"
@@ -22,22 +24,30 @@ import (
 	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/global"
 	"github.com/outrigdev/outrig/pkg/ioutrig"
+	"github.com/outrigdev/outrig/pkg/platform"
 	"github.com/outrigdev/outrig/pkg/utilds"
 	"github.com/outrigdev/outrig/pkg/utilfn"
 	"golang.org/x/term"
 )
 
 const ConnPollTime = 1 * time.Second
+const RemoteInitialBackoff = 1 * time.Second
 const MaxInternalLog = 100
 
 type ControllerImpl struct {
 	Lock                sync.Mutex // lock for this struct
 	config              *config.Config
+	configSource        string                 // where the config came from (see ds.ConfigInfo)
 	pollerOnce          sync.Once              // ensures poller is started only once
 	AppInfo             ds.AppInfo             // combined application information
 	OutrigForceDisabled bool                   // whether outrig is force disabled
 	InternalLogBuf      *utilds.CirBuf[string] // internal log for debugging
 	transport           *Transport             // handles connection management and packet sending
+	remoteBackoff       time.Duration          // current reconnect backoff (remote mode only)
+	nextConnectTime     time.Time              // earliest time for the next reconnect attempt (remote mode only)
+	lastSentDropCount   atomic.Int64           // TransportStats.TotalDropped when last sent (-1 to send on the next poll)
+	lastSentRedactCount atomic.Int64           // TransportStats.Redacted when last sent
+	captureMode         atomic.Bool            // collectors run without a connection, packets only go to the hooks (outrigtest)
 }
 
 // this is idempotent
@@ -49,6 +59,7 @@ func MakeController(appName string, cfg config.Config) (*ControllerImpl, error)
 
 	// Initialize transport
 	c.transport = MakeTransport(&cfg)
+	c.transport.SetPacketHandler(c.handleServerPacket)
 
 	// Initialize AppInfo using the dedicated function
 	c.AppInfo = c.createAppInfo(appName, &cfg)
@@ -66,11 +77,25 @@ func (c *ControllerImpl) InitialStart() {
 		c.OutrigForceDisabled = true
 	}
 
+	if !platform.Connect {
+		// restricted target (wasm, TinyGo), the SDK compiles and runs but can't reach the server
+		caps := c.AppInfo.Capabilities
+		c.ILog("SDK not connecting, restricted platform %s (compiler %s)", caps.Platform, caps.Compiler)
+		if !c.config.Quiet {
+			fmt.Printf("#outrig disabled, %s is not supported (the SDK can't connect to the Outrig server)\n", caps.Platform)
+		}
+		return
+	}
+
 	var connected bool
+	var transErr error
 	if c.config.ConnectOnInit && !c.OutrigForceDisabled {
-		connected, _ = c.connectInternal(true)
+		connected, transErr = c.connectInternal(true)
 	}
-	if connected {
+	if transErr != nil && comm.IsRemoteMode(c.config) {
+		c.scheduleRemoteReconnect()
+	}
+	if connected || c.bufferWhileDisconnected() {
 		c.setEnabled(true)
 	}
 	go func() {
@@ -87,12 +112,16 @@ func (c *ControllerImpl) createAppInfo(appName string, cfg *config.Config) ds.Ap
 	appInfo.AppRunId = config.GetAppRunId()
 	appInfo.AppName = appName
 
-	// Set module name
+	// Set module name and directory (the directory is only set if the go.mod found is the app's module)
+	goModName, goModDir := c.findGoMod()
 	moduleName := cfg.ModuleName
 	if moduleName == "" {
-		moduleName = c.determineModuleName()
+		moduleName = goModName
 	}
 	appInfo.ModuleName = moduleName
+	if moduleName != "" && moduleName == goModName {
+		appInfo.ModuleDir = goModDir
+	}
 
 	// Initialize the rest of AppInfo
 	appInfo.StartTime = time.Now().UnixMilli()
@@ -101,6 +130,8 @@ func (c *ControllerImpl) createAppInfo(appName string, cfg *config.Config) ds.Ap
 	appInfo.Env = utilfn.CopyStrArr(os.Environ())
 	appInfo.Pid = os.Getpid()
 	appInfo.OutrigSDKVersion = config.OutrigSDKVersion
+	caps := platform.GetCapabilities()
+	appInfo.Capabilities = &caps
 
 	// Get user information
 	user, err := user.Current()
@@ -134,6 +165,8 @@ func (c *ControllerImpl) createAppInfo(appName string, cfg *config.Config) ds.Ap
 		appInfo.RunMode = true
 	}
 
+	appInfo.Meta = makeDefaultAppMeta(&appInfo)
+
 	return appInfo
 }
 
@@ -150,12 +183,18 @@ func (c *ControllerImpl) WriteInitMessage(connected bool, connWrap *comm.ConnWra
 
 	if connected && connWrap != nil {
 		printf("%s#outrig%s connected via %s (apprunid:%s)\n", brightCyan, reset, connWrap.PeerName, c.AppInfo.AppRunId)
-		if connWrap.ServerResponse != nil && connWrap.ServerResponse.ServerHttpPort > 0 {
+		if connWrap.ServerResponse != nil && connWrap.ServerResponse.ServerHttpPort > 0 && !comm.IsRemoteMode(c.config) {
 			printf("%s#outrig%s dashboard available @ %shttp://localhost:%d%s\n", brightCyan, reset, brightBlueUnderline, connWrap.ServerResponse.ServerHttpPort, reset)
 		}
 	} else if permErr != nil {
 		printf("%s#outrig%s Permanent connection error: %v\n", brightCyan, reset, permErr)
 		printf("%s#outrig%s Entering standby mode.\n", brightCyan, reset)
+	} else if transErr != nil && comm.IsRemoteMode(c.config) {
+		if c.bufferWhileDisconnected() {
+			printf("%s#outrig%s Remote monitor %s not reachable (%v); buffering and retrying in the background.\n", brightCyan, reset, comm.GetRemoteAddr(c.config), transErr)
+		} else {
+			printf("%s#outrig%s Remote monitor %s not reachable (%v); entering standby mode.\n", brightCyan, reset, comm.GetRemoteAddr(c.config), transErr)
+		}
 	} else if transErr != nil {
 		if outrigPath, _ := exec.LookPath("outrig"); outrigPath == "" {
 			printf("%s#outrig%s Outrig server not detected; entering standby mode. %sInfo: https://outrig.run%s\n", brightCyan, reset, brightBlueUnderline, reset)
@@ -174,7 +213,7 @@ func (c *ControllerImpl) connectInternal(init bool) (rtnConnected bool, rtnErr e
 	if c.transport.HasConnections() {
 		return false, nil
 	}
-	if c.OutrigForceDisabled {
+	if c.OutrigForceDisabled || !platform.Connect {
 		return false, nil
 	}
 	var connWrap *comm.ConnWrap
@@ -205,6 +244,8 @@ func (c *ControllerImpl) connectInternal(init bool) (rtnConnected bool, rtnErr e
 	}
 	c.transport.AddConn(connWrap)
 	c.sendAppInfo()
+	c.sendConfigInfo()
+	c.lastSentDropCount.Store(-1)
 
 	// Notify all collectors of the new connection
 	collector.NotifyCollectorsNewConnection()
@@ -233,11 +274,21 @@ func (c *ControllerImpl) Enable() {
 	if !isConnected {
 		isConnected, _ = c.connectInternal(false)
 	}
-	if isConnected {
+	if isConnected || c.bufferWhileDisconnected() {
 		c.setEnabled(true)
 	}
 }
 
+// StartCapture keeps Outrig enabled without a connection to a monitor, the packets are only seen by the
+// packet hooks (used by the outrigtest package). Goroutine and watch updates are always sent in full.
+func (c *ControllerImpl) StartCapture() {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	c.captureMode.Store(true)
+	c.OutrigForceDisabled = false
+	c.setEnabled(true)
+}
+
 func (c *ControllerImpl) Disable(disconnect bool) {
 	c.Lock.Lock()
 	defer c.Lock.Unlock()
@@ -272,6 +323,15 @@ func (c *ControllerImpl) SendPacket(pk *ds.PacketType) (bool, error) {
 	return c.transport.SendPacket(pk, false)
 }
 
+func (c *ControllerImpl) GetProtocolFeatures() ds.ProtocolFeatures {
+	rtn := c.transport.GetProtocolFeatures()
+	if c.captureMode.Load() {
+		// captured packets are read one at a time, deltas would need the previous packets
+		rtn.DeltaVersion = ds.DeltaVersionNone
+	}
+	return rtn
+}
+
 func (c *ControllerImpl) sendAppInfo() {
 	appInfoPacket := &ds.PacketType{
 		Type: ds.PacketTypeAppInfo,
@@ -280,6 +340,33 @@ func (c *ControllerImpl) sendAppInfo() {
 	c.transport.SendPacket(appInfoPacket, true)
 }
 
+// SetConfigSource records where the config came from (sent with the config on connect), call it before InitialStart
+func (c *ControllerImpl) SetConfigSource(source string) {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	c.configSource = source
+}
+
+// sendConfigInfo sends the resolved config, so the monitor can show why a collector isn't running
+func (c *ControllerImpl) sendConfigInfo() {
+	cfg := *c.config
+	cfg.AppName = c.AppInfo.AppName
+	if len(cfg.Exec.Env) > 0 {
+		maskedEnv := make(map[string]string, len(cfg.Exec.Env))
+		for key := range cfg.Exec.Env {
+			maskedEnv[key] = "***"
+		}
+		cfg.Exec.Env = maskedEnv
+	}
+	c.transport.SendPacket(&ds.PacketType{
+		Type: ds.PacketTypeConfigInfo,
+		Data: &ds.ConfigInfo{
+			Source: c.configSource,
+			Config: cfg,
+		},
+	}, true)
+}
+
 func (c *ControllerImpl) sendCollectorStatus() {
 	if !global.OutrigEnabled.Load() {
 		// Don't send collector status if Outrig is disabled
@@ -294,13 +381,120 @@ func (c *ControllerImpl) sendCollectorStatus() {
 	c.transport.SendPacket(collectorStatusPacket, false)
 }
 
+// sendTransportStats sends the send queue drop counters and the redaction count when they changed (and once per connection)
+// and asks the collectors with dropped delta packets for a full update
+func (c *ControllerImpl) sendTransportStats() {
+	for _, pkType := range c.transport.takeBrokenChains() {
+		collector.NotifyCollectorsPacketDropped(pkType)
+	}
+	if !global.OutrigEnabled.Load() {
+		return
+	}
+	stats := c.transport.GetTransportStats()
+	if stats.TotalDropped == c.lastSentDropCount.Load() && stats.Redacted == c.lastSentRedactCount.Load() {
+		return
+	}
+	transportStatsPacket := &ds.PacketType{
+		Type: ds.PacketTypeTransportStats,
+		Data: &stats,
+	}
+	if sent, _ := c.transport.SendPacket(transportStatsPacket, false); sent {
+		c.lastSentDropCount.Store(stats.TotalDropped)
+		c.lastSentRedactCount.Store(stats.Redacted)
+	}
+}
+
+// handleServerPacket handles packets sent from the server (called from the transport read loop)
+func (c *ControllerImpl) handleServerPacket(pkType string, data json.RawMessage) {
+	switch pkType {
+	case ds.PacketTypeCollectorAdmin:
+		var adminData ds.CollectorAdminData
+		if err := json.Unmarshal(data, &adminData); err != nil {
+			c.ILog("invalid collector admin packet: %v", err)
+			return
+		}
+		if err := c.handleCollectorAdmin(adminData); err != nil {
+			c.ILog("collector admin error (%s): %v", adminData.Collector, err)
+		}
+		// let the server know about the new collector state right away
+		c.sendCollectorStatus()
+	case ds.PacketTypeRuntimeControl:
+		var controlData ds.RuntimeControlData
+		if err := json.Unmarshal(data, &controlData); err != nil {
+			c.ILog("invalid runtime control packet: %v", err)
+			return
+		}
+		c.handleRuntimeControl(controlData)
+	case ds.PacketTypeCPUProfile:
+		var profileData ds.CPUProfileData
+		if err := json.Unmarshal(data, &profileData); err != nil {
+			c.ILog("invalid cpu profile packet: %v", err)
+			return
+		}
+		c.handleCPUProfile(profileData)
+	case ds.PacketTypeSetWatchValue:
+		var setData ds.SetWatchValueData
+		if err := json.Unmarshal(data, &setData); err != nil {
+			c.ILog("invalid set watch value packet: %v", err)
+			return
+		}
+		c.handleSetWatchValue(setData)
+	case ds.PacketTypeExecTrace:
+		var traceData ds.ExecTraceData
+		if err := json.Unmarshal(data, &traceData); err != nil {
+			c.ILog("invalid execution trace packet: %v", err)
+			return
+		}
+		c.handleExecTrace(traceData)
+	case ds.PacketTypeGoroutineDump:
+		var dumpData ds.GoroutineDumpData
+		if err := json.Unmarshal(data, &dumpData); err != nil {
+			c.ILog("invalid goroutine dump packet: %v", err)
+			return
+		}
+		c.handleGoroutineDump(dumpData)
+	case ds.PacketTypeResync:
+		var resyncData ds.ResyncData
+		if err := json.Unmarshal(data, &resyncData); err != nil {
+			c.ILog("invalid resync packet: %v", err)
+			return
+		}
+		c.ILog("server requested a full update of %v (%s)", resyncData.PacketTypes, resyncData.Reason)
+		for _, pkType := range resyncData.PacketTypes {
+			// same as a dropped packet, the collector sends a full update next
+			collector.NotifyCollectorsPacketDropped(pkType)
+		}
+	default:
+		c.ILog("unknown packet type from server: %s", pkType)
+	}
+}
+
+func (c *ControllerImpl) handleCollectorAdmin(adminData ds.CollectorAdminData) error {
+	if len(adminData.Settings) > 0 {
+		if err := collector.ApplyCollectorSettings(adminData.Collector, adminData.Settings); err != nil {
+			return err
+		}
+	}
+	switch adminData.Action {
+	case ds.CollectorAdminActionEnable:
+		return collector.SetCollectorEnabled(adminData.Collector, true)
+	case ds.CollectorAdminActionDisable:
+		return collector.SetCollectorEnabled(adminData.Collector, false)
+	case "":
+		return nil
+	default:
+		return fmt.Errorf("invalid collector admin action: %s", adminData.Action)
+	}
+}
+
 // Initialization methods
 
-func (c *ControllerImpl) determineModuleName() string {
+// findGoMod returns the module name and directory of the nearest go.mod (searching up from the working directory)
+func (c *ControllerImpl) findGoMod() (string, string) {
 	// Start from current directory
 	dir, err := os.Getwd()
 	if err != nil {
-		return ""
+		return "", ""
 	}
 
 	// Keep going up until we find go.mod or hit the filesystem root
@@ -310,7 +504,7 @@ func (c *ControllerImpl) determineModuleName() string {
 			// Found the go.mod file, now parse it
 			content, err := os.ReadFile(goModPath)
 			if err != nil {
-				return ""
+				return "", ""
 			}
 
 			// Look for the module line
@@ -320,10 +514,10 @@ func (c *ControllerImpl) determineModuleName() string {
 				if strings.HasPrefix(line, "module ") {
 					// Extract the module name
 					moduleName := strings.TrimSpace(strings.TrimPrefix(line, "module"))
-					return moduleName
+					return moduleName, dir
 				}
 			}
-			return "" // Module declaration not found
+			return "", "" // Module declaration not found
 		}
 
 		// Move up to parent directory
@@ -335,7 +529,7 @@ func (c *ControllerImpl) determineModuleName() string {
 		dir = parent
 	}
 
-	return "" // No go.mod found
+	return "", "" // No go.mod found
 }
 
 func determineAppName(appNameParam string) string {
@@ -371,6 +565,7 @@ func (c *ControllerImpl) runConnPoller() {
 		for {
 			c.pollConn()
 			c.sendCollectorStatus()
+			c.sendTransportStats()
 			time.Sleep(ConnPollTime)
 		}
 	})
@@ -383,14 +578,55 @@ func (c *ControllerImpl) pollConn() {
 		// Send collector status when connected
 		return
 	}
+	if comm.IsRemoteMode(c.config) && time.Now().Before(c.nextConnectTime) {
+		// waiting out the reconnect backoff
+		c.setEnabled(c.bufferWhileDisconnected())
+		return
+	}
 	// Try to connect
-	connected, _ := c.connectInternal(false)
+	connected, transErr := c.connectInternal(false)
 	if connected {
+		c.remoteBackoff = 0
+		c.nextConnectTime = time.Time{}
 		c.setEnabled(true)
 		return
 	}
-	// No connections after trying to connect, so disable
-	c.setEnabled(false)
+	if transErr != nil && comm.IsRemoteMode(c.config) {
+		c.scheduleRemoteReconnect()
+	}
+	// No connections after trying to connect, so disable (unless we're buffering for a remote monitor)
+	c.setEnabled(c.bufferWhileDisconnected())
+}
+
+// lock should be held
+// scheduleRemoteReconnect backs off exponentially (up to Remote.MaxBackoffMs) between reconnect attempts
+func (c *ControllerImpl) scheduleRemoteReconnect() {
+	maxBackoff := time.Duration(c.config.Remote.MaxBackoffMs) * time.Millisecond
+	if c.remoteBackoff == 0 {
+		c.remoteBackoff = RemoteInitialBackoff
+	} else {
+		c.remoteBackoff *= 2
+	}
+	if maxBackoff > 0 && c.remoteBackoff > maxBackoff {
+		c.remoteBackoff = maxBackoff
+	}
+	c.nextConnectTime = time.Now().Add(c.remoteBackoff)
+}
+
+// lock should be held
+// bufferWhileDisconnected returns true if collectors should keep running while disconnected
+// (remote mode with buffering enabled, packets are held in the transport until we reconnect, or capture mode)
+func (c *ControllerImpl) bufferWhileDisconnected() bool {
+	if c.OutrigForceDisabled {
+		return false
+	}
+	if c.captureMode.Load() {
+		return true
+	}
+	if !platform.Connect {
+		return false
+	}
+	return comm.IsRemoteMode(c.config) && c.config.Remote.BufferSize > 0
 }
 
 // lock should be held
@@ -0,0 +1,51 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/config"
+)
+
+func TestScheduleRemoteReconnect(t *testing.T) {
+	t.Setenv(config.RemoteAddrEnvName, "")
+	c := &ControllerImpl{config: &config.Config{Remote: config.RemoteConfig{Addr: "monitor:5005", MaxBackoffMs: 5000}}}
+	var got []time.Duration
+	for i := 0; i < 5; i++ {
+		c.scheduleRemoteReconnect()
+		got = append(got, c.remoteBackoff)
+	}
+	expect := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i := range expect {
+		if got[i] != expect[i] {
+			t.Fatalf("got backoffs %v, want %v", got, expect)
+		}
+	}
+	if wait := time.Until(c.nextConnectTime); wait <= 4*time.Second || wait > 5*time.Second {
+		t.Errorf("got the next connect in %v, want it after the backoff", wait)
+	}
+}
+
+func TestBufferWhileDisconnected(t *testing.T) {
+	t.Setenv(config.RemoteAddrEnvName, "")
+	tests := []struct {
+		name   string
+		remote config.RemoteConfig
+		forced bool
+		expect bool
+	}{
+		{"local mode", config.RemoteConfig{BufferSize: 100}, false, false},
+		{"remote mode", config.RemoteConfig{Addr: "monitor:5005", BufferSize: 100}, false, true},
+		{"buffering disabled", config.RemoteConfig{Addr: "monitor:5005"}, false, false},
+		{"force disabled", config.RemoteConfig{Addr: "monitor:5005", BufferSize: 100}, true, false},
+	}
+	for _, tc := range tests {
+		c := &ControllerImpl{config: &config.Config{Remote: tc.remote}, OutrigForceDisabled: tc.forced}
+		if got := c.bufferWhileDisconnected(); got != tc.expect {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.expect)
+		}
+	}
+}
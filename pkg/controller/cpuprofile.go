@@ -0,0 +1,73 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"runtime/pprof"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/ioutrig"
+)
+
+// handleCPUProfile captures a CPU profile for the requested duration (in its own goroutine, so the
+// transport read loop isn't blocked) and sends the profile back to the server
+func (c *ControllerImpl) handleCPUProfile(data ds.CPUProfileData) {
+	go func() {
+		ioutrig.I.SetGoRoutineNameAndTags("CPUProfile", "outrig")
+		result := captureCPUProfile(data)
+		c.sendCPUProfileAuditLog(data, result)
+		c.transport.SendPacket(&ds.PacketType{
+			Type: ds.PacketTypeCPUProfileResult,
+			Data: result,
+		}, true)
+	}()
+}
+
+func captureCPUProfile(data ds.CPUProfileData) ds.CPUProfileResult {
+	result := ds.CPUProfileResult{
+		CommandId: data.CommandId,
+	}
+	startTime := time.Now()
+	result.Ts = startTime.UnixMilli()
+	if data.DurationSec <= 0 || data.DurationSec > ds.MaxCPUProfileDurationSec {
+		result.Error = fmt.Sprintf("invalid cpu profile duration %ds (must be between 1 and %d)", data.DurationSec, ds.MaxCPUProfileDurationSec)
+		return result
+	}
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		// only one CPU profile can run at a time (e.g. the app is already profiling itself)
+		result.Error = fmt.Sprintf("cannot start cpu profile: %v", err)
+		return result
+	}
+	time.Sleep(time.Duration(data.DurationSec) * time.Second)
+	pprof.StopCPUProfile()
+	result.DurationMs = time.Since(startTime).Milliseconds()
+	result.Profile = buf.Bytes()
+	return result
+}
+
+func (c *ControllerImpl) sendCPUProfileAuditLog(data ds.CPUProfileData, result ds.CPUProfileResult) {
+	triggeredBy := data.TriggeredBy
+	if triggeredBy == "" {
+		triggeredBy = "unknown"
+	}
+	msg := fmt.Sprintf("[outrig] cpu profile (%ds) triggered by %s", data.DurationSec, triggeredBy)
+	if result.Error != "" {
+		msg += fmt.Sprintf(" failed: %s", result.Error)
+	} else {
+		msg += fmt.Sprintf(" done (%dms, %d bytes)", result.DurationMs, len(result.Profile))
+	}
+	c.ILog("%s", msg)
+	c.transport.SendPacket(&ds.PacketType{
+		Type: ds.PacketTypeLog,
+		Data: &ds.LogLine{
+			Ts:     time.Now().UnixMilli(),
+			Msg:    msg + "\n",
+			Source: RuntimeControlLogSource,
+		},
+	}, true)
+}
@@ -0,0 +1,110 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"runtime/trace"
+	"sync"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/ioutrig"
+)
+
+// execTraceBuffer collects the trace output (written by the runtime's trace reader goroutine) and closes
+// fullCh once the trace reaches maxBytes
+type execTraceBuffer struct {
+	lock     sync.Mutex
+	buf      bytes.Buffer
+	maxBytes int
+	fullCh   chan struct{}
+	full     bool
+}
+
+func (tb *execTraceBuffer) Write(p []byte) (int, error) {
+	tb.lock.Lock()
+	defer tb.lock.Unlock()
+	tb.buf.Write(p)
+	if !tb.full && tb.buf.Len() >= tb.maxBytes {
+		tb.full = true
+		close(tb.fullCh)
+	}
+	return len(p), nil
+}
+
+func (tb *execTraceBuffer) Bytes() []byte {
+	tb.lock.Lock()
+	defer tb.lock.Unlock()
+	return tb.buf.Bytes()
+}
+
+// handleExecTrace captures an execution trace for the requested duration (in its own goroutine, so the
+// transport read loop isn't blocked) and sends the trace back to the server
+func (c *ControllerImpl) handleExecTrace(data ds.ExecTraceData) {
+	go func() {
+		ioutrig.I.SetGoRoutineNameAndTags("ExecTrace", "outrig")
+		result := captureExecTrace(data)
+		c.sendExecTraceAuditLog(data, result)
+		c.transport.SendPacket(&ds.PacketType{
+			Type: ds.PacketTypeExecTraceResult,
+			Data: result,
+		}, true)
+	}()
+}
+
+func captureExecTrace(data ds.ExecTraceData) ds.ExecTraceResult {
+	result := ds.ExecTraceResult{
+		CommandId: data.CommandId,
+	}
+	startTime := time.Now()
+	result.Ts = startTime.UnixMilli()
+	if data.DurationSec <= 0 || data.DurationSec > ds.MaxExecTraceDurationSec {
+		result.Error = fmt.Sprintf("invalid execution trace duration %ds (must be between 1 and %d)", data.DurationSec, ds.MaxExecTraceDurationSec)
+		return result
+	}
+	traceBuf := &execTraceBuffer{maxBytes: ds.MaxExecTraceBytes, fullCh: make(chan struct{})}
+	if err := trace.Start(traceBuf); err != nil {
+		// only one trace can run at a time (e.g. the app is already tracing itself)
+		result.Error = fmt.Sprintf("cannot start execution trace: %v", err)
+		return result
+	}
+	timer := time.NewTimer(time.Duration(data.DurationSec) * time.Second)
+	select {
+	case <-timer.C:
+	case <-traceBuf.fullCh:
+		timer.Stop()
+		result.Truncated = true
+	}
+	trace.Stop()
+	result.DurationMs = time.Since(startTime).Milliseconds()
+	result.Trace = traceBuf.Bytes()
+	return result
+}
+
+func (c *ControllerImpl) sendExecTraceAuditLog(data ds.ExecTraceData, result ds.ExecTraceResult) {
+	triggeredBy := data.TriggeredBy
+	if triggeredBy == "" {
+		triggeredBy = "unknown"
+	}
+	msg := fmt.Sprintf("[outrig] execution trace (%ds) triggered by %s", data.DurationSec, triggeredBy)
+	if result.Error != "" {
+		msg += fmt.Sprintf(" failed: %s", result.Error)
+	} else {
+		msg += fmt.Sprintf(" done (%dms, %d bytes)", result.DurationMs, len(result.Trace))
+		if result.Truncated {
+			msg += " (stopped early, size limit reached)"
+		}
+	}
+	c.ILog("%s", msg)
+	c.transport.SendPacket(&ds.PacketType{
+		Type: ds.PacketTypeLog,
+		Data: &ds.LogLine{
+			Ts:     time.Now().UnixMilli(),
+			Msg:    msg + "\n",
+			Source: RuntimeControlLogSource,
+		},
+	}, true)
+}
@@ -0,0 +1,57 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/collector/goroutine"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/ioutrig"
+	"github.com/outrigdev/outrig/pkg/platform"
+)
+
+// handleGoroutineDump takes a full goroutine dump right away (in its own goroutine, so the transport read
+// loop isn't blocked) and sends it back to the server. The regular goroutine updates are not affected.
+func (c *ControllerImpl) handleGoroutineDump(data ds.GoroutineDumpData) {
+	go func() {
+		ioutrig.I.SetGoRoutineNameAndTags("GoroutineDump", "outrig")
+		result := ds.GoroutineDumpResult{
+			CommandId: data.CommandId,
+		}
+		if !platform.GoRoutineStacks {
+			result.Error = "goroutine stacks are not supported on this platform"
+		} else {
+			result.Info = goroutine.GetInstance().DumpGoroutinesFull()
+		}
+		c.sendGoroutineDumpAuditLog(data, result)
+		c.transport.SendPacket(&ds.PacketType{
+			Type: ds.PacketTypeGoroutineDumpResult,
+			Data: result,
+		}, true)
+	}()
+}
+
+func (c *ControllerImpl) sendGoroutineDumpAuditLog(data ds.GoroutineDumpData, result ds.GoroutineDumpResult) {
+	triggeredBy := data.TriggeredBy
+	if triggeredBy == "" {
+		triggeredBy = "unknown"
+	}
+	msg := fmt.Sprintf("[outrig] goroutine dump triggered by %s", triggeredBy)
+	if result.Error != "" {
+		msg += fmt.Sprintf(" failed: %s", result.Error)
+	} else {
+		msg += fmt.Sprintf(" done (%d goroutines)", result.Info.Count)
+	}
+	c.ILog("%s", msg)
+	c.transport.SendPacket(&ds.PacketType{
+		Type: ds.PacketTypeLog,
+		Data: &ds.LogLine{
+			Ts:     time.Now().UnixMilli(),
+			Msg:    msg + "\n",
+			Source: RuntimeControlLogSource,
+		},
+	}, true)
+}
@@ -0,0 +1,16 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo
+
+package controller
+
+import (
+	"os"
+	"runtime/debug"
+)
+
+func writeHeapDump(fd *os.File) error {
+	debug.WriteHeapDump(fd.Fd())
+	return nil
+}
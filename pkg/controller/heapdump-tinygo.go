@@ -0,0 +1,15 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build tinygo
+
+package controller
+
+import (
+	"fmt"
+	"os"
+)
+
+func writeHeapDump(fd *os.File) error {
+	return fmt.Errorf("heap dumps are not supported by TinyGo")
+}
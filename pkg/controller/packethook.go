@@ -0,0 +1,70 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+// PacketHookFn is called with each outgoing packet before it is queued (or buffered). pk.Data holds the
+// ds type of the packet (e.g. *ds.LogLine for "log", *ds.WatchInfo for "watch") and can be modified in
+// place or replaced. Returning false drops the packet.
+type PacketHookFn func(pk *ds.PacketType) bool
+
+var (
+	packetHooksLock sync.Mutex                     // serializes AddPacketHook
+	packetHooks     atomic.Pointer[[]PacketHookFn] // copy on write, read without locking on every send
+)
+
+// hookExemptTypes are never passed to the hooks: the monitor needs appinfo to accept the connection, and
+// the result packets answer requests the monitor is waiting on
+var hookExemptTypes = map[string]bool{
+	ds.PacketTypeAppInfo:              true,
+	ds.PacketTypeRuntimeControlResult: true,
+	ds.PacketTypeCPUProfileResult:     true,
+	ds.PacketTypeSetWatchValueResult:  true,
+	ds.PacketTypeExecTraceResult:      true,
+	ds.PacketTypeGoroutineDumpResult:  true,
+}
+
+// AddPacketHook registers a hook that sees every packet the SDK sends (hooks run in the order they were added)
+func AddPacketHook(hook PacketHookFn) {
+	if hook == nil {
+		return
+	}
+	packetHooksLock.Lock()
+	defer packetHooksLock.Unlock()
+	var hooks []PacketHookFn
+	if cur := packetHooks.Load(); cur != nil {
+		hooks = append(hooks, *cur...)
+	}
+	hooks = append(hooks, hook)
+	packetHooks.Store(&hooks)
+}
+
+// runPacketHooks returns false if a hook dropped the packet, a panicking hook keeps the packet
+func runPacketHooks(pk *ds.PacketType) bool {
+	hooks := packetHooks.Load()
+	if hooks == nil || hookExemptTypes[pk.Type] {
+		return true
+	}
+	for _, hook := range *hooks {
+		if !runPacketHook(hook, pk) {
+			return false
+		}
+	}
+	return true
+}
+
+func runPacketHook(hook PacketHookFn, pk *ds.PacketType) (keep bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			keep = true
+		}
+	}()
+	return hook(pk)
+}
@@ -0,0 +1,239 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"sync"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+// Packet priorities for the outgoing queue. When the queue is full the oldest packet with the
+// lowest priority is dropped, so a slow monitor loses goroutine dumps before it loses log lines.
+const (
+	PacketPriorityLow    = iota // full goroutine dumps (the next dump supersedes them)
+	PacketPriorityNormal        // goroutine deltas, watches, runtime stats
+	PacketPriorityLog           // log lines
+	PacketPriorityHigh          // app info, collector status, panics, command results
+)
+
+// deltaChainTypes are packet types that are sent as deltas against the previous packet, when one of these
+// is dropped the later deltas of the same type are dropped too, until the collector sends a full update
+var deltaChainTypes = map[string]bool{
+	ds.PacketTypeGoroutine: true,
+	ds.PacketTypeWatch:     true,
+}
+
+// getPacketPriority returns the queue priority of a packet (PacketPriority*)
+func getPacketPriority(pk *ds.PacketType) int {
+	switch pk.Type {
+	case ds.PacketTypeLog, ds.PacketTypeMultiLog:
+		return PacketPriorityLog
+	case ds.PacketTypeGoroutine:
+		if info, ok := pk.Data.(*ds.GoroutineInfo); ok && !info.Delta {
+			return PacketPriorityLow
+		}
+		return PacketPriorityNormal
+	case ds.PacketTypeWatch, ds.PacketTypeRuntimeStats:
+		return PacketPriorityNormal
+	default:
+		return PacketPriorityHigh
+	}
+}
+
+type queuedPacket struct {
+	packet   packetWrap
+	pkType   string // original packet type ("log" for log line batches)
+	priority int
+	isDelta  bool // a delta against the previous packet of a delta chain type
+}
+
+// makeQueuedPacket wraps a marshaled packet for the queue
+func makeQueuedPacket(pk *ds.PacketType, packet packetWrap) queuedPacket {
+	item := queuedPacket{
+		packet:   packet,
+		pkType:   pk.Type,
+		priority: getPacketPriority(pk),
+	}
+	switch data := pk.Data.(type) {
+	case *ds.GoroutineInfo:
+		item.isDelta = data.Delta
+	case *ds.WatchInfo:
+		item.isDelta = data.Delta
+	}
+	return item
+}
+
+// PacketDropFn is called (without any queue locks held) when packets are dropped from a queue
+// count is the number of packets of pkType (for "log" the number of log lines)
+type PacketDropFn func(pkType string, count int)
+
+// packetQueue is the bounded outgoing queue of a connection, packets are sent in the order they were queued
+type packetQueue struct {
+	lock     sync.Mutex
+	items    []queuedPacket
+	maxSize  int
+	closed   bool
+	notifyCh chan struct{} // signaled when items are added or the queue is closed
+	onDrop   PacketDropFn
+
+	// brokenChains are the delta chain types with a dropped packet, new deltas of these types are dropped
+	// until a full packet of the type is queued
+	brokenChains map[string]bool
+}
+
+func makePacketQueue(maxSize int, onDrop PacketDropFn) *packetQueue {
+	return &packetQueue{
+		maxSize:      maxSize,
+		notifyCh:     make(chan struct{}, 1),
+		onDrop:       onDrop,
+		brokenChains: make(map[string]bool),
+	}
+}
+
+// push adds a packet, making room by dropping lower priority packets if needed
+// returns false if the packet itself was dropped (or the queue is closed)
+func (q *packetQueue) push(item queuedPacket) bool {
+	var dropped []queuedPacket
+	added := q.push_withDrops(item, &dropped)
+	for _, d := range dropped {
+		q.reportDrop(d)
+	}
+	return added
+}
+
+func (q *packetQueue) push_withDrops(item queuedPacket, dropped *[]queuedPacket) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return false
+	}
+	if item.isDelta && q.brokenChains[item.pkType] {
+		// the new packet is a delta against a dropped packet
+		*dropped = append(*dropped, item)
+		return false
+	}
+	if len(q.items) >= q.maxSize {
+		victimIdx := q.findVictim_nolock()
+		if victimIdx == -1 || q.items[victimIdx].priority > item.priority {
+			q.markChainBroken_nolock(item.pkType)
+			*dropped = append(*dropped, item)
+			return false
+		}
+		*dropped = append(*dropped, q.removeAt_nolock(victimIdx)...)
+		if item.isDelta && q.brokenChains[item.pkType] {
+			*dropped = append(*dropped, item)
+			return false
+		}
+	}
+	if !item.isDelta {
+		// a full packet starts a new chain
+		delete(q.brokenChains, item.pkType)
+	}
+	q.items = append(q.items, item)
+	select {
+	case q.notifyCh <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// findVictim_nolock returns the index of the oldest packet with the lowest priority (-1 if empty)
+func (q *packetQueue) findVictim_nolock() int {
+	victimIdx := -1
+	for idx, item := range q.items {
+		if victimIdx == -1 || item.priority < q.items[victimIdx].priority {
+			victimIdx = idx
+		}
+	}
+	return victimIdx
+}
+
+// removeAt_nolock removes the packet at idx, and for delta chain types the deltas queued after it
+// (up to the next full packet of the same type, the chain stays broken if there is none)
+func (q *packetQueue) removeAt_nolock(idx int) []queuedPacket {
+	victim := q.items[idx]
+	removed := []queuedPacket{victim}
+	chainBroken := deltaChainTypes[victim.pkType]
+	kept := q.items[:idx]
+	for _, item := range q.items[idx+1:] {
+		if chainBroken && item.pkType == victim.pkType {
+			if item.isDelta {
+				removed = append(removed, item)
+				continue
+			}
+			chainBroken = false
+		}
+		kept = append(kept, item)
+	}
+	clear(q.items[len(kept):])
+	q.items = kept
+	if chainBroken {
+		q.brokenChains[victim.pkType] = true
+	}
+	return removed
+}
+
+// markChainBroken_nolock drops the deltas of pkType queued from now on (until the next full packet of the type)
+func (q *packetQueue) markChainBroken_nolock(pkType string) {
+	if deltaChainTypes[pkType] {
+		q.brokenChains[pkType] = true
+	}
+}
+
+// markChainBroken is markChainBroken_nolock for packets dropped before they reach the queue (by packet hooks)
+func (q *packetQueue) markChainBroken(pkType string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.markChainBroken_nolock(pkType)
+}
+
+func (q *packetQueue) reportDrop(item queuedPacket) {
+	count := 1
+	if item.packet.MultiLog {
+		count = item.packet.LogBatch.markDropped()
+	}
+	if q.onDrop != nil {
+		q.onDrop(item.pkType, count)
+	}
+}
+
+// pop blocks until a packet is available, returns false once the queue is closed
+func (q *packetQueue) pop() (packetWrap, bool) {
+	for {
+		q.lock.Lock()
+		if q.closed {
+			q.lock.Unlock()
+			return packetWrap{}, false
+		}
+		if len(q.items) > 0 {
+			item := q.items[0]
+			q.items[0] = queuedPacket{}
+			q.items = q.items[1:]
+			q.lock.Unlock()
+			return item.packet, true
+		}
+		q.lock.Unlock()
+		<-q.notifyCh
+	}
+}
+
+// close stops the queue, queued packets are discarded
+func (q *packetQueue) close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	q.items = nil
+	close(q.notifyCh)
+}
+
+// size returns the number of queued packets
+func (q *packetQueue) size() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.items)
+}
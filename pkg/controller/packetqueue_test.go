@@ -0,0 +1,169 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+type dropRecorder struct {
+	dropped map[string]int
+}
+
+func (r *dropRecorder) onDrop(pkType string, count int) {
+	r.dropped[pkType] += count
+}
+
+func makeTestQueue(maxSize int) (*packetQueue, *dropRecorder) {
+	rec := &dropRecorder{dropped: make(map[string]int)}
+	return makePacketQueue(maxSize, rec.onDrop), rec
+}
+
+func goroutinePacket(name string, delta bool) queuedPacket {
+	pk := &ds.PacketType{Type: ds.PacketTypeGoroutine, Data: &ds.GoroutineInfo{Delta: delta}}
+	return makeQueuedPacket(pk, packetWrap{RawPacket: name})
+}
+
+func logPacket(lines int) queuedPacket {
+	batch := &logBatch{lock: &sync.Mutex{}, lines: make([]ds.LogLine, lines)}
+	return queuedPacket{packet: packetWrap{MultiLog: true, LogBatch: batch}, pkType: ds.PacketTypeLog, priority: PacketPriorityLog}
+}
+
+func appInfoPacket() queuedPacket {
+	return makeQueuedPacket(&ds.PacketType{Type: ds.PacketTypeAppInfo}, packetWrap{RawPacket: "appinfo"})
+}
+
+func drainQueue(q *packetQueue) []packetWrap {
+	var rtn []packetWrap
+	for q.size() > 0 {
+		packet, _ := q.pop()
+		rtn = append(rtn, packet)
+	}
+	return rtn
+}
+
+func TestPacketQueueDropsGoroutineDumpsBeforeLogs(t *testing.T) {
+	q, rec := makeTestQueue(3)
+	q.push(goroutinePacket("full1", false))
+	q.push(logPacket(10))
+	q.push(logPacket(10))
+	if !q.push(logPacket(10)) {
+		t.Fatalf("log packet should replace the goroutine dump")
+	}
+	if rec.dropped[ds.PacketTypeGoroutine] != 1 || rec.dropped[ds.PacketTypeLog] != 0 {
+		t.Errorf("unexpected drops %v", rec.dropped)
+	}
+
+	// a full queue of log lines drops the oldest log batch (counting its lines) for a new high priority packet
+	if !q.push(appInfoPacket()) {
+		t.Fatalf("appinfo packet should be queued")
+	}
+	if rec.dropped[ds.PacketTypeLog] != 10 {
+		t.Errorf("expected 10 dropped log lines, got %v", rec.dropped)
+	}
+
+	// a low priority packet is dropped itself when everything queued is more important
+	if q.push(goroutinePacket("full2", false)) {
+		t.Errorf("goroutine dump should not replace log lines")
+	}
+	packets := drainQueue(q)
+	if len(packets) != 3 || packets[2].RawPacket != "appinfo" {
+		t.Errorf("expected the queue to keep its order, got %v", packets)
+	}
+}
+
+func TestPacketQueueDropsDeltaChain(t *testing.T) {
+	q, rec := makeTestQueue(4)
+	q.push(goroutinePacket("full1", false))
+	q.push(goroutinePacket("delta1", true))
+	q.push(goroutinePacket("full2", false))
+	q.push(goroutinePacket("delta2", true))
+
+	// dropping full1 also drops delta1 (it is a delta against full1), full2 starts a new chain
+	if !q.push(appInfoPacket()) {
+		t.Fatalf("appinfo packet should be queued")
+	}
+	if rec.dropped[ds.PacketTypeGoroutine] != 2 {
+		t.Errorf("expected 2 dropped goroutine packets, got %v", rec.dropped)
+	}
+	var names []string
+	for _, packet := range drainQueue(q) {
+		names = append(names, packet.RawPacket)
+	}
+	expected := []string{"full2", "delta2", "appinfo"}
+	if len(names) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, names)
+			break
+		}
+	}
+}
+
+func TestPacketQueueMarksDroppedLogBatch(t *testing.T) {
+	q, _ := makeTestQueue(1)
+	logItem := logPacket(5)
+	q.push(logItem)
+	q.push(appInfoPacket())
+	if !logItem.packet.LogBatch.dropped {
+		t.Errorf("dropped log batch should be marked so no more lines are added to it")
+	}
+}
+
+func TestPacketQueueClose(t *testing.T) {
+	q, _ := makeTestQueue(2)
+	done := make(chan bool)
+	go func() {
+		_, ok := q.pop()
+		done <- ok
+	}()
+	q.close()
+	if ok := <-done; ok {
+		t.Errorf("pop should return false after close")
+	}
+	if q.push(appInfoPacket()) {
+		t.Errorf("push should fail after close")
+	}
+}
+
+func TestPacketQueueDropsDeltasUntilFullUpdate(t *testing.T) {
+	q, rec := makeTestQueue(2)
+	q.push(goroutinePacket("full1", false))
+	q.push(goroutinePacket("delta1", true))
+
+	// dropping full1 for the appinfo packet breaks the chain, the deltas queued after that are dropped too
+	if !q.push(appInfoPacket()) {
+		t.Fatalf("appinfo packet should be queued")
+	}
+	if q.push(goroutinePacket("delta2", true)) {
+		t.Errorf("a delta against a dropped packet should not be queued")
+	}
+	if rec.dropped[ds.PacketTypeGoroutine] != 3 {
+		t.Errorf("expected 3 dropped goroutine packets, got %v", rec.dropped)
+	}
+	drainQueue(q)
+
+	// the full update requested by the collector restarts the chain
+	if !q.push(goroutinePacket("full2", false)) || !q.push(goroutinePacket("delta3", true)) {
+		t.Fatalf("the full update and its deltas should be queued")
+	}
+
+	// deltas after a packet dropped by a packet hook are dropped until the next full update
+	q.markChainBroken(ds.PacketTypeGoroutine)
+	if q.push(goroutinePacket("delta4", true)) {
+		t.Errorf("a delta after a hook dropped packet should not be queued")
+	}
+	var names []string
+	for _, packet := range drainQueue(q) {
+		names = append(names, packet.RawPacket)
+	}
+	if len(names) != 2 || names[0] != "full2" || names[1] != "delta3" {
+		t.Errorf("expected [full2 delta3], got %v", names)
+	}
+}
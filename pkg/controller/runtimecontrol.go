@@ -0,0 +1,120 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+// RuntimeControlLogSource is the log source of the audit lines written for runtime control commands
+const RuntimeControlLogSource = "outrig"
+
+// handleRuntimeControl runs a runtime control command from the server, writes an audit line
+// to the app's log stream, and sends the result back to the server
+func (c *ControllerImpl) handleRuntimeControl(data ds.RuntimeControlData) {
+	c.Lock.Lock()
+	appName := c.AppInfo.AppName
+	c.Lock.Unlock()
+	result := runRuntimeControl(data, appName)
+	c.sendRuntimeControlAuditLog(data, result)
+	c.transport.SendPacket(&ds.PacketType{
+		Type: ds.PacketTypeRuntimeControlResult,
+		Data: result,
+	}, true)
+}
+
+func runRuntimeControl(data ds.RuntimeControlData, appName string) ds.RuntimeControlResult {
+	result := ds.RuntimeControlResult{
+		CommandId: data.CommandId,
+		Action:    data.Action,
+	}
+	var memBefore, memAfter runtime.MemStats
+	runtime.ReadMemStats(&memBefore)
+	startTime := time.Now()
+	var err error
+	switch data.Action {
+	case ds.RuntimeControlActionGC:
+		runtime.GC()
+	case ds.RuntimeControlActionFreeOSMemory:
+		debug.FreeOSMemory()
+	case ds.RuntimeControlActionHeapDump:
+		result.Path, result.FileSize, err = writeHeapDumpFile(data.Path, appName)
+	case ds.RuntimeControlActionSetGCPercent:
+		result.OldGCPercent = debug.SetGCPercent(data.GCPercent)
+		result.NewGCPercent = data.GCPercent
+	default:
+		err = fmt.Errorf("invalid runtime control action: %s", data.Action)
+	}
+	result.DurationMs = time.Since(startTime).Milliseconds()
+	result.Ts = time.Now().UnixMilli()
+	if err != nil {
+		result.Error = err.Error()
+	}
+	runtime.ReadMemStats(&memAfter)
+	result.HeapAllocBefore = memBefore.HeapAlloc
+	result.HeapAllocAfter = memAfter.HeapAlloc
+	result.HeapReleasedBefore = memBefore.HeapReleased
+	result.HeapReleasedAfter = memAfter.HeapReleased
+	return result
+}
+
+// writeHeapDumpFile writes a heap dump to path (or to a new file in the temp dir if path is empty).
+// The file must not exist yet so a command can never overwrite an existing file.
+func writeHeapDumpFile(path string, appName string) (string, int64, error) {
+	if path == "" {
+		path = filepath.Join(os.TempDir(), fmt.Sprintf("outrig-heapdump-%s-%d-%d.dump", appName, os.Getpid(), time.Now().UnixMilli()))
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return path, 0, fmt.Errorf("invalid heap dump path: %w", err)
+	}
+	fd, err := os.OpenFile(absPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return absPath, 0, fmt.Errorf("cannot create heap dump file: %w", err)
+	}
+	defer fd.Close()
+	if err := writeHeapDump(fd); err != nil {
+		return absPath, 0, err
+	}
+	info, err := fd.Stat()
+	if err != nil {
+		return absPath, 0, nil
+	}
+	return absPath, info.Size(), nil
+}
+
+func (c *ControllerImpl) sendRuntimeControlAuditLog(data ds.RuntimeControlData, result ds.RuntimeControlResult) {
+	triggeredBy := data.TriggeredBy
+	if triggeredBy == "" {
+		triggeredBy = "unknown"
+	}
+	msg := fmt.Sprintf("[outrig] runtime control %q triggered by %s", data.Action, triggeredBy)
+	switch data.Action {
+	case ds.RuntimeControlActionHeapDump:
+		msg += fmt.Sprintf(" path=%s size=%d", result.Path, result.FileSize)
+	case ds.RuntimeControlActionSetGCPercent:
+		msg += fmt.Sprintf(" gcpercent=%d (was %d)", result.NewGCPercent, result.OldGCPercent)
+	}
+	if result.Error != "" {
+		msg += fmt.Sprintf(" failed: %s", result.Error)
+	} else {
+		msg += fmt.Sprintf(" (%dms, heapalloc %d -> %d)", result.DurationMs, result.HeapAllocBefore, result.HeapAllocAfter)
+	}
+	c.ILog("%s", msg)
+	c.transport.SendPacket(&ds.PacketType{
+		Type: ds.PacketTypeLog,
+		Data: &ds.LogLine{
+			Ts:     result.Ts,
+			Msg:    msg + "\n",
+			Source: RuntimeControlLogSource,
+		},
+	}, true)
+}
@@ -0,0 +1,54 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/collector/watch"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/ioutrig"
+)
+
+// handleSetWatchValue calls the setter of a settable watch (in its own goroutine, the setter is
+// user code and may block), writes an audit line to the app's log stream, and sends the result back
+func (c *ControllerImpl) handleSetWatchValue(data ds.SetWatchValueData) {
+	go func() {
+		ioutrig.I.SetGoRoutineNameAndTags("SetWatchValue", "outrig")
+		result := ds.SetWatchValueResult{
+			CommandId: data.CommandId,
+			Name:      data.Name,
+			Ts:        time.Now().UnixMilli(),
+		}
+		if err := watch.GetInstance().SetWatchValue(data.Name, data.Value); err != nil {
+			result.Error = err.Error()
+		}
+		c.sendSetWatchValueAuditLog(data, result)
+		c.transport.SendPacket(&ds.PacketType{
+			Type: ds.PacketTypeSetWatchValueResult,
+			Data: result,
+		}, true)
+	}()
+}
+
+func (c *ControllerImpl) sendSetWatchValueAuditLog(data ds.SetWatchValueData, result ds.SetWatchValueResult) {
+	triggeredBy := data.TriggeredBy
+	if triggeredBy == "" {
+		triggeredBy = "unknown"
+	}
+	msg := fmt.Sprintf("[outrig] set watch %q to %s triggered by %s", data.Name, data.Value, triggeredBy)
+	if result.Error != "" {
+		msg += fmt.Sprintf(" failed: %s", result.Error)
+	}
+	c.ILog("%s", msg)
+	c.transport.SendPacket(&ds.PacketType{
+		Type: ds.PacketTypeLog,
+		Data: &ds.LogLine{
+			Ts:     result.Ts,
+			Msg:    msg + "\n",
+			Source: RuntimeControlLogSource,
+		},
+	}, true)
+}
@@ -15,13 +15,15 @@ import (
 	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/global"
 	"github.com/outrigdev/outrig/pkg/ioutrig"
+	"github.com/outrigdev/outrig/pkg/redact"
+	"github.com/outrigdev/outrig/pkg/utilds"
 )
 
 // Global counters for transport statistics
 var TransportPacketsQueued int64
 var TransportDroppedPackets int64
 
-const TransportPeerBufferSize = 100
+const TransportPeerBufferSize = 100    // max packets queued per connection (see packetQueue)
 const WriteDeadline = 10 * time.Second // this is very high, just helps to clear out hung connections, not for real flow control
 const LogBatchSize = 100
 
@@ -29,39 +31,149 @@ const LogBatchSize = 100
 type packetWrap struct {
 	RawPacket string
 	MultiLog  bool
-	LogLines  *[]ds.LogLine
+	LogBatch  *logBatch
 }
 
-// transportPeer wraps a comm.ConnWrap with a buffered channel for packet sending
+// logBatch collects log lines for a multilog packet while it waits in the send queue
+// lines and dropped are guarded by the peer's multiLogLock
+type logBatch struct {
+	lock    *sync.Mutex
+	lines   []ds.LogLine
+	dropped bool
+}
+
+// markDropped marks the batch as dropped (no more lines are added) and returns its number of lines
+func (b *logBatch) markDropped() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.dropped = true
+	return len(b.lines)
+}
+
+// transportPeer wraps a comm.ConnWrap with a bounded priority queue for packet sending
 type transportPeer struct {
 	Conn         *comm.ConnWrap
-	SendCh       chan packetWrap
+	Features     ds.ProtocolFeatures // negotiated in the handshake, packets the server can't parse are not sent
+	SendQueue    *packetQueue
+	pending      []string // packets buffered while disconnected, written before anything in SendQueue
 	multiLogLock sync.Mutex
-	logLines     *[]ds.LogLine
+	logBatch     *logBatch // the batch that new log lines are added to (nil after it was sent or dropped)
+}
+
+// PacketHandlerFn handles a packet sent from the server
+type PacketHandlerFn func(pkType string, data json.RawMessage)
+
+// incomingPacket is the envelope for packets sent from the server
+type incomingPacket struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
 }
 
 // Transport handles connection management and packet sending functionality
 type Transport struct {
-	lock    sync.Mutex
-	connMap map[string]*transportPeer // map of connections by peer name
-	config  *config.Config
+	lock          sync.Mutex
+	connMap       map[string]*transportPeer // map of connections by peer name
+	config        *config.Config
+	packetHandler PacketHandlerFn // handles packets sent from the server (may be nil)
+
+	// pendingBuf holds marshaled packets while disconnected from a remote monitor (nil when not buffering)
+	// when full the oldest packets are dropped
+	pendingBuf *utilds.CirBuf[string]
+
+	statsLock    sync.Mutex
+	dropped      map[string]int64 // packets dropped from the send queues by packet type ("log" counts log lines)
+	totalDropped int64
+	brokenChains map[string]bool // delta chain packet types with drops (the collectors need to send a full update)
 }
 
 // MakeTransport creates a new Transport instance
 func MakeTransport(cfg *config.Config) *Transport {
-	return &Transport{
-		connMap: make(map[string]*transportPeer),
-		config:  cfg,
+	t := &Transport{
+		connMap:      make(map[string]*transportPeer),
+		config:       cfg,
+		dropped:      make(map[string]int64),
+		brokenChains: make(map[string]bool),
 	}
+	if comm.IsRemoteMode(cfg) && cfg.Remote.BufferSize > 0 {
+		t.pendingBuf = utilds.MakeCirBuf[string](cfg.Remote.BufferSize)
+	}
+	return t
+}
+
+// SetPacketHandler sets the handler for packets sent from the server
+func (t *Transport) SetPacketHandler(handler PacketHandlerFn) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.packetHandler = handler
 }
 
 // makeTransportPeer creates a new TransportPeer instance
-func makeTransportPeer(conn *comm.ConnWrap) *transportPeer {
+func (t *Transport) makeTransportPeer(conn *comm.ConnWrap) *transportPeer {
 	return &transportPeer{
-		Conn:     conn,
-		SendCh:   make(chan packetWrap, TransportPeerBufferSize),
-		logLines: nil,
+		Conn:      conn,
+		Features:  conn.GetProtocolFeatures(),
+		SendQueue: makePacketQueue(TransportPeerBufferSize, t.recordDrop),
+	}
+}
+
+// recordDrop counts packets dropped from a send queue (called by packetQueue)
+func (t *Transport) recordDrop(pkType string, count int) {
+	atomic.AddInt64(&TransportDroppedPackets, 1)
+	t.statsLock.Lock()
+	defer t.statsLock.Unlock()
+	t.dropped[pkType] += int64(count)
+	t.totalDropped += int64(count)
+	if deltaChainTypes[pkType] {
+		t.brokenChains[pkType] = true
+	}
+}
+
+// GetTransportStats returns the drop counters of the send queues
+func (t *Transport) GetTransportStats() ds.TransportStats {
+	t.statsLock.Lock()
+	defer t.statsLock.Unlock()
+	stats := ds.TransportStats{
+		Ts:           time.Now().UnixMilli(),
+		QueueCap:     TransportPeerBufferSize,
+		TotalDropped: t.totalDropped,
+		Redacted:     redact.GetRedactedCount(),
+	}
+	if len(t.dropped) > 0 {
+		stats.Dropped = make(map[string]int64, len(t.dropped))
+		for pkType, count := range t.dropped {
+			stats.Dropped[pkType] = count
+		}
+	}
+	return stats
+}
+
+// markChainBroken makes the collector send a full update next if pkType is a delta chain type, the send
+// queues drop its deltas until then (used when a packet hook drops a packet, the following deltas would not
+// apply on the server)
+func (t *Transport) markChainBroken(pkType string) {
+	if !deltaChainTypes[pkType] {
+		return
+	}
+	t.lock.Lock()
+	for _, peer := range t.connMap {
+		peer.SendQueue.markChainBroken(pkType)
 	}
+	t.lock.Unlock()
+	t.statsLock.Lock()
+	defer t.statsLock.Unlock()
+	t.brokenChains[pkType] = true
+}
+
+// takeBrokenChains returns (and resets) the delta chain packet types that had packets dropped
+func (t *Transport) takeBrokenChains() []string {
+	t.statsLock.Lock()
+	defer t.statsLock.Unlock()
+	var rtn []string
+	for pkType := range t.brokenChains {
+		rtn = append(rtn, pkType)
+	}
+	clear(t.brokenChains)
+	return rtn
 }
 
 // IsConnected returns true if there are any active connections
@@ -75,7 +187,20 @@ func (t *Transport) HasConnections() bool {
 func (t *Transport) startPeerLoop(peer *transportPeer) {
 	go func() {
 		ioutrig.I.SetGoRoutineNameAndTags("TransportPeerLoop", "outrig")
-		for packet := range peer.SendCh {
+		for _, line := range peer.pending {
+			peer.Conn.Conn.SetWriteDeadline(time.Now().Add(WriteDeadline))
+			err := peer.Conn.WriteLine(line)
+			if err != nil {
+				t.closeConn(peer, err)
+				return
+			}
+		}
+		peer.pending = nil
+		for {
+			packet, ok := peer.SendQueue.pop()
+			if !ok {
+				return
+			}
 			peer.Conn.Conn.SetWriteDeadline(time.Now().Add(WriteDeadline))
 
 			var jsonStr string
@@ -83,7 +208,7 @@ func (t *Transport) startPeerLoop(peer *transportPeer) {
 
 			if packet.MultiLog {
 				// For multilog packets, marshal the packet just before sending
-				jsonStr, err = peer.marshalMultiLogPacket(packet.LogLines)
+				jsonStr, err = peer.marshalMultiLogPacket(packet.LogBatch)
 				if err != nil {
 					// If there's an error marshaling, skip this packet
 					continue
@@ -114,9 +239,63 @@ func (t *Transport) AddConn(conn *comm.ConnWrap) {
 		t.closeConn_nolock(existingPeer, nil)
 	}
 
-	peer := makeTransportPeer(conn)
+	peer := t.makeTransportPeer(conn)
+	if t.pendingBuf != nil && len(t.connMap) == 0 {
+		for {
+			line, ok := t.pendingBuf.Read()
+			if !ok {
+				break
+			}
+			if !peer.Features.SupportsPacketType(getPacketLineType(line)) {
+				continue
+			}
+			peer.pending = append(peer.pending, line)
+		}
+	}
 	t.connMap[conn.PeerName] = peer
 	t.startPeerLoop(peer)
+	t.startReadLoop(peer, t.packetHandler)
+}
+
+// getPacketLineType returns the type of a marshaled packet
+func getPacketLineType(line string) string {
+	var pk incomingPacket
+	json.Unmarshal([]byte(line), &pk)
+	return pk.Type
+}
+
+// GetProtocolFeatures returns the protocol features supported by all the connections
+// (the features of this build when there are no connections)
+func (t *Transport) GetProtocolFeatures() ds.ProtocolFeatures {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	rtn := comm.LocalProtocolFeatures()
+	for _, peer := range t.connMap {
+		rtn = comm.NegotiateProtocolFeatures(rtn, peer.Features)
+	}
+	return rtn
+}
+
+// startReadLoop starts a goroutine to read packets sent from the server (e.g. collector admin commands)
+// the read loop also notices when the server closes the connection
+func (t *Transport) startReadLoop(peer *transportPeer, handler PacketHandlerFn) {
+	go func() {
+		ioutrig.I.SetGoRoutineNameAndTags("TransportReadLoop", "outrig")
+		for {
+			line, err := peer.Conn.ReadLine()
+			if err != nil {
+				t.closeConn(peer, err)
+				return
+			}
+			var pk incomingPacket
+			if err := json.Unmarshal([]byte(line), &pk); err != nil || pk.Type == "" {
+				continue
+			}
+			if handler != nil {
+				handler(pk.Type, pk.Data)
+			}
+		}
+	}()
 }
 
 // closeConn_nolock closes a connection and removes it from the connection map
@@ -125,9 +304,8 @@ func (t *Transport) closeConn_nolock(peer *transportPeer, err error) {
 	if peer == nil || peer.Conn == nil {
 		return
 	}
-	_, found := t.connMap[peer.Conn.PeerName]
-	if !found {
-		// if not found, then we already closed everything, avoids double close errors
+	if t.connMap[peer.Conn.PeerName] != peer {
+		// if not found (or replaced by a new connection), then we already closed everything, avoids double close errors
 		return
 	}
 
@@ -138,8 +316,8 @@ func (t *Transport) closeConn_nolock(peer *transportPeer, err error) {
 			fmt.Printf("#outrig disconnecting from %s\n", peer.Conn.PeerName)
 		}
 	}
-	// Close the channel to stop the goroutine
-	close(peer.SendCh)
+	// Close the queue to stop the goroutine
+	peer.SendQueue.close()
 
 	// Close the connection
 	peer.Conn.Close()
@@ -162,7 +340,7 @@ func (t *Transport) CloseAllConns() {
 }
 
 // marshalMultiLogPacket marshals a multilog packet to JSON
-func (p *transportPeer) marshalMultiLogPacket(logLines *[]ds.LogLine) (string, error) {
+func (p *transportPeer) marshalMultiLogPacket(batch *logBatch) (string, error) {
 	p.multiLogLock.Lock()
 	defer p.multiLogLock.Unlock()
 
@@ -170,7 +348,7 @@ func (p *transportPeer) marshalMultiLogPacket(logLines *[]ds.LogLine) (string, e
 	multiLogPacket := &ds.PacketType{
 		Type: ds.PacketTypeMultiLog,
 		Data: &ds.MultiLogLines{
-			LogLines: *logLines,
+			LogLines: batch.lines,
 		},
 	}
 
@@ -180,9 +358,9 @@ func (p *transportPeer) marshalMultiLogPacket(logLines *[]ds.LogLine) (string, e
 		return "", err
 	}
 
-	// If this is our current logLines, clear it
-	if logLines == p.logLines {
-		p.logLines = nil
+	// If this is our current batch, clear it
+	if batch == p.logBatch {
+		p.logBatch = nil
 	}
 
 	return string(barr), nil
@@ -209,50 +387,76 @@ func (p *transportPeer) addLogLine(pk *ds.PacketType) bool {
 	}
 
 	p.multiLogLock.Lock()
-	defer p.multiLogLock.Unlock()
-
-	// If we don't have a multilog packet in the queue yet or past buffer size, create one
-	if p.logLines == nil || len(*p.logLines) >= LogBatchSize {
-		// Create a new log lines slice
-		logLines := make([]ds.LogLine, 0, LogBatchSize)
-		logLines = append(logLines, logData)
-
-		// Create the packet wrap
-		packet := packetWrap{
-			MultiLog: true,
-			LogLines: &logLines,
-		}
-
-		// Store the log lines pointer
-		p.logLines = &logLines
-
-		// Send the packet to the channel
-		sent := sendNonBlock(p.SendCh, packet)
-		if !sent {
-			// Channel is full, clear the log lines
-			p.logLines = nil
-		}
-		return sent
+	// We already have a multilog packet in the queue, just append the log line
+	if p.logBatch != nil && !p.logBatch.dropped && len(p.logBatch.lines) < LogBatchSize {
+		p.logBatch.lines = append(p.logBatch.lines, logData)
+		p.multiLogLock.Unlock()
+		return true
+	}
+	// Otherwise start a new batch
+	batch := &logBatch{
+		lock:  &p.multiLogLock,
+		lines: make([]ds.LogLine, 0, LogBatchSize),
 	}
+	batch.lines = append(batch.lines, logData)
+	p.logBatch = batch
+	p.multiLogLock.Unlock()
+
+	// queue it without holding multiLogLock (dropping a batch takes that lock)
+	item := queuedPacket{
+		packet:   packetWrap{MultiLog: true, LogBatch: batch},
+		pkType:   ds.PacketTypeLog,
+		priority: PacketPriorityLog,
+	}
+	return queuePacket(p.SendQueue, item)
+}
 
-	// We already have a multilog packet in the queue, just append the log line
-	*p.logLines = append(*p.logLines, logData)
-	return true
+// redactLogPacket returns the log packet with its message redacted (see config.RedactConfig),
+// a new packet is returned if the message changed (the caller's log line is not modified)
+func redactLogPacket(pk *ds.PacketType) *ds.PacketType {
+	var logLine ds.LogLine
+	if data, ok := pk.Data.(ds.LogLine); ok {
+		logLine = data
+	} else if ptrData, ok := pk.Data.(*ds.LogLine); ok && ptrData != nil {
+		logLine = *ptrData
+	} else {
+		return pk
+	}
+	redactedMsg := redact.RedactString(logLine.Msg)
+	if redactedMsg == logLine.Msg {
+		return pk
+	}
+	logLine.Msg = redactedMsg
+	return &ds.PacketType{Type: pk.Type, Data: &logLine}
 }
 
 // SendPacketInternal sends a packet to all available connections
 // This is an internal method that doesn't check if Outrig is enabled
 func (t *Transport) sendPacketInternal(pk *ds.PacketType) (bool, error) {
+	if !runPacketHooks(pk) {
+		t.markChainBroken(pk.Type)
+		return false, nil
+	}
 	isLogPacket := pk.Type == ds.PacketTypeLog
+	if isLogPacket {
+		pk = redactLogPacket(pk)
+	}
 
 	t.lock.Lock()
 	defer t.lock.Unlock()
 	if len(t.connMap) == 0 {
-		return false, nil
+		return t.bufferPacket_nolock(pk), nil
 	}
 
+	sendType := pk.Type
+	if isLogPacket {
+		sendType = ds.PacketTypeMultiLog // log lines are sent in multilog packets
+	}
 	sentToAny := false
 	for _, peer := range t.connMap {
+		if !peer.Features.SupportsPacketType(sendType) {
+			continue
+		}
 		if isLogPacket {
 			// For log packets, just add to the peer's multilog packet
 			if peer.addLogLine(pk) {
@@ -265,13 +469,8 @@ func (t *Transport) sendPacketInternal(pk *ds.PacketType) (bool, error) {
 				continue
 			}
 
-			packet := packetWrap{
-				RawPacket: string(barr),
-				MultiLog:  false,
-				LogLines:  nil,
-			}
-
-			if sendNonBlock(peer.SendCh, packet) {
+			item := makeQueuedPacket(pk, packetWrap{RawPacket: string(barr)})
+			if queuePacket(peer.SendQueue, item) {
 				sentToAny = true
 			}
 		}
@@ -279,6 +478,27 @@ func (t *Transport) sendPacketInternal(pk *ds.PacketType) (bool, error) {
 	return sentToAny, nil
 }
 
+// bufferPacket_nolock stores a packet in the pending buffer while disconnected from a remote monitor
+// Caller must hold the lock
+func (t *Transport) bufferPacket_nolock(pk *ds.PacketType) bool {
+	if t.pendingBuf == nil {
+		return false
+	}
+	// appinfo, configinfo, and collector status are re-sent on every connect, no need to buffer them
+	if pk.Type == ds.PacketTypeAppInfo || pk.Type == ds.PacketTypeConfigInfo || pk.Type == ds.PacketTypeCollectorStatus {
+		return false
+	}
+	barr, err := json.Marshal(pk)
+	if err != nil {
+		return false
+	}
+	if t.pendingBuf.Write(string(barr)) != nil {
+		atomic.AddInt64(&TransportDroppedPackets, 1)
+	}
+	atomic.AddInt64(&TransportPacketsQueued, 1)
+	return true
+}
+
 // SendPacket sends a packet if Outrig is enabled
 func (t *Transport) SendPacket(pk *ds.PacketType, force bool) (bool, error) {
 	if !force && !global.OutrigEnabled.Load() {
@@ -290,16 +510,13 @@ func (t *Transport) SendPacket(pk *ds.PacketType, force bool) (bool, error) {
 // Note: This implementation uses the printf and isStdoutATerminal functions
 // from controller.go to avoid redeclaration issues
 
-// sendNonBlock attempts to send a packet to a channel without blocking
-// Returns true if the send was successful, false if the channel is full
-// Also updates the global packet counters
-func sendNonBlock(ch chan packetWrap, packet packetWrap) bool {
-	select {
-	case ch <- packet:
-		atomic.AddInt64(&TransportPacketsQueued, 1)
-		return true
-	default:
-		atomic.AddInt64(&TransportDroppedPackets, 1)
+// queuePacket adds a packet to a send queue without blocking (lower priority packets are dropped when
+// the queue is full, see packetQueue). Returns false if the packet was dropped.
+// Also updates the global packet counters (drops are counted by Transport.recordDrop)
+func queuePacket(q *packetQueue, item queuedPacket) bool {
+	if !q.push(item) {
 		return false
 	}
+	atomic.AddInt64(&TransportPacketsQueued, 1)
+	return true
 }
@@ -0,0 +1,152 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/comm"
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func TestTransportSkipsUnsupportedPacketTypes(t *testing.T) {
+	transport := MakeTransport(&config.Config{Quiet: true})
+	clientConn, serverConn := net.Pipe()
+	defer serverConn.Close()
+	// no negotiated features in the handshake response: an older monitor
+	connWrap := comm.MakeConnWrap(clientConn, "test")
+	connWrap.ServerResponse = &comm.ServerHandshakeResponse{Success: true}
+	transport.AddConn(connWrap)
+	defer transport.CloseAllConns()
+
+	if features := transport.GetProtocolFeatures(); features.SupportsPacketType(ds.PacketTypeGoroutineChurn) || features.DeltaVersion != ds.DeltaVersionBase {
+		t.Errorf("expected the legacy features, got %+v", features)
+	}
+	if sent, _ := transport.sendPacketInternal(&ds.PacketType{Type: ds.PacketTypeGoroutineChurn, Data: &ds.GoroutineChurnInfo{}}); sent {
+		t.Errorf("goroutinechurn packet should not be sent to an older monitor")
+	}
+	if sent, _ := transport.sendPacketInternal(&ds.PacketType{Type: ds.PacketTypeWatch, Data: &ds.WatchInfo{}}); !sent {
+		t.Errorf("watch packet should be sent")
+	}
+	line, err := comm.MakeConnWrap(serverConn, "server").ReadLine()
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	var pk incomingPacket
+	if err := json.Unmarshal([]byte(line), &pk); err != nil || pk.Type != ds.PacketTypeWatch {
+		t.Errorf("expected the watch packet, got %q (err:%v)", line, err)
+	}
+}
+
+func TestNegotiateProtocolFeatures(t *testing.T) {
+	sdkFeatures := comm.LocalProtocolFeatures()
+	serverFeatures := ds.ProtocolFeatures{
+		PacketTypes:  []string{ds.PacketTypeMultiLog, ds.PacketTypeWatch, "newtype"},
+		DeltaVersion: ds.DeltaVersionBase,
+	}
+	negotiated := comm.NegotiateProtocolFeatures(sdkFeatures, serverFeatures)
+	if len(negotiated.PacketTypes) != 2 || !negotiated.SupportsPacketType(ds.PacketTypeWatch) || negotiated.SupportsPacketType("newtype") {
+		t.Errorf("unexpected packet types: %v", negotiated.PacketTypes)
+	}
+	if negotiated.DeltaVersion != ds.DeltaVersionBase {
+		t.Errorf("expected delta version %d, got %d", ds.DeltaVersionBase, negotiated.DeltaVersion)
+	}
+}
+
+func TestTransportPacketHooks(t *testing.T) {
+	defer packetHooks.Store(nil)
+	transport := MakeTransport(&config.Config{Quiet: true})
+	clientConn, serverConn := net.Pipe()
+	defer serverConn.Close()
+	connWrap := comm.MakeConnWrap(clientConn, "test")
+	connWrap.ServerResponse = &comm.ServerHandshakeResponse{Success: true}
+	transport.AddConn(connWrap)
+	defer transport.CloseAllConns()
+	go io.Copy(io.Discard, serverConn)
+
+	var numSeen atomic.Int64
+	AddPacketHook(func(pk *ds.PacketType) bool {
+		numSeen.Add(1)
+		if logLine, ok := pk.Data.(*ds.LogLine); ok {
+			return logLine.Msg != "drop me"
+		}
+		if watchInfo, ok := pk.Data.(*ds.WatchInfo); ok {
+			for idx := range watchInfo.Decls {
+				watchInfo.Decls[idx].Tags = append(watchInfo.Decls[idx].Tags, "team:payments")
+			}
+		}
+		return pk.Type != ds.PacketTypeGoroutine
+	})
+	AddPacketHook(func(pk *ds.PacketType) bool {
+		panic("hook panics are recovered")
+	})
+
+	if sent, _ := transport.sendPacketInternal(&ds.PacketType{Type: ds.PacketTypeLog, Data: &ds.LogLine{Msg: "drop me"}}); sent {
+		t.Errorf("the log line should be dropped by the hook")
+	}
+	if sent, _ := transport.sendPacketInternal(&ds.PacketType{Type: ds.PacketTypeLog, Data: &ds.LogLine{Msg: "keep me"}}); !sent {
+		t.Errorf("the log line should be sent")
+	}
+	watchInfo := &ds.WatchInfo{Decls: []ds.WatchDecl{{Name: "orders"}}}
+	if sent, _ := transport.sendPacketInternal(&ds.PacketType{Type: ds.PacketTypeWatch, Data: watchInfo}); !sent {
+		t.Errorf("the watch packet should be sent")
+	}
+	if len(watchInfo.Decls[0].Tags) != 1 || watchInfo.Decls[0].Tags[0] != "team:payments" {
+		t.Errorf("expected the hook to tag the watch, got %v", watchInfo.Decls[0].Tags)
+	}
+	if sent, _ := transport.sendPacketInternal(&ds.PacketType{Type: ds.PacketTypeGoroutine, Data: &ds.GoroutineInfo{}}); sent {
+		t.Errorf("the goroutine packet should be dropped by the hook")
+	}
+	if brokenChains := transport.takeBrokenChains(); len(brokenChains) != 1 || brokenChains[0] != ds.PacketTypeGoroutine {
+		t.Errorf("a dropped delta packet should request a full update, got %v", brokenChains)
+	}
+	seenBefore := numSeen.Load()
+	if sent, _ := transport.sendPacketInternal(&ds.PacketType{Type: ds.PacketTypeAppInfo, Data: &ds.AppInfo{}}); !sent || numSeen.Load() != seenBefore {
+		t.Errorf("appinfo packets should be sent without running the hooks")
+	}
+}
+
+func TestTransportBuffersWhileDisconnected(t *testing.T) {
+	t.Setenv(config.RemoteAddrEnvName, "")
+	transport := MakeTransport(&config.Config{Quiet: true, Remote: config.RemoteConfig{Addr: "monitor:5005", BufferSize: 2}})
+	if sent, _ := transport.sendPacketInternal(&ds.PacketType{Type: ds.PacketTypeAppInfo, Data: &ds.AppInfo{}}); sent {
+		t.Errorf("appinfo is sent on connect, it should not be buffered")
+	}
+	droppedBefore := atomic.LoadInt64(&TransportDroppedPackets)
+	for ts := int64(1); ts <= 3; ts++ {
+		if sent, _ := transport.sendPacketInternal(&ds.PacketType{Type: ds.PacketTypeWatch, Data: &ds.WatchInfo{Ts: ts}}); !sent {
+			t.Errorf("watch packet %d was not buffered", ts)
+		}
+	}
+	if dropped := atomic.LoadInt64(&TransportDroppedPackets) - droppedBefore; dropped != 1 {
+		t.Errorf("got %d dropped packets, want the oldest one dropped", dropped)
+	}
+
+	// the buffered packets are written first when the monitor connects
+	clientConn, serverConn := net.Pipe()
+	defer serverConn.Close()
+	connWrap := comm.MakeConnWrap(clientConn, "test")
+	connWrap.ServerResponse = &comm.ServerHandshakeResponse{Success: true}
+	transport.AddConn(connWrap)
+	defer transport.CloseAllConns()
+	serverWrap := comm.MakeConnWrap(serverConn, "server")
+	for _, expectTs := range []int64{2, 3} {
+		line, err := serverWrap.ReadLine()
+		if err != nil {
+			t.Fatalf("read error: %v", err)
+		}
+		var pk struct {
+			Type string       `json:"type"`
+			Data ds.WatchInfo `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(line), &pk); err != nil || pk.Type != ds.PacketTypeWatch || pk.Data.Ts != expectTs {
+			t.Errorf("got %s, want the buffered watch packet %d", line, expectTs)
+		}
+	}
+}
@@ -5,7 +5,10 @@
 package ds
 
 import (
+	"encoding/json"
+	"fmt"
 	"net"
+	"slices"
 	"sync"
 
 	"github.com/outrigdev/outrig/pkg/config"
@@ -21,8 +24,100 @@ const (
 	PacketTypeWatch           = "watch"
 	PacketTypeRuntimeStats    = "runtimestats"
 	PacketTypeCollectorStatus = "collectorstatus"
+	PacketTypePanic           = "panic"
+	PacketTypeAppExit         = "appexit" // sent by the outrig exec/run wrapper when the app process exits
+	PacketTypeAppMeta         = "appmeta"
+	PacketTypeTransportStats  = "transportstats"
+	PacketTypeGoroutineChurn  = "goroutinechurn"
+	PacketTypeConfigInfo      = "configinfo"
+
+	PacketTypeRuntimeControlResult = "runtimecontrolresult"
+	PacketTypeCPUProfileResult     = "cpuprofileresult"
+	PacketTypeSetWatchValueResult  = "setwatchvalueresult"
+	PacketTypeExecTraceResult      = "exectraceresult"
+	PacketTypeGoroutineDumpResult  = "goroutinedumpresult"
+
+	// sent from the server to the SDK
+	PacketTypeCollectorAdmin = "collectoradmin"
+	PacketTypeRuntimeControl = "runtimecontrol"
+	PacketTypeCPUProfile     = "cpuprofile"
+	PacketTypeSetWatchValue  = "setwatchvalue"
+	PacketTypeExecTrace      = "exectrace"
+	PacketTypeResync         = "resync"
+	PacketTypeGoroutineDump  = "goroutinedump"
+)
+
+// SDKPacketTypes are the packet types sent from the SDK to the server (advertised in the handshake, see ProtocolFeatures)
+var SDKPacketTypes = []string{
+	PacketTypeLog,
+	PacketTypeMultiLog,
+	PacketTypeAppInfo,
+	PacketTypeGoroutine,
+	PacketTypeAppDone,
+	PacketTypeWatch,
+	PacketTypeRuntimeStats,
+	PacketTypeCollectorStatus,
+	PacketTypePanic,
+	PacketTypeAppExit,
+	PacketTypeAppMeta,
+	PacketTypeTransportStats,
+	PacketTypeGoroutineChurn,
+	PacketTypeConfigInfo,
+	PacketTypeRuntimeControlResult,
+	PacketTypeCPUProfileResult,
+	PacketTypeSetWatchValueResult,
+	PacketTypeExecTraceResult,
+	PacketTypeGoroutineDumpResult,
+}
+
+// Delta protocol versions (the encoding of the delta goroutine and watch updates)
+const (
+	DeltaVersionNone       = 0 // only full updates
+	DeltaVersionBase       = 1 // delta goroutine and watch updates (GoroutineInfo.Delta, WatchInfo.Delta)
+	DeltaVersionWatchPatch = 2 // large json watch values sent as patches (WatchSample.Patch)
+
+	DeltaVersion = DeltaVersionWatchPatch // the delta version of this build
+)
+
+// ProtocolFeatures are the protocol features of a connection, negotiated in the handshake (see comm.ClientHandshake).
+// The SDK only sends the packet types (and delta encodings) the server can parse, so a newer SDK connected to an
+// older monitor disables its newer features instead of sending packets the monitor doesn't understand.
+type ProtocolFeatures struct {
+	PacketTypes  []string `json:"packettypes"`
+	DeltaVersion int      `json:"deltaversion"`
+}
+
+// SupportsPacketType returns true if packets of type pkType can be sent
+func (f ProtocolFeatures) SupportsPacketType(pkType string) bool {
+	return slices.Contains(f.PacketTypes, pkType)
+}
+
+// Collector admin actions (see CollectorAdminData)
+const (
+	CollectorAdminActionEnable  = "enable"
+	CollectorAdminActionDisable = "disable"
+)
+
+// Collector settings that can be changed at runtime (see CollectorAdminData)
+const (
+	CollectorSettingPollIntervalMs = "pollintervalms"
 )
 
+// Runtime control actions (see RuntimeControlData)
+const (
+	RuntimeControlActionGC           = "gc"           // runtime.GC()
+	RuntimeControlActionFreeOSMemory = "freeosmemory" // debug.FreeOSMemory()
+	RuntimeControlActionHeapDump     = "heapdump"     // debug.WriteHeapDump() to Path
+	RuntimeControlActionSetGCPercent = "setgcpercent" // debug.SetGCPercent(GCPercent)
+)
+
+// AppExitInfo is sent by the process wrapper (outrig exec / outrig run) once the app process has exited
+type AppExitInfo struct {
+	Ts       int64  `json:"ts"`
+	ExitCode int    `json:"exitcode"`
+	Signal   string `json:"signal,omitempty"` // set if the process was killed by a signal
+}
+
 type PacketType struct {
 	Type string `json:"type"`
 	Data any    `json:"data"`
@@ -40,6 +135,14 @@ type LogLine struct {
 	Msg     string `json:"msg"`
 	Source  string `json:"source,omitempty"`
 	Color   int8   `json:"color"`
+	GoId    int64  `json:"goid,omitempty"`  // goroutine that logged the line (only for lines logged through the SDK, not stdout/stderr)
+	Level   string `json:"level,omitempty"` // normalized log level detected from the message by the monitor (trace, debug, info, warn, error, fatal)
+
+	IsContext bool `json:"iscontext,omitempty"` // set on search results that are context lines around a match (not matches)
+
+	// set on search results with dedup, when identical lines were collapsed into this one
+	RepeatCount  int   `json:"repeatcount,omitempty"`  // number of identical lines (including this one)
+	LastRepeatTs int64 `json:"lastrepeatts,omitempty"` // timestamp of the last identical line
 }
 
 // MultiLogLines represents a collection of log lines to be processed together
@@ -65,19 +168,45 @@ type BuildInfoData struct {
 }
 
 type AppInfo struct {
-	AppRunId         string         `json:"apprunid"`
-	AppName          string         `json:"appname"`
-	ModuleName       string         `json:"modulename"`
-	Executable       string         `json:"executable"`
-	Args             []string       `json:"args"`
-	Env              []string       `json:"env"`
-	StartTime        int64          `json:"starttime"`
-	Pid              int            `json:"pid"`
-	User             string         `json:"user,omitempty"`
-	Hostname         string         `json:"hostname,omitempty"`
-	BuildInfo        *BuildInfoData `json:"buildinfo,omitempty"`
-	OutrigSDKVersion string         `json:"outrigsdkversion,omitempty"`
-	RunMode          bool           `json:"runmode,omitempty"`
+	AppRunId         string            `json:"apprunid"`
+	AppName          string            `json:"appname"`
+	ModuleName       string            `json:"modulename"`
+	ModuleDir        string            `json:"moduledir,omitempty"` // directory of the main module's go.mod (if the app was started inside the project)
+	Executable       string            `json:"executable"`
+	Args             []string          `json:"args"`
+	Env              []string          `json:"env"`
+	StartTime        int64             `json:"starttime"`
+	Pid              int               `json:"pid"`
+	User             string            `json:"user,omitempty"`
+	Hostname         string            `json:"hostname,omitempty"`
+	BuildInfo        *BuildInfoData    `json:"buildinfo,omitempty"`
+	OutrigSDKVersion string            `json:"outrigsdkversion,omitempty"`
+	RunMode          bool              `json:"runmode,omitempty"`
+	Capabilities     *SDKCapabilities  `json:"capabilities,omitempty"`
+	Meta             map[string]string `json:"meta,omitempty"` // app run metadata (see outrig.SetAppMeta)
+}
+
+// AppMetaData updates the metadata of a running app (the full map is sent, not a delta)
+type AppMetaData struct {
+	Meta map[string]string `json:"meta"`
+}
+
+// ConfigInfo is the SDK's resolved config (sent on every connect, see GetEffectiveConfigCommand).
+// The values of Exec.Env are masked, they are only used by "outrig run" and may hold secrets.
+type ConfigInfo struct {
+	Source string        `json:"source"` // where the config came from: config.ConfigSourceDefault, config.ConfigSourceInit, "file:<path>", or "env:<name>"
+	Config config.Config `json:"config"` // after the app name override (OUTRIG_APPNAME or the executable name)
+}
+
+// SDKCapabilities reports which SDK features work on the target the app was compiled for (see pkg/platform)
+type SDKCapabilities struct {
+	Platform        string `json:"platform"` // GOOS/GOARCH
+	Compiler        string `json:"compiler"` // gc or tinygo
+	Restricted      bool   `json:"restricted,omitempty"`
+	Connect         bool   `json:"connect"`         // can connect to the Outrig server
+	StdioCapture    bool   `json:"stdiocapture"`    // stdout/stderr capture
+	GoRoutineStacks bool   `json:"goroutinestacks"` // goroutine collector
+	FDStats         bool   `json:"fdstats"`         // fdstats collector
 }
 
 type GoroutineInfo struct {
@@ -91,10 +220,11 @@ type GoroutineInfo struct {
 type GoRoutineStack struct {
 	GoId       int64    `json:"goid"`
 	Ts         int64    `json:"ts"`
-	Same       bool     `json:"same,omitempty"` // true if the GoId, State, Name, Tags, and StackTrace are the same as the previous sample (for delta collection)
+	Same       bool     `json:"same,omitempty"` // true if the GoId, State, Name, Tags, Labels, and StackTrace are the same as the previous sample (for delta collection)
 	State      string   `json:"state,omitempty"`
 	Name       string   `json:"name,omitempty"`
 	Tags       []string `json:"tags,omitempty"`
+	Labels     string   `json:"labels,omitempty"`     // pprof labels from the goroutine header, e.g. "{job: sync}" (Go 1.27+, or GODEBUG=tracebacklabels=1)
 	StackTrace string   `json:"stacktrace,omitempty"` // does not include the goroutine header (goid + state)
 }
 
@@ -102,6 +232,7 @@ type GoDecl struct {
 	GoId          int64    `json:"goid,omitempty"`
 	Name          string   `json:"name"`
 	Group         string   `json:"group,omitempty"`
+	Pool          string   `json:"pool,omitempty"` // name of the worker pool (for the workers of an outrig.Pool)
 	Tags          []string `json:"tags,omitempty"`
 	Pkg           string   `json:"pkg,omitempty"`  // package name that created the goroutine
 	Func          string   `json:"func,omitempty"` // function name that created the goroutine (without anonymous func suffixes)
@@ -117,6 +248,39 @@ type GoDecl struct {
 	LastPollTs    int64    `json:"lastpollts,omitempty"`
 	CSNum         int      `json:"csnum,omitempty"`         // call site number for goroutines spawned from the same location
 	RealCreatedBy string   `json:"realcreatedby,omitempty"` // the real creator of this goroutine (for routines created by the SDK Run() func)
+	Status        string   `json:"status,omitempty"`        // what the goroutine is doing (e.g. waiting on a WaitGroup), empty when cleared
+}
+
+// GoroutineChurnInfo is sent after the goroutine stacks when goroutines started with the SDK's Run() func
+// started or finished since the last report. Goroutines that start and finish between two stack dumps never
+// show up in the stacks, the churn counts (by call site) account for them.
+type GoroutineChurnInfo struct {
+	Ts        int64                    `json:"ts"`
+	SinceTs   int64                    `json:"sincets"` // start of the reported interval (the previous report)
+	CallSites []GoroutineChurnCallSite `json:"callsites"`
+}
+
+// GoroutineChurnCallSite counts the goroutines started from one call site during a GoroutineChurnInfo interval
+type GoroutineChurnCallSite struct {
+	CallSite   string `json:"callsite"`       // file:line of the Run() call
+	Func       string `json:"func,omitempty"` // function that contains the call site
+	Name       string `json:"name,omitempty"` // name of the last goroutine started from the call site
+	Started    int64  `json:"started"`
+	Finished   int64  `json:"finished"`
+	ShortLived int64  `json:"shortlived,omitempty"` // finished goroutines that never appeared in a stack dump
+	RunTimeMs  int64  `json:"runtimems,omitempty"`  // total run time of the finished goroutines
+}
+
+// PanicInfo is sent when a goroutine started with the SDK's Run() func panics
+type PanicInfo struct {
+	GoId       int64    `json:"goid"`
+	Ts         int64    `json:"ts"`
+	Name       string   `json:"name,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	PanicVal   string   `json:"panicval"`
+	PanicType  string   `json:"panictype"`
+	Recovered  bool     `json:"recovered,omitempty"` // true if the SDK recovered the panic (goroutine not created with WithoutRecover())
+	StackTrace string   `json:"stacktrace"`          // starts at the panic() frame, does not include the goroutine header
 }
 
 type WatchInfo struct {
@@ -125,10 +289,57 @@ type WatchInfo struct {
 	Decls     []WatchDecl      `json:"decls,omitempty"`
 	Watches   []WatchSample    `json:"watches"`
 	RegErrors []ErrWithContext `json:"regerrors,omitempty"`
+	Limits    *WatchLimitStats `json:"limits,omitempty"` // only sent once a limit has been hit
+}
+
+// WatchLimitStats are the totals (since the app started) of watches and samples dropped because of the SDK limits
+type WatchLimitStats struct {
+	MaxWatches     int   `json:"maxwatches"`
+	NumEvicted     int64 `json:"numevicted,omitempty"`     // least recently used push/static watches evicted to make room for new watches
+	NumRejected    int64 `json:"numrejected,omitempty"`    // registrations rejected because no watch could be evicted
+	NumDropSamples int64 `json:"numdropsamples,omitempty"` // pushed samples dropped because the push buffer was full
+}
+
+// Warnings describes the limits that have been hit (nil safe)
+func (s *WatchLimitStats) Warnings() []string {
+	if s == nil {
+		return nil
+	}
+	var warnings []string
+	if s.NumEvicted > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d least recently used push/static watches evicted (max %d watches)", s.NumEvicted, s.MaxWatches))
+	}
+	if s.NumRejected > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d watch registrations rejected (max %d watches)", s.NumRejected, s.MaxWatches))
+	}
+	if s.NumDropSamples > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d pushed watch samples dropped (max %d buffered)", s.NumDropSamples, s.MaxWatches))
+	}
+	return warnings
 }
 
+// Units of numeric watch values (see WatchDecl.Unit), other units are shown as a suffix of the value
+const (
+	WatchUnitBytes   = "bytes"
+	WatchUnitNs      = "ns"
+	WatchUnitUs      = "us"
+	WatchUnitMs      = "ms"
+	WatchUnitS       = "s"
+	WatchUnitPercent = "percent"
+
+	MaxWatchUnitLen = 16
+)
+
+// Watch display hints (see WatchDecl.Display)
+const (
+	WatchDisplayGauge = "gauge" // the value is a level (the default)
+	WatchDisplayRate  = "rate"  // the value is cumulative, its change per second is shown as well
+	WatchDisplayRaw   = "raw"   // the value is shown as is (not formatted by its unit)
+)
+
 type WatchDecl struct {
 	Name         string   `json:"name"`
+	Group        string   `json:"group,omitempty"` // namespace of the watch (see outrig.WatchGroup), Name starts with "<group>."
 	Tags         []string `json:"tags,omitempty"`
 	NewLine      string   `json:"newline,omitempty"`
 	WatchType    string   `json:"watchtype"`
@@ -136,9 +347,14 @@ type WatchDecl struct {
 	Counter      bool     `json:"counter,omitempty"`
 	Invalid      bool     `json:"invalid,omitempty"`
 	Unregistered bool     `json:"unregistered,omitempty"`
+	Evicted      bool     `json:"evicted,omitempty"`  // unregistered by the SDK because MaxWatchVals was reached
+	Settable     bool     `json:"settable,omitempty"` // the value can be set from Outrig (see SetWatchValueData)
+	Unit         string   `json:"unit,omitempty"`     // unit of numeric values ("bytes", "ms", ...), see Watch.WithUnit
+	Display      string   `json:"display,omitempty"`  // display hint ("gauge", "rate", "raw"), see Watch.WithDisplay
 
 	SyncLock sync.Locker `json:"-"`
 	PollObj  any         `json:"-"`
+	SetFn    any         `json:"-"` // setter for settable watches, func(T) or func(T) error
 }
 
 type WatchSample struct {
@@ -154,6 +370,29 @@ type WatchSample struct {
 	Len     int      `json:"len,omitempty"`   // same
 	Fmt     string   `json:"fmt,omitempty"`   // same
 	PollDur int64    `json:"polldur,omitempty"`
+
+	Patch *WatchPatch `json:"patch,omitempty"` // set (instead of Val) for large json values that changed a little, see WatchPatch
+}
+
+// WatchPatch is a diff of a large json watch value against the previous sample of the watch (only sent in delta updates).
+// The server applies Ops to the previous value, Crc (crc32 IEEE) is the checksum of the complete new value.
+type WatchPatch struct {
+	Ops []JsonPatchOp `json:"ops"`
+	Crc uint32        `json:"crc"`
+}
+
+// json patch operations (a subset of RFC 6902)
+const (
+	JsonPatchOpAdd     = "add"
+	JsonPatchOpRemove  = "remove"
+	JsonPatchOpReplace = "replace"
+)
+
+// JsonPatchOp is a single json patch operation, Path is a json pointer (RFC 6901)
+type JsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
 }
 
 type MemoryStatsInfo struct {
@@ -191,6 +430,37 @@ type RuntimeStatsInfo struct {
 	Pid            int             `json:"pid"`
 	Cwd            string          `json:"cwd"`
 	MemStats       MemoryStatsInfo `json:"memstats"`
+	FDStats        *FDStatsInfo    `json:"fdstats,omitempty"`    // nil if the fdstats collector is disabled or not supported on this platform
+	SchedStats     *SchedStatsInfo `json:"schedstats,omitempty"` // nil if the schedstats collector is disabled
+}
+
+// FDStatsInfo holds the open file descriptor and socket counts of the process (sampled by the fdstats collector)
+type FDStatsInfo struct {
+	Ts          int64 `json:"ts"`
+	OpenFDs     int   `json:"openfds"`
+	MaxFDs      int   `json:"maxfds,omitempty"` // soft RLIMIT_NOFILE (0 if unknown)
+	Sockets     int   `json:"sockets"`
+	Listening   int   `json:"listening"`   // listening sockets
+	Established int   `json:"established"` // established (connected) TCP connections
+}
+
+// SchedStatsInfo holds scheduler health metrics read from runtime/metrics (sampled by the schedstats collector).
+// The latency and pause values are computed from the histogram deltas since the previous sample (IntervalMs),
+// they are bucket upper bounds so they are approximate. Metrics the app's Go version doesn't provide are 0.
+type SchedStatsInfo struct {
+	Ts                  int64 `json:"ts"`
+	IntervalMs          int64 `json:"intervalms"`
+	SchedLatencyP50Ns   int64 `json:"schedlatencyp50ns"` // time goroutines spent runnable before they ran
+	SchedLatencyP99Ns   int64 `json:"schedlatencyp99ns"`
+	SchedLatencyMaxNs   int64 `json:"schedlatencymaxns"`
+	GCPauses            int64 `json:"gcpauses"` // stop-the-world GC pauses in the interval
+	GCPauseP99Ns        int64 `json:"gcpausep99ns"`
+	GCPauseMaxNs        int64 `json:"gcpausemaxns"`
+	MutexWaitNs         int64 `json:"mutexwaitns"`      // time goroutines spent blocked on a sync.Mutex/RWMutex in the interval
+	MutexWaitTotalNs    int64 `json:"mutexwaittotalns"` // cumulative since the process started
+	GoMaxProcs          int   `json:"gomaxprocs"`
+	GoMaxProcsChanges   int   `json:"gomaxprocschanges,omitempty"`   // GOMAXPROCS changes seen since the collector started
+	GoMaxProcsChangedTs int64 `json:"gomaxprocschangedts,omitempty"` // time of the last change
 }
 
 // for internal use (import cycles)
@@ -200,6 +470,7 @@ type Controller interface {
 
 	// Transport
 	SendPacket(pk *PacketType) (bool, error)
+	GetProtocolFeatures() ProtocolFeatures // the features supported by all the connected servers
 
 	ILog(format string, args ...any)
 }
@@ -211,10 +482,145 @@ type ErrWithContext struct {
 	Line  string `json:"line"`          // file:line
 }
 
+// TransportStats are the SDK's outgoing queue counters (totals since the app started), sent when they change
+type TransportStats struct {
+	Ts           int64            `json:"ts"`
+	QueueCap     int              `json:"queuecap"` // max packets queued per connection
+	TotalDropped int64            `json:"totaldropped"`
+	Dropped      map[string]int64 `json:"dropped,omitempty"`  // by packet type ("log" counts log lines)
+	Redacted     int64            `json:"redacted,omitempty"` // number of values redacted from log lines and watches (see config.RedactConfig)
+}
+
 type CollectorStatus struct {
-	Running         bool     `json:"running"`
-	Info            string   `json:"info,omitempty"`
-	Warnings        []string `json:"warnings,omitempty"`
-	Errors          []string `json:"errors,omitempty"`
-	CollectDuration int64    `json:"collectduration,omitempty"` // time in milliseconds of last collection
+	Running         bool               `json:"running"`
+	Info            string             `json:"info,omitempty"`
+	Warnings        []string           `json:"warnings,omitempty"`
+	Errors          []string           `json:"errors,omitempty"`
+	CollectDuration int64              `json:"collectduration,omitempty"` // time in milliseconds of last collection
+	Overhead        *CollectorOverhead `json:"overhead,omitempty"`        // what the collections cost the app (collectors that collect periodically)
+}
+
+// CollectorOverhead is what a collector's collections cost the app, measured around each collection.
+// The CPU time is the collecting goroutine's thread CPU time (only measured on linux and macOS). The allocations
+// are the heap bytes allocated by the whole process while the collection ran, so they are an upper bound
+// (allocations of the app's goroutines during the collection are included).
+type CollectorOverhead struct {
+	IntervalMs      int64 `json:"intervalms"`      // time between collections
+	NumCycles       int64 `json:"numcycles"`       // collections since the app started
+	CpuMeasured     bool  `json:"cpumeasured"`     // false if the platform can't measure thread CPU time (the CPU fields are 0)
+	LastCpuUs       int64 `json:"lastcpuus"`       // CPU time of the last collection in microseconds
+	TotalCpuUs      int64 `json:"totalcpuus"`      // CPU time of all collections in microseconds
+	LastAllocBytes  int64 `json:"lastallocbytes"`  // bytes allocated during the last collection
+	TotalAllocBytes int64 `json:"totalallocbytes"` // bytes allocated during all collections
+}
+
+// CollectorAdminData turns a collector on/off or changes its settings on a running app
+type CollectorAdminData struct {
+	Collector string         `json:"collector"`
+	Action    string         `json:"action,omitempty"`   // CollectorAdminActionEnable, CollectorAdminActionDisable, or empty to only apply settings
+	Settings  map[string]any `json:"settings,omitempty"` // e.g. CollectorSettingPollIntervalMs
+}
+
+// ResyncData asks the SDK to send full updates of delta packet types (PacketTypeGoroutine, PacketTypeWatch)
+// after the server found its delta updates inconsistent with its state (e.g. a delta for an unknown base)
+type ResyncData struct {
+	PacketTypes []string `json:"packettypes"`
+	Reason      string   `json:"reason,omitempty"`
+}
+
+// RuntimeControlData asks a running app to run a runtime command (GC, heap dump, GOGC change),
+// the SDK answers with a RuntimeControlResult with the same CommandId
+type RuntimeControlData struct {
+	CommandId   string `json:"commandid"`
+	Action      string `json:"action"`              // one of the RuntimeControlAction* constants
+	Path        string `json:"path,omitempty"`      // heap dump file (defaults to a file in the app's temp dir)
+	GCPercent   int    `json:"gcpercent,omitempty"` // new GOGC value for setgcpercent (negative turns the GC off)
+	TriggeredBy string `json:"triggeredby,omitempty"`
+}
+
+// MaxCPUProfileDurationSec caps the length of a CPU profile requested by the server
+const MaxCPUProfileDurationSec = 300
+
+// CPUProfileData asks a running app to capture a CPU profile (runtime/pprof) for DurationSec seconds,
+// the SDK answers with a CPUProfileResult with the same CommandId once the profile is done
+type CPUProfileData struct {
+	CommandId   string `json:"commandid"`
+	DurationSec int    `json:"durationsec"`
+	TriggeredBy string `json:"triggeredby,omitempty"`
+}
+
+type CPUProfileResult struct {
+	CommandId  string `json:"commandid"`
+	Ts         int64  `json:"ts"` // time the profile was started (unix ms)
+	DurationMs int64  `json:"durationms"`
+	Error      string `json:"error,omitempty"`
+	Profile    []byte `json:"profile,omitempty"` // gzipped pprof protobuf (as written by pprof.StartCPUProfile)
+}
+
+// MaxExecTraceDurationSec caps the length of an execution trace requested by the server
+const MaxExecTraceDurationSec = 60
+
+// MaxExecTraceBytes caps the size of an execution trace, the trace is stopped early once it grows past this
+const MaxExecTraceBytes = 64 * 1024 * 1024
+
+// ExecTraceData asks a running app to capture an execution trace (runtime/trace) for DurationSec seconds,
+// the SDK answers with an ExecTraceResult with the same CommandId once the trace is done
+type ExecTraceData struct {
+	CommandId   string `json:"commandid"`
+	DurationSec int    `json:"durationsec"`
+	TriggeredBy string `json:"triggeredby,omitempty"`
+}
+
+type ExecTraceResult struct {
+	CommandId  string `json:"commandid"`
+	Ts         int64  `json:"ts"` // time the trace was started (unix ms)
+	DurationMs int64  `json:"durationms"`
+	Truncated  bool   `json:"truncated,omitempty"` // stopped early because the trace reached MaxExecTraceBytes
+	Error      string `json:"error,omitempty"`
+	Trace      []byte `json:"trace,omitempty"` // as written by trace.Start (readable by "go tool trace")
+}
+
+// GoroutineDumpData asks a running app for an immediate full goroutine dump (outside the regular poll),
+// the SDK answers with a GoroutineDumpResult with the same CommandId
+type GoroutineDumpData struct {
+	CommandId   string `json:"commandid"`
+	TriggeredBy string `json:"triggeredby,omitempty"`
+}
+
+type GoroutineDumpResult struct {
+	CommandId string         `json:"commandid"`
+	Error     string         `json:"error,omitempty"`
+	Info      *GoroutineInfo `json:"info,omitempty"` // a full update (not a delta), the regular updates are not affected
+}
+
+// SetWatchValueData asks a running app to set the value of a settable watch (the SDK calls the watch's setter),
+// the SDK answers with a SetWatchValueResult with the same CommandId
+type SetWatchValueData struct {
+	CommandId   string `json:"commandid"`
+	Name        string `json:"name"`
+	Value       string `json:"value"` // JSON for the setter's argument type (plain text is accepted for string setters)
+	TriggeredBy string `json:"triggeredby,omitempty"`
+}
+
+type SetWatchValueResult struct {
+	CommandId string `json:"commandid"`
+	Name      string `json:"name"`
+	Ts        int64  `json:"ts"`
+	Error     string `json:"error,omitempty"`
+}
+
+type RuntimeControlResult struct {
+	CommandId          string `json:"commandid"`
+	Action             string `json:"action"`
+	Ts                 int64  `json:"ts"`
+	DurationMs         int64  `json:"durationms"`
+	Error              string `json:"error,omitempty"`
+	Path               string `json:"path,omitempty"`     // heap dump file that was written
+	FileSize           int64  `json:"filesize,omitempty"` // size of the heap dump file
+	OldGCPercent       int    `json:"oldgcpercent,omitempty"`
+	NewGCPercent       int    `json:"newgcpercent,omitempty"`
+	HeapAllocBefore    uint64 `json:"heapallocbefore"`
+	HeapAllocAfter     uint64 `json:"heapallocafter"`
+	HeapReleasedBefore uint64 `json:"heapreleasedbefore"`
+	HeapReleasedAfter  uint64 `json:"heapreleasedafter"`
 }
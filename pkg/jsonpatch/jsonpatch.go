@@ -0,0 +1,291 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package jsonpatch makes and applies the json patches (a subset of RFC 6902) of large json watch values
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+// jsonNode is a parsed JSON value that keeps the object key order and the raw bytes of scalars,
+// so applying a patch reproduces the exact bytes of the (compact) document the patch was made from
+type jsonNode struct {
+	kind  byte // '{', '[', or 0 for scalars
+	keys  []string
+	elems []*jsonNode // object values (parallel to keys) or array elements
+	raw   json.RawMessage
+}
+
+func parseJsonNode(raw json.RawMessage) (*jsonNode, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("empty json value")
+	}
+	switch raw[0] {
+	case '{':
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.Token() // {
+		node := &jsonNode{kind: '{'}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyTok.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid json object key")
+			}
+			var val json.RawMessage
+			if err := dec.Decode(&val); err != nil {
+				return nil, err
+			}
+			child, err := parseJsonNode(val)
+			if err != nil {
+				return nil, err
+			}
+			node.keys = append(node.keys, key)
+			node.elems = append(node.elems, child)
+		}
+		return node, nil
+	case '[':
+		var vals []json.RawMessage
+		if err := json.Unmarshal(raw, &vals); err != nil {
+			return nil, err
+		}
+		node := &jsonNode{kind: '['}
+		for _, val := range vals {
+			child, err := parseJsonNode(val)
+			if err != nil {
+				return nil, err
+			}
+			node.elems = append(node.elems, child)
+		}
+		return node, nil
+	default:
+		if !json.Valid(raw) {
+			return nil, fmt.Errorf("invalid json value")
+		}
+		return &jsonNode{raw: raw}, nil
+	}
+}
+
+func (n *jsonNode) write(buf *bytes.Buffer) {
+	switch n.kind {
+	case '{':
+		buf.WriteByte('{')
+		for idx, key := range n.keys {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+			keyBarr, _ := json.Marshal(key)
+			buf.Write(keyBarr)
+			buf.WriteByte(':')
+			n.elems[idx].write(buf)
+		}
+		buf.WriteByte('}')
+	case '[':
+		buf.WriteByte('[')
+		for idx, elem := range n.elems {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+			elem.write(buf)
+		}
+		buf.WriteByte(']')
+	default:
+		buf.Write(n.raw)
+	}
+}
+
+func (n *jsonNode) bytes() []byte {
+	var buf bytes.Buffer
+	n.write(&buf)
+	return buf.Bytes()
+}
+
+func (n *jsonNode) keyIndex(key string) int {
+	for idx, k := range n.keys {
+		if k == key {
+			return idx
+		}
+	}
+	return -1
+}
+
+func escapeJsonPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescapeJsonPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// Make returns the operations that turn the JSON document oldJson into newJson.
+// Changed objects only get per-key operations when no keys were added (otherwise the whole object
+// is replaced), so Apply reproduces newJson exactly when newJson is compact (e.g. from json.Marshal).
+func Make(oldJson string, newJson string) ([]ds.JsonPatchOp, error) {
+	oldNode, err := parseJsonNode(json.RawMessage(oldJson))
+	if err != nil {
+		return nil, fmt.Errorf("invalid old json: %w", err)
+	}
+	newNode, err := parseJsonNode(json.RawMessage(newJson))
+	if err != nil {
+		return nil, fmt.Errorf("invalid new json: %w", err)
+	}
+	var ops []ds.JsonPatchOp
+	diffJsonNodes(oldNode, newNode, "", &ops)
+	return ops, nil
+}
+
+func diffJsonNodes(oldNode *jsonNode, newNode *jsonNode, path string, ops *[]ds.JsonPatchOp) {
+	replace := func() {
+		*ops = append(*ops, ds.JsonPatchOp{Op: ds.JsonPatchOpReplace, Path: path, Value: newNode.bytes()})
+	}
+	if oldNode.kind != newNode.kind {
+		replace()
+		return
+	}
+	switch newNode.kind {
+	case '{':
+		for _, key := range newNode.keys {
+			if oldNode.keyIndex(key) == -1 {
+				replace()
+				return
+			}
+		}
+		for _, key := range oldNode.keys {
+			if newNode.keyIndex(key) == -1 {
+				*ops = append(*ops, ds.JsonPatchOp{Op: ds.JsonPatchOpRemove, Path: path + "/" + escapeJsonPointer(key)})
+			}
+		}
+		for idx, key := range newNode.keys {
+			oldChild := oldNode.elems[oldNode.keyIndex(key)]
+			diffJsonNodes(oldChild, newNode.elems[idx], path+"/"+escapeJsonPointer(key), ops)
+		}
+	case '[':
+		minLen := min(len(oldNode.elems), len(newNode.elems))
+		for idx := 0; idx < minLen; idx++ {
+			diffJsonNodes(oldNode.elems[idx], newNode.elems[idx], path+"/"+strconv.Itoa(idx), ops)
+		}
+		// remove from the end so the indexes stay valid
+		for idx := len(oldNode.elems) - 1; idx >= minLen; idx-- {
+			*ops = append(*ops, ds.JsonPatchOp{Op: ds.JsonPatchOpRemove, Path: path + "/" + strconv.Itoa(idx)})
+		}
+		for idx := minLen; idx < len(newNode.elems); idx++ {
+			*ops = append(*ops, ds.JsonPatchOp{Op: ds.JsonPatchOpAdd, Path: path + "/-", Value: newNode.elems[idx].bytes()})
+		}
+	default:
+		if !bytes.Equal(oldNode.raw, newNode.raw) {
+			replace()
+		}
+	}
+}
+
+// Apply applies the operations (see Make) to the JSON document docJson
+func Apply(docJson string, ops []ds.JsonPatchOp) (string, error) {
+	root, err := parseJsonNode(json.RawMessage(docJson))
+	if err != nil {
+		return "", fmt.Errorf("invalid json document: %w", err)
+	}
+	for _, op := range ops {
+		root, err = applyJsonPatchOp(root, op)
+		if err != nil {
+			return "", fmt.Errorf("json patch %s %q: %w", op.Op, op.Path, err)
+		}
+	}
+	return string(root.bytes()), nil
+}
+
+func applyJsonPatchOp(root *jsonNode, op ds.JsonPatchOp) (*jsonNode, error) {
+	var value *jsonNode
+	if op.Op == ds.JsonPatchOpAdd || op.Op == ds.JsonPatchOpReplace {
+		var err error
+		value, err = parseJsonNode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if op.Path == "" {
+		if op.Op == ds.JsonPatchOpRemove {
+			return nil, fmt.Errorf("cannot remove the root")
+		}
+		return value, nil
+	}
+	if !strings.HasPrefix(op.Path, "/") {
+		return nil, fmt.Errorf("invalid path")
+	}
+	tokens := strings.Split(op.Path[1:], "/")
+	parent := root
+	for _, token := range tokens[:len(tokens)-1] {
+		child, err := parent.getChild(unescapeJsonPointer(token))
+		if err != nil {
+			return nil, err
+		}
+		parent = child
+	}
+	last := unescapeJsonPointer(tokens[len(tokens)-1])
+	switch parent.kind {
+	case '{':
+		idx := parent.keyIndex(last)
+		switch {
+		case op.Op == ds.JsonPatchOpRemove && idx >= 0:
+			parent.keys = append(parent.keys[:idx], parent.keys[idx+1:]...)
+			parent.elems = append(parent.elems[:idx], parent.elems[idx+1:]...)
+		case op.Op == ds.JsonPatchOpRemove:
+			return nil, fmt.Errorf("key not found")
+		case idx >= 0:
+			parent.elems[idx] = value
+		case op.Op == ds.JsonPatchOpAdd:
+			parent.keys = append(parent.keys, last)
+			parent.elems = append(parent.elems, value)
+		default:
+			return nil, fmt.Errorf("key not found")
+		}
+	case '[':
+		if last == "-" && op.Op == ds.JsonPatchOpAdd {
+			parent.elems = append(parent.elems, value)
+			break
+		}
+		idx, err := strconv.Atoi(last)
+		if err != nil || idx < 0 || idx > len(parent.elems) || (idx == len(parent.elems) && op.Op != ds.JsonPatchOpAdd) {
+			return nil, fmt.Errorf("invalid array index")
+		}
+		switch op.Op {
+		case ds.JsonPatchOpRemove:
+			parent.elems = append(parent.elems[:idx], parent.elems[idx+1:]...)
+		case ds.JsonPatchOpAdd:
+			parent.elems = append(parent.elems[:idx], append([]*jsonNode{value}, parent.elems[idx:]...)...)
+		default:
+			parent.elems[idx] = value
+		}
+	default:
+		return nil, fmt.Errorf("parent is not an object or array")
+	}
+	return root, nil
+}
+
+func (n *jsonNode) getChild(token string) (*jsonNode, error) {
+	switch n.kind {
+	case '{':
+		idx := n.keyIndex(token)
+		if idx == -1 {
+			return nil, fmt.Errorf("key %q not found", token)
+		}
+		return n.elems[idx], nil
+	case '[':
+		idx, err := strconv.Atoi(token)
+		if err != nil || idx < 0 || idx >= len(n.elems) {
+			return nil, fmt.Errorf("invalid array index %q", token)
+		}
+		return n.elems[idx], nil
+	default:
+		return nil, fmt.Errorf("cannot index into a scalar")
+	}
+}
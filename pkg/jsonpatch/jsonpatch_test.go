@@ -0,0 +1,92 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func TestJsonPatchRoundTrip(t *testing.T) {
+	type inner struct {
+		Count int               `json:"count"`
+		Tags  []string          `json:"tags,omitempty"`
+		Attrs map[string]string `json:"attrs,omitempty"`
+	}
+	type doc struct {
+		Name  string  `json:"name"`
+		Inner inner   `json:"inner"`
+		List  []inner `json:"list"`
+		Ptr   *inner  `json:"ptr"`
+	}
+	base := doc{Name: "a/b~c", Inner: inner{Count: 1, Tags: []string{"x"}}, List: []inner{{Count: 1}, {Count: 2}}}
+	tests := []struct {
+		name   string
+		change func(d doc) doc
+	}{
+		{name: "no change", change: func(d doc) doc { return d }},
+		{name: "scalar change", change: func(d doc) doc { d.Inner.Count = 2; return d }},
+		{name: "string with escaped chars", change: func(d doc) doc { d.Name = "<tag> & \"quote\""; return d }},
+		{name: "array grows", change: func(d doc) doc { d.List = append(d.List, inner{Count: 3}); return d }},
+		{name: "array shrinks", change: func(d doc) doc { d.List = d.List[:0]; return d }},
+		{name: "key removed", change: func(d doc) doc { d.Inner.Tags = nil; return d }},
+		{name: "key added", change: func(d doc) doc { d.Inner.Attrs = map[string]string{"b": "2", "a": "1"}; return d }},
+		{name: "null to object", change: func(d doc) doc { d.Ptr = &inner{Count: 5}; return d }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldBarr, _ := json.Marshal(base)
+			newDoc := tt.change(base)
+			newBarr, _ := json.Marshal(newDoc)
+			ops, err := Make(string(oldBarr), string(newBarr))
+			if err != nil {
+				t.Fatalf("Make error: %v", err)
+			}
+			if string(oldBarr) == string(newBarr) && len(ops) != 0 {
+				t.Errorf("expected no ops for an unchanged document, got %+v", ops)
+			}
+			result, err := Apply(string(oldBarr), ops)
+			if err != nil {
+				t.Fatalf("Apply error: %v", err)
+			}
+			if result != string(newBarr) {
+				t.Errorf("result mismatch:\ngot  %s\nwant %s\nops %+v", result, newBarr, ops)
+			}
+		})
+	}
+}
+
+func TestJsonPatchSmallDiff(t *testing.T) {
+	vals := make([]int, 500)
+	oldBarr, _ := json.Marshal(map[string]any{"vals": vals, "n": 1})
+	vals[250] = 7
+	newBarr, _ := json.Marshal(map[string]any{"vals": vals, "n": 1})
+	ops, err := Make(string(oldBarr), string(newBarr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 1 || ops[0].Path != "/vals/250" || string(ops[0].Value) != "7" {
+		t.Errorf("unexpected ops %+v", ops)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	doc := `{"a":[1,2],"b":{"c":1}}`
+	badOps := [][]ds.JsonPatchOp{
+		{{Op: ds.JsonPatchOpReplace, Path: "/x/y", Value: json.RawMessage("1")}},
+		{{Op: ds.JsonPatchOpRemove, Path: "/a/5"}},
+		{{Op: ds.JsonPatchOpReplace, Path: "/b/c/d", Value: json.RawMessage("1")}},
+		{{Op: ds.JsonPatchOpReplace, Path: "/b/c", Value: json.RawMessage("{bad")}},
+	}
+	for _, ops := range badOps {
+		if _, err := Apply(doc, ops); err == nil {
+			t.Errorf("expected an error for %+v", ops)
+		}
+	}
+	if _, err := Apply("{bad", nil); err == nil {
+		t.Errorf("expected an error for an invalid document")
+	}
+}
@@ -0,0 +1,17 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !js && !wasip1 && !tinygo
+
+package platform
+
+const compilerName = "gc"
+
+// Connect is true when the SDK can connect to the Outrig server (domain socket or TCP)
+const Connect = true
+
+// StdioCapture is true when stdout/stderr can be captured (dup2 + an external tee process)
+const StdioCapture = true
+
+// GoRoutineStacks is true when runtime.Stack(buf, true) returns the stacks of all goroutines
+const GoRoutineStacks = true
@@ -0,0 +1,17 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build tinygo
+
+package platform
+
+const compilerName = "tinygo"
+
+// Connect is false, TinyGo's net package can't reach the Outrig server
+const Connect = false
+
+// StdioCapture is false, TinyGo doesn't support os/exec
+const StdioCapture = false
+
+// GoRoutineStacks is false, TinyGo's runtime.Stack doesn't return goroutine stacks
+const GoRoutineStacks = false
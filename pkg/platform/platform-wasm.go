@@ -0,0 +1,17 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build (js || wasip1) && !tinygo
+
+package platform
+
+const compilerName = "gc"
+
+// Connect is false, wasm has no domain sockets and no (real) TCP dialer
+const Connect = false
+
+// StdioCapture is false, there are no file descriptors to dup2 and no processes to exec
+const StdioCapture = false
+
+// GoRoutineStacks is true, the gc wasm runtime supports runtime.Stack(buf, true)
+const GoRoutineStacks = true
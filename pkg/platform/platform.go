@@ -0,0 +1,27 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package platform reports what the SDK can do on the target it was compiled for.
+// Restricted targets (js/wasm, wasip1, TinyGo) still compile and run, the collectors that
+// need unsupported runtime or OS features are stubbed out (see the build-tagged files).
+package platform
+
+import (
+	"runtime"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+// GetCapabilities returns the capability report for this build (sent to the server with the AppInfo)
+func GetCapabilities() ds.SDKCapabilities {
+	return ds.SDKCapabilities{
+		Platform:        runtime.GOOS + "/" + runtime.GOARCH,
+		Compiler:        compilerName,
+		Restricted:      !(Connect && StdioCapture && GoRoutineStacks),
+		Connect:         Connect,
+		StdioCapture:    StdioCapture,
+		GoRoutineStacks: GoRoutineStacks,
+		// matches the build tags of the fdstats collector
+		FDStats: runtime.GOOS == "linux" || runtime.GOOS == "darwin",
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package redact removes sensitive values (passwords, tokens, etc.) from log lines and watch values
+// before they are sent to the Outrig server (see config.RedactConfig)
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/outrigdev/outrig/pkg/config"
+)
+
+const DefaultReplacement = "[REDACTED]"
+
+// the value of a field: a quoted string, an auth scheme and its credentials (for "Authorization: Bearer xyz"),
+// or everything up to a separator
+const fieldValueReStr = `"(?:[^"\\]|\\.)*"|'[^']*'|(?:bearer|basic|token|digest)\s+[^\s,;&"'})\]]+|[^\s,;&"'})\]]+`
+
+type Redactor struct {
+	patterns    []*regexp.Regexp
+	fieldRe     *regexp.Regexp // group 1 is the field name and separator, group 2 is the value
+	replacement string
+	count       atomic.Int64
+}
+
+var (
+	defaultRedactor   atomic.Pointer[Redactor]
+	defaultConfigJson atomic.Pointer[string] // the config of defaultRedactor (passed to the log capture process)
+)
+
+// MakeRedactor compiles the redaction config, it returns nil (no redaction) if the config has no patterns or fields
+func MakeRedactor(cfg config.RedactConfig) (*Redactor, error) {
+	if len(cfg.Patterns) == 0 && len(cfg.Fields) == 0 {
+		return nil, nil
+	}
+	r := &Redactor{replacement: cfg.Replacement}
+	if r.replacement == "" {
+		r.replacement = DefaultReplacement
+	}
+	for _, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	var fieldNames []string
+	for _, field := range cfg.Fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		fieldNames = append(fieldNames, regexp.QuoteMeta(field))
+	}
+	if len(fieldNames) > 0 {
+		// matches password=x, password: x, "password": "x", and Password:x (%+v structs)
+		fieldReStr := `(?i)(["']?\b(?:` + strings.Join(fieldNames, "|") + `)\b["']?\s*[:=]\s*)(` + fieldValueReStr + `)`
+		r.fieldRe = regexp.MustCompile(fieldReStr)
+	}
+	return r, nil
+}
+
+// Init sets the redactor used by RedactString (called once from outrig.Init)
+func Init(cfg *config.RedactConfig) error {
+	r, err := MakeRedactor(*cfg)
+	if err != nil {
+		return err
+	}
+	defaultRedactor.Store(r)
+	if r != nil {
+		barr, err := json.Marshal(cfg)
+		if err != nil {
+			return err
+		}
+		configJson := string(barr)
+		defaultConfigJson.Store(&configJson)
+	}
+	return nil
+}
+
+// GetConfigJson returns the JSON of the config set by Init ("" if there is no redaction)
+func GetConfigJson() string {
+	configJson := defaultConfigJson.Load()
+	if configJson == nil {
+		return ""
+	}
+	return *configJson
+}
+
+// RedactString redacts s with the redactor set by Init
+func RedactString(s string) string {
+	return defaultRedactor.Load().Redact(s)
+}
+
+// GetRedactedCount returns the number of values redacted by RedactString
+func GetRedactedCount() int64 {
+	return defaultRedactor.Load().Count()
+}
+
+// Redact returns s with the matches of the patterns and the values of the fields replaced
+// (a nil Redactor returns s unchanged)
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	var numRedacted int64
+	if r.fieldRe != nil {
+		s = r.fieldRe.ReplaceAllStringFunc(s, func(match string) string {
+			numRedacted++
+			submatches := r.fieldRe.FindStringSubmatch(match)
+			value := submatches[2]
+			if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+				return submatches[1] + value[:1] + r.replacement + value[len(value)-1:]
+			}
+			return submatches[1] + r.replacement
+		})
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllStringFunc(s, func(string) string {
+			numRedacted++
+			return r.replacement
+		})
+	}
+	if numRedacted > 0 {
+		r.count.Add(numRedacted)
+	}
+	return s
+}
+
+// Count returns the number of values redacted (0 for a nil Redactor)
+func (r *Redactor) Count() int64 {
+	if r == nil {
+		return 0
+	}
+	return r.count.Load()
+}
@@ -0,0 +1,64 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package redact
+
+import (
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/config"
+)
+
+func TestRedactFields(t *testing.T) {
+	r, err := MakeRedactor(config.RedactConfig{Fields: []string{"password", "Authorization"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"login user=bob password=hunter2 ok", "login user=bob password=[REDACTED] ok"},
+		{`{"user":"bob","password":"hun\"ter2","n":1}`, `{"user":"bob","password":"[REDACTED]","n":1}`},
+		{"{User:bob Password:hunter2}", "{User:bob Password:[REDACTED]}"},
+		{"map[password:hunter2 user:bob]", "map[password:[REDACTED] user:bob]"},
+		{"Authorization: Bearer abc.def.ghi", "Authorization: [REDACTED]"},
+		{"GET /login?password=x&next=/", "GET /login?password=[REDACTED]&next=/"},
+		{"password_hint=birthday passwords=1", "password_hint=birthday passwords=1"},
+		{"nothing to see", "nothing to see"},
+	}
+	for _, tt := range tests {
+		if result := r.Redact(tt.input); result != tt.expected {
+			t.Errorf("Redact(%q) = %q, expected %q", tt.input, result, tt.expected)
+		}
+	}
+	if r.Count() != 6 {
+		t.Errorf("expected 6 redactions, got %d", r.Count())
+	}
+}
+
+func TestRedactPatterns(t *testing.T) {
+	r, err := MakeRedactor(config.RedactConfig{Patterns: []string{`\b\d{4}-\d{4}-\d{4}-\d{4}\b`, `sk_live_[A-Za-z0-9]+`}, Replacement: "***"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := r.Redact("card 1234-5678-9012-3456 key sk_live_abc123 and 1234-5678-9012-3457")
+	expected := "card *** key *** and ***"
+	if result != expected {
+		t.Errorf("got %q, expected %q", result, expected)
+	}
+	if r.Count() != 3 {
+		t.Errorf("expected 3 redactions, got %d", r.Count())
+	}
+
+	if _, err := MakeRedactor(config.RedactConfig{Patterns: []string{"("}}); err == nil {
+		t.Errorf("expected an error for an invalid pattern")
+	}
+	if r, err := MakeRedactor(config.RedactConfig{}); r != nil || err != nil {
+		t.Errorf("expected a nil redactor for an empty config, got %v, %v", r, err)
+	}
+	var nilRedactor *Redactor
+	if nilRedactor.Redact("password=x") != "password=x" || nilRedactor.Count() != 0 {
+		t.Errorf("a nil redactor should not redact")
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package stackparse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// MaxLineLength is the longest stack trace line a Scanner accepts (longer lines stop the scan with bufio.ErrTooLong)
+const MaxLineLength = 1024 * 1024
+
+// Scanner reads the goroutines of a multi-goroutine stack dump (e.g. runtime.Stack(buf, true), or the output
+// of a crashed program) one at a time. Only the current goroutine is buffered, so dumps of any size can be
+// parsed from a stream. Text before the first goroutine header (a panic message) is skipped.
+//
+//	scanner := stackparse.NewScanner(reader)
+//	for scanner.Scan() {
+//		routine := scanner.Goroutine()
+//		...
+//	}
+//	if err := scanner.Err(); err != nil {
+//		...
+//	}
+type Scanner struct {
+	lines      *bufio.Scanner
+	header     Header
+	block      bytes.Buffer // the current goroutine, including the header line
+	bodyStart  int          // offset of the stack trace in block
+	nextHeader Header
+	nextLine   []byte // the header line of the next goroutine (read while finding the end of the current one)
+	hasNext    bool
+}
+
+// NewScanner returns a Scanner that reads a stack dump from r
+func NewScanner(r io.Reader) *Scanner {
+	lines := bufio.NewScanner(r)
+	lines.Buffer(make([]byte, 0, 64*1024), MaxLineLength)
+	return &Scanner{lines: lines}
+}
+
+// Scan advances to the next goroutine, it returns false at the end of the input or on a read error
+func (s *Scanner) Scan() bool {
+	s.block.Reset()
+	found := false
+	if s.hasNext {
+		s.setHeader(s.nextHeader, s.nextLine)
+		s.hasNext = false
+		found = true
+	}
+	for s.lines.Scan() {
+		line := s.lines.Bytes()
+		if bytes.HasPrefix(line, []byte("goroutine ")) {
+			if header, ok := ParseHeader(string(line)); ok {
+				if found {
+					s.nextHeader = header
+					s.nextLine = append(s.nextLine[:0], line...)
+					s.hasNext = true
+					return true
+				}
+				s.setHeader(header, line)
+				found = true
+				continue
+			}
+		}
+		if found {
+			s.block.Write(line)
+			s.block.WriteByte('\n')
+		}
+	}
+	return found
+}
+
+func (s *Scanner) setHeader(header Header, line []byte) {
+	s.header = header
+	s.block.Write(line)
+	s.block.WriteByte('\n')
+	s.bodyStart = s.block.Len()
+}
+
+// Err returns the first read error (nil at the end of the input)
+func (s *Scanner) Err() error {
+	return s.lines.Err()
+}
+
+// Header returns the parsed header of the current goroutine
+func (s *Scanner) Header() Header {
+	return s.header
+}
+
+// Raw returns the text of the current goroutine, including the header line. The slice is only valid
+// until the next call to Scan.
+func (s *Scanner) Raw() []byte {
+	return s.block.Bytes()
+}
+
+// StackTrace returns the stack trace of the current goroutine (without the header line), with surrounding
+// whitespace trimmed
+func (s *Scanner) StackTrace() string {
+	return string(bytes.TrimSpace(s.block.Bytes()[s.bodyStart:]))
+}
+
+// Goroutine parses the current goroutine
+func (s *Scanner) Goroutine() Goroutine {
+	return parseWithHeader(s.header, s.StackTrace())
+}
@@ -0,0 +1,139 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package stackparse
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const testDump = `goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+goroutine 38 [IO wait, 5 minutes]{job: sync}:
+internal/poll.runtime_pollWait(0x1010b0a98, 0x72)
+	/opt/homebrew/Cellar/go/1.23.4/libexec/src/runtime/netpoll.go:351 +0xa0
+created by main.main in goroutine 1
+	/app/main.go:8 +0x3dc
+
+goroutine 40 gp=0x14000002380 m=nil [chan receive]:
+main.worker()
+	/app/worker.go:20 +0x24
+`
+
+func TestScanner(t *testing.T) {
+	scanner := NewScanner(strings.NewReader("panic: boom\n\n" + testDump))
+	var routines []Goroutine
+	var raws []string
+	for scanner.Scan() {
+		routines = append(routines, scanner.Goroutine())
+		raws = append(raws, string(scanner.Raw()))
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if len(routines) != 3 {
+		t.Fatalf("expected 3 goroutines, got %d", len(routines))
+	}
+	expectedIds := []int64{1, 38, 40}
+	for idx, routine := range routines {
+		if routine.GoId != expectedIds[idx] {
+			t.Errorf("goroutine %d: GoId = %d, expected %d", idx, routine.GoId, expectedIds[idx])
+		}
+		if len(routine.Frames) != 1 {
+			t.Errorf("goroutine %d: expected 1 frame, got %d", idx, len(routine.Frames))
+		}
+		if !strings.HasPrefix(raws[idx], "goroutine ") || strings.HasSuffix(routine.RawStackTrace, "\n") {
+			t.Errorf("goroutine %d: unexpected raw text %q / stack trace %q", idx, raws[idx], routine.RawStackTrace)
+		}
+	}
+	if routines[1].RawLabels != "{job: sync}" || routines[1].CreatedByGoId != 1 || routines[1].StateDuration != "5 minutes" {
+		t.Errorf("unexpected goroutine 38: %+v", routines[1])
+	}
+	if routines[2].RawState != "chan receive" {
+		t.Errorf("RawState = %q, expected %q", routines[2].RawState, "chan receive")
+	}
+}
+
+func TestScannerRuntimeStack(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	for i := 0; i < 10; i++ {
+		go func() { <-done }()
+	}
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+
+	scanner := NewScanner(bytes.NewReader(buf))
+	count := 0
+	foundTest := false
+	for scanner.Scan() {
+		count++
+		for _, frame := range scanner.Goroutine().Frames {
+			if frame.Package == "github.com/outrigdev/outrig/pkg/stackparse" && frame.FuncName == "TestScannerRuntimeStack" {
+				foundTest = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if expected := bytes.Count(buf, []byte("\ngoroutine ")) + 1; count != expected {
+		t.Errorf("scanned %d goroutines, expected %d", count, expected)
+	}
+	if !foundTest {
+		t.Errorf("the test goroutine's frame wasn't found")
+	}
+}
+
+func TestScannerLongLine(t *testing.T) {
+	scanner := NewScanner(strings.NewReader("goroutine 1 [running]:\n" + strings.Repeat("x", MaxLineLength+1) + "\n"))
+	for scanner.Scan() {
+	}
+	if scanner.Err() == nil {
+		t.Errorf("expected an error for a line longer than MaxLineLength")
+	}
+}
+
+func FuzzScanner(f *testing.F) {
+	f.Add(testDump)
+	f.Add(testPanicDump)
+	f.Add("goroutine 1 [running]:\ngoroutine 2 [running]:\n")
+	f.Add(" goroutine 1 [running]:\nmain.main()\n")
+	f.Fuzz(func(t *testing.T, dump string) {
+		var expectedIds []int64
+		for _, line := range strings.Split(dump, "\n") {
+			if header, ok := ParseHeader(line); ok {
+				expectedIds = append(expectedIds, header.GoId)
+			}
+		}
+		scanner := NewScanner(strings.NewReader(dump))
+		var ids []int64
+		for scanner.Scan() {
+			routine := scanner.Goroutine()
+			if routine.GoId != scanner.Header().GoId {
+				t.Errorf("Goroutine().GoId %d doesn't match the header %d", routine.GoId, scanner.Header().GoId)
+			}
+			// the raw text of a goroutine parses to the same goroutine
+			if parsed, ok := ParseGoroutine(string(scanner.Raw())); !ok || parsed.GoId != routine.GoId || len(parsed.Frames) != len(routine.Frames) {
+				t.Errorf("ParseGoroutine(Raw()) = %+v, expected %+v", parsed, routine)
+			}
+			ids = append(ids, routine.GoId)
+		}
+		if scanner.Err() != nil {
+			return
+		}
+		if len(ids) != len(expectedIds) {
+			t.Fatalf("scanned %d goroutines, expected %d", len(ids), len(expectedIds))
+		}
+		for idx := range ids {
+			if ids[idx] != expectedIds[idx] {
+				t.Errorf("goroutine %d: GoId = %d, expected %d", idx, ids[idx], expectedIds[idx])
+			}
+		}
+	})
+}
@@ -0,0 +1,348 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package stackparse parses Go goroutine stack traces, as printed by runtime.Stack, debug.Stack, and
+// unrecovered panics. ParseGoroutine and ParseStackTrace parse a single goroutine, and Scanner reads a
+// multi-goroutine dump incrementally from an io.Reader (so large dumps don't have to be held in memory).
+// The package has no dependencies outside the standard library.
+package stackparse
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Frame is a parsed stack frame (a function line and its file line)
+type Frame struct {
+	Package    string `json:"package"`
+	FuncName   string `json:"funcname"`           // includes the receiver, e.g. "(*FD).Read", "main.func1"
+	FuncArgs   string `json:"funcargs,omitempty"` // raw argument words, e.g. "0x140003801e0, {0x140003ae723, 0x8dd}" or "..."
+	FilePath   string `json:"filepath"`
+	LineNumber int    `json:"linenumber"`
+	PCOffset   string `json:"pcoffset,omitempty"` // e.g. "+0x1fc"
+}
+
+// Location returns the "file:line" location of the frame, or "" if the frame has no file line
+func (f Frame) Location() string {
+	if f.FilePath == "" {
+		return ""
+	}
+	return f.FilePath + ":" + strconv.Itoa(f.LineNumber)
+}
+
+// Header is a parsed goroutine header line, e.g. "goroutine 38 [IO wait, 5 minutes]:"
+type Header struct {
+	GoId      int64
+	State     string // the raw state, e.g. "IO wait, 5 minutes"
+	RawLabels string // the pprof label set printed after the state (Go 1.26+), e.g. "{job: sync}"
+}
+
+// Goroutine is a parsed goroutine stack trace
+type Goroutine struct {
+	GoId            int64             `json:"goid"`
+	RawState        string            `json:"rawstate"`
+	PrimaryState    string            `json:"primarystate"`
+	StateDurationMs int64             `json:"statedurationms,omitempty"`
+	StateDuration   string            `json:"stateduration,omitempty"`
+	RawLabels       string            `json:"rawlabels,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	RawStackTrace   string            `json:"rawstacktrace"` // the stack trace without the header line
+	Frames          []Frame           `json:"frames"`
+	CreatedBy       *Frame            `json:"createdby,omitempty"`
+	CreatedByGoId   int64             `json:"createdbygoid,omitempty"`
+}
+
+// headerRe matches a goroutine header line, the optional fields between the id and the state
+// ("gp=0x... m=0 mp=0x...") are printed with GOTRACEBACK=system
+var headerRe = regexp.MustCompile(`^goroutine (\d+)(?: [^\[]*)? \[([^\]]+)\](.*)$`)
+
+var fileLineRe = regexp.MustCompile(`^\s*(.*\.go):(\d+)(?:\s+(\+0x[0-9a-f]+))?$`)
+
+const createdByPrefix = "created by "
+const inGoroutineSep = " in goroutine "
+
+// ParseHeader parses a goroutine header line ("goroutine 1 [running]:", the trailing colon is required,
+// and the line must not be indented)
+func ParseHeader(line string) (Header, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if !strings.HasPrefix(line, "goroutine ") || !strings.HasSuffix(line, ":") {
+		return Header{}, false
+	}
+	match := headerRe.FindStringSubmatch(line)
+	if match == nil {
+		return Header{}, false
+	}
+	goId, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return Header{}, false
+	}
+	return Header{
+		GoId:      goId,
+		State:     match[2],
+		RawLabels: strings.TrimSpace(strings.TrimSuffix(match[3], ":")),
+	}, true
+}
+
+// ParseGoroutine parses the stack trace of a single goroutine, starting with its header line. Lines before the
+// header (e.g. a panic message) are skipped. Returns false if there is no header line.
+func ParseGoroutine(text string) (Goroutine, bool) {
+	for text != "" {
+		line, rest, _ := strings.Cut(text, "\n")
+		if header, ok := ParseHeader(line); ok {
+			return parseWithHeader(header, rest), true
+		}
+		text = rest
+	}
+	return Goroutine{}, false
+}
+
+func parseWithHeader(header Header, stackTrace string) Goroutine {
+	routine := ParseStackTrace(header.GoId, header.State, stackTrace)
+	routine.RawLabels = header.RawLabels
+	routine.Labels = ParseLabels(header.RawLabels)
+	return routine
+}
+
+// ParseStackTrace parses a goroutine stack trace that doesn't include the header line (goId and state come
+// from the header). Lines that aren't valid frames (e.g. "...additional frames elided...") are skipped.
+func ParseStackTrace(goId int64, state string, stackTrace string) Goroutine {
+	routine := Goroutine{
+		GoId:          goId,
+		RawState:      state,
+		RawStackTrace: strings.TrimSpace(stackTrace),
+	}
+	routine.PrimaryState, routine.StateDurationMs, routine.StateDuration = ParseState(state)
+	lines := strings.Split(stackTrace, "\n")
+	for i := 0; i < len(lines); i++ {
+		funcLine := strings.TrimSpace(lines[i])
+		if funcLine == "" {
+			continue
+		}
+		// the file line of a frame is indented
+		var fileLine string
+		if i+1 < len(lines) && isIndented(lines[i+1]) {
+			fileLine = strings.TrimSpace(lines[i+1])
+			i++
+		}
+		if strings.HasPrefix(funcLine, createdByPrefix) {
+			if frame, createdByGoId, ok := ParseCreatedByFrame(funcLine, fileLine); ok {
+				routine.CreatedBy = frame
+				routine.CreatedByGoId = createdByGoId
+			}
+			continue
+		}
+		if frame, ok := ParseFrame(funcLine, fileLine); ok {
+			routine.Frames = append(routine.Frames, frame)
+		}
+	}
+	return routine
+}
+
+func isIndented(line string) bool {
+	return len(line) > 0 && (line[0] == ' ' || line[0] == '\t')
+}
+
+// ParseFrame parses a function line and its file line (which may be empty) into a Frame
+func ParseFrame(funcLine string, fileLine string) (Frame, bool) {
+	return parseFrame(funcLine, fileLine, true)
+}
+
+func parseFrame(funcLine string, fileLine string, argsRequired bool) (Frame, bool) {
+	frame := Frame{}
+	var ok bool
+	frame.Package, frame.FuncName, frame.FuncArgs, ok = ParseFuncLine(funcLine, argsRequired)
+	if !ok {
+		return frame, false
+	}
+	if fileLine != "" {
+		frame.FilePath, frame.LineNumber, frame.PCOffset, ok = ParseFileLine(fileLine)
+		if !ok {
+			return frame, false
+		}
+	}
+	return frame, true
+}
+
+// ParseCreatedByFrame parses the "created by" frame of a goroutine stack trace (fileLine may be empty),
+// it returns the frame and the id of the creating goroutine (0 if the line has no "in goroutine N", which
+// the runtime omits for goroutines created without a parent)
+func ParseCreatedByFrame(funcLine string, fileLine string) (*Frame, int64, bool) {
+	funcLine = strings.TrimSpace(funcLine)
+	if !strings.HasPrefix(funcLine, createdByPrefix) {
+		return nil, 0, false
+	}
+	funcLine = strings.TrimPrefix(funcLine, createdByPrefix)
+	var goId int64
+	if idx := strings.LastIndex(funcLine, inGoroutineSep); idx >= 0 {
+		var err error
+		goId, err = strconv.ParseInt(strings.TrimSpace(funcLine[idx+len(inGoroutineSep):]), 10, 64)
+		if err != nil {
+			return nil, 0, false
+		}
+		funcLine = funcLine[:idx]
+	}
+	frame, ok := parseFrame(funcLine, fileLine, false)
+	if !ok || strings.ContainsAny(frame.FuncName, " \t") {
+		return nil, 0, false
+	}
+	return &frame, goId, true
+}
+
+// ParseFileLine parses a file line from a stack trace, returning the file path, line number, and PC offset
+// Example: /opt/homebrew/Cellar/go/1.23.4/libexec/src/internal/poll/fd_unix.go:165 +0x1fc
+func ParseFileLine(fileLine string) (string, int, string, bool) {
+	match := fileLineRe.FindStringSubmatch(fileLine)
+	if match == nil {
+		return "", 0, "", false
+	}
+	lineNumber, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, "", false
+	}
+	return match[1], lineNumber, match[3], true
+}
+
+// ParseFuncLine parses a function line from a stack trace and extracts the package, function name, and args.
+// The function name includes the receiver if present. If argsRequired is set, lines without an argument
+// list are rejected ("created by" lines don't have one).
+func ParseFuncLine(funcLine string, argsRequired bool) (pkgName string, funcName string, funcArgs string, valid bool) {
+	// the arguments are in the last parens
+	if strings.HasSuffix(funcLine, ")") {
+		lastOpenParenIdx := strings.LastIndex(funcLine, "(")
+		if lastOpenParenIdx < 0 {
+			return "", "", "", false
+		}
+		funcArgs = funcLine[lastOpenParenIdx+1 : len(funcLine)-1]
+		funcLine = funcLine[:lastOpenParenIdx]
+	} else if argsRequired {
+		return "", "", "", false
+	}
+
+	// the package name ends at the first "." after the final "/"
+	finalSlashIdx := strings.LastIndex(funcLine, "/")
+	if finalSlashIdx < 0 {
+		finalSlashIdx = 0 // a package like "os" won't have any slashes
+	}
+	firstDotIdx := strings.Index(funcLine[finalSlashIdx:], ".")
+	if firstDotIdx < 0 {
+		return "", "", "", false
+	}
+	firstDotIdx += finalSlashIdx
+	return funcLine[:firstDotIdx], funcLine[firstDotIdx+1:], funcArgs, true
+}
+
+// ParsePrimaryState returns the primary state of a raw goroutine state (e.g. "chan receive" for "chan receive, 5 minutes")
+func ParsePrimaryState(rawState string) string {
+	primaryState, _, _ := ParseState(rawState)
+	return primaryState
+}
+
+// ParseState parses a raw goroutine state (e.g. "chan receive, 5 minutes, locked to thread") into the primary
+// state, the wait duration in milliseconds, and the raw wait duration ("5 minutes"). Other components are ignored.
+func ParseState(rawState string) (string, int64, string) {
+	components := strings.Split(rawState, ",")
+	primaryState := strings.TrimSpace(components[0])
+	var stateDurationMs int64
+	var stateDuration string
+	for _, component := range components[1:] {
+		component = strings.TrimSpace(component)
+		if durationMs, ok := parseDuration(component); ok {
+			stateDurationMs = durationMs
+			stateDuration = component
+		}
+	}
+	return primaryState, stateDurationMs, stateDuration
+}
+
+type durationPattern struct {
+	regex      *regexp.Regexp
+	multiplier int64
+}
+
+// durationPatterns are the wait duration formats in goroutine states (the runtime prints minutes, the
+// others are accepted for dumps from other tools)
+var durationPatterns = []durationPattern{
+	{regexp.MustCompile(`^(\d+)\s*days?$`), 24 * 60 * 60 * 1000},
+	{regexp.MustCompile(`^(\d+)\s*hours?$`), 60 * 60 * 1000},
+	{regexp.MustCompile(`^(\d+)\s*minutes?$`), 60 * 1000},
+	{regexp.MustCompile(`^(\d+)\s*seconds?$`), 1000},
+	{regexp.MustCompile(`^(\d+)\s*(milliseconds?|ms)$`), 1},
+	{regexp.MustCompile(`^(\d+)\s*(microseconds?|us|µs)$`), 0}, // effectively 0ms
+	{regexp.MustCompile(`^(\d+)\s*(nanoseconds?|ns)$`), 0},     // effectively 0ms
+}
+
+// parseDuration converts a wait duration to milliseconds
+func parseDuration(s string) (int64, bool) {
+	for _, pattern := range durationPatterns {
+		if match := pattern.regex.FindStringSubmatch(s); match != nil {
+			if value, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+				return value * pattern.multiplier, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// ParseLabels parses the pprof label set that the runtime prints in the goroutine header after the
+// state (Go 1.27+, or Go 1.26 with GODEBUG=tracebacklabels=1), e.g. `{job: sync, "user id": "a b"}`.
+// Keys and values with characters other than letters, digits, '.', '/', and '_' are quoted.
+// Returns nil if rawLabels is empty or not a valid label set.
+func ParseLabels(rawLabels string) map[string]string {
+	s := strings.TrimSpace(rawLabels)
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return nil
+	}
+	s = s[1 : len(s)-1]
+	labels := make(map[string]string)
+	for s != "" {
+		key, rest, ok := readLabelString(s)
+		if !ok || !strings.HasPrefix(rest, ": ") {
+			return nil
+		}
+		value, rest, ok := readLabelString(rest[2:])
+		if !ok {
+			return nil
+		}
+		labels[key] = value
+		if rest == "" {
+			break
+		}
+		if !strings.HasPrefix(rest, ", ") {
+			return nil
+		}
+		s = rest[2:]
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	return labels
+}
+
+// readLabelString reads a (possibly quoted) label key or value from the start of s, it returns the
+// unquoted string and the rest of s. Unquoted strings may be empty.
+func readLabelString(s string) (string, string, bool) {
+	if strings.HasPrefix(s, `"`) {
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '"' {
+				str, err := strconv.Unquote(s[:i+1])
+				if err != nil {
+					return "", "", false
+				}
+				return str, s[i+1:], true
+			}
+		}
+		return "", "", false
+	}
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '/' || r == '_')
+	})
+	if end < 0 {
+		end = len(s)
+	}
+	return s[:end], s[end:], true
+}
@@ -0,0 +1,709 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package stackparse
+
+import (
+	"maps"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseFrame(t *testing.T) {
+	tests := []struct {
+		name          string
+		funcLine      string
+		fileLine      string
+		expectSuccess bool
+		expectedFrame Frame
+	}{
+		{
+			name:          "Method with receiver",
+			funcLine:      "internal/poll.(*FD).Read(0x140003801e0, {0x140003ae723, 0x8dd, 0x8dd})",
+			fileLine:      "/opt/homebrew/Cellar/go/1.23.4/libexec/src/internal/poll/fd_unix.go:165 +0x1fc",
+			expectSuccess: true,
+			expectedFrame: Frame{
+				Package:    "internal/poll",
+				FuncName:   "(*FD).Read",
+				FuncArgs:   "0x140003801e0, {0x140003ae723, 0x8dd, 0x8dd}",
+				FilePath:   "/opt/homebrew/Cellar/go/1.23.4/libexec/src/internal/poll/fd_unix.go",
+				LineNumber: 165,
+				PCOffset:   "+0x1fc",
+			},
+		},
+		{
+			name:          "Function without receiver",
+			funcLine:      "runtime.doInit(0x12f7be0)",
+			fileLine:      "/opt/homebrew/Cellar/go/1.23.4/libexec/src/runtime/proc.go:6329",
+			expectSuccess: true,
+			expectedFrame: Frame{
+				Package:    "runtime",
+				FuncName:   "doInit",
+				FuncArgs:   "0x12f7be0",
+				FilePath:   "/opt/homebrew/Cellar/go/1.23.4/libexec/src/runtime/proc.go",
+				LineNumber: 6329,
+				PCOffset:   "",
+			},
+		},
+		{
+			name:          "Function with dots in package name",
+			funcLine:      "github.com/outrigdev/outrig/pkg/rpc.(*WshRouter).RegisterRoute.func2()",
+			fileLine:      "/Users/mike/work/outrig/pkg/rpc/rpcrouter.go:326 +0x14c",
+			expectSuccess: true,
+			expectedFrame: Frame{
+				Package:    "github.com/outrigdev/outrig/pkg/rpc",
+				FuncName:   "(*WshRouter).RegisterRoute.func2",
+				FuncArgs:   "",
+				FilePath:   "/Users/mike/work/outrig/pkg/rpc/rpcrouter.go",
+				LineNumber: 326,
+				PCOffset:   "+0x14c",
+			},
+		},
+		{
+			name:          "Function with ellipsis",
+			funcLine:      "internal/poll.(*pollDesc).waitRead(...)",
+			fileLine:      "/opt/homebrew/Cellar/go/1.23.4/libexec/src/internal/poll/fd_poll_runtime.go:89",
+			expectSuccess: true,
+			expectedFrame: Frame{
+				Package:    "internal/poll",
+				FuncName:   "(*pollDesc).waitRead",
+				FuncArgs:   "...",
+				FilePath:   "/opt/homebrew/Cellar/go/1.23.4/libexec/src/internal/poll/fd_poll_runtime.go",
+				LineNumber: 89,
+				PCOffset:   "",
+			},
+		},
+		{
+			name:          "Main function",
+			funcLine:      "main.main()",
+			fileLine:      "/Users/mike/work/outrig/server/main-server.go:291 +0x714",
+			expectSuccess: true,
+			expectedFrame: Frame{
+				Package:    "main",
+				FuncName:   "main",
+				FuncArgs:   "",
+				FilePath:   "/Users/mike/work/outrig/server/main-server.go",
+				LineNumber: 291,
+				PCOffset:   "+0x714",
+			},
+		},
+		{
+			name:          "Invalid function line",
+			funcLine:      "this is not a valid function line",
+			fileLine:      "/opt/homebrew/Cellar/go/1.23.4/libexec/src/runtime/proc.go:6329",
+			expectSuccess: false,
+		},
+		{
+			name:          "Invalid file line",
+			funcLine:      "runtime.doInit(0x12f7be0)",
+			fileLine:      "this is not a valid file line",
+			expectSuccess: false,
+		},
+		{
+			name:          "Method with value receiver",
+			funcLine:      "time.Time.Add(0x140003801e0, 0x140003ae723)",
+			fileLine:      "/opt/homebrew/Cellar/go/1.23.4/libexec/src/time/time.go:1076 +0x1a4",
+			expectSuccess: true,
+			expectedFrame: Frame{
+				Package:    "time",
+				FuncName:   "Time.Add",
+				FuncArgs:   "0x140003801e0, 0x140003ae723",
+				FilePath:   "/opt/homebrew/Cellar/go/1.23.4/libexec/src/time/time.go",
+				LineNumber: 1076,
+				PCOffset:   "+0x1a4",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, ok := ParseFrame(tt.funcLine, tt.fileLine)
+			if ok != tt.expectSuccess {
+				t.Fatalf("ParseFrame() success = %v, expected %v", ok, tt.expectSuccess)
+			}
+			if !tt.expectSuccess {
+				return
+			}
+			if frame.Package != tt.expectedFrame.Package {
+				t.Errorf("Package = %q, expected %q", frame.Package, tt.expectedFrame.Package)
+			}
+			if frame.FuncName != tt.expectedFrame.FuncName {
+				t.Errorf("FuncName = %q, expected %q", frame.FuncName, tt.expectedFrame.FuncName)
+			}
+			if frame.FuncArgs != tt.expectedFrame.FuncArgs {
+				t.Errorf("Args = %q, expected %q", frame.FuncArgs, tt.expectedFrame.FuncArgs)
+			}
+			if frame.FilePath != tt.expectedFrame.FilePath {
+				t.Errorf("FilePath = %q, expected %q", frame.FilePath, tt.expectedFrame.FilePath)
+			}
+			if frame.LineNumber != tt.expectedFrame.LineNumber {
+				t.Errorf("LineNumber = %d, expected %d", frame.LineNumber, tt.expectedFrame.LineNumber)
+			}
+			if frame.PCOffset != tt.expectedFrame.PCOffset {
+				t.Errorf("PCOffset = %q, expected %q", frame.PCOffset, tt.expectedFrame.PCOffset)
+			}
+		})
+	}
+}
+
+func TestParseState(t *testing.T) {
+	tests := []struct {
+		name               string
+		rawState           string
+		expectedPrimary    string
+		expectedDurationMs int64
+		expectedDuration   string
+	}{
+		{
+			name:               "Simple state",
+			rawState:           "running",
+			expectedPrimary:    "running",
+			expectedDurationMs: 0,
+			expectedDuration:   "",
+		},
+		{
+			name:               "State with duration",
+			rawState:           "chan receive, 101 minutes",
+			expectedPrimary:    "chan receive",
+			expectedDurationMs: 101 * 60 * 1000,
+			expectedDuration:   "101 minutes",
+		},
+		{
+			name:               "State with seconds duration",
+			rawState:           "chan receive, 45 seconds",
+			expectedPrimary:    "chan receive",
+			expectedDurationMs: 45 * 1000,
+			expectedDuration:   "45 seconds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primaryState, durationMs, duration := ParseState(tt.rawState)
+
+			if primaryState != tt.expectedPrimary {
+				t.Errorf("Expected primary state %q, got %q", tt.expectedPrimary, primaryState)
+			}
+
+			if durationMs != tt.expectedDurationMs {
+				t.Errorf("Expected duration %d ms, got %d ms", tt.expectedDurationMs, durationMs)
+			}
+
+			if duration != tt.expectedDuration {
+				t.Errorf("Expected duration string %q, got %q", tt.expectedDuration, duration)
+			}
+		})
+	}
+}
+
+func TestParseFileLine(t *testing.T) {
+	tests := []struct {
+		name             string
+		fileLine         string
+		expectSuccess    bool
+		expectedFilePath string
+		expectedLine     int
+		expectedPCOffset string
+	}{
+		{
+			name:             "Standard file line with PC offset",
+			fileLine:         "/opt/homebrew/Cellar/go/1.23.4/libexec/src/internal/poll/fd_unix.go:165 +0x1fc",
+			expectSuccess:    true,
+			expectedFilePath: "/opt/homebrew/Cellar/go/1.23.4/libexec/src/internal/poll/fd_unix.go",
+			expectedLine:     165,
+			expectedPCOffset: "+0x1fc",
+		},
+		{
+			name:             "File line without PC offset",
+			fileLine:         "/opt/homebrew/Cellar/go/1.23.4/libexec/src/runtime/proc.go:6329",
+			expectSuccess:    true,
+			expectedFilePath: "/opt/homebrew/Cellar/go/1.23.4/libexec/src/runtime/proc.go",
+			expectedLine:     6329,
+			expectedPCOffset: "",
+		},
+		{
+			name:             "File line with leading whitespace",
+			fileLine:         "  /Users/mike/work/outrig/pkg/rpc/rpcrouter.go:326 +0x14c",
+			expectSuccess:    true,
+			expectedFilePath: "/Users/mike/work/outrig/pkg/rpc/rpcrouter.go",
+			expectedLine:     326,
+			expectedPCOffset: "+0x14c",
+		},
+		{
+			name:             "File line with different PC offset format",
+			fileLine:         "/Users/mike/work/outrig/pkg/collector/logprocess/loginitimpl.go:69 +0x3dc",
+			expectSuccess:    true,
+			expectedFilePath: "/Users/mike/work/outrig/pkg/collector/logprocess/loginitimpl.go",
+			expectedLine:     69,
+			expectedPCOffset: "+0x3dc",
+		},
+		{
+			name:          "Invalid file line - no line number",
+			fileLine:      "/opt/homebrew/Cellar/go/1.23.4/libexec/src/runtime/proc.go",
+			expectSuccess: false,
+		},
+		{
+			name:          "Invalid file line - not a go file",
+			fileLine:      "/opt/homebrew/Cellar/go/1.23.4/libexec/src/runtime/proc.c:123",
+			expectSuccess: false,
+		},
+		{
+			name:          "Invalid file line - non-numeric line number",
+			fileLine:      "/opt/homebrew/Cellar/go/1.23.4/libexec/src/runtime/proc.go:abc",
+			expectSuccess: false,
+		},
+		{
+			name:          "Empty file line",
+			fileLine:      "",
+			expectSuccess: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath, lineNumber, pcOffset, ok := ParseFileLine(tt.fileLine)
+
+			if ok != tt.expectSuccess {
+				t.Fatalf("ParseFileLine() success = %v, expected %v", ok, tt.expectSuccess)
+			}
+
+			if !tt.expectSuccess {
+				return
+			}
+
+			if filePath != tt.expectedFilePath {
+				t.Errorf("FilePath = %q, expected %q", filePath, tt.expectedFilePath)
+			}
+
+			if lineNumber != tt.expectedLine {
+				t.Errorf("LineNumber = %d, expected %d", lineNumber, tt.expectedLine)
+			}
+
+			if pcOffset != tt.expectedPCOffset {
+				t.Errorf("PCOffset = %q, expected %q", pcOffset, tt.expectedPCOffset)
+			}
+		})
+	}
+}
+
+func TestParseFuncLine(t *testing.T) {
+	tests := []struct {
+		name             string
+		funcLine         string
+		expectSuccess    bool
+		expectedPackage  string
+		expectedFuncName string
+		expectedArgs     string
+	}{
+		{
+			name:             "Method with pointer receiver",
+			funcLine:         "github.com/outrigdev/outrig/pkg/collector/runtimestats.(*RuntimeStatsCollector).Enable.func1()",
+			expectSuccess:    true,
+			expectedPackage:  "github.com/outrigdev/outrig/pkg/collector/runtimestats",
+			expectedFuncName: "(*RuntimeStatsCollector).Enable.func1",
+			expectedArgs:     "",
+		},
+		{
+			name:             "Method with arguments",
+			funcLine:         "github.com/outrigdev/outrig/pkg/collector/runtimestats.(*RuntimeStatsCollector).CollectRuntimeStats(0x14000136400)",
+			expectSuccess:    true,
+			expectedPackage:  "github.com/outrigdev/outrig/pkg/collector/runtimestats",
+			expectedFuncName: "(*RuntimeStatsCollector).CollectRuntimeStats",
+			expectedArgs:     "0x14000136400",
+		},
+		{
+			name:             "Method with ellipsis",
+			funcLine:         "main.Foo.footest(...)",
+			expectSuccess:    true,
+			expectedPackage:  "main",
+			expectedFuncName: "Foo.footest",
+			expectedArgs:     "...",
+		},
+		{
+			name:             "Simple function",
+			funcLine:         "runtime.doInit(0x12f7be0, ...)",
+			expectSuccess:    true,
+			expectedPackage:  "runtime",
+			expectedFuncName: "doInit",
+			expectedArgs:     "0x12f7be0, ...",
+		},
+		{
+			name:             "Function without arguments",
+			funcLine:         "main.main()",
+			expectSuccess:    true,
+			expectedPackage:  "main",
+			expectedFuncName: "main",
+			expectedArgs:     "",
+		},
+		{
+			name:          "Invalid function line - no dot",
+			funcLine:      "invalidfunctionline",
+			expectSuccess: false,
+		},
+		{
+			name:          "Invalid function line - dot at start",
+			funcLine:      ".invalidfunctionline",
+			expectSuccess: false,
+		},
+		{
+			name:             "Function with complex arguments",
+			funcLine:         "internal/poll.(*FD).Read(0x140003801e0, {0x140003ae723, 0x8dd, 0x8dd})",
+			expectSuccess:    true,
+			expectedPackage:  "internal/poll",
+			expectedFuncName: "(*FD).Read",
+			expectedArgs:     "0x140003801e0, {0x140003ae723, 0x8dd, 0x8dd}",
+		},
+		{
+			name:             "Method with value receiver",
+			funcLine:         "time.Time.Add(0x140003801e0, 0x140003ae723)",
+			expectSuccess:    true,
+			expectedPackage:  "time",
+			expectedFuncName: "Time.Add",
+			expectedArgs:     "0x140003801e0, 0x140003ae723",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packageName, funcName, args, ok := ParseFuncLine(tt.funcLine, true)
+
+			if ok != tt.expectSuccess {
+				t.Fatalf("ParseFuncLine() success = %v, expected %v", ok, tt.expectSuccess)
+			}
+
+			if !tt.expectSuccess {
+				return
+			}
+
+			if packageName != tt.expectedPackage {
+				t.Errorf("Package = %q, expected %q", packageName, tt.expectedPackage)
+			}
+
+			if funcName != tt.expectedFuncName {
+				t.Errorf("FuncName = %q, expected %q", funcName, tt.expectedFuncName)
+			}
+
+			if args != tt.expectedArgs {
+				t.Errorf("Args = %q, expected %q", args, tt.expectedArgs)
+			}
+		})
+	}
+}
+
+func TestParseCreatedByFrame(t *testing.T) {
+	tests := []struct {
+		name             string
+		funcLine         string
+		fileLine         string
+		expectSuccess    bool
+		expectedPackage  string
+		expectedFuncName string
+		expectedGoId     int64
+	}{
+		{
+			name:             "Standard created by line with goroutine ID",
+			funcLine:         "created by github.com/outrigdev/outrig/pkg/rpc.(*WshRouter).RegisterRoute in goroutine 327",
+			fileLine:         "/Users/mike/work/outrig/pkg/rpc/rpcrouter.go:315 +0x3cc",
+			expectSuccess:    true,
+			expectedPackage:  "github.com/outrigdev/outrig/pkg/rpc",
+			expectedFuncName: "(*WshRouter).RegisterRoute",
+			expectedGoId:     327,
+		},
+		{
+			name:             "Created by line with complex package name",
+			funcLine:         "created by github.com/outrigdev/outrig/pkg/collector/logprocess.(*LogCollector).initInternal in goroutine 1",
+			fileLine:         "/Users/mike/work/outrig/pkg/collector/logprocess/loginitimpl.go:69 +0x3dc",
+			expectSuccess:    true,
+			expectedPackage:  "github.com/outrigdev/outrig/pkg/collector/logprocess",
+			expectedFuncName: "(*LogCollector).initInternal",
+			expectedGoId:     1,
+		},
+		{
+			name:             "Created by line without file line",
+			funcLine:         "created by main.main in goroutine 1",
+			fileLine:         "",
+			expectSuccess:    true,
+			expectedPackage:  "main",
+			expectedFuncName: "main",
+			expectedGoId:     1,
+		},
+		{
+			name:          "Invalid created by line - missing prefix",
+			funcLine:      "github.com/outrigdev/outrig/pkg/rpc.(*WshRouter).RegisterRoute in goroutine 327",
+			fileLine:      "/Users/mike/work/outrig/pkg/rpc/rpcrouter.go:315 +0x3cc",
+			expectSuccess: false,
+		},
+		{
+			name:          "Invalid created by line - invalid function format",
+			funcLine:      "created by invalidfunction in goroutine 1",
+			fileLine:      "/Users/mike/work/outrig/pkg/rpc/rpcrouter.go:315 +0x3cc",
+			expectSuccess: false,
+		},
+		{
+			name:          "Invalid created by line - invalid goroutine ID",
+			funcLine:      "created by main.main in goroutine abc",
+			fileLine:      "/Users/mike/work/outrig/pkg/rpc/rpcrouter.go:315 +0x3cc",
+			expectSuccess: false,
+		},
+		{
+			name:          "Invalid file line",
+			funcLine:      "created by main.main in goroutine 1",
+			fileLine:      "this is not a valid file line",
+			expectSuccess: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, goId, ok := ParseCreatedByFrame(tt.funcLine, tt.fileLine)
+
+			if ok != tt.expectSuccess {
+				t.Fatalf("ParseCreatedByFrame() success = %v, expected %v", ok, tt.expectSuccess)
+			}
+
+			if !tt.expectSuccess {
+				return
+			}
+
+			if frame.Package != tt.expectedPackage {
+				t.Errorf("Package = %q, expected %q", frame.Package, tt.expectedPackage)
+			}
+
+			if frame.FuncName != tt.expectedFuncName {
+				t.Errorf("FuncName = %q, expected %q", frame.FuncName, tt.expectedFuncName)
+			}
+
+			if goId != tt.expectedGoId {
+				t.Errorf("GoId = %d, expected %d", goId, tt.expectedGoId)
+			}
+
+			if tt.fileLine != "" {
+				// Verify that file path and line number were parsed correctly
+				if frame.FilePath == "" {
+					t.Errorf("FilePath should not be empty")
+				}
+
+				if frame.LineNumber == 0 {
+					t.Errorf("LineNumber should not be zero")
+				}
+			}
+		})
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name      string
+		rawLabels string
+		expected  map[string]string
+	}{
+		{
+			name:      "No labels",
+			rawLabels: "",
+			expected:  nil,
+		},
+		{
+			name:      "Single label",
+			rawLabels: "{job: sync}",
+			expected:  map[string]string{"job": "sync"},
+		},
+		{
+			name:      "Multiple labels",
+			rawLabels: "{handler: /api/v1/users, request.id: 42}",
+			expected:  map[string]string{"handler": "/api/v1/users", "request.id": "42"},
+		},
+		{
+			name:      "Quoted keys and values",
+			rawLabels: `{"user id": "a, b", path: "x\"y\\z", tab: "a\tb"}`,
+			expected:  map[string]string{"user id": "a, b", "path": `x"y\z`, "tab": "a\tb"},
+		},
+		{
+			name:      "Empty value",
+			rawLabels: "{a: , b: c}",
+			expected:  map[string]string{"a": "", "b": "c"},
+		},
+		{
+			name:      "Not a label set",
+			rawLabels: "locked to thread",
+			expected:  nil,
+		},
+		{
+			name:      "Unterminated quote",
+			rawLabels: `{a: "b}`,
+			expected:  nil,
+		},
+		{
+			name:      "Missing separator",
+			rawLabels: "{a: b c: d}",
+			expected:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := ParseLabels(tt.rawLabels)
+			if !maps.Equal(labels, tt.expected) {
+				t.Errorf("ParseLabels(%q) = %v, expected %v", tt.rawLabels, labels, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		name          string
+		line          string
+		expectSuccess bool
+		expected      Header
+	}{
+		{
+			name:          "Simple header",
+			line:          "goroutine 1 [running]:",
+			expectSuccess: true,
+			expected:      Header{GoId: 1, State: "running"},
+		},
+		{
+			name:          "State with duration",
+			line:          "goroutine 338 [chan receive, 101 minutes]:",
+			expectSuccess: true,
+			expected:      Header{GoId: 338, State: "chan receive, 101 minutes"},
+		},
+		{
+			name:          "Header with labels",
+			line:          `goroutine 7 [select]{job: sync, "user id": "a b"}:`,
+			expectSuccess: true,
+			expected:      Header{GoId: 7, State: "select", RawLabels: `{job: sync, "user id": "a b"}`},
+		},
+		{
+			name:          "GOTRACEBACK=system header",
+			line:          "goroutine 1 gp=0x14000002380 m=0 mp=0x1010d9ce0 [running]:",
+			expectSuccess: true,
+			expected:      Header{GoId: 1, State: "running"},
+		},
+		{
+			name:          "Missing colon",
+			line:          "goroutine 1 [running]",
+			expectSuccess: false,
+		},
+		{
+			name:          "Not a header",
+			line:          "panic: goroutine 1 [running]:",
+			expectSuccess: false,
+		},
+		{
+			name:          "Invalid goroutine id",
+			line:          "goroutine 99999999999999999999 [running]:",
+			expectSuccess: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, ok := ParseHeader(tt.line)
+			if ok != tt.expectSuccess {
+				t.Fatalf("ParseHeader() success = %v, expected %v", ok, tt.expectSuccess)
+			}
+			if ok && header != tt.expected {
+				t.Errorf("ParseHeader() = %+v, expected %+v", header, tt.expected)
+			}
+		})
+	}
+}
+
+const testPanicDump = `panic: boom
+
+goroutine 38 [IO wait, 5 minutes]{job: sync}:
+internal/poll.runtime_pollWait(0x1010b0a98, 0x72)
+	/opt/homebrew/Cellar/go/1.23.4/libexec/src/runtime/netpoll.go:351 +0xa0
+internal/poll.(*pollDesc).waitRead(...)
+	/opt/homebrew/Cellar/go/1.23.4/libexec/src/internal/poll/fd_poll_runtime.go:89
+...additional frames elided...
+created by github.com/outrigdev/outrig/pkg/collector/logprocess.(*LogCollector).initInternal in goroutine 1
+	/Users/mike/work/outrig/pkg/collector/logprocess/loginitimpl.go:69 +0x3dc
+`
+
+func TestParseGoroutine(t *testing.T) {
+	routine, ok := ParseGoroutine(testPanicDump)
+	if !ok {
+		t.Fatalf("ParseGoroutine() failed")
+	}
+	if routine.GoId != 38 || routine.PrimaryState != "IO wait" || routine.StateDurationMs != 5*60*1000 {
+		t.Errorf("unexpected header fields: %+v", routine)
+	}
+	if !maps.Equal(routine.Labels, map[string]string{"job": "sync"}) {
+		t.Errorf("Labels = %v, expected job:sync", routine.Labels)
+	}
+	if len(routine.Frames) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(routine.Frames))
+	}
+	if routine.Frames[1].FuncName != "(*pollDesc).waitRead" || routine.Frames[1].Location() != "/opt/homebrew/Cellar/go/1.23.4/libexec/src/internal/poll/fd_poll_runtime.go:89" {
+		t.Errorf("unexpected frame: %+v", routine.Frames[1])
+	}
+	if routine.CreatedBy == nil || routine.CreatedBy.FuncName != "(*LogCollector).initInternal" || routine.CreatedByGoId != 1 {
+		t.Errorf("unexpected created by: %+v (goid %d)", routine.CreatedBy, routine.CreatedByGoId)
+	}
+
+	routine, ok = ParseGoroutine("goroutine 5 [running]:\nmain.main()\n\t/app/main.go:10 +0x1d\ncreated by runtime.gcenable\n\t/usr/local/go/src/runtime/mgc.go:204 +0x58\n")
+	if !ok || routine.CreatedBy == nil || routine.CreatedByGoId != 0 || routine.CreatedBy.Location() != "/usr/local/go/src/runtime/mgc.go:204" {
+		t.Errorf("created by without a parent goroutine: %+v", routine.CreatedBy)
+	}
+
+	if _, ok := ParseGoroutine("panic: boom\n"); ok {
+		t.Errorf("ParseGoroutine() succeeded without a header")
+	}
+}
+
+func FuzzParseGoroutine(f *testing.F) {
+	f.Add(testPanicDump)
+	f.Add("goroutine 1 [running]:\nmain.main()\n\t/app/main.go:10 +0x1d\n")
+	f.Add(`goroutine 7 [select]{"a\"b": c}:` + "\ncreated by main.main in goroutine 1\n")
+	f.Fuzz(func(t *testing.T, text string) {
+		routine, ok := ParseGoroutine(text)
+		if !ok {
+			return
+		}
+		for _, frame := range routine.Frames {
+			if frame.FilePath == "" && frame.LineNumber != 0 {
+				t.Errorf("frame has a line number without a file: %+v", frame)
+			}
+		}
+		if routine.CreatedBy == nil && routine.CreatedByGoId != 0 {
+			t.Errorf("CreatedByGoId %d set without a created by frame", routine.CreatedByGoId)
+		}
+		if routine.PrimaryState != ParsePrimaryState(routine.RawState) {
+			t.Errorf("PrimaryState %q doesn't match the raw state %q", routine.PrimaryState, routine.RawState)
+		}
+	})
+}
+
+// formatLabelString quotes a label key or value the way the runtime does
+func formatLabelString(s string) string {
+	if strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '/' || r == '_')
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func FuzzParseLabels(f *testing.F) {
+	f.Add("{job: sync}")
+	f.Add(`{"user id": "a, b", path: "x\"y\\z"}`)
+	f.Add("{a: , b: c}")
+	f.Fuzz(func(t *testing.T, rawLabels string) {
+		labels := ParseLabels(rawLabels)
+		if labels == nil {
+			return
+		}
+		// formatting the parsed labels and parsing them again must return the same labels
+		parts := make([]string, 0, len(labels))
+		for key, value := range labels {
+			parts = append(parts, formatLabelString(key)+": "+formatLabelString(value))
+		}
+		formatted := "{" + strings.Join(parts, ", ") + "}"
+		if reparsed := ParseLabels(formatted); !maps.Equal(reparsed, labels) {
+			t.Errorf("ParseLabels(%q) = %v, reparsing %q = %v", rawLabels, labels, formatted, reparsed)
+		}
+	})
+}
@@ -6,6 +6,7 @@ package utilds
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 )
 
@@ -188,6 +189,30 @@ func (tsa *TimeSampleAligner) GetLogicalTimeFromRealTimestamp(ts int64) int {
 	return tsa.baseLogical + len(tsa.ringBuffer) - 1
 }
 
+// GetLogicalTimeAtOrAfter returns the logical time of the first sample taken at or after ts (used to map an
+// event between two samples to the sample that first reflects it). If ts is after the last sample it returns
+// the next logical time (max+1), which will be the logical time of the next sample.
+func (tsa *TimeSampleAligner) GetLogicalTimeAtOrAfter(ts int64) int {
+	tsa.lock.Lock()
+	defer tsa.lock.Unlock()
+
+	if len(tsa.ringBuffer) == 0 {
+		return 0
+	}
+	if ts > tsa.ringBuffer[len(tsa.ringBuffer)-1] {
+		return tsa.baseLogical + len(tsa.ringBuffer)
+	}
+	if ts <= tsa.ringBuffer[0] {
+		// before the ring buffer, round the ideal slot up
+		idealSlot := int((ts - tsa.firstTs + 999) / 1000)
+		return max(min(idealSlot, tsa.baseLogical), 0)
+	}
+	idx := sort.Search(len(tsa.ringBuffer), func(i int) bool {
+		return tsa.ringBuffer[i] >= ts
+	})
+	return tsa.baseLogical + idx
+}
+
 func (tsa *TimeSampleAligner) GetFirstTimestamp() int64 {
 	tsa.lock.Lock()
 	defer tsa.lock.Unlock()
@@ -165,3 +165,35 @@ func TestTimeSampleAlignerGapFilling(t *testing.T) {
 		}
 	}
 }
+
+func TestTimeSampleAlignerAtOrAfter(t *testing.T) {
+	tsa := MakeTimeSampleAligner(3)
+	if logical := tsa.GetLogicalTimeAtOrAfter(5000); logical != 0 {
+		t.Errorf("empty aligner: expected logical time 0, got %d", logical)
+	}
+	for _, ts := range []int64{10000, 11000, 12000, 13000} {
+		if _, err := tsa.AddSample(ts); err != nil {
+			t.Fatalf("AddSample(%d) should not error: %v", ts, err)
+		}
+	}
+	// the ring buffer holds logical times 1-3 (11000, 12000, 13000)
+	tests := []struct {
+		timestamp       int64
+		expectedLogical int
+		description     string
+	}{
+		{11000, 1, "exactly on a sample maps to that sample"},
+		{11001, 2, "just after a sample maps to the next sample"},
+		{12500, 3, "between samples maps to the later sample"},
+		{13000, 3, "exactly on the last sample maps to the last sample"},
+		{13001, 4, "after the last sample maps to the next logical time"},
+		{10200, 1, "before the ring buffer rounds up (capped at the first retained sample)"},
+		{9000, 0, "before the first sample maps to 0"},
+	}
+	for _, test := range tests {
+		logical := tsa.GetLogicalTimeAtOrAfter(test.timestamp)
+		if logical != test.expectedLogical {
+			t.Errorf("%s (%d): expected logical time %d, got %d", test.description, test.timestamp, test.expectedLogical, logical)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package httpmw provides net/http middleware that names handler goroutines after their route,
+// tags them with the request method and response status, and pushes per-route request watches
+// (requests, errors, in-flight, latency) to Outrig.
+//
+// Example:
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/api/users", httpmw.Handler("users", usersHandler))
+//	mux.Handle("/api/orders", httpmw.Handler("orders", ordersHandler))
+//	http.ListenAndServe(":8080", mux)
+//
+// Or, to instrument every route of a mux by URL path (don't combine with Handler() or requests are counted twice):
+//
+//	http.ListenAndServe(":8080", httpmw.Middleware(mux))
+package httpmw
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/outrigdev/outrig"
+)
+
+// MaxRoutes caps the number of distinct routes that get their own watches.
+// Requests for routes past the cap are accounted under OverflowRoute.
+const MaxRoutes = 100
+
+const OverflowRoute = "other"
+
+const DefaultWatchPrefix = "http"
+
+// Options configures the middleware
+type Options struct {
+	// RouteFunc returns the route name for a request.
+	// Defaults to the URL path, so prefer a func that returns the route pattern when paths contain ids.
+	RouteFunc func(r *http.Request) string
+
+	// WatchPrefix is prepended to all watch names (defaults to "http")
+	WatchPrefix string
+
+	// Tags are added to all of the watches created by the middleware
+	Tags []string
+}
+
+type routeStats struct {
+	name     string // base name for the route's watches and handler goroutines
+	requests atomic.Int64
+	errors   atomic.Int64
+	inFlight atomic.Int64
+	latency  *outrig.Pusher
+}
+
+type middleware struct {
+	opts   Options
+	lock   sync.Mutex
+	routes map[string]*routeStats
+}
+
+// shared by Middleware(), Handler() and HandlerFunc() so each route only registers its watches once
+var defaultMiddleware = makeMiddleware(Options{})
+
+// Middleware wraps next using the default options, the route is the URL path of the request
+func Middleware(next http.Handler) http.Handler {
+	return defaultMiddleware.wrap("", next)
+}
+
+// Handler wraps next using the default options with a fixed route name
+func Handler(route string, next http.Handler) http.Handler {
+	return defaultMiddleware.wrap(route, next)
+}
+
+// HandlerFunc wraps fn using the default options with a fixed route name
+func HandlerFunc(route string, fn http.HandlerFunc) http.Handler {
+	return defaultMiddleware.wrap(route, fn)
+}
+
+// MakeMiddleware returns a middleware func (compatible with most routers' Use() methods) for the given options
+func MakeMiddleware(opts Options) func(http.Handler) http.Handler {
+	m := makeMiddleware(opts)
+	return func(next http.Handler) http.Handler {
+		return m.wrap("", next)
+	}
+}
+
+func makeMiddleware(opts Options) *middleware {
+	if opts.WatchPrefix == "" {
+		opts.WatchPrefix = DefaultWatchPrefix
+	}
+	return &middleware{
+		opts:   opts,
+		routes: make(map[string]*routeStats),
+	}
+}
+
+func (m *middleware) getRoute(r *http.Request) string {
+	if m.opts.RouteFunc != nil {
+		if route := m.opts.RouteFunc(r); route != "" {
+			return route
+		}
+	}
+	if r.URL != nil && r.URL.Path != "" {
+		return r.URL.Path
+	}
+	return "/"
+}
+
+// getRouteStats returns the stats for a route, registering the route's watches the first time it is seen
+func (m *middleware) getRouteStats(route string) *routeStats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if stats, ok := m.routes[route]; ok {
+		return stats
+	}
+	if len(m.routes) >= MaxRoutes {
+		route = OverflowRoute
+		if stats, ok := m.routes[route]; ok {
+			return stats
+		}
+	}
+	baseName := m.opts.WatchPrefix + "." + strings.Trim(route, "/")
+	if route == "/" {
+		baseName = m.opts.WatchPrefix + ".root"
+	}
+	stats := &routeStats{name: baseName}
+	outrig.NewWatch(baseName + ".requests").WithTags(m.opts.Tags...).AsCounter().PollAtomic(&stats.requests)
+	outrig.NewWatch(baseName + ".errors").WithTags(m.opts.Tags...).AsCounter().PollAtomic(&stats.errors)
+	outrig.NewWatch(baseName + ".inflight").WithTags(m.opts.Tags...).PollAtomic(&stats.inFlight)
+	stats.latency = outrig.NewWatch(baseName + ".latencyms").WithTags(m.opts.Tags...).ForPush()
+	m.routes[route] = stats
+	return stats
+}
+
+func (m *middleware) wrap(fixedRoute string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := fixedRoute
+		if route == "" {
+			route = m.getRoute(r)
+		}
+		stats := m.getRouteStats(route)
+
+		// net/http runs the handler on the connection's goroutine, so it is renamed for every request
+		gr := outrig.CurrentGR()
+		if gr != nil {
+			gr.WithName(stats.name).WithTags("method:" + strings.ToLower(r.Method))
+		}
+
+		sw := &statusWriter{ResponseWriter: w}
+		stats.requests.Add(1)
+		stats.inFlight.Add(1)
+		startTime := time.Now()
+		defer func() {
+			stats.inFlight.Add(-1)
+			stats.latency.Push(float64(time.Since(startTime).Microseconds()) / 1000)
+			status := sw.getStatus()
+			if status >= 500 {
+				stats.errors.Add(1)
+			}
+			if gr != nil {
+				gr.WithTags("method:"+strings.ToLower(r.Method), "status:"+strconv.Itoa(status))
+			}
+		}()
+		next.ServeHTTP(sw, r)
+	})
+}
+
+// statusWriter records the status code written by the handler
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	if sw.status == 0 {
+		sw.status = status
+	}
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+func (sw *statusWriter) Flush() {
+	if flusher, ok := sw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap allows http.ResponseController to reach the underlying ResponseWriter
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+func (sw *statusWriter) getStatus() int {
+	if sw.status == 0 {
+		// handler returned without writing anything, net/http sends a 200
+		return http.StatusOK
+	}
+	return sw.status
+}
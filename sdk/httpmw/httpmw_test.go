@@ -0,0 +1,91 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestGetRoute(t *testing.T) {
+	m := makeMiddleware(Options{})
+	if got := m.getRoute(httptest.NewRequest("GET", "/api/users/7", nil)); got != "/api/users/7" {
+		t.Errorf("got %q, want the URL path", got)
+	}
+
+	m = makeMiddleware(Options{RouteFunc: func(r *http.Request) string {
+		if r.URL.Path == "/api/users/7" {
+			return "/api/users/{id}"
+		}
+		return ""
+	}})
+	if got := m.getRoute(httptest.NewRequest("GET", "/api/users/7", nil)); got != "/api/users/{id}" {
+		t.Errorf("got %q, want the RouteFunc's route", got)
+	}
+	if got := m.getRoute(httptest.NewRequest("GET", "/health", nil)); got != "/health" {
+		t.Errorf("got %q, want the URL path when RouteFunc returns no route", got)
+	}
+}
+
+func TestGetRouteStats(t *testing.T) {
+	m := makeMiddleware(Options{WatchPrefix: "test-routestats"})
+	if got := m.getRouteStats("/").name; got != "test-routestats.root" {
+		t.Errorf("got %q for the root route", got)
+	}
+	users := m.getRouteStats("/api/users/")
+	if users.name != "test-routestats.api/users" || m.getRouteStats("/api/users/") != users {
+		t.Errorf("got %q, want the same stats for the same route", users.name)
+	}
+	for i := len(m.routes); i < MaxRoutes; i++ {
+		m.getRouteStats("/route" + strconv.Itoa(i))
+	}
+	// routes past MaxRoutes share the overflow route
+	overflow := m.getRouteStats("/new1")
+	if overflow.name != "test-routestats."+OverflowRoute || m.getRouteStats("/new2") != overflow || len(m.routes) != MaxRoutes+1 {
+		t.Errorf("got %q with %d routes, want the overflow route", overflow.name, len(m.routes))
+	}
+}
+
+func TestWrap(t *testing.T) {
+	m := makeMiddleware(Options{WatchPrefix: "test-wrap"})
+	handler := m.wrap("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.WriteHeader(http.StatusOK) // superfluous, the first status is kept
+		case "/write":
+			w.Write([]byte("ok"))
+		}
+	}))
+	for _, path := range []string{"/fail", "/fail", "/write", "/empty"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	fail := m.getRouteStats("/fail")
+	if fail.requests.Load() != 2 || fail.errors.Load() != 2 || fail.inFlight.Load() != 0 {
+		t.Errorf("got %d requests, %d errors, %d in flight for /fail", fail.requests.Load(), fail.errors.Load(), fail.inFlight.Load())
+	}
+	for _, path := range []string{"/write", "/empty"} {
+		stats := m.getRouteStats(path)
+		if stats.requests.Load() != 1 || stats.errors.Load() != 0 {
+			t.Errorf("got %d requests, %d errors for %s", stats.requests.Load(), stats.errors.Load(), path)
+		}
+	}
+}
+
+func TestStatusWriter(t *testing.T) {
+	sw := &statusWriter{ResponseWriter: httptest.NewRecorder()}
+	if got := sw.getStatus(); got != http.StatusOK {
+		t.Errorf("got %d without a write, want 200", got)
+	}
+	sw.WriteHeader(http.StatusNotFound)
+	sw.Write([]byte("not found"))
+	if got := sw.getStatus(); got != http.StatusNotFound {
+		t.Errorf("got %d, want 404", got)
+	}
+	if sw.Unwrap() == nil {
+		t.Errorf("Unwrap returned nil")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package outriglogrus provides a logrus Hook that sends log entries directly to Outrig,
+// keeping the level and fields of each entry (rendered as "LEVEL msg key=value ...").
+//
+// The entries still go to the logger's output, so disable Outrig's stdout/stderr capture
+// to avoid seeing the lines twice:
+//
+//	logrus.AddHook(outriglogrus.NewHook())
+package outriglogrus
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/collector/logprocess"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultSource is the log source used by NewHook (search with $src:logrus)
+const DefaultSource = "logrus"
+
+// Hook implements logrus.Hook
+type Hook struct {
+	// LogLevels are the levels the hook fires for (defaults to logrus.AllLevels)
+	LogLevels []logrus.Level
+
+	// Source is the Outrig log source for the entries (defaults to "logrus")
+	Source string
+}
+
+var _ logrus.Hook = (*Hook)(nil)
+
+// NewHook creates a hook that sends entries of all levels to Outrig
+func NewHook() *Hook {
+	return &Hook{LogLevels: logrus.AllLevels, Source: DefaultSource}
+}
+
+// Levels returns the levels the hook fires for
+func (h *Hook) Levels() []logrus.Level {
+	if h.LogLevels == nil {
+		return logrus.AllLevels
+	}
+	return h.LogLevels
+}
+
+// Fire sends the entry to Outrig (fields are sorted by key since logrus stores them in a map)
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	if !outrig.Enabled() {
+		return nil
+	}
+	rec := &logprocess.LogRecord{
+		Ts:     entry.Time,
+		Level:  levelString(entry.Level),
+		Msg:    entry.Message,
+		Fields: make([]logprocess.LogField, 0, len(entry.Data)),
+	}
+	if entry.HasCaller() {
+		rec.Caller = fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line)
+	}
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		rec.Fields = append(rec.Fields, logprocess.LogField{Key: key, Value: entry.Data[key]})
+	}
+	source := h.Source
+	if source == "" {
+		source = DefaultSource
+	}
+	logprocess.SendLogRecord(source, rec)
+	return nil
+}
+
+// levelString uses the same level names as zap and slog ("warning" becomes "WARN")
+func levelString(level logrus.Level) string {
+	if level == logrus.WarnLevel {
+		return "WARN"
+	}
+	return level.String()
+}
@@ -0,0 +1,37 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package outriglogrus
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLevelString(t *testing.T) {
+	tests := []struct {
+		level  logrus.Level
+		expect string
+	}{
+		{logrus.DebugLevel, "debug"},
+		{logrus.InfoLevel, "info"},
+		{logrus.WarnLevel, "WARN"},
+		{logrus.ErrorLevel, "error"},
+	}
+	for _, tc := range tests {
+		if got := levelString(tc.level); got != tc.expect {
+			t.Errorf("levelString(%v): got %q, want %q", tc.level, got, tc.expect)
+		}
+	}
+}
+
+func TestHookLevels(t *testing.T) {
+	if got := (&Hook{}).Levels(); len(got) != len(logrus.AllLevels) {
+		t.Errorf("got %v, want all levels by default", got)
+	}
+	hook := &Hook{LogLevels: []logrus.Level{logrus.ErrorLevel}}
+	if got := hook.Levels(); len(got) != 1 || got[0] != logrus.ErrorLevel {
+		t.Errorf("got %v, want the configured levels", got)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package outrigslog provides a log/slog Handler that sends records directly to Outrig,
+// keeping the level and attributes of each record (rendered as "LEVEL msg key=value ...").
+//
+// Combine it with your existing handler so the logs still go to stdout (and disable Outrig's
+// stdout/stderr capture to avoid seeing the lines twice):
+//
+//	logger := slog.New(outrigslog.Fanout(slog.NewTextHandler(os.Stderr, nil), outrigslog.NewHandler(nil)))
+//	slog.SetDefault(logger)
+package outrigslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"slices"
+
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/collector/logprocess"
+)
+
+// DefaultSource is the log source used when Options.Source is not set (search with $src:slog)
+const DefaultSource = "slog"
+
+// Options configures the handler
+type Options struct {
+	// Level is the minimum level that is sent to Outrig (defaults to slog.LevelDebug)
+	Level slog.Leveler
+
+	// AddSource adds a caller=file:line field to every record
+	AddSource bool
+
+	// Source is the Outrig log source for the records (defaults to "slog")
+	Source string
+}
+
+// Handler implements slog.Handler
+type Handler struct {
+	opts   Options
+	attrs  []logprocess.LogField // attributes added with WithAttrs (keys already prefixed with their groups)
+	prefix string                // group prefix for attributes of the record, e.g. "req."
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// NewHandler creates a handler that sends records to Outrig, opts can be nil
+func NewHandler(opts *Options) *Handler {
+	h := &Handler{}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelDebug
+	}
+	if h.opts.Source == "" {
+		h.opts.Source = DefaultSource
+	}
+	return h
+}
+
+// Enabled reports whether records at level are sent (always false when Outrig is not enabled)
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return outrig.Enabled() && level >= h.opts.Level.Level()
+}
+
+// Handle sends the record to Outrig
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	rec := &logprocess.LogRecord{
+		Ts:     r.Time,
+		Level:  r.Level.String(),
+		Msg:    r.Message,
+		Fields: slices.Clip(h.attrs),
+	}
+	if h.opts.AddSource && r.PC != 0 {
+		frames := runtime.CallersFrames([]uintptr{r.PC})
+		frame, _ := frames.Next()
+		rec.Caller = fmt.Sprintf("%s:%d", frame.File, frame.Line)
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		rec.Fields = appendAttr(rec.Fields, h.prefix, attr)
+		return true
+	})
+	logprocess.SendLogRecord(h.opts.Source, rec)
+	return nil
+}
+
+// WithAttrs returns a handler that adds attrs to every record
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = slices.Clip(h.attrs)
+	for _, attr := range attrs {
+		h2.attrs = appendAttr(h2.attrs, h.prefix, attr)
+	}
+	return &h2
+}
+
+// WithGroup returns a handler that qualifies the keys of subsequent attributes with name ("name.key")
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// appendAttr flattens groups into dotted keys, empty attrs are dropped (following the slog.Handler rules)
+func appendAttr(fields []logprocess.LogField, prefix string, attr slog.Attr) []logprocess.LogField {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		groupAttrs := attr.Value.Group()
+		if len(groupAttrs) == 0 {
+			return fields
+		}
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix = prefix + attr.Key + "."
+		}
+		for _, groupAttr := range groupAttrs {
+			fields = appendAttr(fields, groupPrefix, groupAttr)
+		}
+		return fields
+	}
+	return append(fields, logprocess.LogField{Key: prefix + attr.Key, Value: attr.Value.Any()})
+}
+
+// Fanout returns a handler that passes every record to all of the handlers (e.g. a text handler and an Outrig handler)
+func Fanout(handlers ...slog.Handler) slog.Handler {
+	return fanoutHandler(handlers)
+}
+
+type fanoutHandler []slog.Handler
+
+func (f fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var firstErr error
+	for _, h := range f {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (f fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	rtn := make(fanoutHandler, len(f))
+	for i, h := range f {
+		rtn[i] = h.WithAttrs(attrs)
+	}
+	return rtn
+}
+
+func (f fanoutHandler) WithGroup(name string) slog.Handler {
+	rtn := make(fanoutHandler, len(f))
+	for i, h := range f {
+		rtn[i] = h.WithGroup(name)
+	}
+	return rtn
+}
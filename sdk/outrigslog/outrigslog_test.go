@@ -0,0 +1,57 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package outrigslog
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/collector/logprocess"
+)
+
+func TestAppendAttr(t *testing.T) {
+	attrs := []slog.Attr{
+		slog.String("user", "bob"),
+		slog.Group("req", slog.String("method", "GET"), slog.Group("url", slog.String("path", "/"))),
+		slog.Group("", slog.Int("inline", 1)),
+		slog.Group("empty"),
+		{},
+	}
+	var got []logprocess.LogField
+	for _, attr := range attrs {
+		got = appendAttr(got, "http.", attr)
+	}
+	expect := []logprocess.LogField{
+		{Key: "http.user", Value: "bob"},
+		{Key: "http.req.method", Value: "GET"},
+		{Key: "http.req.url.path", Value: "/"},
+		{Key: "http.inline", Value: int64(1)},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("got %+v, want %+v", got, expect)
+	}
+}
+
+func TestHandlerWithAttrsAndGroup(t *testing.T) {
+	h := NewHandler(nil)
+	if h.opts.Source != DefaultSource || h.opts.Level.Level() != slog.LevelDebug {
+		t.Errorf("got options %+v, want the defaults", h.opts)
+	}
+	if h.WithAttrs(nil) != h || h.WithGroup("") != h {
+		t.Errorf("empty attrs and groups should return the same handler")
+	}
+
+	base := h.WithAttrs([]slog.Attr{slog.String("app", "test")}).(*Handler)
+	grouped := base.WithGroup("req").(*Handler)
+	a := grouped.WithAttrs([]slog.Attr{slog.Int("a", 1)}).(*Handler)
+	b := grouped.WithAttrs([]slog.Attr{slog.Int("b", 2)}).(*Handler)
+	if grouped.prefix != "req." || len(base.attrs) != 1 || len(grouped.attrs) != 1 {
+		t.Errorf("got prefix %q and attrs %+v, the parent handler was modified", grouped.prefix, grouped.attrs)
+	}
+	// handlers derived from the same parent don't share their attrs
+	if len(a.attrs) != 2 || a.attrs[1].Key != "req.a" || len(b.attrs) != 2 || b.attrs[1].Key != "req.b" {
+		t.Errorf("got attrs %+v and %+v", a.attrs, b.attrs)
+	}
+}
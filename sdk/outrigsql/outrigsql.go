@@ -0,0 +1,86 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package outrigsql reports the connection pool stats of a database/sql *sql.DB to Outrig, so
+// connection pool exhaustion is visible alongside goroutine spikes. It is a separate package so
+// the outrig SDK itself doesn't depend on database/sql.
+//
+// Example:
+//
+//	db, _ := sql.Open("postgres", dsn)
+//	outrigsql.WatchDBPool("maindb", db)
+package outrigsql
+
+import (
+	"database/sql"
+	"fmt"
+	"runtime"
+
+	"github.com/outrigdev/outrig/pkg/collector/dbpool"
+	"github.com/outrigdev/outrig/pkg/collector/watch"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+)
+
+// PoolWatch is returned by WatchDBPool
+type PoolWatch struct {
+	name string
+}
+
+// MakePoolStats converts sql.DBStats to the stats reported for a pool
+func MakePoolStats(stats sql.DBStats) dbpool.PoolStats {
+	return dbpool.PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		Exhausted:          stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections,
+	}
+}
+
+// WatchDBPool reports the connection pool stats of db (db.Stats(): open connections, in use, idle,
+// wait count/duration, ...) as a watch with the given name (tagged #dbpool).
+// The stats are polled every second by the dbpool collector, the "exhausted" field is set when
+// every connection is in use (only if db.SetMaxOpenConns was called).
+func WatchDBPool(name string, db *sql.DB) *PoolWatch {
+	decl := &ds.WatchDecl{
+		Name:      utilfn.NormalizeName(name),
+		Tags:      []string{dbpool.DBPoolTag},
+		NewLine:   getCallerInfo(1),
+		WatchType: watch.WatchType_Push,
+		Format:    watch.WatchFormat_Json,
+	}
+	var statsFn func() dbpool.PoolStats
+	if db != nil {
+		statsFn = func() dbpool.PoolStats {
+			return MakePoolStats(db.Stats())
+		}
+	}
+	err := dbpool.GetInstance().RegisterPool(statsFn, decl)
+	if err != nil {
+		watch.GetInstance().AddRegError(ds.ErrWithContext{
+			Ref:   decl.Name,
+			Error: err.Error(),
+			Line:  decl.NewLine,
+		})
+	}
+	return &PoolWatch{name: decl.Name}
+}
+
+// Unregister stops polling the database pool and unregisters its watch
+func (w *PoolWatch) Unregister() {
+	dbpool.GetInstance().UnregisterPool(w.name)
+}
+
+func getCallerInfo(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
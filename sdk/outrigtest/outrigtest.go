@@ -0,0 +1,319 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package outrigtest captures what the Outrig SDK reports (logs, goroutines, and watches) inside a test,
+// without an Outrig monitor, so unit tests can assert on it:
+//
+//	func TestWorker(t *testing.T) {
+//		outrigtest.Start(t)
+//		runWorker()
+//		outrigtest.Logs(t).Contains("worker started")
+//		outrigtest.Goroutines(t).HasName("worker")
+//		outrigtest.Watch(t, "queue-depth").Equals("0")
+//	}
+//
+// Start initializes Outrig (if the test binary didn't) with the connections to the monitor and the
+// stdout/stderr capture turned off. Only the data sent through the SDK is captured (outrig.Log, log
+// writers and the log adapters, outrig.Go, watches), not the lines written to stdout/stderr.
+// The capture is process wide, tests that use it must not call t.Parallel.
+package outrigtest
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/collector/goroutine"
+	"github.com/outrigdev/outrig/pkg/collector/watch"
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/global"
+)
+
+// LogWaitTime is how long Logs(t).Contains waits for a line (log writers and streams send their lines
+// asynchronously)
+const LogWaitTime = 1 * time.Second
+
+const logPollInterval = 10 * time.Millisecond
+
+var (
+	hookOnce sync.Once
+	current  atomic.Pointer[Capture]
+)
+
+// Capture holds the packets sent by the SDK since Start
+type Capture struct {
+	lock       sync.Mutex
+	logLines   []ds.LogLine
+	goDecls    map[int64]ds.GoDecl
+	goStacks   []ds.GoRoutineStack // from the newest goroutine dump
+	watchDecls map[string]ds.WatchDecl
+	watches    map[string]ds.WatchSample // newest sample of each watch
+}
+
+// Start starts capturing the SDK's packets for the test, the capture ends when the test finishes
+func Start(t testing.TB) *Capture {
+	t.Helper()
+	if global.GetController() == nil {
+		_, err := outrig.Init("", testConfig())
+		if err != nil && global.GetController() == nil {
+			t.Fatalf("outrigtest: error initializing outrig: %v", err)
+		}
+	}
+	ctl, ok := global.GetController().(interface{ StartCapture() })
+	if !ok {
+		t.Skip("outrigtest: outrig is disabled (no_outrig build)")
+	}
+	hookOnce.Do(func() {
+		outrig.AddPacketHook(func(pk *ds.PacketType) bool {
+			if c := current.Load(); c != nil {
+				c.handlePacket(pk)
+			}
+			return true
+		})
+	})
+	c := &Capture{
+		goDecls:    make(map[int64]ds.GoDecl),
+		watchDecls: make(map[string]ds.WatchDecl),
+		watches:    make(map[string]ds.WatchSample),
+	}
+	current.Store(c)
+	t.Cleanup(func() {
+		current.CompareAndSwap(c, nil)
+	})
+	ctl.StartCapture()
+	return c
+}
+
+// testConfig doesn't connect to a monitor or start the stdout/stderr capture process
+func testConfig() *config.Config {
+	cfg := outrig.DefaultConfig()
+	cfg.Quiet = true
+	cfg.DomainSocketPath = "-"
+	cfg.TcpAddr = "-"
+	cfg.PipeName = "-"
+	cfg.DisableDockerProbe = true
+	cfg.ConnectOnInit = false
+	cfg.Collectors.Logs.WrapStdout = false
+	cfg.Collectors.Logs.WrapStderr = false
+	return cfg
+}
+
+func getCapture(t testing.TB) *Capture {
+	t.Helper()
+	c := current.Load()
+	if c == nil {
+		t.Fatalf("outrigtest: Start(t) must be called first")
+	}
+	return c
+}
+
+func (c *Capture) handlePacket(pk *ds.PacketType) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	switch data := pk.Data.(type) {
+	case *ds.LogLine:
+		c.logLines = append(c.logLines, *data)
+	case *ds.GoroutineInfo:
+		for _, decl := range data.Decls {
+			c.goDecls[decl.GoId] = decl
+		}
+		c.goStacks = append([]ds.GoRoutineStack(nil), data.Stacks...)
+	case *ds.WatchInfo:
+		for _, decl := range data.Decls {
+			c.watchDecls[decl.Name] = decl
+		}
+		for _, sample := range data.Watches {
+			c.watches[sample.Name] = sample
+		}
+	}
+}
+
+// LogLines are the captured log lines, see Logs
+type LogLines struct {
+	t testing.TB
+	c *Capture
+}
+
+// Logs returns the log lines captured by the test
+func Logs(t testing.TB) *LogLines {
+	t.Helper()
+	return &LogLines{t: t, c: getCapture(t)}
+}
+
+// Lines returns the messages of the captured log lines (without the trailing newline)
+func (l *LogLines) Lines() []string {
+	l.c.lock.Lock()
+	defer l.c.lock.Unlock()
+	rtn := make([]string, 0, len(l.c.logLines))
+	for _, line := range l.c.logLines {
+		rtn = append(rtn, strings.TrimSuffix(line.Msg, "\n"))
+	}
+	return rtn
+}
+
+func (l *LogLines) hasLine(substr string) bool {
+	for _, line := range l.Lines() {
+		if strings.Contains(line, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports a test error if no captured log line contains substr (waiting up to LogWaitTime for it)
+func (l *LogLines) Contains(substr string) bool {
+	l.t.Helper()
+	deadline := time.Now().Add(LogWaitTime)
+	for !l.hasLine(substr) {
+		if time.Now().After(deadline) {
+			l.t.Errorf("outrigtest: no log line contains %q (%d lines captured)", substr, len(l.Lines()))
+			return false
+		}
+		time.Sleep(logPollInterval)
+	}
+	return true
+}
+
+// NotContains reports a test error if a captured log line contains substr
+func (l *LogLines) NotContains(substr string) bool {
+	l.t.Helper()
+	if l.hasLine(substr) {
+		l.t.Errorf("outrigtest: a log line contains %q", substr)
+		return false
+	}
+	return true
+}
+
+// GoroutineSet are the goroutines reported by a goroutine dump, see Goroutines
+type GoroutineSet struct {
+	t      testing.TB
+	decls  map[int64]ds.GoDecl
+	stacks []ds.GoRoutineStack
+}
+
+// Goroutines dumps the goroutines and returns them along with the named goroutines (outrig.Go, SetGoRoutineName)
+// seen since Start, including the ones that already finished
+func Goroutines(t testing.TB) *GoroutineSet {
+	t.Helper()
+	c := getCapture(t)
+	goroutine.GetInstance().DumpGoroutines()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	rtn := &GoroutineSet{
+		t:      t,
+		decls:  make(map[int64]ds.GoDecl, len(c.goDecls)),
+		stacks: append([]ds.GoRoutineStack(nil), c.goStacks...),
+	}
+	for goId, decl := range c.goDecls {
+		rtn.decls[goId] = decl
+	}
+	return rtn
+}
+
+// Names returns the sorted names of the named goroutines
+func (g *GoroutineSet) Names() []string {
+	nameSet := make(map[string]bool)
+	for _, decl := range g.decls {
+		if decl.Name != "" {
+			nameSet[decl.Name] = true
+		}
+	}
+	for _, stack := range g.stacks {
+		if stack.Name != "" {
+			nameSet[stack.Name] = true
+		}
+	}
+	rtn := make([]string, 0, len(nameSet))
+	for name := range nameSet {
+		rtn = append(rtn, name)
+	}
+	sort.Strings(rtn)
+	return rtn
+}
+
+// NumRunning returns the number of goroutines named name in the goroutine dump
+func (g *GoroutineSet) NumRunning(name string) int {
+	var rtn int
+	for _, stack := range g.stacks {
+		if stack.Name == name {
+			rtn++
+		}
+	}
+	return rtn
+}
+
+// HasName reports a test error if no goroutine was named name
+func (g *GoroutineSet) HasName(name string) bool {
+	g.t.Helper()
+	names := g.Names()
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	g.t.Errorf("outrigtest: no goroutine named %q (names: %s)", name, strings.Join(names, ", "))
+	return false
+}
+
+// WatchValue is the newest sample of a watch, see Watch
+type WatchValue struct {
+	t      testing.TB
+	name   string
+	found  bool
+	decl   ds.WatchDecl
+	sample ds.WatchSample
+}
+
+// Watch collects the watches and returns the newest value of the named watch (a test error is reported
+// if the watch doesn't exist)
+func Watch(t testing.TB, name string) *WatchValue {
+	t.Helper()
+	c := getCapture(t)
+	watch.GetInstance().CollectWatches()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	rtn := &WatchValue{t: t, name: name}
+	rtn.sample, rtn.found = c.watches[name]
+	rtn.decl = c.watchDecls[name]
+	if !rtn.found {
+		t.Errorf("outrigtest: no value for watch %q", name)
+	}
+	return rtn
+}
+
+// Value returns the formatted value of the watch (as shown in Outrig)
+func (w *WatchValue) Value() string {
+	return w.sample.Val
+}
+
+// Err returns the error of the watch's sample (e.g. a panicking poll func), "" if there was none
+func (w *WatchValue) Err() string {
+	return w.sample.Error
+}
+
+// Decl returns the declaration of the watch (tags, format, unit)
+func (w *WatchValue) Decl() ds.WatchDecl {
+	return w.decl
+}
+
+// Equals reports a test error if the watch's formatted value isn't expected
+func (w *WatchValue) Equals(expected string) bool {
+	w.t.Helper()
+	if !w.found {
+		return false
+	}
+	if w.sample.Error != "" {
+		w.t.Errorf("outrigtest: watch %q has an error: %s", w.name, w.sample.Error)
+		return false
+	}
+	if w.sample.Val != expected {
+		w.t.Errorf("outrigtest: watch %q is %q, expected %q", w.name, w.sample.Val, expected)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package outrigtest
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/outrigdev/outrig"
+)
+
+func TestLogs(t *testing.T) {
+	Start(t)
+	outrig.Log("outrigtest line one")
+	fmt.Fprintln(outrig.LogWriter("outrigtest-writer"), "outrigtest written line")
+
+	logs := Logs(t)
+	logs.Contains("line one")
+	logs.Contains("written line")
+	logs.NotContains("line two")
+	if !slices.Contains(logs.Lines(), "outrigtest line one") {
+		t.Errorf("got lines %q, want the message without the newline", logs.Lines())
+	}
+
+	// a new capture starts empty
+	Start(t)
+	if lines := Logs(t).Lines(); len(lines) != 0 {
+		t.Errorf("got lines %q from the previous capture", lines)
+	}
+}
+
+// testRunNum keeps the goroutine names unique when the tests are run with -count
+var testRunNum int
+
+func TestGoroutines(t *testing.T) {
+	Start(t)
+	testRunNum++
+	name := fmt.Sprintf("outrigtest-worker-%d", testRunNum)
+	started := make(chan struct{})
+	done := make(chan struct{})
+	outrig.Go(name).Run(func() {
+		close(started)
+		<-done
+	})
+	<-started
+
+	goroutines := Goroutines(t)
+	goroutines.HasName(name)
+	if got := goroutines.NumRunning(name); got != 1 {
+		t.Errorf("got %d running workers, want 1", got)
+	}
+	close(done)
+
+	// the finished goroutine is still named
+	if names := Goroutines(t).Names(); !slices.Contains(names, name) {
+		t.Errorf("got names %v, want the finished worker", names)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	Start(t)
+	w := outrig.NewWatch("outrigtest-count").Static(42)
+	defer w.Unregister()
+
+	value := Watch(t, "outrigtest-count")
+	value.Equals("42")
+	if value.Err() != "" || value.Decl().Name != "outrigtest-count" {
+		t.Errorf("got err %q and decl %+v", value.Err(), value.Decl())
+	}
+
+	// a missing watch is a test error
+	ft := &testing.T{}
+	if Watch(ft, "outrigtest-missing").Equals("") || !ft.Failed() {
+		t.Errorf("a missing watch should fail the test")
+	}
+}
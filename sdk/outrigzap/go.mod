@@ -0,0 +1,19 @@
+module github.com/outrigdev/outrig/sdk/outrigzap
+
+go 1.23.0
+
+require (
+	github.com/outrigdev/outrig v0.9.1
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/outrigdev/goid v0.3.0 // indirect
+	github.com/stretchr/testify v1.10.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+	golang.org/x/term v0.29.0 // indirect
+)
+
+replace github.com/outrigdev/outrig => ../../
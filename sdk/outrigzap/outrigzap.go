@@ -0,0 +1,119 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package outrigzap provides a zapcore.Core that sends log entries directly to Outrig,
+// keeping the level and fields of each entry (rendered as "LEVEL msg key=value ...").
+//
+// Tee it with your existing core so the logs still go to stdout (and disable Outrig's
+// stdout/stderr capture to avoid seeing the lines twice):
+//
+//	logger := zap.New(zapcore.NewTee(existingCore, outrigzap.NewCore(zapcore.DebugLevel)), zap.AddCaller())
+//
+// or, for an existing logger:
+//
+//	logger = logger.WithOptions(outrigzap.WrapCore(zapcore.DebugLevel))
+package outrigzap
+
+import (
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/collector/logprocess"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultSource is the log source used by NewCore (search with $src:zap)
+const DefaultSource = "zap"
+
+// Core implements zapcore.Core
+type Core struct {
+	zapcore.LevelEnabler
+	source string
+	fields []logprocess.LogField // fields added with With()
+}
+
+var _ zapcore.Core = (*Core)(nil)
+
+// NewCore creates a core that sends entries at or above the enabler's level to Outrig
+func NewCore(enab zapcore.LevelEnabler) *Core {
+	return NewCoreWithSource(enab, DefaultSource)
+}
+
+// NewCoreWithSource is like NewCore but with a custom Outrig log source
+func NewCoreWithSource(enab zapcore.LevelEnabler, source string) *Core {
+	if source == "" {
+		source = DefaultSource
+	}
+	return &Core{LevelEnabler: enab, source: source}
+}
+
+// WrapCore returns a zap option that tees the logger's core with a new Outrig core
+func WrapCore(enab zapcore.LevelEnabler) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, NewCore(enab))
+	})
+}
+
+// Enabled reports whether entries at level are sent (always false when Outrig is not enabled)
+func (c *Core) Enabled(level zapcore.Level) bool {
+	return outrig.Enabled() && c.LevelEnabler.Enabled(level)
+}
+
+// With returns a core that adds fields to every entry
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	c2 := *c
+	c2.fields = append(c.fields[:len(c.fields):len(c.fields)], encodeFields(fields)...)
+	return &c2
+}
+
+// Check adds the core to the checked entry if the entry's level is enabled
+func (c *Core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write sends the entry to Outrig
+func (c *Core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	rec := &logprocess.LogRecord{
+		Ts:     ent.Time,
+		Level:  ent.Level.CapitalString(),
+		Msg:    ent.Message,
+		Fields: append(c.fields[:len(c.fields):len(c.fields)], encodeFields(fields)...),
+	}
+	if ent.LoggerName != "" {
+		rec.Fields = append(rec.Fields, logprocess.LogField{Key: "logger", Value: ent.LoggerName})
+	}
+	if ent.Caller.Defined {
+		rec.Caller = ent.Caller.TrimmedPath()
+	}
+	if ent.Stack != "" {
+		rec.Fields = append(rec.Fields, logprocess.LogField{Key: "stacktrace", Value: ent.Stack})
+	}
+	logprocess.SendLogRecord(c.source, rec)
+	return nil
+}
+
+// Sync is a no-op, entries are handed to the Outrig controller as they are written
+func (c *Core) Sync() error {
+	return nil
+}
+
+// encodeFields uses zap's map encoder so every field type (including namespaces and objects) is handled like zap does
+func encodeFields(fields []zapcore.Field) []logprocess.LogField {
+	if len(fields) == 0 {
+		return nil
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	rtn := make([]logprocess.LogField, 0, len(fields))
+	for _, field := range fields {
+		field.AddTo(enc)
+		// keep the fields in order (the map encoder loses it), namespaces show up as a single nested field
+		key := field.Key
+		if val, ok := enc.Fields[key]; ok {
+			rtn = append(rtn, logprocess.LogField{Key: key, Value: val})
+			delete(enc.Fields, key)
+		}
+	}
+	return rtn
+}
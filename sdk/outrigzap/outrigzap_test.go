@@ -0,0 +1,50 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package outrigzap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/collector/logprocess"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestEncodeFields(t *testing.T) {
+	if got := encodeFields(nil); got != nil {
+		t.Errorf("got %+v for no fields, want nil", got)
+	}
+	got := encodeFields([]zapcore.Field{
+		zap.String("user", "bob"),
+		zap.Int("count", 3),
+		zap.Bool("ok", true),
+		zap.Namespace("req"),
+		zap.String("method", "GET"),
+	})
+	// the fields after a namespace are nested in it
+	expect := []logprocess.LogField{
+		{Key: "user", Value: "bob"},
+		{Key: "count", Value: int64(3)},
+		{Key: "ok", Value: true},
+		{Key: "req", Value: map[string]any{"method": "GET"}},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("got %+v, want %+v", got, expect)
+	}
+}
+
+func TestCoreWith(t *testing.T) {
+	core := NewCoreWithSource(zapcore.InfoLevel, "")
+	if core.source != DefaultSource {
+		t.Errorf("got source %q, want %q", core.source, DefaultSource)
+	}
+	base := core.With([]zapcore.Field{zap.String("app", "test")}).(*Core)
+	a := base.With([]zapcore.Field{zap.Int("a", 1)}).(*Core)
+	b := base.With([]zapcore.Field{zap.Int("b", 2)}).(*Core)
+	// cores derived from the same parent don't share their fields
+	if len(base.fields) != 1 || len(a.fields) != 2 || a.fields[1].Key != "a" || len(b.fields) != 2 || b.fields[1].Key != "b" {
+		t.Errorf("got fields %+v, %+v and %+v", base.fields, a.fields, b.fields)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+)
+
+const ConfigSourceFileName = "pkg/config/config.go"
+const SchemaFileName = "pkg/config/outrig.schema.json"
+
+// readFieldDocs returns the doc comments of the struct fields in the config source ("TypeName.FieldName" => doc)
+func readFieldDocs(fileName string) (map[string]string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileName, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	docs := make(map[string]string)
+	ast.Inspect(file, func(node ast.Node) bool {
+		typeSpec, ok := node.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		structType, ok := typeSpec.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+		for _, field := range structType.Fields.List {
+			doc := field.Doc.Text()
+			if doc == "" {
+				doc = field.Comment.Text()
+			}
+			doc = strings.Join(strings.Fields(doc), " ")
+			if doc == "" {
+				continue
+			}
+			for _, name := range field.Names {
+				docs[typeSpec.Name.Name+"."+name.Name] = doc
+			}
+		}
+		return false
+	})
+	return docs, nil
+}
+
+func main() {
+	fieldDocs, err := readFieldDocs(ConfigSourceFileName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading %s: %v\n", ConfigSourceFileName, err)
+		os.Exit(1)
+	}
+	schema := config.GenerateSchema(fieldDocs)
+	barr, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error marshaling schema: %v\n", err)
+		os.Exit(1)
+	}
+	barr = append(barr, '\n')
+	fmt.Fprintf(os.Stderr, "generating config schema to %s\n", SchemaFileName)
+	written, err := utilfn.WriteFileIfDifferent(SchemaFileName, barr, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing %s: %v\n", SchemaFileName, err)
+		os.Exit(1)
+	}
+	if !written {
+		fmt.Fprintf(os.Stderr, "no changes to %s\n", SchemaFileName)
+	}
+}
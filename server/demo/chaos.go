@@ -0,0 +1,306 @@
+package demo
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+)
+
+//go:embed chaosfrontend/*
+var chaosFrontendFS embed.FS
+
+const (
+	ChaosPreferredPort = 22006 // Preferred port for the chaos demo server
+
+	ChaosLeakBatchSize   = 50               // goroutines leaked per "leak" fault
+	ChaosMemoryBatchSize = 16 * 1024 * 1024 // bytes retained per "memory" fault
+	ChaosOrderInterval   = 200 * time.Millisecond
+	ChaosNumWorkers      = 4
+
+	// fault kinds (POST /api/fault?kind=...)
+	ChaosFaultLeak     = "leak"     // goroutines blocked forever on a channel nobody writes to
+	ChaosFaultDeadlock = "deadlock" // two goroutines taking two mutexes in opposite order
+	ChaosFaultMemory   = "memory"   // a "cache" that only grows
+	ChaosFaultPanic    = "panic"    // a panic in a request handler (recovered, the service keeps running)
+	ChaosFaultCrash    = "crash"    // an unrecovered panic in a goroutine (the process exits)
+	ChaosFaultReset    = "reset"    // releases leaked goroutines and retained memory (deadlocks stay)
+)
+
+// ChaosStatus is the /api/status response (also the "chaos-status" watch)
+type ChaosStatus struct {
+	OrdersProcessed   int64 `json:"ordersprocessed"`
+	LeakedGoRoutines  int64 `json:"leakedgoroutines"`
+	DeadlockedPairs   int64 `json:"deadlockedpairs"`
+	RetainedBytes     int64 `json:"retainedbytes"`
+	RecoveredPanics   int64 `json:"recoveredpanics"`
+	UptimeSec         int64 `json:"uptimesec"`
+	ProcessGoRoutines int   `json:"processgoroutines"`
+}
+
+// chaosService is a small "order processing" microservice with injectable bugs
+type chaosService struct {
+	startTime       time.Time
+	ordersProcessed atomic.Int64
+	recoveredPanics atomic.Int64
+	deadlockedPairs atomic.Int64
+
+	lock        sync.Mutex
+	leakCh      chan struct{} // leaked goroutines block on this (closed by reset)
+	leakedCount int64
+	cache       [][]byte // the leaking "cache"
+	cacheBytes  int64
+}
+
+func makeChaosService() *chaosService {
+	return &chaosService{
+		startTime: time.Now(),
+		leakCh:    make(chan struct{}),
+	}
+}
+
+func (cs *chaosService) getStatus() ChaosStatus {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	return ChaosStatus{
+		OrdersProcessed:   cs.ordersProcessed.Load(),
+		LeakedGoRoutines:  cs.leakedCount,
+		DeadlockedPairs:   cs.deadlockedPairs.Load(),
+		RetainedBytes:     cs.cacheBytes,
+		RecoveredPanics:   cs.recoveredPanics.Load(),
+		UptimeSec:         int64(time.Since(cs.startTime).Seconds()),
+		ProcessGoRoutines: runtime.NumGoroutine(),
+	}
+}
+
+// startWorkers runs the healthy part of the service (an order queue and a worker pool)
+func (cs *chaosService) startWorkers() {
+	orderCh := make(chan int, 100)
+	outrig.Go("orders.producer").WithTags("orders").Run(func() {
+		orderId := 1000
+		ticker := time.NewTicker(ChaosOrderInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			orderId++
+			orderCh <- orderId
+		}
+	})
+	for i := 1; i <= ChaosNumWorkers; i++ {
+		workerId := i
+		outrig.Go(fmt.Sprintf("orders.worker-%d", workerId)).WithTags("orders", "worker").Run(func() {
+			for orderId := range orderCh {
+				time.Sleep(time.Duration(20+rand.Intn(80)) * time.Millisecond)
+				cs.ordersProcessed.Add(1)
+				if orderId%25 == 0 {
+					log.Printf("#orders worker-%d processed order %d (total %d)", workerId, orderId, cs.ordersProcessed.Load())
+				}
+			}
+		})
+	}
+}
+
+// injectLeak starts goroutines that wait for a reply that never comes (a classic forgotten channel)
+func (cs *chaosService) injectLeak() string {
+	cs.lock.Lock()
+	leakCh := cs.leakCh
+	cs.leakedCount += ChaosLeakBatchSize
+	cs.lock.Unlock()
+	for i := 0; i < ChaosLeakBatchSize; i++ {
+		outrig.Go("chaos.awaitreply").WithTags("chaos", "leak").Run(func() {
+			<-leakCh
+		})
+	}
+	log.Printf("#chaos #leak started %d goroutines waiting on a reply channel nobody writes to", ChaosLeakBatchSize)
+	return fmt.Sprintf("leaked %d goroutines", ChaosLeakBatchSize)
+}
+
+// injectDeadlock makes two goroutines lock the same two mutexes in opposite order
+func (cs *chaosService) injectDeadlock() string {
+	pairNum := cs.deadlockedPairs.Add(1)
+	var inventoryLock, paymentLock sync.Mutex
+	var bothLocked sync.WaitGroup
+	bothLocked.Add(2)
+	outrig.Go(fmt.Sprintf("chaos.reserveinventory-%d", pairNum)).WithTags("chaos", "deadlock").Run(func() {
+		inventoryLock.Lock()
+		bothLocked.Done()
+		bothLocked.Wait()
+		paymentLock.Lock() // blocks forever
+		paymentLock.Unlock()
+		inventoryLock.Unlock()
+	})
+	outrig.Go(fmt.Sprintf("chaos.capturepayment-%d", pairNum)).WithTags("chaos", "deadlock").Run(func() {
+		paymentLock.Lock()
+		bothLocked.Done()
+		bothLocked.Wait()
+		inventoryLock.Lock() // blocks forever
+		inventoryLock.Unlock()
+		paymentLock.Unlock()
+	})
+	log.Printf("#chaos #deadlock reserveinventory-%d and capturepayment-%d are waiting on each other's mutex", pairNum, pairNum)
+	return fmt.Sprintf("deadlocked goroutine pair %d", pairNum)
+}
+
+// injectMemory adds an entry to a cache that is never evicted
+func (cs *chaosService) injectMemory() string {
+	buf := make([]byte, ChaosMemoryBatchSize)
+	for i := 0; i < len(buf); i += 4096 {
+		buf[i] = 1 // touch the pages so they count as resident
+	}
+	cs.lock.Lock()
+	cs.cache = append(cs.cache, buf)
+	cs.cacheBytes += int64(len(buf))
+	total := cs.cacheBytes
+	cs.lock.Unlock()
+	log.Printf("#chaos #memory cached another %dMB response (cache is now %dMB, never evicted)", ChaosMemoryBatchSize/(1024*1024), total/(1024*1024))
+	return fmt.Sprintf("retained %dMB", total/(1024*1024))
+}
+
+// injectPanic panics inside the handler (the panic is recovered and logged with its stack)
+func (cs *chaosService) injectPanic() (rtn string) {
+	defer func() {
+		if r := recover(); r != nil {
+			cs.recoveredPanics.Add(1)
+			log.Printf("#chaos #panic recovered panic in order handler: %v\n%s", r, debug.Stack())
+			rtn = fmt.Sprintf("recovered panic: %v", r)
+		}
+	}()
+	var order *struct{ Items []string }
+	log.Printf("#chaos #panic looking up the items of a missing order")
+	return fmt.Sprintf("order has %d items", len(order.Items)) // nil pointer dereference
+}
+
+// injectCrash panics in a goroutine without a recover, which kills the process
+func (cs *chaosService) injectCrash() string {
+	log.Printf("#chaos #crash unrecovered panic in 1s, the demo process will exit")
+	outrig.Go("chaos.crash").WithTags("chaos", "crash").WithoutRecover().Run(func() {
+		time.Sleep(1 * time.Second)
+		var orders map[string]int
+		orders["pending"]++ // assignment to entry in nil map
+	})
+	return "crashing in 1s"
+}
+
+// reset releases the leaked goroutines and the retained memory
+func (cs *chaosService) reset() string {
+	cs.lock.Lock()
+	close(cs.leakCh)
+	cs.leakCh = make(chan struct{})
+	leaked := cs.leakedCount
+	cs.leakedCount = 0
+	cs.cache = nil
+	cs.cacheBytes = 0
+	cs.lock.Unlock()
+	debug.FreeOSMemory()
+	log.Printf("#chaos reset: released %d leaked goroutines and the cache (deadlocked goroutines can't be released)", leaked)
+	return fmt.Sprintf("released %d goroutines and the cache", leaked)
+}
+
+func (cs *chaosService) handleFault(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	kind := r.URL.Query().Get("kind")
+	var result string
+	switch kind {
+	case ChaosFaultLeak:
+		result = cs.injectLeak()
+	case ChaosFaultDeadlock:
+		result = cs.injectDeadlock()
+	case ChaosFaultMemory:
+		result = cs.injectMemory()
+	case ChaosFaultPanic:
+		result = cs.injectPanic()
+	case ChaosFaultCrash:
+		result = cs.injectCrash()
+	case ChaosFaultReset:
+		result = cs.reset()
+	default:
+		http.Error(w, fmt.Sprintf("unknown fault kind %q", kind), http.StatusBadRequest)
+		return
+	}
+	writeChaosJson(w, map[string]any{"result": result, "status": cs.getStatus()})
+}
+
+func (cs *chaosService) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeChaosJson(w, cs.getStatus())
+}
+
+func writeChaosJson(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("#chaos #error error writing response: %v", err)
+	}
+}
+
+// RunChaosDemo runs the chaos demo: an order processing service with buttons to inject
+// goroutine leaks, deadlocks, memory growth, and panics
+func RunChaosDemo(config Config) {
+	outrig.Init("OutrigChaos", nil)
+
+	if config.CloseOnStdin {
+		log.Printf("#system Demo will shut down when stdin is closed")
+		outrig.Go("demo.StdinMonitor").WithTags("system").Run(func() {
+			buffer := make([]byte, 4096)
+			for {
+				_, err := os.Stdin.Read(buffer)
+				if err != nil {
+					log.Printf("#system Stdin closed, shutting down demo")
+					os.Exit(0)
+				}
+			}
+		})
+	}
+
+	cs := makeChaosService()
+	cs.startWorkers()
+	outrig.NewWatch("chaos-status").WithTags("chaos").AsJSON().PollFunc(cs.getStatus)
+	outrig.NewWatch("demo-config").AsJSON().Static(config)
+
+	mux := http.NewServeMux()
+	if config.DevMode {
+		log.Printf("#system #dev Running in development mode - serving files from disk")
+		mux.Handle("/", http.FileServer(http.Dir("./chaosfrontend/")))
+	} else {
+		frontendSubFS, _ := fs.Sub(chaosFrontendFS, "chaosfrontend")
+		mux.Handle("/", http.FileServer(http.FS(frontendSubFS)))
+	}
+	mux.HandleFunc("/api/fault", cs.handleFault)
+	mux.HandleFunc("/api/status", cs.handleStatus)
+
+	port := config.Port
+	if port == 0 {
+		port = ChaosPreferredPort
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		log.Printf("#system #error Could not bind to port %d: %v", port, err)
+		os.Exit(1)
+	}
+
+	url := fmt.Sprintf("http://localhost:%d", port)
+	outrig.NewWatch("chaos-url").Static(url)
+
+	log.Printf("#system Outrig chaos demo launched, available at: %s", url)
+	if !config.NoBrowserLaunch {
+		err := utilfn.LaunchUrl(url)
+		if err != nil {
+			log.Printf("#system #error Failed to open browser: %v", err)
+		}
+	}
+	log.Fatal(http.Serve(listener, mux))
+}
@@ -5,6 +5,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -17,6 +18,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -24,9 +26,13 @@ import (
 	"github.com/outrigdev/outrig/pkg/config"
 	"github.com/outrigdev/outrig/server/demo"
 	"github.com/outrigdev/outrig/server/pkg/boot"
+	"github.com/outrigdev/outrig/server/pkg/cliclient"
 	"github.com/outrigdev/outrig/server/pkg/execlogwrap"
+	"github.com/outrigdev/outrig/server/pkg/packetrecord"
 	"github.com/outrigdev/outrig/server/pkg/runmode"
 	"github.com/outrigdev/outrig/server/pkg/serverbase"
+	"github.com/outrigdev/outrig/server/pkg/serverconfig"
+	"github.com/outrigdev/outrig/server/pkg/serverutil"
 	"github.com/outrigdev/outrig/server/pkg/tevent"
 	"github.com/outrigdev/outrig/server/pkg/updatecheck"
 	"github.com/spf13/cobra"
@@ -37,6 +43,12 @@ type specialArgs struct {
 	ConfigFile         string
 	NoRun              bool
 	NoMonitorAutostart bool
+	MonitorFreePort    bool
+	NoTransformCache   bool
+	NoToolchainPin     bool
+	Watch              bool     // rebuild and restart on source changes (--watch before or right after "run")
+	Report             string   // transform report path from --report ("-" for stdout)
+	Meta               []string // key=value app run metadata from --meta
 	Args               []string
 }
 
@@ -56,7 +68,7 @@ func parseSpecialArgs(keyArg string) (specialArgs, error) {
 		return result, fmt.Errorf("key argument '%s' not found in command line", keyArg)
 	}
 
-	// Look for -v, --config, and --norun flags before keyArg
+	// Look for -v, --config, --norun, --report, the monitor flags, and --meta flags before keyArg
 	for i := 1; i < keyArgIndex; i++ {
 		arg := os.Args[i]
 		if arg == "-v" {
@@ -66,6 +78,34 @@ func parseSpecialArgs(keyArg string) (specialArgs, error) {
 			i++ // Skip the next argument since it's the config file value
 		} else if arg == "--norun" {
 			result.NoRun = true
+		} else if arg == "--report" {
+			result.Report = runmode.ReportStdout
+		} else if strings.HasPrefix(arg, "--report=") {
+			result.Report = strings.TrimPrefix(arg, "--report=")
+		} else if arg == "--no-monitor-autostart" {
+			result.NoMonitorAutostart = true
+		} else if arg == "--monitor-free-port" {
+			result.MonitorFreePort = true
+		} else if arg == "--no-transform-cache" {
+			result.NoTransformCache = true
+		} else if arg == "--no-toolchain-pin" {
+			result.NoToolchainPin = true
+		} else if arg == "--watch" {
+			result.Watch = true
+		} else if arg == "--meta" && i+1 < keyArgIndex {
+			result.Meta = append(result.Meta, os.Args[i+1])
+			i++
+		} else if strings.HasPrefix(arg, "--meta=") {
+			result.Meta = append(result.Meta, strings.TrimPrefix(arg, "--meta="))
+		}
+	}
+	if result.Report != "" && !result.NoRun {
+		return result, fmt.Errorf("--report requires --norun")
+	}
+	for _, meta := range result.Meta {
+		key, _, found := strings.Cut(meta, "=")
+		if !found || strings.TrimSpace(key) == "" || strings.Contains(meta, ",") {
+			return result, fmt.Errorf("invalid --meta value %q (expected key=value without commas)", meta)
 		}
 	}
 
@@ -73,6 +113,14 @@ func parseSpecialArgs(keyArg string) (specialArgs, error) {
 	if keyArgIndex+1 < len(os.Args) {
 		result.Args = os.Args[keyArgIndex+1:]
 	}
+	// "outrig run --watch main.go" (go run has no --watch flag)
+	if keyArg == "run" && len(result.Args) > 0 && result.Args[0] == "--watch" {
+		result.Watch = true
+		result.Args = result.Args[1:]
+	}
+	if result.Watch && result.NoRun {
+		return result, fmt.Errorf("--watch cannot be used with --norun")
+	}
 
 	return result, nil
 }
@@ -104,6 +152,16 @@ func runCaptureLogs(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	appRunId := config.GetExternalAppRunId()
+	// the app passes its redaction config (it can differ from the loaded config when set in code),
+	// the logs are not sent if it can't be parsed
+	if redactJson := os.Getenv(config.RedactJsonEnvName); redactJson != "" {
+		cfg.Redact = config.RedactConfig{}
+		if err := json.Unmarshal([]byte(redactJson), &cfg.Redact); err != nil {
+			fmt.Fprintf(os.Stderr, "#outrig not capturing logs, invalid %s: %v\n", config.RedactJsonEnvName, err)
+			appRunId = ""
+		}
+	}
 
 	stderrIn := os.NewFile(3, "stderr-in")
 	source, _ := cmd.Flags().GetString("source")
@@ -114,7 +172,132 @@ func runCaptureLogs(cmd *cobra.Command, args []string) error {
 		{Input: os.Stdin, Output: os.Stdout, Source: source},
 		{Input: stderrIn, Output: os.Stderr, Source: "/dev/stderr"},
 	}
-	return execlogwrap.ProcessExistingStreams(streams, config.GetExternalAppRunId(), cfg)
+	return execlogwrap.ProcessExistingStreams(streams, appRunId, cfg)
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	speed, _ := cmd.Flags().GetFloat64("speed")
+	if speed < 0 {
+		return fmt.Errorf("--speed cannot be negative")
+	}
+	cfg, err := loadOutrigConfig("", "")
+	if err != nil {
+		return err
+	}
+	if _, _, _, err := comm.GetServerVersion(cfg); err != nil {
+		return fmt.Errorf("cannot connect to the Outrig Monitor (start it with 'outrig monitor'): %w", err)
+	}
+	opts := packetrecord.ReplayOpts{Speed: speed}
+	appRunId, numSent, err := packetrecord.Replay(args[0], opts, cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Replayed %d packets as app run %s\n", numSent, appRunId)
+	return nil
+}
+
+// importResponse is the json response of the monitor's /api/import endpoint
+type importResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+	Data    struct {
+		AppRunId         string `json:"apprunid"`
+		AppName          string `json:"appname"`
+		ExportedAppRunId string `json:"exportedapprunid"`
+		NumLogs          int    `json:"numlogs"`
+	} `json:"data"`
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	serverAddr, _ := cmd.Flags().GetString("addr")
+	host, port, err := getMonitorHostPort(serverAddr)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	monitorHost := net.JoinHostPort(host, strconv.Itoa(port))
+	importURL := &url.URL{Scheme: "http", Host: monitorHost, Path: "/api/import"}
+	resp, err := http.Post(importURL.String(), "application/gzip", file)
+	if err != nil {
+		return fmt.Errorf("cannot connect to the Outrig Monitor (start it with 'outrig monitor'): %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	var importResp importResponse
+	if err := json.Unmarshal(body, &importResp); err != nil {
+		return fmt.Errorf("import request failed with status: %s", resp.Status)
+	}
+	if !importResp.Success {
+		return fmt.Errorf("import failed: %s", importResp.Error)
+	}
+	fmt.Printf("Imported %s (app run %s, %d logs) as read-only app run %s\n", importResp.Data.AppName, importResp.Data.ExportedAppRunId, importResp.Data.NumLogs, importResp.Data.AppRunId)
+	fmt.Printf("http://%s/?tab=logs&appRunId=%s\n", monitorHost, importResp.Data.AppRunId)
+	return nil
+}
+
+func runApiKeyCreate(cmd *cobra.Command, args []string) error {
+	scopes, _ := cmd.Flags().GetStringSlice("scope")
+	apiKey, err := serverbase.CreateApiKey(args[0], scopes)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created read-only api key %q (scopes: %s)\n", apiKey.Name, strings.Join(apiKey.Scopes, ","))
+	fmt.Printf("%s\n", apiKey.Key)
+	fmt.Printf("Use it with \"Authorization: Bearer <key>\" on /api/embed/...\n")
+	return nil
+}
+
+func runApiKeyList(cmd *cobra.Command, args []string) error {
+	apiKeys, err := serverbase.LoadApiKeys()
+	if err != nil {
+		return err
+	}
+	if len(apiKeys) == 0 {
+		fmt.Printf("No api keys (create one with 'outrig apikey create <name>')\n")
+		return nil
+	}
+	for _, apiKey := range apiKeys {
+		created := time.UnixMilli(apiKey.CreatedTs).Format(time.DateTime)
+		fmt.Printf("%-20s %s...  %-24s created %s\n", apiKey.Name, apiKey.Key[:min(len(apiKey.Key), 10)], strings.Join(apiKey.Scopes, ","), created)
+	}
+	return nil
+}
+
+func runApiKeyRevoke(cmd *cobra.Command, args []string) error {
+	if err := serverbase.RevokeApiKey(args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("Revoked api key %q\n", args[0])
+	return nil
+}
+
+// addDemoFlags adds the flags shared by the demo commands
+func addDemoFlags(cmd *cobra.Command, defaultPort int) {
+	cmd.Flags().Bool("dev", false, "Run in development mode (serve files from disk)")
+	cmd.Flags().Bool("no-browser-launch", false, "Don't automatically open the browser")
+	cmd.Flags().Int("port", 0, fmt.Sprintf("Override the default demo server port (default: %d)", defaultPort))
+	cmd.Flags().Bool("close-on-stdin", false, "Shut down the demo when stdin is closed")
+}
+
+func getDemoConfig(cmd *cobra.Command) demo.Config {
+	devMode, _ := cmd.Flags().GetBool("dev")
+	noBrowserLaunch, _ := cmd.Flags().GetBool("no-browser-launch")
+	port, _ := cmd.Flags().GetInt("port")
+	closeOnStdin, _ := cmd.Flags().GetBool("close-on-stdin")
+	return demo.Config{
+		DevMode:         devMode,
+		NoBrowserLaunch: noBrowserLaunch,
+		Port:            port,
+		CloseOnStdin:    closeOnStdin,
+	}
 }
 
 func runPostinstall(cmd *cobra.Command, args []string) {
@@ -177,6 +360,27 @@ func runMonitorForeground(cmd *cobra.Command, args []string) error {
 	listenAddr, _ := cmd.Flags().GetString("listen")
 	closeOnStdin, _ := cmd.Flags().GetBool("close-on-stdin")
 	trayPid, _ := cmd.Flags().GetInt("tray-pid")
+	remoteListenAddr, _ := cmd.Flags().GetString("remote-listen")
+	tlsCertFile, _ := cmd.Flags().GetString("tls-cert")
+	tlsKeyFile, _ := cmd.Flags().GetString("tls-key")
+	tlsClientCAFile, _ := cmd.Flags().GetString("tls-client-ca")
+	logBufferSizeMB, _ := cmd.Flags().GetInt("log-buffer-size")
+	maxRunsPerApp, _ := cmd.Flags().GetInt("max-runs-per-app")
+	maxRunAge, _ := cmd.Flags().GetDuration("max-run-age")
+	maxDataDirSizeMB, _ := cmd.Flags().GetInt("max-data-dir-size")
+	recordPacketsDir, _ := cmd.Flags().GetString("record-packets")
+	embedOrigins, _ := cmd.Flags().GetStringArray("embed-origin")
+	downstreamFlags, _ := cmd.Flags().GetStringArray("downstream")
+	if maxRunsPerApp < 0 || maxRunAge < 0 {
+		return fmt.Errorf("--max-runs-per-app and --max-run-age cannot be negative")
+	}
+	if maxDataDirSizeMB < 0 {
+		return fmt.Errorf("--max-data-dir-size cannot be negative")
+	}
+	downstreams, err := parseDownstreamFlags(downstreamFlags)
+	if err != nil {
+		return err
+	}
 
 	// Validate listen address if provided
 	if listenAddr != "" {
@@ -195,11 +399,51 @@ func runMonitorForeground(cmd *cobra.Command, args []string) error {
 		ListenAddr:   listenAddr,
 		CloseOnStdin: closeOnStdin,
 		TrayAppPid:   trayPid,
+
+		RemoteListenAddr: remoteListenAddr,
+		TLSCertFile:      tlsCertFile,
+		TLSKeyFile:       tlsKeyFile,
+		TLSClientCAFile:  tlsClientCAFile,
+
+		LogBufferSizeMB: logBufferSizeMB,
+
+		MaxAppRunsPerApp: maxRunsPerApp,
+		MaxAppRunAge:     maxRunAge,
+
+		MaxDataDirSizeMB: maxDataDirSizeMB,
+
+		PacketRecordDir: recordPacketsDir,
+
+		EmbedAllowedOrigins: embedOrigins,
+
+		Downstreams: downstreams,
 	}
 
 	return boot.RunServer(cfg)
 }
 
+// parseDownstreamFlags parses the --downstream flags (name=apikey@host:port)
+func parseDownstreamFlags(flags []string) (map[string]string, error) {
+	if len(flags) == 0 {
+		return nil, nil
+	}
+	downstreams := make(map[string]string)
+	for _, flag := range flags {
+		name, addr, ok := strings.Cut(flag, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --downstream %q (expected name=apikey@host:port)", flag)
+		}
+		if _, exists := downstreams[name]; exists {
+			return nil, fmt.Errorf("duplicate --downstream name %q", name)
+		}
+		downstreams[name] = addr
+	}
+	if err := serverconfig.ValidateDownstreams(downstreams); err != nil {
+		return nil, fmt.Errorf("invalid --downstream: %w", err)
+	}
+	return downstreams, nil
+}
+
 func runMonitorStart(cmd *cobra.Command, args []string) error {
 	// Load default config to check if monitor is already running
 	cfg, err := loadOutrigConfig("", "")
@@ -250,9 +494,7 @@ func runMonitorStart(cmd *cobra.Command, args []string) error {
 	daemonCmd.Stderr = logFile
 
 	// Use process group detachment for daemonization
-	daemonCmd.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true, // Create new process group
-	}
+	daemonCmd.SysProcAttr = serverutil.DaemonSysProcAttr()
 
 	// Start the daemon process
 	err = daemonCmd.Start()
@@ -271,7 +513,7 @@ func runMonitorStart(cmd *cobra.Command, args []string) error {
 
 	for time.Since(startTime) < timeout {
 		// Check if the process is still alive
-		if err := daemonCmd.Process.Signal(syscall.Signal(0)); err != nil {
+		if !serverutil.IsProcessAlive(daemonCmd.Process) {
 			// Process died
 			return fmt.Errorf("failed to start - monitor process died, see the log for details")
 		}
@@ -298,37 +540,91 @@ func runMonitorStart(cmd *cobra.Command, args []string) error {
 	}
 
 	// Timeout reached - check if process is still alive
-	if err := daemonCmd.Process.Signal(syscall.Signal(0)); err != nil {
+	if !serverutil.IsProcessAlive(daemonCmd.Process) {
 		return fmt.Errorf("failed to start - monitor process died, see the log for details")
 	}
 
 	return fmt.Errorf("failed to start - could not connect to monitor after 3 seconds, see the log for details")
 }
 
+func runLogs(cmd *cobra.Command, args []string) error {
+	serverAddr, _ := cmd.Flags().GetString("addr")
+	search, _ := cmd.Flags().GetString("search")
+	follow, _ := cmd.Flags().GetBool("follow")
+	asJson, _ := cmd.Flags().GetBool("json")
+	lines, _ := cmd.Flags().GetInt("lines")
+	host, port, err := getMonitorHostPort(serverAddr)
+	if err != nil {
+		return err
+	}
+	client, err := cliclient.Connect(host, port)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	opts := cliclient.LogsOpts{
+		Search: search,
+		Lines:  lines,
+		Follow: follow,
+		Json:   asJson,
+	}
+	if len(args) > 0 {
+		opts.AppRun = args[0]
+	}
+	return cliclient.RunLogs(ctx, client, opts, os.Stdout)
+}
+
+func runGoRoutines(cmd *cobra.Command, args []string) error {
+	serverAddr, _ := cmd.Flags().GetString("addr")
+	opts := cliclient.GoRoutinesOpts{}
+	opts.Search, _ = cmd.Flags().GetString("search")
+	opts.State, _ = cmd.Flags().GetString("state")
+	opts.Name, _ = cmd.Flags().GetString("name")
+	opts.Pkg, _ = cmd.Flags().GetString("pkg")
+	opts.ShowOutrig, _ = cmd.Flags().GetBool("outrig")
+	opts.Json, _ = cmd.Flags().GetBool("json")
+	if len(args) > 0 {
+		opts.AppRun = args[0]
+	}
+	host, port, err := getMonitorHostPort(serverAddr)
+	if err != nil {
+		return err
+	}
+	client, err := cliclient.Connect(host, port)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return cliclient.RunGoRoutines(client, opts, os.Stdout)
+}
+
+// getMonitorHostPort returns the host and port of the monitor's web server (serverAddr overrides the defaults)
+func getMonitorHostPort(serverAddr string) (string, int, error) {
+	if serverAddr == "" {
+		return serverbase.GetWebServerHost(), serverbase.GetWebServerPort(), nil
+	}
+	host, portStr, err := net.SplitHostPort(serverAddr)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid server address '%s': %w", serverAddr, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port in server address '%s': %w", serverAddr, err)
+	}
+	return host, port, nil
+}
+
 func runMonitorStop(cmd *cobra.Command, args []string) error {
 	// Get flags
 	serverAddr, _ := cmd.Flags().GetString("addr")
 	verbose, _ := cmd.Flags().GetBool("verbose")
 
-	var host string
-	var port int
-
-	if serverAddr != "" {
-		// Parse the provided server address
-		var err error
-		var portStr string
-		host, portStr, err = net.SplitHostPort(serverAddr)
-		if err != nil {
-			return fmt.Errorf("invalid server address '%s': %w", serverAddr, err)
-		}
-		port, err = strconv.Atoi(portStr)
-		if err != nil {
-			return fmt.Errorf("invalid port in server address '%s': %w", serverAddr, err)
-		}
-	} else {
-		// Use default values
-		host = serverbase.GetWebServerHost()
-		port = serverbase.GetWebServerPort()
+	host, port, err := getMonitorHostPort(serverAddr)
+	if err != nil {
+		return err
 	}
 
 	// Construct the shutdown URL using proper URL construction
@@ -438,6 +734,17 @@ func main() {
 	monitorStartCmd.Flags().Bool("no-telemetry", false, "Disable telemetry collection")
 	monitorStartCmd.Flags().Bool("no-updatecheck", false, "Disable checking for updates")
 	monitorStartCmd.Flags().String("listen", "", "Override the default web server listen address (default: 127.0.0.1:5005)")
+	monitorStartCmd.Flags().String("remote-listen", "", "Accept SDK connections from remote hosts on this address (e.g. 0.0.0.0:5006)")
+	monitorStartCmd.Flags().String("tls-cert", "", "TLS certificate file for the remote listener")
+	monitorStartCmd.Flags().String("tls-key", "", "TLS key file for the remote listener")
+	monitorStartCmd.Flags().String("tls-client-ca", "", "Require remote SDK clients to present a certificate signed by this CA (mTLS)")
+	monitorStartCmd.Flags().Int("log-buffer-size", serverbase.DefaultLogBufferSizeMB, "Size (in MB) of the on-disk log buffer per app run, e.g. 1024 (0, the default, keeps log lines in memory)")
+	monitorStartCmd.Flags().Int("max-runs-per-app", 0, "Number of app runs to keep for each app name, older finished runs are pruned (0 for no limit)")
+	monitorStartCmd.Flags().Duration("max-run-age", 0, "Prune finished app runs that haven't been updated for this long, e.g. 24h (0 for no limit)")
+	monitorStartCmd.Flags().Int("max-data-dir-size", 0, "Warn (in the tray menu and with a server:diskusagewarning event) when the data directory grows past this size in MB (0 for no warning)")
+	monitorStartCmd.Flags().String("record-packets", "", "Record the raw packets of each app run to <dir>/<apprunid>.packets.jsonl (for 'outrig replay')")
+	monitorStartCmd.Flags().StringArray("embed-origin", nil, "Allow this origin (e.g. https://grafana.internal, or *) to call the read-only embed API from a browser (can be repeated)")
+	monitorStartCmd.Flags().StringArray("downstream", nil, "Show the app runs of another monitor read-only, as name=apikey@host:port with a federation api key of that monitor (can be repeated)")
 
 	monitorForegroundCmd := &cobra.Command{
 		Use:          "foreground",
@@ -450,6 +757,17 @@ func main() {
 	monitorForegroundCmd.Flags().Bool("no-telemetry", false, "Disable telemetry collection")
 	monitorForegroundCmd.Flags().Bool("no-updatecheck", false, "Disable checking for updates")
 	monitorForegroundCmd.Flags().String("listen", "", "Override the default web server listen address (default: 127.0.0.1:5005)")
+	monitorForegroundCmd.Flags().String("remote-listen", "", "Accept SDK connections from remote hosts on this address (e.g. 0.0.0.0:5006)")
+	monitorForegroundCmd.Flags().String("tls-cert", "", "TLS certificate file for the remote listener")
+	monitorForegroundCmd.Flags().String("tls-key", "", "TLS key file for the remote listener")
+	monitorForegroundCmd.Flags().String("tls-client-ca", "", "Require remote SDK clients to present a certificate signed by this CA (mTLS)")
+	monitorForegroundCmd.Flags().Int("log-buffer-size", serverbase.DefaultLogBufferSizeMB, "Size (in MB) of the on-disk log buffer per app run, e.g. 1024 (0, the default, keeps log lines in memory)")
+	monitorForegroundCmd.Flags().Int("max-runs-per-app", 0, "Number of app runs to keep for each app name, older finished runs are pruned (0 for no limit)")
+	monitorForegroundCmd.Flags().Duration("max-run-age", 0, "Prune finished app runs that haven't been updated for this long, e.g. 24h (0 for no limit)")
+	monitorForegroundCmd.Flags().Int("max-data-dir-size", 0, "Warn (in the tray menu and with a server:diskusagewarning event) when the data directory grows past this size in MB (0 for no warning)")
+	monitorForegroundCmd.Flags().String("record-packets", "", "Record the raw packets of each app run to <dir>/<apprunid>.packets.jsonl (for 'outrig replay')")
+	monitorForegroundCmd.Flags().StringArray("embed-origin", nil, "Allow this origin (e.g. https://grafana.internal, or *) to call the read-only embed API from a browser (can be repeated)")
+	monitorForegroundCmd.Flags().StringArray("downstream", nil, "Show the app runs of another monitor read-only, as name=apikey@host:port with a federation api key of that monitor (can be repeated)")
 	monitorForegroundCmd.Flags().Bool("close-on-stdin", false, "Shut down the server when stdin is closed")
 	monitorForegroundCmd.Flags().Int("tray-pid", 0, "PID of the tray application that started the server")
 	monitorForegroundCmd.Flags().MarkHidden("tray-pid")
@@ -493,7 +811,18 @@ func main() {
 		Long: `A drop-in replacement for "go run" that accepts identical arguments. Run your Go programs as usual and instantly gain access to Outrig's real-time insights—no code changes required.
 
 Example:
-  outrig run main.go`,
+  outrig run main.go
+
+Attach metadata to the app run with --meta (before "run", can be repeated):
+  outrig --meta gitsha=$(git rev-parse HEAD) --meta env=staging run main.go
+
+Rebuild and restart the program when its source files change (each restart is a new app run, the
+runs share the "runseries" metadata):
+  outrig run --watch ./cmd/myapp
+
+Audit the instrumentation without running anything (JSON report of every change, to stdout or a file):
+  outrig --norun --report run main.go
+  outrig --norun --report=outrig-report.json run ./cmd/myapp`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			specialArgs, err := parseSpecialArgs("run")
 			if err != nil {
@@ -509,7 +838,13 @@ Example:
 				IsVerbose:          specialArgs.IsVerbose,
 				NoRun:              specialArgs.NoRun,
 				NoMonitorAutostart: specialArgs.NoMonitorAutostart,
+				MonitorFreePort:    specialArgs.MonitorFreePort,
+				NoTransformCache:   specialArgs.NoTransformCache,
+				NoToolchainPin:     specialArgs.NoToolchainPin,
 				ConfigFile:         specialArgs.ConfigFile,
+				Meta:               specialArgs.Meta,
+				Report:             specialArgs.Report,
+				Watch:              specialArgs.Watch,
 			}
 			return runmode.ExecRunMode(cfg)
 		},
@@ -518,6 +853,41 @@ Example:
 		SilenceUsage:       true,
 	}
 
+	buildCmd := &cobra.Command{
+		Use:   "build [go-args]",
+		Short: "Drop-in replacement for 'go build' that produces an Outrig instrumented binary",
+		Long: `A drop-in replacement for "go build" that accepts identical arguments. The resulting binary is
+permanently instrumented (the same transformation as "outrig run") and connects to the Outrig monitor
+configured where it runs, so it can be deployed to a machine running the monitor.
+
+Example:
+  outrig build -o myapp ./cmd/myapp`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			specialArgs, err := parseSpecialArgs("build")
+			if err != nil {
+				return err
+			}
+
+			if len(specialArgs.Args) == 0 {
+				return fmt.Errorf("build command requires at least one argument")
+			}
+
+			cfg := runmode.RunModeConfig{
+				Args:             specialArgs.Args,
+				IsVerbose:        specialArgs.IsVerbose,
+				NoRun:            specialArgs.NoRun,
+				NoTransformCache: specialArgs.NoTransformCache,
+				NoToolchainPin:   specialArgs.NoToolchainPin,
+				ConfigFile:       specialArgs.ConfigFile,
+				Report:           specialArgs.Report,
+			}
+			return runmode.ExecBuildMode(cfg)
+		},
+		// Disable flag parsing for this command so all flags are passed to the go command
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+	}
+
 	execCmd := &cobra.Command{
 		Use:   "exec [command]",
 		Short: "Execute a command with Outrig logging",
@@ -542,6 +912,117 @@ Example: outrig --dev exec ls -latrh`,
 		Hidden:             true,
 	}
 
+	replayCmd := &cobra.Command{
+		Use:   "replay <file>",
+		Short: "Replay a packet recording into the Outrig Monitor",
+		Long: `Replay a packet recording (made with 'outrig monitor --record-packets <dir>') into the running
+Outrig Monitor as a new app run. Useful for reproducing bugs and for demo datasets.
+
+Example:
+  outrig replay --speed 10 ~/recordings/<apprunid>.packets.jsonl`,
+		Args:         cobra.ExactArgs(1),
+		RunE:         runReplay,
+		SilenceUsage: true,
+	}
+	replayCmd.Flags().Float64("speed", 1, "Playback speed relative to the original timing (0 sends packets as fast as possible)")
+
+	importCmd := &cobra.Command{
+		Use:   "import <bundle>",
+		Short: "Import an exported app run bundle into the Outrig Monitor",
+		Long: `Import an app run bundle (exported from the Outrig Monitor, e.g. attached to a bug report) into the
+running Outrig Monitor. The bundle is loaded as a new read-only app run with its logs, goroutine history,
+watches, and runtime stats.
+
+Example:
+  outrig import myapp-20250102-150405.outrig.jsonl.gz`,
+		Args:         cobra.ExactArgs(1),
+		RunE:         runImport,
+		SilenceUsage: true,
+	}
+	importCmd.Flags().String("addr", "", "Override the default server address to connect to (default: localhost:5005)")
+
+	logsCmd := &cobra.Command{
+		Use:   "logs [apprunid|appname]",
+		Short: "Print (and follow) the logs of an app run in the terminal",
+		Long: `Print the log lines of an app run from the running Outrig Monitor, useful over SSH where the web UI
+isn't handy. The app run can be given as an app run id (or a unique prefix of one) or as an app name (the
+newest run of that app), by default the newest running app run is used. --search takes the same search
+syntax as the log viewer.
+
+Examples:
+  outrig logs -f
+  outrig logs myapp --search "error | panic" -n 50
+  outrig logs myapp --search '$level:warn+'
+  outrig logs 4f1c --json -n -1 > logs.jsonl`,
+		Args:         cobra.MaximumNArgs(1),
+		RunE:         runLogs,
+		SilenceUsage: true,
+	}
+	logsCmd.Flags().String("addr", "", "Override the default server address to connect to (default: localhost:5005)")
+	logsCmd.Flags().StringP("search", "s", "", "Only print lines matching this search")
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep printing new matching lines")
+	logsCmd.Flags().Bool("json", false, "Print one JSON object per log line")
+	logsCmd.Flags().IntP("lines", "n", 100, "Number of matching lines to print (the newest ones, -1 for all)")
+
+	goRoutinesCmd := &cobra.Command{
+		Use:   "goroutines [apprunid|appname]",
+		Short: "Print the current goroutines of an app run",
+		Long: `Print the goroutines from the latest goroutine snapshot of an app run (from the running Outrig Monitor)
+as a table or as JSON, so you can grab a snapshot during an incident without the web UI. The app run is
+selected like in 'outrig logs'. --search takes the same search syntax as the goroutine viewer, --state and
+--name match the goroutine state and name, --pkg keeps goroutines with a stack frame in a package.
+
+Examples:
+  outrig goroutines
+  outrig goroutines myapp --state "chan receive" --pkg github.com/me/myapp/worker
+  outrig goroutines 4f1c --json > goroutines.json`,
+		Args:         cobra.MaximumNArgs(1),
+		RunE:         runGoRoutines,
+		SilenceUsage: true,
+	}
+	goRoutinesCmd.Flags().String("addr", "", "Override the default server address to connect to (default: localhost:5005)")
+	goRoutinesCmd.Flags().StringP("search", "s", "", "Only print goroutines matching this search")
+	goRoutinesCmd.Flags().String("state", "", "Only print goroutines in this state (e.g. \"chan receive\", running)")
+	goRoutinesCmd.Flags().String("name", "", "Only print goroutines whose name contains this")
+	goRoutinesCmd.Flags().String("pkg", "", "Only print goroutines with a stack frame in this package (or its subpackages)")
+	goRoutinesCmd.Flags().Bool("outrig", false, "Include the Outrig SDK's own goroutines")
+	goRoutinesCmd.Flags().Bool("json", false, "Print the parsed goroutines as JSON")
+
+	apiKeyCmd := &cobra.Command{
+		Use:   "apikey",
+		Short: "Manage read-only API keys for the embed API",
+		Long: `Manage read-only API keys for the monitor's embed API (/api/embed/...), which lets internal
+dashboards show Outrig goroutine counts and logs. Keys are stored in apikeys.json in the Outrig home
+directory and take effect without restarting the monitor. Allow browser access from a dashboard with
+'outrig monitor start --embed-origin <origin>'. A key with the federation scope lets another monitor
+show this monitor's app runs (--downstream).`,
+	}
+	apiKeyCreateCmd := &cobra.Command{
+		Use:          "create <name>",
+		Short:        "Create a read-only API key",
+		Args:         cobra.ExactArgs(1),
+		RunE:         runApiKeyCreate,
+		SilenceUsage: true,
+	}
+	apiKeyCreateCmd.Flags().StringSlice("scope", nil, "Limit the key to these scopes: goroutines, logs, runtimestats, federation (default: all)")
+	apiKeyListCmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List the API keys",
+		Args:         cobra.NoArgs,
+		RunE:         runApiKeyList,
+		SilenceUsage: true,
+	}
+	apiKeyRevokeCmd := &cobra.Command{
+		Use:          "revoke <name>",
+		Short:        "Revoke an API key",
+		Args:         cobra.ExactArgs(1),
+		RunE:         runApiKeyRevoke,
+		SilenceUsage: true,
+	}
+	apiKeyCmd.AddCommand(apiKeyCreateCmd)
+	apiKeyCmd.AddCommand(apiKeyListCmd)
+	apiKeyCmd.AddCommand(apiKeyRevokeCmd)
+
 	postinstallCmd := &cobra.Command{
 		Use:   "postinstall",
 		Short: "Display post-installation information",
@@ -554,26 +1035,26 @@ Example: outrig --dev exec ls -latrh`,
 		Short: "Run the OutrigAcres demo game",
 		Long:  `Run the OutrigAcres demo game to showcase Outrig's debugging capabilities.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			devMode, _ := cmd.Flags().GetBool("dev")
-			noBrowserLaunch, _ := cmd.Flags().GetBool("no-browser-launch")
-			port, _ := cmd.Flags().GetInt("port")
-			closeOnStdin, _ := cmd.Flags().GetBool("close-on-stdin")
-
-			cfg := demo.Config{
-				DevMode:         devMode,
-				NoBrowserLaunch: noBrowserLaunch,
-				Port:            port,
-				CloseOnStdin:    closeOnStdin,
-			}
-
-			demo.RunOutrigAcres(cfg)
+			demo.RunOutrigAcres(getDemoConfig(cmd))
+			return nil
+		},
+	}
+	addDemoFlags(demoCmd, demo.PreferredPort)
+
+	demoChaosCmd := &cobra.Command{
+		Use:   "chaos",
+		Short: "Run the chaos demo (a service with injectable goroutine leaks, deadlocks, memory growth, and panics)",
+		Long: `Run the chaos demo, a small order processing service with buttons to inject bugs on demand:
+goroutine leaks, deadlocks, memory growth, recovered panics, and crashes.
+Each bug class shows up in a different Outrig view (goroutines, runtime stats, watches, logs).`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			demo.RunChaosDemo(getDemoConfig(cmd))
 			return nil
 		},
 	}
-	demoCmd.Flags().Bool("dev", false, "Run in development mode (serve files from disk)")
-	demoCmd.Flags().Bool("no-browser-launch", false, "Don't automatically open the browser")
-	demoCmd.Flags().Int("port", 0, "Override the default demo server port (default: 22005)")
-	demoCmd.Flags().Bool("close-on-stdin", false, "Shut down the demo when stdin is closed")
+	addDemoFlags(demoChaosCmd, demo.ChaosPreferredPort)
+	demoCmd.AddCommand(demoChaosCmd)
 
 	rootCmd.AddCommand(monitorCmd)
 	rootCmd.AddCommand(serverCmd)
@@ -581,16 +1062,30 @@ Example: outrig --dev exec ls -latrh`,
 	rootCmd.AddCommand(captureLogsCmd)
 	rootCmd.AddCommand(execCmd)
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(buildCmd)
+	rootCmd.AddCommand(replayCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(goRoutinesCmd)
+	rootCmd.AddCommand(apiKeyCmd)
 	rootCmd.AddCommand(postinstallCmd)
 	rootCmd.AddCommand(demoCmd)
 	rootCmd.PersistentFlags().Bool("dev", false, "Run in dev mode")
 	rootCmd.PersistentFlags().MarkHidden("dev")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Verbose output")
 	rootCmd.PersistentFlags().MarkHidden("verbose")
-	rootCmd.PersistentFlags().Bool("norun", false, "Stop 'run' mode after generating new source files")
+	rootCmd.PersistentFlags().Bool("norun", false, "Stop 'run' or 'build' mode after generating new source files")
 	rootCmd.PersistentFlags().MarkHidden("norun")
+	rootCmd.PersistentFlags().String("report", "", "With --norun, write a JSON report of the 'run' or 'build' mode source transformation (--report for stdout, --report=<file>)")
+	rootCmd.PersistentFlags().Lookup("report").NoOptDefVal = runmode.ReportStdout
 	rootCmd.PersistentFlags().Bool("no-monitor-autostart", false, "Disable automatic monitor startup")
 	rootCmd.PersistentFlags().MarkHidden("no-monitor-autostart")
+	rootCmd.PersistentFlags().Bool("monitor-free-port", false, "In 'run' mode, autostart the monitor on a free port if another program holds the default port")
+	rootCmd.PersistentFlags().Bool("no-transform-cache", false, "Don't use the 'run' mode transform cache (~/.cache/outrig)")
+	rootCmd.PersistentFlags().MarkHidden("no-transform-cache")
+	rootCmd.PersistentFlags().Bool("no-toolchain-pin", false, "Don't pin GOTOOLCHAIN to the detected Go version in 'run' mode (for projects that need a specific toolchain)")
+	rootCmd.PersistentFlags().Bool("watch", false, "In 'run' mode, rebuild and restart the program when its source files change")
+	rootCmd.PersistentFlags().StringArray("meta", nil, "Attach key=value metadata to the app run in 'run' mode (can be repeated)")
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
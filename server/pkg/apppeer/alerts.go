@@ -0,0 +1,105 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/utilds"
+	"github.com/outrigdev/outrig/server/pkg/gensearch"
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// alertSearchers caches the parsed searches of the alert rules (search => searcher), rules are only
+// validated when the config file is loaded so a search that fails to parse here is skipped
+var alertSearchers = utilds.MakeSyncMap[string, gensearch.Searcher]()
+
+func getAlertSearcher(search string) gensearch.Searcher {
+	if searcher, ok := alertSearchers.GetEx(search); ok {
+		return searcher
+	}
+	searcher, err := gensearch.GetSearcher(search)
+	if err != nil {
+		log.Printf("invalid alert search %q: %v\n", search, err)
+	}
+	alertSearchers.Set(search, searcher)
+	return searcher
+}
+
+// CheckLogAlerts checks new log lines against the alert rules (serverbase.AlertRule), publishes an
+// Event_LogAlert for each match (at most one per rule and app run every CooldownSec) and returns the alerts.
+// Imported app runs are skipped (their lines are not new).
+func (p *AppRunPeer) CheckLogAlerts(lines []ds.LogLine) []rpctypes.LogAlertEvent {
+	rules := serverbase.GetRuntimeSettings().AlertRules
+	if len(rules) == 0 || p.AppInfo == nil || p.IsImported() {
+		return nil
+	}
+	var alerts []rpctypes.LogAlertEvent
+	sctx := &gensearch.SearchContext{}
+	for _, rule := range rules {
+		if rule.AppName != "" && rule.AppName != p.AppInfo.AppName {
+			continue
+		}
+		searcher := getAlertSearcher(rule.Search)
+		if searcher == nil {
+			continue
+		}
+		for _, line := range lines {
+			if !searcher.Match(sctx, gensearch.LogLineToSearchObject(line)) {
+				continue
+			}
+			if !p.startAlertCooldown(rule) {
+				break
+			}
+			alert := rpctypes.LogAlertEvent{
+				Ts:       time.Now().UnixMilli(),
+				AppRunId: p.AppRunId,
+				AppName:  p.AppInfo.AppName,
+				RuleName: rule.Name,
+				Search:   rule.Search,
+				LineNum:  line.LineNum,
+				Msg:      strings.TrimRight(line.Msg, "\n"),
+			}
+			p.publishLogAlert(alert)
+			alerts = append(alerts, alert)
+			if rule.CooldownSec > 0 {
+				break
+			}
+		}
+	}
+	return alerts
+}
+
+// startAlertCooldown returns false if the rule alerted for this app run less than CooldownSec ago
+func (p *AppRunPeer) startAlertCooldown(rule serverbase.AlertRule) bool {
+	now := time.Now().UnixMilli()
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	if lastTs, ok := p.lastAlertTs[rule.Name]; ok && now-lastTs < int64(rule.CooldownSec)*1000 {
+		return false
+	}
+	if p.lastAlertTs == nil {
+		p.lastAlertTs = make(map[string]int64)
+	}
+	p.lastAlertTs[rule.Name] = now
+	return true
+}
+
+func (p *AppRunPeer) publishLogAlert(alert rpctypes.LogAlertEvent) {
+	log.Printf("Log alert %q for app run %s (%s): %s\n", alert.RuleName, alert.AppRunId, alert.AppName, alert.Msg)
+	scopes := []string{p.AppRunId}
+	if routeId := p.GetRouteId(); routeId != "" {
+		scopes = append(scopes, routeId)
+	}
+	rpc.Broker.Publish(rpctypes.EventType{
+		Event:  rpctypes.Event_LogAlert,
+		Scopes: scopes,
+		Data:   alert,
+	})
+}
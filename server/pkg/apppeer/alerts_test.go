@@ -0,0 +1,76 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+func TestCheckLogAlerts(t *testing.T) {
+	peer := makeTestAppRunPeer(t)
+	peer.AppInfo = &ds.AppInfo{AppName: "api"}
+	setTestDataDir(t, serverbase.RuntimeSettings{AlertRules: []serverbase.AlertRule{
+		{Name: "errors", Search: "ERROR"},
+		{Name: "panics", Search: "panic", CooldownSec: 60},
+		{Name: "worker timeouts", AppName: "worker", Search: "timeout"},
+	}})
+
+	alerts := peer.CheckLogAlerts([]ds.LogLine{
+		{LineNum: 1, Msg: "ERROR db down\n"},
+		{LineNum: 2, Msg: "request timeout\n"},
+		{LineNum: 3, Msg: "panic: nil map\n"},
+		{LineNum: 4, Msg: "ERROR retrying\n"},
+		{LineNum: 5, Msg: "panic: again\n"},
+	})
+	var got []string
+	for _, alert := range alerts {
+		got = append(got, alert.RuleName+":"+alert.Msg)
+	}
+	// the timeout rule is for another app, the second panic is in the rule's cooldown
+	want := []string{"errors:ERROR db down", "errors:ERROR retrying", "panics:panic: nil map"}
+	if len(got) != len(want) {
+		t.Fatalf("expected alerts %v, got %v", want, got)
+	}
+	for idx := range want {
+		if got[idx] != want[idx] {
+			t.Errorf("alert %d: expected %q, got %q", idx, want[idx], got[idx])
+		}
+	}
+	if alerts[0].AppRunId != peer.AppRunId || alerts[0].LineNum != 1 {
+		t.Errorf("unexpected alert %+v", alerts[0])
+	}
+
+	alerts = peer.CheckLogAlerts([]ds.LogLine{{LineNum: 6, Msg: "panic: still cooling down\n"}})
+	if len(alerts) != 0 {
+		t.Errorf("expected the panics rule to be in its cooldown, got %+v", alerts)
+	}
+}
+
+func TestCheckLogAlertsOnPackets(t *testing.T) {
+	peer := makeTestAppRunPeer(t)
+	peer.AppInfo = &ds.AppInfo{AppName: "api"}
+	setTestDataDir(t, serverbase.RuntimeSettings{AlertRules: []serverbase.AlertRule{
+		{Name: "errors", Search: "ERROR", CooldownSec: 60},
+	}})
+
+	data, _ := json.Marshal(ds.MultiLogLines{LogLines: []ds.LogLine{{LineNum: 1, Msg: "ERROR db down"}}})
+	if err := peer.HandlePacket(ds.PacketTypeMultiLog, data); err != nil {
+		t.Fatalf("HandlePacket failed: %v", err)
+	}
+	if alerts := peer.CheckLogAlerts([]ds.LogLine{{LineNum: 2, Msg: "ERROR again\n"}}); len(alerts) != 0 {
+		t.Errorf("expected the log packet to have started the rule's cooldown, got %+v", alerts)
+	}
+
+	peer.setImported(BundleHeader{})
+	peer.dataLock.Lock()
+	peer.lastAlertTs = nil
+	peer.dataLock.Unlock()
+	if alerts := peer.CheckLogAlerts([]ds.LogLine{{LineNum: 3, Msg: "ERROR imported\n"}}); len(alerts) != 0 {
+		t.Errorf("expected no alerts for an imported app run, got %+v", alerts)
+	}
+}
@@ -13,15 +13,20 @@ import (
 	"time"
 
 	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/comm"
 	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/utilds"
+	"github.com/outrigdev/outrig/server/pkg/rpc"
 	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/stacktrace"
 	"github.com/outrigdev/outrig/server/pkg/tevent"
 )
 
 const (
 	MaxAppRunPeers = 8
 	PruneInterval  = 15 * time.Second
+
+	SDKWriteDeadline = 5 * time.Second
 )
 
 // For tracking app run stats deltas
@@ -49,12 +54,32 @@ type AppRunPeer struct {
 
 	Logs            *LogLinePeer
 	GoRoutines      *GoRoutinePeer
+	GoRoutineChurn  *GoRoutineChurnPeer
 	Watches         *WatchesPeer
 	RuntimeStats    *RuntimeStatsPeer
+	Panics          *PanicsPeer
+	Lifecycle       *LifecyclePeer
 	CollectorStatus map[string]ds.CollectorStatus // Collector statuses by name
-
-	TotalBytesReceived atomic.Int64        // Total bytes received from client
-	lastSentStats      *tevent.AppRunStats // Last stats sent in disconnected event
+	transportStats  *ds.TransportStats            // SDK send queue drop counters (nil until the SDK reports drops)
+	configInfo      *ds.ConfigInfo                // the SDK's resolved config (nil for SDKs that don't send it)
+	appMeta         map[string]string             // app run metadata (from AppInfo, updated by AppMeta packets)
+	cpuProfiles     []*CPUProfile                 // captured CPU profiles, oldest first (see CaptureCPUProfile)
+	execTraces      []*ExecTrace                  // captured execution traces, oldest first (see CaptureExecTrace)
+	goroutineDumps  []*GoroutineDump              // goroutine dumps taken on demand, oldest first (see ForceGoroutineDump)
+	lastExport      *AppRunExport                 // last exported bundle (see ExportBundle)
+	importedFrom    *BundleHeader                 // set for app runs imported from a bundle (see ImportBundle)
+	lastResyncTs    map[string]time.Time          // last full update request by packet type (see requestResync)
+	numResyncs      int                           // full updates requested from the SDK
+	lastAlertTs     map[string]int64              // last log alert by rule name (see CheckLogAlerts)
+
+	clockSkewMs          atomic.Int64        // offset of the app's clock from the monitor's clock (0 if not corrected, see GetClockSkewMs)
+	TotalBytesReceived   atomic.Int64        // Total bytes received from client
+	TotalPacketsReceived atomic.Int64        // Total packets received from client
+	ingestRate           ingestRateTracker   // bytes/packets/logs per second (see SampleIngestRates)
+	lastSentStats        *tevent.AppRunStats // Last stats sent in disconnected event
+
+	packetConn     *comm.ConnWrap // current packet connection to the SDK (nil if not connected)
+	packetConnLock sync.Mutex     // Lock for packetConn (also serializes writes)
 }
 
 // Global synchronized map to hold all AppRunPeers
@@ -78,6 +103,14 @@ func init() {
 			}
 		}
 	}()
+
+	go func() {
+		outrig.SetGoRoutineName("apppeer.ingestrate")
+		for {
+			time.Sleep(IngestRateInterval)
+			SampleIngestRates()
+		}
+	}()
 }
 
 // These functions have been moved to the logwriter package
@@ -87,15 +120,18 @@ func init() {
 func GetAppRunPeer(appRunId string, incRefCount bool) *AppRunPeer {
 	peer, _ := appRunPeers.GetOrCreate(appRunId, func() *AppRunPeer {
 		return &AppRunPeer{
-			AppRunId:      appRunId,
-			Logs:          MakeLogLinePeer(),
-			GoRoutines:    MakeGoRoutinePeer(appRunId),
-			Watches:       MakeWatchesPeer(appRunId),
-			RuntimeStats:  MakeRuntimeStatsPeer(),
-			Status:        AppStatusRunning,
-			LastModTime:   time.Now().UnixMilli(),
-			refCount:      0,
-			lastSentStats: nil,
+			AppRunId:       appRunId,
+			Logs:           MakeLogLinePeer(appRunId),
+			GoRoutines:     MakeGoRoutinePeer(appRunId),
+			GoRoutineChurn: MakeGoRoutineChurnPeer(),
+			Watches:        MakeWatchesPeer(appRunId),
+			RuntimeStats:   MakeRuntimeStatsPeer(),
+			Panics:         MakePanicsPeer(),
+			Lifecycle:      MakeLifecyclePeer(appRunId),
+			Status:         AppStatusRunning,
+			LastModTime:    time.Now().UnixMilli(),
+			refCount:       0,
+			lastSentStats:  nil,
 		}
 	})
 
@@ -109,6 +145,13 @@ func GetAppRunPeer(appRunId string, incRefCount bool) *AppRunPeer {
 	return peer
 }
 
+// FindAppRunPeer returns an existing AppRunPeer by ID, or nil if there is none (unlike GetAppRunPeer it never
+// creates a peer, use it for lookups by ids that come from clients)
+func FindAppRunPeer(appRunId string) *AppRunPeer {
+	peer, _ := appRunPeers.GetEx(appRunId)
+	return peer
+}
+
 // Release decrements the reference counter and closes resources when it reaches zero
 func (p *AppRunPeer) Release() {
 	p.refLock.Lock()
@@ -126,10 +169,54 @@ func (p *AppRunPeer) Release() {
 		log.Printf("Connection closed for app run ID: %s, marked as disconnected", p.AppRunId)
 	}
 
+	// connections that never sent AppInfo (e.g. the exit report from the process wrapper) are not part of the timeline
+	if !p.Lifecycle.IsConnected() {
+		return
+	}
+	p.Lifecycle.RecordEvent(rpctypes.AppLifecycleEvent{
+		Event:  rpctypes.Event_AppDisconnected,
+		Ts:     time.Now().UnixMilli(),
+		Status: p.Status,
+	})
+
 	// Send disconnected event
 	p.sendDisconnectedEvent()
 }
 
+// SetPacketConn sets the packet connection used to send packets to the SDK
+func (p *AppRunPeer) SetPacketConn(conn *comm.ConnWrap) {
+	p.packetConnLock.Lock()
+	defer p.packetConnLock.Unlock()
+	p.packetConn = conn
+}
+
+// ClearPacketConn clears the packet connection (only if it is still the current connection)
+func (p *AppRunPeer) ClearPacketConn(conn *comm.ConnWrap) {
+	p.packetConnLock.Lock()
+	defer p.packetConnLock.Unlock()
+	if p.packetConn == conn {
+		p.packetConn = nil
+	}
+}
+
+// SendPacketToSDK sends a packet to the connected SDK (e.g. a collector admin command)
+func (p *AppRunPeer) SendPacketToSDK(pk *ds.PacketType) error {
+	barr, err := json.Marshal(pk)
+	if err != nil {
+		return fmt.Errorf("failed to marshal packet: %w", err)
+	}
+	if p.IsImported() {
+		return fmt.Errorf("app run is imported (read-only): %s", p.AppRunId)
+	}
+	p.packetConnLock.Lock()
+	defer p.packetConnLock.Unlock()
+	if p.packetConn == nil {
+		return fmt.Errorf("app run is not connected: %s", p.AppRunId)
+	}
+	p.packetConn.Conn.SetWriteDeadline(time.Now().Add(SDKWriteDeadline))
+	return p.packetConn.WriteLine(string(barr))
+}
+
 // GetRefCount safely returns the current reference count
 func (p *AppRunPeer) GetRefCount() int {
 	p.refLock.Lock()
@@ -153,6 +240,62 @@ func (p *AppRunPeer) getFirstGoRoutineCollectionTs() int64 {
 	return p.FirstGoRoutineCollectionTs
 }
 
+// GetCollectorStatuses safely returns the last collector statuses sent by the SDK
+func (p *AppRunPeer) GetCollectorStatuses() map[string]ds.CollectorStatus {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	return p.CollectorStatus
+}
+
+// GetConfigInfo safely returns the resolved config sent by the SDK (nil if none)
+func (p *AppRunPeer) GetConfigInfo() *ds.ConfigInfo {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	return p.configInfo
+}
+
+// GetTransportStats safely returns the last send queue drop counters sent by the SDK (nil if none)
+func (p *AppRunPeer) GetTransportStats() *ds.TransportStats {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	return p.transportStats
+}
+
+func (p *AppRunPeer) setAppMeta(meta map[string]string) {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	p.appMeta = meta
+}
+
+// GetAppMeta safely returns the app run metadata (the map must not be modified)
+func (p *AppRunPeer) GetAppMeta() map[string]string {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	return p.appMeta
+}
+
+// MatchAppMeta returns true if meta contains all of the filter's key/values, an empty filter value only requires the key to be set
+func MatchAppMeta(meta map[string]string, filter map[string]string) bool {
+	for key, value := range filter {
+		metaValue, ok := meta[key]
+		if !ok || (value != "" && metaValue != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterAppRunInfosByMeta returns the app runs whose metadata matches the filter (see MatchAppMeta)
+func FilterAppRunInfosByMeta(appRuns []rpctypes.AppRunInfo, filter map[string]string) []rpctypes.AppRunInfo {
+	rtn := make([]rpctypes.AppRunInfo, 0, len(appRuns))
+	for _, appRun := range appRuns {
+		if MatchAppMeta(appRun.Meta, filter) {
+			rtn = append(rtn, appRun)
+		}
+	}
+	return rtn
+}
+
 // GetAllAppRunPeers returns all AppRunPeers
 func GetAllAppRunPeers() []*AppRunPeer {
 	keys := appRunPeers.Keys()
@@ -166,6 +309,34 @@ func GetAllAppRunPeers() []*AppRunPeer {
 	return peers
 }
 
+// GetRouteId returns the app run's route id ("app:<appname>:<apprunid>", "" before the AppInfo arrives)
+func (p *AppRunPeer) GetRouteId() string {
+	if p.AppInfo == nil {
+		return ""
+	}
+	return rpc.MakeAppRunRouteId(p.AppInfo.AppName, p.AppRunId)
+}
+
+// GetModuleInfo returns the module information used to annotate the app run's stack frames
+func (p *AppRunPeer) GetModuleInfo() stacktrace.ModuleInfo {
+	if p.AppInfo == nil {
+		return stacktrace.ModuleInfo{}
+	}
+	return stacktrace.MakeModuleInfo(p.AppInfo.ModuleName, p.getBuildInfoData())
+}
+
+func (p *AppRunPeer) getBuildInfoData() *rpctypes.BuildInfoData {
+	if p.AppInfo == nil || p.AppInfo.BuildInfo == nil {
+		return nil
+	}
+	return &rpctypes.BuildInfoData{
+		GoVersion: p.AppInfo.BuildInfo.GoVersion,
+		Path:      p.AppInfo.BuildInfo.Path,
+		Version:   p.AppInfo.BuildInfo.Version,
+		Settings:  p.AppInfo.BuildInfo.Settings,
+	}
+}
+
 // GetAllAppRunPeerInfos returns AppRunInfo for all valid app run peers
 // If since > 0, only returns peers that have been modified since the given timestamp
 func GetAllAppRunPeerInfos(since int64) []rpctypes.AppRunInfo {
@@ -190,10 +361,18 @@ func GetAllAppRunPeerInfos(since int64) []rpctypes.AppRunInfo {
 	return appRuns
 }
 
-// HandlePacket processes a packet received from the domain socket connection
+// HandlePacket processes a packet whose timestamps are already in the monitor's clock (lines captured or
+// ingested by the monitor, replays)
 func (p *AppRunPeer) HandlePacket(packetType string, packetData json.RawMessage) error {
+	return p.HandleSDKPacket(packetType, packetData, 0)
+}
+
+// HandleSDKPacket processes a packet received from an SDK connection, its timestamps are corrected by the
+// connection's clock skew (see GetClockSkewMs)
+func (p *AppRunPeer) HandleSDKPacket(packetType string, packetData json.RawMessage, clockSkewMs int64) error {
 	p.LastModTime = time.Now().UnixMilli()
 	p.TotalBytesReceived.Add(int64(len(packetData)))
+	p.TotalPacketsReceived.Add(1)
 
 	switch packetType {
 	case ds.PacketTypeAppInfo:
@@ -201,8 +380,10 @@ func (p *AppRunPeer) HandlePacket(packetType string, packetData json.RawMessage)
 		if err := json.Unmarshal(packetData, &appInfo); err != nil {
 			return fmt.Errorf("failed to unmarshal AppInfo: %w", err)
 		}
+		appInfo.StartTime = correctTs(appInfo.StartTime, clockSkewMs)
 		p.AppInfo = &appInfo
 		p.Status = AppStatusRunning
+		p.setAppMeta(appInfo.Meta)
 		log.Printf("Received AppInfo for app run ID: %s, app: %s", p.AppRunId, appInfo.AppName)
 
 		// Extract Go version if available
@@ -211,50 +392,124 @@ func (p *AppRunPeer) HandlePacket(packetType string, packetData json.RawMessage)
 			goVersion = appInfo.BuildInfo.GoVersion
 		}
 		tevent.SendAppRunConnectedEvent(appInfo.OutrigSDKVersion, goVersion, appInfo.AppName, appInfo.RunMode)
+		p.Lifecycle.SetRouteId(p.GetRouteId())
+		p.Lifecycle.RecordEvent(rpctypes.AppLifecycleEvent{
+			Event:  rpctypes.Event_AppConnected,
+			Ts:     p.LastModTime,
+			Status: p.Status,
+		})
 
 	case ds.PacketTypeLog:
 		var logLine ds.LogLine
 		if err := json.Unmarshal(packetData, &logLine); err != nil {
 			return fmt.Errorf("failed to unmarshal LogLine: %w", err)
 		}
+		logLine.Ts = correctTs(logLine.Ts, clockSkewMs)
 		p.Logs.ProcessLogLine(logLine)
+		p.CheckLogAlerts([]ds.LogLine{logLine})
 
 	case ds.PacketTypeMultiLog:
 		var multiLogLines ds.MultiLogLines
 		if err := json.Unmarshal(packetData, &multiLogLines); err != nil {
 			return fmt.Errorf("failed to unmarshal MultiLogLines: %w", err)
 		}
+		correctLogLines(multiLogLines.LogLines, clockSkewMs)
 		p.Logs.ProcessMultiLogLines(multiLogLines.LogLines)
+		p.CheckLogAlerts(multiLogLines.LogLines)
 
 	case ds.PacketTypeGoroutine:
 		var goroutineInfo ds.GoroutineInfo
 		if err := json.Unmarshal(packetData, &goroutineInfo); err != nil {
 			return fmt.Errorf("failed to unmarshal GoroutineInfo: %w", err)
 		}
+		correctGoroutineInfo(&goroutineInfo, clockSkewMs)
 		p.setFirstGoRoutineCollectionTs(goroutineInfo.Ts)
-		p.GoRoutines.ProcessGoroutineStacks(goroutineInfo)
+		if p.GoRoutines.ProcessGoroutineStacks(goroutineInfo) {
+			p.requestResync(ds.PacketTypeGoroutine, "inconsistent goroutine delta update")
+		}
 		log.Printf("Processed %d goroutines for app run ID: %s (delta: %v)", len(goroutineInfo.Stacks), p.AppRunId, goroutineInfo.Delta)
 
+	case ds.PacketTypeGoroutineChurn:
+		var churnInfo ds.GoroutineChurnInfo
+		if err := json.Unmarshal(packetData, &churnInfo); err != nil {
+			return fmt.Errorf("failed to unmarshal GoroutineChurnInfo: %w", err)
+		}
+		churnInfo.Ts = correctTs(churnInfo.Ts, clockSkewMs)
+		churnInfo.SinceTs = correctTs(churnInfo.SinceTs, clockSkewMs)
+		p.GoRoutineChurn.ProcessChurnInfo(churnInfo)
+
 	case ds.PacketTypeWatch:
 		var watchInfo ds.WatchInfo
 		if err := json.Unmarshal(packetData, &watchInfo); err != nil {
 			return fmt.Errorf("failed to unmarshal WatchInfo: %w", err)
 		}
-		p.Watches.ProcessWatchInfo(watchInfo)
+		correctWatchInfo(&watchInfo, clockSkewMs)
+		if p.Watches.ProcessWatchInfo(watchInfo) {
+			p.requestResync(ds.PacketTypeWatch, "inconsistent watch delta update")
+		}
 		log.Printf("Processed %d watches for app run ID: %s (delta: %v)", len(watchInfo.Watches), p.AppRunId, watchInfo.Delta)
 
 	case ds.PacketTypeAppDone:
 		p.Status = AppStatusDone
 		log.Printf("Received AppDone for app run ID: %s", p.AppRunId)
 
+	case ds.PacketTypeAppExit:
+		var exitInfo ds.AppExitInfo
+		if err := json.Unmarshal(packetData, &exitInfo); err != nil {
+			return fmt.Errorf("failed to unmarshal AppExitInfo: %w", err)
+		}
+		exitInfo.Ts = correctTs(exitInfo.Ts, clockSkewMs)
+		log.Printf("Received AppExit for app run ID: %s (exit code: %d, signal: %q)", p.AppRunId, exitInfo.ExitCode, exitInfo.Signal)
+		if exitInfo.ExitCode != 0 || exitInfo.Signal != "" {
+			p.Lifecycle.RecordEvent(rpctypes.AppLifecycleEvent{
+				Event:    rpctypes.Event_AppCrashed,
+				Ts:       exitInfo.Ts,
+				Status:   p.Status,
+				ExitCode: &exitInfo.ExitCode,
+				Signal:   exitInfo.Signal,
+			})
+		}
+
 	case ds.PacketTypeRuntimeStats:
 		var runtimeStats ds.RuntimeStatsInfo
 		if err := json.Unmarshal(packetData, &runtimeStats); err != nil {
 			return fmt.Errorf("failed to unmarshal RuntimeStatsInfo: %w", err)
 		}
+		correctRuntimeStats(&runtimeStats, clockSkewMs)
 		p.RuntimeStats.ProcessRuntimeStats(runtimeStats)
 		log.Printf("Received runtime stats for app run ID: %s", p.AppRunId)
 
+	case ds.PacketTypePanic:
+		var panicInfo ds.PanicInfo
+		if err := json.Unmarshal(packetData, &panicInfo); err != nil {
+			return fmt.Errorf("failed to unmarshal PanicInfo: %w", err)
+		}
+		panicInfo.Ts = correctTs(panicInfo.Ts, clockSkewMs)
+		p.Panics.ProcessPanicInfo(panicInfo)
+		log.Printf("Received panic for app run ID: %s (goid: %d, recovered: %v)", p.AppRunId, panicInfo.GoId, panicInfo.Recovered)
+
+	case ds.PacketTypeAppMeta:
+		var appMeta ds.AppMetaData
+		if err := json.Unmarshal(packetData, &appMeta); err != nil {
+			return fmt.Errorf("failed to unmarshal AppMetaData: %w", err)
+		}
+		p.setAppMeta(appMeta.Meta)
+
+	case ds.PacketTypeRuntimeControlResult:
+		return p.handleRuntimeControlResult(packetData)
+
+	case ds.PacketTypeCPUProfileResult:
+		return p.handleCPUProfileResult(packetData)
+
+	case ds.PacketTypeExecTraceResult:
+		return p.handleExecTraceResult(packetData)
+
+	case ds.PacketTypeGoroutineDumpResult:
+		return p.handleGoroutineDumpResult(packetData, clockSkewMs)
+
+	case ds.PacketTypeSetWatchValueResult:
+		return p.handleSetWatchValueResult(packetData)
+
 	case ds.PacketTypeCollectorStatus:
 		var collectorStatuses map[string]ds.CollectorStatus
 		if err := json.Unmarshal(packetData, &collectorStatuses); err != nil {
@@ -265,6 +520,27 @@ func (p *AppRunPeer) HandlePacket(packetType string, packetData json.RawMessage)
 		p.dataLock.Unlock()
 		log.Printf("Received collector statuses for app run ID: %s (%d collectors)", p.AppRunId, len(collectorStatuses))
 
+	case ds.PacketTypeConfigInfo:
+		var configInfo ds.ConfigInfo
+		if err := json.Unmarshal(packetData, &configInfo); err != nil {
+			return fmt.Errorf("failed to unmarshal ConfigInfo: %w", err)
+		}
+		p.dataLock.Lock()
+		p.configInfo = &configInfo
+		p.dataLock.Unlock()
+
+	case ds.PacketTypeTransportStats:
+		var transportStats ds.TransportStats
+		if err := json.Unmarshal(packetData, &transportStats); err != nil {
+			return fmt.Errorf("failed to unmarshal TransportStats: %w", err)
+		}
+		p.dataLock.Lock()
+		p.transportStats = &transportStats
+		p.dataLock.Unlock()
+		if transportStats.TotalDropped > 0 {
+			log.Printf("SDK dropped %d packets for app run ID: %s (%v)", transportStats.TotalDropped, p.AppRunId, transportStats.Dropped)
+		}
+
 	default:
 		log.Printf("Unknown packet type: %s", packetType)
 	}
@@ -273,35 +549,10 @@ func (p *AppRunPeer) HandlePacket(packetType string, packetData json.RawMessage)
 }
 
 // PruneAppRunPeers removes old app run peers to keep the total count under MaxAppRunPeers
+// and to enforce the server's retention settings (see PruneAppRuns)
 // It will not prune peers that are running or have a non-zero reference count
 func PruneAppRunPeers() int {
-	allPeers := GetAllAppRunPeers()
-	if len(allPeers) <= MaxAppRunPeers {
-		return 0
-	}
-
-	sort.Slice(allPeers, func(i, j int) bool {
-		return allPeers[i].LastModTime < allPeers[j].LastModTime
-	})
-
-	numPruned := 0
-	for _, peer := range allPeers {
-		if len(allPeers)-numPruned <= MaxAppRunPeers {
-			break
-		}
-		if peer.Status == AppStatusRunning {
-			continue
-		}
-		if peer.GetRefCount() > 0 {
-			continue
-		}
-		appRunPeers.Delete(peer.AppRunId)
-		log.Printf("Pruned app run peer: %s (last modified: %s)",
-			peer.AppRunId, time.UnixMilli(peer.LastModTime).Format(time.RFC3339))
-		numPruned++
-	}
-
-	return numPruned
+	return len(PruneAppRuns(GetRetentionPolicy(), false))
 }
 
 // ClearNonActiveAppRuns removes all AppPeers that are not currently running
@@ -313,6 +564,7 @@ func ClearNonActiveAppRuns() error {
 		// Only remove peers that are not running
 		if peer.Status != AppStatusRunning {
 			appRunPeers.Delete(peer.AppRunId)
+			peer.Logs.Close()
 			log.Printf("Cleared non-active app run peer: %s (status: %s)", peer.AppRunId, peer.Status)
 			numCleared++
 		}
@@ -322,6 +574,24 @@ func ClearNonActiveAppRuns() error {
 	return nil
 }
 
+// ClearAppRun removes a single app run peer (running app runs and app runs that are in use cannot be cleared)
+func ClearAppRun(appRunId string) error {
+	peer, exists := appRunPeers.GetEx(appRunId)
+	if !exists || peer.AppInfo == nil {
+		return fmt.Errorf("app run %q not found", appRunId)
+	}
+	if peer.Status == AppStatusRunning {
+		return fmt.Errorf("app run %q is still running", appRunId)
+	}
+	if peer.GetRefCount() > 0 {
+		return fmt.Errorf("app run %q is in use", appRunId)
+	}
+	appRunPeers.Delete(appRunId)
+	peer.Logs.Close()
+	log.Printf("Cleared app run peer: %s (status: %s)", appRunId, peer.Status)
+	return nil
+}
+
 // GetAppRunInfo constructs and returns an AppRunInfo struct for this peer
 func (p *AppRunPeer) GetAppRunInfo() rpctypes.AppRunInfo {
 	if p.AppInfo == nil {
@@ -333,6 +603,7 @@ func (p *AppRunPeer) GetAppRunInfo() rpctypes.AppRunInfo {
 	numActiveWatches := p.Watches.GetActiveWatchCount()
 	numTotalWatches := p.Watches.GetTotalWatchCount()
 	numLogs := p.Logs.GetTotalCount()
+	numPanics := p.Panics.GetTotalCount()
 	appRunInfo := rpctypes.AppRunInfo{
 		AppRunId:                   p.AppRunId,
 		AppName:                    p.AppInfo.AppName,
@@ -346,20 +617,19 @@ func (p *AppRunPeer) GetAppRunInfo() rpctypes.AppRunInfo {
 		NumOutrigGoRoutines:        numOutrigGoRoutines,
 		NumActiveWatches:           numActiveWatches,
 		NumTotalWatches:            numTotalWatches,
+		NumPanics:                  numPanics,
 		LastModTime:                p.LastModTime,
 		ModuleName:                 p.AppInfo.ModuleName,
 		Executable:                 p.AppInfo.Executable,
 		OutrigSDKVersion:           p.AppInfo.OutrigSDKVersion,
+		Meta:                       p.GetAppMeta(),
+		Imported:                   p.IsImported(),
+		TransportStats:             p.GetTransportStats(),
+		ClockSkewMs:                p.GetClockSkew(),
+		NumResyncs:                 p.GetNumResyncs(),
 	}
 
-	if p.AppInfo.BuildInfo != nil {
-		appRunInfo.BuildInfo = &rpctypes.BuildInfoData{
-			GoVersion: p.AppInfo.BuildInfo.GoVersion,
-			Path:      p.AppInfo.BuildInfo.Path,
-			Version:   p.AppInfo.BuildInfo.Version,
-			Settings:  p.AppInfo.BuildInfo.Settings,
-		}
-	}
+	appRunInfo.BuildInfo = p.getBuildInfoData()
 
 	return appRunInfo
 }
@@ -0,0 +1,413 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/packetrecord"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// An app run bundle is a gzipped packet recording (one packetrecord.RecordedPacket per line) preceded by a
+// BundleHeader line. The packets are rebuilt from what the monitor has stored for the app run (logs, goroutine
+// history, watches, runtime stats, panics), so a bundle can be imported into any monitor (see ImportBundle).
+const (
+	BundleHeaderType   = "outrig-bundle"
+	BundleVersion      = 1
+	BundleFileSuffix   = ".outrig.jsonl.gz"
+	BundleDownloadPath = "/api/export"
+
+	bundleLogLinesPerPacket = 1000
+	maxBundleLineSize       = 64 * 1024 * 1024
+)
+
+// BundleHeader is the first line of an app run bundle
+type BundleHeader struct {
+	Type          string `json:"type"` // always BundleHeaderType
+	Version       int    `json:"version"`
+	AppRunId      string `json:"apprunid"`
+	AppName       string `json:"appname"`
+	ExportTs      int64  `json:"exportts"`
+	ServerVersion string `json:"serverversion"`
+}
+
+// BundleStats counts what was written to a bundle
+type BundleStats struct {
+	NumLogs             int
+	NumGoRoutineSamples int
+	NumWatchSamples     int
+	NumRuntimeStats     int
+	NumPanics           int
+}
+
+// AppRunExport is an exported bundle kept by the server for download (only the last export of an app run is kept)
+type AppRunExport struct {
+	ExportId string
+	Ts       int64
+	FileName string
+	Data     []byte
+	Stats    BundleStats
+}
+
+// bundlePacketTypes are the packet types that are accepted when importing a bundle
+var bundlePacketTypes = map[string]bool{
+	ds.PacketTypeAppInfo:         true,
+	ds.PacketTypeAppMeta:         true,
+	ds.PacketTypeLog:             true,
+	ds.PacketTypeMultiLog:        true,
+	ds.PacketTypeGoroutine:       true,
+	ds.PacketTypeWatch:           true,
+	ds.PacketTypeRuntimeStats:    true,
+	ds.PacketTypePanic:           true,
+	ds.PacketTypeCollectorStatus: true,
+	ds.PacketTypeConfigInfo:      true,
+}
+
+type bundleWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (bw *bundleWriter) writeLine(v any) {
+	if bw.err != nil {
+		return
+	}
+	barr, err := json.Marshal(v)
+	if err != nil {
+		bw.err = err
+		return
+	}
+	barr = append(barr, '\n')
+	_, bw.err = bw.w.Write(barr)
+}
+
+func (bw *bundleWriter) writePacket(ts int64, packetType string, data any) {
+	if bw.err != nil {
+		return
+	}
+	barr, err := json.Marshal(data)
+	if err != nil {
+		bw.err = fmt.Errorf("error marshaling %s packet: %w", packetType, err)
+		return
+	}
+	bw.writeLine(packetrecord.RecordedPacket{Ts: ts, Type: packetType, Data: barr})
+}
+
+// WriteBundle writes the app run as a bundle (see BundleHeader) to w
+func (p *AppRunPeer) WriteBundle(w io.Writer) (BundleStats, error) {
+	var stats BundleStats
+	if p.AppInfo == nil {
+		return stats, fmt.Errorf("app run %s has no app info", p.AppRunId)
+	}
+	gzWriter := gzip.NewWriter(w)
+	bufWriter := bufio.NewWriter(gzWriter)
+	bw := &bundleWriter{w: bufWriter}
+
+	bw.writeLine(BundleHeader{
+		Type:          BundleHeaderType,
+		Version:       BundleVersion,
+		AppRunId:      p.AppRunId,
+		AppName:       p.AppInfo.AppName,
+		ExportTs:      time.Now().UnixMilli(),
+		ServerVersion: serverbase.OutrigServerVersion,
+	})
+	bw.writePacket(p.AppInfo.StartTime, ds.PacketTypeAppInfo, p.AppInfo)
+	if meta := p.GetAppMeta(); meta != nil {
+		bw.writePacket(p.AppInfo.StartTime, ds.PacketTypeAppMeta, ds.AppMetaData{Meta: meta})
+	}
+
+	logSeq, _ := p.Logs.GetLogLineSeq()
+	var logLines []ds.LogLine
+	flushLogs := func() {
+		if len(logLines) == 0 {
+			return
+		}
+		bw.writePacket(logLines[0].Ts, ds.PacketTypeMultiLog, ds.MultiLogLines{LogLines: logLines})
+		logLines = nil
+	}
+	for line := range logSeq {
+		logLines = append(logLines, line)
+		stats.NumLogs++
+		if len(logLines) >= bundleLogLinesPerPacket {
+			flushLogs()
+		}
+	}
+	flushLogs()
+
+	for _, info := range p.GoRoutines.exportGoroutineInfos() {
+		stats.NumGoRoutineSamples += len(info.Stacks)
+		bw.writePacket(info.Ts, ds.PacketTypeGoroutine, info)
+	}
+	for _, info := range p.Watches.exportWatchInfos() {
+		stats.NumWatchSamples += len(info.Watches)
+		bw.writePacket(info.Ts, ds.PacketTypeWatch, info)
+	}
+	for _, runtimeStats := range p.RuntimeStats.GetFilteredStats(0) {
+		stats.NumRuntimeStats++
+		bw.writePacket(runtimeStats.Ts, ds.PacketTypeRuntimeStats, runtimeStats)
+	}
+	for _, panicInfo := range p.Panics.GetFilteredPanics(0) {
+		stats.NumPanics++
+		bw.writePacket(panicInfo.Ts, ds.PacketTypePanic, panicInfo)
+	}
+	if collectorStatuses := p.GetCollectorStatuses(); collectorStatuses != nil {
+		bw.writePacket(p.LastModTime, ds.PacketTypeCollectorStatus, collectorStatuses)
+	}
+	if configInfo := p.GetConfigInfo(); configInfo != nil {
+		bw.writePacket(p.LastModTime, ds.PacketTypeConfigInfo, configInfo)
+	}
+
+	if bw.err != nil {
+		return stats, bw.err
+	}
+	if err := bufWriter.Flush(); err != nil {
+		return stats, err
+	}
+	return stats, gzWriter.Close()
+}
+
+// ExportBundle creates a bundle for the app run and keeps it on the peer for download (see GetExportDownloadUrl)
+func (p *AppRunPeer) ExportBundle() (*AppRunExport, error) {
+	if p.AppInfo == nil {
+		return nil, fmt.Errorf("cannot export app run %s, it has no app info", p.AppRunId)
+	}
+	var buf bytes.Buffer
+	stats, err := p.WriteBundle(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("error exporting app run %s: %w", p.AppRunId, err)
+	}
+	now := time.Now()
+	export := &AppRunExport{
+		ExportId: uuid.New().String(),
+		Ts:       now.UnixMilli(),
+		FileName: fmt.Sprintf("%s-%s%s", p.AppInfo.AppName, now.Format("20060102-150405"), BundleFileSuffix),
+		Data:     buf.Bytes(),
+		Stats:    stats,
+	}
+	p.dataLock.Lock()
+	p.lastExport = export
+	p.dataLock.Unlock()
+	log.Printf("Exported app run %s (%d bytes, %d logs)", p.AppRunId, len(export.Data), stats.NumLogs)
+	return export, nil
+}
+
+// GetExport returns the last export of the app run if it matches exportId (nil otherwise)
+func (p *AppRunPeer) GetExport(exportId string) *AppRunExport {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	if p.lastExport == nil || p.lastExport.ExportId != exportId {
+		return nil
+	}
+	return p.lastExport
+}
+
+// GetExportDownloadUrl returns the (monitor relative) url of an exported bundle
+func GetExportDownloadUrl(appRunId string, exportId string) string {
+	query := url.Values{}
+	query.Set("apprunid", appRunId)
+	query.Set("exportid", exportId)
+	return BundleDownloadPath + "?" + query.Encode()
+}
+
+// exportGoroutineInfos rebuilds full (non-delta) goroutine collections from the stored stack history.
+// Each goroutine declaration is sent with the first collection that has a stack for the goroutine.
+func (gp *GoRoutinePeer) exportGoroutineInfos() []ds.GoroutineInfo {
+	gp.lock.RLock()
+	defer gp.lock.RUnlock()
+
+	infoMap := make(map[int64]*ds.GoroutineInfo)
+	var unplacedDecls []ds.GoDecl
+	for _, goId := range gp.goRoutines.Keys() {
+		goroutine, ok := gp.goRoutines.GetEx(goId)
+		if !ok {
+			continue
+		}
+		stacks, _ := goroutine.StackTraces.GetAll()
+		var firstTs int64
+		for _, stack := range stacks {
+			if stack.Ts == 0 {
+				continue // gap in the history (goroutine was not in that collection)
+			}
+			info := infoMap[stack.Ts]
+			if info == nil {
+				info = &ds.GoroutineInfo{Ts: stack.Ts}
+				infoMap[stack.Ts] = info
+			}
+			stack.Same = false
+			info.Stacks = append(info.Stacks, stack)
+			if firstTs == 0 {
+				firstTs = stack.Ts
+			}
+		}
+		if goroutine.Decl == nil {
+			continue
+		}
+		if firstTs == 0 {
+			unplacedDecls = append(unplacedDecls, *goroutine.Decl)
+			continue
+		}
+		infoMap[firstTs].Decls = append(infoMap[firstTs].Decls, *goroutine.Decl)
+	}
+
+	infos := make([]ds.GoroutineInfo, 0, len(infoMap))
+	for _, info := range infoMap {
+		info.Count = len(info.Stacks)
+		infos = append(infos, *info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Ts < infos[j].Ts })
+	if len(infos) > 0 {
+		infos[0].Decls = append(infos[0].Decls, unplacedDecls...)
+	}
+	return infos
+}
+
+// exportWatchInfos rebuilds full (non-delta) watch collections from the stored watch samples.
+// Each watch declaration is sent with the first collection that has a sample for the watch.
+func (wp *WatchesPeer) exportWatchInfos() []ds.WatchInfo {
+	wp.lock.RLock()
+	defer wp.lock.RUnlock()
+
+	infoMap := make(map[int64]*ds.WatchInfo)
+	var unplacedDecls []ds.WatchDecl
+	for _, watchNum := range wp.watches.Keys() {
+		watch, ok := wp.watches.GetEx(watchNum)
+		if !ok {
+			continue
+		}
+		samples, _ := watch.WatchVals.GetAll()
+		if len(samples) == 0 {
+			unplacedDecls = append(unplacedDecls, watch.Decl)
+			continue
+		}
+		for idx, sample := range samples {
+			info := infoMap[sample.Ts]
+			if info == nil {
+				info = &ds.WatchInfo{Ts: sample.Ts}
+				infoMap[sample.Ts] = info
+			}
+			if idx == 0 {
+				info.Decls = append(info.Decls, watch.Decl)
+			}
+			sample.Same = false
+			info.Watches = append(info.Watches, sample)
+		}
+	}
+
+	infos := make([]ds.WatchInfo, 0, len(infoMap))
+	for _, info := range infoMap {
+		infos = append(infos, *info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Ts < infos[j].Ts })
+	if len(infos) > 0 {
+		infos[0].Decls = append(infos[0].Decls, unplacedDecls...)
+	}
+	return infos
+}
+
+// ImportBundle reads a bundle (see WriteBundle) into a new, read-only app run (the app run gets a new id
+// so a bundle can be imported more than once). Imported app runs are shown as done and never talk to an SDK.
+func ImportBundle(r io.Reader) (*AppRunPeer, error) {
+	gzReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle (not gzip compressed): %w", err)
+	}
+	defer gzReader.Close()
+	scanner := bufio.NewScanner(gzReader)
+	scanner.Buffer(make([]byte, 64*1024), maxBundleLineSize)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error reading bundle: %w", err)
+		}
+		return nil, fmt.Errorf("bundle is empty")
+	}
+	var header BundleHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Type != BundleHeaderType {
+		return nil, fmt.Errorf("invalid bundle header")
+	}
+	if header.Version > BundleVersion {
+		return nil, fmt.Errorf("bundle version %d is not supported (max %d), upgrade outrig", header.Version, BundleVersion)
+	}
+
+	// the only place a peer is created for an id that is not from an SDK connection (the id is new, and the
+	// peer is removed again if the import fails), lookups by client ids use FindAppRunPeer
+	appRunId := uuid.New().String()
+	peer := GetAppRunPeer(appRunId, false)
+	peer.setImported(header)
+	discard := func() {
+		appRunPeers.Delete(appRunId)
+		peer.Logs.Close()
+	}
+
+	lineNum := 1
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var pkt packetrecord.RecordedPacket
+		if err := json.Unmarshal(line, &pkt); err != nil {
+			discard()
+			return nil, fmt.Errorf("bundle line %d: invalid packet: %w", lineNum, err)
+		}
+		if !bundlePacketTypes[pkt.Type] {
+			continue
+		}
+		data := pkt.Data
+		if pkt.Type == ds.PacketTypeAppInfo {
+			data, err = packetrecord.RewriteAppRunId(data, appRunId)
+			if err != nil {
+				discard()
+				return nil, fmt.Errorf("bundle line %d: %w", lineNum, err)
+			}
+		}
+		if err := peer.HandlePacket(pkt.Type, data); err != nil {
+			discard()
+			return nil, fmt.Errorf("bundle line %d: %w", lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		discard()
+		return nil, fmt.Errorf("error reading bundle: %w", err)
+	}
+	if peer.AppInfo == nil {
+		discard()
+		return nil, fmt.Errorf("bundle has no app info")
+	}
+	peer.Status = AppStatusDone
+	peer.LastModTime = time.Now().UnixMilli()
+	log.Printf("Imported bundle of app run %s (app: %s) as app run ID: %s", header.AppRunId, header.AppName, appRunId)
+	return peer, nil
+}
+
+func (p *AppRunPeer) setImported(header BundleHeader) {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	p.importedFrom = &header
+}
+
+// GetImportedFrom returns the header of the bundle the app run was imported from (nil if it wasn't imported)
+func (p *AppRunPeer) GetImportedFrom() *BundleHeader {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	return p.importedFrom
+}
+
+// IsImported returns true if the app run was imported from a bundle (imported app runs are read-only)
+func (p *AppRunPeer) IsImported() bool {
+	return p.GetImportedFrom() != nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/packetrecord"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+func sendTestPacket(t *testing.T, peer *AppRunPeer, packetType string, data any) {
+	t.Helper()
+	barr, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := peer.HandlePacket(packetType, barr); err != nil {
+		t.Fatalf("error handling %s packet: %v", packetType, err)
+	}
+}
+
+// readTestBundle returns the header and the packets of a bundle
+func readTestBundle(t *testing.T, bundle []byte) (BundleHeader, []packetrecord.RecordedPacket) {
+	t.Helper()
+	gzReader, err := gzip.NewReader(bytes.NewReader(bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := bufio.NewScanner(gzReader)
+	var header BundleHeader
+	var packets []packetrecord.RecordedPacket
+	for scanner.Scan() {
+		if header.Type == "" {
+			if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		var pkt packetrecord.RecordedPacket
+		if err := json.Unmarshal(scanner.Bytes(), &pkt); err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, pkt)
+	}
+	return header, packets
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	peer := makeTestAppRunPeer(t)
+	sendTestPacket(t, peer, ds.PacketTypeAppInfo, ds.AppInfo{AppRunId: peer.AppRunId, AppName: "bundletest", StartTime: 1000, Pid: 42})
+	sendTestPacket(t, peer, ds.PacketTypeMultiLog, ds.MultiLogLines{LogLines: []ds.LogLine{
+		{LineNum: 1, Ts: 1100, Msg: "starting\n", Source: "/dev/stdout"},
+		{LineNum: 2, Ts: 1200, Msg: "failed\n", Source: "/dev/stderr"},
+	}})
+	sendTestPacket(t, peer, ds.PacketTypeGoroutine, ds.GoroutineInfo{Ts: 2000, Count: 2, Stacks: []ds.GoRoutineStack{
+		{GoId: 1, Ts: 2000, State: "running", StackTrace: "main.main()\n\t/app/main.go:10 +0x20"},
+		{GoId: 7, Ts: 2000, State: "chan receive", StackTrace: "main.work()\n\t/app/main.go:20 +0x10"},
+	}, Decls: []ds.GoDecl{{GoId: 7, Name: "worker"}}})
+	sendTestPacket(t, peer, ds.PacketTypeWatch, ds.WatchInfo{Ts: 2000,
+		Decls:   []ds.WatchDecl{{Name: "counter", WatchType: "sync", Format: "json"}},
+		Watches: []ds.WatchSample{{Name: "counter", Ts: 2000, Val: "5"}},
+	})
+	sendTestPacket(t, peer, ds.PacketTypeWatch, ds.WatchInfo{Ts: 3000, Delta: true,
+		Watches: []ds.WatchSample{{Name: "counter", Ts: 3000, Val: "6"}},
+	})
+	sendTestPacket(t, peer, ds.PacketTypeRuntimeStats, ds.RuntimeStatsInfo{Ts: 2000, GoRoutineCount: 2, Pid: 42})
+	sendTestPacket(t, peer, ds.PacketTypePanic, ds.PanicInfo{GoId: 7, Ts: 2500, PanicVal: "boom", StackTrace: "panic()\nmain.work()"})
+
+	var bundle bytes.Buffer
+	stats, err := peer.WriteBundle(&bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStats := BundleStats{NumLogs: 2, NumGoRoutineSamples: 2, NumWatchSamples: 2, NumRuntimeStats: 1, NumPanics: 1}
+	if stats != expectStats {
+		t.Errorf("got stats %+v, want %+v", stats, expectStats)
+	}
+
+	imported, err := ImportBundle(bytes.NewReader(bundle.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		appRunPeers.Delete(imported.AppRunId)
+		imported.Logs.Close()
+	})
+	if imported.AppRunId == peer.AppRunId || imported.AppInfo.AppRunId != imported.AppRunId || imported.AppInfo.AppName != "bundletest" {
+		t.Errorf("got app run %s with app info %+v, want a new app run id", imported.AppRunId, imported.AppInfo)
+	}
+	if !imported.IsImported() || imported.GetImportedFrom().AppRunId != peer.AppRunId || imported.Status != AppStatusDone {
+		t.Errorf("got imported from %+v, status %s", imported.GetImportedFrom(), imported.Status)
+	}
+
+	// exporting the imported app run gives the same packets (except for the app run id)
+	var reexported bytes.Buffer
+	reexportStats, err := imported.WriteBundle(&reexported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reexportStats != expectStats {
+		t.Errorf("got stats %+v after the import, want %+v", reexportStats, expectStats)
+	}
+	header, packets := readTestBundle(t, bundle.Bytes())
+	_, reexportedPackets := readTestBundle(t, reexported.Bytes())
+	if header.Type != BundleHeaderType || header.AppName != "bundletest" || header.AppRunId != peer.AppRunId {
+		t.Errorf("got header %+v", header)
+	}
+	if len(packets) != len(reexportedPackets) {
+		t.Fatalf("got %d packets after the import, want %d", len(reexportedPackets), len(packets))
+	}
+	for idx, pkt := range packets {
+		if pkt.Type == ds.PacketTypeAppInfo {
+			continue
+		}
+		if !reflect.DeepEqual(pkt, reexportedPackets[idx]) {
+			t.Errorf("packet %d: got %s %s after the import, want %s %s", idx, reexportedPackets[idx].Type, reexportedPackets[idx].Data, pkt.Type, pkt.Data)
+		}
+	}
+}
+
+func TestImportBundleErrors(t *testing.T) {
+	makeBundle := func(lines ...string) []byte {
+		var buf bytes.Buffer
+		gzWriter := gzip.NewWriter(&buf)
+		for _, line := range lines {
+			gzWriter.Write([]byte(line + "\n"))
+		}
+		gzWriter.Close()
+		return buf.Bytes()
+	}
+	header := `{"type":"outrig-bundle","version":1,"apprunid":"old","appname":"test"}`
+	tests := []struct {
+		name   string
+		bundle []byte
+	}{
+		{"not gzipped", []byte("{}")},
+		{"empty", makeBundle()},
+		{"invalid header", makeBundle(`{"type":"something"}`)},
+		{"newer version", makeBundle(`{"type":"outrig-bundle","version":99}`)},
+		{"invalid packet", makeBundle(header, `{bad`)},
+		{"no app info", makeBundle(header, `{"ts":1,"type":"log","data":{"linenum":1,"ts":1,"msg":"hi"}}`)},
+	}
+	before := len(GetAllAppRunPeers())
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setTestDataDir(t, serverbase.RuntimeSettings{})
+			if peer, err := ImportBundle(bytes.NewReader(tc.bundle)); err == nil {
+				t.Errorf("got app run %s, want an error", peer.AppRunId)
+			}
+		})
+	}
+	if after := len(GetAllAppRunPeers()); after != before {
+		t.Errorf("got %d app runs after the failed imports, want %d", after, before)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/comm"
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+// MinClockSkew is the smallest clock offset that is corrected, smaller offsets (and offsets smaller than the
+// handshake's uncertainty) are measurement noise on a local connection
+const MinClockSkew = 20 * time.Millisecond
+
+// GetClockSkewMs returns the clock offset (ms) to correct the timestamps of a connection with, 0 if the
+// offset is unknown or too small to matter
+func GetClockSkewMs(clockOffset *comm.ClockOffset) int64 {
+	if clockOffset == nil {
+		return 0
+	}
+	offsetUs := clockOffset.OffsetUs
+	if offsetUs < 0 {
+		offsetUs = -offsetUs
+	}
+	if offsetUs < MinClockSkew.Microseconds() || offsetUs <= clockOffset.DelayUs/2 {
+		return 0
+	}
+	return clockOffset.OffsetUs / 1000
+}
+
+// SetClockSkew records the clock offset of the app's packet connection (shown in the app run info)
+func (p *AppRunPeer) SetClockSkew(clockSkewMs int64) {
+	if clockSkewMs != 0 {
+		log.Printf("App run %s: the app's clock is off by %dms, correcting its timestamps", p.AppRunId, clockSkewMs)
+	}
+	p.clockSkewMs.Store(clockSkewMs)
+}
+
+// GetClockSkew returns the offset (ms) of the app's clock from the monitor's clock the timestamps are corrected with
+func (p *AppRunPeer) GetClockSkew() int64 {
+	return p.clockSkewMs.Load()
+}
+
+// CorrectPacketData returns an SDK packet with its timestamps converted to the monitor's clock (the way
+// HandleSDKPacket stores them), so recorded packets can be replayed and imported without the connection's
+// clock skew. Packets without timestamps (or that fail to decode) are returned unchanged.
+func CorrectPacketData(packetType string, packetData json.RawMessage, clockSkewMs int64) json.RawMessage {
+	if clockSkewMs == 0 {
+		return packetData
+	}
+	var rtn json.RawMessage
+	var err error
+	switch packetType {
+	case ds.PacketTypeAppInfo:
+		rtn, err = correctPacketJson(packetData, func(appInfo *ds.AppInfo) {
+			appInfo.StartTime = correctTs(appInfo.StartTime, clockSkewMs)
+		})
+	case ds.PacketTypeLog:
+		rtn, err = correctPacketJson(packetData, func(logLine *ds.LogLine) {
+			logLine.Ts = correctTs(logLine.Ts, clockSkewMs)
+		})
+	case ds.PacketTypeMultiLog:
+		rtn, err = correctPacketJson(packetData, func(multiLogLines *ds.MultiLogLines) {
+			correctLogLines(multiLogLines.LogLines, clockSkewMs)
+		})
+	case ds.PacketTypeGoroutine:
+		rtn, err = correctPacketJson(packetData, func(goroutineInfo *ds.GoroutineInfo) {
+			correctGoroutineInfo(goroutineInfo, clockSkewMs)
+		})
+	case ds.PacketTypeGoroutineChurn:
+		rtn, err = correctPacketJson(packetData, func(churnInfo *ds.GoroutineChurnInfo) {
+			churnInfo.Ts = correctTs(churnInfo.Ts, clockSkewMs)
+			churnInfo.SinceTs = correctTs(churnInfo.SinceTs, clockSkewMs)
+		})
+	case ds.PacketTypeWatch:
+		rtn, err = correctPacketJson(packetData, func(watchInfo *ds.WatchInfo) {
+			correctWatchInfo(watchInfo, clockSkewMs)
+		})
+	case ds.PacketTypeAppExit:
+		rtn, err = correctPacketJson(packetData, func(exitInfo *ds.AppExitInfo) {
+			exitInfo.Ts = correctTs(exitInfo.Ts, clockSkewMs)
+		})
+	case ds.PacketTypeRuntimeStats:
+		rtn, err = correctPacketJson(packetData, func(runtimeStats *ds.RuntimeStatsInfo) {
+			correctRuntimeStats(runtimeStats, clockSkewMs)
+		})
+	case ds.PacketTypePanic:
+		rtn, err = correctPacketJson(packetData, func(panicInfo *ds.PanicInfo) {
+			panicInfo.Ts = correctTs(panicInfo.Ts, clockSkewMs)
+		})
+	case ds.PacketTypeGoroutineDumpResult:
+		rtn, err = correctPacketJson(packetData, func(result *ds.GoroutineDumpResult) {
+			if result.Info != nil {
+				correctGoroutineInfo(result.Info, clockSkewMs)
+			}
+		})
+	default:
+		return packetData
+	}
+	if err != nil {
+		return packetData
+	}
+	return rtn
+}
+
+func correctPacketJson[T any](packetData json.RawMessage, correctFn func(*T)) (json.RawMessage, error) {
+	var v T
+	if err := json.Unmarshal(packetData, &v); err != nil {
+		return nil, err
+	}
+	correctFn(&v)
+	return json.Marshal(&v)
+}
+
+// correctTs converts an SDK timestamp to the monitor's clock (0 means unset and is kept)
+func correctTs(ts int64, clockSkewMs int64) int64 {
+	if ts == 0 {
+		return 0
+	}
+	return ts - clockSkewMs
+}
+
+func correctLogLines(lines []ds.LogLine, clockSkewMs int64) {
+	if clockSkewMs == 0 {
+		return
+	}
+	for i := range lines {
+		lines[i].Ts = correctTs(lines[i].Ts, clockSkewMs)
+	}
+}
+
+func correctGoroutineInfo(info *ds.GoroutineInfo, clockSkewMs int64) {
+	if clockSkewMs == 0 {
+		return
+	}
+	info.Ts = correctTs(info.Ts, clockSkewMs)
+	for i := range info.Stacks {
+		info.Stacks[i].Ts = correctTs(info.Stacks[i].Ts, clockSkewMs)
+	}
+	for i := range info.Decls {
+		decl := &info.Decls[i]
+		decl.StartTs = correctTs(decl.StartTs, clockSkewMs)
+		decl.EndTs = correctTs(decl.EndTs, clockSkewMs)
+		decl.FirstPollTs = correctTs(decl.FirstPollTs, clockSkewMs)
+		decl.LastPollTs = correctTs(decl.LastPollTs, clockSkewMs)
+	}
+}
+
+func correctWatchInfo(info *ds.WatchInfo, clockSkewMs int64) {
+	if clockSkewMs == 0 {
+		return
+	}
+	info.Ts = correctTs(info.Ts, clockSkewMs)
+	for i := range info.Watches {
+		info.Watches[i].Ts = correctTs(info.Watches[i].Ts, clockSkewMs)
+	}
+}
+
+func correctRuntimeStats(stats *ds.RuntimeStatsInfo, clockSkewMs int64) {
+	if clockSkewMs == 0 {
+		return
+	}
+	stats.Ts = correctTs(stats.Ts, clockSkewMs)
+	if stats.FDStats != nil {
+		stats.FDStats.Ts = correctTs(stats.FDStats.Ts, clockSkewMs)
+	}
+	if stats.SchedStats != nil {
+		stats.SchedStats.Ts = correctTs(stats.SchedStats.Ts, clockSkewMs)
+		stats.SchedStats.GoMaxProcsChangedTs = correctTs(stats.SchedStats.GoMaxProcsChangedTs, clockSkewMs)
+	}
+}
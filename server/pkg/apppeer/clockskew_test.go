@@ -0,0 +1,69 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/comm"
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func TestGetClockSkewMs(t *testing.T) {
+	tests := []struct {
+		name        string
+		clockOffset *comm.ClockOffset
+		expect      int64
+	}{
+		{"unknown offset", nil, 0},
+		{"below the minimum", &comm.ClockOffset{OffsetUs: 15000, DelayUs: 100}, 0},
+		{"within the handshake's uncertainty", &comm.ClockOffset{OffsetUs: 40000, DelayUs: 100000}, 0},
+		{"app clock ahead", &comm.ClockOffset{OffsetUs: 250400, DelayUs: 300}, 250},
+		{"app clock behind", &comm.ClockOffset{OffsetUs: -3000000, DelayUs: 300}, -3000},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := GetClockSkewMs(tc.clockOffset); got != tc.expect {
+				t.Errorf("got %d, want %d", got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestCorrectPacketData(t *testing.T) {
+	logData := json.RawMessage(`{"linenum":1,"ts":5000,"msg":"hello\n","source":"/dev/stdout"}`)
+	if got := CorrectPacketData(ds.PacketTypeLog, logData, 0); string(got) != string(logData) {
+		t.Errorf("packet changed without a clock skew: %s", got)
+	}
+
+	var logLine ds.LogLine
+	if err := json.Unmarshal(CorrectPacketData(ds.PacketTypeLog, logData, 1000), &logLine); err != nil {
+		t.Fatal(err)
+	}
+	if logLine.Ts != 4000 || logLine.Msg != "hello\n" {
+		t.Errorf("got %+v, want the ts corrected to 4000", logLine)
+	}
+
+	dumpData, _ := json.Marshal(ds.GoroutineDumpResult{
+		CommandId: "cmd1",
+		Info:      &ds.GoroutineInfo{Ts: 5000, Stacks: []ds.GoRoutineStack{{GoId: 1, Ts: 5000}}},
+	})
+	var dump ds.GoroutineDumpResult
+	if err := json.Unmarshal(CorrectPacketData(ds.PacketTypeGoroutineDumpResult, dumpData, -1000), &dump); err != nil {
+		t.Fatal(err)
+	}
+	if dump.CommandId != "cmd1" || dump.Info.Ts != 6000 || dump.Info.Stacks[0].Ts != 6000 {
+		t.Errorf("got %+v, want the info ts corrected to 6000", dump.Info)
+	}
+
+	metaData := json.RawMessage(`{"meta":{"team":"infra"}}`)
+	if got := CorrectPacketData(ds.PacketTypeAppMeta, metaData, 1000); string(got) != string(metaData) {
+		t.Errorf("packet without timestamps changed: %s", got)
+	}
+	badData := json.RawMessage(`{"ts":"x"}`)
+	if got := CorrectPacketData(ds.PacketTypeLog, badData, 1000); string(got) != string(badData) {
+		t.Errorf("undecodable packet changed: %s", got)
+	}
+}
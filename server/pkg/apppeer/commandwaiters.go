@@ -0,0 +1,58 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/utilds"
+)
+
+// commandWaiters holds the pending commands of one kind that were sent to the SDKs, by CommandId. The SDK
+// answers a command with a result packet carrying the same CommandId, which is passed to the waiting sender.
+type commandWaiters[T any] struct {
+	name    string // the kind of command (for errors and logs)
+	waiters *utilds.SyncMap[string, chan T]
+}
+
+func makeCommandWaiters[T any](name string) *commandWaiters[T] {
+	return &commandWaiters[T]{
+		name:    name,
+		waiters: utilds.MakeSyncMap[string, chan T](),
+	}
+}
+
+// sendAndWait sends the command packet to the app run's SDK and waits (until ctx is done) for the result of commandId
+func (cw *commandWaiters[T]) sendAndWait(ctx context.Context, p *AppRunPeer, commandId string, pk *ds.PacketType) (T, error) {
+	var rtn T
+	resultCh := make(chan T, 1)
+	cw.waiters.Set(commandId, resultCh)
+	defer cw.waiters.Delete(commandId)
+
+	if err := p.SendPacketToSDK(pk); err != nil {
+		return rtn, err
+	}
+	select {
+	case rtn = <-resultCh:
+		return rtn, nil
+	case <-ctx.Done():
+		return rtn, fmt.Errorf("no %s result from app run %s: %w", cw.name, p.AppRunId, ctx.Err())
+	}
+}
+
+// deliver passes a result to the sender waiting for commandId (results nobody is waiting for are dropped)
+func (cw *commandWaiters[T]) deliver(p *AppRunPeer, commandId string, result T) {
+	resultCh, ok := cw.waiters.GetEx(commandId)
+	if !ok {
+		log.Printf("Received %s result for unknown command %s (app run ID: %s)", cw.name, commandId, p.AppRunId)
+		return
+	}
+	select {
+	case resultCh <- result:
+	default:
+	}
+}
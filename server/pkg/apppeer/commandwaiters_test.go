@@ -0,0 +1,109 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/comm"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// makeTestAppRunPeer registers an app run peer for the test (removed when the test ends)
+func makeTestAppRunPeer(t *testing.T) *AppRunPeer {
+	t.Helper()
+	setTestDataDir(t, serverbase.RuntimeSettings{})
+	appRunId := "test-" + t.Name()
+	peer := GetAppRunPeer(appRunId, false)
+	t.Cleanup(func() { appRunPeers.Delete(appRunId) })
+	return peer
+}
+
+// connectTestSDK connects a fake SDK to the peer's packet connection. respond is called with every packet the
+// monitor sends, the packet it returns (if any) is handled as the SDK's answer.
+func connectTestSDK(t *testing.T, peer *AppRunPeer, respond func(packetType string, data json.RawMessage) *ds.PacketType) {
+	t.Helper()
+	serverConn, sdkConn := net.Pipe()
+	connWrap := comm.MakeConnWrap(serverConn, "test-sdk")
+	peer.SetPacketConn(connWrap)
+	t.Cleanup(func() {
+		peer.ClearPacketConn(connWrap)
+		serverConn.Close()
+		sdkConn.Close()
+	})
+	go func() {
+		reader := bufio.NewReader(sdkConn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			var pk struct {
+				Type string          `json:"type"`
+				Data json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal([]byte(line), &pk); err != nil {
+				t.Errorf("monitor sent an invalid packet: %v", err)
+				return
+			}
+			answer := respond(pk.Type, pk.Data)
+			if answer == nil {
+				continue
+			}
+			barr, _ := json.Marshal(answer.Data)
+			if err := peer.HandleSDKPacket(answer.Type, barr, 0); err != nil {
+				t.Errorf("error handling the answer: %v", err)
+			}
+		}
+	}()
+}
+
+func TestCommandWaiters(t *testing.T) {
+	peer := makeTestAppRunPeer(t)
+	waiters := makeCommandWaiters[string]("test")
+	pk := &ds.PacketType{Type: "testcommand", Data: "cmd1"}
+
+	if _, err := waiters.sendAndWait(context.Background(), peer, "cmd1", pk); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("got %v without a connection, want the send error", err)
+	}
+
+	sentCh := make(chan string, 10)
+	connectTestSDK(t, peer, func(packetType string, data json.RawMessage) *ds.PacketType {
+		var commandId string
+		json.Unmarshal(data, &commandId)
+		sentCh <- commandId
+		if commandId == "cmd2" {
+			// the answer to a command nobody waits for is dropped
+			waiters.deliver(peer, "unknown", "ignored")
+			waiters.deliver(peer, commandId, "result2")
+			waiters.deliver(peer, commandId, "duplicate")
+		}
+		return nil
+	})
+	result, err := waiters.sendAndWait(context.Background(), peer, "cmd2", &ds.PacketType{Type: "testcommand", Data: "cmd2"})
+	if err != nil || result != "result2" {
+		t.Errorf("got %q, %v, want the delivered result", result, err)
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelFn()
+	_, err = waiters.sendAndWait(ctx, peer, "cmd3", &ds.PacketType{Type: "testcommand", Data: "cmd3"})
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "no test result from app run") {
+		t.Errorf("got %v without an answer, want the timeout", err)
+	}
+	if waiters.waiters.Len() != 0 {
+		t.Errorf("got %d waiters left, want 0", waiters.waiters.Len())
+	}
+	if sent := []string{<-sentCh, <-sentCh}; sent[0] != "cmd2" || sent[1] != "cmd3" {
+		t.Errorf("got commands %v sent to the sdk", sent)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+const (
+	DefaultCompareIntervalMs = 10 * 1000
+	MinCompareIntervalMs     = 1000
+)
+
+// CompareAppRuns aligns the runtime stats of several runs of the same app by the time since each run started
+// (only the samples still in each run's RuntimeStatsPeer buffer are used)
+func CompareAppRuns(req rpctypes.CompareAppRunsRequest) (rpctypes.CompareAppRunsData, error) {
+	if req.AppName == "" {
+		return rpctypes.CompareAppRunsData{}, fmt.Errorf("app name is required")
+	}
+	intervalMs := req.IntervalMs
+	if intervalMs <= 0 {
+		intervalMs = DefaultCompareIntervalMs
+	}
+	if intervalMs < MinCompareIntervalMs {
+		intervalMs = MinCompareIntervalMs
+	}
+
+	var peers []*AppRunPeer
+	if len(req.AppRunIds) == 0 {
+		for _, peer := range GetAllAppRunPeers() {
+			if peer.AppInfo != nil && peer.AppInfo.AppName == req.AppName {
+				peers = append(peers, peer)
+			}
+		}
+	} else {
+		for _, appRunId := range req.AppRunIds {
+			peer := FindAppRunPeer(appRunId)
+			if peer == nil || peer.AppInfo == nil {
+				return rpctypes.CompareAppRunsData{}, fmt.Errorf("app run not found: %s", appRunId)
+			}
+			if peer.AppInfo.AppName != req.AppName {
+				return rpctypes.CompareAppRunsData{}, fmt.Errorf("app run %s is not a run of %q (app name %q)", appRunId, req.AppName, peer.AppInfo.AppName)
+			}
+			peers = append(peers, peer)
+		}
+	}
+
+	runs := make([]rpctypes.AppRunComparison, 0, len(peers))
+	for _, peer := range peers {
+		runs = append(runs, peer.makeAppRunComparison(intervalMs))
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].StartTime < runs[j].StartTime
+	})
+	return rpctypes.CompareAppRunsData{
+		AppName:    req.AppName,
+		IntervalMs: intervalMs,
+		Runs:       runs,
+	}, nil
+}
+
+func (p *AppRunPeer) makeAppRunComparison(intervalMs int64) rpctypes.AppRunComparison {
+	info := p.GetAppRunInfo()
+	rtn := rpctypes.AppRunComparison{
+		AppRunId:  info.AppRunId,
+		StartTime: info.StartTime,
+		Status:    info.Status,
+		BuildInfo: info.BuildInfo,
+		Samples:   []rpctypes.AppRunCompareSample{},
+	}
+	stats := p.RuntimeStats.GetFilteredStats(0)
+	var goRoutinePoints, heapPoints [][2]float64
+	for _, stat := range stats {
+		offsetMs := stat.Ts - info.StartTime
+		if offsetMs < 0 {
+			offsetMs = 0
+		}
+		sample := makeCompareSample(stat, (offsetMs/intervalMs)*intervalMs)
+		// stats are in time order, the last sample in a bucket replaces the earlier ones
+		if n := len(rtn.Samples); n > 0 && rtn.Samples[n-1].OffsetMs == sample.OffsetMs {
+			rtn.Samples[n-1] = sample
+		} else {
+			rtn.Samples = append(rtn.Samples, sample)
+		}
+		rtn.MaxGoRoutineCount = max(rtn.MaxGoRoutineCount, stat.GoRoutineCount)
+		rtn.MaxHeapAlloc = max(rtn.MaxHeapAlloc, stat.MemStats.HeapAlloc)
+		offsetMin := float64(offsetMs) / 60000
+		goRoutinePoints = append(goRoutinePoints, [2]float64{offsetMin, float64(stat.GoRoutineCount)})
+		heapPoints = append(heapPoints, [2]float64{offsetMin, float64(stat.MemStats.HeapAlloc)})
+	}
+	rtn.GoRoutineSlope = linearSlope(goRoutinePoints)
+	rtn.HeapAllocSlope = linearSlope(heapPoints)
+	return rtn
+}
+
+func makeCompareSample(stat ds.RuntimeStatsInfo, offsetMs int64) rpctypes.AppRunCompareSample {
+	return rpctypes.AppRunCompareSample{
+		OffsetMs:       offsetMs,
+		GoRoutineCount: stat.GoRoutineCount,
+		HeapAlloc:      stat.MemStats.HeapAlloc,
+		Sys:            stat.MemStats.Sys,
+		NumGC:          stat.MemStats.NumGC,
+	}
+}
+
+// linearSlope returns the least squares slope of the (x, y) points (0 if there are fewer than 2 distinct x values)
+func linearSlope(points [][2]float64) float64 {
+	n := float64(len(points))
+	if n < 2 {
+		return 0
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for _, pt := range points {
+		sumX += pt[0]
+		sumY += pt[1]
+		sumXY += pt[0] * pt[1]
+		sumXX += pt[0] * pt[0]
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denom
+}
@@ -0,0 +1,103 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+// MaxStoredCPUProfiles is the number of captured CPU profiles kept (in memory) for each app run
+const MaxStoredCPUProfiles = 10
+
+// CPUProfileDownloadPath is where the web server serves the stored CPU profiles (see GetCPUProfileDownloadUrl)
+const CPUProfileDownloadPath = "/api/cpuprofile"
+
+// CPUProfile is a CPU profile captured from an app run (Data is a gzipped pprof protobuf)
+type CPUProfile struct {
+	ProfileId   string
+	Ts          int64
+	DurationMs  int64
+	TriggeredBy string
+	Data        []byte
+}
+
+// cpuProfileWaiters holds the pending CPU profile captures by CommandId
+var cpuProfileWaiters = makeCommandWaiters[ds.CPUProfileResult]("cpu profile")
+
+// CaptureCPUProfile asks the SDK to capture a CPU profile for durationSec seconds and waits (until ctx is done)
+// for the profile. The profile is stored on the peer so it can be downloaded later (see GetCPUProfile).
+func (p *AppRunPeer) CaptureCPUProfile(ctx context.Context, durationSec int, triggeredBy string) (*CPUProfile, error) {
+	data := ds.CPUProfileData{
+		CommandId:   uuid.New().String(),
+		DurationSec: durationSec,
+		TriggeredBy: triggeredBy,
+	}
+	log.Printf("audit: cpu profile (%ds) on app run %s triggered by %q", durationSec, p.AppRunId, triggeredBy)
+	result, err := cpuProfileWaiters.sendAndWait(ctx, p, data.CommandId, &ds.PacketType{
+		Type: ds.PacketTypeCPUProfile,
+		Data: data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		log.Printf("audit: cpu profile on app run %s failed: %s", p.AppRunId, result.Error)
+		return nil, fmt.Errorf("cpu profile failed: %s", result.Error)
+	}
+	log.Printf("audit: cpu profile on app run %s done in %dms (%d bytes)", p.AppRunId, result.DurationMs, len(result.Profile))
+	profile := &CPUProfile{
+		ProfileId:   data.CommandId,
+		Ts:          result.Ts,
+		DurationMs:  result.DurationMs,
+		TriggeredBy: triggeredBy,
+		Data:        result.Profile,
+	}
+	p.addCPUProfile(profile)
+	return profile, nil
+}
+
+func (p *AppRunPeer) handleCPUProfileResult(packetData json.RawMessage) error {
+	var result ds.CPUProfileResult
+	if err := json.Unmarshal(packetData, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal CPUProfileResult: %w", err)
+	}
+	cpuProfileWaiters.deliver(p, result.CommandId, result)
+	return nil
+}
+
+func (p *AppRunPeer) addCPUProfile(profile *CPUProfile) {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	p.cpuProfiles = append(p.cpuProfiles, profile)
+	if len(p.cpuProfiles) > MaxStoredCPUProfiles {
+		p.cpuProfiles = p.cpuProfiles[len(p.cpuProfiles)-MaxStoredCPUProfiles:]
+	}
+}
+
+// GetCPUProfile returns a stored CPU profile (nil if it doesn't exist or was already dropped)
+func (p *AppRunPeer) GetCPUProfile(profileId string) *CPUProfile {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	for _, profile := range p.cpuProfiles {
+		if profile.ProfileId == profileId {
+			return profile
+		}
+	}
+	return nil
+}
+
+// GetCPUProfileDownloadUrl returns the (monitor relative) url of a stored CPU profile
+func GetCPUProfileDownloadUrl(appRunId string, profileId string) string {
+	query := url.Values{}
+	query.Set("apprunid", appRunId)
+	query.Set("profileid", profileId)
+	return CPUProfileDownloadPath + "?" + query.Encode()
+}
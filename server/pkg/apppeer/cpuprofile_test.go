@@ -0,0 +1,43 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func TestCaptureCPUProfile(t *testing.T) {
+	peer := makeTestAppRunPeer(t)
+	connectTestSDK(t, peer, func(packetType string, data json.RawMessage) *ds.PacketType {
+		var cmd ds.CPUProfileData
+		json.Unmarshal(data, &cmd)
+		result := ds.CPUProfileResult{CommandId: cmd.CommandId, Ts: 1000, DurationMs: 1000, Profile: []byte("pprof")}
+		if cmd.DurationSec > 5 {
+			result = ds.CPUProfileResult{CommandId: cmd.CommandId, Error: "a profile is already running"}
+		}
+		return &ds.PacketType{Type: ds.PacketTypeCPUProfileResult, Data: result}
+	})
+
+	profile, err := peer.CaptureCPUProfile(context.Background(), 1, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(profile.Data) != "pprof" || profile.TriggeredBy != "test" {
+		t.Errorf("got profile %+v", profile)
+	}
+	if stored := peer.GetCPUProfile(profile.ProfileId); stored != profile {
+		t.Errorf("the profile wasn't stored on the peer")
+	}
+
+	if _, err := peer.CaptureCPUProfile(context.Background(), 10, "test"); err == nil {
+		t.Errorf("the sdk's error wasn't returned")
+	}
+	if waiters := cpuProfileWaiters.waiters.Len(); waiters != 0 {
+		t.Errorf("got %d waiters left, want 0", waiters)
+	}
+}
@@ -0,0 +1,430 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/utilds"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/disklogbuf"
+	"github.com/outrigdev/outrig/server/pkg/packetrecord"
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// DiskUsageCheckInterval is how often the data directory is checked against MaxDataDirSizeMB
+const DiskUsageCheckInterval = 30 * time.Second
+
+// the stores of an app run (see GetDiskUsage and CompactStore)
+const (
+	Store_Logs         = "logs"         // compact drops the old segments of the disk log buffer
+	Store_GoRoutines   = "goroutines"   // compact removes the goroutines that are no longer running
+	Store_Watches      = "watches"      // compact removes the unregistered watches
+	Store_RuntimeStats = "runtimestats" // drop only
+	Store_Panics       = "panics"       // drop only
+	Store_CPUProfiles  = "cpuprofiles"  // drop only
+	Store_Export       = "export"       // drop only (the last exported bundle)
+	Store_Packets      = "packets"      // drop only (the packet recording, see --record-packets)
+)
+
+// rough per-item sizes for the memory estimates (the variable length fields are added separately)
+var (
+	logLineSize      = int64(unsafe.Sizeof(ds.LogLine{}))
+	goRoutineSize    = int64(unsafe.Sizeof(GoRoutine{}))
+	stackSize        = int64(unsafe.Sizeof(ds.GoRoutineStack{}))
+	watchSize        = int64(unsafe.Sizeof(Watch{}))
+	watchSampleSize  = int64(unsafe.Sizeof(ds.WatchSample{}))
+	runtimeStatsSize = int64(unsafe.Sizeof(ds.RuntimeStatsInfo{}))
+	panicSize        = int64(unsafe.Sizeof(ds.PanicInfo{}))
+)
+
+// dataDirWarning is the last published disk usage warning (nil when the data directory is under the max size)
+var dataDirWarning atomic.Pointer[rpctypes.DiskUsageWarningEvent]
+
+// StartDiskUsageChecker checks the size of the data directory every DiskUsageCheckInterval (see CheckDataDirSize)
+func StartDiskUsageChecker() {
+	go func() {
+		outrig.SetGoRoutineName("apppeer.diskusage")
+		for {
+			time.Sleep(DiskUsageCheckInterval)
+			CheckDataDirSize()
+		}
+	}()
+}
+
+// CheckDataDirSize compares the size of the data directory with the MaxDataDirSizeMB setting and publishes
+// an Event_DiskUsageWarning when it goes over the max size (and again when it goes back under it)
+func CheckDataDirSize() {
+	maxBytes := int64(serverbase.GetRuntimeSettings().MaxDataDirSizeMB) * 1024 * 1024
+	var dirBytes int64
+	if maxBytes > 0 {
+		dirBytes = serverbase.GetDirSize(utilfn.ExpandHomeDir(serverbase.GetOutrigDataDir()))
+	}
+	isOver := maxBytes > 0 && dirBytes > maxBytes
+	wasOver := dataDirWarning.Load() != nil
+	if !isOver && !wasOver {
+		return
+	}
+	event := &rpctypes.DiskUsageWarningEvent{
+		Ts:              time.Now().UnixMilli(),
+		DataDirBytes:    dirBytes,
+		MaxDataDirBytes: maxBytes,
+	}
+	if isOver {
+		event.Warning = fmt.Sprintf("Outrig data directory is %dMB (max %dMB)", dirBytes/(1024*1024), maxBytes/(1024*1024))
+		dataDirWarning.Store(event)
+		if wasOver {
+			// still over, only the sizes changed
+			return
+		}
+		log.Printf("warning: %s, clear or compact app runs to free space\n", event.Warning)
+	} else {
+		dataDirWarning.Store(nil)
+		log.Printf("Outrig data directory is back under the max size (%dMB)\n", dirBytes/(1024*1024))
+	}
+	rpc.Broker.Publish(rpctypes.EventType{
+		Event: rpctypes.Event_DiskUsageWarning,
+		Data:  *event,
+	})
+}
+
+// GetDataDirWarning returns the current disk usage warning ("" when the data directory is under the max size)
+func GetDataDirWarning() string {
+	event := dataDirWarning.Load()
+	if event == nil {
+		return ""
+	}
+	return event.Warning
+}
+
+// GetDiskUsage returns the size of the data directory and the footprint of each app run
+func GetDiskUsage() rpctypes.DiskUsageData {
+	dataDir := utilfn.ExpandHomeDir(serverbase.GetOutrigDataDir())
+	rtn := rpctypes.DiskUsageData{
+		DataDir:         dataDir,
+		DataDirBytes:    serverbase.GetDirSize(dataDir),
+		LogBufferBytes:  serverbase.GetDirSize(utilfn.ExpandHomeDir(serverbase.GetLogBufferDir())),
+		SnapshotsBytes:  serverbase.GetDirSize(utilfn.ExpandHomeDir(serverbase.GetSnapshotsDir())),
+		PacketRecordDir: serverbase.PacketRecordDir,
+		MaxDataDirBytes: int64(serverbase.GetRuntimeSettings().MaxDataDirSizeMB) * 1024 * 1024,
+		Warning:         GetDataDirWarning(),
+		AppRuns:         []rpctypes.AppRunDiskUsage{},
+	}
+	if info, err := os.Stat(utilfn.ExpandHomeDir(serverbase.GetTEventsFilePath())); err == nil {
+		rtn.TEventsBytes = info.Size()
+	}
+	if serverbase.PacketRecordDir != "" {
+		rtn.PacketRecordBytes = serverbase.GetDirSize(serverbase.PacketRecordDir)
+	}
+	if freeBytes, totalBytes, err := serverbase.GetDiskSpace(dataDir); err == nil {
+		rtn.FreeBytes, rtn.TotalBytes = freeBytes, totalBytes
+	}
+	for _, peer := range GetAllAppRunPeers() {
+		if peer.AppInfo == nil {
+			continue
+		}
+		rtn.AppRuns = append(rtn.AppRuns, peer.GetDiskUsage())
+	}
+	sort.Slice(rtn.AppRuns, func(i, j int) bool {
+		return rtn.AppRuns[i].MemBytes+rtn.AppRuns[i].DiskBytes > rtn.AppRuns[j].MemBytes+rtn.AppRuns[j].DiskBytes
+	})
+	return rtn
+}
+
+// GetDiskUsage returns the footprint of the app run's stores
+func (p *AppRunPeer) GetDiskUsage() rpctypes.AppRunDiskUsage {
+	rtn := rpctypes.AppRunDiskUsage{
+		AppRunId:  p.AppRunId,
+		Status:    p.Status,
+		IsRunning: p.Status == AppStatusRunning,
+		Stores: []rpctypes.AppRunStoreUsage{
+			p.Logs.getStoreUsage(),
+			p.GoRoutines.getStoreUsage(),
+			p.Watches.getStoreUsage(),
+			p.RuntimeStats.getStoreUsage(),
+			p.Panics.getStoreUsage(),
+		},
+	}
+	if p.AppInfo != nil {
+		rtn.AppName = p.AppInfo.AppName
+	}
+	rtn.Stores = append(rtn.Stores, p.getCPUProfilesUsage(), p.getExportUsage())
+	if serverbase.PacketRecordDir != "" {
+		rtn.Stores = append(rtn.Stores, p.getPacketsUsage())
+	}
+	for _, store := range rtn.Stores {
+		rtn.MemBytes += store.MemBytes
+		rtn.DiskBytes += store.DiskBytes
+	}
+	return rtn
+}
+
+// CompactAppRunStore compacts (or with drop, clears) one of the stores of an app run
+func CompactAppRunStore(appRunId string, store string, drop bool) (rpctypes.CompactAppRunStoreResult, error) {
+	peer, exists := appRunPeers.GetEx(appRunId)
+	if !exists || peer.AppInfo == nil {
+		return rpctypes.CompactAppRunStoreResult{}, fmt.Errorf("app run %q not found", appRunId)
+	}
+	return peer.CompactStore(store, drop)
+}
+
+// CompactStore compacts (or with drop, clears) one of the app run's stores, see the Store_* constants
+// for what each store supports
+func (p *AppRunPeer) CompactStore(store string, drop bool) (rpctypes.CompactAppRunStoreResult, error) {
+	var before rpctypes.AppRunStoreUsage
+	for _, usage := range p.GetDiskUsage().Stores {
+		if usage.Store == store {
+			before = usage
+		}
+	}
+	if before.Store == "" {
+		return rpctypes.CompactAppRunStoreResult{}, fmt.Errorf("unknown store %q", store)
+	}
+	if drop && !before.CanDrop {
+		return rpctypes.CompactAppRunStoreResult{}, fmt.Errorf("store %q cannot be dropped (it can only be compacted)", store)
+	}
+	if !drop && !before.CanCompact {
+		return rpctypes.CompactAppRunStoreResult{}, fmt.Errorf("store %q cannot be compacted (it can only be dropped)", store)
+	}
+	var after rpctypes.AppRunStoreUsage
+	switch store {
+	case Store_Logs:
+		after = p.Logs.compact(drop)
+	case Store_GoRoutines:
+		after = p.GoRoutines.compact()
+	case Store_Watches:
+		after = p.Watches.compact()
+	case Store_RuntimeStats:
+		after = p.RuntimeStats.drop()
+	case Store_Panics:
+		after = p.Panics.drop()
+	case Store_CPUProfiles:
+		p.dataLock.Lock()
+		p.cpuProfiles = nil
+		p.dataLock.Unlock()
+		after = p.getCPUProfilesUsage()
+	case Store_Export:
+		p.dataLock.Lock()
+		p.lastExport = nil
+		p.dataLock.Unlock()
+		after = p.getExportUsage()
+	case Store_Packets:
+		if packetrecord.IsRecording(p.AppRunId) {
+			return rpctypes.CompactAppRunStoreResult{}, fmt.Errorf("app run %q is still being recorded", p.AppRunId)
+		}
+		err := os.Remove(packetrecord.GetRecordFileName(serverbase.PacketRecordDir, p.AppRunId))
+		if err != nil && !os.IsNotExist(err) {
+			return rpctypes.CompactAppRunStoreResult{}, err
+		}
+		after = p.getPacketsUsage()
+	}
+	p.LastModTime = time.Now().UnixMilli()
+	result := rpctypes.CompactAppRunStoreResult{
+		AppRunId:       p.AppRunId,
+		Store:          store,
+		ItemsRemoved:   max(before.Items-after.Items, 0),
+		MemBytesFreed:  max(before.MemBytes-after.MemBytes, 0),
+		DiskBytesFreed: max(before.DiskBytes-after.DiskBytes, 0),
+	}
+	log.Printf("compacted store %s of app run %s (drop:%v): removed %d items, freed %d bytes of memory and %d bytes of disk\n",
+		store, p.AppRunId, drop, result.ItemsRemoved, result.MemBytesFreed, result.DiskBytesFreed)
+	return result, nil
+}
+
+func (p *AppRunPeer) getCPUProfilesUsage() rpctypes.AppRunStoreUsage {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	usage := rpctypes.AppRunStoreUsage{Store: Store_CPUProfiles, Items: len(p.cpuProfiles), CanDrop: true}
+	for _, profile := range p.cpuProfiles {
+		usage.MemBytes += int64(len(profile.Data))
+	}
+	return usage
+}
+
+func (p *AppRunPeer) getExportUsage() rpctypes.AppRunStoreUsage {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	usage := rpctypes.AppRunStoreUsage{Store: Store_Export, CanDrop: true}
+	if p.lastExport != nil {
+		usage.Items = 1
+		usage.MemBytes = int64(len(p.lastExport.Data))
+	}
+	return usage
+}
+
+func (p *AppRunPeer) getPacketsUsage() rpctypes.AppRunStoreUsage {
+	usage := rpctypes.AppRunStoreUsage{Store: Store_Packets, CanDrop: true}
+	info, err := os.Stat(packetrecord.GetRecordFileName(serverbase.PacketRecordDir, p.AppRunId))
+	if err == nil {
+		usage.Items = 1
+		usage.DiskBytes = info.Size()
+	}
+	return usage
+}
+
+func (lp *LogLinePeer) getStoreUsage() rpctypes.AppRunStoreUsage {
+	usage := rpctypes.AppRunStoreUsage{Store: Store_Logs, CanDrop: true}
+	switch store := lp.getStore().(type) {
+	case *memLogLineStore:
+		store.buf.ForEach(func(line ds.LogLine) bool {
+			usage.Items++
+			usage.MemBytes += logLineSize + int64(len(line.Msg)+len(line.Source))
+			return true
+		})
+	case *disklogbuf.DiskLogBuf:
+		usage.Items = store.Size()
+		usage.DiskBytes = store.DiskSize()
+		usage.CanCompact = true
+	}
+	return usage
+}
+
+// compact drops the old segments of a disk log buffer, or with drop, all of the stored lines
+// (a new store is created for the next line, line numbers keep counting up)
+func (lp *LogLinePeer) compact(drop bool) rpctypes.AppRunStoreUsage {
+	if !drop {
+		if diskBuf, ok := lp.getStore().(*disklogbuf.DiskLogBuf); ok {
+			diskBuf.DropOldSegments()
+		}
+		return lp.getStoreUsage()
+	}
+	lp.logLineLock.Lock()
+	store := lp.logLines
+	lp.logLines = nil
+	// the index has the positions of the dropped store, it is recreated with the next store
+	lp.index = nil
+	lp.logLineLock.Unlock()
+	if store != nil {
+		if err := store.Close(); err != nil {
+			log.Printf("error closing log line store: %v\n", err)
+		}
+	}
+	return lp.getStoreUsage()
+}
+
+func (gp *GoRoutinePeer) getStoreUsage() rpctypes.AppRunStoreUsage {
+	usage := rpctypes.AppRunStoreUsage{Store: Store_GoRoutines, CanCompact: true}
+	for _, goId := range gp.goRoutines.Keys() {
+		goroutine, exists := gp.goRoutines.GetEx(goId)
+		if !exists {
+			continue
+		}
+		usage.Items++
+		usage.MemBytes += goRoutineSize + getStacksSize(goroutine.StackTraces)
+	}
+	return usage
+}
+
+// getStacksSize estimates the memory used by a goroutine's stacks
+// (unchanged samples share their strings with the previous sample)
+func getStacksSize(stacks *utilds.CirBuf[ds.GoRoutineStack]) int64 {
+	var size int64
+	var prevTrace string
+	stacks.ForEach(func(stack ds.GoRoutineStack) bool {
+		size += stackSize
+		if stack.StackTrace != prevTrace {
+			size += int64(len(stack.StackTrace) + len(stack.Labels))
+			prevTrace = stack.StackTrace
+		}
+		return true
+	})
+	return size
+}
+
+// compact removes the goroutines that are not running (they are counted as dropped, like pruned goroutines)
+func (gp *GoRoutinePeer) compact() rpctypes.AppRunStoreUsage {
+	gp.lock.Lock()
+	for _, goId := range gp.goRoutines.Keys() {
+		if !gp.activeGoRoutines[goId] {
+			gp.goRoutines.Delete(goId)
+			gp.droppedCount.Add(1)
+		}
+	}
+	gp.lock.Unlock()
+	return gp.getStoreUsage()
+}
+
+func (wp *WatchesPeer) getStoreUsage() rpctypes.AppRunStoreUsage {
+	usage := rpctypes.AppRunStoreUsage{Store: Store_Watches, CanCompact: true}
+	for _, watchNum := range wp.watches.Keys() {
+		watch, exists := wp.watches.GetEx(watchNum)
+		if !exists {
+			continue
+		}
+		usage.Items++
+		usage.MemBytes += watchSize
+		var prevVal string
+		watch.WatchVals.ForEach(func(sample ds.WatchSample) bool {
+			usage.MemBytes += watchSampleSize
+			if sample.Val != prevVal {
+				usage.MemBytes += int64(len(sample.Val))
+				prevVal = sample.Val
+			}
+			return true
+		})
+	}
+	return usage
+}
+
+// compact removes the watches that were unregistered in the SDK
+func (wp *WatchesPeer) compact() rpctypes.AppRunStoreUsage {
+	wp.lock.Lock()
+	for name, watchNum := range wp.nameToWatchNum {
+		watch, exists := wp.watches.GetEx(watchNum)
+		if exists && watch.Decl.Unregistered {
+			wp.watches.Delete(watchNum)
+			delete(wp.nameToWatchNum, name)
+		}
+	}
+	wp.lock.Unlock()
+	return wp.getStoreUsage()
+}
+
+func (rsp *RuntimeStatsPeer) getStoreUsage() rpctypes.AppRunStoreUsage {
+	rsp.lock.RLock()
+	defer rsp.lock.RUnlock()
+	numStats := rsp.runtimeStats.Size()
+	return rpctypes.AppRunStoreUsage{
+		Store:    Store_RuntimeStats,
+		Items:    numStats,
+		MemBytes: int64(numStats) * runtimeStatsSize,
+		CanDrop:  true,
+	}
+}
+
+func (rsp *RuntimeStatsPeer) drop() rpctypes.AppRunStoreUsage {
+	rsp.lock.Lock()
+	rsp.runtimeStats = utilds.MakeCirBuf[ds.RuntimeStatsInfo](RuntimeStatsBufferSize)
+	rsp.lock.Unlock()
+	return rsp.getStoreUsage()
+}
+
+func (pp *PanicsPeer) getStoreUsage() rpctypes.AppRunStoreUsage {
+	pp.lock.RLock()
+	defer pp.lock.RUnlock()
+	usage := rpctypes.AppRunStoreUsage{Store: Store_Panics, CanDrop: true}
+	pp.panics.ForEach(func(panicInfo ds.PanicInfo) bool {
+		usage.Items++
+		usage.MemBytes += panicSize + int64(len(panicInfo.PanicVal)+len(panicInfo.StackTrace))
+		return true
+	})
+	return usage
+}
+
+func (pp *PanicsPeer) drop() rpctypes.AppRunStoreUsage {
+	pp.lock.Lock()
+	pp.panics = utilds.MakeCirBuf[ds.PanicInfo](PanicsBufferSize)
+	pp.lock.Unlock()
+	return pp.getStoreUsage()
+}
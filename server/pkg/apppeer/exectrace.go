@@ -0,0 +1,131 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// MaxStoredExecTraces is the number of captured execution traces kept (in memory) for each app run
+// (fewer than CPU profiles, traces are much larger)
+const MaxStoredExecTraces = 5
+
+// ExecTraceDownloadPath is where the web server serves the stored execution traces (see GetExecTraceDownloadUrl)
+const ExecTraceDownloadPath = "/api/exectrace"
+
+// ExecTrace is an execution trace captured from an app run (Data is in the runtime/trace format)
+type ExecTrace struct {
+	TraceId     string
+	Ts          int64
+	DurationMs  int64
+	Truncated   bool
+	TriggeredBy string
+	Data        []byte
+}
+
+var execTraceWaiters = makeCommandWaiters[ds.ExecTraceResult]("execution trace")
+
+// CaptureExecTrace asks the SDK to capture an execution trace for durationSec seconds and waits (until ctx is done)
+// for the trace. The trace is stored on the peer so it can be downloaded later (see GetExecTrace).
+func (p *AppRunPeer) CaptureExecTrace(ctx context.Context, durationSec int, triggeredBy string) (*ExecTrace, error) {
+	data := ds.ExecTraceData{
+		CommandId:   uuid.New().String(),
+		DurationSec: durationSec,
+		TriggeredBy: triggeredBy,
+	}
+	log.Printf("audit: execution trace (%ds) on app run %s triggered by %q", durationSec, p.AppRunId, triggeredBy)
+	result, err := execTraceWaiters.sendAndWait(ctx, p, data.CommandId, &ds.PacketType{
+		Type: ds.PacketTypeExecTrace,
+		Data: data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		log.Printf("audit: execution trace on app run %s failed: %s", p.AppRunId, result.Error)
+		return nil, fmt.Errorf("execution trace failed: %s", result.Error)
+	}
+	log.Printf("audit: execution trace on app run %s done in %dms (%d bytes)", p.AppRunId, result.DurationMs, len(result.Trace))
+	execTrace := &ExecTrace{
+		TraceId:     data.CommandId,
+		Ts:          result.Ts,
+		DurationMs:  result.DurationMs,
+		Truncated:   result.Truncated,
+		TriggeredBy: triggeredBy,
+		Data:        result.Trace,
+	}
+	p.addExecTrace(execTrace)
+	return execTrace, nil
+}
+
+func (p *AppRunPeer) handleExecTraceResult(packetData json.RawMessage) error {
+	var result ds.ExecTraceResult
+	if err := json.Unmarshal(packetData, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal ExecTraceResult: %w", err)
+	}
+	execTraceWaiters.deliver(p, result.CommandId, result)
+	return nil
+}
+
+func (p *AppRunPeer) addExecTrace(execTrace *ExecTrace) {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	p.execTraces = append(p.execTraces, execTrace)
+	if len(p.execTraces) > MaxStoredExecTraces {
+		p.execTraces = p.execTraces[len(p.execTraces)-MaxStoredExecTraces:]
+	}
+}
+
+// GetExecTrace returns a stored execution trace (nil if it doesn't exist or was already dropped)
+func (p *AppRunPeer) GetExecTrace(traceId string) *ExecTrace {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	for _, execTrace := range p.execTraces {
+		if execTrace.TraceId == traceId {
+			return execTrace
+		}
+	}
+	return nil
+}
+
+// GetExecTraceInfos returns the stored execution traces (oldest first)
+func (p *AppRunPeer) GetExecTraceInfos() []rpctypes.ExecTraceInfo {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	rtn := make([]rpctypes.ExecTraceInfo, 0, len(p.execTraces))
+	for _, execTrace := range p.execTraces {
+		rtn = append(rtn, execTrace.ToInfo(p.AppRunId))
+	}
+	return rtn
+}
+
+// ToInfo returns the description of the trace sent to the frontend
+func (t *ExecTrace) ToInfo(appRunId string) rpctypes.ExecTraceInfo {
+	return rpctypes.ExecTraceInfo{
+		AppRunId:    appRunId,
+		TraceId:     t.TraceId,
+		Ts:          t.Ts,
+		DurationMs:  t.DurationMs,
+		Size:        len(t.Data),
+		Truncated:   t.Truncated,
+		TriggeredBy: t.TriggeredBy,
+		DownloadUrl: GetExecTraceDownloadUrl(appRunId, t.TraceId),
+	}
+}
+
+// GetExecTraceDownloadUrl returns the (monitor relative) url of a stored execution trace
+func GetExecTraceDownloadUrl(appRunId string, traceId string) string {
+	query := url.Values{}
+	query.Set("apprunid", appRunId)
+	query.Set("traceid", traceId)
+	return ExecTraceDownloadPath + "?" + query.Encode()
+}
@@ -0,0 +1,53 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/outrigdev/outrig/server/pkg/gensearch"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// GlobalSearchLogs searches the logs of the requested app runs (all app runs, or all runs of req.AppName,
+// if no ids are given). Downstream (federated) app runs are not searched.
+func GlobalSearchLogs(ctx context.Context, req rpctypes.GlobalSearchRequest) (rpctypes.GlobalSearchResultData, error) {
+	if strings.TrimSpace(req.SearchTerm) == "" {
+		return rpctypes.GlobalSearchResultData{}, fmt.Errorf("searchterm is required")
+	}
+	var peers []*AppRunPeer
+	if len(req.AppRunIds) == 0 {
+		for _, peer := range GetAllAppRunPeers() {
+			if peer.AppInfo == nil {
+				continue
+			}
+			if req.AppName != "" && peer.AppInfo.AppName != req.AppName {
+				continue
+			}
+			peers = append(peers, peer)
+		}
+	} else {
+		for _, appRunId := range req.AppRunIds {
+			peer := FindAppRunPeer(appRunId)
+			if peer == nil || peer.AppInfo == nil {
+				return rpctypes.GlobalSearchResultData{}, fmt.Errorf("app run not found: %s", appRunId)
+			}
+			peers = append(peers, peer)
+		}
+	}
+
+	targets := make([]gensearch.GlobalSearchTarget, 0, len(peers))
+	for _, peer := range peers {
+		targets = append(targets, gensearch.GlobalSearchTarget{
+			AppRunId:  peer.AppRunId,
+			AppName:   peer.AppInfo.AppName,
+			Status:    peer.Status,
+			StartTime: peer.AppInfo.StartTime,
+			LogPeer:   peer.Logs,
+		})
+	}
+	return gensearch.GlobalSearch(ctx, req.SearchTerm, targets, req.MaxResults)
+}
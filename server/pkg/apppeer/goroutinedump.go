@@ -0,0 +1,153 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// MaxStoredGoroutineDumps is the number of goroutine dumps taken on demand that are kept for each app run.
+// They are pinned (not pruned with the regular goroutine samples), only the oldest is dropped past the limit.
+const MaxStoredGoroutineDumps = 20
+
+// GoroutineDumpLabel_Manual is the label of the goroutine dumps requested with ForceGoroutineDumpCommand
+const GoroutineDumpLabel_Manual = "manual snapshot"
+
+// GoroutineDumpDownloadPath is where the web server serves the stored goroutine dumps (see GetGoroutineDumpDownloadUrl)
+const GoroutineDumpDownloadPath = "/api/goroutinedump"
+
+// GoroutineDump is a full goroutine dump taken on demand (outside the regular goroutine poll)
+type GoroutineDump struct {
+	DumpId      string
+	Ts          int64
+	Label       string
+	TriggeredBy string
+	Info        ds.GoroutineInfo
+}
+
+var goroutineDumpWaiters = makeCommandWaiters[ds.GoroutineDumpResult]("goroutine dump")
+
+// ForceGoroutineDump asks the SDK for an immediate full goroutine dump and waits (until ctx is done) for it.
+// The dump is stored (pinned) on the peer so it stays available after the regular samples are pruned.
+func (p *AppRunPeer) ForceGoroutineDump(ctx context.Context, label string, triggeredBy string) (*GoroutineDump, error) {
+	data := ds.GoroutineDumpData{
+		CommandId:   uuid.New().String(),
+		TriggeredBy: triggeredBy,
+	}
+	log.Printf("audit: goroutine dump (%s) on app run %s triggered by %q", label, p.AppRunId, triggeredBy)
+	result, err := goroutineDumpWaiters.sendAndWait(ctx, p, data.CommandId, &ds.PacketType{
+		Type: ds.PacketTypeGoroutineDump,
+		Data: data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != "" || result.Info == nil {
+		log.Printf("audit: goroutine dump on app run %s failed: %s", p.AppRunId, result.Error)
+		return nil, fmt.Errorf("goroutine dump failed: %s", result.Error)
+	}
+	log.Printf("audit: goroutine dump on app run %s done (%d goroutines)", p.AppRunId, result.Info.Count)
+	dump := &GoroutineDump{
+		DumpId:      data.CommandId,
+		Ts:          result.Info.Ts,
+		Label:       label,
+		TriggeredBy: triggeredBy,
+		Info:        *result.Info,
+	}
+	p.addGoroutineDump(dump)
+	return dump, nil
+}
+
+func (p *AppRunPeer) handleGoroutineDumpResult(packetData json.RawMessage, clockSkewMs int64) error {
+	var result ds.GoroutineDumpResult
+	if err := json.Unmarshal(packetData, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal GoroutineDumpResult: %w", err)
+	}
+	if result.Info != nil {
+		correctGoroutineInfo(result.Info, clockSkewMs)
+	}
+	goroutineDumpWaiters.deliver(p, result.CommandId, result)
+	return nil
+}
+
+func (p *AppRunPeer) addGoroutineDump(dump *GoroutineDump) {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	p.goroutineDumps = append(p.goroutineDumps, dump)
+	if len(p.goroutineDumps) > MaxStoredGoroutineDumps {
+		p.goroutineDumps = p.goroutineDumps[len(p.goroutineDumps)-MaxStoredGoroutineDumps:]
+	}
+}
+
+// GetGoroutineDump returns a stored goroutine dump (nil if it doesn't exist or was already dropped)
+func (p *AppRunPeer) GetGoroutineDump(dumpId string) *GoroutineDump {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	for _, dump := range p.goroutineDumps {
+		if dump.DumpId == dumpId {
+			return dump
+		}
+	}
+	return nil
+}
+
+// GetGoroutineDumpInfos returns the stored goroutine dumps (oldest first)
+func (p *AppRunPeer) GetGoroutineDumpInfos() []rpctypes.GoroutineDumpInfo {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	rtn := make([]rpctypes.GoroutineDumpInfo, 0, len(p.goroutineDumps))
+	for _, dump := range p.goroutineDumps {
+		rtn = append(rtn, dump.ToInfo(p.AppRunId))
+	}
+	return rtn
+}
+
+// ToInfo returns the description of the dump sent to the frontend
+func (d *GoroutineDump) ToInfo(appRunId string) rpctypes.GoroutineDumpInfo {
+	return rpctypes.GoroutineDumpInfo{
+		AppRunId:      appRunId,
+		DumpId:        d.DumpId,
+		Ts:            d.Ts,
+		Label:         d.Label,
+		NumGoRoutines: d.Info.Count,
+		TriggeredBy:   d.TriggeredBy,
+		DownloadUrl:   GetGoroutineDumpDownloadUrl(appRunId, d.DumpId),
+	}
+}
+
+// Text returns the dump in the format of runtime.Stack (goroutines sorted by id), named goroutines get a
+// "# name: <name>" line before their header
+func (d *GoroutineDump) Text() []byte {
+	stacks := make([]ds.GoRoutineStack, len(d.Info.Stacks))
+	copy(stacks, d.Info.Stacks)
+	sort.Slice(stacks, func(i, j int) bool {
+		return stacks[i].GoId < stacks[j].GoId
+	})
+	var buf bytes.Buffer
+	for _, stack := range stacks {
+		if stack.Name != "" {
+			fmt.Fprintf(&buf, "# name: %s\n", stack.Name)
+		}
+		fmt.Fprintf(&buf, "goroutine %d [%s]:\n%s\n\n", stack.GoId, stack.State, stack.StackTrace)
+	}
+	return buf.Bytes()
+}
+
+// GetGoroutineDumpDownloadUrl returns the (monitor relative) url of a stored goroutine dump
+func GetGoroutineDumpDownloadUrl(appRunId string, dumpId string) string {
+	query := url.Values{}
+	query.Set("apprunid", appRunId)
+	query.Set("dumpid", dumpId)
+	return GoroutineDumpDownloadPath + "?" + query.Encode()
+}
@@ -0,0 +1,91 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func makeTestGoroutineInfo() *ds.GoroutineInfo {
+	return &ds.GoroutineInfo{
+		Ts:    1000,
+		Count: 2,
+		Stacks: []ds.GoRoutineStack{
+			{GoId: 7, Ts: 1000, State: "running", Name: "worker", StackTrace: "main.work()\n\t/app/main.go:20 +0x10"},
+			{GoId: 1, Ts: 1000, State: "chan receive", StackTrace: "main.main()\n\t/app/main.go:10 +0x20"},
+		},
+	}
+}
+
+func TestForceGoroutineDump(t *testing.T) {
+	peer := makeTestAppRunPeer(t)
+	sdkError := ""
+	connectTestSDK(t, peer, func(packetType string, data json.RawMessage) *ds.PacketType {
+		var dumpData ds.GoroutineDumpData
+		if packetType != ds.PacketTypeGoroutineDump || json.Unmarshal(data, &dumpData) != nil {
+			t.Errorf("got packet %s %s, want a goroutine dump command", packetType, data)
+			return nil
+		}
+		result := ds.GoroutineDumpResult{CommandId: dumpData.CommandId, Error: sdkError}
+		if sdkError == "" {
+			result.Info = makeTestGoroutineInfo()
+		}
+		return &ds.PacketType{Type: ds.PacketTypeGoroutineDumpResult, Data: result}
+	})
+
+	dump, err := peer.ForceGoroutineDump(context.Background(), "before deploy", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dump.Label != "before deploy" || dump.TriggeredBy != "test" || dump.Ts != 1000 || dump.Info.Count != 2 {
+		t.Errorf("got dump %+v", dump)
+	}
+	if peer.GetGoroutineDump(dump.DumpId) != dump {
+		t.Errorf("the dump was not stored")
+	}
+	infos := peer.GetGoroutineDumpInfos()
+	if len(infos) != 1 || infos[0].NumGoRoutines != 2 || !strings.Contains(infos[0].DownloadUrl, "dumpid="+dump.DumpId) {
+		t.Errorf("got dump infos %+v", infos)
+	}
+
+	sdkError = "goroutine stacks are not supported on this platform"
+	if _, err := peer.ForceGoroutineDump(context.Background(), "", "test"); err == nil || !strings.Contains(err.Error(), sdkError) {
+		t.Errorf("got %v, want the sdk's error", err)
+	}
+	if len(peer.GetGoroutineDumpInfos()) != 1 {
+		t.Errorf("a failed dump was stored")
+	}
+}
+
+func TestGoroutineDumpPruning(t *testing.T) {
+	peer := makeTestAppRunPeer(t)
+	for i := 0; i < MaxStoredGoroutineDumps+2; i++ {
+		peer.addGoroutineDump(&GoroutineDump{DumpId: fmt.Sprintf("dump%d", i)})
+	}
+	infos := peer.GetGoroutineDumpInfos()
+	if len(infos) != MaxStoredGoroutineDumps || infos[0].DumpId != "dump2" {
+		t.Errorf("got %d dumps starting with %s, want the newest %d", len(infos), infos[0].DumpId, MaxStoredGoroutineDumps)
+	}
+	if peer.GetGoroutineDump("dump0") != nil {
+		t.Errorf("the oldest dump was not dropped")
+	}
+}
+
+func TestGoroutineDumpText(t *testing.T) {
+	dump := &GoroutineDump{Info: *makeTestGoroutineInfo()}
+	expected := "goroutine 1 [chan receive]:\nmain.main()\n\t/app/main.go:10 +0x20\n\n" +
+		"# name: worker\ngoroutine 7 [running]:\nmain.work()\n\t/app/main.go:20 +0x10\n\n"
+	if got := string(dump.Text()); got != expected {
+		t.Errorf("got:\n%s\nwant:\n%s", got, expected)
+	}
+	if dump.Info.Stacks[0].GoId != 7 {
+		t.Errorf("Text reordered the stored stacks")
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/utilds"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// GoRoutineChurnRateWindowMs is the window used to compute the current goroutine start rate of a call site
+const GoRoutineChurnRateWindowMs = 10 * 1000
+
+// the SDK sends at most one churn report per goroutine poll (1s), this covers the rate window
+const goRoutineChurnReportBufferSize = 30
+
+// minimum report interval used for the peak rate (so a report right after a restart doesn't spike it)
+const minChurnPeakIntervalMs = 500
+
+// GoRoutineChurnPeer stores the goroutine churn reports (goroutines started and finished between
+// goroutine polls, by call site) of an AppRunPeer
+type GoRoutineChurnPeer struct {
+	lock    sync.RWMutex
+	reports *utilds.CirBuf[ds.GoroutineChurnInfo]
+	totals  map[string]*rpctypes.GoRoutineChurnSite // call site => totals over the app run
+	runTime map[string]int64                        // call site => total run time of the finished goroutines
+}
+
+// MakeGoRoutineChurnPeer creates a new GoRoutineChurnPeer instance
+func MakeGoRoutineChurnPeer() *GoRoutineChurnPeer {
+	return &GoRoutineChurnPeer{
+		reports: utilds.MakeCirBuf[ds.GoroutineChurnInfo](goRoutineChurnReportBufferSize),
+		totals:  make(map[string]*rpctypes.GoRoutineChurnSite),
+		runTime: make(map[string]int64),
+	}
+}
+
+// ProcessChurnInfo adds a churn report from a packet to the call site totals
+func (cp *GoRoutineChurnPeer) ProcessChurnInfo(info ds.GoroutineChurnInfo) {
+	cp.lock.Lock()
+	defer cp.lock.Unlock()
+
+	cp.reports.Write(info)
+	intervalMs := info.Ts - info.SinceTs
+	for _, cs := range info.CallSites {
+		site := cp.totals[cs.CallSite]
+		if site == nil {
+			site = &rpctypes.GoRoutineChurnSite{CallSite: cs.CallSite}
+			cp.totals[cs.CallSite] = site
+		}
+		if cs.Func != "" {
+			site.Func = cs.Func
+		}
+		if cs.Name != "" {
+			site.Name = cs.Name
+		}
+		site.Started += cs.Started
+		site.Finished += cs.Finished
+		site.ShortLived += cs.ShortLived
+		site.LastTs = info.Ts
+		cp.runTime[cs.CallSite] += cs.RunTimeMs
+		if intervalMs >= minChurnPeakIntervalMs {
+			site.PeakStartRate = max(site.PeakStartRate, float64(cs.Started)*1000/float64(intervalMs))
+		}
+	}
+}
+
+// GetChurnSites returns the churn of all the call sites (highest current start rate first).
+// The start rates are computed over the GoRoutineChurnRateWindowMs before nowTs (the latest goroutine poll).
+func (cp *GoRoutineChurnPeer) GetChurnSites(nowTs int64) []rpctypes.GoRoutineChurnSite {
+	cp.lock.RLock()
+	defer cp.lock.RUnlock()
+
+	windowStart := nowTs - GoRoutineChurnRateWindowMs
+	recentStarted := make(map[string]int64)
+	firstTs := int64(0)
+	cp.reports.ForEach(func(info ds.GoroutineChurnInfo) bool {
+		if info.Ts <= windowStart {
+			return true
+		}
+		if firstTs == 0 {
+			firstTs = info.SinceTs
+		}
+		for _, cs := range info.CallSites {
+			recentStarted[cs.CallSite] += cs.Started
+		}
+		return true
+	})
+	// the window is shorter for app runs that just started reporting
+	windowMs := int64(GoRoutineChurnRateWindowMs)
+	if firstTs > windowStart {
+		windowMs = nowTs - firstTs
+	}
+
+	rtn := make([]rpctypes.GoRoutineChurnSite, 0, len(cp.totals))
+	for callSite, site := range cp.totals {
+		siteCopy := *site
+		if siteCopy.Finished > 0 {
+			siteCopy.AvgRunTimeMs = float64(cp.runTime[callSite]) / float64(siteCopy.Finished)
+		}
+		if windowMs > 0 {
+			siteCopy.StartRate = float64(recentStarted[callSite]) * 1000 / float64(windowMs)
+		}
+		rtn = append(rtn, siteCopy)
+	}
+	sort.Slice(rtn, func(i, j int) bool {
+		if rtn[i].StartRate != rtn[j].StartRate {
+			return rtn[i].StartRate > rtn[j].StartRate
+		}
+		if rtn[i].Started != rtn[j].Started {
+			return rtn[i].Started > rtn[j].Started
+		}
+		return rtn[i].CallSite < rtn[j].CallSite
+	})
+	return rtn
+}
+
+// GetGoRoutineChurnSites returns the goroutine churn by call site, the start rates are as of the latest goroutine poll
+func (p *AppRunPeer) GetGoRoutineChurnSites() []rpctypes.GoRoutineChurnSite {
+	return p.GoRoutineChurn.GetChurnSites(p.GoRoutines.getTimeSpan().End)
+}
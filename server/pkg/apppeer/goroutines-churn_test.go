@@ -0,0 +1,57 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func TestGoRoutineChurnPeer(t *testing.T) {
+	cp := MakeGoRoutineChurnPeer()
+	if sites := cp.GetChurnSites(1000); len(sites) != 0 {
+		t.Fatalf("got %+v, want no call sites", sites)
+	}
+
+	cp.ProcessChurnInfo(ds.GoroutineChurnInfo{SinceTs: 1000, Ts: 2000, CallSites: []ds.GoroutineChurnCallSite{
+		{CallSite: "a.go:1", Func: "main.a", Started: 4, Finished: 2, ShortLived: 2, RunTimeMs: 100},
+		{CallSite: "b.go:1", Started: 1},
+	}})
+	cp.ProcessChurnInfo(ds.GoroutineChurnInfo{SinceTs: 2000, Ts: 3000, CallSites: []ds.GoroutineChurnCallSite{
+		{CallSite: "a.go:1", Name: "worker", Started: 2, Finished: 2, RunTimeMs: 300},
+		{CallSite: "b.go:1", Started: 20},
+		{CallSite: "c.go:1", Started: 1},
+	}})
+	// a report far in the past of the rate window
+	sites := cp.GetChurnSites(3000 + GoRoutineChurnRateWindowMs)
+	if len(sites) != 3 {
+		t.Fatalf("got %d call sites, want 3", len(sites))
+	}
+	for _, site := range sites {
+		if site.StartRate != 0 {
+			t.Errorf("%s: got start rate %v outside of the rate window", site.CallSite, site.StartRate)
+		}
+	}
+	// sorted by total started when the rates are equal
+	if sites[0].CallSite != "b.go:1" || sites[1].CallSite != "a.go:1" || sites[2].CallSite != "c.go:1" {
+		t.Errorf("got order %s %s %s", sites[0].CallSite, sites[1].CallSite, sites[2].CallSite)
+	}
+	a := sites[1]
+	if a.Func != "main.a" || a.Name != "worker" || a.Started != 6 || a.Finished != 4 || a.ShortLived != 2 || a.AvgRunTimeMs != 100 || a.LastTs != 3000 {
+		t.Errorf("got %+v for a.go:1", a)
+	}
+	if sites[0].PeakStartRate != 20 {
+		t.Errorf("got peak rate %v, want 20/s", sites[0].PeakStartRate)
+	}
+
+	// both reports are in the window, which is shorter than GoRoutineChurnRateWindowMs since the run just started
+	sites = cp.GetChurnSites(3000)
+	if sites[0].CallSite != "b.go:1" || sites[0].StartRate != 10.5 {
+		t.Errorf("got %+v, want b.go:1 first at 10.5/s", sites[0])
+	}
+	if sites[1].CallSite != "a.go:1" || sites[1].StartRate != 3 {
+		t.Errorf("got %+v, want a.go:1 second at 3/s", sites[1])
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// GroupGoRoutines groups goroutines by (start function, creation site, CSNum) so that goroutines started from
+// the same place (e.g. a pool of workers) collapse into a single group. The workers of an outrig.Pool are grouped by
+// pool (wherever they were started) so the pool gets a single rollup row. The lowest goid in a group is its representative.
+// Groups are sorted by size (largest first), then by key.
+func GroupGoRoutines(grs []rpctypes.ParsedGoRoutine) []rpctypes.GoRoutineGroup {
+	groupMap := make(map[string]*rpctypes.GoRoutineGroup)
+	for _, gr := range grs {
+		key, pkg, funcName := goRoutineGroupKey(gr)
+		group := groupMap[key]
+		if group == nil {
+			group = &rpctypes.GoRoutineGroup{
+				Key:              key,
+				Package:          pkg,
+				FuncName:         funcName,
+				CreatedByFrame:   gr.CreatedByFrame,
+				CSNum:            gr.CSNum,
+				Pool:             gr.Pool,
+				StateCounts:      make(map[string]int),
+				RepresentativeId: gr.GoId,
+			}
+			groupMap[key] = group
+		}
+		group.Count++
+		group.GoIds = append(group.GoIds, gr.GoId)
+		if gr.PrimaryState != "" {
+			group.StateCounts[gr.PrimaryState]++
+		}
+		if gr.GoId < group.RepresentativeId {
+			group.RepresentativeId = gr.GoId
+		}
+	}
+
+	groups := make([]rpctypes.GoRoutineGroup, 0, len(groupMap))
+	for _, group := range groupMap {
+		sort.Slice(group.GoIds, func(i, j int) bool {
+			return group.GoIds[i] < group.GoIds[j]
+		})
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+// goRoutineGroupKey returns the group key plus the package and name of the goroutine's start function
+func goRoutineGroupKey(gr rpctypes.ParsedGoRoutine) (string, string, string) {
+	var pkg, funcName string
+	if len(gr.ParsedFrames) > 0 {
+		startFrame := gr.ParsedFrames[len(gr.ParsedFrames)-1]
+		pkg, funcName = startFrame.Package, startFrame.FuncName
+	}
+	if gr.Pool != "" {
+		return "pool:" + gr.Pool, pkg, funcName
+	}
+	createdBy := ""
+	if gr.CreatedByFrame != nil {
+		createdBy = fmt.Sprintf("%s.%s@%s:%d", gr.CreatedByFrame.Package, gr.CreatedByFrame.FuncName, gr.CreatedByFrame.FilePath, gr.CreatedByFrame.LineNumber)
+	}
+	if pkg == "" && createdBy == "" {
+		// unparsed stack, don't merge it with anything else
+		return fmt.Sprintf("goid:%d", gr.GoId), "", ""
+	}
+	return fmt.Sprintf("%s.%s|%s|%d", pkg, funcName, createdBy, gr.CSNum), pkg, funcName
+}
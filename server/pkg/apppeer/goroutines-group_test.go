@@ -0,0 +1,81 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+func makeTestGroupGoRoutine(goId int64, funcName string, createdByLine int, state string) rpctypes.ParsedGoRoutine {
+	gr := rpctypes.ParsedGoRoutine{
+		GoId:         goId,
+		PrimaryState: state,
+		ParsedFrames: []rpctypes.StackFrame{
+			{Package: "runtime", FuncName: "gopark"},
+			{Package: "example.com/app", FuncName: funcName},
+		},
+	}
+	if createdByLine > 0 {
+		gr.CreatedByFrame = &rpctypes.StackFrame{Package: "example.com/app", FuncName: "main", FilePath: "/app/main.go", LineNumber: createdByLine}
+	}
+	return gr
+}
+
+func TestGroupGoRoutines(t *testing.T) {
+	pooled := makeTestGroupGoRoutine(20, "handle", 30, "running")
+	pooled.Pool = "handlers"
+	pooledElsewhere := makeTestGroupGoRoutine(21, "handle", 40, "select")
+	pooledElsewhere.Pool = "handlers"
+	secondCallSite := makeTestGroupGoRoutine(6, "worker", 10, "chan receive")
+	secondCallSite.CSNum = 1
+	grs := []rpctypes.ParsedGoRoutine{
+		makeTestGroupGoRoutine(5, "worker", 10, "chan receive"),
+		makeTestGroupGoRoutine(3, "worker", 10, "running"),
+		makeTestGroupGoRoutine(4, "worker", 10, "chan receive"),
+		secondCallSite,
+		makeTestGroupGoRoutine(8, "worker", 12, "running"),
+		pooled,
+		pooledElsewhere,
+		{GoId: 9, RawStackTrace: "unparsed"},
+		{GoId: 10, RawStackTrace: "unparsed"},
+	}
+	groups := GroupGoRoutines(grs)
+
+	var keys []string
+	for _, group := range groups {
+		keys = append(keys, group.Key)
+	}
+	// largest group first, then by key (each unparsed goroutine is a group of its own)
+	expectKeys := []string{
+		"example.com/app.worker|example.com/app.main@/app/main.go:10|0",
+		"pool:handlers",
+		"example.com/app.worker|example.com/app.main@/app/main.go:10|1",
+		"example.com/app.worker|example.com/app.main@/app/main.go:12|0",
+		"goid:10",
+		"goid:9",
+	}
+	if !reflect.DeepEqual(keys, expectKeys) {
+		t.Fatalf("got groups %q, want %q", keys, expectKeys)
+	}
+
+	workers := groups[0]
+	if workers.Count != 3 || !reflect.DeepEqual(workers.GoIds, []int64{3, 4, 5}) || workers.RepresentativeId != 3 {
+		t.Errorf("got count %d, goids %v, representative %d, want the 3 workers with the lowest goid", workers.Count, workers.GoIds, workers.RepresentativeId)
+	}
+	if !reflect.DeepEqual(workers.StateCounts, map[string]int{"chan receive": 2, "running": 1}) {
+		t.Errorf("got state counts %v", workers.StateCounts)
+	}
+	if workers.Package != "example.com/app" || workers.FuncName != "worker" {
+		t.Errorf("got start function %s.%s, want example.com/app.worker", workers.Package, workers.FuncName)
+	}
+	if pool := groups[1]; pool.Pool != "handlers" || pool.Count != 2 || pool.RepresentativeId != 20 {
+		t.Errorf("got pool group %+v, want the pool's workers from both call sites", pool)
+	}
+	if unparsed := groups[4]; len(unparsed.StateCounts) != 0 || unparsed.FuncName != "" {
+		t.Errorf("got %+v for an unparsed goroutine", unparsed)
+	}
+}
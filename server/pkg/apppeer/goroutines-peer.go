@@ -5,7 +5,6 @@ package apppeer
 
 import (
 	"fmt"
-	"maps"
 	"slices"
 	"sort"
 	"strings"
@@ -13,6 +12,7 @@ import (
 	"sync/atomic"
 
 	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/stackparse"
 	"github.com/outrigdev/outrig/pkg/utilds"
 	"github.com/outrigdev/outrig/pkg/utilfn"
 	"github.com/outrigdev/outrig/server/pkg/logutil"
@@ -49,6 +49,8 @@ type GoRoutinePeer struct {
 	timeSpan          rpctypes.TimeSpan                               // Time range for goroutine collections
 	timeAligner       *utilds.TimeSampleAligner                       // Aligns goroutine stack timestamps to logical indices
 	droppedCount      atomic.Int64                                    // Count of goroutines dropped during pruning (synchronized with atomic operations)
+	pendingSpans      map[int64]bool                                  // Goroutines with an exact start/end after the last sample (indexes are resolved by later samples)
+	stateCounts       *utilds.CirBuf[goRoutineStateCounts]            // Primary state counts by logical time
 }
 
 // GoRoutinesAtTimestampResult contains the result of GetParsedGoRoutinesAtTimestamp
@@ -84,6 +86,8 @@ func MakeGoRoutinePeer(appRunId string) *GoRoutinePeer {
 		maxGoId:          0,
 		appRunId:         appRunId,
 		timeAligner:      utilds.MakeTimeSampleAligner(GoRoutineStackBufferSize),
+		pendingSpans:     make(map[int64]bool),
+		stateCounts:      utilds.MakeCirBuf[goRoutineStateCounts](GoRoutineStackBufferSize),
 	}
 }
 
@@ -128,8 +132,10 @@ func (gp *GoRoutinePeer) updateTimeSpanMap(goId int64, goroutine GoRoutine) {
 	}
 }
 
-// ProcessGoroutineStacks processes goroutine stacks from a packet
-func (gp *GoRoutinePeer) ProcessGoroutineStacks(info ds.GoroutineInfo) {
+// ProcessGoroutineStacks processes goroutine stacks from a packet. It returns true if the packet was a delta
+// update the server couldn't apply (no full update seen, or a missing base stack), the SDK then needs to
+// send a full update (see AppRunPeer.requestResync).
+func (gp *GoRoutinePeer) ProcessGoroutineStacks(info ds.GoroutineInfo) bool {
 	gp.lock.Lock()
 	defer gp.lock.Unlock()
 
@@ -144,12 +150,13 @@ func (gp *GoRoutinePeer) ProcessGoroutineStacks(info ds.GoroutineInfo) {
 	logicalTime, err := gp.timeAligner.AddSample(timestamp)
 	if err != nil {
 		fmt.Printf("WARNING: [AppRun: %s] TimeSampleAligner error: %v\n", gp.appRunId, err)
-		return // Drop this sample
+		return false // Drop this sample
 	}
 	firstSampleTs := gp.timeAligner.GetFirstTimestamp()
 
 	// Set the version to match the logical time
 	gp.timeSpanMap.SetVersion(int64(logicalTime))
+	gp.resolvePendingSpans(timestamp)
 
 	// Update the overall TimeSpan for goroutine collections
 	if gp.timeSpan.Start == 0 || timestamp < gp.timeSpan.Start {
@@ -164,13 +171,14 @@ func (gp *GoRoutinePeer) ProcessGoroutineStacks(info ds.GoroutineInfo) {
 	// If this is a delta update but we haven't seen a full update yet, ignore it
 	if isDelta && !gp.hasSeenFullUpdate {
 		fmt.Printf("WARNING: [AppRun: %s] Ignoring delta update because no full update has been seen yet\n", gp.appRunId)
-		return
+		return true
 	}
 
 	// If this is a full update, mark that we've seen one
 	if !isDelta {
 		gp.hasSeenFullUpdate = true
 	}
+	var needsResync bool
 
 	// Process goroutine declarations first
 	for _, decl := range info.Decls {
@@ -203,17 +211,22 @@ func (gp *GoRoutinePeer) ProcessGoroutineStacks(info ds.GoroutineInfo) {
 				decl.StartTs = firstSampleTs
 			}
 			goroutine.TimeSpan.Start = decl.StartTs
-			goroutine.TimeSpan.StartIdx = gp.timeAligner.GetLogicalTimeFromRealTimestamp(decl.StartTs)
 			goroutine.TimeSpan.Exact = true
 		}
 
-		// If GoDecl has EndTs set, this is the exact end time for the goroutine
+		// If GoDecl has EndTs set, this is the exact end time for the goroutine (recorded when it returned,
+		// so goroutines that start and finish between two samples still get a span)
 		if decl.EndTs != 0 {
 			if decl.EndTs < firstSampleTs {
 				decl.EndTs = firstSampleTs
 			}
 			goroutine.TimeSpan.End = decl.EndTs
-			goroutine.TimeSpan.EndIdx = gp.timeAligner.GetLogicalTimeFromRealTimestamp(decl.EndTs)
+		}
+		if decl.StartTs != 0 || decl.EndTs != 0 {
+			gp.setSpanIdxs(&goroutine.TimeSpan)
+			if goroutine.TimeSpan.Start > timestamp || goroutine.TimeSpan.End > timestamp {
+				gp.pendingSpans[goId] = true
+			}
 		}
 
 		// If GoDecl has RealCreatedBy set, extract created by information
@@ -227,6 +240,7 @@ func (gp *GoRoutinePeer) ProcessGoroutineStacks(info ds.GoroutineInfo) {
 	}
 
 	// Process goroutine stacks
+	stateCounts := goRoutineStateCounts{counts: make(map[string]int), outrigCounts: make(map[string]int)}
 	for _, stack := range info.Stacks {
 		goId := stack.GoId
 
@@ -253,7 +267,7 @@ func (gp *GoRoutinePeer) ProcessGoroutineStacks(info ds.GoroutineInfo) {
 		// Set CreatedByGoId and CreatedByFrame from the first stack trace we see for this goroutine
 		if goroutine.CreatedByGoId == 0 && goroutine.CreatedByFrame == nil && stack.StackTrace != "" {
 			// Parse the stack trace to extract creation information
-			if parsedGoRoutine, err := stacktrace.ParseGoRoutineStackTrace(stack.StackTrace, "", stack.GoId, stack.State); err == nil {
+			if parsedGoRoutine, err := stacktrace.ParseGoRoutineStackTrace(stack.StackTrace, stacktrace.ModuleInfo{}, stack.GoId, stack.State); err == nil {
 				if parsedGoRoutine.CreatedByGoId != 0 {
 					goroutine.CreatedByGoId = parsedGoRoutine.CreatedByGoId
 				}
@@ -274,13 +288,16 @@ func (gp *GoRoutinePeer) ProcessGoroutineStacks(info ds.GoroutineInfo) {
 			if exists {
 				completeStack := mergeGoRoutineStacks(lastStack, stack)
 				goroutine.StackTraces.WriteAt(completeStack, logicalTime)
+				stateCounts.add(completeStack.State, goroutine.Tags)
 			} else {
 				logKey := fmt.Sprintf("goroutine-nodeltaupdate-%s", gp.appRunId)
 				logutil.LogfOnce(logKey, "WARNING: [AppRun: %s] Delta update received for goroutine %d with no last stack\n", gp.appRunId, goId)
+				needsResync = true
 			}
 		} else {
 			// full updates write the stack directly
 			goroutine.StackTraces.WriteAt(stack, logicalTime)
+			stateCounts.add(stack.State, goroutine.Tags)
 		}
 
 		gp.goRoutines.Set(goId, goroutine)
@@ -288,6 +305,8 @@ func (gp *GoRoutinePeer) ProcessGoroutineStacks(info ds.GoroutineInfo) {
 		gp.updateTimeSpanMap(goId, goroutine)
 	}
 
+	gp.stateCounts.WriteAt(stateCounts, logicalTime)
+
 	// Check for goroutines that should be marked as ended:
 	// 1. Previously active goroutines no longer in current active set
 	// 2. Goroutines with StartTs before current timestamp but not in current active set
@@ -317,6 +336,36 @@ func (gp *GoRoutinePeer) ProcessGoroutineStacks(info ds.GoroutineInfo) {
 	gp.activeGoRoutines = activeGoroutines
 
 	gp.pruneOldGoroutines()
+	return needsResync
+}
+
+// setSpanIdxs sets the logical indexes of a goroutine time span from its start/end timestamps.
+// A goroutine is active at a sample if it was running at any time since the previous sample, so the
+// timestamps are mapped to the first sample at or after them (a goroutine that starts and ends between
+// two samples is active at the later sample). Times after the last sample map to the next sample.
+func (gp *GoRoutinePeer) setSpanIdxs(span *rpctypes.TimeSpan) {
+	span.StartIdx = gp.timeAligner.GetLogicalTimeAtOrAfter(span.Start)
+	if span.End != -1 {
+		span.EndIdx = max(gp.timeAligner.GetLogicalTimeAtOrAfter(span.End), span.StartIdx)
+	}
+}
+
+// resolvePendingSpans recomputes the indexes of the exact time spans that were after the last sample
+// (their index was a guess until a sample at or after them arrived)
+func (gp *GoRoutinePeer) resolvePendingSpans(timestamp int64) {
+	for goId := range gp.pendingSpans {
+		goroutine, exists := gp.goRoutines.GetEx(goId)
+		if !exists {
+			delete(gp.pendingSpans, goId)
+			continue
+		}
+		gp.setSpanIdxs(&goroutine.TimeSpan)
+		if goroutine.TimeSpan.Start <= timestamp && goroutine.TimeSpan.End <= timestamp {
+			delete(gp.pendingSpans, goId)
+		}
+		gp.goRoutines.Set(goId, goroutine)
+		gp.updateTimeSpanMap(goId, goroutine)
+	}
 }
 
 // pruneOldGoroutines removes goroutines that haven't been active for more than GoRoutinePruneThreshold iterations
@@ -396,7 +445,7 @@ func (gp *GoRoutinePeer) GetGoRoutineCounts() (int, int, int) {
 // If timestamp is provided, returns all goroutines that were active at that timestamp by finding
 // the stack trace with the largest timestamp <= the provided timestamp
 // If activeOnly is false, returns all goroutines regardless of active status
-func (gp *GoRoutinePeer) GetParsedGoRoutinesAtTimestamp(moduleName string, timestamp int64, activeOnly bool) GoRoutinesAtTimestampResult {
+func (gp *GoRoutinePeer) GetParsedGoRoutinesAtTimestamp(modInfo stacktrace.ModuleInfo, timestamp int64, activeOnly bool) GoRoutinesAtTimestampResult {
 	gp.lock.RLock()
 	defer gp.lock.RUnlock()
 
@@ -422,16 +471,9 @@ func (gp *GoRoutinePeer) GetParsedGoRoutinesAtTimestamp(moduleName string, times
 		}
 	}
 
-	var goroutineIds []int64
-	if activeOnly && timestamp == 0 {
-		// If activeOnly and timestamp is 0, use currently active goroutines
-		activeGoRoutinesCopy := gp.getActiveGoRoutinesCopy()
-		goroutineIds = slices.Collect(maps.Keys(activeGoRoutinesCopy))
-	} else {
-		// For all other cases: use all goroutines (either activeOnly with timestamp, or not activeOnly)
-		goroutineIds = allGoroutineIds
-	}
-	parsedGoRoutines := gp.getParsedGoRoutinesAtTimestamp_nolock(moduleName, goroutineIds, timestamp, activeOnly)
+	// all goroutines are checked (even for the latest timestamp), goroutines that started and finished
+	// since the previous sample are not in the active set but were active at the latest sample
+	parsedGoRoutines := gp.getParsedGoRoutinesAtTimestamp_nolock(modInfo, allGoroutineIds, timestamp, activeOnly)
 
 	return GoRoutinesAtTimestampResult{
 		GoRoutines:         parsedGoRoutines,
@@ -442,7 +484,7 @@ func (gp *GoRoutinePeer) GetParsedGoRoutinesAtTimestamp(moduleName string, times
 }
 
 // createParsedGoRoutine creates a ParsedGoRoutine from a GoRoutine and stack trace
-func (gp *GoRoutinePeer) createParsedGoRoutine(goroutineObj GoRoutine, stack *ds.GoRoutineStack, moduleName string, isActive bool) (rpctypes.ParsedGoRoutine, error) {
+func (gp *GoRoutinePeer) createParsedGoRoutine(goroutineObj GoRoutine, stack *ds.GoRoutineStack, modInfo stacktrace.ModuleInfo, isActive bool) (rpctypes.ParsedGoRoutine, error) {
 	var parsedGoRoutine rpctypes.ParsedGoRoutine
 	var err error
 
@@ -453,7 +495,7 @@ func (gp *GoRoutinePeer) createParsedGoRoutine(goroutineObj GoRoutine, stack *ds
 			PrimaryState: "inactive",
 		}
 	} else {
-		parsedGoRoutine, err = stacktrace.ParseGoRoutineStackTrace(stack.StackTrace, moduleName, stack.GoId, stack.State)
+		parsedGoRoutine, err = stacktrace.ParseGoRoutineStackTrace(stack.StackTrace, modInfo, stack.GoId, stack.State)
 		if err != nil {
 			return rpctypes.ParsedGoRoutine{}, err
 		}
@@ -461,16 +503,32 @@ func (gp *GoRoutinePeer) createParsedGoRoutine(goroutineObj GoRoutine, stack *ds
 
 	parsedGoRoutine.Name = goroutineObj.Name
 	parsedGoRoutine.Tags = goroutineObj.Tags
+	if stack != nil && stack.Labels != "" {
+		// pprof labels are searchable as "key:value" tags (goroutines not started with the SDK have no other tags)
+		if labelTags := stacktrace.LabelTags(stackparse.ParseLabels(stack.Labels)); len(labelTags) > 0 {
+			parsedGoRoutine.Tags = append(slices.Clone(goroutineObj.Tags), labelTags...)
+		}
+	}
 	parsedGoRoutine.Active = isActive
 
-	// Set CSNum from declaration if available
+	// Set CSNum, Pool, and Status from declaration if available
 	if goroutineObj.Decl != nil {
 		parsedGoRoutine.CSNum = goroutineObj.Decl.CSNum
+		parsedGoRoutine.Pool = goroutineObj.Decl.Pool
+		if isActive {
+			parsedGoRoutine.Status = goroutineObj.Decl.Status
+		}
 	}
 
 	// Set CreatedBy information from stored values
+	// (the stored frame is re-annotated, it was parsed without the module info)
 	parsedGoRoutine.CreatedByGoId = goroutineObj.CreatedByGoId
-	parsedGoRoutine.CreatedByFrame = goroutineObj.CreatedByFrame
+	parsedGoRoutine.CreatedByFrame = nil
+	if goroutineObj.CreatedByFrame != nil {
+		createdByFrame := *goroutineObj.CreatedByFrame
+		stacktrace.AnnotateFrame(&createdByFrame, modInfo)
+		parsedGoRoutine.CreatedByFrame = &createdByFrame
+	}
 
 	// Set the active time span
 	parsedGoRoutine.ActiveTimeSpan = goroutineObj.TimeSpan
@@ -479,7 +537,7 @@ func (gp *GoRoutinePeer) createParsedGoRoutine(goroutineObj GoRoutine, stack *ds
 }
 
 // getParsedGoRoutinesAtTimestamp_nolock is the internal implementation that assumes the lock is already held
-func (gp *GoRoutinePeer) getParsedGoRoutinesAtTimestamp_nolock(moduleName string, goroutineIds []int64, timestamp int64, activeOnly bool) []rpctypes.ParsedGoRoutine {
+func (gp *GoRoutinePeer) getParsedGoRoutinesAtTimestamp_nolock(modInfo stacktrace.ModuleInfo, goroutineIds []int64, timestamp int64, activeOnly bool) []rpctypes.ParsedGoRoutine {
 	effectiveTimestamp := timestamp
 	if effectiveTimestamp == 0 {
 		effectiveTimestamp = gp.timeSpan.End
@@ -496,8 +554,8 @@ func (gp *GoRoutinePeer) getParsedGoRoutinesAtTimestamp_nolock(moduleName string
 		logicalIndex := gp.timeAligner.GetLogicalTimeFromRealTimestamp(effectiveTimestamp)
 		bestStack, found := goroutineObj.StackTraces.GetAt(logicalIndex)
 
-		// Determine if goroutine is active at this timestamp
-		isActive := goroutineObj.TimeSpan.IsWithinSpanTs(effectiveTimestamp)
+		// Determine if goroutine is active at this timestamp (by index, see setSpanIdxs)
+		isActive := goroutineObj.TimeSpan.IsWithinSpanIdx(logicalIndex)
 		if activeOnly && !isActive {
 			continue
 		}
@@ -508,7 +566,7 @@ func (gp *GoRoutinePeer) getParsedGoRoutinesAtTimestamp_nolock(moduleName string
 			stackPtr = &bestStack
 		}
 
-		parsedGoRoutine, err := gp.createParsedGoRoutine(goroutineObj, stackPtr, moduleName, isActive)
+		parsedGoRoutine, err := gp.createParsedGoRoutine(goroutineObj, stackPtr, modInfo, isActive)
 		if err != nil {
 			continue
 		}
@@ -526,11 +584,11 @@ func (gp *GoRoutinePeer) getParsedGoRoutinesAtTimestamp_nolock(moduleName string
 }
 
 // GetParsedGoRoutinesByIds returns parsed goroutines for specific goroutine IDs
-func (gp *GoRoutinePeer) GetParsedGoRoutinesByIds(moduleName string, goIds []int64, timestamp int64) []rpctypes.ParsedGoRoutine {
+func (gp *GoRoutinePeer) GetParsedGoRoutinesByIds(modInfo stacktrace.ModuleInfo, goIds []int64, timestamp int64) []rpctypes.ParsedGoRoutine {
 	gp.lock.RLock()
 	defer gp.lock.RUnlock()
 
-	return gp.getParsedGoRoutinesAtTimestamp_nolock(moduleName, goIds, timestamp, false)
+	return gp.getParsedGoRoutinesAtTimestamp_nolock(modInfo, goIds, timestamp, false)
 }
 
 // getOutrigGoIds_nolock returns a map of goroutine IDs that are tagged with "outrig"
@@ -622,8 +680,8 @@ func (gp *GoRoutinePeer) parseRealCreatedBy(decl *ds.GoDecl, goroutine *GoRoutin
 		fileLine := strings.TrimSpace(lines[1])
 		frame, createdByGoId, ok := stacktrace.ParseCreatedByFrame(funcLine, fileLine)
 		if ok {
-			stacktrace.AnnotateFrame(frame, "")
-			goroutine.CreatedByGoId = int64(createdByGoId)
+			stacktrace.AnnotateFrame(frame, stacktrace.ModuleInfo{})
+			goroutine.CreatedByGoId = createdByGoId
 			goroutine.CreatedByFrame = frame
 		}
 	}
@@ -0,0 +1,91 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/outrigdev/outrig/pkg/stackparse"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// goRoutineStateCounts are the primary state counts of the goroutines sampled at a tick, they are computed as
+// each sample is processed (so the histogram doesn't have to re-parse the stored stacks). Outrig-tagged goroutines
+// are counted separately so they can be filtered out.
+type goRoutineStateCounts struct {
+	counts       map[string]int
+	outrigCounts map[string]int
+}
+
+func (sc goRoutineStateCounts) add(rawState string, tags []string) {
+	primaryState := stackparse.ParsePrimaryState(rawState)
+	if slices.Contains(tags, "outrig") {
+		sc.outrigCounts[primaryState]++
+	} else {
+		sc.counts[primaryState]++
+	}
+}
+
+// mergeStateCounts returns the state counts at a tick, including the outrig goroutines if showOutrig is set
+func mergeStateCounts(stateCounts goRoutineStateCounts, showOutrig bool) map[string]int {
+	if !showOutrig || len(stateCounts.outrigCounts) == 0 {
+		return stateCounts.counts
+	}
+	rtn := make(map[string]int, len(stateCounts.counts)+len(stateCounts.outrigCounts))
+	for state, count := range stateCounts.counts {
+		rtn[state] = count
+	}
+	for state, count := range stateCounts.outrigCounts {
+		rtn[state] += count
+	}
+	return rtn
+}
+
+// GetStateHistogramSinceTickIdx returns the goroutine primary state counts of the ticks after sinceTickIdx
+func (gp *GoRoutinePeer) GetStateHistogramSinceTickIdx(sinceTickIdx int64, showOutrig bool) rpctypes.GoRoutineStateHistogramResponse {
+	gp.lock.RLock()
+	defer gp.lock.RUnlock()
+
+	baseLogical, timestamps := gp.timeAligner.GetTimestamps()
+	startIdx := utilfn.BoundValue(int(sinceTickIdx)-baseLogical+1, 0, len(timestamps))
+	filteredTimestamps := timestamps[startIdx:]
+	baseLogical += startIdx
+
+	ticks := make([]rpctypes.GoRoutineStateTick, 0, len(filteredTimestamps))
+	stateTotals := make(map[string]int)
+	for i, ts := range filteredTimestamps {
+		logicalIdx := baseLogical + i
+		tick := rpctypes.GoRoutineStateTick{TimeIdx: logicalIdx, Ts: ts}
+		if stateCounts, ok := gp.stateCounts.GetAt(logicalIdx); ok {
+			tick.StateCounts = mergeStateCounts(stateCounts, showOutrig)
+		}
+		for state, count := range tick.StateCounts {
+			stateTotals[state] += count
+		}
+		ticks = append(ticks, tick)
+	}
+
+	states := make([]string, 0, len(stateTotals))
+	for state := range stateTotals {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if stateTotals[states[i]] != stateTotals[states[j]] {
+			return stateTotals[states[i]] > stateTotals[states[j]]
+		}
+		return states[i] < states[j]
+	})
+
+	maxLogicalTime := gp.timeAligner.GetMaxLogicalTime()
+	return rpctypes.GoRoutineStateHistogramResponse{
+		Ticks:  ticks,
+		States: states,
+		LastTick: rpctypes.Tick{
+			Idx: maxLogicalTime,
+			Ts:  gp.timeAligner.GetRealTimestampFromLogical(maxLogicalTime),
+		},
+	}
+}
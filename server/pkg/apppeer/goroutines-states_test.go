@@ -0,0 +1,56 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+func TestGetStateHistogramSinceTickIdx(t *testing.T) {
+	gp := MakeGoRoutinePeer("test-apprun")
+	gp.ProcessGoroutineStacks(ds.GoroutineInfo{Ts: 1000, Count: 3, Stacks: []ds.GoRoutineStack{
+		{GoId: 1, Ts: 1000, State: "running"},
+		{GoId: 2, Ts: 1000, State: "chan receive, 5 minutes"},
+		{GoId: 3, Ts: 1000, State: "select", Tags: []string{"outrig"}},
+	}})
+	gp.ProcessGoroutineStacks(ds.GoroutineInfo{Ts: 2000, Count: 2, Stacks: []ds.GoRoutineStack{
+		{GoId: 1, Ts: 2000, State: "running"},
+		{GoId: 2, Ts: 2000, State: "running"},
+	}})
+
+	all := gp.GetStateHistogramSinceTickIdx(-1, false)
+	expectTicks := []rpctypes.GoRoutineStateTick{
+		{TimeIdx: 0, Ts: 1000, StateCounts: map[string]int{"running": 1, "chan receive": 1}},
+		{TimeIdx: 1, Ts: 2000, StateCounts: map[string]int{"running": 2}},
+	}
+	if !reflect.DeepEqual(all.Ticks, expectTicks) {
+		t.Errorf("got ticks %+v, want %+v", all.Ticks, expectTicks)
+	}
+	if !reflect.DeepEqual(all.States, []string{"running", "chan receive"}) {
+		t.Errorf("got states %v, want the most common state first", all.States)
+	}
+	if all.LastTick != (rpctypes.Tick{Idx: 1, Ts: 2000}) {
+		t.Errorf("got last tick %+v", all.LastTick)
+	}
+
+	// the outrig goroutines are only counted with showOutrig (ties are sorted by name)
+	withOutrig := gp.GetStateHistogramSinceTickIdx(-1, true)
+	if withOutrig.Ticks[0].StateCounts["select"] != 1 || !reflect.DeepEqual(withOutrig.States, []string{"running", "chan receive", "select"}) {
+		t.Errorf("got %+v with the outrig goroutines", withOutrig)
+	}
+
+	// only the ticks after sinceTickIdx are returned
+	since := gp.GetStateHistogramSinceTickIdx(0, false)
+	if !reflect.DeepEqual(since.Ticks, expectTicks[1:]) || !reflect.DeepEqual(since.States, []string{"running"}) {
+		t.Errorf("got %+v since tick 0", since)
+	}
+	latest := gp.GetStateHistogramSinceTickIdx(1, false)
+	if len(latest.Ticks) != 0 || len(latest.States) != 0 || latest.LastTick.Idx != 1 {
+		t.Errorf("got %+v since the last tick, want no ticks", latest)
+	}
+}
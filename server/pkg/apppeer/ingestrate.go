@@ -0,0 +1,73 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"sync"
+	"time"
+)
+
+// IngestRateInterval is how often the ingestion counters of the app runs are sampled
+const IngestRateInterval = 5 * time.Second
+
+// IngestRate is the rate at which an app run's data arrived over the last sampling interval
+type IngestRate struct {
+	BytesPerSec   float64 `json:"bytespersec"`
+	PacketsPerSec float64 `json:"packetspersec"`
+	LogsPerSec    float64 `json:"logspersec"`
+	TotalBytes    int64   `json:"totalbytes"`
+	TotalPackets  int64   `json:"totalpackets"`
+}
+
+type ingestSample struct {
+	ts      time.Time
+	bytes   int64
+	packets int64
+	logs    int
+}
+
+type ingestRateTracker struct {
+	lock sync.Mutex
+	last ingestSample
+	rate IngestRate
+}
+
+// SampleIngestRates updates the ingestion rates of all app runs (called every IngestRateInterval)
+func SampleIngestRates() {
+	now := time.Now()
+	for _, peer := range GetAllAppRunPeers() {
+		peer.sampleIngestRate(now)
+	}
+}
+
+func (p *AppRunPeer) sampleIngestRate(now time.Time) {
+	cur := ingestSample{
+		ts:      now,
+		bytes:   p.TotalBytesReceived.Load(),
+		packets: p.TotalPacketsReceived.Load(),
+		logs:    p.Logs.GetTotalCount(),
+	}
+	t := &p.ingestRate
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !t.last.ts.IsZero() {
+		secs := cur.ts.Sub(t.last.ts).Seconds()
+		if secs > 0 {
+			t.rate.BytesPerSec = float64(cur.bytes-t.last.bytes) / secs
+			t.rate.PacketsPerSec = float64(cur.packets-t.last.packets) / secs
+			t.rate.LogsPerSec = float64(cur.logs-t.last.logs) / secs
+		}
+	}
+	t.last = cur
+}
+
+// GetIngestRate returns the ingestion rate from the last sampling interval along with the running totals
+func (p *AppRunPeer) GetIngestRate() IngestRate {
+	p.ingestRate.lock.Lock()
+	rate := p.ingestRate.rate
+	p.ingestRate.lock.Unlock()
+	rate.TotalBytes = p.TotalBytesReceived.Load()
+	rate.TotalPackets = p.TotalPacketsReceived.Load()
+	return rate
+}
@@ -0,0 +1,83 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"sync"
+
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+const LifecycleBufferSize = 1000
+
+// LifecyclePeer stores the lifecycle timeline (connects, disconnects, crashes) of an AppRunPeer
+type LifecyclePeer struct {
+	appRunId  string
+	routeId   string // app run route id (set when the AppInfo arrives), lets subscribers use "app:<appname>:*" scopes
+	events    []rpctypes.AppLifecycleEvent
+	connected bool // true after app:connected until the matching app:disconnected
+	lock      sync.Mutex
+}
+
+// MakeLifecyclePeer creates a new LifecyclePeer instance
+func MakeLifecyclePeer(appRunId string) *LifecyclePeer {
+	return &LifecyclePeer{appRunId: appRunId}
+}
+
+// SetRouteId sets the app run route id that is added to the scopes of the published events
+func (lp *LifecyclePeer) SetRouteId(routeId string) {
+	lp.lock.Lock()
+	defer lp.lock.Unlock()
+	lp.routeId = routeId
+}
+
+// RecordEvent adds an event to the timeline and publishes it to subscribers
+// (scoped by app run id and by app run route id)
+func (lp *LifecyclePeer) RecordEvent(event rpctypes.AppLifecycleEvent) {
+	event.AppRunId = lp.appRunId
+	scopes := []string{lp.appRunId}
+	lp.lock.Lock()
+	if lp.routeId != "" {
+		scopes = append(scopes, lp.routeId)
+	}
+	switch event.Event {
+	case rpctypes.Event_AppConnected:
+		lp.connected = true
+	case rpctypes.Event_AppDisconnected:
+		lp.connected = false
+	}
+	lp.events = append(lp.events, event)
+	if len(lp.events) > LifecycleBufferSize {
+		// always keep the first event (the initial connect)
+		lp.events = append(lp.events[:1], lp.events[len(lp.events)-LifecycleBufferSize+1:]...)
+	}
+	lp.lock.Unlock()
+
+	rpc.Broker.Publish(rpctypes.EventType{
+		Event:  event.Event,
+		Scopes: scopes,
+		Data:   event,
+	})
+}
+
+// IsConnected returns true if the app has connected and has not disconnected since
+func (lp *LifecyclePeer) IsConnected() bool {
+	lp.lock.Lock()
+	defer lp.lock.Unlock()
+	return lp.connected
+}
+
+// GetEvents returns the timeline events with a timestamp greater than sinceTs (oldest first)
+func (lp *LifecyclePeer) GetEvents(sinceTs int64) []rpctypes.AppLifecycleEvent {
+	lp.lock.Lock()
+	defer lp.lock.Unlock()
+	rtn := make([]rpctypes.AppLifecycleEvent, 0, len(lp.events))
+	for _, event := range lp.events {
+		if event.Ts > sinceTs {
+			rtn = append(rtn, event)
+		}
+	}
+	return rtn
+}
@@ -4,33 +4,141 @@
 package apppeer
 
 import (
+	"iter"
+	"log"
+	"os"
+	"slices"
 	"strings"
 	"sync"
 
 	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/utilds"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/disklogbuf"
 	"github.com/outrigdev/outrig/server/pkg/gensearch"
+	"github.com/outrigdev/outrig/server/pkg/loglineparser"
+	"github.com/outrigdev/outrig/server/pkg/searchparser"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
 )
 
 const LogLineBufferSize = gensearch.LogLineBufferSize
 
+// logLineStore holds the log lines for a LogLinePeer (in memory or on disk)
+type logLineStore interface {
+	Write(line ds.LogLine) error
+	All() (iter.Seq[ds.LogLine], int)
+	AllInRange(startTs int64, endTs int64) (iter.Seq[ds.LogLine], int) // inclusive, 0 means unbounded
+	AllAt(positions []int) iter.Seq[ds.LogLine]                        // ascending absolute positions, dropped lines are skipped
+	GetTotalCountAndHeadOffset() (int, int)
+	Close() error
+}
+
+// memLogLineStore keeps the last LogLineBufferSize lines in memory
+type memLogLineStore struct {
+	buf *utilds.CirBuf[ds.LogLine]
+}
+
+func (ms *memLogLineStore) Write(line ds.LogLine) error {
+	ms.buf.Write(line)
+	return nil
+}
+
+func (ms *memLogLineStore) All() (iter.Seq[ds.LogLine], int) {
+	lines, headOffset := ms.buf.GetAll()
+	return slices.Values(lines), len(lines) + headOffset
+}
+
+func (ms *memLogLineStore) AllInRange(startTs int64, endTs int64) (iter.Seq[ds.LogLine], int) {
+	lines, headOffset := ms.buf.GetAll()
+	seq := func(yield func(ds.LogLine) bool) {
+		for _, line := range lines {
+			if !disklogbuf.InRange(line.Ts, startTs, endTs) {
+				continue
+			}
+			if !yield(line) {
+				return
+			}
+		}
+	}
+	return seq, len(lines) + headOffset
+}
+
+func (ms *memLogLineStore) AllAt(positions []int) iter.Seq[ds.LogLine] {
+	return func(yield func(ds.LogLine) bool) {
+		for _, pos := range positions {
+			line, ok := ms.buf.GetAt(pos)
+			if !ok {
+				continue
+			}
+			if !yield(line) {
+				return
+			}
+		}
+	}
+}
+
+func (ms *memLogLineStore) GetTotalCountAndHeadOffset() (int, int) {
+	return ms.buf.GetTotalCountAndHeadOffset()
+}
+
+func (ms *memLogLineStore) Close() error {
+	return nil
+}
+
+// makeLogLineStore creates a disk-backed store (if enabled), falling back to memory if the buffer can't be created.
+// Each disk buffer gets its own directory, a dropped buffer that is still being read removes its directory
+// later (see DiskLogBuf.Close), which must not take the files of the app run's next buffer with it.
+func makeLogLineStore(appRunId string) logLineStore {
+	bufSizeMB := serverbase.GetRuntimeSettings().LogBufferSizeMB
+	if bufSizeMB > 0 && appRunId != "" {
+		diskBuf, err := makeDiskLogBuf(appRunId, bufSizeMB)
+		if err == nil {
+			return diskBuf
+		}
+		log.Printf("cannot create disk log buffer for app run %s, keeping logs in memory: %v\n", appRunId, err)
+	}
+	return &memLogLineStore{buf: utilds.MakeCirBuf[ds.LogLine](LogLineBufferSize)}
+}
+
+// makeDiskLogBuf creates the app run's disk buffer in a new directory under the log buffer directory
+func makeDiskLogBuf(appRunId string, bufSizeMB int) (*disklogbuf.DiskLogBuf, error) {
+	// the buffer can be turned on by a config reload, the directory isn't created at startup
+	if err := serverbase.EnsureLogBufferDir(); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(utilfn.ExpandHomeDir(serverbase.GetLogBufferDir()), appRunId+"-")
+	if err != nil {
+		return nil, err
+	}
+	return disklogbuf.MakeDiskLogBuf(dir, int64(bufSizeMB)*1024*1024)
+}
+
 // LogLinePeer manages log lines for an AppRunPeer
 type LogLinePeer struct {
-	logLines      *utilds.CirBuf[ds.LogLine]
+	appRunId      string
+	logLines      logLineStore                       // created when the first line arrives (nil before that)
+	index         *gensearch.LogIndex                // word index of the stored lines (nil unless the LogSearchIndex setting is on)
 	lineNum       int64                              // Counter for log line numbers
 	logLineLock   sync.Mutex                         // Lock for synchronizing log line operations
 	searchMgr     []gensearch.SearchManagerInterface // Registered search managers
 	logSearchLock sync.RWMutex                       // Lock for search managers
+	marks         *gensearch.MarkManager             // marked lines (shared by all search managers for this app run)
 }
 
 // MakeLogLinePeer creates a new LogLinePeer instance
-func MakeLogLinePeer() *LogLinePeer {
+func MakeLogLinePeer(appRunId string) *LogLinePeer {
 	return &LogLinePeer{
-		logLines: utilds.MakeCirBuf[ds.LogLine](LogLineBufferSize),
+		appRunId: appRunId,
 		lineNum:  0,
+		marks:    gensearch.MakeMarkManager(),
 	}
 }
 
+// GetMarkManager returns the marked lines of the app run
+func (lp *LogLinePeer) GetMarkManager() *gensearch.MarkManager {
+	return lp.marks
+}
+
 // addLogLine adds a log line to the buffer with proper synchronization
 func (lp *LogLinePeer) addLogLine(line *ds.LogLine) {
 	lp.logLineLock.Lock()
@@ -39,12 +147,27 @@ func (lp *LogLinePeer) addLogLine(line *ds.LogLine) {
 	lp.lineNum++
 	line.LineNum = lp.lineNum
 
-	lp.logLines.Write(*line)
+	if lp.logLines == nil {
+		lp.logLines = makeLogLineStore(lp.appRunId)
+		if serverbase.GetRuntimeSettings().LogSearchIndex {
+			lp.index = gensearch.MakeLogIndex()
+		}
+	}
+	if err := lp.logLines.Write(*line); err != nil {
+		log.Printf("error writing log line %d: %v\n", line.LineNum, err)
+		return
+	}
+	if lp.index != nil {
+		totalCount, headOffset := lp.logLines.GetTotalCountAndHeadOffset()
+		lp.index.AddLine(totalCount-1, line.Msg)
+		lp.index.SetHeadOffset(headOffset)
+	}
 }
 
 // ProcessLogLine processes a log line
 func (lp *LogLinePeer) ProcessLogLine(line ds.LogLine) {
 	line.Msg = normalizeLineEndings(line.Msg)
+	line.Level = loglineparser.DetectLogLevel(line.Msg)
 	lp.addLogLine(&line)
 	lp.NotifySearchManagers(line)
 }
@@ -53,6 +176,7 @@ func (lp *LogLinePeer) ProcessLogLine(line ds.LogLine) {
 func (lp *LogLinePeer) ProcessMultiLogLines(lines []ds.LogLine) {
 	for i := range lines {
 		lines[i].Msg = normalizeLineEndings(lines[i].Msg)
+		lines[i].Level = loglineparser.DetectLogLevel(lines[i].Msg)
 		lp.addLogLine(&lines[i])
 		lp.NotifySearchManagers(lines[i])
 	}
@@ -72,10 +196,98 @@ func normalizeLineEndings(msg string) string {
 	return msg
 }
 
-// GetLogLines returns all log lines and the total count
-func (lp *LogLinePeer) GetLogLines() ([]ds.LogLine, int) {
-	lines, headOffset := lp.logLines.GetAll()
-	return lines, len(lines) + headOffset
+// GetLogLineSeq returns an iterator over all stored log lines (oldest to newest) and the total count
+func (lp *LogLinePeer) GetLogLineSeq() (iter.Seq[ds.LogLine], int) {
+	store := lp.getStore()
+	if store == nil {
+		return slices.Values([]ds.LogLine(nil)), 0
+	}
+	return store.All()
+}
+
+// GetLogLineSeqInWindow is GetLogLineSeq restricted to the lines with a timestamp inside the window
+// (the disk buffer skips the segments that are outside of the window)
+func (lp *LogLinePeer) GetLogLineSeqInWindow(window searchparser.TimeWindow) (iter.Seq[ds.LogLine], int) {
+	store := lp.getStore()
+	if store == nil {
+		return slices.Values([]ds.LogLine(nil)), 0
+	}
+	return store.AllInRange(window.Start, window.End)
+}
+
+// GetIndexedLogLineSeq is GetLogLineSeq restricted to the lines that the word index returns for the terms.
+// It returns false if the app run has no index, or if the index doesn't rule out at least half of the lines
+// (reading the lines in order is as fast then).
+func (lp *LogLinePeer) GetIndexedLogLineSeq(terms []string) (iter.Seq[ds.LogLine], int, bool) {
+	lp.logLineLock.Lock()
+	store, index := lp.logLines, lp.index
+	lp.logLineLock.Unlock()
+	if store == nil || index == nil {
+		return nil, 0, false
+	}
+	totalCount, headOffset := store.GetTotalCountAndHeadOffset()
+	positions, ok := index.GetCandidates(terms)
+	if !ok || len(positions)*2 > totalCount-headOffset {
+		return nil, 0, false
+	}
+	return store.AllAt(positions), totalCount, true
+}
+
+// GetGoRoutineLogLines returns the newest maxLines (0 for all) log lines logged by a goroutine
+// and the number of lines the goroutine logged in total
+func (lp *LogLinePeer) GetGoRoutineLogLines(goId int64, maxLines int) ([]ds.LogLine, int) {
+	allLines, _ := lp.GetLogLineSeq()
+	lines := []ds.LogLine{}
+	matchCount := 0
+	for line := range allLines {
+		if line.GoId != goId {
+			continue
+		}
+		matchCount++
+		lines = append(lines, line)
+		if maxLines > 0 && len(lines) > 2*maxLines {
+			// drop the oldest lines in chunks so we aren't copying on every line
+			lines = append(lines[:0], lines[len(lines)-maxLines:]...)
+		}
+	}
+	if maxLines > 0 && len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+	return lines, matchCount
+}
+
+// GetLastLogLines returns the newest maxLines log lines with a timestamp >= sinceTs (0 for all)
+func (lp *LogLinePeer) GetLastLogLines(maxLines int, sinceTs int64) []ds.LogLine {
+	allLines, _ := lp.GetLogLineSeqInWindow(searchparser.TimeWindow{Start: sinceTs})
+	lines := []ds.LogLine{}
+	for line := range allLines {
+		lines = append(lines, line)
+		if len(lines) > 2*maxLines {
+			// drop the oldest lines in chunks so we aren't copying on every line
+			lines = append(lines[:0], lines[len(lines)-maxLines:]...)
+		}
+	}
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+	return lines
+}
+
+func (lp *LogLinePeer) getStore() logLineStore {
+	lp.logLineLock.Lock()
+	defer lp.logLineLock.Unlock()
+	return lp.logLines
+}
+
+// Close releases the log line storage (removes the disk buffer)
+func (lp *LogLinePeer) Close() {
+	store := lp.getStore()
+	if store == nil {
+		return
+	}
+	if err := store.Close(); err != nil {
+		log.Printf("error closing log line store: %v\n", err)
+	}
 }
 
 // RegisterSearchManager registers a search manager with this LogLinePeer
@@ -112,6 +324,10 @@ func (lp *LogLinePeer) NotifySearchManagers(line ds.LogLine) {
 
 // GetTotalCount returns the total count of log lines
 func (lp *LogLinePeer) GetTotalCount() int {
-	totalCount, _ := lp.logLines.GetTotalCountAndHeadOffset()
+	store := lp.getStore()
+	if store == nil {
+		return 0
+	}
+	totalCount, _ := store.GetTotalCountAndHeadOffset()
 	return totalCount
 }
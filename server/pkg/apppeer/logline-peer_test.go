@@ -0,0 +1,57 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"iter"
+	"os"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// setTestDataDir points the outrig home (and so the log buffer dir) at a temp dir and sets the runtime settings
+func setTestDataDir(t *testing.T, settings serverbase.RuntimeSettings) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(serverbase.OutrigDevEnvName, "")
+	if err := os.MkdirAll(utilfn.ExpandHomeDir(serverbase.GetLogBufferDir()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	prevSettings := serverbase.GetRuntimeSettings()
+	serverbase.SetRuntimeSettings(settings)
+	t.Cleanup(func() { serverbase.SetRuntimeSettings(prevSettings) })
+}
+
+func TestLogLinePeerDropWhileReading(t *testing.T) {
+	setTestDataDir(t, serverbase.RuntimeSettings{LogBufferSizeMB: 1})
+	lp := MakeLogLinePeer("test-apprun")
+	lp.ProcessLogLine(ds.LogLine{Ts: 1, Msg: "old line"})
+
+	// drop the store while a search is still reading it
+	oldLines, _ := lp.GetLogLineSeq()
+	next, stop := iter.Pull(oldLines)
+	if line, ok := next(); !ok || line.Msg != "old line\n" {
+		t.Fatalf("got %q, %v reading the old store", line.Msg, ok)
+	}
+	lp.compact(true)
+	lp.ProcessLogLine(ds.LogLine{Ts: 2, Msg: "new line"})
+	stop() // the old store removes its files now
+
+	newLines, count := lp.GetLogLineSeq()
+	var msgs []string
+	for line := range newLines {
+		msgs = append(msgs, line.Msg)
+	}
+	if count != 1 || len(msgs) != 1 || msgs[0] != "new line\n" {
+		t.Errorf("got %d lines %q after dropping the old store, want the new line", count, msgs)
+	}
+	// the new store's files must survive the removal of the old store's files
+	if size := serverbase.GetDirSize(utilfn.ExpandHomeDir(serverbase.GetLogBufferDir())); size == 0 {
+		t.Errorf("the new store's files were removed with the old store")
+	}
+	lp.Close()
+}
@@ -0,0 +1,87 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"sync"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/utilds"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/stacktrace"
+)
+
+const PanicsBufferSize = 500
+
+// PanicsPeer stores the panics reported by an AppRunPeer
+// panics are kept independently of goroutine polls so they are still available after the goroutine is gone
+type PanicsPeer struct {
+	panics *utilds.CirBuf[ds.PanicInfo]
+	lock   sync.RWMutex
+}
+
+// MakePanicsPeer creates a new PanicsPeer instance
+func MakePanicsPeer() *PanicsPeer {
+	return &PanicsPeer{
+		panics: utilds.MakeCirBuf[ds.PanicInfo](PanicsBufferSize),
+	}
+}
+
+// ProcessPanicInfo stores a panic from a packet
+func (pp *PanicsPeer) ProcessPanicInfo(panicInfo ds.PanicInfo) {
+	pp.lock.Lock()
+	defer pp.lock.Unlock()
+
+	pp.panics.Write(panicInfo)
+}
+
+// GetTotalCount returns the total number of panics received
+func (pp *PanicsPeer) GetTotalCount() int {
+	pp.lock.RLock()
+	defer pp.lock.RUnlock()
+	totalCount, _ := pp.panics.GetTotalCountAndHeadOffset()
+	return totalCount
+}
+
+// GetFilteredPanics returns the stored panics filtered by a timestamp
+func (pp *PanicsPeer) GetFilteredPanics(sinceTs int64) []ds.PanicInfo {
+	pp.lock.RLock()
+	defer pp.lock.RUnlock()
+
+	return pp.panics.FilterItems(func(panicInfo ds.PanicInfo, _ int) bool {
+		return panicInfo.Ts > sinceTs
+	})
+}
+
+// ConvertToPanicData converts a ds.PanicInfo to rpctypes.PanicData, parsing the stack trace
+func ConvertToPanicData(panicInfo ds.PanicInfo, modInfo stacktrace.ModuleInfo) rpctypes.PanicData {
+	panicData := rpctypes.PanicData{
+		GoId:          panicInfo.GoId,
+		Ts:            panicInfo.Ts,
+		Name:          panicInfo.Name,
+		Tags:          panicInfo.Tags,
+		PanicVal:      panicInfo.PanicVal,
+		PanicType:     panicInfo.PanicType,
+		Recovered:     panicInfo.Recovered,
+		RawStackTrace: panicInfo.StackTrace,
+	}
+	parsed, err := stacktrace.ParseGoRoutineStackTrace(panicInfo.StackTrace, modInfo, panicInfo.GoId, "panic")
+	if err == nil {
+		panicData.ParsedFrames = parsed.ParsedFrames
+		panicData.CreatedByGoId = parsed.CreatedByGoId
+		panicData.CreatedByFrame = parsed.CreatedByFrame
+	}
+	return panicData
+}
+
+// GetPanics retrieves panics for RPC
+func (pp *PanicsPeer) GetPanics(sinceTs int64, modInfo stacktrace.ModuleInfo) []rpctypes.PanicData {
+	filteredPanics := pp.GetFilteredPanics(sinceTs)
+	result := make([]rpctypes.PanicData, 0, len(filteredPanics))
+	for _, panicInfo := range filteredPanics {
+		result = append(result, ConvertToPanicData(panicInfo, modInfo))
+	}
+
+	return result
+}
@@ -0,0 +1,45 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/stacktrace"
+)
+
+const testPanicStack = `panic({0x1023e4f40?, 0x1024a5b30?})
+	/usr/local/go/src/runtime/panic.go:787 +0x124
+example.com/app.(*Worker).process(...)
+	/src/app/worker.go:42
+example.com/app.(*Worker).Run(0x14000124000)
+	/src/app/worker.go:30 +0x5c
+created by example.com/app.Start in goroutine 1
+	/src/app/main.go:12 +0x40`
+
+func TestPanicsPeer(t *testing.T) {
+	pp := MakePanicsPeer()
+	for idx := int64(1); idx <= 3; idx++ {
+		pp.ProcessPanicInfo(ds.PanicInfo{GoId: 10 + idx, Ts: idx * 1000, PanicVal: "boom", PanicType: "string", StackTrace: testPanicStack})
+	}
+	if n := pp.GetTotalCount(); n != 3 {
+		t.Errorf("got %d panics, want 3", n)
+	}
+	panics := pp.GetPanics(1000, stacktrace.ModuleInfo{})
+	if len(panics) != 2 || panics[0].GoId != 12 || panics[1].GoId != 13 {
+		t.Fatalf("got %+v, want the 2 panics after the timestamp", panics)
+	}
+
+	panicData := panics[0]
+	if panicData.PanicVal != "boom" || panicData.RawStackTrace != testPanicStack {
+		t.Errorf("got %+v", panicData)
+	}
+	if len(panicData.ParsedFrames) != 2 || panicData.ParsedFrames[0].FuncName != "(*Worker).process" {
+		t.Errorf("got frames %+v, want the 2 worker frames (without the panic() frame)", panicData.ParsedFrames)
+	}
+	if panicData.CreatedByGoId != 1 || panicData.CreatedByFrame == nil || panicData.CreatedByFrame.FuncName != "Start" {
+		t.Errorf("got created by %d %+v", panicData.CreatedByGoId, panicData.CreatedByFrame)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"log"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+// ResyncMinInterval is the minimum time between two resync requests for the same packet type (the SDK's full
+// update takes a collection interval to arrive, and deltas received until then are inconsistent as well)
+const ResyncMinInterval = 10 * time.Second
+
+// requestResync asks the SDK to send a full update of pkType after a delta update of it couldn't be applied,
+// instead of losing the data until the next reconnect. Requests are throttled per packet type (see
+// ResyncMinInterval) and skipped for app runs without an SDK connection (imported, ingested or replayed runs).
+func (p *AppRunPeer) requestResync(pkType string, reason string) {
+	if p.IsImported() {
+		return
+	}
+	p.packetConnLock.Lock()
+	connected := p.packetConn != nil
+	p.packetConnLock.Unlock()
+	if !connected {
+		return
+	}
+	now := time.Now()
+	p.dataLock.Lock()
+	if p.lastResyncTs == nil {
+		p.lastResyncTs = make(map[string]time.Time)
+	}
+	if now.Sub(p.lastResyncTs[pkType]) < ResyncMinInterval {
+		p.dataLock.Unlock()
+		return
+	}
+	p.lastResyncTs[pkType] = now
+	p.numResyncs++
+	p.dataLock.Unlock()
+
+	log.Printf("App run %s: requesting a full %s update from the SDK (%s)", p.AppRunId, pkType, reason)
+	err := p.SendPacketToSDK(&ds.PacketType{
+		Type: ds.PacketTypeResync,
+		Data: ds.ResyncData{PacketTypes: []string{pkType}, Reason: reason},
+	})
+	if err != nil {
+		log.Printf("App run %s: error sending resync request: %v", p.AppRunId, err)
+	}
+}
+
+// GetNumResyncs returns the number of full updates requested from the SDK (see requestResync)
+func (p *AppRunPeer) GetNumResyncs() int {
+	p.dataLock.Lock()
+	defer p.dataLock.Unlock()
+	return p.numResyncs
+}
@@ -0,0 +1,111 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func TestProcessWatchInfoNeedsResync(t *testing.T) {
+	wp := MakeWatchesPeer("test-resync")
+	sample := ds.WatchSample{Name: "counter", Ts: 1000, Kind: int(reflect.Int64), Val: "1"}
+	tests := []struct {
+		name   string
+		info   ds.WatchInfo
+		expect bool
+	}{
+		{"delta before a full update", ds.WatchInfo{Ts: 1000, Delta: true, Watches: []ds.WatchSample{sample}}, true},
+		{"full update", ds.WatchInfo{Ts: 1000, Decls: []ds.WatchDecl{{Name: "counter"}}, Watches: []ds.WatchSample{sample}}, false},
+		{"same sample with a base", ds.WatchInfo{Ts: 2000, Delta: true, Watches: []ds.WatchSample{{Name: "counter", Ts: 2000, Same: true}}}, false},
+		{"delta for an unknown watch", ds.WatchInfo{Ts: 3000, Delta: true, Watches: []ds.WatchSample{{Name: "unknown", Ts: 3000, Val: "1"}}}, true},
+		{"full update for an unknown watch", ds.WatchInfo{Ts: 4000, Watches: []ds.WatchSample{{Name: "unknown", Ts: 4000, Val: "1"}}}, false},
+		{"same sample without a base", ds.WatchInfo{Ts: 5000, Delta: true, Decls: []ds.WatchDecl{{Name: "new"}}, Watches: []ds.WatchSample{{Name: "new", Ts: 5000, Same: true}}}, true},
+	}
+	for _, tc := range tests {
+		if got := wp.ProcessWatchInfo(tc.info); got != tc.expect {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.expect)
+		}
+	}
+}
+
+func TestProcessGoroutineStacksNeedsResync(t *testing.T) {
+	gp := MakeGoRoutinePeer("test-resync")
+	stack := ds.GoRoutineStack{GoId: 1, State: "running", StackTrace: "main.main()\n\t/src/main.go:10 +0x1d\n"}
+	tests := []struct {
+		name   string
+		info   ds.GoroutineInfo
+		expect bool
+	}{
+		{"delta before a full update", ds.GoroutineInfo{Ts: 1000, Delta: true, Stacks: []ds.GoRoutineStack{stack}}, true},
+		{"full update", ds.GoroutineInfo{Ts: 2000, Stacks: []ds.GoRoutineStack{stack}}, false},
+		{"same stack with a base", ds.GoroutineInfo{Ts: 3000, Delta: true, Stacks: []ds.GoRoutineStack{{GoId: 1, Same: true}}}, false},
+		{"same stack without a base", ds.GoroutineInfo{Ts: 4000, Delta: true, Stacks: []ds.GoRoutineStack{{GoId: 1, Same: true}, {GoId: 2, Same: true}}}, true},
+	}
+	for _, tc := range tests {
+		if got := gp.ProcessGoroutineStacks(tc.info); got != tc.expect {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.expect)
+		}
+	}
+}
+
+func TestRequestResync(t *testing.T) {
+	peer := makeTestAppRunPeer(t)
+	// no request without an SDK connection
+	peer.requestResync(ds.PacketTypeWatch, "test")
+	if n := peer.GetNumResyncs(); n != 0 {
+		t.Fatalf("got %d resyncs without a connection, want 0", n)
+	}
+
+	resyncCh := make(chan ds.ResyncData, 10)
+	connectTestSDK(t, peer, func(packetType string, data json.RawMessage) *ds.PacketType {
+		if packetType == ds.PacketTypeResync {
+			var resync ds.ResyncData
+			json.Unmarshal(data, &resync)
+			resyncCh <- resync
+		}
+		return nil
+	})
+
+	// a delta update before a full update requests a resync, the next ones are throttled per packet type
+	deltaWatches, _ := json.Marshal(ds.WatchInfo{Ts: 1000, Delta: true})
+	for i := 0; i < 3; i++ {
+		if err := peer.HandleSDKPacket(ds.PacketTypeWatch, deltaWatches, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deltaGoroutines, _ := json.Marshal(ds.GoroutineInfo{Ts: 1000, Delta: true})
+	if err := peer.HandleSDKPacket(ds.PacketTypeGoroutine, deltaGoroutines, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for len(got) < 2 {
+		select {
+		case resync := <-resyncCh:
+			got = append(got, resync.PacketTypes...)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got resync requests %v, want 2", got)
+		}
+	}
+	if !reflect.DeepEqual(got, []string{ds.PacketTypeWatch, ds.PacketTypeGoroutine}) {
+		t.Errorf("got resync requests %v", got)
+	}
+	if n := peer.GetNumResyncs(); n != 2 {
+		t.Errorf("got %d resyncs, want 2", n)
+	}
+
+	// the throttle is per packet type and expires after ResyncMinInterval
+	peer.dataLock.Lock()
+	peer.lastResyncTs[ds.PacketTypeWatch] = time.Now().Add(-ResyncMinInterval)
+	peer.dataLock.Unlock()
+	peer.requestResync(ds.PacketTypeWatch, "test")
+	if n := peer.GetNumResyncs(); n != 3 {
+		t.Errorf("got %d resyncs after the interval, want 3", n)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// reasons reported in rpctypes.PrunedAppRun
+const (
+	PruneReasonMaxAge        = "maxage"
+	PruneReasonMaxRunsPerApp = "maxrunsperapp"
+	PruneReasonMaxAppRuns    = "maxappruns"
+)
+
+// RetentionPolicy controls which finished app runs are pruned (0 means no limit)
+type RetentionPolicy struct {
+	MaxRunsPerApp int
+	MaxAge        time.Duration
+}
+
+// GetRetentionPolicy returns the current retention settings
+func GetRetentionPolicy() RetentionPolicy {
+	settings := serverbase.GetRuntimeSettings()
+	return RetentionPolicy{
+		MaxRunsPerApp: settings.MaxAppRunsPerApp,
+		MaxAge:        settings.MaxAppRunAge,
+	}
+}
+
+// PruneAppRuns removes the app runs that are older than policy.MaxAge, runs past the newest policy.MaxRunsPerApp
+// runs of each app, and finally the least recently modified runs until at most MaxAppRunPeers remain.
+// Running app runs and app runs that are in use (non-zero reference count) are never pruned, but running
+// app runs do count towards the limits. With dryRun the app runs are only reported.
+func PruneAppRuns(policy RetentionPolicy, dryRun bool) []rpctypes.PrunedAppRun {
+	allPeers := GetAllAppRunPeers()
+	now := time.Now()
+	reasons := make(map[string]string) // apprunid => reason
+	canPrune := func(peer *AppRunPeer) bool {
+		return peer.Status != AppStatusRunning && peer.GetRefCount() == 0 && reasons[peer.AppRunId] == ""
+	}
+
+	if policy.MaxAge > 0 {
+		for _, peer := range allPeers {
+			if canPrune(peer) && now.Sub(time.UnixMilli(peer.LastModTime)) > policy.MaxAge {
+				reasons[peer.AppRunId] = PruneReasonMaxAge
+			}
+		}
+	}
+
+	if policy.MaxRunsPerApp > 0 {
+		peersByApp := make(map[string][]*AppRunPeer)
+		for _, peer := range allPeers {
+			if peer.AppInfo == nil {
+				continue
+			}
+			peersByApp[peer.AppInfo.AppName] = append(peersByApp[peer.AppInfo.AppName], peer)
+		}
+		for _, appPeers := range peersByApp {
+			// newest first
+			sort.Slice(appPeers, func(i, j int) bool {
+				return appPeers[i].AppInfo.StartTime > appPeers[j].AppInfo.StartTime
+			})
+			numKept := 0
+			for _, peer := range appPeers {
+				if reasons[peer.AppRunId] != "" {
+					continue
+				}
+				if numKept < policy.MaxRunsPerApp || !canPrune(peer) {
+					numKept++
+					continue
+				}
+				reasons[peer.AppRunId] = PruneReasonMaxRunsPerApp
+			}
+		}
+	}
+
+	numRemaining := len(allPeers) - len(reasons)
+	if numRemaining > MaxAppRunPeers {
+		sort.Slice(allPeers, func(i, j int) bool {
+			return allPeers[i].LastModTime < allPeers[j].LastModTime
+		})
+		for _, peer := range allPeers {
+			if numRemaining <= MaxAppRunPeers {
+				break
+			}
+			if !canPrune(peer) {
+				continue
+			}
+			reasons[peer.AppRunId] = PruneReasonMaxAppRuns
+			numRemaining--
+		}
+	}
+
+	pruned := make([]rpctypes.PrunedAppRun, 0, len(reasons))
+	for _, peer := range allPeers {
+		reason := reasons[peer.AppRunId]
+		if reason == "" {
+			continue
+		}
+		prunedRun := rpctypes.PrunedAppRun{
+			AppRunId:    peer.AppRunId,
+			LastModTime: peer.LastModTime,
+			Reason:      reason,
+		}
+		if peer.AppInfo != nil {
+			prunedRun.AppName = peer.AppInfo.AppName
+			prunedRun.StartTime = peer.AppInfo.StartTime
+		}
+		pruned = append(pruned, prunedRun)
+		if dryRun {
+			continue
+		}
+		appRunPeers.Delete(peer.AppRunId)
+		peer.Logs.Close()
+		log.Printf("Pruned app run peer: %s (reason: %s, last modified: %s)",
+			peer.AppRunId, reason, time.UnixMilli(peer.LastModTime).Format(time.RFC3339))
+	}
+	sort.Slice(pruned, func(i, j int) bool {
+		return pruned[i].LastModTime < pruned[j].LastModTime
+	})
+	return pruned
+}
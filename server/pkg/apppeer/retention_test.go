@@ -0,0 +1,100 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// makeTestRetentionPeer registers a finished app run of appName (removed when the test ends)
+func makeTestRetentionPeer(t *testing.T, appRunId string, appName string, startTime int64, lastModTime time.Time) *AppRunPeer {
+	t.Helper()
+	peer := GetAppRunPeer(appRunId, false)
+	peer.AppInfo = &ds.AppInfo{AppRunId: appRunId, AppName: appName, StartTime: startTime}
+	peer.Status = AppStatusDone
+	peer.LastModTime = lastModTime.UnixMilli()
+	t.Cleanup(func() {
+		appRunPeers.Delete(appRunId)
+		peer.Logs.Close()
+	})
+	return peer
+}
+
+// getPrunedReasons returns apprunid => reason for the pruned app runs
+func getPrunedReasons(pruned []rpctypes.PrunedAppRun) map[string]string {
+	reasons := make(map[string]string)
+	for _, run := range pruned {
+		reasons[run.AppRunId] = run.Reason
+	}
+	return reasons
+}
+
+func TestPruneAppRuns(t *testing.T) {
+	setTestDataDir(t, serverbase.RuntimeSettings{})
+	now := time.Now()
+	makeTestRetentionPeer(t, "old", "a", 1, now.Add(-2*time.Hour))
+	makeTestRetentionPeer(t, "a1", "a", 10, now)
+	makeTestRetentionPeer(t, "a2", "a", 20, now)
+	makeTestRetentionPeer(t, "a3", "a", 30, now).Status = AppStatusRunning
+	makeTestRetentionPeer(t, "a4", "a", 40, now)
+	makeTestRetentionPeer(t, "b1", "b", 10, now)
+	makeTestRetentionPeer(t, "b2", "b", 20, now)
+	makeTestRetentionPeer(t, "b3", "b", 30, now)
+	makeTestRetentionPeer(t, "running", "c", 10, now.Add(-2*time.Hour)).Status = AppStatusRunning
+	// b1 is in use
+	GetAppRunPeer("b1", true)
+
+	policy := RetentionPolicy{MaxRunsPerApp: 2, MaxAge: time.Hour}
+	// the running a3 counts towards a's limit, the in use b1 is kept (so b keeps 3 runs)
+	expect := map[string]string{
+		"old": PruneReasonMaxAge,
+		"a1":  PruneReasonMaxRunsPerApp,
+		"a2":  PruneReasonMaxRunsPerApp,
+	}
+	if got := getPrunedReasons(PruneAppRuns(policy, true)); fmt.Sprint(got) != fmt.Sprint(expect) {
+		t.Fatalf("dry run: got pruned %v, want %v", got, expect)
+	}
+	if len(GetAllAppRunPeers()) != 9 {
+		t.Fatalf("the dry run removed app runs")
+	}
+
+	pruned := PruneAppRuns(policy, false)
+	if got := getPrunedReasons(pruned); fmt.Sprint(got) != fmt.Sprint(expect) {
+		t.Errorf("got pruned %v, want %v", got, expect)
+	}
+	if !sort.SliceIsSorted(pruned, func(i, j int) bool { return pruned[i].LastModTime < pruned[j].LastModTime }) || pruned[0].AppRunId != "old" {
+		t.Errorf("got %+v, want the pruned runs oldest first", pruned)
+	}
+	for appRunId := range expect {
+		if _, exists := appRunPeers.GetEx(appRunId); exists {
+			t.Errorf("app run %s was not removed", appRunId)
+		}
+	}
+	if len(GetAllAppRunPeers()) != 6 {
+		t.Errorf("got %d app runs left, want 6", len(GetAllAppRunPeers()))
+	}
+}
+
+func TestPruneAppRunsMaxAppRuns(t *testing.T) {
+	setTestDataDir(t, serverbase.RuntimeSettings{})
+	now := time.Now()
+	for i := 0; i < MaxAppRunPeers+2; i++ {
+		makeTestRetentionPeer(t, fmt.Sprintf("run%d", i), fmt.Sprintf("app%d", i), int64(i), now.Add(time.Duration(i)*time.Minute))
+	}
+	// the least recently modified runs go first
+	expect := map[string]string{"run0": PruneReasonMaxAppRuns, "run1": PruneReasonMaxAppRuns}
+	if got := getPrunedReasons(PruneAppRuns(RetentionPolicy{}, false)); fmt.Sprint(got) != fmt.Sprint(expect) {
+		t.Errorf("got pruned %v, want %v", got, expect)
+	}
+	if len(GetAllAppRunPeers()) != MaxAppRunPeers {
+		t.Errorf("got %d app runs left, want %d", len(GetAllAppRunPeers()), MaxAppRunPeers)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+var runtimeControlWaiters = makeCommandWaiters[ds.RuntimeControlResult]("runtime control")
+
+// RunRuntimeControl sends a runtime control command to the SDK and waits (until ctx is done) for its result.
+// Every command is written to the server log with the route that triggered it.
+func (p *AppRunPeer) RunRuntimeControl(ctx context.Context, data ds.RuntimeControlData) (ds.RuntimeControlResult, error) {
+	data.CommandId = uuid.New().String()
+	log.Printf("audit: runtime control %q (%s) on app run %s triggered by %q", data.Action, formatRuntimeControlArgs(data), p.AppRunId, data.TriggeredBy)
+	result, err := runtimeControlWaiters.sendAndWait(ctx, p, data.CommandId, &ds.PacketType{
+		Type: ds.PacketTypeRuntimeControl,
+		Data: data,
+	})
+	if err != nil {
+		return ds.RuntimeControlResult{}, err
+	}
+	if result.Error != "" {
+		log.Printf("audit: runtime control %q on app run %s failed: %s", data.Action, p.AppRunId, result.Error)
+	} else {
+		log.Printf("audit: runtime control %q on app run %s done in %dms", data.Action, p.AppRunId, result.DurationMs)
+	}
+	return result, nil
+}
+
+func (p *AppRunPeer) handleRuntimeControlResult(packetData json.RawMessage) error {
+	var result ds.RuntimeControlResult
+	if err := json.Unmarshal(packetData, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal RuntimeControlResult: %w", err)
+	}
+	runtimeControlWaiters.deliver(p, result.CommandId, result)
+	return nil
+}
+
+func formatRuntimeControlArgs(data ds.RuntimeControlData) string {
+	switch data.Action {
+	case ds.RuntimeControlActionHeapDump:
+		return fmt.Sprintf("path=%q", data.Path)
+	case ds.RuntimeControlActionSetGCPercent:
+		return fmt.Sprintf("gcpercent=%d", data.GCPercent)
+	default:
+		return "no args"
+	}
+}
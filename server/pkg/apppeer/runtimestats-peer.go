@@ -4,8 +4,11 @@
 package apppeer
 
 import (
+	"context"
 	"sync"
+	"time"
 
+	"github.com/outrigdev/outrig"
 	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/utilds"
 	"github.com/outrigdev/outrig/server/pkg/rpctypes"
@@ -13,9 +16,13 @@ import (
 
 const RuntimeStatsBufferSize = 600 // 10 minutes of 1-second samples
 
+// RuntimeStatsSubCheckInterval is how often a runtime stats subscription checks if the app run is still running
+const RuntimeStatsSubCheckInterval = 5 * time.Second
+
 // RuntimeStatsPeer manages runtime stats for an AppRunPeer
 type RuntimeStatsPeer struct {
 	runtimeStats *utilds.CirBuf[ds.RuntimeStatsInfo]
+	subs         map[chan struct{}]bool // subscriptions, notified when new stats arrive
 	lock         sync.RWMutex
 }
 
@@ -23,6 +30,7 @@ type RuntimeStatsPeer struct {
 func MakeRuntimeStatsPeer() *RuntimeStatsPeer {
 	return &RuntimeStatsPeer{
 		runtimeStats: utilds.MakeCirBuf[ds.RuntimeStatsInfo](RuntimeStatsBufferSize),
+		subs:         make(map[chan struct{}]bool),
 	}
 }
 
@@ -32,6 +40,27 @@ func (rsp *RuntimeStatsPeer) ProcessRuntimeStats(stats ds.RuntimeStatsInfo) {
 	defer rsp.lock.Unlock()
 
 	rsp.runtimeStats.Write(stats)
+	for ch := range rsp.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// addSub returns a channel that is notified (without blocking, it may coalesce notifications) when new stats arrive
+func (rsp *RuntimeStatsPeer) addSub() chan struct{} {
+	rsp.lock.Lock()
+	defer rsp.lock.Unlock()
+	ch := make(chan struct{}, 1)
+	rsp.subs[ch] = true
+	return ch
+}
+
+func (rsp *RuntimeStatsPeer) removeSub(ch chan struct{}) {
+	rsp.lock.Lock()
+	defer rsp.lock.Unlock()
+	delete(rsp.subs, ch)
 }
 
 // IsEmpty returns true if the runtime stats buffer is empty
@@ -64,20 +93,96 @@ func ConvertToRuntimeStatData(stat ds.RuntimeStatsInfo) rpctypes.RuntimeStatData
 		Pid:            stat.Pid,
 		Cwd:            stat.Cwd,
 		MemStats:       stat.MemStats,
+		FDStats:        stat.FDStats,
+		SchedStats:     stat.SchedStats,
 	}
 }
 
-// GetRuntimeStats retrieves runtime stats for RPC
+// setRuntimeStatRates sets the rates of data (the stats of cur) computed from the previous sample
+func setRuntimeStatRates(data *rpctypes.RuntimeStatData, prev ds.RuntimeStatsInfo, cur ds.RuntimeStatsInfo) {
+	elapsedSecs := float64(cur.Ts-prev.Ts) / 1000
+	// counters go backwards if the samples are not from the same process
+	if elapsedSecs <= 0 || cur.MemStats.TotalAlloc < prev.MemStats.TotalAlloc || cur.MemStats.NumGC < prev.MemStats.NumGC {
+		return
+	}
+	data.AllocRate = float64(cur.MemStats.TotalAlloc-prev.MemStats.TotalAlloc) / elapsedSecs
+	data.GCRate = float64(cur.MemStats.NumGC-prev.MemStats.NumGC) / elapsedSecs
+}
+
+// GetRuntimeStats retrieves runtime stats for RPC, with the rates computed from the previous sample
 func (rsp *RuntimeStatsPeer) GetRuntimeStats(sinceTs int64) []rpctypes.RuntimeStatData {
-	filteredStats := rsp.GetFilteredStats(sinceTs)
-	result := make([]rpctypes.RuntimeStatData, 0, len(filteredStats))
-	for _, stat := range filteredStats {
-		result = append(result, ConvertToRuntimeStatData(stat))
+	allStats := rsp.GetFilteredStats(0)
+	result := make([]rpctypes.RuntimeStatData, 0, len(allStats))
+	for idx, stat := range allStats {
+		if stat.Ts <= sinceTs {
+			continue
+		}
+		data := ConvertToRuntimeStatData(stat)
+		if idx > 0 {
+			setRuntimeStatRates(&data, allStats[idx-1], stat)
+		}
+		result = append(result, data)
 	}
 
 	return result
 }
 
+// GetRuntimeStatsData returns the runtime stats newer than sinceTs along with the current goroutine counts
+func (p *AppRunPeer) GetRuntimeStatsData(sinceTs int64) rpctypes.AppRunRuntimeStatsData {
+	numGoRoutines, numActiveGoRoutines, numOutrigGoRoutines := p.GoRoutines.GetGoRoutineCounts()
+	rtn := rpctypes.AppRunRuntimeStatsData{
+		AppRunId:            p.AppRunId,
+		NumTotalGoRoutines:  numGoRoutines,
+		NumActiveGoRoutines: numActiveGoRoutines,
+		NumOutrigGoRoutines: numOutrigGoRoutines,
+		Stats:               p.RuntimeStats.GetRuntimeStats(sinceTs),
+	}
+	if p.AppInfo != nil {
+		rtn.AppName = p.AppInfo.AppName
+	}
+	return rtn
+}
+
+// SubscribeRuntimeStats streams the runtime stats of the app run. The first update has the stats newer than
+// sinceTs, then an update with the new stats is sent as the stats packets arrive. The stream ends when ctx
+// is done or when the app run is no longer running.
+func (p *AppRunPeer) SubscribeRuntimeStats(ctx context.Context, sinceTs int64) chan rpctypes.RespUnion[rpctypes.AppRunRuntimeStatsData] {
+	notifyCh := p.RuntimeStats.addSub()
+	rtnCh := make(chan rpctypes.RespUnion[rpctypes.AppRunRuntimeStatsData], 1)
+	go func() {
+		outrig.SetGoRoutineName("runtimestats.sub")
+		defer close(rtnCh)
+		defer p.RuntimeStats.removeSub(notifyCh)
+		ticker := time.NewTicker(RuntimeStatsSubCheckInterval)
+		defer ticker.Stop()
+		first := true
+		for {
+			data := p.GetRuntimeStatsData(sinceTs)
+			if first || len(data.Stats) > 0 {
+				if len(data.Stats) > 0 {
+					sinceTs = data.Stats[len(data.Stats)-1].Ts
+				}
+				select {
+				case rtnCh <- rpctypes.RespUnion[rpctypes.AppRunRuntimeStatsData]{Response: data}:
+				case <-ctx.Done():
+					return
+				}
+				first = false
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-notifyCh:
+			case <-ticker.C:
+				if p.Status != AppStatusRunning {
+					return
+				}
+			}
+		}
+	}()
+	return rtnCh
+}
+
 // GetTotalCollectionCount returns the total number of runtime stats collected
 func (rsp *RuntimeStatsPeer) GetTotalCollectionCount() int {
 	rsp.lock.RLock()
@@ -0,0 +1,52 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+// setWatchValueWaiters holds the pending set watch value commands by CommandId
+var setWatchValueWaiters = makeCommandWaiters[ds.SetWatchValueResult]("set watch value")
+
+// SetWatchValue asks the SDK to set the value of a settable watch and waits (until ctx is done) for the result.
+// Every command is written to the server log with the route that triggered it.
+func (p *AppRunPeer) SetWatchValue(ctx context.Context, data ds.SetWatchValueData) error {
+	decl, ok := p.Watches.GetWatchDecl(data.Name)
+	if !ok || decl.Unregistered {
+		return fmt.Errorf("watch %q not found in app run %s", data.Name, p.AppRunId)
+	}
+	if !decl.Settable {
+		return fmt.Errorf("watch %q is not settable", data.Name)
+	}
+	data.CommandId = uuid.New().String()
+	log.Printf("audit: set watch %q to %s on app run %s triggered by %q", data.Name, data.Value, p.AppRunId, data.TriggeredBy)
+	result, err := setWatchValueWaiters.sendAndWait(ctx, p, data.CommandId, &ds.PacketType{
+		Type: ds.PacketTypeSetWatchValue,
+		Data: data,
+	})
+	if err != nil {
+		return fmt.Errorf("set watch %q: %w", data.Name, err)
+	}
+	if result.Error != "" {
+		log.Printf("audit: set watch %q on app run %s failed: %s", data.Name, p.AppRunId, result.Error)
+		return fmt.Errorf("set watch %q failed: %s", data.Name, result.Error)
+	}
+	return nil
+}
+
+func (p *AppRunPeer) handleSetWatchValueResult(packetData json.RawMessage) error {
+	var result ds.SetWatchValueResult
+	if err := json.Unmarshal(packetData, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal SetWatchValueResult: %w", err)
+	}
+	setWatchValueWaiters.deliver(p, result.CommandId, result)
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/stacktrace"
+)
+
+// GitShowTimeout limits the "git show" used to read a file at the build's revision
+const GitShowTimeout = 5 * time.Second
+
+// MaxSourceFileSize is the largest source file a preview is read from
+const MaxSourceFileSize = 4 * 1024 * 1024
+
+// gitRevisionRe matches an abbreviated or full (sha1 or sha256) commit hash
+var gitRevisionRe = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+
+// GetSourcePreview returns the source around the line of a stack frame from the app's own module. The file is
+// read from the build's revision in the project's git checkout when the build info has a clean git revision,
+// otherwise from the project directory recorded by the SDK (or the frame's file path for non -trimpath builds).
+func (p *AppRunPeer) GetSourcePreview(ctx context.Context, frame rpctypes.StackFrame, contextLines int) (rpctypes.SourcePreviewData, error) {
+	if p.AppInfo == nil {
+		return rpctypes.SourcePreviewData{}, fmt.Errorf("app run %s has no app info", p.AppRunId)
+	}
+	if contextLines <= 0 {
+		contextLines = stacktrace.DefaultSourcePreviewContext
+	}
+	// the frame comes from the client, annotate it again so only files of the app's module are read
+	frame.IsImportant, frame.IsSys, frame.RelPath, frame.VcsUrl = false, false, "", ""
+	stacktrace.AnnotateFrame(&frame, p.GetModuleInfo())
+	if !frame.IsImportant {
+		return rpctypes.SourcePreviewData{}, fmt.Errorf("source previews are only available for frames of the app's own module")
+	}
+	if !strings.HasSuffix(frame.FilePath, ".go") {
+		return rpctypes.SourcePreviewData{}, fmt.Errorf("not a go source file: %s", frame.FilePath)
+	}
+	projectDir := p.getProjectDir(frame)
+	rtn := rpctypes.SourcePreviewData{
+		RelPath:    frame.RelPath,
+		LineNumber: frame.LineNumber,
+	}
+	var content string
+	if revision := p.getCleanGitRevision(); revision != "" && projectDir != "" && frame.RelPath != "" {
+		gitContent, err := gitShowFile(ctx, projectDir, revision, frame.RelPath)
+		if err == nil {
+			content = gitContent
+			rtn.FilePath = filepath.Join(projectDir, filepath.FromSlash(frame.RelPath))
+			rtn.Source = rpctypes.SourcePreviewSourceGit
+			rtn.Revision = revision
+		}
+	}
+	if rtn.Source == "" {
+		filePath, fileContent, modTime, err := readSourceFile(projectDir, frame)
+		if err != nil {
+			return rpctypes.SourcePreviewData{}, err
+		}
+		content = fileContent
+		rtn.FilePath = filePath
+		rtn.Source = rpctypes.SourcePreviewSourceFile
+		rtn.Modified = modTime.UnixMilli() > p.AppInfo.StartTime
+	}
+	startLine, lines, err := stacktrace.ExtractSourceLines(content, frame.LineNumber, contextLines)
+	if err != nil {
+		return rpctypes.SourcePreviewData{}, fmt.Errorf("%s: %w", rtn.FilePath, err)
+	}
+	rtn.StartLine = startLine
+	rtn.Lines = lines
+	return rtn, nil
+}
+
+// getProjectDir returns the main module's directory, the one recorded by the SDK or the root the frame's
+// absolute file path is under ("" if unknown)
+func (p *AppRunPeer) getProjectDir(frame rpctypes.StackFrame) string {
+	if p.AppInfo.ModuleDir != "" {
+		return p.AppInfo.ModuleDir
+	}
+	if frame.RelPath == "" || !filepath.IsAbs(frame.FilePath) {
+		return ""
+	}
+	relPath := filepath.FromSlash(frame.RelPath)
+	if !strings.HasSuffix(frame.FilePath, string(filepath.Separator)+relPath) {
+		return ""
+	}
+	return strings.TrimSuffix(frame.FilePath, string(filepath.Separator)+relPath)
+}
+
+// getCleanGitRevision returns the git revision the app was built from, "" if the build isn't from a git
+// checkout or had uncommitted changes (the working tree is then closer to the running code)
+func (p *AppRunPeer) getCleanGitRevision() string {
+	buildInfo := p.AppInfo.BuildInfo
+	if buildInfo == nil || buildInfo.Settings["vcs"] != "git" || buildInfo.Settings["vcs.modified"] == "true" {
+		return ""
+	}
+	return buildInfo.Settings["vcs.revision"]
+}
+
+// gitShowFile reads a file (relative to dir) at a git revision. The revision comes from the app's build info,
+// so it must be a commit hash (anything else could be read by git as an option).
+func gitShowFile(ctx context.Context, dir string, revision string, relPath string) (string, error) {
+	if !gitRevisionRe.MatchString(revision) {
+		return "", fmt.Errorf("invalid git revision %q", revision)
+	}
+	ctx, cancelFn := context.WithTimeout(ctx, GitShowTimeout)
+	defer cancelFn()
+	output, err := exec.CommandContext(ctx, "git", "-C", dir, "show", "--end-of-options", revision+":./"+relPath).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// readSourceFile reads the frame's file from the project directory, or from the frame's (absolute) file path
+func readSourceFile(projectDir string, frame rpctypes.StackFrame) (string, string, time.Time, error) {
+	var candidates []string
+	if projectDir != "" && frame.RelPath != "" {
+		candidates = append(candidates, filepath.Join(projectDir, filepath.FromSlash(frame.RelPath)))
+	}
+	if filepath.IsAbs(frame.FilePath) {
+		candidates = append(candidates, frame.FilePath)
+	}
+	if len(candidates) == 0 {
+		return "", "", time.Time{}, fmt.Errorf("source file %s not found (the project directory is unknown)", frame.FilePath)
+	}
+	for _, filePath := range candidates {
+		finfo, err := os.Stat(filePath)
+		if err != nil || !finfo.Mode().IsRegular() {
+			continue
+		}
+		if finfo.Size() > MaxSourceFileSize {
+			return "", "", time.Time{}, fmt.Errorf("source file %s is too large (%d bytes)", filePath, finfo.Size())
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", "", time.Time{}, fmt.Errorf("reading source file %s: %w", filePath, err)
+		}
+		return filePath, string(content), finfo.ModTime(), nil
+	}
+	return "", "", time.Time{}, fmt.Errorf("source file not found: %s", candidates[0])
+}
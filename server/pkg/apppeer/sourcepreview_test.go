@@ -0,0 +1,23 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitShowFileRejectsInvalidRevisions(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "out")
+	for _, revision := range []string{"", "HEAD", "--output=" + outputPath, "-p", "abc", "0123456789ABCDEF", "0123456 --all"} {
+		if _, err := gitShowFile(context.Background(), t.TempDir(), revision, "main.go"); err == nil {
+			t.Errorf("revision %q should be rejected", revision)
+		}
+	}
+	if _, err := os.Stat(outputPath); err == nil {
+		t.Errorf("git wrote %s", outputPath)
+	}
+}
@@ -5,11 +5,13 @@ package apppeer
 
 import (
 	"fmt"
+	"hash/crc32"
 	"reflect"
 	"strconv"
 	"sync"
 
 	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/jsonpatch"
 	"github.com/outrigdev/outrig/pkg/utilds"
 	"github.com/outrigdev/outrig/server/pkg/logutil"
 	"github.com/outrigdev/outrig/server/pkg/rpctypes"
@@ -17,6 +19,16 @@ import (
 
 const WatchBufferSize = 600 // 10 minutes of 1-second samples
 
+// WatchRateSamples is the number of samples the rate of "rate" watches is averaged over
+const WatchRateSamples = 5
+
+// WatchRateHistorySamples is the number of samples the rate history (sparkline) of "rate" watches covers
+const WatchRateHistorySamples = 60
+
+// MaxWatchesPerAppRun limits the watches kept for an app run (the SDK limits live watches, but unregistered
+// watches stay on the server), when it is reached the least recently updated unregistered watch is evicted
+const MaxWatchesPerAppRun = 10000
+
 // Watch represents a single watch with its values
 type Watch struct {
 	WatchNum  int64
@@ -32,6 +44,9 @@ type WatchesPeer struct {
 	lock              sync.RWMutex     // Lock for synchronizing watch operations
 	hasSeenFullUpdate bool             // Flag to track if we've seen a full update
 	appRunId          string           // ID of the app run this peer belongs to
+	sdkLimitStats     *ds.WatchLimitStats
+	numEvicted        int // unregistered watches evicted on the server
+	numDropped        int // new watches dropped on the server (no unregistered watch to evict)
 }
 
 // MakeWatchesPeer creates a new WatchesPeer instance
@@ -73,11 +88,45 @@ func mergeWatchSamples(baseSample, deltaSample ds.WatchSample) ds.WatchSample {
 	return deltaSample
 }
 
+// evictUnregisteredWatch_nolock removes the unregistered watch with the oldest last sample
+// returns false if there are no unregistered watches
+func (wp *WatchesPeer) evictUnregisteredWatch_nolock() bool {
+	var lruName string
+	var lruNum int64
+	var lruTs int64
+	for name, watchNum := range wp.nameToWatchNum {
+		watch, exists := wp.watches.GetEx(watchNum)
+		if !exists || !watch.Decl.Unregistered {
+			continue
+		}
+		var lastTs int64
+		if lastSample, _, ok := watch.WatchVals.GetLast(); ok {
+			lastTs = lastSample.Ts
+		}
+		if lruName == "" || lastTs < lruTs {
+			lruName, lruNum, lruTs = name, watchNum, lastTs
+		}
+	}
+	if lruName == "" {
+		return false
+	}
+	wp.watches.Delete(lruNum)
+	delete(wp.nameToWatchNum, lruName)
+	wp.numEvicted++
+	return true
+}
+
 // getOrCreateWatch_nolock gets or creates a watch by name
 // Assumes the lock is already held
 func (wp *WatchesPeer) getOrCreateWatch_nolock(watchDecl ds.WatchDecl) (Watch, int64) {
 	watchName := watchDecl.Name
 	watchNum, exists := wp.nameToWatchNum[watchName]
+	if !exists && len(wp.nameToWatchNum) >= MaxWatchesPerAppRun && !wp.evictUnregisteredWatch_nolock() {
+		wp.numDropped++
+		logKey := fmt.Sprintf("watches-limit-%s", wp.appRunId)
+		logutil.LogfOnce(logKey, "WARNING: [AppRun: %s] Max watches (%d) reached, dropping watch %s\n", wp.appRunId, MaxWatchesPerAppRun, watchName)
+		return Watch{}, 0
+	}
 	if !exists {
 		// Create a new watch with a new number
 		wp.watchNum++
@@ -113,15 +162,28 @@ func (wp *WatchesPeer) getWatchByName_nolock(name string) *Watch {
 	return &watch
 }
 
-// ProcessWatchInfo processes watch information from a packet
-func (wp *WatchesPeer) ProcessWatchInfo(watchInfo ds.WatchInfo) {
+// GetWatchDecl returns the declaration of the named watch
+func (wp *WatchesPeer) GetWatchDecl(name string) (ds.WatchDecl, bool) {
+	wp.lock.RLock()
+	defer wp.lock.RUnlock()
+	watch := wp.getWatchByName_nolock(name)
+	if watch == nil {
+		return ds.WatchDecl{}, false
+	}
+	return watch.Decl, true
+}
+
+// ProcessWatchInfo processes watch information from a packet. It returns true if the packet was a delta
+// update the server couldn't apply (no full update seen, an unknown watch, a patch or a "same" sample without
+// a base), the SDK then needs to send a full update (see AppRunPeer.requestResync).
+func (wp *WatchesPeer) ProcessWatchInfo(watchInfo ds.WatchInfo) bool {
 	wp.lock.Lock()
 	defer wp.lock.Unlock()
 
 	// If this is a delta update but we haven't seen a full update yet, ignore it
 	if watchInfo.Delta && !wp.hasSeenFullUpdate {
 		fmt.Printf("WARNING: [AppRun: %s] Ignoring delta update because no full update has been seen yet\n", wp.appRunId)
-		return
+		return true
 	}
 
 	// If this is a full update, mark that we've seen one
@@ -129,19 +191,37 @@ func (wp *WatchesPeer) ProcessWatchInfo(watchInfo ds.WatchInfo) {
 		wp.hasSeenFullUpdate = true
 	}
 
+	if watchInfo.Limits != nil {
+		wp.sdkLimitStats = watchInfo.Limits
+	}
+
 	// Process watch declarations first
 	for _, decl := range watchInfo.Decls {
 		wp.getOrCreateWatch_nolock(decl)
 	}
 
 	// Process watch samples
+	var needsResync bool
 	for _, sample := range watchInfo.Watches {
 		watch := wp.getWatchByName_nolock(sample.Name)
 		if watch == nil {
 			logKey := fmt.Sprintf("watches-nosample-%s", wp.appRunId)
 			logutil.LogfOnce(logKey, "WARNING: [AppRun: %s] No watch found for sample %s in watch info\n", wp.appRunId, sample.Name)
+			needsResync = needsResync || watchInfo.Delta
 			continue // Skip this sample if no watch is found
 		}
+		if sample.Patch != nil {
+			patchedSample, err := applyWatchPatch(watch, sample)
+			if err != nil {
+				// the sdk sends the full value again after at most WatchPatchMaxChain patches, a resync sends it now.
+				// until then the watch has no value (the previous one is out of date)
+				logKey := fmt.Sprintf("watches-patch-%s", wp.appRunId)
+				logutil.LogfOnce(logKey, "WARNING: [AppRun: %s] Cannot apply patch for watch %s: %v\n", wp.appRunId, sample.Name, err)
+				needsResync = true
+				patchedSample.Error = fmt.Sprintf("cannot apply value update (waiting for the full value): %v", err)
+			}
+			sample = patchedSample
+		}
 		// Handle watch value updates based on whether the sample is marked as "same"
 		if watchInfo.Delta && sample.Same {
 			// Delta updates need a base sample to merge with
@@ -152,12 +232,49 @@ func (wp *WatchesPeer) ProcessWatchInfo(watchInfo ds.WatchInfo) {
 			} else {
 				logKey := fmt.Sprintf("watches-nodeltaupdate-%s", wp.appRunId)
 				logutil.LogfOnce(logKey, "WARNING: [AppRun: %s] Delta update received for watch %s with no last sample\n", wp.appRunId, sample.Name)
+				needsResync = true
 			}
 		} else {
 			// Full update or changed sample, write the sample directly
 			watch.WatchVals.Write(sample)
 		}
 	}
+	return needsResync
+}
+
+// applyWatchPatch rebuilds the value of a patched sample (see ds.WatchPatch) from the last sample of the watch.
+// The value is only set once the whole patch applied and the checksum matched, on error the returned sample has no value.
+func applyWatchPatch(watch *Watch, sample ds.WatchSample) (ds.WatchSample, error) {
+	patch := sample.Patch
+	sample.Patch = nil
+	sample.Val = ""
+	lastSample, _, exists := watch.WatchVals.GetLast()
+	if !exists {
+		return sample, fmt.Errorf("no previous sample")
+	}
+	val, err := jsonpatch.Apply(lastSample.Val, patch.Ops)
+	if err != nil {
+		return sample, err
+	}
+	if crc32.ChecksumIEEE([]byte(val)) != patch.Crc {
+		return sample, fmt.Errorf("checksum mismatch")
+	}
+	sample.Val = val
+	return sample, nil
+}
+
+// GetLimitWarnings returns warnings for the watch limits that were hit in the SDK or on the server
+func (wp *WatchesPeer) GetLimitWarnings() []string {
+	wp.lock.RLock()
+	defer wp.lock.RUnlock()
+	warnings := wp.sdkLimitStats.Warnings()
+	if wp.numEvicted > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d unregistered watches removed (max %d watches per app run)", wp.numEvicted, MaxWatchesPerAppRun))
+	}
+	if wp.numDropped > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d watches dropped (max %d watches per app run)", wp.numDropped, MaxWatchesPerAppRun))
+	}
+	return warnings
 }
 
 // GetActiveWatchCount returns the number of active watches
@@ -212,15 +329,77 @@ func (wp *WatchesPeer) GetWatchesByIds(watchIds []int64) []rpctypes.CombinedWatc
 		if !exists {
 			continue
 		}
-		latestSample, _, exists := watch.WatchVals.GetLast()
+		latestSample, latestOffset, exists := watch.WatchVals.GetLast()
 		if !exists {
 			continue
 		}
-		result = append(result, rpctypes.CombinedWatchSample{
+		combined := rpctypes.CombinedWatchSample{
 			WatchNum: watch.WatchNum,
 			Decl:     watch.Decl,
 			Sample:   latestSample,
-		})
+		}
+		if watch.Decl.Display == ds.WatchDisplayRate {
+			combined.Rate = getWatchRate(watch, latestSample, latestOffset)
+			combined.RateHistory = getWatchRateHistory(watch, latestOffset)
+		}
+		result = append(result, combined)
 	}
 	return result
 }
+
+// getWatchRate returns the change of the watch's value per second over the last WatchRateSamples samples,
+// nil if there are no earlier samples (or the value is an error). A decrease is a counter reset (see getCounterDelta).
+func getWatchRate(watch Watch, latestSample ds.WatchSample, latestOffset int) *float64 {
+	if latestSample.Error != "" {
+		return nil
+	}
+	samples, _, _ := watch.WatchVals.GetRange(latestOffset-WatchRateSamples, latestOffset+1)
+	var delta float64
+	var firstTs int64
+	var prev *ds.WatchSample
+	for idx := range samples {
+		sample := &samples[idx]
+		if sample.Error != "" || (prev != nil && sample.Ts <= prev.Ts) {
+			continue
+		}
+		if prev == nil {
+			firstTs = sample.Ts
+		} else {
+			delta += getCounterDelta(getNumericVal(*prev), getNumericVal(*sample))
+		}
+		prev = sample
+	}
+	if prev == nil || prev.Ts == firstTs {
+		return nil
+	}
+	rate := delta * 1000 / float64(prev.Ts-firstTs)
+	return &rate
+}
+
+// getWatchRateHistory returns the rates per second between the consecutive samples of the last
+// WatchRateHistorySamples samples (oldest first), samples with errors are skipped
+func getWatchRateHistory(watch Watch, latestOffset int) []float64 {
+	samples, _, _ := watch.WatchVals.GetRange(latestOffset-WatchRateHistorySamples, latestOffset+1)
+	var rtn []float64
+	var prev *ds.WatchSample
+	for idx := range samples {
+		sample := &samples[idx]
+		if sample.Error != "" {
+			continue
+		}
+		if prev != nil && sample.Ts > prev.Ts {
+			rtn = append(rtn, getCounterDelta(getNumericVal(*prev), getNumericVal(*sample))*1000/float64(sample.Ts-prev.Ts))
+		}
+		prev = sample
+	}
+	return rtn
+}
+
+// getCounterDelta returns the increase of a cumulative value from prev to cur. "rate" watches are counters,
+// a decrease means the counter was reset (e.g. a restarted component), so cur is the increase since the reset.
+func getCounterDelta(prev float64, cur float64) float64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
@@ -0,0 +1,126 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package apppeer
+
+import (
+	"hash/crc32"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/jsonpatch"
+	"github.com/outrigdev/outrig/pkg/utilds"
+)
+
+// makeTestWatch returns a watch with an int sample per value, 1 second apart (an "err" value is an error sample)
+func makeTestWatch(vals ...string) (Watch, int) {
+	watch := Watch{WatchVals: utilds.MakeCirBuf[ds.WatchSample](WatchBufferSize)}
+	for idx, val := range vals {
+		sample := ds.WatchSample{Name: "test", Ts: int64(idx+1) * 1000, Kind: int(reflect.Int64), Val: val}
+		if val == "err" {
+			sample = ds.WatchSample{Name: "test", Ts: sample.Ts, Error: "poll error"}
+		}
+		watch.WatchVals.Write(sample)
+	}
+	return watch, len(vals) - 1
+}
+
+func TestGetWatchRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		vals   []string
+		expect *float64
+	}{
+		{"one sample", []string{"5"}, nil},
+		{"error value", []string{"0", "10", "err"}, nil},
+		{"average over the samples", []string{"0", "10", "30"}, ptrFloat(15)},
+		{"error samples are skipped", []string{"0", "err", "20"}, ptrFloat(10)},
+		{"counter reset", []string{"100", "110", "10", "20"}, ptrFloat(10)},
+		{"only the last samples", []string{"1000", "0", "0", "0", "0", "0", "6"}, ptrFloat(1.2)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			watch, latestOffset := makeTestWatch(tc.vals...)
+			latestSample, _ := watch.WatchVals.GetAt(latestOffset)
+			got := getWatchRate(watch, latestSample, latestOffset)
+			if (got == nil) != (tc.expect == nil) || (got != nil && *got != *tc.expect) {
+				t.Errorf("got %v, want %v", fmtFloatPtr(got), fmtFloatPtr(tc.expect))
+			}
+		})
+	}
+}
+
+func ptrFloat(v float64) *float64 {
+	return &v
+}
+
+func fmtFloatPtr(v *float64) string {
+	if v == nil {
+		return "nil"
+	}
+	return strconv.FormatFloat(*v, 'g', -1, 64)
+}
+
+func TestGetWatchRateHistory(t *testing.T) {
+	tests := []struct {
+		name   string
+		vals   []string
+		expect []float64
+	}{
+		{"one sample", []string{"5"}, nil},
+		{"rates between samples", []string{"0", "10", "30", "30"}, []float64{10, 20, 0}},
+		{"error samples are skipped", []string{"0", "err", "20"}, []float64{10}},
+		{"counter reset", []string{"100", "110", "5"}, []float64{10, 5}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			watch, latestOffset := makeTestWatch(tc.vals...)
+			if got := getWatchRateHistory(watch, latestOffset); !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("got %v, want %v", got, tc.expect)
+			}
+		})
+	}
+
+	// only the last WatchRateHistorySamples samples are used
+	var vals []string
+	for i := 0; i < WatchRateHistorySamples+20; i++ {
+		vals = append(vals, strconv.Itoa(i*i))
+	}
+	watch, latestOffset := makeTestWatch(vals...)
+	got := getWatchRateHistory(watch, latestOffset)
+	if len(got) != WatchRateHistorySamples || got[len(got)-1] != float64(2*latestOffset-1) {
+		t.Errorf("got %d rates ending with %v, want %d ending with the newest rate", len(got), got[len(got)-1], WatchRateHistorySamples)
+	}
+}
+
+func TestApplyWatchPatch(t *testing.T) {
+	oldVal, newVal := `{"a":1,"b":[1,2]}`, `{"a":2,"b":[1,2,3]}`
+	ops, err := jsonpatch.Make(oldVal, newVal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watch, _ := makeTestWatch(oldVal)
+	patchSample := ds.WatchSample{Name: "test", Ts: 2000, Patch: &ds.WatchPatch{Ops: ops, Crc: crc32.ChecksumIEEE([]byte(newVal))}}
+	got, err := applyWatchPatch(&watch, patchSample)
+	if err != nil || got.Val != newVal || got.Patch != nil {
+		t.Errorf("got %+v, %v, want the patched value", got, err)
+	}
+
+	// a patch that fails part way (or doesn't match the checksum) leaves no value, not a partly patched or the old one
+	badPatches := []ds.WatchPatch{
+		{Ops: append(ops, ds.JsonPatchOp{Op: ds.JsonPatchOpRemove, Path: "/missing"}), Crc: crc32.ChecksumIEEE([]byte(newVal))},
+		{Ops: ops, Crc: 1},
+	}
+	for _, patch := range badPatches {
+		patchSample.Patch = &patch
+		got, err := applyWatchPatch(&watch, patchSample)
+		if err == nil || got.Val != "" || got.Patch != nil {
+			t.Errorf("got %+v, %v, want an error and no value", got, err)
+		}
+	}
+	if last, _, _ := watch.WatchVals.GetLast(); last.Val != oldVal {
+		t.Errorf("the previous sample was changed to %q", last.Val)
+	}
+}
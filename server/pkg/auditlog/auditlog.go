@@ -0,0 +1,78 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package auditlog keeps an in-memory log of the state-changing commands run on the monitor,
+// so teams sharing a monitor can see who cleared app runs, toggled collectors, etc.
+package auditlog
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/utilds"
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// AuditLogBufferSize is the number of audit entries kept by the monitor
+const AuditLogBufferSize = 10000
+
+var auditBuf = utilds.MakeCirBuf[rpctypes.AuditLogEntry](AuditLogBufferSize)
+var nextId atomic.Int64
+
+// Record adds an entry to the audit log (err may be nil), writes it to the server log,
+// and publishes it as an audit:log event
+func Record(source string, command string, appRunId string, params any, err error) rpctypes.AuditLogEntry {
+	entry := rpctypes.AuditLogEntry{
+		Id:       nextId.Add(1),
+		Ts:       time.Now().UnixMilli(),
+		Source:   source,
+		Command:  command,
+		AppRunId: appRunId,
+		Params:   params,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	auditBuf.Write(entry)
+	if entry.Error != "" {
+		log.Printf("audit: %s (app run %q) from %q failed: %s", command, appRunId, source, entry.Error)
+	} else {
+		log.Printf("audit: %s (app run %q) from %q", command, appRunId, source)
+	}
+	rpc.Broker.Publish(rpctypes.EventType{
+		Event: rpctypes.Event_AuditLog,
+		Data:  entry,
+	})
+	return entry
+}
+
+// Query returns the entries matching the request (oldest first)
+func Query(req rpctypes.AuditLogRequest) rpctypes.AuditLogData {
+	entries := auditBuf.FilterItems(func(entry rpctypes.AuditLogEntry, _ int) bool {
+		if entry.Id <= req.SinceId {
+			return false
+		}
+		if req.Command != "" && entry.Command != req.Command {
+			return false
+		}
+		if req.Source != "" && entry.Source != req.Source {
+			return false
+		}
+		if req.AppRunId != "" && entry.AppRunId != req.AppRunId {
+			return false
+		}
+		return true
+	})
+	if req.Limit > 0 && len(entries) > req.Limit {
+		entries = entries[len(entries)-req.Limit:]
+	}
+	if entries == nil {
+		entries = []rpctypes.AuditLogEntry{}
+	}
+	return rpctypes.AuditLogData{
+		Entries:    entries,
+		TotalCount: nextId.Load(),
+	}
+}
@@ -11,18 +11,26 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/comm"
+	"github.com/outrigdev/outrig/pkg/utilfn"
 	"github.com/outrigdev/outrig/server/pkg/apppeer"
 	"github.com/outrigdev/outrig/server/pkg/browsertabs"
 	"github.com/outrigdev/outrig/server/pkg/democontroller"
+	"github.com/outrigdev/outrig/server/pkg/federation"
+	"github.com/outrigdev/outrig/server/pkg/logtail"
+	"github.com/outrigdev/outrig/server/pkg/packetrecord"
 	"github.com/outrigdev/outrig/server/pkg/rpc"
 	"github.com/outrigdev/outrig/server/pkg/rpcserver"
 	"github.com/outrigdev/outrig/server/pkg/serverbase"
+	"github.com/outrigdev/outrig/server/pkg/serverconfig"
+	"github.com/outrigdev/outrig/server/pkg/snapshots"
 	"github.com/outrigdev/outrig/server/pkg/tevent"
 	"github.com/outrigdev/outrig/server/pkg/updatecheck"
 	"github.com/outrigdev/outrig/server/pkg/web"
@@ -52,6 +60,26 @@ type CLIConfig struct {
 	CloseOnStdin bool
 	// TrayAppPid is the PID of the tray application that started the server (0 if not from tray)
 	TrayAppPid int
+	// RemoteListenAddr is the address to accept SDK connections from remote hosts ("" to disable)
+	RemoteListenAddr string
+	// TLSCertFile and TLSKeyFile enable TLS on the remote listener
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile requires remote SDK clients to present a certificate signed by this CA (mTLS)
+	TLSClientCAFile string
+	// LogBufferSizeMB is the on-disk log buffer size per app run in MB (0 keeps log lines in memory)
+	LogBufferSizeMB int
+	// MaxAppRunsPerApp and MaxAppRunAge control the pruning of finished app runs (0 means no limit)
+	MaxAppRunsPerApp int
+	MaxAppRunAge     time.Duration
+	// MaxDataDirSizeMB is the data directory size that triggers a disk usage warning (0 for no warning)
+	MaxDataDirSizeMB int
+	// PacketRecordDir records the raw packets of each app run to <dir>/<apprunid>.packets.jsonl ("" to disable)
+	PacketRecordDir string
+	// EmbedAllowedOrigins are the origins allowed to call the read-only embed API from a browser ("*" for any)
+	EmbedAllowedOrigins []string
+	// Downstreams are other monitors (name => apikey@host:port) whose app runs are shown read-only in this monitor
+	Downstreams map[string]string
 }
 
 // parseListenAddr parses a listen address string into host and port
@@ -60,12 +88,12 @@ func parseListenAddr(addr string) (string, int, error) {
 	if err != nil {
 		return "", 0, fmt.Errorf("invalid listen address format: %w", err)
 	}
-	
+
 	port, err := strconv.Atoi(portStr)
 	if err != nil {
 		return "", 0, fmt.Errorf("invalid port number: %w", err)
 	}
-	
+
 	return host, port, nil
 }
 
@@ -80,7 +108,7 @@ func getWebServerAddr(config CLIConfig) (string, int) {
 			return host, port
 		}
 	}
-	
+
 	// Use serverbase defaults
 	return serverbase.GetWebServerHost(), serverbase.GetWebServerPort()
 }
@@ -159,6 +187,44 @@ func RunServer(config CLIConfig) error {
 	}
 	defer lock.Close() // the defer statement will keep the lock alive
 
+	// the monitor config file (if any) overrides the command line flags
+	cliSettings := serverconfig.Effective{
+		Settings: serverbase.RuntimeSettings{
+			LogBufferSizeMB:     config.LogBufferSizeMB,
+			MaxAppRunsPerApp:    config.MaxAppRunsPerApp,
+			MaxAppRunAge:        config.MaxAppRunAge,
+			MaxDataDirSizeMB:    config.MaxDataDirSizeMB,
+			EmbedAllowedOrigins: config.EmbedAllowedOrigins,
+			Downstreams:         config.Downstreams,
+		},
+		RemoteListen: config.RemoteListenAddr,
+	}
+	configPath := serverconfig.GetConfigFilePath()
+	monitorConfig, err := serverconfig.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading monitor config file (%s): %w", configPath, err)
+	}
+	effective, err := monitorConfig.Resolve(cliSettings)
+	if err != nil {
+		return fmt.Errorf("invalid monitor config file (%s): %w", configPath, err)
+	}
+	serverbase.SetRuntimeSettings(effective.Settings)
+	config.RemoteListenAddr = effective.RemoteListen
+	if config.PacketRecordDir != "" {
+		recordDir, err := packetrecord.EnsureRecordDir(utilfn.ExpandHomeDir(config.PacketRecordDir))
+		if err != nil {
+			return err
+		}
+		serverbase.PacketRecordDir = recordDir
+		log.Printf("Recording app run packets to %s\n", recordDir)
+	}
+	if len(effective.Settings.EmbedAllowedOrigins) > 0 {
+		log.Printf("Embed API (/api/embed) allowed origins: %s\n", strings.Join(effective.Settings.EmbedAllowedOrigins, ", "))
+	}
+	for name, addr := range effective.Settings.Downstreams {
+		_, hostPort := serverbase.SplitDownstreamAddr(addr)
+		log.Printf("Downstream monitor %q: %s\n", name, hostPort)
+	}
 	// Ensure we have a unique server ID
 	outrigId, isFirstRun, err := serverbase.EnsureOutrigId(true)
 	if err != nil {
@@ -168,6 +234,17 @@ func RunServer(config CLIConfig) error {
 	serverbase.OutrigId = outrigId
 	serverbase.OutrigFirstRun = isFirstRun
 
+	ingestToken, err := serverbase.EnsureIngestToken()
+	if err != nil {
+		return fmt.Errorf("error ensuring ingest token: %w", err)
+	}
+	serverbase.IngestToken = ingestToken
+	tokenLocation := serverbase.GetIngestTokenFilePath()
+	if os.Getenv(serverbase.IngestTokenEnvName) != "" {
+		tokenLocation = serverbase.IngestTokenEnvName
+	}
+	log.Printf("HTTP log ingestion: POST /api/ingest/logs?apprunid=<id> (token in %s)\n", tokenLocation)
+
 	// Set tray app flag for telemetry (derive boolean from PID)
 	fromTrayApp := config.TrayAppPid > 0
 	if fromTrayApp {
@@ -198,6 +275,9 @@ func RunServer(config CLIConfig) error {
 	// Initialize update checker
 	updatecheck.StartUpdateChecker(config.TrayAppPid)
 
+	// Warn when the data directory grows over its max size
+	apppeer.StartDiskUsageChecker()
+
 	// Determine web server host and ports
 	listenHost, listenPort := getWebServerAddr(config)
 	advertisePort := serverbase.GetAdvertisePort(listenPort)
@@ -232,8 +312,45 @@ func RunServer(config CLIConfig) error {
 		return fmt.Errorf("error starting domain socket server: %w", err)
 	}
 
+	// Run named pipe server (Windows only)
+	if comm.PipeSupported {
+		err = runNamedPipeServer(ctx, advertisePort)
+		if err != nil {
+			return fmt.Errorf("error starting named pipe server: %w", err)
+		}
+	}
+
+	// Run remote SDK server if requested
+	if config.RemoteListenAddr != "" {
+		err = runRemoteServer(ctx, config, advertisePort)
+		if err != nil {
+			return fmt.Errorf("error starting remote SDK server: %w", err)
+		}
+	}
+
 	log.Printf("All servers started successfully\n")
 
+	serverconfig.StartWatcher(ctx, serverconfig.WatcherOpts{
+		Path:    configPath,
+		Base:    cliSettings,
+		Current: effective,
+		File:    monitorConfig,
+		StartRemoteListener: func(addr string) error {
+			remoteConfig := config
+			remoteConfig.RemoteListenAddr = addr
+			return runRemoteServer(ctx, remoteConfig, advertisePort)
+		},
+	})
+
+	// downstream monitors can be added with a config reload, so federation always runs
+	federation.Start(ctx)
+
+	// log files and journald units ("logtails" in the config file) are followed for the app runs
+	logtail.Start(ctx)
+
+	// scheduled snapshot rules (stored in the data dir)
+	snapshots.Start(ctx)
+
 	// If we're in development mode, start the Vite server
 	if serverbase.IsDev() {
 		viteCmd, err := startViteServer(ctx)
@@ -20,6 +20,7 @@ import (
 	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/utilfn"
 	"github.com/outrigdev/outrig/server/pkg/apppeer"
+	"github.com/outrigdev/outrig/server/pkg/packetrecord"
 	"github.com/outrigdev/outrig/server/pkg/serverbase"
 )
 
@@ -42,6 +43,9 @@ func handleLogMode(connWrap *comm.ConnWrap, appRunId string, source string) {
 
 	defer peer.Release()
 
+	recorder := packetrecord.Open(appRunId)
+	defer recorder.Close()
+
 	// Use the ConnWrap to read lines
 	for {
 		line, err := connWrap.ReadLine()
@@ -67,6 +71,7 @@ func handleLogMode(connWrap *comm.ConnWrap, appRunId string, source string) {
 		}
 
 		// Handle the packet
+		recorder.Record(ds.PacketTypeLog, logData)
 		if err := peer.HandlePacket(ds.PacketTypeLog, logData); err != nil {
 			log.Printf("Error handling log line: %v\n", err)
 		}
@@ -74,7 +79,8 @@ func handleLogMode(connWrap *comm.ConnWrap, appRunId string, source string) {
 }
 
 // handlePacketMode handles a connection in packet mode
-func handlePacketMode(connWrap *comm.ConnWrap, appRunId string) {
+// (submode is comm.PacketSubmodeWrapper for the process wrapper's connection, "" for the SDK)
+func handlePacketMode(connWrap *comm.ConnWrap, appRunId string, submode string) {
 	// Get the AppRunPeer for this connection
 	peer := apppeer.GetAppRunPeer(appRunId, true)
 	if peer == nil {
@@ -85,6 +91,19 @@ func handlePacketMode(connWrap *comm.ConnWrap, appRunId string) {
 
 	defer peer.Release()
 
+	// timestamps of an app on a host with a different clock are converted to the monitor's clock
+	clockSkewMs := apppeer.GetClockSkewMs(connWrap.ClockOffset)
+
+	if submode != comm.PacketSubmodeWrapper {
+		// the SDK's packet connection is also used to send commands to the SDK
+		peer.SetPacketConn(connWrap)
+		defer peer.ClearPacketConn(connWrap)
+		peer.SetClockSkew(clockSkewMs)
+	}
+
+	recorder := packetrecord.Open(appRunId)
+	defer recorder.Close()
+
 	// Use the ConnWrap to read lines
 	for {
 		line, err := connWrap.ReadLine()
@@ -102,8 +121,12 @@ func handlePacketMode(connWrap *comm.ConnWrap, appRunId string) {
 			continue
 		}
 
-		// Route the packet to the AppRunPeer
-		if err := peer.HandlePacket(pkt.Type, pkt.Data); err != nil {
+		// Route the packet to the AppRunPeer (recordings are in the monitor's clock, they're replayed and
+		// imported without the connection's clock skew)
+		if recorder != nil {
+			recorder.Record(pkt.Type, apppeer.CorrectPacketData(pkt.Type, pkt.Data, clockSkewMs))
+		}
+		if err := peer.HandleSDKPacket(pkt.Type, pkt.Data, clockSkewMs); err != nil {
 			fmt.Printf("error handling packet: %v\n", err)
 		}
 	}
@@ -137,7 +160,7 @@ func handleServerConn(conn net.Conn, webServerPort int, isTcp bool) {
 	// Dispatch to the appropriate handler based on the mode
 	switch mode {
 	case comm.ConnectionModePacket:
-		handlePacketMode(connWrap, appRunId)
+		handlePacketMode(connWrap, appRunId, submode)
 	case comm.ConnectionModeLog:
 		handleLogMode(connWrap, appRunId, submode)
 	default:
@@ -0,0 +1,74 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package boot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/comm"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+const (
+	pipeAcceptMinRetryDelay = 5 * time.Millisecond
+	pipeAcceptMaxRetryDelay = 1 * time.Second
+)
+
+// runNamedPipeServer accepts SDK connections on the monitor's named pipe (Windows only, the
+// counterpart of the domain socket server). The protocol is the same as on the domain socket.
+func runNamedPipeServer(ctx context.Context, webServerPort int) error {
+	pipeName := serverbase.GetPipeName()
+	listener, err := comm.ListenPipe(pipeName)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", pipeName, err)
+	}
+	log.Printf("Named Pipe Server listening at %s\n", pipeName)
+
+	go func() {
+		outrig.SetGoRoutineName("boot.pipe/wait")
+		<-ctx.Done()
+		log.Printf("Shutting down named pipe server...\n")
+		listener.Close() // This will cause Accept() to return with an error
+	}()
+
+	go func() {
+		outrig.SetGoRoutineName("boot.pipe/accept")
+		acceptPipeConns(ctx, listener, func(conn net.Conn) {
+			outrig.SetGoRoutineName("boot.pipe/conn")
+			handleServerConn(conn, webServerPort, false)
+		})
+		log.Printf("Named pipe server shutdown complete\n")
+	}()
+	return nil
+}
+
+// acceptPipeConns accepts connections until the listener is closed, handling each one on its own goroutine.
+// Creating or connecting a pipe instance can fail transiently (e.g. a client that went away), so other errors
+// are retried (backing off like net/http's Serve) instead of leaving the SDKs without a server.
+func acceptPipeConns(ctx context.Context, listener net.Listener, handleConn func(net.Conn)) {
+	var retryDelay time.Duration
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) || ctx.Err() != nil {
+				return
+			}
+			retryDelay = min(max(retryDelay*2, pipeAcceptMinRetryDelay), pipeAcceptMaxRetryDelay)
+			log.Printf("failed to accept named pipe connection (retrying in %v): %v\n", retryDelay, err)
+			select {
+			case <-time.After(retryDelay):
+			case <-ctx.Done():
+			}
+			continue
+		}
+		retryDelay = 0
+		go handleConn(conn)
+	}
+}
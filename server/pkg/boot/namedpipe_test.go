@@ -0,0 +1,66 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package boot
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// testListener returns the queued results from Accept, then net.ErrClosed
+type testListener struct {
+	results []error // nil for a connection
+}
+
+func (l *testListener) Accept() (net.Conn, error) {
+	if len(l.results) == 0 {
+		return nil, net.ErrClosed
+	}
+	err := l.results[0]
+	l.results = l.results[1:]
+	if err != nil {
+		return nil, err
+	}
+	conn, _ := net.Pipe()
+	return conn, nil
+}
+
+func (l *testListener) Close() error   { return nil }
+func (l *testListener) Addr() net.Addr { return nil }
+
+func TestAcceptPipeConnsRetries(t *testing.T) {
+	transientErr := errors.New("pipe instance busy")
+	listener := &testListener{results: []error{transientErr, nil, transientErr, transientErr, nil}}
+	connCh := make(chan net.Conn, 10)
+	done := make(chan struct{})
+	go func() {
+		acceptPipeConns(context.Background(), listener, func(conn net.Conn) { connCh <- conn })
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the accept loop didn't stop when the listener was closed")
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-connCh:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d connections, want 2 (errors are retried)", i)
+		}
+	}
+}
+
+func TestAcceptPipeConnsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	listener := &testListener{results: []error{errors.New("pipe broken"), nil}}
+	acceptPipeConns(ctx, listener, func(conn net.Conn) { t.Errorf("got a connection after the context was canceled") })
+	if len(listener.results) != 1 {
+		t.Errorf("the accept loop didn't stop on the error after the cancel")
+	}
+}
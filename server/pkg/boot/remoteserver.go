@@ -0,0 +1,68 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package boot
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/outrigdev/outrig/pkg/comm"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+)
+
+// makeServerTlsConfig creates the TLS config for the remote listener
+// if clientCAFile is set, clients must present a certificate signed by that CA (mTLS)
+func makeServerTlsConfig(certFile string, keyFile string, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(utilfn.ExpandHomeDir(certFile), utilfn.ExpandHomeDir(keyFile))
+	if err != nil {
+		return nil, fmt.Errorf("cannot load server certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		pool, err := comm.LoadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// runRemoteServer listens for SDK connections from remote hosts (optionally over TLS/mTLS)
+// connections use the same "!OUTRIG" TCP protocol as the local multiplexed port
+func runRemoteServer(ctx context.Context, config CLIConfig, webServerPort int) error {
+	if config.TLSCertFile == "" && config.TLSKeyFile == "" && config.TLSClientCAFile != "" {
+		return fmt.Errorf("--tls-client-ca requires --tls-cert and --tls-key")
+	}
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return fmt.Errorf("--tls-cert and --tls-key must be specified together")
+	}
+	listener, err := net.Listen("tcp", config.RemoteListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", config.RemoteListenAddr, err)
+	}
+	if config.TLSCertFile != "" {
+		tlsConfig, err := makeServerTlsConfig(config.TLSCertFile, config.TLSKeyFile, config.TLSClientCAFile)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		listener = tls.NewListener(listener, tlsConfig)
+		if config.TLSClientCAFile != "" {
+			log.Printf("Remote SDK server listening at %s (TLS, client certificates required)\n", listener.Addr().String())
+		} else {
+			log.Printf("Remote SDK server listening at %s (TLS)\n", listener.Addr().String())
+		}
+	} else {
+		log.Printf("Remote SDK server listening at %s (unencrypted)\n", listener.Addr().String())
+	}
+	return runTCPAcceptLoop(ctx, listener, webServerPort)
+}
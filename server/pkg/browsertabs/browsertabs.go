@@ -5,7 +5,10 @@ package browsertabs
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/outrigdev/outrig"
 	"github.com/outrigdev/outrig/server/pkg/apppeer"
@@ -16,14 +19,19 @@ import (
 // Constants
 const (
 	BrowserTabsRouteId = "browsertabs"
+	MaxUserNameLen     = 40  // longer user names are truncated
+	MaxFocusLabelLen   = 100 // longer focus labels are truncated
 )
 
 // BrowserTabInfo stores information about a browser tab
 type BrowserTabInfo struct {
-	AppRunId   string `json:"apprunid"`
-	Url        string `json:"url"`
-	Focused    bool   `json:"focused"`
-	AutoFollow bool   `json:"autofollow"`
+	AppRunId   string                  `json:"apprunid"`
+	Url        string                  `json:"url"`
+	Focused    bool                    `json:"focused"`
+	AutoFollow bool                    `json:"autofollow"`
+	UserName   string                  `json:"username,omitempty"`
+	Focus      *rpctypes.PresenceFocus `json:"focus,omitempty"` // what the tab is looking at in the app run
+	UpdatedTs  int64                   `json:"updatedts"`       // last presence change
 }
 
 // Global map to store route ID to browser tab info mapping
@@ -61,16 +69,114 @@ func Initialize() {
 }
 
 // UpdateBrowserTab updates or adds a browser tab in the tracking map
+// (the presence of the app runs the tab left or joined is published)
 func UpdateBrowserTab(routeId string, data rpctypes.BrowserTabUrlData) {
+	changedAppRunIds := updateBrowserTab(routeId, data)
+	for _, appRunId := range changedAppRunIds {
+		publishAppRunPresence(appRunId)
+	}
+}
+
+func updateBrowserTab(routeId string, data rpctypes.BrowserTabUrlData) []string {
 	browserTabsMutex.Lock()
 	defer browserTabsMutex.Unlock()
 
-	browserTabs[routeId] = BrowserTabInfo{
+	oldInfo, exists := browserTabs[routeId]
+	newInfo := BrowserTabInfo{
 		Url:        data.Url,
 		AppRunId:   data.AppRunId,
 		Focused:    data.Focused,
 		AutoFollow: data.AutoFollow,
+		UserName:   data.UserName,
+		UpdatedTs:  oldInfo.UpdatedTs,
+	}
+	if exists && oldInfo.AppRunId == newInfo.AppRunId {
+		// the focus is only kept while the tab stays on the same app run
+		newInfo.Focus = oldInfo.Focus
+		if oldInfo.Focused == newInfo.Focused && oldInfo.UserName == newInfo.UserName {
+			browserTabs[routeId] = newInfo
+			return nil
+		}
+	}
+	newInfo.UpdatedTs = time.Now().UnixMilli()
+	browserTabs[routeId] = newInfo
+	var changed []string
+	if exists && oldInfo.AppRunId != "" && oldInfo.AppRunId != newInfo.AppRunId {
+		changed = append(changed, oldInfo.AppRunId)
+	}
+	if newInfo.AppRunId != "" {
+		changed = append(changed, newInfo.AppRunId)
+	}
+	return changed
+}
+
+// SetBrowserTabFocus sets what a browser tab is looking at in its app run and publishes the app run's presence
+func SetBrowserTabFocus(routeId string, data rpctypes.BrowserTabFocusData) error {
+	if routeId == "" {
+		return fmt.Errorf("no route ID provided")
+	}
+	if data.Focus != nil {
+		focus := *data.Focus
+		focus.Label = truncateText(focus.Label, MaxFocusLabelLen)
+		data.Focus = &focus
+	}
+	if err := setBrowserTabFocus(routeId, data); err != nil {
+		return err
 	}
+	publishAppRunPresence(data.AppRunId)
+	return nil
+}
+
+func setBrowserTabFocus(routeId string, data rpctypes.BrowserTabFocusData) error {
+	browserTabsMutex.Lock()
+	defer browserTabsMutex.Unlock()
+
+	info, exists := browserTabs[routeId]
+	if !exists || info.AppRunId != data.AppRunId {
+		return fmt.Errorf("browser tab is not viewing app run %s", data.AppRunId)
+	}
+	info.Focus = data.Focus
+	info.UpdatedTs = time.Now().UnixMilli()
+	browserTabs[routeId] = info
+	return nil
+}
+
+// GetAppRunPresence returns the browser tabs viewing an app run (sorted by user name)
+func GetAppRunPresence(appRunId string) rpctypes.AppRunPresenceData {
+	browserTabsMutex.Lock()
+	defer browserTabsMutex.Unlock()
+
+	rtn := rpctypes.AppRunPresenceData{
+		AppRunId: appRunId,
+		Users:    []rpctypes.PresenceUser{},
+	}
+	for routeId, info := range browserTabs {
+		if info.AppRunId != appRunId {
+			continue
+		}
+		rtn.Users = append(rtn.Users, rpctypes.PresenceUser{
+			TabId:     routeId,
+			UserName:  info.UserName,
+			Focused:   info.Focused,
+			Focus:     info.Focus,
+			UpdatedTs: info.UpdatedTs,
+		})
+	}
+	sort.Slice(rtn.Users, func(i, j int) bool {
+		if rtn.Users[i].UserName != rtn.Users[j].UserName {
+			return rtn.Users[i].UserName < rtn.Users[j].UserName
+		}
+		return rtn.Users[i].TabId < rtn.Users[j].TabId
+	})
+	return rtn
+}
+
+func publishAppRunPresence(appRunId string) {
+	rpc.Broker.Publish(rpctypes.EventType{
+		Event:  rpctypes.Event_AppRunPresence,
+		Scopes: []string{appRunId},
+		Data:   GetAppRunPresence(appRunId),
+	})
 }
 
 // GetBrowserTabs returns a copy of the current browser tabs map
@@ -107,12 +213,16 @@ func GetBrowserTabsForAppName(appName string) []string {
 	return result
 }
 
-// RemoveBrowserTab removes a browser tab from the tracking map
+// RemoveBrowserTab removes a browser tab from the tracking map (and publishes the presence of its app run)
 func RemoveBrowserTab(routeId string) {
 	browserTabsMutex.Lock()
-	defer browserTabsMutex.Unlock()
-
+	info, exists := browserTabs[routeId]
 	delete(browserTabs, routeId)
+	browserTabsMutex.Unlock()
+
+	if exists && info.AppRunId != "" {
+		publishAppRunPresence(info.AppRunId)
+	}
 }
 
 // HandleRouteDown handles route down events to clean up browser tabs
@@ -127,6 +237,17 @@ func UpdateBrowserTabUrl(routeId string, data rpctypes.BrowserTabUrlData) error
 	if routeId == "" {
 		return fmt.Errorf("no route ID provided")
 	}
+	data.UserName = truncateText(data.UserName, MaxUserNameLen)
 	UpdateBrowserTab(routeId, data)
 	return nil
 }
+
+// truncateText trims the whitespace of user provided text shown to other tabs and truncates it to maxLen bytes
+// (without splitting a multi-byte character)
+func truncateText(text string, maxLen int) string {
+	text = strings.TrimSpace(text)
+	if len(text) > maxLen {
+		text = strings.ToValidUTF8(text[:maxLen], "")
+	}
+	return text
+}
@@ -0,0 +1,104 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package browsertabs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+func resetTestBrowserTabs(t *testing.T) {
+	browserTabsMutex.Lock()
+	browserTabs = make(map[string]BrowserTabInfo)
+	browserTabsMutex.Unlock()
+	t.Cleanup(func() {
+		browserTabsMutex.Lock()
+		browserTabs = make(map[string]BrowserTabInfo)
+		browserTabsMutex.Unlock()
+	})
+}
+
+func TestUpdateBrowserTab(t *testing.T) {
+	resetTestBrowserTabs(t)
+	focus := &rpctypes.PresenceFocus{Kind: rpctypes.PresenceFocus_GoRoutine, Id: "7"}
+	steps := []struct {
+		name        string
+		data        rpctypes.BrowserTabUrlData
+		expect      []string
+		expectFocus bool
+	}{
+		{"new tab", rpctypes.BrowserTabUrlData{Url: "/logs", AppRunId: "run1", Focused: true, UserName: "ann"}, []string{"run1"}, false},
+		{"url change only", rpctypes.BrowserTabUrlData{Url: "/goroutines", AppRunId: "run1", Focused: true, UserName: "ann"}, nil, true},
+		{"focus change", rpctypes.BrowserTabUrlData{Url: "/goroutines", AppRunId: "run1", UserName: "ann"}, []string{"run1"}, true},
+		{"user name change", rpctypes.BrowserTabUrlData{Url: "/goroutines", AppRunId: "run1", UserName: "bob"}, []string{"run1"}, true},
+		{"other app run", rpctypes.BrowserTabUrlData{Url: "/logs", AppRunId: "run2", UserName: "bob"}, []string{"run1", "run2"}, false},
+		{"no app run", rpctypes.BrowserTabUrlData{Url: "/"}, []string{"run2"}, false},
+	}
+	for idx, step := range steps {
+		if got := updateBrowserTab("tab1", step.data); !reflect.DeepEqual(got, step.expect) {
+			t.Errorf("%s: got changed app runs %v, want %v", step.name, got, step.expect)
+		}
+		info := GetBrowserTabs()["tab1"]
+		if info.Url != step.data.Url || info.UserName != step.data.UserName || (info.Focus != nil) != step.expectFocus {
+			t.Errorf("%s: got %+v", step.name, info)
+		}
+		if idx == 0 {
+			// the tab looks at a goroutine until it leaves the app run
+			if err := setBrowserTabFocus("tab1", rpctypes.BrowserTabFocusData{AppRunId: "run1", Focus: focus}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestBrowserTabFocus(t *testing.T) {
+	resetTestBrowserTabs(t)
+	if err := setBrowserTabFocus("tab1", rpctypes.BrowserTabFocusData{AppRunId: "run1"}); err == nil {
+		t.Errorf("got nil for an unknown tab, want an error")
+	}
+	updateBrowserTab("tab1", rpctypes.BrowserTabUrlData{AppRunId: "run1", UserName: "bob"})
+	updateBrowserTab("tab2", rpctypes.BrowserTabUrlData{AppRunId: "run1", UserName: "ann"})
+	updateBrowserTab("tab3", rpctypes.BrowserTabUrlData{AppRunId: "run2", UserName: "cat"})
+	if err := setBrowserTabFocus("tab1", rpctypes.BrowserTabFocusData{AppRunId: "run2"}); err == nil {
+		t.Errorf("got nil for a tab viewing another app run, want an error")
+	}
+
+	longLabel := strings.Repeat("é", MaxFocusLabelLen)
+	focus := &rpctypes.PresenceFocus{Kind: rpctypes.PresenceFocus_GoRoutine, Id: "7", Label: "  " + longLabel}
+	if err := SetBrowserTabFocus("tab1", rpctypes.BrowserTabFocusData{AppRunId: "run1", Focus: focus}); err != nil {
+		t.Fatal(err)
+	}
+	if focus.Label != "  "+longLabel {
+		t.Errorf("the caller's focus was modified")
+	}
+
+	presence := GetAppRunPresence("run1")
+	if len(presence.Users) != 2 || presence.Users[0].UserName != "ann" || presence.Users[1].UserName != "bob" {
+		t.Fatalf("got %+v, want ann and bob (sorted by user name)", presence.Users)
+	}
+	label := presence.Users[1].Focus.Label
+	if len(label) != MaxFocusLabelLen || !strings.HasPrefix(longLabel, label) {
+		t.Errorf("got label of %d bytes, want it trimmed and truncated to %d bytes", len(label), MaxFocusLabelLen)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		text   string
+		maxLen int
+		expect string
+	}{
+		{"  ann  ", 10, "ann"},
+		{"abcdef", 4, "abcd"},
+		{"aé", 2, "a"}, // the multi-byte character is not split
+	}
+	for _, tc := range tests {
+		if got := truncateText(tc.text, tc.maxLen); got != tc.expect {
+			t.Errorf("truncateText(%q, %d): got %q, want %q", tc.text, tc.maxLen, got, tc.expect)
+		}
+	}
+}
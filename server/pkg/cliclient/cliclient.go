@@ -0,0 +1,174 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cliclient connects CLI commands (outrig logs, ...) to a running Outrig Monitor.
+// It speaks the same websocket RPC protocol as the web UI, so the CLI can use the generated rpcclient commands.
+package cliclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpcclient"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/web"
+)
+
+const DialTimeout = 5 * time.Second
+const WriteTimeout = 10 * time.Second
+
+// Client is an RPC connection to the monitor
+type Client struct {
+	RpcClient *rpc.RpcClient
+	RouteId   string
+	conn      *websocket.Conn
+	doneCh    chan struct{} // closed when the connection is closed
+}
+
+// Connect opens a websocket RPC connection to the monitor at host:port
+func Connect(host string, port int) (*Client, error) {
+	routeId := "cli:" + uuid.New().String()
+	wsURL := &url.URL{
+		Scheme:   "ws",
+		Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Path:     "/ws",
+		RawQuery: url.Values{"routeid": []string{routeId}}.Encode(),
+	}
+	dialer := websocket.Dialer{HandshakeTimeout: DialTimeout}
+	conn, _, err := dialer.Dial(wsURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to the Outrig Monitor (start it with 'outrig monitor'): %w", err)
+	}
+	client := &Client{
+		RpcClient: rpc.MakeRpcClient(nil, nil, nil, "cli"),
+		RouteId:   routeId,
+		conn:      conn,
+		doneCh:    make(chan struct{}),
+	}
+	// all writes go through the write loop (websocket connections don't support concurrent writers)
+	writeCh := make(chan web.WSEventType, 16)
+	go client.readLoop(writeCh)
+	go client.writeLoop(writeCh)
+	return client, nil
+}
+
+func (c *Client) readLoop(writeCh chan web.WSEventType) {
+	defer close(c.doneCh)
+	defer close(c.RpcClient.InputCh)
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var event struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data,omitempty"`
+		}
+		if err := json.Unmarshal(message, &event); err != nil {
+			continue
+		}
+		switch event.Type {
+		case web.EventType_Ping:
+			// the monitor closes connections that don't send anything for a while
+			select {
+			case writeCh <- web.WSEventType{Type: web.EventType_Pong, Ts: time.Now().UnixMilli()}:
+			case <-c.doneCh:
+			}
+		case web.EventType_Rpc:
+			c.RpcClient.InputCh <- event.Data
+		}
+	}
+}
+
+func (c *Client) writeLoop(writeCh chan web.WSEventType) {
+	for {
+		var event web.WSEventType
+		select {
+		case <-c.doneCh:
+			return
+		case event = <-writeCh:
+		case msg, ok := <-c.RpcClient.OutputCh:
+			if !ok {
+				return
+			}
+			event = web.WSEventType{Type: web.EventType_Rpc, Ts: time.Now().UnixMilli(), Data: json.RawMessage(msg)}
+		}
+		barr, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		c.conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+		if err := c.conn.WriteMessage(websocket.TextMessage, barr); err != nil {
+			c.conn.Close()
+			return
+		}
+	}
+}
+
+// Done returns a channel that is closed when the connection to the monitor is lost (or closed)
+func (c *Client) Done() <-chan struct{} {
+	return c.doneCh
+}
+
+// Close closes the connection, pending requests fail
+func (c *Client) Close() {
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	c.conn.Close()
+	<-c.doneCh
+}
+
+// FindAppRun returns the app run matching query: an app run id (or a unique prefix of one), or an app name
+// (the newest run of that app). An empty query returns the newest running app run (or the newest app run).
+func (c *Client) FindAppRun(query string) (rpctypes.AppRunInfo, error) {
+	data, err := rpcclient.GetAppRunsCommand(c.RpcClient, rpctypes.AppRunUpdatesRequest{}, nil)
+	if err != nil {
+		return rpctypes.AppRunInfo{}, err
+	}
+	appRuns := data.AppRuns
+	if len(appRuns) == 0 {
+		return rpctypes.AppRunInfo{}, fmt.Errorf("no app runs found")
+	}
+	sort.Slice(appRuns, func(i, j int) bool {
+		return appRuns[i].StartTime > appRuns[j].StartTime
+	})
+	if query == "" {
+		for _, appRun := range appRuns {
+			if appRun.IsRunning {
+				return appRun, nil
+			}
+		}
+		return appRuns[0], nil
+	}
+	for _, appRun := range appRuns {
+		if appRun.AppRunId == query {
+			return appRun, nil
+		}
+	}
+	var prefixMatches []rpctypes.AppRunInfo
+	for _, appRun := range appRuns {
+		if strings.HasPrefix(appRun.AppRunId, query) {
+			prefixMatches = append(prefixMatches, appRun)
+		}
+	}
+	if len(prefixMatches) == 1 {
+		return prefixMatches[0], nil
+	}
+	if len(prefixMatches) > 1 {
+		return rpctypes.AppRunInfo{}, fmt.Errorf("app run id prefix %q is ambiguous (%d app runs match)", query, len(prefixMatches))
+	}
+	for _, appRun := range appRuns {
+		if appRun.AppName == query {
+			return appRun, nil
+		}
+	}
+	return rpctypes.AppRunInfo{}, fmt.Errorf("no app run found matching %q", query)
+}
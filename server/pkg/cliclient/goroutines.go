@@ -0,0 +1,164 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cliclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/outrigdev/outrig/server/pkg/rpcclient"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// GoRoutinesOpts are the options of "outrig goroutines"
+type GoRoutinesOpts struct {
+	AppRun     string // app run id (or prefix) or app name, empty for the newest running app run
+	Search     string // search query (same syntax as the goroutine viewer)
+	State      string // only goroutines in this primary state (e.g. "chan receive")
+	Name       string // only goroutines whose name contains this (case-insensitive)
+	Pkg        string // only goroutines with a frame in this package (or one of its subpackages)
+	ShowOutrig bool   // include the SDK's own #outrig goroutines
+	Json       bool   // print a JSON array of the parsed goroutines
+}
+
+// RunGoRoutines prints the goroutines of the latest goroutine snapshot of an app run that match opts
+func RunGoRoutines(client *Client, opts GoRoutinesOpts, out io.Writer) error {
+	appRun, err := client.FindAppRun(opts.AppRun)
+	if err != nil {
+		return err
+	}
+	searchTerm := opts.Search
+	searchResult, err := rpcclient.GoRoutineSearchRequestCommand(client.RpcClient, rpctypes.GoRoutineSearchRequestData{
+		AppRunId:    appRun.AppRunId,
+		SearchTerm:  searchTerm,
+		SystemQuery: makeGoRoutinesSystemQuery(opts),
+		ShowOutrig:  opts.ShowOutrig,
+		ActiveOnly:  true,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if len(searchResult.ErrorSpans) > 0 {
+		return fmt.Errorf("invalid search %q: %s", searchTerm, searchResult.ErrorSpans[0].ErrorMessage)
+	}
+	var goRoutines []rpctypes.ParsedGoRoutine
+	if len(searchResult.Results) > 0 {
+		data, err := rpcclient.GetAppRunGoRoutinesByIdsCommand(client.RpcClient, rpctypes.AppRunGoRoutinesByIdsRequest{
+			AppRunId:  appRun.AppRunId,
+			GoIds:     searchResult.Results,
+			Timestamp: searchResult.EffectiveSearchTimestamp,
+		}, nil)
+		if err != nil {
+			return err
+		}
+		goRoutines = data.GoRoutines
+	}
+	if opts.Pkg != "" {
+		goRoutines = filterGoRoutinesByPkg(goRoutines, opts.Pkg)
+	}
+	if goRoutines == nil {
+		goRoutines = []rpctypes.ParsedGoRoutine{}
+	}
+	if opts.Json {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(goRoutines)
+	}
+	if err := printGoRoutineTable(out, goRoutines); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d goroutines (%d total) in %s (app run %s)\n", len(goRoutines), searchResult.TotalCount, appRun.AppName, appRun.AppRunId)
+	return nil
+}
+
+// makeGoRoutinesSystemQuery combines the state/name filters with the user's search
+// (the same way the goroutine viewer combines its filter toggles with the search box)
+func makeGoRoutinesSystemQuery(opts GoRoutinesOpts) string {
+	var parts []string
+	if !opts.ShowOutrig {
+		parts = append(parts, "-#outrig")
+	}
+	if opts.State != "" {
+		parts = append(parts, "$state:"+quoteSearchValue(opts.State))
+	}
+	if opts.Name != "" {
+		parts = append(parts, "$name:"+quoteSearchValue(opts.Name))
+	}
+	parts = append(parts, "#userquery")
+	return strings.Join(parts, " ")
+}
+
+// quoteSearchValue quotes value as a search parser string (\" and \\ are the only escapes)
+func quoteSearchValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+// filterGoRoutinesByPkg keeps the goroutines that have a stack frame in pkg (or a subpackage of pkg)
+func filterGoRoutinesByPkg(goRoutines []rpctypes.ParsedGoRoutine, pkg string) []rpctypes.ParsedGoRoutine {
+	pkg = strings.TrimSuffix(pkg, "/")
+	var rtn []rpctypes.ParsedGoRoutine
+	for _, gr := range goRoutines {
+		for _, frame := range gr.ParsedFrames {
+			if frame.Package == pkg || strings.HasPrefix(frame.Package, pkg+"/") {
+				rtn = append(rtn, gr)
+				break
+			}
+		}
+	}
+	return rtn
+}
+
+func printGoRoutineTable(out io.Writer, goRoutines []rpctypes.ParsedGoRoutine) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GOID\tSTATE\tDURATION\tNAME\tFUNCTION")
+	for _, gr := range goRoutines {
+		duration := gr.StateDuration
+		if duration == "" {
+			duration = "-"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", gr.GoId, gr.PrimaryState, duration, formatGoRoutineName(gr), formatTopFrame(gr))
+	}
+	return tw.Flush()
+}
+
+// formatGoRoutineName returns the goroutine's name followed by its tags ("-" if it has neither)
+func formatGoRoutineName(gr rpctypes.ParsedGoRoutine) string {
+	parts := make([]string, 0, len(gr.Tags)+1)
+	if gr.Name != "" {
+		parts = append(parts, gr.Name)
+	}
+	for _, tag := range gr.Tags {
+		parts = append(parts, "#"+tag)
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatTopFrame returns the first frame from the user's own module (or the top frame) as "pkg.Func file:line"
+// (the file is relative to the module root when known)
+func formatTopFrame(gr rpctypes.ParsedGoRoutine) string {
+	if len(gr.ParsedFrames) == 0 {
+		return "-"
+	}
+	frame := gr.ParsedFrames[0]
+	for _, f := range gr.ParsedFrames {
+		if f.IsImportant {
+			frame = f
+			break
+		}
+	}
+	filePath := frame.RelPath
+	if filePath == "" {
+		filePath = frame.FilePath
+	}
+	return fmt.Sprintf("%s.%s %s:%d", frame.Package, frame.FuncName, filePath, frame.LineNumber)
+}
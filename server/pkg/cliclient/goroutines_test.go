@@ -0,0 +1,91 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cliclient
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+func TestMakeGoRoutinesSystemQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   GoRoutinesOpts
+		expect string
+	}{
+		{"defaults", GoRoutinesOpts{}, "-#outrig #userquery"},
+		{"show outrig", GoRoutinesOpts{ShowOutrig: true}, "#userquery"},
+		{"state and name", GoRoutinesOpts{State: "chan receive", Name: "worker"}, `-#outrig $state:"chan receive" $name:"worker" #userquery`},
+		{"quoted values", GoRoutinesOpts{ShowOutrig: true, Name: `say "hi" \o/`}, `$name:"say \"hi\" \\o/" #userquery`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := makeGoRoutinesSystemQuery(tc.opts); got != tc.expect {
+				t.Errorf("got %q, want %q", got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestFilterGoRoutinesByPkg(t *testing.T) {
+	makeGoRoutine := func(goId int64, pkgs ...string) rpctypes.ParsedGoRoutine {
+		gr := rpctypes.ParsedGoRoutine{GoId: goId}
+		for _, pkg := range pkgs {
+			gr.ParsedFrames = append(gr.ParsedFrames, rpctypes.StackFrame{Package: pkg, FuncName: "Fn"})
+		}
+		return gr
+	}
+	goRoutines := []rpctypes.ParsedGoRoutine{
+		makeGoRoutine(1, "net/http", "example.com/app/server"),
+		makeGoRoutine(2, "example.com/app"),
+		makeGoRoutine(3, "example.com/application"),
+		makeGoRoutine(4),
+	}
+	for _, pkg := range []string{"example.com/app", "example.com/app/"} {
+		var goIds []int64
+		for _, gr := range filterGoRoutinesByPkg(goRoutines, pkg) {
+			goIds = append(goIds, gr.GoId)
+		}
+		if len(goIds) != 2 || goIds[0] != 1 || goIds[1] != 2 {
+			t.Errorf("pkg %q: got goroutines %v, want [1 2] (subpackages match, other prefixes don't)", pkg, goIds)
+		}
+	}
+}
+
+func TestPrintGoRoutineTable(t *testing.T) {
+	goRoutines := []rpctypes.ParsedGoRoutine{
+		{
+			GoId:          7,
+			PrimaryState:  "chan receive",
+			StateDuration: "5 minutes",
+			Name:          "worker",
+			Tags:          []string{"queue"},
+			ParsedFrames: []rpctypes.StackFrame{
+				{Package: "runtime", FuncName: "gopark", FilePath: "/usr/local/go/src/runtime/proc.go", LineNumber: 425},
+				{Package: "example.com/app", FuncName: "(*Worker).Run", FilePath: "/src/app/worker.go", RelPath: "worker.go", LineNumber: 42, IsImportant: true},
+			},
+		},
+		{GoId: 8, PrimaryState: "running"},
+	}
+	var out bytes.Buffer
+	if err := printGoRoutineTable(&out, goRoutines); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want a header and 2 goroutines:\n%s", len(lines), out.String())
+	}
+	if fields := strings.Fields(lines[0]); strings.Join(fields, " ") != "GOID STATE DURATION NAME FUNCTION" {
+		t.Errorf("got header %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "worker #queue") || !strings.Contains(lines[1], "example.com/app.(*Worker).Run worker.go:42") {
+		t.Errorf("got %q, want the name with tags and the user's own frame", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); strings.Join(fields, " ") != "8 running - - -" {
+		t.Errorf("got %q, want placeholders for a goroutine without duration, name, and frames", lines[2])
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cliclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpcclient"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// LogsKeepAliveInterval is how often the log widget is kept alive while following (the monitor drops idle widgets after a minute)
+const LogsKeepAliveInterval = 5 * time.Second
+
+// LogsFollowTimeout bounds a single follow request, the stream is restarted when it expires
+const LogsFollowTimeout = 24 * time.Hour
+
+// LogsOpts are the options of "outrig logs"
+type LogsOpts struct {
+	AppRun string // app run id (or prefix) or app name, empty for the newest running app run
+	Search string // search query (same syntax as the log viewer)
+	Lines  int    // number of matching lines to print first (-1 for all)
+	Follow bool   // keep printing new matching lines
+	Json   bool   // print one JSON object per line
+}
+
+// RunLogs prints the log lines of an app run that match opts.Search, and follows new lines if opts.Follow is set.
+// It returns when ctx is done (or when all lines are printed if not following).
+func RunLogs(ctx context.Context, client *Client, opts LogsOpts, out io.Writer) error {
+	appRun, err := client.FindAppRun(opts.AppRun)
+	if err != nil {
+		return err
+	}
+	if opts.Follow {
+		fmt.Fprintf(os.Stderr, "following logs of %s (app run %s)\n", appRun.AppName, appRun.AppRunId)
+	}
+	widgetId := "cli-logs:" + uuid.New().String()
+	defer rpcclient.LogWidgetAdminCommand(client.RpcClient, rpctypes.LogWidgetAdminData{WidgetId: widgetId, Drop: true}, &rpc.RpcOpts{NoResponse: true})
+
+	backfill := opts.Lines
+	if backfill < 0 {
+		backfill = math.MaxInt32
+	}
+	for {
+		req := rpctypes.LogTailRequest{
+			WidgetId:   widgetId,
+			AppRunId:   appRun.AppRunId,
+			SearchTerm: opts.Search,
+			Backfill:   backfill,
+		}
+		done, err := runLogTail(ctx, client, req, opts, out)
+		if err != nil || done {
+			return err
+		}
+		// the follow request timed out, restart it without repeating the lines that were already printed
+		backfill = 0
+	}
+}
+
+// runLogTail prints the updates of one LogTailCommand stream, returns true when RunLogs is done
+func runLogTail(ctx context.Context, client *Client, req rpctypes.LogTailRequest, opts LogsOpts, out io.Writer) (bool, error) {
+	rpcOpts := &rpc.RpcOpts{Timeout: LogsFollowTimeout.Milliseconds()}
+	tailCh := rpcclient.LogTailCommand(client.RpcClient, req, rpcOpts)
+	defer func() {
+		if rpcOpts.StreamCancelFn != nil {
+			rpcOpts.StreamCancelFn()
+		}
+	}()
+	keepAliveTicker := time.NewTicker(LogsKeepAliveInterval)
+	defer keepAliveTicker.Stop()
+	first := true
+	for {
+		select {
+		case <-ctx.Done():
+			return true, nil
+		case <-client.Done():
+			return true, fmt.Errorf("lost the connection to the Outrig Monitor")
+		case <-keepAliveTicker.C:
+			rpcclient.LogWidgetAdminCommand(client.RpcClient, rpctypes.LogWidgetAdminData{WidgetId: req.WidgetId, KeepAlive: true}, &rpc.RpcOpts{NoResponse: true})
+		case resp, ok := <-tailCh:
+			if !ok {
+				return !opts.Follow, nil
+			}
+			if resp.Error != nil {
+				return true, resp.Error
+			}
+			if first && len(resp.Response.ErrorSpans) > 0 {
+				return true, fmt.Errorf("invalid search %q: %s", req.SearchTerm, resp.Response.ErrorSpans[0].ErrorMessage)
+			}
+			if err := printLogTailData(out, resp.Response, opts.Json); err != nil {
+				return true, err
+			}
+			if first && !opts.Follow {
+				return true, nil
+			}
+			first = false
+		}
+	}
+}
+
+func printLogTailData(out io.Writer, data rpctypes.LogTailData, asJson bool) error {
+	if data.DroppedLines > 0 {
+		fmt.Fprintf(os.Stderr, "[%d matching lines skipped, the terminal couldn't keep up]\n", data.DroppedLines)
+	}
+	for _, line := range data.Lines {
+		var err error
+		if asJson {
+			err = json.NewEncoder(out).Encode(line)
+		} else {
+			_, err = fmt.Fprintln(out, FormatLogLine(line))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatLogLine formats a log line for the terminal: "15:04:05.000 [source] msg"
+// (the source is omitted for stdout, context lines are marked with a "-")
+func FormatLogLine(line ds.LogLine) string {
+	var sb strings.Builder
+	sb.WriteString(time.UnixMilli(line.Ts).Format("15:04:05.000"))
+	if line.IsContext {
+		sb.WriteString(" -")
+	}
+	if line.Source != "" && line.Source != "/dev/stdout" {
+		sb.WriteString(" [")
+		sb.WriteString(line.Source)
+		sb.WriteString("]")
+	}
+	sb.WriteString(" ")
+	sb.WriteString(strings.TrimRight(line.Msg, "\n"))
+	return sb.String()
+}
@@ -0,0 +1,70 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cliclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+func TestFormatLogLine(t *testing.T) {
+	const ts = 1700000000123
+	tsStr := time.UnixMilli(ts).Format("15:04:05.000")
+
+	tests := []struct {
+		name   string
+		line   ds.LogLine
+		expect string
+	}{
+		{"stdout", ds.LogLine{Ts: ts, Source: "/dev/stdout", Msg: "hello\n"}, tsStr + " hello"},
+		{"stderr", ds.LogLine{Ts: ts, Source: "/dev/stderr", Msg: "failed\n"}, tsStr + " [/dev/stderr] failed"},
+		{"no source", ds.LogLine{Ts: ts, Msg: "hello"}, tsStr + " hello"},
+		{"context line", ds.LogLine{Ts: ts, Source: "/dev/stdout", Msg: "before\n", IsContext: true}, tsStr + " - before"},
+		{"trailing newlines", ds.LogLine{Ts: ts, Source: "slog", Msg: "INFO done\n\n"}, tsStr + " [slog] INFO done"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := FormatLogLine(tc.line); got != tc.expect {
+				t.Errorf("got %q, want %q", got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestPrintLogTailData(t *testing.T) {
+	data := rpctypes.LogTailData{Lines: []ds.LogLine{
+		{LineNum: 1, Ts: 1700000000000, Source: "/dev/stdout", Msg: "one\n"},
+		{LineNum: 2, Ts: 1700000001000, Source: "/dev/stdout", Msg: "two\n"},
+	}}
+
+	var out bytes.Buffer
+	if err := printLogTailData(&out, data, false); err != nil {
+		t.Fatal(err)
+	}
+	expect := FormatLogLine(data.Lines[0]) + "\n" + FormatLogLine(data.Lines[1]) + "\n"
+	if out.String() != expect {
+		t.Errorf("got %q, want %q", out.String(), expect)
+	}
+
+	// --json prints one log line object per line
+	out.Reset()
+	if err := printLogTailData(&out, data, true); err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(&out)
+	for _, expectLine := range data.Lines {
+		var line ds.LogLine
+		if err := dec.Decode(&line); err != nil {
+			t.Fatal(err)
+		}
+		if line.LineNum != expectLine.LineNum || line.Msg != expectLine.Msg {
+			t.Errorf("got %+v, want %+v", line, expectLine)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package disklogbuf implements a file-backed circular buffer for log lines.
+// Lines are appended (as JSON, one per line) to a fixed number of segment files, when the buffer
+// is over its size limit the oldest segment is dropped. Only segment metadata is kept in memory.
+package disklogbuf
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+const (
+	NumSegments    = 16
+	MinSegmentSize = 64 * 1024
+	writeBufSize   = 32 * 1024
+	readBufSize    = 64 * 1024
+)
+
+type segment struct {
+	path  string
+	file  *os.File
+	size  int64 // bytes written to the segment
+	count int   // number of lines in the segment
+	minTs int64 // smallest line timestamp in the segment (lines are not always in timestamp order)
+	maxTs int64 // largest line timestamp in the segment
+}
+
+// DiskLogBuf is a file-backed circular buffer of log lines
+type DiskLogBuf struct {
+	lock          sync.Mutex
+	dir           string
+	segmentSize   int64
+	segments      []*segment // oldest first, the last segment is the one being written
+	writer        *bufio.Writer
+	nextSegNum    int
+	totalCount    int        // total number of lines ever written
+	headOffset    int        // number of lines dropped with old segments
+	numReaders    int        // number of in-progress iterations
+	pendingRemove []*segment // dropped segments that are still being read
+	closed        bool
+}
+
+// MakeDiskLogBuf creates a new buffer in dir (which is created, and removed on Close) holding up to ~maxBytes of log lines
+func MakeDiskLogBuf(dir string, maxBytes int64) (*DiskLogBuf, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create log buffer directory %s: %w", dir, err)
+	}
+	b := &DiskLogBuf{
+		dir:         dir,
+		segmentSize: max(maxBytes/NumSegments, MinSegmentSize),
+	}
+	err = b.addSegment_nolock()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *DiskLogBuf) addSegment_nolock() error {
+	if b.writer != nil {
+		if err := b.writer.Flush(); err != nil {
+			return err
+		}
+	}
+	path := filepath.Join(b.dir, fmt.Sprintf("seg-%06d.jsonl", b.nextSegNum))
+	b.nextSegNum++
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot create log buffer segment: %w", err)
+	}
+	b.segments = append(b.segments, &segment{path: path, file: file})
+	b.writer = bufio.NewWriterSize(file, writeBufSize)
+	if len(b.segments) > NumSegments {
+		oldest := b.segments[0]
+		b.segments = b.segments[1:]
+		b.headOffset += oldest.count
+		if b.numReaders > 0 {
+			b.pendingRemove = append(b.pendingRemove, oldest)
+		} else {
+			removeSegment(oldest)
+		}
+	}
+	return nil
+}
+
+func removeSegment(seg *segment) {
+	seg.file.Close()
+	if err := os.Remove(seg.path); err != nil {
+		log.Printf("disklogbuf: cannot remove segment %s: %v\n", seg.path, err)
+	}
+}
+
+// Write appends a log line to the buffer (dropping the oldest segment if the buffer is full)
+func (b *DiskLogBuf) Write(line ds.LogLine) error {
+	barr, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	barr = append(barr, '\n')
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.closed {
+		return fmt.Errorf("log buffer is closed")
+	}
+	active := b.segments[len(b.segments)-1]
+	if active.count > 0 && active.size+int64(len(barr)) > b.segmentSize {
+		if err := b.addSegment_nolock(); err != nil {
+			return err
+		}
+		active = b.segments[len(b.segments)-1]
+	}
+	n, err := b.writer.Write(barr)
+	active.size += int64(n)
+	if err != nil {
+		return err
+	}
+	if active.count == 0 || line.Ts < active.minTs {
+		active.minTs = line.Ts
+	}
+	if active.count == 0 || line.Ts > active.maxTs {
+		active.maxTs = line.Ts
+	}
+	active.count++
+	b.totalCount++
+	return nil
+}
+
+// GetTotalCountAndHeadOffset returns the total number of lines written and the number of lines that have been dropped
+func (b *DiskLogBuf) GetTotalCountAndHeadOffset() (int, int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.totalCount, b.headOffset
+}
+
+// Size returns the number of lines currently in the buffer
+func (b *DiskLogBuf) Size() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.totalCount - b.headOffset
+}
+
+// DiskSize returns the number of bytes written to the buffer's segment files
+func (b *DiskLogBuf) DiskSize() int64 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	var size int64
+	for _, seg := range b.segments {
+		size += seg.size
+	}
+	return size
+}
+
+// DropOldSegments drops all of the segments except the one being written,
+// it returns the number of lines and bytes that were dropped
+func (b *DiskLogBuf) DropOldSegments() (int, int64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.closed || len(b.segments) <= 1 {
+		return 0, 0
+	}
+	var numLines int
+	var numBytes int64
+	for _, seg := range b.segments[:len(b.segments)-1] {
+		numLines += seg.count
+		numBytes += seg.size
+		if b.numReaders > 0 {
+			b.pendingRemove = append(b.pendingRemove, seg)
+		} else {
+			removeSegment(seg)
+		}
+	}
+	b.segments = b.segments[len(b.segments)-1:]
+	b.headOffset += numLines
+	return numLines, numBytes
+}
+
+type segmentSnapshot struct {
+	file  *os.File
+	size  int64
+	count int
+	minTs int64
+	maxTs int64
+}
+
+// snapshot flushes pending writes and returns the current segments and the head offset (registering a reader)
+func (b *DiskLogBuf) snapshot() ([]segmentSnapshot, int, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.closed {
+		return nil, 0, false
+	}
+	if err := b.writer.Flush(); err != nil {
+		log.Printf("disklogbuf: error flushing log buffer: %v\n", err)
+	}
+	snaps := make([]segmentSnapshot, 0, len(b.segments))
+	for _, seg := range b.segments {
+		snaps = append(snaps, segmentSnapshot{file: seg.file, size: seg.size, count: seg.count, minTs: seg.minTs, maxTs: seg.maxTs})
+	}
+	b.numReaders++
+	return snaps, b.headOffset, true
+}
+
+func (b *DiskLogBuf) releaseReader() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.numReaders--
+	if b.numReaders > 0 {
+		return
+	}
+	for _, seg := range b.pendingRemove {
+		removeSegment(seg)
+	}
+	b.pendingRemove = nil
+	if b.closed {
+		os.RemoveAll(b.dir)
+	}
+}
+
+// All returns an iterator over the lines in the buffer (oldest to newest) along with the total line count.
+// Lines are read from disk as the iterator runs, the lock is not held while iterating so writes are not blocked.
+func (b *DiskLogBuf) All() (iter.Seq[ds.LogLine], int) {
+	return b.AllInRange(0, 0)
+}
+
+// AllInRange is like All but only returns the lines with startTs <= Ts <= endTs (0 means unbounded).
+// Segments that have no lines in the range are not read.
+func (b *DiskLogBuf) AllInRange(startTs int64, endTs int64) (iter.Seq[ds.LogLine], int) {
+	totalCount, _ := b.GetTotalCountAndHeadOffset()
+	filtered := startTs > 0 || endTs > 0
+	seq := func(yield func(ds.LogLine) bool) {
+		snaps, _, ok := b.snapshot()
+		if !ok {
+			return
+		}
+		defer b.releaseReader()
+		for _, snap := range snaps {
+			if filtered && snap.size > 0 && !rangesOverlap(snap.minTs, snap.maxTs, startTs, endTs) {
+				continue
+			}
+			reader := bufio.NewReaderSize(io.NewSectionReader(snap.file, 0, snap.size), readBufSize)
+			for {
+				lineBytes, err := reader.ReadBytes('\n')
+				if len(lineBytes) > 0 {
+					var line ds.LogLine
+					if jsonErr := json.Unmarshal(lineBytes, &line); jsonErr == nil && (!filtered || InRange(line.Ts, startTs, endTs)) {
+						if !yield(line) {
+							return
+						}
+					}
+				}
+				if err != nil {
+					if err != io.EOF {
+						log.Printf("disklogbuf: error reading log buffer: %v\n", err)
+					}
+					break
+				}
+			}
+		}
+	}
+	return seq, totalCount
+}
+
+// AllAt returns an iterator over the lines at the given positions (ascending absolute indexes, like the
+// LineNum of the lines minus one), positions that were dropped from the buffer are skipped.
+// Segments without one of the positions are not read and only the lines at the positions are decoded.
+func (b *DiskLogBuf) AllAt(positions []int) iter.Seq[ds.LogLine] {
+	return func(yield func(ds.LogLine) bool) {
+		snaps, headOffset, ok := b.snapshot()
+		if !ok {
+			return
+		}
+		defer b.releaseReader()
+		remaining := positions
+		segStart := headOffset
+		for _, snap := range snaps {
+			segEnd := segStart + snap.count
+			for len(remaining) > 0 && remaining[0] < segStart {
+				remaining = remaining[1:]
+			}
+			if len(remaining) == 0 {
+				return
+			}
+			if remaining[0] >= segEnd {
+				segStart = segEnd
+				continue
+			}
+			reader := bufio.NewReaderSize(io.NewSectionReader(snap.file, 0, snap.size), readBufSize)
+			for pos := segStart; len(remaining) > 0 && remaining[0] < segEnd; pos++ {
+				lineBytes, err := reader.ReadBytes('\n')
+				if pos == remaining[0] && len(lineBytes) > 0 {
+					remaining = remaining[1:]
+					var line ds.LogLine
+					if jsonErr := json.Unmarshal(lineBytes, &line); jsonErr == nil {
+						if !yield(line) {
+							return
+						}
+					}
+				}
+				if err != nil {
+					if err != io.EOF {
+						log.Printf("disklogbuf: error reading log buffer: %v\n", err)
+					}
+					break
+				}
+			}
+			segStart = segEnd
+		}
+	}
+}
+
+// InRange returns true if startTs <= ts <= endTs (a 0 start or end is unbounded)
+func InRange(ts int64, startTs int64, endTs int64) bool {
+	return (startTs == 0 || ts >= startTs) && (endTs == 0 || ts <= endTs)
+}
+
+func rangesOverlap(minTs int64, maxTs int64, startTs int64, endTs int64) bool {
+	return (startTs == 0 || maxTs >= startTs) && (endTs == 0 || minTs <= endTs)
+}
+
+// Close closes the buffer and removes its files (once any in-progress iterations finish)
+func (b *DiskLogBuf) Close() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	if b.numReaders > 0 {
+		b.pendingRemove = append(b.pendingRemove, b.segments...)
+		b.segments = nil
+		return nil
+	}
+	for _, seg := range b.segments {
+		seg.file.Close()
+	}
+	b.segments = nil
+	return os.RemoveAll(b.dir)
+}
@@ -0,0 +1,236 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package disklogbuf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func collectLines(t *testing.T, b *DiskLogBuf) ([]ds.LogLine, int) {
+	t.Helper()
+	seq, totalCount := b.All()
+	var lines []ds.LogLine
+	for line := range seq {
+		lines = append(lines, line)
+	}
+	return lines, totalCount
+}
+
+func TestDiskLogBufBasic(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	b, err := MakeDiskLogBuf(dir, 1024*1024)
+	if err != nil {
+		t.Fatalf("MakeDiskLogBuf failed: %v", err)
+	}
+	defer b.Close()
+
+	for i := 1; i <= 100; i++ {
+		err := b.Write(ds.LogLine{LineNum: int64(i), Msg: fmt.Sprintf("line %d\n", i), Source: "/dev/stdout"})
+		if err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	lines, totalCount := collectLines(t, b)
+	if totalCount != 100 {
+		t.Errorf("expected total count 100, got %d", totalCount)
+	}
+	if len(lines) != 100 {
+		t.Fatalf("expected 100 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if line.LineNum != int64(i+1) || line.Msg != fmt.Sprintf("line %d\n", i+1) {
+			t.Errorf("unexpected line at %d: %+v", i, line)
+		}
+	}
+}
+
+func TestDiskLogBufDropsOldestSegment(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	// minimum segment size, so we can overflow the buffer quickly
+	b, err := MakeDiskLogBuf(dir, 0)
+	if err != nil {
+		t.Fatalf("MakeDiskLogBuf failed: %v", err)
+	}
+	defer b.Close()
+
+	msg := fmt.Sprintf("%01000d\n", 0) // ~1KB lines
+	numLines := (NumSegments + 4) * MinSegmentSize / 1000
+	for i := 1; i <= numLines; i++ {
+		if err := b.Write(ds.LogLine{LineNum: int64(i), Msg: msg}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	totalCount, headOffset := b.GetTotalCountAndHeadOffset()
+	if totalCount != numLines {
+		t.Errorf("expected total count %d, got %d", numLines, totalCount)
+	}
+	if headOffset == 0 {
+		t.Errorf("expected old segments to be dropped")
+	}
+	lines, _ := collectLines(t, b)
+	if len(lines) != totalCount-headOffset {
+		t.Errorf("expected %d lines, got %d", totalCount-headOffset, len(lines))
+	}
+	if len(lines) > 0 && (lines[0].LineNum != int64(headOffset+1) || lines[len(lines)-1].LineNum != int64(numLines)) {
+		t.Errorf("unexpected line range %d-%d", lines[0].LineNum, lines[len(lines)-1].LineNum)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != NumSegments {
+		t.Errorf("expected %d segment files, got %d", NumSegments, len(entries))
+	}
+}
+
+func TestDiskLogBufDropOldSegments(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	b, err := MakeDiskLogBuf(dir, 0)
+	if err != nil {
+		t.Fatalf("MakeDiskLogBuf failed: %v", err)
+	}
+	defer b.Close()
+
+	msg := fmt.Sprintf("%01000d\n", 0) // ~1KB lines
+	numLines := 3 * MinSegmentSize / 1000
+	for i := 1; i <= numLines; i++ {
+		if err := b.Write(ds.LogLine{LineNum: int64(i), Msg: msg}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	sizeBefore := b.DiskSize()
+	numDropped, bytesDropped := b.DropOldSegments()
+	if numDropped == 0 || bytesDropped == 0 {
+		t.Fatalf("expected old segments to be dropped, got %d lines (%d bytes)", numDropped, bytesDropped)
+	}
+	if b.DiskSize() != sizeBefore-bytesDropped {
+		t.Errorf("expected disk size %d, got %d", sizeBefore-bytesDropped, b.DiskSize())
+	}
+	lines, totalCount := collectLines(t, b)
+	if totalCount != numLines || len(lines) != numLines-numDropped || b.Size() != len(lines) {
+		t.Errorf("unexpected counts after drop: total %d, lines %d, size %d (dropped %d)", totalCount, len(lines), b.Size(), numDropped)
+	}
+	if len(lines) > 0 && lines[0].LineNum != int64(numDropped+1) {
+		t.Errorf("expected the first line to be %d, got %d", numDropped+1, lines[0].LineNum)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected 1 segment file, got %d", len(entries))
+	}
+	if numDropped, _ := b.DropOldSegments(); numDropped != 0 {
+		t.Errorf("the segment being written should not be dropped")
+	}
+}
+
+func TestDiskLogBufWriteDuringIteration(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	b, err := MakeDiskLogBuf(dir, 1024*1024)
+	if err != nil {
+		t.Fatalf("MakeDiskLogBuf failed: %v", err)
+	}
+	for i := 1; i <= 10; i++ {
+		b.Write(ds.LogLine{LineNum: int64(i), Msg: "x\n"})
+	}
+	seq, _ := b.All()
+	count := 0
+	for range seq {
+		// lines written during the iteration are not part of the snapshot
+		b.Write(ds.LogLine{LineNum: int64(100 + count), Msg: "y\n"})
+		count++
+	}
+	if count != 10 {
+		t.Errorf("expected 10 lines from the snapshot, got %d", count)
+	}
+	if b.Size() != 20 {
+		t.Errorf("expected 20 lines in the buffer, got %d", b.Size())
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected buffer directory to be removed on Close")
+	}
+}
+
+func TestDiskLogBufAllInRange(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	// minimum segment size, so the lines are spread over several segments
+	b, err := MakeDiskLogBuf(dir, 0)
+	if err != nil {
+		t.Fatalf("MakeDiskLogBuf failed: %v", err)
+	}
+	defer b.Close()
+
+	msg := fmt.Sprintf("%01000d\n", 0)
+	numLines := 2 * MinSegmentSize / 1000
+	for i := 1; i <= numLines; i++ {
+		if err := b.Write(ds.LogLine{LineNum: int64(i), Ts: int64(i * 10), Msg: msg}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	seq, totalCount := b.AllInRange(100, 200)
+	if totalCount != numLines {
+		t.Errorf("expected total count %d, got %d", numLines, totalCount)
+	}
+	var lines []ds.LogLine
+	for line := range seq {
+		lines = append(lines, line)
+	}
+	if len(lines) != 11 {
+		t.Fatalf("expected 11 lines in range, got %d", len(lines))
+	}
+	if lines[0].LineNum != 10 || lines[len(lines)-1].LineNum != 20 {
+		t.Errorf("unexpected line range %d-%d", lines[0].LineNum, lines[len(lines)-1].LineNum)
+	}
+
+	seq, _ = b.AllInRange(int64(numLines*10+1), 0)
+	for line := range seq {
+		t.Errorf("expected no lines after the last timestamp, got %+v", line)
+	}
+}
+
+func TestDiskLogBufAllAt(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	// minimum segment size, so the oldest segments are dropped
+	b, err := MakeDiskLogBuf(dir, 0)
+	if err != nil {
+		t.Fatalf("MakeDiskLogBuf failed: %v", err)
+	}
+	defer b.Close()
+
+	msg := fmt.Sprintf("%01000d\n", 0)
+	numLines := (NumSegments + 2) * MinSegmentSize / 1000
+	for i := 1; i <= numLines; i++ {
+		if err := b.Write(ds.LogLine{LineNum: int64(i), Msg: msg}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	_, headOffset := b.GetTotalCountAndHeadOffset()
+	if headOffset == 0 {
+		t.Fatalf("expected old segments to be dropped")
+	}
+
+	// dropped positions are skipped, the others are returned in order
+	positions := []int{0, headOffset - 1, headOffset, headOffset + 70, numLines - 1, numLines}
+	var lineNums []int64
+	for line := range b.AllAt(positions) {
+		lineNums = append(lineNums, line.LineNum)
+	}
+	expected := []int64{int64(headOffset + 1), int64(headOffset + 71), int64(numLines)}
+	if fmt.Sprint(lineNums) != fmt.Sprint(expected) {
+		t.Errorf("expected lines %v, got %v", expected, lineNums)
+	}
+}
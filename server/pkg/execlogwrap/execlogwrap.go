@@ -4,16 +4,20 @@
 package execlogwrap
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/outrigdev/outrig/pkg/comm"
 	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/pkg/redact"
 	"github.com/outrigdev/outrig/pkg/utilfn"
 )
 
@@ -33,17 +37,42 @@ type TeeStreamDecl struct {
 	Source string
 }
 
-var stdoutWrap = LogDataWrap{source: "/dev/stdout"}
-var stderrWrap = LogDataWrap{source: "/dev/stderr"}
+// registered streams by source name (each stream gets its own log connection to the server)
+var (
+	streamsLock       sync.Mutex
+	registeredStreams = make(map[string]*LogDataWrap)
+)
+
+// RegisterStream registers a named log stream (e.g. "/dev/stdout" or "audit") and returns its LogDataWrap
+// registering the same name twice returns the same stream
+func RegisterStream(source string) *LogDataWrap {
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	ldw := registeredStreams[source]
+	if ldw == nil {
+		ldw = &LogDataWrap{source: source}
+		registeredStreams[source] = ldw
+	}
+	return ldw
+}
 
-// getLogDataWrap returns the appropriate LogDataWrap for the given source
+// getStreams returns all registered streams
+func getStreams() []*LogDataWrap {
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	rtn := make([]*LogDataWrap, 0, len(registeredStreams))
+	for _, ldw := range registeredStreams {
+		rtn = append(rtn, ldw)
+	}
+	return rtn
+}
+
+// getLogDataWrap returns the LogDataWrap for the given source (nil for an empty source)
 func getLogDataWrap(source string) *LogDataWrap {
-	if source == "/dev/stdout" {
-		return &stdoutWrap
-	} else if source == "/dev/stderr" {
-		return &stderrWrap
+	if source == "" {
+		return nil
 	}
-	return nil
+	return RegisterStream(source)
 }
 
 // processLogData sends log data to the connection if available
@@ -100,75 +129,168 @@ func tryConnect(source string, appRunId string, cfg *config.Config) *comm.ConnWr
 }
 
 // ensureConnections ensures that we have connections to the Outrig server
-// for both stdout and stderr
+// for all of the registered streams
 func ensureConnections(appRunId string, cfg *config.Config) {
-	stdoutWrap.ensureConnection(appRunId, cfg)
-	stderrWrap.ensureConnection(appRunId, cfg)
+	for _, ldw := range getStreams() {
+		ldw.ensureConnection(appRunId, cfg)
+	}
 }
 
 // startConnPoller starts a goroutine that periodically tries to establish
-// connections to the Outrig server if they don't already exist
-func startConnPoller(appRunId string, cfg *config.Config) {
+// connections to the Outrig server if they don't already exist, until the returned stop func is called
+func startConnPoller(appRunId string, cfg *config.Config) func() {
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
 	go func() {
+		defer close(doneCh)
+		ticker := time.NewTicker(ConnPollTime)
+		defer ticker.Stop()
 		for {
 			ensureConnections(appRunId, cfg)
-			time.Sleep(ConnPollTime)
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+			}
 		}
 	}()
+	return func() {
+		close(stopCh)
+		<-doneCh
+	}
 }
 
 // closeConnections closes any open connections and resets the connection pointers
 func closeConnections() {
-	stdoutWrap.closeConnection()
-	stderrWrap.closeConnection()
+	for _, ldw := range getStreams() {
+		ldw.closeConnection()
+	}
 }
 
 // processStream processes a stream using TeeCopy in a goroutine
-func processStream(wg *sync.WaitGroup, decl TeeStreamDecl) {
+// with a redactor the data is sent to Outrig a line at a time, so a partial line is only sent once it is
+// complete (the output gets the data unchanged and right away)
+func processStream(wg *sync.WaitGroup, decl TeeStreamDecl, redactor *redact.Redactor) {
 	ldw := getLogDataWrap(decl.Source)
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		utilfn.TeeCopy(decl.Input, decl.Output, func(data []byte) {
-			if ldw != nil {
-				ldw.processLogData(data)
-			}
-		})
-		// do not log errors, just ignore
+		// TeeCopy errors are ignored (do not log them)
+		if ldw == nil {
+			utilfn.TeeCopy(decl.Input, decl.Output, nil)
+			return
+		}
+		if redactor == nil {
+			utilfn.TeeCopy(decl.Input, decl.Output, ldw.processLogData)
+			return
+		}
+		lineBuf := &redactLineBuf{redactor: redactor, sendFn: ldw.processLogData}
+		utilfn.TeeCopy(decl.Input, decl.Output, lineBuf.write)
+		lineBuf.flush()
 	}()
 }
 
+// MaxRedactLineSize is the max size of a buffered partial line, longer lines are redacted in pieces
+const MaxRedactLineSize = 64 * 1024
+
+// redactLineBuf splits the data into lines so each line is redacted as a whole
+type redactLineBuf struct {
+	redactor *redact.Redactor
+	sendFn   func([]byte)
+	partial  []byte
+}
+
+func (lb *redactLineBuf) write(data []byte) {
+	for len(data) > 0 {
+		newlineIdx := bytes.IndexByte(data, '\n')
+		if newlineIdx < 0 {
+			lb.partial = append(lb.partial, data...)
+			if len(lb.partial) >= MaxRedactLineSize {
+				lb.flush()
+			}
+			return
+		}
+		lb.partial = append(lb.partial, data[:newlineIdx+1]...)
+		data = data[newlineIdx+1:]
+		lb.flush()
+	}
+}
+
+func (lb *redactLineBuf) flush() {
+	if len(lb.partial) == 0 {
+		return
+	}
+	lb.sendFn([]byte(lb.redactor.Redact(string(lb.partial))))
+	lb.partial = lb.partial[:0]
+}
+
 // ProcessExistingStreams handles capturing logs from provided input/output streams
-// cfg cannot be nil
+// cfg cannot be nil. If cfg.Redact is invalid the streams are still copied, but nothing is sent to Outrig.
 func ProcessExistingStreams(streams []TeeStreamDecl, appRunId string, cfg *config.Config) error {
 	if cfg == nil {
 		return fmt.Errorf("config cannot be nil")
 	}
+	redactor, err := redact.MakeRedactor(cfg.Redact)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "#outrig not capturing logs: %v\n", err)
+		appRunId = ""
+	}
 
+	// register the streams first so the connections are made before we start copying
+	for _, stream := range streams {
+		getLogDataWrap(stream.Source)
+	}
+	stopPoller := func() {}
 	if appRunId != "" {
 		ensureConnections(appRunId, cfg)
-		startConnPoller(appRunId, cfg)
+		stopPoller = startConnPoller(appRunId, cfg)
 	}
 
 	var wg sync.WaitGroup
 	for _, stream := range streams {
-		processStream(&wg, stream)
+		processStream(&wg, stream, redactor)
 	}
 	wg.Wait()
+	// the poller is stopped first so it can't reconnect the streams (the next command can use another app run id)
+	stopPoller()
 	closeConnections()
 
 	return nil
 }
 
-// ExecCommand executes a command with the provided arguments
+// CommandOpts are the options of StartCommand
+type CommandOpts struct {
+	Dir string // working directory of the command (the current directory if "")
+
+	// run the command in its own process group (without stdin), so Signal and Kill also reach the processes it
+	// started. Windows has no process groups, only the command itself is signaled there.
+	ProcessGroup bool
+}
+
+// RunningCommand is a command started with StartCommand (its output is being captured)
+type RunningCommand struct {
+	cmd          *exec.Cmd
+	processGroup bool
+	appRunId     string
+	cfg          *config.Config
+	streamsCh    chan struct{} // closed once the output streams are done
+
+	// the wrapper's packet connection (comm.PacketSubmodeWrapper), made when the command starts so the exit is
+	// reported on a connection the server already has (a new connection at exit is another connect/disconnect)
+	exitConn *comm.ConnWrap
+}
+
+// StartCommand starts a command with the provided arguments and captures its output for the app run.
+// Call Wait to wait for it to exit.
 // cfg cannot be nil
-func ExecCommand(args []string, appRunId string, cfg *config.Config, extraEnv map[string]string) error {
+func StartCommand(args []string, opts CommandOpts, appRunId string, cfg *config.Config, extraEnv map[string]string) (*RunningCommand, error) {
 	if cfg == nil {
-		return fmt.Errorf("config cannot be nil")
+		return nil, fmt.Errorf("config cannot be nil")
 	}
 
 	execCmd := exec.Command(args[0], args[1:]...)
+	execCmd.Dir = opts.Dir
 
 	// Set up environment variables for external log capture on the command
 	execCmd.Env = os.Environ()
@@ -183,35 +305,131 @@ func ExecCommand(args []string, appRunId string, cfg *config.Config, extraEnv ma
 	// Serialize config to JSON and set as environment variable
 	configJson, err := json.Marshal(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to serialize config to JSON: %v", err)
+		return nil, fmt.Errorf("failed to serialize config to JSON: %v", err)
 	}
 	execCmd.Env = append(execCmd.Env, config.ConfigJsonEnvName+"="+string(configJson))
 
 	stdoutPipe, err := execCmd.StdoutPipe()
 	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %v", err)
+		return nil, fmt.Errorf("failed to create stdout pipe: %v", err)
 	}
 
 	stderrPipe, err := execCmd.StderrPipe()
 	if err != nil {
-		return fmt.Errorf("failed to create stderr pipe: %v", err)
+		return nil, fmt.Errorf("failed to create stderr pipe: %v", err)
 	}
 
-	execCmd.Stdin = os.Stdin
+	// a background process group reading the terminal would be stopped (SIGTTIN)
+	if opts.ProcessGroup {
+		setProcessGroup(execCmd)
+	} else {
+		execCmd.Stdin = os.Stdin
+	}
 
 	if err := execCmd.Start(); err != nil {
-		return fmt.Errorf("failed to start command: %v", err)
+		return nil, fmt.Errorf("failed to start command: %v", err)
 	}
 
+	rc := &RunningCommand{
+		cmd:          execCmd,
+		processGroup: opts.ProcessGroup,
+		appRunId:     appRunId,
+		cfg:          cfg,
+		streamsCh:    make(chan struct{}),
+		exitConn:     connectWrapper(appRunId, cfg),
+	}
 	streams := []TeeStreamDecl{
 		{Input: stdoutPipe, Output: os.Stdout, Source: "/dev/stdout"},
 		{Input: stderrPipe, Output: os.Stderr, Source: "/dev/stderr"},
 	}
-	ProcessExistingStreams(streams, appRunId, cfg)
+	go func() {
+		defer close(rc.streamsCh)
+		ProcessExistingStreams(streams, appRunId, cfg)
+	}()
+	return rc, nil
+}
+
+// Wait waits for the command to exit (and its output to be sent) and reports the exit to the Outrig server.
+// It returns the error from exec.Cmd.Wait (an *exec.ExitError for a non-zero exit code).
+func (rc *RunningCommand) Wait() error {
+	<-rc.streamsCh
+	err := rc.cmd.Wait()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		rc.sendAppExit(makeAppExitInfo(exitErr))
+	} else if err == nil {
+		rc.sendAppExit(ds.AppExitInfo{Ts: time.Now().UnixMilli()})
+	}
+	if rc.exitConn != nil {
+		rc.exitConn.Close()
+	}
+	return err
+}
 
-	err = execCmd.Wait()
+// Signal sends a signal to the command's process (and its process group with CommandOpts.ProcessGroup)
+func (rc *RunningCommand) Signal(sig os.Signal) error {
+	if rc.processGroup {
+		return signalProcessGroup(rc.cmd.Process, sig)
+	}
+	return rc.cmd.Process.Signal(sig)
+}
+
+// Kill kills the command's process (and its process group with CommandOpts.ProcessGroup)
+func (rc *RunningCommand) Kill() error {
+	if rc.processGroup {
+		return signalProcessGroup(rc.cmd.Process, os.Kill)
+	}
+	return rc.cmd.Process.Kill()
+}
+
+// ExecCommand executes a command with the provided arguments
+// exits with the command's exit code if it fails, cfg cannot be nil
+func ExecCommand(args []string, appRunId string, cfg *config.Config, extraEnv map[string]string) error {
+	rc, err := StartCommand(args, CommandOpts{}, appRunId, cfg, extraEnv)
+	if err != nil {
+		return err
+	}
+	err = rc.Wait()
 	if exitErr, ok := err.(*exec.ExitError); ok {
 		os.Exit(exitErr.ExitCode())
 	}
 	return err
 }
+
+func makeAppExitInfo(exitErr *exec.ExitError) ds.AppExitInfo {
+	exitInfo := ds.AppExitInfo{
+		Ts:       time.Now().UnixMilli(),
+		ExitCode: exitErr.ExitCode(),
+	}
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		exitInfo.Signal = ws.Signal().String()
+	}
+	return exitInfo
+}
+
+// connectWrapper makes the wrapper's packet connection (nil if the server can't be reached)
+func connectWrapper(appRunId string, cfg *config.Config) *comm.ConnWrap {
+	if appRunId == "" {
+		return nil
+	}
+	connWrap, _, transErr := comm.Connect(comm.ConnectionModePacket, comm.PacketSubmodeWrapper, appRunId, cfg)
+	if transErr != nil {
+		return nil
+	}
+	return connWrap
+}
+
+// sendAppExit reports the exit status of the app process to the Outrig server (best effort)
+// if the server wasn't reachable when the command started, a connection is made now
+func (rc *RunningCommand) sendAppExit(exitInfo ds.AppExitInfo) {
+	if rc.exitConn == nil {
+		rc.exitConn = connectWrapper(rc.appRunId, rc.cfg)
+		if rc.exitConn == nil {
+			return
+		}
+	}
+	barr, err := json.Marshal(&ds.PacketType{Type: ds.PacketTypeAppExit, Data: exitInfo})
+	if err != nil {
+		return
+	}
+	rc.exitConn.WriteLine(string(barr))
+}
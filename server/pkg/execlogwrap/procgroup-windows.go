@@ -0,0 +1,19 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build windows
+
+package execlogwrap
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup does nothing on Windows (there are no process groups to signal)
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// signalProcessGroup only reaches proc itself on Windows
+func signalProcessGroup(proc *os.Process, sig os.Signal) error {
+	return proc.Signal(sig)
+}
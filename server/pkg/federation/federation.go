@@ -0,0 +1,206 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package federation lets a "hub" monitor show the app runs of downstream monitors (read-only).
+// Each downstream monitor (--downstream or "downstreams" in monitor.json) gets a "monitor:<name>" route in
+// the hub's router, which forwards the FederatedCommands over a websocket to the downstream monitor (the
+// same connection the web UI uses, authenticated with an api key that has the "federation" scope). The router's RouteResolver sends the commands for downstream app runs
+// (and their log widgets) to that route, so the web UI doesn't need to know where an app run lives.
+package federation
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// ReconnectInterval is how often the downstream config is checked and disconnected monitors are retried
+const ReconnectInterval = 5 * time.Second
+
+// AppRunsPollInterval is how often the app run list of a connected downstream monitor is refreshed
+const AppRunsPollInterval = 2 * time.Second
+
+// FederatedCommands are the (read-only) commands that are forwarded to downstream monitors,
+// other commands for downstream app runs fail. A downstream monitor only accepts these commands on
+// a federation connection (see web.HandleWsInternal).
+var FederatedCommands = map[string]bool{
+	"getappruns":               true,
+	"getapprunruntimestats":    true,
+	"subscriberuntimestats":    true,
+	"getapprunpanics":          true,
+	"getappruntimeline":        true,
+	"getapprungoroutinesbyids": true,
+	"goroutinesearchrequest":   true,
+	"goroutinetimespans":       true,
+	"getgoroutinelogs":         true,
+	"getgoroutinechurn":        true,
+	"getapprunwatchesbyids":    true,
+	"watchsearchrequest":       true,
+	"logsearchrequest":         true,
+	"logsearchrange":           true,
+	"logtail":                  true,
+	"logwidgetadmin":           true, // keepalive/drop of the widgets the hub created
+	"loggetmarkedlines":        true,
+	"logexportmarkedlines":     true,
+}
+
+// pushCommands are the commands a downstream monitor may send to the hub (they are delivered to the log widget in the data)
+var pushCommands = map[string]bool{
+	"logstreamupdate": true,
+}
+
+var (
+	linksLock   sync.Mutex
+	links       = make(map[string]*link)  // monitor name => link
+	widgetLinks = make(map[string]string) // log widget id => monitor name (widget commands don't have an app run id)
+)
+
+// DownstreamStatus is the connection state of a downstream monitor
+type DownstreamStatus struct {
+	Name       string `json:"name"`
+	Addr       string `json:"addr"`
+	Connected  bool   `json:"connected"`
+	NumAppRuns int    `json:"numappruns"`
+	LastError  string `json:"lasterror,omitempty"`
+}
+
+// Start connects to the configured downstream monitors and keeps the connections in sync with the
+// runtime settings (config reloads) until ctx is done
+func Start(ctx context.Context) {
+	rpc.GetDefaultRouter().SetRouteResolver(resolveRoute)
+	go func() {
+		outrig.SetGoRoutineName("federation.reconcile")
+		ticker := time.NewTicker(ReconnectInterval)
+		defer ticker.Stop()
+		for {
+			reconcile()
+			select {
+			case <-ctx.Done():
+				closeAllLinks()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// reconcile creates, replaces, and removes links to match the configured downstreams, and reconnects
+// disconnected links
+func reconcile() {
+	downstreams := serverbase.GetRuntimeSettings().Downstreams
+	linksLock.Lock()
+	defer linksLock.Unlock()
+	for name, l := range links {
+		if downstreams[name] != l.addr {
+			log.Printf("[federation] removing downstream monitor %q (%s)\n", name, l.hostPort)
+			l.close()
+			delete(links, name)
+		}
+	}
+	for name, addr := range downstreams {
+		l := links[name]
+		if l == nil {
+			l = makeLink(name, addr)
+			log.Printf("[federation] adding downstream monitor %q (%s)\n", name, l.hostPort)
+			links[name] = l
+		}
+		l.maybeConnect()
+	}
+}
+
+func closeAllLinks() {
+	linksLock.Lock()
+	defer linksLock.Unlock()
+	for name, l := range links {
+		l.close()
+		delete(links, name)
+	}
+}
+
+func getAllLinks() []*link {
+	linksLock.Lock()
+	defer linksLock.Unlock()
+	rtn := make([]*link, 0, len(links))
+	for _, l := range links {
+		rtn = append(rtn, l)
+	}
+	sort.Slice(rtn, func(i, j int) bool {
+		return rtn[i].name < rtn[j].name
+	})
+	return rtn
+}
+
+// resolveRoute is the router's RouteResolver, it returns the route of the downstream monitor that has
+// the app run (or log widget) of msg, or "" for local commands
+func resolveRoute(msg *rpc.RpcMessage) string {
+	data, ok := msg.Data.(map[string]any)
+	if !ok {
+		return ""
+	}
+	appRunId, _ := data["apprunid"].(string)
+	widgetId, _ := data["widgetid"].(string)
+	if appRunId != "" {
+		for _, l := range getAllLinks() {
+			if l.hasAppRun(appRunId) {
+				if widgetId != "" {
+					setWidgetLink(widgetId, l.name)
+				}
+				return l.routeId
+			}
+		}
+		if widgetId != "" {
+			// the widget was switched to a local app run
+			setWidgetLink(widgetId, "")
+		}
+		return ""
+	}
+	if widgetId == "" {
+		return ""
+	}
+	linksLock.Lock()
+	defer linksLock.Unlock()
+	name := widgetLinks[widgetId]
+	if name == "" {
+		return ""
+	}
+	if drop, _ := data["drop"].(bool); drop && msg.Command == "logwidgetadmin" {
+		delete(widgetLinks, widgetId)
+	}
+	return rpc.MakeMonitorRouteId(name)
+}
+
+func setWidgetLink(widgetId string, name string) {
+	linksLock.Lock()
+	defer linksLock.Unlock()
+	if name == "" {
+		delete(widgetLinks, widgetId)
+		return
+	}
+	widgetLinks[widgetId] = name
+}
+
+// GetAppRunInfos returns the app runs of the downstream monitors that changed after since (hub time)
+func GetAppRunInfos(since int64) []rpctypes.AppRunInfo {
+	var rtn []rpctypes.AppRunInfo
+	for _, l := range getAllLinks() {
+		rtn = append(rtn, l.getAppRunInfos(since)...)
+	}
+	return rtn
+}
+
+// GetDownstreamStatus returns the state of the configured downstream monitors (sorted by name)
+func GetDownstreamStatus() []DownstreamStatus {
+	allLinks := getAllLinks()
+	rtn := make([]DownstreamStatus, 0, len(allLinks))
+	for _, l := range allLinks {
+		rtn = append(rtn, l.getStatus())
+	}
+	return rtn
+}
@@ -0,0 +1,415 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package federation
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/server/pkg/apppeer"
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpcclient"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+const (
+	DialTimeout  = 5 * time.Second
+	WriteTimeout = 10 * time.Second
+
+	// ReadTimeout closes a downstream connection that stopped sending (the monitor pings every 10s)
+	ReadTimeout = 30 * time.Second
+
+	appRunsTimeoutMs = 5000
+)
+
+// websocket event types (see web.WSEventType)
+const (
+	wsEventType_Rpc  = "rpc"
+	wsEventType_Ping = "ping"
+	wsEventType_Pong = "pong"
+)
+
+type wsEvent struct {
+	Type string          `json:"type"`
+	Ts   int64           `json:"ts"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// appRunEntry is a downstream app run, LastModTime of info is in hub time (so "since" queries work across clocks)
+type appRunEntry struct {
+	info          rpctypes.AppRunInfo
+	remoteModTime int64 // LastModTime on the downstream monitor
+}
+
+// link is the connection to one downstream monitor
+type link struct {
+	name     string
+	addr     string // apikey@host:port as configured
+	apiKey   string // federation api key created on the downstream monitor
+	hostPort string
+	routeId  string
+
+	lock          sync.Mutex
+	connected     bool
+	connecting    bool
+	closed        bool            // removed from the config
+	conn          *websocket.Conn // nil when not connected
+	lastError     string
+	widgetSources map[string]string // log widget id => hub route of the widget (where the downstream's pushes go)
+	appRuns       map[string]*appRunEntry
+}
+
+func makeLink(name string, addr string) *link {
+	apiKey, hostPort := serverbase.SplitDownstreamAddr(addr)
+	return &link{
+		name:     name,
+		addr:     addr,
+		apiKey:   apiKey,
+		hostPort: hostPort,
+		routeId:  rpc.MakeMonitorRouteId(name),
+		appRuns:  make(map[string]*appRunEntry),
+	}
+}
+
+// maybeConnect starts connecting if the link is not connected (or connecting) yet
+func (l *link) maybeConnect() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.connected || l.connecting || l.closed {
+		return
+	}
+	l.connecting = true
+	go func() {
+		outrig.SetGoRoutineName("federation.link:" + l.name)
+		err := l.run()
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		l.connecting = false
+		if err != nil && !l.closed {
+			if l.lastError != err.Error() {
+				log.Printf("[federation] downstream monitor %q (%s): %v\n", l.name, l.hostPort, err)
+			}
+			l.lastError = err.Error()
+		}
+	}()
+}
+
+// close disconnects the link, it won't reconnect
+func (l *link) close() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.closed = true
+	if l.conn != nil {
+		l.conn.Close()
+	}
+}
+
+// run connects to the downstream monitor and forwards messages until the connection is lost
+func (l *link) run() error {
+	wsURL := &url.URL{
+		Scheme:   "ws",
+		Host:     l.hostPort,
+		Path:     "/ws",
+		RawQuery: url.Values{"routeid": []string{rpc.RoutePrefix_Hub + uuid.New().String()}}.Encode(),
+	}
+	dialer := websocket.Dialer{HandshakeTimeout: DialTimeout}
+	header := http.Header{"Authorization": []string{"Bearer " + l.apiKey}}
+	conn, resp, err := dialer.Dial(wsURL.String(), header)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			return fmt.Errorf("cannot connect: api key rejected (%s), it needs the %q scope", resp.Status, serverbase.ApiKeyScopeFederation)
+		}
+		return fmt.Errorf("cannot connect: %w", err)
+	}
+	defer conn.Close()
+	l.lock.Lock()
+	if l.closed {
+		l.lock.Unlock()
+		return nil
+	}
+	l.conn = conn
+	l.connected = true
+	l.connecting = false
+	l.lastError = ""
+	l.widgetSources = make(map[string]string)
+	l.lock.Unlock()
+	log.Printf("[federation] connected to downstream monitor %q (%s)\n", l.name, l.hostPort)
+
+	proxy := rpc.MakeRpcProxy()
+	rpc.GetDefaultRouter().RegisterRoute(l.routeId, proxy, false)
+	doneCh := make(chan struct{})
+	pongCh := make(chan struct{}, 1)
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		outrig.SetGoRoutineName("federation.write:" + l.name)
+		defer wg.Done()
+		l.writeLoop(conn, proxy, pongCh, doneCh)
+	}()
+	go func() {
+		outrig.SetGoRoutineName("federation.apppoll:" + l.name)
+		defer wg.Done()
+		l.pollAppRuns(doneCh)
+	}()
+	readErr := l.readLoop(conn, proxy, pongCh)
+	close(doneCh)
+	conn.Close()
+	wg.Wait()
+	rpc.GetDefaultRouter().UnregisterRoute(l.routeId)
+	close(proxy.FromRemoteCh)
+	close(proxy.ToRemoteCh)
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.conn = nil
+	l.connected = false
+	l.markAppRunsDisconnected_nolock()
+	if l.closed {
+		return nil
+	}
+	return fmt.Errorf("connection lost: %w", readErr)
+}
+
+// readLoop forwards the responses (and allowed pushes) of the downstream monitor to the hub's router
+func (l *link) readLoop(conn *websocket.Conn, proxy *rpc.WshRpcProxy, pongCh chan struct{}) error {
+	for {
+		conn.SetReadDeadline(time.Now().Add(ReadTimeout))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		var event wsEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			continue
+		}
+		switch event.Type {
+		case wsEventType_Ping:
+			select {
+			case pongCh <- struct{}{}:
+			default:
+			}
+		case wsEventType_Rpc:
+			msgBytes, ok := l.filterIncoming(event.Data)
+			if ok {
+				proxy.FromRemoteCh <- msgBytes
+			}
+		}
+	}
+}
+
+// filterIncoming lets responses (routed by the hub's router with the rpc id) and the pushCommands for the
+// hub's log widgets through. Replies to a push are sent back through this link. The messages of a databatch
+// message are filtered individually.
+func (l *link) filterIncoming(msgBytes []byte) ([]byte, bool) {
+	var msg rpc.RpcMessage
+	if err := json.Unmarshal(msgBytes, &msg); err != nil {
+		return nil, false
+	}
+	if msg.DataBatch != nil {
+		var batch [][]byte
+		for _, batchMsgBytes := range msg.DataBatch {
+			if filtered, ok := l.filterIncoming(batchMsgBytes); ok {
+				batch = append(batch, filtered)
+			}
+		}
+		if len(batch) == 0 {
+			return nil, false
+		}
+		return rpc.MakeBatchMessage(batch), true
+	}
+	if msg.Command == "" {
+		return msgBytes, true
+	}
+	if !pushCommands[msg.Command] {
+		return nil, false
+	}
+	data, _ := msg.Data.(map[string]any)
+	widgetId, _ := data["widgetid"].(string)
+	route := l.getWidgetSource(widgetId)
+	if route == "" {
+		return nil, false
+	}
+	msg.Route = route
+	msg.Source = l.routeId
+	rtn, err := json.Marshal(msg)
+	if err != nil {
+		return nil, false
+	}
+	return rtn, true
+}
+
+// writeLoop forwards the hub's commands for this link to the downstream monitor
+func (l *link) writeLoop(conn *websocket.Conn, proxy *rpc.WshRpcProxy, pongCh chan struct{}, doneCh chan struct{}) {
+	for {
+		var event wsEvent
+		select {
+		case <-doneCh:
+			return
+		case <-pongCh:
+			event = wsEvent{Type: wsEventType_Pong, Ts: time.Now().UnixMilli()}
+		case msgBytes := <-proxy.ToRemoteCh:
+			msgBytes = l.prepareOutgoing(msgBytes, proxy)
+			if msgBytes == nil {
+				continue
+			}
+			event = wsEvent{Type: wsEventType_Rpc, Ts: time.Now().UnixMilli(), Data: msgBytes}
+		}
+		barr, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+		if err := conn.WriteMessage(websocket.TextMessage, barr); err != nil {
+			conn.Close()
+			return
+		}
+	}
+}
+
+// prepareOutgoing returns the message to send for a message routed to this link (nil to drop it).
+// Commands that are not FederatedCommands get an error response. The route and source of a command are
+// cleared, so the downstream monitor runs the command itself and sends the responses back through this
+// connection (the hub's router knows where they go from the rpc id).
+func (l *link) prepareOutgoing(msgBytes []byte, proxy *rpc.WshRpcProxy) []byte {
+	var msg rpc.RpcMessage
+	if err := json.Unmarshal(msgBytes, &msg); err != nil {
+		return nil
+	}
+	if msg.Command == "" {
+		// stream messages and cancels are routed by the downstream router with the rpc id
+		return msgBytes
+	}
+	if !FederatedCommands[msg.Command] {
+		if msg.ReqId != "" {
+			resp := rpc.RpcMessage{ResId: msg.ReqId, Error: fmt.Sprintf("%s is not available for app runs of downstream monitor %q (read-only)", msg.Command, l.name)}
+			respBytes, _ := json.Marshal(resp)
+			proxy.FromRemoteCh <- respBytes
+		}
+		return nil
+	}
+	if data, ok := msg.Data.(map[string]any); ok {
+		if widgetId, _ := data["widgetid"].(string); widgetId != "" {
+			drop, _ := data["drop"].(bool)
+			l.setWidgetSource(widgetId, msg.Source, drop && msg.Command == "logwidgetadmin")
+		}
+	}
+	msg.Route = ""
+	msg.Source = ""
+	rtn, err := json.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+	return rtn
+}
+
+// setWidgetSource records the hub route of a log widget (so the downstream's pushes for it can be delivered)
+func (l *link) setWidgetSource(widgetId string, source string, drop bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if drop {
+		delete(l.widgetSources, widgetId)
+		return
+	}
+	if source != "" {
+		l.widgetSources[widgetId] = source
+	}
+}
+
+func (l *link) getWidgetSource(widgetId string) string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.widgetSources[widgetId]
+}
+
+// pollAppRuns refreshes the downstream app runs (through the router, like any other command) until doneCh is closed
+func (l *link) pollAppRuns(doneCh chan struct{}) {
+	ticker := time.NewTicker(AppRunsPollInterval)
+	defer ticker.Stop()
+	for {
+		data, err := rpcclient.GetAppRunsCommand(rpcclient.GetBareClient(), rpctypes.AppRunUpdatesRequest{}, &rpc.RpcOpts{Route: l.routeId, Timeout: appRunsTimeoutMs})
+		if err == nil {
+			l.updateAppRuns(data.AppRuns)
+		}
+		select {
+		case <-doneCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateAppRuns replaces the downstream app runs, app runs that changed on the downstream monitor get a new LastModTime
+func (l *link) updateAppRuns(appRuns []rpctypes.AppRunInfo) {
+	now := time.Now().UnixMilli()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if !l.connected {
+		return
+	}
+	next := make(map[string]*appRunEntry, len(appRuns))
+	for _, info := range appRuns {
+		entry := l.appRuns[info.AppRunId]
+		if entry == nil || entry.remoteModTime != info.LastModTime {
+			entry = &appRunEntry{remoteModTime: info.LastModTime}
+			info.Monitor = l.name
+			info.LastModTime = now
+			entry.info = info
+		}
+		next[info.AppRunId] = entry
+	}
+	l.appRuns = next
+}
+
+// markAppRunsDisconnected_nolock shows the running app runs as disconnected while the downstream monitor is unreachable
+func (l *link) markAppRunsDisconnected_nolock() {
+	now := time.Now().UnixMilli()
+	for _, entry := range l.appRuns {
+		if !entry.info.IsRunning {
+			continue
+		}
+		entry.info.IsRunning = false
+		entry.info.Status = apppeer.AppStatusDisconnected
+		entry.info.LastModTime = now
+		entry.remoteModTime = 0 // refresh after reconnecting
+	}
+}
+
+func (l *link) hasAppRun(appRunId string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.appRuns[appRunId] != nil
+}
+
+func (l *link) getAppRunInfos(since int64) []rpctypes.AppRunInfo {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	var rtn []rpctypes.AppRunInfo
+	for _, entry := range l.appRuns {
+		if entry.info.LastModTime > since {
+			rtn = append(rtn, entry.info)
+		}
+	}
+	return rtn
+}
+
+func (l *link) getStatus() DownstreamStatus {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return DownstreamStatus{
+		Name:       l.name,
+		Addr:       l.hostPort,
+		Connected:  l.connected,
+		NumAppRuns: len(l.appRuns),
+		LastError:  l.lastError,
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package federation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// makeTestLink makes a connected link with the given app runs and adds it to links
+func makeTestLink(t *testing.T, name string, appRunIds ...string) *link {
+	t.Helper()
+	l := makeLink(name, "ork_key@"+name+".internal:5005")
+	l.connected = true
+	l.widgetSources = make(map[string]string)
+	var appRuns []rpctypes.AppRunInfo
+	for _, appRunId := range appRunIds {
+		appRuns = append(appRuns, rpctypes.AppRunInfo{AppRunId: appRunId})
+	}
+	l.updateAppRuns(appRuns)
+	linksLock.Lock()
+	links[name] = l
+	linksLock.Unlock()
+	t.Cleanup(func() {
+		linksLock.Lock()
+		defer linksLock.Unlock()
+		delete(links, name)
+		for widgetId, linkName := range widgetLinks {
+			if linkName == name {
+				delete(widgetLinks, widgetId)
+			}
+		}
+	})
+	return l
+}
+
+func marshalTestMsg(t *testing.T, msg rpc.RpcMessage) []byte {
+	t.Helper()
+	barr, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return barr
+}
+
+func unmarshalTestMsg(t *testing.T, barr []byte) rpc.RpcMessage {
+	t.Helper()
+	var msg rpc.RpcMessage
+	if err := json.Unmarshal(barr, &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestMakeLink(t *testing.T) {
+	l := makeLink("staging", "ork_abc@staging.internal:5005")
+	if l.apiKey != "ork_abc" || l.hostPort != "staging.internal:5005" {
+		t.Errorf("got key %q and host %q", l.apiKey, l.hostPort)
+	}
+	if status := l.getStatus(); status.Addr != "staging.internal:5005" {
+		t.Errorf("got status addr %q, the api key should not be shown", status.Addr)
+	}
+}
+
+func TestResolveRoute(t *testing.T) {
+	staging := makeTestLink(t, "staging", "run1")
+
+	tests := []struct {
+		name   string
+		msg    rpc.RpcMessage
+		expect string
+	}{
+		{"local app run", rpc.RpcMessage{Command: "getapprunpanics", Data: map[string]any{"apprunid": "local1"}}, ""},
+		{"downstream app run", rpc.RpcMessage{Command: "getapprunpanics", Data: map[string]any{"apprunid": "run1"}}, staging.routeId},
+		{"widget on a downstream app run", rpc.RpcMessage{Command: "logsearchrequest", Data: map[string]any{"apprunid": "run1", "widgetid": "w1"}}, staging.routeId},
+		{"widget command without an app run", rpc.RpcMessage{Command: "logwidgetadmin", Data: map[string]any{"widgetid": "w1"}}, staging.routeId},
+		{"dropping the widget", rpc.RpcMessage{Command: "logwidgetadmin", Data: map[string]any{"widgetid": "w1", "drop": true}}, staging.routeId},
+		{"dropped widget", rpc.RpcMessage{Command: "logwidgetadmin", Data: map[string]any{"widgetid": "w1"}}, ""},
+		{"widget switched to a local app run", rpc.RpcMessage{Command: "logsearchrequest", Data: map[string]any{"apprunid": "local1", "widgetid": "w2"}}, ""},
+		{"no data", rpc.RpcMessage{Command: "getappruns"}, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resolveRoute(&tc.msg); got != tc.expect {
+				t.Errorf("got route %q, want %q", got, tc.expect)
+			}
+		})
+	}
+
+	// a widget moved from a downstream app run to a local one isn't routed to the downstream monitor anymore
+	resolveRoute(&rpc.RpcMessage{Command: "logsearchrequest", Data: map[string]any{"apprunid": "run1", "widgetid": "w3"}})
+	resolveRoute(&rpc.RpcMessage{Command: "logsearchrequest", Data: map[string]any{"apprunid": "local1", "widgetid": "w3"}})
+	if got := resolveRoute(&rpc.RpcMessage{Command: "logwidgetadmin", Data: map[string]any{"widgetid": "w3"}}); got != "" {
+		t.Errorf("got route %q for a widget switched to a local app run", got)
+	}
+}
+
+func TestPrepareOutgoing(t *testing.T) {
+	l := makeTestLink(t, "staging", "run1")
+	proxy := rpc.MakeRpcProxy()
+
+	// a federated command runs on the downstream monitor, the hub route of the widget is recorded
+	out := l.prepareOutgoing(marshalTestMsg(t, rpc.RpcMessage{Command: "logtail", ReqId: "req1", Route: l.routeId, Source: "tab:1", Data: map[string]any{"apprunid": "run1", "widgetid": "w1"}}), proxy)
+	if out == nil {
+		t.Fatal("federated command was dropped")
+	}
+	if msg := unmarshalTestMsg(t, out); msg.Route != "" || msg.Source != "" || msg.ReqId != "req1" {
+		t.Errorf("got route %q, source %q, reqid %q, want the route and source cleared", msg.Route, msg.Source, msg.ReqId)
+	}
+	if got := l.getWidgetSource("w1"); got != "tab:1" {
+		t.Errorf("got widget source %q, want tab:1", got)
+	}
+
+	// stream messages and cancels are passed through
+	cancel := marshalTestMsg(t, rpc.RpcMessage{ReqId: "req1", Cancel: true})
+	if out := l.prepareOutgoing(cancel, proxy); string(out) != string(cancel) {
+		t.Errorf("got %s for a cancel", out)
+	}
+
+	// other commands get an error response
+	for _, command := range []string{"clearapprun", "logupdatemarkedlines"} {
+		out := l.prepareOutgoing(marshalTestMsg(t, rpc.RpcMessage{Command: command, ReqId: "req2", Data: map[string]any{"apprunid": "run1"}}), proxy)
+		if out != nil {
+			t.Errorf("%s was forwarded to the downstream monitor", command)
+		}
+		resp := unmarshalTestMsg(t, <-proxy.FromRemoteCh)
+		if resp.ResId != "req2" || resp.Error == "" {
+			t.Errorf("got response %+v for %s, want an error", resp, command)
+		}
+	}
+
+	// dropping the widget forgets its source
+	l.prepareOutgoing(marshalTestMsg(t, rpc.RpcMessage{Command: "logwidgetadmin", Source: "tab:1", Data: map[string]any{"widgetid": "w1", "drop": true}}), proxy)
+	if got := l.getWidgetSource("w1"); got != "" {
+		t.Errorf("got widget source %q after the drop", got)
+	}
+}
+
+func TestFilterIncoming(t *testing.T) {
+	l := makeTestLink(t, "staging", "run1")
+	l.setWidgetSource("w1", "tab:1", false)
+
+	// responses are routed by the hub's router
+	resp := marshalTestMsg(t, rpc.RpcMessage{ResId: "req1", Data: "ok"})
+	if out, ok := l.filterIncoming(resp); !ok || string(out) != string(resp) {
+		t.Errorf("got %s (ok=%v) for a response", out, ok)
+	}
+
+	// pushes for the hub's widgets are delivered to the widget's route
+	out, ok := l.filterIncoming(marshalTestMsg(t, rpc.RpcMessage{Command: "logstreamupdate", Data: map[string]any{"widgetid": "w1"}}))
+	if !ok {
+		t.Fatal("push for a hub widget was dropped")
+	}
+	if msg := unmarshalTestMsg(t, out); msg.Route != "tab:1" || msg.Source != l.routeId {
+		t.Errorf("got route %q and source %q, want tab:1 from %q", msg.Route, msg.Source, l.routeId)
+	}
+
+	dropped := []rpc.RpcMessage{
+		{Command: "logstreamupdate", Data: map[string]any{"widgetid": "unknown"}},
+		{Command: "clearapprun", Route: "tab:1", Data: map[string]any{"apprunid": "local1"}},
+		{Command: "getappruns", ReqId: "req2"},
+	}
+	for _, msg := range dropped {
+		if out, ok := l.filterIncoming(marshalTestMsg(t, msg)); ok {
+			t.Errorf("got %s, want %s dropped", out, msg.Command)
+		}
+	}
+
+	// the messages of a batch are filtered individually
+	batch := rpc.MakeBatchMessage([][]byte{resp, marshalTestMsg(t, dropped[1])})
+	out, ok = l.filterIncoming(batch)
+	if !ok {
+		t.Fatal("batch was dropped")
+	}
+	if msg := unmarshalTestMsg(t, out); len(msg.DataBatch) != 1 || string(msg.DataBatch[0]) != string(resp) {
+		t.Errorf("got batch %s, want only the response", out)
+	}
+	if _, ok := l.filterIncoming(rpc.MakeBatchMessage([][]byte{marshalTestMsg(t, dropped[1])})); ok {
+		t.Errorf("a batch without allowed messages should be dropped")
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	MaxIndexWordLen    = 64   // longer words are not indexed, the lines that have one are always candidates
+	MinIndexTermLen    = 2    // shorter words of a search term are not looked up (they would match most lines)
+	IndexPruneInterval = 1000 // number of lines dropped from the store before the dropped positions are pruned
+)
+
+// LogIndex is an inverted index of the words in the log lines of an app run. It maps each (lowercased) word to
+// the positions (absolute index in the log line store) of the lines that contain it. It is updated as the lines
+// are stored, so exact searches only have to scan the lines that contain their terms.
+//
+// The index only narrows down the lines to search: a line that contains a term has a word that contains each
+// of the term's words, so the lines returned by GetCandidates are a superset of the matches and the searcher
+// still runs on every one of them.
+type LogIndex struct {
+	lock       sync.Mutex
+	words      map[string][]int // word => ascending positions of the lines that contain it
+	vocab      []string         // the words of the index, only appended to (GetCandidates scans a snapshot of it without the lock)
+	longLines  []int            // positions of the lines with a word longer than MaxIndexWordLen
+	nextPos    int              // position after the last added line
+	headOffset int              // positions below this have been dropped from the store
+	prunedTo   int              // positions below this have been removed from the index
+}
+
+// MakeLogIndex creates an empty index
+func MakeLogIndex() *LogIndex {
+	return &LogIndex{
+		words: make(map[string][]int),
+	}
+}
+
+// AddLine indexes the words of the line stored at pos (lines must be added in position order)
+func (idx *LogIndex) AddLine(pos int, msg string) {
+	words := getIndexWords(msg)
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	hasLongWord := false
+	for _, word := range words {
+		if len(word) > MaxIndexWordLen {
+			hasLongWord = true
+			continue
+		}
+		positions, exists := idx.words[word]
+		if !exists {
+			idx.vocab = append(idx.vocab, word)
+		}
+		idx.words[word] = append(positions, pos)
+	}
+	if hasLongWord {
+		idx.longLines = append(idx.longLines, pos)
+	}
+	idx.nextPos = pos + 1
+}
+
+// SetHeadOffset records that the lines below headOffset were dropped from the store,
+// their positions are removed from the index once IndexPruneInterval lines have been dropped
+func (idx *LogIndex) SetHeadOffset(headOffset int) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	if headOffset <= idx.headOffset {
+		return
+	}
+	idx.headOffset = headOffset
+	if headOffset-idx.prunedTo < IndexPruneInterval {
+		return
+	}
+	for word, positions := range idx.words {
+		start := sort.SearchInts(positions, headOffset)
+		if start == len(positions) {
+			delete(idx.words, word)
+		} else if start > 0 {
+			idx.words[word] = positions[start:]
+		}
+	}
+	idx.longLines = idx.longLines[sort.SearchInts(idx.longLines, headOffset):]
+	idx.prunedTo = headOffset
+	// a new slice, snapshots of the old one stay valid
+	vocab := make([]string, 0, len(idx.words))
+	for word := range idx.words {
+		vocab = append(vocab, word)
+	}
+	idx.vocab = vocab
+}
+
+// NumWords returns the number of distinct words in the index
+func (idx *LogIndex) NumWords() int {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	return len(idx.words)
+}
+
+// GetCandidates returns the ascending positions of the lines that can contain all of the terms.
+// It returns false if none of the terms can be looked up (the lines have to be scanned).
+func (idx *LogIndex) GetCandidates(terms []string) ([]int, bool) {
+	var termWords []string
+	for _, term := range terms {
+		for _, word := range getIndexWords(term) {
+			if len(word) >= MinIndexTermLen {
+				termWords = append(termWords, word)
+			}
+		}
+	}
+	if len(termWords) == 0 {
+		return nil, false
+	}
+	slices.Sort(termWords)
+	termWords = slices.Compact(termWords)
+
+	// the term words can be any part of a line's word ("err" matches "error"), so the words to look up are
+	// found by scanning the vocabulary, which is done on a snapshot to not block AddLine. The candidates are
+	// limited to the lines added before the snapshot (the newer lines can have words that aren't in it).
+	idx.lock.Lock()
+	vocab, limit := idx.vocab, idx.nextPos
+	idx.lock.Unlock()
+	lookupWords := make([][]string, len(termWords))
+	for i, termWord := range termWords {
+		for _, word := range vocab {
+			if strings.Contains(word, termWord) {
+				lookupWords[i] = append(lookupWords[i], word)
+			}
+		}
+	}
+
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	var rtn []int
+	for i := range termWords {
+		positions := slices.Clone(idx.longLines)
+		for _, word := range lookupWords[i] {
+			positions = append(positions, idx.words[word]...)
+		}
+		slices.Sort(positions)
+		positions = slices.Compact(positions)
+		positions = positions[sort.SearchInts(positions, idx.headOffset):sort.SearchInts(positions, limit)]
+		if i == 0 {
+			rtn = positions
+		} else {
+			rtn = intersectSorted(rtn, positions)
+		}
+		if len(rtn) == 0 {
+			break
+		}
+	}
+	return rtn, true
+}
+
+// intersectSorted returns the values that are in both ascending slices (reusing a's storage)
+func intersectSorted(a []int, b []int) []int {
+	rtn := a[:0]
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] < b[j] {
+			i++
+		} else if a[i] > b[j] {
+			j++
+		} else {
+			rtn = append(rtn, a[i])
+			i++
+			j++
+		}
+	}
+	return rtn
+}
+
+// getIndexWords returns the distinct lowercased words (runs of letters, digits, and underscores) of a string
+func getIndexWords(str string) []string {
+	words := strings.FieldsFunc(strings.ToLower(str), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	slices.Sort(words)
+	return slices.Compact(words)
+}
+
+// GetIndexTerms returns the terms that every match of the searcher must contain in its message (nil if there
+// aren't any). Only exact searches on the message that are ANDed at the top level of the search count, so the
+// terms can be looked up in the LogIndex before the search runs. userQuery is the searcher that #userquery
+// refers to (can be nil).
+func GetIndexTerms(searcher Searcher, userQuery Searcher) []string {
+	switch s := searcher.(type) {
+	case *ExactSearcher:
+		if s.field != "" && s.field != "msg" && s.field != "line" {
+			return nil
+		}
+		return []string{s.searchTerm}
+	case *AndSearcher:
+		var rtn []string
+		for _, child := range s.searchers {
+			rtn = append(rtn, GetIndexTerms(child, userQuery)...)
+		}
+		return rtn
+	case *UserQuerySearcher:
+		if userQuery == nil {
+			return nil
+		}
+		return GetIndexTerms(userQuery, nil)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLogIndexGetCandidates(t *testing.T) {
+	idx := MakeLogIndex()
+	lines := []string{
+		"connection error: timeout",
+		"request handled in 5ms",
+		"Error writing file",
+		"retrying connection",
+		"user_id=42 logged in",
+		strings.Repeat("x", MaxIndexWordLen+1) + " other",
+	}
+	for pos, line := range lines {
+		idx.AddLine(pos, line)
+	}
+
+	tests := []struct {
+		name   string
+		terms  []string
+		expect []int
+		ok     bool
+	}{
+		{"single word", []string{"connection"}, []int{0, 3, 5}, true},
+		{"part of a word", []string{"err"}, []int{0, 2, 5}, true},
+		{"case insensitive", []string{"ERROR"}, []int{0, 2, 5}, true},
+		{"all terms", []string{"connection", "error"}, []int{0, 5}, true},
+		{"words of a term", []string{"error writing"}, []int{2, 5}, true},
+		{"underscore words", []string{"user_id"}, []int{4, 5}, true},
+		{"no match", []string{"missing"}, []int{5}, true},
+		{"short terms can't be looked up", []string{"5", "x"}, nil, false},
+		{"no terms", nil, nil, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := idx.GetCandidates(tc.terms)
+			if ok != tc.ok {
+				t.Fatalf("got ok=%v, want %v", ok, tc.ok)
+			}
+			if len(got) == 0 && len(tc.expect) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("got %v, want %v", got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestLogIndexSetHeadOffset(t *testing.T) {
+	idx := MakeLogIndex()
+	numLines := IndexPruneInterval + 10
+	for pos := 0; pos < numLines; pos++ {
+		msg := "common line"
+		if pos == 5 {
+			msg = "rare line"
+		}
+		idx.AddLine(pos, msg)
+	}
+	if got := idx.NumWords(); got != 3 {
+		t.Fatalf("got %d words, want 3", got)
+	}
+
+	// below the prune interval, the dropped positions are still in the index but aren't returned
+	idx.SetHeadOffset(6)
+	if got, _ := idx.GetCandidates([]string{"rare"}); len(got) != 0 {
+		t.Errorf("got dropped candidates %v", got)
+	}
+	if got := idx.NumWords(); got != 3 {
+		t.Errorf("words pruned before the prune interval, got %d words", got)
+	}
+
+	// once enough lines were dropped, the words that only dropped lines have are removed
+	idx.SetHeadOffset(IndexPruneInterval)
+	if got := idx.NumWords(); got != 2 {
+		t.Errorf("got %d words after pruning, want 2", got)
+	}
+	got, ok := idx.GetCandidates([]string{"common"})
+	if !ok || len(got) != 10 || got[0] != IndexPruneInterval {
+		t.Errorf("got %d candidates starting at %v, want 10 starting at %d", len(got), got, IndexPruneInterval)
+	}
+	if got, _ := idx.GetCandidates([]string{"rare"}); len(got) != 0 {
+		t.Errorf("got pruned candidates %v", got)
+	}
+
+	// new words are found after the vocabulary was rebuilt by the prune
+	idx.AddLine(numLines, "brand new words")
+	if got, _ := idx.GetCandidates([]string{"brand"}); !reflect.DeepEqual(got, []int{numLines}) {
+		t.Errorf("got %v for a word added after pruning, want [%d]", got, numLines)
+	}
+}
+
+func TestGetIndexTerms(t *testing.T) {
+	tests := []struct {
+		name      string
+		search    string
+		userQuery string
+		expect    []string
+	}{
+		{"single term", "error", "", []string{"error"}},
+		{"anded terms", "error timeout", "", []string{"error", "timeout"}},
+		{"quoted term", `"connection reset"`, "", []string{"connection reset"}},
+		{"msg field", "$msg:error", "", []string{"error"}},
+		{"other field", "$source:stderr", "", nil},
+		{"ored terms", "error | timeout", "", nil},
+		{"negated term", "-error", "", nil},
+		{"regexp", "/err.*/", "", nil},
+		{"fuzzy", "~error", "", nil},
+		{"anded with a non-indexable term", "error -timeout", "", []string{"error"}},
+		{"user query", "#userquery", "error", []string{"error"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			searcher, err := GetSearcher(tc.search)
+			if err != nil {
+				t.Fatalf("GetSearcher(%q): %v", tc.search, err)
+			}
+			var userQuery Searcher
+			if tc.userQuery != "" {
+				userQuery, err = GetSearcher(tc.userQuery)
+				if err != nil {
+					t.Fatalf("GetSearcher(%q): %v", tc.userQuery, err)
+				}
+			}
+			got := GetIndexTerms(searcher, userQuery)
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("GetIndexTerms(%q) = %#v, want %#v", tc.search, got, tc.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// TailBufferSize is the number of updates buffered for a log tail before new lines are dropped
+const TailBufferSize = 64
+
+// logTail delivers the new matching lines of a search manager to a LogTailCommand stream.
+// Its fields are protected by the manager's lock.
+type logTail struct {
+	ch      chan rpctypes.LogTailData
+	dropped int // matching lines dropped since the last delivered update
+	closed  bool
+}
+
+func (t *logTail) close_nolock() {
+	if t.closed {
+		return
+	}
+	t.closed = true
+	close(t.ch)
+}
+
+// TailLogs runs the standing search for the widget (reusing the cached result if the query hasn't changed)
+// and returns a stream of the new matching lines. The tail replaces the LogStreamUpdateCommand pushes
+// for the widget, and it lives as long as the widget (keepalive/drop with LogWidgetAdminCommand).
+func (m *SearchManager) TailLogs(ctx context.Context, data rpctypes.LogTailRequest) (chan rpctypes.RespUnion[rpctypes.LogTailData], error) {
+	m.Lock.Lock()
+	defer m.Lock.Unlock()
+
+	m.LastUsed = time.Now()
+	m.RpcSource = rpc.GetRpcSourceFromContext(ctx)
+	errorSpans, err := m.maybeRunNewSearch(ctx, data.SearchTerm, data.SystemQuery, true, data.ContextBefore, data.ContextAfter, data.Dedup, data.DedupWindowMs)
+	if err != nil {
+		return nil, err
+	}
+
+	backfill := slices.Clone(m.CachedResult[len(m.CachedResult)-min(max(data.Backfill, 0), len(m.CachedResult)):])
+	initial := m.makeTailUpdate_nolock(backfill)
+	initial.ErrorSpans = errorSpans
+
+	if m.Tail != nil {
+		m.Tail.close_nolock()
+	}
+	tail := &logTail{ch: make(chan rpctypes.LogTailData, TailBufferSize)}
+	m.Tail = tail
+
+	rtnCh := make(chan rpctypes.RespUnion[rpctypes.LogTailData], 1)
+	go func() {
+		outrig.SetGoRoutineName("search.tail")
+		defer close(rtnCh)
+		rtnCh <- rpctypes.RespUnion[rpctypes.LogTailData]{Response: initial}
+		for {
+			select {
+			case <-ctx.Done():
+				m.removeTail(tail)
+				return
+			case update, ok := <-tail.ch:
+				if !ok {
+					return
+				}
+				rtnCh <- rpctypes.RespUnion[rpctypes.LogTailData]{Response: update}
+			}
+		}
+	}()
+	return rtnCh, nil
+}
+
+// makeTailUpdate_nolock creates a tail update for lines, which must be the last lines of CachedResult
+func (m *SearchManager) makeTailUpdate_nolock(lines []ds.LogLine) rpctypes.LogTailData {
+	update := rpctypes.LogTailData{
+		WidgetId:      m.WidgetId,
+		FilteredCount: len(m.CachedResult),
+		SearchedCount: m.Stats.SearchedCount,
+		TotalCount:    m.Stats.TotalCount,
+		TrimmedLines:  m.TrimmedCount,
+		Offset:        len(m.CachedResult) - len(lines) + m.TrimmedCount,
+		Lines:         lines,
+		MatchSpans:    make(map[int64][]rpctypes.SearchMatchSpan),
+	}
+	m.addMatchSpans(lines, update.MatchSpans)
+	return update
+}
+
+// sendToTail_nolock delivers new matching lines to the tail, dropping them if the client has fallen behind
+func (m *SearchManager) sendToTail_nolock(newLines []ds.LogLine) {
+	update := m.makeTailUpdate_nolock(newLines)
+	update.DroppedLines = m.Tail.dropped
+	select {
+	case m.Tail.ch <- update:
+		m.Tail.dropped = 0
+	default:
+		m.Tail.dropped += len(newLines)
+	}
+}
+
+// removeTail closes tail if it is still the manager's tail
+func (m *SearchManager) removeTail(tail *logTail) {
+	m.Lock.Lock()
+	defer m.Lock.Unlock()
+	tail.close_nolock()
+	if m.Tail == tail {
+		m.Tail = nil
+	}
+}
+
+// closeTail ends the manager's tail stream (if any)
+func (m *SearchManager) closeTail() {
+	m.Lock.Lock()
+	defer m.Lock.Unlock()
+	if m.Tail != nil {
+		m.Tail.close_nolock()
+		m.Tail = nil
+	}
+}
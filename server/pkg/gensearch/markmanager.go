@@ -4,22 +4,27 @@
 package gensearch
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
 )
 
-// MarkManager handles management of marked log lines
+// MaxMarkRangeSize is the maximum number of lines in a single MarkedRange
+const MaxMarkRangeSize = 100000
+
+// MarkManager handles management of marked log lines (one per app run, shared by the app run's search managers)
 type MarkManager struct {
 	Lock      *sync.Mutex
-	MarkedIds map[int64]bool // Map of line numbers that are marked
+	MarkedIds map[int64]rpctypes.LineMark // Map of line numbers that are marked (with their optional annotation and color)
 }
 
 // MakeMarkManager creates a new MarkManager
 func MakeMarkManager() *MarkManager {
 	return &MarkManager{
 		Lock:      &sync.Mutex{},
-		MarkedIds: make(map[int64]bool),
+		MarkedIds: make(map[int64]rpctypes.LineMark),
 	}
 }
 
@@ -28,7 +33,7 @@ func (m *MarkManager) ClearMarks() {
 	m.Lock.Lock()
 	defer m.Lock.Unlock()
 
-	m.MarkedIds = make(map[int64]bool)
+	m.MarkedIds = make(map[int64]rpctypes.LineMark)
 }
 
 // GetNumMarks returns the number of marked lines
@@ -44,28 +49,84 @@ func (m *MarkManager) GetMarkedIds() map[int64]bool {
 	defer m.Lock.Unlock()
 
 	markedLinesCopy := make(map[int64]bool, len(m.MarkedIds))
-	for lineNum, isMarked := range m.MarkedIds {
-		markedLinesCopy[lineNum] = isMarked
+	for lineNum := range m.MarkedIds {
+		markedLinesCopy[lineNum] = true
 	}
 
 	return markedLinesCopy
 }
 
+// GetMarks returns a copy of the marks (line number -> annotation and color)
+func (m *MarkManager) GetMarks() map[int64]rpctypes.LineMark {
+	m.Lock.Lock()
+	defer m.Lock.Unlock()
+
+	marksCopy := make(map[int64]rpctypes.LineMark, len(m.MarkedIds))
+	for lineNum, mark := range m.MarkedIds {
+		marksCopy[lineNum] = mark
+	}
+	return marksCopy
+}
+
 // UpdateMarkedLines updates the marked status of lines based on the provided map
-// If the value is true, the line is marked; if false, the mark is removed
+// If the value is true, the line is marked (an existing annotation is kept); if false, the mark is removed
 func (m *MarkManager) UpdateMarkedLines(marks map[int64]bool) {
 	m.Lock.Lock()
 	defer m.Lock.Unlock()
 
 	for lineNum, isMarked := range marks {
 		if isMarked {
-			m.MarkedIds[lineNum] = true
+			if _, exists := m.MarkedIds[lineNum]; !exists {
+				m.MarkedIds[lineNum] = rpctypes.LineMark{}
+			}
 		} else {
 			delete(m.MarkedIds, lineNum)
 		}
 	}
 }
 
+// ValidateMarkedRanges returns an error for the first invalid range (empty, reversed, or larger than MaxMarkRangeSize)
+func ValidateMarkedRanges(ranges []rpctypes.MarkedRange) error {
+	for _, r := range ranges {
+		if r.Start <= 0 || r.End < r.Start {
+			return fmt.Errorf("invalid mark range %d-%d", r.Start, r.End)
+		}
+		if r.End-r.Start+1 > MaxMarkRangeSize {
+			return fmt.Errorf("mark range %d-%d is too large (max %d lines)", r.Start, r.End, MaxMarkRangeSize)
+		}
+	}
+	return nil
+}
+
+// UpdateMarkedRanges marks or unmarks ranges of lines (see rpctypes.MarkedRange)
+// all the ranges are validated before any of them are applied
+func (m *MarkManager) UpdateMarkedRanges(ranges []rpctypes.MarkedRange) error {
+	if err := ValidateMarkedRanges(ranges); err != nil {
+		return err
+	}
+
+	m.Lock.Lock()
+	defer m.Lock.Unlock()
+
+	for _, r := range ranges {
+		for lineNum := r.Start; lineNum <= r.End; lineNum++ {
+			if r.Unmark {
+				delete(m.MarkedIds, lineNum)
+				continue
+			}
+			mark := m.MarkedIds[lineNum]
+			if r.Color != "" {
+				mark.Color = r.Color
+			}
+			if lineNum == r.Start && r.Annotation != "" {
+				mark.Annotation = r.Annotation
+			}
+			m.MarkedIds[lineNum] = mark
+		}
+	}
+	return nil
+}
+
 // GetMarkedLogLines returns all marked log lines from the provided logs
 func (m *MarkManager) GetMarkedLogLines(allLogs []ds.LogLine) []ds.LogLine {
 	// If no lines are marked, return empty result
@@ -0,0 +1,47 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+func TestUpdateMarkedRanges(t *testing.T) {
+	m := MakeMarkManager()
+	m.UpdateMarkedLines(map[int64]bool{1: true, 8: true})
+	err := m.UpdateMarkedRanges([]rpctypes.MarkedRange{
+		{Start: 3, End: 5, Color: "red", Annotation: "retry loop"},
+		{Start: 8, End: 8, Unmark: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[int64]rpctypes.LineMark{
+		1: {},
+		3: {Color: "red", Annotation: "retry loop"},
+		4: {Color: "red"},
+		5: {Color: "red"},
+	}
+	if got := m.GetMarks(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("got %+v, want %+v", got, expect)
+	}
+
+	// an invalid range fails the whole update
+	invalid := [][]rpctypes.MarkedRange{
+		{{Start: 10, End: 12}, {Start: 0, End: 2}},
+		{{Start: 10, End: 12}, {Start: 5, End: 4}},
+		{{Start: 10, End: 12}, {Start: 1, End: MaxMarkRangeSize + 1}},
+	}
+	for _, ranges := range invalid {
+		if err := m.UpdateMarkedRanges(ranges); err == nil {
+			t.Errorf("got nil for %+v, want an error", ranges)
+		}
+	}
+	if got := m.GetMarks(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("got %+v after the invalid updates, want the marks unchanged", got)
+	}
+}
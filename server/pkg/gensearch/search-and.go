@@ -3,6 +3,10 @@
 
 package gensearch
 
+import (
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
 // AndSearcher implements a searcher that requires all contained searchers to match
 type AndSearcher struct {
 	searchers []Searcher
@@ -32,6 +36,11 @@ func (s *AndSearcher) Match(sctx *SearchContext, obj SearchObject) bool {
 	return true
 }
 
+// GetMatchSpans returns the spans of all contained searchers
+func (s *AndSearcher) GetMatchSpans(sctx *SearchContext, obj SearchObject) []rpctypes.SearchMatchSpan {
+	return collectSpans(s.searchers, sctx, obj, false)
+}
+
 // GetType returns the search type identifier
 func (s *AndSearcher) GetType() string {
 	return SearchTypeAnd
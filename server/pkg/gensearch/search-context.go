@@ -0,0 +1,139 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"iter"
+	"log"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/searchparser"
+)
+
+// MaxContextLines caps the number of context lines before/after a match
+const MaxContextLines = 100
+
+// LogContextExpander adds the lines around each match to the search results (like grep -C).
+// It is kept by the SearchManager so streamed lines continue the context of the initial search.
+type LogContextExpander struct {
+	Before    int
+	After     int
+	Pending   []ds.LogLine // the last Before non-matching lines (possible before-context)
+	AfterLeft int          // number of after-context lines still to add
+}
+
+func MakeLogContextExpander(before int, after int) *LogContextExpander {
+	return &LogContextExpander{
+		Before: min(max(before, 0), MaxContextLines),
+		After:  min(max(after, 0), MaxContextLines),
+	}
+}
+
+// AddMatch returns the pending before-context lines followed by the matching line
+func (ce *LogContextExpander) AddMatch(line ds.LogLine) []ds.LogLine {
+	lines := make([]ds.LogLine, 0, len(ce.Pending)+1)
+	lines = append(lines, ce.Pending...)
+	lines = append(lines, line)
+	ce.Pending = ce.Pending[:0]
+	ce.AfterLeft = ce.After
+	return lines
+}
+
+// AddNonMatch returns the line marked as context if it follows a match, otherwise
+// it is remembered as possible before-context for the next match
+func (ce *LogContextExpander) AddNonMatch(line ds.LogLine) (ds.LogLine, bool) {
+	line.IsContext = true
+	if ce.AfterLeft > 0 {
+		ce.AfterLeft--
+		return line, true
+	}
+	if ce.Before == 0 {
+		return ds.LogLine{}, false
+	}
+	if len(ce.Pending) >= ce.Before {
+		ce.Pending = append(ce.Pending[:0], ce.Pending[len(ce.Pending)-ce.Before+1:]...)
+	}
+	ce.Pending = append(ce.Pending, line)
+	return ds.LogLine{}, false
+}
+
+// matchLogLine runs the searcher and the color filters on a line (nil searcher matches everything)
+func matchLogLine(searcher Searcher, sctx *SearchContext, colorFilters []ColorSearcher, line *ds.LogLine) bool {
+	if searcher == nil {
+		return true
+	}
+	searchObj := LogLineToSearchObject(*line)
+	if !searcher.Match(sctx, searchObj) {
+		return false
+	}
+	// Check color filters in reverse order (last match wins)
+	for i := len(colorFilters) - 1; i >= 0; i-- {
+		if colorFilters[i].Searcher.Match(sctx, searchObj) {
+			line.Color = searchparser.ColorToInt8(colorFilters[i].Color)
+			break
+		}
+	}
+	return true
+}
+
+// PerformLogSearchWithContext is PerformSearchSeq for log lines, adding the context lines from the expander
+// around each match and collapsing identical lines with the deduper (if not nil). Colors are applied to the
+// matching lines. If maxResults > 0 only the newest maxResults lines are returned. If sctx is canceled the
+// lines found so far are returned (stats.Canceled is set).
+func PerformLogSearchWithContext(allLogs iter.Seq[ds.LogLine], totalCount int, searcher Searcher, sctx *SearchContext, colorFilters []ColorSearcher, maxResults int, expander *LogContextExpander, deduper *LogDeduper) ([]ds.LogLine, *SearchStats) {
+	startTs := time.Now()
+	searchedCount := 0
+	result := []ds.LogLine{}
+	var lastLineNum int64
+	trimmed := 0
+	canceled := false
+	addLine := func(line ds.LogLine) {
+		if deduper != nil {
+			result, _ = deduper.Add(result, trimmed, line)
+			return
+		}
+		result = append(result, line)
+	}
+	for line := range allLogs {
+		if searchedCount%CancelCheckInterval == 0 && sctx.IsCanceled() {
+			canceled = true
+			break
+		}
+		searchedCount++
+		lastLineNum = line.LineNum
+		if maxResults > 0 && len(result) >= maxResults+TrimSize {
+			// drop the oldest lines (in chunks, so we aren't copying on every match)
+			trimmed += len(result) - maxResults
+			result = append(result[:0], result[len(result)-maxResults:]...)
+		}
+		if matchLogLine(searcher, sctx, colorFilters, &line) {
+			for _, matchLine := range expander.AddMatch(line) {
+				addLine(matchLine)
+			}
+			continue
+		}
+		if contextLine, ok := expander.AddNonMatch(line); ok {
+			addLine(contextLine)
+		}
+	}
+	if maxResults > 0 && len(result) > maxResults {
+		trimmed += len(result) - maxResults
+		result = result[len(result)-maxResults:]
+	}
+	if deduper != nil {
+		// the returned result starts at index 0, streamed lines are added with the manager's (reset) trimmed count
+		deduper.Rebase(trimmed)
+	}
+	searchDuration := int(time.Since(startTs).Milliseconds())
+	stats := &SearchStats{
+		TotalCount:     totalCount,
+		SearchedCount:  searchedCount,
+		LastLineNum:    lastLineNum,
+		SearchDuration: searchDuration,
+		Canceled:       canceled,
+	}
+	log.Printf("SearchManager: filtered %d/%d items (with context) in %dms%s\n", len(result), searchedCount, searchDuration, canceledLogSuffix(canceled))
+	return result, stats
+}
@@ -0,0 +1,70 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMakeLogContextExpander(t *testing.T) {
+	ce := MakeLogContextExpander(-1, MaxContextLines+50)
+	if ce.Before != 0 || ce.After != MaxContextLines {
+		t.Errorf("got before %d, after %d, want the counts clamped to [0, %d]", ce.Before, ce.After, MaxContextLines)
+	}
+}
+
+func TestPerformLogSearchWithContext(t *testing.T) {
+	peer := makeTestPeer(false, "a", "b", "c", "ERR 1", "d", "e", "f", "ERR 2", "ERR 3", "g", "h")
+	searcher, err := GetSearcher("ERR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expander := MakeLogContextExpander(2, 1)
+	allLogs, totalCount := peer.GetLogLineSeq()
+	result, stats := PerformLogSearchWithContext(allLogs, totalCount, searcher, &SearchContext{}, nil, 0, expander, nil)
+	// only the last 2 lines before a match are context, the lines between adjacent matches aren't repeated
+	expect := []string{
+		"b (context)", "c (context)", "ERR 1", "d (context)",
+		"e (context)", "f (context)", "ERR 2", "ERR 3", "g (context)",
+	}
+	if got := getLineSummary(result); !reflect.DeepEqual(got, expect) {
+		t.Errorf("got %v, want %v", got, expect)
+	}
+	if stats.SearchedCount != 11 || stats.LastLineNum != 11 {
+		t.Errorf("got stats %+v, want all 11 lines searched", stats)
+	}
+
+	// streamed lines continue from the expander's state ("h" is pending before-context)
+	if _, ok := expander.AddNonMatch(peer.addLine("i")); ok {
+		t.Errorf("a line after the after-context was added")
+	}
+	if got := getLineSummary(expander.AddMatch(peer.addLine("ERR 4"))); !reflect.DeepEqual(got, []string{"h (context)", "i (context)", "ERR 4"}) {
+		t.Errorf("got %v for the streamed match, want the 2 pending lines and the match", got)
+	}
+	if line, ok := expander.AddNonMatch(peer.addLine("j")); !ok || !line.IsContext {
+		t.Errorf("got %+v, %v, want the line after the match as context", line, ok)
+	}
+}
+
+func TestPerformLogSearchWithContextMaxResults(t *testing.T) {
+	var msgs []string
+	for i := 0; i < TrimSize+20; i++ {
+		msgs = append(msgs, "ERR", "x")
+	}
+	peer := makeTestPeer(false, msgs...)
+	searcher, err := GetSearcher("ERR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	allLogs, totalCount := peer.GetLogLineSeq()
+	result, _ := PerformLogSearchWithContext(allLogs, totalCount, searcher, &SearchContext{}, nil, 5, MakeLogContextExpander(0, 1), nil)
+	// the newest lines (matches and context) are kept
+	if got := getLineSummary(result); !reflect.DeepEqual(got, []string{"x (context)", "ERR", "x (context)", "ERR", "x (context)"}) {
+		t.Errorf("got %v, want the newest 5 lines", got)
+	}
+	if result[len(result)-1].LineNum != int64(len(msgs)) {
+		t.Errorf("got last line %d, want %d", result[len(result)-1].LineNum, len(msgs))
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+// MaxDedupWindowMs caps the window of a LogDeduper
+const MaxDedupWindowMs = 60 * 60 * 1000
+
+// MaxDedupUpdateLines is how far back (in result lines) a streamed line can update a collapsed entry, older entries
+// get their new repeat count when the client loads their page again
+const MaxDedupUpdateLines = 1000
+
+// dedupPruneSize is the number of tracked messages before a window deduper drops the expired ones
+const dedupPruneSize = 1000
+
+// LogDeduper collapses identical log lines (same message and source) in the search results into one entry
+// with a repeat count (so a panic in a tight loop doesn't drown the log view). With WindowMs == 0 only
+// consecutive lines are collapsed, otherwise a line is collapsed into the entry of an identical line that
+// started less than WindowMs before it (even if other lines were logged in between).
+// Context lines are never collapsed. It is kept by the SearchManager so streamed lines continue the dedup
+// of the initial search.
+type LogDeduper struct {
+	WindowMs  int64
+	entries   map[string]dedupEntry // window mode: dedup key => the entry of the last line with that key
+	nextPrune int
+}
+
+type dedupEntry struct {
+	logicalIdx int   // index in the results including the trimmed lines
+	firstTs    int64 // timestamp of the entry's first line
+}
+
+func MakeLogDeduper(windowMs int64) *LogDeduper {
+	windowMs = min(max(windowMs, 0), MaxDedupWindowMs)
+	d := &LogDeduper{WindowMs: windowMs}
+	if windowMs > 0 {
+		d.entries = make(map[string]dedupEntry)
+		d.nextPrune = dedupPruneSize
+	}
+	return d
+}
+
+func dedupKey(line *ds.LogLine) string {
+	return line.Source + "\x00" + line.Msg
+}
+
+func isSameLogLine(a *ds.LogLine, b *ds.LogLine) bool {
+	return a.Msg == b.Msg && a.Source == b.Source
+}
+
+// Add appends line to result, or collapses it into the entry of an identical line. trimmed is the number of
+// lines that were removed from the front of result since the deduper was created. It returns the new result
+// and the index (in result) of the entry the line was collapsed into, or -1 if the line was appended.
+func (d *LogDeduper) Add(result []ds.LogLine, trimmed int, line ds.LogLine) ([]ds.LogLine, int) {
+	if line.IsContext {
+		return append(result, line), -1
+	}
+	if d.WindowMs == 0 {
+		if len(result) > 0 {
+			last := &result[len(result)-1]
+			if !last.IsContext && isSameLogLine(last, &line) {
+				collapseLogLine(last, &line)
+				return result, len(result) - 1
+			}
+		}
+		return append(result, line), -1
+	}
+	key := dedupKey(&line)
+	if entry, ok := d.entries[key]; ok && line.Ts-entry.firstTs < d.WindowMs {
+		idx := entry.logicalIdx - trimmed
+		if idx >= 0 && idx < len(result) && isSameLogLine(&result[idx], &line) {
+			collapseLogLine(&result[idx], &line)
+			return result, idx
+		}
+	}
+	d.entries[key] = dedupEntry{logicalIdx: len(result) + trimmed, firstTs: line.Ts}
+	if len(d.entries) >= d.nextPrune {
+		d.prune(line.Ts)
+	}
+	return append(result, line), -1
+}
+
+// Rebase makes the entries relative to a result that had its first trimmed lines removed (so later calls to Add
+// count the trimmed lines from that result), entries of removed lines are dropped
+func (d *LogDeduper) Rebase(trimmed int) {
+	for key, entry := range d.entries {
+		if entry.logicalIdx < trimmed {
+			delete(d.entries, key)
+			continue
+		}
+		entry.logicalIdx -= trimmed
+		d.entries[key] = entry
+	}
+}
+
+// prune drops the entries whose window has expired
+func (d *LogDeduper) prune(nowTs int64) {
+	for key, entry := range d.entries {
+		if nowTs-entry.firstTs >= d.WindowMs {
+			delete(d.entries, key)
+		}
+	}
+	d.nextPrune = max(dedupPruneSize, 2*len(d.entries))
+}
+
+func collapseLogLine(entry *ds.LogLine, line *ds.LogLine) {
+	entry.RepeatCount = max(entry.RepeatCount, 1) + 1
+	entry.LastRepeatTs = line.Ts
+}
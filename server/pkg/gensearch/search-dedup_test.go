@@ -0,0 +1,128 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+func makeDedupLine(ts int64, msg string) ds.LogLine {
+	return ds.LogLine{LineNum: ts, Ts: ts, Msg: msg + "\n", Source: "/dev/stdout"}
+}
+
+func dedupLines(d *LogDeduper, trimmed int, lines ...ds.LogLine) []ds.LogLine {
+	var result []ds.LogLine
+	for _, line := range lines {
+		result, _ = d.Add(result, trimmed, line)
+	}
+	return result
+}
+
+func TestLogDeduperConsecutive(t *testing.T) {
+	contextLine := makeDedupLine(4, "a")
+	contextLine.IsContext = true
+	lines := []ds.LogLine{
+		makeDedupLine(1, "a"),
+		makeDedupLine(2, "a"),
+		makeDedupLine(3, "b"),
+		contextLine,
+		makeDedupLine(5, "a"),
+		makeDedupLine(6, "a"),
+	}
+	got := getLineSummary(dedupLines(MakeLogDeduper(0), 0, lines...))
+	expect := []string{"a x2", "b", "a (context)", "a x2"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("got %v, want %v", got, expect)
+	}
+}
+
+func TestLogDeduperWindow(t *testing.T) {
+	d := MakeLogDeduper(100)
+	lines := []ds.LogLine{
+		makeDedupLine(10, "a"),
+		makeDedupLine(20, "b"),
+		makeDedupLine(50, "a"),  // within the window of the first "a"
+		makeDedupLine(110, "a"), // the window of the first "a" has expired
+		makeDedupLine(120, "b"),
+	}
+	// the trimmed count is only an offset, the results are the same with any value
+	for _, trimmed := range []int{0, 5} {
+		result := dedupLines(MakeLogDeduper(100), trimmed, lines...)
+		got := getLineSummary(result)
+		expect := []string{"a x2", "b", "a", "b"}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("trimmed %d: got %v, want %v", trimmed, got, expect)
+		}
+		if result[0].LastRepeatTs != 50 {
+			t.Errorf("trimmed %d: got last repeat ts %d, want 50", trimmed, result[0].LastRepeatTs)
+		}
+	}
+
+	// an entry that was trimmed from the results isn't updated
+	result, _ := d.Add(nil, 0, makeDedupLine(10, "a"))
+	result, idx := d.Add(result[:0], 1, makeDedupLine(20, "a"))
+	if idx != -1 || len(result) != 1 || result[0].RepeatCount != 0 {
+		t.Errorf("collapsed into a trimmed entry: idx %d, %+v", idx, result)
+	}
+}
+
+func TestLogDeduperRebase(t *testing.T) {
+	d := MakeLogDeduper(1000)
+	result := dedupLines(d, 0, makeDedupLine(1, "a"), makeDedupLine(2, "b"), makeDedupLine(3, "c"))
+
+	// drop "a" from the results, "b" and "c" are now at 0 and 1
+	result = result[1:]
+	d.Rebase(1)
+	result, idx := d.Add(result, 0, makeDedupLine(4, "c"))
+	if idx != 1 || result[1].RepeatCount != 2 {
+		t.Errorf("got idx %d, %v after rebasing, want \"c\" collapsed at 1", idx, getLineSummary(result))
+	}
+	result, idx = d.Add(result, 0, makeDedupLine(5, "a"))
+	if idx != -1 || len(result) != 3 {
+		t.Errorf("got idx %d, %v, the trimmed \"a\" should start a new entry", idx, getLineSummary(result))
+	}
+}
+
+func TestPerformLogSearchWithContextDedupTrim(t *testing.T) {
+	peer := makeTestPeer(false, "a", "b", "c", "d", "e")
+	allLogs, totalCount := peer.GetLogLineSeq()
+	deduper := MakeLogDeduper(60000)
+	result, stats := PerformLogSearchWithContext(allLogs, totalCount, nil, &SearchContext{}, nil, 2, MakeLogContextExpander(0, 0), deduper)
+	if got := getLineSummary(result); !reflect.DeepEqual(got, []string{"d", "e"}) || stats.SearchedCount != 5 {
+		t.Fatalf("got %v (searched %d), want the newest 2 lines", got, stats.SearchedCount)
+	}
+
+	// a streamed line (added with the manager's trimmed count, 0 for a new result) collapses into the right entry
+	result, idx := deduper.Add(result, 0, peer.addLine("d"))
+	if idx != 0 || !reflect.DeepEqual(getLineSummary(result), []string{"d x2", "e"}) {
+		t.Errorf("got idx %d, %v, want \"d\" collapsed at 0", idx, getLineSummary(result))
+	}
+}
+
+func TestTailLogsDedup(t *testing.T) {
+	peer := makeTestPeer(false, "a", "a", "b")
+	manager := MakeSearchManager("test-widget", "test-apprun", peer)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	tailCh, err := manager.TailLogs(ctx, rpctypes.LogTailRequest{Backfill: 10, Dedup: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	initial := <-tailCh
+	if got := getLineSummary(initial.Response.Lines); !reflect.DeepEqual(got, []string{"a x2", "b"}) {
+		t.Errorf("got backfill %v, want the collapsed lines", got)
+	}
+
+	manager.ProcessNewLine(peer.addLine("b"))
+	update := <-tailCh
+	if got := getLineSummary(update.Response.Lines); !slices.Equal(got, []string{"b x2"}) || update.Response.Offset != 1 {
+		t.Errorf("got update %v at offset %d, want the collapsed \"b\" at 1", got, update.Response.Offset)
+	}
+}
@@ -5,6 +5,8 @@ package gensearch
 
 import (
 	"strings"
+
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
 )
 
 // ExactSearcher implements exact string matching with case sensitivity option
@@ -38,6 +40,16 @@ func (s *ExactSearcher) Match(sctx *SearchContext, obj SearchObject) bool {
 	return strings.Contains(fieldText, s.searchTerm)
 }
 
+// GetMatchSpans returns every occurrence of the search term in the field
+func (s *ExactSearcher) GetMatchSpans(sctx *SearchContext, obj SearchObject) []rpctypes.SearchMatchSpan {
+	fieldMods := 0
+	if !s.caseSensitive {
+		fieldMods = FieldMod_ToLower
+	}
+	fieldText := obj.GetField(s.field, fieldMods)
+	return makeByteSpans(s.field, s.searchTerm, fieldText, findAllIndex(fieldText, s.searchTerm))
+}
+
 // GetType returns the search type identifier
 func (s *ExactSearcher) GetType() string {
 	if s.caseSensitive {
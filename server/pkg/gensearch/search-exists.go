@@ -0,0 +1,24 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+// ExistsSearcher matches objects that have a field ($field?), negated it matches objects without it (-$field?)
+type ExistsSearcher struct {
+	field string
+}
+
+// MakeExistsSearcher creates a new field existence searcher
+func MakeExistsSearcher(field string) Searcher {
+	return &ExistsSearcher{field: field}
+}
+
+// Match checks if the search object has the field
+func (s *ExistsSearcher) Match(sctx *SearchContext, obj SearchObject) bool {
+	return obj.HasField(s.field)
+}
+
+// GetType returns the search type identifier
+func (s *ExistsSearcher) GetType() string {
+	return SearchTypeExists
+}
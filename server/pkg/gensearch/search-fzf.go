@@ -4,8 +4,11 @@
 package gensearch
 
 import (
+	"slices"
+
 	"github.com/junegunn/fzf/src/algo"
 	"github.com/junegunn/fzf/src/util"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
 )
 
 // FzfSearcher implements fuzzy matching using the fzf algorithm
@@ -52,6 +55,25 @@ func (s *FzfSearcher) Match(sctx *SearchContext, obj SearchObject) bool {
 	return result.Score > 0
 }
 
+// GetMatchSpans returns the characters the fuzzy match used (adjacent characters are merged into one span)
+func (s *FzfSearcher) GetMatchSpans(sctx *SearchContext, obj SearchObject) []rpctypes.SearchMatchSpan {
+	var fieldText string
+	if s.caseSensitive {
+		fieldText = obj.GetField(s.field, 0)
+	} else {
+		fieldText = obj.GetField(s.field, FieldMod_ToLower)
+	}
+	chars := util.ToChars([]byte(fieldText))
+	result, positions := algo.FuzzyMatchV2(false, true, true, &chars, s.pattern, true, s.slab)
+	if result.Score <= 0 || positions == nil {
+		return nil
+	}
+	// positions are character indexes in reverse order
+	sorted := slices.Clone(*positions)
+	slices.Sort(sorted)
+	return makeRuneSpans(s.field, s.searchTerm, sorted)
+}
+
 // GetType returns the search type identifier
 func (s *FzfSearcher) GetType() string {
 	if s.caseSensitive {
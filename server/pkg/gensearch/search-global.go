@@ -0,0 +1,165 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/searchparser"
+)
+
+// GlobalSearchDefaultMaxResults is the number of matches returned if the request doesn't set MaxResults
+const GlobalSearchDefaultMaxResults = 500
+
+// GlobalSearchMaxResults is the most matches a global search returns
+const GlobalSearchMaxResults = 5000
+
+// globalSearchConcurrency is the number of app runs searched at the same time
+const globalSearchConcurrency = 4
+
+// GlobalSearchTarget is an app run searched by GlobalSearch
+type GlobalSearchTarget struct {
+	AppRunId  string
+	AppName   string
+	Status    string
+	StartTime int64
+	LogPeer   PeerInterface
+}
+
+// countingSearcher counts the matches of the wrapped searcher (PerformSearchSeq only keeps the newest)
+type countingSearcher struct {
+	Searcher
+	numMatches int
+}
+
+func (s *countingSearcher) Match(sctx *SearchContext, obj SearchObject) bool {
+	if s.Searcher.Match(sctx, obj) {
+		s.numMatches++
+		return true
+	}
+	return false
+}
+
+type globalSearchRun struct {
+	result rpctypes.GlobalSearchRunResult
+	lines  []rpctypes.GlobalSearchLine
+}
+
+// GlobalSearch runs a log search over several app runs (in parallel) and merges the newest maxResults
+// matches by timestamp. Each run is searched with its own searcher (searchers are not safe for concurrent use).
+func GlobalSearch(ctx context.Context, searchTerm string, targets []GlobalSearchTarget, maxResults int) (rpctypes.GlobalSearchResultData, error) {
+	if maxResults <= 0 {
+		maxResults = GlobalSearchDefaultMaxResults
+	}
+	maxResults = min(maxResults, GlobalSearchMaxResults)
+	_, errorSpans, _, err := GetSearcherWithErrors(searchTerm)
+	if err != nil {
+		return rpctypes.GlobalSearchResultData{}, fmt.Errorf("failed to create searcher: %w", err)
+	}
+
+	startTs := time.Now()
+	runs := make([]globalSearchRun, len(targets))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, globalSearchConcurrency)
+	for idx, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			runs[idx] = searchGlobalTarget(ctx, searchTerm, target, maxResults)
+		}()
+	}
+	wg.Wait()
+
+	rtn := rpctypes.GlobalSearchResultData{
+		Lines:      []rpctypes.GlobalSearchLine{},
+		Runs:       make([]rpctypes.GlobalSearchRunResult, 0, len(runs)),
+		ErrorSpans: errorSpans,
+		Canceled:   ctx.Err() != nil,
+	}
+	for _, run := range runs {
+		rtn.Runs = append(rtn.Runs, run.result)
+		rtn.MatchCount += run.result.MatchCount
+		rtn.SearchedCount += run.result.SearchedCount
+		rtn.Lines = append(rtn.Lines, run.lines...)
+	}
+	sort.SliceStable(rtn.Runs, func(i, j int) bool {
+		if rtn.Runs[i].MatchCount != rtn.Runs[j].MatchCount {
+			return rtn.Runs[i].MatchCount > rtn.Runs[j].MatchCount
+		}
+		return rtn.Runs[i].StartTime > rtn.Runs[j].StartTime
+	})
+	sort.SliceStable(rtn.Lines, func(i, j int) bool {
+		if rtn.Lines[i].Line.Ts != rtn.Lines[j].Line.Ts {
+			return rtn.Lines[i].Line.Ts < rtn.Lines[j].Line.Ts
+		}
+		if rtn.Lines[i].AppRunId != rtn.Lines[j].AppRunId {
+			return rtn.Lines[i].AppRunId < rtn.Lines[j].AppRunId
+		}
+		return rtn.Lines[i].Line.LineNum < rtn.Lines[j].Line.LineNum
+	})
+	if len(rtn.Lines) > maxResults {
+		rtn.Lines = rtn.Lines[len(rtn.Lines)-maxResults:]
+	}
+	rtn.Truncated = rtn.MatchCount > len(rtn.Lines)
+	log.Printf("GlobalSearch: %d matches in %d app runs (%d lines searched) in %dms%s\n", rtn.MatchCount, len(targets),
+		rtn.SearchedCount, time.Since(startTs).Milliseconds(), canceledLogSuffix(rtn.Canceled))
+	return rtn, nil
+}
+
+// searchGlobalTarget searches the logs of one app run, returning its newest maxResults matches
+func searchGlobalTarget(ctx context.Context, searchTerm string, target GlobalSearchTarget, maxResults int) globalSearchRun {
+	run := globalSearchRun{
+		result: rpctypes.GlobalSearchRunResult{
+			AppRunId:  target.AppRunId,
+			AppName:   target.AppName,
+			Status:    target.Status,
+			StartTime: target.StartTime,
+		},
+	}
+	userSearcher, _, colorFilters, err := GetSearcherWithErrors(searchTerm)
+	if err != nil {
+		run.result.Error = err.Error()
+		return run
+	}
+	searcher := &countingSearcher{Searcher: userSearcher}
+	sctx := &SearchContext{
+		MarkedLines: target.LogPeer.GetMarkManager().GetMarkedIds(),
+		UserQuery:   userSearcher,
+		Ctx:         ctx,
+	}
+	allLogs, totalCount := getSearchLogLineSeq(target.LogPeer, userSearcher, userSearcher, true)
+	lines, stats, colorMap, err := PerformSearchSeq(allLogs, totalCount, LogLineToSearchObject, searcher, sctx, colorFilters, maxResults)
+	if err != nil {
+		run.result.Error = err.Error()
+		return run
+	}
+	for i := range lines {
+		if color, exists := colorMap[lines[i].LineNum]; exists {
+			lines[i].Color = searchparser.ColorToInt8(color)
+		}
+	}
+	run.result.MatchCount = searcher.numMatches
+	run.result.SearchedCount = stats.SearchedCount
+	run.result.TotalCount = stats.TotalCount
+	spanCtx := &SearchContext{UserQuery: userSearcher}
+	for _, line := range lines {
+		run.lines = append(run.lines, rpctypes.GlobalSearchLine{
+			AppRunId:   target.AppRunId,
+			AppName:    target.AppName,
+			Line:       line,
+			MatchSpans: GetMatchSpans(userSearcher, spanCtx, LogLineToSearchObject(line)),
+		})
+	}
+	return run
+}
@@ -0,0 +1,92 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// makeGlobalSearchTarget makes a target whose lines have the given timestamps
+func makeGlobalSearchTarget(appRunId string, startTime int64, msgs []string, timestamps []int64) GlobalSearchTarget {
+	peer := makeTestPeer(false, msgs...)
+	for i := range peer.lines {
+		peer.lines[i].Ts = timestamps[i]
+	}
+	return GlobalSearchTarget{AppRunId: appRunId, AppName: "app-" + appRunId, Status: "done", StartTime: startTime, LogPeer: peer}
+}
+
+func TestGlobalSearch(t *testing.T) {
+	targets := []GlobalSearchTarget{
+		makeGlobalSearchTarget("run1", 100, []string{"error a", "ok", "error b"}, []int64{10, 20, 50}),
+		makeGlobalSearchTarget("run2", 200, []string{"error c", "error d", "error e"}, []int64{30, 50, 60}),
+		makeGlobalSearchTarget("run3", 300, []string{"ok"}, []int64{40}),
+	}
+
+	tests := []struct {
+		name          string
+		maxResults    int
+		expectLines   []string
+		expectRuns    []string
+		expectMatches int
+		truncated     bool
+	}{
+		// lines with the same ts are ordered by app run id
+		{"merged by timestamp", 0, []string{"run1:error a", "run2:error c", "run1:error b", "run2:error d", "run2:error e"}, []string{"run2", "run1", "run3"}, 5, false},
+		{"newest matches kept", 2, []string{"run2:error d", "run2:error e"}, []string{"run2", "run1", "run3"}, 5, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := GlobalSearch(context.Background(), "error", targets, tc.maxResults)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lines []string
+			for _, line := range result.Lines {
+				lines = append(lines, line.AppRunId+":"+line.Line.Msg[:len(line.Line.Msg)-1])
+			}
+			if !reflect.DeepEqual(lines, tc.expectLines) {
+				t.Errorf("got lines %v, want %v", lines, tc.expectLines)
+			}
+			var runs []string
+			for _, run := range result.Runs {
+				runs = append(runs, run.AppRunId)
+			}
+			if !reflect.DeepEqual(runs, tc.expectRuns) {
+				t.Errorf("got runs %v, want %v (most matches first, then newest)", runs, tc.expectRuns)
+			}
+			if result.MatchCount != tc.expectMatches || result.Truncated != tc.truncated || result.SearchedCount != 7 {
+				t.Errorf("got %d matches (truncated=%v) in %d lines, want %d (truncated=%v) in 7",
+					result.MatchCount, result.Truncated, result.SearchedCount, tc.expectMatches, tc.truncated)
+			}
+			if len(result.Lines) > 0 && len(result.Lines[0].MatchSpans) == 0 {
+				t.Errorf("got no match spans for %+v", result.Lines[0])
+			}
+		})
+	}
+
+	// runs with the same match count are ordered by start time (newest first)
+	result, err := GlobalSearch(context.Background(), "ok", targets, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Runs) != 3 || result.Runs[0].AppRunId != "run3" || result.Runs[1].AppRunId != "run1" {
+		t.Errorf("got runs %+v, want run3 then run1", result.Runs)
+	}
+
+	// an invalid part of the search is reported in the error spans
+	result, err = GlobalSearch(context.Background(), "/[/", targets, 0)
+	if err != nil || len(result.ErrorSpans) == 0 {
+		t.Errorf("got %v with error spans %v, want the invalid regexp in the error spans", err, result.ErrorSpans)
+	}
+
+	// a search that runs out of time returns what it found, marked as canceled
+	ctx, cancelFn := context.WithCancel(context.Background())
+	cancelFn()
+	result, err = GlobalSearch(ctx, "error", targets, 0)
+	if err != nil || !result.Canceled || len(result.Runs) != 3 {
+		t.Errorf("got %v, canceled=%v with %d runs, want a canceled result for every run", err, result.Canceled, len(result.Runs))
+	}
+}
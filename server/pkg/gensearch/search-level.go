@@ -0,0 +1,33 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"github.com/outrigdev/outrig/server/pkg/searchparser"
+)
+
+// LevelSearcher matches log lines by their normalized log level ($level:warn+), lines without a detected
+// level never match
+type LevelSearcher struct {
+	level    string
+	operator string
+}
+
+// MakeLevelSearcher creates a level searcher, level is a normalized level (see searchparser.NormalizeLogLevel)
+func MakeLevelSearcher(level string, operator string) Searcher {
+	return &LevelSearcher{
+		level:    level,
+		operator: operator,
+	}
+}
+
+// Match checks if the level of the object satisfies the comparison
+func (s *LevelSearcher) Match(sctx *SearchContext, obj SearchObject) bool {
+	return searchparser.CompareLogLevel(obj.GetField(searchparser.LevelField, 0), s.operator, s.level)
+}
+
+// GetType returns the search type identifier
+func (s *LevelSearcher) GetType() string {
+	return SearchTypeLevel
+}
@@ -4,20 +4,21 @@
 package gensearch
 
 import (
-	"strconv"
+	"github.com/outrigdev/outrig/server/pkg/searchparser"
 )
 
-// NumericSearcher implements numeric comparison operations (>, <, >=, <=)
+// NumericSearcher implements numeric comparison operations (>, <, >=, <=).
+// The search value and the field values can have size or duration units (1kb, 1.5s), see searchparser.ParseNumericValue.
 type NumericSearcher struct {
 	field     string
-	searchNum int
+	searchNum searchparser.NumericValue
 	operator  string
 }
 
 // MakeNumericSearcher creates a new numeric comparison searcher
 func MakeNumericSearcher(field string, searchTerm string, operator string) (Searcher, error) {
-	// Convert the search term to an integer
-	searchNum, err := strconv.Atoi(searchTerm)
+	// Convert the search term to a number (normalized to the base unit of its unit kind)
+	searchNum, err := searchparser.ParseNumericValue(searchTerm)
 	if err != nil {
 		return nil, err
 	}
@@ -37,24 +38,12 @@ func (s *NumericSearcher) Match(sctx *SearchContext, obj SearchObject) bool {
 		return false
 	}
 
-	// Try to convert the field value to an integer
-	fieldNum, err := strconv.Atoi(fieldText)
+	// Try to convert the field value to a number (a field value can have a unit as well, e.g. "250ms")
+	fieldNum, err := searchparser.ParseNumericValue(fieldText)
 	if err != nil {
 		return false
 	}
-	// Perform the comparison based on the operator
-	switch s.operator {
-	case ">":
-		return fieldNum > s.searchNum
-	case "<":
-		return fieldNum < s.searchNum
-	case ">=":
-		return fieldNum >= s.searchNum
-	case "<=":
-		return fieldNum <= s.searchNum
-	default:
-		return false
-	}
+	return searchparser.CompareNumeric(fieldNum, s.operator, s.searchNum)
 }
 
 // GetType returns the search type identifier
@@ -3,6 +3,10 @@
 
 package gensearch
 
+import (
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
 // OrSearcher implements a searcher that matches if any contained searcher matches
 type OrSearcher struct {
 	searchers []Searcher
@@ -32,6 +36,11 @@ func (s *OrSearcher) Match(sctx *SearchContext, obj SearchObject) bool {
 	return false
 }
 
+// GetMatchSpans returns the spans of the contained searchers that matched
+func (s *OrSearcher) GetMatchSpans(sctx *SearchContext, obj SearchObject) []rpctypes.SearchMatchSpan {
+	return collectSpans(s.searchers, sctx, obj, true)
+}
+
 // GetType returns the search type identifier
 func (s *OrSearcher) GetType() string {
 	return SearchTypeOr
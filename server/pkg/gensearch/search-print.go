@@ -60,6 +60,9 @@ func PrettyPrint(s Searcher) string {
 	case *NotSearcher:
 		return fmt.Sprintf("NotSearcher{%s}", PrettyPrint(searcher.searcher))
 
+	case *ExistsSearcher:
+		return fmt.Sprintf("ExistsSearcher{field: %q}", searcher.field)
+
 	case *AllSearcher:
 		return "AllSearcher{}"
 
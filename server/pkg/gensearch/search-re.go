@@ -6,6 +6,8 @@ package gensearch
 import (
 	"fmt"
 	"regexp"
+
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
 )
 
 // RegexpSearcher implements regular expression matching
@@ -47,6 +49,12 @@ func (s *RegexpSearcher) Match(sctx *SearchContext, obj SearchObject) bool {
 	return s.regex.MatchString(fieldText)
 }
 
+// GetMatchSpans returns every match of the regexp in the field (empty matches are skipped)
+func (s *RegexpSearcher) GetMatchSpans(sctx *SearchContext, obj SearchObject) []rpctypes.SearchMatchSpan {
+	fieldText := obj.GetField(s.field, 0)
+	return makeByteSpans(s.field, s.searchTerm, fieldText, s.regex.FindAllStringIndex(fieldText, -1))
+}
+
 // GetType returns the search type identifier
 func (s *RegexpSearcher) GetType() string {
 	if s.caseSensitive {
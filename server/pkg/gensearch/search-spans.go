@@ -0,0 +1,118 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// SpanSearcher is implemented by searchers that can report which parts of an object they matched
+// (spans are only requested for objects that matched the full search)
+type SpanSearcher interface {
+	GetMatchSpans(sctx *SearchContext, obj SearchObject) []rpctypes.SearchMatchSpan
+}
+
+// GetMatchSpans returns the sorted match spans of obj for searcher (nil if the searcher can't report spans)
+func GetMatchSpans(searcher Searcher, sctx *SearchContext, obj SearchObject) []rpctypes.SearchMatchSpan {
+	spanSearcher, ok := searcher.(SpanSearcher)
+	if !ok {
+		return nil
+	}
+	spans := spanSearcher.GetMatchSpans(sctx, obj)
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].Field != spans[j].Field {
+			return spans[i].Field < spans[j].Field
+		}
+		return spans[i].Start < spans[j].Start
+	})
+	return spans
+}
+
+// canonicalSpanField maps field aliases to the name used in match spans
+func canonicalSpanField(field string) string {
+	switch field {
+	case "", "msg", "line":
+		return "msg"
+	case "src":
+		return "source"
+	}
+	return field
+}
+
+// makeByteSpans converts byte offset pairs in text to rune offset spans
+func makeByteSpans(field string, term string, text string, byteSpans [][]int) []rpctypes.SearchMatchSpan {
+	if len(byteSpans) == 0 {
+		return nil
+	}
+	field = canonicalSpanField(field)
+	spans := make([]rpctypes.SearchMatchSpan, 0, len(byteSpans))
+	runePos := 0
+	bytePos := 0
+	toRunePos := func(offset int) int {
+		runePos += utf8.RuneCountInString(text[bytePos:offset])
+		bytePos = offset
+		return runePos
+	}
+	for _, bs := range byteSpans {
+		if bs[0] < bytePos || bs[1] <= bs[0] {
+			continue
+		}
+		start := toRunePos(bs[0])
+		end := toRunePos(bs[1])
+		spans = append(spans, rpctypes.SearchMatchSpan{Field: field, Start: start, End: end, Term: term})
+	}
+	return spans
+}
+
+// findAllIndex returns the byte offsets of the non-overlapping occurrences of term in text
+func findAllIndex(text string, term string) [][]int {
+	if term == "" {
+		return nil
+	}
+	var rtn [][]int
+	offset := 0
+	for {
+		idx := strings.Index(text[offset:], term)
+		if idx == -1 {
+			return rtn
+		}
+		start := offset + idx
+		rtn = append(rtn, []int{start, start + len(term)})
+		offset = start + len(term)
+	}
+}
+
+// makeRuneSpans converts sorted rune positions into spans (adjacent positions are merged)
+func makeRuneSpans(field string, term string, positions []int) []rpctypes.SearchMatchSpan {
+	field = canonicalSpanField(field)
+	var spans []rpctypes.SearchMatchSpan
+	for _, pos := range positions {
+		if len(spans) > 0 && spans[len(spans)-1].End == pos {
+			spans[len(spans)-1].End = pos + 1
+			continue
+		}
+		spans = append(spans, rpctypes.SearchMatchSpan{Field: field, Start: pos, End: pos + 1, Term: term})
+	}
+	return spans
+}
+
+// collectSpans gathers the spans of child searchers
+func collectSpans(searchers []Searcher, sctx *SearchContext, obj SearchObject, onlyMatching bool) []rpctypes.SearchMatchSpan {
+	var spans []rpctypes.SearchMatchSpan
+	for _, searcher := range searchers {
+		spanSearcher, ok := searcher.(SpanSearcher)
+		if !ok {
+			continue
+		}
+		if onlyMatching && !searcher.Match(sctx, obj) {
+			continue
+		}
+		spans = append(spans, spanSearcher.GetMatchSpans(sctx, obj)...)
+	}
+	return spans
+}
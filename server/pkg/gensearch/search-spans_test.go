@@ -0,0 +1,61 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// getSpanSummary returns "field:start-end" for each span
+func getSpanSummary(spans []rpctypes.SearchMatchSpan) []string {
+	var rtn []string
+	for _, span := range spans {
+		rtn = append(rtn, fmt.Sprintf("%s:%d-%d", span.Field, span.Start, span.End))
+	}
+	return rtn
+}
+
+func TestGetMatchSpans(t *testing.T) {
+	line := ds.LogLine{LineNum: 1, Msg: "héllo Error, error again", Source: "/dev/stderr"}
+
+	tests := []struct {
+		name   string
+		search string
+		expect []string
+	}{
+		{"exact, every occurrence", "error", []string{"msg:6-11", "msg:13-18"}},
+		{"exact case", `'error'`, []string{"msg:13-18"}},
+		{"offsets are in runes", "llo", []string{"msg:2-5"}},
+		{"field alias", "$src:stderr", []string{"source:5-11"}},
+		{"regexp", "/e[a-z]+/", []string{"msg:6-11", "msg:13-18"}},
+		{"case sensitive regexp", "c/e[a-z]+/", []string{"msg:13-18"}},
+		{"fuzzy, adjacent characters merged", "~hllo", []string{"msg:0-1", "msg:2-5"}},
+		{"and, sorted by field and position", "again error", []string{"msg:6-11", "msg:13-18", "msg:19-24"}},
+		{"or, only the matching terms", "missing | again", []string{"msg:19-24"}},
+		{"not has no spans", "-missing", nil},
+		{"no span searcher", "$linenum:>0", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			searcher, err := GetSearcher(tc.search)
+			if err != nil {
+				t.Fatalf("GetSearcher(%q): %v", tc.search, err)
+			}
+			sctx := &SearchContext{}
+			obj := LogLineToSearchObject(line)
+			if !searcher.Match(sctx, obj) {
+				t.Fatalf("%q doesn't match %q", tc.search, line.Msg)
+			}
+			got := getSpanSummary(GetMatchSpans(searcher, sctx, obj))
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("got spans %v, want %v", got, tc.expect)
+			}
+		})
+	}
+}
@@ -56,3 +56,30 @@ func (s *TagSearcher) Match(sctx *SearchContext, obj SearchObject) bool {
 func (s *TagSearcher) GetType() string {
 	return SearchTypeTag
 }
+
+// getTagField returns the value of the first "name:<value>" tag ("" for a "name" tag), so $name:value searches
+// the same tags that $name? checks (see hasTagField)
+func getTagField(tags []string, name string, fieldMods int) string {
+	for _, tag := range tags {
+		tagName, value, _ := strings.Cut(tag, ":")
+		if !strings.EqualFold(tagName, name) {
+			continue
+		}
+		if fieldMods&FieldMod_ToLower != 0 {
+			return strings.ToLower(value)
+		}
+		return value
+	}
+	return ""
+}
+
+// hasTagField returns true if there is a "name" or "name:<value>" tag (pprof labels are key:value tags)
+func hasTagField(tags []string, name string) bool {
+	for _, tag := range tags {
+		tagName, _, _ := strings.Cut(tag, ":")
+		if strings.EqualFold(tagName, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"time"
+
+	"github.com/outrigdev/outrig/server/pkg/searchparser"
+)
+
+// TimestampedSearchObject is a SearchObject with a timestamp (used by time window searches)
+type TimestampedSearchObject interface {
+	GetTs() int64
+}
+
+// TimeWindowSearcher matches objects with a timestamp inside the window (@last:15m, @between:ts1,ts2)
+// Objects without a timestamp always match
+type TimeWindowSearcher struct {
+	window searchparser.TimeWindow
+}
+
+// MakeTimeWindowSearcher creates a new time window searcher, the window is resolved relative to the current time
+func MakeTimeWindowSearcher(op string, value string) (Searcher, error) {
+	window, err := searchparser.ParseTimeWindow(op, value, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &TimeWindowSearcher{window: window}, nil
+}
+
+// Match checks if the object's timestamp is inside the window
+func (s *TimeWindowSearcher) Match(sctx *SearchContext, obj SearchObject) bool {
+	tsObj, ok := obj.(TimestampedSearchObject)
+	if !ok {
+		return true
+	}
+	return s.window.Contains(tsObj.GetTs())
+}
+
+// GetType returns the search type identifier
+func (s *TimeWindowSearcher) GetType() string {
+	return SearchTypeTimeWindow
+}
+
+// GetTimeWindow returns the time window that every match of the searcher must be in (nil if there isn't one).
+// Only time windows that are ANDed at the top level of the search count, so they can be applied to the
+// data before the search runs. userQuery is the searcher that #userquery refers to (can be nil).
+func GetTimeWindow(searcher Searcher, userQuery Searcher) *searchparser.TimeWindow {
+	switch s := searcher.(type) {
+	case *TimeWindowSearcher:
+		window := s.window
+		return &window
+	case *AndSearcher:
+		var rtn *searchparser.TimeWindow
+		for _, child := range s.searchers {
+			window := GetTimeWindow(child, userQuery)
+			if window == nil {
+				continue
+			}
+			if rtn == nil {
+				rtn = window
+			} else {
+				intersected := rtn.Intersect(*window)
+				rtn = &intersected
+			}
+		}
+		return rtn
+	case *UserQuerySearcher:
+		if userQuery == nil {
+			return nil
+		}
+		return GetTimeWindow(userQuery, nil)
+	default:
+		return nil
+	}
+}
@@ -81,10 +81,15 @@ func MakeSearcherFromNode(node *searchparser.Node) (Searcher, error) {
 		if len(searchers) == 0 {
 			return nil, nil
 		}
-		if len(searchers) == 1 {
-			return searchers[0], nil
+		searcher := searchers[0]
+		if len(searchers) > 1 {
+			searcher = MakeAndSearcher(searchers)
 		}
-		return MakeAndSearcher(searchers), nil
+		if node.IsNot {
+			// negated field group, e.g. -$state:(running | runnable)
+			return MakeNotSearcher(searcher), nil
+		}
+		return searcher, nil
 
 	case searchparser.NodeTypeOr:
 		var searchers []Searcher
@@ -101,10 +106,14 @@ func MakeSearcherFromNode(node *searchparser.Node) (Searcher, error) {
 		if len(searchers) == 0 {
 			return nil, nil
 		}
-		if len(searchers) == 1 {
-			return searchers[0], nil
+		searcher := searchers[0]
+		if len(searchers) > 1 {
+			searcher = MakeOrSearcher(searchers)
 		}
-		return MakeOrSearcher(searchers), nil
+		if node.IsNot {
+			return MakeNotSearcher(searcher), nil
+		}
+		return searcher, nil
 
 	default:
 		return nil, nil
@@ -118,6 +127,8 @@ func createSearcherFromSearchNode(node *searchparser.Node) (Searcher, error) {
 		return MakeMarkedSearcher(), nil
 	} else if node.SearchType == SearchTypeUserQuery {
 		return MakeUserQuerySearcher(), nil
+	} else if node.SearchType == SearchTypeExists {
+		return MakeExistsSearcher(node.Field), nil
 	}
 
 	// Handle empty search term
@@ -145,6 +156,10 @@ func createSearcherFromSearchNode(node *searchparser.Node) (Searcher, error) {
 		return MakeNumericSearcher(node.Field, node.SearchTerm, node.Op)
 	case SearchTypeColorFilter:
 		return MakeColorFilterSearcher(), nil
+	case SearchTypeTimeWindow:
+		return MakeTimeWindowSearcher(node.Op, node.SearchTerm)
+	case SearchTypeLevel:
+		return MakeLevelSearcher(node.SearchTerm, node.Op), nil
 	default:
 		// Default to case-insensitive exact search
 		return MakeExactSearcher(node.Field, node.SearchTerm, false), nil
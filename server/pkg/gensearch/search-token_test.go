@@ -0,0 +1,41 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"testing"
+)
+
+func TestNegatedFieldGroup(t *testing.T) {
+	running := &GoRoutineSearchObject{GoId: 1, Name: "worker", State: "running"}
+	waiting := &GoRoutineSearchObject{GoId: 2, Name: "reader", State: "chan receive"}
+
+	tests := []struct {
+		search        string
+		expectRunning bool
+		expectWaiting bool
+	}{
+		{"$state:(running | runnable)", true, false},
+		{"-$state:(running | runnable)", false, true},
+		{"-$state:(running)", false, true},
+		// "$field" is an error node (dropped), the group with a single searcher left is still negated
+		{"-$state:(running | $field)", false, true},
+		{"-$state:(running $field)", false, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.search, func(t *testing.T) {
+			searcher, err := GetSearcher(tc.search)
+			if err != nil {
+				t.Fatalf("GetSearcher(%q): %v", tc.search, err)
+			}
+			sctx := &SearchContext{}
+			if got := searcher.Match(sctx, running); got != tc.expectRunning {
+				t.Errorf("got %v for the running goroutine, want %v", got, tc.expectRunning)
+			}
+			if got := searcher.Match(sctx, waiting); got != tc.expectWaiting {
+				t.Errorf("got %v for the waiting goroutine, want %v", got, tc.expectWaiting)
+			}
+		})
+	}
+}
@@ -3,6 +3,10 @@
 
 package gensearch
 
+import (
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
 // UserQuerySearcher is a searcher that delegates to the UserQuery field in SearchContext
 type UserQuerySearcher struct{}
 
@@ -19,6 +23,14 @@ func (s *UserQuerySearcher) Match(sctx *SearchContext, obj SearchObject) bool {
 	return sctx.UserQuery.Match(sctx, obj)
 }
 
+// GetMatchSpans returns the spans of the user query
+func (s *UserQuerySearcher) GetMatchSpans(sctx *SearchContext, obj SearchObject) []rpctypes.SearchMatchSpan {
+	if sctx.UserQuery == nil {
+		return nil
+	}
+	return GetMatchSpans(sctx.UserQuery, sctx, obj)
+}
+
 // GetType returns the search type identifier
 func (s *UserQuerySearcher) GetType() string {
 	return SearchTypeUserQuery
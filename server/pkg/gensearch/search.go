@@ -4,6 +4,8 @@
 package gensearch
 
 import (
+	"context"
+
 	"github.com/outrigdev/outrig/server/pkg/rpctypes"
 	"github.com/outrigdev/outrig/server/pkg/searchparser"
 )
@@ -22,6 +24,9 @@ const (
 	SearchTypeMarked      = searchparser.SearchTypeMarked
 	SearchTypeNumeric     = searchparser.SearchTypeNumeric
 	SearchTypeColorFilter = searchparser.SearchTypeColorFilter
+	SearchTypeTimeWindow  = searchparser.SearchTypeTimeWindow
+	SearchTypeLevel       = searchparser.SearchTypeLevel
+	SearchTypeExists      = searchparser.SearchTypeExists
 
 	// Additional constants not in searchparser
 	SearchTypeAnd = "and"
@@ -33,15 +38,27 @@ const (
 	FieldMod_ToLower = 1
 )
 
+// CancelCheckInterval is the number of items scanned between checks of the search context
+const CancelCheckInterval = 1024
+
 // SearchContext contains runtime context for search operations
 type SearchContext struct {
 	MarkedLines map[int64]bool
 	UserQuery   Searcher
+	Ctx         context.Context // stops long scans when canceled (nil is never canceled)
 	// Future fields can be added here without changing the interface
 }
 
+// IsCanceled returns true if the search's context has been canceled (or has timed out)
+func (sctx *SearchContext) IsCanceled() bool {
+	return sctx != nil && sctx.Ctx != nil && sctx.Ctx.Err() != nil
+}
+
 type SearchObject interface {
 	GetField(fieldName string, fieldMods int) string
+	// HasField returns true if the object has the field ($field? searches), built-in fields exist when they are
+	// not empty, other fields are structured fields (a JSON field of a log line, a key:value tag)
+	HasField(fieldName string) bool
 	GetTags() []string
 	GetId() int64
 }
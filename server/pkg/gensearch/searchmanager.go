@@ -6,7 +6,9 @@ package gensearch
 import (
 	"context"
 	"fmt"
+	"iter"
 	"log"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -28,12 +30,16 @@ const (
 	MaxIdleTime       = 1 * time.Minute
 	TrimSize          = 1000
 	LogLineBufferSize = 10000
+	MaxCachedResults  = LogLineBufferSize // max number of matching lines kept for a search (the newest lines are kept), as many as the in-memory log buffer holds
 )
 
 type PeerInterface interface {
-	GetLogLines() ([]ds.LogLine, int)
+	GetLogLineSeq() (iter.Seq[ds.LogLine], int)
+	GetLogLineSeqInWindow(window searchparser.TimeWindow) (iter.Seq[ds.LogLine], int) // only the lines inside the window
+	GetIndexedLogLineSeq(terms []string) (iter.Seq[ds.LogLine], int, bool)            // only the lines the LogIndex returns for the terms (false if there is no index)
 	RegisterSearchManager(manager SearchManagerInterface)
 	UnregisterSearchManager(manager SearchManagerInterface)
+	GetMarkManager() *MarkManager
 }
 
 // SearchManagerInterface defines the interface for search managers
@@ -44,10 +50,11 @@ type SearchManagerInterface interface {
 
 // SearchStats contains statistics about a search operation
 type SearchStats struct {
-	TotalCount     int   `json:"totalcount"`     // Total number of log lines in the AppRunPeer
-	SearchedCount  int   `json:"searchedcount"`  // Number of log lines that were actually searched
-	LastLineNum    int64 `json:"lastlinenum"`    // Last line number processed to avoid duplicates
-	SearchDuration int   `json:"searchduration"` // Duration of the search operation in milliseconds
+	TotalCount     int   `json:"totalcount"`         // Total number of log lines in the AppRunPeer
+	SearchedCount  int   `json:"searchedcount"`      // Number of log lines that were actually searched
+	LastLineNum    int64 `json:"lastlinenum"`        // Last line number processed to avoid duplicates
+	SearchDuration int   `json:"searchduration"`     // Duration of the search operation in milliseconds
+	Canceled       bool  `json:"canceled,omitempty"` // The search was canceled before all items were searched
 }
 
 // SearchManagerInfo contains thread-safe information about a SearchManager
@@ -62,6 +69,7 @@ type SearchManagerInfo struct {
 	TrimmedCount     int         `json:"trimmedcount,omitempty"`
 	Stats            SearchStats `json:"stats"`
 	Streaming        bool        `json:"streaming"`
+	Tailing          bool        `json:"tailing,omitempty"`
 }
 
 // SearchManager handles search functionality for a specific widget
@@ -82,6 +90,14 @@ type SearchManager struct {
 
 	ColorFilters []ColorSearcher // Color filters for search result colorization
 
+	ContextBefore   int                 // Number of context lines before each match
+	ContextAfter    int                 // Number of context lines after each match
+	ContextExpander *LogContextExpander // Adds context lines to streamed results (nil when there is no context)
+
+	Dedup         bool        // Whether identical lines are collapsed into one entry with a repeat count
+	DedupWindowMs int64       // Window for collapsing non-consecutive identical lines (0 for consecutive lines only)
+	Deduper       *LogDeduper // Collapses identical streamed lines (nil when dedup is off)
+
 	CachedResult []ds.LogLine // Filtered log lines matching the search criteria
 	Stats        SearchStats  // Statistics about the search operation
 	TrimmedCount int          // Number of lines trimmed from the filtered logs
@@ -89,6 +105,7 @@ type SearchManager struct {
 	MarkManager *MarkManager // Manager for marked lines
 	RpcSource   string       // Source of the last RPC request that used this manager
 	Streaming   bool         // Whether to stream updates to the client
+	Tail        *logTail     // LogTailCommand stream that receives the new lines instead of LogStreamUpdateCommand (nil if none)
 }
 
 // GetInfo returns a thread-safe copy of the SearchManager's information
@@ -107,6 +124,7 @@ func (m *SearchManager) GetInfo() SearchManagerInfo {
 		TrimmedCount:     m.TrimmedCount,
 		Stats:            m.Stats,
 		Streaming:        m.Streaming,
+		Tailing:          m.Tail != nil,
 	}
 }
 
@@ -141,27 +159,40 @@ func (m *SearchManager) ProcessNewLine(line ds.LogLine) {
 		UserQuery:   m.UserSearcher, // Set the user query searcher for #userquery references
 	}
 
-	// Convert the log line to a SearchObject
-	searchObj := LogLineToSearchObject(line)
-
-	if !effectiveSearcher.Match(sctx, searchObj) {
+	var newLines []ds.LogLine
+	if matchLogLine(effectiveSearcher, sctx, m.ColorFilters, &line) {
+		if m.ContextExpander != nil {
+			newLines = m.ContextExpander.AddMatch(line)
+		} else {
+			newLines = []ds.LogLine{line}
+		}
+	} else if m.ContextExpander != nil {
+		if contextLine, ok := m.ContextExpander.AddNonMatch(line); ok {
+			newLines = []ds.LogLine{contextLine}
+		}
+	}
+	if len(newLines) == 0 {
 		return
 	}
 
-	// Apply colorization to the line if color filters are available
-	if len(m.ColorFilters) > 0 {
-		// Check color filters in reverse order (last match wins)
-		for i := len(m.ColorFilters) - 1; i >= 0; i-- {
-			colorFilter := m.ColorFilters[i]
-			if colorFilter.Searcher.Match(sctx, searchObj) {
-				line.Color = searchparser.ColorToInt8(colorFilter.Color)
-				break // First match wins (since we're going in reverse)
+	// the first changed line of the results (not counting the trimmed lines), dedup can update an earlier entry
+	firstChanged := len(m.CachedResult)
+	if m.Deduper != nil {
+		for _, newLine := range newLines {
+			var updatedIdx int
+			m.CachedResult, updatedIdx = m.Deduper.Add(m.CachedResult, m.TrimmedCount, newLine)
+			if updatedIdx >= 0 && len(m.CachedResult)-updatedIdx <= MaxDedupUpdateLines {
+				firstChanged = min(firstChanged, updatedIdx)
 			}
 		}
+		if firstChanged == len(m.CachedResult) {
+			return
+		}
+	} else {
+		m.CachedResult = append(m.CachedResult, newLines...)
 	}
-
-	m.CachedResult = append(m.CachedResult, line)
-	if len(m.CachedResult) > LogLineBufferSize+TrimSize {
+	firstChanged += m.TrimmedCount
+	if len(m.CachedResult) > MaxCachedResults+TrimSize {
 		m.TrimmedCount += TrimSize
 
 		// Create a new slice with the remaining elements (after trimming)
@@ -174,6 +205,13 @@ func (m *SearchManager) ProcessNewLine(line ds.LogLine) {
 		// Replace the old slice with the new one
 		m.CachedResult = newResult
 	}
+	// the changed lines are copied, dedup updates the entries of CachedResult in place
+	newLines = slices.Clone(m.CachedResult[max(firstChanged-m.TrimmedCount, 0):])
+
+	if m.Tail != nil {
+		m.sendToTail_nolock(newLines)
+		return
+	}
 
 	streamUpdate := rpctypes.StreamUpdateData{
 		WidgetId:      m.WidgetId,
@@ -181,8 +219,8 @@ func (m *SearchManager) ProcessNewLine(line ds.LogLine) {
 		SearchedCount: m.Stats.SearchedCount,
 		TotalCount:    m.Stats.TotalCount,
 		TrimmedLines:  m.TrimmedCount,
-		Offset:        len(m.CachedResult) - 1 + m.TrimmedCount,
-		Lines:         []ds.LogLine{line},
+		Offset:        len(m.CachedResult) - len(newLines) + m.TrimmedCount,
+		Lines:         newLines,
 	}
 	go func() {
 		outrig.SetGoRoutineName("search.stream")
@@ -199,7 +237,7 @@ func MakeSearchManager(widgetId string, appRunId string, peer PeerInterface) *Se
 		LogPeer:     peer,
 		LastUsed:    time.Now(),
 		UserQuery:   uuid.New().String(), // pick a random value that will never match a real search term
-		MarkManager: MakeMarkManager(),
+		MarkManager: peer.GetMarkManager(),
 		Streaming:   true, // Default to streaming mode
 	}
 
@@ -277,6 +315,7 @@ func GetOrCreateManager(widgetId string, appRunId string, peer PeerInterface) *S
 // deleteSearchManager removes a SearchManager from the widgetManagers map and unregisters it from the peer
 func deleteSearchManager(manager *SearchManager) {
 	manager.LogPeer.UnregisterSearchManager(manager)
+	manager.closeTail()
 	widgetManagers.Delete(manager.WidgetId)
 }
 
@@ -305,32 +344,51 @@ func (m *SearchManager) GetLastUsed() time.Time {
 // It takes a slice of items of any type T, a total count, a function to convert T to SearchObject,
 // a searcher, and a search context, and returns filtered items and search stats
 func PerformSearch[T any](allItems []T, totalCount int, toSearchObj func(T) SearchObject, searcher Searcher, sctx *SearchContext, colorFilters []ColorSearcher) ([]T, *SearchStats, map[int64]string, error) {
+	return PerformSearchSeq(slices.Values(allItems), totalCount, toSearchObj, searcher, sctx, colorFilters, 0)
+}
+
+// PerformSearchSeq is PerformSearch over an iterator (items are not all held in memory).
+// If maxResults > 0 only the newest maxResults matches are returned.
+// If sctx is canceled the matches found so far are returned (stats.Canceled is set).
+func PerformSearchSeq[T any](allItems iter.Seq[T], totalCount int, toSearchObj func(T) SearchObject, searcher Searcher, sctx *SearchContext, colorFilters []ColorSearcher, maxResults int) ([]T, *SearchStats, map[int64]string, error) {
 	startTs := time.Now()
-	searchedCount := len(allItems)
+	searchedCount := 0
 	result := []T{}
-	colorMap := make(map[int64]string)
+	var lastItem T
+	canceled := false
 
 	// Filter the items based on the search criteria
-	for _, item := range allItems {
-		if searcher == nil {
+	for item := range allItems {
+		if searchedCount%CancelCheckInterval == 0 && sctx.IsCanceled() {
+			canceled = true
+			break
+		}
+		searchedCount++
+		lastItem = item
+		if maxResults > 0 && len(result) >= maxResults+TrimSize {
+			// drop the oldest matches (in chunks, so we aren't copying on every match)
+			result = append(result[:0], result[len(result)-maxResults:]...)
+		}
+		if searcher == nil || searcher.Match(sctx, toSearchObj(item)) {
 			result = append(result, item)
-			continue
 		}
+	}
 
-		// Convert the item to a SearchObject using the provided function
-		searchObj := toSearchObj(item)
-
-		if searcher.Match(sctx, searchObj) {
-			result = append(result, item)
+	if maxResults > 0 && len(result) > maxResults {
+		result = result[len(result)-maxResults:]
+	}
 
+	// colors are only computed for the returned items (the map doesn't hold the colors of dropped matches)
+	colorMap := make(map[int64]string)
+	if searcher != nil && len(colorFilters) > 0 {
+		for _, item := range result {
+			searchObj := toSearchObj(item)
 			// Apply color filters in reverse order (last color wins)
-			if len(colorFilters) > 0 {
-				for i := len(colorFilters) - 1; i >= 0; i-- {
-					colorFilter := colorFilters[i]
-					if colorFilter.Searcher.Match(sctx, searchObj) {
-						colorMap[searchObj.GetId()] = colorFilter.Color
-						break // First match wins (since we're going in reverse)
-					}
+			for i := len(colorFilters) - 1; i >= 0; i-- {
+				colorFilter := colorFilters[i]
+				if colorFilter.Searcher.Match(sctx, searchObj) {
+					colorMap[searchObj.GetId()] = colorFilter.Color
+					break // First match wins (since we're going in reverse)
 				}
 			}
 		}
@@ -338,9 +396,9 @@ func PerformSearch[T any](allItems []T, totalCount int, toSearchObj func(T) Sear
 
 	// Determine the last line number (if available)
 	var lastLineNum int64 = 0
-	if len(allItems) > 0 {
+	if searchedCount > 0 {
 		// Try to get the last item's ID, which might be a line number
-		if searchObj := toSearchObj(allItems[len(allItems)-1]); searchObj != nil {
+		if searchObj := toSearchObj(lastItem); searchObj != nil {
 			lastLineNum = searchObj.GetId()
 		}
 	}
@@ -351,11 +409,19 @@ func PerformSearch[T any](allItems []T, totalCount int, toSearchObj func(T) Sear
 		SearchedCount:  searchedCount,
 		LastLineNum:    lastLineNum,
 		SearchDuration: searchDuration,
+		Canceled:       canceled,
 	}
-	log.Printf("SearchManager: filtered %d/%d items in %dms\n", len(result), searchedCount, searchDuration)
+	log.Printf("SearchManager: filtered %d/%d items in %dms%s\n", len(result), searchedCount, searchDuration, canceledLogSuffix(canceled))
 	return result, stats, colorMap, nil
 }
 
+func canceledLogSuffix(canceled bool) string {
+	if canceled {
+		return " (canceled)"
+	}
+	return ""
+}
+
 // GetMarkManager returns the MarkManager for the given widget ID
 func GetMarkManager(widgetId string) *MarkManager {
 	manager := GetManager(widgetId)
@@ -385,16 +451,35 @@ func (m *SearchManager) GetMarkedLogLines() ([]ds.LogLine, error) {
 	}
 	// Get all log lines and total count from the LogLinePeer in a single synchronized call
 	// We don't need to hold the lock during the search since PerformSearch is a pure function
-	allLogs, totalCount := m.LogPeer.GetLogLines()
-	result, _, _, err := PerformSearch(allLogs, totalCount, LogLineToSearchObject, searcher, sctx, nil)
+	allLogs, totalCount := m.LogPeer.GetLogLineSeq()
+	result, _, _, err := PerformSearchSeq(allLogs, totalCount, LogLineToSearchObject, searcher, sctx, nil, 0)
 	return result, err
 }
 
+// getSearchLogLineSeq returns the log lines a search has to scan. Top-level exact terms are looked up in the
+// LogIndex (if the app run has one and useIndex is set), otherwise a top-level time window (@last, @between)
+// is pushed down to the log storage. The index returns only the candidate lines, so it can't be used when
+// the search needs the lines around the matches (context lines).
+func getSearchLogLineSeq(peer PeerInterface, searcher Searcher, userQuery Searcher, useIndex bool) (iter.Seq[ds.LogLine], int) {
+	if terms := GetIndexTerms(searcher, userQuery); useIndex && len(terms) > 0 {
+		if allLogs, totalCount, ok := peer.GetIndexedLogLineSeq(terms); ok {
+			return allLogs, totalCount
+		}
+	}
+	if window := GetTimeWindow(searcher, userQuery); window != nil {
+		return peer.GetLogLineSeqInWindow(*window)
+	}
+	return peer.GetLogLineSeq()
+}
+
 // maybeRunNewSearch checks if a new search is needed and performs it if necessary
-// Returns error spans from the user query and an error if the search fails
-func (m *SearchManager) maybeRunNewSearch(searchTerm, systemQuery string, streaming bool) ([]rpctypes.SearchErrorSpan, error) {
-	// If the search term, system query, and streaming flag haven't changed, no need to run a new search
-	if searchTerm == m.UserQuery && systemQuery == m.SystemQuery && streaming == m.Streaming {
+// Returns error spans from the user query and an error if the search fails.
+// If ctx is canceled mid-scan the partial result is kept (m.Stats.Canceled is set) but not reused by the next request.
+func (m *SearchManager) maybeRunNewSearch(ctx context.Context, searchTerm, systemQuery string, streaming bool, contextBefore int, contextAfter int, dedup bool, dedupWindowMs int64) ([]rpctypes.SearchErrorSpan, error) {
+	// If the search term, system query, streaming flag, context, and dedup haven't changed, no need to run a new search
+	if searchTerm == m.UserQuery && systemQuery == m.SystemQuery && streaming == m.Streaming &&
+		contextBefore == m.ContextBefore && contextAfter == m.ContextAfter &&
+		dedup == m.Dedup && dedupWindowMs == m.DedupWindowMs {
 		return nil, nil
 	}
 
@@ -424,19 +509,41 @@ func (m *SearchManager) maybeRunNewSearch(searchTerm, systemQuery string, stream
 	m.SystemQuery = systemQuery
 	m.Streaming = streaming
 	m.ColorFilters = colorFilters
+	m.ContextBefore = contextBefore
+	m.ContextAfter = contextAfter
+	m.ContextExpander = nil
+	m.Dedup = dedup
+	m.DedupWindowMs = dedupWindowMs
+	m.Deduper = nil
 
 	sctx := &SearchContext{
 		MarkedLines: m.MarkManager.GetMarkedIds(),
 		UserQuery:   userSearcher, // Set the user query searcher for #userquery references
+		Ctx:         ctx,
+	}
+	useContext := contextBefore > 0 || contextAfter > 0 || dedup
+	allLogs, totalCount := getSearchLogLineSeq(m.LogPeer, effectiveSearcher, userSearcher, !useContext)
+	if useContext {
+		// context lines need the non-matching lines as well and dedup needs the result order,
+		// so logs are searched with their own loop
+		m.ContextExpander = MakeLogContextExpander(contextBefore, contextAfter)
+		if dedup {
+			m.Deduper = MakeLogDeduper(dedupWindowMs)
+		}
+		result, stats := PerformLogSearchWithContext(allLogs, totalCount, effectiveSearcher, sctx, colorFilters, MaxCachedResults, m.ContextExpander, m.Deduper)
+		m.CachedResult = result
+		m.TrimmedCount = 0 // a new result starts at 0 for the client, the deduper was rebased to it
+		m.Stats = *stats
+		m.invalidateIfCanceled()
+		return errorSpans, nil
 	}
-	allLogs, totalCount := m.LogPeer.GetLogLines()
-	result, stats, colorMap, err := PerformSearch(allLogs, totalCount, LogLineToSearchObject, effectiveSearcher, sctx, colorFilters)
+	result, stats, colorMap, err := PerformSearchSeq(allLogs, totalCount, LogLineToSearchObject, effectiveSearcher, sctx, colorFilters, MaxCachedResults)
 	if err != nil {
 		m.UserQuery = uuid.New().String() // set to random value to prevent using cache
 		m.SystemQuery = ""                // Clear the cached system query
 		m.UserSearcher = nil              // Clear the cached searchers on error
 		m.SystemSearcher = nil
-		m.ColorFilters = nil              // Clear the cached color filters on error
+		m.ColorFilters = nil // Clear the cached color filters on error
 		m.Stats = SearchStats{}
 		return errorSpans, err
 	}
@@ -449,10 +556,19 @@ func (m *SearchManager) maybeRunNewSearch(searchTerm, systemQuery string, stream
 	}
 
 	m.CachedResult = result
+	m.TrimmedCount = 0
 	m.Stats = *stats
+	m.invalidateIfCanceled()
 	return errorSpans, nil
 }
 
+// invalidateIfCanceled makes sure a partial (canceled) result is searched again by the next request
+func (m *SearchManager) invalidateIfCanceled() {
+	if m.Stats.Canceled {
+		m.UserQuery = uuid.New().String() // set to random value to prevent using cache
+	}
+}
+
 // SearchLogs handles a search request for logs
 func (m *SearchManager) SearchLogs(ctx context.Context, data rpctypes.SearchRequestData) (rpctypes.SearchResultData, error) {
 	m.Lock.Lock()
@@ -460,7 +576,7 @@ func (m *SearchManager) SearchLogs(ctx context.Context, data rpctypes.SearchRequ
 
 	m.LastUsed = time.Now()
 	m.RpcSource = rpc.GetRpcSourceFromContext(ctx)
-	errorSpans, err := m.maybeRunNewSearch(data.SearchTerm, data.SystemQuery, data.Streaming)
+	errorSpans, err := m.maybeRunNewSearch(ctx, data.SearchTerm, data.SystemQuery, data.Streaming, data.ContextBefore, data.ContextAfter, data.Dedup, data.DedupWindowMs)
 	if err != nil {
 		return rpctypes.SearchResultData{}, err
 	}
@@ -473,6 +589,7 @@ func (m *SearchManager) SearchLogs(ctx context.Context, data rpctypes.SearchRequ
 	totalLogicalPages := totalPages + trimmedPages
 
 	pages := make([]rpctypes.PageData, 0, len(data.RequestPages))
+	matchSpans := make(map[int64][]rpctypes.SearchMatchSpan)
 	seenPages := make(map[int]bool)
 	for _, pageNum := range data.RequestPages {
 		// Handle negative indices (counting from end)
@@ -504,8 +621,9 @@ func (m *SearchManager) SearchLogs(ctx context.Context, data rpctypes.SearchRequ
 		endIndex := utilfn.BoundValue(startIndex+data.PageSize, startIndex, filteredSize)
 		pages = append(pages, rpctypes.PageData{
 			PageNum: resolvedPage,
-			Lines:   m.CachedResult[startIndex:endIndex],
+			Lines:   slices.Clone(m.CachedResult[startIndex:endIndex]), // copied, dedup updates the cached lines in place
 		})
+		m.addMatchSpans(m.CachedResult[startIndex:endIndex], matchSpans)
 	}
 
 	return rpctypes.SearchResultData{
@@ -515,9 +633,29 @@ func (m *SearchManager) SearchLogs(ctx context.Context, data rpctypes.SearchRequ
 		MaxCount:      LogLineBufferSize,
 		Pages:         pages,
 		ErrorSpans:    errorSpans,
+		Canceled:      m.Stats.Canceled,
+		MatchSpans:    matchSpans,
 	}, nil
 }
 
+// addMatchSpans adds the spans of the user's search term for lines to spans (context lines didn't match
+// so they are skipped). Lock must be held.
+func (m *SearchManager) addMatchSpans(lines []ds.LogLine, spans map[int64][]rpctypes.SearchMatchSpan) {
+	if m.UserSearcher == nil {
+		return
+	}
+	sctx := &SearchContext{UserQuery: m.UserSearcher}
+	for _, line := range lines {
+		if line.IsContext {
+			continue
+		}
+		lineSpans := GetMatchSpans(m.UserSearcher, sctx, LogLineToSearchObject(line))
+		if len(lineSpans) > 0 {
+			spans[line.LineNum] = lineSpans
+		}
+	}
+}
+
 // SearchLogsRange handles a range-based search request for logs
 func (m *SearchManager) SearchLogsRange(ctx context.Context, data rpctypes.LogSearchRangeRequest) (rpctypes.LogSearchRangeResultData, error) {
 	m.Lock.Lock()
@@ -525,17 +663,17 @@ func (m *SearchManager) SearchLogsRange(ctx context.Context, data rpctypes.LogSe
 
 	m.LastUsed = time.Now()
 	m.RpcSource = rpc.GetRpcSourceFromContext(ctx)
-	errorSpans, err := m.maybeRunNewSearch(data.SearchTerm, data.SystemQuery, data.Streaming)
+	errorSpans, err := m.maybeRunNewSearch(ctx, data.SearchTerm, data.SystemQuery, data.Streaming, data.ContextBefore, data.ContextAfter, data.Dedup, data.DedupWindowMs)
 	if err != nil {
 		return rpctypes.LogSearchRangeResultData{}, err
 	}
 
 	filteredSize := len(m.CachedResult)
-	
+
 	// Calculate start and end indices with bounds checking
 	startIndex := utilfn.BoundValue(data.Offset, 0, filteredSize)
 	endIndex := utilfn.BoundValue(startIndex+data.Limit, startIndex, filteredSize)
-	
+
 	// Extract the requested range of lines (will be empty slice if startIndex >= filteredSize)
 	var lines []ds.LogLine
 	if startIndex < filteredSize {
@@ -544,6 +682,8 @@ func (m *SearchManager) SearchLogsRange(ctx context.Context, data rpctypes.LogSe
 	} else {
 		lines = []ds.LogLine{}
 	}
+	matchSpans := make(map[int64][]rpctypes.SearchMatchSpan)
+	m.addMatchSpans(lines, matchSpans)
 
 	return rpctypes.LogSearchRangeResultData{
 		FilteredCount: filteredSize,
@@ -552,6 +692,8 @@ func (m *SearchManager) SearchLogsRange(ctx context.Context, data rpctypes.LogSe
 		MaxCount:      LogLineBufferSize,
 		Lines:         lines,
 		ErrorSpans:    errorSpans,
+		Canceled:      m.Stats.Canceled,
+		MatchSpans:    matchSpans,
 	}, nil
 }
 
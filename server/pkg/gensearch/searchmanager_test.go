@@ -0,0 +1,73 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+func searchTestPeer(t *testing.T, peer *testPeer, req rpctypes.SearchRequestData) []string {
+	t.Helper()
+	manager := MakeSearchManager("test-widget", "test-apprun", peer)
+	req.PageSize = 100
+	req.RequestPages = []int{0}
+	result, err := manager.SearchLogs(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SearchLogs(%q): %v", req.SearchTerm, err)
+	}
+	if len(result.Pages) == 0 {
+		return nil
+	}
+	return getLineSummary(result.Pages[0].Lines)
+}
+
+func TestSearchLogsWithIndex(t *testing.T) {
+	msgs := []string{"starting", "loading config", "connection error", "retrying", "connected", "request done"}
+	for _, withIndex := range []bool{false, true} {
+		peer := makeTestPeer(withIndex, msgs...)
+		if got := searchTestPeer(t, peer, rpctypes.SearchRequestData{SearchTerm: "error"}); !reflect.DeepEqual(got, []string{"connection error"}) {
+			t.Errorf("index=%v: got %v", withIndex, got)
+		}
+		// context lines aren't index candidates, the index must not be used for them
+		got := searchTestPeer(t, peer, rpctypes.SearchRequestData{SearchTerm: "error", ContextBefore: 1, ContextAfter: 2})
+		expect := []string{"loading config (context)", "connection error", "retrying (context)", "connected (context)"}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("index=%v: got %v with context, want %v", withIndex, got, expect)
+		}
+	}
+}
+
+func TestPerformSearchSeqMaxResults(t *testing.T) {
+	var msgs []string
+	for i := 0; i < 3*TrimSize; i++ {
+		msgs = append(msgs, "match "+strconv.Itoa(i))
+	}
+	peer := makeTestPeer(false, msgs...)
+	searcher, err := GetSearcher("match")
+	if err != nil {
+		t.Fatal(err)
+	}
+	colorSearcher, err := GetSearcher("match")
+	if err != nil {
+		t.Fatal(err)
+	}
+	colorFilters := []ColorSearcher{{Color: "red", Searcher: colorSearcher}}
+	allLogs, totalCount := peer.GetLogLineSeq()
+	result, stats, colorMap, err := PerformSearchSeq(allLogs, totalCount, LogLineToSearchObject, searcher, &SearchContext{}, colorFilters, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 10 || result[0].LineNum != int64(len(msgs)-9) || stats.SearchedCount != len(msgs) {
+		t.Fatalf("got %d results starting at line %d (searched %d), want the newest 10", len(result), result[0].LineNum, stats.SearchedCount)
+	}
+	// only the returned lines are colored
+	if len(colorMap) != len(result) || colorMap[result[0].LineNum] != "red" {
+		t.Errorf("got %d colors for %d results", len(colorMap), len(result))
+	}
+}
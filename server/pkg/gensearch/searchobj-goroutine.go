@@ -139,7 +139,21 @@ func (gso *GoRoutineSearchObject) GetField(fieldName string, fieldMods int) stri
 		}
 		return gso.Combined
 	}
-	return ""
+	return getTagField(gso.Tags, fieldName, fieldMods)
+}
+
+func (gso *GoRoutineSearchObject) HasField(fieldName string) bool {
+	switch fieldName {
+	case "goid":
+		return true
+	case "name":
+		return gso.GetName() != ""
+	case "stack":
+		return gso.Stack != ""
+	case "state":
+		return gso.State != ""
+	}
+	return hasTagField(gso.Tags, fieldName)
 }
 
 // ParsedGoRoutineToSearchObject converts a ParsedGoRoutine to a GoRoutineSearchObject
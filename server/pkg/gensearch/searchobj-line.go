@@ -4,11 +4,14 @@
 package gensearch
 
 import (
+	"encoding/json"
 	"strconv"
 	"strings"
 
 	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/loglineparser"
+	"github.com/outrigdev/outrig/server/pkg/searchparser"
 )
 
 type LogSearchObject struct {
@@ -16,13 +19,20 @@ type LogSearchObject struct {
 	Msg     string
 	Source  string
 	LineNum int64
+	Ts      int64
+	GoId    int64
+	Level   string
 
 	// Cached values for searches
 	MsgToLower    string
 	SourceToLower string
 	LineNumStr    string
+	GoIdStr       string
+	LenStr        string
 	CachedTags    []string
 	TagsParsed    bool
+	JsonFields    map[string]any // the first JSON object in the message (nil if there is none)
+	JsonParsed    bool
 }
 
 // LogLineToSearchObject converts a ds.LogLine to a SearchObject
@@ -31,6 +41,9 @@ func LogLineToSearchObject(line ds.LogLine) SearchObject {
 		Msg:     line.Msg,
 		Source:  line.Source,
 		LineNum: line.LineNum,
+		Ts:      line.Ts,
+		GoId:    line.GoId,
+		Level:   line.Level,
 	}
 }
 
@@ -46,6 +59,10 @@ func (lso *LogSearchObject) GetId() int64 {
 	return lso.LineNum
 }
 
+func (lso *LogSearchObject) GetTs() int64 {
+	return lso.Ts
+}
+
 func (lso *LogSearchObject) GetField(fieldName string, fieldMods int) string {
 	if fieldName == "" || fieldName == "msg" || fieldName == "line" {
 		if fieldMods&FieldMod_ToLower != 0 {
@@ -56,7 +73,7 @@ func (lso *LogSearchObject) GetField(fieldName string, fieldMods int) string {
 		}
 		return lso.Msg
 	}
-	if fieldName == "source" {
+	if fieldName == "source" || fieldName == "src" {
 		if fieldMods&FieldMod_ToLower != 0 {
 			if lso.SourceToLower == "" {
 				lso.SourceToLower = strings.ToLower(lso.Source)
@@ -71,5 +88,104 @@ func (lso *LogSearchObject) GetField(fieldName string, fieldMods int) string {
 		}
 		return lso.LineNumStr
 	}
-	return ""
+	if fieldName == "len" {
+		// the length of the message in bytes (without the newline), for numeric searches like $len:>1kb
+		if lso.LenStr == "" {
+			lso.LenStr = strconv.Itoa(len(strings.TrimSuffix(lso.Msg, "\n")))
+		}
+		return lso.LenStr
+	}
+	if fieldName == "goid" {
+		// lines without a goroutine (stdout/stderr) never match a $goid search
+		if lso.GoIdStr == "" && lso.GoId != 0 {
+			lso.GoIdStr = strconv.FormatInt(lso.GoId, 10)
+		}
+		return lso.GoIdStr
+	}
+	if fieldName == searchparser.LevelField {
+		return lso.Level
+	}
+	// other fields are JSON fields, so $user:bob matches the lines $user? finds
+	value, ok := getJsonField(lso.getJsonFields(), fieldName)
+	if !ok {
+		return ""
+	}
+	valueStr := formatJsonFieldValue(value)
+	if fieldMods&FieldMod_ToLower != 0 {
+		return strings.ToLower(valueStr)
+	}
+	return valueStr
+}
+
+func (lso *LogSearchObject) HasField(fieldName string) bool {
+	switch fieldName {
+	case "", "msg", "line", "linenum", "len":
+		return true
+	case "source", "src":
+		return lso.Source != ""
+	case "goid":
+		return lso.GoId != 0
+	case searchparser.LevelField:
+		return lso.Level != ""
+	}
+	_, ok := getJsonField(lso.getJsonFields(), fieldName)
+	return ok
+}
+
+func (lso *LogSearchObject) getJsonFields() map[string]any {
+	if !lso.JsonParsed {
+		lso.JsonFields = parseJsonFields(lso.Msg)
+		lso.JsonParsed = true
+	}
+	return lso.JsonFields
+}
+
+// parseJsonFields returns the first JSON object in a log message (structured loggers put one in each line)
+func parseJsonFields(msg string) map[string]any {
+	if !strings.Contains(msg, "{") {
+		return nil
+	}
+	pos := loglineparser.FindFirstJSON(msg, false)
+	if pos == nil {
+		return nil
+	}
+	// numbers are kept as json.Number so $field:value compares them as written
+	dec := json.NewDecoder(strings.NewReader(msg[pos.Start:pos.End]))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// getJsonField looks up a top-level key, then a dotted path into nested objects (http.status)
+func getJsonField(fields map[string]any, path string) (any, bool) {
+	if fields == nil {
+		return nil, false
+	}
+	if value, ok := fields[path]; ok {
+		return value, true
+	}
+	key, rest, found := strings.Cut(path, ".")
+	if !found {
+		return nil, false
+	}
+	nested, ok := fields[key].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return getJsonField(nested, rest)
+}
+
+// formatJsonFieldValue returns strings as is and other values as JSON (200, true, null, {"a":1})
+func formatJsonFieldValue(value any) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	barr, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(barr)
 }
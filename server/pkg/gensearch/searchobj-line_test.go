@@ -0,0 +1,127 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+func TestLogLineLenField(t *testing.T) {
+	short := ds.LogLine{LineNum: 1, Msg: "short line\n"}
+	long := ds.LogLine{LineNum: 2, Msg: strings.Repeat("x", 2000) + "\n"}
+
+	tests := []struct {
+		search      string
+		expectShort bool
+		expectLong  bool
+	}{
+		{"$len:>1kb", false, true},
+		{"$len:<=10", true, false},
+		{"$len:>=10", true, true},
+		{"$len?", true, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.search, func(t *testing.T) {
+			searcher, err := GetSearcher(tc.search)
+			if err != nil {
+				t.Fatalf("GetSearcher(%q): %v", tc.search, err)
+			}
+			sctx := &SearchContext{}
+			if got := searcher.Match(sctx, LogLineToSearchObject(short)); got != tc.expectShort {
+				t.Errorf("got %v for the short line, want %v", got, tc.expectShort)
+			}
+			if got := searcher.Match(sctx, LogLineToSearchObject(long)); got != tc.expectLong {
+				t.Errorf("got %v for the long line, want %v", got, tc.expectLong)
+			}
+		})
+	}
+}
+
+func TestParseJsonFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		msg    string
+		expect map[string]any
+	}{
+		{"no json", "plain text\n", nil},
+		{"object after a prefix", `INFO request {"user":"bob","status":200}` + "\n", map[string]any{"user": "bob", "status": json.Number("200")}},
+		{"first object only", `{"a":1} {"b":2}`, map[string]any{"a": json.Number("1")}},
+		{"invalid json", `{"a": }`, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseJsonFields(tc.msg); !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("got %#v, want %#v", got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestLogLineHasField(t *testing.T) {
+	jsonLine := LogLineToSearchObject(ds.LogLine{LineNum: 1, Source: "/dev/stdout", Msg: `{"user":"Bob","http":{"status":404},"ok":false,"none":null}` + "\n"})
+	plainLine := LogLineToSearchObject(ds.LogLine{LineNum: 2, Msg: "plain\n"})
+	tests := []struct {
+		field       string
+		expectJson  bool
+		expectPlain bool
+	}{
+		{"msg", true, true},
+		{"len", true, true},
+		{"source", true, false},
+		{"goid", false, false},
+		{"user", true, false},
+		{"http.status", true, false},
+		{"http.method", false, false},
+		{"none", true, false},
+		{"missing", false, false},
+	}
+	for _, tc := range tests {
+		if got := jsonLine.HasField(tc.field); got != tc.expectJson {
+			t.Errorf("%s: got %v for the json line, want %v", tc.field, got, tc.expectJson)
+		}
+		if got := plainLine.HasField(tc.field); got != tc.expectPlain {
+			t.Errorf("%s: got %v for the plain line, want %v", tc.field, got, tc.expectPlain)
+		}
+	}
+}
+
+func TestFieldValueMatchesExists(t *testing.T) {
+	jsonLine := LogLineToSearchObject(ds.LogLine{LineNum: 1, Msg: `{"user":"Bob","http":{"status":404},"ok":false}` + "\n"})
+	goroutine := &GoRoutineSearchObject{GoId: 1, Tags: []string{"role:Admin", "worker"}}
+	watch := &WatchSearchObject{Name: "counter", Tags: []string{"role:cache"}}
+	tests := []struct {
+		search string
+		obj    SearchObject
+		expect bool
+	}{
+		{"$user?", jsonLine, true},
+		{"$user:bob", jsonLine, true},
+		{"$user:'Bob'", jsonLine, true},
+		{"$user:alice", jsonLine, false},
+		{"$http.status:404", jsonLine, true},
+		{"$http.status:>=400", jsonLine, true},
+		{"$ok:false", jsonLine, true},
+		{"$role?", goroutine, true},
+		{"$role:admin", goroutine, true},
+		{"$role:cache", goroutine, false},
+		{"$worker?", goroutine, true},
+		{"$role:cache", watch, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.search, func(t *testing.T) {
+			searcher, err := GetSearcher(tc.search)
+			if err != nil {
+				t.Fatalf("GetSearcher(%q): %v", tc.search, err)
+			}
+			if got := searcher.Match(&SearchContext{}, tc.obj); got != tc.expect {
+				t.Errorf("got %v, want %v", got, tc.expect)
+			}
+		})
+	}
+}
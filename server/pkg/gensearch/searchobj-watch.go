@@ -10,12 +10,14 @@ import (
 type WatchSearchObject struct {
 	WatchNum int64
 	Name     string
+	Group    string // namespace of the watch (see outrig.WatchGroup)
 	Val      string // Value of the watch
 	Tags     []string
 	Type     string
 
 	// Cached values for searches
 	NameToLower     string
+	GroupToLower    string
 	ValToLower      string
 	TypeToLower     string
 	Combined        string
@@ -40,6 +42,15 @@ func (wso *WatchSearchObject) GetField(fieldName string, fieldMods int) string {
 		}
 		return wso.Name
 	}
+	if fieldName == "group" {
+		if fieldMods&FieldMod_ToLower != 0 {
+			if wso.GroupToLower == "" {
+				wso.GroupToLower = strings.ToLower(wso.Group)
+			}
+			return wso.GroupToLower
+		}
+		return wso.Group
+	}
 	if fieldName == "val" {
 		if fieldMods&FieldMod_ToLower != 0 {
 			if wso.ValToLower == "" {
@@ -72,6 +83,19 @@ func (wso *WatchSearchObject) GetField(fieldName string, fieldMods int) string {
 		}
 		return wso.Combined
 	}
-	return ""
+	return getTagField(wso.Tags, fieldName, fieldMods)
 }
 
+func (wso *WatchSearchObject) HasField(fieldName string) bool {
+	switch fieldName {
+	case "name":
+		return wso.Name != ""
+	case "group":
+		return wso.Group != ""
+	case "val":
+		return wso.Val != ""
+	case "type":
+		return wso.Type != ""
+	}
+	return hasTagField(wso.Tags, fieldName)
+}
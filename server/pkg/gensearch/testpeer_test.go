@@ -0,0 +1,99 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package gensearch
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/searchparser"
+)
+
+// testPeer is an in-memory PeerInterface, with a LogIndex if withIndex is set
+type testPeer struct {
+	lines    []ds.LogLine
+	index    *LogIndex
+	marks    *MarkManager
+	managers []SearchManagerInterface
+}
+
+func makeTestPeer(withIndex bool, msgs ...string) *testPeer {
+	peer := &testPeer{marks: MakeMarkManager()}
+	if withIndex {
+		peer.index = MakeLogIndex()
+	}
+	for _, msg := range msgs {
+		peer.addLine(msg)
+	}
+	return peer
+}
+
+func (p *testPeer) addLine(msg string) ds.LogLine {
+	lineNum := int64(len(p.lines) + 1)
+	line := ds.LogLine{LineNum: lineNum, Ts: 1000 * lineNum, Msg: msg + "\n", Source: "/dev/stdout"}
+	p.lines = append(p.lines, line)
+	if p.index != nil {
+		p.index.AddLine(len(p.lines)-1, line.Msg)
+	}
+	return line
+}
+
+func (p *testPeer) GetLogLineSeq() (iter.Seq[ds.LogLine], int) {
+	return slices.Values(slices.Clone(p.lines)), len(p.lines)
+}
+
+func (p *testPeer) GetLogLineSeqInWindow(window searchparser.TimeWindow) (iter.Seq[ds.LogLine], int) {
+	var lines []ds.LogLine
+	for _, line := range p.lines {
+		if window.Contains(line.Ts) {
+			lines = append(lines, line)
+		}
+	}
+	return slices.Values(lines), len(p.lines)
+}
+
+func (p *testPeer) GetIndexedLogLineSeq(terms []string) (iter.Seq[ds.LogLine], int, bool) {
+	if p.index == nil {
+		return nil, 0, false
+	}
+	positions, ok := p.index.GetCandidates(terms)
+	if !ok {
+		return nil, 0, false
+	}
+	var lines []ds.LogLine
+	for _, pos := range positions {
+		lines = append(lines, p.lines[pos])
+	}
+	return slices.Values(lines), len(p.lines), true
+}
+
+func (p *testPeer) RegisterSearchManager(manager SearchManagerInterface) {
+	p.managers = append(p.managers, manager)
+}
+
+func (p *testPeer) UnregisterSearchManager(manager SearchManagerInterface) {
+	p.managers = slices.DeleteFunc(p.managers, func(m SearchManagerInterface) bool { return m == manager })
+}
+
+func (p *testPeer) GetMarkManager() *MarkManager {
+	return p.marks
+}
+
+// getLineSummary returns "msg" for matches and "msg (context)" for context lines, with " xN" for collapsed lines
+func getLineSummary(lines []ds.LogLine) []string {
+	var rtn []string
+	for _, line := range lines {
+		summary := line.Msg[:len(line.Msg)-1]
+		if line.IsContext {
+			summary += " (context)"
+		}
+		if line.RepeatCount > 0 {
+			summary += fmt.Sprintf(" x%d", line.RepeatCount)
+		}
+		rtn = append(rtn, summary)
+	}
+	return rtn
+}
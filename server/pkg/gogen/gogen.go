@@ -62,7 +62,7 @@ func GenMethod_ResponseStream(buf *strings.Builder, methodDecl *rpc.RpcMethodDec
 	if methodDecl.DefaultResponseDataType != nil {
 		respType = methodDecl.DefaultResponseDataType.String()
 	}
-	fmt.Fprintf(buf, "func %s(w *rpc.RpcClient%s, opts *rpc.RpcOpts) chan rpc.RespUnion[%s] {\n", methodDecl.MethodName, dataType, respType)
+	fmt.Fprintf(buf, "func %s(w *rpc.RpcClient%s, opts *rpc.RpcOpts) chan rpctypes.RespUnion[%s] {\n", methodDecl.MethodName, dataType, respType)
 	fmt.Fprintf(buf, "\treturn SendRpcRequestResponseStreamHelper[%s](w, %q, %s, opts)\n", respType, methodDecl.Command, dataVarName)
 	fmt.Fprintf(buf, "}\n\n")
 }
@@ -0,0 +1,134 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package loglineparser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/outrigdev/outrig/server/pkg/searchparser"
+)
+
+// maxLevelPrefixLen is how far into a plain text line a level word is looked for (levels are logged near
+// the start of the line, after the timestamp)
+const maxLevelPrefixLen = 80
+
+// jsonLevelRegex matches the level field of a JSON log line (zap, logrus, zerolog, and slog use "level")
+var jsonLevelRegex = regexp.MustCompile(`"(?:level|lvl|severity)"\s*:\s*(?:"([A-Za-z]+)|(\d+))`)
+
+// logfmtLevelRegex matches the level field of a logfmt line (logrus and slog text output)
+var logfmtLevelRegex = regexp.MustCompile(`(?:^|\s)(?:level|lvl|severity)="?([A-Za-z]+)`)
+
+// klogLevelRegex matches the level prefix of a glog/klog line (I0102 15:04:05.000000 ...)
+var klogLevelRegex = regexp.MustCompile(`^([IWEF])\d{4} \d`)
+
+var klogLevels = map[string]string{
+	"I": searchparser.LogLevelInfo,
+	"W": searchparser.LogLevelWarn,
+	"E": searchparser.LogLevelError,
+	"F": searchparser.LogLevelFatal,
+}
+
+// DetectLogLevel returns the normalized log level of a log line (see searchparser.NormalizeLogLevel), or ""
+// if no level was found. It recognizes JSON lines with a level field (including pino's numeric levels),
+// logfmt level=... fields, glog/klog prefixes, and level words near the start of plain text lines:
+// uppercase words (INFO, WARN[0000], [ERROR]) and lowercase words that are bracketed ([info]), tab
+// separated (zap console output), or that start the line followed by a colon (panic: ...).
+func DetectLogLevel(msg string) string {
+	if strings.Contains(msg, "\x1b") {
+		msg = ansiEscapeRegex.ReplaceAllString(msg, "")
+	}
+	trimmed := strings.TrimLeft(msg, " \t")
+	if strings.HasPrefix(trimmed, "{") {
+		if matches := jsonLevelRegex.FindStringSubmatch(trimmed); matches != nil {
+			if matches[1] != "" {
+				return searchparser.NormalizeLogLevel(matches[1])
+			}
+			return pinoLevel(matches[2])
+		}
+		return ""
+	}
+	if strings.Contains(msg, "level=") || strings.Contains(msg, "lvl=") || strings.Contains(msg, "severity=") {
+		if matches := logfmtLevelRegex.FindStringSubmatch(msg); matches != nil {
+			if level := searchparser.NormalizeLogLevel(matches[1]); level != "" {
+				return level
+			}
+		}
+	}
+	if matches := klogLevelRegex.FindStringSubmatch(msg); matches != nil {
+		return klogLevels[matches[1]]
+	}
+	return detectPlainLogLevel(msg)
+}
+
+// pinoLevel converts a numeric (pino/bunyan) level to a normalized level
+func pinoLevel(levelStr string) string {
+	levelNum, err := strconv.Atoi(levelStr)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case levelNum >= 60:
+		return searchparser.LogLevelFatal
+	case levelNum >= 50:
+		return searchparser.LogLevelError
+	case levelNum >= 40:
+		return searchparser.LogLevelWarn
+	case levelNum >= 30:
+		return searchparser.LogLevelInfo
+	case levelNum >= 20:
+		return searchparser.LogLevelDebug
+	case levelNum >= 10:
+		return searchparser.LogLevelTrace
+	}
+	return ""
+}
+
+func isAsciiLetter(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+// detectPlainLogLevel looks for a level word in the start of a plain text line
+func detectPlainLogLevel(msg string) string {
+	prefix := msg[:min(len(msg), maxLevelPrefixLen)]
+	for idx := 0; idx < len(prefix); {
+		if !isAsciiLetter(prefix[idx]) {
+			idx++
+			continue
+		}
+		endIdx := idx
+		for endIdx < len(prefix) && isAsciiLetter(prefix[endIdx]) {
+			endIdx++
+		}
+		word := prefix[idx:endIdx]
+		startIdx := idx
+		idx = endIdx
+		if endIdx < len(prefix) && prefix[endIdx] >= '0' && prefix[endIdx] <= '9' {
+			// part of an identifier (ERR42)
+			continue
+		}
+		if startIdx > 0 && prefix[startIdx-1] >= '0' && prefix[startIdx-1] <= '9' {
+			continue
+		}
+		level := searchparser.NormalizeLogLevel(word)
+		if level == "" {
+			continue
+		}
+		if len(word) >= 3 && word == strings.ToUpper(word) {
+			return level
+		}
+		var before, after byte
+		if startIdx > 0 {
+			before = prefix[startIdx-1]
+		}
+		if endIdx < len(msg) {
+			after = msg[endIdx]
+		}
+		if (before == '[' && after == ']') || (before == '\t' && after == '\t') || (startIdx == 0 && after == ':') {
+			return level
+		}
+	}
+	return ""
+}
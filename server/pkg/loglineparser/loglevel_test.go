@@ -0,0 +1,38 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package loglineparser
+
+import "testing"
+
+func TestDetectLogLevel(t *testing.T) {
+	tests := []struct {
+		msg      string
+		expected string
+	}{
+		{`{"level":"info","ts":1700000000.1,"msg":"started"}`, "info"},
+		{`{"time":"2025-01-02T10:00:00Z","level":"WARN","msg":"slow"}`, "warn"},
+		{`{"level":50,"msg":"pino error"}`, "error"},
+		{`{"msg":"no level"}`, ""},
+		{`time="2025-01-02T10:00:00Z" level=error msg="failed"`, "error"},
+		{`time=2025-01-02T10:00:00Z level=DEBUG msg=hi`, "debug"},
+		{"WARN[0000] disk almost full", "warn"},
+		{"\x1b[31mERRO\x1b[0m[0001] connection refused", "error"},
+		{"2025-01-02T10:00:00.000Z\tINFO\tmain.go:12\tstarted", "info"},
+		{"1.7e9\tdebug\tmain.go:12\tdetails", "debug"},
+		{"2025/01/02 10:00:00 [error] lookup failed", "error"},
+		{"2025/01/02 10:00:00 FATAL: out of memory", "fatal"},
+		{"panic: runtime error: index out of range", "fatal"},
+		{"E0102 10:00:00.000000    1234 server.go:12] failed", "error"},
+		{"I0102 10:00:00.000000    1234 server.go:12] ok", "info"},
+		{"the info page was loaded", ""},
+		{"Error handling is tested below", ""},
+		{"ERR42 is not a level", ""},
+		{"plain line", ""},
+	}
+	for _, tt := range tests {
+		if result := DetectLogLevel(tt.msg); result != tt.expected {
+			t.Errorf("DetectLogLevel(%q) = %q, expected %q", tt.msg, result, tt.expected)
+		}
+	}
+}
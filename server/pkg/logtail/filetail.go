@@ -0,0 +1,148 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package logtail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+)
+
+// FilePollInterval is how often a followed file is checked for new lines
+const FilePollInterval = 500 * time.Millisecond
+
+// MaxLineSize is the longest line that is kept together, longer lines are split
+const MaxLineSize = 64 * 1024
+
+// maxReadPerPoll limits how much of a file is read per poll (so a large backlog doesn't block the dispatch)
+const maxReadPerPoll = 4 * 1024 * 1024
+
+// fileTail is the read state of a followed file
+type fileTail struct {
+	path    string
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte // the start of a line that doesn't have its newline yet
+	polled  bool
+}
+
+// runFile follows the file like "tail -F": it starts at the end of the file, and reads a rotated
+// (replaced) or truncated file from the start
+func (t *tailer) runFile(ctx context.Context) {
+	outrig.SetGoRoutineName("logtail.file")
+	ft := &fileTail{path: utilfn.ExpandHomeDir(t.cfg.File)}
+	defer ft.close()
+	t.setRunning(true)
+	defer t.setRunning(false)
+	ticker := time.NewTicker(FilePollInterval)
+	defer ticker.Stop()
+	for {
+		lines, err := ft.poll()
+		if err != nil {
+			t.setError(err.Error())
+		}
+		t.dispatchLines(lines)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (ft *fileTail) close() {
+	if ft.file != nil {
+		ft.file.Close()
+		ft.file = nil
+	}
+}
+
+// poll returns the lines appended since the last poll
+func (ft *fileTail) poll() ([]tailedLine, error) {
+	firstPoll := !ft.polled
+	ft.polled = true
+	info, err := os.Stat(ft.path)
+	if err != nil {
+		// the file may be mid-rotation, finish reading the old one
+		lines, _ := ft.readNew()
+		return lines, fmt.Errorf("cannot stat file: %w", err)
+	}
+	var lines []tailedLine
+	if ft.file == nil || !os.SameFile(ft.info, info) {
+		if ft.file != nil {
+			lines, _ = ft.readNew()
+			lines = append(lines, ft.flushPartial()...)
+			ft.close()
+		}
+		file, err := os.Open(ft.path)
+		if err != nil {
+			return lines, fmt.Errorf("cannot open file: %w", err)
+		}
+		ft.file = file
+		ft.offset = 0
+		if firstPoll {
+			// only lines written after the monitor started following the file
+			ft.offset = info.Size()
+		}
+	} else if info.Size() < ft.offset {
+		// truncated (copytruncate rotation)
+		ft.offset = 0
+		ft.partial = nil
+	}
+	ft.info = info
+	newLines, err := ft.readNew()
+	return append(lines, newLines...), err
+}
+
+// readNew reads from the current offset to the end of the file (up to maxReadPerPoll)
+func (ft *fileTail) readNew() ([]tailedLine, error) {
+	if ft.file == nil {
+		return nil, nil
+	}
+	barr, err := io.ReadAll(io.LimitReader(io.NewSectionReader(ft.file, ft.offset, maxReadPerPoll), maxReadPerPoll))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read file: %w", err)
+	}
+	ft.offset += int64(len(barr))
+	return ft.splitLines(barr), nil
+}
+
+// splitLines returns the complete lines of partial+barr and keeps the rest as the new partial line
+func (ft *fileTail) splitLines(barr []byte) []tailedLine {
+	var lines []tailedLine
+	ts := time.Now().UnixMilli()
+	data := append(ft.partial, barr...)
+	for {
+		idx := bytes.IndexByte(data, '\n')
+		if idx == -1 || idx >= MaxLineSize {
+			if len(data) < MaxLineSize {
+				break
+			}
+			lines = append(lines, tailedLine{Ts: ts, Msg: string(data[:MaxLineSize])})
+			data = data[MaxLineSize:]
+			continue
+		}
+		lines = append(lines, tailedLine{Ts: ts, Msg: string(data[:idx+1])})
+		data = data[idx+1:]
+	}
+	ft.partial = bytes.Clone(data)
+	return lines
+}
+
+// flushPartial returns the partial line as a line (the file was replaced, so it won't be completed)
+func (ft *fileTail) flushPartial() []tailedLine {
+	if len(ft.partial) == 0 {
+		return nil
+	}
+	line := tailedLine{Ts: time.Now().UnixMilli(), Msg: string(ft.partial)}
+	ft.partial = nil
+	return []tailedLine{line}
+}
@@ -0,0 +1,98 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package logtail
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func getTailedMsgs(lines []tailedLine) []string {
+	var msgs []string
+	for _, line := range lines {
+		msgs = append(msgs, line.Msg)
+	}
+	return msgs
+}
+
+func TestSplitLines(t *testing.T) {
+	ft := &fileTail{}
+	if got := getTailedMsgs(ft.splitLines([]byte("one\ntw"))); !reflect.DeepEqual(got, []string{"one\n"}) {
+		t.Errorf("got %q, want the complete line", got)
+	}
+	if string(ft.partial) != "tw" {
+		t.Errorf("got partial %q, want \"tw\"", ft.partial)
+	}
+	if got := getTailedMsgs(ft.splitLines([]byte("o\nthree\n"))); !reflect.DeepEqual(got, []string{"two\n", "three\n"}) {
+		t.Errorf("got %q, want the partial line completed", got)
+	}
+
+	// long lines are split at MaxLineSize
+	long := strings.Repeat("x", MaxLineSize+10)
+	got := getTailedMsgs(ft.splitLines([]byte(long + "\n")))
+	if len(got) != 2 || len(got[0]) != MaxLineSize || got[1] != strings.Repeat("x", 10)+"\n" || len(ft.partial) != 0 {
+		t.Errorf("got %d lines (partial %d bytes), want the long line split in 2", len(got), len(ft.partial))
+	}
+	if got := ft.flushPartial(); got != nil {
+		t.Errorf("got %q flushed without a partial line", getTailedMsgs(got))
+	}
+}
+
+func TestFileTailPoll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	writeFile := func(content string, flag int) {
+		t.Helper()
+		file, err := os.OpenFile(path, flag|os.O_WRONLY|os.O_CREATE, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if _, err := file.WriteString(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	poll := func(ft *fileTail) []string {
+		t.Helper()
+		lines, err := ft.poll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return getTailedMsgs(lines)
+	}
+
+	writeFile("before the tail\n", os.O_TRUNC)
+	ft := &fileTail{path: path}
+	defer ft.close()
+	if got := poll(ft); len(got) != 0 {
+		t.Errorf("got %q on the first poll, want only the lines written later", got)
+	}
+	writeFile("one\ntwo", os.O_APPEND)
+	if got := poll(ft); !reflect.DeepEqual(got, []string{"one\n"}) {
+		t.Errorf("got %q, want the appended line", got)
+	}
+
+	// rotated: the rest of the old file (and its partial line) is read before the new file
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile("new\n", os.O_TRUNC)
+	if got := poll(ft); !reflect.DeepEqual(got, []string{"two", "new\n"}) {
+		t.Errorf("got %q after the rotation, want the old partial line and the new file", got)
+	}
+
+	// truncated (copytruncate): read from the start
+	writeFile("x\n", os.O_TRUNC)
+	if got := poll(ft); !reflect.DeepEqual(got, []string{"x\n"}) {
+		t.Errorf("got %q after the truncation, want the file from the start", got)
+	}
+
+	// a missing file is an error
+	os.Remove(path)
+	if _, err := ft.poll(); err == nil {
+		t.Errorf("got no error for a missing file")
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package logtail
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/outrigdev/outrig"
+)
+
+// JournaldRestartDelay is how long to wait before restarting journalctl after it exited
+const JournaldRestartDelay = 5 * time.Second
+
+// journaldFlushInterval batches the journal lines before they are added to the app runs
+const journaldFlushInterval = 200 * time.Millisecond
+
+// journalEntry holds the fields of a "journalctl -o json" entry that are used (journalctl encodes all
+// values as strings, MESSAGE is an array of bytes if it isn't valid UTF-8)
+type journalEntry struct {
+	Message    json.RawMessage `json:"MESSAGE"`
+	Pid        string          `json:"_PID"`
+	RealtimeTs string          `json:"__REALTIME_TIMESTAMP"` // microseconds
+}
+
+// runJournald follows the unit with journalctl (restarting it if it exits) until ctx is done
+func (t *tailer) runJournald(ctx context.Context) {
+	outrig.SetGoRoutineName("logtail.journald")
+	for {
+		err := t.runJournalctl(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			t.setError(err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(JournaldRestartDelay):
+		}
+	}
+}
+
+// runJournalctl runs "journalctl -f" for the unit (only new entries) until it exits or ctx is done
+func (t *tailer) runJournalctl(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "journalctl", "--follow", "--output=json", "--lines=0", "--unit", t.cfg.Journald)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("journalctl not found (journald tails need systemd)")
+		}
+		return fmt.Errorf("cannot start journalctl: %w", err)
+	}
+	t.setRunning(true)
+	defer t.setRunning(false)
+
+	lineCh := make(chan tailedLine, 1000)
+	go func() {
+		outrig.SetGoRoutineName("logtail.journald.read")
+		defer close(lineCh)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 4*MaxLineSize)
+		for scanner.Scan() {
+			if line, ok := parseJournalEntry(scanner.Bytes()); ok {
+				lineCh <- line
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			// journalctl would block writing to the pipe
+			cmd.Process.Kill()
+		}
+	}()
+
+	ticker := time.NewTicker(journaldFlushInterval)
+	defer ticker.Stop()
+	var lines []tailedLine
+	for {
+		select {
+		case line, ok := <-lineCh:
+			if !ok {
+				t.dispatchLines(lines)
+				if err := cmd.Wait(); err != nil {
+					return fmt.Errorf("journalctl exited: %w", err)
+				}
+				return fmt.Errorf("journalctl exited")
+			}
+			lines = append(lines, line)
+		case <-ticker.C:
+			t.dispatchLines(lines)
+			lines = nil
+		}
+	}
+}
+
+// parseJournalEntry converts a journal entry to a line (entries without a message are skipped)
+func parseJournalEntry(barr []byte) (tailedLine, bool) {
+	var entry journalEntry
+	if err := json.Unmarshal(barr, &entry); err != nil || len(entry.Message) == 0 {
+		return tailedLine{}, false
+	}
+	var msg string
+	if err := json.Unmarshal(entry.Message, &msg); err != nil {
+		var msgBytes []byte
+		var byteVals []int
+		if err := json.Unmarshal(entry.Message, &byteVals); err != nil {
+			return tailedLine{}, false
+		}
+		for _, val := range byteVals {
+			msgBytes = append(msgBytes, byte(val))
+		}
+		msg = string(msgBytes)
+	}
+	if len(msg) > MaxLineSize {
+		msg = msg[:MaxLineSize]
+	}
+	line := tailedLine{Ts: time.Now().UnixMilli(), Msg: msg}
+	if realtimeUs, err := strconv.ParseInt(entry.RealtimeTs, 10, 64); err == nil {
+		line.Ts = realtimeUs / 1000
+	}
+	line.Pid, _ = strconv.Atoi(entry.Pid)
+	return line, true
+}
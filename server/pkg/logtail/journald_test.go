@@ -0,0 +1,39 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package logtail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJournalEntry(t *testing.T) {
+	tests := []struct {
+		name   string
+		entry  string
+		ok     bool
+		expect tailedLine
+	}{
+		{"string message", `{"MESSAGE":"started","_PID":"123","__REALTIME_TIMESTAMP":"1700000000123456"}`, true, tailedLine{Ts: 1700000000123, Msg: "started", Pid: 123}},
+		{"byte array message", `{"MESSAGE":[104,105,255],"_PID":"7","__REALTIME_TIMESTAMP":"5000"}`, true, tailedLine{Ts: 5, Msg: "hi\xff", Pid: 7}},
+		{"no pid", `{"MESSAGE":"kernel","__REALTIME_TIMESTAMP":"1000"}`, true, tailedLine{Ts: 1, Msg: "kernel"}},
+		{"no message", `{"_PID":"123"}`, false, tailedLine{}},
+		{"invalid message", `{"MESSAGE":{"a":1}}`, false, tailedLine{}},
+		{"invalid json", `{"MESSAGE":`, false, tailedLine{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := parseJournalEntry([]byte(tc.entry))
+			if ok != tc.ok || got != tc.expect {
+				t.Errorf("got %+v (ok=%v), want %+v (ok=%v)", got, ok, tc.expect, tc.ok)
+			}
+		})
+	}
+
+	// long messages are truncated, entries without a timestamp get the current time
+	got, ok := parseJournalEntry([]byte(`{"MESSAGE":"` + strings.Repeat("x", MaxLineSize+1) + `"}`))
+	if !ok || len(got.Msg) != MaxLineSize || got.Ts == 0 {
+		t.Errorf("got a %d byte message at %d, want it truncated to %d", len(got.Msg), got.Ts, MaxLineSize)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package logtail follows log files and journald units ("logtails" in monitor.json) and adds their lines
+// to app runs, for apps that log to a file or to the journal instead of stdout. Journald lines carry the
+// pid of the process that logged them and go to the running app run with that pid. Other lines (and
+// journald lines from pids that aren't app runs) go to the running app runs with the configured app name,
+// lines that don't match any running app run are dropped.
+package logtail
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/apppeer"
+	"github.com/outrigdev/outrig/server/pkg/packetrecord"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// ReconcileInterval is how often the configured log tails are checked (config reloads)
+const ReconcileInterval = 2 * time.Second
+
+var (
+	tailersLock sync.Mutex
+	tailers     = make(map[serverbase.LogTailConfig]*tailer)
+)
+
+// tailedLine is a line read from a file or the journal
+type tailedLine struct {
+	Ts  int64
+	Msg string
+	Pid int // 0 if unknown (files)
+}
+
+type tailer struct {
+	cfg      serverbase.LogTailConfig
+	cancelFn context.CancelFunc
+
+	lock       sync.Mutex
+	running    bool
+	lastError  string
+	numLines   int64
+	numDropped int64
+	lastLineTs int64
+}
+
+// Start follows the configured log tails and keeps them in sync with the runtime settings (config reloads)
+// until ctx is done
+func Start(ctx context.Context) {
+	go func() {
+		outrig.SetGoRoutineName("logtail.reconcile")
+		ticker := time.NewTicker(ReconcileInterval)
+		defer ticker.Stop()
+		for {
+			reconcile(ctx)
+			select {
+			case <-ctx.Done():
+				stopAllTailers()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// reconcile starts the newly configured tailers and stops the ones that were removed from the config
+func reconcile(ctx context.Context) {
+	logTails := serverbase.GetRuntimeSettings().LogTails
+	wanted := make(map[serverbase.LogTailConfig]bool)
+	for _, cfg := range logTails {
+		wanted[cfg] = true
+	}
+	tailersLock.Lock()
+	defer tailersLock.Unlock()
+	for cfg, t := range tailers {
+		if !wanted[cfg] {
+			log.Printf("[logtail] stopping %s\n", t.describe())
+			t.cancelFn()
+			delete(tailers, cfg)
+		}
+	}
+	for cfg := range wanted {
+		if tailers[cfg] != nil {
+			continue
+		}
+		t := &tailer{cfg: cfg}
+		tailCtx, cancelFn := context.WithCancel(ctx)
+		t.cancelFn = cancelFn
+		tailers[cfg] = t
+		log.Printf("[logtail] starting %s\n", t.describe())
+		if cfg.Journald != "" {
+			go t.runJournald(tailCtx)
+		} else {
+			go t.runFile(tailCtx)
+		}
+	}
+}
+
+func stopAllTailers() {
+	tailersLock.Lock()
+	defer tailersLock.Unlock()
+	for cfg, t := range tailers {
+		t.cancelFn()
+		delete(tailers, cfg)
+	}
+}
+
+// GetStatuses returns the state of the configured log tails (in config order)
+func GetStatuses() []rpctypes.LogTailStatus {
+	logTails := serverbase.GetRuntimeSettings().LogTails
+	tailersLock.Lock()
+	defer tailersLock.Unlock()
+	var rtn []rpctypes.LogTailStatus
+	for _, cfg := range logTails {
+		status := rpctypes.LogTailStatus{
+			File:     cfg.File,
+			Journald: cfg.Journald,
+			AppName:  cfg.AppName,
+			Source:   getSource(cfg),
+		}
+		if t := tailers[cfg]; t != nil {
+			t.lock.Lock()
+			status.Running = t.running
+			status.Error = t.lastError
+			status.NumLines = t.numLines
+			status.NumDropped = t.numDropped
+			status.LastLineTs = t.lastLineTs
+			t.lock.Unlock()
+		}
+		rtn = append(rtn, status)
+	}
+	return rtn
+}
+
+// getSource returns the log source of the lines (the configured source, or the file name or the unit)
+func getSource(cfg serverbase.LogTailConfig) string {
+	if cfg.Source != "" {
+		return cfg.Source
+	}
+	if cfg.Journald != "" {
+		return strings.TrimSuffix(cfg.Journald, ".service")
+	}
+	return filepath.Base(cfg.File)
+}
+
+func (t *tailer) describe() string {
+	if t.cfg.Journald != "" {
+		return "journald unit " + t.cfg.Journald
+	}
+	return "file " + t.cfg.File
+}
+
+func (t *tailer) setRunning(running bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.running = running
+}
+
+func (t *tailer) setError(errStr string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if errStr != "" && errStr != t.lastError {
+		log.Printf("[logtail] %s: %s\n", t.describe(), errStr)
+	}
+	t.lastError = errStr
+}
+
+// getRunningPeers returns the running app runs (by pid, and by app name for the tailer's app)
+func (t *tailer) getRunningPeers() (map[int]*apppeer.AppRunPeer, []*apppeer.AppRunPeer) {
+	byPid := make(map[int]*apppeer.AppRunPeer)
+	var byAppName []*apppeer.AppRunPeer
+	for _, peer := range apppeer.GetAllAppRunPeers() {
+		if peer.Status != apppeer.AppStatusRunning || peer.AppInfo == nil {
+			continue
+		}
+		byPid[peer.AppInfo.Pid] = peer
+		if t.cfg.AppName != "" && peer.AppInfo.AppName == t.cfg.AppName {
+			byAppName = append(byAppName, peer)
+		}
+	}
+	return byPid, byAppName
+}
+
+// dispatchLines adds the lines to the matching running app runs
+func (t *tailer) dispatchLines(lines []tailedLine) {
+	if len(lines) == 0 {
+		return
+	}
+	byPid, byAppName := t.getRunningPeers()
+	source := getSource(t.cfg)
+	peerLines := make(map[*apppeer.AppRunPeer][]ds.LogLine)
+	var numLines, numDropped int64
+	for _, line := range lines {
+		logLine := ds.LogLine{Ts: line.Ts, Msg: line.Msg, Source: source}
+		if peer := byPid[line.Pid]; line.Pid != 0 && peer != nil {
+			peerLines[peer] = append(peerLines[peer], logLine)
+			numLines++
+			continue
+		}
+		if len(byAppName) == 0 {
+			numDropped++
+			continue
+		}
+		for _, peer := range byAppName {
+			peerLines[peer] = append(peerLines[peer], logLine)
+		}
+		numLines++
+	}
+	for peer, logLines := range peerLines {
+		addLogLines(peer, logLines)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.numLines += numLines
+	t.numDropped += numDropped
+	t.lastLineTs = lines[len(lines)-1].Ts
+	t.lastError = ""
+}
+
+// addLogLines adds the lines to the app run as a multilog packet (like the lines of the ingest endpoint), so
+// they are also part of the app run's packet recording
+func addLogLines(peer *apppeer.AppRunPeer, logLines []ds.LogLine) {
+	logData, err := json.Marshal(&ds.MultiLogLines{LogLines: logLines})
+	if err != nil {
+		log.Printf("[logtail] error marshaling log lines: %v\n", err)
+		return
+	}
+	recorder := packetrecord.Open(peer.AppRunId)
+	defer recorder.Close()
+	recorder.Record(ds.PacketTypeMultiLog, logData)
+	if err := peer.HandlePacket(ds.PacketTypeMultiLog, logData); err != nil {
+		log.Printf("[logtail] error adding log lines to app run %s: %v\n", peer.AppRunId, err)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package logtail
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/apppeer"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// makeTestAppRun registers a running app run with the app name and pid, it is removed when the test ends
+func makeTestAppRun(t *testing.T, name string, appName string, pid int) *apppeer.AppRunPeer {
+	t.Helper()
+	appRunId := "test-" + t.Name() + "-" + name
+	peer := apppeer.GetAppRunPeer(appRunId, false)
+	t.Cleanup(func() {
+		peer.Status = apppeer.AppStatusDone
+		apppeer.ClearAppRun(appRunId)
+	})
+	barr, err := json.Marshal(ds.AppInfo{AppRunId: appRunId, AppName: appName, Pid: pid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := peer.HandlePacket(ds.PacketTypeAppInfo, barr); err != nil {
+		t.Fatal(err)
+	}
+	return peer
+}
+
+func getPeerMsgs(peer *apppeer.AppRunPeer) []string {
+	var msgs []string
+	for _, line := range peer.Logs.GetLastLogLines(100, 0) {
+		msgs = append(msgs, line.Source+":"+strings.TrimSuffix(line.Msg, "\n"))
+	}
+	return msgs
+}
+
+func TestDispatchLines(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(serverbase.OutrigDevEnvName, "")
+	byPid := makeTestAppRun(t, "pid", "other", 1001)
+	first := makeTestAppRun(t, "first", "tailedapp", 1002)
+	second := makeTestAppRun(t, "second", "tailedapp", 1003)
+
+	tl := &tailer{cfg: serverbase.LogTailConfig{Journald: "tailedapp.service", AppName: "tailedapp"}}
+	tl.dispatchLines([]tailedLine{
+		{Ts: 1, Msg: "by pid", Pid: 1001},
+		{Ts: 2, Msg: "by app name", Pid: 9999},
+		{Ts: 3, Msg: "no pid"},
+	})
+	if got := getPeerMsgs(byPid); !reflect.DeepEqual(got, []string{"tailedapp:by pid"}) {
+		t.Errorf("got %q for the pid's app run", got)
+	}
+	expect := []string{"tailedapp:by app name", "tailedapp:no pid"}
+	for _, peer := range []*apppeer.AppRunPeer{first, second} {
+		if got := getPeerMsgs(peer); !reflect.DeepEqual(got, expect) {
+			t.Errorf("got %q for %s, want %q", got, peer.AppRunId, expect)
+		}
+	}
+	if tl.numLines != 3 || tl.numDropped != 0 || tl.lastLineTs != 3 {
+		t.Errorf("got %d lines, %d dropped, last ts %d", tl.numLines, tl.numDropped, tl.lastLineTs)
+	}
+
+	// lines that don't match a running app run are dropped
+	unmatched := &tailer{cfg: serverbase.LogTailConfig{File: "/var/log/unknown.log", AppName: "unknown"}}
+	unmatched.dispatchLines([]tailedLine{{Ts: 4, Msg: "dropped"}})
+	if unmatched.numLines != 0 || unmatched.numDropped != 1 {
+		t.Errorf("got %d lines, %d dropped, want the line dropped", unmatched.numLines, unmatched.numDropped)
+	}
+}
+
+func TestGetSource(t *testing.T) {
+	tests := []struct {
+		cfg    serverbase.LogTailConfig
+		expect string
+	}{
+		{serverbase.LogTailConfig{File: "/var/log/app/server.log"}, "server.log"},
+		{serverbase.LogTailConfig{Journald: "myapp.service"}, "myapp"},
+		{serverbase.LogTailConfig{File: "/var/log/app.log", Source: "custom"}, "custom"},
+	}
+	for _, tc := range tests {
+		if got := getSource(tc.cfg); got != tc.expect {
+			t.Errorf("getSource(%+v) = %q, want %q", tc.cfg, got, tc.expect)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package packetrecord records the raw packets that app runs send to the monitor
+// (one JSON object per line, see RecordedPacket) and replays recorded files back into a monitor.
+package packetrecord
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// RecordFileSuffix is appended to the app run id to make the name of a recording
+const RecordFileSuffix = ".packets.jsonl"
+
+// RecordedPacket is a single line of a recording, the timestamps in Data are already corrected to the
+// monitor's clock (see apppeer.CorrectPacketData)
+type RecordedPacket struct {
+	Ts   int64           `json:"ts"` // time the server received the packet (unix ms)
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Recorder appends the packets of one app run to its recording.
+// The packet and log connections of an app run share the same Recorder.
+type Recorder struct {
+	Lock     sync.Mutex
+	AppRunId string
+	File     *os.File
+	RefCount int
+}
+
+var recordersLock sync.Mutex
+var recorders = make(map[string]*Recorder)
+
+// GetRecordFileName returns the path of the recording for an app run
+func GetRecordFileName(dir string, appRunId string) string {
+	return filepath.Join(dir, appRunId+RecordFileSuffix)
+}
+
+// Open returns the Recorder for an app run (creating the recording if needed).
+// It returns nil when packet recording is disabled. Callers must Close the returned Recorder.
+func Open(appRunId string) *Recorder {
+	dir := serverbase.PacketRecordDir
+	if dir == "" || appRunId == "" {
+		return nil
+	}
+	recordersLock.Lock()
+	defer recordersLock.Unlock()
+	if rec, ok := recorders[appRunId]; ok {
+		rec.RefCount++
+		return rec
+	}
+	fileName := GetRecordFileName(dir, appRunId)
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.Printf("Error opening packet recording %s: %v\n", fileName, err)
+		return nil
+	}
+	log.Printf("Recording packets for app run ID: %s to %s\n", appRunId, fileName)
+	rec := &Recorder{AppRunId: appRunId, File: file, RefCount: 1}
+	recorders[appRunId] = rec
+	return rec
+}
+
+// IsRecording returns true while the recording of an app run is open (its app run is connected)
+func IsRecording(appRunId string) bool {
+	recordersLock.Lock()
+	defer recordersLock.Unlock()
+	_, ok := recorders[appRunId]
+	return ok
+}
+
+// Record appends a packet to the recording (safe to call on a nil Recorder)
+func (r *Recorder) Record(packetType string, packetData json.RawMessage) {
+	if r == nil {
+		return
+	}
+	barr, err := json.Marshal(RecordedPacket{Ts: time.Now().UnixMilli(), Type: packetType, Data: packetData})
+	if err != nil {
+		log.Printf("Error marshaling recorded packet: %v\n", err)
+		return
+	}
+	barr = append(barr, '\n')
+	r.Lock.Lock()
+	defer r.Lock.Unlock()
+	if r.File == nil {
+		return
+	}
+	if _, err := r.File.Write(barr); err != nil {
+		log.Printf("Error writing packet recording for app run ID: %s: %v\n", r.AppRunId, err)
+	}
+}
+
+// Close releases the Recorder, the recording is closed when its last connection is done
+func (r *Recorder) Close() {
+	if r == nil {
+		return
+	}
+	recordersLock.Lock()
+	defer recordersLock.Unlock()
+	r.RefCount--
+	if r.RefCount > 0 {
+		return
+	}
+	delete(recorders, r.AppRunId)
+	r.Lock.Lock()
+	defer r.Lock.Unlock()
+	if r.File != nil {
+		r.File.Close()
+		r.File = nil
+	}
+}
+
+// EnsureRecordDir creates the recording directory and returns its absolute path
+func EnsureRecordDir(dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid packet recording directory %q: %w", dir, err)
+	}
+	if err := os.MkdirAll(absDir, 0755); err != nil {
+		return "", fmt.Errorf("cannot create packet recording directory %s: %w", absDir, err)
+	}
+	return absDir, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package packetrecord
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig/pkg/comm"
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/ds"
+)
+
+const maxRecordedLineSize = 64 * 1024 * 1024
+
+// ReplayOpts controls how a recording is replayed
+type ReplayOpts struct {
+	// Speed is the playback speed relative to the original timing (2 is twice as fast), 0 sends the packets as fast as possible
+	Speed float64
+	// ProgressFn (optional) is called after each packet is sent
+	ProgressFn func(numSent int, pkt *RecordedPacket)
+}
+
+// ReadRecording reads all of the packets in a recording
+func ReadRecording(fileName string) ([]*RecordedPacket, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var packets []*RecordedPacket
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordedLineSize)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var pkt RecordedPacket
+		if err := json.Unmarshal(line, &pkt); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid recorded packet: %w", fileName, lineNum, err)
+		}
+		packets = append(packets, &pkt)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", fileName, err)
+	}
+	return packets, nil
+}
+
+// RewriteAppRunId replaces the app run id in a recorded AppInfo packet
+func RewriteAppRunId(data json.RawMessage, appRunId string) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("invalid appinfo packet: %w", err)
+	}
+	idBarr, _ := json.Marshal(appRunId)
+	fields["apprunid"] = idBarr
+	return json.Marshal(fields)
+}
+
+// Replay sends the packets of a recording to the monitor as a new app run.
+// It returns the id of the new app run and the number of packets sent.
+func Replay(fileName string, opts ReplayOpts, cfg *config.Config) (string, int, error) {
+	packets, err := ReadRecording(fileName)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(packets) == 0 {
+		return "", 0, fmt.Errorf("recording %s has no packets", fileName)
+	}
+	appRunId := uuid.New().String()
+	connWrap, permErr, transErr := comm.Connect(comm.ConnectionModePacket, "", appRunId, cfg)
+	if permErr != nil {
+		return "", 0, permErr
+	}
+	if transErr != nil {
+		return "", 0, transErr
+	}
+	if connWrap == nil {
+		return "", 0, fmt.Errorf("no outrig monitor address configured")
+	}
+	defer connWrap.Close()
+
+	// the monitor also sends commands over the packet connection, these are ignored
+	go func() {
+		for {
+			if _, err := connWrap.ReadLine(); err != nil {
+				return
+			}
+		}
+	}()
+
+	startTime := time.Now()
+	firstTs := packets[0].Ts
+	for idx, pkt := range packets {
+		if opts.Speed > 0 {
+			offset := time.Duration(float64(pkt.Ts-firstTs)/opts.Speed) * time.Millisecond
+			if wait := time.Until(startTime.Add(offset)); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+		data := pkt.Data
+		if pkt.Type == ds.PacketTypeAppInfo {
+			data, err = RewriteAppRunId(data, appRunId)
+			if err != nil {
+				return appRunId, idx, err
+			}
+		}
+		barr, err := json.Marshal(&ds.PacketType{Type: pkt.Type, Data: data})
+		if err != nil {
+			return appRunId, idx, fmt.Errorf("error marshaling packet: %w", err)
+		}
+		if err := connWrap.WriteLine(string(barr)); err != nil {
+			return appRunId, idx, fmt.Errorf("error sending packet: %w", err)
+		}
+		if opts.ProgressFn != nil {
+			opts.ProgressFn(idx+1, pkt)
+		}
+	}
+	return appRunId, len(packets), nil
+}
@@ -68,7 +68,8 @@ type RpcOpts struct {
 	Timeout        int64  `json:"timeout,omitempty"`
 	NoResponse     bool   `json:"noresponse,omitempty"`
 	Route          string `json:"route,omitempty"`
-	StreamCancelFn func() `json:"-"` // this is an *output* parameter, set by the handler
+	ReqId          string `json:"reqid,omitempty"` // use this request id (so the caller can cancel the request), default is a new uuid
+	StreamCancelFn func() `json:"-"`               // this is an *output* parameter, set by the handler
 }
 
 type rpcContextKey struct{}
@@ -124,18 +125,19 @@ func (w *RpcClient) RecvRpcMessage() ([]byte, bool) {
 }
 
 type RpcMessage struct {
-	Command   string `json:"command,omitempty"`
-	ReqId     string `json:"reqid,omitempty"`
-	ResId     string `json:"resid,omitempty"`
-	Timeout   int64  `json:"timeout,omitempty"`
-	Route     string `json:"route,omitempty"`     // to route/forward requests to alternate servers
-	AuthToken string `json:"authtoken,omitempty"` // needed for routing unauthenticated requests (RpcMultiProxy)
-	Source    string `json:"source,omitempty"`    // source route id
-	Cont      bool   `json:"cont,omitempty"`      // flag if additional requests/responses are forthcoming
-	Cancel    bool   `json:"cancel,omitempty"`    // used to cancel a streaming request or response (sent from the side that is not streaming)
-	Error     string `json:"error,omitempty"`
-	DataType  string `json:"datatype,omitempty"`
-	Data      any    `json:"data,omitempty"`
+	Command   string            `json:"command,omitempty"`
+	ReqId     string            `json:"reqid,omitempty"`
+	ResId     string            `json:"resid,omitempty"`
+	Timeout   int64             `json:"timeout,omitempty"`
+	Route     string            `json:"route,omitempty"`     // to route/forward requests to alternate servers
+	AuthToken string            `json:"authtoken,omitempty"` // needed for routing unauthenticated requests (RpcMultiProxy)
+	Source    string            `json:"source,omitempty"`    // source route id
+	Cont      bool              `json:"cont,omitempty"`      // flag if additional requests/responses are forthcoming
+	Cancel    bool              `json:"cancel,omitempty"`    // used to cancel a streaming request or response (sent from the side that is not streaming)
+	Error     string            `json:"error,omitempty"`
+	DataType  string            `json:"datatype,omitempty"`
+	Data      any               `json:"data,omitempty"`
+	DataBatch []json.RawMessage `json:"databatch,omitempty" tstype:"RpcMessage[]"` // a batch of messages (see RunRpcBatcher), no other fields are set
 }
 
 func (r *RpcMessage) IsRpcRequest() bool {
@@ -143,6 +145,12 @@ func (r *RpcMessage) IsRpcRequest() bool {
 }
 
 func (r *RpcMessage) Validate() error {
+	if r.DataBatch != nil {
+		if r.Command != "" || r.ReqId != "" || r.ResId != "" || r.Data != nil {
+			return fmt.Errorf("databatch packets may not have command, reqid, resid, or data set")
+		}
+		return nil
+	}
 	if r.ReqId != "" && r.ResId != "" {
 		return fmt.Errorf("request packets may not have both reqid and resid set")
 	}
@@ -267,8 +275,30 @@ func (w *RpcClient) cancelRequest(reqId string) {
 	handler := w.ResponseHandlerMap[reqId]
 	if handler != nil {
 		handler.canceled.Store(true)
+		// cancel the handler's context so long running commands can stop early
+		cancelFn := handler.contextCancelFn.Load()
+		if cancelFn != nil && *cancelFn != nil {
+			(*cancelFn)()
+		}
 	}
+}
+
+// makeRequestContext returns the context of a request (for the requester and for the handler). Requests
+// without a timeout get DefaultTimeoutMs, except response streams (e.g. log tails), which have no deadline
+// and run until the requester cancels them, the handler ends the stream, or the connection is closed.
+func makeRequestContext(command string, timeoutMs int64) (context.Context, context.CancelFunc) {
+	if timeoutMs <= 0 {
+		if isResponseStream(command) {
+			return context.WithCancel(context.Background())
+		}
+		timeoutMs = DefaultTimeoutMs
+	}
+	return context.WithTimeout(context.Background(), time.Duration(timeoutMs)*time.Millisecond)
+}
 
+func isResponseStream(command string) bool {
+	methodDecl := WshCommandDeclMap[command]
+	return methodDecl != nil && methodDecl.CommandType == RpcType_ResponseStream
 }
 
 func (w *RpcClient) handleRequest(req *RpcMessage) {
@@ -289,11 +319,7 @@ func (w *RpcClient) handleRequest(req *RpcMessage) {
 	}
 
 	var respHandler *RpcResponseHandler
-	timeoutMs := req.Timeout
-	if timeoutMs <= 0 {
-		timeoutMs = DefaultTimeoutMs
-	}
-	ctx, cancelFn := context.WithTimeout(context.Background(), time.Duration(timeoutMs)*time.Millisecond)
+	ctx, cancelFn := makeRequestContext(req.Command, req.Timeout)
 	ctx = withRpcClientContext(ctx, w)
 	respHandler = &RpcResponseHandler{
 		w:               w,
@@ -361,31 +387,41 @@ outer:
 			continue
 		}
 
-		var msg RpcMessage
-		err := json.Unmarshal(msgBytes, &msg)
-		if err != nil {
-			log.Printf("[%s] rpcclient received bad message: %v\n", w.DebugName, err)
-			continue
+		w.processMessage(msgBytes)
+	}
+}
+
+func (w *RpcClient) processMessage(msgBytes []byte) {
+	var msg RpcMessage
+	err := json.Unmarshal(msgBytes, &msg)
+	if err != nil {
+		log.Printf("[%s] rpcclient received bad message: %v\n", w.DebugName, err)
+		return
+	}
+	if msg.DataBatch != nil {
+		for _, batchMsgBytes := range msg.DataBatch {
+			w.processMessage(batchMsgBytes)
 		}
-		if msg.Cancel {
-			if msg.ReqId != "" {
-				w.cancelRequest(msg.ReqId)
-			}
-			continue
+		return
+	}
+	if msg.Cancel {
+		if msg.ReqId != "" {
+			w.cancelRequest(msg.ReqId)
 		}
-		if msg.IsRpcRequest() {
-			go func() {
-				outrig.SetGoRoutineName("rpc.req/" + w.DebugName + "/" + msg.Command)
-				defer func() {
-					panichandler.PanicHandler("handleRequest:goroutine", recover())
-				}()
-				w.handleRequest(&msg)
+		return
+	}
+	if msg.IsRpcRequest() {
+		go func() {
+			outrig.SetGoRoutineName("rpc.req/" + w.DebugName + "/" + msg.Command)
+			defer func() {
+				panichandler.PanicHandler("handleRequest:goroutine", recover())
 			}()
-		} else {
-			w.sendRespWithBlockMessage(msg)
-			if !msg.Cont {
-				w.unregisterRpc(msg.ResId, nil)
-			}
+			w.handleRequest(&msg)
+		}()
+	} else {
+		w.sendRespWithBlockMessage(msg)
+		if !msg.Cont {
+			w.unregisterRpc(msg.ResId, nil)
 		}
 	}
 }
@@ -683,10 +719,6 @@ func (w *RpcClient) SendComplexRequest(command string, data any, opts *RpcOpts)
 	if opts == nil {
 		opts = &RpcOpts{}
 	}
-	timeoutMs := opts.Timeout
-	if timeoutMs <= 0 {
-		timeoutMs = DefaultTimeoutMs
-	}
 	defer func() {
 		panichandler.PanicHandler("SendComplexRequest", recover())
 	}()
@@ -698,16 +730,19 @@ func (w *RpcClient) SendComplexRequest(command string, data any, opts *RpcOpts)
 		ctxCancelFn: &atomic.Pointer[context.CancelFunc]{},
 	}
 	var cancelFn context.CancelFunc
-	handler.ctx, cancelFn = context.WithTimeout(context.Background(), time.Duration(timeoutMs)*time.Millisecond)
+	handler.ctx, cancelFn = makeRequestContext(command, opts.Timeout)
 	handler.ctxCancelFn.Store(&cancelFn)
 	if !opts.NoResponse {
-		handler.reqId = uuid.New().String()
+		handler.reqId = opts.ReqId
+		if handler.reqId == "" {
+			handler.reqId = uuid.New().String()
+		}
 	}
 	req := &RpcMessage{
 		Command:   command,
 		ReqId:     handler.reqId,
 		Data:      data,
-		Timeout:   timeoutMs,
+		Timeout:   max(opts.Timeout, 0),
 		Route:     opts.Route,
 		AuthToken: w.GetAuthToken(),
 	}
@@ -731,6 +766,13 @@ func (w *RpcClient) setServerDone() {
 	defer w.Lock.Unlock()
 	w.ServerDone = true
 	close(w.CtxDoneCh)
+	// the connection is closed, stop the running handlers (response streams have no deadline)
+	for _, handler := range w.ResponseHandlerMap {
+		cancelFn := handler.contextCancelFn.Load()
+		if cancelFn != nil && *cancelFn != nil {
+			(*cancelFn)()
+		}
+	}
 	go func() {
 		outrig.SetGoRoutineName("rpc.drain")
 		utilfn.DrainChan(w.InputCh)
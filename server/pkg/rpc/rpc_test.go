@@ -0,0 +1,41 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMakeRequestContext(t *testing.T) {
+	tests := []struct {
+		name         string
+		command      string
+		timeoutMs    int64
+		hasDeadline  bool
+		wantDeadline time.Duration
+	}{
+		{"call", "getappruns", 0, true, DefaultTimeoutMs * time.Millisecond},
+		{"call with a timeout", "getappruns", 60000, true, time.Minute},
+		{"response stream", "logtail", 0, false, 0},
+		{"response stream with a timeout", "logtail", 60000, true, time.Minute},
+		{"unknown command", "nosuchcommand", 0, true, DefaultTimeoutMs * time.Millisecond},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancelFn := makeRequestContext(tc.command, tc.timeoutMs)
+			deadline, ok := ctx.Deadline()
+			if ok != tc.hasDeadline {
+				t.Fatalf("got deadline=%v, want %v", ok, tc.hasDeadline)
+			}
+			if ok && (time.Until(deadline) > tc.wantDeadline || time.Until(deadline) < tc.wantDeadline-time.Second) {
+				t.Errorf("got a deadline in %v, want %v", time.Until(deadline), tc.wantDeadline)
+			}
+			cancelFn()
+			if ctx.Err() == nil {
+				t.Errorf("the context isn't canceled by its cancel func")
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"bytes"
+	"time"
+)
+
+// High-frequency streams (log lines, events) produce one rpc message per item. A batcher combines the messages
+// written within a short interval into one "databatch" message (an RpcMessage with only DataBatch set) so a
+// connection writes one frame instead of many. The batcher runs per websocket connection (web.HandleWsInternal),
+// not in RpcClient, so it covers the messages of every RpcClient that the router sends to the connection.
+// RpcClient and the routers (Go and frontend) expand a databatch message and process its messages in order.
+
+const DefaultBatchMaxMessages = 256
+const DefaultBatchMaxBytes = 48 * 1024 // stays under the websocket read limit
+const DefaultBatchFlushInterval = 5 * time.Millisecond
+
+type BatchOpts struct {
+	MaxMessages   int           // flush when the batch has this many messages
+	MaxBytes      int           // flush before the batch would exceed this size (a larger message is sent on its own)
+	FlushInterval time.Duration // flush this long after the first message of the batch
+}
+
+func DefaultBatchOpts() BatchOpts {
+	return BatchOpts{
+		MaxMessages:   DefaultBatchMaxMessages,
+		MaxBytes:      DefaultBatchMaxBytes,
+		FlushInterval: DefaultBatchFlushInterval,
+	}
+}
+
+// MakeBatchMessage returns a databatch message containing msgs (each must be a marshaled RpcMessage)
+func MakeBatchMessage(msgs [][]byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"databatch":[`)
+	for idx, msg := range msgs {
+		if idx > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(msg)
+	}
+	buf.WriteString(`]}`)
+	return buf.Bytes()
+}
+
+// RunRpcBatcher reads messages from inputCh and writes them to outputCh combined into databatch messages,
+// a batch of one message is written as is. When inputCh is closed the pending batch is flushed and outputCh
+// is closed. Blocks until then, so it should be run in its own goroutine.
+func RunRpcBatcher(inputCh <-chan []byte, outputCh chan<- []byte, opts BatchOpts) {
+	defer close(outputCh)
+	var pending [][]byte
+	pendingBytes := 0
+	var timer *time.Timer
+	var timerCh <-chan time.Time
+	flush := func() {
+		if timer != nil {
+			timer.Stop()
+			timerCh = nil
+		}
+		if len(pending) == 1 {
+			outputCh <- pending[0]
+		} else if len(pending) > 1 {
+			outputCh <- MakeBatchMessage(pending)
+		}
+		pending = nil
+		pendingBytes = 0
+	}
+	for {
+		select {
+		case msg, ok := <-inputCh:
+			if !ok {
+				flush()
+				return
+			}
+			if len(pending) > 0 && pendingBytes+len(msg) > opts.MaxBytes {
+				flush()
+			}
+			pending = append(pending, msg)
+			pendingBytes += len(msg)
+			if len(pending) >= opts.MaxMessages || pendingBytes >= opts.MaxBytes {
+				flush()
+				continue
+			}
+			if timerCh == nil {
+				if timer == nil {
+					timer = time.NewTimer(opts.FlushInterval)
+				} else {
+					timer.Reset(opts.FlushInterval)
+				}
+				timerCh = timer.C
+			}
+		case <-timerCh:
+			timerCh = nil
+			flush()
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func makeTestBatchMsg(n int) []byte {
+	return []byte(fmt.Sprintf(`{"resid":"req%d","cont":true}`, n))
+}
+
+// getBatchSizes returns the number of messages in each message written by the batcher (0 for an unbatched message)
+func getBatchSizes(t *testing.T, outputCh chan []byte) []int {
+	t.Helper()
+	var sizes []int
+	for msgBytes := range outputCh {
+		var msg RpcMessage
+		if err := json.Unmarshal(msgBytes, &msg); err != nil {
+			t.Fatalf("invalid message %s: %v", msgBytes, err)
+		}
+		sizes = append(sizes, len(msg.DataBatch))
+	}
+	return sizes
+}
+
+func TestMakeBatchMessage(t *testing.T) {
+	batch := MakeBatchMessage([][]byte{makeTestBatchMsg(1), makeTestBatchMsg(2)})
+	var msg RpcMessage
+	if err := json.Unmarshal(batch, &msg); err != nil {
+		t.Fatalf("invalid batch %s: %v", batch, err)
+	}
+	if len(msg.DataBatch) != 2 || string(msg.DataBatch[1]) != string(makeTestBatchMsg(2)) || msg.ResId != "" {
+		t.Errorf("got %+v, want the 2 messages in order", msg)
+	}
+	if got := string(MakeBatchMessage(nil)); got != `{"databatch":[]}` {
+		t.Errorf("got %s for an empty batch", got)
+	}
+}
+
+func TestRunRpcBatcher(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   BatchOpts
+		msgs   int
+		expect []int
+	}{
+		// the interval is long enough that every flush is from the limits (or from closing the input)
+		{"single message is sent as is", BatchOpts{MaxMessages: 10, MaxBytes: 1024, FlushInterval: time.Minute}, 1, []int{0}},
+		{"flushed on close", BatchOpts{MaxMessages: 10, MaxBytes: 1024, FlushInterval: time.Minute}, 3, []int{3}},
+		{"max messages", BatchOpts{MaxMessages: 2, MaxBytes: 1024, FlushInterval: time.Minute}, 5, []int{2, 2, 0}},
+		{"max bytes", BatchOpts{MaxMessages: 10, MaxBytes: 2*len(makeTestBatchMsg(1)) + 1, FlushInterval: time.Minute}, 5, []int{2, 2, 0}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			inputCh := make(chan []byte, tc.msgs)
+			outputCh := make(chan []byte, tc.msgs)
+			for i := 0; i < tc.msgs; i++ {
+				inputCh <- makeTestBatchMsg(i)
+			}
+			close(inputCh)
+			RunRpcBatcher(inputCh, outputCh, tc.opts)
+			got := getBatchSizes(t, outputCh)
+			if fmt.Sprint(got) != fmt.Sprint(tc.expect) {
+				t.Errorf("got batch sizes %v, want %v", got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestRunRpcBatcherLargeMessage(t *testing.T) {
+	inputCh := make(chan []byte, 3)
+	outputCh := make(chan []byte, 3)
+	large := []byte(`{"resid":"large","data":"` + strings.Repeat("x", 100) + `"}`)
+	inputCh <- makeTestBatchMsg(1)
+	inputCh <- large
+	inputCh <- makeTestBatchMsg(2)
+	close(inputCh)
+	RunRpcBatcher(inputCh, outputCh, BatchOpts{MaxMessages: 10, MaxBytes: 64, FlushInterval: time.Minute})
+	var got []string
+	for msg := range outputCh {
+		got = append(got, string(msg))
+	}
+	// the pending message is flushed first, the large message is sent on its own
+	expect := []string{string(makeTestBatchMsg(1)), string(large), string(makeTestBatchMsg(2))}
+	if fmt.Sprint(got) != fmt.Sprint(expect) {
+		t.Errorf("got %v, want %v", got, expect)
+	}
+}
+
+func TestRunRpcBatcherFlushInterval(t *testing.T) {
+	inputCh := make(chan []byte)
+	outputCh := make(chan []byte, 10)
+	go RunRpcBatcher(inputCh, outputCh, BatchOpts{MaxMessages: 10, MaxBytes: 1024, FlushInterval: 100 * time.Millisecond})
+	inputCh <- makeTestBatchMsg(1)
+	inputCh <- makeTestBatchMsg(2)
+	select {
+	case msg := <-outputCh:
+		var batch RpcMessage
+		if err := json.Unmarshal(msg, &batch); err != nil || len(batch.DataBatch) != 2 {
+			t.Errorf("got %s, want a batch of 2 messages", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the batch wasn't flushed after the interval")
+	}
+
+	// the timer restarts with the next message
+	inputCh <- makeTestBatchMsg(3)
+	select {
+	case msg := <-outputCh:
+		if string(msg) != string(makeTestBatchMsg(3)) {
+			t.Errorf("got %s, want the single message", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the second batch wasn't flushed after the interval")
+	}
+	close(inputCh)
+	if _, ok := <-outputCh; ok {
+		t.Errorf("the output channel wasn't closed")
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig/pkg/panichandler"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// MaxBroadcastConcurrency limits how many routes a broadcast command runs on at the same time
+const MaxBroadcastConcurrency = 16
+
+// BroadcastTarget is one route of a broadcast command (Route and Data are what is sent, RouteId is reported in the result)
+type BroadcastTarget struct {
+	RouteId  string
+	AppRunId string // only for app run routes
+	Route    string
+	Data     any
+}
+
+// IsRoutePattern returns true if route contains a "*" or "**" part (see utilfn.StarMatchString)
+func IsRoutePattern(route string) bool {
+	return scopeHasStarMatch(route)
+}
+
+// MatchRoutePattern matches a route id with a route id or pattern ("app:myservice:*")
+func MatchRoutePattern(pattern string, routeId string) bool {
+	if !IsRoutePattern(pattern) {
+		return pattern == routeId
+	}
+	return utilfn.StarMatchString(pattern, routeId, ":")
+}
+
+// GetMatchingRouteIds returns the (sorted) registered and announced route ids matching pattern
+func (router *WshRouter) GetMatchingRouteIds(pattern string) []string {
+	router.Lock.Lock()
+	defer router.Lock.Unlock()
+	var rtn []string
+	for routeId := range router.RouteMap {
+		if MatchRoutePattern(pattern, routeId) {
+			rtn = append(rtn, routeId)
+		}
+	}
+	for routeId := range router.AnnouncedRoutes {
+		if router.RouteMap[routeId] == nil && MatchRoutePattern(pattern, routeId) {
+			rtn = append(rtn, routeId)
+		}
+	}
+	sort.Strings(rtn)
+	return rtn
+}
+
+// RunBroadcastCommand runs command on all targets concurrently and returns the per-route results
+// (sorted by route id). A failure on one route doesn't stop the others, it is reported in its result.
+func (router *WshRouter) RunBroadcastCommand(ctx context.Context, command string, targets []BroadcastTarget, source string) []rpctypes.BroadcastRouteResult {
+	results := make([]rpctypes.BroadcastRouteResult, len(targets))
+	sem := make(chan struct{}, MaxBroadcastConcurrency)
+	var wg sync.WaitGroup
+	for idx, target := range targets {
+		results[idx] = rpctypes.BroadcastRouteResult{Route: target.RouteId, AppRunId: target.AppRunId}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				panichandler.PanicHandler("WshRouter:RunBroadcastCommand", recover())
+			}()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[idx].Error = ctx.Err().Error()
+				return
+			}
+			msg := RpcMessage{
+				Command: command,
+				ReqId:   uuid.New().String(),
+				Source:  source,
+				Route:   target.Route,
+				Data:    target.Data,
+			}
+			if deadline, ok := ctx.Deadline(); ok {
+				msg.Timeout = max(int64(1), time.Until(deadline).Milliseconds())
+			}
+			resp, err := router.RunSimpleRawCommand(ctx, msg, source)
+			if err != nil {
+				results[idx].Error = err.Error()
+				return
+			}
+			if resp != nil {
+				results[idx].Data = resp.Data
+			}
+		}()
+	}
+	wg.Wait()
+	sort.SliceStable(results, func(i, j int) bool {
+		return strings.Compare(results[i].Route, results[j].Route) < 0
+	})
+	return results
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"log"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -30,6 +31,9 @@ const (
 	RoutePrefix_Proc       = "proc:"
 	RoutePrefix_Tab        = "tab:"
 	RoutePrefix_FeBlock    = "feblock:"
+	RoutePrefix_AppRun     = "app:"     // virtual routes for app runs, handled by the server (see BroadcastCommand)
+	RoutePrefix_Monitor    = "monitor:" // downstream monitors (see the federation package)
+	RoutePrefix_Hub        = "hub:"     // websocket connections from a hub monitor (see the federation package)
 )
 
 // this works like a network switch
@@ -55,6 +59,10 @@ type WshRouter struct {
 	RpcMap           map[string]*routeInfo        // rpcid => routeinfo
 	SimpleRequestMap map[string]chan *RpcMessage  // simple reqid => response channel
 	InputCh          chan msgAndRoute
+
+	// RouteResolver (optional) can redirect a command sent to the DefaultRoute to another route,
+	// it returns "" to keep the DefaultRoute
+	RouteResolver func(msg *RpcMessage) string
 }
 
 func MakeConnectionRouteId(connId string) string {
@@ -77,6 +85,16 @@ func MakeFeBlockRouteId(blockId string) string {
 	return "feblock:" + blockId
 }
 
+func MakeMonitorRouteId(monitorName string) string {
+	return "monitor:" + monitorName
+}
+
+// MakeAppRunRouteId returns the route id of an app run ("app:<appname>:<apprunid>"),
+// ":" in the app name is replaced so "app:<appname>:*" patterns match all runs of an app
+func MakeAppRunRouteId(appName string, appRunId string) string {
+	return "app:" + strings.ReplaceAll(appName, ":", "_") + ":" + appRunId
+}
+
 var (
 	defaultRouter     *WshRouter
 	defaultRouterOnce sync.Once
@@ -112,6 +130,26 @@ func (router *WshRouter) GetRouteKeys() []string {
 	return keys
 }
 
+// RouterStats is a snapshot of the router's state (used by the status API)
+type RouterStats struct {
+	NumRoutes      int `json:"numroutes"`
+	NumPendingRpcs int `json:"numpendingrpcs"` // rpcs that haven't received their final response
+	InputQueueLen  int `json:"inputqueuelen"`
+	InputQueueCap  int `json:"inputqueuecap"`
+}
+
+// GetStats returns the number of routes, pending rpcs, and the length of the input queue
+func (router *WshRouter) GetStats() RouterStats {
+	router.Lock.Lock()
+	defer router.Lock.Unlock()
+	return RouterStats{
+		NumRoutes:      len(router.RouteMap),
+		NumPendingRpcs: len(router.RpcMap),
+		InputQueueLen:  len(router.InputCh),
+		InputQueueCap:  cap(router.InputCh),
+	}
+}
+
 func NewWshRouter() *WshRouter {
 	rtn := &WshRouter{
 		Lock:             &sync.Mutex{},
@@ -275,6 +313,14 @@ func (router *WshRouter) runServer() {
 			continue
 		}
 		if msg.Command != "" {
+			if routeId == DefaultRoute {
+				if resolver := router.GetRouteResolver(); resolver != nil {
+					if resolvedRouteId := resolver(&msg); resolvedRouteId != "" {
+						routeId = resolvedRouteId
+						msg.Route = resolvedRouteId // for the no route error
+					}
+				}
+			}
 			// new comand, setup new rpc
 			ok := router.sendRoutedMessage(msgBytes, routeId, msg.Command)
 			if !ok {
@@ -371,28 +417,39 @@ func (router *WshRouter) RegisterRoute(routeId string, rpc AbstractRpcClient, sh
 			if !ok {
 				break
 			}
-			var rpcMsg RpcMessage
-			err := json.Unmarshal(msgBytes, &rpcMsg)
-			if err != nil {
-				continue
-			}
-			if rpcMsg.Command != "" {
-				if rpcMsg.Source == "" {
-					rpcMsg.Source = routeId
-				}
-				if rpcMsg.Route == "" {
-					rpcMsg.Route = DefaultRoute
-				}
-				msgBytes, err = json.Marshal(rpcMsg)
-				if err != nil {
-					continue
-				}
-			}
-			router.InputCh <- msgAndRoute{msgBytes: msgBytes, fromRouteId: routeId}
+			router.recvRouteMessage(msgBytes, routeId)
 		}
 	}()
 }
 
+// recvRouteMessage queues a message received from routeId (a databatch message is expanded, its messages are routed separately)
+func (router *WshRouter) recvRouteMessage(msgBytes []byte, routeId string) {
+	var rpcMsg RpcMessage
+	err := json.Unmarshal(msgBytes, &rpcMsg)
+	if err != nil {
+		return
+	}
+	if rpcMsg.DataBatch != nil {
+		for _, batchMsgBytes := range rpcMsg.DataBatch {
+			router.recvRouteMessage(batchMsgBytes, routeId)
+		}
+		return
+	}
+	if rpcMsg.Command != "" {
+		if rpcMsg.Source == "" {
+			rpcMsg.Source = routeId
+		}
+		if rpcMsg.Route == "" {
+			rpcMsg.Route = DefaultRoute
+		}
+		msgBytes, err = json.Marshal(rpcMsg)
+		if err != nil {
+			return
+		}
+	}
+	router.InputCh <- msgAndRoute{msgBytes: msgBytes, fromRouteId: routeId}
+}
+
 func (router *WshRouter) UnregisterRoute(routeId string) {
 	// log.Printf("[router] unregistering wsh route %q\n", routeId)
 	router.Lock.Lock()
@@ -433,6 +490,18 @@ func (router *WshRouter) GetUpstreamClient() AbstractRpcClient {
 	return router.UpstreamClient
 }
 
+func (router *WshRouter) SetRouteResolver(resolver func(msg *RpcMessage) string) {
+	router.Lock.Lock()
+	defer router.Lock.Unlock()
+	router.RouteResolver = resolver
+}
+
+func (router *WshRouter) GetRouteResolver() func(msg *RpcMessage) string {
+	router.Lock.Lock()
+	defer router.Lock.Unlock()
+	return router.RouteResolver
+}
+
 func (router *WshRouter) InjectMessage(msgBytes []byte, fromRouteId string) {
 	router.InputCh <- msgAndRoute{msgBytes: msgBytes, fromRouteId: fromRouteId}
 }
@@ -10,12 +10,60 @@ import (
 	"github.com/outrigdev/outrig/server/pkg/rpctypes"
 )
 
+// command "broadcast", rpctypes.BroadcastCommand
+func BroadcastCommand(w *rpc.RpcClient, data rpctypes.BroadcastRequest, opts *rpc.RpcOpts) (rpctypes.BroadcastResponse, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.BroadcastResponse](w, "broadcast", data, opts)
+	return resp, err
+}
+
+// command "capturecpuprofile", rpctypes.CaptureCPUProfileCommand
+func CaptureCPUProfileCommand(w *rpc.RpcClient, data rpctypes.CaptureCPUProfileRequest, opts *rpc.RpcOpts) (rpctypes.CaptureCPUProfileResponse, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.CaptureCPUProfileResponse](w, "capturecpuprofile", data, opts)
+	return resp, err
+}
+
+// command "captureexectrace", rpctypes.CaptureExecTraceCommand
+func CaptureExecTraceCommand(w *rpc.RpcClient, data rpctypes.CaptureExecTraceRequest, opts *rpc.RpcOpts) (rpctypes.ExecTraceInfo, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.ExecTraceInfo](w, "captureexectrace", data, opts)
+	return resp, err
+}
+
 // command "clearnonactiveappruns", rpctypes.ClearNonActiveAppRunsCommand
 func ClearNonActiveAppRunsCommand(w *rpc.RpcClient, opts *rpc.RpcOpts) error {
 	_, err := SendRpcRequestCallHelper[any](w, "clearnonactiveappruns", nil, opts)
 	return err
 }
 
+// command "collectoradmin", rpctypes.CollectorAdminCommand
+func CollectorAdminCommand(w *rpc.RpcClient, data rpctypes.CollectorAdminRequest, opts *rpc.RpcOpts) error {
+	_, err := SendRpcRequestCallHelper[any](w, "collectoradmin", data, opts)
+	return err
+}
+
+// command "compactapprunstore", rpctypes.CompactAppRunStoreCommand
+func CompactAppRunStoreCommand(w *rpc.RpcClient, data rpctypes.CompactAppRunStoreRequest, opts *rpc.RpcOpts) (rpctypes.CompactAppRunStoreResult, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.CompactAppRunStoreResult](w, "compactapprunstore", data, opts)
+	return resp, err
+}
+
+// command "compareappruns", rpctypes.CompareAppRunsCommand
+func CompareAppRunsCommand(w *rpc.RpcClient, data rpctypes.CompareAppRunsRequest, opts *rpc.RpcOpts) (rpctypes.CompareAppRunsData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.CompareAppRunsData](w, "compareappruns", data, opts)
+	return resp, err
+}
+
+// command "deletesnapshot", rpctypes.DeleteSnapshotCommand
+func DeleteSnapshotCommand(w *rpc.RpcClient, data rpctypes.SnapshotRequest, opts *rpc.RpcOpts) error {
+	_, err := SendRpcRequestCallHelper[any](w, "deletesnapshot", data, opts)
+	return err
+}
+
+// command "deletesnapshotrule", rpctypes.DeleteSnapshotRuleCommand
+func DeleteSnapshotRuleCommand(w *rpc.RpcClient, data rpctypes.SnapshotRuleRequest, opts *rpc.RpcOpts) error {
+	_, err := SendRpcRequestCallHelper[any](w, "deletesnapshotrule", data, opts)
+	return err
+}
+
 // command "eventpublish", rpctypes.EventPublishCommand
 func EventPublishCommand(w *rpc.RpcClient, data rpctypes.EventType, opts *rpc.RpcOpts) error {
 	_, err := SendRpcRequestCallHelper[any](w, "eventpublish", data, opts)
@@ -46,12 +94,48 @@ func EventUnsubAllCommand(w *rpc.RpcClient, opts *rpc.RpcOpts) error {
 	return err
 }
 
+// command "exportapprun", rpctypes.ExportAppRunCommand
+func ExportAppRunCommand(w *rpc.RpcClient, data rpctypes.AppRunRequest, opts *rpc.RpcOpts) (rpctypes.ExportAppRunResponse, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.ExportAppRunResponse](w, "exportapprun", data, opts)
+	return resp, err
+}
+
+// command "forcegoroutinedump", rpctypes.ForceGoroutineDumpCommand
+func ForceGoroutineDumpCommand(w *rpc.RpcClient, data rpctypes.ForceGoroutineDumpRequest, opts *rpc.RpcOpts) (rpctypes.GoroutineDumpInfo, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.GoroutineDumpInfo](w, "forcegoroutinedump", data, opts)
+	return resp, err
+}
+
+// command "getapprunexectraces", rpctypes.GetAppRunExecTracesCommand
+func GetAppRunExecTracesCommand(w *rpc.RpcClient, data rpctypes.AppRunRequest, opts *rpc.RpcOpts) (rpctypes.AppRunExecTracesData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.AppRunExecTracesData](w, "getapprunexectraces", data, opts)
+	return resp, err
+}
+
+// command "getapprungoroutinedumps", rpctypes.GetAppRunGoroutineDumpsCommand
+func GetAppRunGoroutineDumpsCommand(w *rpc.RpcClient, data rpctypes.AppRunRequest, opts *rpc.RpcOpts) (rpctypes.AppRunGoroutineDumpsData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.AppRunGoroutineDumpsData](w, "getapprungoroutinedumps", data, opts)
+	return resp, err
+}
+
 // command "getapprungoroutinesbyids", rpctypes.GetAppRunGoRoutinesByIdsCommand
 func GetAppRunGoRoutinesByIdsCommand(w *rpc.RpcClient, data rpctypes.AppRunGoRoutinesByIdsRequest, opts *rpc.RpcOpts) (rpctypes.AppRunGoRoutinesData, error) {
 	resp, err := SendRpcRequestCallHelper[rpctypes.AppRunGoRoutinesData](w, "getapprungoroutinesbyids", data, opts)
 	return resp, err
 }
 
+// command "getapprunpanics", rpctypes.GetAppRunPanicsCommand
+func GetAppRunPanicsCommand(w *rpc.RpcClient, data rpctypes.AppRunRequest, opts *rpc.RpcOpts) (rpctypes.AppRunPanicsData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.AppRunPanicsData](w, "getapprunpanics", data, opts)
+	return resp, err
+}
+
+// command "getapprunpresence", rpctypes.GetAppRunPresenceCommand
+func GetAppRunPresenceCommand(w *rpc.RpcClient, data rpctypes.AppRunRequest, opts *rpc.RpcOpts) (rpctypes.AppRunPresenceData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.AppRunPresenceData](w, "getapprunpresence", data, opts)
+	return resp, err
+}
+
 // command "getapprunruntimestats", rpctypes.GetAppRunRuntimeStatsCommand
 func GetAppRunRuntimeStatsCommand(w *rpc.RpcClient, data rpctypes.AppRunRequest, opts *rpc.RpcOpts) (rpctypes.AppRunRuntimeStatsData, error) {
 	resp, err := SendRpcRequestCallHelper[rpctypes.AppRunRuntimeStatsData](w, "getapprunruntimestats", data, opts)
@@ -64,24 +148,102 @@ func GetAppRunsCommand(w *rpc.RpcClient, data rpctypes.AppRunUpdatesRequest, opt
 	return resp, err
 }
 
+// command "getappruntimeline", rpctypes.GetAppRunTimelineCommand
+func GetAppRunTimelineCommand(w *rpc.RpcClient, data rpctypes.AppRunRequest, opts *rpc.RpcOpts) (rpctypes.AppRunTimelineData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.AppRunTimelineData](w, "getappruntimeline", data, opts)
+	return resp, err
+}
+
 // command "getapprunwatchesbyids", rpctypes.GetAppRunWatchesByIdsCommand
 func GetAppRunWatchesByIdsCommand(w *rpc.RpcClient, data rpctypes.AppRunWatchesByIdsRequest, opts *rpc.RpcOpts) (rpctypes.AppRunWatchesData, error) {
 	resp, err := SendRpcRequestCallHelper[rpctypes.AppRunWatchesData](w, "getapprunwatchesbyids", data, opts)
 	return resp, err
 }
 
+// command "getauditlog", rpctypes.GetAuditLogCommand
+func GetAuditLogCommand(w *rpc.RpcClient, data rpctypes.AuditLogRequest, opts *rpc.RpcOpts) (rpctypes.AuditLogData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.AuditLogData](w, "getauditlog", data, opts)
+	return resp, err
+}
+
 // command "getdemoappstatus", rpctypes.GetDemoAppStatusCommand
 func GetDemoAppStatusCommand(w *rpc.RpcClient, opts *rpc.RpcOpts) (string, error) {
 	resp, err := SendRpcRequestCallHelper[string](w, "getdemoappstatus", nil, opts)
 	return resp, err
 }
 
+// command "getdiskusage", rpctypes.GetDiskUsageCommand
+func GetDiskUsageCommand(w *rpc.RpcClient, opts *rpc.RpcOpts) (rpctypes.DiskUsageData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.DiskUsageData](w, "getdiskusage", nil, opts)
+	return resp, err
+}
+
+// command "geteffectiveconfig", rpctypes.GetEffectiveConfigCommand
+func GetEffectiveConfigCommand(w *rpc.RpcClient, data rpctypes.AppRunRequest, opts *rpc.RpcOpts) (rpctypes.EffectiveConfigData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.EffectiveConfigData](w, "geteffectiveconfig", data, opts)
+	return resp, err
+}
+
+// command "getgoroutinechurn", rpctypes.GetGoRoutineChurnCommand
+func GetGoRoutineChurnCommand(w *rpc.RpcClient, data rpctypes.AppRunRequest, opts *rpc.RpcOpts) (rpctypes.GoRoutineChurnData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.GoRoutineChurnData](w, "getgoroutinechurn", data, opts)
+	return resp, err
+}
+
+// command "getgoroutinelogs", rpctypes.GetGoRoutineLogsCommand
+func GetGoRoutineLogsCommand(w *rpc.RpcClient, data rpctypes.GoRoutineLogsRequest, opts *rpc.RpcOpts) (rpctypes.GoRoutineLogsData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.GoRoutineLogsData](w, "getgoroutinelogs", data, opts)
+	return resp, err
+}
+
+// command "getsdkoverhead", rpctypes.GetSDKOverheadCommand
+func GetSDKOverheadCommand(w *rpc.RpcClient, data rpctypes.AppRunRequest, opts *rpc.RpcOpts) (rpctypes.SDKOverheadData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.SDKOverheadData](w, "getsdkoverhead", data, opts)
+	return resp, err
+}
+
+// command "getsessiontimeline", rpctypes.GetSessionTimelineCommand
+func GetSessionTimelineCommand(w *rpc.RpcClient, data rpctypes.SessionTimelineRequest, opts *rpc.RpcOpts) (rpctypes.SessionTimelineData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.SessionTimelineData](w, "getsessiontimeline", data, opts)
+	return resp, err
+}
+
+// command "getsnapshotrules", rpctypes.GetSnapshotRulesCommand
+func GetSnapshotRulesCommand(w *rpc.RpcClient, opts *rpc.RpcOpts) (rpctypes.SnapshotRulesData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.SnapshotRulesData](w, "getsnapshotrules", nil, opts)
+	return resp, err
+}
+
+// command "getsnapshots", rpctypes.GetSnapshotsCommand
+func GetSnapshotsCommand(w *rpc.RpcClient, data rpctypes.SnapshotsRequest, opts *rpc.RpcOpts) (rpctypes.SnapshotsData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.SnapshotsData](w, "getsnapshots", data, opts)
+	return resp, err
+}
+
+// command "getsourcepreview", rpctypes.GetSourcePreviewCommand
+func GetSourcePreviewCommand(w *rpc.RpcClient, data rpctypes.SourcePreviewRequest, opts *rpc.RpcOpts) (rpctypes.SourcePreviewData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.SourcePreviewData](w, "getsourcepreview", data, opts)
+	return resp, err
+}
+
+// command "globalsearchrequest", rpctypes.GlobalSearchRequestCommand
+func GlobalSearchRequestCommand(w *rpc.RpcClient, data rpctypes.GlobalSearchRequest, opts *rpc.RpcOpts) (rpctypes.GlobalSearchResultData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.GlobalSearchResultData](w, "globalsearchrequest", data, opts)
+	return resp, err
+}
+
 // command "goroutinesearchrequest", rpctypes.GoRoutineSearchRequestCommand
 func GoRoutineSearchRequestCommand(w *rpc.RpcClient, data rpctypes.GoRoutineSearchRequestData, opts *rpc.RpcOpts) (rpctypes.GoRoutineSearchResultData, error) {
 	resp, err := SendRpcRequestCallHelper[rpctypes.GoRoutineSearchResultData](w, "goroutinesearchrequest", data, opts)
 	return resp, err
 }
 
+// command "goroutinestatehistogram", rpctypes.GoRoutineStateHistogramCommand
+func GoRoutineStateHistogramCommand(w *rpc.RpcClient, data rpctypes.GoRoutineStateHistogramRequest, opts *rpc.RpcOpts) (rpctypes.GoRoutineStateHistogramResponse, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.GoRoutineStateHistogramResponse](w, "goroutinestatehistogram", data, opts)
+	return resp, err
+}
+
 // command "goroutinetimespans", rpctypes.GoRoutineTimeSpansCommand
 func GoRoutineTimeSpansCommand(w *rpc.RpcClient, data rpctypes.GoRoutineTimeSpansRequest, opts *rpc.RpcOpts) (rpctypes.GoRoutineTimeSpansResponse, error) {
 	resp, err := SendRpcRequestCallHelper[rpctypes.GoRoutineTimeSpansResponse](w, "goroutinetimespans", data, opts)
@@ -100,12 +262,24 @@ func LaunchDemoAppCommand(w *rpc.RpcClient, opts *rpc.RpcOpts) error {
 	return err
 }
 
+// command "logexportmarkedlines", rpctypes.LogExportMarkedLinesCommand
+func LogExportMarkedLinesCommand(w *rpc.RpcClient, data rpctypes.MarkedLinesRequestData, opts *rpc.RpcOpts) (rpctypes.MarkedLinesExportData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.MarkedLinesExportData](w, "logexportmarkedlines", data, opts)
+	return resp, err
+}
+
 // command "loggetmarkedlines", rpctypes.LogGetMarkedLinesCommand
 func LogGetMarkedLinesCommand(w *rpc.RpcClient, data rpctypes.MarkedLinesRequestData, opts *rpc.RpcOpts) (rpctypes.MarkedLinesResultData, error) {
 	resp, err := SendRpcRequestCallHelper[rpctypes.MarkedLinesResultData](w, "loggetmarkedlines", data, opts)
 	return resp, err
 }
 
+// command "logsearchrange", rpctypes.LogSearchRangeCommand
+func LogSearchRangeCommand(w *rpc.RpcClient, data rpctypes.LogSearchRangeRequest, opts *rpc.RpcOpts) (rpctypes.LogSearchRangeResultData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.LogSearchRangeResultData](w, "logsearchrange", data, opts)
+	return resp, err
+}
+
 // command "logsearchrequest", rpctypes.LogSearchRequestCommand
 func LogSearchRequestCommand(w *rpc.RpcClient, data rpctypes.SearchRequestData, opts *rpc.RpcOpts) (rpctypes.SearchResultData, error) {
 	resp, err := SendRpcRequestCallHelper[rpctypes.SearchResultData](w, "logsearchrequest", data, opts)
@@ -118,6 +292,11 @@ func LogStreamUpdateCommand(w *rpc.RpcClient, data rpctypes.StreamUpdateData, op
 	return err
 }
 
+// command "logtail", rpctypes.LogTailCommand
+func LogTailCommand(w *rpc.RpcClient, data rpctypes.LogTailRequest, opts *rpc.RpcOpts) chan rpctypes.RespUnion[rpctypes.LogTailData] {
+	return SendRpcRequestResponseStreamHelper[rpctypes.LogTailData](w, "logtail", data, opts)
+}
+
 // command "logupdatemarkedlines", rpctypes.LogUpdateMarkedLinesCommand
 func LogUpdateMarkedLinesCommand(w *rpc.RpcClient, data rpctypes.MarkedLinesData, opts *rpc.RpcOpts) error {
 	_, err := SendRpcRequestCallHelper[any](w, "logupdatemarkedlines", data, opts)
@@ -136,18 +315,59 @@ func MessageCommand(w *rpc.RpcClient, data rpctypes.CommandMessageData, opts *rp
 	return err
 }
 
+// command "pruneappruns", rpctypes.PruneAppRunsCommand
+func PruneAppRunsCommand(w *rpc.RpcClient, data rpctypes.PruneAppRunsRequest, opts *rpc.RpcOpts) (rpctypes.PruneAppRunsResult, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.PruneAppRunsResult](w, "pruneappruns", data, opts)
+	return resp, err
+}
+
+// command "recordsessionevent", rpctypes.RecordSessionEventCommand
+func RecordSessionEventCommand(w *rpc.RpcClient, data rpctypes.SessionEvent, opts *rpc.RpcOpts) error {
+	_, err := SendRpcRequestCallHelper[any](w, "recordsessionevent", data, opts)
+	return err
+}
+
+// command "runsnapshotrule", rpctypes.RunSnapshotRuleCommand
+func RunSnapshotRuleCommand(w *rpc.RpcClient, data rpctypes.SnapshotRuleRequest, opts *rpc.RpcOpts) (rpctypes.SnapshotsData, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.SnapshotsData](w, "runsnapshotrule", data, opts)
+	return resp, err
+}
+
+// command "runtimecontrol", rpctypes.RuntimeControlCommand
+func RuntimeControlCommand(w *rpc.RpcClient, data rpctypes.RuntimeControlRequest, opts *rpc.RpcOpts) (rpctypes.RuntimeControlResponse, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.RuntimeControlResponse](w, "runtimecontrol", data, opts)
+	return resp, err
+}
+
 // command "sendteventfe", rpctypes.SendTEventFeCommand
 func SendTEventFeCommand(w *rpc.RpcClient, data rpctypes.TEventFeData, opts *rpc.RpcOpts) error {
 	_, err := SendRpcRequestCallHelper[any](w, "sendteventfe", data, opts)
 	return err
 }
 
+// command "setwatchvalue", rpctypes.SetWatchValueCommand
+func SetWatchValueCommand(w *rpc.RpcClient, data rpctypes.SetWatchValueRequest, opts *rpc.RpcOpts) (rpctypes.SetWatchValueResponse, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.SetWatchValueResponse](w, "setwatchvalue", data, opts)
+	return resp, err
+}
+
+// command "subscriberuntimestats", rpctypes.SubscribeRuntimeStatsCommand
+func SubscribeRuntimeStatsCommand(w *rpc.RpcClient, data rpctypes.AppRunRequest, opts *rpc.RpcOpts) chan rpctypes.RespUnion[rpctypes.AppRunRuntimeStatsData] {
+	return SendRpcRequestResponseStreamHelper[rpctypes.AppRunRuntimeStatsData](w, "subscriberuntimestats", data, opts)
+}
+
 // command "triggertrayupdate", rpctypes.TriggerTrayUpdateCommand
 func TriggerTrayUpdateCommand(w *rpc.RpcClient, opts *rpc.RpcOpts) error {
 	_, err := SendRpcRequestCallHelper[any](w, "triggertrayupdate", nil, opts)
 	return err
 }
 
+// command "updatebrowsertabfocus", rpctypes.UpdateBrowserTabFocusCommand
+func UpdateBrowserTabFocusCommand(w *rpc.RpcClient, data rpctypes.BrowserTabFocusData, opts *rpc.RpcOpts) error {
+	_, err := SendRpcRequestCallHelper[any](w, "updatebrowsertabfocus", data, opts)
+	return err
+}
+
 // command "updatebrowsertaburl", rpctypes.UpdateBrowserTabUrlCommand
 func UpdateBrowserTabUrlCommand(w *rpc.RpcClient, data rpctypes.BrowserTabUrlData, opts *rpc.RpcOpts) error {
 	_, err := SendRpcRequestCallHelper[any](w, "updatebrowsertaburl", data, opts)
@@ -160,6 +380,12 @@ func UpdateCheckCommand(w *rpc.RpcClient, opts *rpc.RpcOpts) (rpctypes.UpdateChe
 	return resp, err
 }
 
+// command "updatesnapshotrule", rpctypes.UpdateSnapshotRuleCommand
+func UpdateSnapshotRuleCommand(w *rpc.RpcClient, data rpctypes.SnapshotRule, opts *rpc.RpcOpts) (rpctypes.SnapshotRule, error) {
+	resp, err := SendRpcRequestCallHelper[rpctypes.SnapshotRule](w, "updatesnapshotrule", data, opts)
+	return resp, err
+}
+
 // command "updatestatus", rpctypes.UpdateStatusCommand
 func UpdateStatusCommand(w *rpc.RpcClient, data rpctypes.StatusUpdateData, opts *rpc.RpcOpts) error {
 	_, err := SendRpcRequestCallHelper[any](w, "updatestatus", data, opts)
@@ -5,23 +5,42 @@ package rpcserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/outrigdev/outrig/pkg/ds"
 	"github.com/outrigdev/outrig/server/pkg/apppeer"
+	"github.com/outrigdev/outrig/server/pkg/auditlog"
 	"github.com/outrigdev/outrig/server/pkg/browsertabs"
 	"github.com/outrigdev/outrig/server/pkg/democontroller"
+	"github.com/outrigdev/outrig/server/pkg/federation"
 	"github.com/outrigdev/outrig/server/pkg/gensearch"
+	"github.com/outrigdev/outrig/server/pkg/logtail"
 	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpcclient"
 	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverconfig"
+	"github.com/outrigdev/outrig/server/pkg/snapshots"
 	"github.com/outrigdev/outrig/server/pkg/tevent"
 	"github.com/outrigdev/outrig/server/pkg/updatecheck"
 )
 
 const (
-	MaxGoRoutineSearchResults = 1000 // Maximum number of goroutines to return from a search
+	MaxGoRoutineSearchResults    = 1000 // Maximum number of goroutines to return from a search
+	DefaultGoRoutineLogLines     = 1000
+	DefaultCPUProfileDurationSec = 10
+	DefaultExecTraceDurationSec  = 5
+
+	// time left at the end of a search request's timeout to send back the partial results
+	SearchResponseMargin = 500 * time.Millisecond
+	// time (on top of the capture duration) the app has to send a cpu profile or trace back
+	CaptureResponseMargin = 15 * time.Second
 )
 
 type RpcServerImpl struct{}
@@ -74,9 +93,14 @@ func (*RpcServerImpl) EventReadHistoryCommand(ctx context.Context, data rpctypes
 
 // GetAppRunsCommand returns a list of app runs
 // If since > 0, only returns app runs that have been updated since the given timestamp
+// If meta is set, only returns app runs with matching metadata
 func (*RpcServerImpl) GetAppRunsCommand(ctx context.Context, data rpctypes.AppRunUpdatesRequest) (rpctypes.AppRunsData, error) {
 	// Get app run infos directly from the apppeer package
 	appRuns := apppeer.GetAllAppRunPeerInfos(data.Since)
+	appRuns = append(appRuns, federation.GetAppRunInfos(data.Since)...)
+	if len(data.Meta) > 0 {
+		appRuns = apppeer.FilterAppRunInfosByMeta(appRuns, data.Meta)
+	}
 
 	return rpctypes.AppRunsData{
 		AppRuns: appRuns,
@@ -86,19 +110,13 @@ func (*RpcServerImpl) GetAppRunsCommand(ctx context.Context, data rpctypes.AppRu
 // GetAppRunGoRoutinesByIdsCommand returns specific goroutines by their IDs for a specific app run
 func (*RpcServerImpl) GetAppRunGoRoutinesByIdsCommand(ctx context.Context, data rpctypes.AppRunGoRoutinesByIdsRequest) (rpctypes.AppRunGoRoutinesData, error) {
 	// Get the app run peer
-	peer := apppeer.GetAppRunPeer(data.AppRunId, false)
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
 	if peer == nil || peer.AppInfo == nil {
 		return rpctypes.AppRunGoRoutinesData{}, fmt.Errorf("app run not found: %s", data.AppRunId)
 	}
 
-	// Get module name from AppInfo
-	moduleName := ""
-	if peer.AppInfo != nil {
-		moduleName = peer.AppInfo.ModuleName
-	}
-
 	// Get parsed goroutines by IDs from the GoRoutinePeer
-	parsedGoRoutines := peer.GoRoutines.GetParsedGoRoutinesByIds(moduleName, data.GoIds, data.Timestamp)
+	parsedGoRoutines := peer.GoRoutines.GetParsedGoRoutinesByIds(peer.GetModuleInfo(), data.GoIds, data.Timestamp)
 
 	return rpctypes.AppRunGoRoutinesData{
 		AppRunId:   peer.AppRunId,
@@ -107,6 +125,43 @@ func (*RpcServerImpl) GetAppRunGoRoutinesByIdsCommand(ctx context.Context, data
 	}, nil
 }
 
+// GetGoRoutineLogsCommand returns the log lines logged by a goroutine (joins the goroutine view to the logs)
+func (*RpcServerImpl) GetGoRoutineLogsCommand(ctx context.Context, data rpctypes.GoRoutineLogsRequest) (rpctypes.GoRoutineLogsData, error) {
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return rpctypes.GoRoutineLogsData{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	if data.GoId <= 0 {
+		return rpctypes.GoRoutineLogsData{}, fmt.Errorf("invalid goroutine id: %d", data.GoId)
+	}
+	maxLines := data.MaxLines
+	if maxLines <= 0 {
+		maxLines = DefaultGoRoutineLogLines
+	}
+	lines, totalCount := peer.Logs.GetGoRoutineLogLines(data.GoId, maxLines)
+	return rpctypes.GoRoutineLogsData{
+		AppRunId:   peer.AppRunId,
+		GoId:       data.GoId,
+		Lines:      lines,
+		TotalCount: totalCount,
+		SearchTerm: fmt.Sprintf("$goid:/^%d$/", data.GoId),
+	}, nil
+}
+
+// GetGoRoutineChurnCommand returns the goroutine churn (goroutines started and finished between goroutine polls)
+// of an app run by call site
+func (*RpcServerImpl) GetGoRoutineChurnCommand(ctx context.Context, data rpctypes.AppRunRequest) (rpctypes.GoRoutineChurnData, error) {
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return rpctypes.GoRoutineChurnData{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	return rpctypes.GoRoutineChurnData{
+		AppRunId: peer.AppRunId,
+		AppName:  peer.AppInfo.AppName,
+		Sites:    peer.GetGoRoutineChurnSites(),
+	}, nil
+}
+
 // GetAppRunWatchesByIdsCommand returns specific watches by their IDs for a specific app run
 func (*RpcServerImpl) GetAppRunWatchesByIdsCommand(ctx context.Context, data rpctypes.AppRunWatchesByIdsRequest) (rpctypes.AppRunWatchesData, error) {
 	// Get the app run peer
@@ -128,25 +183,437 @@ func (*RpcServerImpl) GetAppRunWatchesByIdsCommand(ctx context.Context, data rpc
 // GetAppRunRuntimeStatsCommand returns runtime stats for a specific app run
 func (*RpcServerImpl) GetAppRunRuntimeStatsCommand(ctx context.Context, data rpctypes.AppRunRequest) (rpctypes.AppRunRuntimeStatsData, error) {
 	// Get the app run peer
-	peer := apppeer.GetAppRunPeer(data.AppRunId, false)
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
 	if peer == nil || peer.AppInfo == nil {
 		return rpctypes.AppRunRuntimeStatsData{}, fmt.Errorf("app run not found: %s", data.AppRunId)
 	}
 
-	// Get goroutine counts
-	numGoRoutines, numActiveGoRoutines, numOutrigGoRoutines := peer.GoRoutines.GetGoRoutineCounts()
+	return peer.GetRuntimeStatsData(data.Since), nil
+}
+
+// SubscribeRuntimeStatsCommand streams the runtime stats of an app run as they arrive (stats newer than data.Since first)
+func (*RpcServerImpl) SubscribeRuntimeStatsCommand(ctx context.Context, data rpctypes.AppRunRequest) chan rpctypes.RespUnion[rpctypes.AppRunRuntimeStatsData] {
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		rtnCh := make(chan rpctypes.RespUnion[rpctypes.AppRunRuntimeStatsData], 1)
+		rpcclient.RtnStreamErr(rtnCh, fmt.Errorf("app run not found: %s", data.AppRunId))
+		return rtnCh
+	}
+	return peer.SubscribeRuntimeStats(ctx, data.Since)
+}
+
+// GetAppRunPanicsCommand returns the panics captured for a specific app run
+func (*RpcServerImpl) GetAppRunPanicsCommand(ctx context.Context, data rpctypes.AppRunRequest) (rpctypes.AppRunPanicsData, error) {
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return rpctypes.AppRunPanicsData{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+
+	return rpctypes.AppRunPanicsData{
+		AppRunId: peer.AppRunId,
+		AppName:  peer.AppInfo.AppName,
+		Panics:   peer.Panics.GetPanics(data.Since, peer.GetModuleInfo()),
+	}, nil
+}
+
+// GetAppRunTimelineCommand returns the lifecycle timeline (connects, disconnects, crashes) for a specific app run
+func (*RpcServerImpl) GetAppRunTimelineCommand(ctx context.Context, data rpctypes.AppRunRequest) (rpctypes.AppRunTimelineData, error) {
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return rpctypes.AppRunTimelineData{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+
+	return rpctypes.AppRunTimelineData{
+		AppRunId: peer.AppRunId,
+		AppName:  peer.AppInfo.AppName,
+		Events:   peer.Lifecycle.GetEvents(data.Since),
+	}, nil
+}
+
+// CompareAppRunsCommand aligns the runtime stats and goroutine counts of several runs of the same app
+func (*RpcServerImpl) CompareAppRunsCommand(ctx context.Context, data rpctypes.CompareAppRunsRequest) (rpctypes.CompareAppRunsData, error) {
+	return apppeer.CompareAppRuns(data)
+}
 
-	// Initialize result with goroutine counts
-	result := rpctypes.AppRunRuntimeStatsData{
-		AppRunId:            peer.AppRunId,
-		AppName:             peer.AppInfo.AppName,
-		NumTotalGoRoutines:  numGoRoutines,
-		NumActiveGoRoutines: numActiveGoRoutines,
-		NumOutrigGoRoutines: numOutrigGoRoutines,
-		Stats:               peer.RuntimeStats.GetRuntimeStats(data.Since),
+// BroadcastCommand runs a command on all routes matching a route pattern (e.g. "app:myservice:*") and
+// aggregates the results, errors are reported per route
+func (*RpcServerImpl) BroadcastCommand(ctx context.Context, data rpctypes.BroadcastRequest) (rpctypes.BroadcastResponse, error) {
+	if data.Route == "" {
+		return rpctypes.BroadcastResponse{}, fmt.Errorf("route is required")
 	}
+	if data.Command == "" {
+		return rpctypes.BroadcastResponse{}, fmt.Errorf("command is required")
+	}
+	if data.Command == "broadcast" {
+		return rpctypes.BroadcastResponse{}, fmt.Errorf("cannot broadcast the broadcast command")
+	}
+	var targets []rpc.BroadcastTarget
+	if strings.HasPrefix(data.Route, rpc.RoutePrefix_AppRun) {
+		cmdData, ok := data.Data.(map[string]any)
+		if data.Data != nil && !ok {
+			return rpctypes.BroadcastResponse{}, fmt.Errorf("data must be an object for app run routes")
+		}
+		for _, peer := range apppeer.GetAllAppRunPeers() {
+			routeId := peer.GetRouteId()
+			if routeId == "" || !rpc.MatchRoutePattern(data.Route, routeId) {
+				continue
+			}
+			targetData := maps.Clone(cmdData)
+			if targetData == nil {
+				targetData = make(map[string]any)
+			}
+			targetData["apprunid"] = peer.AppRunId
+			targets = append(targets, rpc.BroadcastTarget{
+				RouteId:  routeId,
+				AppRunId: peer.AppRunId,
+				Route:    rpc.DefaultRoute,
+				Data:     targetData,
+			})
+		}
+	} else {
+		for _, routeId := range rpc.GetDefaultRouter().GetMatchingRouteIds(data.Route) {
+			targets = append(targets, rpc.BroadcastTarget{RouteId: routeId, Route: routeId, Data: data.Data})
+		}
+	}
+	if len(targets) == 0 {
+		return rpctypes.BroadcastResponse{}, fmt.Errorf("no routes match %q", data.Route)
+	}
+	results := rpc.GetDefaultRouter().RunBroadcastCommand(ctx, data.Command, targets, rpc.GetRpcSourceFromContext(ctx))
+	return rpctypes.BroadcastResponse{Results: results}, nil
+}
 
-	return result, nil
+// CollectorAdminCommand forwards a collector enable/disable/settings command to a running app
+func (*RpcServerImpl) CollectorAdminCommand(ctx context.Context, data rpctypes.CollectorAdminRequest) (rtnErr error) {
+	defer func() { recordAudit(ctx, "CollectorAdminCommand", data.AppRunId, data, rtnErr) }()
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	if data.Collector == "" {
+		return fmt.Errorf("collector name is required")
+	}
+	if data.Action != "" && data.Action != ds.CollectorAdminActionEnable && data.Action != ds.CollectorAdminActionDisable {
+		return fmt.Errorf("invalid collector action: %s", data.Action)
+	}
+	if data.Action == "" && len(data.Settings) == 0 {
+		return fmt.Errorf("no collector action or settings given")
+	}
+	if statuses := peer.GetCollectorStatuses(); len(statuses) > 0 {
+		if _, ok := statuses[data.Collector]; !ok {
+			return fmt.Errorf("collector not found: %s", data.Collector)
+		}
+	}
+	return peer.SendPacketToSDK(&ds.PacketType{
+		Type: ds.PacketTypeCollectorAdmin,
+		Data: ds.CollectorAdminData{
+			Collector: data.Collector,
+			Action:    data.Action,
+			Settings:  data.Settings,
+		},
+	})
+}
+
+// GetEffectiveConfigCommand returns the config the monitor is running with and, if an app run is given, the
+// config its SDK resolved (so questions like "why isn't the watch collector running" can be answered)
+func (*RpcServerImpl) GetEffectiveConfigCommand(ctx context.Context, data rpctypes.AppRunRequest) (rpctypes.EffectiveConfigData, error) {
+	rtn := rpctypes.EffectiveConfigData{
+		Monitor: serverconfig.GetMonitorEffectiveConfig(),
+	}
+	rtn.Monitor.LogTails = logtail.GetStatuses()
+	if data.AppRunId == "" {
+		return rtn, nil
+	}
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return rtn, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	rtn.AppRunId = peer.AppRunId
+	rtn.AppName = peer.AppInfo.AppName
+	configInfo := peer.GetConfigInfo()
+	if configInfo == nil {
+		rtn.SDKError = fmt.Sprintf("the app's SDK (%s) did not report its config, upgrade the SDK to see it", peer.AppInfo.OutrigSDKVersion)
+		return rtn, nil
+	}
+	env := make(map[string]string)
+	for _, envVar := range peer.AppInfo.Env {
+		key, value, _ := strings.Cut(envVar, "=")
+		if strings.HasPrefix(key, "OUTRIG_") {
+			env[key] = value
+		}
+	}
+	rtn.SDK = &rpctypes.SDKEffectiveConfig{
+		SDKVersion:   peer.AppInfo.OutrigSDKVersion,
+		Source:       configInfo.Source,
+		Config:       configInfo.Config,
+		Env:          env,
+		Capabilities: peer.AppInfo.Capabilities,
+		Collectors:   peer.GetCollectorStatuses(),
+	}
+	return rtn, nil
+}
+
+// GetSDKOverheadCommand returns what the SDK's collectors cost the app (CPU and allocations), computed from
+// the overhead in the last collector statuses
+func (*RpcServerImpl) GetSDKOverheadCommand(ctx context.Context, data rpctypes.AppRunRequest) (rpctypes.SDKOverheadData, error) {
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return rpctypes.SDKOverheadData{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	rtn := rpctypes.SDKOverheadData{
+		AppRunId:   peer.AppRunId,
+		AppName:    peer.AppInfo.AppName,
+		Collectors: []rpctypes.CollectorOverheadInfo{},
+	}
+	for name, status := range peer.GetCollectorStatuses() {
+		if status.Overhead == nil {
+			continue
+		}
+		info := rpctypes.CollectorOverheadInfo{
+			Name:     name,
+			Running:  status.Running,
+			Overhead: status.Overhead,
+		}
+		if status.Overhead.NumCycles > 0 {
+			info.AvgCpuUs = float64(status.Overhead.TotalCpuUs) / float64(status.Overhead.NumCycles)
+			info.AvgAllocBytes = float64(status.Overhead.TotalAllocBytes) / float64(status.Overhead.NumCycles)
+		}
+		// a stopped collector costs nothing until it is enabled again
+		if status.Running && status.Overhead.IntervalMs > 0 {
+			info.CpuPercent = info.AvgCpuUs / float64(status.Overhead.IntervalMs*1000) * 100
+			info.AllocBytesRate = info.AvgAllocBytes / (float64(status.Overhead.IntervalMs) / 1000)
+		}
+		rtn.CpuMeasured = rtn.CpuMeasured || status.Overhead.CpuMeasured
+		rtn.TotalCpuPercent += info.CpuPercent
+		rtn.TotalAllocBytesRate += info.AllocBytesRate
+		rtn.Collectors = append(rtn.Collectors, info)
+	}
+	sort.Slice(rtn.Collectors, func(i, j int) bool {
+		if rtn.Collectors[i].CpuPercent != rtn.Collectors[j].CpuPercent {
+			return rtn.Collectors[i].CpuPercent > rtn.Collectors[j].CpuPercent
+		}
+		return rtn.Collectors[i].Name < rtn.Collectors[j].Name
+	})
+	return rtn, nil
+}
+
+// RuntimeControlCommand runs a runtime command (GC, heap dump, GOGC change) in a running app and returns its result
+func (*RpcServerImpl) RuntimeControlCommand(ctx context.Context, data rpctypes.RuntimeControlRequest) (rtn rpctypes.RuntimeControlResponse, rtnErr error) {
+	defer func() {
+		if rtnErr == nil && rtn.Result.Error != "" {
+			recordAudit(ctx, "RuntimeControlCommand", data.AppRunId, data, errors.New(rtn.Result.Error))
+			return
+		}
+		recordAudit(ctx, "RuntimeControlCommand", data.AppRunId, data, rtnErr)
+	}()
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return rpctypes.RuntimeControlResponse{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	switch data.Action {
+	case ds.RuntimeControlActionGC, ds.RuntimeControlActionFreeOSMemory, ds.RuntimeControlActionHeapDump, ds.RuntimeControlActionSetGCPercent:
+	default:
+		return rpctypes.RuntimeControlResponse{}, fmt.Errorf("invalid runtime control action: %s", data.Action)
+	}
+	if data.Path != "" && data.Action != ds.RuntimeControlActionHeapDump {
+		return rpctypes.RuntimeControlResponse{}, fmt.Errorf("path is only valid for the %s action", ds.RuntimeControlActionHeapDump)
+	}
+	if peer.Status != apppeer.AppStatusRunning {
+		return rpctypes.RuntimeControlResponse{}, fmt.Errorf("app run is not running: %s", data.AppRunId)
+	}
+	triggeredBy := rpc.GetRpcSourceFromContext(ctx)
+	result, err := peer.RunRuntimeControl(ctx, ds.RuntimeControlData{
+		Action:      data.Action,
+		Path:        data.Path,
+		GCPercent:   data.GCPercent,
+		TriggeredBy: triggeredBy,
+	})
+	if err != nil {
+		return rpctypes.RuntimeControlResponse{}, err
+	}
+	return rpctypes.RuntimeControlResponse{
+		AppRunId:    data.AppRunId,
+		TriggeredBy: triggeredBy,
+		Result:      result,
+	}, nil
+}
+
+// SetWatchValueCommand sets the value of a settable watch in a running app (the SDK calls the watch's setter)
+func (*RpcServerImpl) SetWatchValueCommand(ctx context.Context, data rpctypes.SetWatchValueRequest) (_ rpctypes.SetWatchValueResponse, rtnErr error) {
+	defer func() { recordAudit(ctx, "SetWatchValueCommand", data.AppRunId, data, rtnErr) }()
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return rpctypes.SetWatchValueResponse{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	if data.Name == "" {
+		return rpctypes.SetWatchValueResponse{}, fmt.Errorf("watch name is required")
+	}
+	if peer.Status != apppeer.AppStatusRunning {
+		return rpctypes.SetWatchValueResponse{}, fmt.Errorf("app run is not running: %s", data.AppRunId)
+	}
+	triggeredBy := rpc.GetRpcSourceFromContext(ctx)
+	err := peer.SetWatchValue(ctx, ds.SetWatchValueData{
+		Name:        data.Name,
+		Value:       data.Value,
+		TriggeredBy: triggeredBy,
+	})
+	if err != nil {
+		return rpctypes.SetWatchValueResponse{}, err
+	}
+	return rpctypes.SetWatchValueResponse{
+		AppRunId:    data.AppRunId,
+		Name:        data.Name,
+		TriggeredBy: triggeredBy,
+	}, nil
+}
+
+// CaptureCPUProfileCommand captures a CPU profile of a running app, the profile is kept by the server for download
+func (*RpcServerImpl) CaptureCPUProfileCommand(ctx context.Context, data rpctypes.CaptureCPUProfileRequest) (_ rpctypes.CaptureCPUProfileResponse, rtnErr error) {
+	defer func() { recordAudit(ctx, "CaptureCPUProfileCommand", data.AppRunId, data, rtnErr) }()
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return rpctypes.CaptureCPUProfileResponse{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	durationSec := data.DurationSec
+	if durationSec == 0 {
+		durationSec = DefaultCPUProfileDurationSec
+	}
+	if durationSec < 0 || durationSec > ds.MaxCPUProfileDurationSec {
+		return rpctypes.CaptureCPUProfileResponse{}, fmt.Errorf("invalid cpu profile duration %ds (must be between 1 and %d)", durationSec, ds.MaxCPUProfileDurationSec)
+	}
+	if peer.Status != apppeer.AppStatusRunning {
+		return rpctypes.CaptureCPUProfileResponse{}, fmt.Errorf("app run is not running: %s", data.AppRunId)
+	}
+	triggeredBy := rpc.GetRpcSourceFromContext(ctx)
+	captureCtx, cancelFn := makeCaptureContext(ctx, durationSec)
+	defer cancelFn()
+	profile, err := peer.CaptureCPUProfile(captureCtx, durationSec, triggeredBy)
+	if err != nil {
+		return rpctypes.CaptureCPUProfileResponse{}, err
+	}
+	return rpctypes.CaptureCPUProfileResponse{
+		AppRunId:    data.AppRunId,
+		ProfileId:   profile.ProfileId,
+		Ts:          profile.Ts,
+		DurationMs:  profile.DurationMs,
+		Size:        len(profile.Data),
+		TriggeredBy: triggeredBy,
+		DownloadUrl: apppeer.GetCPUProfileDownloadUrl(data.AppRunId, profile.ProfileId),
+	}, nil
+}
+
+// makeCaptureContext returns the context for a capture that runs for durationSec seconds in the app. The request's
+// own timeout (5s unless the caller set one) is often shorter than the capture, so the capture gets its duration
+// plus CaptureResponseMargin instead, but it is still stopped when the request is canceled.
+func makeCaptureContext(ctx context.Context, durationSec int) (context.Context, context.CancelFunc) {
+	captureCtx, cancelFn := context.WithTimeout(context.WithoutCancel(ctx), time.Duration(durationSec)*time.Second+CaptureResponseMargin)
+	stopAfter := context.AfterFunc(ctx, func() {
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			cancelFn()
+		}
+	})
+	return captureCtx, func() {
+		stopAfter()
+		cancelFn()
+	}
+}
+
+// ExportAppRunCommand exports an app run as a bundle, the bundle is kept by the server for download
+func (*RpcServerImpl) ExportAppRunCommand(ctx context.Context, data rpctypes.AppRunRequest) (_ rpctypes.ExportAppRunResponse, rtnErr error) {
+	defer func() { recordAudit(ctx, "ExportAppRunCommand", data.AppRunId, data, rtnErr) }()
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return rpctypes.ExportAppRunResponse{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	export, err := peer.ExportBundle()
+	if err != nil {
+		return rpctypes.ExportAppRunResponse{}, err
+	}
+	return rpctypes.ExportAppRunResponse{
+		AppRunId:            data.AppRunId,
+		ExportId:            export.ExportId,
+		FileName:            export.FileName,
+		Size:                len(export.Data),
+		NumLogs:             export.Stats.NumLogs,
+		NumGoRoutineSamples: export.Stats.NumGoRoutineSamples,
+		NumWatchSamples:     export.Stats.NumWatchSamples,
+		NumRuntimeStats:     export.Stats.NumRuntimeStats,
+		NumPanics:           export.Stats.NumPanics,
+		DownloadUrl:         apppeer.GetExportDownloadUrl(data.AppRunId, export.ExportId),
+	}, nil
+}
+
+// CaptureExecTraceCommand captures an execution trace of a running app, the trace is kept by the server for download
+func (*RpcServerImpl) CaptureExecTraceCommand(ctx context.Context, data rpctypes.CaptureExecTraceRequest) (_ rpctypes.ExecTraceInfo, rtnErr error) {
+	defer func() { recordAudit(ctx, "CaptureExecTraceCommand", data.AppRunId, data, rtnErr) }()
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return rpctypes.ExecTraceInfo{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	durationSec := data.DurationSec
+	if durationSec == 0 {
+		durationSec = DefaultExecTraceDurationSec
+	}
+	if durationSec < 0 || durationSec > ds.MaxExecTraceDurationSec {
+		return rpctypes.ExecTraceInfo{}, fmt.Errorf("invalid execution trace duration %ds (must be between 1 and %d)", durationSec, ds.MaxExecTraceDurationSec)
+	}
+	if peer.Status != apppeer.AppStatusRunning {
+		return rpctypes.ExecTraceInfo{}, fmt.Errorf("app run is not running: %s", data.AppRunId)
+	}
+	triggeredBy := rpc.GetRpcSourceFromContext(ctx)
+	captureCtx, cancelFn := makeCaptureContext(ctx, durationSec)
+	defer cancelFn()
+	execTrace, err := peer.CaptureExecTrace(captureCtx, durationSec, triggeredBy)
+	if err != nil {
+		return rpctypes.ExecTraceInfo{}, err
+	}
+	return execTrace.ToInfo(data.AppRunId), nil
+}
+
+// GetAppRunExecTracesCommand returns the execution traces captured for an app run
+func (*RpcServerImpl) GetAppRunExecTracesCommand(ctx context.Context, data rpctypes.AppRunRequest) (rpctypes.AppRunExecTracesData, error) {
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil {
+		return rpctypes.AppRunExecTracesData{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	return rpctypes.AppRunExecTracesData{
+		AppRunId: data.AppRunId,
+		Traces:   peer.GetExecTraceInfos(),
+	}, nil
+}
+
+// ForceGoroutineDumpCommand takes a full goroutine dump of a running app right away (outside the 1s poll),
+// the dump is pinned on the app run's goroutine timeline and kept by the server for download
+func (*RpcServerImpl) ForceGoroutineDumpCommand(ctx context.Context, data rpctypes.ForceGoroutineDumpRequest) (_ rpctypes.GoroutineDumpInfo, rtnErr error) {
+	defer func() { recordAudit(ctx, "ForceGoroutineDumpCommand", data.AppRunId, data, rtnErr) }()
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return rpctypes.GoroutineDumpInfo{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	if peer.Status != apppeer.AppStatusRunning {
+		return rpctypes.GoroutineDumpInfo{}, fmt.Errorf("app run is not running: %s", data.AppRunId)
+	}
+	label := strings.TrimSpace(data.Label)
+	if label == "" {
+		label = apppeer.GoroutineDumpLabel_Manual
+	}
+	triggeredBy := rpc.GetRpcSourceFromContext(ctx)
+	dump, err := peer.ForceGoroutineDump(ctx, label, triggeredBy)
+	if err != nil {
+		return rpctypes.GoroutineDumpInfo{}, err
+	}
+	return dump.ToInfo(data.AppRunId), nil
+}
+
+// GetAppRunGoroutineDumpsCommand returns the goroutine dumps taken on demand for an app run
+func (*RpcServerImpl) GetAppRunGoroutineDumpsCommand(ctx context.Context, data rpctypes.AppRunRequest) (rpctypes.AppRunGoroutineDumpsData, error) {
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil {
+		return rpctypes.AppRunGoroutineDumpsData{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	return rpctypes.AppRunGoroutineDumpsData{
+		AppRunId: data.AppRunId,
+		Dumps:    peer.GetGoroutineDumpInfos(),
+	}, nil
 }
 
 // GoRoutineSearchRequestCommand handles search requests for goroutines
@@ -157,14 +624,8 @@ func (*RpcServerImpl) GoRoutineSearchRequestCommand(ctx context.Context, data rp
 		return rpctypes.GoRoutineSearchResultData{}, fmt.Errorf("app run not found: %s", data.AppRunId)
 	}
 
-	// Get module name from AppInfo (needed for proper goroutine parsing)
-	moduleName := ""
-	if peer.AppInfo != nil {
-		moduleName = peer.AppInfo.ModuleName
-	}
-
-	// Get goroutines based on ActiveOnly flag and timestamp
-	result := peer.GoRoutines.GetParsedGoRoutinesAtTimestamp(moduleName, data.Timestamp, data.ActiveOnly)
+	// Get goroutines based on ActiveOnly flag and timestamp (the module info is needed for proper goroutine parsing)
+	result := peer.GoRoutines.GetParsedGoRoutinesAtTimestamp(peer.GetModuleInfo(), data.Timestamp, data.ActiveOnly)
 	allGoRoutines := result.GoRoutines
 	totalCount := result.TotalCount
 	effectiveTimestamp := result.EffectiveTimestamp
@@ -206,16 +667,27 @@ func (*RpcServerImpl) GoRoutineSearchRequestCommand(ctx context.Context, data rp
 		return rpctypes.GoRoutineSearchResultData{}, err
 	}
 
-	// Extract GoIds from filtered results
+	// Extract GoIds from filtered results (one representative per group for aggregate searches)
+	var groups []rpctypes.GoRoutineGroup
 	results := make([]int64, 0, len(filteredGoRoutines))
-	for _, gr := range filteredGoRoutines {
-		results = append(results, gr.GoId)
-	}
+	if data.Aggregate {
+		groups = apppeer.GroupGoRoutines(filteredGoRoutines)
+		for _, group := range groups {
+			results = append(results, group.RepresentativeId)
+		}
+		if len(groups) > MaxGoRoutineSearchResults {
+			groups = groups[:MaxGoRoutineSearchResults]
+		}
+	} else {
+		for _, gr := range filteredGoRoutines {
+			results = append(results, gr.GoId)
+		}
 
-	// Sort the results by goroutine ID for consistent ordering
-	sort.Slice(results, func(i, j int) bool {
-		return results[i] < results[j]
-	})
+		// Sort the results by goroutine ID for consistent ordering
+		sort.Slice(results, func(i, j int) bool {
+			return results[i] < results[j]
+		})
+	}
 
 	// Limit the number of results to MaxGoRoutineSearchResults
 	if len(results) > MaxGoRoutineSearchResults {
@@ -241,13 +713,14 @@ func (*RpcServerImpl) GoRoutineSearchRequestCommand(ctx context.Context, data rp
 		Results:                  results,
 		ErrorSpans:               errorSpans,
 		EffectiveSearchTimestamp: effectiveTimestamp,
+		Groups:                   groups,
 	}, nil
 }
 
 // GoRoutineTimeSpansCommand handles requests for goroutine time spans since a tick index
 func (*RpcServerImpl) GoRoutineTimeSpansCommand(ctx context.Context, data rpctypes.GoRoutineTimeSpansRequest) (rpctypes.GoRoutineTimeSpansResponse, error) {
 	// Get the app run peer
-	peer := apppeer.GetAppRunPeer(data.AppRunId, false)
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
 	if peer == nil {
 		return rpctypes.GoRoutineTimeSpansResponse{}, fmt.Errorf("app run not found: %s", data.AppRunId)
 	}
@@ -257,6 +730,15 @@ func (*RpcServerImpl) GoRoutineTimeSpansCommand(ctx context.Context, data rpctyp
 	return response, nil
 }
 
+// GoRoutineStateHistogramCommand returns the goroutine primary state counts of the ticks after a tick index
+func (*RpcServerImpl) GoRoutineStateHistogramCommand(ctx context.Context, data rpctypes.GoRoutineStateHistogramRequest) (rpctypes.GoRoutineStateHistogramResponse, error) {
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil {
+		return rpctypes.GoRoutineStateHistogramResponse{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	return peer.GoRoutines.GetStateHistogramSinceTickIdx(data.SinceTickIdx, data.ShowOutrig), nil
+}
+
 // combinedWatchSampleToSearchObject converts a CombinedWatchSample to a WatchSearchObject
 func combinedWatchSampleToSearchObject(combined rpctypes.CombinedWatchSample) gensearch.SearchObject {
 	// Extract data from both the declaration and sample
@@ -272,6 +754,7 @@ func combinedWatchSampleToSearchObject(combined rpctypes.CombinedWatchSample) ge
 	return &gensearch.WatchSearchObject{
 		WatchNum: watchNum,
 		Name:     sample.Name,
+		Group:    decl.Group,
 		Val:      val,
 		Tags:     decl.Tags,
 		Type:     sample.Type,
@@ -343,6 +826,7 @@ func (*RpcServerImpl) WatchSearchRequestCommand(ctx context.Context, data rpctyp
 		TotalCount:    stats.TotalCount,
 		Results:       results,
 		ErrorSpans:    errorSpans,
+		Warnings:      peer.Watches.GetLimitWarnings(),
 	}, nil
 }
 
@@ -350,14 +834,62 @@ func (*RpcServerImpl) WatchSearchRequestCommand(ctx context.Context, data rpctyp
 func (*RpcServerImpl) LogSearchRequestCommand(ctx context.Context, data rpctypes.SearchRequestData) (rpctypes.SearchResultData, error) {
 	peer := apppeer.GetAppRunPeer(data.AppRunId, false)
 	manager := gensearch.GetOrCreateManager(data.WidgetId, data.AppRunId, peer.Logs)
-	return manager.SearchLogs(ctx, data)
+	searchCtx, cancelFn := makeSearchContext(ctx)
+	defer cancelFn()
+	return manager.SearchLogs(searchCtx, data)
 }
 
 // LogSearchRangeCommand handles range-based search requests for logs
 func (*RpcServerImpl) LogSearchRangeCommand(ctx context.Context, data rpctypes.LogSearchRangeRequest) (rpctypes.LogSearchRangeResultData, error) {
 	peer := apppeer.GetAppRunPeer(data.AppRunId, false)
 	manager := gensearch.GetOrCreateManager(data.WidgetId, data.AppRunId, peer.Logs)
-	return manager.SearchLogsRange(ctx, data)
+	searchCtx, cancelFn := makeSearchContext(ctx)
+	defer cancelFn()
+	return manager.SearchLogsRange(searchCtx, data)
+}
+
+// makeSearchContext stops a search a little before the request's deadline, so a search that runs out of time
+// returns the lines found so far (marked as canceled) instead of failing the whole request with a timeout
+func makeSearchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) <= 2*SearchResponseMargin {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline.Add(-SearchResponseMargin))
+}
+
+// GetSourcePreviewCommand returns the source around the line of a stack frame from the app's own module
+func (*RpcServerImpl) GetSourcePreviewCommand(ctx context.Context, data rpctypes.SourcePreviewRequest) (rpctypes.SourcePreviewData, error) {
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil || peer.AppInfo == nil {
+		return rpctypes.SourcePreviewData{}, fmt.Errorf("app run not found: %s", data.AppRunId)
+	}
+	return peer.GetSourcePreview(ctx, data.Frame, data.ContextLines)
+}
+
+// GlobalSearchRequestCommand searches the logs of several app runs at once (to find which app logged a line).
+// All the runs are searched within the request's timeout, callers searching many large runs should raise it.
+func (*RpcServerImpl) GlobalSearchRequestCommand(ctx context.Context, data rpctypes.GlobalSearchRequest) (rpctypes.GlobalSearchResultData, error) {
+	searchCtx, cancelFn := makeSearchContext(ctx)
+	defer cancelFn()
+	return apppeer.GlobalSearchLogs(searchCtx, data)
+}
+
+// LogTailCommand streams the new log lines matching the widget's search (see rpctypes.LogTailRequest)
+func (*RpcServerImpl) LogTailCommand(ctx context.Context, data rpctypes.LogTailRequest) chan rpctypes.RespUnion[rpctypes.LogTailData] {
+	peer := apppeer.FindAppRunPeer(data.AppRunId)
+	if peer == nil {
+		rtnCh := make(chan rpctypes.RespUnion[rpctypes.LogTailData], 1)
+		rpcclient.RtnStreamErr(rtnCh, fmt.Errorf("app run not found: %s", data.AppRunId))
+		return rtnCh
+	}
+	manager := gensearch.GetOrCreateManager(data.WidgetId, data.AppRunId, peer.Logs)
+	rtnCh, err := manager.TailLogs(ctx, data)
+	if err != nil {
+		rtnCh = make(chan rpctypes.RespUnion[rpctypes.LogTailData], 1)
+		rpcclient.RtnStreamErr(rtnCh, err)
+	}
+	return rtnCh
 }
 
 // LogWidgetAdminCommand handles widget administration requests
@@ -380,7 +912,8 @@ func (*RpcServerImpl) LogWidgetAdminCommand(ctx context.Context, data rpctypes.L
 }
 
 // LogUpdateMarkedLinesCommand handles updating marked lines for a widget
-func (*RpcServerImpl) LogUpdateMarkedLinesCommand(ctx context.Context, data rpctypes.MarkedLinesData) error {
+func (*RpcServerImpl) LogUpdateMarkedLinesCommand(ctx context.Context, data rpctypes.MarkedLinesData) (rtnErr error) {
+	defer func() { recordAudit(ctx, "LogUpdateMarkedLinesCommand", "", data, rtnErr) }()
 	markManager := gensearch.GetMarkManager(data.WidgetId)
 	if markManager == nil {
 		return fmt.Errorf("widget not found: %s", data.WidgetId)
@@ -401,10 +934,14 @@ func (*RpcServerImpl) LogUpdateMarkedLinesCommand(ctx context.Context, data rpct
 		}
 		markedLines[lineNum] = isMarked
 	}
+	// validate the ranges first so an invalid request doesn't leave a partial update
+	if err := gensearch.ValidateMarkedRanges(data.Ranges); err != nil {
+		return err
+	}
 
 	// Update the marked lines
 	markManager.UpdateMarkedLines(markedLines)
-	return nil
+	return markManager.UpdateMarkedRanges(data.Ranges)
 }
 
 // LogGetMarkedLinesCommand retrieves all marked log lines for a widget
@@ -420,7 +957,42 @@ func (*RpcServerImpl) LogGetMarkedLinesCommand(ctx context.Context, data rpctype
 		return rpctypes.MarkedLinesResultData{}, err
 	}
 
-	return rpctypes.MarkedLinesResultData{Lines: markedLines}, nil
+	marks := make(map[string]rpctypes.LineMark)
+	for lineNum, mark := range manager.MarkManager.GetMarks() {
+		marks[strconv.FormatInt(lineNum, 10)] = mark
+	}
+	return rpctypes.MarkedLinesResultData{Lines: markedLines, Marks: marks}, nil
+}
+
+// LogExportMarkedLinesCommand returns the marked lines of a widget's app run (with their annotations) as a shareable bundle
+func (*RpcServerImpl) LogExportMarkedLinesCommand(ctx context.Context, data rpctypes.MarkedLinesRequestData) (rpctypes.MarkedLinesExportData, error) {
+	manager := gensearch.GetManager(data.WidgetId)
+	if manager == nil {
+		return rpctypes.MarkedLinesExportData{}, fmt.Errorf("widget not found: %s", data.WidgetId)
+	}
+	markedLines, err := manager.GetMarkedLogLines()
+	if err != nil {
+		return rpctypes.MarkedLinesExportData{}, err
+	}
+	marks := manager.MarkManager.GetMarks()
+	rtn := rpctypes.MarkedLinesExportData{
+		Version:  1,
+		AppRunId: manager.AppRunId,
+		ExportTs: time.Now().UnixMilli(),
+		Lines:    make([]rpctypes.MarkedLineExport, 0, len(markedLines)),
+	}
+	if peer := apppeer.GetAppRunPeer(manager.AppRunId, false); peer != nil && peer.AppInfo != nil {
+		rtn.AppName = peer.AppInfo.AppName
+	}
+	for _, line := range markedLines {
+		mark := marks[line.LineNum]
+		rtn.Lines = append(rtn.Lines, rpctypes.MarkedLineExport{
+			Line:       line,
+			Annotation: mark.Annotation,
+			Color:      mark.Color,
+		})
+	}
+	return rtn, nil
 }
 
 // UpdateBrowserTabUrlCommand updates the URL for a browser tab
@@ -433,6 +1005,23 @@ func (*RpcServerImpl) UpdateBrowserTabUrlCommand(ctx context.Context, data rpcty
 	return browsertabs.UpdateBrowserTabUrl(rpcSource, data)
 }
 
+// UpdateBrowserTabFocusCommand sets what a browser tab is looking at in its app run (shown to the other tabs viewing it)
+func (*RpcServerImpl) UpdateBrowserTabFocusCommand(ctx context.Context, data rpctypes.BrowserTabFocusData) error {
+	rpcSource := rpc.GetRpcSourceFromContext(ctx)
+	if rpcSource == "" {
+		return fmt.Errorf("no rpc source set")
+	}
+	return browsertabs.SetBrowserTabFocus(rpcSource, data)
+}
+
+// GetAppRunPresenceCommand returns the browser tabs viewing an app run
+func (*RpcServerImpl) GetAppRunPresenceCommand(ctx context.Context, data rpctypes.AppRunRequest) (rpctypes.AppRunPresenceData, error) {
+	if data.AppRunId == "" {
+		return rpctypes.AppRunPresenceData{}, fmt.Errorf("no app run id provided")
+	}
+	return browsertabs.GetAppRunPresence(data.AppRunId), nil
+}
+
 // SendTEventFeCommand sends a telemetry event from the frontend
 func (*RpcServerImpl) SendTEventFeCommand(ctx context.Context, data rpctypes.TEventFeData) error {
 	// Create a TEvent from the frontend data
@@ -449,6 +1038,29 @@ func (*RpcServerImpl) SendTEventFeCommand(ctx context.Context, data rpctypes.TEv
 	return nil
 }
 
+// RecordSessionEventCommand adds a frontend event to the local session timeline (a no-op unless the
+// sessiontimeline setting is on), session events are never part of the telemetry
+func (*RpcServerImpl) RecordSessionEventCommand(ctx context.Context, data rpctypes.SessionEvent) error {
+	data.Ts = 0
+	data.AppName = ""
+	if data.AppRunId != "" {
+		peer := apppeer.FindAppRunPeer(data.AppRunId)
+		if peer != nil && peer.AppInfo != nil {
+			data.AppName = peer.AppInfo.AppName
+		}
+	}
+	tevent.RecordSessionEvent(data)
+	return nil
+}
+
+// GetSessionTimelineCommand returns the events of the local session timeline
+func (*RpcServerImpl) GetSessionTimelineCommand(ctx context.Context, data rpctypes.SessionTimelineRequest) (rpctypes.SessionTimelineData, error) {
+	if data.Limit < 0 {
+		return rpctypes.SessionTimelineData{}, fmt.Errorf("limit cannot be negative")
+	}
+	return tevent.QuerySessionTimeline(data), nil
+}
+
 // UpdateCheckCommand returns information about available updates
 func (*RpcServerImpl) UpdateCheckCommand(ctx context.Context) (rpctypes.UpdateCheckData, error) {
 	newerVersion := updatecheck.GetUpdatedVersion()
@@ -467,17 +1079,107 @@ func (*RpcServerImpl) TriggerTrayUpdateCommand(ctx context.Context) error {
 
 // ClearNonActiveAppRunsCommand removes all AppPeers for non-connected app runs
 func (*RpcServerImpl) ClearNonActiveAppRunsCommand(ctx context.Context) error {
-	return apppeer.ClearNonActiveAppRuns()
+	err := apppeer.ClearNonActiveAppRuns()
+	recordAudit(ctx, "ClearNonActiveAppRunsCommand", "", nil, err)
+	return err
+}
+
+// PruneAppRunsCommand applies the retention settings (optionally overridden by the request) and reports the pruned app runs
+func (*RpcServerImpl) PruneAppRunsCommand(ctx context.Context, data rpctypes.PruneAppRunsRequest) (rpctypes.PruneAppRunsResult, error) {
+	if data.MaxRunsPerApp < 0 || data.MaxAgeMs < 0 {
+		return rpctypes.PruneAppRunsResult{}, fmt.Errorf("maxrunsperapp and maxagems cannot be negative")
+	}
+	policy := apppeer.GetRetentionPolicy()
+	if data.MaxRunsPerApp > 0 {
+		policy.MaxRunsPerApp = data.MaxRunsPerApp
+	}
+	if data.MaxAgeMs > 0 {
+		policy.MaxAge = time.Duration(data.MaxAgeMs) * time.Millisecond
+	}
+	pruned := apppeer.PruneAppRuns(policy, data.DryRun)
+	if !data.DryRun {
+		recordAudit(ctx, "PruneAppRunsCommand", "", map[string]any{"request": data, "pruned": len(pruned)}, nil)
+	}
+	return rpctypes.PruneAppRunsResult{Pruned: pruned, DryRun: data.DryRun}, nil
+}
+
+// GetDiskUsageCommand reports the size of the data directory and the footprint of each app run
+func (*RpcServerImpl) GetDiskUsageCommand(ctx context.Context) (rpctypes.DiskUsageData, error) {
+	return apppeer.GetDiskUsage(), nil
+}
+
+// CompactAppRunStoreCommand compacts (or drops) one of the stores of an app run to free memory and disk space
+func (*RpcServerImpl) CompactAppRunStoreCommand(ctx context.Context, data rpctypes.CompactAppRunStoreRequest) (_ rpctypes.CompactAppRunStoreResult, rtnErr error) {
+	defer func() { recordAudit(ctx, "CompactAppRunStoreCommand", data.AppRunId, data, rtnErr) }()
+	return apppeer.CompactAppRunStore(data.AppRunId, data.Store, data.Drop)
+}
+
+// GetSnapshotRulesCommand returns the scheduled snapshot rules
+func (*RpcServerImpl) GetSnapshotRulesCommand(ctx context.Context) (rpctypes.SnapshotRulesData, error) {
+	return rpctypes.SnapshotRulesData{Rules: snapshots.GetRules()}, nil
+}
+
+// UpdateSnapshotRuleCommand creates (empty RuleId) or updates a scheduled snapshot rule
+func (*RpcServerImpl) UpdateSnapshotRuleCommand(ctx context.Context, data rpctypes.SnapshotRule) (_ rpctypes.SnapshotRule, rtnErr error) {
+	defer func() { recordAudit(ctx, "UpdateSnapshotRuleCommand", "", data, rtnErr) }()
+	return snapshots.UpdateRule(data)
+}
+
+// DeleteSnapshotRuleCommand deletes a scheduled snapshot rule (its snapshots are kept)
+func (*RpcServerImpl) DeleteSnapshotRuleCommand(ctx context.Context, data rpctypes.SnapshotRuleRequest) (rtnErr error) {
+	defer func() { recordAudit(ctx, "DeleteSnapshotRuleCommand", "", data, rtnErr) }()
+	return snapshots.DeleteRule(data.RuleId)
+}
+
+// RunSnapshotRuleCommand runs a snapshot rule now and returns the snapshots it took
+func (*RpcServerImpl) RunSnapshotRuleCommand(ctx context.Context, data rpctypes.SnapshotRuleRequest) (_ rpctypes.SnapshotsData, rtnErr error) {
+	defer func() { recordAudit(ctx, "RunSnapshotRuleCommand", "", data, rtnErr) }()
+	taken, err := snapshots.RunRule(data.RuleId)
+	if taken == nil {
+		taken = []rpctypes.SnapshotInfo{}
+	}
+	return rpctypes.SnapshotsData{Snapshots: taken}, err
+}
+
+// GetSnapshotsCommand returns the snapshots taken by the snapshot rules, newest first
+func (*RpcServerImpl) GetSnapshotsCommand(ctx context.Context, data rpctypes.SnapshotsRequest) (rpctypes.SnapshotsData, error) {
+	if data.Limit < 0 {
+		return rpctypes.SnapshotsData{}, fmt.Errorf("limit cannot be negative")
+	}
+	return rpctypes.SnapshotsData{Snapshots: snapshots.GetSnapshots(data)}, nil
+}
+
+// DeleteSnapshotCommand deletes a snapshot and its file
+func (*RpcServerImpl) DeleteSnapshotCommand(ctx context.Context, data rpctypes.SnapshotRequest) (rtnErr error) {
+	defer func() { recordAudit(ctx, "DeleteSnapshotCommand", "", data, rtnErr) }()
+	return snapshots.DeleteSnapshot(data.SnapshotId)
 }
 
 // LaunchDemoAppCommand launches the demo application
 func (*RpcServerImpl) LaunchDemoAppCommand(ctx context.Context) error {
-	return democontroller.LaunchDemoApp()
+	err := democontroller.LaunchDemoApp()
+	recordAudit(ctx, "LaunchDemoAppCommand", "", nil, err)
+	return err
 }
 
 // KillDemoAppCommand kills the demo application
 func (*RpcServerImpl) KillDemoAppCommand(ctx context.Context) error {
-	return democontroller.KillDemoApp()
+	err := democontroller.KillDemoApp()
+	recordAudit(ctx, "KillDemoAppCommand", "", nil, err)
+	return err
+}
+
+// GetAuditLogCommand returns the audit log of state-changing commands run on this monitor
+func (*RpcServerImpl) GetAuditLogCommand(ctx context.Context, data rpctypes.AuditLogRequest) (rpctypes.AuditLogData, error) {
+	if data.Limit < 0 {
+		return rpctypes.AuditLogData{}, fmt.Errorf("limit cannot be negative")
+	}
+	return auditlog.Query(data), nil
+}
+
+// recordAudit adds a state-changing command to the audit log, using the rpc route as the source
+func recordAudit(ctx context.Context, command string, appRunId string, params any, err error) {
+	auditlog.Record(rpc.GetRpcSourceFromContext(ctx), command, appRunId, params, err)
 }
 
 // GetDemoAppStatusCommand returns the status of the demo application
@@ -0,0 +1,88 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package rpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/apppeer"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// makeTestAppRun registers an app run that sent its app info (and the given packets), it is removed when the test ends
+func makeTestAppRun(t *testing.T, packets ...ds.PacketType) *apppeer.AppRunPeer {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(serverbase.OutrigDevEnvName, "")
+	appRunId := "test-" + t.Name()
+	peer := apppeer.GetAppRunPeer(appRunId, false)
+	t.Cleanup(func() {
+		peer.Status = apppeer.AppStatusDone
+		apppeer.ClearAppRun(appRunId)
+	})
+	packets = append([]ds.PacketType{{Type: ds.PacketTypeAppInfo, Data: ds.AppInfo{AppRunId: appRunId, AppName: "testapp"}}}, packets...)
+	for _, pk := range packets {
+		barr, err := json.Marshal(pk.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := peer.HandlePacket(pk.Type, barr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return peer
+}
+
+func TestGetSDKOverheadCommand(t *testing.T) {
+	statuses := map[string]ds.CollectorStatus{
+		"goroutine":    {Running: true, Overhead: &ds.CollectorOverhead{IntervalMs: 1000, NumCycles: 10, TotalCpuUs: 20000, TotalAllocBytes: 1000000}},
+		"watch":        {Running: true, Overhead: &ds.CollectorOverhead{IntervalMs: 500, NumCycles: 4, TotalCpuUs: 4000, TotalAllocBytes: 4000}},
+		"runtimestats": {Running: false, Overhead: &ds.CollectorOverhead{IntervalMs: 1000, NumCycles: 5, TotalCpuUs: 50000, TotalAllocBytes: 5000}},
+		"cheap":        {Running: true, Overhead: &ds.CollectorOverhead{IntervalMs: 1000, CpuMeasured: true}},
+		"logs":         {Running: true},
+	}
+	peer := makeTestAppRun(t, ds.PacketType{Type: ds.PacketTypeCollectorStatus, Data: statuses})
+
+	rtn, err := (&RpcServerImpl{}).GetSDKOverheadCommand(context.Background(), rpctypes.AppRunRequest{AppRunId: peer.AppRunId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	approxEqual := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	expected := []struct {
+		name           string
+		avgCpuUs       float64
+		cpuPercent     float64
+		allocBytesRate float64
+	}{
+		// equal CPU is ordered by name, a stopped collector costs nothing
+		{"goroutine", 2000, 0.2, 100000},
+		{"watch", 1000, 0.2, 2000},
+		{"cheap", 0, 0, 0},
+		{"runtimestats", 10000, 0, 0},
+	}
+	if len(rtn.Collectors) != len(expected) {
+		t.Fatalf("got %d collectors, want %d (collectors without overhead are skipped)", len(rtn.Collectors), len(expected))
+	}
+	for i, exp := range expected {
+		info := rtn.Collectors[i]
+		if info.Name != exp.name || !approxEqual(info.AvgCpuUs, exp.avgCpuUs) || !approxEqual(info.CpuPercent, exp.cpuPercent) || !approxEqual(info.AllocBytesRate, exp.allocBytesRate) {
+			t.Errorf("collector %d: got %s avg %vus %v%% %vB/s, want %+v", i, info.Name, info.AvgCpuUs, info.CpuPercent, info.AllocBytesRate, exp)
+		}
+	}
+	if !approxEqual(rtn.TotalCpuPercent, 0.4) || !approxEqual(rtn.TotalAllocBytesRate, 102000) || !rtn.CpuMeasured || rtn.AppName != "testapp" {
+		t.Errorf("got totals %v%% %vB/s (cpu measured %v, app %q)", rtn.TotalCpuPercent, rtn.TotalAllocBytesRate, rtn.CpuMeasured, rtn.AppName)
+	}
+
+	if _, err := (&RpcServerImpl{}).GetSDKOverheadCommand(context.Background(), rpctypes.AppRunRequest{AppRunId: "missing"}); err == nil {
+		t.Errorf("expected an error for an unknown app run")
+	}
+	if apppeer.FindAppRunPeer("missing") != nil {
+		t.Errorf("the lookup of an unknown app run created a peer")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"reflect"
 
+	"github.com/outrigdev/outrig/pkg/config"
 	"github.com/outrigdev/outrig/pkg/ds"
 )
 
@@ -21,12 +22,44 @@ const (
 	Event_RouteDown       = "route:down"
 	Event_RouteUp         = "route:up"
 	Event_AppStatusUpdate = "app:statusupdate"
+
+	// app run lifecycle events (scoped by app run id, see AppLifecycleEvent)
+	Event_AppConnected    = "app:connected"
+	Event_AppDisconnected = "app:disconnected"
+	Event_AppCrashed      = "app:crashed"
+
+	// a state-changing command was run on the monitor (see AuditLogEntry)
+	Event_AuditLog = "audit:log"
+
+	// the monitor config file was reloaded, or a reload was rejected (see ConfigChangedEvent)
+	Event_ConfigChanged = "server:configchanged"
+
+	// the data directory grew past the configured max size, or went back under it (see DiskUsageWarningEvent)
+	Event_DiskUsageWarning = "server:diskusagewarning"
+
+	// a snapshot rule took a snapshot (see SnapshotInfo)
+	Event_SnapshotTaken = "server:snapshot"
+
+	// a new log line matched a log alert rule from the monitor config file (scoped by app run id, see LogAlertEvent)
+	Event_LogAlert = "app:logalert"
+
+	// the browser tabs viewing an app run (or what they focus on) changed (scoped by app run id, see AppRunPresenceData)
+	Event_AppRunPresence = "app:presence"
 )
 
 var EventToTypeMap = map[string]reflect.Type{
-	Event_RouteDown:       nil,
-	Event_RouteUp:         nil,
-	Event_AppStatusUpdate: reflect.TypeOf(StatusUpdateData{}),
+	Event_RouteDown:        nil,
+	Event_RouteUp:          nil,
+	Event_AppStatusUpdate:  reflect.TypeOf(StatusUpdateData{}),
+	Event_AppConnected:     reflect.TypeOf(AppLifecycleEvent{}),
+	Event_AppDisconnected:  reflect.TypeOf(AppLifecycleEvent{}),
+	Event_AppCrashed:       reflect.TypeOf(AppLifecycleEvent{}),
+	Event_AuditLog:         reflect.TypeOf(AuditLogEntry{}),
+	Event_ConfigChanged:    reflect.TypeOf(ConfigChangedEvent{}),
+	Event_DiskUsageWarning: reflect.TypeOf(DiskUsageWarningEvent{}),
+	Event_SnapshotTaken:    reflect.TypeOf(SnapshotInfo{}),
+	Event_AppRunPresence:   reflect.TypeOf(AppRunPresenceData{}),
+	Event_LogAlert:         reflect.TypeOf(LogAlertEvent{}),
 }
 
 type FullRpcInterface interface {
@@ -34,21 +67,44 @@ type FullRpcInterface interface {
 
 	LogSearchRequestCommand(ctx context.Context, data SearchRequestData) (SearchResultData, error)
 	LogSearchRangeCommand(ctx context.Context, data LogSearchRangeRequest) (LogSearchRangeResultData, error)
+	GlobalSearchRequestCommand(ctx context.Context, data GlobalSearchRequest) (GlobalSearchResultData, error)
 	LogWidgetAdminCommand(ctx context.Context, data LogWidgetAdminData) error
 	LogStreamUpdateCommand(ctx context.Context, data StreamUpdateData) error
+	LogTailCommand(ctx context.Context, data LogTailRequest) chan RespUnion[LogTailData]
 	LogUpdateMarkedLinesCommand(ctx context.Context, data MarkedLinesData) error
 	LogGetMarkedLinesCommand(ctx context.Context, data MarkedLinesRequestData) (MarkedLinesResultData, error)
+	LogExportMarkedLinesCommand(ctx context.Context, data MarkedLinesRequestData) (MarkedLinesExportData, error)
 
 	UpdateStatusCommand(ctx context.Context, data StatusUpdateData) error
 
 	// app run commands
 	GetAppRunsCommand(ctx context.Context, data AppRunUpdatesRequest) (AppRunsData, error)
 	GetAppRunRuntimeStatsCommand(ctx context.Context, data AppRunRequest) (AppRunRuntimeStatsData, error)
+	SubscribeRuntimeStatsCommand(ctx context.Context, data AppRunRequest) chan RespUnion[AppRunRuntimeStatsData]
+	GetAppRunPanicsCommand(ctx context.Context, data AppRunRequest) (AppRunPanicsData, error)
+	CollectorAdminCommand(ctx context.Context, data CollectorAdminRequest) error
+	GetEffectiveConfigCommand(ctx context.Context, data AppRunRequest) (EffectiveConfigData, error)
+	GetSDKOverheadCommand(ctx context.Context, data AppRunRequest) (SDKOverheadData, error)
+	RuntimeControlCommand(ctx context.Context, data RuntimeControlRequest) (RuntimeControlResponse, error)
+	CaptureCPUProfileCommand(ctx context.Context, data CaptureCPUProfileRequest) (CaptureCPUProfileResponse, error)
+	CaptureExecTraceCommand(ctx context.Context, data CaptureExecTraceRequest) (ExecTraceInfo, error)
+	GetAppRunExecTracesCommand(ctx context.Context, data AppRunRequest) (AppRunExecTracesData, error)
+	ForceGoroutineDumpCommand(ctx context.Context, data ForceGoroutineDumpRequest) (GoroutineDumpInfo, error)
+	GetAppRunGoroutineDumpsCommand(ctx context.Context, data AppRunRequest) (AppRunGoroutineDumpsData, error)
+	SetWatchValueCommand(ctx context.Context, data SetWatchValueRequest) (SetWatchValueResponse, error)
+	ExportAppRunCommand(ctx context.Context, data AppRunRequest) (ExportAppRunResponse, error)
+	GetAppRunTimelineCommand(ctx context.Context, data AppRunRequest) (AppRunTimelineData, error)
+	CompareAppRunsCommand(ctx context.Context, data CompareAppRunsRequest) (CompareAppRunsData, error)
+	BroadcastCommand(ctx context.Context, data BroadcastRequest) (BroadcastResponse, error)
 
 	// goroutine search
 	GetAppRunGoRoutinesByIdsCommand(ctx context.Context, data AppRunGoRoutinesByIdsRequest) (AppRunGoRoutinesData, error)
 	GoRoutineSearchRequestCommand(ctx context.Context, data GoRoutineSearchRequestData) (GoRoutineSearchResultData, error)
 	GoRoutineTimeSpansCommand(ctx context.Context, data GoRoutineTimeSpansRequest) (GoRoutineTimeSpansResponse, error)
+	GoRoutineStateHistogramCommand(ctx context.Context, data GoRoutineStateHistogramRequest) (GoRoutineStateHistogramResponse, error)
+	GetGoRoutineLogsCommand(ctx context.Context, data GoRoutineLogsRequest) (GoRoutineLogsData, error)
+	GetGoRoutineChurnCommand(ctx context.Context, data AppRunRequest) (GoRoutineChurnData, error)
+	GetSourcePreviewCommand(ctx context.Context, data SourcePreviewRequest) (SourcePreviewData, error)
 
 	// watch search
 	GetAppRunWatchesByIdsCommand(ctx context.Context, data AppRunWatchesByIdsRequest) (AppRunWatchesData, error)
@@ -63,9 +119,12 @@ type FullRpcInterface interface {
 
 	// tevent commands
 	SendTEventFeCommand(ctx context.Context, data TEventFeData) error
+	RecordSessionEventCommand(ctx context.Context, data SessionEvent) error
 
 	// browser tab tracking
 	UpdateBrowserTabUrlCommand(ctx context.Context, data BrowserTabUrlData) error
+	UpdateBrowserTabFocusCommand(ctx context.Context, data BrowserTabFocusData) error
+	GetAppRunPresenceCommand(ctx context.Context, data AppRunRequest) (AppRunPresenceData, error)
 
 	// update check commands
 	UpdateCheckCommand(ctx context.Context) (UpdateCheckData, error)
@@ -73,6 +132,19 @@ type FullRpcInterface interface {
 
 	// app peer management commands
 	ClearNonActiveAppRunsCommand(ctx context.Context) error
+	PruneAppRunsCommand(ctx context.Context, data PruneAppRunsRequest) (PruneAppRunsResult, error)
+	GetAuditLogCommand(ctx context.Context, data AuditLogRequest) (AuditLogData, error)
+	GetSessionTimelineCommand(ctx context.Context, data SessionTimelineRequest) (SessionTimelineData, error)
+	GetDiskUsageCommand(ctx context.Context) (DiskUsageData, error)
+	CompactAppRunStoreCommand(ctx context.Context, data CompactAppRunStoreRequest) (CompactAppRunStoreResult, error)
+
+	// scheduled snapshots
+	GetSnapshotRulesCommand(ctx context.Context) (SnapshotRulesData, error)
+	UpdateSnapshotRuleCommand(ctx context.Context, data SnapshotRule) (SnapshotRule, error)
+	DeleteSnapshotRuleCommand(ctx context.Context, data SnapshotRuleRequest) error
+	RunSnapshotRuleCommand(ctx context.Context, data SnapshotRuleRequest) (SnapshotsData, error)
+	GetSnapshotsCommand(ctx context.Context, data SnapshotsRequest) (SnapshotsData, error)
+	DeleteSnapshotCommand(ctx context.Context, data SnapshotRequest) error
 
 	// demo controller commands
 	LaunchDemoAppCommand(ctx context.Context) error
@@ -108,6 +180,16 @@ type SearchRequestData struct {
 	PageSize     int    `json:"pagesize"`
 	RequestPages []int  `json:"requestpages"`
 	Streaming    bool   `json:"streaming"`
+
+	// ContextBefore and ContextAfter add the lines before/after each match to the results (like grep -B/-A),
+	// context lines have IsContext set
+	ContextBefore int `json:"contextbefore,omitempty"`
+	ContextAfter  int `json:"contextafter,omitempty"`
+
+	// Dedup collapses identical lines (same message and source) into one entry with RepeatCount set,
+	// consecutive lines only, or identical lines less than DedupWindowMs apart if DedupWindowMs > 0
+	Dedup         bool  `json:"dedup,omitempty"`
+	DedupWindowMs int64 `json:"dedupwindowms,omitempty"`
 }
 
 type LogSearchRangeRequest struct {
@@ -118,6 +200,12 @@ type LogSearchRangeRequest struct {
 	Offset      int    `json:"offset"`
 	Limit       int    `json:"limit"`
 	Streaming   bool   `json:"streaming"`
+
+	ContextBefore int `json:"contextbefore,omitempty"` // see SearchRequestData
+	ContextAfter  int `json:"contextafter,omitempty"`
+
+	Dedup         bool  `json:"dedup,omitempty"` // see SearchRequestData
+	DedupWindowMs int64 `json:"dedupwindowms,omitempty"`
 }
 
 type PageData struct {
@@ -132,22 +220,74 @@ type SearchErrorSpan struct {
 	ErrorMessage string `json:"errormessage"` // The error message
 }
 
+// SearchMatchSpan is the part of a result that matched a search term (for highlighting)
+// offsets are in characters (runes), not bytes
+type SearchMatchSpan struct {
+	Field string `json:"field"` // the searched field ("msg" for the log message)
+	Start int    `json:"start"` // inclusive
+	End   int    `json:"end"`   // exclusive
+	Term  string `json:"term"`  // the search term that matched
+}
+
 type SearchResultData struct {
-	FilteredCount int               `json:"filteredcount"`
-	SearchedCount int               `json:"searchedcount"`
-	TotalCount    int               `json:"totalcount"`
-	MaxCount      int               `json:"maxcount"`
-	Pages         []PageData        `json:"pages"`
-	ErrorSpans    []SearchErrorSpan `json:"errorspans,omitempty"`
+	FilteredCount int                         `json:"filteredcount"`
+	SearchedCount int                         `json:"searchedcount"`
+	TotalCount    int                         `json:"totalcount"`
+	MaxCount      int                         `json:"maxcount"`
+	Pages         []PageData                  `json:"pages"`
+	ErrorSpans    []SearchErrorSpan           `json:"errorspans,omitempty"`
+	Canceled      bool                        `json:"canceled,omitempty"`   // the search was canceled, the results are partial
+	MatchSpans    map[int64][]SearchMatchSpan `json:"matchspans,omitempty"` // by line number, for the lines in Pages
 }
 
 type LogSearchRangeResultData struct {
-	FilteredCount int               `json:"filteredcount"`
-	SearchedCount int               `json:"searchedcount"`
-	TotalCount    int               `json:"totalcount"`
-	MaxCount      int               `json:"maxcount"`
-	Lines         []ds.LogLine      `json:"lines"`
-	ErrorSpans    []SearchErrorSpan `json:"errorspans,omitempty"`
+	FilteredCount int                         `json:"filteredcount"`
+	SearchedCount int                         `json:"searchedcount"`
+	TotalCount    int                         `json:"totalcount"`
+	MaxCount      int                         `json:"maxcount"`
+	Lines         []ds.LogLine                `json:"lines"`
+	ErrorSpans    []SearchErrorSpan           `json:"errorspans,omitempty"`
+	Canceled      bool                        `json:"canceled,omitempty"`   // the search was canceled, the results are partial
+	MatchSpans    map[int64][]SearchMatchSpan `json:"matchspans,omitempty"` // by line number, for the lines in Lines
+}
+
+// GlobalSearchRequest searches the logs of several app runs at once (the same search syntax as the log view).
+// If AppRunIds is empty all the local app runs are searched (or the runs of AppName if it is set).
+type GlobalSearchRequest struct {
+	SearchTerm string   `json:"searchterm"`
+	AppRunIds  []string `json:"apprunids,omitempty"`
+	AppName    string   `json:"appname,omitempty"`
+	MaxResults int      `json:"maxresults,omitempty"` // the newest matches returned over all runs (default 500)
+}
+
+// GlobalSearchLine is a matching log line and the app run it belongs to
+type GlobalSearchLine struct {
+	AppRunId   string            `json:"apprunid"`
+	AppName    string            `json:"appname"`
+	Line       ds.LogLine        `json:"line"`
+	MatchSpans []SearchMatchSpan `json:"matchspans,omitempty"`
+}
+
+// GlobalSearchRunResult is the match count of one app run (Error is set if the run couldn't be searched)
+type GlobalSearchRunResult struct {
+	AppRunId      string `json:"apprunid"`
+	AppName       string `json:"appname"`
+	Status        string `json:"status"`
+	StartTime     int64  `json:"starttime"`
+	MatchCount    int    `json:"matchcount"`
+	SearchedCount int    `json:"searchedcount"`
+	TotalCount    int    `json:"totalcount"`
+	Error         string `json:"error,omitempty"`
+}
+
+type GlobalSearchResultData struct {
+	Lines         []GlobalSearchLine      `json:"lines"` // oldest first
+	Runs          []GlobalSearchRunResult `json:"runs"`  // runs with the most matches first
+	MatchCount    int                     `json:"matchcount"`
+	SearchedCount int                     `json:"searchedcount"`
+	Truncated     bool                    `json:"truncated,omitempty"` // more than MaxResults lines matched, only the newest are in Lines
+	ErrorSpans    []SearchErrorSpan       `json:"errorspans,omitempty"`
+	Canceled      bool                    `json:"canceled,omitempty"` // the search was canceled, the results are partial
 }
 
 type StreamUpdateData struct {
@@ -160,6 +300,37 @@ type StreamUpdateData struct {
 	Lines         []ds.LogLine `json:"lines"`
 }
 
+// LogTailRequest tails the new log lines matching a standing search for a widget (see LogTailCommand).
+// The stream ends when the widget is dropped (LogWidgetAdminCommand drop, or no keepalive for a minute),
+// when a new tail is started for the widget, or when the request is canceled (or reaches its timeout, the
+// stream has no deadline if the request doesn't set one).
+type LogTailRequest struct {
+	WidgetId    string `json:"widgetid"`
+	AppRunId    string `json:"apprunid"`
+	SearchTerm  string `json:"searchterm"`
+	SystemQuery string `json:"systemquery,omitempty"`
+	Backfill    int    `json:"backfill,omitempty"` // number of already matching lines to send first (the newest ones)
+
+	ContextBefore int   `json:"contextbefore,omitempty"`
+	ContextAfter  int   `json:"contextafter,omitempty"`
+	Dedup         bool  `json:"dedup,omitempty"` // see SearchRequestData
+	DedupWindowMs int64 `json:"dedupwindowms,omitempty"`
+}
+
+// LogTailData is one update of a log tail stream, the first update has the backfilled lines (and any search errors)
+type LogTailData struct {
+	WidgetId      string                      `json:"widgetid"`
+	FilteredCount int                         `json:"filteredcount"`
+	SearchedCount int                         `json:"searchedcount"`
+	TotalCount    int                         `json:"totalcount"`
+	TrimmedLines  int                         `json:"trimmedlines"`
+	Offset        int                         `json:"offset"` // offset of Lines[0] in the search results (including trimmed lines)
+	Lines         []ds.LogLine                `json:"lines"`
+	DroppedLines  int                         `json:"droppedlines,omitempty"` // matching lines skipped since the last update because the client fell behind
+	ErrorSpans    []SearchErrorSpan           `json:"errorspans,omitempty"`
+	MatchSpans    map[int64][]SearchMatchSpan `json:"matchspans,omitempty"`
+}
+
 type DropRequestData struct {
 	WidgetId string `json:"widgetid"`
 }
@@ -170,10 +341,28 @@ type LogWidgetAdminData struct {
 	KeepAlive bool   `json:"keepalive,omitempty"`
 }
 
+// LineMark holds the optional annotation and color of a marked line
+type LineMark struct {
+	Annotation string `json:"annotation,omitempty"`
+	Color      string `json:"color,omitempty"`
+}
+
+// MarkedRange marks (or unmarks) the inclusive range of line numbers [Start, End].
+// Color is set on every line in the range, Annotation is attached to the first line.
+type MarkedRange struct {
+	Start      int64  `json:"start"`
+	End        int64  `json:"end"`
+	Unmark     bool   `json:"unmark,omitempty"`
+	Annotation string `json:"annotation,omitempty"`
+	Color      string `json:"color,omitempty"`
+}
+
 // MarkedLinesData represents the data for managing marked lines
+// (marks are stored per app run, they are shared by all the log widgets of the app run)
 type MarkedLinesData struct {
 	WidgetId    string          `json:"widgetid"`
 	MarkedLines map[string]bool `json:"markedlines"`
+	Ranges      []MarkedRange   `json:"ranges,omitempty"` // applied after MarkedLines
 	Clear       bool            `json:"clear,omitempty"`
 }
 
@@ -184,7 +373,23 @@ type MarkedLinesRequestData struct {
 
 // MarkedLinesResultData represents the response with marked log lines
 type MarkedLinesResultData struct {
-	Lines []ds.LogLine `json:"lines"`
+	Lines []ds.LogLine        `json:"lines"`
+	Marks map[string]LineMark `json:"marks,omitempty"` // line number -> mark, for every marked line (including lines no longer in the buffer)
+}
+
+type MarkedLineExport struct {
+	Line       ds.LogLine `json:"line"`
+	Annotation string     `json:"annotation,omitempty"`
+	Color      string     `json:"color,omitempty"`
+}
+
+// MarkedLinesExportData is a shareable JSON bundle of the marked (and annotated) lines of an app run
+type MarkedLinesExportData struct {
+	Version  int                `json:"version"`
+	AppRunId string             `json:"apprunid"`
+	AppName  string             `json:"appname"`
+	ExportTs int64              `json:"exportts"`
+	Lines    []MarkedLineExport `json:"lines"`
 }
 
 type StatusUpdateData struct {
@@ -204,23 +409,30 @@ type BuildInfoData struct {
 
 // App run data types
 type AppRunInfo struct {
-	AppRunId                   string         `json:"apprunid"`
-	AppName                    string         `json:"appname"`
-	StartTime                  int64          `json:"starttime"`
-	FirstGoRoutineCollectionTs int64          `json:"firstgoroutinecollectionts,omitempty"`
-	IsRunning                  bool           `json:"isrunning"`
-	Status                     string         `json:"status"`
-	NumLogs                    int            `json:"numlogs"`
-	NumTotalGoRoutines         int            `json:"numtotalgoroutines"`
-	NumActiveGoRoutines        int            `json:"numactivegoroutines"`
-	NumOutrigGoRoutines        int            `json:"numoutriggoroutines"`
-	NumActiveWatches           int            `json:"numactivewatches"`
-	NumTotalWatches            int            `json:"numtotalwatches"`
-	LastModTime                int64          `json:"lastmodtime"`
-	BuildInfo                  *BuildInfoData `json:"buildinfo,omitempty"`
-	ModuleName                 string         `json:"modulename,omitempty"`
-	Executable                 string         `json:"executable,omitempty"`
-	OutrigSDKVersion           string         `json:"outrigsdkversion,omitempty"`
+	AppRunId                   string             `json:"apprunid"`
+	AppName                    string             `json:"appname"`
+	StartTime                  int64              `json:"starttime"`
+	FirstGoRoutineCollectionTs int64              `json:"firstgoroutinecollectionts,omitempty"`
+	IsRunning                  bool               `json:"isrunning"`
+	Status                     string             `json:"status"`
+	NumLogs                    int                `json:"numlogs"`
+	NumTotalGoRoutines         int                `json:"numtotalgoroutines"`
+	NumActiveGoRoutines        int                `json:"numactivegoroutines"`
+	NumOutrigGoRoutines        int                `json:"numoutriggoroutines"`
+	NumActiveWatches           int                `json:"numactivewatches"`
+	NumTotalWatches            int                `json:"numtotalwatches"`
+	NumPanics                  int                `json:"numpanics,omitempty"`
+	LastModTime                int64              `json:"lastmodtime"`
+	BuildInfo                  *BuildInfoData     `json:"buildinfo,omitempty"`
+	ModuleName                 string             `json:"modulename,omitempty"`
+	Executable                 string             `json:"executable,omitempty"`
+	OutrigSDKVersion           string             `json:"outrigsdkversion,omitempty"`
+	Meta                       map[string]string  `json:"meta,omitempty"`           // set with outrig.SetAppMeta (plus hostname/region/gitsha defaults)
+	Imported                   bool               `json:"imported,omitempty"`       // imported from a bundle with "outrig import" (read-only)
+	TransportStats             *ds.TransportStats `json:"transportstats,omitempty"` // packets the SDK dropped because the monitor couldn't keep up
+	Monitor                    string             `json:"monitor,omitempty"`        // the downstream monitor the app run is proxied from (read-only), empty for local app runs
+	ClockSkewMs                int64              `json:"clockskewms,omitempty"`    // the app's clock minus the monitor's clock, the app run's timestamps are corrected by it
+	NumResyncs                 int                `json:"numresyncs,omitempty"`     // full goroutine/watch updates requested after inconsistent delta updates
 }
 
 type AppRunsData struct {
@@ -228,7 +440,8 @@ type AppRunsData struct {
 }
 
 type AppRunUpdatesRequest struct {
-	Since int64 `json:"since"`
+	Since int64             `json:"since"`
+	Meta  map[string]string `json:"meta,omitempty"` // only return app runs with this metadata (an empty value matches any value)
 }
 
 type AppRunRequest struct {
@@ -243,6 +456,31 @@ type AppRunGoRoutinesByIdsRequest struct {
 	Timestamp int64   `json:"timestamp"` // if 0, get latest stack; otherwise get stack at this timestamp
 }
 
+// SourcePreviewRequest requests the source around the line of a stack frame from the app's own module
+type SourcePreviewRequest struct {
+	AppRunId     string     `json:"apprunid"`
+	Frame        StackFrame `json:"frame"`
+	ContextLines int        `json:"contextlines,omitempty"` // lines before and after the frame's line (default 5, max 50)
+}
+
+// Source preview sources (see SourcePreviewData.Source)
+const (
+	SourcePreviewSourceFile = "file" // read from the project directory (or the frame's file path)
+	SourcePreviewSourceGit  = "git"  // read from the build's revision in the project's git checkout
+)
+
+// SourcePreviewData is a snippet of a source file around the line of a stack frame
+type SourcePreviewData struct {
+	FilePath   string   `json:"filepath"` // the file the snippet was read from
+	RelPath    string   `json:"relpath,omitempty"`
+	Source     string   `json:"source"`             // SourcePreviewSourceFile or SourcePreviewSourceGit
+	Revision   string   `json:"revision,omitempty"` // the git revision the snippet was read from (git source)
+	LineNumber int      `json:"linenumber"`         // the frame's line
+	StartLine  int      `json:"startline"`          // line number of Lines[0]
+	Lines      []string `json:"lines"`
+	Modified   bool     `json:"modified,omitempty"` // the file changed after the app started, it may not match the running code
+}
+
 // AppRunWatchesByIdsRequest defines the request for getting specific watches by their IDs
 type AppRunWatchesByIdsRequest struct {
 	AppRunId string  `json:"apprunid"`
@@ -278,6 +516,12 @@ type RuntimeStatData struct {
 	Pid            int                `json:"pid"`
 	Cwd            string             `json:"cwd"`
 	MemStats       ds.MemoryStatsInfo `json:"memstats"`
+	FDStats        *ds.FDStatsInfo    `json:"fdstats,omitempty"`
+	SchedStats     *ds.SchedStatsInfo `json:"schedstats,omitempty"`
+
+	// rates since the previous sample (not set for the first sample)
+	AllocRate float64 `json:"allocrate,omitempty"` // bytes allocated per second
+	GCRate    float64 `json:"gcrate,omitempty"`    // GC cycles per second
 }
 
 type AppRunRuntimeStatsData struct {
@@ -289,6 +533,240 @@ type AppRunRuntimeStatsData struct {
 	Stats               []RuntimeStatData `json:"stats"`
 }
 
+// PanicData represents a panic captured from a goroutine (kept even after the goroutine has exited)
+type PanicData struct {
+	GoId           int64        `json:"goid"`
+	Ts             int64        `json:"ts"`
+	Name           string       `json:"name,omitempty"`
+	Tags           []string     `json:"tags,omitempty"`
+	PanicVal       string       `json:"panicval"`
+	PanicType      string       `json:"panictype"`
+	Recovered      bool         `json:"recovered,omitempty"`
+	RawStackTrace  string       `json:"rawstacktrace"`
+	ParsedFrames   []StackFrame `json:"parsedframes,omitempty"`
+	CreatedByGoId  int64        `json:"createdbygoid,omitempty"`
+	CreatedByFrame *StackFrame  `json:"createdbyframe,omitempty"`
+}
+
+// CollectorAdminRequest turns a collector on/off or changes its settings on a running app (forwarded to the SDK)
+type CollectorAdminRequest struct {
+	AppRunId  string         `json:"apprunid"`
+	Collector string         `json:"collector"`
+	Action    string         `json:"action,omitempty"`   // "enable", "disable", or empty to only apply settings
+	Settings  map[string]any `json:"settings,omitempty"` // e.g. {"pollintervalms": 500}
+}
+
+// BroadcastRequest runs a command against every route matching Route (e.g. "app:myservice:*").
+// For app run routes ("app:<appname>:<apprunid>") the command runs on the server with the app run id
+// set in Data (so Data must be an object), other routes receive the command directly.
+type BroadcastRequest struct {
+	Route   string `json:"route"` // route id or pattern ("*" matches one ":" separated part, a trailing "**" matches the rest)
+	Command string `json:"command"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// BroadcastRouteResult is the result of a broadcast command on one route
+type BroadcastRouteResult struct {
+	Route    string `json:"route"`
+	AppRunId string `json:"apprunid,omitempty"` // only for app run routes
+	Data     any    `json:"data,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type BroadcastResponse struct {
+	Results []BroadcastRouteResult `json:"results"` // sorted by route
+}
+
+// RuntimeControlRequest runs a runtime command (gc, freeosmemory, heapdump, setgcpercent) in a running app (forwarded to the SDK)
+type RuntimeControlRequest struct {
+	AppRunId  string `json:"apprunid"`
+	Action    string `json:"action"`
+	Path      string `json:"path,omitempty"`      // heapdump only, a path on the app's machine (must not exist yet)
+	GCPercent int    `json:"gcpercent,omitempty"` // setgcpercent only, negative turns the GC off
+}
+
+// RuntimeControlResponse is the result of RuntimeControlCommand as reported by the SDK
+type RuntimeControlResponse struct {
+	AppRunId    string                  `json:"apprunid"`
+	TriggeredBy string                  `json:"triggeredby"` // source route of the request (written to the audit logs)
+	Result      ds.RuntimeControlResult `json:"result"`
+}
+
+// SetWatchValueRequest sets the value of a settable watch in a running app (forwarded to the SDK,
+// which calls the setter registered with Watch.Settable). Value is JSON for the setter's argument type.
+type SetWatchValueRequest struct {
+	AppRunId string `json:"apprunid"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+}
+
+// SetWatchValueResponse is the result of SetWatchValueCommand as reported by the SDK
+type SetWatchValueResponse struct {
+	AppRunId    string `json:"apprunid"`
+	Name        string `json:"name"`
+	TriggeredBy string `json:"triggeredby"` // source route of the request (written to the audit logs)
+}
+
+// CaptureCPUProfileRequest captures a CPU profile of a running app for DurationSec seconds (default 10).
+// The rpc timeout must be longer than the profile duration.
+type CaptureCPUProfileRequest struct {
+	AppRunId    string `json:"apprunid"`
+	DurationSec int    `json:"durationsec,omitempty"`
+}
+
+// CaptureCPUProfileResponse describes a captured CPU profile, the profile itself (pprof format) is
+// downloaded from DownloadUrl (e.g. "go tool pprof -http=: <monitor-url><downloadurl>" for a flamegraph)
+type CaptureCPUProfileResponse struct {
+	AppRunId    string `json:"apprunid"`
+	ProfileId   string `json:"profileid"`
+	Ts          int64  `json:"ts"`
+	DurationMs  int64  `json:"durationms"`
+	Size        int    `json:"size"`
+	TriggeredBy string `json:"triggeredby"`
+	DownloadUrl string `json:"downloadurl"` // path on the monitor's web server
+}
+
+// CaptureExecTraceRequest captures an execution trace (runtime/trace) of a running app for DurationSec seconds
+// (default 5). The rpc timeout must be longer than the trace duration.
+type CaptureExecTraceRequest struct {
+	AppRunId    string `json:"apprunid"`
+	DurationSec int    `json:"durationsec,omitempty"`
+}
+
+// ExecTraceInfo describes a captured execution trace, the trace itself is downloaded from DownloadUrl and
+// opened with "go tool trace <file>"
+type ExecTraceInfo struct {
+	AppRunId    string `json:"apprunid"`
+	TraceId     string `json:"traceid"`
+	Ts          int64  `json:"ts"`
+	DurationMs  int64  `json:"durationms"`
+	Size        int    `json:"size"`
+	Truncated   bool   `json:"truncated,omitempty"` // stopped early because the trace reached the size limit
+	TriggeredBy string `json:"triggeredby"`
+	DownloadUrl string `json:"downloadurl"` // path on the monitor's web server
+}
+
+type AppRunExecTracesData struct {
+	AppRunId string          `json:"apprunid"`
+	Traces   []ExecTraceInfo `json:"traces"` // oldest first
+}
+
+// ForceGoroutineDumpRequest takes a full goroutine dump of a running app right away (outside the regular poll),
+// the dump is pinned on the goroutine timeline
+type ForceGoroutineDumpRequest struct {
+	AppRunId string `json:"apprunid"`
+	Label    string `json:"label,omitempty"` // default "manual snapshot"
+}
+
+// GoroutineDumpInfo describes a goroutine dump taken on demand, the dump is downloaded (as text) from DownloadUrl
+type GoroutineDumpInfo struct {
+	AppRunId      string `json:"apprunid"`
+	DumpId        string `json:"dumpid"`
+	Ts            int64  `json:"ts"`
+	Label         string `json:"label"`
+	NumGoRoutines int    `json:"numgoroutines"`
+	TriggeredBy   string `json:"triggeredby"`
+	DownloadUrl   string `json:"downloadurl"` // path on the monitor's web server
+}
+
+type AppRunGoroutineDumpsData struct {
+	AppRunId string              `json:"apprunid"`
+	Dumps    []GoroutineDumpInfo `json:"dumps"` // oldest first
+}
+
+// ExportAppRunResponse describes an exported app run bundle (logs, goroutine history, watches, runtime stats,
+// and panics). The bundle is downloaded from DownloadUrl and can be loaded into another monitor with "outrig import".
+type ExportAppRunResponse struct {
+	AppRunId            string `json:"apprunid"`
+	ExportId            string `json:"exportid"`
+	FileName            string `json:"filename"`
+	Size                int    `json:"size"`
+	NumLogs             int    `json:"numlogs"`
+	NumGoRoutineSamples int    `json:"numgoroutinesamples"`
+	NumWatchSamples     int    `json:"numwatchsamples"`
+	NumRuntimeStats     int    `json:"numruntimestats"`
+	NumPanics           int    `json:"numpanics"`
+	DownloadUrl         string `json:"downloadurl"` // path on the monitor's web server
+}
+
+// AppLifecycleEvent is an entry in an app run's lifecycle timeline (also the data for the app:* lifecycle events)
+type AppLifecycleEvent struct {
+	AppRunId string `json:"apprunid"`
+	Event    string `json:"event"` // app:connected, app:disconnected, or app:crashed
+	Ts       int64  `json:"ts"`
+	Status   string `json:"status"`             // app run status after the event
+	ExitCode *int   `json:"exitcode,omitempty"` // only for app:crashed
+	Signal   string `json:"signal,omitempty"`   // only for app:crashed (if the process was killed by a signal)
+}
+
+type AppRunTimelineData struct {
+	AppRunId string              `json:"apprunid"`
+	AppName  string              `json:"appname"`
+	Events   []AppLifecycleEvent `json:"events"`
+}
+
+// CompareAppRunsRequest selects the runs of one app to compare
+// if AppRunIds is empty, all the (retained) runs with AppName are compared
+type CompareAppRunsRequest struct {
+	AppName    string   `json:"appname"`
+	AppRunIds  []string `json:"apprunids,omitempty"`
+	IntervalMs int64    `json:"intervalms,omitempty"` // size of the alignment buckets (default 10s)
+}
+
+// AppRunCompareSample is the last runtime stats sample in a bucket, aligned by the time since the run started
+type AppRunCompareSample struct {
+	OffsetMs       int64  `json:"offsetms"`
+	GoRoutineCount int    `json:"goroutinecount"`
+	HeapAlloc      uint64 `json:"heapalloc"`
+	Sys            uint64 `json:"sys"`
+	NumGC          uint32 `json:"numgc"`
+}
+
+type AppRunComparison struct {
+	AppRunId          string                `json:"apprunid"`
+	StartTime         int64                 `json:"starttime"`
+	Status            string                `json:"status"`
+	BuildInfo         *BuildInfoData        `json:"buildinfo,omitempty"`
+	Samples           []AppRunCompareSample `json:"samples"`
+	MaxGoRoutineCount int                   `json:"maxgoroutinecount"`
+	MaxHeapAlloc      uint64                `json:"maxheapalloc"`
+	GoRoutineSlope    float64               `json:"goroutineslope"` // goroutines per minute (least squares fit over all samples)
+	HeapAllocSlope    float64               `json:"heapallocslope"` // bytes per minute
+}
+
+type CompareAppRunsData struct {
+	AppName    string             `json:"appname"`
+	IntervalMs int64              `json:"intervalms"`
+	Runs       []AppRunComparison `json:"runs"` // oldest run first
+}
+
+type AppRunPanicsData struct {
+	AppRunId string      `json:"apprunid"`
+	AppName  string      `json:"appname"`
+	Panics   []PanicData `json:"panics"`
+}
+
+// GoRoutineChurnSite is the churn of the goroutines started (with the SDK's Run() func) from one call site,
+// it includes goroutines that started and finished between two stack dumps (which never show up in the stacks)
+type GoRoutineChurnSite struct {
+	CallSite      string  `json:"callsite"`       // file:line of the Run() call
+	Func          string  `json:"func,omitempty"` // function that contains the call site
+	Name          string  `json:"name,omitempty"` // name of the last goroutine started from the call site
+	Started       int64   `json:"started"`
+	Finished      int64   `json:"finished"`
+	ShortLived    int64   `json:"shortlived"`             // finished goroutines that never appeared in a stack dump
+	AvgRunTimeMs  float64 `json:"avgruntimems,omitempty"` // average run time of the finished goroutines
+	StartRate     float64 `json:"startrate"`              // goroutines started per second (over the last 10 seconds)
+	PeakStartRate float64 `json:"peakstartrate"`          // highest start rate of a single report interval
+	LastTs        int64   `json:"lastts"`                 // last report with goroutines started/finished from the call site
+}
+
+type GoRoutineChurnData struct {
+	AppRunId string               `json:"apprunid"`
+	AppName  string               `json:"appname"`
+	Sites    []GoRoutineChurnSite `json:"sites"` // highest start rate first
+}
+
 // GoRoutineSearchRequestData defines the request for goroutine search
 type GoRoutineSearchRequestData struct {
 	AppRunId    string `json:"apprunid"`
@@ -297,6 +775,7 @@ type GoRoutineSearchRequestData struct {
 	Timestamp   int64  `json:"timestamp,omitempty"` // Timestamp in milliseconds, 0 means use latest
 	ShowOutrig  bool   `json:"showoutrig"`          // Whether to include outrig-tagged goroutines in state counts
 	ActiveOnly  bool   `json:"activeonly"`          // Whether to filter to only active goroutines at the timestamp
+	Aggregate   bool   `json:"aggregate,omitempty"` // Group the results by creation site (Results holds one representative goroutine per group)
 }
 
 // GoRoutineSearchResultData defines the response for goroutine search
@@ -308,6 +787,297 @@ type GoRoutineSearchResultData struct {
 	Results                  []int64           `json:"results"`
 	ErrorSpans               []SearchErrorSpan `json:"errorspans,omitempty"`     // Error spans in the search query
 	EffectiveSearchTimestamp int64             `json:"effectivesearchtimestamp"` // The actual timestamp used for the search
+	Groups                   []GoRoutineGroup  `json:"groups,omitempty"`         // Only set for aggregate searches, largest group first
+}
+
+// PruneAppRunsRequest overrides the server's retention settings for a single prune (0 uses the server setting)
+type PruneAppRunsRequest struct {
+	MaxRunsPerApp int   `json:"maxrunsperapp,omitempty"`
+	MaxAgeMs      int64 `json:"maxagems,omitempty"`
+	DryRun        bool  `json:"dryrun,omitempty"` // only report the app runs that would be pruned
+}
+
+// AuditLogEntry records a state-changing command run on the monitor (clearing app runs, collector admin,
+// runtime control, demo app, marked lines changes)
+type AuditLogEntry struct {
+	Id       int64  `json:"id"` // increasing entry number (use with AuditLogRequest.SinceId)
+	Ts       int64  `json:"ts"`
+	Source   string `json:"source"`  // rpc route (or "web:<remote addr>") that ran the command
+	Command  string `json:"command"` // e.g. "CollectorAdminCommand"
+	AppRunId string `json:"apprunid,omitempty"`
+	Params   any    `json:"params,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ConfigChangedEvent is published when the monitor config file changes
+type ConfigChangedEvent struct {
+	Ts              int64    `json:"ts"`
+	Path            string   `json:"path"`
+	Changed         []string `json:"changed,omitempty"`         // settings that were applied
+	RestartRequired []string `json:"restartrequired,omitempty"` // settings that changed but only take effect after a restart
+	Error           string   `json:"error,omitempty"`           // the reload was rejected, the previous config is still in effect
+}
+
+// AuditLogRequest queries the audit log, all filters are optional
+type AuditLogRequest struct {
+	SinceId  int64  `json:"sinceid,omitempty"` // only entries with an id greater than SinceId
+	Command  string `json:"command,omitempty"`
+	Source   string `json:"source,omitempty"`
+	AppRunId string `json:"apprunid,omitempty"`
+	Limit    int    `json:"limit,omitempty"` // newest Limit entries (0 for all)
+}
+
+// AuditLogData is the result of GetAuditLogCommand (entries are oldest first)
+type AuditLogData struct {
+	Entries    []AuditLogEntry `json:"entries"`
+	TotalCount int64           `json:"totalcount"` // number of entries recorded since the monitor started
+}
+
+// PrunedAppRun is an app run that was removed by PruneAppRunsCommand
+type PrunedAppRun struct {
+	AppRunId    string `json:"apprunid"`
+	AppName     string `json:"appname"`
+	StartTime   int64  `json:"starttime"`
+	LastModTime int64  `json:"lastmodtime"`
+	Reason      string `json:"reason"` // maxage, maxrunsperapp, or maxappruns
+}
+
+// PruneAppRunsResult reports what PruneAppRunsCommand removed (oldest first)
+type PruneAppRunsResult struct {
+	Pruned []PrunedAppRun `json:"pruned"`
+	DryRun bool           `json:"dryrun,omitempty"`
+}
+
+// AppRunStoreUsage is the footprint of one of the stores of an app run (logs, goroutines, watches, ...).
+// MemBytes is an estimate of the retained data (not including Go's bookkeeping).
+type AppRunStoreUsage struct {
+	Store      string `json:"store"`
+	Items      int    `json:"items"`
+	MemBytes   int64  `json:"membytes"`
+	DiskBytes  int64  `json:"diskbytes,omitempty"`
+	CanCompact bool   `json:"cancompact,omitempty"`
+	CanDrop    bool   `json:"candrop,omitempty"`
+}
+
+// AppRunDiskUsage is the footprint of an app run (the totals of its stores)
+type AppRunDiskUsage struct {
+	AppRunId  string             `json:"apprunid"`
+	AppName   string             `json:"appname"`
+	Status    string             `json:"status"`
+	IsRunning bool               `json:"isrunning"`
+	MemBytes  int64              `json:"membytes"`
+	DiskBytes int64              `json:"diskbytes"`
+	Stores    []AppRunStoreUsage `json:"stores"`
+}
+
+// DiskUsageData is the result of GetDiskUsageCommand (app runs are sorted by total size, largest first)
+type DiskUsageData struct {
+	DataDir           string            `json:"datadir"`
+	DataDirBytes      int64             `json:"datadirbytes"`
+	LogBufferBytes    int64             `json:"logbufferbytes"`
+	SnapshotsBytes    int64             `json:"snapshotsbytes,omitempty"` // scheduled snapshots (see SnapshotRule)
+	TEventsBytes      int64             `json:"teventsbytes"`
+	PacketRecordDir   string            `json:"packetrecorddir,omitempty"`
+	PacketRecordBytes int64             `json:"packetrecordbytes,omitempty"`
+	FreeBytes         int64             `json:"freebytes,omitempty"`
+	TotalBytes        int64             `json:"totalbytes,omitempty"`
+	MaxDataDirBytes   int64             `json:"maxdatadirbytes,omitempty"` // 0 if no max size is configured
+	Warning           string            `json:"warning,omitempty"`         // set when the data directory is over the max size
+	AppRuns           []AppRunDiskUsage `json:"appruns"`
+}
+
+// SDKEffectiveConfig is the config an app run's SDK resolved at startup (defaults, then the config file or
+// the config passed to outrig.Init, then environment overrides)
+type SDKEffectiveConfig struct {
+	SDKVersion   string                        `json:"sdkversion,omitempty"`
+	Source       string                        `json:"source"` // see ds.ConfigInfo
+	Config       config.Config                 `json:"config"`
+	Env          map[string]string             `json:"env,omitempty"` // the OUTRIG_* environment variables of the app
+	Capabilities *ds.SDKCapabilities           `json:"capabilities,omitempty"`
+	Collectors   map[string]ds.CollectorStatus `json:"collectors,omitempty"` // last reported collector statuses
+}
+
+// MonitorEffectiveConfig is the config the monitor is running with (command line flags overridden by the
+// monitor config file)
+type MonitorEffectiveConfig struct {
+	Version             string            `json:"version"`
+	Dev                 bool              `json:"dev,omitempty"`
+	OutrigHome          string            `json:"outrighome"`
+	DataDir             string            `json:"datadir"`
+	ConfigFile          string            `json:"configfile"`
+	ConfigFileExists    bool              `json:"configfileexists"`
+	ConfigFileSettings  []string          `json:"configfilesettings,omitempty"` // settings set in the config file
+	ConfigFileError     string            `json:"configfileerror,omitempty"`    // the last reload was rejected, the previous config is still in effect
+	RemoteListen        string            `json:"remotelisten,omitempty"`       // address of the running remote SDK listener
+	LogBufferSizeMB     int               `json:"logbuffersizemb"`
+	MaxRunsPerApp       int               `json:"maxrunsperapp"`
+	MaxRunAge           string            `json:"maxrunage"` // Go duration ("0s" for no limit)
+	MaxDataDirSizeMB    int               `json:"maxdatadirsizemb"`
+	EmbedAllowedOrigins []string          `json:"embedallowedorigins,omitempty"`
+	Downstreams         map[string]string `json:"downstreams,omitempty"` // name => host:port (without the api key)
+	SessionTimeline     bool              `json:"sessiontimeline,omitempty"`
+	LogSearchIndex      bool              `json:"logsearchindex,omitempty"`
+	LogTails            []LogTailStatus   `json:"logtails,omitempty"`
+	AlertRules          []string          `json:"alertrules,omitempty"` // names of the log alert rules
+}
+
+// LogTailStatus is the state of a log file or journald unit the monitor follows (see the logtail package)
+type LogTailStatus struct {
+	File       string `json:"file,omitempty"`
+	Journald   string `json:"journald,omitempty"`
+	AppName    string `json:"appname,omitempty"`
+	Source     string `json:"source"`
+	Running    bool   `json:"running"`
+	Error      string `json:"error,omitempty"`      // last error (e.g. the file doesn't exist yet), cleared when lines are read again
+	NumLines   int64  `json:"numlines"`             // lines added to app runs
+	NumDropped int64  `json:"numdropped,omitempty"` // lines that didn't match a running app run
+	LastLineTs int64  `json:"lastlinets,omitempty"`
+}
+
+// EffectiveConfigData is the result of GetEffectiveConfigCommand, SDK is nil when no app run was requested
+// or the app run's SDK didn't report its config (SDKError says why)
+type EffectiveConfigData struct {
+	AppRunId string                 `json:"apprunid,omitempty"`
+	AppName  string                 `json:"appname,omitempty"`
+	SDK      *SDKEffectiveConfig    `json:"sdk,omitempty"`
+	SDKError string                 `json:"sdkerror,omitempty"`
+	Monitor  MonitorEffectiveConfig `json:"monitor"`
+}
+
+// CollectorOverheadInfo is the average cost of one collector's collections (from its ds.CollectorOverhead)
+type CollectorOverheadInfo struct {
+	Name           string                `json:"name"`
+	Running        bool                  `json:"running"`
+	Overhead       *ds.CollectorOverhead `json:"overhead"`
+	AvgCpuUs       float64               `json:"avgcpuus"`       // CPU time per collection in microseconds
+	AvgAllocBytes  float64               `json:"avgallocbytes"`  // bytes allocated per collection
+	CpuPercent     float64               `json:"cpupercent"`     // share of one CPU used at the collector's interval
+	AllocBytesRate float64               `json:"allocbytesrate"` // bytes allocated per second at the collector's interval
+}
+
+// SDKOverheadData is the result of GetSDKOverheadCommand, the totals add up the collectors (sorted by CPU, highest first)
+type SDKOverheadData struct {
+	AppRunId            string                  `json:"apprunid"`
+	AppName             string                  `json:"appname"`
+	CpuMeasured         bool                    `json:"cpumeasured"` // false if the app's platform can't measure CPU time (or its SDK doesn't report overhead)
+	Collectors          []CollectorOverheadInfo `json:"collectors"`
+	TotalCpuPercent     float64                 `json:"totalcpupercent"`
+	TotalAllocBytesRate float64                 `json:"totalallocbytesrate"`
+}
+
+// snapshot rule kinds
+const (
+	SnapshotKind_GoRoutines = "goroutines" // a goroutine dump (runtime.Stack format) of the latest goroutine sample
+	SnapshotKind_Logs       = "logs"       // the log lines matching SearchTerm since the rule's previous run
+)
+
+// SnapshotRule is a scheduled snapshot, rules are stored by the monitor and run for each running app run
+// of AppName (every running app run if AppName is empty). The snapshots are kept in the data directory, so
+// they outlive their app runs.
+type SnapshotRule struct {
+	RuleId     string `json:"ruleid"` // assigned by the monitor (empty to create a rule)
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`                 // SnapshotKind_GoRoutines or SnapshotKind_Logs
+	Schedule   string `json:"schedule"`             // "@every 10m", "@hourly", "@daily", "@weekly", or a cron expression ("0 3 * * 1-5")
+	AppName    string `json:"appname,omitempty"`    // "" for every app
+	SearchTerm string `json:"searchterm,omitempty"` // logs only, log viewer search syntax ("" exports every line)
+	Disabled   bool   `json:"disabled,omitempty"`
+	CreatedTs  int64  `json:"createdts,omitempty"` // set by the monitor
+	LastRunTs  int64  `json:"lastrunts,omitempty"` // set by the monitor
+	NextRunTs  int64  `json:"nextrunts,omitempty"` // set by the monitor (0 if disabled)
+	LastError  string `json:"lasterror,omitempty"` // set by the monitor, the error of the last run
+}
+
+type SnapshotRulesData struct {
+	Rules []SnapshotRule `json:"rules"`
+}
+
+type SnapshotRuleRequest struct {
+	RuleId string `json:"ruleid"`
+}
+
+// SnapshotInfo describes a snapshot taken by a SnapshotRule
+type SnapshotInfo struct {
+	SnapshotId  string `json:"snapshotid"`
+	RuleId      string `json:"ruleid"`
+	RuleName    string `json:"rulename"`
+	Kind        string `json:"kind"`
+	AppRunId    string `json:"apprunid"`
+	AppName     string `json:"appname"`
+	Ts          int64  `json:"ts"`
+	NumItems    int    `json:"numitems"` // goroutines or log lines
+	Size        int64  `json:"size"`
+	FileName    string `json:"filename"`
+	DownloadUrl string `json:"downloadurl"`
+}
+
+// SnapshotsRequest filters the snapshot list (empty fields match every snapshot)
+type SnapshotsRequest struct {
+	RuleId   string `json:"ruleid,omitempty"`
+	AppRunId string `json:"apprunid,omitempty"`
+	Limit    int    `json:"limit,omitempty"` // the newest Limit snapshots (0 for all)
+}
+
+// SnapshotsData holds snapshots, newest first
+type SnapshotsData struct {
+	Snapshots []SnapshotInfo `json:"snapshots"`
+}
+
+type SnapshotRequest struct {
+	SnapshotId string `json:"snapshotid"`
+}
+
+// CompactAppRunStoreRequest compacts (or with Drop, clears) one of the stores of an app run.
+// Compacting removes data that is no longer live (inactive goroutines, unregistered watches, old log segments),
+// dropping removes all of the store's data. AppRunStoreUsage reports which of the two a store supports.
+type CompactAppRunStoreRequest struct {
+	AppRunId string `json:"apprunid"`
+	Store    string `json:"store"`
+	Drop     bool   `json:"drop,omitempty"`
+}
+
+// CompactAppRunStoreResult reports what CompactAppRunStoreCommand removed
+type CompactAppRunStoreResult struct {
+	AppRunId       string `json:"apprunid"`
+	Store          string `json:"store"`
+	ItemsRemoved   int    `json:"itemsremoved"`
+	MemBytesFreed  int64  `json:"membytesfreed"`
+	DiskBytesFreed int64  `json:"diskbytesfreed"`
+}
+
+// DiskUsageWarningEvent is published when the data directory grows past the configured max size (Warning is set)
+// and when it goes back under the max size (Warning is empty)
+type DiskUsageWarningEvent struct {
+	Ts              int64  `json:"ts"`
+	DataDirBytes    int64  `json:"datadirbytes"`
+	MaxDataDirBytes int64  `json:"maxdatadirbytes"`
+	Warning         string `json:"warning,omitempty"`
+}
+
+// LogAlertEvent is published when a log line matches a log alert rule (see serverbase.AlertRule)
+type LogAlertEvent struct {
+	Ts       int64  `json:"ts"`
+	AppRunId string `json:"apprunid"`
+	AppName  string `json:"appname"`
+	RuleName string `json:"rulename"`
+	Search   string `json:"search"`
+	LineNum  int64  `json:"linenum"`
+	Msg      string `json:"msg"`
+}
+
+// GoRoutineGroup is a set of goroutines started by the same function from the same call site
+// (or the workers of a worker pool)
+type GoRoutineGroup struct {
+	Key              string         `json:"key"`                      // see apppeer.GroupGoRoutines
+	Pool             string         `json:"pool,omitempty"`           // set for the group of a worker pool's goroutines
+	Package          string         `json:"package,omitempty"`        // package of the goroutine's start function
+	FuncName         string         `json:"funcname,omitempty"`       // the goroutine's start function (last frame of the stack)
+	CreatedByFrame   *StackFrame    `json:"createdbyframe,omitempty"` // Frame information for the creation point
+	CSNum            int            `json:"csnum,omitempty"`          // Call site number for goroutines spawned from the same location
+	Count            int            `json:"count"`                    // number of goroutines in the group
+	StateCounts      map[string]int `json:"statecounts"`              // PrimaryState counts of the group's goroutines
+	GoIds            []int64        `json:"goids"`                    // all of the group's goroutines (sorted)
+	RepresentativeId int64          `json:"representativeid"`         // lowest goid of the group
 }
 
 // WatchSearchRequestData defines the request for watch search
@@ -323,6 +1093,7 @@ type WatchSearchResultData struct {
 	TotalCount    int               `json:"totalcount"`
 	Results       []int64           `json:"results"`
 	ErrorSpans    []SearchErrorSpan `json:"errorspans,omitempty"` // Error spans in the search query
+	Warnings      []string          `json:"warnings,omitempty"`   // watch limits that were hit
 }
 
 type EventReadHistoryData struct {
@@ -358,6 +1129,40 @@ type BrowserTabUrlData struct {
 	AppRunId   string `json:"apprunid,omitempty"`
 	Focused    bool   `json:"focused"`
 	AutoFollow bool   `json:"autofollow"`
+	UserName   string `json:"username,omitempty"` // display name shown to the other tabs viewing the app run
+}
+
+// presence focus kinds
+const (
+	PresenceFocus_GoRoutine = "goroutine" // a goroutine expanded in the goroutines view (Id is the goroutine id)
+)
+
+// PresenceFocus is the object a browser tab is looking at in an app run
+type PresenceFocus struct {
+	Kind  string `json:"kind"` // PresenceFocus_ constants
+	Id    string `json:"id"`
+	Label string `json:"label,omitempty"` // display text (e.g. the goroutine name)
+}
+
+// BrowserTabFocusData is sent by a browser tab when the object it is looking at changes
+type BrowserTabFocusData struct {
+	AppRunId string         `json:"apprunid"`
+	Focus    *PresenceFocus `json:"focus,omitempty"` // nil clears the focus
+}
+
+// PresenceUser is a browser tab viewing an app run
+type PresenceUser struct {
+	TabId     string         `json:"tabid"` // rpc route of the tab
+	UserName  string         `json:"username"`
+	Focused   bool           `json:"focused"` // the browser window has focus
+	Focus     *PresenceFocus `json:"focus,omitempty"`
+	UpdatedTs int64          `json:"updatedts"`
+}
+
+// AppRunPresenceData is the list of browser tabs viewing an app run (sent with Event_AppRunPresence)
+type AppRunPresenceData struct {
+	AppRunId string         `json:"apprunid"`
+	Users    []PresenceUser `json:"users"`
 }
 
 // StackFrame represents a single frame in a goroutine stack trace
@@ -371,6 +1176,8 @@ type StackFrame struct {
 	FilePath   string `json:"filepath"`           // Full path to the source file (e.g., "/opt/homebrew/Cellar/go/1.23.4/libexec/src/internal/poll/fd_unix.go")
 	LineNumber int    `json:"linenumber"`         // Line number in the source file (e.g., 165)
 	PCOffset   string `json:"pcoffset,omitempty"` // Program counter offset (e.g., "+0x1fc")
+	RelPath    string `json:"relpath,omitempty"`  // Path relative to the module root, "module@version/..." for dependencies (e.g., "github.com/google/uuid@v1.6.0/uuid.go")
+	VcsUrl     string `json:"vcsurl,omitempty"`   // Link to the source line in the module's repository (e.g., "https://github.com/google/uuid/blob/v1.6.0/uuid.go#L42")
 
 	// Classification flags
 	IsImportant bool `json:"isimportant,omitempty"` // True if the frame is from the user's own module
@@ -378,12 +1185,12 @@ type StackFrame struct {
 }
 
 type TimeSpan struct {
-	Label    string `json:"label,omitempty"`    // Label for the time span (e.g., "Running", "Waiting")
-	Start    int64  `json:"start"`              // Start time in milliseconds
-	StartIdx int    `json:"startidx"`           // Start index in the logical time sequence (if applicable)
-	End      int64  `json:"end"`                // End time in milliseconds (-1 means ongoing)
-	EndIdx   int    `json:"endidx"`             // End index in the logical time sequence (-1 means ongoing)
-	Exact    bool   `json:"exact,omitempty"`    // True if the start and end times are exact (not approximate)
+	Label    string `json:"label,omitempty"` // Label for the time span (e.g., "Running", "Waiting")
+	Start    int64  `json:"start"`           // Start time in milliseconds
+	StartIdx int    `json:"startidx"`        // Start index in the logical time sequence (if applicable)
+	End      int64  `json:"end"`             // End time in milliseconds (-1 means ongoing)
+	EndIdx   int    `json:"endidx"`          // End index in the logical time sequence (-1 means ongoing)
+	Exact    bool   `json:"exact,omitempty"` // True if the start and end times are exact (not approximate)
 }
 
 func (span TimeSpan) IsWithinSpanTs(ts int64) bool {
@@ -405,6 +1212,8 @@ type ParsedGoRoutine struct {
 	GoId            int64        `json:"goid"`
 	Name            string       `json:"name,omitempty"`            // Optional name for the goroutine
 	Tags            []string     `json:"tags,omitempty"`            // Optional tags for the goroutine
+	Status          string       `json:"status,omitempty"`          // SDK reported status (e.g. "waiting on 3 members of waitgroup workers")
+	Pool            string       `json:"pool,omitempty"`            // Worker pool the goroutine belongs to (outrig.Pool workers)
 	CSNum           int          `json:"csnum,omitempty"`           // Call site number for goroutines spawned from the same location
 	ActiveTimeSpan  TimeSpan     `json:"activetimespan"`            // Time span when the goroutine was active
 	Active          bool         `json:"active"`                    // Whether the goroutine is currently active
@@ -435,10 +1244,37 @@ type TEventFeData struct {
 	Props TEventFeProps `json:"props"`
 }
 
+// SessionEvent is an entry of the local session timeline (see the sessiontimeline monitor setting).
+// The frontend records them with RecordSessionEventCommand (Ts and AppName are set by the server).
+type SessionEvent struct {
+	Ts         int64  `json:"ts"`
+	Event      string `json:"event"` // e.g. "frontend:tab", "frontend:selectapprun" or "frontend:search:logs"
+	AppRunId   string `json:"apprunid,omitempty"`
+	AppName    string `json:"appname,omitempty"`
+	Tab        string `json:"tab,omitempty"`
+	SearchTerm string `json:"searchterm,omitempty"`
+}
+
+// SessionTimelineRequest queries the session timeline, all filters are optional
+type SessionTimelineRequest struct {
+	StartTs  int64  `json:"startts,omitempty"` // only events at or after StartTs (unix ms)
+	EndTs    int64  `json:"endts,omitempty"`   // only events at or before EndTs (unix ms)
+	AppRunId string `json:"apprunid,omitempty"`
+	Limit    int    `json:"limit,omitempty"` // newest Limit events (0 for all)
+}
+
+// SessionTimelineData is the result of GetSessionTimelineCommand (events are oldest first)
+type SessionTimelineData struct {
+	Enabled bool           `json:"enabled"` // false when the sessiontimeline setting is off (older events are still returned)
+	Events  []SessionEvent `json:"events"`
+}
+
 type CombinedWatchSample struct {
-	WatchNum int64          `json:"watchnum"`
-	Decl     ds.WatchDecl   `json:"decl"`
-	Sample   ds.WatchSample `json:"sample"`
+	WatchNum    int64          `json:"watchnum"`
+	Decl        ds.WatchDecl   `json:"decl"`
+	Sample      ds.WatchSample `json:"sample"`
+	Rate        *float64       `json:"rate,omitempty"`        // change of the value per second, for watches with the "rate" display hint
+	RateHistory []float64      `json:"ratehistory,omitempty"` // recent rates per second (oldest first), for the sparkline of "rate" watches
 }
 
 // GoTimeSpan represents a goroutine's time span with its ID
@@ -449,9 +1285,9 @@ type GoTimeSpan struct {
 
 // GoRoutineTimeSpansRequest defines the request for getting goroutine time spans since a tick index
 type GoRoutineTimeSpansRequest struct {
-	AppRunId      string `json:"apprunid"`
-	SinceTickIdx  int64  `json:"sincetickidx"`
-	ShowOutrig    bool   `json:"showoutrig"` // Whether to include outrig-tagged goroutines in time spans
+	AppRunId     string `json:"apprunid"`
+	SinceTickIdx int64  `json:"sincetickidx"`
+	ShowOutrig   bool   `json:"showoutrig"` // Whether to include outrig-tagged goroutines in time spans
 }
 
 type GoRoutineActiveCount struct {
@@ -460,11 +1296,50 @@ type GoRoutineActiveCount struct {
 	Ts      int64 `json:"ts"`      // Timestamp in milliseconds
 }
 
+// GoRoutineStateHistogramRequest asks for the goroutine state counts of the ticks after SinceTickIdx
+// (use -1 for all the ticks, then the LastTick index of the previous response)
+type GoRoutineStateHistogramRequest struct {
+	AppRunId     string `json:"apprunid"`
+	SinceTickIdx int64  `json:"sincetickidx"`
+	ShowOutrig   bool   `json:"showoutrig"` // Whether to count outrig-tagged goroutines
+}
+
+// GoRoutineStateTick is the number of sampled goroutines per primary state (e.g. "running", "chan receive",
+// "IO wait", "semacquire") at a tick. StateCounts is empty for ticks without a goroutine sample (gaps).
+type GoRoutineStateTick struct {
+	TimeIdx     int            `json:"timeidx"` // Index in the logical time sequence
+	Ts          int64          `json:"ts"`      // Timestamp in milliseconds
+	StateCounts map[string]int `json:"statecounts,omitempty"`
+}
+
+// GoRoutineStateHistogramResponse is the goroutine state distribution over time (for a stacked area chart)
+type GoRoutineStateHistogramResponse struct {
+	Ticks    []GoRoutineStateTick `json:"ticks"`
+	States   []string             `json:"states"` // the states in Ticks, most goroutines first
+	LastTick Tick                 `json:"lasttick"`
+}
+
 type Tick struct {
 	Idx int   `json:"idx"` // Index in the logical time sequence
 	Ts  int64 `json:"ts"`  // Timestamp in milliseconds
 }
 
+// GoRoutineLogsRequest asks for the log lines a goroutine logged through the SDK (see ds.LogLine.GoId)
+type GoRoutineLogsRequest struct {
+	AppRunId string `json:"apprunid"`
+	GoId     int64  `json:"goid"`
+	MaxLines int    `json:"maxlines,omitempty"` // newest MaxLines lines (default 1000)
+}
+
+// GoRoutineLogsData is the result of GetGoRoutineLogsCommand (lines are oldest first)
+type GoRoutineLogsData struct {
+	AppRunId   string       `json:"apprunid"`
+	GoId       int64        `json:"goid"`
+	Lines      []ds.LogLine `json:"lines"`
+	TotalCount int          `json:"totalcount"` // number of lines logged by the goroutine (can be more than len(Lines))
+	SearchTerm string       `json:"searchterm"` // log search that shows the same lines in the log viewer
+}
+
 // GoRoutineTimeSpansResponse defines the response with updated time spans
 type GoRoutineTimeSpansResponse struct {
 	Data         []GoTimeSpan           `json:"data"`
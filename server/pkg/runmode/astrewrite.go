@@ -32,6 +32,7 @@ func modifyMainFunctionWithReplacement(state *astutil.TransformState, file *astu
 	// Add the outrig calls using the new AddInsertStmt method
 	insertText := "\t" + outrigInitText
 	file.AddInsertStmt(position, insertText)
+	file.MainInjectPos = &position
 
 	return true
 }
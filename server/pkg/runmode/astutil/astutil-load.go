@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/version"
 	"log"
 	"os"
 	"os/exec"
@@ -24,29 +25,36 @@ type TransformState struct {
 	FileSet          *token.FileSet
 	PackageMap       map[string]*packages.Package
 	Packages         []*packages.Package
-	MainPkg          *packages.Package // never nil - always contains the main package
+	MainPkg          *packages.Package // main package (nil when the state is restored from the transform cache)
+	MainPkgDir       string            // absolute path to the main package directory
+	MainGoFiles      []string          // absolute paths of the main package files when it was given as a list of .go files (empty for a directory)
 	OverlayMap       map[string]string
 	ModifiedFiles    map[string]*ModifiedFile
 	GoModPath        string // absolute path to go.mod file
 	GoWorkPath       string // absolute path to go.work file (empty if not found)
 	ToolchainVersion string // Go toolchain version from "go env GOVERSION"
+	NoToolchainPin   bool   // don't set GOTOOLCHAIN for the go commands (see GetToolchainPin)
 	MainDir          string // absolute path to main directory
 	TempDir          string
 	Verbose          bool
 	Config           config.Config
+	VendorMode       bool   // true if the main module is built from its vendor directory
+	VendorGoWorkPath string // temp go.work for vendored builds that add the SDK (empty if the vendor dir is used as-is)
 }
 
 // BuildArgs contains the build configuration for loading Go files
 type BuildArgs struct {
-	GoFiles      []string
-	BuildFlags   []string
-	ProgramArgs  []string
-	WorkingDir   string        // will always be set (will not be empty)
-	MainDir      string        // absolute path to main directory
-	FilePatterns []string      // file patterns for packages.Load
-	Config       config.Config // loaded configuration (must be set)
-	Verbose      bool
-	ConfigFile   string
+	GoFiles        []string
+	BuildFlags     []string
+	ProgramArgs    []string
+	ProgramEnv     map[string]string // extra environment variables for the program (from the JSON config)
+	WorkingDir     string            // will always be set (will not be empty)
+	MainDir        string            // absolute path to main directory
+	FilePatterns   []string          // file patterns for packages.Load
+	Config         config.Config     // loaded configuration (must be set)
+	Verbose        bool
+	ConfigFile     string
+	NoToolchainPin bool // don't pin GOTOOLCHAIN to the detected toolchain version
 }
 
 // ParseGoWorkFile parses a go.work file and returns the absolute paths of modules listed in the use directive
@@ -132,6 +140,42 @@ func IsModuleInWorkspace(transformState *TransformState) (bool, error) {
 	return false, nil
 }
 
+// GetWorkspaceModuleNames returns the module names of the go.work modules other than the main module.
+// Returns nil if there is no go.work file or the main module is not listed in it (the build only
+// uses the other workspace modules, via replace directives, when the main module is in the workspace).
+func GetWorkspaceModuleNames(goWorkPath string, mainModuleDir string) ([]string, error) {
+	if goWorkPath == "" {
+		return nil, nil
+	}
+	modules, err := ParseGoWorkFile(goWorkPath)
+	if err != nil {
+		return nil, err
+	}
+	inWorkspace := false
+	for _, modulePath := range modules {
+		if modulePath == mainModuleDir {
+			inWorkspace = true
+			break
+		}
+	}
+	if !inWorkspace {
+		return nil, nil
+	}
+	var moduleNames []string
+	for _, modulePath := range modules {
+		if modulePath == mainModuleDir {
+			continue
+		}
+		moduleName, err := GetModuleName(filepath.Join(modulePath, "go.mod"))
+		if err != nil {
+			// same as the replace directives, modules without a readable go.mod are skipped
+			continue
+		}
+		moduleNames = append(moduleNames, moduleName)
+	}
+	return moduleNames, nil
+}
+
 // addPackageToMap adds a package to the packageMap if not already present
 // and processes its imports to add them as well
 func addPackageToMap(pkg *packages.Package, packageMap map[string]*packages.Package, visited map[string]bool, transformPkgs []string) {
@@ -162,11 +206,11 @@ func addPackageToMap(pkg *packages.Package, packageMap map[string]*packages.Pack
 	}
 }
 
-// findGoWorkPath searches for a go.work file starting from the given directory
+// FindGoWorkPath searches for a go.work file starting from the given directory
 // and working up through parent directories. It also handles the GOWORK environment variable.
 // If GOWORK is set to "off", it returns an empty string.
 // Returns an absolute path to the go.work file if found.
-func findGoWorkPath(startDir string) (string, error) {
+func FindGoWorkPath(startDir string) (string, error) {
 	// Check GOWORK environment variable first
 	gowork := os.Getenv("GOWORK")
 	if gowork == "off" {
@@ -230,7 +274,20 @@ func DetectToolchainVersion(pkgDir string) (string, error) {
 	return version, nil
 }
 
-// DetermineMainDirAndPatterns determines the main directory and file patterns from the provided Go files
+// GetToolchainPin returns the GOTOOLCHAIN value that pins the go commands run on the transformed sources to the
+// toolchain the files were loaded with, or "" when the toolchain isn't pinned (NoToolchainPin is set, or the
+// toolchain can't be selected with GOTOOLCHAIN, like a devel build or a "go1.x X:experiment" version)
+func (ts *TransformState) GetToolchainPin() string {
+	if ts.NoToolchainPin || !version.IsValid(ts.ToolchainVersion) {
+		return ""
+	}
+	return ts.ToolchainVersion
+}
+
+// DetermineMainDirAndPatterns determines the main directory and file patterns from the provided Go files.
+// Listed .go files are loaded the way "go run" loads them, as a package of just those files where build
+// constraints (//go:build lines and GOOS/GOARCH file name suffixes) don't apply. A "file=" query would
+// load the package of the file's directory instead, with the files (and main func) of the target platform.
 func DetermineMainDirAndPatterns(workingDir string, goFiles []string) (string, []string, error) {
 	var mainDir string
 	var filePatterns []string
@@ -238,18 +295,24 @@ func DetermineMainDirAndPatterns(workingDir string, goFiles []string) (string, [
 	for _, goFile := range goFiles {
 		if strings.HasSuffix(goFile, ".go") {
 			// Validate .go file exists
-			filePath := filepath.Join(workingDir, goFile)
+			filePath := goFile
+			if !filepath.IsAbs(filePath) {
+				filePath = filepath.Join(workingDir, goFile)
+			}
 			if _, err := os.Stat(filePath); os.IsNotExist(err) {
 				return "", nil, fmt.Errorf("Go file does not exist: %s", filePath)
 			}
 
-			filePatterns = append(filePatterns, "file="+goFile)
+			filePatterns = append(filePatterns, goFile)
 			if mainDir == "" {
-				mainDir = filepath.Dir(goFile)
+				mainDir = filepath.Dir(filePath)
 			}
 		} else {
 			// Validate directory exists
-			dirPath := filepath.Join(workingDir, goFile)
+			dirPath := goFile
+			if !filepath.IsAbs(dirPath) {
+				dirPath = filepath.Join(workingDir, goFile)
+			}
 			if stat, err := os.Stat(dirPath); os.IsNotExist(err) {
 				return "", nil, fmt.Errorf("directory does not exist: %s", dirPath)
 			} else if err != nil {
@@ -265,7 +328,10 @@ func DetermineMainDirAndPatterns(workingDir string, goFiles []string) (string, [
 		}
 	}
 
-	// Make MainDir absolute
+	// Make MainDir absolute (relative paths are relative to the working directory, which can be set with -C)
+	if mainDir != "" && !filepath.IsAbs(mainDir) {
+		mainDir = filepath.Join(workingDir, mainDir)
+	}
 	mainDir, err := filepath.Abs(mainDir)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to get absolute path for main directory: %w", err)
@@ -274,7 +340,33 @@ func DetermineMainDirAndPatterns(workingDir string, goFiles []string) (string, [
 	return mainDir, filePatterns, nil
 }
 
-// LoadGoFiles loads the specified Go files using packages.Load with "file=" prefix
+// findDirModule returns the module that contains dir (the closest go.mod in dir or a parent directory),
+// or nil if dir is not in a module
+func findDirModule(dir string) *packages.Module {
+	currentDir := dir
+	for {
+		goModPath := filepath.Join(currentDir, "go.mod")
+		if _, err := os.Stat(goModPath); err == nil {
+			moduleName, err := GetModuleName(goModPath)
+			if err != nil {
+				return nil
+			}
+			return &packages.Module{
+				Path:  moduleName,
+				Main:  true,
+				Dir:   currentDir,
+				GoMod: goModPath,
+			}
+		}
+		parentDir := filepath.Dir(currentDir)
+		if parentDir == currentDir {
+			return nil
+		}
+		currentDir = parentDir
+	}
+}
+
+// LoadGoFiles loads the specified Go files (or main package directory) using packages.Load
 // and returns a TransformState containing the FileSet and package information
 func LoadGoFiles(buildArgs BuildArgs) (*TransformState, error) {
 	if len(buildArgs.GoFiles) == 0 {
@@ -324,41 +416,76 @@ func LoadGoFiles(buildArgs BuildArgs) (*TransformState, error) {
 	var mainPkg *packages.Package
 	for _, pkg := range pkgs {
 		if pkg.Name == "main" && pkg.Dir == mainDir {
-			if pkg.Module != nil {
-				transformPkgs = append(transformPkgs, pkg.Module.Path)
-				transformPkgs = append(transformPkgs, pkg.Module.Path+"/**")
-			}
 			mainPkg = pkg
 		}
 	}
 
+	// Validate that we have a main package with module information
+	if mainPkg == nil {
+		return nil, fmt.Errorf("no main package found")
+	}
+	if mainPkg.Module == nil && mainPkg.PkgPath == "command-line-arguments" {
+		// go list doesn't report the module of a package loaded from a list of .go files
+		mainPkg.Module = findDirModule(mainPkg.Dir)
+	}
+	if mainPkg.Module == nil {
+		return nil, fmt.Errorf("main package has no module information")
+	}
+	transformPkgs = append(transformPkgs, mainPkg.Module.Path)
+	transformPkgs = append(transformPkgs, mainPkg.Module.Path+"/**")
+	var mainGoFiles []string
+	if mainPkg.PkgPath == "command-line-arguments" {
+		mainGoFiles = mainPkg.GoFiles
+	}
+	if mainPkg.Module.GoMod == "" {
+		return nil, fmt.Errorf("main package module has no go.mod file")
+	}
+
+	// Find GoWorkPath starting from the main package's directory
+	goWorkPath, err := FindGoWorkPath(mainPkg.Module.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find go.work path: %w", err)
+	}
+
+	// Packages from the other go.work modules are built from source like the main module,
+	// so their go statements are transformed too
+	mainModuleDir, err := filepath.Abs(mainPkg.Module.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for main module: %w", err)
+	}
+	workspaceModules, err := GetWorkspaceModuleNames(goWorkPath, mainModuleDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.work file: %w", err)
+	}
+	for _, moduleName := range workspaceModules {
+		transformPkgs = append(transformPkgs, moduleName, moduleName+"/**")
+	}
+	if len(workspaceModules) > 0 && buildArgs.Verbose {
+		log.Printf("transformpkgs (from go.work): %v\n", workspaceModules)
+	}
+
 	// Process each package and its imports
 	for _, pkg := range pkgs {
+		if pkg == mainPkg {
+			// the main package is always transformed (when loaded from a list of .go files its path is
+			// "command-line-arguments", which doesn't match the transform patterns)
+			visited[pkg.ID] = true
+			for _, importedPkg := range pkg.Imports {
+				addPackageToMap(importedPkg, packageMap, visited, transformPkgs)
+			}
+			continue
+		}
 		addPackageToMap(pkg, packageMap, visited, transformPkgs)
 	}
 
 	// Convert packageMap to slice for Packages field, with main package first
-	var packages []*packages.Package
-	if mainPkg != nil {
-		packages = append(packages, mainPkg)
-	}
+	packages := []*packages.Package{mainPkg}
 	for _, pkg := range packageMap {
 		if pkg != mainPkg {
 			packages = append(packages, pkg)
 		}
 	}
 
-	// Validate that we have a main package with module information
-	if mainPkg == nil {
-		return nil, fmt.Errorf("no main package found")
-	}
-	if mainPkg.Module == nil {
-		return nil, fmt.Errorf("main package has no module information")
-	}
-	if mainPkg.Module.GoMod == "" {
-		return nil, fmt.Errorf("main package module has no go.mod file")
-	}
-
 	// Set GoModPath from the main package's module (make it absolute)
 	goModPath, err := filepath.Abs(mainPkg.Module.GoMod)
 	if err != nil {
@@ -368,12 +495,6 @@ func LoadGoFiles(buildArgs BuildArgs) (*TransformState, error) {
 		log.Printf("detected go.mod path: %s\n", goModPath)
 	}
 
-	// Find GoWorkPath starting from the main package's directory
-	goWorkPath, err := findGoWorkPath(mainPkg.Module.Dir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find go.work path: %w", err)
-	}
-
 	// Detect toolchain version
 	toolchainVersion, err := DetectToolchainVersion(mainPkg.Module.Dir)
 	if err != nil {
@@ -388,9 +509,12 @@ func LoadGoFiles(buildArgs BuildArgs) (*TransformState, error) {
 		PackageMap:       packageMap,
 		Packages:         packages,
 		MainPkg:          mainPkg,
+		MainPkgDir:       mainPkg.Dir,
+		MainGoFiles:      mainGoFiles,
 		GoModPath:        goModPath,
 		GoWorkPath:       goWorkPath,
 		ToolchainVersion: toolchainVersion,
+		NoToolchainPin:   buildArgs.NoToolchainPin,
 		MainDir:          mainDir,
 		Config:           cfg,
 	}, nil
@@ -4,6 +4,9 @@
 package astutil
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -175,4 +178,58 @@ func TestFindStatementBoundary(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetWorkspaceModuleNames(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "go.work"), "go 1.21\n\nuse (\n\t./app\n\t./lib\n\t./nomod\n)\n")
+	writeTestFile(t, filepath.Join(root, "app", "go.mod"), "module example.com/app\n\ngo 1.21\n")
+	writeTestFile(t, filepath.Join(root, "lib", "go.mod"), "module example.com/lib\n\ngo 1.21\n")
+	writeTestFile(t, filepath.Join(root, "other", "go.mod"), "module example.com/other\n\ngo 1.21\n")
+
+	names, err := GetWorkspaceModuleNames(filepath.Join(root, "go.work"), filepath.Join(root, "app"))
+	if err != nil {
+		t.Fatalf("GetWorkspaceModuleNames failed: %v", err)
+	}
+	if !slices.Equal(names, []string{"example.com/lib"}) {
+		t.Errorf("expected [example.com/lib], got %v", names)
+	}
+
+	// a main module outside the workspace doesn't build the workspace modules from source
+	names, err = GetWorkspaceModuleNames(filepath.Join(root, "go.work"), filepath.Join(root, "other"))
+	if err != nil {
+		t.Fatalf("GetWorkspaceModuleNames failed: %v", err)
+	}
+	if len(names) != 0 {
+		t.Errorf("expected no workspace modules, got %v", names)
+	}
+}
+
+func TestGetToolchainPin(t *testing.T) {
+	tests := []struct {
+		toolchainVersion string
+		noToolchainPin   bool
+		expected         string
+	}{
+		{"go1.24.2", false, "go1.24.2"},
+		{"go1.25rc1", false, "go1.25rc1"},
+		{"go1.24.2", true, ""},
+		{"devel go1.26-abcdef Mon Jan 1 00:00:00 2025 +0000", false, ""},
+		{"go1.24.2 X:boringcrypto", false, ""},
+	}
+	for _, tt := range tests {
+		ts := &TransformState{ToolchainVersion: tt.toolchainVersion, NoToolchainPin: tt.noToolchainPin}
+		if pin := ts.GetToolchainPin(); pin != tt.expected {
+			t.Errorf("GetToolchainPin(%q, %v) = %q, expected %q", tt.toolchainVersion, tt.noToolchainPin, pin, tt.expected)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -19,6 +20,17 @@ type ModifiedFile struct {
 	RawBytes          []byte
 	Modified          bool
 	OutrigImportAdded bool
+	MainInjectPos     *token.Position // where outrig.Init was injected (nil if this is not the main file)
+	GoStmtRewrites    []GoStmtRewrite // go statements rewritten to outrig.Go (in source order)
+	TypesInfo         *types.Info     // type info of the file's package (nil if the package was not type checked)
+}
+
+// GoStmtRewrite records a go statement that was rewritten to outrig.Go(...).Run(...) (used for the transform report)
+type GoStmtRewrite struct {
+	Line   int
+	Column int
+	Name   string   // name from the //outrig directive (empty if there was none)
+	Tags   []string // tags from the //outrig directive
 }
 
 // statementBoundary represents the result of finding a statement boundary
@@ -0,0 +1,524 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package astutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/version"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"golang.org/x/mod/modfile"
+)
+
+// Vendored builds can't use a temp go.mod (-modfile) + "go get", the go command checks go.mod against
+// vendor/modules.txt and only ever looks for the vendor directory next to the main module's go.mod.
+// Instead we build a temp workspace (go.work) that uses the main module plus a copy of the SDK, with
+// a workspace vendor directory (<tempdir>/vendor) that contains the user's vendored modules plus the SDK's
+// dependencies. Workspace vendoring requires Go 1.22+.
+//
+// If the main module already vendors the SDK we don't need any of that, the user's vendor directory is used as-is.
+
+const vendorModulesTxt = "modules.txt"
+const vendorSDKDirName = "outrigsdk"
+const minWorkspaceVendorGoVersion = "1.22"
+
+// vendorModule is a module entry from vendor/modules.txt
+type vendorModule struct {
+	Line      string // original "# path version [=> replacement]" line
+	Path      string
+	Version   string
+	Explicit  bool
+	GoVersion string
+	Pkgs      []string
+}
+
+// modDownloadInfo is the subset of "go mod download -json" output that we need
+type modDownloadInfo struct {
+	Path    string
+	Version string
+	Dir     string
+	GoMod   string
+	Error   string
+}
+
+// getModFlag returns the -mod flag value from the build flags (or GOFLAGS), "" if not set
+func getModFlag(buildFlags []string) string {
+	var modFlag string
+	for i := 0; i < len(buildFlags); i++ {
+		if buildFlags[i] == "-mod" && i+1 < len(buildFlags) {
+			modFlag = buildFlags[i+1]
+			i++
+		} else if strings.HasPrefix(buildFlags[i], "-mod=") {
+			modFlag = strings.TrimPrefix(buildFlags[i], "-mod=")
+		}
+	}
+	if modFlag != "" {
+		return modFlag
+	}
+	goflags := os.Getenv("GOFLAGS")
+	if goflags == "" {
+		return ""
+	}
+	flagArgs, err := shellquote.Split(goflags)
+	if err != nil {
+		return ""
+	}
+	for _, arg := range flagArgs {
+		if strings.HasPrefix(arg, "-mod=") {
+			modFlag = strings.TrimPrefix(arg, "-mod=")
+		}
+	}
+	return modFlag
+}
+
+// IsVendorMode returns true if the go command would build the main module from its vendor directory.
+// Follows the go command's rules: an explicit -mod flag wins, otherwise vendor/ is used when it exists
+// and the go.mod go version is at least 1.14 (we always build with GOWORK=off).
+func IsVendorMode(transformState *TransformState, buildFlags []string) bool {
+	vendorDir := filepath.Join(filepath.Dir(transformState.GoModPath), "vendor")
+	if info, err := os.Stat(vendorDir); err != nil || !info.IsDir() {
+		return false
+	}
+	modFlag := getModFlag(buildFlags)
+	if modFlag != "" {
+		return modFlag == "vendor"
+	}
+	goModData, err := os.ReadFile(transformState.GoModPath)
+	if err != nil {
+		return false
+	}
+	goModFile, err := modfile.ParseLax(transformState.GoModPath, goModData, nil)
+	if err != nil || goModFile.Go == nil {
+		return false
+	}
+	return version.Compare("go"+goModFile.Go.Version, "go1.14") >= 0
+}
+
+// parseVendorModules parses vendor/modules.txt into its module entries (in file order)
+func parseVendorModules(data []byte) []*vendorModule {
+	var modules []*vendorModule
+	var curMod *vendorModule
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "# ") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				curMod = nil
+				continue
+			}
+			curMod = &vendorModule{Line: line, Path: fields[1]}
+			if len(fields) >= 3 && fields[2] != "=>" {
+				curMod.Version = fields[2]
+			}
+			modules = append(modules, curMod)
+			continue
+		}
+		if curMod == nil {
+			continue
+		}
+		if annotations, ok := strings.CutPrefix(line, "## "); ok {
+			for _, entry := range strings.Split(annotations, ";") {
+				entry = strings.TrimSpace(entry)
+				if entry == "explicit" {
+					curMod.Explicit = true
+				}
+				if goVersion, ok := strings.CutPrefix(entry, "go "); ok {
+					curMod.GoVersion = goVersion
+				}
+			}
+			continue
+		}
+		if pkg := strings.TrimSpace(line); pkg != "" {
+			curMod.Pkgs = append(curMod.Pkgs, pkg)
+		}
+	}
+	return modules
+}
+
+// formatVendorModules formats the module entries as a workspace vendor/modules.txt
+func formatVendorModules(modules []*vendorModule) []byte {
+	var sb strings.Builder
+	sb.WriteString("## workspace\n")
+	for _, mod := range modules {
+		sb.WriteString(mod.Line + "\n")
+		var annotations []string
+		if mod.Explicit {
+			annotations = append(annotations, "explicit")
+		}
+		if mod.GoVersion != "" {
+			annotations = append(annotations, "go "+mod.GoVersion)
+		}
+		if len(annotations) > 0 {
+			sb.WriteString("## " + strings.Join(annotations, "; ") + "\n")
+		}
+		for _, pkg := range mod.Pkgs {
+			sb.WriteString(pkg + "\n")
+		}
+	}
+	return []byte(sb.String())
+}
+
+func findVendorModule(modules []*vendorModule, modPath string) *vendorModule {
+	for _, mod := range modules {
+		if mod.Path == modPath && mod.Version != "" {
+			return mod
+		}
+	}
+	return nil
+}
+
+// downloadModule returns the module cache directory for modPath@modVersion.
+// When cacheOnly is set the network is never used (GOPROXY=off).
+func downloadModule(transformState *TransformState, modPath string, modVersion string, cacheOnly bool) (*modDownloadInfo, error) {
+	cmd := exec.Command("go", "mod", "download", "-json", modPath+"@"+modVersion)
+	// run in the temp dir so we're outside of any module
+	cmd.Dir = transformState.TempDir
+	// -mod=mod is appended to the user's GOFLAGS (the last -mod flag wins)
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS="+strings.TrimSpace(os.Getenv("GOFLAGS")+" -mod=mod"))
+	if toolchainPin := transformState.GetToolchainPin(); toolchainPin != "" {
+		cmd.Env = append(cmd.Env, "GOTOOLCHAIN="+toolchainPin)
+	}
+	if cacheOnly {
+		cmd.Env = append(cmd.Env, "GOPROXY=off")
+	}
+	output, err := cmd.Output()
+	var info modDownloadInfo
+	if jsonErr := json.Unmarshal(output, &info); jsonErr != nil {
+		if err != nil {
+			return nil, fmt.Errorf("go mod download %s@%s failed: %w", modPath, modVersion, err)
+		}
+		return nil, fmt.Errorf("cannot parse go mod download output for %s@%s: %w", modPath, modVersion, jsonErr)
+	}
+	if info.Error != "" {
+		return nil, fmt.Errorf("go mod download %s@%s failed: %s", modPath, modVersion, info.Error)
+	}
+	if info.Dir == "" {
+		return nil, fmt.Errorf("go mod download %s@%s did not return a module directory", modPath, modVersion)
+	}
+	return &info, nil
+}
+
+// downloadModuleCacheFirst tries the local module cache before falling back to the network
+func downloadModuleCacheFirst(transformState *TransformState, modPath string, modVersion string) (*modDownloadInfo, error) {
+	info, err := downloadModule(transformState, modPath, modVersion, true)
+	if err == nil {
+		return info, nil
+	}
+	if transformState.Verbose {
+		log.Printf("vendor: %s@%s not in module cache, downloading", modPath, modVersion)
+	}
+	return downloadModule(transformState, modPath, modVersion, false)
+}
+
+// walkModulePackages calls fn for every package directory in the module (skipping nested modules, testdata, and _/. dirs)
+func walkModulePackages(modDir string, fn func(pkgDir string, relPath string) error) error {
+	return filepath.WalkDir(modDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != modDir {
+			name := d.Name()
+			if name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") && !strings.HasSuffix(entry.Name(), "_test.go") {
+				relPath, err := filepath.Rel(modDir, path)
+				if err != nil {
+					return err
+				}
+				return fn(path, relPath)
+			}
+		}
+		return nil
+	})
+}
+
+// copyPackageFiles copies the (non-test) files of a single package directory, subdirectories are not copied
+func copyPackageFiles(srcDir string, dstDir string) error {
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(dstDir, 0755)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		err = utilfn.CopyFile(filepath.Join(srcDir, entry.Name()), filepath.Join(dstDir, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyDirTree copies all regular files under srcDir to dstDir, skipping skipFn matches
+func copyDirTree(srcDir string, dstDir string, skipFn func(relPath string, d fs.DirEntry) bool) error {
+	return filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		if relPath != "." && skipFn != nil && skipFn(relPath, d) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		dstPath := filepath.Join(dstDir, relPath)
+		if d.IsDir() {
+			return os.MkdirAll(dstPath, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return utilfn.CopyFile(path, dstPath)
+	})
+}
+
+// vendorModulePackages adds the packages of a downloaded module to the vendor dir.
+// Packages already listed for the module are left alone (the user's vendored copy wins).
+func vendorModulePackages(vendorDir string, vmod *vendorModule, modDir string) error {
+	existing := make(map[string]bool)
+	for _, pkg := range vmod.Pkgs {
+		existing[pkg] = true
+	}
+	err := walkModulePackages(modDir, func(pkgDir string, relPath string) error {
+		importPath := vmod.Path
+		if relPath != "." {
+			importPath = vmod.Path + "/" + filepath.ToSlash(relPath)
+		}
+		if existing[importPath] {
+			return nil
+		}
+		vmod.Pkgs = append(vmod.Pkgs, importPath)
+		return copyPackageFiles(pkgDir, filepath.Join(vendorDir, filepath.FromSlash(importPath)))
+	})
+	sort.Strings(vmod.Pkgs)
+	return err
+}
+
+// getSDKSourceDir returns the directory of the SDK source to vendor (SDKReplacePath or the module cache)
+func getSDKSourceDir(transformState *TransformState) (string, error) {
+	sdkReplacePath := os.Getenv(config.RunSDKReplacePathEnvName)
+	if sdkReplacePath == "" {
+		sdkReplacePath = transformState.Config.RunMode.SDKReplacePath
+	}
+	if sdkReplacePath != "" {
+		if transformState.Verbose {
+			log.Printf("vendor: using outrig SDK from %s", sdkReplacePath)
+		}
+		return sdkReplacePath, nil
+	}
+	info, err := downloadModuleCacheFirst(transformState, OutrigImportPath, config.OutrigSDKVersion)
+	if err != nil {
+		return "", fmt.Errorf("cannot get outrig SDK source: %w", err)
+	}
+	return info.Dir, nil
+}
+
+func maxGoVersion(versions ...string) string {
+	var rtn string
+	for _, v := range versions {
+		if v == "" {
+			continue
+		}
+		if rtn == "" || version.Compare("go"+v, "go"+rtn) > 0 {
+			rtn = v
+		}
+	}
+	return rtn
+}
+
+// SetupVendorBuild prepares a vendored build of the main module.
+// If the SDK is already vendored nothing needs to be written, otherwise it creates the temp workspace
+// (go.work + vendor dir) and sets transformState.VendorGoWorkPath.
+func SetupVendorBuild(transformState *TransformState) error {
+	transformState.VendorMode = true
+	mainModuleDir := filepath.Dir(transformState.GoModPath)
+	userVendorDir := filepath.Join(mainModuleDir, "vendor")
+
+	modulesTxt, err := os.ReadFile(filepath.Join(userVendorDir, vendorModulesTxt))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read vendor/modules.txt: %w", err)
+	}
+	modules := parseVendorModules(modulesTxt)
+
+	// if the user already vendors the SDK, just build against their vendor directory
+	if sdkMod := findVendorModule(modules, OutrigImportPath); sdkMod != nil {
+		if err := validateSDKVersionCompatibility(sdkMod.Version); err != nil {
+			return fmt.Errorf("incompatible vendored SDK version %s: %w", sdkMod.Version, err)
+		}
+		if transformState.Verbose {
+			log.Printf("vendor: outrig SDK %s already vendored, using vendor directory as-is", sdkMod.Version)
+		}
+		return nil
+	}
+
+	if version.Compare(transformState.ToolchainVersion, "go"+minWorkspaceVendorGoVersion) < 0 {
+		return fmt.Errorf("vendored builds require Go %s or later to add the outrig SDK (toolchain is %s), run 'go mod vendor' with the SDK added to go.mod instead", minWorkspaceVendorGoVersion, transformState.ToolchainVersion)
+	}
+
+	// copy the SDK into the temp dir, it becomes a workspace module (so it needs a writable go.mod)
+	sdkSrcDir, err := getSDKSourceDir(transformState)
+	if err != nil {
+		return err
+	}
+	sdkDir := filepath.Join(transformState.TempDir, vendorSDKDirName)
+	err = copyDirTree(sdkSrcDir, sdkDir, func(relPath string, d fs.DirEntry) bool {
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") {
+				return true
+			}
+			_, err := os.Stat(filepath.Join(sdkSrcDir, relPath, "go.mod"))
+			return err == nil
+		}
+		return strings.HasSuffix(d.Name(), "_test.go")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy outrig SDK: %w", err)
+	}
+
+	// copy the user's vendor tree (modules.txt is regenerated below)
+	vendorDir := filepath.Join(transformState.TempDir, "vendor")
+	err = copyDirTree(userVendorDir, vendorDir, func(relPath string, d fs.DirEntry) bool {
+		return relPath == vendorModulesTxt
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy vendor directory: %w", err)
+	}
+
+	sdkGoModPath := filepath.Join(sdkDir, "go.mod")
+	sdkGoModData, err := os.ReadFile(sdkGoModPath)
+	if err != nil {
+		return fmt.Errorf("failed to read SDK go.mod: %w", err)
+	}
+	sdkGoMod, err := modfile.Parse(sdkGoModPath, sdkGoModData, nil)
+	if err != nil {
+		return fmt.Errorf("failed to parse SDK go.mod: %w", err)
+	}
+	for _, rep := range sdkGoMod.Replace {
+		sdkGoMod.DropReplace(rep.Old.Path, rep.Old.Version)
+	}
+
+	// every requirement of a workspace module must be vendored (and marked explicit) at exactly the required version,
+	// the user's vendored version always wins so the main module builds exactly as it does without outrig
+	for _, req := range sdkGoMod.Require {
+		modPath := req.Mod.Path
+		vmod := findVendorModule(modules, modPath)
+		if vmod != nil {
+			vmod.Explicit = true
+			if vmod.Version != req.Mod.Version {
+				if transformState.Verbose {
+					log.Printf("vendor: SDK requires %s@%s, using vendored %s", modPath, req.Mod.Version, vmod.Version)
+				}
+				err = sdkGoMod.AddRequire(modPath, vmod.Version)
+				if err != nil {
+					return fmt.Errorf("failed to update SDK requirement %s: %w", modPath, err)
+				}
+			}
+			// fill in any packages the SDK needs that the user didn't vendor (only if available offline)
+			info, err := downloadModule(transformState, modPath, vmod.Version, true)
+			if err == nil {
+				err = vendorModulePackages(vendorDir, vmod, info.Dir)
+				if err != nil {
+					return fmt.Errorf("failed to vendor %s: %w", modPath, err)
+				}
+			}
+			continue
+		}
+		info, err := downloadModuleCacheFirst(transformState, modPath, req.Mod.Version)
+		if err != nil {
+			return fmt.Errorf("failed to vendor SDK dependency: %w", err)
+		}
+		vmod = &vendorModule{
+			Line:     "# " + modPath + " " + req.Mod.Version,
+			Path:     modPath,
+			Version:  req.Mod.Version,
+			Explicit: true,
+		}
+		if depGoModData, err := os.ReadFile(info.GoMod); err == nil {
+			if depGoMod, err := modfile.ParseLax(info.GoMod, depGoModData, nil); err == nil && depGoMod.Go != nil {
+				vmod.GoVersion = depGoMod.Go.Version
+			}
+		}
+		err = vendorModulePackages(vendorDir, vmod, info.Dir)
+		if err != nil {
+			return fmt.Errorf("failed to vendor %s: %w", modPath, err)
+		}
+		modules = append(modules, vmod)
+		if transformState.Verbose {
+			log.Printf("vendor: added SDK dependency %s@%s (%d packages)", modPath, req.Mod.Version, len(vmod.Pkgs))
+		}
+	}
+
+	formattedData, err := sdkGoMod.Format()
+	if err != nil {
+		return fmt.Errorf("failed to format SDK go.mod: %w", err)
+	}
+	err = os.WriteFile(sdkGoModPath, formattedData, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write SDK go.mod: %w", err)
+	}
+	err = os.WriteFile(filepath.Join(vendorDir, vendorModulesTxt), formatVendorModules(modules), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write vendor/modules.txt: %w", err)
+	}
+
+	// write the go.work (go version must cover every module in the workspace)
+	var mainGoVersion, sdkGoVersion string
+	if mainGoModData, err := os.ReadFile(transformState.GoModPath); err == nil {
+		if mainGoMod, err := modfile.ParseLax(transformState.GoModPath, mainGoModData, nil); err == nil && mainGoMod.Go != nil {
+			mainGoVersion = mainGoMod.Go.Version
+		}
+	}
+	if sdkGoMod.Go != nil {
+		sdkGoVersion = sdkGoMod.Go.Version
+	}
+	workFile := &modfile.WorkFile{Syntax: &modfile.FileSyntax{}}
+	err = workFile.AddGoStmt(maxGoVersion(mainGoVersion, sdkGoVersion, minWorkspaceVendorGoVersion))
+	if err != nil {
+		return fmt.Errorf("failed to create go.work: %w", err)
+	}
+	workFile.AddUse(mainModuleDir, "")
+	workFile.AddUse(sdkDir, "")
+	goWorkPath := filepath.Join(transformState.TempDir, "go.work")
+	err = os.WriteFile(goWorkPath, modfile.Format(workFile.Syntax), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write go.work: %w", err)
+	}
+	transformState.VendorGoWorkPath = goWorkPath
+
+	if transformState.Verbose {
+		log.Printf("vendor: created workspace %s (vendor dir %s)", goWorkPath, vendorDir)
+	}
+	return nil
+}
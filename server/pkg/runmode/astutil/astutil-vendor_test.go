@@ -0,0 +1,79 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package astutil
+
+import (
+	"testing"
+)
+
+func TestParseVendorModules(t *testing.T) {
+	modulesTxt := `# github.com/google/uuid v1.6.0
+## explicit
+github.com/google/uuid
+# golang.org/x/sys v0.30.0
+## explicit; go 1.18
+golang.org/x/sys/unix
+golang.org/x/sys/windows
+# example.com/old v1.0.0 => example.com/new v1.1.0
+## explicit; go 1.20
+example.com/old/pkg
+# example.com/wild => ../wild
+`
+	modules := parseVendorModules([]byte(modulesTxt))
+	if len(modules) != 4 {
+		t.Fatalf("expected 4 modules, got %d", len(modules))
+	}
+
+	sysMod := findVendorModule(modules, "golang.org/x/sys")
+	if sysMod == nil {
+		t.Fatalf("golang.org/x/sys not found")
+	}
+	if sysMod.Version != "v0.30.0" || !sysMod.Explicit || sysMod.GoVersion != "1.18" || len(sysMod.Pkgs) != 2 {
+		t.Errorf("unexpected golang.org/x/sys entry: %+v", sysMod)
+	}
+
+	uuidMod := findVendorModule(modules, "github.com/google/uuid")
+	if uuidMod == nil || !uuidMod.Explicit || uuidMod.GoVersion != "" {
+		t.Errorf("unexpected github.com/google/uuid entry: %+v", uuidMod)
+	}
+
+	if findVendorModule(modules, "example.com/wild") != nil {
+		t.Errorf("wildcard replacement should not be returned as a vendored module")
+	}
+
+	// formatting should keep every entry (including replacements) and mark the file as a workspace vendor dir
+	expected := "## workspace\n" + modulesTxt
+	if got := string(formatVendorModules(modules)); got != expected {
+		t.Errorf("formatVendorModules mismatch:\ngot:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestGetModFlag(t *testing.T) {
+	t.Setenv("GOFLAGS", "-mod=mod -trimpath")
+	tests := []struct {
+		name       string
+		buildFlags []string
+		expected   string
+	}{
+		{name: "separate arg", buildFlags: []string{"-mod", "vendor"}, expected: "vendor"},
+		{name: "equals form", buildFlags: []string{"-race", "-mod=readonly"}, expected: "readonly"},
+		{name: "falls back to GOFLAGS", buildFlags: []string{"-race"}, expected: "mod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getModFlag(tt.buildFlags); got != tt.expected {
+				t.Errorf("getModFlag(%v) = %q, expected %q", tt.buildFlags, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMaxGoVersion(t *testing.T) {
+	if got := maxGoVersion("1.21", "", "1.22", "1.9"); got != "1.22" {
+		t.Errorf("maxGoVersion = %q, expected 1.22", got)
+	}
+	if got := maxGoVersion("1.24.3", "1.22"); got != "1.24.3" {
+		t.Errorf("maxGoVersion = %q, expected 1.24.3", got)
+	}
+}
@@ -6,9 +6,17 @@ package astutil
 import (
 	"fmt"
 	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"runtime"
 )
 
-// FindMainFileAST finds the main file AST from the parsed packages
+// FindMainFileAST finds the main file AST from the parsed packages.
+// Only the files that are built for the target platform (GOOS/GOARCH and build tags) are parsed, so with
+// platform-specific entrypoints (main_linux.go, main_windows.go, or files behind build tags) this is the
+// file that has main() for the target.
 func FindMainFileAST(transformState *TransformState) (*ast.File, error) {
 	mainPkg := transformState.MainPkg
 
@@ -22,5 +30,33 @@ func FindMainFileAST(transformState *TransformState) (*ast.File, error) {
 		}
 	}
 
+	if ignoredFile := findIgnoredMainFile(mainPkg.IgnoredFiles); ignoredFile != "" {
+		return nil, fmt.Errorf("no main() function found in main package files for %s/%s (main() is in %s, which is excluded by its build constraints, set GOOS/GOARCH or -tags to build it)",
+			getTargetEnv("GOOS", runtime.GOOS), getTargetEnv("GOARCH", runtime.GOARCH), filepath.Base(ignoredFile))
+	}
 	return nil, fmt.Errorf("no main() function found in main package files")
 }
+
+// findIgnoredMainFile returns the first file excluded by build constraints that has a main() function
+func findIgnoredMainFile(ignoredFiles []string) string {
+	for _, fileName := range ignoredFiles {
+		if filepath.Ext(fileName) != ".go" {
+			continue
+		}
+		astFile, err := parser.ParseFile(token.NewFileSet(), fileName, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		if FindMainFunction(astFile) != nil {
+			return fileName
+		}
+	}
+	return ""
+}
+
+func getTargetEnv(name string, defaultValue string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return defaultValue
+}
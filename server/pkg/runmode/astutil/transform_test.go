@@ -0,0 +1,139 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package astutil
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testMainFileFmt = "%spackage main\n\nfunc main() {\n\thelper()\n}\n"
+
+func writeTestMainModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.21\n")
+	writeTestFile(t, filepath.Join(dir, "helper.go"), "package main\n\nfunc helper() {}\n")
+	for name, buildLine := range files {
+		content := strings.Replace(testMainFileFmt, "%s", buildLine, 1)
+		writeTestFile(t, filepath.Join(dir, name), content)
+	}
+	return dir
+}
+
+func findTestMainFile(t *testing.T, dir string, goFiles []string, buildFlags []string) (string, error) {
+	t.Helper()
+	mainDir, filePatterns, err := DetermineMainDirAndPatterns(dir, goFiles)
+	if err != nil {
+		t.Fatalf("DetermineMainDirAndPatterns failed: %v", err)
+	}
+	transformState, err := LoadGoFiles(BuildArgs{
+		GoFiles:      goFiles,
+		BuildFlags:   buildFlags,
+		WorkingDir:   dir,
+		MainDir:      mainDir,
+		FilePatterns: filePatterns,
+	})
+	if err != nil {
+		t.Fatalf("LoadGoFiles failed: %v", err)
+	}
+	mainFile, err := FindMainFileAST(transformState)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(transformState.GetFilePath(mainFile)), nil
+}
+
+func TestFindMainFileASTPlatformSuffix(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+	dir := writeTestMainModule(t, map[string]string{
+		"main_linux.go":   "",
+		"main_windows.go": "",
+		"main_other.go":   "//go:build !linux && !windows\n\n",
+	})
+	tests := []struct {
+		goos     string
+		expected string
+	}{
+		{"linux", "main_linux.go"},
+		{"windows", "main_windows.go"},
+		{"darwin", "main_other.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			t.Setenv("GOOS", tt.goos)
+			t.Setenv("GOARCH", "amd64")
+			mainFile, err := findTestMainFile(t, dir, []string{"."}, nil)
+			if err != nil {
+				t.Fatalf("FindMainFileAST failed: %v", err)
+			}
+			if mainFile != tt.expected {
+				t.Errorf("GOOS=%s: expected main file %s, got %s", tt.goos, tt.expected, mainFile)
+			}
+		})
+	}
+}
+
+func TestFindMainFileASTBuildTags(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+	dir := writeTestMainModule(t, map[string]string{
+		"main_debug.go":   "//go:build debug\n\n",
+		"main_release.go": "//go:build !debug\n\n",
+	})
+	tests := []struct {
+		name       string
+		goFiles    []string
+		buildFlags []string
+		expected   string
+	}{
+		{"no tags", []string{"."}, nil, "main_release.go"},
+		{"tags flag", []string{"."}, []string{"-tags", "debug"}, "main_debug.go"},
+		{"tags= flag", []string{"."}, []string{"-tags=debug"}, "main_debug.go"},
+		// like "go run", build constraints don't apply to listed files
+		{"listed files", []string{"main_debug.go", "helper.go"}, nil, "main_debug.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mainFile, err := findTestMainFile(t, dir, tt.goFiles, tt.buildFlags)
+			if err != nil {
+				t.Fatalf("FindMainFileAST failed: %v", err)
+			}
+			if mainFile != tt.expected {
+				t.Errorf("expected main file %s, got %s", tt.expected, mainFile)
+			}
+		})
+	}
+}
+
+func TestFindMainFileASTListedIgnoredFile(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+	dir := writeTestMainModule(t, map[string]string{
+		"gen.go": "//go:build ignore\n\n",
+	})
+	writeTestFile(t, filepath.Join(dir, "gen.go"), "//go:build ignore\n\npackage main\n\nfunc main() {}\n")
+	mainFile, err := findTestMainFile(t, dir, []string{"gen.go"}, nil)
+	if err != nil {
+		t.Fatalf("FindMainFileAST failed: %v", err)
+	}
+	if mainFile != "gen.go" {
+		t.Errorf("expected main file gen.go, got %s", mainFile)
+	}
+}
+
+func TestFindMainFileASTExcludedMain(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOOS", "linux")
+	t.Setenv("GOARCH", "amd64")
+	dir := writeTestMainModule(t, map[string]string{
+		"main_windows.go": "",
+	})
+	_, err := findTestMainFile(t, dir, []string{"."}, nil)
+	if err == nil {
+		t.Fatalf("expected an error for a main() that is only built on windows")
+	}
+	if !strings.Contains(err.Error(), "main_windows.go") || !strings.Contains(err.Error(), "linux/amd64") {
+		t.Errorf("expected the error to name main_windows.go and linux/amd64, got: %v", err)
+	}
+}
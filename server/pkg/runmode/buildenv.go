@@ -0,0 +1,41 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package runmode
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+// buildEnvVarNames are the variables (besides the CGO_ ones) that change how the go command loads and builds
+// the program: the C toolchain for cgo, the target platform, and the go command settings
+var buildEnvVarNames = map[string]bool{
+	"CC": true, "CXX": true, "FC": true, "AR": true, "PKG_CONFIG": true,
+	"GOOS": true, "GOARCH": true, "GOARM": true, "GOARM64": true, "GOAMD64": true, "GO386": true,
+	"GOMIPS": true, "GOMIPS64": true, "GOPPC64": true, "GORISCV64": true, "GOWASM": true,
+	"GOEXPERIMENT": true, "GOFLAGS": true, "GOTOOLCHAIN": true, "GOROOT": true, "GOPATH": true,
+	"GOPROXY": true, "GOPRIVATE": true, "GONOPROXY": true, "GONOSUMDB": true, "GOSUMDB": true,
+	"GOINSECURE": true, "GOMODCACHE": true, "GOCACHE": true,
+}
+
+func isBuildEnvVar(name string) bool {
+	return buildEnvVarNames[name] || strings.HasPrefix(name, "CGO_")
+}
+
+// applyBuildEnv sets the build variables of the JSON config env in our environment, so they apply to every
+// go command (loading the packages, detecting the toolchain, downloading the SDK, and the build itself) and
+// not just to "go run" (which gets the whole env). Cgo projects and cross-compiles then load the same files
+// they build.
+func applyBuildEnv(programEnv map[string]string, verbose bool) {
+	for name, value := range programEnv {
+		if !isBuildEnvVar(name) {
+			continue
+		}
+		if verbose {
+			log.Printf("Using build environment variable %s from config", name)
+		}
+		os.Setenv(name, value)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package runmode
+
+import (
+	"os"
+	"testing"
+)
+
+func TestApplyBuildEnv(t *testing.T) {
+	t.Setenv("CC", "")
+	t.Setenv("CGO_CFLAGS", "")
+	t.Setenv("OUTRIG_TEST_APPVAR", "")
+	applyBuildEnv(map[string]string{
+		"CC":                 "clang",
+		"CGO_CFLAGS":         "-O2 -I/opt/include",
+		"OUTRIG_TEST_APPVAR": "program-only",
+	}, false)
+	if os.Getenv("CC") != "clang" || os.Getenv("CGO_CFLAGS") != "-O2 -I/opt/include" {
+		t.Errorf("build variables were not applied: CC=%q CGO_CFLAGS=%q", os.Getenv("CC"), os.Getenv("CGO_CFLAGS"))
+	}
+	if os.Getenv("OUTRIG_TEST_APPVAR") != "" {
+		t.Errorf("program variables should only be set for the program, got %q", os.Getenv("OUTRIG_TEST_APPVAR"))
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package runmode
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// normalizeGoFlag converts a --flag (which the go command also accepts) to -flag so the
+// flags can be matched against flagsWithArgs and stripped/checked by name.
+// Flag values are never changed.
+func normalizeGoFlag(arg string) string {
+	if strings.HasPrefix(arg, "--") && len(arg) > 2 && arg[2] != '-' {
+		return arg[1:]
+	}
+	return arg
+}
+
+// parseRunArgs splits the arguments of "outrig run" into three phases:
+// go build flags, go files/package, and program args.
+// Flag values (e.g. the -ldflags string) are passed through untouched.
+func parseRunArgs(args []string) (buildFlags []string, goFiles []string, programArgs []string) {
+	const (
+		parseFlags = iota
+		parseGoFiles
+		parseProgArgs
+	)
+
+	state := parseFlags
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch state {
+		case parseFlags:
+			if strings.HasPrefix(arg, "-") {
+				// This is a flag
+				flagName := normalizeGoFlag(arg)
+				buildFlags = append(buildFlags, flagName)
+
+				if strings.Contains(flagName, "=") {
+					// Flag is in -flag=value format, no need to consume next arg
+					continue
+				}
+
+				if flagsWithArgs[flagName] && i+1 < len(args) {
+					// This flag takes an argument, consume the next argument too (even if it starts with "-", e.g. -ldflags "-X main.version=1.0")
+					i++
+					buildFlags = append(buildFlags, args[i])
+				}
+			} else {
+				// Hit first non-flag, switch to parsing go files/package
+				state = parseGoFiles
+				i-- // Re-process this argument in the new state
+			}
+
+		case parseGoFiles:
+			if strings.HasSuffix(arg, ".go") {
+				// This is a .go file, collect it
+				goFiles = append(goFiles, arg)
+			} else {
+				// This is either a package or the first program arg
+				if len(goFiles) == 0 {
+					// No .go files seen yet, this must be a package
+					goFiles = append(goFiles, arg)
+				} else {
+					// We already have .go files, this is a program arg
+					state = parseProgArgs
+					i-- // Re-process this argument in the new state
+				}
+			}
+
+		case parseProgArgs:
+			// Everything from here on is a program argument
+			programArgs = append(programArgs, arg)
+		}
+	}
+	return buildFlags, goFiles, programArgs
+}
+
+// getLdflagsValues returns the values of all -ldflags flags (go only uses the last one for the main package)
+func getLdflagsValues(buildFlags []string) []string {
+	var values []string
+	for i := 0; i < len(buildFlags); i++ {
+		if buildFlags[i] == "-ldflags" && i+1 < len(buildFlags) {
+			values = append(values, buildFlags[i+1])
+			i++
+		} else if value, ok := strings.CutPrefix(buildFlags[i], "-ldflags="); ok {
+			values = append(values, value)
+		} else if flagsWithArgs[buildFlags[i]] {
+			i++
+		}
+	}
+	return values
+}
+
+// splitQuotedFlags splits a flag value like -ldflags into fields the same way the go command does:
+// fields are separated by spaces and may be quoted with ' or " to include spaces
+// (e.g. -X 'main.version=1.0 beta'). Quotes are only recognized at the start of a field.
+func splitQuotedFlags(s string) ([]string, error) {
+	var fields []string
+	for len(s) > 0 {
+		for len(s) > 0 && isFlagSpace(s[0]) {
+			s = s[1:]
+		}
+		if len(s) == 0 {
+			break
+		}
+		if s[0] == '"' || s[0] == '\'' {
+			quote := s[0]
+			s = s[1:]
+			end := strings.IndexByte(s, quote)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated %c string", quote)
+			}
+			fields = append(fields, s[:end])
+			s = s[end+1:]
+			continue
+		}
+		end := 0
+		for end < len(s) && !isFlagSpace(s[end]) {
+			end++
+		}
+		fields = append(fields, s[:end])
+		s = s[end:]
+	}
+	return fields, nil
+}
+
+func isFlagSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// checkLdflags makes sure the -ldflags values can be parsed (so a quoting mistake is reported
+// before the AST transform instead of as a linker error) and logs the -X settings in verbose mode.
+// The values are forwarded to the go command unchanged.
+func checkLdflags(buildFlags []string, verbose bool) error {
+	for _, value := range getLdflagsValues(buildFlags) {
+		flagList := strings.TrimSpace(value)
+		if !strings.HasPrefix(flagList, "-") {
+			// -ldflags=[pattern=]arg list
+			if _, rest, ok := strings.Cut(flagList, "="); ok {
+				flagList = rest
+			}
+		}
+		fields, err := splitQuotedFlags(flagList)
+		if err != nil {
+			return fmt.Errorf("invalid -ldflags %q: %w", value, err)
+		}
+		if !verbose {
+			continue
+		}
+		for i := 0; i < len(fields); i++ {
+			if fields[i] == "-X" && i+1 < len(fields) {
+				log.Printf("ldflags: -X %s\n", fields[i+1])
+				i++
+			} else if setting, ok := strings.CutPrefix(fields[i], "-X="); ok {
+				log.Printf("ldflags: -X %s\n", setting)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package runmode
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/outrigdev/outrig/server/pkg/runmode/astutil"
+)
+
+func TestParseRunArgs(t *testing.T) {
+	tests := []struct {
+		name                string
+		args                []string
+		expectedBuildFlags  []string
+		expectedGoFiles     []string
+		expectedProgramArgs []string
+	}{
+		{
+			name:               "ldflags with separate value",
+			args:               []string{"-ldflags", "-X main.version=1.2.3", "."},
+			expectedBuildFlags: []string{"-ldflags", "-X main.version=1.2.3"},
+			expectedGoFiles:    []string{"."},
+		},
+		{
+			name:               "ldflags with quoted spaces",
+			args:               []string{"-ldflags", "-X 'main.version=1.2.3 beta' -X main.commit=abc", "main.go"},
+			expectedBuildFlags: []string{"-ldflags", "-X 'main.version=1.2.3 beta' -X main.commit=abc"},
+			expectedGoFiles:    []string{"main.go"},
+		},
+		{
+			name:               "ldflags with equals",
+			args:               []string{"-ldflags=-X main.version=1.2.3 -s -w", "./cmd/app"},
+			expectedBuildFlags: []string{"-ldflags=-X main.version=1.2.3 -s -w"},
+			expectedGoFiles:    []string{"./cmd/app"},
+		},
+		{
+			name:                "double dash ldflags",
+			args:                []string{"--ldflags", "-X main.version=1.2.3", "-race", "main.go", "arg1", "-v"},
+			expectedBuildFlags:  []string{"-ldflags", "-X main.version=1.2.3", "-race"},
+			expectedGoFiles:     []string{"main.go"},
+			expectedProgramArgs: []string{"arg1", "-v"},
+		},
+		{
+			name:               "ldflags value that is not a flag",
+			args:               []string{"--tags", "dev", "-ldflags", "main=-X main.version=1.2.3", "."},
+			expectedBuildFlags: []string{"-tags", "dev", "-ldflags", "main=-X main.version=1.2.3"},
+			expectedGoFiles:    []string{"."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buildFlags, goFiles, programArgs := parseRunArgs(tt.args)
+			if !reflect.DeepEqual(buildFlags, tt.expectedBuildFlags) {
+				t.Errorf("buildFlags: expected %q, got %q", tt.expectedBuildFlags, buildFlags)
+			}
+			if !reflect.DeepEqual(goFiles, tt.expectedGoFiles) {
+				t.Errorf("goFiles: expected %q, got %q", tt.expectedGoFiles, goFiles)
+			}
+			if !reflect.DeepEqual(programArgs, tt.expectedProgramArgs) {
+				t.Errorf("programArgs: expected %q, got %q", tt.expectedProgramArgs, programArgs)
+			}
+		})
+	}
+}
+
+func TestStripGoFlagKeepsLdflags(t *testing.T) {
+	args := []string{"-ldflags", "-o", "-C", "/tmp/dir", "-o", "out", "-gcflags=-N -l"}
+	dir, result := stripGoFlag("C", args)
+	if dir != "/tmp/dir" {
+		t.Errorf("expected -C value /tmp/dir, got %q", dir)
+	}
+	output, result := stripGoFlag("o", result)
+	if output != "out" {
+		t.Errorf("expected -o value out, got %q", output)
+	}
+	expected := []string{"-ldflags", "-o", "-gcflags=-N -l"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestSplitQuotedFlags(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    []string
+		expectError bool
+	}{
+		{input: "-X main.version=1.2.3", expected: []string{"-X", "main.version=1.2.3"}},
+		{input: "  -s   -w\t", expected: []string{"-s", "-w"}},
+		{input: `-X 'main.version=1.2.3 beta' -X "main.name=my app"`, expected: []string{"-X", "main.version=1.2.3 beta", "-X", "main.name=my app"}},
+		{input: `-X main.version='1.2.3`, expected: []string{"-X", "main.version='1.2.3"}},
+		{input: `-X 'main.version=1.2.3`, expectError: true},
+		{input: "", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			fields, err := splitQuotedFlags(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %q", fields)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(fields, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, fields)
+			}
+		})
+	}
+}
+
+func TestCheckLdflags(t *testing.T) {
+	if err := checkLdflags([]string{"-ldflags", "-X 'main.version=1.2.3 beta'", "-tags", "-ldflags="}, true); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkLdflags([]string{"-ldflags=main=-X 'main.version=1.2.3"}, false); err == nil {
+		t.Errorf("expected error for unterminated quote")
+	}
+}
+
+func TestMakeOverlayGoArgsPreservesLdflags(t *testing.T) {
+	moduleDir := t.TempDir()
+	transformState := &astutil.TransformState{
+		GoModPath:  filepath.Join(moduleDir, "go.mod"),
+		MainPkgDir: filepath.Join(moduleDir, "cmd", "app"),
+		TempDir:    t.TempDir(),
+		OverlayMap: map[string]string{},
+	}
+	ldflags := "-X 'main.version=1.2.3 beta' -X main.commit=abc"
+	goArgs, err := makeOverlayGoArgs(transformState, "run", []string{"-ldflags", ldflags, "-race"}, RunModeConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{
+		"run", "-C", moduleDir,
+		"-overlay", filepath.Join(transformState.TempDir, "overlay.json"),
+		"-modfile", filepath.Join(transformState.TempDir, "go.mod"),
+		"-ldflags", ldflags, "-race",
+		"./cmd/app",
+	}
+	if !reflect.DeepEqual(goArgs, expected) {
+		t.Errorf("expected %q, got %q", expected, goArgs)
+	}
+}
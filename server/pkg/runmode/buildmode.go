@@ -0,0 +1,123 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package runmode
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/outrigdev/outrig/server/pkg/runmode/astutil"
+)
+
+// ExecBuildMode performs the same transformation as ExecRunMode but runs "go build" instead of "go run",
+// producing a permanently instrumented binary. The binary connects to the monitor configured at runtime
+// (environment or config file), so no monitor needs to be running at build time.
+func ExecBuildMode(cfg RunModeConfig) error {
+	cfg, buildArgs, err := setupBuildConfiguration(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.RawCmd != nil {
+		return fmt.Errorf("outrig build does not support rawcmd configurations")
+	}
+	if len(buildArgs.ProgramArgs) > 0 {
+		if strings.HasSuffix(cfg.ConfigFile, ".json") {
+			log.Printf("outrig build: ignoring program arguments from %s: %v", cfg.ConfigFile, buildArgs.ProgramArgs)
+		} else {
+			return fmt.Errorf("outrig build takes a single main package or list of .go files (unexpected arguments: %v)", buildArgs.ProgramArgs)
+		}
+	}
+	buildArgs.ProgramArgs = nil
+
+	// -o is stripped before the transform (it doesn't change the transformed output or the transform cache key)
+	outputPath, buildFlags := stripGoFlag("o", buildArgs.BuildFlags)
+	buildArgs.BuildFlags = buildFlags
+	outputPath, err = resolveBuildOutputPath(outputPath, buildArgs)
+	if err != nil {
+		return err
+	}
+
+	transformState, err := performASTTransformation(buildArgs, cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.NoRun {
+		return finishNoRun(transformState, cfg)
+	}
+	defer os.RemoveAll(transformState.TempDir)
+
+	// copy the flags, appending to BuildFlags directly could write into its backing array
+	otherArgs := append(slices.Clone(buildArgs.BuildFlags), "-o", outputPath)
+	goArgs, err := makeOverlayGoArgs(transformState, "build", otherArgs, cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.IsVerbose {
+		log.Printf("Executing go command with args: %v", append([]string{"go"}, goArgs...))
+	}
+
+	cmd := exec.Command("go", goArgs...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	for key, value := range getGoCommandEnv(transformState) {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("go build failed: %w", err)
+	}
+	if cfg.IsVerbose {
+		log.Printf("Wrote instrumented binary: %s", outputPath)
+	}
+	return nil
+}
+
+// resolveBuildOutputPath returns the absolute path of the binary to write.
+// The go command runs in the main module directory (-C), so relative paths are resolved against the
+// working directory here. Like "go build", the default name is the main package directory (or the
+// first .go file when building from a list of files).
+func resolveBuildOutputPath(outputPath string, buildArgs astutil.BuildArgs) (string, error) {
+	if outputPath == "" {
+		if len(buildArgs.GoFiles) == 0 {
+			return "", fmt.Errorf("no main package or .go files to build")
+		}
+		firstArg := buildArgs.GoFiles[0]
+		if strings.HasSuffix(firstArg, ".go") {
+			outputPath = strings.TrimSuffix(filepath.Base(firstArg), ".go")
+		} else {
+			absPkgDir := firstArg
+			if !filepath.IsAbs(absPkgDir) {
+				absPkgDir = filepath.Join(buildArgs.WorkingDir, absPkgDir)
+			}
+			outputPath = filepath.Base(filepath.Clean(absPkgDir))
+		}
+		if getTargetGOOS() == "windows" {
+			outputPath += ".exe"
+		}
+	}
+	if !filepath.IsAbs(outputPath) {
+		// keep a trailing separator, "go build" treats "-o dir/" as an output directory
+		isDir := strings.HasSuffix(outputPath, "/") || strings.HasSuffix(outputPath, string(filepath.Separator))
+		outputPath = filepath.Join(buildArgs.WorkingDir, outputPath)
+		if isDir {
+			outputPath += string(filepath.Separator)
+		}
+	}
+	return outputPath, nil
+}
+
+func getTargetGOOS() string {
+	if goos := os.Getenv("GOOS"); goos != "" {
+		return goos
+	}
+	return runtime.GOOS
+}
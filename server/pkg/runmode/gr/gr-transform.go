@@ -6,6 +6,8 @@ package gr
 import (
 	"fmt"
 	"go/ast"
+	"go/token"
+	"go/types"
 	"log"
 	"strings"
 
@@ -28,27 +30,47 @@ func isPackageBlacklisted(pkgPath string) bool {
 	return false
 }
 
-// createOutrigGoCallPrelude creates the outrig.Go("name").WithTags("...").Run(func() { part
+// splitDirectiveTags splits the comma separated tags of an //outrig directive (empty tags are dropped)
+func splitDirectiveTags(tags string) []string {
+	var result []string
+	for _, tag := range strings.Split(tags, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// createOutrigGoCallPrelude creates the outrig.Go("name").WithTags("...").InheritParent().Run(func() { part
 func createOutrigGoCallPrelude(directive *astutil.OutrigDirective) string {
 	code := fmt.Sprintf("outrig.Go(%q)", directive.Go.Name)
-	if directive.Go.Tags != "" {
-		// Split tags on comma and create separate arguments
-		tags := strings.Split(directive.Go.Tags, ",")
-		var quotedTags []string
-		for _, tag := range tags {
-			tag = strings.TrimSpace(tag)
-			if tag != "" {
-				quotedTags = append(quotedTags, fmt.Sprintf("%q", tag))
-			}
-		}
-		if len(quotedTags) > 0 {
-			code += ".WithTags(" + strings.Join(quotedTags, ", ") + ")"
-		}
+	var quotedTags []string
+	for _, tag := range splitDirectiveTags(directive.Go.Tags) {
+		quotedTags = append(quotedTags, fmt.Sprintf("%q", tag))
+	}
+	if len(quotedTags) > 0 {
+		code += ".WithTags(" + strings.Join(quotedTags, ", ") + ")"
 	}
+	// the child's name is prefixed with its parent's name at runtime (see GoRoutineConfig.InheritParentName)
+	code += ".InheritParent()"
 	code += ".Run(func() {\n"
 	return code
 }
 
+const (
+	hoistedFnName    = "outrigGoFn"  // variable for a go call's function value that is evaluated at the go statement
+	hoistedArgPrefix = "outrigGoArg" // prefix of the variables for a go call's arguments that are evaluated at the go statement
+)
+
+// goCallHoist is a part of a go statement's call (the function value or an argument) that is evaluated
+// into variables before the goroutine starts
+type goCallHoist struct {
+	expr     ast.Expr
+	names    []string // more than one for a multi-value call used as the arguments (go f(g()))
+	convType string   // type the expression is converted to when assigned (for untyped expressions, see getGoCallHoists)
+}
+
 // TransformGoStatementsInPackageWithReplacement iterates over all files in a package and applies go statement transformations using the replacement system
 func TransformGoStatementsInPackageWithReplacement(transformState *astutil.TransformState, pkg *packages.Package) (int, int) {
 	// Skip blacklisted packages
@@ -79,6 +101,7 @@ func TransformGoStatementsInPackageWithReplacement(transformState *astutil.Trans
 			}
 			transformState.ModifiedFiles[filePath] = modifiedFile
 		}
+		modifiedFile.TypesInfo = pkg.TypesInfo
 
 		// Apply go statement transformations using replacements
 		if transformCount := TransformGoStatementsWithReplacement(transformState, modifiedFile); transformCount > 0 {
@@ -103,6 +126,44 @@ func transformSingleGoStatement(transformState *astutil.TransformState, modified
 
 	// Create the outrig.Go().Run(func() { prelude
 	prelude := createOutrigGoCallPrelude(&directive)
+	modifiedFile.GoStmtRewrites = append(modifiedFile.GoStmtRewrites, astutil.GoStmtRewrite{
+		Line:   goPos.Line,
+		Column: goPos.Column,
+		Name:   directive.Go.Name,
+		Tags:   splitDirectiveTags(directive.Go.Tags),
+	})
+
+	// The go statement evaluates the function value and the arguments in the calling goroutine, but in the
+	// func literal they would be evaluated in the new goroutine, so the ones that can change are assigned to
+	// variables first (in a block, so the variables don't leak into the enclosing scope)
+	hoists := getGoCallHoists(modifiedFile.TypesInfo, modifiedFile.FileAST, goStmt.Call)
+	if len(hoists) > 0 {
+		var hoistCode strings.Builder
+		hoistCode.WriteString("{\n")
+		for _, hoist := range hoists {
+			exprPos := transformState.FileSet.Position(hoist.expr.Pos())
+			exprEndPos := transformState.FileSet.Position(hoist.expr.End())
+			hoistCode.WriteString(astutil.MakeLineDirective(exprPos.Filename, exprPos.Line))
+			hoistCode.WriteString(strings.Join(hoist.names, ", ") + " := ")
+			if hoist.convType != "" {
+				hoistCode.WriteString(hoist.convType + "(")
+			}
+			hoistCode.Write(modifiedFile.RawBytes[exprPos.Offset:exprEndPos.Offset])
+			if hoist.convType != "" {
+				hoistCode.WriteString(")")
+			}
+			hoistCode.WriteString("\n")
+
+			// the call uses the variables instead of the expression
+			modifiedFile.AddInsert(int64(exprPos.Offset), strings.Join(hoist.names, ", "))
+			modifiedFile.Replacements = append(modifiedFile.Replacements, astutil.Replacement{
+				Mode:     astutil.ReplacementModeDelete,
+				StartPos: int64(exprPos.Offset),
+				EndPos:   int64(exprEndPos.Offset),
+			})
+		}
+		modifiedFile.AddInsert(int64(goPos.Offset), hoistCode.String())
+	}
 
 	// Delete the "go " keyword
 	deleteReplacement := astutil.Replacement{
@@ -117,11 +178,213 @@ func transformSingleGoStatement(transformState *astutil.TransformState, modified
 	modifiedFile.AddLineDirective(int64(goPos.Offset), goPos.Filename, goPos.Line)
 
 	// Add the closing part after the call
-	modifiedFile.AddInsert(int64(callEndPos.Offset), " })")
+	if len(hoists) > 0 {
+		modifiedFile.AddInsert(int64(callEndPos.Offset), " })}")
+	} else {
+		modifiedFile.AddInsert(int64(callEndPos.Offset), " })")
+	}
+
+	return true
+}
+
+// getGoCallHoists returns the parts of a go statement's call that have to be evaluated before the goroutine
+// starts: method values (go obj.Method()), function variables and fields (go s.handlers[i]()), and arguments
+// that aren't constants, nil, or func literals. Calls of package level functions (including instantiated
+// generic functions), method expressions, and func literals don't need their function value hoisted.
+// Untyped non-constant arguments (comparisons, shifts of untyped constants) get their type from the call,
+// so they are converted to the parameter type when hoisted (outrigGoArg0 := Flag(a < b)), and they are
+// left in the call if that type can't be written in the file.
+// It returns nil if the file has no type info or if a hoisted part contains a go
+// statement (its text is moved, so the nested transformation would be lost).
+func getGoCallHoists(info *types.Info, file *ast.File, call *ast.CallExpr) []goCallHoist {
+	if info == nil {
+		return nil
+	}
+	var hoists []goCallHoist
+	if !isStaticGoCallFunc(info, call.Fun) {
+		hoists = append(hoists, goCallHoist{expr: call.Fun, names: []string{hoistedFnName}})
+	}
+	argNum := 0
+	for _, arg := range call.Args {
+		if !argNeedsHoist(info, arg) {
+			continue
+		}
+		tv, ok := info.Types[arg]
+		if !ok {
+			return nil
+		}
+		var convType string
+		// a comparison is a bool when assigned to a variable, so it only needs the conversion for other types
+		if isUntypedExpr(info, arg) && !types.Identical(tv.Type, types.Typ[types.Bool]) {
+			if convType, ok = getTypeNameAt(info, file, tv.Type, arg.Pos()); !ok {
+				continue
+			}
+		}
+		numValues := 1
+		if tuple, ok := tv.Type.(*types.Tuple); ok {
+			numValues = tuple.Len()
+		}
+		var names []string
+		for i := 0; i < numValues; i++ {
+			names = append(names, fmt.Sprintf("%s%d", hoistedArgPrefix, argNum))
+			argNum++
+		}
+		hoists = append(hoists, goCallHoist{expr: arg, names: names, convType: convType})
+	}
+	for _, hoist := range hoists {
+		if containsGoStmt(hoist.expr) {
+			return nil
+		}
+	}
+	return hoists
+}
 
+// isStaticGoCallFunc returns true if the function value of a go call can't change before the goroutine runs
+// (a func literal, a builtin, a package level function or an instantiation of one, or a method expression)
+func isStaticGoCallFunc(info *types.Info, fun ast.Expr) bool {
+	switch f := ast.Unparen(fun).(type) {
+	case *ast.FuncLit:
+		return true
+	case *ast.IndexExpr:
+		// explicit instantiation of a generic function (go process[int](ch))
+		return isFuncObject(info, f.X)
+	case *ast.IndexListExpr:
+		return isFuncObject(info, f.X)
+	default:
+		return isFuncObject(info, f)
+	}
+}
+
+// isFuncObject returns true if expr names a declared function or a builtin (or a method expression like (*T).Method)
+func isFuncObject(info *types.Info, expr ast.Expr) bool {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		switch info.Uses[e].(type) {
+		case *types.Func, *types.Builtin:
+			return true
+		}
+		return false
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[e]; ok {
+			// method values bind their receiver, so only method expressions are static
+			return sel.Kind() == types.MethodExpr
+		}
+		// package qualified identifier
+		_, isFunc := info.Uses[e.Sel].(*types.Func)
+		return isFunc
+	default:
+		return false
+	}
+}
+
+// argNeedsHoist returns true if the value of a go call's argument could change before the goroutine runs
+func argNeedsHoist(info *types.Info, arg ast.Expr) bool {
+	if _, isFuncLit := ast.Unparen(arg).(*ast.FuncLit); isFuncLit {
+		return false
+	}
+	tv, ok := info.Types[arg]
+	if !ok {
+		return true
+	}
+	if tv.Value != nil || tv.IsNil() || tv.IsType() {
+		return false
+	}
 	return true
 }
 
+// isUntypedExpr returns true if expr is untyped on its own (a comparison, an untyped constant, or an
+// operation on those), the type checker records the type it gets from where it's used
+func isUntypedExpr(info *types.Info, expr ast.Expr) bool {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.Ident:
+		return isUntypedConst(info.Uses[e])
+	case *ast.SelectorExpr:
+		// package qualified constant
+		return isUntypedConst(info.Uses[e.Sel])
+	case *ast.UnaryExpr:
+		return isUntypedExpr(info, e.X)
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return true
+		case token.SHL, token.SHR:
+			// the shift count doesn't affect the type
+			return isUntypedExpr(info, e.X)
+		default:
+			return isUntypedExpr(info, e.X) && isUntypedExpr(info, e.Y)
+		}
+	default:
+		return false
+	}
+}
+
+// isUntypedConst returns true if obj is a constant without a type (const x = 5, true)
+func isUntypedConst(obj types.Object) bool {
+	c, ok := obj.(*types.Const)
+	if !ok {
+		return false
+	}
+	basic, ok := c.Type().(*types.Basic)
+	return ok && basic.Info()&types.IsUntyped != 0
+}
+
+// getTypeNameAt returns how typ is written at pos in file. Only predeclared types and named types without
+// type arguments are handled, ok is false for other types and if the name is shadowed at pos (or the type's
+// package isn't imported, or it's an unexported type of another package).
+func getTypeNameAt(info *types.Info, file *ast.File, typ types.Type, pos token.Pos) (string, bool) {
+	fileScope := info.Scopes[file]
+	if fileScope == nil {
+		return "", false
+	}
+	scope := fileScope.Innermost(pos)
+	if scope == nil {
+		return "", false
+	}
+	switch t := types.Unalias(typ).(type) {
+	case *types.Basic:
+		_, obj := scope.LookupParent(t.Name(), pos)
+		return t.Name(), obj != nil && obj == types.Universe.Lookup(t.Name())
+	case *types.Named:
+		typeName := t.Obj()
+		if t.TypeArgs().Len() > 0 {
+			return "", false
+		}
+		// declared in this package, predeclared (error), or dot imported
+		if _, obj := scope.LookupParent(typeName.Name(), pos); obj == typeName {
+			return typeName.Name(), true
+		}
+		if !typeName.Exported() {
+			return "", false
+		}
+		for _, imp := range file.Imports {
+			pkgName := info.PkgNameOf(imp)
+			if pkgName == nil || pkgName.Imported() != typeName.Pkg() || pkgName.Name() == "_" || pkgName.Name() == "." {
+				continue
+			}
+			if _, obj := scope.LookupParent(pkgName.Name(), pos); obj == pkgName {
+				return pkgName.Name() + "." + typeName.Name(), true
+			}
+		}
+		return "", false
+	default:
+		return "", false
+	}
+}
+
+// containsGoStmt returns true if there is a go statement inside node
+func containsGoStmt(node ast.Node) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if _, ok := n.(*ast.GoStmt); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
 // TransformGoStatementsWithReplacement finds all go statements preceded by //outrig directives and transforms them using the replacement system
 func TransformGoStatementsWithReplacement(transformState *astutil.TransformState, modifiedFile *astutil.ModifiedFile) int {
 	var transformCount int
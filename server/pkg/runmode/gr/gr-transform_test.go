@@ -4,8 +4,14 @@
 package gr
 
 import (
+	"flag"
+	"go/ast"
+	"go/importer"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -28,7 +34,7 @@ func main() {
 		println("test")
 	}()
 }`,
-			expected: `outrig.Go("hello").Run(func() {
+			expected: `outrig.Go("hello").InheritParent().Run(func() {
 		func() {
 			println("test")
 		}()
@@ -43,7 +49,7 @@ func main() {
 		println("test")
 	}()
 }`,
-			expected: `outrig.Go("").Run(func() {
+			expected: `outrig.Go("").InheritParent().Run(func() {
 		func() {
 			println("test")
 		}()
@@ -61,7 +67,7 @@ func main() {
 	//outrig name="worker-task"
 	go worker()
 }`,
-			expected: `outrig.Go("worker-task").Run(func() {
+			expected: `outrig.Go("worker-task").InheritParent().Run(func() {
 		worker()
 	})`,
 		},
@@ -75,7 +81,7 @@ func main() {
 		println("value:", x)
 	}(42)
 }`,
-			expected: `outrig.Go("param-task").Run(func() {
+			expected: `outrig.Go("param-task").InheritParent().Run(func() {
 		func(x int) {
 			println("value:", x)
 		}(42)
@@ -124,8 +130,8 @@ func main() {
 				if !strings.Contains(result, `outrig.Go("`) {
 					t.Errorf("Expected output to contain outrig.Go call, but got:\n%s", result)
 				}
-				if !strings.Contains(result, `.Run(func() {`) {
-					t.Errorf("Expected output to contain .Run(func() call, but got:\n%s", result)
+				if !strings.Contains(result, `.InheritParent().Run(func() {`) {
+					t.Errorf("Expected output to contain .InheritParent().Run(func() call, but got:\n%s", result)
 				}
 				if !strings.Contains(result, `import "github.com/outrigdev/outrig"`) {
 					t.Errorf("Expected output to contain outrig import, but got:\n%s", result)
@@ -142,3 +148,119 @@ func main() {
 	}
 }
 
+var updateGolden = flag.Bool("update", false, "update the .golden files in testdata")
+
+// outrigStubSrc declares the part of the outrig package that transformed files use, so the
+// golden output can be type checked without loading the sdk
+const outrigStubSrc = `package outrig
+
+type GoRoutine struct{}
+
+func Go(name string) *GoRoutine                         { return &GoRoutine{} }
+func (g *GoRoutine) WithTags(tags ...string) *GoRoutine { return g }
+func (g *GoRoutine) InheritParent() *GoRoutine          { return g }
+func (g *GoRoutine) Run(fn func())                      { fn() }
+`
+
+// outrigStubImporter imports the outrig stub for the outrig package and everything else from the default importer
+type outrigStubImporter struct {
+	outrigPkg *types.Package
+}
+
+func (imp outrigStubImporter) Import(path string) (*types.Package, error) {
+	if path == "github.com/outrigdev/outrig" {
+		return imp.outrigPkg, nil
+	}
+	return importer.Default().Import(path)
+}
+
+// typeCheckResult type checks the transformed output of a testdata file
+func typeCheckResult(t *testing.T, result []byte) error {
+	t.Helper()
+	fset := token.NewFileSet()
+	stubFile, err := parser.ParseFile(fset, "outrig.go", outrigStubSrc, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse the outrig stub: %v", err)
+	}
+	outrigPkg, err := (&types.Config{}).Check("github.com/outrigdev/outrig", fset, []*ast.File{stubFile}, nil)
+	if err != nil {
+		t.Fatalf("Failed to type check the outrig stub: %v", err)
+	}
+	resultFile, err := parser.ParseFile(fset, "result.go", result, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	typesConfig := &types.Config{Importer: outrigStubImporter{outrigPkg: outrigPkg}}
+	_, err = typesConfig.Check("main", fset, []*ast.File{resultFile}, nil)
+	return err
+}
+
+// TestTransformGoStatementsGolden transforms the (type checked) files in testdata and compares the
+// result with the matching .golden file (run with -update to rewrite them)
+func TestTransformGoStatementsGolden(t *testing.T) {
+	inputFiles, err := filepath.Glob(filepath.Join("testdata", "*.go"))
+	if err != nil {
+		t.Fatalf("Failed to list testdata: %v", err)
+	}
+	if len(inputFiles) == 0 {
+		t.Fatalf("No test files found in testdata")
+	}
+	for _, inputFile := range inputFiles {
+		t.Run(filepath.Base(inputFile), func(t *testing.T) {
+			input, err := os.ReadFile(inputFile)
+			if err != nil {
+				t.Fatalf("Failed to read input: %v", err)
+			}
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, inputFile, input, parser.ParseComments)
+			if err != nil {
+				t.Fatalf("Failed to parse input: %v", err)
+			}
+			typesInfo := &types.Info{
+				Types:      make(map[ast.Expr]types.TypeAndValue),
+				Uses:       make(map[*ast.Ident]types.Object),
+				Defs:       make(map[*ast.Ident]types.Object),
+				Selections: make(map[*ast.SelectorExpr]*types.Selection),
+				Instances:  make(map[*ast.Ident]types.Instance),
+				Implicits:  make(map[ast.Node]types.Object),
+				Scopes:     make(map[ast.Node]*types.Scope),
+			}
+			typesConfig := &types.Config{Importer: importer.Default()}
+			if _, err := typesConfig.Check("main", fset, []*ast.File{node}, typesInfo); err != nil {
+				t.Fatalf("Failed to type check input: %v", err)
+			}
+
+			transformState := &astutil.TransformState{
+				FileSet:       fset,
+				ModifiedFiles: make(map[string]*astutil.ModifiedFile),
+			}
+			modifiedFile := &astutil.ModifiedFile{
+				FileAST:   node,
+				RawBytes:  input,
+				TypesInfo: typesInfo,
+			}
+			if TransformGoStatementsWithReplacement(transformState, modifiedFile) == 0 {
+				t.Fatalf("Expected go statements to be transformed")
+			}
+			result := astutil.ApplyReplacements(modifiedFile.RawBytes, modifiedFile.Replacements)
+			if err := typeCheckResult(t, result); err != nil {
+				t.Fatalf("Transformed output does not type check: %v\n%s", err, result)
+			}
+
+			goldenFile := strings.TrimSuffix(inputFile, ".go") + ".golden"
+			if *updateGolden {
+				if err := os.WriteFile(goldenFile, result, 0644); err != nil {
+					t.Fatalf("Failed to write golden file: %v", err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(goldenFile)
+			if err != nil {
+				t.Fatalf("Failed to read golden file: %v", err)
+			}
+			if string(result) != string(expected) {
+				t.Errorf("Transformed output does not match %s, got:\n%s", goldenFile, result)
+			}
+		})
+	}
+}
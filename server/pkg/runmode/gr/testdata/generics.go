@@ -0,0 +1,37 @@
+package main
+
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+func sum[T Number](vals []T, out chan<- T) {
+	var total T
+	for _, v := range vals {
+		total += v
+	}
+	out <- total
+}
+
+type Pool[T any] struct {
+	items chan T
+}
+
+func (p *Pool[T]) drain(fn func(T)) {
+	for item := range p.items {
+		fn(item)
+	}
+}
+
+func startAll[T Number](vals []T, fn func(T)) chan T {
+	out := make(chan T, 2)
+	go sum(vals, out)
+	go sum[T](vals[1:], out)
+	p := &Pool[T]{items: make(chan T)}
+	go p.drain(fn)
+	go fn(vals[0])
+	return out
+}
+
+func main() {
+	startAll([]int{1, 2, 3}, func(int) {})
+}
@@ -0,0 +1,30 @@
+package main
+
+type Worker struct {
+	jobs chan string
+}
+
+func (w *Worker) start(name string) {
+	//outrig name="worker" tags="a,b"
+	go func(n string) {
+		for job := range w.jobs {
+			println(n, job)
+		}
+	}(name)
+	go func() {
+		go w.process("nested")
+	}()
+	process := func(job string) {
+		println(job)
+	}
+	go process("literal")
+}
+
+func (w *Worker) process(job string) {
+	println(job)
+}
+
+func main() {
+	w := &Worker{jobs: make(chan string)}
+	w.start("main")
+}
@@ -0,0 +1,28 @@
+package main
+
+type Server struct {
+	name     string
+	handlers []func(int)
+}
+
+func (s Server) serve(id int) {
+	println(s.name, id)
+}
+
+func (s *Server) stop() {}
+
+func (s *Server) run(ch chan int) {
+	go s.serve(<-ch)
+	go (*Server).stop(s)
+	serve := s.serve
+	go serve(1)
+	for i := range s.handlers {
+		go s.handlers[i](i * 2)
+	}
+	go println("done", s.name)
+}
+
+func main() {
+	s := &Server{name: "main"}
+	s.run(make(chan int, 1))
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"time"
+)
+
+type Flag bool
+
+func report(ok bool, label string) {
+	println(ok, label)
+}
+
+func reportFlag(flag Flag) {
+	println(flag)
+}
+
+func reportAny(v any) {
+	println(v)
+}
+
+func reportMode(mode os.FileMode) {
+	println(mode)
+}
+
+func reportMonth(month time.Month) {
+	println(month)
+}
+
+func wait(d time.Duration) {
+	time.Sleep(d)
+}
+
+func check(a, b int, p *int, n uint) {
+	go report(a == b, "eq")
+	go reportFlag(a < b)
+	go reportAny(a != b)
+	go report(!(a > b), "not")
+	go reportFlag(p != nil && *p > 0)
+	go reportMonth(1 << n)
+	// os.FileMode is an alias of fs.FileMode and io/fs isn't imported, so the argument can't be converted
+	go reportMode(1 << n)
+	go wait(time.Second << n)
+	{
+		type Flag int
+		// the parameter type is shadowed, so the argument can't be converted
+		go reportFlag(a >= b)
+	}
+}
+
+func main() {
+	x := 2
+	check(1, 2, &x, 3)
+}
@@ -0,0 +1,192 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package runmode
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/comm"
+	"github.com/outrigdev/outrig/pkg/config"
+)
+
+const (
+	PortProbeTimeout  = 500 * time.Millisecond
+	PortHolderTimeout = 2 * time.Second
+)
+
+// PortConflictError is returned when the monitor's TCP port accepts connections but
+// the process listening on it is not an outrig monitor
+type PortConflictError struct {
+	Addr       string
+	Port       int
+	HolderPid  int    // 0 if unknown
+	HolderName string // "" if unknown
+}
+
+func (e *PortConflictError) Error() string {
+	holder := "another program"
+	if e.HolderName != "" && e.HolderPid != 0 {
+		holder = fmt.Sprintf("%s (pid %d)", e.HolderName, e.HolderPid)
+	} else if e.HolderPid != 0 {
+		holder = fmt.Sprintf("pid %d", e.HolderPid)
+	}
+	return fmt.Sprintf("port %d (%s) is in use by %s, not the outrig monitor", e.Port, e.Addr, holder)
+}
+
+// getLocalMonitorTcpAddr returns the local TCP address the monitor is expected on ("" if there isn't one)
+func getLocalMonitorTcpAddr(monitorConfig *config.Config) string {
+	for _, addr := range comm.MakeConnectAddrs(monitorConfig) {
+		if addr.IsTcp() && addr.IsLocal() {
+			return addr.DialAddr
+		}
+	}
+	return ""
+}
+
+// probeMonitorPort checks whether some other program is listening on the monitor's local TCP port.
+// Only call this after failing to connect to the monitor (any listener is then not a monitor).
+// Returns nil when the port is free (or the monitor is not local).
+func probeMonitorPort(monitorConfig *config.Config) *PortConflictError {
+	tcpAddr := getLocalMonitorTcpAddr(monitorConfig)
+	if tcpAddr == "" {
+		return nil
+	}
+	_, portStr, err := net.SplitHostPort(tcpAddr)
+	if err != nil {
+		return nil
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil
+	}
+	conn, err := net.DialTimeout("tcp", tcpAddr, PortProbeTimeout)
+	if err != nil {
+		return nil
+	}
+	conn.Close()
+	conflict := &PortConflictError{Addr: tcpAddr, Port: port}
+	conflict.HolderPid, conflict.HolderName = findPortHolder(port)
+	return conflict
+}
+
+// findFreeLocalPort asks the OS for an unused localhost TCP port
+func findFreeLocalPort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("cannot find a free port: %w", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// findPortHolder returns the pid and name of the process listening on the given TCP port (best effort, 0 and "" if unknown)
+func findPortHolder(port int) (int, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), PortHolderTimeout)
+	defer cancel()
+	if runtime.GOOS == "windows" {
+		output, err := exec.CommandContext(ctx, "netstat", "-ano", "-p", "TCP").Output()
+		if err != nil {
+			return 0, ""
+		}
+		pid := parseNetstatListenPid(string(output), port)
+		if pid == 0 {
+			return 0, ""
+		}
+		output, err = exec.CommandContext(ctx, "tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH").Output()
+		if err != nil {
+			return pid, ""
+		}
+		return pid, parseTasklistName(string(output))
+	}
+	output, err := exec.CommandContext(ctx, "lsof", "-nP", fmt.Sprintf("-iTCP:%d", port), "-sTCP:LISTEN", "-Fpc").Output()
+	if err == nil {
+		if pid, name := parseLsofOutput(string(output)); pid != 0 {
+			return pid, name
+		}
+	}
+	output, err = exec.CommandContext(ctx, "ss", "-Hltnp", fmt.Sprintf("sport = :%d", port)).Output()
+	if err == nil {
+		return parseSsOutput(string(output))
+	}
+	return 0, ""
+}
+
+// parseLsofOutput parses "lsof -Fpc" output (lines of "p<pid>" and "c<command>"), returning the first process
+func parseLsofOutput(output string) (int, string) {
+	var pid int
+	var name string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) < 2 {
+			continue
+		}
+		switch line[0] {
+		case 'p':
+			if pid != 0 {
+				return pid, name
+			}
+			pid, _ = strconv.Atoi(line[1:])
+		case 'c':
+			if pid != 0 && name == "" {
+				name = line[1:]
+			}
+		}
+	}
+	return pid, name
+}
+
+var ssUsersRegex = regexp.MustCompile(`users:\(\("([^"]*)",pid=(\d+)`)
+
+// parseSsOutput parses "ss -ltnp" output, process info is only shown for processes the user can see
+func parseSsOutput(output string) (int, string) {
+	m := ssUsersRegex.FindStringSubmatch(output)
+	if m == nil {
+		return 0, ""
+	}
+	pid, _ := strconv.Atoi(m[2])
+	return pid, m[1]
+}
+
+// parseNetstatListenPid finds the pid listening on port in Windows "netstat -ano" output
+func parseNetstatListenPid(output string, port int) int {
+	portSuffix := ":" + strconv.Itoa(port)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 5 || fields[0] != "TCP" || fields[3] != "LISTENING" {
+			continue
+		}
+		if !strings.HasSuffix(fields[1], portSuffix) {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[4])
+		if err == nil {
+			return pid
+		}
+	}
+	return 0
+}
+
+// parseTasklistName returns the image name from Windows "tasklist /FO CSV /NH" output
+func parseTasklistName(output string) string {
+	line := strings.TrimSpace(output)
+	if !strings.HasPrefix(line, `"`) {
+		return ""
+	}
+	name, _, found := strings.Cut(line[1:], `"`)
+	if !found {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,53 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package runmode
+
+import (
+	"testing"
+)
+
+func TestParsePortHolderOutput(t *testing.T) {
+	pid, name := parseLsofOutput("p4321\ncnc\nf3\np999\ncother\n")
+	if pid != 4321 || name != "nc" {
+		t.Errorf("lsof: got (%d, %q), expected (4321, \"nc\")", pid, name)
+	}
+
+	pid, name = parseSsOutput(`LISTEN 0      1            0.0.0.0:5005      0.0.0.0:*    users:(("python3",pid=1234,fd=3))` + "\n")
+	if pid != 1234 || name != "python3" {
+		t.Errorf("ss: got (%d, %q), expected (1234, \"python3\")", pid, name)
+	}
+	pid, _ = parseSsOutput("LISTEN 0      1            0.0.0.0:5005      0.0.0.0:*\n")
+	if pid != 0 {
+		t.Errorf("ss without process info: got pid %d, expected 0", pid)
+	}
+
+	netstat := `
+Active Connections
+
+  Proto  Local Address          Foreign Address        State           PID
+  TCP    0.0.0.0:135            0.0.0.0:0              LISTENING       1016
+  TCP    127.0.0.1:50050        0.0.0.0:0              LISTENING       77
+  TCP    127.0.0.1:5005         127.0.0.1:61234        ESTABLISHED     88
+  TCP    127.0.0.1:5005         0.0.0.0:0              LISTENING       5512
+`
+	if pid := parseNetstatListenPid(netstat, 5005); pid != 5512 {
+		t.Errorf("netstat: got pid %d, expected 5512", pid)
+	}
+	if name := parseTasklistName(`"node.exe","5512","Console","1","45,120 K"` + "\r\n"); name != "node.exe" {
+		t.Errorf("tasklist: got %q, expected \"node.exe\"", name)
+	}
+}
+
+func TestPortConflictErrorMessage(t *testing.T) {
+	err := &PortConflictError{Addr: "127.0.0.1:5005", Port: 5005, HolderPid: 42, HolderName: "nginx"}
+	expected := "port 5005 (127.0.0.1:5005) is in use by nginx (pid 42), not the outrig monitor"
+	if err.Error() != expected {
+		t.Errorf("got %q, expected %q", err.Error(), expected)
+	}
+	err = &PortConflictError{Addr: "127.0.0.1:5005", Port: 5005}
+	expected = "port 5005 (127.0.0.1:5005) is in use by another program, not the outrig monitor"
+	if err.Error() != expected {
+		t.Errorf("got %q, expected %q", err.Error(), expected)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package runmode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/server/pkg/runmode/astutil"
+)
+
+// TransformReportVersion is bumped whenever a field of the report changes meaning or is removed
+const TransformReportVersion = 1
+
+// ReportStdout as the report path writes the report to stdout
+const ReportStdout = "-"
+
+// TransformReport is the machine readable list of changes "outrig run --norun --report" / "outrig build
+// --norun --report" made to the source files (so the instrumentation can be audited, e.g. in CI)
+type TransformReport struct {
+	Version    int                   `json:"version"`
+	SDKVersion string                `json:"sdkversion"`
+	GoModPath  string                `json:"gomodpath"`
+	GoWorkPath string                `json:"goworkpath,omitempty"`
+	MainPkgDir string                `json:"mainpkgdir"`
+	TempDir    string                `json:"tempdir"`
+	ModFile    string                `json:"modfile,omitempty"` // temp go.mod with the SDK dependency (empty for vendored builds)
+	VendorMode bool                  `json:"vendormode,omitempty"`
+	Main       *MainInjectionReport  `json:"main"`
+	Files      []FileTransformReport `json:"files"` // sorted by path
+	NumGoStmts int                   `json:"numgostmts"`
+}
+
+// MainInjectionReport describes the outrig.Init call added to main()
+type MainInjectionReport struct {
+	File        string `json:"file"`
+	Line        int    `json:"line"`   // line of main's opening brace
+	Column      int    `json:"column"` // column of main's opening brace
+	Code        string `json:"code"`   // the injected statements
+	ImportAdded bool   `json:"importadded"`
+}
+
+// FileTransformReport describes one modified source file
+type FileTransformReport struct {
+	Path        string                `json:"path"`
+	OverlayPath string                `json:"overlaypath"` // the transformed file the go command builds instead
+	ImportAdded bool                  `json:"importadded"`
+	MainFile    bool                  `json:"mainfile,omitempty"`
+	GoStmts     []GoStmtRewriteReport `json:"gostmts,omitempty"`
+}
+
+// GoStmtRewriteReport is a go statement that was rewritten to outrig.Go(...).Run(...)
+type GoStmtRewriteReport struct {
+	Line   int      `json:"line"`
+	Column int      `json:"column"`
+	Name   string   `json:"name,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// makeTransformReport builds the report from a transform state (the state must not come from the transform
+// cache, cached states don't have the per-file changes)
+func makeTransformReport(transformState *astutil.TransformState) *TransformReport {
+	report := &TransformReport{
+		Version:    TransformReportVersion,
+		SDKVersion: config.OutrigSDKVersion,
+		GoModPath:  transformState.GoModPath,
+		GoWorkPath: transformState.GoWorkPath,
+		MainPkgDir: transformState.MainPkgDir,
+		TempDir:    transformState.TempDir,
+		VendorMode: transformState.VendorMode,
+		Files:      []FileTransformReport{},
+	}
+	if !transformState.VendorMode {
+		report.ModFile = filepath.Join(transformState.TempDir, "go.mod")
+	}
+	for origPath, modifiedFile := range transformState.ModifiedFiles {
+		if !modifiedFile.Modified {
+			continue
+		}
+		fileReport := FileTransformReport{
+			Path:        origPath,
+			OverlayPath: transformState.OverlayMap[origPath],
+			ImportAdded: modifiedFile.OutrigImportAdded,
+			MainFile:    modifiedFile.MainInjectPos != nil,
+		}
+		for _, rewrite := range modifiedFile.GoStmtRewrites {
+			fileReport.GoStmts = append(fileReport.GoStmts, GoStmtRewriteReport{
+				Line:   rewrite.Line,
+				Column: rewrite.Column,
+				Name:   rewrite.Name,
+				Tags:   rewrite.Tags,
+			})
+		}
+		report.NumGoStmts += len(fileReport.GoStmts)
+		if modifiedFile.MainInjectPos != nil {
+			report.Main = &MainInjectionReport{
+				File:        origPath,
+				Line:        modifiedFile.MainInjectPos.Line,
+				Column:      modifiedFile.MainInjectPos.Column,
+				Code:        outrigInitText,
+				ImportAdded: modifiedFile.OutrigImportAdded,
+			}
+		}
+		report.Files = append(report.Files, fileReport)
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].Path < report.Files[j].Path
+	})
+	return report
+}
+
+// writeTransformReport writes the report as JSON to reportPath (or stdout for ReportStdout)
+func writeTransformReport(transformState *astutil.TransformState, reportPath string) error {
+	barr, err := json.MarshalIndent(makeTransformReport(transformState), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal transform report: %w", err)
+	}
+	barr = append(barr, '\n')
+	if reportPath == ReportStdout {
+		_, err = os.Stdout.Write(barr)
+		return err
+	}
+	err = os.WriteFile(reportPath, barr, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write transform report: %w", err)
+	}
+	return nil
+}
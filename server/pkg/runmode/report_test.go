@@ -0,0 +1,85 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package runmode
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/outrigdev/outrig/server/pkg/runmode/astutil"
+	"github.com/outrigdev/outrig/server/pkg/runmode/gr"
+)
+
+const reportTestMain = `package main
+
+func main() {
+	//outrig name="worker" tags="a, b"
+	go worker()
+	go func() {}()
+}
+
+func worker() {}
+`
+
+const reportTestOther = `package main
+
+func helper() {}
+`
+
+func TestMakeTransformReport(t *testing.T) {
+	fset := token.NewFileSet()
+	transformState := &astutil.TransformState{
+		FileSet:       fset,
+		ModifiedFiles: make(map[string]*astutil.ModifiedFile),
+		OverlayMap:    make(map[string]string),
+		GoModPath:     "/src/go.mod",
+		TempDir:       "/tmp/outrig_tmp_1",
+	}
+	for path, src := range map[string]string{"/src/main.go": reportTestMain, "/src/other.go": reportTestOther} {
+		node, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", path, err)
+		}
+		transformState.ModifiedFiles[path] = &astutil.ModifiedFile{FileAST: node, RawBytes: []byte(src)}
+	}
+
+	mainFile := transformState.ModifiedFiles["/src/main.go"]
+	astutil.AddOutrigImportReplacement(transformState, mainFile)
+	if !modifyMainFunctionWithReplacement(transformState, mainFile) {
+		t.Fatalf("main() was not modified")
+	}
+	if gr.TransformGoStatementsWithReplacement(transformState, mainFile) != 2 {
+		t.Fatalf("expected 2 go statements to be transformed")
+	}
+	mainFile.Modified = true
+	transformState.OverlayMap["/src/main.go"] = "/tmp/outrig_tmp_1/main.go"
+
+	report := makeTransformReport(transformState)
+	if report.Version != TransformReportVersion || report.ModFile != "/tmp/outrig_tmp_1/go.mod" {
+		t.Errorf("unexpected report header: %+v", report)
+	}
+	if len(report.Files) != 1 {
+		t.Fatalf("expected only the modified file in the report, got %+v", report.Files)
+	}
+	fileReport := report.Files[0]
+	if fileReport.Path != "/src/main.go" || fileReport.OverlayPath != "/tmp/outrig_tmp_1/main.go" || !fileReport.ImportAdded || !fileReport.MainFile {
+		t.Errorf("unexpected file report: %+v", fileReport)
+	}
+	expectedGoStmts := []GoStmtRewriteReport{
+		{Line: 5, Column: 2, Name: "worker", Tags: []string{"a", "b"}},
+		{Line: 6, Column: 2},
+	}
+	if !reflect.DeepEqual(fileReport.GoStmts, expectedGoStmts) {
+		t.Errorf("go statements = %+v, expected %+v", fileReport.GoStmts, expectedGoStmts)
+	}
+	if report.NumGoStmts != 2 {
+		t.Errorf("NumGoStmts = %d, expected 2", report.NumGoStmts)
+	}
+	expectedMain := &MainInjectionReport{File: "/src/main.go", Line: 3, Column: 13, Code: outrigInitText, ImportAdded: true}
+	if !reflect.DeepEqual(report.Main, expectedMain) {
+		t.Errorf("main = %+v, expected %+v", report.Main, expectedMain)
+	}
+}
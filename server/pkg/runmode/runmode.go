@@ -5,11 +5,15 @@ package runmode
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"maps"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -42,7 +46,13 @@ type RunModeConfig struct {
 	IsVerbose          bool
 	NoRun              bool
 	NoMonitorAutostart bool
+	MonitorFreePort    bool // when the monitor port is held by another program, autostart the monitor on a free port
+	NoTransformCache   bool // always load and transform the source files (don't use the transform cache)
+	NoToolchainPin     bool // don't set GOTOOLCHAIN to the detected toolchain version (see astutil.TransformState.GetToolchainPin)
 	ConfigFile         string
+	Meta               []string // key=value app run metadata (passed to the app in OUTRIG_APPMETA)
+	Report             string   // with NoRun, write a JSON report of the transform to this path (ReportStdout for stdout)
+	Watch              bool     // rebuild and restart the program when its source files change (see execWatchMode)
 	RawCmd             *RawCmdDef
 }
 
@@ -309,6 +319,11 @@ func stripGoFlag(flag string, args []string) (string, []string) {
 			continue
 		} else {
 			result = append(result, arg)
+			// keep the value of other flags as is (e.g. "-ldflags -o" must not strip -o)
+			if flagsWithArgs[arg] && i+1 < len(args) {
+				i++
+				result = append(result, args[i])
+			}
 		}
 	}
 	return extractedValue, result
@@ -320,7 +335,7 @@ func getRelativeMainPkgDir(transformState *astutil.TransformState) (string, erro
 	mainModuleDir := filepath.Dir(transformState.GoModPath)
 
 	// Calculate relative path from module directory to package directory
-	relPath, err := filepath.Rel(mainModuleDir, transformState.MainPkg.Dir)
+	relPath, err := filepath.Rel(mainModuleDir, transformState.MainPkgDir)
 	if err != nil {
 		return "", fmt.Errorf("failed to calculate relative path: %w", err)
 	}
@@ -330,82 +345,44 @@ func getRelativeMainPkgDir(transformState *astutil.TransformState) (string, erro
 	return "./" + relPath, nil
 }
 
+// getMainPkgArgs returns the main package argument(s) for the go command (run in the main module directory):
+// the relative main package directory, or the main package files when a list of .go files was given
+// (like "go run main.go", build constraints don't apply to them)
+func getMainPkgArgs(transformState *astutil.TransformState) ([]string, error) {
+	if len(transformState.MainGoFiles) == 0 {
+		packagePath, err := getRelativeMainPkgDir(transformState)
+		if err != nil {
+			return nil, err
+		}
+		return []string{packagePath}, nil
+	}
+	mainModuleDir := filepath.Dir(transformState.GoModPath)
+	rtn := make([]string, 0, len(transformState.MainGoFiles))
+	for _, goFile := range transformState.MainGoFiles {
+		relPath, err := filepath.Rel(mainModuleDir, goFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate relative path: %w", err)
+		}
+		rtn = append(rtn, "./"+filepath.ToSlash(relPath))
+	}
+	return rtn, nil
+}
+
 // setupBuildArgs prepares build arguments from the config
 func setupBuildArgs(cfg RunModeConfig) (astutil.BuildArgs, error) {
-	// Check if user already provided -overlay flag
-	for _, arg := range cfg.Args {
+	buildFlags, goFiles, programArgs := parseRunArgs(cfg.Args)
+
+	// Check if user already provided -overlay or -modfile flags
+	for _, arg := range buildFlags {
 		if arg == "-overlay" || strings.HasPrefix(arg, "-overlay=") {
 			return astutil.BuildArgs{}, fmt.Errorf("cannot use -overlay flag with 'outrig run' as it conflicts with AST rewriting")
 		}
-	}
-
-	// Check if user already provided -modfile flag
-	for _, arg := range cfg.Args {
 		if arg == "-modfile" || strings.HasPrefix(arg, "-modfile=") {
 			return astutil.BuildArgs{}, fmt.Errorf("cannot use -modfile flag with 'outrig run' as it conflicts with go.mod handling")
 		}
 	}
-
-	// Parse arguments in three phases: flags, go files/package, program args
-	var goFiles []string
-	var programArgs []string
-	var buildFlags []string
-
-	const (
-		parseFlags = iota
-		parseGoFiles
-		parseProgArgs
-	)
-
-	state := parseFlags
-
-	for i := 0; i < len(cfg.Args); i++ {
-		arg := cfg.Args[i]
-
-		switch state {
-		case parseFlags:
-			if strings.HasPrefix(arg, "-") {
-				// This is a flag
-				buildFlags = append(buildFlags, arg)
-
-				// Check if this flag takes an argument
-				flagName := arg
-				if strings.Contains(arg, "=") {
-					// Flag is in -flag=value format, no need to consume next arg
-					continue
-				}
-
-				if flagsWithArgs[flagName] && i+1 < len(cfg.Args) {
-					// This flag takes an argument, consume the next argument too
-					i++
-					buildFlags = append(buildFlags, cfg.Args[i])
-				}
-			} else {
-				// Hit first non-flag, switch to parsing go files/package
-				state = parseGoFiles
-				i-- // Re-process this argument in the new state
-			}
-
-		case parseGoFiles:
-			if strings.HasSuffix(arg, ".go") {
-				// This is a .go file, collect it
-				goFiles = append(goFiles, arg)
-			} else {
-				// This is either a package or the first program arg
-				if len(goFiles) == 0 {
-					// No .go files seen yet, this must be a package
-					goFiles = append(goFiles, arg)
-				} else {
-					// We already have .go files, this is a program arg
-					state = parseProgArgs
-					i-- // Re-process this argument in the new state
-				}
-			}
-
-		case parseProgArgs:
-			// Everything from here on is a program argument
-			programArgs = append(programArgs, arg)
-		}
+	if err := checkLdflags(buildFlags, cfg.IsVerbose); err != nil {
+		return astutil.BuildArgs{}, err
 	}
 
 	// Determine working directory
@@ -426,6 +403,11 @@ func setupBuildArgs(cfg RunModeConfig) (astutil.BuildArgs, error) {
 		return astutil.BuildArgs{}, fmt.Errorf("failed to get absolute path for working directory %s: %w", workingDir, err)
 	}
 
+	// A JSON config file is handled by setupBuildConfiguration (its entry determines the main package and config)
+	if len(goFiles) > 0 && strings.HasSuffix(goFiles[0], ".json") {
+		return astutil.BuildArgs{GoFiles: goFiles, BuildFlags: buildFlags, WorkingDir: absWorkingDir}, nil
+	}
+
 	// Determine MainDir and file patterns
 	mainDir, filePatterns, err := astutil.DetermineMainDirAndPatterns(absWorkingDir, goFiles)
 	if err != nil {
@@ -443,7 +425,7 @@ func setupBuildArgs(cfg RunModeConfig) (astutil.BuildArgs, error) {
 	var configObj config.Config
 	if loadedCfg == nil {
 		configObj = *config.DefaultConfig()
-		configSource = "default-config"
+		configSource = config.ConfigSourceDefault
 	} else {
 		configObj = *loadedCfg
 	}
@@ -454,40 +436,37 @@ func setupBuildArgs(cfg RunModeConfig) (astutil.BuildArgs, error) {
 
 	// Load the specified Go files using the new astutil.LoadGoFiles function
 	buildArgs := astutil.BuildArgs{
-		GoFiles:      goFiles,
-		BuildFlags:   buildFlags,
-		ProgramArgs:  programArgs,
-		WorkingDir:   absWorkingDir,
-		MainDir:      mainDir,
-		FilePatterns: filePatterns,
-		Config:       configObj,
-		Verbose:      cfg.IsVerbose,
-		ConfigFile:   cfg.ConfigFile,
+		GoFiles:        goFiles,
+		BuildFlags:     buildFlags,
+		ProgramArgs:    programArgs,
+		WorkingDir:     absWorkingDir,
+		MainDir:        mainDir,
+		FilePatterns:   filePatterns,
+		Config:         configObj,
+		Verbose:        cfg.IsVerbose,
+		ConfigFile:     cfg.ConfigFile,
+		NoToolchainPin: cfg.NoToolchainPin,
 	}
 
 	return buildArgs, nil
 }
 
 // loadFilesAndSetupTransformState loads Go files and sets up transform state
-// Note: This function may call os.Exit() on errors
-func loadFilesAndSetupTransformState(buildArgs astutil.BuildArgs, cfg RunModeConfig) *astutil.TransformState {
+func loadFilesAndSetupTransformState(buildArgs astutil.BuildArgs, cfg RunModeConfig) (*astutil.TransformState, error) {
 	transformState, err := astutil.LoadGoFiles(buildArgs)
 	if err != nil {
-		log.Printf("#outrig failed to load Go files for AST rewriting: %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to load Go files for AST rewriting: %w", err)
 	}
 
 	// Check for compilation errors in the loaded packages
 	if packages.PrintErrors(transformState.Packages) > 0 {
-		log.Printf("#outrig cannot proceed with AST rewriting due to compilation errors")
-		os.Exit(1)
+		return nil, fmt.Errorf("cannot proceed with AST rewriting due to compilation errors")
 	}
 
 	// Create temporary directory for all temp files
 	tempDir, err := os.MkdirTemp("", "outrig_tmp_*")
 	if err != nil {
-		log.Printf("#outrig failed to create temporary directory: %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
 	}
 	if cfg.IsVerbose {
 		log.Printf("Using temp directory: %s", tempDir)
@@ -499,7 +478,7 @@ func loadFilesAndSetupTransformState(buildArgs astutil.BuildArgs, cfg RunModeCon
 	transformState.TempDir = tempDir
 	transformState.Verbose = cfg.IsVerbose
 
-	return transformState
+	return transformState, nil
 }
 
 // downloadOutrigSDK runs go mod download to populate go.sum in the temp directory
@@ -509,7 +488,10 @@ func downloadOutrigSDK(transformState *astutil.TransformState, verbose bool) err
 	cmd := exec.Command("go", args...)
 
 	// Set GOWORK=off to disable workspace mode and GOTOOLCHAIN for version consistency
-	cmd.Env = append(os.Environ(), "GOWORK=off", "GOTOOLCHAIN="+transformState.ToolchainVersion)
+	cmd.Env = append(os.Environ(), "GOWORK=off")
+	if toolchainPin := transformState.GetToolchainPin(); toolchainPin != "" {
+		cmd.Env = append(cmd.Env, "GOTOOLCHAIN="+toolchainPin)
+	}
 
 	if verbose {
 		log.Printf("Executing: go %v", strings.Join(args, " "))
@@ -547,30 +529,29 @@ func determineWorkingDir(jsonFilePath string, execConfigCwd string) (string, err
 	return absWorkingDir, nil
 }
 
-// handleJSONConfig processes a JSON configuration file for run mode
-func handleJSONConfig(jsonFilePath string, buildFlags []string, verbose bool) (astutil.BuildArgs, error) {
-	// JSON mode doesn't allow build flags
-	if len(buildFlags) > 0 {
-		return astutil.BuildArgs{}, fmt.Errorf("build flags are not allowed when using JSON configuration file")
-	}
-
-	// Read and parse the JSON configuration file
+// readJSONConfigFile reads a JSON configuration file and validates it against the config schema
+// (so type errors are reported with their path) before parsing it
+func readJSONConfigFile(jsonFilePath string) (*config.Config, error) {
 	jsonData, err := os.ReadFile(jsonFilePath)
 	if err != nil {
-		return astutil.BuildArgs{}, fmt.Errorf("failed to read JSON config file %s: %w", jsonFilePath, err)
+		return nil, fmt.Errorf("failed to read JSON config file %s: %w", jsonFilePath, err)
 	}
-
-	var config config.Config
-	err = json.Unmarshal(jsonData, &config)
+	if err := config.ValidateConfigJSON(jsonData); err != nil {
+		return nil, fmt.Errorf("invalid JSON config file %s:\n%w", jsonFilePath, err)
+	}
+	var parsedConfig config.Config
+	err = json.Unmarshal(jsonData, &parsedConfig)
 	if err != nil {
-		return astutil.BuildArgs{}, fmt.Errorf("failed to parse JSON config file %s: %w", jsonFilePath, err)
+		return nil, fmt.Errorf("failed to parse JSON config file %s: %w", jsonFilePath, err)
 	}
+	return &parsedConfig, nil
+}
 
-	// Validate ExecConfig
-	execConfig := config.Exec
-	err = execConfig.ValidateExecConfig()
-	if err != nil {
-		return astutil.BuildArgs{}, err
+// handleJSONConfig processes the (resolved) exec config of a JSON configuration file for run mode
+func handleJSONConfig(jsonFilePath string, parsedConfig *config.Config, execConfig config.ExecConfig, buildFlags []string, verbose bool) (astutil.BuildArgs, error) {
+	// JSON mode doesn't allow build flags
+	if len(buildFlags) > 0 {
+		return astutil.BuildArgs{}, fmt.Errorf("build flags are not allowed when using JSON configuration file")
 	}
 
 	// If it's a rawcmd, we can't handle it in normal run mode
@@ -584,14 +565,33 @@ func handleJSONConfig(jsonFilePath string, buildFlags []string, verbose bool) (a
 		return astutil.BuildArgs{}, err
 	}
 
+	// Entry is relative to the config file (not the working directory)
+	configDir, err := filepath.Abs(filepath.Dir(jsonFilePath))
+	if err != nil {
+		return astutil.BuildArgs{}, fmt.Errorf("failed to get absolute path for config directory: %w", err)
+	}
+	entry := execConfig.Entry
+	if !filepath.IsAbs(entry) {
+		entry = filepath.Join(configDir, entry)
+	}
+	mainDir, filePatterns, err := astutil.DetermineMainDirAndPatterns(configDir, []string{entry})
+	if err != nil {
+		return astutil.BuildArgs{}, err
+	}
+
 	// Build the BuildArgs from ExecConfig
 	buildArgs := astutil.BuildArgs{
-		GoFiles:     []string{execConfig.Entry}, // Entry becomes the go file/package
-		BuildFlags:  execConfig.BuildFlags,      // Use build flags from ExecConfig
-		ProgramArgs: execConfig.Args,            // Args become program arguments
-		WorkingDir:  absWorkingDir,
-		Verbose:     verbose,
-		ConfigFile:  jsonFilePath,
+		GoFiles:        []string{entry},       // Entry becomes the go file/package
+		BuildFlags:     execConfig.BuildFlags, // Use build flags from ExecConfig
+		ProgramArgs:    execConfig.Args,       // Args become program arguments
+		ProgramEnv:     execConfig.Env,
+		WorkingDir:     absWorkingDir,
+		MainDir:        mainDir,
+		FilePatterns:   filePatterns,
+		Config:         *parsedConfig,
+		Verbose:        verbose,
+		ConfigFile:     jsonFilePath,
+		NoToolchainPin: execConfig.NoToolchainPin,
 	}
 
 	return buildArgs, nil
@@ -611,24 +611,22 @@ func setupBuildConfiguration(cfg RunModeConfig) (RunModeConfig, astutil.BuildArg
 
 	jsonFilePath := buildArgs.GoFiles[0]
 
-	// Read and parse the JSON configuration file
-	jsonData, err := os.ReadFile(jsonFilePath)
+	// Read, validate, and parse the JSON configuration file
+	parsedConfig, err := readJSONConfigFile(jsonFilePath)
 	if err != nil {
-		return cfg, astutil.BuildArgs{}, fmt.Errorf("failed to read JSON config file %s: %w", jsonFilePath, err)
-	}
-
-	var parsedConfig config.Config
-	err = json.Unmarshal(jsonData, &parsedConfig)
-	if err != nil {
-		return cfg, astutil.BuildArgs{}, fmt.Errorf("failed to parse JSON config file %s: %w", jsonFilePath, err)
+		return cfg, astutil.BuildArgs{}, err
 	}
 
-	// Validate ExecConfig
+	// Validate ExecConfig, then load the envfiles and replace the ${VAR} references
 	execConfig := parsedConfig.Exec
 	err = execConfig.ValidateExecConfig()
 	if err != nil {
 		return cfg, astutil.BuildArgs{}, err
 	}
+	execConfig, err = execConfig.ResolveExecConfig(filepath.Dir(jsonFilePath))
+	if err != nil {
+		return cfg, astutil.BuildArgs{}, fmt.Errorf("invalid exec config in %s: %w", jsonFilePath, err)
+	}
 
 	// Update the config file path to point to the JSON file
 	cfg.ConfigFile = jsonFilePath
@@ -663,7 +661,7 @@ func setupBuildConfiguration(cfg RunModeConfig) (RunModeConfig, astutil.BuildArg
 			Cmd: []string{shell, "-c", execConfig.RawCmd},
 			Env: execConfig.Env,
 			Cwd: absWorkingDir,
-			Cfg: parsedConfig,
+			Cfg: *parsedConfig,
 		}
 
 		// Return empty BuildArgs since we're using RawCmd
@@ -676,10 +674,12 @@ func setupBuildConfiguration(cfg RunModeConfig) (RunModeConfig, astutil.BuildArg
 	}
 
 	// Handle normal (non-RawCmd) JSON config
-	buildArgs, err = handleJSONConfig(jsonFilePath, buildArgs.BuildFlags, cfg.IsVerbose)
+	buildArgs, err = handleJSONConfig(jsonFilePath, parsedConfig, execConfig, buildArgs.BuildFlags, cfg.IsVerbose)
 	if err != nil {
 		return cfg, astutil.BuildArgs{}, err
 	}
+	buildArgs.NoToolchainPin = buildArgs.NoToolchainPin || cfg.NoToolchainPin
+	applyBuildEnv(execConfig.Env, cfg.IsVerbose)
 
 	return cfg, buildArgs, nil
 }
@@ -707,14 +707,19 @@ func isMonitorLocal(monitorConfig *config.Config) bool {
 }
 
 // startMonitorProcess starts the monitor process using the new daemonized start command
-func startMonitorProcess(cfg RunModeConfig) error {
+// (listenAddr overrides the monitor's default listen address if not "")
+func startMonitorProcess(cfg RunModeConfig, listenAddr string) error {
 	// Get our own executable path
 	executable, err := os.Executable()
 	if err != nil {
 		return fmt.Errorf("failed to get executable path: %w", err)
 	}
 
-	cmd := exec.Command(executable, "monitor", "start")
+	cmdArgs := []string{"monitor", "start"}
+	if listenAddr != "" {
+		cmdArgs = append(cmdArgs, "--listen", listenAddr)
+	}
+	cmd := exec.Command(executable, cmdArgs...)
 
 	// If we're in dev mode, add OUTRIG_DEV=1 environment variable
 	if config.UseDevConfig() {
@@ -727,7 +732,7 @@ func startMonitorProcess(cfg RunModeConfig) error {
 		if config.UseDevConfig() {
 			envStr = " (with OUTRIG_DEV=1)"
 		}
-		log.Printf("Starting monitor with command: %s monitor start%s", executable, envStr)
+		log.Printf("Starting monitor with command: %s %s%s", executable, strings.Join(cmdArgs, " "), envStr)
 	}
 
 	// Use CombinedOutput to get the daemon startup output
@@ -751,16 +756,34 @@ func startMonitorProcess(cfg RunModeConfig) error {
 	return nil
 }
 
-// AutostartMonitor attempts to start the monitor locally and waits for it to be ready
-func AutostartMonitor(cfg RunModeConfig, buildArgs astutil.BuildArgs) error {
+// AutostartMonitor attempts to start the monitor locally and waits for it to be ready.
+// If another program holds the monitor port (portConflict != nil) the monitor is started on a
+// free port when cfg.MonitorFreePort is set, and the app is pointed at it with OUTRIG_TCPADDR.
+func AutostartMonitor(cfg RunModeConfig, buildArgs astutil.BuildArgs, portConflict *PortConflictError) error {
 	monitorConfig := getOutrigConfig(cfg, buildArgs)
 
+	var listenAddr string
+	if portConflict != nil {
+		if !cfg.MonitorFreePort {
+			return fmt.Errorf("%w (stop that program, or use --monitor-free-port to start the monitor on a free port)", portConflict)
+		}
+		freePort, err := findFreeLocalPort()
+		if err != nil {
+			return err
+		}
+		listenAddr = net.JoinHostPort("127.0.0.1", strconv.Itoa(freePort))
+		os.Setenv(config.TcpAddrEnvName, listenAddr)
+		if !monitorConfig.Quiet {
+			fmt.Printf("#outrig %v, starting the monitor on port %d instead\n", portConflict, freePort)
+		}
+	}
+
 	if !monitorConfig.Quiet {
 		fmt.Printf("#outrig Local outrig monitor not detected, attempting to autostart (suppress with --no-monitor-autostart)...\n")
 	}
 
 	// Start the monitor process
-	err := startMonitorProcess(cfg)
+	err := startMonitorProcess(cfg, listenAddr)
 	if err != nil {
 		return err
 	}
@@ -795,7 +818,10 @@ func checkMonitorVersion(cfg RunModeConfig, buildArgs astutil.BuildArgs) (bool,
 
 	serverVersion, _, _, err := comm.GetServerVersion(monitorConfig)
 	if err != nil {
-		// Monitor is not running
+		// Monitor is not running, report other software holding the monitor port rather than a bare connect error
+		if portConflict := probeMonitorPort(monitorConfig); portConflict != nil {
+			return false, portConflict
+		}
 		return false, fmt.Errorf("outrig monitor is not running: %w", err)
 	}
 
@@ -824,6 +850,21 @@ func ExecRunMode(cfg RunModeConfig) error {
 	if err != nil {
 		return err
 	}
+	if cfg.Watch && cfg.RawCmd != nil {
+		return fmt.Errorf("--watch is not supported for rawcmd configurations")
+	}
+
+	if cfg.Report != "" {
+		if cfg.RawCmd != nil {
+			return fmt.Errorf("--report is not supported for rawcmd configurations (nothing is transformed)")
+		}
+		// the report only covers the transform, nothing runs so no monitor is needed
+		transformState, err := performASTTransformation(buildArgs, cfg)
+		if err != nil {
+			return err
+		}
+		return finishNoRun(transformState, cfg)
+	}
 
 	// Check if monitor is running and compatible
 	isRunning, err := checkMonitorVersion(cfg, buildArgs)
@@ -837,7 +878,9 @@ func ExecRunMode(cfg RunModeConfig) error {
 		isLocal := isMonitorLocal(monitorConfig)
 		if isLocal && !cfg.NoMonitorAutostart {
 			// Try to autostart the monitor
-			if autostartErr := AutostartMonitor(cfg, buildArgs); autostartErr != nil {
+			var portConflict *PortConflictError
+			errors.As(err, &portConflict)
+			if autostartErr := AutostartMonitor(cfg, buildArgs, portConflict); autostartErr != nil {
 				return fmt.Errorf("outrig monitor autostart failed: %w", autostartErr)
 			}
 		} else {
@@ -858,40 +901,85 @@ func ExecRunMode(cfg RunModeConfig) error {
 			return err
 		}
 		if cfg.NoRun {
-			log.Printf("--norun flag set: transforms complete, tempdir %s", transformState.TempDir)
-			return nil
+			return finishNoRun(transformState, cfg)
+		}
+		if cfg.Watch {
+			return execWatchMode(transformState, buildArgs, cfg)
 		}
-		return runWithOverlay(transformState, buildArgs.GoFiles, buildArgs.BuildFlags, buildArgs.ProgramArgs, cfg)
+		return runWithOverlay(transformState, buildArgs.GoFiles, buildArgs.BuildFlags, buildArgs.ProgramArgs, buildArgs.ProgramEnv, cfg)
 	}
 }
 
-// performASTTransformation handles all AST transformation steps
-func performASTTransformation(buildArgs astutil.BuildArgs, cfg RunModeConfig) (*astutil.TransformState, error) {
-	transformState := loadFilesAndSetupTransformState(buildArgs, cfg)
+// finishNoRun ends a --norun invocation after the transform (the temp dir is kept for inspection)
+func finishNoRun(transformState *astutil.TransformState, cfg RunModeConfig) error {
+	if cfg.Report != "" {
+		err := writeTransformReport(transformState, cfg.Report)
+		if err != nil {
+			return err
+		}
+	}
+	log.Printf("--norun flag set: transforms complete, tempdir %s", transformState.TempDir)
+	return nil
+}
+
+// setupModuleFiles makes the outrig SDK available to the build, either with a temp go.mod (-modfile)
+// or, for vendored modules, with a vendor directory that contains the SDK
+func setupModuleFiles(transformState *astutil.TransformState, buildArgs astutil.BuildArgs, cfg RunModeConfig) error {
+	if astutil.IsVendorMode(transformState, buildArgs.BuildFlags) {
+		if cfg.IsVerbose {
+			log.Printf("vendor directory detected, using vendored build")
+		}
+		err := astutil.SetupVendorBuild(transformState)
+		if err != nil {
+			return fmt.Errorf("failed to set up vendored build: %w", err)
+		}
+		return nil
+	}
 
 	// Copy go.mod and go.sum to temp directory
 	err := copyGoModFiles(transformState.GoModPath, transformState.TempDir, cfg.IsVerbose)
 	if err != nil {
-		return nil, fmt.Errorf("failed to copy go.mod files: %w", err)
+		return fmt.Errorf("failed to copy go.mod files: %w", err)
 	}
 
 	// If we have a go.work file, modify the copied go.mod with replace directives
 	err = addGoWorkReplaceDirectives(transformState)
 	if err != nil {
-		return nil, fmt.Errorf("failed to add go.work replace directives: %w", err)
+		return fmt.Errorf("failed to add go.work replace directives: %w", err)
 	}
 
 	// Add the version locked Outrig SDK dependency to the temp go.mod
 	tempGoModPath := filepath.Join(transformState.TempDir, "go.mod")
 	err = astutil.AddOutrigSDKDependency(tempGoModPath, cfg.IsVerbose, transformState.Config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to add outrig SDK dependency: %w", err)
+		return fmt.Errorf("failed to add outrig SDK dependency: %w", err)
 	}
 
 	// Download dependencies to populate go.sum in temp directory
 	err = downloadOutrigSDK(transformState, cfg.IsVerbose)
 	if err != nil {
-		return nil, fmt.Errorf("failed to download dependencies: %w", err)
+		return fmt.Errorf("failed to download dependencies: %w", err)
+	}
+	return nil
+}
+
+// performASTTransformation handles all AST transformation steps
+func performASTTransformation(buildArgs astutil.BuildArgs, cfg RunModeConfig) (*astutil.TransformState, error) {
+	tcache := makeTransformCache(buildArgs, cfg)
+	if tcache != nil {
+		if transformState := tcache.restore(buildArgs, cfg); transformState != nil {
+			return transformState, nil
+		}
+	}
+
+	transformState, err := loadFilesAndSetupTransformState(buildArgs, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setupModuleFiles(transformState, buildArgs, cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	// Find and transform the main file using new replacement flow
@@ -921,11 +1009,31 @@ func performASTTransformation(buildArgs astutil.BuildArgs, cfg RunModeConfig) (*
 		}
 	}
 
+	if tcache != nil {
+		tcache.save(transformState)
+	}
+
 	return transformState, nil
 }
 
 // runWithOverlay creates the overlay file and runs the go command
-func runWithOverlay(transformState *astutil.TransformState, goFiles []string, otherArgs []string, programArgs []string, cfg RunModeConfig) error {
+func runWithOverlay(transformState *astutil.TransformState, goFiles []string, otherArgs []string, programArgs []string, programEnv map[string]string, cfg RunModeConfig) error {
+	goArgs, err := makeOverlayGoArgs(transformState, "run", otherArgs, cfg)
+	if err != nil {
+		return err
+	}
+	goArgs = append(goArgs, programArgs...)
+
+	if cfg.IsVerbose {
+		log.Printf("Executing go command with args: %v", append([]string{"go"}, goArgs...))
+	}
+
+	return runGoCommand(goArgs, programEnv, transformState, cfg)
+}
+
+// makeOverlayGoArgs creates the overlay file and returns the args for the go subcommand (e.g. "run" or "build")
+// ending with the main package path
+func makeOverlayGoArgs(transformState *astutil.TransformState, goCmd string, otherArgs []string, cfg RunModeConfig) ([]string, error) {
 	// Create overlay file mapping
 	overlayData := map[string]interface{}{
 		"Replace": transformState.OverlayMap,
@@ -933,14 +1041,14 @@ func runWithOverlay(transformState *astutil.TransformState, goFiles []string, ot
 
 	overlayBytes, err := json.Marshal(overlayData)
 	if err != nil {
-		return fmt.Errorf("failed to create overlay JSON: %w", err)
+		return nil, fmt.Errorf("failed to create overlay JSON: %w", err)
 	}
 
 	// Create overlay file in the same temp directory
 	overlayFilePath := filepath.Join(transformState.TempDir, "overlay.json")
 	err = os.WriteFile(overlayFilePath, overlayBytes, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to write overlay file: %w", err)
+		return nil, fmt.Errorf("failed to write overlay file: %w", err)
 	}
 
 	// Get the main module directory
@@ -949,41 +1057,74 @@ func runWithOverlay(transformState *astutil.TransformState, goFiles []string, ot
 	// Add -modfile flag to use the copied go.mod in temp directory
 	tempGoModPath := filepath.Join(transformState.TempDir, "go.mod")
 
-	// Calculate the relative path from module directory to the main package
-	packagePath, err := getRelativeMainPkgDir(transformState)
+	// Calculate the relative path from module directory to the main package (or its files)
+	mainPkgArgs, err := getMainPkgArgs(transformState)
 	if err != nil {
-		return fmt.Errorf("failed to get relative main package directory: %w", err)
+		return nil, fmt.Errorf("failed to get relative main package directory: %w", err)
 	}
 
-	// Build the go run command with -C to change to main module directory (note that -C was already stripped from otherArgs)
-	goArgs := []string{"run", "-C", mainModuleDir, "-overlay", overlayFilePath, "-modfile", tempGoModPath}
+	// Build the go command with -C to change to main module directory (note that -C was already stripped from otherArgs)
+	// vendored builds don't use -modfile, the SDK comes from the vendor directory
+	goArgs := []string{goCmd, "-C", mainModuleDir, "-overlay", overlayFilePath}
+	if !transformState.VendorMode {
+		goArgs = append(goArgs, "-modfile", tempGoModPath)
+	}
 	goArgs = append(goArgs, otherArgs...)
-	goArgs = append(goArgs, packagePath)
-	goArgs = append(goArgs, programArgs...)
+	goArgs = append(goArgs, mainPkgArgs...)
 
 	if cfg.IsVerbose {
 		log.Printf("Using overlay file: %s", overlayFilePath)
 		log.Printf("Using -C flag to change to main module directory: %s", mainModuleDir)
-		log.Printf("Using -modfile flag: %s", tempGoModPath)
-		log.Printf("Executing go command with args: %v", append([]string{"go"}, goArgs...))
+		if transformState.VendorMode {
+			log.Printf("Using vendored build (go.work: %q)", transformState.VendorGoWorkPath)
+		} else {
+			log.Printf("Using -modfile flag: %s", tempGoModPath)
+		}
 	}
 
-	return runGoCommand(goArgs, transformState, cfg)
+	return goArgs, nil
+}
+
+// getGoCommandEnv returns the environment variables needed to run the go command on the transformed sources
+func getGoCommandEnv(transformState *astutil.TransformState) map[string]string {
+	// Set GOWORK=off to disable workspace mode when using replace directives
+	// (vendored builds that add the SDK use a temp workspace instead)
+	goWork := "off"
+	if transformState.VendorGoWorkPath != "" {
+		goWork = transformState.VendorGoWorkPath
+	}
+	env := map[string]string{"GOWORK": goWork}
+	if toolchainPin := transformState.GetToolchainPin(); toolchainPin != "" {
+		env["GOTOOLCHAIN"] = toolchainPin
+	}
+	return env
 }
 
 // runGoCommand executes a go command with the given arguments using execlogwrap
 // for log capture and exits with the same exit code as the go command
-func runGoCommand(args []string, transformState *astutil.TransformState, cfg RunModeConfig) error {
+func runGoCommand(args []string, programEnv map[string]string, transformState *astutil.TransformState, cfg RunModeConfig) error {
 	// Prepare the full command arguments
 	goArgs := append([]string{"go"}, args...)
-
-	// Set GOWORK=off to disable workspace mode when using replace directives
-	extraEnv := map[string]string{
-		"GOWORK":                  "off",
-		"GOTOOLCHAIN":             transformState.ToolchainVersion,
-		config.FromRunModeEnvName: "1",
-	}
+	extraEnv := makeProgramEnv(programEnv, transformState, cfg.Meta)
 
 	// Use execlogwrap to execute the command with log capture
 	return execlogwrap.ExecCommand(goArgs, config.GetAppRunId(), &transformState.Config, extraEnv)
 }
+
+// makeProgramEnv returns the extra environment variables for the program (and the go command that runs it),
+// meta is the key=value app run metadata
+func makeProgramEnv(programEnv map[string]string, transformState *astutil.TransformState, meta []string) map[string]string {
+	// the program inherits the environment of "go run"
+	extraEnv := make(map[string]string)
+	maps.Copy(extraEnv, programEnv)
+	maps.Copy(extraEnv, getGoCommandEnv(transformState))
+	extraEnv[config.FromRunModeEnvName] = "1"
+	if len(meta) > 0 {
+		appMeta := meta
+		if envMeta := os.Getenv(config.AppMetaEnvName); envMeta != "" {
+			appMeta = append([]string{envMeta}, meta...)
+		}
+		extraEnv[config.AppMetaEnvName] = strings.Join(appMeta, ",")
+	}
+	return extraEnv
+}
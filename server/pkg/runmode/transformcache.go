@@ -0,0 +1,427 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package runmode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/runmode/astutil"
+)
+
+// The transform cache lets repeated "outrig run" invocations on an unchanged project skip loading
+// (parsing + type checking) and rewriting the source files.
+//
+// Layout (under TransformCacheDir):
+//
+//	objects/<key>.go     transformed file output, key is derived from (transformer version, file path, content hash, main file)
+//	objects/<key>.mod    temp go.mod / go.sum for the run (content addressed)
+//	manifests/<key>.json one manifest per (working dir, args, build flags, config, env), lists every input file
+//	                     with its content hash and the objects that make up the overlay
+//
+// A manifest is only used if every input file (and the set of .go files in each package directory) is unchanged.
+const (
+	TransformCacheDir     = "~/.cache/outrig"
+	TransformCacheVersion = 5 // bump whenever the transform output changes
+	TransformCacheMaxAge  = 30 * 24 * time.Hour
+
+	transformCachePruneInterval = 24 * time.Hour
+	transformCachePruneStamp    = "lastprune"
+)
+
+// env vars that change what gets loaded/built (they are part of the manifest key)
+var transformCacheEnvVars = []string{
+	"GOOS", "GOARCH", "GOFLAGS", "GOWORK", "GOEXPERIMENT", "CGO_ENABLED", "GOTOOLCHAIN",
+	config.RunSDKReplacePathEnvName,
+}
+
+type transformManifest struct {
+	Version          int                          `json:"version"`
+	ToolchainVersion string                       `json:"toolchainversion"`
+	GoModPath        string                       `json:"gomodpath"`
+	GoWorkPath       string                       `json:"goworkpath,omitempty"`
+	MainDir          string                       `json:"maindir"`
+	MainPkgDir       string                       `json:"mainpkgdir"`
+	MainGoFiles      []string                     `json:"maingofiles,omitempty"`
+	VendorMode       bool                         `json:"vendormode,omitempty"`
+	ModFiles         map[string]string            `json:"modfiles"`     // go.mod/go.sum/go.work/modules.txt path -> content hash ("" if the file did not exist)
+	PkgDirs          map[string]map[string]string `json:"pkgdirs"`      // package dir -> .go file name -> content hash
+	Overlay          map[string]string            `json:"overlay"`      // original file path -> object key
+	TempGoModObj     string                       `json:"tempgomodobj"` // object key of the temp go.mod (empty for vendored builds)
+	TempGoSumObj     string                       `json:"tempgosumobj"` // object key of the temp go.sum
+}
+
+type transformCache struct {
+	dir         string
+	manifestKey string
+	verbose     bool
+}
+
+// makeTransformCache returns nil if the cache is disabled.
+// Reports are built from the per-file changes, which a restored transform doesn't have, so they always transform.
+func makeTransformCache(buildArgs astutil.BuildArgs, cfg RunModeConfig) *transformCache {
+	if cfg.NoTransformCache || cfg.Report != "" {
+		return nil
+	}
+	keyData := map[string]any{
+		"version":    TransformCacheVersion,
+		"sdkversion": config.OutrigSDKVersion,
+		"workingdir": buildArgs.WorkingDir,
+		"gofiles":    buildArgs.GoFiles,
+		"buildflags": buildArgs.BuildFlags,
+		"runmode":    buildArgs.Config.RunMode,
+	}
+	env := make(map[string]string)
+	for _, name := range transformCacheEnvVars {
+		env[name] = os.Getenv(name)
+	}
+	keyData["env"] = env
+	barr, err := json.Marshal(keyData)
+	if err != nil {
+		return nil
+	}
+	return &transformCache{
+		dir:         utilfn.ExpandHomeDir(TransformCacheDir),
+		manifestKey: hashBytes(barr),
+		verbose:     cfg.IsVerbose,
+	}
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hashFile returns the content hash of a file ("" if it does not exist)
+func hashFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return hashBytes(data), nil
+}
+
+// fileObjectKey is the cache key for the transformed output of a source file
+func fileObjectKey(origPath string, contentHash string, isMainFile bool) string {
+	return hashBytes([]byte(fmt.Sprintf("v%d\x00%s\x00%s\x00%t", TransformCacheVersion, origPath, contentHash, isMainFile)))
+}
+
+func (tc *transformCache) manifestPath() string {
+	return filepath.Join(tc.dir, "manifests", tc.manifestKey+".json")
+}
+
+func (tc *transformCache) objectPath(key string, ext string) string {
+	return filepath.Join(tc.dir, "objects", key+ext)
+}
+
+// writeFileAtomic writes to a temp file and renames it, so concurrent runs never see partial files
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmpFile.Write(data)
+	closeErr := tmpFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return err
+	}
+	return os.Rename(tmpFile.Name(), path)
+}
+
+func (tc *transformCache) logf(format string, args ...any) {
+	if tc.verbose {
+		log.Printf("transform cache: "+format, args...)
+	}
+}
+
+// listGoFiles returns the .go files in a package directory (name -> content hash)
+func listGoFiles(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+		hash, err := hashFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files[entry.Name()] = hash
+	}
+	return files, nil
+}
+
+func getModFilePaths(goModPath string, goWorkPath string) []string {
+	moduleDir := filepath.Dir(goModPath)
+	paths := []string{goModPath, filepath.Join(moduleDir, "go.sum"), filepath.Join(moduleDir, "vendor", "modules.txt")}
+	if goWorkPath != "" {
+		paths = append(paths, goWorkPath, goWorkPath+".sum")
+		// the other workspace modules are built from source (and transformed), so their go.mod files matter too
+		modules, _ := astutil.ParseGoWorkFile(goWorkPath)
+		for _, modulePath := range modules {
+			if modulePath != moduleDir {
+				paths = append(paths, filepath.Join(modulePath, "go.mod"))
+			}
+		}
+	}
+	return paths
+}
+
+// validate checks that nothing the manifest was built from has changed
+func (tc *transformCache) validate(m *transformManifest) (bool, string) {
+	if m.Version != TransformCacheVersion {
+		return false, "version mismatch"
+	}
+	toolchainVersion, err := astutil.DetectToolchainVersion(filepath.Dir(m.GoModPath))
+	if err != nil || toolchainVersion != m.ToolchainVersion {
+		return false, "toolchain changed"
+	}
+	goWorkPath, err := astutil.FindGoWorkPath(filepath.Dir(m.GoModPath))
+	if err != nil || goWorkPath != m.GoWorkPath {
+		return false, "go.work changed"
+	}
+	for path, hash := range m.ModFiles {
+		curHash, err := hashFile(path)
+		if err != nil || curHash != hash {
+			return false, fmt.Sprintf("%s changed", path)
+		}
+	}
+	for dir, files := range m.PkgDirs {
+		curFiles, err := listGoFiles(dir)
+		if err != nil || len(curFiles) != len(files) {
+			return false, fmt.Sprintf("files in %s changed", dir)
+		}
+		for name, hash := range files {
+			if curFiles[name] != hash {
+				return false, fmt.Sprintf("%s changed", filepath.Join(dir, name))
+			}
+		}
+	}
+	return true, ""
+}
+
+// restore returns a TransformState rebuilt from the cache (module files and overlay written to a new temp dir)
+// returns nil on a cache miss
+func (tc *transformCache) restore(buildArgs astutil.BuildArgs, cfg RunModeConfig) *astutil.TransformState {
+	startTs := time.Now()
+	data, err := os.ReadFile(tc.manifestPath())
+	if err != nil {
+		tc.logf("miss (no manifest)")
+		return nil
+	}
+	var m transformManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		tc.logf("miss (bad manifest: %v)", err)
+		return nil
+	}
+	if ok, reason := tc.validate(&m); !ok {
+		tc.logf("miss (%s)", reason)
+		return nil
+	}
+
+	tempDir, err := os.MkdirTemp("", "outrig_tmp_*")
+	if err != nil {
+		return nil
+	}
+	transformState := &astutil.TransformState{
+		OverlayMap:       make(map[string]string),
+		ModifiedFiles:    make(map[string]*astutil.ModifiedFile),
+		GoModPath:        m.GoModPath,
+		GoWorkPath:       m.GoWorkPath,
+		ToolchainVersion: m.ToolchainVersion,
+		NoToolchainPin:   buildArgs.NoToolchainPin,
+		MainDir:          m.MainDir,
+		MainPkgDir:       m.MainPkgDir,
+		MainGoFiles:      m.MainGoFiles,
+		TempDir:          tempDir,
+		Verbose:          cfg.IsVerbose,
+		Config:           buildArgs.Config,
+	}
+	copyObject := func(key string, ext string, dstPath string) bool {
+		objData, err := os.ReadFile(tc.objectPath(key, ext))
+		if err != nil {
+			return false
+		}
+		now := time.Now()
+		os.Chtimes(tc.objectPath(key, ext), now, now)
+		return os.WriteFile(dstPath, objData, 0644) == nil
+	}
+	for origPath, key := range m.Overlay {
+		tempFilePath := filepath.Join(tempDir, astutil.GenerateTempFileName(origPath))
+		if !copyObject(key, ".go", tempFilePath) {
+			tc.logf("miss (object for %s not found)", origPath)
+			os.RemoveAll(tempDir)
+			return nil
+		}
+		transformState.OverlayMap[origPath] = tempFilePath
+	}
+	if m.TempGoModObj != "" {
+		if !copyObject(m.TempGoModObj, ".mod", filepath.Join(tempDir, "go.mod")) || !copyObject(m.TempGoSumObj, ".mod", filepath.Join(tempDir, "go.sum")) {
+			tc.logf("miss (module file objects not found)")
+			os.RemoveAll(tempDir)
+			return nil
+		}
+	}
+	if m.VendorMode {
+		// the vendor setup is not cached (it depends on the module cache), redo it
+		err := setupModuleFiles(transformState, buildArgs, cfg)
+		if err != nil {
+			tc.logf("miss (%v)", err)
+			os.RemoveAll(tempDir)
+			return nil
+		}
+	}
+	now := time.Now()
+	os.Chtimes(tc.manifestPath(), now, now)
+	tc.logf("hit, restored %d files in %v (tempdir %s)", len(transformState.OverlayMap), time.Since(startTs), tempDir)
+	return transformState
+}
+
+// save records the transform results (errors are logged, a failed save only means the next run is a cache miss)
+func (tc *transformCache) save(transformState *astutil.TransformState) {
+	err := tc.saveInternal(transformState)
+	if err != nil {
+		tc.logf("failed to save: %v", err)
+		return
+	}
+	tc.prune()
+}
+
+func (tc *transformCache) saveInternal(transformState *astutil.TransformState) error {
+	m := transformManifest{
+		Version:          TransformCacheVersion,
+		ToolchainVersion: transformState.ToolchainVersion,
+		GoModPath:        transformState.GoModPath,
+		GoWorkPath:       transformState.GoWorkPath,
+		MainDir:          transformState.MainDir,
+		MainPkgDir:       transformState.MainPkgDir,
+		MainGoFiles:      transformState.MainGoFiles,
+		VendorMode:       transformState.VendorMode,
+		ModFiles:         make(map[string]string),
+		PkgDirs:          make(map[string]map[string]string),
+		Overlay:          make(map[string]string),
+	}
+	for _, path := range getModFilePaths(transformState.GoModPath, transformState.GoWorkPath) {
+		hash, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		m.ModFiles[path] = hash
+	}
+	for _, pkg := range transformState.Packages {
+		if pkg.Dir == "" || m.PkgDirs[pkg.Dir] != nil {
+			continue
+		}
+		files, err := listGoFiles(pkg.Dir)
+		if err != nil {
+			return err
+		}
+		m.PkgDirs[pkg.Dir] = files
+	}
+
+	var mainFilePath string
+	if mainFileAST, err := astutil.FindMainFileAST(transformState); err == nil {
+		mainFilePath = transformState.GetFilePath(mainFileAST)
+	}
+	for origPath, tempFilePath := range transformState.OverlayMap {
+		files := m.PkgDirs[filepath.Dir(origPath)]
+		contentHash, ok := files[filepath.Base(origPath)]
+		if !ok {
+			// not in a tracked package dir (shouldn't happen), can't validate it so don't cache this run
+			return fmt.Errorf("overlay file %s is not in a package directory", origPath)
+		}
+		output, err := os.ReadFile(tempFilePath)
+		if err != nil {
+			return err
+		}
+		key := fileObjectKey(origPath, contentHash, origPath == mainFilePath)
+		if err := writeFileAtomic(tc.objectPath(key, ".go"), output); err != nil {
+			return err
+		}
+		m.Overlay[origPath] = key
+	}
+
+	if !transformState.VendorMode {
+		for _, name := range []string{"go.mod", "go.sum"} {
+			modData, err := os.ReadFile(filepath.Join(transformState.TempDir, name))
+			if err != nil {
+				return err
+			}
+			key := hashBytes(modData)
+			if err := writeFileAtomic(tc.objectPath(key, ".mod"), modData); err != nil {
+				return err
+			}
+			if name == "go.mod" {
+				m.TempGoModObj = key
+			} else {
+				m.TempGoSumObj = key
+			}
+		}
+	}
+
+	barr, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(tc.manifestPath(), barr); err != nil {
+		return err
+	}
+	tc.logf("saved manifest %s (%d pkg dirs, %d overlay files)", tc.manifestKey[:12], len(m.PkgDirs), len(m.Overlay))
+	return nil
+}
+
+// prune removes manifests and objects that haven't been used in TransformCacheMaxAge (runs at most once a day)
+func (tc *transformCache) prune() {
+	stampPath := filepath.Join(tc.dir, transformCachePruneStamp)
+	if info, err := os.Stat(stampPath); err == nil && time.Since(info.ModTime()) < transformCachePruneInterval {
+		return
+	}
+	if err := writeFileAtomic(stampPath, nil); err != nil {
+		return
+	}
+	numRemoved := 0
+	for _, subDir := range []string{"manifests", "objects"} {
+		entries, err := os.ReadDir(filepath.Join(tc.dir, subDir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || time.Since(info.ModTime()) < TransformCacheMaxAge {
+				continue
+			}
+			if os.Remove(filepath.Join(tc.dir, subDir, entry.Name())) == nil {
+				numRemoved++
+			}
+		}
+	}
+	if numRemoved > 0 {
+		tc.logf("pruned %d old entries", numRemoved)
+	}
+}
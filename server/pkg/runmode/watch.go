@@ -0,0 +1,297 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package runmode
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/server/pkg/execlogwrap"
+	"github.com/outrigdev/outrig/server/pkg/runmode/astutil"
+)
+
+const (
+	WatchPollInterval = 500 * time.Millisecond // how often the source files are checked for changes
+	WatchSettleTime   = 300 * time.Millisecond // a change is picked up once no file has changed for this long
+	WatchStopTimeout  = 5 * time.Second        // how long the program has to exit after an interrupt before it is killed
+
+	// app run metadata set in watch mode, the app runs of one "outrig run --watch" share MetaKeyRunSeries
+	MetaKeyRunSeries = "runseries" // the app run id of the first run
+	MetaKeyRestart   = "restart"   // number of restarts before this run (not set on the first run)
+)
+
+// fileStamp is what the watcher compares to detect a changed file
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// watchProcess is a running instance of the program
+type watchProcess struct {
+	rc     *execlogwrap.RunningCommand
+	doneCh chan struct{} // closed when the program has exited
+	err    error         // the program's exit error (set before doneCh is closed)
+}
+
+// execWatchMode runs the program like "go run", but rebuilds and restarts it when its source files change.
+// The program is built from the overlay into the temp dir and run directly without stdin (so it can be stopped
+// for a restart). Each rebuild goes through performASTTransformation, so it reuses the transform cache. If a
+// rebuild fails the running program is kept. Every restart is a new app run, the runs are grouped by their
+// MetaKeyRunSeries metadata (the app run list shows a series as one entry).
+func execWatchMode(transformState *astutil.TransformState, buildArgs astutil.BuildArgs, cfg RunModeConfig) error {
+	binPath, err := resolveBuildOutputPath("", buildArgs)
+	if err != nil {
+		return err
+	}
+	binName := filepath.Base(binPath)
+	quiet := transformState.Config.Quiet
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	seriesId := config.GetAppRunId()
+	watchRoots := getWatchRoots(transformState)
+	snapshot := snapshotWatchFiles(watchRoots)
+	if cfg.IsVerbose {
+		log.Printf("watching %d files in %v", len(snapshot), watchRoots)
+	}
+
+	var proc *watchProcess
+	var procTempDir string
+	numRuns := 0
+	for {
+		if transformState != nil {
+			// the running program is only stopped once the new one is built
+			binPath, err := buildWatchBinary(transformState, buildArgs, cfg, binName)
+			if err == nil && proc != nil {
+				proc.stop()
+				os.RemoveAll(procTempDir)
+				proc = nil
+			}
+			if err == nil {
+				proc, err = startWatchProcess(transformState, buildArgs, cfg, binPath, seriesId, numRuns)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "#outrig %v, waiting for changes\n", err)
+				os.RemoveAll(transformState.TempDir)
+			} else {
+				procTempDir = transformState.TempDir
+				numRuns++
+			}
+		}
+
+		// wait for a change (or for the watcher to be stopped)
+		snapshot = waitForWatchChange(watchRoots, snapshot, sigCh, proc, quiet)
+		if snapshot == nil {
+			if proc != nil {
+				proc.stop()
+			}
+			os.RemoveAll(procTempDir)
+			return nil
+		}
+
+		if !quiet {
+			fmt.Printf("#outrig source files changed, rebuilding...\n")
+		}
+		transformState, err = performASTTransformation(buildArgs, cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "#outrig %v, waiting for changes\n", err)
+			transformState = nil
+			continue
+		}
+		// a rebuild can pull in new workspace modules (or a new go.work)
+		watchRoots = getWatchRoots(transformState)
+	}
+}
+
+// waitForWatchChange waits until the watched files change (and stop changing) and returns their new snapshot,
+// nil if the watcher was interrupted first. The program's exit is reported while waiting.
+func waitForWatchChange(watchRoots []string, snapshot map[string]fileStamp, sigCh chan os.Signal, proc *watchProcess, quiet bool) map[string]fileStamp {
+	var procDoneCh chan struct{}
+	if proc != nil {
+		procDoneCh = proc.doneCh
+	}
+	ticker := time.NewTicker(WatchPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sigCh:
+			return nil
+		case <-procDoneCh:
+			procDoneCh = nil
+			if !quiet {
+				fmt.Printf("#outrig %s, waiting for changes to restart\n", describeExit(proc.err))
+			}
+		case <-ticker.C:
+			newSnapshot := snapshotWatchFiles(watchRoots)
+			if maps.Equal(newSnapshot, snapshot) {
+				continue
+			}
+			// editors save several files (or write a file in steps), wait until the files stop changing
+			for {
+				time.Sleep(WatchSettleTime)
+				settledSnapshot := snapshotWatchFiles(watchRoots)
+				if maps.Equal(settledSnapshot, newSnapshot) {
+					return newSnapshot
+				}
+				newSnapshot = settledSnapshot
+			}
+		}
+	}
+}
+
+// buildWatchBinary builds the program into the transform's temp dir and returns the path of the binary
+func buildWatchBinary(transformState *astutil.TransformState, buildArgs astutil.BuildArgs, cfg RunModeConfig, binName string) (string, error) {
+	binPath := filepath.Join(transformState.TempDir, "bin", binName)
+	otherArgs := append(append([]string{}, buildArgs.BuildFlags...), "-o", binPath)
+	goArgs, err := makeOverlayGoArgs(transformState, "build", otherArgs, cfg)
+	if err != nil {
+		return "", err
+	}
+	if cfg.IsVerbose {
+		log.Printf("Executing go command with args: %v", append([]string{"go"}, goArgs...))
+	}
+	cmd := exec.Command("go", goArgs...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	for key, value := range getGoCommandEnv(transformState) {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("go build failed: %w", err)
+	}
+	return binPath, nil
+}
+
+// startWatchProcess starts the program as a new app run of the series (runNum is the number of runs before this one)
+func startWatchProcess(transformState *astutil.TransformState, buildArgs astutil.BuildArgs, cfg RunModeConfig, binPath string, seriesId string, runNum int) (*watchProcess, error) {
+	appRunId := seriesId
+	meta := append(append([]string{}, cfg.Meta...), MetaKeyRunSeries+"="+seriesId)
+	if runNum > 0 {
+		appRunId = uuid.New().String()
+		meta = append(meta, MetaKeyRestart+"="+strconv.Itoa(runNum))
+		if !transformState.Config.Quiet {
+			fmt.Printf("#outrig restarting %s (restart %d)\n", filepath.Base(binPath), runNum)
+		}
+	}
+	// like "go run", the program runs in the current directory. It gets its own process group so a restart
+	// also stops the processes it started.
+	args := append([]string{binPath}, buildArgs.ProgramArgs...)
+	extraEnv := makeProgramEnv(buildArgs.ProgramEnv, transformState, meta)
+	opts := execlogwrap.CommandOpts{ProcessGroup: true}
+	rc, err := execlogwrap.StartCommand(args, opts, appRunId, &transformState.Config, extraEnv)
+	if err != nil {
+		return nil, err
+	}
+	proc := &watchProcess{rc: rc, doneCh: make(chan struct{})}
+	go func() {
+		proc.err = rc.Wait()
+		close(proc.doneCh)
+	}()
+	return proc, nil
+}
+
+// stop interrupts the program's process group and waits for the program to exit (the group is killed after
+// WatchStopTimeout)
+func (proc *watchProcess) stop() {
+	select {
+	case <-proc.doneCh:
+		return
+	default:
+	}
+	// interrupts are not supported on windows, the program is killed right away
+	if err := proc.rc.Signal(os.Interrupt); err != nil {
+		proc.rc.Kill()
+	}
+	select {
+	case <-proc.doneCh:
+	case <-time.After(WatchStopTimeout):
+		proc.rc.Kill()
+		<-proc.doneCh
+	}
+}
+
+// describeExit describes how the program exited (err is from exec.Cmd.Wait)
+func describeExit(err error) string {
+	if err == nil {
+		return "program exited"
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return fmt.Sprintf("program exited with code %d", exitErr.ExitCode())
+	}
+	return fmt.Sprintf("program failed: %v", err)
+}
+
+// getWatchRoots returns the paths to watch: the main module and the other modules of its workspace (and go.work)
+func getWatchRoots(transformState *astutil.TransformState) []string {
+	mainModuleDir := filepath.Dir(transformState.GoModPath)
+	roots := []string{mainModuleDir}
+	if transformState.GoWorkPath == "" {
+		return roots
+	}
+	modules, err := astutil.ParseGoWorkFile(transformState.GoWorkPath)
+	if err != nil {
+		return roots
+	}
+	for _, modulePath := range modules {
+		if modulePath != mainModuleDir {
+			roots = append(roots, modulePath)
+		}
+	}
+	// walking a file visits just the file
+	return append(roots, transformState.GoWorkPath)
+}
+
+// isWatchedFile returns true for the files that change the build (test files don't)
+func isWatchedFile(name string) bool {
+	switch name {
+	case "go.mod", "go.sum", "go.work", "go.work.sum":
+		return true
+	}
+	return strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")
+}
+
+// snapshotWatchFiles returns the stamps of the watched files under the roots, skipping the directories
+// the go command ignores (and vendor, which only changes with go.mod)
+func snapshotWatchFiles(roots []string) map[string]fileStamp {
+	snapshot := make(map[string]fileStamp)
+	for _, root := range roots {
+		filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			name := entry.Name()
+			if entry.IsDir() {
+				if path != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor" || name == "node_modules") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !isWatchedFile(name) {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			snapshot[path] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+			return nil
+		})
+	}
+	return snapshot
+}
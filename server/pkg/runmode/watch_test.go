@@ -0,0 +1,151 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package runmode
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/config"
+	"github.com/outrigdev/outrig/server/pkg/runmode/astutil"
+)
+
+func TestSnapshotWatchFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFile := func(relPath string, content string) {
+		path := filepath.Join(root, relPath)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("go.mod", "module example.com/app\n")
+	writeFile("main.go", "package main\n")
+	writeFile("pkg/util/util.go", "package util\n")
+	writeFile("pkg/util/util_test.go", "package util\n")
+	writeFile("pkg/util/testdata/input.go", "package input\n")
+	writeFile(".git/hooks/hook.go", "package hooks\n")
+	writeFile("vendor/example.com/dep/dep.go", "package dep\n")
+	writeFile("README.md", "readme\n")
+
+	snapshot := snapshotWatchFiles([]string{root})
+	expected := []string{"go.mod", "main.go", "pkg/util/util.go"}
+	if len(snapshot) != len(expected) {
+		t.Errorf("expected %d watched files, got %d: %v", len(expected), len(snapshot), snapshot)
+	}
+	for _, relPath := range expected {
+		if _, ok := snapshot[filepath.Join(root, relPath)]; !ok {
+			t.Errorf("expected %s to be watched", relPath)
+		}
+	}
+
+	writeFile("README.md", "changed readme\n")
+	writeFile("pkg/util/util_test.go", "package util // changed\n")
+	if !maps.Equal(snapshot, snapshotWatchFiles([]string{root})) {
+		t.Errorf("changes to unwatched files should not change the snapshot")
+	}
+	writeFile("pkg/util/util.go", "package util // changed\n")
+	if maps.Equal(snapshot, snapshotWatchFiles([]string{root})) {
+		t.Errorf("a changed source file should change the snapshot")
+	}
+}
+
+func TestWaitForWatchChange(t *testing.T) {
+	root := t.TempDir()
+	srcPath := filepath.Join(root, "main.go")
+	if err := os.WriteFile(srcPath, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := snapshotWatchFiles([]string{root})
+	sigCh := make(chan os.Signal, 1)
+
+	resultCh := make(chan map[string]fileStamp, 1)
+	go func() {
+		resultCh <- waitForWatchChange([]string{root}, snapshot, sigCh, nil, true)
+	}()
+	time.Sleep(WatchPollInterval)
+	if err := os.WriteFile(srcPath, []byte("package main // changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case newSnapshot := <-resultCh:
+		if newSnapshot[srcPath].size == snapshot[srcPath].size {
+			t.Errorf("got snapshot %v, want the changed file", newSnapshot)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the change was not picked up")
+	}
+
+	// an interrupt stops the wait
+	sigCh <- os.Interrupt
+	if got := waitForWatchChange([]string{root}, snapshot, sigCh, nil, true); got != nil {
+		t.Errorf("got snapshot %v after an interrupt, want nil", got)
+	}
+}
+
+func TestWatchProcessRestart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh and process groups")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.AppMetaEnvName, "")
+	workDir := t.TempDir()
+	t.Chdir(workDir)
+
+	transformState := &astutil.TransformState{GoModPath: filepath.Join(t.TempDir(), "go.mod")}
+	transformState.Config.Quiet = true
+	// the program records how it was started, then waits on a pipeline (the sleep only exits if its process
+	// group is signaled)
+	script := fmt.Sprintf(`echo "$PWD|$%s|$%s" >> runs.txt; sleep 60 | cat`, config.AppRunIdEnvName, config.AppMetaEnvName)
+	buildArgs := astutil.BuildArgs{ProgramArgs: []string{"-c", script}}
+	cfg := RunModeConfig{Meta: []string{"env=dev"}}
+
+	for runNum := 0; runNum < 2; runNum++ {
+		proc, err := startWatchProcess(transformState, buildArgs, cfg, "/bin/sh", "series1", runNum)
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitForRunsFile(t, runNum+1)
+		startTime := time.Now()
+		proc.stop()
+		if elapsed := time.Since(startTime); elapsed >= WatchStopTimeout {
+			t.Errorf("run %d: stopping took %v, the interrupt didn't reach the process group", runNum, elapsed)
+		}
+	}
+
+	runs := waitForRunsFile(t, 2)
+	first := strings.Split(runs[0], "|")
+	if first[0] != workDir || first[1] != "series1" || first[2] != "env=dev,runseries=series1" {
+		t.Errorf("got first run %q, want it in %s as the series' app run", runs[0], workDir)
+	}
+	restart := strings.Split(runs[1], "|")
+	if restart[0] != workDir || restart[1] == "series1" || restart[2] != "env=dev,runseries=series1,restart=1" {
+		t.Errorf("got restart %q, want a new app run in %s with the restart metadata", runs[1], workDir)
+	}
+}
+
+// waitForRunsFile waits until the test program wrote numRuns lines to runs.txt and returns them
+func waitForRunsFile(t *testing.T, numRuns int) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		barr, _ := os.ReadFile("runs.txt")
+		lines := strings.Split(strings.TrimSpace(string(barr)), "\n")
+		if len(barr) > 0 && len(lines) >= numRuns {
+			return lines
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the program didn't start (got %q)", barr)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package searchparser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LevelField is the log line field that holds the normalized log level (searched with $level:warn+)
+const LevelField = "level"
+
+// Normalized log levels (lowest to highest)
+const (
+	LogLevelTrace = "trace"
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+	LogLevelFatal = "fatal"
+)
+
+var logLevelRanks = map[string]int{
+	LogLevelTrace: 1,
+	LogLevelDebug: 2,
+	LogLevelInfo:  3,
+	LogLevelWarn:  4,
+	LogLevelError: 5,
+	LogLevelFatal: 6,
+}
+
+// logLevelAliases maps the (lowercase) level names used by common loggers to the normalized levels
+// (logrus text output abbreviates to 4 characters, zerolog console output to 3)
+var logLevelAliases = map[string]string{
+	"trace":         LogLevelTrace,
+	"trac":          LogLevelTrace,
+	"trc":           LogLevelTrace,
+	"debug":         LogLevelDebug,
+	"debu":          LogLevelDebug,
+	"dbg":           LogLevelDebug,
+	"info":          LogLevelInfo,
+	"inf":           LogLevelInfo,
+	"notice":        LogLevelInfo,
+	"informational": LogLevelInfo,
+	"warn":          LogLevelWarn,
+	"warning":       LogLevelWarn,
+	"wrn":           LogLevelWarn,
+	"error":         LogLevelError,
+	"erro":          LogLevelError,
+	"err":           LogLevelError,
+	"fatal":         LogLevelFatal,
+	"fata":          LogLevelFatal,
+	"ftl":           LogLevelFatal,
+	"panic":         LogLevelFatal,
+	"pani":          LogLevelFatal,
+	"pnc":           LogLevelFatal,
+	"dpanic":        LogLevelFatal,
+	"crit":          LogLevelFatal,
+	"critical":      LogLevelFatal,
+	"emerg":         LogLevelFatal,
+	"alert":         LogLevelFatal,
+}
+
+// levelSearchTermRegex matches a level search term: an optional comparison operator, the level, and an optional
+// "+" (this level and above) or "-" (this level and below) suffix
+var levelSearchTermRegex = regexp.MustCompile(`^([><]=?)?([A-Za-z]+)([+-])?$`)
+
+// NormalizeLogLevel returns the normalized level for a level name (case-insensitive, e.g. "WARNING" => "warn"),
+// or "" if it isn't a known level name
+func NormalizeLogLevel(name string) string {
+	return logLevelAliases[strings.ToLower(name)]
+}
+
+// LogLevelRank returns the rank of a normalized level (higher is more severe), or 0 for an unknown level
+func LogLevelRank(level string) int {
+	return logLevelRanks[level]
+}
+
+// CompareLogLevel returns true if level satisfies the comparison with searchLevel (both normalized levels).
+// An empty operator is an equality check. A line without a level never matches.
+func CompareLogLevel(level string, operator string, searchLevel string) bool {
+	rank := LogLevelRank(level)
+	if rank == 0 {
+		return false
+	}
+	searchRank := LogLevelRank(searchLevel)
+	switch operator {
+	case ">":
+		return rank > searchRank
+	case ">=":
+		return rank >= searchRank
+	case "<":
+		return rank < searchRank
+	case "<=":
+		return rank <= searchRank
+	default:
+		return rank == searchRank
+	}
+}
+
+// parseLevelSearchTerm parses a $level search term: warn, warn+ (warn and above), info- (info and below),
+// >=warn, <error. Returns the operator ("" for equality) and the normalized level.
+func parseLevelSearchTerm(searchTerm string) (operator string, level string, err error) {
+	matches := levelSearchTermRegex.FindStringSubmatch(searchTerm)
+	if matches == nil || (matches[1] != "" && matches[3] != "") {
+		return "", "", fmt.Errorf("invalid level search %q (use a level with an optional +/- suffix or comparison operator, e.g. warn+)", searchTerm)
+	}
+	level = NormalizeLogLevel(matches[2])
+	if level == "" {
+		return "", "", fmt.Errorf("unknown log level %q (use trace, debug, info, warn, error, or fatal)", matches[2])
+	}
+	operator = matches[1]
+	switch matches[3] {
+	case "+":
+		operator = ">="
+	case "-":
+		operator = "<="
+	}
+	return operator, level, nil
+}
@@ -0,0 +1,30 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package searchparser
+
+import "testing"
+
+func TestCompareLogLevel(t *testing.T) {
+	tests := []struct {
+		level       string
+		operator    string
+		searchLevel string
+		expected    bool
+	}{
+		{"warn", ">=", "warn", true},
+		{"error", ">=", "warn", true},
+		{"info", ">=", "warn", false},
+		{"info", "<=", "info", true},
+		{"debug", "<", "info", true},
+		{"fatal", ">", "error", true},
+		{"warn", "", "warn", true},
+		{"error", "", "warn", false},
+		{"", "<=", "fatal", false},
+	}
+	for _, tt := range tests {
+		if result := CompareLogLevel(tt.level, tt.operator, tt.searchLevel); result != tt.expected {
+			t.Errorf("CompareLogLevel(%q, %q, %q) = %v, expected %v", tt.level, tt.operator, tt.searchLevel, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package searchparser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unit kinds of numeric values (a value without a unit has no kind)
+const (
+	NumericUnitKindSize     = "size"     // normalized to bytes
+	NumericUnitKindDuration = "duration" // normalized to nanoseconds
+)
+
+type numericUnit struct {
+	Kind       string
+	Multiplier float64
+}
+
+// numericUnits are the units accepted after a number in numeric comparisons ($len:>1kb, $duration:>1.5s, $mem:>100mb).
+// Sizes are binary (1kb = 1024 bytes), units are case-insensitive.
+var numericUnits = map[string]numericUnit{
+	"b":   {NumericUnitKindSize, 1},
+	"kb":  {NumericUnitKindSize, 1 << 10},
+	"kib": {NumericUnitKindSize, 1 << 10},
+	"mb":  {NumericUnitKindSize, 1 << 20},
+	"mib": {NumericUnitKindSize, 1 << 20},
+	"gb":  {NumericUnitKindSize, 1 << 30},
+	"gib": {NumericUnitKindSize, 1 << 30},
+	"tb":  {NumericUnitKindSize, 1 << 40},
+	"tib": {NumericUnitKindSize, 1 << 40},
+	"ns":  {NumericUnitKindDuration, 1},
+	"us":  {NumericUnitKindDuration, 1e3},
+	"µs":  {NumericUnitKindDuration, 1e3},
+	"ms":  {NumericUnitKindDuration, 1e6},
+	"s":   {NumericUnitKindDuration, 1e9},
+	"m":   {NumericUnitKindDuration, 60e9},
+	"h":   {NumericUnitKindDuration, 3600e9},
+}
+
+// numericValueRegex matches a number (optionally signed, with a decimal part) followed by an optional unit
+var numericValueRegex = regexp.MustCompile(`^([-+]?\d+(?:\.\d+)?|[-+]?\.\d+)\s*([a-zA-Zµ]*)$`)
+
+// NumericValue is a parsed numeric value, Value is normalized to the base unit of Kind (bytes, nanoseconds)
+type NumericValue struct {
+	Value float64
+	Kind  string // NumericUnitKindSize, NumericUnitKindDuration, or "" for a plain number
+}
+
+// ParseNumericValue parses a number with an optional unit (e.g. 500, 1.5s, 100mb, 250 ms)
+func ParseNumericValue(str string) (NumericValue, error) {
+	matches := numericValueRegex.FindStringSubmatch(strings.TrimSpace(str))
+	if matches == nil {
+		return NumericValue{}, fmt.Errorf("invalid number %q", str)
+	}
+	num, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return NumericValue{}, fmt.Errorf("invalid number %q: %w", str, err)
+	}
+	if matches[2] == "" {
+		return NumericValue{Value: num}, nil
+	}
+	unit, ok := numericUnits[strings.ToLower(matches[2])]
+	if !ok {
+		return NumericValue{}, fmt.Errorf("unknown unit %q in %q", matches[2], str)
+	}
+	return NumericValue{Value: num * unit.Multiplier, Kind: unit.Kind}, nil
+}
+
+// CompareNumeric compares a field value with a search value using a numeric operator (>, <, >=, <=).
+// Values with different unit kinds (e.g. a size and a duration) never match.
+// A plain number matches any kind (it is taken to be in the base unit, bytes or nanoseconds).
+func CompareNumeric(fieldVal NumericValue, operator string, searchVal NumericValue) bool {
+	if fieldVal.Kind != "" && searchVal.Kind != "" && fieldVal.Kind != searchVal.Kind {
+		return false
+	}
+	switch operator {
+	case ">":
+		return fieldVal.Value > searchVal.Value
+	case "<":
+		return fieldVal.Value < searchVal.Value
+	case ">=":
+		return fieldVal.Value >= searchVal.Value
+	case "<=":
+		return fieldVal.Value <= searchVal.Value
+	default:
+		return false
+	}
+}
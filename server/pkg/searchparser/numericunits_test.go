@@ -0,0 +1,61 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package searchparser
+
+import "testing"
+
+func TestParseNumericValue(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected NumericValue
+		wantErr  bool
+	}{
+		{input: "500", expected: NumericValue{Value: 500}},
+		{input: "-2.5", expected: NumericValue{Value: -2.5}},
+		{input: "1kb", expected: NumericValue{Value: 1024, Kind: NumericUnitKindSize}},
+		{input: "100MB", expected: NumericValue{Value: 100 << 20, Kind: NumericUnitKindSize}},
+		{input: "1.5s", expected: NumericValue{Value: 1.5e9, Kind: NumericUnitKindDuration}},
+		{input: "250 ms", expected: NumericValue{Value: 250e6, Kind: NumericUnitKindDuration}},
+		{input: "2m", expected: NumericValue{Value: 120e9, Kind: NumericUnitKindDuration}},
+		{input: "10xb", wantErr: true},
+		{input: "kb", wantErr: true},
+		{input: "1.2.3", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			val, err := ParseNumericValue(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", val)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if val != tt.expected {
+				t.Errorf("value mismatch: got %+v, want %+v", val, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompareNumeric(t *testing.T) {
+	kb := NumericValue{Value: 1024, Kind: NumericUnitKindSize}
+	sec := NumericValue{Value: 1e9, Kind: NumericUnitKindDuration}
+	if !CompareNumeric(NumericValue{Value: 2048, Kind: NumericUnitKindSize}, ">", kb) {
+		t.Errorf("expected 2kb > 1kb")
+	}
+	if !CompareNumeric(NumericValue{Value: 2048}, ">=", kb) {
+		t.Errorf("expected a plain number to compare with a size")
+	}
+	if CompareNumeric(NumericValue{Value: 2e9, Kind: NumericUnitKindDuration}, ">", kb) {
+		t.Errorf("expected a duration not to match a size")
+	}
+	if !CompareNumeric(NumericValue{Value: 5e8, Kind: NumericUnitKindDuration}, "<", sec) {
+		t.Errorf("expected 500ms < 1s")
+	}
+}
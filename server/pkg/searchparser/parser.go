@@ -7,10 +7,11 @@
 // or_expr          = and_expr { WS? "|" WS? and_expr } ;
 // and_expr         = group { WS group } ;
 // group            = "(" WS? or_expr WS? ")" | token
-// token            = not_token | field_token | colorfilter_token | unmodified_token ;
+// token            = not_token | field_token | colorfilter_token | timewindow_token | unmodified_token ;
 // not_token        = "-" field_token | "-" unmodified_token ;
-// field_token      = "$" WORD | "$" WORD unmodified_token ;
+// field_token      = "$" WORD | "$" WORD unmodified_token | "$" WORD "(" WS? or_expr WS? ")" ;  (WORD is field:value, field:, or field?)
 // colorfilter_token = "%" WORD "(" WS? or_expr WS? ")" ;
+// timewindow_token = "@" WORD ;  (WORD is last:<duration> or between:<start>,<end>)
 // unmodified_token = fuzzy_token | regexp_token | tag_token | simple_token ;
 // fuzzy_token      = "~" simple_token ;
 // regexp_token     = REGEXP | CREGEXP;
@@ -26,6 +27,21 @@
 // - Not token (-) negates the search result of the token that follows it
 // - A literal "-" at the start of a token must be quoted: "-hello" searches for "-hello" literally
 // - Numeric field search supports operators: >, <, >=, <= (e.g., $goid:>500, $goid:<=200)
+//   The number can have a size or duration unit: $len:>1kb, $duration:>1.5s, $mem:>100mb (see ParseNumericValue),
+//   $len is the length of a log message in bytes
+// - $level searches compare normalized log levels (trace < debug < info < warn < error < fatal):
+//   $level:warn, $level:warn+ (warn and above), $level:info- (info and below), $level:>=error
+// - $field? matches items that have the field, -$field? items that don't: $user? finds log lines with a JSON "user"
+//   field, $role? goroutines or watches with a role:<value> tag (see gensearch.SearchObject.HasField).
+//   $user:bob and $role:admin search the values of those fields
+// - A field group ($state:(running | "chan receive")) applies the field to every term in the group that doesn't set its own field
+// - A backslash escapes a special character in a WORD: a\|b, \#notatag, \-dash, foo\ bar, and $field:\>5 are all literal terms
+//   (a backslash before any other character is a literal backslash, so C:\path works unescaped)
+// - Inside quotes, \" (or \') and \\ are the only escapes: "say \"hi\"" searches for: say "hi"
+// - Field values can be quoted: $name:"foo bar", $name:'Foo' (case-sensitive), -$name:"foo bar"
+// - Time window tokens restrict the search to a range of timestamps: @last:15m, @between:10:00,10:30 (see ParseTimeWindow)
+//   "@" only starts a time window token when followed by "last:" or "between:", otherwise it is part of a WORD (@user)
+//   A top-level time window is applied before the other filters run (for logs it is pushed down to the log storage)
 // Once parsing a WORD the only characters that break a WORD are whitespace, "|", "(", ")", "\"", "'", and EOF
 //
 // Debugging:
@@ -39,13 +55,11 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/outrigdev/outrig/pkg/utilfn"
 )
 
-// numericSearchRegex matches numeric comparison operators (>, <, >=, <=) followed by digits
-var numericSearchRegex = regexp.MustCompile(`^([><]=?)(\d+)$`)
-
 // numericOperatorRegex matches just the numeric comparison operators (>, <, >=, <=)
 var numericOperatorRegex = regexp.MustCompile(`^([><]=?)(.*)$`)
 
@@ -113,6 +127,9 @@ const (
 	SearchTypeMarked      = "marked"
 	SearchTypeNumeric     = "numeric"
 	SearchTypeColorFilter = "colorfilter"
+	SearchTypeTimeWindow  = "timewindow"
+	SearchTypeLevel       = "level"
+	SearchTypeExists      = "exists"
 )
 
 // --- AST Node Definition ---
@@ -535,7 +552,7 @@ func (p *Parser) parseTokenWithErrorSync() *Node {
 }
 
 // parseToken parses a token according to the grammar:
-// token = not_token | field_token | colorfilter_token | unmodified_token
+// token = not_token | field_token | colorfilter_token | timewindow_token | unmodified_token
 func (p *Parser) parseToken() (*Node, error) {
 	// Check for "-" to parse a not token
 	if p.current().Type == TokenMinus {
@@ -552,6 +569,11 @@ func (p *Parser) parseToken() (*Node, error) {
 		return p.parseColorFilterToken()
 	}
 
+	// Check for "@" to parse a time window token
+	if p.current().Type == TokenAt {
+		return p.parseTimeWindowToken()
+	}
+
 	// Otherwise, parse an unmodified token directly
 	return p.parseUnmodifiedToken()
 }
@@ -567,6 +589,10 @@ func (p *Parser) parseNotToken() (*Node, error) {
 		return nil, fmt.Errorf("expected '-' token")
 	}
 
+	if p.current().Type == TokenAt {
+		return nil, fmt.Errorf("time window tokens cannot be negated")
+	}
+
 	// Check if the next token is "$" for a field token
 	if p.current().Type == TokenDollar {
 		// Parse a field token
@@ -599,7 +625,7 @@ func (p *Parser) parseNotToken() (*Node, error) {
 }
 
 // parseFieldToken parses a field token according to the grammar:
-// field_token = "$" WORD | "$" WORD unmodified_token
+// field_token = "$" WORD | "$" WORD unmodified_token (a WORD ending in "?" is an existence check)
 func (p *Parser) parseFieldToken() (*Node, error) {
 	startPos := p.getCurrentStartPos()
 
@@ -619,6 +645,19 @@ func (p *Parser) parseFieldToken() (*Node, error) {
 	fieldValue := wordToken.Value
 	colonPos := strings.Index(fieldValue, ":")
 
+	if colonPos == -1 && strings.HasSuffix(fieldValue, "?") {
+		fieldName := strings.TrimSuffix(fieldValue, "?")
+		if fieldName == "" || strings.Contains(fieldName, "?") {
+			return nil, fmt.Errorf("'?' must follow a field name, e.g. $user?")
+		}
+		return &Node{
+			Type:       NodeTypeSearch,
+			Position:   Position{Start: startPos, End: wordToken.Position.End},
+			SearchType: SearchTypeExists,
+			Field:      fieldName,
+		}, nil
+	}
+
 	if colonPos == -1 {
 		return nil, fmt.Errorf("field name must contain a colon to separate field and value")
 	}
@@ -628,6 +667,10 @@ func (p *Parser) parseFieldToken() (*Node, error) {
 
 	// Check if there's exactly one colon and it's the last character in the word
 	if colonPos == len(fieldValue)-1 && strings.Count(fieldValue, ":") == 1 {
+		if p.current().Type == TokenLParen {
+			return p.parseFieldGroup(fieldName, startPos)
+		}
+
 		// The colon is the last character, so we need to parse an unmodified_token
 		// to get the search term
 		unmodifiedNode, err := p.parseUnmodifiedToken()
@@ -650,10 +693,30 @@ func (p *Parser) parseFieldToken() (*Node, error) {
 		// The colon is not the last character, so the search term is part of the word
 		searchTerm := fieldValue[colonPos+1:]
 
-		// Check if this is a numeric search term
-		isNumeric, operator, numericValue, err := parseNumericSearchTerm(searchTerm)
-		if err != nil {
-			return nil, err
+		if fieldName == LevelField && !wordToken.Escaped {
+			operator, level, err := parseLevelSearchTerm(searchTerm)
+			if err != nil {
+				return nil, err
+			}
+			return &Node{
+				Type:       NodeTypeSearch,
+				Position:   Position{Start: startPos, End: wordToken.Position.End},
+				SearchType: SearchTypeLevel,
+				SearchTerm: level,
+				Field:      fieldName,
+				Op:         operator,
+			}, nil
+		}
+
+		// Check if this is a numeric search term (an escaped operator, e.g. $name:\>5, is searched for literally)
+		var isNumeric bool
+		var operator, numericValue string
+		if !wordToken.Escaped {
+			var err error
+			isNumeric, operator, numericValue, err = parseNumericSearchTerm(searchTerm)
+			if err != nil {
+				return nil, err
+			}
 		}
 		if isNumeric {
 			return &Node{
@@ -677,23 +740,99 @@ func (p *Parser) parseFieldToken() (*Node, error) {
 	}
 }
 
+// parseFieldGroup parses the parenthesized part of a field token:
+// "$" WORD "(" WS? or_expr WS? ")"
+// the field is applied to every search node in the group that does not already have one
+func (p *Parser) parseFieldGroup(fieldName string, startPos int) (*Node, error) {
+	// Consume the "(" token (we already checked it exists in parseFieldToken)
+	_, hasLParen := p.consumeToken(TokenLParen)
+	if !hasLParen {
+		return nil, fmt.Errorf("expected '(' token")
+	}
+
+	p.skipOptionalWhitespace()
+	innerNode := p.parseOrExpr()
+	p.skipOptionalWhitespace()
+
+	if innerNode == nil {
+		return nil, fmt.Errorf("field group must not be empty, must include at least one search term")
+	}
+	applyFieldToNode(innerNode, fieldName)
+
+	// Expect closing parenthesis
+	rparenToken, hasRParen := p.consumeToken(TokenRParen)
+	if !hasRParen {
+		// If we're at EOF, treat it as if the parenthesis was closed (for typeahead search)
+		if !p.atEOF() {
+			return nil, fmt.Errorf("field group must end with ')'")
+		}
+		innerNode.Position = Position{Start: startPos, End: p.current().Position.Start}
+		return innerNode, nil
+	}
+
+	innerNode.Position = Position{Start: startPos, End: rparenToken.Position.End}
+	return innerNode, nil
+}
+
+// applyFieldToNode sets the field on all search nodes in the tree that don't have a field yet
+// exact word terms that look like numeric comparisons (>500) are converted to numeric searches
+// (and in a $level group, level terms like warn+ are converted to level searches)
+func applyFieldToNode(node *Node, fieldName string) {
+	if node == nil {
+		return
+	}
+	if node.Type != NodeTypeSearch {
+		for _, child := range node.Children {
+			applyFieldToNode(child, fieldName)
+		}
+		return
+	}
+	if node.Field != "" {
+		return
+	}
+	switch node.SearchType {
+	case SearchTypeColorFilter, SearchTypeMarked, SearchTypeUserQuery, SearchTypeTag, SearchTypeTimeWindow, SearchTypeExists:
+		return
+	}
+	node.Field = fieldName
+	if node.SearchType == SearchTypeExact && fieldName == LevelField {
+		operator, level, err := parseLevelSearchTerm(node.SearchTerm)
+		if err == nil {
+			node.SearchType = SearchTypeLevel
+			node.SearchTerm = level
+			node.Op = operator
+		}
+		return
+	}
+	if node.SearchType == SearchTypeExact {
+		isNumeric, operator, numericValue, err := parseNumericSearchTerm(node.SearchTerm)
+		if err == nil && isNumeric {
+			node.SearchType = SearchTypeNumeric
+			node.SearchTerm = numericValue
+			node.Op = operator
+		}
+	}
+}
+
 // parseNumericSearchTerm checks if a search term is a numeric comparison
 // Returns:
 // - ok: true if the search term is a valid numeric comparison
 // - operator: the comparison operator (>, <, >=, <=)
-// - value: the numeric value as a string
+// - value: the numeric value as a string, with its optional unit (e.g. "500", "1.5s", "100mb", see ParseNumericValue)
 // - err: error if there's an operator but no valid numeric value
 func parseNumericSearchTerm(searchTerm string) (ok bool, operator string, value string, err error) {
 	// First check if it starts with a numeric operator
-	if numericOperatorRegex.MatchString(searchTerm) {
-		// We have an operator, now check if the rest is a valid number
-		matches := numericSearchRegex.FindStringSubmatch(searchTerm)
-		if matches == nil || len(matches) != 3 {
-			// We have an operator but not a valid number
-			opMatches := numericOperatorRegex.FindStringSubmatch(searchTerm)
-			return false, opMatches[1], "", fmt.Errorf("numeric operator '%s' must be followed by a number", opMatches[1])
+	opMatches := numericOperatorRegex.FindStringSubmatch(searchTerm)
+	if opMatches != nil {
+		// We have an operator, now check if the rest is a valid number (with an optional unit)
+		operator, value = opMatches[1], opMatches[2]
+		if value == "" || !numericValueRegex.MatchString(value) {
+			return false, operator, "", fmt.Errorf("numeric operator '%s' must be followed by a number", operator)
 		}
-		return true, matches[1], matches[2], nil
+		if _, err := ParseNumericValue(value); err != nil {
+			return false, operator, "", err
+		}
+		return true, operator, value, nil
 	}
 
 	// No operator found
@@ -853,6 +992,38 @@ func (p *Parser) parseTagToken() (*Node, error) {
 	return node, nil
 }
 
+// parseTimeWindowToken parses a time window token according to the grammar:
+// timewindow_token = "@" WORD
+// the op ("last" or "between") goes in Op and the rest of the word in SearchTerm
+func (p *Parser) parseTimeWindowToken() (*Node, error) {
+	startPos := p.getCurrentStartPos()
+
+	// Consume the "@" token (we already checked it exists in parseToken)
+	_, hasAt := p.consumeToken(TokenAt)
+	if !hasAt {
+		return nil, fmt.Errorf("expected '@' token")
+	}
+
+	wordToken, hasWord := p.consumeToken(TokenWord)
+	if !hasWord {
+		return nil, fmt.Errorf("'@' must be followed by last:<duration> or between:<start>,<end>")
+	}
+	op, value, _ := strings.Cut(wordToken.Value, ":")
+
+	// validate the window now so errors are shown on the token (it is resolved again when the search runs)
+	if _, err := ParseTimeWindow(op, value, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &Node{
+		Type:       NodeTypeSearch,
+		Position:   Position{Start: startPos, End: wordToken.Position.End},
+		SearchType: SearchTypeTimeWindow,
+		SearchTerm: value,
+		Op:         op,
+	}, nil
+}
+
 // parseSimpleToken parses a simple token according to the grammar:
 // simple_token = DQUOTE | SQUOTE | WORD
 func (p *Parser) parseSimpleToken() (*Node, error) {
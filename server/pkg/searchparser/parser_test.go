@@ -831,6 +831,548 @@ func TestParseAST(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "field group with OR",
+			input: `$state:(running | "chan receive")`,
+			expected: &Node{
+				Type:     "or",
+				Position: Position{Start: 0, End: 33},
+				Children: []*Node{
+					{
+						Type:       "search",
+						Position:   Position{Start: 8, End: 15},
+						SearchType: "exact",
+						SearchTerm: "running",
+						Field:      "state",
+					},
+					{
+						Type:       "search",
+						Position:   Position{Start: 18, End: 32},
+						SearchType: "exact",
+						SearchTerm: "chan receive",
+						Field:      "state",
+					},
+				},
+			},
+		},
+		{
+			name:  "field group with AND and explicit inner field",
+			input: "$state:(chan receive $name:foo)",
+			expected: &Node{
+				Type:     "and",
+				Position: Position{Start: 0, End: 31},
+				Children: []*Node{
+					{
+						Type:       "search",
+						Position:   Position{Start: 8, End: 12},
+						SearchType: "exact",
+						SearchTerm: "chan",
+						Field:      "state",
+					},
+					{
+						Type:       "search",
+						Position:   Position{Start: 13, End: 20},
+						SearchType: "exact",
+						SearchTerm: "receive",
+						Field:      "state",
+					},
+					{
+						Type:       "search",
+						Position:   Position{Start: 21, End: 30},
+						SearchType: "exact",
+						SearchTerm: "foo",
+						Field:      "name",
+					},
+				},
+			},
+		},
+		{
+			name:  "field group with single term",
+			input: "$state:(running)",
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 16},
+				SearchType: "exact",
+				SearchTerm: "running",
+				Field:      "state",
+			},
+		},
+		{
+			name:  "field group with numeric terms",
+			input: "$goid:(>500 | <10)",
+			expected: &Node{
+				Type:     "or",
+				Position: Position{Start: 0, End: 18},
+				Children: []*Node{
+					{
+						Type:       "search",
+						Position:   Position{Start: 7, End: 11},
+						SearchType: "numeric",
+						SearchTerm: "500",
+						Field:      "goid",
+					},
+					{
+						Type:       "search",
+						Position:   Position{Start: 14, End: 17},
+						SearchType: "numeric",
+						SearchTerm: "10",
+						Field:      "goid",
+					},
+				},
+			},
+		},
+		{
+			name:  "negated field group",
+			input: "-$state:(running | runnable)",
+			expected: &Node{
+				Type:     "or",
+				Position: Position{Start: 0, End: 28},
+				IsNot:    true,
+				Children: []*Node{
+					{
+						Type:       "search",
+						Position:   Position{Start: 9, End: 16},
+						SearchType: "exact",
+						SearchTerm: "running",
+						Field:      "state",
+					},
+					{
+						Type:       "search",
+						Position:   Position{Start: 19, End: 27},
+						SearchType: "exact",
+						SearchTerm: "runnable",
+						Field:      "state",
+					},
+				},
+			},
+		},
+		{
+			name:  "field group followed by another token",
+			input: "$state:(running | runnable) hello",
+			expected: &Node{
+				Type:     "and",
+				Position: Position{Start: 0, End: 33},
+				Children: []*Node{
+					{
+						Type:     "or",
+						Position: Position{Start: 0, End: 27},
+						Children: []*Node{
+							{
+								Type:       "search",
+								Position:   Position{Start: 8, End: 15},
+								SearchType: "exact",
+								SearchTerm: "running",
+								Field:      "state",
+							},
+							{
+								Type:       "search",
+								Position:   Position{Start: 18, End: 26},
+								SearchType: "exact",
+								SearchTerm: "runnable",
+								Field:      "state",
+							},
+						},
+					},
+					{
+						Type:       "search",
+						Position:   Position{Start: 28, End: 33},
+						SearchType: "exact",
+						SearchTerm: "hello",
+					},
+				},
+			},
+		},
+		{
+			name:  "field group missing closing parenthesis at EOF",
+			input: "$state:(running | runnable",
+			expected: &Node{
+				Type:     "or",
+				Position: Position{Start: 0, End: 26},
+				Children: []*Node{
+					{
+						Type:       "search",
+						Position:   Position{Start: 8, End: 15},
+						SearchType: "exact",
+						SearchTerm: "running",
+						Field:      "state",
+					},
+					{
+						Type:       "search",
+						Position:   Position{Start: 18, End: 26},
+						SearchType: "exact",
+						SearchTerm: "runnable",
+						Field:      "state",
+					},
+				},
+			},
+		},
+		{
+			name:  "negated field group with an error child",
+			input: "-$state:(running | $field)",
+			expected: &Node{
+				Type:     "or",
+				Position: Position{Start: 0, End: 26},
+				IsNot:    true,
+				Children: []*Node{
+					{
+						Type:       "search",
+						Position:   Position{Start: 9, End: 16},
+						SearchType: "exact",
+						SearchTerm: "running",
+						Field:      "state",
+					},
+					{
+						Type:         "error",
+						Position:     Position{Start: 19, End: 25},
+						ErrorMessage: "field name must contain a colon to separate field and value",
+					},
+				},
+			},
+		},
+		{
+			name:  "empty field group",
+			input: "$state:()",
+			expected: &Node{
+				Type:     "error",
+				Position: Position{Start: 0, End: 7},
+			},
+		},
+		{
+			name:  "escaped pipe and parens",
+			input: `a\|b \(x\)`,
+			expected: &Node{
+				Type:     "and",
+				Position: Position{Start: 0, End: 10},
+				Children: []*Node{
+					{
+						Type:       "search",
+						Position:   Position{Start: 0, End: 4},
+						SearchType: "exact",
+						SearchTerm: "a|b",
+					},
+					{
+						Type:       "search",
+						Position:   Position{Start: 5, End: 10},
+						SearchType: "exact",
+						SearchTerm: "(x)",
+					},
+				},
+			},
+		},
+		{
+			name:  "escaped hash is not a tag",
+			input: `\#backend`,
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 9},
+				SearchType: "exact",
+				SearchTerm: "#backend",
+			},
+		},
+		{
+			name:  "escaped minus is not a negation",
+			input: `\-v`,
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 3},
+				SearchType: "exact",
+				SearchTerm: "-v",
+			},
+		},
+		{
+			name:  "escaped space in word",
+			input: `io\ wait`,
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 8},
+				SearchType: "exact",
+				SearchTerm: "io wait",
+			},
+		},
+		{
+			name:  "quoted string with escaped quotes",
+			input: `"say \"hi\""`,
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 12},
+				SearchType: "exact",
+				SearchTerm: `say "hi"`,
+			},
+		},
+		{
+			name:  "quoted field value",
+			input: `$name:"foo bar"`,
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 15},
+				SearchType: "exact",
+				SearchTerm: "foo bar",
+				Field:      "name",
+			},
+		},
+		{
+			name:  "single quoted field value",
+			input: `$name:'Foo Bar'`,
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 15},
+				SearchType: "exactcase",
+				SearchTerm: "Foo Bar",
+				Field:      "name",
+			},
+		},
+		{
+			name:  "negated quoted field value with escapes",
+			input: `-$msg:"a \"b\" | c"`,
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 19},
+				SearchType: "exact",
+				SearchTerm: `a "b" | c`,
+				Field:      "msg",
+				IsNot:      true,
+			},
+		},
+		{
+			name:  "quoted field value in an OR",
+			input: `$state:"io wait" | $state:running`,
+			expected: &Node{
+				Type:     "or",
+				Position: Position{Start: 0, End: 33},
+				Children: []*Node{
+					{
+						Type:       "search",
+						Position:   Position{Start: 0, End: 16},
+						SearchType: "exact",
+						SearchTerm: "io wait",
+						Field:      "state",
+					},
+					{
+						Type:       "search",
+						Position:   Position{Start: 19, End: 33},
+						SearchType: "exact",
+						SearchTerm: "running",
+						Field:      "state",
+					},
+				},
+			},
+		},
+		{
+			name:  "escaped field value",
+			input: `$name:foo\ bar\|baz`,
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 19},
+				SearchType: "exact",
+				SearchTerm: "foo bar|baz",
+				Field:      "name",
+			},
+		},
+		{
+			name:  "escaped numeric operator is a literal search",
+			input: `$name:\>5`,
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 9},
+				SearchType: "exact",
+				SearchTerm: ">5",
+				Field:      "name",
+			},
+		},
+		{
+			name:  "numeric operator without escape",
+			input: `$goid:>5`,
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 8},
+				SearchType: "numeric",
+				SearchTerm: "5",
+				Field:      "goid",
+			},
+		},
+		{
+			name:  "numeric operator with size unit",
+			input: `$len:>1kb`,
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 9},
+				SearchType: "numeric",
+				SearchTerm: "1kb",
+				Field:      "len",
+			},
+		},
+		{
+			name:  "numeric operator with decimal duration",
+			input: `$duration:<=1.5s`,
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 16},
+				SearchType: "numeric",
+				SearchTerm: "1.5s",
+				Field:      "duration",
+			},
+		},
+		{
+			name:  "numeric operator with unknown unit",
+			input: `$mem:>100xb`,
+			expected: &Node{
+				Type:         "error",
+				Position:     Position{Start: 0, End: 11},
+				ErrorMessage: `unknown unit "xb" in "100xb"`,
+			},
+		},
+		{
+			name:  "time window with search term",
+			input: "@last:15m foo",
+			expected: &Node{
+				Type:     "and",
+				Position: Position{Start: 0, End: 13},
+				Children: []*Node{
+					{
+						Type:       "search",
+						Position:   Position{Start: 0, End: 9},
+						SearchType: "timewindow",
+						SearchTerm: "15m",
+						Op:         "last",
+					},
+					{
+						Type:       "search",
+						Position:   Position{Start: 10, End: 13},
+						SearchType: "exact",
+						SearchTerm: "foo",
+					},
+				},
+			},
+		},
+		{
+			name:  "between time window",
+			input: "@between:2025-01-02T10:00,2025-01-02T11:00",
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 42},
+				SearchType: "timewindow",
+				SearchTerm: "2025-01-02T10:00,2025-01-02T11:00",
+				Op:         "between",
+			},
+		},
+		{
+			name:  "invalid time window duration",
+			input: "@last:x",
+			expected: &Node{
+				Type:         "error",
+				Position:     Position{Start: 0, End: 7},
+				ErrorMessage: "invalid duration 'x'",
+			},
+		},
+		{
+			name:  "at sign inside a word is not a time window",
+			input: "user@last:5m",
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 12},
+				SearchType: "exact",
+				SearchTerm: "user@last:5m",
+			},
+		},
+		{
+			name:  "level and above",
+			input: "$level:warn+",
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 12},
+				SearchType: "level",
+				SearchTerm: "warn",
+				Field:      "level",
+				Op:         ">=",
+			},
+		},
+		{
+			name:  "level alias with operator",
+			input: "$level:<ERR",
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 11},
+				SearchType: "level",
+				SearchTerm: "error",
+				Field:      "level",
+				Op:         "<",
+			},
+		},
+		{
+			name:  "level group",
+			input: "$level:(debug- | fatal)",
+			expected: &Node{
+				Type:     "or",
+				Position: Position{Start: 0, End: 23},
+				Children: []*Node{
+					{Type: "search", Position: Position{Start: 8, End: 14}, SearchType: "level", SearchTerm: "debug", Field: "level", Op: "<="},
+					{Type: "search", Position: Position{Start: 17, End: 22}, SearchType: "level", SearchTerm: "fatal", Field: "level"},
+				},
+			},
+		},
+		{
+			name:  "unknown level",
+			input: "$level:verbose",
+			expected: &Node{
+				Type:         "error",
+				Position:     Position{Start: 0, End: 14},
+				ErrorMessage: `unknown log level "verbose" (use trace, debug, info, warn, error, or fatal)`,
+			},
+		},
+		{
+			name:  "field exists",
+			input: "$user?",
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 6},
+				SearchType: "exists",
+				Field:      "user",
+			},
+		},
+		{
+			name:  "field missing",
+			input: "error -$http.status?",
+			expected: &Node{
+				Type:     "and",
+				Position: Position{Start: 0, End: 20},
+				Children: []*Node{
+					{Type: "search", Position: Position{Start: 0, End: 5}, SearchType: "exact", SearchTerm: "error"},
+					{Type: "search", Position: Position{Start: 6, End: 20}, SearchType: "exists", Field: "http.status", IsNot: true},
+				},
+			},
+		},
+		{
+			name:  "field exists in a field group keeps its own field",
+			input: "$state:(running | $name?)",
+			expected: &Node{
+				Type:     "or",
+				Position: Position{Start: 0, End: 25},
+				Children: []*Node{
+					{Type: "search", Position: Position{Start: 8, End: 15}, SearchType: "exact", SearchTerm: "running", Field: "state"},
+					{Type: "search", Position: Position{Start: 18, End: 24}, SearchType: "exists", Field: "name"},
+				},
+			},
+		},
+		{
+			name:  "question mark without a field name",
+			input: "$?",
+			expected: &Node{
+				Type:         "error",
+				Position:     Position{Start: 0, End: 2},
+				ErrorMessage: "'?' must follow a field name, e.g. $user?",
+			},
+		},
+		{
+			name:  "question mark in a field value is literal",
+			input: "$msg:why?",
+			expected: &Node{
+				Type:       "search",
+				Position:   Position{Start: 0, End: 9},
+				SearchType: "exact",
+				SearchTerm: "why?",
+				Field:      "msg",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -889,6 +1431,9 @@ func compareNodes(t *testing.T, actual, expected *Node) {
 		if actual.IsNot != expected.IsNot {
 			t.Errorf("isNot mismatch: got %t, want %t", actual.IsNot, expected.IsNot)
 		}
+		if expected.Op != "" && actual.Op != expected.Op {
+			t.Errorf("op mismatch: got %s, want %s", actual.Op, expected.Op)
+		}
 	} else if actual.Type == "error" {
 		if expected.ErrorMessage != "" && actual.ErrorMessage != expected.ErrorMessage {
 			t.Errorf("error message mismatch: got %s, want %s", actual.ErrorMessage, expected.ErrorMessage)
@@ -0,0 +1,155 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package searchparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Time window operators (@last:15m, @between:ts1,ts2)
+const (
+	TimeWindowOpLast    = "last"
+	TimeWindowOpBetween = "between"
+)
+
+// local time formats accepted by @between (RFC3339 and epoch timestamps are also accepted)
+var timeWindowLocalFormats = []string{
+	"2006-01-02T15:04:05.000",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// formats for a time of day (today, in local time)
+var timeWindowClockFormats = []string{
+	"15:04:05.000",
+	"15:04:05",
+	"15:04",
+}
+
+// TimeWindow is a range of timestamps in milliseconds, Start and End are inclusive (0 means unbounded)
+type TimeWindow struct {
+	Start int64
+	End   int64
+}
+
+// Contains returns true if ts is inside the window
+func (tw TimeWindow) Contains(ts int64) bool {
+	if tw.Start > 0 && ts < tw.Start {
+		return false
+	}
+	if tw.End > 0 && ts > tw.End {
+		return false
+	}
+	return true
+}
+
+// Intersect returns the window that is inside both windows
+func (tw TimeWindow) Intersect(other TimeWindow) TimeWindow {
+	rtn := tw
+	if other.Start > 0 && (rtn.Start == 0 || other.Start > rtn.Start) {
+		rtn.Start = other.Start
+	}
+	if other.End > 0 && (rtn.End == 0 || other.End < rtn.End) {
+		rtn.End = other.End
+	}
+	return rtn
+}
+
+func isTimeWindowStart(s string) bool {
+	return strings.HasPrefix(s, "@"+TimeWindowOpLast+":") || strings.HasPrefix(s, "@"+TimeWindowOpBetween+":")
+}
+
+// ParseTimeWindow resolves the value of a time window token relative to now:
+//
+//	last:<duration>       e.g. 30s, 15m, 2h, 1d (time.ParseDuration syntax plus "d" for days)
+//	between:<start>,<end> either side can be empty, timestamps are epoch seconds/milliseconds,
+//	                      RFC3339, 2006-01-02T15:04[:05] or 2006-01-02 (local time), or 15:04[:05] (today)
+func ParseTimeWindow(op string, value string, now time.Time) (TimeWindow, error) {
+	switch op {
+	case TimeWindowOpLast:
+		dur, err := parseTimeWindowDuration(value)
+		if err != nil {
+			return TimeWindow{}, err
+		}
+		return TimeWindow{Start: now.Add(-dur).UnixMilli()}, nil
+	case TimeWindowOpBetween:
+		startStr, endStr, found := strings.Cut(value, ",")
+		if !found {
+			return TimeWindow{}, fmt.Errorf("@between requires two timestamps separated by a comma (either can be empty)")
+		}
+		if startStr == "" && endStr == "" {
+			return TimeWindow{}, fmt.Errorf("@between requires a start or an end timestamp")
+		}
+		var tw TimeWindow
+		var err error
+		if startStr != "" {
+			if tw.Start, err = parseTimeWindowTs(startStr, now); err != nil {
+				return TimeWindow{}, err
+			}
+		}
+		if endStr != "" {
+			if tw.End, err = parseTimeWindowTs(endStr, now); err != nil {
+				return TimeWindow{}, err
+			}
+		}
+		if tw.Start > 0 && tw.End > 0 && tw.End < tw.Start {
+			return TimeWindow{}, fmt.Errorf("@between end timestamp is before the start timestamp")
+		}
+		return tw, nil
+	default:
+		return TimeWindow{}, fmt.Errorf("invalid time window '@%s', must be @last or @between", op)
+	}
+}
+
+func parseTimeWindowDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, fmt.Errorf("@last must be followed by a duration (e.g. @last:15m)")
+	}
+	var dur time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		numDays, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s'", value)
+		}
+		dur = time.Duration(numDays) * 24 * time.Hour
+	} else {
+		var err error
+		dur, err = time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s'", value)
+		}
+	}
+	if dur <= 0 {
+		return 0, fmt.Errorf("duration must be positive")
+	}
+	return dur, nil
+}
+
+func parseTimeWindowTs(value string, now time.Time) (int64, error) {
+	if num, err := strconv.ParseInt(value, 10, 64); err == nil && num > 0 {
+		if num < 1e12 {
+			return num * 1000, nil // epoch seconds
+		}
+		return num, nil
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return ts.UnixMilli(), nil
+	}
+	for _, format := range timeWindowLocalFormats {
+		if ts, err := time.ParseInLocation(format, value, now.Location()); err == nil {
+			return ts.UnixMilli(), nil
+		}
+	}
+	for _, format := range timeWindowClockFormats {
+		if clock, err := time.ParseInLocation(format, value, now.Location()); err == nil {
+			ts := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), now.Location())
+			return ts.UnixMilli(), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid timestamp '%s'", value)
+}
@@ -0,0 +1,86 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package searchparser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeWindow(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC)
+	ms := func(tm time.Time) int64 { return tm.UnixMilli() }
+
+	tests := []struct {
+		name     string
+		op       string
+		value    string
+		expected TimeWindow
+		wantErr  bool
+	}{
+		{name: "last minutes", op: "last", value: "15m", expected: TimeWindow{Start: ms(now.Add(-15 * time.Minute))}},
+		{name: "last days", op: "last", value: "2d", expected: TimeWindow{Start: ms(now.Add(-48 * time.Hour))}},
+		{name: "last invalid", op: "last", value: "x", wantErr: true},
+		{name: "last negative", op: "last", value: "-5m", wantErr: true},
+		{name: "last empty", op: "last", value: "", wantErr: true},
+		{
+			name:     "between local times",
+			op:       "between",
+			value:    "2025-03-10T10:00,2025-03-10T11:00:30",
+			expected: TimeWindow{Start: ms(time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)), End: ms(time.Date(2025, 3, 10, 11, 0, 30, 0, time.UTC))},
+		},
+		{
+			name:     "between epoch seconds and milliseconds",
+			op:       "between",
+			value:    "1741600000,1741600005000",
+			expected: TimeWindow{Start: 1741600000000, End: 1741600005000},
+		},
+		{
+			name:     "between open end with clock time",
+			op:       "between",
+			value:    "09:15,",
+			expected: TimeWindow{Start: ms(time.Date(2025, 3, 10, 9, 15, 0, 0, time.UTC))},
+		},
+		{
+			name:     "between open start with RFC3339",
+			op:       "between",
+			value:    ",2025-03-10T12:00:00Z",
+			expected: TimeWindow{End: ms(time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC))},
+		},
+		{name: "between missing comma", op: "between", value: "10:00", wantErr: true},
+		{name: "between empty", op: "between", value: ",", wantErr: true},
+		{name: "between end before start", op: "between", value: "11:00,10:00", wantErr: true},
+		{name: "between invalid timestamp", op: "between", value: "yesterday,", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw, err := ParseTimeWindow(tt.op, tt.value, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", tw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tw != tt.expected {
+				t.Errorf("window mismatch: got %+v, want %+v", tw, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTimeWindowIntersect(t *testing.T) {
+	a := TimeWindow{Start: 100}
+	b := TimeWindow{Start: 50, End: 200}
+	got := a.Intersect(b)
+	if got != (TimeWindow{Start: 100, End: 200}) {
+		t.Errorf("unexpected intersection %+v", got)
+	}
+	if !got.Contains(150) || got.Contains(99) || got.Contains(201) {
+		t.Errorf("unexpected Contains results for %+v", got)
+	}
+}
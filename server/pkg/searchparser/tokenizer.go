@@ -4,6 +4,7 @@
 package searchparser
 
 import (
+	"strings"
 	"unicode"
 )
 
@@ -29,6 +30,7 @@ const (
 	TokenTilde   TokenType = "~" // Tilde
 	TokenHash    TokenType = "#" // Hash
 	TokenPercent TokenType = "%" // Percent sign
+	TokenAt      TokenType = "@" // At sign (only at the start of a time window token, e.g. @last:15m)
 )
 
 // Token represents a token in the search expression
@@ -37,6 +39,7 @@ type Token struct {
 	Value      string    // Value of the token
 	Position   Position  // Position in the source
 	Incomplete bool      // True if the token is incomplete (e.g., unterminated string)
+	Escaped    bool      // True if the value contained escape sequences (Value is unescaped)
 }
 
 // Tokenizer represents a lexical analyzer for search expressions
@@ -125,21 +128,28 @@ func (t *Tokenizer) NextToken() Token {
 	case t.ch == '%':
 		tok = Token{Type: "%", Value: "%", Position: Position{Start: startPos, End: t.position + 1}}
 		t.readChar()
+	case t.ch == '@' && isTimeWindowStart(t.input[t.position:]):
+		tok = Token{Type: "@", Value: "@", Position: Position{Start: startPos, End: t.position + 1}}
+		t.readChar()
 	case t.ch == '"':
 		value, incomplete := t.readDoubleQuotedString()
+		value, escaped := unescapeQuoted(value, '"')
 		tok = Token{
 			Type:       TokenDQuote,
 			Value:      value,
 			Position:   Position{Start: startPos, End: t.position},
 			Incomplete: incomplete,
+			Escaped:    escaped,
 		}
 	case t.ch == '\'':
 		value, incomplete := t.readSingleQuotedString()
+		value, escaped := unescapeQuoted(value, '\'')
 		tok = Token{
 			Type:       TokenSQuote,
 			Value:      value,
 			Position:   Position{Start: startPos, End: t.position},
 			Incomplete: incomplete,
+			Escaped:    escaped,
 		}
 	case t.ch == '/':
 		value, incomplete := t.readRegexpString()
@@ -165,13 +175,13 @@ func (t *Tokenizer) NextToken() Token {
 				}
 			} else {
 				// Just a 'c' followed by something else
-				value := t.readWord()
-				tok = Token{Type: TokenWord, Value: "c" + value, Position: Position{Start: startPos, End: t.position}}
+				value, escaped := t.readWord()
+				tok = Token{Type: TokenWord, Value: "c" + value, Position: Position{Start: startPos, End: t.position}, Escaped: escaped}
 			}
 		} else {
 			// Read a word token
-			value := t.readWord()
-			tok = Token{Type: TokenWord, Value: value, Position: Position{Start: startPos, End: t.position}}
+			value, escaped := t.readWord()
+			tok = Token{Type: TokenWord, Value: value, Position: Position{Start: startPos, End: t.position}, Escaped: escaped}
 		}
 	}
 
@@ -245,18 +255,56 @@ func (t *Tokenizer) readRegexpString() (string, bool) {
 }
 
 // readWord reads a word token (any sequence of non-word-break characters)
-func (t *Tokenizer) readWord() string {
-	startPos := t.position
+// A backslash before an escapable character (see isEscapableChar) adds that character to the word literally,
+// so "a\|b" is the single word "a|b" and "\#foo" is the word "#foo" (not a tag).
+// Returns the unescaped word and true if any escape sequences were found.
+func (t *Tokenizer) readWord() (string, bool) {
+	var sb strings.Builder
+	escaped := false
 
 	for {
+		if t.ch == '\\' && isEscapableChar(t.peek()) {
+			t.readChar() // Skip the backslash
+			sb.WriteByte(byte(t.ch))
+			escaped = true
+			t.readChar()
+			continue
+		}
 		// Stop at EOF or word break characters
 		if t.ch == 0 || isWordBreakChar(t.ch) {
 			break
 		}
+		sb.WriteByte(byte(t.ch))
 		t.readChar()
 	}
 
-	return t.input[startPos:t.position]
+	return sb.String(), escaped
+}
+
+// isEscapableChar returns true if the character can be escaped with a backslash in a word
+// (whitespace, characters that break a word, characters that start a special token, and the numeric operators)
+// A backslash followed by any other character is a literal backslash
+func isEscapableChar(ch rune) bool {
+	return isWordBreakChar(ch) || strings.ContainsRune(`\-$~#%@/<>`, ch)
+}
+
+// unescapeQuoted removes the backslash from escaped delimiters and escaped backslashes in a quoted string
+// Other backslashes are kept as is (so "C:\path" still searches for a single backslash)
+// Returns the unescaped string and true if any escape sequences were found
+func unescapeQuoted(value string, delimiter rune) (string, bool) {
+	if !strings.ContainsRune(value, '\\') {
+		return value, false
+	}
+	var sb strings.Builder
+	escaped := false
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) && (rune(value[i+1]) == delimiter || value[i+1] == '\\') {
+			i++
+			escaped = true
+		}
+		sb.WriteByte(value[i])
+	}
+	return sb.String(), escaped
 }
 
 // isWordBreakChar returns true if the character breaks a word
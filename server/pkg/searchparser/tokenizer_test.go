@@ -254,7 +254,7 @@ func TestTokenizer(t *testing.T) {
 			name:  "double quoted string with escaped quotes",
 			input: `"hello \"world\""`,
 			expected: []Token{
-				{Type: TokenDQuote, Value: `hello \"world\"`, Position: Position{Start: 0, End: 17}},
+				{Type: TokenDQuote, Value: `hello "world"`, Position: Position{Start: 0, End: 17}, Escaped: true},
 				{Type: TokenEOF, Value: "", Position: Position{Start: 17, End: 17}},
 			},
 		},
@@ -262,7 +262,7 @@ func TestTokenizer(t *testing.T) {
 			name:  "single quoted string with escaped quotes",
 			input: `'hello \'world\''`,
 			expected: []Token{
-				{Type: TokenSQuote, Value: `hello \'world\'`, Position: Position{Start: 0, End: 17}},
+				{Type: TokenSQuote, Value: `hello 'world'`, Position: Position{Start: 0, End: 17}, Escaped: true},
 				{Type: TokenEOF, Value: "", Position: Position{Start: 17, End: 17}},
 			},
 		},
@@ -278,7 +278,7 @@ func TestTokenizer(t *testing.T) {
 			name:  "double quoted string with various escapes",
 			input: `"line1\nline2\tindented\r\n\"quoted\""`,
 			expected: []Token{
-				{Type: TokenDQuote, Value: `line1\nline2\tindented\r\n\"quoted\"`, Position: Position{Start: 0, End: 38}},
+				{Type: TokenDQuote, Value: `line1\nline2\tindented\r\n"quoted"`, Position: Position{Start: 0, End: 38}, Escaped: true},
 				{Type: TokenEOF, Value: "", Position: Position{Start: 38, End: 38}},
 			},
 		},
@@ -296,6 +296,96 @@ func TestTokenizer(t *testing.T) {
 				{Type: TokenEOF, Value: "", Position: Position{Start: 44, End: 44}},
 			},
 		},
+		{
+			name:  "double quoted string with escaped backslash",
+			input: `"C:\\temp\path"`,
+			expected: []Token{
+				{Type: TokenDQuote, Value: `C:\temp\path`, Position: Position{Start: 0, End: 15}, Escaped: true},
+				{Type: TokenEOF, Value: "", Position: Position{Start: 15, End: 15}},
+			},
+		},
+		{
+			name:  "word with escaped break characters",
+			input: `a\|b c\(d\) x\ y`,
+			expected: []Token{
+				{Type: TokenWord, Value: "a|b", Position: Position{Start: 0, End: 4}, Escaped: true},
+				{Type: TokenWhitespace, Value: " ", Position: Position{Start: 4, End: 5}},
+				{Type: TokenWord, Value: "c(d)", Position: Position{Start: 5, End: 11}, Escaped: true},
+				{Type: TokenWhitespace, Value: " ", Position: Position{Start: 11, End: 12}},
+				{Type: TokenWord, Value: "x y", Position: Position{Start: 12, End: 16}, Escaped: true},
+				{Type: TokenEOF, Value: "", Position: Position{Start: 16, End: 16}},
+			},
+		},
+		{
+			name:  "escaped special token characters",
+			input: `\#tag \-foo \$x \~y \%z \/path/`,
+			expected: []Token{
+				{Type: TokenWord, Value: "#tag", Position: Position{Start: 0, End: 5}, Escaped: true},
+				{Type: TokenWhitespace, Value: " ", Position: Position{Start: 5, End: 6}},
+				{Type: TokenWord, Value: "-foo", Position: Position{Start: 6, End: 11}, Escaped: true},
+				{Type: TokenWhitespace, Value: " ", Position: Position{Start: 11, End: 12}},
+				{Type: TokenWord, Value: "$x", Position: Position{Start: 12, End: 15}, Escaped: true},
+				{Type: TokenWhitespace, Value: " ", Position: Position{Start: 15, End: 16}},
+				{Type: TokenWord, Value: "~y", Position: Position{Start: 16, End: 19}, Escaped: true},
+				{Type: TokenWhitespace, Value: " ", Position: Position{Start: 19, End: 20}},
+				{Type: TokenWord, Value: "%z", Position: Position{Start: 20, End: 23}, Escaped: true},
+				{Type: TokenWhitespace, Value: " ", Position: Position{Start: 23, End: 24}},
+				{Type: TokenWord, Value: "/path/", Position: Position{Start: 24, End: 31}, Escaped: true},
+				{Type: TokenEOF, Value: "", Position: Position{Start: 31, End: 31}},
+			},
+		},
+		{
+			name:  "literal backslashes in words",
+			input: `C:\temp\file foo\ \\|`,
+			expected: []Token{
+				{Type: TokenWord, Value: `C:\temp\file`, Position: Position{Start: 0, End: 12}},
+				{Type: TokenWhitespace, Value: " ", Position: Position{Start: 12, End: 13}},
+				{Type: TokenWord, Value: `foo \`, Position: Position{Start: 13, End: 20}, Escaped: true},
+				{Type: "|", Value: "|", Position: Position{Start: 20, End: 21}},
+				{Type: TokenEOF, Value: "", Position: Position{Start: 21, End: 21}},
+			},
+		},
+		{
+			name:  "trailing backslash",
+			input: `foo\`,
+			expected: []Token{
+				{Type: TokenWord, Value: `foo\`, Position: Position{Start: 0, End: 4}},
+				{Type: TokenEOF, Value: "", Position: Position{Start: 4, End: 4}},
+			},
+		},
+		{
+			name:  "escaped quote in word",
+			input: `it\'s`,
+			expected: []Token{
+				{Type: TokenWord, Value: "it's", Position: Position{Start: 0, End: 5}, Escaped: true},
+				{Type: TokenEOF, Value: "", Position: Position{Start: 5, End: 5}},
+			},
+		},
+		{
+			name:  "time window token",
+			input: "@last:5m",
+			expected: []Token{
+				{Type: TokenAt, Value: "@", Position: Position{Start: 0, End: 1}},
+				{Type: TokenWord, Value: "last:5m", Position: Position{Start: 1, End: 8}},
+				{Type: TokenEOF, Value: "", Position: Position{Start: 8, End: 8}},
+			},
+		},
+		{
+			name:  "escaped time window is a word",
+			input: `\@last:5m`,
+			expected: []Token{
+				{Type: TokenWord, Value: "@last:5m", Position: Position{Start: 0, End: 9}, Escaped: true},
+				{Type: TokenEOF, Value: "", Position: Position{Start: 9, End: 9}},
+			},
+		},
+		{
+			name:  "at sign without a time window op",
+			input: "@foo",
+			expected: []Token{
+				{Type: TokenWord, Value: "@foo", Position: Position{Start: 0, End: 4}},
+				{Type: TokenEOF, Value: "", Position: Position{Start: 4, End: 4}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -330,6 +420,10 @@ func TestTokenizer(t *testing.T) {
 					t.Errorf("Token[%d] position end mismatch: got %d, want %d", i, actualToken.Position.End, expectedToken.Position.End)
 				}
 
+				if actualToken.Escaped != expectedToken.Escaped {
+					t.Errorf("Token[%d] escaped flag mismatch: got %t, want %t", i, actualToken.Escaped, expectedToken.Escaped)
+				}
+
 				if actualToken.Incomplete != expectedToken.Incomplete {
 					t.Errorf("Token[%d] incomplete flag mismatch: got %t, want %t", i, actualToken.Incomplete, expectedToken.Incomplete)
 				}
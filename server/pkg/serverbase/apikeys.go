@@ -0,0 +1,171 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package serverbase
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/utilfn"
+)
+
+const OutrigApiKeysFile = "apikeys.json"
+
+// ApiKeyPrefix marks read-only API keys (so they are easy to spot in dashboard configs)
+const ApiKeyPrefix = "ork_"
+
+// API key scopes, each scope grants read-only access to a part of the embed API (/api/embed/...).
+// RPC commands called through /api/embed/rpc need the scope of the data they return. The federation scope
+// lets a hub monitor connect to the websocket and run the read-only federation commands (see the federation package).
+const (
+	ApiKeyScopeGoRoutines   = "goroutines"
+	ApiKeyScopeLogs         = "logs"
+	ApiKeyScopeRuntimeStats = "runtimestats"
+	ApiKeyScopeFederation   = "federation"
+)
+
+var AllApiKeyScopes = []string{ApiKeyScopeGoRoutines, ApiKeyScopeLogs, ApiKeyScopeRuntimeStats, ApiKeyScopeFederation}
+
+// ApiKey is a read-only API key for embedding Outrig data in other dashboards
+type ApiKey struct {
+	Name      string   `json:"name"`
+	Key       string   `json:"key"`
+	Scopes    []string `json:"scopes"`
+	CreatedTs int64    `json:"createdts"`
+}
+
+// HasScope returns true if the key grants the scope
+func (k ApiKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+var apiKeysCache struct {
+	lock    sync.Mutex
+	modTime time.Time
+	keys    []ApiKey
+}
+
+// GetApiKeysFilePath returns the full path to the apikeys.json file
+func GetApiKeysFilePath() string {
+	return filepath.Join(GetOutrigHome(), OutrigApiKeysFile)
+}
+
+// LoadApiKeys reads the API keys from the apikeys.json file (a missing file means no keys)
+func LoadApiKeys() ([]ApiKey, error) {
+	content, err := os.ReadFile(utilfn.ExpandHomeDir(GetApiKeysFilePath()))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var keys []ApiKey
+	if err := json.Unmarshal(content, &keys); err != nil {
+		return nil, fmt.Errorf("invalid %s file: %w", OutrigApiKeysFile, err)
+	}
+	return keys, nil
+}
+
+func saveApiKeys(keys []ApiKey) error {
+	if err := EnsureHomeDir(); err != nil {
+		return err
+	}
+	barr, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(utilfn.ExpandHomeDir(GetApiKeysFilePath()), barr, 0600)
+}
+
+// CreateApiKey adds a new API key with the given name and scopes (all scopes if none are given)
+func CreateApiKey(name string, scopes []string) (ApiKey, error) {
+	if name == "" {
+		return ApiKey{}, fmt.Errorf("api key name is required")
+	}
+	if len(scopes) == 0 {
+		scopes = AllApiKeyScopes
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(AllApiKeyScopes, scope) {
+			return ApiKey{}, fmt.Errorf("invalid scope %q (valid scopes: %v)", scope, AllApiKeyScopes)
+		}
+	}
+	keys, err := LoadApiKeys()
+	if err != nil {
+		return ApiKey{}, err
+	}
+	for _, key := range keys {
+		if key.Name == name {
+			return ApiKey{}, fmt.Errorf("api key %q already exists", name)
+		}
+	}
+	keyBytes := make([]byte, 24)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return ApiKey{}, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	newKey := ApiKey{
+		Name:      name,
+		Key:       ApiKeyPrefix + hex.EncodeToString(keyBytes),
+		Scopes:    slices.Clone(scopes),
+		CreatedTs: time.Now().UnixMilli(),
+	}
+	keys = append(keys, newKey)
+	if err := saveApiKeys(keys); err != nil {
+		return ApiKey{}, err
+	}
+	return newKey, nil
+}
+
+// RevokeApiKey removes the API key with the given name
+func RevokeApiKey(name string) error {
+	keys, err := LoadApiKeys()
+	if err != nil {
+		return err
+	}
+	idx := slices.IndexFunc(keys, func(key ApiKey) bool { return key.Name == name })
+	if idx == -1 {
+		return fmt.Errorf("api key %q not found", name)
+	}
+	return saveApiKeys(slices.Delete(keys, idx, idx+1))
+}
+
+// LookupApiKey returns the API key matching the token.
+// The apikeys.json file is re-read when it changes, so keys created or revoked with
+// "outrig apikey" take effect without restarting the monitor.
+func LookupApiKey(token string) (ApiKey, bool) {
+	if token == "" {
+		return ApiKey{}, false
+	}
+	apiKeysCache.lock.Lock()
+	defer apiKeysCache.lock.Unlock()
+	finfo, err := os.Stat(utilfn.ExpandHomeDir(GetApiKeysFilePath()))
+	if err != nil {
+		apiKeysCache.keys = nil
+		apiKeysCache.modTime = time.Time{}
+		return ApiKey{}, false
+	}
+	if !finfo.ModTime().Equal(apiKeysCache.modTime) {
+		keys, err := LoadApiKeys()
+		if err != nil {
+			return ApiKey{}, false
+		}
+		apiKeysCache.keys = keys
+		apiKeysCache.modTime = finfo.ModTime()
+	}
+	for _, key := range apiKeysCache.keys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(key.Key)) == 1 {
+			return key, true
+		}
+	}
+	return ApiKey{}, false
+}
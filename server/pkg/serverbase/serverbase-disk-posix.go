@@ -0,0 +1,19 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package serverbase
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// GetDiskSpace returns the free (available to this user) and total bytes of the filesystem that holds path
+func GetDiskSpace(path string) (int64, int64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), int64(stat.Blocks) * int64(stat.Bsize), nil
+}
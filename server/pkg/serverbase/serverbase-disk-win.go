@@ -0,0 +1,23 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build windows
+
+package serverbase
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// GetDiskSpace returns the free (available to this user) and total bytes of the filesystem that holds path
+func GetDiskSpace(path string) (int64, int64, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	var freeBytes, totalBytes, totalFreeBytes uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &freeBytes, &totalBytes, &totalFreeBytes); err != nil {
+		return 0, 0, err
+	}
+	return int64(freeBytes), int64(totalBytes), nil
+}
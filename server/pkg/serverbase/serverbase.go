@@ -4,10 +4,15 @@
 package serverbase
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/outrigdev/outrig/pkg/config"
@@ -37,6 +42,12 @@ const OutrigIdFile = "outrig.id"
 const OutrigDataDir = "data"
 const OutrigDevEnvName = "OUTRIG_DEV"
 const OutrigTEventsFile = "tevents.jsonl"
+const OutrigSessionTimelineFile = "sessiontimeline.jsonl"
+const OutrigIngestTokenFile = "ingest.token"
+const IngestTokenEnvName = "OUTRIG_INGESTTOKEN"
+const OutrigLogBufferDir = "logbuf"
+const OutrigSnapshotsDir = "snapshots"
+const DefaultLogBufferSizeMB = 0 // the disk log buffer is opt-in (e.g. --log-buffer-size 1024)
 const AppcastURL = "https://updates.outrig.run/appcast.xml"
 
 // Default host for monitor
@@ -48,6 +59,14 @@ const ProdWebServerPort = 5005
 // Development port for monitor
 const DevWebServerPort = 6005
 
+// PacketRecordDir is the directory where the raw packets of each app run are recorded ("" disables recording)
+// This gets set from boot.RunServer during initialization
+var PacketRecordDir string
+
+// IngestToken is the token required by the HTTP log ingestion endpoint (/api/ingest/logs)
+// This gets set from boot.RunServer during initialization
+var IngestToken string
+
 type FDLock interface {
 	Close() error
 }
@@ -70,6 +89,14 @@ func GetDomainSocketName() string {
 	return GetOutrigHome() + config.DefaultDomainSocketName
 }
 
+// GetPipeName returns the Windows named pipe the monitor listens on
+func GetPipeName() string {
+	if IsDev() {
+		return config.DefaultDevPipeName
+	}
+	return config.DefaultPipeName
+}
+
 // GetWebServerHost returns the appropriate web server host based on mode
 func GetWebServerHost() string {
 	return WebServerHost
@@ -138,6 +165,37 @@ func EnsureOutrigId(shouldCreate bool) (string, bool, error) {
 	return newId, true, nil
 }
 
+// GetIngestTokenFilePath returns the full path to the ingest.token file
+func GetIngestTokenFilePath() string {
+	return filepath.Join(GetOutrigHome(), OutrigIngestTokenFile)
+}
+
+// EnsureIngestToken returns the token for the HTTP log ingestion endpoint.
+// The OUTRIG_INGESTTOKEN env var takes priority, otherwise the token is read from (or created in)
+// the ingest.token file, which is only readable by the current user.
+func EnsureIngestToken() (string, error) {
+	if envToken := strings.TrimSpace(os.Getenv(IngestTokenEnvName)); envToken != "" {
+		return envToken, nil
+	}
+	tokenFilePath := utilfn.ExpandHomeDir(GetIngestTokenFilePath())
+	content, err := os.ReadFile(tokenFilePath)
+	if err == nil {
+		if token := strings.TrimSpace(string(content)); token != "" {
+			return token, nil
+		}
+	}
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", fmt.Errorf("failed to generate ingest token: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+	err = os.WriteFile(tokenFilePath, []byte(token), 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s file: %w", OutrigIngestTokenFile, err)
+	}
+	return token, nil
+}
+
 // GetOutrigDataDir returns the path to the data directory
 func GetOutrigDataDir() string {
 	return filepath.Join(GetOutrigHome(), OutrigDataDir)
@@ -153,7 +211,73 @@ func EnsureDataDir() error {
 	return os.MkdirAll(dataDir, 0755)
 }
 
+// GetLogBufferDir returns the directory that holds the per app run log buffers
+func GetLogBufferDir() string {
+	return filepath.Join(GetOutrigDataDir(), OutrigLogBufferDir)
+}
+
+// EnsureLogBufferDir creates the log buffer directory (if it doesn't exist)
+func EnsureLogBufferDir() error {
+	return os.MkdirAll(utilfn.ExpandHomeDir(GetLogBufferDir()), 0755)
+}
+
+// GetSnapshotsDir returns the directory that holds the scheduled snapshots (and their rules)
+func GetSnapshotsDir() string {
+	return filepath.Join(GetOutrigDataDir(), OutrigSnapshotsDir)
+}
+
+// GetDirSize returns the total size of the files under dir (0 if it doesn't exist)
+func GetDirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// DirSizeCacheTTL is how long GetCachedDirSize reuses a directory size before walking the directory again
+const DirSizeCacheTTL = 30 * time.Second
+
+type dirSizeEntry struct {
+	size int64
+	ts   time.Time
+}
+
+var (
+	dirSizeCacheLock sync.Mutex
+	dirSizeCache     = make(map[string]dirSizeEntry)
+)
+
+// GetCachedDirSize is GetDirSize for frequently polled endpoints, the size is at most DirSizeCacheTTL old
+func GetCachedDirSize(dir string) int64 {
+	dirSizeCacheLock.Lock()
+	entry, ok := dirSizeCache[dir]
+	dirSizeCacheLock.Unlock()
+	if ok && time.Since(entry.ts) < DirSizeCacheTTL {
+		return entry.size
+	}
+	// walk without the lock, concurrent misses for the same dir both walk it (the last one wins)
+	size := GetDirSize(dir)
+	dirSizeCacheLock.Lock()
+	dirSizeCache[dir] = dirSizeEntry{size: size, ts: time.Now()}
+	dirSizeCacheLock.Unlock()
+	return size
+}
+
 // GetTEventsFilePath returns the full path to the tevents.jsonl file
 func GetTEventsFilePath() string {
 	return filepath.Join(GetOutrigDataDir(), OutrigTEventsFile)
 }
+
+// GetSessionTimelineFilePath returns the full path to the sessiontimeline.jsonl file
+func GetSessionTimelineFilePath() string {
+	return filepath.Join(GetOutrigDataDir(), OutrigSessionTimelineFile)
+}
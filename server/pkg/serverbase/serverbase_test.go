@@ -0,0 +1,34 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package serverbase
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetCachedDirSize(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a"), make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if size := GetCachedDirSize(dir); size != 100 {
+		t.Fatalf("got %d, want 100", size)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b"), make([]byte, 50), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if size := GetCachedDirSize(dir); size != 100 {
+		t.Errorf("got %d, want the cached size", size)
+	}
+
+	dirSizeCacheLock.Lock()
+	dirSizeCache[dir] = dirSizeEntry{size: 100, ts: time.Now().Add(-DirSizeCacheTTL)}
+	dirSizeCacheLock.Unlock()
+	if size := GetCachedDirSize(dir); size != 150 {
+		t.Errorf("got %d after the TTL, want 150", size)
+	}
+}
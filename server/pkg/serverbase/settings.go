@@ -0,0 +1,93 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package serverbase
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RuntimeSettings are the monitor settings that can change while the server is running
+// (command line flags, overridden by the monitor config file, see the serverconfig package)
+type RuntimeSettings struct {
+	// LogBufferSizeMB is the size of the disk-backed log buffer for each app run (0 keeps log lines in memory)
+	// changes only apply to app runs that connect after the change
+	LogBufferSizeMB int
+
+	// MaxAppRunsPerApp is the number of app runs kept for each app name (0 means no per-app limit)
+	MaxAppRunsPerApp int
+
+	// MaxAppRunAge is how long a finished app run is kept after its last update (0 means no age limit)
+	MaxAppRunAge time.Duration
+
+	// MaxDataDirSizeMB is the data directory size that triggers a disk usage warning (0 means no warning, see apppeer.CheckDataDirSize)
+	MaxDataDirSizeMB int
+
+	// EmbedAllowedOrigins are the origins allowed to call the embed API from a browser (CORS), "*" allows any origin
+	EmbedAllowedOrigins []string
+
+	// Downstreams are the monitors (name => apikey@host:port) whose app runs this monitor proxies read-only
+	// (see the federation package and SplitDownstreamAddr)
+	Downstreams map[string]string
+
+	// SessionTimeline records the UI session (tabs visited, app runs viewed, searches run) in a local
+	// file that can be queried with GetSessionTimelineCommand (opt-in, it is never sent anywhere, see tevent.RecordSessionEvent)
+	SessionTimeline bool
+
+	// LogSearchIndex keeps a word index of each app run's log lines (gensearch.LogIndex) so exact searches only
+	// scan the lines that contain their words (uses more memory, only applies to app runs that connect after the change)
+	LogSearchIndex bool
+
+	// LogTails are log files and journald units the monitor follows, their lines are added to the matching
+	// running app runs (see the logtail package)
+	LogTails []LogTailConfig
+
+	// AlertRules are log searches checked against every incoming log line, a matching line publishes an
+	// Event_LogAlert (see apppeer.CheckLogAlerts)
+	AlertRules []AlertRule
+}
+
+// LogTailConfig is a log file or journald unit to follow (one of File and Journald is set)
+type LogTailConfig struct {
+	File     string `json:"file,omitempty"`     // absolute path, rotation and truncation are followed
+	Journald string `json:"journald,omitempty"` // systemd unit, lines go to the running app run with the same pid
+	AppName  string `json:"appname,omitempty"`  // add the lines to the running app runs with this app name (required for files, journald lines that match an app run's pid ignore it)
+	Source   string `json:"source,omitempty"`   // log source of the lines (defaults to the file name or the unit)
+}
+
+// AlertRule alerts when a log line matches Search (a gensearch query, the same syntax as the log search box)
+type AlertRule struct {
+	Name        string `json:"name"`
+	AppName     string `json:"appname,omitempty"` // only check the app runs with this app name (all app runs if empty)
+	Search      string `json:"search"`
+	CooldownSec int    `json:"cooldownsec,omitempty"` // minimum time between two alerts of the rule for the same app run
+}
+
+var runtimeSettings atomic.Pointer[RuntimeSettings]
+
+func init() {
+	runtimeSettings.Store(&RuntimeSettings{LogBufferSizeMB: DefaultLogBufferSizeMB})
+}
+
+// GetRuntimeSettings returns the current settings (callers must not modify EmbedAllowedOrigins, Downstreams, LogTails, or AlertRules)
+func GetRuntimeSettings() RuntimeSettings {
+	return *runtimeSettings.Load()
+}
+
+// SetRuntimeSettings replaces all of the settings at once
+// This gets set from boot.RunServer during initialization and on config file reloads
+func SetRuntimeSettings(settings RuntimeSettings) {
+	runtimeSettings.Store(&settings)
+}
+
+// SplitDownstreamAddr splits the address of a downstream monitor (apikey@host:port) into the api key (a key
+// with the federation scope created on the downstream monitor, "" if there is none) and host:port
+func SplitDownstreamAddr(addr string) (string, string) {
+	idx := strings.LastIndex(addr, "@")
+	if idx == -1 {
+		return "", addr
+	}
+	return addr[:idx], addr[idx+1:]
+}
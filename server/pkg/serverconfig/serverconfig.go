@@ -0,0 +1,510 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package serverconfig loads the monitor config file (monitor.json in the outrig home directory) and
+// watches it, so retention limits, the log buffer size, embed origins, downstream monitors, log tails, log alert rules,
+// and the remote listener can be changed without restarting the monitor. A reload is applied all at once or not at all.
+package serverconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/gensearch"
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+const ConfigFileName = "monitor.json"
+
+// PollInterval is how often the config file is checked for changes
+const PollInterval = 2 * time.Second
+
+// setting names (used in ConfigChangedEvent)
+const (
+	Setting_LogBufferSizeMB     = "logbuffersizemb"
+	Setting_MaxRunsPerApp       = "maxrunsperapp"
+	Setting_MaxRunAge           = "maxrunage"
+	Setting_MaxDataDirSizeMB    = "maxdatadirsizemb"
+	Setting_EmbedAllowedOrigins = "embedallowedorigins"
+	Setting_RemoteListen        = "remotelisten"
+	Setting_Downstreams         = "downstreams"
+	Setting_SessionTimeline     = "sessiontimeline"
+	Setting_LogSearchIndex      = "logsearchindex"
+	Setting_LogTails            = "logtails"
+	Setting_AlertRules          = "alertrules"
+)
+
+// Config is the monitor config file, settings that are not set keep their command line value
+type Config struct {
+	// LogBufferSizeMB only applies to app runs that connect after the change
+	LogBufferSizeMB     *int     `json:"logbuffersizemb,omitempty"`
+	MaxRunsPerApp       *int     `json:"maxrunsperapp,omitempty"`
+	MaxRunAge           *string  `json:"maxrunage,omitempty"` // Go duration, e.g. "72h" ("0" for no limit)
+	MaxDataDirSizeMB    *int     `json:"maxdatadirsizemb,omitempty"`
+	EmbedAllowedOrigins []string `json:"embedallowedorigins,omitempty"`
+
+	// RemoteListen starts the remote SDK listener if it is not running yet ("" disables it),
+	// changing or stopping a running listener requires a restart
+	RemoteListen *string `json:"remotelisten,omitempty"`
+
+	// Downstreams are other monitors (name => apikey@host:port) whose app runs are shown read-only in this monitor,
+	// they replace the --downstream flags
+	Downstreams map[string]string `json:"downstreams,omitempty"`
+
+	// SessionTimeline enables the local session timeline (see tevent.RecordSessionEvent)
+	SessionTimeline *bool `json:"sessiontimeline,omitempty"`
+
+	// LogSearchIndex enables the word index of the log lines (only applies to app runs that connect after the change)
+	LogSearchIndex *bool `json:"logsearchindex,omitempty"`
+
+	// LogTails are log files and journald units to follow, for apps that log to files instead of stdout
+	LogTails []serverbase.LogTailConfig `json:"logtails,omitempty"`
+
+	// AlertRules are log searches that publish an alert when a new log line matches
+	AlertRules []serverbase.AlertRule `json:"alertrules,omitempty"`
+}
+
+// Effective is the result of applying a config file on top of the command line values
+type Effective struct {
+	Settings     serverbase.RuntimeSettings
+	RemoteListen string
+}
+
+// GetConfigFilePath returns the path of the monitor config file
+func GetConfigFilePath() string {
+	return filepath.Join(serverbase.GetOutrigHome(), ConfigFileName)
+}
+
+// ParseConfig parses the config file contents, unknown keys are an error (they are usually typos)
+func ParseConfig(content []byte) (*Config, error) {
+	var cfg Config
+	if len(bytes.TrimSpace(content)) == 0 {
+		return &cfg, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", ConfigFileName, err)
+	}
+	return &cfg, nil
+}
+
+// LoadConfig reads the config file at path (a missing file is an empty config)
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(utilfn.ExpandHomeDir(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(content)
+}
+
+// Resolve validates the config and returns base overridden by the settings in the config
+func (cfg *Config) Resolve(base Effective) (Effective, error) {
+	rtn := base
+	rtn.Settings.EmbedAllowedOrigins = slices.Clone(base.Settings.EmbedAllowedOrigins)
+	rtn.Settings.Downstreams = maps.Clone(base.Settings.Downstreams)
+	rtn.Settings.LogTails = slices.Clone(base.Settings.LogTails)
+	rtn.Settings.AlertRules = slices.Clone(base.Settings.AlertRules)
+	if cfg.LogBufferSizeMB != nil {
+		if *cfg.LogBufferSizeMB < 0 {
+			return base, fmt.Errorf("%s cannot be negative", Setting_LogBufferSizeMB)
+		}
+		rtn.Settings.LogBufferSizeMB = *cfg.LogBufferSizeMB
+	}
+	if cfg.MaxRunsPerApp != nil {
+		if *cfg.MaxRunsPerApp < 0 {
+			return base, fmt.Errorf("%s cannot be negative", Setting_MaxRunsPerApp)
+		}
+		rtn.Settings.MaxAppRunsPerApp = *cfg.MaxRunsPerApp
+	}
+	if cfg.MaxRunAge != nil {
+		maxAge, err := time.ParseDuration(*cfg.MaxRunAge)
+		if err != nil {
+			return base, fmt.Errorf("invalid %s: %w", Setting_MaxRunAge, err)
+		}
+		if maxAge < 0 {
+			return base, fmt.Errorf("%s cannot be negative", Setting_MaxRunAge)
+		}
+		rtn.Settings.MaxAppRunAge = maxAge
+	}
+	if cfg.MaxDataDirSizeMB != nil {
+		if *cfg.MaxDataDirSizeMB < 0 {
+			return base, fmt.Errorf("%s cannot be negative", Setting_MaxDataDirSizeMB)
+		}
+		rtn.Settings.MaxDataDirSizeMB = *cfg.MaxDataDirSizeMB
+	}
+	if cfg.EmbedAllowedOrigins != nil {
+		for _, origin := range cfg.EmbedAllowedOrigins {
+			if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+				return base, fmt.Errorf("invalid %s entry %q (must be \"*\" or start with http:// or https://)", Setting_EmbedAllowedOrigins, origin)
+			}
+		}
+		rtn.Settings.EmbedAllowedOrigins = slices.Clone(cfg.EmbedAllowedOrigins)
+	}
+	if cfg.Downstreams != nil {
+		if err := ValidateDownstreams(cfg.Downstreams); err != nil {
+			return base, fmt.Errorf("invalid %s: %w", Setting_Downstreams, err)
+		}
+		rtn.Settings.Downstreams = maps.Clone(cfg.Downstreams)
+	}
+	if cfg.SessionTimeline != nil {
+		rtn.Settings.SessionTimeline = *cfg.SessionTimeline
+	}
+	if cfg.LogSearchIndex != nil {
+		rtn.Settings.LogSearchIndex = *cfg.LogSearchIndex
+	}
+	if cfg.LogTails != nil {
+		if err := ValidateLogTails(cfg.LogTails); err != nil {
+			return base, fmt.Errorf("invalid %s: %w", Setting_LogTails, err)
+		}
+		rtn.Settings.LogTails = slices.Clone(cfg.LogTails)
+	}
+	if cfg.AlertRules != nil {
+		if err := ValidateAlertRules(cfg.AlertRules); err != nil {
+			return base, fmt.Errorf("invalid %s: %w", Setting_AlertRules, err)
+		}
+		rtn.Settings.AlertRules = slices.Clone(cfg.AlertRules)
+	}
+	if cfg.RemoteListen != nil {
+		if *cfg.RemoteListen != "" {
+			if _, _, err := net.SplitHostPort(*cfg.RemoteListen); err != nil {
+				return base, fmt.Errorf("invalid %s: %w", Setting_RemoteListen, err)
+			}
+		}
+		rtn.RemoteListen = *cfg.RemoteListen
+	}
+	return rtn, nil
+}
+
+// SetSettings returns the names of the settings that are set in the config file (in Setting_ order)
+func (cfg *Config) SetSettings() []string {
+	var settings []string
+	if cfg.LogBufferSizeMB != nil {
+		settings = append(settings, Setting_LogBufferSizeMB)
+	}
+	if cfg.MaxRunsPerApp != nil {
+		settings = append(settings, Setting_MaxRunsPerApp)
+	}
+	if cfg.MaxRunAge != nil {
+		settings = append(settings, Setting_MaxRunAge)
+	}
+	if cfg.MaxDataDirSizeMB != nil {
+		settings = append(settings, Setting_MaxDataDirSizeMB)
+	}
+	if cfg.EmbedAllowedOrigins != nil {
+		settings = append(settings, Setting_EmbedAllowedOrigins)
+	}
+	if cfg.RemoteListen != nil {
+		settings = append(settings, Setting_RemoteListen)
+	}
+	if cfg.Downstreams != nil {
+		settings = append(settings, Setting_Downstreams)
+	}
+	if cfg.SessionTimeline != nil {
+		settings = append(settings, Setting_SessionTimeline)
+	}
+	if cfg.LogSearchIndex != nil {
+		settings = append(settings, Setting_LogSearchIndex)
+	}
+	if cfg.LogTails != nil {
+		settings = append(settings, Setting_LogTails)
+	}
+	if cfg.AlertRules != nil {
+		settings = append(settings, Setting_AlertRules)
+	}
+	return settings
+}
+
+// ValidateDownstreams checks the names (used in route ids) and the apikey@host:port addresses of downstream monitors
+func ValidateDownstreams(downstreams map[string]string) error {
+	for name, addr := range downstreams {
+		if name == "" || strings.ContainsAny(name, ": /") {
+			return fmt.Errorf("invalid monitor name %q (cannot be empty or contain ':', '/', or spaces)", name)
+		}
+		apiKey, hostPort := serverbase.SplitDownstreamAddr(addr)
+		if _, _, err := net.SplitHostPort(hostPort); err != nil {
+			return fmt.Errorf("invalid address for monitor %q: %w", name, err)
+		}
+		if apiKey == "" {
+			return fmt.Errorf("no api key for monitor %q (expected apikey@host:port, with a key created on that monitor with the %q scope)", name, serverbase.ApiKeyScopeFederation)
+		}
+	}
+	return nil
+}
+
+// ValidateLogTails checks that each entry follows either an absolute file path (with the app name the lines
+// belong to) or a journald unit
+func ValidateLogTails(logTails []serverbase.LogTailConfig) error {
+	for idx, logTail := range logTails {
+		if (logTail.File == "") == (logTail.Journald == "") {
+			return fmt.Errorf("entry %d must set one of \"file\" or \"journald\"", idx)
+		}
+		if logTail.File != "" {
+			if !filepath.IsAbs(utilfn.ExpandHomeDir(logTail.File)) {
+				return fmt.Errorf("entry %d: file %q must be an absolute path", idx, logTail.File)
+			}
+			if logTail.AppName == "" {
+				return fmt.Errorf("entry %d: file %q needs an \"appname\" (the app runs its lines are added to)", idx, logTail.File)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateAlertRules checks that each rule has a unique name and a search that parses
+func ValidateAlertRules(rules []serverbase.AlertRule) error {
+	names := make(map[string]bool)
+	for idx, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("entry %d needs a \"name\"", idx)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+		if strings.TrimSpace(rule.Search) == "" {
+			return fmt.Errorf("rule %q needs a \"search\"", rule.Name)
+		}
+		if _, err := gensearch.GetSearcher(rule.Search); err != nil {
+			return fmt.Errorf("rule %q: invalid search: %w", rule.Name, err)
+		}
+		if rule.CooldownSec < 0 {
+			return fmt.Errorf("rule %q: cooldownsec cannot be negative", rule.Name)
+		}
+	}
+	return nil
+}
+
+// diffSettings returns the names of the runtime settings that differ
+func diffSettings(oldSettings serverbase.RuntimeSettings, newSettings serverbase.RuntimeSettings) []string {
+	var changed []string
+	if oldSettings.LogBufferSizeMB != newSettings.LogBufferSizeMB {
+		changed = append(changed, Setting_LogBufferSizeMB)
+	}
+	if oldSettings.MaxAppRunsPerApp != newSettings.MaxAppRunsPerApp {
+		changed = append(changed, Setting_MaxRunsPerApp)
+	}
+	if oldSettings.MaxAppRunAge != newSettings.MaxAppRunAge {
+		changed = append(changed, Setting_MaxRunAge)
+	}
+	if oldSettings.MaxDataDirSizeMB != newSettings.MaxDataDirSizeMB {
+		changed = append(changed, Setting_MaxDataDirSizeMB)
+	}
+	if !slices.Equal(oldSettings.EmbedAllowedOrigins, newSettings.EmbedAllowedOrigins) {
+		changed = append(changed, Setting_EmbedAllowedOrigins)
+	}
+	if !maps.Equal(oldSettings.Downstreams, newSettings.Downstreams) {
+		changed = append(changed, Setting_Downstreams)
+	}
+	if oldSettings.SessionTimeline != newSettings.SessionTimeline {
+		changed = append(changed, Setting_SessionTimeline)
+	}
+	if oldSettings.LogSearchIndex != newSettings.LogSearchIndex {
+		changed = append(changed, Setting_LogSearchIndex)
+	}
+	if !slices.Equal(oldSettings.LogTails, newSettings.LogTails) {
+		changed = append(changed, Setting_LogTails)
+	}
+	if !slices.Equal(oldSettings.AlertRules, newSettings.AlertRules) {
+		changed = append(changed, Setting_AlertRules)
+	}
+	return changed
+}
+
+// WatcherOpts configures the config file watcher
+type WatcherOpts struct {
+	Path string
+	Base Effective // the command line values
+
+	// Current is what the server is running with (from the config file loaded at startup)
+	Current Effective
+	File    *Config // the config file loaded at startup
+
+	// StartRemoteListener starts the remote SDK listener (called when a reload enables it)
+	StartRemoteListener func(addr string) error
+}
+
+type watcher struct {
+	opts WatcherOpts
+
+	lock         sync.Mutex
+	current      Effective
+	lastModTime  time.Time
+	lastSize     int64
+	lastExists   bool
+	remoteListen string // address of the running remote listener ("" if not running)
+	fileSettings []string
+	lastError    string // the last reload was rejected
+}
+
+// activeWatcher is the watcher started by StartWatcher (used by GetMonitorEffectiveConfig)
+var activeWatcher atomic.Pointer[watcher]
+
+// StartWatcher polls the config file and applies changes until ctx is done
+func StartWatcher(ctx context.Context, opts WatcherOpts) {
+	w := &watcher{
+		opts:         opts,
+		current:      opts.Current,
+		remoteListen: opts.Current.RemoteListen,
+	}
+	if opts.File != nil {
+		w.fileSettings = opts.File.SetSettings()
+	}
+	w.lastModTime, w.lastSize, w.lastExists = statFile(opts.Path)
+	activeWatcher.Store(w)
+	go func() {
+		outrig.SetGoRoutineName("serverconfig.watcher")
+		ticker := time.NewTicker(PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.checkForChanges()
+			}
+		}
+	}()
+}
+
+func statFile(path string) (time.Time, int64, bool) {
+	info, err := os.Stat(utilfn.ExpandHomeDir(path))
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	return info.ModTime(), info.Size(), true
+}
+
+func (w *watcher) checkForChanges() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	modTime, size, exists := statFile(w.opts.Path)
+	if exists == w.lastExists && modTime.Equal(w.lastModTime) && size == w.lastSize {
+		return
+	}
+	w.lastModTime, w.lastSize, w.lastExists = modTime, size, exists
+	event, err := w.reload()
+	w.lastError = ""
+	if err != nil {
+		w.lastError = err.Error()
+		log.Printf("rejected %s reload (keeping the previous config): %v\n", w.opts.Path, err)
+		event.Error = err.Error()
+	} else if len(event.Changed) == 0 && len(event.RestartRequired) == 0 {
+		return
+	} else {
+		log.Printf("reloaded %s, changed: [%s], restart required: [%s]\n", w.opts.Path, strings.Join(event.Changed, ", "), strings.Join(event.RestartRequired, ", "))
+	}
+	rpc.Broker.Publish(rpctypes.EventType{
+		Event: rpctypes.Event_ConfigChanged,
+		Data:  event,
+	})
+}
+
+// reload loads and validates the whole file before changing anything, so an invalid file leaves the
+// current config in place. lock must be held.
+func (w *watcher) reload() (rpctypes.ConfigChangedEvent, error) {
+	event := rpctypes.ConfigChangedEvent{
+		Ts:   time.Now().UnixMilli(),
+		Path: w.opts.Path,
+	}
+	cfg, err := LoadConfig(w.opts.Path)
+	if err != nil {
+		return event, err
+	}
+	next, err := cfg.Resolve(w.opts.Base)
+	if err != nil {
+		return event, err
+	}
+	event.Changed = diffSettings(w.current.Settings, next.Settings)
+	if next.RemoteListen != w.current.RemoteListen {
+		if w.remoteListen == "" && next.RemoteListen != "" {
+			// starting a new listener is safe, and it is the only step that can fail so it goes first
+			if w.opts.StartRemoteListener == nil {
+				return event, fmt.Errorf("cannot start the remote listener")
+			}
+			if err := w.opts.StartRemoteListener(next.RemoteListen); err != nil {
+				return event, err
+			}
+			w.remoteListen = next.RemoteListen
+			event.Changed = append(event.Changed, Setting_RemoteListen)
+		} else if next.RemoteListen != w.remoteListen {
+			event.RestartRequired = append(event.RestartRequired, Setting_RemoteListen)
+		}
+	}
+	w.current = next
+	w.fileSettings = cfg.SetSettings()
+	serverbase.SetRuntimeSettings(next.Settings)
+	return event, nil
+}
+
+// getDownstreamHosts returns the downstream monitors without their api keys (name => host:port)
+func getDownstreamHosts(downstreams map[string]string) map[string]string {
+	if downstreams == nil {
+		return nil
+	}
+	rtn := make(map[string]string, len(downstreams))
+	for name, addr := range downstreams {
+		_, rtn[name] = serverbase.SplitDownstreamAddr(addr)
+	}
+	return rtn
+}
+
+// getAlertRuleNames returns the names of the alert rules (in config file order)
+func getAlertRuleNames(rules []serverbase.AlertRule) []string {
+	var rtn []string
+	for _, rule := range rules {
+		rtn = append(rtn, rule.Name)
+	}
+	return rtn
+}
+
+// GetMonitorEffectiveConfig returns the settings the monitor is running with and where they came from
+func GetMonitorEffectiveConfig() rpctypes.MonitorEffectiveConfig {
+	settings := serverbase.GetRuntimeSettings()
+	rtn := rpctypes.MonitorEffectiveConfig{
+		Version:             serverbase.OutrigServerVersion,
+		Dev:                 serverbase.IsDev(),
+		OutrigHome:          serverbase.GetOutrigHome(),
+		DataDir:             serverbase.GetOutrigDataDir(),
+		ConfigFile:          GetConfigFilePath(),
+		LogBufferSizeMB:     settings.LogBufferSizeMB,
+		MaxRunsPerApp:       settings.MaxAppRunsPerApp,
+		MaxRunAge:           settings.MaxAppRunAge.String(),
+		MaxDataDirSizeMB:    settings.MaxDataDirSizeMB,
+		EmbedAllowedOrigins: settings.EmbedAllowedOrigins,
+		Downstreams:         getDownstreamHosts(settings.Downstreams),
+		SessionTimeline:     settings.SessionTimeline,
+		LogSearchIndex:      settings.LogSearchIndex,
+		AlertRules:          getAlertRuleNames(settings.AlertRules),
+	}
+	_, _, rtn.ConfigFileExists = statFile(rtn.ConfigFile)
+	if w := activeWatcher.Load(); w != nil {
+		w.lock.Lock()
+		defer w.lock.Unlock()
+		rtn.ConfigFileSettings = w.fileSettings
+		rtn.ConfigFileError = w.lastError
+		rtn.RemoteListen = w.remoteListen
+	}
+	return rtn
+}
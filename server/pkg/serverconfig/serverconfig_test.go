@@ -0,0 +1,233 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package serverconfig
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+var testBase = Effective{
+	Settings: serverbase.RuntimeSettings{
+		LogBufferSizeMB:  1024,
+		MaxAppRunsPerApp: 10,
+	},
+}
+
+func TestResolveOverridesOnlySetFields(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{"maxrunage": "72h", "embedallowedorigins": ["https://grafana.example.com"], "remotelisten": ":5006"}`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	eff, err := cfg.Resolve(testBase)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if eff.Settings.LogBufferSizeMB != 1024 || eff.Settings.MaxAppRunsPerApp != 10 {
+		t.Errorf("unset fields should keep their base values, got %+v", eff.Settings)
+	}
+	if eff.Settings.MaxAppRunAge != 72*time.Hour {
+		t.Errorf("expected maxrunage 72h, got %v", eff.Settings.MaxAppRunAge)
+	}
+	if !slices.Equal(eff.Settings.EmbedAllowedOrigins, []string{"https://grafana.example.com"}) {
+		t.Errorf("unexpected embed origins %v", eff.Settings.EmbedAllowedOrigins)
+	}
+	if eff.RemoteListen != ":5006" {
+		t.Errorf("expected remote listen :5006, got %q", eff.RemoteListen)
+	}
+	if eff.Settings.Downstreams != nil {
+		t.Errorf("unset downstreams should keep the base value, got %v", eff.Settings.Downstreams)
+	}
+
+	cfg, err = ParseConfig([]byte(`{"downstreams": {"staging": "ork_key@staging.internal:5005"}}`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	eff, err = cfg.Resolve(testBase)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if eff.Settings.Downstreams["staging"] != "ork_key@staging.internal:5005" || len(eff.Settings.Downstreams) != 1 {
+		t.Errorf("unexpected downstreams %v", eff.Settings.Downstreams)
+	}
+	if !slices.Equal(diffSettings(testBase.Settings, eff.Settings), []string{Setting_Downstreams}) {
+		t.Errorf("expected only downstreams to change, got %v", diffSettings(testBase.Settings, eff.Settings))
+	}
+
+	cfg, err = ParseConfig([]byte(`{"sessiontimeline": true}`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	eff, err = cfg.Resolve(testBase)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if !eff.Settings.SessionTimeline {
+		t.Errorf("expected the session timeline to be enabled")
+	}
+	if !slices.Equal(diffSettings(testBase.Settings, eff.Settings), []string{Setting_SessionTimeline}) {
+		t.Errorf("expected only sessiontimeline to change, got %v", diffSettings(testBase.Settings, eff.Settings))
+	}
+	cfg, err = ParseConfig([]byte(`{"logtails": [{"file": "/var/log/api.log", "appname": "api"}, {"journald": "worker.service"}]}`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	eff, err = cfg.Resolve(testBase)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if len(eff.Settings.LogTails) != 2 || eff.Settings.LogTails[1].Journald != "worker.service" {
+		t.Errorf("unexpected log tails %+v", eff.Settings.LogTails)
+	}
+	if !slices.Equal(diffSettings(testBase.Settings, eff.Settings), []string{Setting_LogTails}) {
+		t.Errorf("expected only logtails to change, got %v", diffSettings(testBase.Settings, eff.Settings))
+	}
+	cfg, err = ParseConfig([]byte(`{"alertrules": [{"name": "errors", "search": "ERROR", "cooldownsec": 60}, {"name": "api panics", "appname": "api", "search": "panic"}]}`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	eff, err = cfg.Resolve(testBase)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if len(eff.Settings.AlertRules) != 2 || eff.Settings.AlertRules[0].CooldownSec != 60 || eff.Settings.AlertRules[1].AppName != "api" {
+		t.Errorf("unexpected alert rules %+v", eff.Settings.AlertRules)
+	}
+	if !slices.Equal(diffSettings(testBase.Settings, eff.Settings), []string{Setting_AlertRules}) {
+		t.Errorf("expected only alertrules to change, got %v", diffSettings(testBase.Settings, eff.Settings))
+	}
+}
+
+func TestInvalidConfigs(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"bad json", `{"maxrunsperapp": }`},
+		{"unknown key", `{"maxrunsperap": 5}`},
+		{"negative runs", `{"maxrunsperapp": -1}`},
+		{"negative buffer", `{"logbuffersizemb": -5}`},
+		{"bad duration", `{"maxrunage": "3 days"}`},
+		{"negative data dir size", `{"maxdatadirsizemb": -1}`},
+		{"bad origin", `{"embedallowedorigins": ["grafana.example.com"]}`},
+		{"bad address", `{"remotelisten": "5006"}`},
+		{"bad downstream name", `{"downstreams": {"staging:1": "ork_key@staging.internal:5005"}}`},
+		{"bad downstream address", `{"downstreams": {"staging": "ork_key@staging.internal"}}`},
+		{"downstream without api key", `{"downstreams": {"staging": "staging.internal:5005"}}`},
+		{"log tail without source", `{"logtails": [{"appname": "api"}]}`},
+		{"log tail with file and unit", `{"logtails": [{"file": "/var/log/api.log", "journald": "api.service", "appname": "api"}]}`},
+		{"relative log tail file", `{"logtails": [{"file": "api.log", "appname": "api"}]}`},
+		{"log tail file without app", `{"logtails": [{"file": "/var/log/api.log"}]}`},
+		{"alert rule without name", `{"alertrules": [{"search": "ERROR"}]}`},
+		{"alert rule without search", `{"alertrules": [{"name": "errors"}]}`},
+		{"duplicate alert rule", `{"alertrules": [{"name": "errors", "search": "ERROR"}, {"name": "errors", "search": "panic"}]}`},
+		{"negative alert cooldown", `{"alertrules": [{"name": "errors", "search": "ERROR", "cooldownsec": -1}]}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := ParseConfig([]byte(tc.content))
+			if err == nil {
+				_, err = cfg.Resolve(testBase)
+			}
+			if err == nil {
+				t.Errorf("expected an error for %s", tc.content)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), ConfigFileName))
+	if err != nil {
+		t.Fatalf("a missing config file should not be an error: %v", err)
+	}
+	eff, err := cfg.Resolve(testBase)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if len(diffSettings(testBase.Settings, eff.Settings)) != 0 {
+		t.Errorf("an empty config should not change any settings, got %+v", eff.Settings)
+	}
+}
+
+func TestReloadRejectsInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	serverbase.SetRuntimeSettings(testBase.Settings)
+	w := &watcher{opts: WatcherOpts{Path: path, Base: testBase}, current: testBase}
+
+	os.WriteFile(path, []byte(`{"maxrunsperapp": 3, "logbuffersizemb": 0}`), 0644)
+	event, err := w.reload()
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if !slices.Equal(event.Changed, []string{Setting_LogBufferSizeMB, Setting_MaxRunsPerApp}) {
+		t.Errorf("unexpected changed settings %v", event.Changed)
+	}
+	if serverbase.GetRuntimeSettings().MaxAppRunsPerApp != 3 {
+		t.Errorf("reload was not applied")
+	}
+
+	// the valid maxrunsperapp must not be applied when another setting is invalid
+	os.WriteFile(path, []byte(`{"maxrunsperapp": 7, "maxrunage": "soon"}`), 0644)
+	_, err = w.reload()
+	if err == nil {
+		t.Fatalf("expected the reload to be rejected")
+	}
+	settings := serverbase.GetRuntimeSettings()
+	if settings.MaxAppRunsPerApp != 3 || settings.LogBufferSizeMB != 0 {
+		t.Errorf("rejected reload changed the settings: %+v", settings)
+	}
+}
+
+func TestReloadRemoteListen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	var started []string
+	w := &watcher{opts: WatcherOpts{
+		Path: path,
+		Base: testBase,
+		StartRemoteListener: func(addr string) error {
+			started = append(started, addr)
+			return nil
+		},
+	}, current: testBase}
+
+	os.WriteFile(path, []byte(`{"remotelisten": "127.0.0.1:5006"}`), 0644)
+	event, err := w.reload()
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if !slices.Equal(started, []string{"127.0.0.1:5006"}) || !slices.Contains(event.Changed, Setting_RemoteListen) {
+		t.Errorf("expected the remote listener to be started, started=%v event=%+v", started, event)
+	}
+
+	// a running listener can't be moved without a restart
+	os.WriteFile(path, []byte(`{"remotelisten": "127.0.0.1:5007"}`), 0644)
+	event, err = w.reload()
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if len(started) != 1 || !slices.Equal(event.RestartRequired, []string{Setting_RemoteListen}) {
+		t.Errorf("expected restart required, started=%v event=%+v", started, event)
+	}
+}
+
+func TestSetSettings(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{"sessiontimeline": false, "maxrunsperapp": 0, "remotelisten": ""}`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	// settings set to their zero value still override the command line
+	expected := []string{Setting_MaxRunsPerApp, Setting_RemoteListen, Setting_SessionTimeline}
+	if settings := cfg.SetSettings(); !slices.Equal(settings, expected) {
+		t.Errorf("SetSettings() = %v, expected %v", settings, expected)
+	}
+	if settings := (&Config{}).SetSettings(); len(settings) != 0 {
+		t.Errorf("an empty config should not set any settings, got %v", settings)
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package serverutil
+
+import (
+	"os"
+	"syscall"
+)
+
+// DaemonSysProcAttr detaches the monitor daemon from the terminal's process group
+func DaemonSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		Setpgid: true, // Create new process group
+	}
+}
+
+// IsProcessAlive returns true if the process has not exited
+func IsProcessAlive(proc *os.Process) bool {
+	return proc.Signal(syscall.Signal(0)) == nil
+}
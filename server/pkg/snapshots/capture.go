@@ -0,0 +1,159 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package snapshots
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/apppeer"
+	"github.com/outrigdev/outrig/server/pkg/gensearch"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// LogSearchTimeout bounds the log scan of one app run
+const LogSearchTimeout = 30 * time.Second
+
+// captureRule takes a snapshot of each running app run the rule applies to. App runs with nothing to capture
+// (no goroutine sample yet, or no new matching log lines) are skipped. Errors are combined, a failure in one
+// app run doesn't stop the others.
+func captureRule(rule rpctypes.SnapshotRule, now time.Time) ([]rpctypes.SnapshotInfo, error) {
+	var taken []rpctypes.SnapshotInfo
+	var errs []error
+	for _, peer := range apppeer.GetAllAppRunPeers() {
+		if peer.Status != apppeer.AppStatusRunning || peer.AppInfo == nil {
+			continue
+		}
+		if rule.AppName != "" && peer.AppInfo.AppName != rule.AppName {
+			continue
+		}
+		var content []byte
+		var numItems int
+		var err error
+		if rule.Kind == rpctypes.SnapshotKind_GoRoutines {
+			content, numItems = captureGoRoutines(peer, now)
+		} else {
+			content, numItems, err = captureLogs(peer, rule, now)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", peer.AppInfo.AppName, peer.AppRunId, err))
+			continue
+		}
+		if numItems == 0 {
+			continue
+		}
+		info := rpctypes.SnapshotInfo{
+			SnapshotId: uuid.New().String(),
+			RuleId:     rule.RuleId,
+			RuleName:   rule.Name,
+			Kind:       rule.Kind,
+			AppRunId:   peer.AppRunId,
+			AppName:    peer.AppInfo.AppName,
+			Ts:         now.UnixMilli(),
+			NumItems:   numItems,
+			Size:       int64(len(content)),
+			FileName:   fmt.Sprintf("%s-%s-%s.txt", peer.AppInfo.AppName, rule.Kind, now.Format("20060102-150405")),
+		}
+		if err := os.MkdirAll(getSnapshotsDir(), 0755); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := os.WriteFile(GetSnapshotFilePath(info.SnapshotId), content, 0644); err != nil {
+			errs = append(errs, fmt.Errorf("writing snapshot: %w", err))
+			continue
+		}
+		taken = append(taken, info)
+	}
+	return taken, errors.Join(errs...)
+}
+
+func writePreamble(buf *bytes.Buffer, peer *apppeer.AppRunPeer, now time.Time, desc string) {
+	fmt.Fprintf(buf, "# %s (app run %s): %s, captured at %s\n\n", peer.AppInfo.AppName, peer.AppRunId, desc, now.Format(time.RFC3339))
+}
+
+// captureGoRoutines writes the goroutines of the app run's latest sample in the runtime.Stack format (so the
+// file can be read by stack dump tools, and parsed with stackparse)
+func captureGoRoutines(peer *apppeer.AppRunPeer, now time.Time) ([]byte, int) {
+	result := peer.GoRoutines.GetParsedGoRoutinesAtTimestamp(peer.GetModuleInfo(), 0, true)
+	goRoutines := result.GoRoutines
+	if len(goRoutines) == 0 {
+		return nil, 0
+	}
+	sort.Slice(goRoutines, func(i, j int) bool {
+		return goRoutines[i].GoId < goRoutines[j].GoId
+	})
+	var buf bytes.Buffer
+	writePreamble(&buf, peer, now, fmt.Sprintf("goroutine dump (%d goroutines, sampled %s)", len(goRoutines), time.UnixMilli(result.EffectiveTimestamp).Format(time.RFC3339)))
+	for _, goRoutine := range goRoutines {
+		fmt.Fprintf(&buf, "goroutine %d [%s]:\n", goRoutine.GoId, goRoutine.RawState)
+		buf.WriteString(strings.TrimSpace(goRoutine.RawStackTrace))
+		buf.WriteString("\n\n")
+	}
+	return buf.Bytes(), len(goRoutines)
+}
+
+// captureLogs writes the app run's log lines that match the rule's search and were logged since the rule's
+// previous run
+func captureLogs(peer *apppeer.AppRunPeer, rule rpctypes.SnapshotRule, now time.Time) ([]byte, int, error) {
+	searcher, err := gensearch.GetSearcher(rule.SearchTerm)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctx, cancelFn := context.WithTimeout(context.Background(), LogSearchTimeout)
+	defer cancelFn()
+	sctx := &gensearch.SearchContext{Ctx: ctx}
+	var body bytes.Buffer
+	numLines := 0
+	lineSeq, _ := peer.Logs.GetLogLineSeq()
+	for line := range lineSeq {
+		if sctx.IsCanceled() {
+			return nil, 0, fmt.Errorf("log search timed out after %s", LogSearchTimeout)
+		}
+		if line.Ts <= rule.LastRunTs {
+			continue
+		}
+		if !searcher.Match(sctx, gensearch.LogLineToSearchObject(line)) {
+			continue
+		}
+		body.WriteString(formatLogLine(line))
+		body.WriteByte('\n')
+		numLines++
+	}
+	if numLines == 0 {
+		return nil, 0, nil
+	}
+	desc := "logs"
+	if rule.SearchTerm != "" {
+		desc = fmt.Sprintf("logs matching %q", rule.SearchTerm)
+	}
+	if rule.LastRunTs > 0 {
+		desc += fmt.Sprintf(" since %s", time.UnixMilli(rule.LastRunTs).Format(time.RFC3339))
+	}
+	var buf bytes.Buffer
+	writePreamble(&buf, peer, now, fmt.Sprintf("%s (%d lines)", desc, numLines))
+	buf.Write(body.Bytes())
+	return buf.Bytes(), numLines, nil
+}
+
+// formatLogLine formats a log line like "outrig logs" does, with the date (exports can span days)
+func formatLogLine(line ds.LogLine) string {
+	var sb strings.Builder
+	sb.WriteString(time.UnixMilli(line.Ts).Format("2006-01-02 15:04:05.000"))
+	if line.Source != "" && line.Source != "/dev/stdout" {
+		sb.WriteString(" [")
+		sb.WriteString(line.Source)
+		sb.WriteString("]")
+	}
+	sb.WriteString(" ")
+	sb.WriteString(strings.TrimRight(line.Msg, "\n"))
+	return sb.String()
+}
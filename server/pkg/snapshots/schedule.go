@@ -0,0 +1,222 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package snapshots
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinEveryInterval is the shortest "@every" interval (snapshots are checked by a ticker, and each one is written to disk)
+const MinEveryInterval = time.Minute
+
+// maxNextSearch bounds the search for the next cron match (an impossible date like "0 0 30 2 *" never matches)
+const maxNextSearch = 5 * 366 * 24 * time.Hour
+
+// Schedule returns the next time a snapshot rule runs
+type Schedule interface {
+	// Next returns the first run time after t (the zero time if there is none)
+	Next(t time.Time) time.Time
+}
+
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Truncate(time.Second).Add(s.interval)
+}
+
+// cronSchedule is a standard 5-field cron expression (minute hour day-of-month month day-of-week), in local time.
+// Each field is a bitset of the allowed values.
+type cronSchedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	domAll bool // day-of-month was "*"
+	dowAll bool // day-of-week was "*"
+}
+
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7}, // 0 and 7 are both Sunday
+}
+
+var scheduleShortcuts = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// ParseSchedule parses a snapshot rule schedule: "@every <duration>" (at least a minute), a shortcut (@hourly,
+// @daily, @midnight, @weekly, @monthly), or a 5-field cron expression. Cron fields accept "*", numbers, ranges
+// (1-5), steps (*/15, 0-30/10), and comma separated lists. As in cron, a day matches if either the day of month
+// or the day of week matches when both are restricted.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("empty schedule")
+	}
+	if strings.HasPrefix(spec, "@every") {
+		durStr := strings.TrimSpace(strings.TrimPrefix(spec, "@every"))
+		interval, err := time.ParseDuration(durStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid @every interval %q (use a duration like 10m or 1h30m)", durStr)
+		}
+		if interval < MinEveryInterval {
+			return nil, fmt.Errorf("@every interval %s is shorter than the minimum of %s", interval, MinEveryInterval)
+		}
+		return everySchedule{interval: interval}, nil
+	}
+	if strings.HasPrefix(spec, "@") {
+		expr, ok := scheduleShortcuts[spec]
+		if !ok {
+			return nil, fmt.Errorf("unknown schedule %q (use @every <duration>, @hourly, @daily, @weekly, @monthly, or a cron expression)", spec)
+		}
+		spec = expr
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("invalid cron expression %q (expected 5 fields: minute hour day-of-month month day-of-week)", spec)
+	}
+	var bits [5]uint64
+	for idx, field := range fields {
+		fieldBits, err := parseCronField(field, cronFields[idx])
+		if err != nil {
+			return nil, err
+		}
+		bits[idx] = fieldBits
+	}
+	dow := bits[4]
+	if dow&(1<<7) != 0 {
+		dow = (dow | 1) &^ (1 << 7)
+	}
+	return &cronSchedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    dow,
+		domAll: fields[2] == "*",
+		dowAll: fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		partBits, err := parseCronPart(part, spec)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q: %w", spec.name, field, err)
+		}
+		bits |= partBits
+	}
+	return bits, nil
+}
+
+// parseCronPart parses one list element of a cron field: *, n, a-b, with an optional /step
+func parseCronPart(part string, spec cronField) (uint64, error) {
+	rangeStr, stepStr, hasStep := strings.Cut(part, "/")
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepStr)
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("bad step %q", stepStr)
+		}
+	}
+	low, high := spec.min, spec.max
+	switch {
+	case rangeStr == "*":
+	case strings.Contains(rangeStr, "-"):
+		lowStr, highStr, _ := strings.Cut(rangeStr, "-")
+		var err error
+		if low, err = parseCronValue(lowStr, spec); err != nil {
+			return 0, err
+		}
+		if high, err = parseCronValue(highStr, spec); err != nil {
+			return 0, err
+		}
+		if low > high {
+			return 0, fmt.Errorf("range %q is backwards", rangeStr)
+		}
+	default:
+		value, err := parseCronValue(rangeStr, spec)
+		if err != nil {
+			return 0, err
+		}
+		low = value
+		if hasStep {
+			// "5/15" means starting at 5, every 15
+			high = spec.max
+		} else {
+			high = value
+		}
+	}
+	var bits uint64
+	for value := low; value <= high; value += step {
+		bits |= 1 << uint(value)
+	}
+	return bits, nil
+}
+
+func parseCronValue(str string, spec cronField) (int, error) {
+	value, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("bad value %q", str)
+	}
+	if value < spec.min || value > spec.max {
+		return 0, fmt.Errorf("value %d out of range (%d-%d)", value, spec.min, spec.max)
+	}
+	return value, nil
+}
+
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxNextSearch)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAll || s.dowAll {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,100 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package snapshots
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleNext(t *testing.T) {
+	// a Wednesday
+	base := time.Date(2025, 1, 15, 10, 7, 30, 0, time.Local)
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"@every 10m", time.Date(2025, 1, 15, 10, 17, 30, 0, time.Local)},
+		{"@every 1h30m", time.Date(2025, 1, 15, 11, 37, 30, 0, time.Local)},
+		{"@hourly", time.Date(2025, 1, 15, 11, 0, 0, 0, time.Local)},
+		{"@daily", time.Date(2025, 1, 16, 0, 0, 0, 0, time.Local)},
+		{"@midnight", time.Date(2025, 1, 16, 0, 0, 0, 0, time.Local)},
+		{"@weekly", time.Date(2025, 1, 19, 0, 0, 0, 0, time.Local)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local)},
+		{"* * * * *", time.Date(2025, 1, 15, 10, 8, 0, 0, time.Local)},
+		{"*/15 * * * *", time.Date(2025, 1, 15, 10, 15, 0, 0, time.Local)},
+		{"5/20 * * * *", time.Date(2025, 1, 15, 10, 25, 0, 0, time.Local)},
+		{"0 3 * * *", time.Date(2025, 1, 16, 3, 0, 0, 0, time.Local)},
+		{"30 9-17/4 * * *", time.Date(2025, 1, 15, 13, 30, 0, 0, time.Local)},
+		{"0 0 * * 1-5", time.Date(2025, 1, 16, 0, 0, 0, 0, time.Local)},
+		{"0 0 * * 6,7", time.Date(2025, 1, 18, 0, 0, 0, 0, time.Local)},
+		{"0 0 * * 7", time.Date(2025, 1, 19, 0, 0, 0, 0, time.Local)},
+		{"0 12 1 * *", time.Date(2025, 2, 1, 12, 0, 0, 0, time.Local)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.Local)},
+		// both day fields restricted: either one matches (the 20th, or the next Friday)
+		{"0 0 20 * 5", time.Date(2025, 1, 17, 0, 0, 0, 0, time.Local)},
+		{"0 0 1,20 * 1", time.Date(2025, 1, 20, 0, 0, 0, 0, time.Local)},
+		{"0 0 1 6 *", time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, test := range tests {
+		sched, err := ParseSchedule(test.spec)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) failed: %v", test.spec, err)
+			continue
+		}
+		next := sched.Next(base)
+		if !next.Equal(test.expected) {
+			t.Errorf("ParseSchedule(%q).Next(%v) = %v, expected %v", test.spec, base, next, test.expected)
+		}
+	}
+}
+
+func TestParseScheduleNextIsAfter(t *testing.T) {
+	sched, err := ParseSchedule("0 * * * *")
+	if err != nil {
+		t.Fatalf("ParseSchedule failed: %v", err)
+	}
+	onTheHour := time.Date(2025, 1, 15, 10, 0, 0, 0, time.Local)
+	next := sched.Next(onTheHour)
+	if !next.Equal(onTheHour.Add(time.Hour)) {
+		t.Errorf("Next should be strictly after the given time, got %v", next)
+	}
+}
+
+func TestParseScheduleImpossible(t *testing.T) {
+	sched, err := ParseSchedule("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("ParseSchedule failed: %v", err)
+	}
+	if next := sched.Next(time.Now()); !next.IsZero() {
+		t.Errorf("February 30th should never match, got %v", next)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	badSpecs := []string{
+		"",
+		"@every",
+		"@every 10",
+		"@every 30s",
+		"@yearly",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+		"-1 * * * *",
+	}
+	for _, spec := range badSpecs {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q) should fail", spec)
+		}
+	}
+}
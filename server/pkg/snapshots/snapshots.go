@@ -0,0 +1,442 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package snapshots runs scheduled snapshot rules ("every 10m capture a goroutine dump", "daily export the
+// logs matching ERROR") against the running app runs. Rules and the snapshot index are stored in the
+// snapshots directory of the data dir (rules.json and snapshots.json), each snapshot is a text file next to
+// them, so rules and snapshots survive monitor restarts and outlive their app runs.
+package snapshots
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/gensearch"
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// CheckInterval is how often the rules are checked for due runs (schedules have minute granularity)
+const CheckInterval = 15 * time.Second
+
+// MaxSnapshotsPerRule is the number of snapshots kept per rule, older snapshots are deleted
+const MaxSnapshotsPerRule = 100
+
+// MaxRules is the number of snapshot rules the monitor accepts
+const MaxRules = 100
+
+// SnapshotDownloadPath is where the web server serves the snapshot files (see GetSnapshotDownloadUrl)
+const SnapshotDownloadPath = "/api/snapshot"
+
+const rulesFileName = "rules.json"
+const indexFileName = "snapshots.json"
+
+var (
+	lock      sync.Mutex
+	runLock   sync.Mutex // serializes rule runs (scheduled and run now)
+	loadOnce  sync.Once
+	rules     []rpctypes.SnapshotRule
+	schedules = make(map[string]Schedule) // rule id => parsed schedule
+	snapshots []rpctypes.SnapshotInfo     // oldest first
+)
+
+// Start runs the due snapshot rules until ctx is done
+func Start(ctx context.Context) {
+	ensureLoaded()
+	go func() {
+		outrig.SetGoRoutineName("snapshots.scheduler")
+		ticker := time.NewTicker(CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			runDueRules(time.Now())
+		}
+	}()
+}
+
+func getSnapshotsDir() string {
+	return utilfn.ExpandHomeDir(serverbase.GetSnapshotsDir())
+}
+
+func ensureLoaded() {
+	loadOnce.Do(func() {
+		lock.Lock()
+		defer lock.Unlock()
+		var rulesData rpctypes.SnapshotRulesData
+		if err := readJsonFile(filepath.Join(getSnapshotsDir(), rulesFileName), &rulesData); err != nil {
+			log.Printf("[snapshots] error reading snapshot rules: %v\n", err)
+		}
+		now := time.Now()
+		for _, rule := range rulesData.Rules {
+			sched, err := ParseSchedule(rule.Schedule)
+			if err != nil {
+				log.Printf("[snapshots] skipping rule %q: %v\n", rule.Name, err)
+				continue
+			}
+			if rule.Disabled {
+				rule.NextRunTs = 0
+			} else if rule.NextRunTs == 0 {
+				rule.NextRunTs = sched.Next(now).UnixMilli()
+			}
+			// a NextRunTs in the past (the monitor wasn't running) runs once on the first check
+			schedules[rule.RuleId] = sched
+			rules = append(rules, rule)
+		}
+		var indexData rpctypes.SnapshotsData
+		if err := readJsonFile(filepath.Join(getSnapshotsDir(), indexFileName), &indexData); err != nil {
+			log.Printf("[snapshots] error reading snapshot index: %v\n", err)
+		}
+		// newest first on disk (same as GetSnapshots)
+		for idx := len(indexData.Snapshots) - 1; idx >= 0; idx-- {
+			snapshots = append(snapshots, indexData.Snapshots[idx])
+		}
+	})
+}
+
+// readJsonFile reads a json file into v, a missing file is not an error
+func readJsonFile(path string, v any) error {
+	barr, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(barr, v)
+}
+
+// writeJsonFile writes v to a temp file and renames it, so a crash never leaves a partial file
+func writeJsonFile(path string, v any) error {
+	barr, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmpPath := fmt.Sprintf("%s.tmp-%d", path, os.Getpid())
+	if err := os.WriteFile(tmpPath, barr, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+func saveRules_nolock() {
+	data := rpctypes.SnapshotRulesData{Rules: rules}
+	if data.Rules == nil {
+		data.Rules = []rpctypes.SnapshotRule{}
+	}
+	if err := writeJsonFile(filepath.Join(getSnapshotsDir(), rulesFileName), data); err != nil {
+		log.Printf("[snapshots] error writing snapshot rules: %v\n", err)
+	}
+}
+
+func saveIndex_nolock() {
+	data := rpctypes.SnapshotsData{Snapshots: getSnapshots_nolock(rpctypes.SnapshotsRequest{})}
+	for idx := range data.Snapshots {
+		// download urls are computed on read
+		data.Snapshots[idx].DownloadUrl = ""
+	}
+	if err := writeJsonFile(filepath.Join(getSnapshotsDir(), indexFileName), data); err != nil {
+		log.Printf("[snapshots] error writing snapshot index: %v\n", err)
+	}
+}
+
+func findRule_nolock(ruleId string) int {
+	for idx, rule := range rules {
+		if rule.RuleId == ruleId {
+			return idx
+		}
+	}
+	return -1
+}
+
+// GetRules returns the snapshot rules (in creation order)
+func GetRules() []rpctypes.SnapshotRule {
+	ensureLoaded()
+	lock.Lock()
+	defer lock.Unlock()
+	rtn := make([]rpctypes.SnapshotRule, len(rules))
+	copy(rtn, rules)
+	return rtn
+}
+
+// UpdateRule validates and stores a rule, a rule with an empty RuleId is created. The fields set by the
+// monitor (CreatedTs, LastRunTs, NextRunTs, LastError) are ignored.
+func UpdateRule(rule rpctypes.SnapshotRule) (rpctypes.SnapshotRule, error) {
+	ensureLoaded()
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.Schedule = strings.TrimSpace(rule.Schedule)
+	rule.AppName = strings.TrimSpace(rule.AppName)
+	sched, err := ParseSchedule(rule.Schedule)
+	if err != nil {
+		return rpctypes.SnapshotRule{}, err
+	}
+	switch rule.Kind {
+	case rpctypes.SnapshotKind_GoRoutines:
+		rule.SearchTerm = ""
+	case rpctypes.SnapshotKind_Logs:
+		if _, err := gensearch.GetSearcher(rule.SearchTerm); err != nil {
+			return rpctypes.SnapshotRule{}, fmt.Errorf("invalid search %q: %w", rule.SearchTerm, err)
+		}
+	default:
+		return rpctypes.SnapshotRule{}, fmt.Errorf("invalid snapshot kind %q (must be %q or %q)", rule.Kind, rpctypes.SnapshotKind_GoRoutines, rpctypes.SnapshotKind_Logs)
+	}
+	if rule.Name == "" {
+		rule.Name = defaultRuleName(rule)
+	}
+	now := time.Now()
+	lock.Lock()
+	defer lock.Unlock()
+	if rule.RuleId == "" {
+		if len(rules) >= MaxRules {
+			return rpctypes.SnapshotRule{}, fmt.Errorf("too many snapshot rules (max %d)", MaxRules)
+		}
+		rule.RuleId = uuid.New().String()
+		rule.CreatedTs = now.UnixMilli()
+		rule.LastRunTs = 0
+		rule.LastError = ""
+		rules = append(rules, rule)
+	} else {
+		idx := findRule_nolock(rule.RuleId)
+		if idx == -1 {
+			return rpctypes.SnapshotRule{}, fmt.Errorf("snapshot rule not found: %s", rule.RuleId)
+		}
+		rule.CreatedTs = rules[idx].CreatedTs
+		rule.LastRunTs = rules[idx].LastRunTs
+		rule.LastError = rules[idx].LastError
+		rules[idx] = rule
+	}
+	idx := findRule_nolock(rule.RuleId)
+	if rule.Disabled {
+		rules[idx].NextRunTs = 0
+	} else {
+		rules[idx].NextRunTs = sched.Next(now).UnixMilli()
+	}
+	schedules[rule.RuleId] = sched
+	saveRules_nolock()
+	return rules[idx], nil
+}
+
+func defaultRuleName(rule rpctypes.SnapshotRule) string {
+	var name string
+	if rule.Kind == rpctypes.SnapshotKind_GoRoutines {
+		name = "goroutine dump"
+	} else if rule.SearchTerm != "" {
+		name = fmt.Sprintf("logs matching %q", rule.SearchTerm)
+	} else {
+		name = "logs"
+	}
+	if rule.AppName != "" {
+		name = rule.AppName + " " + name
+	}
+	return fmt.Sprintf("%s (%s)", name, rule.Schedule)
+}
+
+// DeleteRule deletes a rule, its snapshots are kept (they can be deleted with DeleteSnapshot)
+func DeleteRule(ruleId string) error {
+	ensureLoaded()
+	lock.Lock()
+	defer lock.Unlock()
+	idx := findRule_nolock(ruleId)
+	if idx == -1 {
+		return fmt.Errorf("snapshot rule not found: %s", ruleId)
+	}
+	rules = append(rules[:idx], rules[idx+1:]...)
+	delete(schedules, ruleId)
+	saveRules_nolock()
+	return nil
+}
+
+// RunRule runs a rule now (even if it is disabled), the rule's schedule is not changed. Returns the snapshots
+// taken (none if no app run matched, or there was nothing to capture).
+func RunRule(ruleId string) ([]rpctypes.SnapshotInfo, error) {
+	ensureLoaded()
+	lock.Lock()
+	idx := findRule_nolock(ruleId)
+	var rule rpctypes.SnapshotRule
+	if idx != -1 {
+		rule = rules[idx]
+	}
+	lock.Unlock()
+	if idx == -1 {
+		return nil, fmt.Errorf("snapshot rule not found: %s", ruleId)
+	}
+	return runRule(rule, time.Now(), false)
+}
+
+func runDueRules(now time.Time) {
+	nowTs := now.UnixMilli()
+	lock.Lock()
+	var dueRules []rpctypes.SnapshotRule
+	for _, rule := range rules {
+		if !rule.Disabled && rule.NextRunTs > 0 && rule.NextRunTs <= nowTs {
+			dueRules = append(dueRules, rule)
+		}
+	}
+	lock.Unlock()
+	for _, rule := range dueRules {
+		runRule(rule, now, true)
+	}
+}
+
+// runRule captures the rule's snapshots and records the run, scheduled runs also advance NextRunTs
+func runRule(rule rpctypes.SnapshotRule, now time.Time, scheduled bool) ([]rpctypes.SnapshotInfo, error) {
+	runLock.Lock()
+	defer runLock.Unlock()
+	taken, runErr := captureRule(rule, now)
+	lock.Lock()
+	defer lock.Unlock()
+	snapshots = append(snapshots, taken...)
+	if len(taken) > 0 {
+		pruneSnapshots_nolock(rule.RuleId)
+		saveIndex_nolock()
+	}
+	if idx := findRule_nolock(rule.RuleId); idx != -1 {
+		rules[idx].LastRunTs = now.UnixMilli()
+		rules[idx].LastError = ""
+		if runErr != nil {
+			rules[idx].LastError = runErr.Error()
+		}
+		if scheduled {
+			rules[idx].NextRunTs = schedules[rule.RuleId].Next(now).UnixMilli()
+		}
+		saveRules_nolock()
+	}
+	for idx := range taken {
+		taken[idx].DownloadUrl = GetSnapshotDownloadUrl(taken[idx].SnapshotId)
+		rpc.Broker.Publish(rpctypes.EventType{
+			Event: rpctypes.Event_SnapshotTaken,
+			Data:  taken[idx],
+		})
+	}
+	if runErr != nil {
+		log.Printf("[snapshots] rule %q failed: %v\n", rule.Name, runErr)
+	}
+	return taken, runErr
+}
+
+// pruneSnapshots_nolock deletes the oldest snapshots of a rule past MaxSnapshotsPerRule
+func pruneSnapshots_nolock(ruleId string) {
+	count := 0
+	for _, info := range snapshots {
+		if info.RuleId == ruleId {
+			count++
+		}
+	}
+	if count <= MaxSnapshotsPerRule {
+		return
+	}
+	toDelete := count - MaxSnapshotsPerRule
+	kept := snapshots[:0]
+	for _, info := range snapshots {
+		if toDelete > 0 && info.RuleId == ruleId {
+			toDelete--
+			removeSnapshotFile(info)
+			continue
+		}
+		kept = append(kept, info)
+	}
+	snapshots = kept
+}
+
+func removeSnapshotFile(info rpctypes.SnapshotInfo) {
+	err := os.Remove(GetSnapshotFilePath(info.SnapshotId))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("[snapshots] error removing snapshot %s: %v\n", info.SnapshotId, err)
+	}
+}
+
+// GetSnapshotFilePath returns the path of a snapshot's file
+func GetSnapshotFilePath(snapshotId string) string {
+	return filepath.Join(getSnapshotsDir(), snapshotId+".txt")
+}
+
+// GetSnapshotDownloadUrl returns the (server relative) url to download a snapshot
+func GetSnapshotDownloadUrl(snapshotId string) string {
+	query := url.Values{}
+	query.Set("snapshotid", snapshotId)
+	return SnapshotDownloadPath + "?" + query.Encode()
+}
+
+// GetSnapshot returns a snapshot's info
+func GetSnapshot(snapshotId string) (rpctypes.SnapshotInfo, bool) {
+	ensureLoaded()
+	lock.Lock()
+	defer lock.Unlock()
+	for _, info := range snapshots {
+		if info.SnapshotId == snapshotId {
+			info.DownloadUrl = GetSnapshotDownloadUrl(info.SnapshotId)
+			return info, true
+		}
+	}
+	return rpctypes.SnapshotInfo{}, false
+}
+
+// GetSnapshots returns the snapshots matching the request, newest first
+func GetSnapshots(req rpctypes.SnapshotsRequest) []rpctypes.SnapshotInfo {
+	ensureLoaded()
+	lock.Lock()
+	defer lock.Unlock()
+	return getSnapshots_nolock(req)
+}
+
+func getSnapshots_nolock(req rpctypes.SnapshotsRequest) []rpctypes.SnapshotInfo {
+	rtn := []rpctypes.SnapshotInfo{}
+	for idx := len(snapshots) - 1; idx >= 0; idx-- {
+		info := snapshots[idx]
+		if req.RuleId != "" && info.RuleId != req.RuleId {
+			continue
+		}
+		if req.AppRunId != "" && info.AppRunId != req.AppRunId {
+			continue
+		}
+		info.DownloadUrl = GetSnapshotDownloadUrl(info.SnapshotId)
+		rtn = append(rtn, info)
+		if req.Limit > 0 && len(rtn) >= req.Limit {
+			break
+		}
+	}
+	sort.SliceStable(rtn, func(i, j int) bool {
+		return rtn[i].Ts > rtn[j].Ts
+	})
+	return rtn
+}
+
+// DeleteSnapshot deletes a snapshot and its file
+func DeleteSnapshot(snapshotId string) error {
+	ensureLoaded()
+	lock.Lock()
+	defer lock.Unlock()
+	for idx, info := range snapshots {
+		if info.SnapshotId == snapshotId {
+			removeSnapshotFile(info)
+			snapshots = append(snapshots[:idx], snapshots[idx+1:]...)
+			saveIndex_nolock()
+			return nil
+		}
+	}
+	return fmt.Errorf("snapshot not found: %s", snapshotId)
+}
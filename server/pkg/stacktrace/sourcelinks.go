@@ -0,0 +1,183 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package stacktrace
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+)
+
+// ModuleInfo describes the app's main module, used to annotate stack frames
+type ModuleInfo struct {
+	Name        string // main module path (e.g. "github.com/outrigdev/outrig")
+	MainPkgPath string // import path of the main package (BuildInfo.Path), used for frames in package "main"
+	GoVersion   string // Go version the app was built with (e.g. "go1.23.4"), used to link std library files
+	VcsRevision string // VCS revision of the main module (the "vcs.revision" build setting)
+}
+
+// MakeModuleInfo creates a ModuleInfo from the app's module name and (optional) build info
+func MakeModuleInfo(moduleName string, buildInfo *rpctypes.BuildInfoData) ModuleInfo {
+	modInfo := ModuleInfo{Name: moduleName}
+	if buildInfo != nil {
+		modInfo.MainPkgPath = buildInfo.Path
+		modInfo.GoVersion = buildInfo.GoVersion
+		modInfo.VcsRevision = buildInfo.Settings["vcs.revision"]
+	}
+	return modInfo
+}
+
+var (
+	goReleaseVersionRe = regexp.MustCompile(`^go\d+\.\d+(\.\d+)?((rc|beta)\d+)?$`)
+	pseudoVersionRe    = regexp.MustCompile(`\d{14}-([0-9a-f]{12})$`)
+	majorVersionRe     = regexp.MustCompile(`^v[2-9][0-9]*$`)
+)
+
+// annotateSourceLocation sets RelPath and VcsUrl on a frame:
+//   - module cache files (.../pkg/mod/<module>@<version>/<file>) become "<module>@<version>/<file>"
+//   - std library files (.../src/<pkg>/<file>) become "<pkg>/<file>"
+//   - files of the main module become relative to the module root
+//
+// VcsUrl is only set for modules hosted on a known code host (github.com, gitlab.com, bitbucket.org, golang.org/x),
+// for the main module it also requires the VCS revision from the build info.
+func annotateSourceLocation(frame *StackFrame, modInfo ModuleInfo) {
+	if frame.FilePath == "" {
+		return
+	}
+	filePath := strings.ReplaceAll(frame.FilePath, "\\", "/")
+	if modPath, version, fileRel, ok := splitModCachePath(filePath); ok {
+		frame.RelPath = modPath + "@" + version + "/" + fileRel
+		ref, isTag := vcsRefFromVersion(version)
+		frame.VcsUrl = makeVcsUrl(modPath, ref, isTag, fileRel, frame.LineNumber)
+		return
+	}
+	if frame.IsSys && !strings.Contains(frame.Package, ".") {
+		if idx := strings.LastIndex(filePath, "src/"+frame.Package+"/"); idx >= 0 && (idx == 0 || filePath[idx-1] == '/') {
+			stdRel := filePath[idx+len("src/"):]
+			frame.RelPath = stdRel
+			goVersion, _, _ := strings.Cut(modInfo.GoVersion, " ")
+			if goReleaseVersionRe.MatchString(goVersion) {
+				frame.VcsUrl = "https://github.com/golang/go/blob/" + goVersion + "/src/" + stdRel + "#L" + strconv.Itoa(frame.LineNumber)
+			}
+		}
+		return
+	}
+	if modInfo.Name == "" {
+		return
+	}
+	pkgPath := frame.Package
+	if pkgPath == "main" {
+		pkgPath = modInfo.MainPkgPath
+	}
+	if pkgPath != modInfo.Name && !strings.HasPrefix(pkgPath, modInfo.Name+"/") {
+		return
+	}
+	subPkg := strings.TrimPrefix(strings.TrimPrefix(pkgPath, modInfo.Name), "/")
+	var fileRel string
+	switch {
+	case strings.HasPrefix(filePath, modInfo.Name+"/"):
+		// -trimpath builds report main module files as <module>/<file>
+		fileRel = strings.TrimPrefix(filePath, modInfo.Name+"/")
+	case subPkg == "":
+		fileRel = path.Base(filePath)
+	case strings.HasSuffix(path.Dir(filePath), "/"+subPkg):
+		fileRel = subPkg + "/" + path.Base(filePath)
+	default:
+		return
+	}
+	frame.RelPath = fileRel
+	if modInfo.VcsRevision != "" {
+		frame.VcsUrl = makeVcsUrl(modInfo.Name, modInfo.VcsRevision, false, fileRel, frame.LineNumber)
+	}
+}
+
+// splitModCachePath splits a module cache path (or a -trimpath "<module>@<version>/<file>" path)
+// into the (unescaped) module path, version, and the file path relative to the module root
+func splitModCachePath(filePath string) (string, string, string, bool) {
+	modRel := filePath
+	if idx := strings.LastIndex(filePath, "/pkg/mod/"); idx >= 0 {
+		modRel = filePath[idx+len("/pkg/mod/"):]
+	} else if strings.HasPrefix(filePath, "/") || (len(filePath) > 1 && filePath[1] == ':') {
+		return "", "", "", false
+	}
+	escModPath, rest, found := strings.Cut(modRel, "@")
+	if !found || escModPath == "" {
+		return "", "", "", false
+	}
+	version, fileRel, found := strings.Cut(rest, "/")
+	if !found || !strings.HasPrefix(version, "v") || fileRel == "" {
+		return "", "", "", false
+	}
+	return unescapeModulePath(escModPath), version, fileRel, true
+}
+
+// unescapeModulePath undoes the module cache case-encoding ("!b" => "B")
+func unescapeModulePath(escPath string) string {
+	if !strings.Contains(escPath, "!") {
+		return escPath
+	}
+	var sb strings.Builder
+	upperNext := false
+	for _, ch := range escPath {
+		if ch == '!' {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			ch = []rune(strings.ToUpper(string(ch)))[0]
+			upperNext = false
+		}
+		sb.WriteRune(ch)
+	}
+	return sb.String()
+}
+
+// vcsRefFromVersion returns the VCS ref for a module version: the commit hash of a
+// pseudo-version, otherwise the version tag (isTag = true)
+func vcsRefFromVersion(version string) (string, bool) {
+	version = strings.TrimSuffix(version, "+incompatible")
+	if m := pseudoVersionRe.FindStringSubmatch(version); m != nil {
+		return m[1], false
+	}
+	return version, true
+}
+
+// makeVcsUrl returns a link to a line of a file in a module's repository ("" for unknown code hosts).
+// Modules in a repository subdirectory use "<subdir>/<version>" tags, a major version suffix (/v2)
+// is assumed to be a branch rather than a directory.
+func makeVcsUrl(modPath string, ref string, isTag bool, fileRel string, lineNumber int) string {
+	if ref == "" {
+		return ""
+	}
+	parts := strings.Split(modPath, "/")
+	if parts[0] == "golang.org" && len(parts) >= 3 && parts[1] == "x" {
+		parts = append([]string{"github.com", "golang"}, parts[2:]...)
+	}
+	if len(parts) < 3 {
+		return ""
+	}
+	repoUrl := "https://" + strings.Join(parts[:3], "/")
+	subDir := parts[3:]
+	if len(subDir) > 0 && majorVersionRe.MatchString(subDir[len(subDir)-1]) {
+		subDir = subDir[:len(subDir)-1]
+	}
+	if isTag && len(subDir) > 0 {
+		ref = strings.Join(subDir, "/") + "/" + ref
+	}
+	repoPath := path.Join(append(subDir, fileRel)...)
+	lineStr := strconv.Itoa(lineNumber)
+	switch parts[0] {
+	case "github.com":
+		return repoUrl + "/blob/" + ref + "/" + repoPath + "#L" + lineStr
+	case "gitlab.com":
+		return repoUrl + "/-/blob/" + ref + "/" + repoPath + "#L" + lineStr
+	case "bitbucket.org":
+		return repoUrl + "/src/" + ref + "/" + repoPath + "#lines-" + lineStr
+	default:
+		return ""
+	}
+}
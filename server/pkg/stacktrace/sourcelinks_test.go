@@ -0,0 +1,134 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package stacktrace
+
+import (
+	"testing"
+)
+
+func TestAnnotateSourceLocation(t *testing.T) {
+	modInfo := ModuleInfo{
+		Name:        "github.com/outrigdev/outrig",
+		MainPkgPath: "github.com/outrigdev/outrig/server",
+		GoVersion:   "go1.23.4",
+		VcsRevision: "0123456789abcdef0123456789abcdef01234567",
+	}
+	tests := []struct {
+		name            string
+		pkg             string
+		filePath        string
+		modInfo         ModuleInfo
+		expectedRelPath string
+		expectedVcsUrl  string
+	}{
+		{
+			name:            "module cache",
+			pkg:             "github.com/gorilla/mux",
+			filePath:        "/Users/mike/go/pkg/mod/github.com/gorilla/mux@v1.8.1/mux.go",
+			modInfo:         modInfo,
+			expectedRelPath: "github.com/gorilla/mux@v1.8.1/mux.go",
+			expectedVcsUrl:  "https://github.com/gorilla/mux/blob/v1.8.1/mux.go#L42",
+		},
+		{
+			name:            "module cache with escaped path and major version",
+			pkg:             "github.com/BurntSushi/toml/v2/internal",
+			filePath:        "/home/u/go/pkg/mod/github.com/!burnt!sushi/toml/v2@v2.0.1/internal/tz.go",
+			modInfo:         modInfo,
+			expectedRelPath: "github.com/BurntSushi/toml/v2@v2.0.1/internal/tz.go",
+			expectedVcsUrl:  "https://github.com/BurntSushi/toml/blob/v2.0.1/internal/tz.go#L42",
+		},
+		{
+			name:            "submodule tag",
+			pkg:             "github.com/aws/aws-sdk-go-v2/service/s3",
+			filePath:        "/home/u/go/pkg/mod/github.com/aws/aws-sdk-go-v2/service/s3@v1.50.0/api_client.go",
+			modInfo:         modInfo,
+			expectedRelPath: "github.com/aws/aws-sdk-go-v2/service/s3@v1.50.0/api_client.go",
+			expectedVcsUrl:  "https://github.com/aws/aws-sdk-go-v2/blob/service/s3/v1.50.0/service/s3/api_client.go#L42",
+		},
+		{
+			name:            "pseudo-version links the commit",
+			pkg:             "golang.org/x/net/http2",
+			filePath:        "/home/u/go/pkg/mod/golang.org/x/net@v0.0.0-20240102150405-abcdef012345/http2/server.go",
+			modInfo:         modInfo,
+			expectedRelPath: "golang.org/x/net@v0.0.0-20240102150405-abcdef012345/http2/server.go",
+			expectedVcsUrl:  "https://github.com/golang/net/blob/abcdef012345/http2/server.go#L42",
+		},
+		{
+			name:            "trimpath module path",
+			pkg:             "gitlab.com/acme/lib",
+			filePath:        "gitlab.com/acme/lib@v1.2.3/lib.go",
+			modInfo:         modInfo,
+			expectedRelPath: "gitlab.com/acme/lib@v1.2.3/lib.go",
+			expectedVcsUrl:  "https://gitlab.com/acme/lib/-/blob/v1.2.3/lib.go#L42",
+		},
+		{
+			name:            "unknown code host",
+			pkg:             "example.com/lib",
+			filePath:        "/home/u/go/pkg/mod/example.com/lib@v1.0.0/lib.go",
+			modInfo:         modInfo,
+			expectedRelPath: "example.com/lib@v1.0.0/lib.go",
+			expectedVcsUrl:  "",
+		},
+		{
+			name:            "std library",
+			pkg:             "internal/poll",
+			filePath:        "/opt/homebrew/Cellar/go/1.23.4/libexec/src/internal/poll/fd_unix.go",
+			modInfo:         modInfo,
+			expectedRelPath: "internal/poll/fd_unix.go",
+			expectedVcsUrl:  "https://github.com/golang/go/blob/go1.23.4/src/internal/poll/fd_unix.go#L42",
+		},
+		{
+			name:            "std library with devel go version",
+			pkg:             "runtime",
+			filePath:        "/usr/local/go/src/runtime/proc.go",
+			modInfo:         ModuleInfo{GoVersion: "devel go1.24-abcdef"},
+			expectedRelPath: "runtime/proc.go",
+			expectedVcsUrl:  "",
+		},
+		{
+			name:            "main module package",
+			pkg:             "github.com/outrigdev/outrig/pkg/rpc",
+			filePath:        "/Users/mike/work/outrig/pkg/rpc/rpcrouter.go",
+			modInfo:         modInfo,
+			expectedRelPath: "pkg/rpc/rpcrouter.go",
+			expectedVcsUrl:  "https://github.com/outrigdev/outrig/blob/0123456789abcdef0123456789abcdef01234567/pkg/rpc/rpcrouter.go#L42",
+		},
+		{
+			name:            "main package",
+			pkg:             "main",
+			filePath:        "/Users/mike/work/outrig/server/main-server.go",
+			modInfo:         modInfo,
+			expectedRelPath: "server/main-server.go",
+			expectedVcsUrl:  "https://github.com/outrigdev/outrig/blob/0123456789abcdef0123456789abcdef01234567/server/main-server.go#L42",
+		},
+		{
+			name:            "main module without vcs info",
+			pkg:             "github.com/outrigdev/outrig",
+			filePath:        "/Users/mike/work/outrig/outrig.go",
+			modInfo:         ModuleInfo{Name: "github.com/outrigdev/outrig"},
+			expectedRelPath: "outrig.go",
+			expectedVcsUrl:  "",
+		},
+		{
+			name:            "unknown module",
+			pkg:             "example.com/other",
+			filePath:        "/src/other/other.go",
+			modInfo:         modInfo,
+			expectedRelPath: "",
+			expectedVcsUrl:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := StackFrame{Package: tt.pkg, FuncName: "Fn", FilePath: tt.filePath, LineNumber: 42}
+			AnnotateFrame(&frame, tt.modInfo)
+			if frame.RelPath != tt.expectedRelPath {
+				t.Errorf("RelPath: got %q, expected %q", frame.RelPath, tt.expectedRelPath)
+			}
+			if frame.VcsUrl != tt.expectedVcsUrl {
+				t.Errorf("VcsUrl: got %q, expected %q", frame.VcsUrl, tt.expectedVcsUrl)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package stacktrace
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	DefaultSourcePreviewContext = 5  // lines shown before and after the frame's line
+	MaxSourcePreviewContext     = 50 // max lines before and after the frame's line
+	MaxSourcePreviewLineLen     = 1000
+)
+
+// ExtractSourceLines returns the lines of a source file around lineNumber (1-based, contextLines before and
+// after it, clamped to the file), and the line number of the first line returned
+func ExtractSourceLines(content string, lineNumber int, contextLines int) (int, []string, error) {
+	if lineNumber <= 0 {
+		return 0, nil, fmt.Errorf("invalid line number %d", lineNumber)
+	}
+	contextLines = max(0, min(contextLines, MaxSourcePreviewContext))
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if lineNumber > len(lines) {
+		return 0, nil, fmt.Errorf("line %d is past the end of the file (%d lines)", lineNumber, len(lines))
+	}
+	startLine := max(1, lineNumber-contextLines)
+	endLine := min(len(lines), lineNumber+contextLines)
+	rtn := make([]string, 0, endLine-startLine+1)
+	for _, line := range lines[startLine-1 : endLine] {
+		line = strings.TrimSuffix(line, "\r")
+		if len(line) > MaxSourcePreviewLineLen {
+			line = line[:MaxSourcePreviewLineLen] + "..."
+		}
+		rtn = append(rtn, line)
+	}
+	return startLine, rtn, nil
+}
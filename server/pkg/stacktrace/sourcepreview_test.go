@@ -0,0 +1,80 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package stacktrace
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractSourceLines(t *testing.T) {
+	content := "package main\r\n\r\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n"
+	tests := []struct {
+		name          string
+		lineNumber    int
+		contextLines  int
+		expectedStart int
+		expectedLines []string
+		expectErr     bool
+	}{
+		{
+			name:          "middle of the file",
+			lineNumber:    5,
+			contextLines:  1,
+			expectedStart: 4,
+			expectedLines: []string{"", "func main() {", "\tfmt.Println(\"hi\")"},
+		},
+		{
+			name:          "clamped to the start (crlf stripped)",
+			lineNumber:    1,
+			contextLines:  2,
+			expectedStart: 1,
+			expectedLines: []string{"package main", "", "import \"fmt\""},
+		},
+		{
+			name:          "clamped to the end",
+			lineNumber:    7,
+			contextLines:  1,
+			expectedStart: 6,
+			expectedLines: []string{"\tfmt.Println(\"hi\")", "}"},
+		},
+		{
+			name:          "no context",
+			lineNumber:    3,
+			contextLines:  0,
+			expectedStart: 3,
+			expectedLines: []string{"import \"fmt\""},
+		},
+		{
+			name:       "past the end",
+			lineNumber: 8,
+			expectErr:  true,
+		},
+		{
+			name:       "invalid line",
+			lineNumber: 0,
+			expectErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startLine, lines, err := ExtractSourceLines(content, tt.lineNumber, tt.contextLines)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got lines %q", lines)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if startLine != tt.expectedStart {
+				t.Errorf("start line: expected %d, got %d", tt.expectedStart, startLine)
+			}
+			if !reflect.DeepEqual(lines, tt.expectedLines) {
+				t.Errorf("lines: expected %q, got %q", tt.expectedLines, lines)
+			}
+		})
+	}
+}
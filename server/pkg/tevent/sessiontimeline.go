@@ -0,0 +1,156 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package tevent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/utilds"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// SessionTimelineBufferSize is the number of session events kept by the monitor (and in the timeline file)
+const SessionTimelineBufferSize = 10000
+
+// The session timeline is a local-only record of the UI session (tabs visited, app runs viewed, searches run)
+// so users can reconstruct what they looked at while debugging. It is opt-in (the sessiontimeline monitor
+// setting), independent of telemetry, and is never transmitted: events are kept in memory and appended to
+// sessiontimeline.jsonl in the data directory.
+var (
+	sessionLock     sync.Mutex
+	sessionBuf      = utilds.MakeCirBuf[rpctypes.SessionEvent](SessionTimelineBufferSize)
+	sessionLoaded   bool
+	sessionLast     *rpctypes.SessionEvent
+	sessionFileRows int
+)
+
+// loadSessionTimeline_nolock reads the timeline file into the buffer (once), compacting the file when it
+// has grown to more than twice the buffer size
+func loadSessionTimeline_nolock() {
+	if sessionLoaded {
+		return
+	}
+	sessionLoaded = true
+	data, err := os.ReadFile(utilfn.ExpandHomeDir(serverbase.GetSessionTimelineFilePath()))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("error reading session timeline: %v\n", err)
+		}
+		return
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var event rpctypes.SessionEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		sessionBuf.Write(event)
+		sessionFileRows++
+	}
+	if sessionFileRows > 2*SessionTimelineBufferSize {
+		compactSessionTimeline_nolock()
+	}
+}
+
+// compactSessionTimeline_nolock rewrites the timeline file with the buffered events
+func compactSessionTimeline_nolock() {
+	events, _ := sessionBuf.GetAll()
+	var buf bytes.Buffer
+	for _, event := range events {
+		barr, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		buf.Write(barr)
+		buf.WriteByte('\n')
+	}
+	if err := os.WriteFile(utilfn.ExpandHomeDir(serverbase.GetSessionTimelineFilePath()), buf.Bytes(), 0644); err != nil {
+		log.Printf("error compacting session timeline: %v\n", err)
+		return
+	}
+	sessionFileRows = len(events)
+}
+
+func appendSessionEvent_nolock(event rpctypes.SessionEvent) {
+	barr, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if err := serverbase.EnsureDataDir(); err != nil {
+		log.Printf("error writing session timeline: %v\n", err)
+		return
+	}
+	file, err := os.OpenFile(utilfn.ExpandHomeDir(serverbase.GetSessionTimelineFilePath()), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.Printf("error writing session timeline: %v\n", err)
+		return
+	}
+	defer file.Close()
+	file.Write(append(barr, '\n'))
+	sessionFileRows++
+}
+
+// RecordSessionEvent adds an event to the session timeline (a no-op unless the sessiontimeline setting is on).
+// An event that repeats the previous one (same event, app run, tab, and search term) is skipped.
+func RecordSessionEvent(event rpctypes.SessionEvent) {
+	if !serverbase.GetRuntimeSettings().SessionTimeline {
+		return
+	}
+	if event.Ts == 0 {
+		event.Ts = time.Now().UnixMilli()
+	}
+	sessionLock.Lock()
+	defer sessionLock.Unlock()
+	loadSessionTimeline_nolock()
+	if sessionLast != nil {
+		last := *sessionLast
+		last.Ts = event.Ts
+		if last == event {
+			return
+		}
+	}
+	sessionLast = &event
+	sessionBuf.Write(event)
+	appendSessionEvent_nolock(event)
+	if sessionFileRows > 2*SessionTimelineBufferSize {
+		compactSessionTimeline_nolock()
+	}
+}
+
+// QuerySessionTimeline returns the session events matching the request (oldest first)
+func QuerySessionTimeline(req rpctypes.SessionTimelineRequest) rpctypes.SessionTimelineData {
+	sessionLock.Lock()
+	loadSessionTimeline_nolock()
+	sessionLock.Unlock()
+	events := sessionBuf.FilterItems(func(event rpctypes.SessionEvent, _ int) bool {
+		if req.StartTs > 0 && event.Ts < req.StartTs {
+			return false
+		}
+		if req.EndTs > 0 && event.Ts > req.EndTs {
+			return false
+		}
+		if req.AppRunId != "" && event.AppRunId != req.AppRunId {
+			return false
+		}
+		return true
+	})
+	if req.Limit > 0 && len(events) > req.Limit {
+		events = events[len(events)-req.Limit:]
+	}
+	if events == nil {
+		events = []rpctypes.SessionEvent{}
+	}
+	return rpctypes.SessionTimelineData{
+		Enabled: serverbase.GetRuntimeSettings().SessionTimeline,
+		Events:  events,
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package tevent
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/outrigdev/outrig/pkg/utilds"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// resetSessionTimeline points the data dir at a temp dir and clears the in-memory timeline
+func resetSessionTimeline(t *testing.T, enabled bool) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(serverbase.OutrigDevEnvName, "")
+	prevSettings := serverbase.GetRuntimeSettings()
+	serverbase.SetRuntimeSettings(serverbase.RuntimeSettings{SessionTimeline: enabled})
+	t.Cleanup(func() { serverbase.SetRuntimeSettings(prevSettings) })
+	sessionLock.Lock()
+	defer sessionLock.Unlock()
+	sessionBuf = utilds.MakeCirBuf[rpctypes.SessionEvent](SessionTimelineBufferSize)
+	sessionLoaded = false
+	sessionLast = nil
+	sessionFileRows = 0
+}
+
+func getSessionEventNames(data rpctypes.SessionTimelineData) []string {
+	var names []string
+	for _, event := range data.Events {
+		names = append(names, event.Event+"/"+event.AppRunId)
+	}
+	return names
+}
+
+func TestRecordSessionEventDisabled(t *testing.T) {
+	resetSessionTimeline(t, false)
+	RecordSessionEvent(rpctypes.SessionEvent{Event: "frontend:tab", Tab: "logs"})
+	data := QuerySessionTimeline(rpctypes.SessionTimelineRequest{})
+	if data.Enabled || len(data.Events) != 0 {
+		t.Errorf("got enabled=%v with %d events, want nothing recorded", data.Enabled, len(data.Events))
+	}
+	if _, err := os.Stat(utilfn.ExpandHomeDir(serverbase.GetSessionTimelineFilePath())); !os.IsNotExist(err) {
+		t.Errorf("timeline file written while disabled: %v", err)
+	}
+}
+
+func TestRecordSessionEvent(t *testing.T) {
+	resetSessionTimeline(t, true)
+	events := []rpctypes.SessionEvent{
+		{Ts: 100, Event: "frontend:selectapprun", AppRunId: "run1"},
+		{Ts: 200, Event: "frontend:tab", AppRunId: "run1", Tab: "logs"},
+		{Ts: 300, Event: "frontend:tab", AppRunId: "run1", Tab: "logs"}, // repeat, skipped
+		{Ts: 400, Event: "frontend:search:logs", AppRunId: "run1", SearchTerm: "error"},
+		{Ts: 500, Event: "frontend:selectapprun", AppRunId: "run2"},
+	}
+	for _, event := range events {
+		RecordSessionEvent(event)
+	}
+
+	tests := []struct {
+		name   string
+		req    rpctypes.SessionTimelineRequest
+		expect string
+	}{
+		{"all", rpctypes.SessionTimelineRequest{}, "frontend:selectapprun/run1 frontend:tab/run1 frontend:search:logs/run1 frontend:selectapprun/run2"},
+		{"app run", rpctypes.SessionTimelineRequest{AppRunId: "run2"}, "frontend:selectapprun/run2"},
+		{"time range", rpctypes.SessionTimelineRequest{StartTs: 200, EndTs: 400}, "frontend:tab/run1 frontend:search:logs/run1"},
+		{"limit keeps the newest", rpctypes.SessionTimelineRequest{Limit: 2}, "frontend:search:logs/run1 frontend:selectapprun/run2"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data := QuerySessionTimeline(tc.req)
+			if got := strings.Join(getSessionEventNames(data), " "); !data.Enabled || got != tc.expect {
+				t.Errorf("got %q (enabled=%v), want %q", got, data.Enabled, tc.expect)
+			}
+		})
+	}
+
+	// the events are reloaded from the timeline file
+	sessionLock.Lock()
+	sessionBuf = utilds.MakeCirBuf[rpctypes.SessionEvent](SessionTimelineBufferSize)
+	sessionLoaded = false
+	sessionLock.Unlock()
+	if got := QuerySessionTimeline(rpctypes.SessionTimelineRequest{}); len(got.Events) != 4 {
+		t.Errorf("got %d events after reloading the file, want 4", len(got.Events))
+	}
+}
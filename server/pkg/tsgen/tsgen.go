@@ -106,14 +106,21 @@ func TypeToTSType(t reflect.Type, tsTypesMap map[reflect.Type]string) (string, [
 		}
 		return fmt.Sprintf("%s[]", elemType), subTypes
 	case reflect.Map:
-		if t.Key().Kind() != reflect.String {
+		// JSON encodes integer keys as strings, JS objects index them the same way
+		keyType := "string"
+		switch t.Key().Kind() {
+		case reflect.String:
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			keyType = "number"
+		default:
 			return "", nil
 		}
 		elemType, subTypes := TypeToTSType(t.Elem(), tsTypesMap)
 		if elemType == "" {
 			return "", nil
 		}
-		return fmt.Sprintf("{[key: string]: %s}", elemType), subTypes
+		return fmt.Sprintf("{[key: %s]: %s}", keyType, elemType), subTypes
 	case reflect.Struct:
 		name := t.Name()
 		if tsRename := tsRenameMap[name]; tsRename != "" {
@@ -363,9 +370,9 @@ func generateRpcClientApiMethod_ResponseStream(methodDecl *rpc.RpcMethodDecl, ts
 	genRespType := fmt.Sprintf("AsyncGenerator<%s, void, boolean>", respType)
 	if methodDecl.CommandDataType != nil {
 		cmdDataTsName, _ := TypeToTSType(methodDecl.CommandDataType, tsTypesMap)
-		sb.WriteString(fmt.Sprintf("	%s(client: RpcClient, data: %s, opts?: RpcOpts): %s {\n", methodDecl.MethodName, cmdDataTsName, genRespType))
+		sb.WriteString(fmt.Sprintf("    %s(client: RpcClient, data: %s, opts?: RpcOpts): %s {\n", methodDecl.MethodName, cmdDataTsName, genRespType))
 	} else {
-		sb.WriteString(fmt.Sprintf("	%s(client: RpcClient, opts?: RpcOpts): %s {\n", methodDecl.MethodName, genRespType))
+		sb.WriteString(fmt.Sprintf("    %s(client: RpcClient, opts?: RpcOpts): %s {\n", methodDecl.MethodName, genRespType))
 	}
 	sb.WriteString(fmt.Sprintf("        return client.rpcStream(%q, %s, opts);\n", methodDecl.Command, dataName))
 	sb.WriteString("    }\n")
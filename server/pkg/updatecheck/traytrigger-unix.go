@@ -0,0 +1,35 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package updatecheck
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"syscall"
+)
+
+// TriggerTrayAppUpdateCheck sends a SIGUSR1 signal to the tray app to trigger update check
+func TriggerTrayAppUpdateCheck() error {
+	if trayAppPid <= 0 {
+		return fmt.Errorf("no tray app PID available")
+	}
+
+	// Check if the process exists
+	process, err := os.FindProcess(trayAppPid)
+	if err != nil {
+		return fmt.Errorf("failed to find process with PID %d: %w", trayAppPid, err)
+	}
+
+	// Send SIGUSR1 signal to trigger update check
+	err = process.Signal(syscall.SIGUSR1)
+	if err != nil {
+		return fmt.Errorf("failed to send SIGUSR1 signal to PID %d: %w", trayAppPid, err)
+	}
+
+	log.Printf("Sent SIGUSR1 signal to tray app (PID %d) to trigger update check", trayAppPid)
+	return nil
+}
@@ -0,0 +1,22 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build windows
+
+package updatecheck
+
+import (
+	"fmt"
+	"log"
+)
+
+// TriggerTrayAppUpdateCheck bumps the tray update check sequence number (there is no SIGUSR1 on Windows),
+// the tray app polls it through /api/status and runs an update check when it changes
+func TriggerTrayAppUpdateCheck() error {
+	if trayAppPid <= 0 {
+		return fmt.Errorf("no tray app PID available")
+	}
+	seq := trayUpdateCheckSeq.Add(1)
+	log.Printf("Requested update check from tray app (PID %d), seq %d", trayAppPid, seq)
+	return nil
+}
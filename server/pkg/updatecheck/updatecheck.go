@@ -10,11 +10,9 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
-	"syscall"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
@@ -26,6 +24,9 @@ const (
 	// GitHubReleasesURL is the URL to check for the latest release
 	GitHubReleasesURL = "https://api.github.com/repos/outrigdev/outrig/releases/latest"
 
+	// GitHubReleasesPageURL is the download page for the latest release (used by the Windows tray app, which has no auto-updater)
+	GitHubReleasesPageURL = "https://github.com/outrigdev/outrig/releases/latest"
+
 	// InitialDelay is the delay before the first update check
 	InitialDelay = 10 * time.Second
 
@@ -52,6 +53,9 @@ var (
 	// trayAppPid stores the PID of the tray app that started the server (0 if not from tray)
 	trayAppPid int
 
+	// trayUpdateCheckSeq is incremented to ask a tray app that can't receive signals (Windows) to check for updates
+	trayUpdateCheckSeq atomic.Int64
+
 	// Global Watch variable for update check results
 	latestReleaseWatch = outrig.NewWatch("updatecheck.latestreleasecheck").ForPush()
 )
@@ -247,6 +251,11 @@ func GetFromTrayApp() bool {
 	return trayAppPid > 0
 }
 
+// GetTrayUpdateCheckSeq returns the sequence number of update check requests for the tray app (polled via /api/status)
+func GetTrayUpdateCheckSeq() int64 {
+	return trayUpdateCheckSeq.Load()
+}
+
 // GetLatestAppcastRelease downloads and parses the appcast.xml file to get the latest version
 func GetLatestAppcastRelease() (string, error) {
 	client := &http.Client{
@@ -302,25 +311,3 @@ func GetLatestAppcastRelease() (string, error) {
 
 	return latestVersion, nil
 }
-
-// TriggerTrayAppUpdateCheck sends a SIGUSR1 signal to the tray app to trigger update check
-func TriggerTrayAppUpdateCheck() error {
-	if trayAppPid <= 0 {
-		return fmt.Errorf("no tray app PID available")
-	}
-
-	// Check if the process exists
-	process, err := os.FindProcess(trayAppPid)
-	if err != nil {
-		return fmt.Errorf("failed to find process with PID %d: %w", trayAppPid, err)
-	}
-
-	// Send SIGUSR1 signal to trigger update check
-	err = process.Signal(syscall.SIGUSR1)
-	if err != nil {
-		return fmt.Errorf("failed to send SIGUSR1 signal to PID %d: %w", trayAppPid, err)
-	}
-
-	log.Printf("Sent SIGUSR1 signal to tray app (PID %d) to trigger update check", trayAppPid)
-	return nil
-}
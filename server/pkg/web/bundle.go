@@ -0,0 +1,71 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/outrigdev/outrig/server/pkg/apppeer"
+	"github.com/outrigdev/outrig/server/pkg/auditlog"
+)
+
+// ImportBundlePath is the endpoint "outrig import" posts app run bundles to
+const ImportBundlePath = "/api/import"
+
+// ImportMaxBodyBytes limits the size of an imported (compressed) bundle
+const ImportMaxBodyBytes = 512 * 1024 * 1024
+
+// handleExportDownload serves a bundle created by ExportAppRunCommand
+func handleExportDownload(w http.ResponseWriter, r *http.Request) {
+	appRunId := r.URL.Query().Get("apprunid")
+	exportId := r.URL.Query().Get("exportid")
+	peer := apppeer.FindAppRunPeer(appRunId)
+	if peer == nil {
+		http.Error(w, fmt.Sprintf("app run not found: %s", appRunId), http.StatusNotFound)
+		return
+	}
+	export := peer.GetExport(exportId)
+	if export == nil {
+		http.Error(w, fmt.Sprintf("export not found: %s", exportId), http.StatusNotFound)
+		return
+	}
+	w.Header().Set(ContentTypeHeaderKey, "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName))
+	w.Header().Set("Content-Length", strconv.Itoa(len(export.Data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(export.Data)
+}
+
+// handleImportBundle loads a bundle (the request body) into a new read-only app run
+func handleImportBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !validateOriginAndReferrer(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	peer, err := apppeer.ImportBundle(http.MaxBytesReader(w, r.Body, ImportMaxBodyBytes))
+	appRunId := ""
+	if peer != nil {
+		appRunId = peer.AppRunId
+	}
+	auditlog.Record("web:"+r.RemoteAddr, ImportBundlePath, appRunId, nil, err)
+	if err != nil {
+		WriteJsonError(w, err)
+		return
+	}
+	appRunInfo := peer.GetAppRunInfo()
+	WriteJsonSuccess(w, map[string]interface{}{
+		"apprunid":           appRunInfo.AppRunId,
+		"appname":            appRunInfo.AppName,
+		"exportedapprunid":   peer.GetImportedFrom().AppRunId,
+		"numlogs":            appRunInfo.NumLogs,
+		"numtotalgoroutines": appRunInfo.NumTotalGoRoutines,
+		"numtotalwatches":    appRunInfo.NumTotalWatches,
+	})
+}
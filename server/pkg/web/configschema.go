@@ -0,0 +1,20 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"net/http"
+
+	"github.com/outrigdev/outrig/pkg/config"
+)
+
+// ConfigSchemaPath is the well-known URL of the outrig.json schema
+const ConfigSchemaPath = config.SchemaPath
+
+// handleConfigSchema serves the JSON schema for outrig.json (for editor completion and validation)
+func handleConfigSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(ContentTypeHeaderKey, "application/schema+json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(config.SchemaJSON)
+}
@@ -0,0 +1,40 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/outrigdev/outrig/server/pkg/apppeer"
+)
+
+// handleCPUProfileDownload serves a CPU profile captured by CaptureCPUProfileCommand (pprof format,
+// so the url can be passed directly to "go tool pprof")
+func handleCPUProfileDownload(w http.ResponseWriter, r *http.Request) {
+	appRunId := r.URL.Query().Get("apprunid")
+	profileId := r.URL.Query().Get("profileid")
+	peer := apppeer.FindAppRunPeer(appRunId)
+	if peer == nil {
+		http.Error(w, fmt.Sprintf("app run not found: %s", appRunId), http.StatusNotFound)
+		return
+	}
+	profile := peer.GetCPUProfile(profileId)
+	if profile == nil {
+		http.Error(w, fmt.Sprintf("cpu profile not found: %s", profileId), http.StatusNotFound)
+		return
+	}
+	appName := "app"
+	if peer.AppInfo != nil && peer.AppInfo.AppName != "" {
+		appName = peer.AppInfo.AppName
+	}
+	fileName := fmt.Sprintf("%s-cpu-%s.pprof", appName, time.UnixMilli(profile.Ts).Format("20060102-150405"))
+	w.Header().Set(ContentTypeHeaderKey, "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.Header().Set("Content-Length", strconv.Itoa(len(profile.Data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(profile.Data)
+}
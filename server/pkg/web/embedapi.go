@@ -0,0 +1,261 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig/server/pkg/apppeer"
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/rpctypes"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// The embed API (/api/embed/...) is a read-only subset of the monitor's API for embedding Outrig
+// widgets in other dashboards. It is authenticated with API keys ("outrig apikey create") instead
+// of the same-origin checks, and browsers on the --embed-origin origins may call it (CORS).
+// The key is only accepted in the Authorization header (query params end up in proxy logs and browser history).
+const (
+	EmbedDefaultLogLines = 100
+	EmbedMaxLogLines     = 1000
+	EmbedRpcTimeout      = 5 * time.Second
+	EmbedRpcMaxBodyBytes = 1024 * 1024
+	embedRpcRouteId      = "embed"
+)
+
+// EmbedRpcCommands are the (read-only) RPC commands that can be called with /api/embed/rpc,
+// mapped to the API key scope they need ("" for any valid key)
+var EmbedRpcCommands = map[string]string{
+	"getappruns":               "",
+	"getapprunruntimestats":    serverbase.ApiKeyScopeRuntimeStats,
+	"getappruntimeline":        serverbase.ApiKeyScopeRuntimeStats,
+	"getapprunpanics":          serverbase.ApiKeyScopeGoRoutines,
+	"getapprungoroutinesbyids": serverbase.ApiKeyScopeGoRoutines,
+	"goroutinetimespans":       serverbase.ApiKeyScopeGoRoutines,
+	"getgoroutinelogs":         serverbase.ApiKeyScopeLogs,
+}
+
+type embedApiKeyCtxKey struct{}
+
+// EmbedAppRun is an app run in the /api/embed/appruns response
+type EmbedAppRun struct {
+	AppRunId            string `json:"apprunid"`
+	AppName             string `json:"appname"`
+	IsRunning           bool   `json:"isrunning"`
+	Status              string `json:"status"`
+	StartTime           int64  `json:"starttime"`
+	NumLogs             int    `json:"numlogs"`
+	NumActiveGoRoutines int    `json:"numactivegoroutines"`
+}
+
+// EmbedGoRoutineCounts is the /api/embed/goroutines response
+type EmbedGoRoutineCounts struct {
+	AppRunId     string `json:"apprunid"`
+	AppName      string `json:"appname"`
+	IsRunning    bool   `json:"isrunning"`
+	Time         int64  `json:"time"`
+	Total        int    `json:"total"`
+	Active       int    `json:"active"`
+	ActiveOutrig int    `json:"activeoutrig"`
+}
+
+// EmbedRpcRequest is the body of /api/embed/rpc
+type EmbedRpcRequest struct {
+	Command string          `json:"command"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// getApiKeyToken returns the API key from the "Authorization: Bearer" header
+func getApiKeyToken(r *http.Request) string {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return strings.TrimSpace(token)
+}
+
+// setEmbedCorsHeaders allows the request's origin when it is one of the configured embed origins
+func setEmbedCorsHeaders(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+	w.Header().Add("Vary", "Origin")
+	allowedOrigins := serverbase.GetRuntimeSettings().EmbedAllowedOrigins
+	if !slices.Contains(allowedOrigins, "*") && !slices.Contains(allowedOrigins, origin) {
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+	w.Header().Set("Access-Control-Max-Age", "600")
+}
+
+// embedApiWrap handles CORS and API key authentication for an embed API handler
+// (scope is the API key scope the handler requires, "" for any valid key)
+func embedApiWrap(scope string, fn WebFnType) WebFnType {
+	return func(w http.ResponseWriter, r *http.Request) {
+		setEmbedCorsHeaders(w, r)
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		apiKey, ok := serverbase.LookupApiKey(getApiKeyToken(r))
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !checkApiKeyScope(w, apiKey, scope) {
+			return
+		}
+		fn(w, r.WithContext(context.WithValue(r.Context(), embedApiKeyCtxKey{}, apiKey)))
+	}
+}
+
+// checkApiKeyScope writes a 403 error and returns false if the key doesn't grant the scope ("" for any key)
+func checkApiKeyScope(w http.ResponseWriter, apiKey serverbase.ApiKey, scope string) bool {
+	if scope == "" || apiKey.HasScope(scope) {
+		return true
+	}
+	http.Error(w, fmt.Sprintf("Forbidden (api key %q does not have the %q scope)", apiKey.Name, scope), http.StatusForbidden)
+	return false
+}
+
+// getEmbedAppRunPeer returns the app run of the apprunid query param, or writes an error and returns nil
+// (unknown ids are a 404, the peer is never created for them)
+func getEmbedAppRunPeer(w http.ResponseWriter, r *http.Request) *apppeer.AppRunPeer {
+	appRunId := r.URL.Query().Get("apprunid")
+	if appRunId == "" {
+		WriteJsonError(w, fmt.Errorf("apprunid is required"))
+		return nil
+	}
+	peer := apppeer.FindAppRunPeer(appRunId)
+	if peer == nil || peer.AppInfo == nil {
+		http.Error(w, fmt.Sprintf("app run not found: %s", appRunId), http.StatusNotFound)
+		return nil
+	}
+	return peer
+}
+
+// handleEmbedAppRuns lists the app runs (newest first)
+func handleEmbedAppRuns(w http.ResponseWriter, r *http.Request) {
+	appRunInfos := apppeer.GetAllAppRunPeerInfos(0)
+	slices.SortFunc(appRunInfos, func(a, b rpctypes.AppRunInfo) int {
+		return cmp.Compare(b.StartTime, a.StartTime)
+	})
+	appRuns := make([]EmbedAppRun, 0, len(appRunInfos))
+	for _, info := range appRunInfos {
+		appRuns = append(appRuns, EmbedAppRun{
+			AppRunId:            info.AppRunId,
+			AppName:             info.AppName,
+			IsRunning:           info.IsRunning,
+			Status:              info.Status,
+			StartTime:           info.StartTime,
+			NumLogs:             info.NumLogs,
+			NumActiveGoRoutines: info.NumActiveGoRoutines,
+		})
+	}
+	WriteJsonSuccess(w, map[string]interface{}{
+		"appruns": appRuns,
+	})
+}
+
+// handleEmbedGoRoutines returns the goroutine counts of an app run
+func handleEmbedGoRoutines(w http.ResponseWriter, r *http.Request) {
+	peer := getEmbedAppRunPeer(w, r)
+	if peer == nil {
+		return
+	}
+	total, active, activeOutrig := peer.GoRoutines.GetGoRoutineCounts()
+	appRunInfo := peer.GetAppRunInfo()
+	WriteJsonSuccess(w, EmbedGoRoutineCounts{
+		AppRunId:     appRunInfo.AppRunId,
+		AppName:      appRunInfo.AppName,
+		IsRunning:    appRunInfo.IsRunning,
+		Time:         time.Now().UnixMilli(),
+		Total:        total,
+		Active:       active,
+		ActiveOutrig: activeOutrig,
+	})
+}
+
+// handleEmbedLogs returns the newest log lines of an app run (limit and since query params)
+func handleEmbedLogs(w http.ResponseWriter, r *http.Request) {
+	peer := getEmbedAppRunPeer(w, r)
+	if peer == nil {
+		return
+	}
+	var err error
+	limit := EmbedDefaultLogLines
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			WriteJsonError(w, fmt.Errorf("invalid limit %q", limitStr))
+			return
+		}
+		limit = min(limit, EmbedMaxLogLines)
+	}
+	var sinceTs int64
+	if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+		sinceTs, err = strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil {
+			WriteJsonError(w, fmt.Errorf("invalid since %q (expected unix ms)", sinceStr))
+			return
+		}
+	}
+	lines := peer.Logs.GetLastLogLines(limit, sinceTs)
+	WriteJsonSuccess(w, map[string]interface{}{
+		"apprunid": peer.AppRunId,
+		"numlogs":  peer.Logs.GetTotalCount(),
+		"lines":    lines,
+	})
+}
+
+// handleEmbedRpc runs one of the read-only EmbedRpcCommands and returns its result
+func handleEmbedRpc(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req EmbedRpcRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, EmbedRpcMaxBodyBytes)).Decode(&req); err != nil {
+		WriteJsonError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	command := strings.ToLower(strings.TrimSuffix(req.Command, "Command"))
+	scope, ok := EmbedRpcCommands[command]
+	if !ok {
+		WriteJsonError(w, fmt.Errorf("command %q is not available in the embed api", req.Command))
+		return
+	}
+	apiKey, _ := r.Context().Value(embedApiKeyCtxKey{}).(serverbase.ApiKey)
+	if !checkApiKeyScope(w, apiKey, scope) {
+		return
+	}
+	var data any
+	if len(req.Data) > 0 {
+		data = req.Data
+	}
+	ctx, cancelFn := context.WithTimeout(r.Context(), EmbedRpcTimeout)
+	defer cancelFn()
+	msg := rpc.RpcMessage{
+		Command: command,
+		ReqId:   uuid.New().String(),
+		Route:   rpc.DefaultRoute,
+		Timeout: EmbedRpcTimeout.Milliseconds(),
+		Data:    data,
+	}
+	resp, err := rpc.GetDefaultRouter().RunSimpleRawCommand(ctx, msg, embedRpcRouteId)
+	if err != nil {
+		WriteJsonError(w, err)
+		return
+	}
+	WriteJsonSuccess(w, resp.Data)
+}
@@ -0,0 +1,148 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/outrigdev/outrig/server/pkg/apppeer"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// makeTestApiKey creates an api key in a temp outrig home
+func makeTestApiKey(t *testing.T, name string, scopes ...string) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(serverbase.OutrigDevEnvName, "")
+	apiKey, err := serverbase.CreateApiKey(name, scopes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return apiKey.Key
+}
+
+func TestEmbedApiScopes(t *testing.T) {
+	key := makeTestApiKey(t, "logsonly", serverbase.ApiKeyScopeLogs)
+
+	tests := []struct {
+		name       string
+		scope      string
+		authHeader string
+		url        string
+		expectCode int
+	}{
+		{"no key", serverbase.ApiKeyScopeLogs, "", "/api/embed/logs", http.StatusUnauthorized},
+		{"invalid key", serverbase.ApiKeyScopeLogs, "Bearer ork_invalid", "/api/embed/logs", http.StatusUnauthorized},
+		{"key in the query", serverbase.ApiKeyScopeLogs, "", "/api/embed/logs?apikey=" + key, http.StatusUnauthorized},
+		{"missing scope", serverbase.ApiKeyScopeGoRoutines, "Bearer " + key, "/api/embed/goroutines", http.StatusForbidden},
+		{"granted scope", serverbase.ApiKeyScopeLogs, "Bearer " + key, "/api/embed/logs", http.StatusOK},
+		{"any key", "", "Bearer " + key, "/api/embed/appruns", http.StatusOK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := embedApiWrap(tc.scope, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if tc.authHeader != "" {
+				req.Header.Set("Authorization", tc.authHeader)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != tc.expectCode {
+				t.Errorf("got status %d, want %d", rec.Code, tc.expectCode)
+			}
+		})
+	}
+}
+
+func TestEmbedUnknownAppRun(t *testing.T) {
+	key := makeTestApiKey(t, "logs", serverbase.ApiKeyScopeLogs)
+	handler := embedApiWrap(serverbase.ApiKeyScopeLogs, handleEmbedLogs)
+	req := httptest.NewRequest(http.MethodGet, "/api/embed/logs?apprunid=not-an-app-run", nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if apppeer.FindAppRunPeer("not-an-app-run") != nil {
+		t.Errorf("looking up an unknown app run should not create it")
+	}
+}
+
+func TestEmbedRpcCommandScopes(t *testing.T) {
+	key := makeTestApiKey(t, "logsonly", serverbase.ApiKeyScopeLogs)
+	handler := embedApiWrap("", handleEmbedRpc)
+
+	tests := []struct {
+		name       string
+		command    string
+		expectCode int
+		expectErr  string
+	}{
+		{"command needs another scope", "getapprunruntimestats", http.StatusForbidden, "runtimestats"},
+		{"goroutine command", "GetAppRunGoRoutinesByIdsCommand", http.StatusForbidden, "goroutines"},
+		{"command not in the embed api", "clearapprun", http.StatusOK, "not available in the embed api"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body := strings.NewReader(`{"command":"` + tc.command + `"}`)
+			req := httptest.NewRequest(http.MethodPost, "/api/embed/rpc", body)
+			req.Header.Set("Authorization", "Bearer "+key)
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != tc.expectCode || !strings.Contains(rec.Body.String(), tc.expectErr) {
+				t.Errorf("got status %d %q, want %d containing %q", rec.Code, rec.Body.String(), tc.expectCode, tc.expectErr)
+			}
+		})
+	}
+	for command, scope := range EmbedRpcCommands {
+		if scope != "" && !slices.Contains(serverbase.AllApiKeyScopes, scope) {
+			t.Errorf("embed rpc command %q needs unknown scope %q", command, scope)
+		}
+	}
+}
+
+func TestSameOriginChecks(t *testing.T) {
+	t.Setenv(serverbase.OutrigDevEnvName, "")
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	tests := []struct {
+		name        string
+		path        string
+		origin      string
+		expectAllow bool
+	}{
+		{"no origin (curl, tray app)", "/api/status", "", true},
+		{"same origin", "/api/status", "http://localhost:5005", true},
+		{"other site", "/api/status", "https://evil.example", false},
+		{"other site on the embed api", "/api/embed/logs", "https://dashboard.example", true},
+		{"other site on the websocket", "/ws", "https://dashboard.example", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:5005"+tc.path, nil)
+			if tc.origin != "" {
+				req.Header.Set("Origin", tc.origin)
+			}
+			var allowed bool
+			if tc.path == "/ws" {
+				allowed = checkWsOrigin(req)
+			} else {
+				rec := httptest.NewRecorder()
+				sameOriginMiddleware(next).ServeHTTP(rec, req)
+				allowed = rec.Code == http.StatusOK
+			}
+			if allowed != tc.expectAllow {
+				t.Errorf("got allowed=%v, want %v", allowed, tc.expectAllow)
+			}
+		})
+	}
+}
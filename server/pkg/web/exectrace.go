@@ -0,0 +1,40 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/outrigdev/outrig/server/pkg/apppeer"
+)
+
+// handleExecTraceDownload serves an execution trace captured by CaptureExecTraceCommand (runtime/trace format,
+// so the downloaded file can be opened with "go tool trace")
+func handleExecTraceDownload(w http.ResponseWriter, r *http.Request) {
+	appRunId := r.URL.Query().Get("apprunid")
+	traceId := r.URL.Query().Get("traceid")
+	peer := apppeer.FindAppRunPeer(appRunId)
+	if peer == nil {
+		http.Error(w, fmt.Sprintf("app run not found: %s", appRunId), http.StatusNotFound)
+		return
+	}
+	execTrace := peer.GetExecTrace(traceId)
+	if execTrace == nil {
+		http.Error(w, fmt.Sprintf("execution trace not found: %s", traceId), http.StatusNotFound)
+		return
+	}
+	appName := "app"
+	if peer.AppInfo != nil && peer.AppInfo.AppName != "" {
+		appName = peer.AppInfo.AppName
+	}
+	fileName := fmt.Sprintf("%s-trace-%s.out", appName, time.UnixMilli(execTrace.Ts).Format("20060102-150405"))
+	w.Header().Set(ContentTypeHeaderKey, "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.Header().Set("Content-Length", strconv.Itoa(len(execTrace.Data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(execTrace.Data)
+}
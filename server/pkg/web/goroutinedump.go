@@ -0,0 +1,41 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/outrigdev/outrig/server/pkg/apppeer"
+)
+
+// handleGoroutineDumpDownload serves a goroutine dump taken by ForceGoroutineDumpCommand (as text, in the
+// runtime.Stack format)
+func handleGoroutineDumpDownload(w http.ResponseWriter, r *http.Request) {
+	appRunId := r.URL.Query().Get("apprunid")
+	dumpId := r.URL.Query().Get("dumpid")
+	peer := apppeer.FindAppRunPeer(appRunId)
+	if peer == nil {
+		http.Error(w, fmt.Sprintf("app run not found: %s", appRunId), http.StatusNotFound)
+		return
+	}
+	dump := peer.GetGoroutineDump(dumpId)
+	if dump == nil {
+		http.Error(w, fmt.Sprintf("goroutine dump not found: %s", dumpId), http.StatusNotFound)
+		return
+	}
+	appName := "app"
+	if peer.AppInfo != nil && peer.AppInfo.AppName != "" {
+		appName = peer.AppInfo.AppName
+	}
+	data := dump.Text()
+	fileName := fmt.Sprintf("%s-goroutines-%s.txt", appName, time.UnixMilli(dump.Ts).Format("20060102-150405"))
+	w.Header().Set(ContentTypeHeaderKey, "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
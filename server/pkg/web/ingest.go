@@ -0,0 +1,142 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/outrigdev/outrig/pkg/ds"
+	"github.com/outrigdev/outrig/server/pkg/apppeer"
+	"github.com/outrigdev/outrig/server/pkg/packetrecord"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+const (
+	IngestTokenHeader      = "X-Outrig-Token"
+	IngestDefaultSource    = "ingest"
+	IngestMaxBodyBytes     = 32 * 1024 * 1024
+	IngestMaxLineBytes     = 1024 * 1024
+	ingestMaxReportedError = 10
+)
+
+// IngestLogLine is a line of the NDJSON body of /api/ingest/logs.
+// Only msg is required, ts defaults to the time the line was received (unix ms).
+type IngestLogLine struct {
+	Ts     int64  `json:"ts,omitempty"`
+	Msg    string `json:"msg"`
+	Source string `json:"source,omitempty"`
+}
+
+// getIngestRequestToken returns the token from the X-Outrig-Token or "Authorization: Bearer" header
+func getIngestRequestToken(r *http.Request) string {
+	if token := r.Header.Get(IngestTokenHeader); token != "" {
+		return token
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+func validIngestToken(r *http.Request) bool {
+	token := getIngestRequestToken(r)
+	if token == "" || serverbase.IngestToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(serverbase.IngestToken)) == 1
+}
+
+// handleIngestLogs adds log lines from non-Go processes (sidecars, scripts) to an app run.
+// The body is NDJSON (one IngestLogLine per line), the source defaults to the "source" query param.
+func handleIngestLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !validIngestToken(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	appRunId := r.URL.Query().Get("apprunid")
+	if _, err := uuid.Parse(appRunId); err != nil {
+		WriteJsonError(w, fmt.Errorf("apprunid must be a valid app run id"))
+		return
+	}
+	defaultSource := r.URL.Query().Get("source")
+	if defaultSource == "" {
+		defaultSource = IngestDefaultSource
+	}
+
+	var multiLog ds.MultiLogLines
+	var lineErrors []string
+	numErrors := 0
+	scanner := bufio.NewScanner(http.MaxBytesReader(w, r.Body, IngestMaxBodyBytes))
+	scanner.Buffer(make([]byte, 64*1024), IngestMaxLineBytes)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var ingestLine IngestLogLine
+		if err := json.Unmarshal([]byte(line), &ingestLine); err != nil {
+			numErrors++
+			if len(lineErrors) < ingestMaxReportedError {
+				lineErrors = append(lineErrors, fmt.Sprintf("line %d: %v", lineNum, err))
+			}
+			continue
+		}
+		if ingestLine.Ts == 0 {
+			ingestLine.Ts = time.Now().UnixMilli()
+		}
+		if ingestLine.Source == "" {
+			ingestLine.Source = defaultSource
+		}
+		multiLog.LogLines = append(multiLog.LogLines, ds.LogLine{
+			Ts:     ingestLine.Ts,
+			Msg:    ingestLine.Msg,
+			Source: ingestLine.Source,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		WriteJsonError(w, fmt.Errorf("error reading request body: %w", err))
+		return
+	}
+
+	if len(multiLog.LogLines) > 0 {
+		peer := apppeer.GetAppRunPeer(appRunId, true)
+		defer peer.Release()
+		if peer.IsImported() {
+			WriteJsonError(w, fmt.Errorf("app run %s is imported (read-only)", appRunId))
+			return
+		}
+		recorder := packetrecord.Open(appRunId)
+		defer recorder.Close()
+
+		logData, err := json.Marshal(&multiLog)
+		if err != nil {
+			WriteJsonError(w, fmt.Errorf("error marshaling log lines: %w", err))
+			return
+		}
+		recorder.Record(ds.PacketTypeMultiLog, logData)
+		if err := peer.HandlePacket(ds.PacketTypeMultiLog, logData); err != nil {
+			WriteJsonError(w, err)
+			return
+		}
+	}
+	WriteJsonSuccess(w, map[string]interface{}{
+		"apprunid": appRunId,
+		"accepted": len(multiLog.LogLines),
+		"rejected": numErrors,
+		"errors":   lineErrors,
+	})
+}
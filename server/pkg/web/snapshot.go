@@ -0,0 +1,24 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/outrigdev/outrig/server/pkg/snapshots"
+)
+
+// handleSnapshotDownload serves a snapshot taken by a scheduled snapshot rule (a text file)
+func handleSnapshotDownload(w http.ResponseWriter, r *http.Request) {
+	snapshotId := r.URL.Query().Get("snapshotid")
+	info, ok := snapshots.GetSnapshot(snapshotId)
+	if !ok {
+		http.Error(w, fmt.Sprintf("snapshot not found: %s", snapshotId), http.StatusNotFound)
+		return
+	}
+	w.Header().Set(ContentTypeHeaderKey, "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", info.FileName))
+	http.ServeFile(w, r, snapshots.GetSnapshotFilePath(info.SnapshotId))
+}
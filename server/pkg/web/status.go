@@ -0,0 +1,215 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/apppeer"
+	"github.com/outrigdev/outrig/server/pkg/federation"
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+// StatusApiVersion is the version of the /api/v2/status response
+const StatusApiVersion = 2
+
+const (
+	HealthOk       = "ok"
+	HealthDegraded = "degraded"
+	HealthError    = "error"
+)
+
+const (
+	routerQueueDegradedPct = 80                 // router input queue fill percentage that counts as degraded
+	diskFreeDegradedBytes  = 1024 * 1024 * 1024 // 1GB
+	diskFreeErrorBytes     = 100 * 1024 * 1024  // 100MB
+)
+
+var serverStartTime = time.Now()
+
+// SubsystemHealth is the health of a single monitor subsystem
+type SubsystemHealth struct {
+	Status  string `json:"status"` // ok, degraded, or error
+	Message string `json:"message,omitempty"`
+	Details any    `json:"details,omitempty"`
+}
+
+// StatusAppRun is the per app run part of the status response
+type StatusAppRun struct {
+	AppRunId    string             `json:"apprunid"`
+	AppName     string             `json:"appname"`
+	IsRunning   bool               `json:"isrunning"`
+	Status      string             `json:"status"`
+	StartTime   int64              `json:"starttime"`
+	LastModTime int64              `json:"lastmodtime"`
+	NumLogs     int                `json:"numlogs"`
+	Ingest      apppeer.IngestRate `json:"ingest"`
+}
+
+// StatusV2 is the response of /api/v2/status.
+// Status is the worst status of the subsystems, the HTTP status code is 503 when it is "error".
+type StatusV2 struct {
+	StatusVersion  int                        `json:"statusversion"`
+	Status         string                     `json:"status"`
+	Time           int64                      `json:"time"`
+	Version        string                     `json:"version"`
+	StartTime      int64                      `json:"starttime"`
+	UptimeMs       int64                      `json:"uptimems"`
+	HasConnections bool                       `json:"hasconnections"`
+	Subsystems     map[string]SubsystemHealth `json:"subsystems"`
+	AppRuns        []StatusAppRun             `json:"appruns"`
+}
+
+// handleStatusV2 returns the versioned status of the monitor (subsystem health, uptime, and ingestion rates)
+func handleStatusV2(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	status := StatusV2{
+		StatusVersion: StatusApiVersion,
+		Time:          now.UnixMilli(),
+		Version:       serverbase.OutrigServerVersion,
+		StartTime:     serverStartTime.UnixMilli(),
+		UptimeMs:      now.Sub(serverStartTime).Milliseconds(),
+		Subsystems: map[string]SubsystemHealth{
+			"rpcrouter": getRouterHealth(),
+			"storage":   getStorageHealth(),
+			"websocket": getWebSocketHealth(),
+			"disk":      getDiskHealth(),
+		},
+		AppRuns: []StatusAppRun{},
+	}
+	if len(serverbase.GetRuntimeSettings().Downstreams) > 0 {
+		status.Subsystems["federation"] = getFederationHealth()
+	}
+	status.Status = HealthOk
+	for _, health := range status.Subsystems {
+		status.Status = worseHealth(status.Status, health.Status)
+	}
+
+	for _, peer := range apppeer.GetAllAppRunPeers() {
+		if peer.AppInfo == nil {
+			continue
+		}
+		info := peer.GetAppRunInfo()
+		if info.IsRunning {
+			status.HasConnections = true
+		}
+		status.AppRuns = append(status.AppRuns, StatusAppRun{
+			AppRunId:    info.AppRunId,
+			AppName:     info.AppName,
+			IsRunning:   info.IsRunning,
+			Status:      info.Status,
+			StartTime:   info.StartTime,
+			LastModTime: info.LastModTime,
+			NumLogs:     info.NumLogs,
+			Ingest:      peer.GetIngestRate(),
+		})
+	}
+	sort.Slice(status.AppRuns, func(i, j int) bool {
+		return status.AppRuns[i].StartTime < status.AppRuns[j].StartTime
+	})
+
+	barr, err := json.Marshal(map[string]any{"success": true, "data": status})
+	if err != nil {
+		WriteJsonError(w, err)
+		return
+	}
+	w.Header().Set(ContentTypeHeaderKey, ContentTypeJson)
+	if status.Status == HealthError {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.Write(barr)
+}
+
+func worseHealth(a string, b string) string {
+	rank := map[string]int{HealthOk: 0, HealthDegraded: 1, HealthError: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+func getRouterHealth() SubsystemHealth {
+	stats := rpc.GetDefaultRouter().GetStats()
+	health := SubsystemHealth{Status: HealthOk, Details: stats}
+	if stats.InputQueueCap > 0 && stats.InputQueueLen*100 >= stats.InputQueueCap*routerQueueDegradedPct {
+		health.Status = HealthDegraded
+		health.Message = fmt.Sprintf("input queue is %d/%d full", stats.InputQueueLen, stats.InputQueueCap)
+	}
+	return health
+}
+
+func getWebSocketHealth() SubsystemHealth {
+	return SubsystemHealth{
+		Status:  HealthOk,
+		Details: map[string]int{"numconnections": len(ConnMap.Keys())},
+	}
+}
+
+// getFederationHealth is degraded when a downstream monitor is not connected
+func getFederationHealth() SubsystemHealth {
+	downstreams := federation.GetDownstreamStatus()
+	health := SubsystemHealth{Status: HealthOk, Details: downstreams}
+	var disconnected []string
+	for _, ds := range downstreams {
+		if !ds.Connected {
+			disconnected = append(disconnected, ds.Name)
+		}
+	}
+	if len(disconnected) > 0 {
+		health.Status = HealthDegraded
+		health.Message = fmt.Sprintf("downstream monitors not connected: %s", strings.Join(disconnected, ", "))
+	}
+	return health
+}
+
+// getStorageHealth checks that the data directory exists and is writable
+func getStorageHealth() SubsystemHealth {
+	dataDir := utilfn.ExpandHomeDir(serverbase.GetOutrigDataDir())
+	details := map[string]any{
+		"datadir":         dataDir,
+		"logbuffersizemb": serverbase.GetRuntimeSettings().LogBufferSizeMB,
+	}
+	testFile, err := os.CreateTemp(dataDir, ".statuscheck-*")
+	if err != nil {
+		return SubsystemHealth{Status: HealthError, Message: fmt.Sprintf("data directory is not writable: %v", err), Details: details}
+	}
+	testFile.Close()
+	os.Remove(testFile.Name())
+	return SubsystemHealth{Status: HealthOk, Details: details}
+}
+
+// getDiskHealth reports the size of the data directory and the free space on its filesystem
+// (the directory sizes are cached, status is polled by health checks and walking the data dir is slow)
+func getDiskHealth() SubsystemHealth {
+	dataDir := utilfn.ExpandHomeDir(serverbase.GetOutrigDataDir())
+	details := map[string]int64{
+		"datadirbytes":   serverbase.GetCachedDirSize(dataDir),
+		"logbufferbytes": serverbase.GetCachedDirSize(utilfn.ExpandHomeDir(serverbase.GetLogBufferDir())),
+	}
+	freeBytes, totalBytes, err := serverbase.GetDiskSpace(dataDir)
+	if err != nil {
+		return SubsystemHealth{Status: HealthDegraded, Message: fmt.Sprintf("cannot get free disk space: %v", err), Details: details}
+	}
+	details["freebytes"] = freeBytes
+	details["totalbytes"] = totalBytes
+	health := SubsystemHealth{Status: HealthOk, Details: details}
+	if freeBytes < diskFreeErrorBytes {
+		health.Status = HealthError
+		health.Message = fmt.Sprintf("only %dMB of disk space left", freeBytes/(1024*1024))
+	} else if freeBytes < diskFreeDegradedBytes {
+		health.Status = HealthDegraded
+		health.Message = fmt.Sprintf("only %dMB of disk space left", freeBytes/(1024*1024))
+	}
+	return health
+}
@@ -17,7 +17,10 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/outrigdev/outrig"
 	"github.com/outrigdev/outrig/server/pkg/apppeer"
+	"github.com/outrigdev/outrig/server/pkg/auditlog"
 	"github.com/outrigdev/outrig/server/pkg/serverbase"
+	"github.com/outrigdev/outrig/server/pkg/snapshots"
+	"github.com/outrigdev/outrig/server/pkg/updatecheck"
 )
 
 // Header constants
@@ -55,7 +58,9 @@ type TrayAppRunInfo struct {
 	AppRunId  string `json:"apprunid"`
 	AppName   string `json:"appname"`
 	IsRunning bool   `json:"isrunning"`
+	Status    string `json:"status"` // running, done, or disconnected (the tray notifies on unexpected disconnects)
 	StartTime int64  `json:"starttime"`
+	NumPanics int    `json:"numpanics,omitempty"`
 }
 
 func WriteJsonError(w http.ResponseWriter, errVal error) {
@@ -111,7 +116,9 @@ func handleStatus(w http.ResponseWriter, r *http.Request) {
 			AppRunId:  info.AppRunId,
 			AppName:   info.AppName,
 			IsRunning: info.IsRunning,
+			Status:    info.Status,
 			StartTime: info.StartTime,
+			NumPanics: info.NumPanics,
 		})
 	}
 
@@ -121,6 +128,8 @@ func handleStatus(w http.ResponseWriter, r *http.Request) {
 		"hasconnections": hasConnections,
 		"appruns":        trayAppRuns,
 		"version":        serverbase.OutrigServerVersion,
+		"updatecheckseq": updatecheck.GetTrayUpdateCheckSeq(),
+		"diskwarning":    apppeer.GetDataDirWarning(),
 	})
 }
 
@@ -157,6 +166,18 @@ func validateOriginAndReferrer(r *http.Request) bool {
 	return true
 }
 
+// sameOriginMiddleware rejects browser requests from other sites to the /api endpoints. Other origins
+// can only use the API key authenticated embed API (/api/embed/...), which does its own CORS checks.
+func sameOriginMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/embed/") && !serverbase.IsDev() && !validateOriginAndReferrer(r) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // handleShutdown handles POST requests to shutdown the server
 func handleShutdown(config *WebConfig) WebFnType {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -178,6 +199,8 @@ func handleShutdown(config *WebConfig) WebFnType {
 			return
 		}
 		
+		auditlog.Record("web:"+r.RemoteAddr, "/api/shutdown", "", nil, nil)
+
 		// Send success response before shutting down
 		WriteJsonSuccess(w, map[string]interface{}{
 			"message": "shutdown initiated",
@@ -191,6 +214,63 @@ func handleShutdown(config *WebConfig) WebFnType {
 	}
 }
 
+// handleClearAppRun removes a single (non-running) app run, used by the tray app
+func handleClearAppRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !validateOriginAndReferrer(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	appRunId := r.URL.Query().Get("apprunid")
+	if appRunId == "" {
+		WriteJsonError(w, fmt.Errorf("apprunid is required"))
+		return
+	}
+	err := apppeer.ClearAppRun(appRunId)
+	auditlog.Record("web:"+r.RemoteAddr, "/api/clearapprun", appRunId, nil, err)
+	if err != nil {
+		WriteJsonError(w, err)
+		return
+	}
+	WriteJsonSuccess(w, map[string]interface{}{
+		"apprunid": appRunId,
+	})
+}
+
+// handleDiskUsage returns the size of the data directory and the footprint of each app run
+func handleDiskUsage(w http.ResponseWriter, r *http.Request) {
+	WriteJsonSuccess(w, apppeer.GetDiskUsage())
+}
+
+// handleCompactAppRunStore compacts (or with drop=1, clears) one of the stores of an app run
+func handleCompactAppRunStore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !validateOriginAndReferrer(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	appRunId := r.URL.Query().Get("apprunid")
+	store := r.URL.Query().Get("store")
+	if appRunId == "" || store == "" {
+		WriteJsonError(w, fmt.Errorf("apprunid and store are required"))
+		return
+	}
+	drop := r.URL.Query().Get("drop") == "1"
+	result, err := apppeer.CompactAppRunStore(appRunId, store, drop)
+	auditlog.Record("web:"+r.RemoteAddr, "/api/compactapprunstore", appRunId, map[string]any{"store": store, "drop": drop}, err)
+	if err != nil {
+		WriteJsonError(w, err)
+		return
+	}
+	WriteJsonSuccess(w, result)
+}
+
 func WebFnWrap(opts WebFnOpts, fn WebFnType) WebFnType {
 	return func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
@@ -231,10 +311,28 @@ func runWebServerInternal(ctx context.Context, listener net.Listener, config *We
 	apiRouter := gr.PathPrefix("/api").Subrouter()
 	apiRouter.HandleFunc("/health", WebFnWrap(WebFnOpts{AllowCaching: false, JsonErrors: true}, handleHealth))
 	apiRouter.HandleFunc("/status", WebFnWrap(WebFnOpts{AllowCaching: false, JsonErrors: true}, handleStatus))
+	apiRouter.HandleFunc("/v2/status", WebFnWrap(WebFnOpts{AllowCaching: false, JsonErrors: true}, handleStatusV2))
 	apiRouter.HandleFunc("/shutdown", WebFnWrap(WebFnOpts{AllowCaching: false, JsonErrors: true}, handleShutdown(config)))
+	apiRouter.HandleFunc("/clearapprun", WebFnWrap(WebFnOpts{AllowCaching: false, JsonErrors: true}, handleClearAppRun))
+	apiRouter.HandleFunc("/diskusage", WebFnWrap(WebFnOpts{AllowCaching: false, JsonErrors: true}, handleDiskUsage))
+	apiRouter.HandleFunc("/compactapprunstore", WebFnWrap(WebFnOpts{AllowCaching: false, JsonErrors: true}, handleCompactAppRunStore))
+	apiRouter.HandleFunc("/ingest/logs", WebFnWrap(WebFnOpts{AllowCaching: false, JsonErrors: true}, handleIngestLogs))
+	apiRouter.HandleFunc("/import", WebFnWrap(WebFnOpts{AllowCaching: false, JsonErrors: true}, handleImportBundle))
+	apiRouter.HandleFunc("/embed/appruns", WebFnWrap(WebFnOpts{AllowCaching: false, JsonErrors: true}, embedApiWrap("", handleEmbedAppRuns)))
+	apiRouter.HandleFunc("/embed/goroutines", WebFnWrap(WebFnOpts{AllowCaching: false, JsonErrors: true}, embedApiWrap(serverbase.ApiKeyScopeGoRoutines, handleEmbedGoRoutines)))
+	apiRouter.HandleFunc("/embed/logs", WebFnWrap(WebFnOpts{AllowCaching: false, JsonErrors: true}, embedApiWrap(serverbase.ApiKeyScopeLogs, handleEmbedLogs)))
+	apiRouter.HandleFunc("/embed/rpc", WebFnWrap(WebFnOpts{AllowCaching: false, JsonErrors: true}, embedApiWrap("", handleEmbedRpc)))
+	apiRouter.Use(sameOriginMiddleware)
 
 	// Add more API endpoints here as needed
 
+	gr.HandleFunc(ConfigSchemaPath, WebFnWrap(WebFnOpts{AllowCaching: true}, handleConfigSchema))
+	gr.HandleFunc(apppeer.CPUProfileDownloadPath, WebFnWrap(WebFnOpts{AllowCaching: false}, handleCPUProfileDownload))
+	gr.HandleFunc(apppeer.ExecTraceDownloadPath, WebFnWrap(WebFnOpts{AllowCaching: false}, handleExecTraceDownload))
+	gr.HandleFunc(apppeer.GoroutineDumpDownloadPath, WebFnWrap(WebFnOpts{AllowCaching: false}, handleGoroutineDumpDownload))
+	gr.HandleFunc(apppeer.BundleDownloadPath, WebFnWrap(WebFnOpts{AllowCaching: false}, handleExportDownload))
+	gr.HandleFunc(snapshots.SnapshotDownloadPath, WebFnWrap(WebFnOpts{AllowCaching: false}, handleSnapshotDownload))
+
 	fileSystem := GetFileSystem()
 
 	// Handle SPA routing - serve static files or fall back to index.html
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,7 +17,9 @@ import (
 	"github.com/outrigdev/outrig"
 	"github.com/outrigdev/outrig/pkg/utilds"
 	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/federation"
 	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
 )
 
 // WSInfo contains information about a WebSocket connection
@@ -69,17 +72,24 @@ type WSEventType struct {
 }
 
 type WebSocketModel struct {
-	ConnId   string
-	RouteId  string
-	Conn     *websocket.Conn
-	OutputCh chan WSEventType
+	ConnId    string
+	RouteId   string
+	Federated bool // a hub monitor, only the read-only federation.FederatedCommands are accepted
+	Conn      *websocket.Conn
+	OutputCh  chan WSEventType
 }
 
 var WebSocketUpgrader = websocket.Upgrader{
 	ReadBufferSize:   4 * 1024,
 	WriteBufferSize:  32 * 1024,
 	HandshakeTimeout: 1 * time.Second,
-	CheckOrigin:      func(r *http.Request) bool { return true },
+	CheckOrigin:      checkWsOrigin,
+}
+
+// checkWsOrigin only allows websocket connections from the Outrig UI itself (or from non-browser clients),
+// the websocket has full access to the monitor so other sites (even the --embed-origin ones) can't open it
+func checkWsOrigin(r *http.Request) bool {
+	return serverbase.IsDev() || validateOriginAndReferrer(r)
 }
 
 // HandleWs handles WebSocket connections
@@ -111,7 +121,51 @@ func processMessage(event WSEventType, rpcCh chan []byte) {
 	log.Printf("#websocket invalid message type: %s\n", event.Type)
 }
 
-func ReadLoop(conn *websocket.Conn, outputCh chan WSEventType, closeCh chan any, connId string, rpcCh chan []byte) {
+// federatedMessageError returns why an rpc message from a hub monitor is not accepted ("" if it is).
+// A hub may only run the read-only federation.FederatedCommands on this monitor, and send responses and cancels.
+func federatedMessageError(msg *rpc.RpcMessage) string {
+	if msg.DataBatch != nil {
+		return "batched messages are not accepted from a hub monitor"
+	}
+	if msg.Command == "" {
+		return ""
+	}
+	if !federation.FederatedCommands[msg.Command] {
+		return fmt.Sprintf("%s is not available to a hub monitor (read-only)", msg.Command)
+	}
+	if msg.Route != "" {
+		return fmt.Sprintf("%s cannot be routed to %q by a hub monitor", msg.Command, msg.Route)
+	}
+	return ""
+}
+
+// checkFederatedEvent returns false (and sends an error response to requests) for an rpc event from a hub
+// monitor that is not accepted
+func checkFederatedEvent(event WSEventType, outputCh chan WSEventType, connId string) bool {
+	if event.Type != EventType_Rpc {
+		return true
+	}
+	msgBytes, err := json.Marshal(event.Data)
+	if err != nil {
+		return false
+	}
+	var msg rpc.RpcMessage
+	if err := json.Unmarshal(msgBytes, &msg); err != nil {
+		return false
+	}
+	errStr := federatedMessageError(&msg)
+	if errStr == "" {
+		return true
+	}
+	log.Printf("#websocket rejected message from hub (%s): %s\n", connId, errStr)
+	if msg.ReqId != "" {
+		resp := rpc.RpcMessage{ResId: msg.ReqId, Error: errStr}
+		outputCh <- WSEventType{Type: EventType_Rpc, Ts: time.Now().UnixMilli(), Data: resp}
+	}
+	return false
+}
+
+func ReadLoop(conn *websocket.Conn, outputCh chan WSEventType, closeCh chan any, connId string, rpcCh chan []byte, federated bool) {
 	readWait := wsReadWaitTimeout
 	conn.SetReadLimit(64 * 1024)
 	conn.SetReadDeadline(time.Now().Add(readWait))
@@ -147,6 +201,9 @@ func ReadLoop(conn *websocket.Conn, outputCh chan WSEventType, closeCh chan any,
 			outputCh <- pongMessage
 			continue
 		}
+		if federated && !checkFederatedEvent(event, outputCh, connId) {
+			continue
+		}
 		outrig.Go("ws.read/process").WithTags("#websocket").Run(func() {
 			processMessage(event, rpcCh)
 		})
@@ -210,7 +267,30 @@ func WriteLoop(conn *websocket.Conn, outputCh chan WSEventType, closeCh chan any
 	}
 }
 
+// authorizeFederation checks the api key of a connection from a hub monitor (a "hub:" route, or any
+// connection with an api key), it needs the federation scope. It writes the error response and returns
+// an error if the connection is not allowed, federated is false for other (web UI) connections.
+func authorizeFederation(w http.ResponseWriter, r *http.Request) (bool, error) {
+	routeId := r.URL.Query().Get("routeid")
+	if !strings.HasPrefix(routeId, rpc.RoutePrefix_Hub) && r.Header.Get("Authorization") == "" {
+		return false, nil
+	}
+	apiKey, ok := serverbase.LookupApiKey(getApiKeyToken(r))
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false, fmt.Errorf("hub connection (routeid:%q) without a valid api key", routeId)
+	}
+	if !checkApiKeyScope(w, apiKey, serverbase.ApiKeyScopeFederation) {
+		return false, fmt.Errorf("hub connection (routeid:%q) with api key %q, it does not have the %q scope", routeId, apiKey.Name, serverbase.ApiKeyScopeFederation)
+	}
+	return true, nil
+}
+
 func HandleWsInternal(w http.ResponseWriter, r *http.Request) error {
+	federated, err := authorizeFederation(w, r)
+	if err != nil {
+		return err
+	}
 	conn, err := WebSocketUpgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return fmt.Errorf("WebSocket Upgrade Failed: %v", err)
@@ -225,12 +305,13 @@ func HandleWsInternal(w http.ResponseWriter, r *http.Request) error {
 	outputCh := make(chan WSEventType, 100)
 	closeCh := make(chan any)
 
-	log.Printf("#websocket new connection: connid:%s, routeid:%q\n", connId, routeId)
+	log.Printf("#websocket new connection: connid:%s, routeid:%q, federated:%v\n", connId, routeId, federated)
 	wsModel := &WebSocketModel{
-		ConnId:   connId,
-		RouteId:  routeId,
-		Conn:     conn,
-		OutputCh: outputCh,
+		ConnId:    connId,
+		RouteId:   routeId,
+		Federated: federated,
+		Conn:      conn,
+		OutputCh:  outputCh,
 	}
 	ConnMap.Set(connId, wsModel)
 	defer func() {
@@ -246,8 +327,13 @@ func HandleWsInternal(w http.ResponseWriter, r *http.Request) error {
 	wg := &sync.WaitGroup{}
 	wg.Add(2)
 
+	// rpc messages to the client are batched (one websocket frame for a burst of streamed log lines or events)
+	batchedCh := make(chan []byte, rpc.DefaultOutputChSize)
+	outrig.Go("ws.batcher").WithTags("#websocket").Run(func() {
+		rpc.RunRpcBatcher(proxy.ToRemoteCh, batchedCh, rpc.DefaultBatchOpts())
+	})
 	outrig.Go("ws.proxy").WithTags("#websocket").Run(func() {
-		for msg := range proxy.ToRemoteCh {
+		for msg := range batchedCh {
 			rawMsg := json.RawMessage(msg)
 			outputCh <- WSEventType{Type: EventType_Rpc, Ts: time.Now().UnixMilli(), Data: rawMsg}
 		}
@@ -256,7 +342,7 @@ func HandleWsInternal(w http.ResponseWriter, r *http.Request) error {
 	outrig.Go("ws.read").WithTags("#websocket").Run(func() {
 		// read loop
 		defer wg.Done()
-		ReadLoop(conn, outputCh, closeCh, connId, proxy.FromRemoteCh)
+		ReadLoop(conn, outputCh, closeCh, connId, proxy.FromRemoteCh, federated)
 	})
 
 	outrig.Go("ws.write").WithTags("#websocket").Run(func() {
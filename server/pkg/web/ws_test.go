@@ -0,0 +1,96 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/outrigdev/outrig/server/pkg/rpc"
+	"github.com/outrigdev/outrig/server/pkg/serverbase"
+)
+
+func TestAuthorizeFederation(t *testing.T) {
+	fedKey := makeTestApiKey(t, "hub", serverbase.ApiKeyScopeFederation)
+	logsKey, err := serverbase.CreateApiKey("logsonly", []string{serverbase.ApiKeyScopeLogs})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		routeId         string
+		authHeader      string
+		expectFederated bool
+		expectCode      int // 0 if the connection is allowed
+	}{
+		{"web ui", "tab:1", "", false, 0},
+		{"hub without a key", "hub:1", "", false, http.StatusUnauthorized},
+		{"hub with an invalid key", "hub:1", "Bearer ork_invalid", false, http.StatusUnauthorized},
+		{"hub with a key without the scope", "hub:1", "Bearer " + logsKey.Key, false, http.StatusForbidden},
+		{"hub", "hub:1", "Bearer " + fedKey, true, 0},
+		{"key on another route", "tab:1", "Bearer " + fedKey, true, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ws?routeid="+tc.routeId, nil)
+			if tc.authHeader != "" {
+				req.Header.Set("Authorization", tc.authHeader)
+			}
+			rec := httptest.NewRecorder()
+			federated, err := authorizeFederation(rec, req)
+			if tc.expectCode == 0 && err != nil {
+				t.Fatalf("connection refused: %v", err)
+			}
+			if tc.expectCode != 0 && (err == nil || rec.Code != tc.expectCode) {
+				t.Fatalf("got status %d (err %v), want %d", rec.Code, err, tc.expectCode)
+			}
+			if federated != tc.expectFederated {
+				t.Errorf("got federated=%v, want %v", federated, tc.expectFederated)
+			}
+		})
+	}
+}
+
+func TestFederatedMessageError(t *testing.T) {
+	tests := []struct {
+		name        string
+		msg         rpc.RpcMessage
+		expectAllow bool
+	}{
+		{"federated command", rpc.RpcMessage{Command: "logtail", ReqId: "req1"}, true},
+		{"response", rpc.RpcMessage{ResId: "req1", Data: "ok"}, true},
+		{"cancel", rpc.RpcMessage{ReqId: "req1", Cancel: true}, true},
+		{"mutating command", rpc.RpcMessage{Command: "clearapprun", ReqId: "req1"}, false},
+		{"marked lines update", rpc.RpcMessage{Command: "logupdatemarkedlines", ReqId: "req1"}, false},
+		{"routed command", rpc.RpcMessage{Command: "logtail", ReqId: "req1", Route: "tab:1"}, false},
+		{"batch", rpc.RpcMessage{DataBatch: []json.RawMessage{json.RawMessage(`{"command":"clearapprun"}`)}}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := federatedMessageError(&tc.msg); (got == "") != tc.expectAllow {
+				t.Errorf("got error %q, want allowed=%v", got, tc.expectAllow)
+			}
+		})
+	}
+}
+
+func TestCheckFederatedEvent(t *testing.T) {
+	outputCh := make(chan WSEventType, 1)
+	allowed := WSEventType{Type: EventType_Rpc, Data: map[string]any{"command": "getappruns", "reqid": "req1"}}
+	if !checkFederatedEvent(allowed, outputCh, "conn1") || len(outputCh) != 0 {
+		t.Errorf("federated command was rejected")
+	}
+
+	rejected := WSEventType{Type: EventType_Rpc, Data: map[string]any{"command": "clearapprun", "reqid": "req2"}}
+	if checkFederatedEvent(rejected, outputCh, "conn1") {
+		t.Fatalf("mutating command was accepted")
+	}
+	resp, ok := (<-outputCh).Data.(rpc.RpcMessage)
+	if !ok || resp.ResId != "req2" || resp.Error == "" {
+		t.Errorf("got response %+v, want an error for req2", resp)
+	}
+}
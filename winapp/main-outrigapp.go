@@ -0,0 +1,867 @@
+// Copyright 2025, Command Line Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build windows
+
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"fyne.io/systray"
+	"github.com/Masterminds/semver/v3"
+	"github.com/outrigdev/outrig/pkg/utilfn"
+	"github.com/outrigdev/outrig/server/pkg/updatecheck"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+var (
+	// Version information
+	OutrigAppVersion = "v0.10.0-alpha.0"
+
+	// Server process
+	serverCmd          *exec.Cmd
+	serverLock         sync.Mutex
+	serverFirstStartCh = make(chan bool)
+	serverStartOnce    sync.Once
+
+	statusUpdateLock sync.Mutex
+	rebuildMenuLock  sync.Mutex
+	lastServerStatus ServerStatus
+	lastIconType     string
+
+	// Menu items
+	mCheckUpdatesGlobal *systray.MenuItem
+
+	isQuitting atomic.Bool
+
+	// Update checking (GitHub releases, there is no auto-updater on Windows)
+	latestVersion      string
+	latestVersionLock  sync.RWMutex
+	lastUpdateCheck    atomic.Int64
+	isCheckingUpdates  atomic.Bool
+	lastUpdateCheckSeq atomic.Int64
+	hasUpdateCheckSeq  atomic.Bool
+)
+
+const (
+	IconTypeNormal = "normal"
+	IconTypeError  = "error"
+	IconTypeConn   = "conn"
+
+	// App status icon types
+	IconTypeAppRunning = "app-running"
+	IconTypeAppStopped = "app-stopped"
+
+	// Log file names
+	OutrigAppLogFile    = "outrigapp.log"
+	OutrigServerLogFile = "outrigserver.log"
+
+	OutrigServerUrl = "http://localhost:5005"
+
+	// Update check interval
+	UpdateCheckInterval = 8 * time.Hour
+
+	// Max number of runs listed in an app's submenu
+	MaxTrayAppRunsPerGroup = 10
+
+	// CREATE_NO_WINDOW keeps the server from opening a console window
+	createNoWindow = 0x08000000
+)
+
+var iconDataMap = make(map[string][]byte)
+
+//go:embed assets/outrigapp-trayicon.ico
+var baseIconData []byte
+
+//go:embed assets/outrigapp-trayicon-error.ico
+var errorIconData []byte
+
+//go:embed assets/outrigapp-trayicon-conn.ico
+var connIconData []byte
+
+//go:embed assets/wifi-template.png
+var wifiIconData []byte
+
+//go:embed assets/wifioff-template.png
+var wifiOffIconData []byte
+
+//go:embed assets/wrench-template.png
+var wrenchIconData []byte
+
+//go:embed assets/download-template.png
+var downloadIconData []byte
+
+func init() {
+	iconDataMap[IconTypeNormal] = baseIconData
+	iconDataMap[IconTypeError] = errorIconData
+	iconDataMap[IconTypeConn] = connIconData
+	iconDataMap[IconTypeAppRunning] = wifiIconData
+	iconDataMap[IconTypeAppStopped] = wifiOffIconData
+}
+
+// getOutrigPath returns the path to the outrig executable
+func getOutrigPath() string {
+	// Always use the outrig.exe in the same directory
+	execPath, err := os.Executable()
+	if err != nil {
+		log.Printf("Error getting executable path: %v", err)
+		return "outrig.exe"
+	}
+
+	return filepath.Join(filepath.Dir(execPath), "outrig.exe")
+}
+
+// StatusResponse represents the response from the status endpoint
+type StatusResponse struct {
+	Success bool       `json:"success"`
+	Data    StatusData `json:"data"`
+}
+
+type StatusData struct {
+	Status         string           `json:"status"`
+	Time           int64            `json:"time"`
+	HasConnections bool             `json:"hasconnections"`
+	AppRuns        []TrayAppRunInfo `json:"appruns"`
+	Version        string           `json:"version"`
+	DiskWarning    string           `json:"diskwarning,omitempty"`
+	UpdateCheckSeq int64            `json:"updatecheckseq"`
+}
+
+type TrayAppRunInfo struct {
+	AppRunId  string `json:"apprunid"`
+	AppName   string `json:"appname"`
+	IsRunning bool   `json:"isrunning"`
+	StartTime int64  `json:"starttime"`
+}
+
+// AppGroup represents a group of app runs with the same app name
+type AppGroup struct {
+	AppName string
+	AppRuns []TrayAppRunInfo
+}
+
+// GetTopAppRun returns the highest ranked app run in the group
+// Ranking is: IsRunning (true first), then StartTime (newest first)
+func (g *AppGroup) GetTopAppRun() TrayAppRunInfo {
+	if len(g.AppRuns) == 0 {
+		return TrayAppRunInfo{}
+	}
+	sortAppRuns(g.AppRuns)
+	return g.AppRuns[0]
+}
+
+// sortAppRuns sorts app runs by IsRunning (true first) and then by StartTime (newest first)
+func sortAppRuns(appRuns []TrayAppRunInfo) {
+	sort.Slice(appRuns, func(i, j int) bool {
+		if appRuns[i].IsRunning != appRuns[j].IsRunning {
+			return appRuns[i].IsRunning
+		}
+		return appRuns[i].StartTime > appRuns[j].StartTime
+	})
+}
+
+// ServerStatus holds the current status of the server
+type ServerStatus struct {
+	Running        bool
+	HasConnections bool
+	AppRuns        []TrayAppRunInfo
+	Version        string
+	DiskWarning    string // set when the data directory is over its max size
+}
+
+func getIconTypeForStatus(status ServerStatus) string {
+	if !status.Running {
+		return IconTypeError
+	}
+	if status.HasConnections {
+		return IconTypeConn
+	}
+	return IconTypeNormal
+}
+
+func updateIcon(iconType string) {
+	if iconType != lastIconType {
+		systray.SetIcon(iconDataMap[iconType])
+	}
+	var statusMsg string
+	switch iconType {
+	case IconTypeNormal:
+		statusMsg = "Outrig Server is Running"
+	case IconTypeConn:
+		statusMsg = "Outrig Server is running with Active Connections"
+	case IconTypeError:
+		statusMsg = "Server is Not Running"
+	}
+	systray.SetTooltip(statusMsg)
+	lastIconType = iconType
+}
+
+func getServerStatus() ServerStatus {
+	status := ServerStatus{
+		AppRuns: []TrayAppRunInfo{},
+	}
+
+	// Check if the server process exists
+	if serverCmd == nil || serverCmd.Process == nil {
+		return status
+	}
+	client := http.Client{
+		Timeout: 500 * time.Millisecond,
+	}
+	resp, err := client.Get(OutrigServerUrl + "/api/status")
+	if err != nil {
+		return status
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return status
+	}
+
+	status.Running = true
+	var statusResp StatusResponse
+	decoder := json.NewDecoder(resp.Body)
+	if err := decoder.Decode(&statusResp); err == nil {
+		status.HasConnections = statusResp.Data.HasConnections
+		status.AppRuns = statusResp.Data.AppRuns
+		status.Version = statusResp.Data.Version
+		status.DiskWarning = statusResp.Data.DiskWarning
+		checkUpdateCheckSeq(statusResp.Data.UpdateCheckSeq)
+
+		// Sort AppRuns by apprunid to ensure consistent ordering
+		sort.Slice(status.AppRuns, func(i, j int) bool {
+			return status.AppRuns[i].AppRunId < status.AppRuns[j].AppRunId
+		})
+	}
+
+	return status
+}
+
+// checkUpdateCheckSeq runs an update check when the server asks for one (the macOS app gets a SIGUSR1 instead)
+func checkUpdateCheckSeq(seq int64) {
+	prevSeq := lastUpdateCheckSeq.Swap(seq)
+	if hasUpdateCheckSeq.Swap(true) && seq != prevSeq {
+		go checkForUpdates()
+	}
+}
+
+func updateServerStatus(serverStatus ServerStatus) {
+	statusUpdateLock.Lock()
+	defer statusUpdateLock.Unlock()
+
+	if isQuitting.Load() {
+		updateIcon(IconTypeError)
+		return
+	}
+
+	defer func() {
+		lastServerStatus = serverStatus
+	}()
+
+	updateIcon(getIconTypeForStatus(serverStatus))
+
+	if serverStatus.Running {
+		serverStartOnce.Do(func() {
+			close(serverFirstStartCh)
+		})
+	}
+
+	if !reflect.DeepEqual(lastServerStatus, serverStatus) {
+		rebuildMenu(serverStatus)
+	}
+}
+
+func startServer() {
+	serverLock.Lock()
+	defer serverLock.Unlock()
+
+	log.Printf("Starting Outrig server...\n")
+	outrigPath := getOutrigPath()
+	trayPid := os.Getpid()
+	serverCmd = exec.Command(outrigPath, "monitor", "foreground", "--close-on-stdin", "--tray-pid", fmt.Sprintf("%d", trayPid))
+	serverCmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: createNoWindow,
+	}
+
+	// We keep stdin open, if outrigapp crashes it is closed and the server shuts down (--close-on-stdin)
+	stdin, err := serverCmd.StdinPipe()
+	if err != nil {
+		log.Printf("Error creating stdin pipe: %v", err)
+		return
+	}
+
+	logPath := filepath.Join(os.TempDir(), OutrigServerLogFile)
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		log.Printf("Error opening log file for server output: %v", err)
+	} else {
+		serverCmd.Stdout = logFile
+		serverCmd.Stderr = logFile
+	}
+
+	err = serverCmd.Start()
+	if err != nil {
+		log.Printf("Error starting server: %v", err)
+		return
+	}
+
+	log.Printf("Outrig server started\n")
+
+	go func(cmd *exec.Cmd, stdinPipe io.WriteCloser) {
+		err := cmd.Wait()
+		if err != nil {
+			log.Printf("Server process exited with error: %v", err)
+		} else {
+			log.Printf("Server process exited normally")
+		}
+		stdinPipe.Close()
+		updateServerStatus(getServerStatus())
+	}(serverCmd, stdin)
+}
+
+// stopServer asks the server to shut down through /api/shutdown (Windows has no SIGINT for other processes)
+// and kills it if it doesn't exit within 5 seconds
+func stopServer() {
+	serverLock.Lock()
+	defer serverLock.Unlock()
+
+	log.Printf("Stopping Outrig server...\n")
+
+	if serverCmd != nil && serverCmd.Process != nil {
+		client := http.Client{
+			Timeout: 2 * time.Second,
+		}
+		resp, err := client.Post(OutrigServerUrl+"/api/shutdown", "application/json", nil)
+		if err != nil {
+			log.Printf("Error requesting server shutdown: %v", err)
+			serverCmd.Process.Kill()
+		} else {
+			resp.Body.Close()
+		}
+
+		done := make(chan error, 1)
+		go func(proc *os.Process) {
+			_, err := proc.Wait()
+			done <- err
+		}(serverCmd.Process)
+
+		select {
+		case err := <-done:
+			if err != nil {
+				log.Printf("Error waiting for process to exit: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			log.Printf("Timeout waiting for server to exit, forcing kill\n")
+			serverCmd.Process.Kill()
+		}
+
+		serverCmd = nil
+	}
+
+	log.Printf("Outrig server stopped\n")
+}
+
+func restartServer() {
+	log.Printf("Restarting Outrig server...\n")
+
+	stopServer()
+	startServer()
+
+	log.Printf("Outrig server restarted\n")
+}
+
+func groupAppRuns(appRuns []TrayAppRunInfo) []AppGroup {
+	groupMap := make(map[string][]TrayAppRunInfo)
+	for _, appRun := range appRuns {
+		groupMap[appRun.AppName] = append(groupMap[appRun.AppName], appRun)
+	}
+
+	groups := make([]AppGroup, 0, len(groupMap))
+	for appName, runs := range groupMap {
+		sortAppRuns(runs)
+		groups = append(groups, AppGroup{
+			AppName: appName,
+			AppRuns: runs,
+		})
+	}
+
+	// Sort the groups by their top app run
+	sort.Slice(groups, func(i, j int) bool {
+		topI := groups[i].GetTopAppRun()
+		topJ := groups[j].GetTopAppRun()
+		if topI.IsRunning != topJ.IsRunning {
+			return topI.IsRunning
+		}
+		return topI.StartTime > topJ.StartTime
+	})
+
+	return groups
+}
+
+func rebuildMenu(status ServerStatus) {
+	rebuildMenuLock.Lock()
+	defer rebuildMenuLock.Unlock()
+
+	systray.ResetMenu()
+
+	if status.Running {
+		mOpen := systray.AddMenuItem("Open Outrig", "Open the Outrig web interface @ "+OutrigServerUrl)
+		go func() {
+			for range mOpen.ClickedCh {
+				launchOutrigUrl(OutrigServerUrl)
+			}
+		}()
+	} else {
+		mNotRunning := systray.AddMenuItem("Outrig Server Not Running", "")
+		mNotRunning.Disable()
+	}
+	if status.Running && status.DiskWarning != "" {
+		mDiskWarning := systray.AddMenuItem("⚠ "+status.DiskWarning, "Clear or compact old app runs to free space")
+		mDiskWarning.Disable()
+	}
+
+	systray.AddSeparator()
+
+	mAppsHeader := systray.AddMenuItem("Recent Applications", "")
+	mAppsHeader.Disable()
+
+	// Add a submenu for each app group, listing its runs (running first, then newest)
+	for _, group := range groupAppRuns(status.AppRuns) {
+		topRun := group.GetTopAppRun()
+
+		iconType := IconTypeAppStopped
+		if topRun.IsRunning {
+			iconType = IconTypeAppRunning
+		}
+		groupItem := systray.AddMenuItem(group.AppName, fmt.Sprintf("App Runs for '%s'", group.AppName))
+		groupItem.SetIcon(iconDataMap[iconType])
+
+		for idx, appRun := range group.AppRuns {
+			if idx >= MaxTrayAppRunsPerGroup {
+				moreItem := groupItem.AddSubMenuItem(fmt.Sprintf("%d more runs...", len(group.AppRuns)-idx), "Open Outrig to see all app runs")
+				go func() {
+					for range moreItem.ClickedCh {
+						launchOutrigUrl(OutrigServerUrl)
+					}
+				}()
+				break
+			}
+			addAppRunSubMenu(groupItem, appRun)
+		}
+	}
+
+	systray.AddSeparator()
+	addInstallCLIMenuItems(status)
+
+	if OutrigAppVersion != "" {
+		versionItem := systray.AddMenuItem("Outrig "+OutrigAppVersion, "")
+		versionItem.Disable()
+	}
+
+	newVersion := getLatestVersion()
+	if newVersion != "" {
+		mCheckUpdatesGlobal = systray.AddMenuItem("Download Outrig "+newVersion+"...", "Open the download page for the latest version of Outrig")
+		mCheckUpdatesGlobal.SetIcon(downloadIconData)
+	} else {
+		mCheckUpdatesGlobal = systray.AddMenuItem("Check for Updates...", "")
+	}
+	go func() {
+		for range mCheckUpdatesGlobal.ClickedCh {
+			if getLatestVersion() != "" {
+				launchOutrigUrl(updatecheck.GitHubReleasesPageURL)
+				continue
+			}
+			checkForUpdates()
+		}
+	}()
+
+	systray.AddSeparator()
+
+	mRestart := systray.AddMenuItem("Restart Outrig Server", "")
+	go func() {
+		for range mRestart.ClickedCh {
+			restartServer()
+		}
+	}()
+
+	mQuit := systray.AddMenuItem("Quit Outrig Completely", "")
+	go func() {
+		for range mQuit.ClickedCh {
+			isQuitting.Store(true)
+			updateServerStatus(ServerStatus{})
+			systray.Quit()
+		}
+	}()
+}
+
+// getAppRunMenuTitle returns the title of an app run submenu, e.g. "Running · Jan 2 15:04:05"
+func getAppRunMenuTitle(appRun TrayAppRunInfo) string {
+	state := "Stopped"
+	if appRun.IsRunning {
+		state = "Running"
+	}
+	startTime := time.UnixMilli(appRun.StartTime)
+	format := "15:04:05"
+	if !isToday(startTime) {
+		format = "Jan 2 15:04:05"
+	}
+	return fmt.Sprintf("%s · %s", state, startTime.Format(format))
+}
+
+func isToday(t time.Time) bool {
+	now := time.Now()
+	return t.Year() == now.Year() && t.YearDay() == now.YearDay()
+}
+
+// addAppRunSubMenu adds a submenu with the quick actions for a single app run
+func addAppRunSubMenu(groupItem *systray.MenuItem, appRun TrayAppRunInfo) {
+	runItem := groupItem.AddSubMenuItem(getAppRunMenuTitle(appRun), appRun.AppRunId)
+	iconType := IconTypeAppStopped
+	if appRun.IsRunning {
+		iconType = IconTypeAppRunning
+	}
+	runItem.SetIcon(iconDataMap[iconType])
+
+	mLogs := runItem.AddSubMenuItem("Open Logs", "Open the logs for this app run in the Outrig web interface")
+	mGoroutines := runItem.AddSubMenuItem("Open Goroutines", "Open the goroutines for this app run in the Outrig web interface")
+	mCopyId := runItem.AddSubMenuItem("Copy AppRunId", appRun.AppRunId)
+	mClear := runItem.AddSubMenuItem("Clear Run", "Remove this app run from Outrig")
+	if appRun.IsRunning {
+		mClear.Disable()
+	}
+
+	// the click channels are closed when the menu is rebuilt
+	go func() {
+		for {
+			select {
+			case _, ok := <-mLogs.ClickedCh:
+				if !ok {
+					return
+				}
+				launchOutrigUrl(getAppRunUrl(appRun.AppRunId, "logs"))
+			case _, ok := <-mGoroutines.ClickedCh:
+				if !ok {
+					return
+				}
+				launchOutrigUrl(getAppRunUrl(appRun.AppRunId, "goroutines"))
+			case _, ok := <-mCopyId.ClickedCh:
+				if !ok {
+					return
+				}
+				if err := copyToClipboard(appRun.AppRunId); err != nil {
+					log.Printf("Error copying app run id: %v", err)
+				}
+			case _, ok := <-mClear.ClickedCh:
+				if !ok {
+					return
+				}
+				if err := clearAppRun(appRun.AppRunId); err != nil {
+					log.Printf("Error clearing app run %s: %v", appRun.AppRunId, err)
+					continue
+				}
+				go updateServerStatus(getServerStatus())
+			}
+		}
+	}()
+}
+
+func getAppRunUrl(appRunId string, tab string) string {
+	return fmt.Sprintf("%s/?appRunId=%s&tab=%s", OutrigServerUrl, url.QueryEscape(appRunId), tab)
+}
+
+func launchOutrigUrl(urlStr string) {
+	err := utilfn.LaunchUrl(urlStr)
+	if err != nil {
+		log.Printf("Error opening browser: %v", err)
+	}
+}
+
+func copyToClipboard(text string) error {
+	cmd := exec.Command("clip")
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true, CreationFlags: createNoWindow}
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// clearAppRun asks the server to remove a (non-running) app run
+func clearAppRun(appRunId string) error {
+	client := http.Client{
+		Timeout: 2 * time.Second,
+	}
+	resp, err := client.Post(OutrigServerUrl+"/api/clearapprun?apprunid="+url.QueryEscape(appRunId), "application/json", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("error decoding response (status %d): %w", resp.StatusCode, err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%s", result.Error)
+	}
+	return nil
+}
+
+// getCliDir returns the directory with outrig.exe, which is added to the user's PATH
+func getCliDir() string {
+	return filepath.Dir(getOutrigPath())
+}
+
+// readUserPath returns the user's PATH from the registry (and whether it is a REG_EXPAND_SZ value)
+func readUserPath() (string, bool, error) {
+	key, err := registry.OpenKey(registry.CURRENT_USER, "Environment", registry.QUERY_VALUE)
+	if err != nil {
+		return "", false, err
+	}
+	defer key.Close()
+	val, valType, err := key.GetStringValue("Path")
+	if err == registry.ErrNotExist {
+		return "", true, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return val, valType == registry.EXPAND_SZ, nil
+}
+
+func pathListContains(pathList string, dir string) bool {
+	cleanDir := filepath.Clean(dir)
+	for _, entry := range filepath.SplitList(pathList) {
+		if expanded, err := registry.ExpandString(entry); err == nil {
+			entry = expanded
+		}
+		if strings.EqualFold(filepath.Clean(entry), cleanDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOutrigCLIInstalled checks if the outrig.exe directory is in the user's PATH
+func IsOutrigCLIInstalled() bool {
+	userPath, _, err := readUserPath()
+	if err != nil {
+		log.Printf("Error reading user PATH: %v", err)
+		return false
+	}
+	return pathListContains(userPath, getCliDir())
+}
+
+// InstallOutrigCLI adds the outrig.exe directory to the user's PATH (new terminals pick it up)
+func InstallOutrigCLI() error {
+	userPath, isExpand, err := readUserPath()
+	if err != nil {
+		return fmt.Errorf("error reading user PATH: %w", err)
+	}
+	cliDir := getCliDir()
+	if pathListContains(userPath, cliDir) {
+		return nil
+	}
+	newPath := cliDir
+	if trimmed := strings.TrimRight(userPath, ";"); trimmed != "" {
+		newPath = trimmed + ";" + cliDir
+	}
+	key, err := registry.OpenKey(registry.CURRENT_USER, "Environment", registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("error opening environment registry key: %w", err)
+	}
+	defer key.Close()
+	if isExpand {
+		err = key.SetExpandStringValue("Path", newPath)
+	} else {
+		err = key.SetStringValue("Path", newPath)
+	}
+	if err != nil {
+		return fmt.Errorf("error writing user PATH: %w", err)
+	}
+	log.Printf("Added %s to the user PATH", cliDir)
+	broadcastEnvironmentChange()
+	return nil
+}
+
+// broadcastEnvironmentChange tells running programs (e.g. Explorer) that the environment changed
+func broadcastEnvironmentChange() {
+	const hwndBroadcast = 0xffff
+	const wmSettingChange = 0x001a
+	const smtoAbortIfHung = 0x0002
+	envStr, err := windows.UTF16PtrFromString("Environment")
+	if err != nil {
+		return
+	}
+	sendMessageTimeout := windows.NewLazySystemDLL("user32.dll").NewProc("SendMessageTimeoutW")
+	var result uintptr
+	sendMessageTimeout.Call(hwndBroadcast, wmSettingChange, 0, uintptr(unsafe.Pointer(envStr)), smtoAbortIfHung, 5000, uintptr(unsafe.Pointer(&result)))
+}
+
+func addInstallCLIMenuItems(status ServerStatus) {
+	if IsOutrigCLIInstalled() {
+		return
+	}
+	mInstall := systray.AddMenuItem("Add Outrig CLI to PATH", "Add "+getCliDir()+" to your user PATH")
+	mInstall.SetIcon(wrenchIconData)
+	go func() {
+		for range mInstall.ClickedCh {
+			if err := InstallOutrigCLI(); err != nil {
+				log.Printf("Error installing Outrig CLI: %v", err)
+			}
+			rebuildMenu(status)
+		}
+	}()
+	systray.AddSeparator()
+}
+
+// checkForUpdates checks the latest GitHub release and updates the menu if needed
+func checkForUpdates() {
+	if !isCheckingUpdates.CompareAndSwap(false, true) {
+		return
+	}
+	defer isCheckingUpdates.Store(false)
+	lastUpdateCheck.Store(time.Now().UnixMilli())
+
+	log.Printf("Checking for updates...")
+	releaseVersion, err := updatecheck.GetLatestRelease()
+	if err != nil {
+		log.Printf("Error checking for updates: %v", err)
+		return
+	}
+	log.Printf("Latest version: %s, current version: %s", releaseVersion, OutrigAppVersion)
+
+	current, err := semver.NewVersion(OutrigAppVersion)
+	if err != nil {
+		log.Printf("Error parsing current version: %v", err)
+		return
+	}
+	latest, err := semver.NewVersion(releaseVersion)
+	if err != nil {
+		log.Printf("Error parsing latest version: %v", err)
+		return
+	}
+
+	latestVersionLock.Lock()
+	if latest.GreaterThan(current) {
+		latestVersion = releaseVersion
+		log.Printf("New version available: %s", releaseVersion)
+	} else {
+		latestVersion = ""
+		log.Printf("No new version available")
+	}
+	latestVersionLock.Unlock()
+
+	updateCheckUpdatesMenuItem()
+}
+
+func updateCheckUpdatesMenuItem() {
+	rebuildMenuLock.Lock()
+	defer rebuildMenuLock.Unlock()
+
+	if mCheckUpdatesGlobal == nil {
+		return
+	}
+	newVersion := getLatestVersion()
+	if newVersion != "" {
+		mCheckUpdatesGlobal.SetTitle("Download Outrig " + newVersion + "...")
+		mCheckUpdatesGlobal.SetIcon(downloadIconData)
+	} else {
+		mCheckUpdatesGlobal.SetTitle("Check for Updates...")
+	}
+}
+
+// getLatestVersion returns the latest release version if it is newer than this app
+func getLatestVersion() string {
+	latestVersionLock.RLock()
+	defer latestVersionLock.RUnlock()
+	return latestVersion
+}
+
+func runServerStatusCheckLoop() {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		updateServerStatus(getServerStatus())
+	}
+}
+
+func runUpdateCheckLoop() {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		if time.Now().UnixMilli()-lastUpdateCheck.Load() >= UpdateCheckInterval.Milliseconds() {
+			checkForUpdates()
+		}
+	}
+}
+
+func startServerOnStartup() {
+	startServer()
+
+	select {
+	case <-serverFirstStartCh:
+		time.Sleep(200 * time.Millisecond)
+		log.Printf("Opening browser on startup\n")
+		launchOutrigUrl(OutrigServerUrl)
+	case <-time.After(10 * time.Second):
+		log.Printf("Timeout waiting for server to start on startup\n")
+	}
+}
+
+func onReady() {
+	updateIcon(IconTypeError)
+	rebuildMenu(ServerStatus{})
+
+	go checkForUpdates()
+	go runServerStatusCheckLoop()
+	go runUpdateCheckLoop()
+	go startServerOnStartup()
+}
+
+func onExit() {
+	log.Printf("Exiting OutrigApp...\n")
+	stopServer()
+	log.Printf("OutrigApp exited\n")
+}
+
+func main() {
+	logFile, err := os.OpenFile(filepath.Join(os.TempDir(), OutrigAppLogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err == nil {
+		log.SetOutput(logFile)
+		defer logFile.Close()
+	}
+
+	shutdownChan := make(chan os.Signal, 1)
+	signal.Notify(shutdownChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-shutdownChan
+		log.Printf("Received signal %v, shutting down gracefully", sig)
+		isQuitting.Store(true)
+		systray.Quit()
+	}()
+
+	log.Printf("Starting OutrigApp")
+	log.Printf("CLI in PATH: %v\n", IsOutrigCLIInstalled())
+
+	systray.Run(onReady, onExit)
+}